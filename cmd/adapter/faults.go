@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+)
+
+// clientFaults injects the failures of --enable-fault-injection into the
+// clients of serve. It is only created in builds with the faultinject build
+// tag; a nil clientFaults leaves the clients untouched.
+type clientFaults struct {
+	// apiTransport wraps the round trippers of the HyperFleet API client and its targets
+	apiTransport func(http.RoundTripper) http.RoundTripper
+	// transportClient wraps the transport client of the executor
+	transportClient func(transportclient.TransportClient) transportclient.TransportClient
+}
+
+// apiClientOptions returns the options installing the faults in the HyperFleet API client
+func (f *clientFaults) apiClientOptions() []hyperfleetapi.ClientOption {
+	if f == nil {
+		return nil
+	}
+	return []hyperfleetapi.ClientOption{hyperfleetapi.WithTransportWrapper(f.apiTransport)}
+}
+
+// wrapTransportClient returns tc with the faults installed
+func (f *clientFaults) wrapTransportClient(tc transportclient.TransportClient) transportclient.TransportClient {
+	if f == nil {
+		return tc
+	}
+	return f.transportClient(tc)
+}
@@ -0,0 +1,21 @@
+//go:build !faultinject
+
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/health"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// errFaultInjectionNotBuilt is returned for --enable-fault-injection by builds
+// without the faultinject build tag, such as the release images
+var errFaultInjectionNotBuilt = errors.New(
+	"--enable-fault-injection requires an adapter built with the faultinject build tag")
+
+// setUpFaultInjection fails: fault injection is not compiled into this build
+func setUpFaultInjection(context.Context, *health.Server, logger.Logger) (*clientFaults, error) {
+	return nil, errFaultInjectionNotBuilt
+}
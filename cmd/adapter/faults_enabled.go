@@ -0,0 +1,25 @@
+//go:build faultinject
+
+package main
+
+import (
+	"context"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/faultinject"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/health"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// setUpFaultInjection creates the fault injector of --enable-fault-injection,
+// serves its rules on /admin/faults and /statusz and returns the wrappers
+// installing it in the clients
+func setUpFaultInjection(ctx context.Context, healthServer *health.Server, log logger.Logger) (*clientFaults, error) {
+	injector := faultinject.New(faultinject.Config{}, log)
+	healthServer.Handle("/admin/faults", injector.Handler())
+	healthServer.SetFaultsProvider(func() any { return injector.Report() })
+	log.Warn(ctx, "Fault injection enabled at /admin/faults: injected failures affect real executions")
+	return &clientFaults{
+		apiTransport:    injector.RoundTripper,
+		transportClient: injector.WrapTransportClient,
+	}, nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+)
+
+// -----------------------------------------------------------------------------
+// Generate-schema mode
+// -----------------------------------------------------------------------------
+
+// runGenerateSchema writes the JSON Schema of --config-type to --output or stdout
+func runGenerateSchema() error {
+	schema, err := configloader.GenerateJSONSchema(schemaConfigType)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s config schema: %w", schemaConfigType, err)
+	}
+	data = append(data, '\n')
+
+	if schemaOutputPath == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(schemaOutputPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write schema to %q: %w", schemaOutputPath, err)
+	}
+	return nil
+}
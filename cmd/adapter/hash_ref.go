@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+)
+
+// -----------------------------------------------------------------------------
+// Hash-ref mode
+// -----------------------------------------------------------------------------
+
+// runHashRef prints the digest of the file at ref, a path or an http(s) URL, in
+// the form of build_ref_digest and manifest_ref_digest
+func runHashRef(ref string) error {
+	data, err := readRef(ref)
+	if err != nil {
+		return err
+	}
+	fmt.Println(configloader.ComputeDigest(data))
+	return nil
+}
+
+// readRef returns the content of the file at ref, fetched when ref is an http(s) URL
+func readRef(ref string) ([]byte, error) {
+	if !strings.HasPrefix(ref, "http://") && !strings.HasPrefix(ref, "https://") {
+		data, err := os.ReadFile(filepath.Clean(ref))
+		if err != nil {
+			return nil, invalidInput(fmt.Errorf("failed to read %q: %w", ref, err))
+		}
+		return data, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hashRefTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ref, nil)
+	if err != nil {
+		return nil, invalidInput(fmt.Errorf("invalid URL %q: %w", ref, err))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %q: %w", ref, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only body
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("failed to fetch %q: HTTP %s", ref, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", ref, err)
+	}
+	return data, nil
+}
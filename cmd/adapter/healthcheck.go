@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/health"
+)
+
+// -----------------------------------------------------------------------------
+// Healthcheck mode
+// -----------------------------------------------------------------------------
+
+// runHealthcheck probes a health endpoint of the local health server. Returns
+// an error, making the command exit 1, unless the endpoint answers 2xx; the
+// response body of a failed probe is printed to stdout.
+func runHealthcheck() error {
+	if !slices.Contains(health.ProbeEndpoints, healthcheckEndpoint) {
+		return invalidInput(fmt.Errorf("invalid --endpoint %q: must be one of %v",
+			healthcheckEndpoint, health.ProbeEndpoints))
+	}
+
+	opts := health.ProbeOptions{
+		Endpoint:           healthcheckEndpoint,
+		Port:               healthcheckPort,
+		Timeout:            healthcheckTimeout,
+		TLS:                healthcheckTLS,
+		InsecureSkipVerify: healthcheckInsecure,
+	}
+	if healthcheckInsecure && !healthcheckTLS {
+		return invalidInput(fmt.Errorf("--insecure-skip-verify requires --tls"))
+	}
+
+	err := health.Probe(context.Background(), opts)
+	var probeErr *health.ProbeError
+	if errors.As(err, &probeErr) && len(probeErr.Body) > 0 {
+		_, _ = os.Stdout.Write(probeErr.Body) //nolint:errcheck // best-effort diagnostics
+		fmt.Println()
+	}
+	return err
+}
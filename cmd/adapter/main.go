@@ -2,25 +2,32 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
-	"os/signal"
+	"slices"
 	"strings"
-	"syscall"
 	"time"
 
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/audit"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/brokerconsumer"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/dryrun"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/executor"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/featureflags"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/idempotency"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/journal"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/lookup"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/maestroclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/postbuffer"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/selftest"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/health"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
-	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/otel"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/version"
 	"github.com/openshift-hyperfleet/hyperfleet-broker/broker"
 	"github.com/spf13/cobra"
@@ -32,6 +39,7 @@ import (
 var (
 	configPath     string // Path to deployment config (adapter-config.yaml)
 	taskConfigPath string // Path to task config (adapter-task-config.yaml)
+	schemaValidate bool   // Validate the config files against the generated JSON Schemas
 	logLevel       string
 	logFormat      string
 	logOutput      string
@@ -42,6 +50,56 @@ var (
 	dryRunDiscovery    string // Path to mock discovery responses JSON file
 	dryRunVerbose      bool   // Show verbose dry-run output
 	dryRunOutput       string // Output format: text or json
+
+	// Replay flags
+	replayFrom       string // RFC3339 timestamp or snapshot name to seek the subscription to
+	confirmReplay    bool   // Required guard for --replay-from
+	snapshotEndpoint bool   // Expose POST /admin/snapshot on the health server
+
+	// Debug flags
+	enablePprof          bool // Serve pprof and expvar endpoints on the debug port
+	enableFaultInjection bool // Serve /admin/faults and inject its failures (faultinject builds only)
+
+	// Preflight flags
+	preflightMode string // RBAC preflight at startup: warn, enforce or off
+
+	// Run-once flags
+	runOnceEvent       string // Path to the event JSON file
+	runOnceEventType   string // Type of the event wrapping a bare data payload
+	runOnceEventSource string // Source of the event wrapping a bare data payload
+	useDryRunClients   bool   // Use the dry-run mock clients (run-once, replay)
+
+	// Replay command flags
+	replayFile        string   // Path to the JSONL file of events
+	replayConcurrency int      // Number of events executed at once
+	replayRate        string   // Maximum event rate, e.g. 10/s
+	replayFilterTypes []string // Only replay events of these types
+	replayFailFast    bool     // Stop at the first failure
+
+	// Validate-config flags
+	validateOutput string // Output format: text or json
+	validateStrict bool   // Fail on warnings
+
+	// Self-test flags
+	selfTestOutput  string        // Output format: text or json
+	selfTestTimeout time.Duration // Timeout of each check
+
+	// Generate-schema flags
+	schemaConfigType string // Config file the schema is generated for: adapter or task
+	schemaOutputPath string // Path the schema is written to; stdout when empty
+
+	// Print-config flags
+	printConfigOutput string // Output format: yaml or json
+
+	// Healthcheck flags
+	healthcheckEndpoint string        // Health endpoint probed: healthz, readyz or livez
+	healthcheckPort     string        // Port of the health server
+	healthcheckTimeout  time.Duration // Timeout of the probe
+	healthcheckTLS      bool          // Probe over https
+	healthcheckInsecure bool          // Skip verification of the server certificate
+
+	// Hash-ref flags
+	hashRefTimeout time.Duration // Timeout of fetching a URL
 )
 
 // Timeout constants
@@ -50,6 +108,12 @@ const (
 	OTelShutdownTimeout = 5 * time.Second
 	// HealthServerShutdownTimeout is the timeout for gracefully shutting down the health server
 	HealthServerShutdownTimeout = 5 * time.Second
+	// StartupPollInterval is how often readiness is polled until the startup probe latches
+	StartupPollInterval = time.Second
+	// HealthcheckTimeout is the default timeout of the healthcheck command's probe
+	HealthcheckTimeout = 2 * time.Second
+	// HashRefTimeout is the default timeout of the hash-ref command's fetch of a URL
+	HashRefTimeout = 30 * time.Second
 )
 
 // Server port constants
@@ -58,6 +122,23 @@ const (
 	HealthServerPort = "8080"
 	// MetricsServerPort is the port for /metrics endpoint
 	MetricsServerPort = "9090"
+	// DebugServerPort is the port for /debug/pprof/ and /debug/vars endpoints (--enable-pprof)
+	DebugServerPort = "6060"
+)
+
+// LogLevelFileEnv names the env var holding the path of a file the log level is
+// re-read from on SIGHUP. Without it, SIGHUP cycles debug, info, warn, error.
+const LogLevelFileEnv = "LOG_LEVEL_FILE"
+
+// Bearer token env vars of the debug and metrics servers
+const (
+	// DebugTokenEnv names the env var holding the bearer token required by the debug server
+	DebugTokenEnv = "HYPERFLEET_DEBUG_TOKEN"
+	// MetricsTokenEnv names the env var holding the bearer token required by /metrics
+	MetricsTokenEnv = "HYPERFLEET_METRICS_TOKEN"
+	// MetricsTokenFileEnv names the env var holding the path of a file with the bearer
+	// token required by /metrics. It takes precedence over MetricsTokenEnv.
+	MetricsTokenFileEnv = "HYPERFLEET_METRICS_TOKEN_FILE"
 )
 
 func main() {
@@ -72,7 +153,10 @@ and HyperFleet API calls.`,
 		CompletionOptions: cobra.CompletionOptions{
 			DisableDefaultCmd: true,
 		},
+		// --version prints the same output as the version command
+		Version: version.Info().Version,
 	}
+	rootCmd.SetVersionTemplate(version.Info().String())
 
 	// Add flags to root command (so they work on all subcommands)
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
@@ -107,7 +191,7 @@ Dry-run mode:
 	serveCmd.Flags().StringVar(&logFormat, "log-format", "",
 		"Log format (text, json). Env: LOG_FORMAT")
 	serveCmd.Flags().StringVar(&logOutput, "log-output", "",
-		"Log output (stdout, stderr). Env: LOG_OUTPUT")
+		"Log output (stdout, stderr, file, both). Env: LOG_OUTPUT")
 	serveCmd.Flags().StringVar(&dryRunEvent, "dry-run-event", "",
 		"Path to CloudEvent JSON file for dry-run mode")
 	serveCmd.Flags().StringVar(&dryRunAPIResponses, "dry-run-api-responses", "",
@@ -118,6 +202,18 @@ Dry-run mode:
 		"Show rendered manifests, API request/response bodies in dry-run output")
 	serveCmd.Flags().StringVar(&dryRunOutput, "dry-run-output", "text",
 		"Dry-run output format: text or json")
+	serveCmd.Flags().StringVar(&replayFrom, "replay-from", "",
+		"Seek the subscription to an RFC3339 timestamp or snapshot name before receiving (googlepubsub only)")
+	serveCmd.Flags().BoolVar(&confirmReplay, "confirm-replay", false,
+		"Confirm --replay-from; seeking affects every consumer of the subscription")
+	serveCmd.Flags().BoolVar(&snapshotEndpoint, "enable-snapshot-endpoint", false,
+		"Expose POST /admin/snapshot on the health port to snapshot the subscription (googlepubsub only)")
+	serveCmd.Flags().BoolVar(&enablePprof, "enable-pprof", false,
+		"Serve pprof and expvar endpoints on port "+DebugServerPort+" (bearer token from "+DebugTokenEnv+")")
+	serveCmd.Flags().BoolVar(&enableFaultInjection, "enable-fault-injection", false,
+		"Expose /admin/faults on the health port to inject client failures (builds with the faultinject tag only)")
+	serveCmd.Flags().StringVar(&preflightMode, "preflight", selftest.PreflightWarn,
+		"RBAC preflight at startup: warn logs missing permissions, enforce fails the startup, off skips it")
 
 	// Config-dump command: loads config and prints the merged result as YAML, then exits.
 	// Useful for debugging and verifying that config files, env vars, and CLI flags load correctly.
@@ -143,32 +239,293 @@ Priority order (lowest to highest): config file < env vars < CLI flags`,
 	configDumpCmd.Flags().StringVar(&logFormat, "log-format", "",
 		"Log format (text, json). Env: LOG_FORMAT")
 	configDumpCmd.Flags().StringVar(&logOutput, "log-output", "",
-		"Log output (stdout, stderr). Env: LOG_OUTPUT")
+		"Log output (stdout, stderr, file, both). Env: LOG_OUTPUT")
+
+	// Print-config command: prints the effective config with secrets replaced, its hash and sources
+	printConfigCmd := &cobra.Command{
+		Use:   "print-config",
+		Short: "Print the effective adapter configuration with secrets redacted",
+		Long: `Load the adapter configuration like serve does and print the config the
+adapter runs with: file references (build_ref, schema_ref, manifest.ref) are
+inlined, and the Maestro TLS files, credential headers of the HyperFleet API
+client and defaults of sensitive params are replaced by ***.
+
+The YAML output starts with a comment listing the config hash and the source
+files, and loads again as a config. With --output json the config, hash and
+sources are fields of a JSON document. serve logs the same hash at startup
+and exports it as the config_hash label of hyperfleet_adapter_config_info.
+
+Exit codes: 0 printed, 2 invalid config or flags.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPrintConfig(cmd.Flags())
+		},
+	}
+	addConfigPathFlags(printConfigCmd)
+	addOverrideFlags(printConfigCmd)
+	printConfigCmd.Flags().StringVarP(&printConfigOutput, "output", "o", "yaml",
+		"Output format: yaml or json")
+	printConfigCmd.Flags().StringVar(&logLevel, "log-level", "",
+		"Log level (debug, info, warn, error). Env: LOG_LEVEL")
+	printConfigCmd.Flags().StringVar(&logFormat, "log-format", "",
+		"Log format (text, json). Env: LOG_FORMAT")
+
+	// Validate-config command: loads and validates the config like serve does, without
+	// connecting to anything. Meant for CI gating of config changes.
+	validateConfigCmd := &cobra.Command{
+		Use:   "validate-config",
+		Short: "Validate the adapter configuration without starting the adapter",
+		Long: `Load the adapter configuration from config files, environment variables,
+and CLI flags and run the same validation as serve at startup: schema and
+unknown fields, file references (manifest.ref, buildRef, schema_ref), event
+schemas, CEL expressions and template variables. No broker, cluster, or API
+is contacted.
+
+Errors and warnings are printed as text or JSON (--output json).
+Exits with code 0 when the config is valid, 1 when it has errors, or
+warnings with --strict.`,
+		// Findings are the output; usage and the error line would only repeat them
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runValidateConfig(cmd.Flags())
+		},
+	}
+	addConfigPathFlags(validateConfigCmd)
+	addOverrideFlags(validateConfigCmd)
+	validateConfigCmd.Flags().StringVarP(&validateOutput, "output", "o", "text",
+		"Output format: text or json")
+	validateConfigCmd.Flags().BoolVar(&validateStrict, "strict", false,
+		"Treat warnings as errors")
+
+	// Run-once command: processes one event from a file with the serve executor and exits
+	runOnceCmd := &cobra.Command{
+		Use:   "run-once",
+		Short: "Process a single event from a file and exit",
+		Long: `Load the adapter configuration, build the executor with the same clients
+and credentials as serve, process the CloudEvent of --event without a broker,
+and print the execution result as JSON to stdout. Logs go to stderr.
+
+The event file is a structured CloudEvent JSON. A file holding only the
+event data is wrapped in a new event when --type and --source are set.
+Pass --dry-run to use the dry-run mock clients instead of real ones.
+
+Exit codes: 0 success or skipped, 1 failed, 2 invalid config, event, or flags.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRunOnce(cmd.Flags())
+		},
+	}
+	addConfigPathFlags(runOnceCmd)
+	addOverrideFlags(runOnceCmd)
+	runOnceCmd.Flags().StringVar(&logLevel, "log-level", "",
+		"Log level (debug, info, warn, error). Env: LOG_LEVEL")
+	runOnceCmd.Flags().StringVar(&logFormat, "log-format", "",
+		"Log format (text, json). Env: LOG_FORMAT")
+	runOnceCmd.Flags().StringVar(&runOnceEvent, "event", "",
+		"Path to the CloudEvent JSON file to process")
+	runOnceCmd.Flags().StringVar(&runOnceEventType, "type", "",
+		"Event type when --event holds only the event data")
+	runOnceCmd.Flags().StringVar(&runOnceEventSource, "source", "",
+		"Event source when --event holds only the event data")
+	runOnceCmd.Flags().BoolVar(&useDryRunClients, "dry-run", false,
+		"Use mock API and transport clients instead of real ones")
+	runOnceCmd.Flags().StringVar(&dryRunAPIResponses, "dry-run-api-responses", "",
+		"Path to mock API responses JSON file for --dry-run (defaults to 200 OK)")
+	runOnceCmd.Flags().StringVar(&dryRunDiscovery, "dry-run-discovery", "",
+		"Path to mock discovery responses JSON file for --dry-run")
+
+	// Replay command: processes a JSONL file of events with the serve executor and exits
+	replayCmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Process a JSONL file of events and exit",
+		Long: `Load the adapter configuration, build the executor like run-once, and
+process every structured CloudEvent of --file, one JSON event per line,
+with the configured concurrency and rate limit. No broker is used.
+
+One summary line per event is printed as JSON to stdout, in completion
+order; logs and the aggregate (counts by status, p50/p99 duration) go to
+stderr. Failed events do not stop the replay unless --fail-fast is set.
+
+Exit codes: 0 all events succeeded or were skipped, 1 an event failed or
+was invalid, 2 invalid config, file, or flags.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReplay(cmd.Flags())
+		},
+	}
+	addConfigPathFlags(replayCmd)
+	addOverrideFlags(replayCmd)
+	replayCmd.Flags().StringVar(&logLevel, "log-level", "",
+		"Log level (debug, info, warn, error). Env: LOG_LEVEL")
+	replayCmd.Flags().StringVar(&logFormat, "log-format", "",
+		"Log format (text, json). Env: LOG_FORMAT")
+	replayCmd.Flags().StringVarP(&replayFile, "file", "f", "",
+		"Path to the JSONL file of CloudEvents to process")
+	replayCmd.Flags().IntVar(&replayConcurrency, "concurrency", 1,
+		"Number of events processed at once")
+	replayCmd.Flags().StringVar(&replayRate, "rate", "",
+		"Maximum events started per second, minute or hour, e.g. 10/s or 600/m (default unlimited)")
+	replayCmd.Flags().StringSliceVar(&replayFilterTypes, "filter-type", nil,
+		"Only process events of this type (repeatable)")
+	replayCmd.Flags().BoolVar(&replayFailFast, "fail-fast", false,
+		"Stop reading events after the first failed or invalid one")
+	replayCmd.Flags().BoolVar(&useDryRunClients, "dry-run", false,
+		"Use mock API and transport clients instead of real ones")
+	replayCmd.Flags().StringVar(&dryRunAPIResponses, "dry-run-api-responses", "",
+		"Path to mock API responses JSON file for --dry-run (defaults to 200 OK)")
+	replayCmd.Flags().StringVar(&dryRunDiscovery, "dry-run-discovery", "",
+		"Path to mock discovery responses JSON file for --dry-run")
+
+	// Self-test command: checks every dependency with the normal config, for deployment smoke tests
+	selfTestCmd := &cobra.Command{
+		Use:   "self-test",
+		Short: "Check connectivity and permissions of every dependency and exit",
+		Long: `Load the adapter configuration, create the clients like serve, and check:
+
+  hyperfleet_api   an authenticated GET against the HyperFleet API
+  kubernetes       the API server accepts the client's credentials (Kubernetes transport)
+  kubernetes_rbac  the verbs the config's resources need, with SelfSubjectAccessReviews
+  maestro          the Maestro HTTP API is reachable (Maestro transport)
+  broker           every Pub/Sub subscription exists and may be consumed from
+
+Each check is bounded by --timeout. The report is printed as a table with
+remediation hints for failed checks, or as JSON (--output json). /readyz
+runs the same hyperfleet_api, kubernetes and maestro checks.
+
+Exit codes: 0 every check passed or was skipped, 1 a check failed, 2 invalid config or flags.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSelfTest(cmd.Flags())
+		},
+	}
+	addConfigPathFlags(selfTestCmd)
+	addOverrideFlags(selfTestCmd)
+	selfTestCmd.Flags().StringVar(&logLevel, "log-level", "",
+		"Log level (debug, info, warn, error). Env: LOG_LEVEL")
+	selfTestCmd.Flags().StringVar(&logFormat, "log-format", "",
+		"Log format (text, json). Env: LOG_FORMAT")
+	selfTestCmd.Flags().StringVarP(&selfTestOutput, "output", "o", "text",
+		"Output format: text or json")
+	selfTestCmd.Flags().DurationVar(&selfTestTimeout, "timeout", selftest.DefaultTimeout,
+		"Timeout of each check")
+
+	// Generate-schema command: prints the JSON Schema of a config file for editors and CI
+	generateSchemaCmd := &cobra.Command{
+		Use:   "generate-schema",
+		Short: "Print the JSON Schema of the adapter or task config file",
+		Long: `Generate the JSON Schema (draft 2020-12) of the adapter deployment config
+(--config-type adapter) or of the task config (--config-type task) from the
+config structs: properties, required fields, enums and mutually exclusive
+fields such as build and build_ref.
+
+The schema is the one --schema-validate checks config files against, so
+editors and CI validating with it agree with the adapter at startup.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGenerateSchema()
+		},
+	}
+	generateSchemaCmd.Flags().StringVar(&schemaConfigType, "config-type", configloader.ConfigTypeAdapter,
+		"Config file to generate the schema for: adapter or task")
+	generateSchemaCmd.Flags().StringVarP(&schemaOutputPath, "output", "o", "",
+		"Path to write the schema to (default stdout)")
+
+	// Healthcheck command: probes the local health server, for images without curl
+	healthcheckCmd := &cobra.Command{
+		Use:   "healthcheck",
+		Short: "Probe a health endpoint of the running adapter and exit",
+		Long: `Perform a GET of /readyz, /healthz or /livez on the local health server,
+for container HEALTHCHECKs and probes in images without curl or wget:
+
+  HEALTHCHECK CMD ["/app/adapter", "healthcheck", "--endpoint", "livez"]
+
+The response body of a failed probe is printed to stdout. Use --tls for a
+health server behind TLS and --insecure-skip-verify for a self-signed
+certificate.
+
+Exit codes: 0 the endpoint answered 2xx, 1 any other status or no answer, 2 invalid flags.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHealthcheck()
+		},
+	}
+	healthcheckCmd.Flags().StringVar(&healthcheckEndpoint, "endpoint", "readyz",
+		"Health endpoint to probe: readyz, healthz or livez")
+	healthcheckCmd.Flags().StringVar(&healthcheckPort, "port", HealthServerPort,
+		"Port of the health server")
+	healthcheckCmd.Flags().DurationVar(&healthcheckTimeout, "timeout", HealthcheckTimeout,
+		"Timeout of the probe")
+	healthcheckCmd.Flags().BoolVar(&healthcheckTLS, "tls", false,
+		"Probe the health server over https")
+	healthcheckCmd.Flags().BoolVar(&healthcheckInsecure, "insecure-skip-verify", false,
+		"Accept any server certificate, e.g. a self-signed probe certificate (requires --tls)")
+
+	// Hash-ref command: prints the digest pinning a build_ref or manifest_ref file
+	hashRefCmd := &cobra.Command{
+		Use:   "hash-ref <path|url>",
+		Short: "Print the digest of a referenced file for build_ref_digest or manifest_ref_digest",
+		Long: `Compute the sha256 digest of a build_ref or manifest_ref file, read from a
+path or fetched from an http(s) URL, and print it in the form pinned in the task
+config:
+
+  build_ref: payloads/status.yaml
+  build_ref_digest: sha256:<hex>
+
+Config loading fails when a referenced file does not match its digest.
+
+Exit codes: 0 the digest was printed, 1 a URL could not be fetched, 2 an unreadable path.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHashRef(args[0])
+		},
+	}
+	hashRefCmd.Flags().DurationVar(&hashRefTimeout, "timeout", HashRefTimeout,
+		"Timeout of fetching a URL")
 
 	// Version command
 	versionCmd := &cobra.Command{
 		Use:   "version",
 		Short: "Print version information",
 		Run: func(cmd *cobra.Command, args []string) {
-			info := version.Info()
-			fmt.Printf("HyperFleet Adapter\n")
-			fmt.Printf("  Version:    %s\n", info.Version)
-			fmt.Printf("  Commit:     %s\n", info.Commit)
-			fmt.Printf("  Built:      %s\n", info.BuildDate)
+			fmt.Print(version.Info().String())
 		},
 	}
 
 	// Add subcommands
 	rootCmd.AddCommand(serveCmd)
 	rootCmd.AddCommand(configDumpCmd)
+	rootCmd.AddCommand(printConfigCmd)
+	rootCmd.AddCommand(validateConfigCmd)
+	rootCmd.AddCommand(runOnceCmd)
+	rootCmd.AddCommand(replayCmd)
+	rootCmd.AddCommand(selfTestCmd)
+	rootCmd.AddCommand(generateSchemaCmd)
+	rootCmd.AddCommand(healthcheckCmd)
+	rootCmd.AddCommand(hashRefCmd)
 	rootCmd.AddCommand(versionCmd)
 
 	// Execute
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
 
+// exitCodeError makes the adapter exit with code instead of 1
+type exitCodeError struct {
+	err  error
+	code int
+}
+
+func (e *exitCodeError) Error() string { return e.err.Error() }
+
+func (e *exitCodeError) Unwrap() error { return e.err }
+
 // isDryRun returns true when dry-run flags are present.
 func isDryRun() bool {
 	return dryRunEvent != "" || dryRunAPIResponses != ""
@@ -219,6 +576,12 @@ func buildLoggerConfig(component string, logCfg *configloader.LogConfig) logger.
 		cfg.Output = logOutput
 	}
 
+	// The log file is configured in the adapter config, so loggers created
+	// before it is loaded write to stdout
+	if logCfg == nil && (cfg.Output == logger.OutputFile || cfg.Output == logger.OutputBoth) {
+		cfg.Output = "stdout"
+	}
+
 	cfg.Component = component
 	cfg.Version = version.Version
 
@@ -228,12 +591,11 @@ func buildLoggerConfig(component string, logCfg *configloader.LogConfig) logger.
 // loadConfig loads the unified adapter configuration from both config files.
 func loadConfig(ctx context.Context, log logger.Logger, flags *pflag.FlagSet) (*configloader.Config, error) {
 	log.Info(ctx, "Loading adapter configuration...")
-	config, err := configloader.LoadConfig(
-		configloader.WithAdapterConfigPath(configPath),
-		configloader.WithTaskConfigPath(taskConfigPath),
-		configloader.WithAdapterVersion(version.Version),
-		configloader.WithFlags(flags),
-	)
+	warnings := &configloader.ValidationErrors{}
+	config, err := configloader.LoadConfig(configLoadOptions(flags, warnings)...)
+	for _, warning := range warnings.Errors {
+		log.Warnf(ctx, "Config validation warning: %s", warning.Error())
+	}
 	if err != nil {
 		errCtx := logger.WithErrorField(ctx, err)
 		log.Errorf(errCtx, "Failed to load adapter configuration")
@@ -242,12 +604,31 @@ func loadConfig(ctx context.Context, log logger.Logger, flags *pflag.FlagSet) (*
 	return config, nil
 }
 
+// configLoadOptions returns the options every command loads the config with,
+// so validate-config runs exactly the validation of serve
+func configLoadOptions(flags *pflag.FlagSet, warnings *configloader.ValidationErrors) []configloader.LoadOption {
+	opts := []configloader.LoadOption{
+		configloader.WithAdapterConfigPath(configPath),
+		configloader.WithTaskConfigPath(taskConfigPath),
+		configloader.WithAdapterVersion(version.Version),
+		configloader.WithFlags(flags),
+		configloader.WithWarnings(warnings),
+	}
+	if schemaValidate {
+		opts = append(opts, configloader.WithSchemaValidation())
+	}
+	return opts
+}
+
 // -----------------------------------------------------------------------------
 // Client creation (shared between serve and dry-run)
 // -----------------------------------------------------------------------------
 
-// createAPIClient creates a HyperFleet API client from the config
-func createAPIClient(apiConfig configloader.HyperfleetAPIConfig, log logger.Logger) (hyperfleetapi.Client, error) {
+// createAPIClient creates a HyperFleet API client from the config, with extra
+// options applied last
+func createAPIClient(
+	apiConfig configloader.HyperfleetAPIConfig, log logger.Logger, extra ...hyperfleetapi.ClientOption,
+) (hyperfleetapi.Client, error) {
 	var opts []hyperfleetapi.ClientOption
 
 	// Set base URL if configured (env fallback handled in NewClient)
@@ -288,12 +669,18 @@ func createAPIClient(apiConfig configloader.HyperfleetAPIConfig, log logger.Logg
 		opts = append(opts, hyperfleetapi.WithMaxDelay(apiConfig.MaxDelay))
 	}
 
+	opts = append(opts, hyperfleetapi.WithCompressRequests(apiConfig.CompressRequests))
+
 	// Set default headers
 	for key, value := range apiConfig.DefaultHeaders {
 		opts = append(opts, hyperfleetapi.WithDefaultHeader(key, value))
 	}
 
-	return hyperfleetapi.NewClient(log, opts...)
+	if len(apiConfig.Targets) > 0 {
+		opts = append(opts, hyperfleetapi.WithTargets(apiConfig.Targets))
+	}
+
+	return hyperfleetapi.NewClient(log, append(opts, extra...)...)
 }
 
 // createTransportClient creates the appropriate transport client based on config.
@@ -321,6 +708,309 @@ func createTransportClient(
 	return client, nil
 }
 
+// registerReadinessChecks registers the enabled dependency checks run by /readyz
+func registerReadinessChecks(
+	healthServer *health.Server,
+	config *configloader.Config,
+	apiClient hyperfleetapi.Client,
+	tc transportclient.TransportClient,
+) {
+	healthConfig := config.Health
+	healthServer.SetCheckTimings(healthConfig.CheckTimeout, healthConfig.CacheTTL)
+
+	for _, check := range selftest.DependencyChecks(config, apiClient, tc) {
+		if check.Readiness {
+			healthServer.RegisterCheck(check.Name, check.Run)
+		}
+	}
+}
+
+// createReplayer creates a Pub/Sub replayer for the subscription, resolving the
+// project ID from the broker library configuration.
+func createReplayer(
+	ctx context.Context, subscriptionID string, log logger.Logger,
+) (*brokerconsumer.Replayer, func() error, error) {
+	projectID, err := brokerconsumer.BrokerPubSubProjectID()
+	if err != nil {
+		return nil, nil, fmt.Errorf("replay is unavailable: %w", err)
+	}
+	return brokerconsumer.NewPubSubReplayer(ctx, projectID, subscriptionID, log)
+}
+
+// createSizeLimit builds the event size limit config, with a broker publisher
+// for the oversized dead letter topic when one is configured. The returned
+// function closes the publisher.
+func createSizeLimit(
+	ctx context.Context, brokerConfig configloader.BrokerConfig, log logger.Logger, brokerMetrics *broker.MetricsRecorder,
+) (brokerconsumer.SizeLimitConfig, func(), error) {
+	sizeLimit := brokerconsumer.SizeLimitConfig{MaxBytes: brokerConfig.MaxEventBytes}
+	log.Infof(ctx, "Acknowledging events with data over %d bytes without handling", brokerConfig.MaxEventBytes)
+	if brokerConfig.OversizedDeadLetterTopic == "" {
+		return sizeLimit, func() {}, nil
+	}
+	publisher, err := broker.NewPublisher(log, brokerMetrics)
+	if err != nil {
+		return sizeLimit, nil, err
+	}
+	sizeLimit.DeadLetter = publisher
+	sizeLimit.DeadLetterTopic = brokerConfig.OversizedDeadLetterTopic
+	return sizeLimit, func() {
+		if closeErr := publisher.Close(); closeErr != nil {
+			errCtx := logger.WithErrorField(ctx, closeErr)
+			log.Warnf(errCtx, "Failed to close dead letter publisher")
+		}
+	}, nil
+}
+
+// createDedupStore creates the event dedup store selected in the broker config.
+// The configmap store reuses the transport client when it is a Kubernetes client.
+func createDedupStore(
+	ctx context.Context,
+	config *configloader.Config,
+	tc transportclient.TransportClient,
+	log logger.Logger,
+) (brokerconsumer.DedupStore, error) {
+	dedupConfig := config.Clients.Broker.Dedup
+	switch dedupConfig.Store {
+	case configloader.DedupStoreMemory:
+		log.Info(ctx, "Using in-memory event dedup store")
+		return brokerconsumer.NewMemoryDedupStore(dedupConfig.MaxEntries, dedupConfig.TTL), nil
+	case configloader.DedupStoreConfigMap:
+		k8sClient, ok := tc.(k8sclient.K8sClient)
+		if !ok {
+			client, err := createK8sClient(ctx, config.Clients.Kubernetes, log)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+			}
+			k8sClient = client
+		}
+		log.Infof(ctx, "Using ConfigMap %s/%s as event dedup store",
+			dedupConfig.ConfigMapNamespace, dedupConfig.ConfigMapName)
+		return brokerconsumer.NewConfigMapDedupStore(ctx, k8sClient, brokerconsumer.ConfigMapDedupConfig{
+			Namespace:     dedupConfig.ConfigMapNamespace,
+			Name:          dedupConfig.ConfigMapName,
+			MaxEntries:    dedupConfig.MaxEntries,
+			TTL:           dedupConfig.TTL,
+			FlushInterval: dedupConfig.FlushInterval,
+		}, log)
+	default:
+		return nil, fmt.Errorf("unsupported dedup store %q", dedupConfig.Store)
+	}
+}
+
+// createJournalStore creates the execution journal store selected in the
+// adapter config. Returns nil when the journal is disabled.
+func createJournalStore(
+	ctx context.Context,
+	config *configloader.Config,
+	tc transportclient.TransportClient,
+	log logger.Logger,
+) (journal.Store, error) {
+	journalConfig := config.Journal
+	if journalConfig == nil {
+		return nil, nil
+	}
+	limits := journal.Limits{MaxEntries: journalConfig.MaxEntries, MaxEntryBytes: journalConfig.MaxEntryBytes}
+	switch journalConfig.Store {
+	case configloader.JournalStoreFile:
+		log.Infof(ctx, "Using directory %s as execution journal", journalConfig.Path)
+		return journal.NewFileStore(journalConfig.Path, limits, log)
+	case configloader.JournalStoreConfigMap:
+		k8sClient, ok := tc.(k8sclient.K8sClient)
+		if !ok {
+			client, err := createK8sClient(ctx, config.Clients.Kubernetes, log)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+			}
+			k8sClient = client
+		}
+		log.Infof(ctx, "Using ConfigMap %s/%s as execution journal",
+			journalConfig.ConfigMapNamespace, journalConfig.ConfigMapName)
+		return journal.NewConfigMapStore(
+			k8sClient, journalConfig.ConfigMapNamespace, journalConfig.ConfigMapName, limits, log)
+	default:
+		return nil, fmt.Errorf("unsupported journal store %q", journalConfig.Store)
+	}
+}
+
+// createIdempotencyStore creates the store of the processed generations of the
+// idempotency guard. Returns nil, for the executor's in-memory store, when the
+// guard is not configured or uses the memory store.
+func createIdempotencyStore(
+	ctx context.Context,
+	config *configloader.Config,
+	tc transportclient.TransportClient,
+	log logger.Logger,
+) (idempotency.Store, error) {
+	guard := config.Idempotency
+	if guard == nil || guard.EffectiveStore() != configloader.IdempotencyStoreConfigMap {
+		return nil, nil
+	}
+	k8sClient, ok := tc.(k8sclient.K8sClient)
+	if !ok {
+		client, err := createK8sClient(ctx, config.Clients.Kubernetes, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+		}
+		k8sClient = client
+	}
+	log.Infof(ctx, "Using ConfigMap %s/%s as idempotency store", guard.ConfigMapNamespace, guard.ConfigMapName)
+	return idempotency.NewConfigMapStore(k8sClient, guard.ConfigMapNamespace, guard.ConfigMapName, guard.MaxKeys, log)
+}
+
+// createPostBuffer creates the buffer of the post action API calls of
+// buffer_on_failure, in the directory of the adapter config, and starts its
+// replays. Returns nil when the post buffer is not configured.
+func createPostBuffer(
+	ctx context.Context,
+	config *configloader.Config,
+	apiClient hyperfleetapi.Client,
+	recorder *metrics.Recorder,
+	log logger.Logger,
+) (*postbuffer.Buffer, error) {
+	if config.PostBuffer == nil {
+		return nil, nil
+	}
+	// The store holds the calls of every buffering action
+	maxEntries := 0
+	for _, workflow := range config.AllWorkflows() {
+		if workflow.Post == nil {
+			continue
+		}
+		for _, action := range workflow.Post.PostActions {
+			if action.BufferOnFailure == nil || !action.BufferOnFailure.Enabled {
+				continue
+			}
+			if action.BufferOnFailure.MaxEntries > 0 {
+				maxEntries += action.BufferOnFailure.MaxEntries
+			} else {
+				maxEntries += postbuffer.DefaultMaxEntries
+			}
+		}
+	}
+	store, err := journal.NewFileStore(config.PostBuffer.Path, journal.Limits{MaxEntries: maxEntries}, log)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof(ctx, "Using directory %s as post buffer", config.PostBuffer.Path)
+	return postbuffer.New(postbuffer.Config{
+		Store:          store,
+		Client:         apiClient,
+		Recorder:       recorder,
+		ReplayInterval: config.PostBuffer.ReplayInterval,
+	}, log)
+}
+
+// createFeatureFlagStore creates the store of the feature flags ConfigMap of
+// the adapter config. Returns nil when feature flags are not configured.
+func createFeatureFlagStore(
+	ctx context.Context,
+	config *configloader.Config,
+	tc transportclient.TransportClient,
+	log logger.Logger,
+) (*featureflags.Store, error) {
+	if config.FeatureFlags == nil {
+		return nil, nil
+	}
+	source := config.FeatureFlags.Source
+	k8sClient, ok := tc.(k8sclient.K8sClient)
+	if !ok {
+		client, err := createK8sClient(ctx, config.Clients.Kubernetes, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+		}
+		k8sClient = client
+	}
+	log.Infof(ctx, "Reading feature flags from ConfigMap %s/%s", source.ConfigMapNamespace, source.ConfigMapName)
+	return featureflags.NewStore(ctx, k8sClient, featureflags.Config{
+		Namespace:       source.ConfigMapNamespace,
+		Name:            source.ConfigMapName,
+		RefreshInterval: source.RefreshInterval,
+	}, log)
+}
+
+// createLookupTables builds the maps of lookup(), reading the ConfigMap backed
+// ones through tc when it is the Kubernetes or the dry-run transport client,
+// and through a Kubernetes client of their own otherwise
+func createLookupTables(
+	ctx context.Context,
+	config *configloader.Config,
+	tc transportclient.TransportClient,
+	log logger.Logger,
+) (*lookup.Tables, error) {
+	fromConfigMap := slices.ContainsFunc(config.Maps, func(m configloader.LookupMap) bool { return m.FromConfigMap() })
+	if !fromConfigMap {
+		return lookup.New(config.Maps)
+	}
+	var getter lookup.ConfigMapGetter = tc
+	switch tc.(type) {
+	case k8sclient.K8sClient, *dryrun.DryrunTransportClient:
+	default:
+		client, err := createK8sClient(ctx, config.Clients.Kubernetes, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+		}
+		getter = client
+	}
+	log.Infof(ctx, "Reading lookup maps from their ConfigMaps")
+	return lookup.Load(ctx, config.Maps, getter)
+}
+
+// createAuditor creates the auditor writing execution audit records to the
+// sinks of the audit config. Returns nil when auditing is disabled.
+func createAuditor(
+	ctx context.Context,
+	auditConfig *configloader.AuditConfig,
+	log logger.Logger,
+	recorder *metrics.Recorder,
+) (*audit.Auditor, error) {
+	if auditConfig == nil {
+		return nil, nil
+	}
+	var sinks []audit.Sink
+	if auditConfig.File.Path != "" {
+		fileSink, err := audit.NewFileSink(logger.FileConfig{
+			Path:       auditConfig.File.Path,
+			MaxSizeMB:  auditConfig.File.MaxSizeMB,
+			MaxBackups: auditConfig.File.MaxBackups,
+			MaxAgeDays: auditConfig.File.MaxAgeDays,
+			Compress:   auditConfig.File.Compress,
+		})
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, fileSink)
+		log.Infof(ctx, "Writing audit records to %s", auditConfig.File.Path)
+	}
+	if httpConfig := auditConfig.HTTP; httpConfig != nil {
+		httpSink, err := audit.NewHTTPSink(audit.HTTPSinkConfig{
+			URL:           httpConfig.URL,
+			Headers:       httpConfig.Headers,
+			Timeout:       httpConfig.Timeout,
+			BaseDelay:     httpConfig.BaseDelay,
+			RetryAttempts: httpConfig.RetryAttempts,
+		})
+		if err != nil {
+			for _, sink := range sinks {
+				sink.Close() //nolint:errcheck // already failing
+			}
+			return nil, err
+		}
+		sinks = append(sinks, httpSink)
+		log.Infof(ctx, "Posting audit records to %s", httpConfig.URL)
+	}
+	if len(sinks) == 0 {
+		log.Warn(ctx, "Audit is configured without a file path or HTTP URL, audit records are not written")
+		return nil, nil
+	}
+	return audit.New(audit.Config{
+		Logger:    log,
+		Recorder:  recorder,
+		Sinks:     sinks,
+		QueueSize: auditConfig.QueueSize,
+	}), nil
+}
+
 // createK8sClient creates a Kubernetes client from the config
 func createK8sClient(
 	ctx context.Context,
@@ -385,269 +1075,39 @@ func buildExecutor(
 	tc transportclient.TransportClient,
 	log logger.Logger,
 	recorder *metrics.Recorder,
+	heartbeat *health.Heartbeat,
+	history *health.ExecutionHistory,
+	auditor *audit.Auditor,
+	webhookClient executor.WebhookClient,
+	journalStore journal.Store,
+	flags *featureflags.Store,
+	postBuffer *postbuffer.Buffer,
+	generations idempotency.Store,
+	tables *lookup.Tables,
 ) (*executor.Executor, error) {
+	configHash, err := config.Hash()
+	if err != nil {
+		return nil, err
+	}
 	return executor.NewBuilder().
 		WithConfig(config).
 		WithAPIClient(apiClient).
 		WithTransportClient(tc).
 		WithLogger(log).
 		WithMetricsRecorder(recorder).
+		WithHeartbeat(heartbeat).
+		WithExecutionHistory(history).
+		WithAuditor(auditor).
+		WithWebhookClient(webhookClient).
+		WithJournal(journalStore).
+		WithFeatureFlags(flags).
+		WithPostBuffer(postBuffer).
+		WithIdempotencyStore(generations).
+		WithLookupTables(tables).
+		WithRuntimeMetadata(executor.RuntimeMetadataFromEnv(configHash)).
 		Build()
 }
 
-// -----------------------------------------------------------------------------
-// Serve mode (normal operation)
-// -----------------------------------------------------------------------------
-
-// runServe contains the main application logic for the serve command
-func runServe(flags *pflag.FlagSet) error {
-	// Create context that cancels on system signals
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Create bootstrap logger (before config is loaded)
-	log, err := logger.NewLogger(buildLoggerConfig("hyperfleet-adapter", nil))
-	if err != nil {
-		return fmt.Errorf("failed to create logger: %w", err)
-	}
-
-	log.Infof(ctx, "Starting Hyperfleet Adapter version=%s commit=%s built=%s",
-		version.Version, version.Commit, version.BuildDate)
-
-	// Load unified configuration (deployment + task configs)
-	config, err := loadConfig(ctx, log, flags)
-	if err != nil {
-		return err
-	}
-
-	// Recreate logger with component name and log settings from config
-	log, err = logger.NewLogger(buildLoggerConfig(config.Adapter.Name, &config.Log))
-	if err != nil {
-		return fmt.Errorf("failed to create logger with adapter config: %w", err)
-	}
-
-	log.Infof(ctx, "Adapter configuration loaded successfully: name=%s ", config.Adapter.Name)
-	log.Infof(ctx, "HyperFleet API client configured: timeout=%s retry_attempts=%d",
-		config.Clients.HyperfleetAPI.Timeout.String(), config.Clients.HyperfleetAPI.RetryAttempts)
-	var redactedConfigBytes []byte
-	if config.DebugConfig {
-		var data []byte
-		data, err = yaml.Marshal(config.Redacted())
-		if err != nil {
-			errCtx := logger.WithErrorField(ctx, err)
-			log.Warnf(errCtx, "Failed to marshal adapter configuration for logging")
-		} else {
-			redactedConfigBytes = data
-			log.Infof(ctx, "Loaded adapter configuration:\n%s", string(redactedConfigBytes))
-		}
-	}
-
-	// Initialize OpenTelemetry
-	sampleRatio := otel.GetTraceSampleRatio(log, ctx)
-	tp, err := otel.InitTracer(config.Adapter.Name, version.Version, sampleRatio)
-	if err != nil {
-		errCtx := logger.WithErrorField(ctx, err)
-		log.Errorf(errCtx, "Failed to initialize OpenTelemetry")
-		return fmt.Errorf("failed to initialize OpenTelemetry: %w", err)
-	}
-	defer func() {
-		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), OTelShutdownTimeout)
-		defer shutdownCancel()
-		if shutdownErr := tp.Shutdown(shutdownCtx); shutdownErr != nil {
-			errCtx := logger.WithErrorField(shutdownCtx, shutdownErr)
-			log.Warnf(errCtx, "Failed to shutdown TracerProvider")
-		}
-	}()
-
-	// Start health server
-	healthServer := health.NewServer(log, HealthServerPort, config.Adapter.Name)
-	err = healthServer.Start(ctx)
-	if err != nil {
-		errCtx := logger.WithErrorField(ctx, err)
-		log.Errorf(errCtx, "Failed to start health server")
-		return fmt.Errorf("failed to start health server: %w", err)
-	}
-	healthServer.SetConfigLoaded()
-	if len(redactedConfigBytes) > 0 {
-		healthServer.SetConfig(redactedConfigBytes)
-	}
-	defer func() {
-		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), HealthServerShutdownTimeout)
-		defer shutdownCancel()
-		if shutdownErr := healthServer.Shutdown(shutdownCtx); shutdownErr != nil {
-			errCtx := logger.WithErrorField(shutdownCtx, shutdownErr)
-			log.Warnf(errCtx, "Failed to shutdown health server")
-		}
-	}()
-
-	// Start metrics server
-	metricsServer := health.NewMetricsServer(log, MetricsServerPort, health.MetricsConfig{
-		Component: config.Adapter.Name,
-		Version:   version.Version,
-		Commit:    version.Commit,
-	})
-	err = metricsServer.Start(ctx)
-	if err != nil {
-		errCtx := logger.WithErrorField(ctx, err)
-		log.Errorf(errCtx, "Failed to start metrics server")
-		return fmt.Errorf("failed to start metrics server: %w", err)
-	}
-	defer func() {
-		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), HealthServerShutdownTimeout)
-		defer shutdownCancel()
-		if shutdownErr := metricsServer.Shutdown(shutdownCtx); shutdownErr != nil {
-			errCtx := logger.WithErrorField(shutdownCtx, shutdownErr)
-			log.Warnf(errCtx, "Failed to shutdown metrics server")
-		}
-	}()
-
-	// Create adapter metrics recorder
-	metricsRecorder := metrics.NewRecorder(config.Adapter.Name, version.Version, nil)
-
-	// Create real clients
-	log.Info(ctx, "Creating HyperFleet API client...")
-	apiClient, err := createAPIClient(config.Clients.HyperfleetAPI, log)
-	if err != nil {
-		errCtx := logger.WithErrorField(ctx, err)
-		log.Errorf(errCtx, "Failed to create HyperFleet API client")
-		return fmt.Errorf("failed to create HyperFleet API client: %w", err)
-	}
-
-	tc, err := createTransportClient(ctx, config, log)
-	if err != nil {
-		errCtx := logger.WithErrorField(ctx, err)
-		log.Errorf(errCtx, "Failed to create transport client")
-		return err
-	}
-
-	// Build executor
-	log.Info(ctx, "Creating event executor...")
-	exec, err := buildExecutor(config, apiClient, tc, log, metricsRecorder)
-	if err != nil {
-		errCtx := logger.WithErrorField(ctx, err)
-		log.Errorf(errCtx, "Failed to create executor")
-		return fmt.Errorf("failed to create executor: %w", err)
-	}
-
-	// Create the event handler and subscribe to broker
-	handler := exec.CreateHandler()
-
-	// Handle signals for graceful shutdown
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		sig := <-sigCh
-		log.Infof(ctx, "Received signal %s, initiating graceful shutdown...", sig)
-		log.Info(ctx, "Shutdown initiated, marking not ready")
-		healthServer.SetShuttingDown(true)
-		cancel()
-
-		// Second signal forces immediate exit
-		sig = <-sigCh
-		log.Infof(ctx, "Received second signal %s, forcing immediate exit", sig)
-		os.Exit(1)
-	}()
-
-	// Get broker config
-	subscriptionID := config.Clients.Broker.SubscriptionID
-	if subscriptionID == "" {
-		err = fmt.Errorf("clients.broker.subscription_id is required")
-		errCtx := logger.WithErrorField(ctx, err)
-		log.Errorf(errCtx, "Missing required broker configuration")
-		return err
-	}
-
-	topic := config.Clients.Broker.Topic
-	if topic == "" {
-		err = fmt.Errorf("clients.broker.topic is required")
-		errCtx := logger.WithErrorField(ctx, err)
-		log.Errorf(errCtx, "Missing required broker configuration")
-		return err
-	}
-
-	// Create broker metrics recorder
-	brokerMetrics := broker.NewMetricsRecorder(config.Adapter.Name, version.Version, nil)
-
-	// Create broker subscriber and subscribe
-	log.Info(ctx, "Creating broker subscriber...")
-	subscriber, err := broker.NewSubscriber(log, subscriptionID, brokerMetrics)
-	if err != nil {
-		errCtx := logger.WithErrorField(ctx, err)
-		log.Errorf(errCtx, "Failed to create subscriber")
-		return fmt.Errorf("failed to create subscriber: %w", err)
-	}
-	log.Info(ctx, "Broker subscriber created successfully")
-
-	log.Info(ctx, "Subscribing to broker topic...")
-	err = subscriber.Subscribe(ctx, topic, handler)
-	if err != nil {
-		errCtx := logger.WithErrorField(ctx, err)
-		log.Errorf(errCtx, "Failed to subscribe to topic")
-		return fmt.Errorf("failed to subscribe to topic: %w", err)
-	}
-	log.Info(ctx, "Successfully subscribed to broker topic")
-
-	// Mark as ready
-	healthServer.SetBrokerReady(true)
-	log.Info(ctx, "Adapter is ready to process events")
-
-	// Monitor subscription errors
-	fatalErrCh := make(chan error, 1)
-	go func() {
-		for subErr := range subscriber.Errors() {
-			errCtx := logger.WithErrorField(ctx, subErr)
-			log.Errorf(errCtx, "Subscription error")
-			select {
-			case fatalErrCh <- subErr:
-			default:
-			}
-		}
-	}()
-
-	log.Info(ctx, "Adapter started, waiting for events...")
-
-	// Wait for shutdown signal or fatal subscription error
-	select {
-	case <-ctx.Done():
-		log.Info(ctx, "Context canceled, shutting down...")
-	case err := <-fatalErrCh:
-		errCtx := logger.WithErrorField(ctx, err)
-		log.Errorf(errCtx, "Fatal subscription error, shutting down")
-		healthServer.SetShuttingDown(true)
-		cancel()
-	}
-
-	// Close subscriber gracefully
-	log.Info(ctx, "Closing broker subscriber...")
-	shutdownCtx, shutdownCancel := context.WithTimeout(
-		context.Background(), 30*time.Second,
-	)
-	defer shutdownCancel()
-
-	closeDone := make(chan error, 1)
-	go func() {
-		closeDone <- subscriber.Close()
-	}()
-
-	select {
-	case err := <-closeDone:
-		if err != nil {
-			errCtx := logger.WithErrorField(ctx, err)
-			log.Errorf(errCtx, "Error closing subscriber")
-		} else {
-			log.Info(ctx, "Subscriber closed successfully")
-		}
-	case <-shutdownCtx.Done():
-		err := fmt.Errorf("subscriber close timed out after 30 seconds")
-		errCtx := logger.WithErrorField(ctx, err)
-		log.Error(errCtx, "Subscriber close timed out")
-	}
-
-	log.Info(ctx, "Adapter shutdown complete")
-
-	return nil
-}
-
 // -----------------------------------------------------------------------------
 // Dry-run mode
 // -----------------------------------------------------------------------------
@@ -694,6 +1154,7 @@ func runDryRun(flags *pflag.FlagSet) error {
 	if err != nil {
 		return fmt.Errorf("failed to create dryrun API client: %w", err)
 	}
+	dryrunAPI.Targets = config.Clients.HyperfleetAPI.Targets
 
 	// Create recording transport client
 	var dryrunClient *dryrun.DryrunTransportClient
@@ -709,13 +1170,19 @@ func runDryRun(flags *pflag.FlagSet) error {
 	}
 
 	// Build executor with mock clients (same builder as serve, no metrics in dry-run)
-	exec, err := buildExecutor(config, dryrunAPI, dryrunClient, log, nil)
+	dryrunWebhooks := dryrun.NewDryrunWebhookClient()
+	tables, err := createLookupTables(ctx, config, dryrunClient, log)
+	if err != nil {
+		return fmt.Errorf("failed to load lookup maps: %w", err)
+	}
+	exec, err := buildExecutor(
+		config, dryrunAPI, dryrunClient, log, nil, nil, nil, nil, dryrunWebhooks, nil, nil, nil, nil, tables)
 	if err != nil {
 		return fmt.Errorf("failed to create executor: %w", err)
 	}
 
 	// Execute with event data
-	result := exec.Execute(ctx, evt.Data())
+	result := exec.ExecuteEvent(ctx, evt)
 
 	// Build and output execution trace
 	trace := &dryrun.ExecutionTrace{
@@ -724,6 +1191,7 @@ func runDryRun(flags *pflag.FlagSet) error {
 		Result:    result,
 		APIClient: dryrunAPI,
 		Transport: dryrunClient,
+		Webhooks:  dryrunWebhooks,
 		Verbose:   dryRunVerbose,
 	}
 
@@ -785,6 +1253,8 @@ func addConfigPathFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&taskConfigPath, "task-config", "t", "",
 		fmt.Sprintf("Path to adapter task config file (can also use %s env var)",
 			configloader.EnvTaskConfigPath))
+	cmd.Flags().BoolVar(&schemaValidate, "schema-validate", false,
+		"Also validate the config files against the JSON Schemas of generate-schema")
 }
 
 // addOverrideFlags registers all configuration override flags (Maestro, API, broker, Kubernetes).
@@ -833,6 +1303,12 @@ func addOverrideFlags(cmd *cobra.Command) {
 	// Broker override flags
 	cmd.Flags().String("broker-subscription-id", "", "Broker subscription ID. Env: HYPERFLEET_BROKER_SUBSCRIPTION_ID")
 	cmd.Flags().String("broker-topic", "", "Broker topic. Env: HYPERFLEET_BROKER_TOPIC")
+	cmd.Flags().Float64("broker-rate-limit", 0,
+		"Maximum events handled per second (0 = unlimited). Env: HYPERFLEET_BROKER_RATE_LIMIT_EVENTS_PER_SECOND")
+	cmd.Flags().Int("broker-rate-limit-burst", 0,
+		"Rate limiter burst size (0 = events per second). Env: HYPERFLEET_BROKER_RATE_LIMIT_BURST")
+	cmd.Flags().String("broker-start-failure-policy", "",
+		"Subscription start failure policy (fatal, degraded). Env: HYPERFLEET_BROKER_START_FAILURE_POLICY")
 
 	// Kubernetes override flags
 	cmd.Flags().String("kubernetes-kube-config-path", "",
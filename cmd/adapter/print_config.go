@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// -----------------------------------------------------------------------------
+// Print-config mode
+// -----------------------------------------------------------------------------
+
+// effectiveConfigJSON is the --output json document of print-config
+type effectiveConfigJSON struct {
+	Config     interface{} `json:"config"`
+	ConfigHash string      `json:"config_hash"`
+	Sources    []string    `json:"sources"`
+}
+
+// runPrintConfig loads the configuration and prints the effective config with
+// its hash and source files: YAML with a header comment, or a JSON document.
+func runPrintConfig(flags *pflag.FlagSet) error {
+	ctx := context.Background()
+
+	if printConfigOutput != "yaml" && printConfigOutput != "json" {
+		return invalidInput(fmt.Errorf("invalid --output %q: must be 'yaml' or 'json'", printConfigOutput))
+	}
+
+	logCfg := buildLoggerConfig("print-config", nil)
+	logCfg.Output = "stderr"
+	log, err := logger.NewLogger(logCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+	config, err := loadConfig(ctx, log, flags)
+	if err != nil {
+		return invalidInput(err)
+	}
+
+	return writeEffectiveConfig(os.Stdout, config, printConfigOutput)
+}
+
+// writeEffectiveConfig writes the effective config of config to w in format yaml or json
+func writeEffectiveConfig(w io.Writer, config *configloader.Config, format string) error {
+	hash, err := config.Hash()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(config.Effective())
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if format == "json" {
+		// Convert through YAML so the JSON keys are the config file keys
+		var document interface{}
+		if err = yaml.Unmarshal(data, &document); err != nil {
+			return fmt.Errorf("failed to convert config to JSON: %w", err)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(effectiveConfigJSON{Config: document, ConfigHash: hash, Sources: config.Sources})
+	}
+
+	header := fmt.Sprintf("# config-hash: sha256:%s\n# sources:\n", hash)
+	for _, source := range config.Sources {
+		header += fmt.Sprintf("#   - %s\n", source)
+	}
+	if _, err = io.WriteString(w, header); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
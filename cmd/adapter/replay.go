@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/replay"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/otel"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/version"
+	"github.com/spf13/pflag"
+)
+
+// -----------------------------------------------------------------------------
+// Replay mode
+// -----------------------------------------------------------------------------
+
+// runReplay processes the events of --file with the executor of run-once,
+// printing one summary line per event to stdout and the aggregate to stderr
+func runReplay(flags *pflag.FlagSet) error {
+	// SIGINT and SIGTERM stop reading; events already started complete
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	logCfg := buildLoggerConfig("replay", nil)
+	logCfg.Output = "stderr"
+	log, err := logger.NewLogger(logCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+
+	if replayFile == "" {
+		return invalidInput(fmt.Errorf("--file is required"))
+	}
+	rate, err := replay.ParseRate(replayRate)
+	if err != nil {
+		return invalidInput(err)
+	}
+	config, err := loadConfig(ctx, log, flags)
+	if err != nil {
+		return invalidInput(err)
+	}
+	file, err := os.Open(filepath.Clean(replayFile))
+	if err != nil {
+		return invalidInput(fmt.Errorf("failed to open events file: %w", err))
+	}
+	defer file.Close() //nolint:errcheck // read-only
+
+	logCfg = buildLoggerConfig(config.Adapter.Name, &config.Log)
+	logCfg.Output = "stderr"
+	log, err = logger.NewLogger(logCfg)
+	if err != nil {
+		return invalidInput(fmt.Errorf("failed to create logger with adapter config: %w", err))
+	}
+
+	shutdownTracing, err := otel.Setup(ctx, log, config.Adapter.Name, version.Version)
+	if err != nil {
+		return fmt.Errorf("failed to initialize OpenTelemetry: %w", err)
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), OTelShutdownTimeout)
+		defer shutdownCancel()
+		_ = shutdownTracing(shutdownCtx) //nolint:errcheck // best-effort before exit
+	}()
+
+	exec, err := buildOfflineExecutor(ctx, config, log)
+	if err != nil {
+		return err
+	}
+
+	report, err := replay.Run(ctx, file, os.Stdout, exec, replay.Options{
+		FilterTypes: replayFilterTypes,
+		Concurrency: replayConcurrency,
+		Rate:        rate,
+		FailFast:    replayFailFast,
+	})
+	if report != nil {
+		_, _ = fmt.Fprintln(os.Stderr, report.String()) //nolint:errcheck // stderr
+	}
+	if err != nil {
+		return err
+	}
+	if failures := report.Failures(); failures > 0 {
+		return &exitCodeError{err: fmt.Errorf("%d event(s) failed or were invalid", failures), code: ExitCodeFailed}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/dryrun"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/executor"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/otel"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/version"
+	"github.com/spf13/pflag"
+)
+
+// -----------------------------------------------------------------------------
+// Run-once mode
+// -----------------------------------------------------------------------------
+
+// Exit codes of run-once and replay besides 0 for success
+const (
+	// ExitCodeFailed is returned when an execution failed, or clients could not be created
+	ExitCodeFailed = 1
+	// ExitCodeInvalidInput is returned when the config, the event file or the flags are invalid
+	ExitCodeInvalidInput = 2
+)
+
+func invalidInput(err error) error {
+	return &exitCodeError{err: err, code: ExitCodeInvalidInput}
+}
+
+// runRunOnce executes the event of --event with the executor serve would build,
+// bypassing the broker, and prints the ExecutionResult as JSON to stdout.
+// Logs go to stderr.
+func runRunOnce(flags *pflag.FlagSet) error {
+	ctx := context.Background()
+
+	logCfg := buildLoggerConfig("run-once", nil)
+	logCfg.Output = "stderr"
+	log, err := logger.NewLogger(logCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+
+	if runOnceEvent == "" {
+		return invalidInput(fmt.Errorf("--event is required"))
+	}
+	config, err := loadConfig(ctx, log, flags)
+	if err != nil {
+		return invalidInput(err)
+	}
+	evt, err := dryrun.LoadCloudEventOrData(runOnceEvent, runOnceEventType, runOnceEventSource)
+	if err != nil {
+		return invalidInput(fmt.Errorf("failed to load event: %w", err))
+	}
+
+	logCfg = buildLoggerConfig(config.Adapter.Name, &config.Log)
+	logCfg.Output = "stderr"
+	log, err = logger.NewLogger(logCfg)
+	if err != nil {
+		return invalidInput(fmt.Errorf("failed to create logger with adapter config: %w", err))
+	}
+
+	shutdownTracing, err := otel.Setup(ctx, log, config.Adapter.Name, version.Version)
+	if err != nil {
+		return fmt.Errorf("failed to initialize OpenTelemetry: %w", err)
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), OTelShutdownTimeout)
+		defer shutdownCancel()
+		_ = shutdownTracing(shutdownCtx) //nolint:errcheck // best-effort before exit
+	}()
+
+	exec, err := buildOfflineExecutor(ctx, config, log)
+	if err != nil {
+		return err
+	}
+	result := exec.ExecuteEvent(ctx, evt)
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal execution result: %w", err)
+	}
+	// Sensitive params and registered tokens are redacted like in logs
+	fmt.Println(logger.DefaultRedactor().Redact(string(data)))
+
+	if result.Status == executor.StatusFailed {
+		return &exitCodeError{
+			err:  fmt.Errorf("event %s failed in phase %s", evt.ID(), result.CurrentPhase),
+			code: ExitCodeFailed,
+		}
+	}
+	return nil
+}
+
+// buildOfflineExecutor builds the executor of run-once and replay: with the
+// clients of serve, or the dry-run mock clients with --dry-run, and without
+// metrics or health reporting
+func buildOfflineExecutor(
+	ctx context.Context, config *configloader.Config, log logger.Logger,
+) (*executor.Executor, error) {
+	var apiClient hyperfleetapi.Client
+	var tc transportclient.TransportClient
+	var webhookClient executor.WebhookClient
+	var err error
+	if useDryRunClients {
+		apiClient, tc, err = createDryRunClients(config.Clients.HyperfleetAPI.Targets)
+		if err != nil {
+			return nil, invalidInput(err)
+		}
+		webhookClient = dryrun.NewDryrunWebhookClient()
+	} else {
+		apiClient, err = createAPIClient(config.Clients.HyperfleetAPI, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HyperFleet API client: %w", err)
+		}
+		tc, err = createTransportClient(ctx, config, log)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tables, err := createLookupTables(ctx, config, tc, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lookup maps: %w", err)
+	}
+	exec, err := buildExecutor(config, apiClient, tc, log, nil, nil, nil, nil, webhookClient, nil, nil, nil, nil, tables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create executor: %w", err)
+	}
+	return exec, nil
+}
+
+// createDryRunClients creates the mock clients of --dry-run from the
+// --dry-run-api-responses and --dry-run-discovery files
+func createDryRunClients(
+	targets []hyperfleetapi.TargetConfig,
+) (hyperfleetapi.Client, transportclient.TransportClient, error) {
+	var responses *dryrun.DryrunResponsesFile
+	if dryRunAPIResponses != "" {
+		var err error
+		responses, err = dryrun.LoadDryrunResponses(dryRunAPIResponses)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dryrun responses: %w", err)
+		}
+	}
+	apiClient, err := dryrun.NewDryrunAPIClient(responses)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create dryrun API client: %w", err)
+	}
+	apiClient.Targets = targets
+	if dryRunDiscovery == "" {
+		return apiClient, dryrun.NewDryrunTransportClient(), nil
+	}
+	overrides, err := dryrun.LoadDiscoveryOverrides(dryRunDiscovery)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load discovery overrides: %w", err)
+	}
+	return apiClient, dryrun.NewDryrunTransportClientWithOverrides(overrides), nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/selftest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/spf13/pflag"
+)
+
+// -----------------------------------------------------------------------------
+// Self-test mode
+// -----------------------------------------------------------------------------
+
+// runSelfTest checks the connectivity and permissions of every dependency of
+// the config and prints a report. Returns an error, making the command exit
+// 1, when a check fails.
+func runSelfTest(flags *pflag.FlagSet) error {
+	ctx := context.Background()
+
+	if selfTestOutput != "text" && selfTestOutput != "json" {
+		return invalidInput(fmt.Errorf("invalid --output %q: must be 'text' or 'json'", selfTestOutput))
+	}
+
+	logCfg := buildLoggerConfig("self-test", nil)
+	logCfg.Output = "stderr"
+	log, err := logger.NewLogger(logCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+	config, err := loadConfig(ctx, log, flags)
+	if err != nil {
+		return invalidInput(err)
+	}
+
+	apiClient, err := createAPIClient(config.Clients.HyperfleetAPI, log)
+	if err != nil {
+		return fmt.Errorf("failed to create HyperFleet API client: %w", err)
+	}
+	tc, err := createTransportClient(ctx, config, log)
+	if err != nil {
+		return err
+	}
+
+	checks := selftest.DependencyChecks(config, apiClient, tc)
+	checks = append(checks, selftest.RBACCheck(config, tc, log))
+	brokerCheck, closeBroker, err := selftest.BrokerCheck(ctx, config)
+	if err != nil {
+		brokerCheck.Run = func(context.Context) error { return err }
+	}
+	defer func() {
+		_ = closeBroker() //nolint:errcheck // best-effort before exit
+	}()
+	checks = append(checks, brokerCheck)
+
+	report := selftest.Run(ctx, checks, selfTestTimeout)
+	if selfTestOutput == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !report.Passed {
+		return fmt.Errorf("self-test failed")
+	}
+	return nil
+}
@@ -0,0 +1,731 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/bootstrap"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/brokerconsumer"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/executor"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/featureflags"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/httpx"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/postbuffer"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/selftest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/webhook"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/health"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/otel"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/version"
+	"github.com/openshift-hyperfleet/hyperfleet-broker/broker"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// -----------------------------------------------------------------------------
+// Serve mode (normal operation)
+// -----------------------------------------------------------------------------
+
+// runServe contains the main application logic for the serve command
+func runServe(flags *pflag.FlagSet) error {
+	// Create context that cancels on system signals
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create bootstrap logger (before config is loaded)
+	log, err := logger.NewLogger(buildLoggerConfig("hyperfleet-adapter", nil))
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+
+	buildInfo := version.Info()
+	log.Infof(ctx, "Starting Hyperfleet Adapter version=%s commit=%s built=%s",
+		buildInfo.Version, buildInfo.Commit, buildInfo.BuildDate)
+
+	// Validate replay flags before doing any work
+	var replayTarget *brokerconsumer.ReplayTarget
+	if replayFrom != "" {
+		target, parseErr := brokerconsumer.ParseReplayFrom(replayFrom, time.Now())
+		if parseErr != nil {
+			return fmt.Errorf("invalid --replay-from: %w", parseErr)
+		}
+		if !confirmReplay {
+			return brokerconsumer.ErrReplayNotConfirmed
+		}
+		replayTarget = &target
+	}
+	if !slices.Contains(selftest.ValidPreflightModes, preflightMode) {
+		return fmt.Errorf("invalid --preflight %q: must be one of %s",
+			preflightMode, strings.Join(selftest.ValidPreflightModes, ", "))
+	}
+
+	// Readiness is only reported once every startup step succeeded; the health
+	// server is started after the config step, before Complete can be reached
+	var healthServer *health.Server
+	startup := bootstrap.NewSequence(bootstrap.Config{
+		Steps:    bootstrap.ServeSteps,
+		SetReady: func(ready bool) { healthServer.SetStartupComplete(ready) },
+	}, log)
+
+	// Load unified configuration (deployment + task configs)
+	var config *configloader.Config
+	if err = startup.Run(ctx, bootstrap.StepConfig, func(ctx context.Context) error {
+		config, err = loadConfig(ctx, log, flags)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	// Recreate logger with component name and log settings from config. Its
+	// level can be changed at runtime with SIGHUP or /admin/loglevel.
+	logCfg := buildLoggerConfig(config.Adapter.Name, &config.Log)
+	logLevels := logger.NewLevelController(logCfg.Level)
+	logCfg.Levels = logLevels
+	if logCfg.Output == logger.OutputFile || logCfg.Output == logger.OutputBoth {
+		logCfg.File, err = logger.OpenRotatingFile(logger.FileConfig{
+			Path:       config.Log.File.Path,
+			MaxSizeMB:  config.Log.File.MaxSizeMB,
+			MaxBackups: config.Log.File.MaxBackups,
+			MaxAgeDays: config.Log.File.MaxAgeDays,
+			Compress:   config.Log.File.Compress,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		defer logCfg.File.Close() //nolint:errcheck // best-effort on shutdown
+	}
+	log, err = logger.NewLogger(logCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create logger with adapter config: %w", err)
+	}
+	startup.SetLogger(log)
+
+	configHash, err := config.Hash()
+	if err != nil {
+		return err
+	}
+	log.Infof(ctx, "Adapter configuration loaded successfully: name=%s config_hash=%s",
+		config.Adapter.Name, configHash)
+	log.Infof(ctx, "HyperFleet API client configured: timeout=%s retry_attempts=%d",
+		config.Clients.HyperfleetAPI.Timeout.String(), config.Clients.HyperfleetAPI.RetryAttempts)
+	var redactedConfigBytes []byte
+	if config.DebugConfig {
+		var data []byte
+		data, err = yaml.Marshal(config.Redacted())
+		if err != nil {
+			errCtx := logger.WithErrorField(ctx, err)
+			log.Warnf(errCtx, "Failed to marshal adapter configuration for logging")
+		} else {
+			redactedConfigBytes = data
+			log.Infof(ctx, "Loaded adapter configuration:\n%s", string(redactedConfigBytes))
+		}
+	}
+
+	// Initialize OpenTelemetry: OTLP export when configured by the standard env vars, no-op otherwise
+	shutdownTracing, err := otel.Setup(ctx, log, config.Adapter.Name, version.Version)
+	if err != nil {
+		errCtx := logger.WithErrorField(ctx, err)
+		log.Errorf(errCtx, "Failed to initialize OpenTelemetry")
+		return fmt.Errorf("failed to initialize OpenTelemetry: %w", err)
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), OTelShutdownTimeout)
+		defer shutdownCancel()
+		if shutdownErr := shutdownTracing(shutdownCtx); shutdownErr != nil {
+			errCtx := logger.WithErrorField(shutdownCtx, shutdownErr)
+			log.Warnf(errCtx, "Failed to shutdown TracerProvider")
+		}
+	}()
+
+	servers, shutdownServers, err := startServers(ctx, config, log, buildInfo)
+	defer shutdownServers()
+	if err != nil {
+		return err
+	}
+	healthServer, debugServer := servers.health, servers.debug
+	var faults *clientFaults
+	if enableFaultInjection {
+		if faults, err = setUpFaultInjection(ctx, healthServer, log); err != nil {
+			return err
+		}
+	}
+	healthServer.SetStartupReportProvider(func() any { return startup.Report() })
+	healthServer.Handle("/admin/loglevel", logLevels.Handler())
+	if len(redactedConfigBytes) > 0 {
+		healthServer.SetConfig(redactedConfigBytes)
+	}
+
+	// Create adapter metrics recorder
+	metricsRecorder := metrics.NewRecorder(config.Adapter.Name, version.Version, nil)
+	metricsRecorder.SetConfigInfo(configHash)
+	logLevels.OnChange(func(level string) {
+		metricsRecorder.SetLogLevel(level)
+		log.Infof(ctx, "Log level set to %s", level)
+	})
+
+	// The HyperFleet API client, its targets and the webhook client share the
+	// connections of one transport pool
+	transportPool := httpx.NewPool(config.Clients.HyperfleetAPI.Transport, metricsRecorder)
+
+	var apiClient hyperfleetapi.Client
+	var tc transportclient.TransportClient
+	if err = startup.RunRetryable(ctx, bootstrap.StepClients, func(ctx context.Context) error {
+		apiClient, tc, err = createClients(ctx, config, log, faults, transportPool)
+		return err
+	}); err != nil {
+		return err
+	}
+	// RBAC is often granted by the same rollout, so a denial is retried too
+	if err = startup.RunRetryable(ctx, bootstrap.StepPreflight, func(ctx context.Context) error {
+		return selftest.Preflight(ctx, config, tc, preflightMode, log)
+	}); err != nil {
+		errCtx := logger.WithErrorField(ctx, err)
+		log.Errorf(errCtx, "RBAC preflight failed")
+		return err
+	}
+	registerReadinessChecks(healthServer, config, apiClient, tc)
+
+	// Build executor
+	log.Info(ctx, "Creating event executor...")
+	executorHeartbeat := healthServer.Heartbeat("executor", config.Health.Liveness.ExecutorStaleAfter)
+	executionHistory := health.NewExecutionHistory(config.Health.ExecutionHistorySize)
+	healthServer.SetExecutionHistory(executionHistory)
+	auditor, err := createAuditor(ctx, config.Audit, log, metricsRecorder)
+	if err != nil {
+		errCtx := logger.WithErrorField(ctx, err)
+		log.Errorf(errCtx, "Failed to create auditor")
+		return fmt.Errorf("failed to create auditor: %w", err)
+	}
+	defer func() {
+		closeCtx, closeCancel := context.WithTimeout(context.Background(), HealthServerShutdownTimeout)
+		defer closeCancel()
+		if closeErr := auditor.Close(closeCtx); closeErr != nil {
+			errCtx := logger.WithErrorField(closeCtx, closeErr)
+			log.Warnf(errCtx, "Failed to flush audit records")
+		}
+	}()
+	var exec *executor.Executor
+	var flagStore *featureflags.Store
+	defer func() { flagStore.Close() }()
+	var postBuffer *postbuffer.Buffer
+	defer func() { postBuffer.Close() }()
+	if err = startup.Run(ctx, bootstrap.StepExecutor, func(ctx context.Context) error {
+		var flagErr error
+		flagStore, flagErr = createFeatureFlagStore(ctx, config, tc, log)
+		if flagErr != nil {
+			errCtx := logger.WithErrorField(ctx, flagErr)
+			log.Errorf(errCtx, "Failed to create feature flag store")
+			return fmt.Errorf("failed to create feature flag store: %w", flagErr)
+		}
+		journalStore, journalErr := createJournalStore(ctx, config, tc, log)
+		if journalErr != nil {
+			errCtx := logger.WithErrorField(ctx, journalErr)
+			log.Errorf(errCtx, "Failed to create execution journal")
+			return fmt.Errorf("failed to create execution journal: %w", journalErr)
+		}
+		generations, idempotencyErr := createIdempotencyStore(ctx, config, tc, log)
+		if idempotencyErr != nil {
+			errCtx := logger.WithErrorField(ctx, idempotencyErr)
+			log.Errorf(errCtx, "Failed to create idempotency store")
+			return fmt.Errorf("failed to create idempotency store: %w", idempotencyErr)
+		}
+		webhookClient, webhookErr := webhook.NewClient(config.Clients.Webhook, transportPool, log, nil)
+		if webhookErr != nil {
+			errCtx := logger.WithErrorField(ctx, webhookErr)
+			log.Errorf(errCtx, "Failed to create webhook client")
+			return fmt.Errorf("failed to create webhook client: %w", webhookErr)
+		}
+		var bufferErr error
+		postBuffer, bufferErr = createPostBuffer(ctx, config, apiClient, metricsRecorder, log)
+		if bufferErr != nil {
+			errCtx := logger.WithErrorField(ctx, bufferErr)
+			log.Errorf(errCtx, "Failed to create post buffer")
+			return fmt.Errorf("failed to create post buffer: %w", bufferErr)
+		}
+		tables, tablesErr := createLookupTables(ctx, config, tc, log)
+		if tablesErr != nil {
+			errCtx := logger.WithErrorField(ctx, tablesErr)
+			log.Errorf(errCtx, "Failed to load lookup maps")
+			return fmt.Errorf("failed to load lookup maps: %w", tablesErr)
+		}
+		var buildErr error
+		exec, buildErr = buildExecutor(
+			config, apiClient, faults.wrapTransportClient(tc), log, metricsRecorder, executorHeartbeat, executionHistory,
+			auditor, webhookClient, journalStore, flagStore, postBuffer, generations, tables)
+		if buildErr != nil {
+			errCtx := logger.WithErrorField(ctx, buildErr)
+			log.Errorf(errCtx, "Failed to create executor")
+			return fmt.Errorf("failed to create executor: %w", buildErr)
+		}
+		// Complete the post actions of the executions interrupted by a crash before
+		// consuming events; the failed ones are kept for the next start and their
+		// events are redelivered by the broker
+		if recovered, recoverErr := exec.RecoverJournal(ctx); recoverErr != nil {
+			errCtx := logger.WithErrorField(ctx, recoverErr)
+			log.Warnf(errCtx, "Recovered %d journaled execution(s), others failed", recovered)
+		} else if recovered > 0 {
+			log.Infof(ctx, "Recovered %d journaled execution(s)", recovered)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	debugServer.Publish("in_flight_executions", func() any { return exec.InFlight() })
+	healthServer.SetStatsProvider(func() any { return exec.Stats() })
+	if flagStore != nil {
+		healthServer.SetFeatureFlagsProvider(func() any { return flagStore.Report() })
+	}
+	metrics.Register(prometheus.DefaultRegisterer, exec.StatsCollector(config.Adapter.Name, version.Version))
+
+	// Create the event handler and subscribe to broker
+	brokerMetrics := broker.NewMetricsRecorder(config.Adapter.Name, version.Version, nil)
+	middlewares, closeMiddlewares, err := buildMiddlewares(
+		ctx, config, log, servers, exec, tc, metricsRecorder, brokerMetrics)
+	defer closeMiddlewares()
+	if err != nil {
+		return err
+	}
+	handler := brokerconsumer.Chain(exec.CreateHandler(), middlewares...)
+
+	stopSignals := handleSignals(ctx, cancel, log, healthServer, logCfg.File, logLevels)
+	defer stopSignals()
+
+	// Get broker config
+	brokerConfig := config.Clients.Broker
+	subscriptions := brokerConfig.EffectiveSubscriptions()
+	if len(subscriptions) == 0 {
+		err = fmt.Errorf("clients.broker.subscriptions (or clients.broker.subscription_id and topic) is required")
+		errCtx := logger.WithErrorField(ctx, err)
+		log.Errorf(errCtx, "Missing required broker configuration")
+		return err
+	}
+	specs := make([]brokerconsumer.SubscriptionSpec, len(subscriptions))
+	for i, sub := range subscriptions {
+		specs[i] = brokerconsumer.SubscriptionSpec{
+			ID:       sub.SubscriptionID,
+			Topic:    sub.Topic,
+			Settings: sub.FlowControl.BrokerSettings(),
+		}
+	}
+
+	var onSubscriptionStatus brokerconsumer.SubscriptionStatusFunc
+	if configloader.CheckEnabled(config.Health.Checks.Broker) {
+		onSubscriptionStatus = healthServer.SetSubscriptionReady
+	}
+	group, err := brokerconsumer.NewSubscriptionGroup(
+		specs,
+		brokerconsumer.NewBrokerSubscriberFactory(log, brokerMetrics),
+		brokerConfig.FailFast(),
+		log,
+		metricsRecorder,
+		onSubscriptionStatus,
+	)
+	if err != nil {
+		errCtx := logger.WithErrorField(ctx, err)
+		log.Errorf(errCtx, "Invalid broker configuration")
+		return err
+	}
+
+	if replayTarget != nil || snapshotEndpoint {
+		closeReplayer, replayErr := setUpReplay(ctx, specs, replayTarget, healthServer, log)
+		defer closeReplayer()
+		if replayErr != nil {
+			return replayErr
+		}
+	}
+
+	// Start one receive loop per subscription
+	if err = startup.RunRetryable(ctx, bootstrap.StepBroker, func(ctx context.Context) error {
+		log.Infof(ctx, "Subscribing to %d broker subscription(s)...", len(specs))
+		if startErr := group.Start(ctx, handler); startErr != nil {
+			errCtx := logger.WithErrorField(ctx, startErr)
+			log.Errorf(errCtx, "Failed to start broker subscriptions")
+			return fmt.Errorf("failed to start broker subscriptions: %w", startErr)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	log.Infof(ctx, "Receiving from subscriptions: %s", strings.Join(group.Running(), ", "))
+	debugServer.Publish("subscriptions_running", func() any { return group.Running() })
+
+	// Mark as ready
+	healthServer.SetBrokerReady(true)
+	if err = startup.Complete(ctx); err != nil {
+		return err
+	}
+	log.Info(ctx, "Adapter is ready to process events")
+
+	// Latch the startup probe once the first dependency checks pass
+	go func() {
+		if waitErr := healthServer.WaitUntilReady(ctx, StartupPollInterval); waitErr != nil {
+			return
+		}
+		startupDuration := healthServer.MarkStarted()
+		metricsRecorder.SetStartupDuration(startupDuration)
+		log.Infof(ctx, "Adapter startup completed in %s", startupDuration.Round(time.Millisecond))
+	}()
+
+	log.Info(ctx, "Adapter started, waiting for events...")
+
+	// Wait for shutdown signal or fatal subscription error
+	select {
+	case <-ctx.Done():
+		log.Info(ctx, "Context canceled, shutting down...")
+	case err := <-group.FatalErrors():
+		errCtx := logger.WithErrorField(ctx, err)
+		log.Errorf(errCtx, "Fatal subscription error, shutting down")
+		healthServer.SetShuttingDown(true)
+		cancel()
+	}
+
+	// Close subscribers gracefully, draining in-flight messages
+	log.Info(ctx, "Closing broker subscribers...")
+	shutdownCtx, shutdownCancel := context.WithTimeout(
+		context.Background(), 30*time.Second,
+	)
+	defer shutdownCancel()
+
+	if err := group.Close(shutdownCtx); err != nil {
+		errCtx := logger.WithErrorField(ctx, err)
+		log.Errorf(errCtx, "Error closing subscribers")
+	} else {
+		log.Info(ctx, "Subscribers closed successfully")
+	}
+
+	log.Info(ctx, "Adapter shutdown complete")
+
+	return nil
+}
+
+// serveServers are the HTTP servers of the serve command
+type serveServers struct {
+	health *health.Server
+	// debug is nil unless --enable-pprof is set; its methods are no-ops then
+	debug *health.DebugServer
+}
+
+// startServers starts the health server, the metrics server, unless /metrics is
+// served by the health server, and the opt-in debug server. The returned
+// function shuts down the started servers and is set even on error.
+func startServers(
+	ctx context.Context, config *configloader.Config, log logger.Logger, buildInfo version.VersionInfo,
+) (serveServers, func(), error) {
+	var servers serveServers
+	var shutdowns []func(context.Context) error
+	var names []string
+	shutdown := func() {
+		for i := len(shutdowns) - 1; i >= 0; i-- {
+			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), HealthServerShutdownTimeout)
+			if shutdownErr := shutdowns[i](shutdownCtx); shutdownErr != nil {
+				errCtx := logger.WithErrorField(shutdownCtx, shutdownErr)
+				log.Warnf(errCtx, "Failed to shutdown %s server", names[i])
+			}
+			shutdownCancel()
+		}
+	}
+	started := func(name string, stop func(context.Context) error) {
+		names = append(names, name)
+		shutdowns = append(shutdowns, stop)
+	}
+
+	// Start health server
+	servers.health = health.NewServer(log, HealthServerPort, config.Adapter.Name)
+	if err := servers.health.Start(ctx); err != nil {
+		errCtx := logger.WithErrorField(ctx, err)
+		log.Errorf(errCtx, "Failed to start health server")
+		return servers, shutdown, fmt.Errorf("failed to start health server: %w", err)
+	}
+	started("health", servers.health.Shutdown)
+	servers.health.SetConfigLoaded()
+	servers.health.SetStartupComplete(false)
+
+	// Start metrics server
+	metricsServer, err := health.NewMetricsServer(log, MetricsServerPort, health.MetricsConfig{
+		Component:       config.Adapter.Name,
+		Version:         buildInfo.Version,
+		Commit:          buildInfo.Commit,
+		BuildDate:       buildInfo.BuildDate,
+		BearerToken:     os.Getenv(MetricsTokenEnv),
+		BearerTokenFile: os.Getenv(MetricsTokenFileEnv),
+	})
+	if err != nil {
+		errCtx := logger.WithErrorField(ctx, err)
+		log.Errorf(errCtx, "Failed to create metrics server")
+		return servers, shutdown, fmt.Errorf("failed to create metrics server: %w", err)
+	}
+	if config.Health.CombinedPort {
+		// Single container port: serve /metrics from the health server
+		servers.health.Handle("/metrics", metricsServer.Handler())
+		log.Infof(ctx, "Serving metrics on the health server at %s/metrics", servers.health.Addr())
+	} else if err = metricsServer.Start(ctx); err != nil {
+		errCtx := logger.WithErrorField(ctx, err)
+		log.Errorf(errCtx, "Failed to start metrics server")
+		return servers, shutdown, fmt.Errorf("failed to start metrics server: %w", err)
+	}
+	started("metrics", metricsServer.Shutdown)
+
+	// Start the opt-in debug server
+	if enablePprof {
+		debugToken := os.Getenv(DebugTokenEnv)
+		if debugToken == "" {
+			log.Warnf(ctx, "Debug server is enabled without authentication, set %s to require a bearer token",
+				DebugTokenEnv)
+		}
+		servers.debug = health.NewDebugServer(log, DebugServerPort, debugToken)
+		if err = servers.debug.Start(ctx); err != nil {
+			errCtx := logger.WithErrorField(ctx, err)
+			log.Errorf(errCtx, "Failed to start debug server")
+			return servers, shutdown, fmt.Errorf("failed to start debug server: %w", err)
+		}
+		started("debug", servers.debug.Shutdown)
+	}
+	return servers, shutdown, nil
+}
+
+// createClients creates the HyperFleet API client, over the transports of pool,
+// and the transport client. The API client injects the failures of faults; the
+// transport client is wrapped by the caller, so the readiness checks and the
+// RBAC preflight see the real client.
+func createClients(
+	ctx context.Context, config *configloader.Config, log logger.Logger, faults *clientFaults, pool *httpx.Pool,
+) (hyperfleetapi.Client, transportclient.TransportClient, error) {
+	log.Info(ctx, "Creating HyperFleet API client...")
+	opts := append([]hyperfleetapi.ClientOption{hyperfleetapi.WithTransportPool(pool)}, faults.apiClientOptions()...)
+	apiClient, err := createAPIClient(config.Clients.HyperfleetAPI, log, opts...)
+	if err != nil {
+		errCtx := logger.WithErrorField(ctx, err)
+		log.Errorf(errCtx, "Failed to create HyperFleet API client")
+		return nil, nil, fmt.Errorf("failed to create HyperFleet API client: %w", err)
+	}
+
+	tc, err := createTransportClient(ctx, config, log)
+	if err != nil {
+		errCtx := logger.WithErrorField(ctx, err)
+		log.Errorf(errCtx, "Failed to create transport client")
+		return nil, nil, err
+	}
+	return apiClient, tc, nil
+}
+
+// buildMiddlewares builds the middleware chain of the event handler. Middlewares
+// run outermost first: panics are recovered before anything else sees them.
+// The returned function closes the dead letter publisher and the dedup store
+// and is set even on error.
+func buildMiddlewares(
+	ctx context.Context,
+	config *configloader.Config,
+	log logger.Logger,
+	servers serveServers,
+	exec *executor.Executor,
+	tc transportclient.TransportClient,
+	metricsRecorder *metrics.Recorder,
+	brokerMetrics *broker.MetricsRecorder,
+) ([]brokerconsumer.Middleware, func(), error) {
+	var closers []func()
+	closeAll := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+
+	var limiter *brokerconsumer.RateLimiter
+	if rl := config.Clients.Broker.RateLimit; rl.Enabled() {
+		var err error
+		limiter, err = brokerconsumer.NewRateLimiter(rl.EventsPerSecond, rl.Burst, metricsRecorder)
+		if err != nil {
+			errCtx := logger.WithErrorField(ctx, err)
+			log.Errorf(errCtx, "Failed to create rate limiter")
+			return nil, closeAll, fmt.Errorf("failed to create rate limiter: %w", err)
+		}
+		log.Infof(ctx, "Rate limiting event handling to %v events/s (burst %d)", rl.EventsPerSecond, rl.Burst)
+		servers.debug.Publish("rate_limiter_waiting", func() any { return limiter.Waiting() })
+	}
+
+	var requeueConfig brokerconsumer.RequeueConfig
+	if rq := config.Clients.Broker.Requeue; rq != nil {
+		requeueConfig.MaxDelay = rq.MaxDelay
+		requeueConfig.MaxHeld = rq.MaxHeld
+	}
+	// Requeue holds messages outside of tracing, logging and metrics, so the
+	// delay is not counted as handling time
+	middlewares := []brokerconsumer.Middleware{
+		brokerconsumer.Recoverer(log, metricsRecorder),
+		brokerconsumer.Heartbeat(servers.health.Heartbeat("broker", config.Health.Liveness.BrokerStaleAfter)),
+		brokerconsumer.Redeliveries(brokerconsumer.RedeliveryConfig{
+			WarnAttempts: config.Clients.Broker.RedeliveryWarnAttempts,
+		}, log, metricsRecorder),
+		brokerconsumer.Requeue(ctx, requeueConfig, log, metricsRecorder),
+		brokerconsumer.Tracing(config.Adapter.Name),
+		brokerconsumer.Logging(log),
+		brokerconsumer.Metrics(metricsRecorder),
+	}
+	if config.Clients.Broker.MaxEventBytes > 0 {
+		sizeLimit, closeSizeLimit, sizeErr := createSizeLimit(ctx, config.Clients.Broker, log, brokerMetrics)
+		if sizeErr != nil {
+			errCtx := logger.WithErrorField(ctx, sizeErr)
+			log.Errorf(errCtx, "Failed to create dead letter publisher")
+			return nil, closeAll, fmt.Errorf("failed to create dead letter publisher: %w", sizeErr)
+		}
+		closers = append(closers, closeSizeLimit)
+		middlewares = append(middlewares, brokerconsumer.SizeLimit(sizeLimit, log, metricsRecorder))
+	}
+	if config.Clients.Broker.Dedup != nil {
+		dedupStore, dedupErr := createDedupStore(ctx, config, tc, log)
+		if dedupErr != nil {
+			errCtx := logger.WithErrorField(ctx, dedupErr)
+			log.Errorf(errCtx, "Failed to create dedup store")
+			return nil, closeAll, fmt.Errorf("failed to create dedup store: %w", dedupErr)
+		}
+		closers = append(closers, func() {
+			closeCtx, closeCancel := context.WithTimeout(context.Background(), HealthServerShutdownTimeout)
+			defer closeCancel()
+			if closeErr := dedupStore.Close(closeCtx); closeErr != nil {
+				errCtx := logger.WithErrorField(closeCtx, closeErr)
+				log.Warnf(errCtx, "Failed to close dedup store")
+			}
+		})
+		if sized, ok := dedupStore.(interface{ Len() int }); ok {
+			servers.debug.Publish("dedup_cache_entries", func() any { return sized.Len() })
+			exec.RegisterCacheSize("dedup", sized.Len)
+		}
+		middlewares = append(middlewares, brokerconsumer.Dedup(dedupStore, log, metricsRecorder))
+	}
+	middlewares = append(middlewares, limiter.Middleware(ctx))
+
+	if qc := config.Clients.Broker.Queue; qc.Enabled() {
+		queue, queueErr := brokerconsumer.NewQueue(brokerconsumer.QueueConfig{
+			Depth:   qc.Depth,
+			Workers: qc.Workers,
+			Reject:  qc.WhenFull == configloader.QueueFullReject,
+		}, metricsRecorder)
+		if queueErr != nil {
+			errCtx := logger.WithErrorField(ctx, queueErr)
+			log.Errorf(errCtx, "Failed to create event queue")
+			return nil, closeAll, fmt.Errorf("failed to create event queue: %w", queueErr)
+		}
+		// Closed once the subscriptions are, which waits for their queued events
+		closers = append(closers, func() {
+			closeCtx, closeCancel := context.WithTimeout(context.Background(), HealthServerShutdownTimeout)
+			defer closeCancel()
+			if closeErr := queue.Close(closeCtx); closeErr != nil {
+				errCtx := logger.WithErrorField(closeCtx, closeErr)
+				log.Warnf(errCtx, "Failed to drain event queue")
+			}
+		})
+		log.Infof(ctx, "Queueing events for %d worker(s) (depth %d)", max(qc.Workers, 1), qc.Depth)
+		servers.debug.Publish("event_queue_length", func() any { return queue.Len() })
+		// The workers handle events on their own goroutines, out of reach of the outer Recoverer
+		middlewares = append(middlewares, queue.Middleware(), brokerconsumer.Recoverer(log, metricsRecorder))
+	}
+	return middlewares, closeAll, nil
+}
+
+// handleSignals starts the signal handlers of the serve command. SIGINT and
+// SIGTERM mark the adapter not ready and cancel ctx; a second one exits right
+// away. SIGHUP reopens the log file for logrotate and re-reads the log level
+// from LOG_LEVEL_FILE; without a log file or LOG_LEVEL_FILE it cycles the level.
+// The returned function stops the SIGHUP handler.
+func handleSignals(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	log logger.Logger,
+	healthServer *health.Server,
+	logFile *logger.RotatingFile,
+	logLevels *logger.LevelController,
+) func() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-sigCh
+		log.Infof(ctx, "Received signal %s, initiating graceful shutdown...", sig)
+		log.Info(ctx, "Shutdown initiated, marking not ready")
+		healthServer.SetShuttingDown(true)
+		cancel()
+
+		// Second signal forces immediate exit
+		sig = <-sigCh
+		log.Infof(ctx, "Received second signal %s, forcing immediate exit", sig)
+		os.Exit(1)
+	}()
+
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hupCh:
+				if logFile != nil {
+					if reopenErr := logFile.Reopen(); reopenErr != nil {
+						errCtx := logger.WithErrorField(ctx, reopenErr)
+						log.Errorf(errCtx, "Failed to reopen log file %s on SIGHUP", logFile.Path())
+					}
+					if os.Getenv(LogLevelFileEnv) == "" {
+						continue
+					}
+				}
+				if _, reloadErr := logLevels.Reload(os.Getenv(LogLevelFileEnv)); reloadErr != nil {
+					errCtx := logger.WithErrorField(ctx, reloadErr)
+					log.Warnf(errCtx, "Failed to reload log level on SIGHUP")
+				}
+			}
+		}
+	}()
+	return func() { signal.Stop(hupCh) }
+}
+
+// setUpReplay seeks the single subscription to --replay-from and serves the
+// snapshot admin endpoint when enabled. The returned function closes the replay
+// client and is set even on error.
+func setUpReplay(
+	ctx context.Context,
+	specs []brokerconsumer.SubscriptionSpec,
+	replayTarget *brokerconsumer.ReplayTarget,
+	healthServer *health.Server,
+	log logger.Logger,
+) (func(), error) {
+	noop := func() {}
+	if len(specs) != 1 {
+		err := fmt.Errorf("--replay-from and --enable-snapshot-endpoint require a single subscription")
+		errCtx := logger.WithErrorField(ctx, err)
+		log.Errorf(errCtx, "Invalid replay configuration")
+		return noop, err
+	}
+	replayer, closeReplayer, err := createReplayer(ctx, specs[0].ID, log)
+	if err != nil {
+		errCtx := logger.WithErrorField(ctx, err)
+		log.Errorf(errCtx, "Failed to create replay client")
+		return noop, err
+	}
+	closeFn := func() {
+		if closeErr := closeReplayer(); closeErr != nil {
+			errCtx := logger.WithErrorField(ctx, closeErr)
+			log.Warnf(errCtx, "Failed to close replay client")
+		}
+	}
+
+	if replayTarget != nil {
+		if err = replayer.Seek(ctx, *replayTarget, confirmReplay); err != nil {
+			errCtx := logger.WithErrorField(ctx, err)
+			log.Errorf(errCtx, "Failed to seek subscription")
+			return closeFn, err
+		}
+	}
+	if snapshotEndpoint {
+		healthServer.Handle("/admin/snapshot", replayer.SnapshotHandler())
+		log.Warn(ctx, "Snapshot admin endpoint enabled at POST /admin/snapshot")
+	}
+	return closeFn, nil
+}
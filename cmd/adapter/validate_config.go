@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/spf13/pflag"
+)
+
+// -----------------------------------------------------------------------------
+// Validate-config mode
+// -----------------------------------------------------------------------------
+
+// Finding severities of validate-config
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// validationFinding is one error or warning reported by validate-config
+type validationFinding struct {
+	Severity string `json:"severity"`
+	Path     string `json:"path,omitempty"`
+	Message  string `json:"message"`
+}
+
+// validationReport is the output of validate-config
+type validationReport struct {
+	Findings []validationFinding `json:"findings"`
+	Errors   int                 `json:"errors"`
+	Warnings int                 `json:"warnings"`
+	Valid    bool                `json:"valid"`
+}
+
+// runValidateConfig loads the configuration like serve does and prints the
+// findings. Returns an error, making the command exit 1, when the config has
+// errors, or warnings with --strict.
+func runValidateConfig(flags *pflag.FlagSet) error {
+	if validateOutput != "text" && validateOutput != "json" {
+		return fmt.Errorf("invalid --output %q: must be 'text' or 'json'", validateOutput)
+	}
+
+	warnings := &configloader.ValidationErrors{}
+	_, loadErr := configloader.LoadConfig(configLoadOptions(flags, warnings)...)
+	report := newValidationReport(loadErr, warnings, validateStrict)
+
+	if validateOutput == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal validation report: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		report.writeText(os.Stdout)
+	}
+
+	if !report.Valid {
+		return fmt.Errorf("config validation failed with %d error(s) and %d warning(s)",
+			report.Errors, report.Warnings)
+	}
+	return nil
+}
+
+// newValidationReport builds the report of a LoadConfig call. Semantic
+// validation errors are reported one by one; LoadConfig stops at the first
+// structural error, which is reported as a single finding.
+func newValidationReport(loadErr error, warnings *configloader.ValidationErrors, strict bool) *validationReport {
+	report := &validationReport{Findings: []validationFinding{}}
+	if loadErr != nil {
+		var validationErrs *configloader.ValidationErrors
+		if errors.As(loadErr, &validationErrs) && validationErrs.HasErrors() {
+			for _, e := range validationErrs.Errors {
+				report.Findings = append(report.Findings,
+					validationFinding{Severity: severityError, Path: e.Path, Message: e.Message})
+			}
+		} else {
+			report.Findings = append(report.Findings,
+				validationFinding{Severity: severityError, Message: loadErr.Error()})
+		}
+		report.Errors = len(report.Findings)
+	}
+	for _, w := range warnings.Errors {
+		report.Findings = append(report.Findings,
+			validationFinding{Severity: severityWarning, Path: w.Path, Message: w.Message})
+	}
+	report.Warnings = warnings.Count()
+	report.Valid = report.Errors == 0 && (!strict || report.Warnings == 0)
+	return report
+}
+
+func (r *validationReport) writeText(w io.Writer) {
+	for _, f := range r.Findings {
+		if f.Path != "" {
+			_, _ = fmt.Fprintf(w, "%-7s %s: %s\n", f.Severity, f.Path, f.Message) //nolint:errcheck // stdout
+		} else {
+			_, _ = fmt.Fprintf(w, "%-7s %s\n", f.Severity, f.Message) //nolint:errcheck // stdout
+		}
+	}
+	status := "valid"
+	if !r.Valid {
+		status = "invalid"
+	}
+	//nolint:errcheck // stdout
+	_, _ = fmt.Fprintf(w, "Config is %s: %d error(s), %d warning(s)\n", status, r.Errors, r.Warnings)
+}
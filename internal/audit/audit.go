@@ -0,0 +1,267 @@
+// Package audit writes one structured record per event execution to dedicated
+// sinks (a rotating JSON lines file, an HTTP endpoint), for compliance trails
+// kept apart from the operational logs.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+)
+
+// SchemaVersion is the version of the Record schema. It changes when fields
+// are renamed or removed; added fields keep the version.
+const SchemaVersion = "v1"
+
+// DefaultQueueSize is the number of records buffered for the sinks when
+// Config.QueueSize is not set
+const DefaultQueueSize = 1000
+
+// QueueSinkName is the metric sink label of records dropped because the queue was full
+const QueueSinkName = "queue"
+
+// Precondition outcomes
+const (
+	OutcomeMet    = "met"
+	OutcomeNotMet = "not_met"
+	OutcomeFailed = "failed"
+)
+
+// Record is the audit record of one event execution
+type Record struct {
+	StartedAt     time.Time              `json:"started_at"`
+	FinishedAt    time.Time              `json:"finished_at"`
+	Params        map[string]interface{} `json:"params,omitempty"`
+	Event         Event                  `json:"event"`
+	SchemaVersion string                 `json:"schema_version"`
+	Adapter       string                 `json:"adapter"`
+	// Status is the execution outcome: "success", "skipped" or "failed"
+	Status string `json:"status"`
+	// Phase is the phase the execution ended in
+	Phase      string `json:"phase"`
+	SkipReason string `json:"skip_reason,omitempty"`
+	ErrorCode  string `json:"error_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	TraceID    string `json:"trace_id,omitempty"`
+	// CorrelationID is the correlation ID sent to the HyperFleet API and set on applied resources
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// ExecutionKey is the execution_fence key of the execution
+	ExecutionKey string `json:"execution_key,omitempty"`
+	// Workflow is the name of the workflow whose phases ran
+	Workflow string `json:"workflow,omitempty"`
+	// Preconditions lists the evaluated preconditions, in evaluation order
+	Preconditions []Precondition `json:"preconditions,omitempty"`
+	// Resources lists the applied resources, in apply order
+	Resources []Resource `json:"resources,omitempty"`
+	// PostActions lists the executed post actions, in execution order
+	PostActions []PostAction `json:"post_actions,omitempty"`
+	// PhaseDurationsMs is the time spent in each phase that ran
+	PhaseDurationsMs map[string]int64 `json:"phase_durations_ms,omitempty"`
+	// APICalls lists the outbound HyperFleet API calls, in call order
+	APICalls   []APICall `json:"api_calls,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// Event identifies the CloudEvent of an execution
+type Event struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Source string `json:"source"`
+}
+
+// Precondition is the outcome of one precondition
+type Precondition struct {
+	StartedAt time.Time `json:"started_at"`
+	Name      string    `json:"name"`
+	// Outcome is OutcomeMet, OutcomeNotMet or OutcomeFailed
+	Outcome    string `json:"outcome"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Resource is one applied resource
+type Resource struct {
+	StartedAt  time.Time `json:"started_at"`
+	Name       string    `json:"name"`
+	APIVersion string    `json:"api_version,omitempty"`
+	Kind       string    `json:"kind,omitempty"`
+	Namespace  string    `json:"namespace,omitempty"`
+	// ResourceName is the name of the Kubernetes object
+	ResourceName string `json:"resource_name,omitempty"`
+	// Operation is create, update, recreate or skip; empty if the apply failed
+	Operation string `json:"operation,omitempty"`
+	// ContentHash is the "sha256:<hex>" digest of the rendered manifest
+	ContentHash string `json:"content_hash,omitempty"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	DurationMs  int64  `json:"duration_ms"`
+}
+
+// PostAction is one executed post action
+type PostAction struct {
+	StartedAt time.Time `json:"started_at"`
+	Name      string    `json:"name"`
+	// Status is success, skipped, buffered (its API call failed and was
+	// buffered by buffer_on_failure) or failed
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// APICall is one outbound HyperFleet API call
+type APICall struct {
+	Phase      string `json:"phase"`
+	Step       string `json:"step"`
+	Method     string `json:"method"`
+	URL        string `json:"url,omitempty"`
+	Target     string `json:"target,omitempty"`
+	Error      string `json:"error,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+	LatencyMs  int64  `json:"latency_ms,omitempty"`
+	Attempts   int    `json:"attempts,omitempty"`
+}
+
+// Sink is a destination of audit records
+type Sink interface {
+	// Name is the sink label of the audit_write_failures_total metric
+	Name() string
+	// Write writes one JSON encoded record
+	Write(ctx context.Context, record []byte) error
+	// Close releases the sink's resources
+	Close() error
+}
+
+// Config configures an Auditor
+type Config struct {
+	Logger   logger.Logger
+	Recorder *metrics.Recorder
+	Sinks    []Sink
+	// QueueSize bounds the records waiting to be written. Zero uses DefaultQueueSize.
+	QueueSize int
+}
+
+// Auditor writes audit records to its sinks from a background worker, so a
+// slow or failing sink never delays or fails an execution: write failures and
+// records dropped on a full queue are logged and counted in the
+// audit_write_failures_total metric. A nil *Auditor discards records.
+type Auditor struct {
+	log      logger.Logger
+	recorder *metrics.Recorder
+	queue    chan Record
+	done     chan struct{}
+	// ctx is canceled when Close gives up waiting, aborting in-flight writes
+	ctx    context.Context
+	cancel context.CancelFunc
+	sinks  []Sink
+	mu     sync.RWMutex
+	closed bool
+}
+
+// New creates an Auditor and starts its worker
+func New(cfg Config) *Auditor {
+	size := cfg.QueueSize
+	if size <= 0 {
+		size = DefaultQueueSize
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &Auditor{
+		log:      cfg.Logger,
+		recorder: cfg.Recorder,
+		sinks:    cfg.Sinks,
+		queue:    make(chan Record, size),
+		done:     make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	go a.run()
+	return a
+}
+
+// Record queues a record for the sinks without blocking. The record is dropped
+// when the queue is full or the Auditor is closed.
+func (a *Auditor) Record(record Record) {
+	if a == nil {
+		return
+	}
+	if record.SchemaVersion == "" {
+		record.SchemaVersion = SchemaVersion
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return
+	}
+	select {
+	case a.queue <- record:
+	default:
+		a.recorder.RecordAuditWriteFailure(QueueSinkName)
+		a.log.Warnf(a.ctx, "Audit queue is full, dropping the audit record of event %s", record.Event.ID)
+	}
+}
+
+// Close stops accepting records, waits for the queued records to be written
+// and closes the sinks. If ctx is done first, in-flight writes are aborted and
+// ctx.Err() is returned.
+func (a *Auditor) Close(ctx context.Context) error {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+
+	var err error
+	select {
+	case <-a.done:
+	case <-ctx.Done():
+		a.cancel()
+		<-a.done
+		err = ctx.Err()
+	}
+	a.cancel()
+	for _, sink := range a.sinks {
+		if closeErr := sink.Close(); closeErr != nil {
+			errCtx := logger.WithErrorField(ctx, closeErr)
+			a.log.Warnf(errCtx, "Failed to close audit sink %s", sink.Name())
+		}
+	}
+	return err
+}
+
+// run writes the queued records until the queue is closed
+func (a *Auditor) run() {
+	defer close(a.done)
+	for record := range a.queue {
+		a.write(record)
+	}
+}
+
+// write writes a record to every sink
+func (a *Auditor) write(record Record) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		for _, sink := range a.sinks {
+			a.recorder.RecordAuditWriteFailure(sink.Name())
+		}
+		errCtx := logger.WithErrorField(a.ctx, err)
+		a.log.Errorf(errCtx, "Failed to encode the audit record of event %s", record.Event.ID)
+		return
+	}
+	for _, sink := range a.sinks {
+		if writeErr := sink.Write(a.ctx, data); writeErr != nil {
+			a.recorder.RecordAuditWriteFailure(sink.Name())
+			errCtx := logger.WithErrorField(a.ctx, writeErr)
+			a.log.Errorf(errCtx, "Failed to write the audit record of event %s to sink %s",
+				record.Event.ID, sink.Name())
+		}
+	}
+}
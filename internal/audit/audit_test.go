@@ -0,0 +1,330 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// testRecord is a record with every field set
+func testRecord() Record {
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return Record{
+		SchemaVersion: SchemaVersion,
+		Adapter:       "test-adapter",
+		Event: Event{
+			ID:     "evt-1",
+			Type:   "com.redhat.hyperfleet.cluster.reconcile",
+			Source: "/api/hyperfleet/v1/clusters/cluster-1",
+		},
+		StartedAt:     started,
+		FinishedAt:    started.Add(1500 * time.Millisecond),
+		DurationMs:    1500,
+		Status:        "failed",
+		Phase:         "post_actions",
+		SkipReason:    "",
+		ErrorCode:     "APIError",
+		Error:         "post action execution failed: HTTP 500",
+		TraceID:       "4bf92f3577b34da6a3ce929d0e0e4736",
+		CorrelationID: "5b0e8f6e-2f4c-4d9a-9a57-0f1f3c6f8d21",
+		Params: map[string]interface{}{
+			"clusterId": "cluster-1",
+			"token":     "[REDACTED]",
+		},
+		Preconditions: []Precondition{
+			{StartedAt: started, Name: "clusterStatus", Outcome: OutcomeMet, DurationMs: 120},
+			{
+				StartedAt: started.Add(120 * time.Millisecond), Name: "quota",
+				Outcome: OutcomeFailed, Error: "HTTP 503", DurationMs: 80,
+			},
+		},
+		Resources: []Resource{{
+			Name:         "clusterNamespace",
+			APIVersion:   "v1",
+			Kind:         "Namespace",
+			ResourceName: "cluster-1",
+			Operation:    "create",
+			ContentHash:  "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			Status:       "success",
+			StartedAt:    started.Add(200 * time.Millisecond),
+			DurationMs:   900,
+		}},
+		PostActions: []PostAction{{
+			StartedAt:  started.Add(1100 * time.Millisecond),
+			Name:       "reportStatus",
+			Status:     "failed",
+			Error:      "HTTP 500",
+			DurationMs: 400,
+		}},
+		PhaseDurationsMs: map[string]int64{
+			"param_extraction": 0,
+			"preconditions":    200,
+			"resources":        900,
+			"post_actions":     400,
+		},
+		APICalls: []APICall{
+			{
+				Phase: "preconditions", Step: "clusterStatus", Method: "GET",
+				URL: "/clusters/cluster-1", Target: "default", StatusCode: 200,
+			},
+			{
+				Phase: "post_actions", Step: "reportStatus", Method: "POST",
+				URL: "/clusters/cluster-1/statuses", Target: "default", StatusCode: 500, Error: "HTTP 500",
+			},
+		},
+	}
+}
+
+// TestRecord_Golden pins the JSON encoding of the v1 schema: a change to it
+// must either keep this file identical or bump SchemaVersion
+func TestRecord_Golden(t *testing.T) {
+	golden := filepath.Join("testdata", "record_"+SchemaVersion+".golden.json")
+
+	got, err := json.MarshalIndent(testRecord(), "", "  ")
+	require.NoError(t, err)
+
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(golden, append(got, '\n'), 0o600))
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), string(got))
+	assert.Equal(t, string(want), string(got)+"\n", "field order changed")
+}
+
+// memorySink records the written records, failing the first failures writes
+type memorySink struct {
+	records  [][]byte
+	failures int
+	mu       sync.Mutex
+	closed   bool
+}
+
+func (s *memorySink) Name() string { return "memory" }
+
+func (s *memorySink) Write(_ context.Context, record []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func auditWriteFailures(t *testing.T, registry *prometheus.Registry, sink string) float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, f := range families {
+		if f.GetName() != "hyperfleet_adapter_audit_write_failures_total" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "sink" && l.GetValue() == sink {
+					return m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestAuditor_WritesAndCountsFailures(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewRecorder("test-adapter", "v0.1.0", registry)
+	sink := &memorySink{failures: 1}
+	auditor := New(Config{Logger: logger.NewTestLogger(), Recorder: recorder, Sinks: []Sink{sink}})
+
+	first := testRecord()
+	first.Event.ID = "evt-1"
+	second := testRecord()
+	second.Event.ID = "evt-2"
+	second.SchemaVersion = ""
+	auditor.Record(first)
+	auditor.Record(second)
+	require.NoError(t, auditor.Close(context.Background()))
+
+	assert.True(t, sink.closed)
+	require.Len(t, sink.records, 1, "the first write failed")
+	var written Record
+	require.NoError(t, json.Unmarshal(sink.records[0], &written))
+	assert.Equal(t, "evt-2", written.Event.ID)
+	assert.Equal(t, SchemaVersion, written.SchemaVersion, "Record sets the schema version")
+	assert.Equal(t, float64(1), auditWriteFailures(t, registry, "memory"))
+
+	// Records after Close are discarded
+	auditor.Record(first)
+	assert.Len(t, sink.records, 1)
+}
+
+// blockingSink blocks writes until release is closed
+type blockingSink struct {
+	release chan struct{}
+	writes  atomic.Int32
+}
+
+func (s *blockingSink) Name() string { return "blocking" }
+
+func (s *blockingSink) Write(ctx context.Context, _ []byte) error {
+	s.writes.Add(1)
+	select {
+	case <-s.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *blockingSink) Close() error { return nil }
+
+func TestAuditor_FullQueueDropsRecords(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewRecorder("test-adapter", "v0.1.0", registry)
+	sink := &blockingSink{release: make(chan struct{})}
+	auditor := New(Config{Logger: logger.NewTestLogger(), Recorder: recorder, Sinks: []Sink{sink}, QueueSize: 1})
+
+	// The worker takes the first record and blocks on it, the second fills the queue
+	auditor.Record(testRecord())
+	require.Eventually(t, func() bool { return sink.writes.Load() == 1 }, time.Second, time.Millisecond)
+	auditor.Record(testRecord())
+	auditor.Record(testRecord())
+	assert.Equal(t, float64(1), auditWriteFailures(t, registry, QueueSinkName))
+
+	close(sink.release)
+	require.NoError(t, auditor.Close(context.Background()))
+	assert.Equal(t, int32(2), sink.writes.Load())
+}
+
+func TestAuditor_CloseTimeoutAbortsWrites(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	auditor := New(Config{Logger: logger.NewTestLogger(), Sinks: []Sink{sink}})
+	auditor.Record(testRecord())
+	require.Eventually(t, func() bool { return sink.writes.Load() == 1 }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, auditor.Close(ctx), context.DeadlineExceeded)
+}
+
+func TestAuditor_Nil(t *testing.T) {
+	var auditor *Auditor
+	assert.NotPanics(t, func() { auditor.Record(testRecord()) })
+	assert.NoError(t, auditor.Close(context.Background()))
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(logger.FileConfig{Path: path})
+	require.NoError(t, err)
+	auditor := New(Config{Logger: logger.NewTestLogger(), Sinks: []Sink{sink}})
+
+	for _, id := range []string{"evt-1", "evt-2"} {
+		record := testRecord()
+		record.Event.ID = id
+		auditor.Record(record)
+	}
+	require.NoError(t, auditor.Close(context.Background()))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close() //nolint:errcheck // read-only
+
+	var ids []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record), "each line is a record")
+		ids = append(ids, record.Event.ID)
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, []string{"evt-1", "evt-2"}, ids)
+}
+
+func TestHTTPSink(t *testing.T) {
+	tests := []struct {
+		name          string
+		statuses      []int
+		expectErr     bool
+		expectedCalls int32
+	}{
+		{name: "success", statuses: []int{http.StatusAccepted}, expectedCalls: 1},
+		{
+			name:          "retries server errors",
+			statuses:      []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			expectedCalls: 3,
+		},
+		{
+			name:          "gives up after the attempts",
+			statuses:      []int{http.StatusInternalServerError},
+			expectErr:     true,
+			expectedCalls: 3,
+		},
+		{
+			name:          "client errors are not retried",
+			statuses:      []int{http.StatusBadRequest},
+			expectErr:     true,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(calls.Add(1))
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+				w.WriteHeader(tt.statuses[min(n, len(tt.statuses))-1])
+			}))
+			defer server.Close()
+
+			sink, err := NewHTTPSink(HTTPSinkConfig{
+				URL:       server.URL,
+				Headers:   map[string]string{"Authorization": "Bearer secret"},
+				BaseDelay: time.Millisecond,
+			})
+			require.NoError(t, err)
+			defer sink.Close() //nolint:errcheck // test cleanup
+
+			err = sink.Write(context.Background(), []byte(`{"schema_version":"v1"}`))
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedCalls, calls.Load())
+		})
+	}
+
+	_, err := NewHTTPSink(HTTPSinkConfig{})
+	assert.Error(t, err, "URL is required")
+}
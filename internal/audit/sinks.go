@@ -0,0 +1,183 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// Sink names, used as the sink label of the audit_write_failures_total metric
+const (
+	FileSinkName = "file"
+	HTTPSinkName = "http"
+)
+
+// HTTP sink defaults
+const (
+	DefaultHTTPTimeout       = 5 * time.Second
+	DefaultHTTPRetryAttempts = 3
+	DefaultHTTPBaseDelay     = 500 * time.Millisecond
+	DefaultHTTPMaxDelay      = 10 * time.Second
+)
+
+// FileSink appends records as JSON lines to a rotating file
+type FileSink struct {
+	file *logger.RotatingFile
+}
+
+// NewFileSink opens the rotating audit file
+func NewFileSink(cfg logger.FileConfig) (*FileSink, error) {
+	file, err := logger.OpenRotatingFile(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+// Name returns FileSinkName
+func (s *FileSink) Name() string {
+	return FileSinkName
+}
+
+// Write appends the record and a newline in a single write, so a rotation
+// never splits a line
+func (s *FileSink) Write(_ context.Context, record []byte) error {
+	line := make([]byte, 0, len(record)+1)
+	line = append(line, record...)
+	line = append(line, '\n')
+	_, err := s.file.Write(line)
+	return err
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// HTTPSinkConfig configures an HTTPSink
+type HTTPSinkConfig struct {
+	// Client sends the requests. Nil uses a client with Timeout.
+	Client *http.Client
+	// Clock times the retry backoff (nil uses the real clock)
+	Clock clock.Clock
+	// Headers are added to every request, e.g. an Authorization header
+	Headers map[string]string
+	// URL receives each record as the body of a POST request
+	URL string
+	// Timeout bounds each attempt. Zero uses DefaultHTTPTimeout.
+	Timeout time.Duration
+	// BaseDelay is the delay before the first retry, doubled after each retry
+	// up to DefaultHTTPMaxDelay. Zero uses DefaultHTTPBaseDelay.
+	BaseDelay time.Duration
+	// RetryAttempts is the number of attempts, including the first. Zero uses DefaultHTTPRetryAttempts.
+	RetryAttempts int
+}
+
+// HTTPSink POSTs each record to an HTTP endpoint, retrying connection errors,
+// 429 and 5xx responses with exponential backoff
+type HTTPSink struct {
+	client  *http.Client
+	clock   clock.Clock
+	headers map[string]string
+	url     string
+	timeout time.Duration
+	delay   time.Duration
+	retries int
+}
+
+// NewHTTPSink creates an HTTPSink
+func NewHTTPSink(cfg HTTPSinkConfig) (*HTTPSink, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("audit HTTP sink URL is required")
+	}
+	s := &HTTPSink{
+		client:  cfg.Client,
+		clock:   clock.OrReal(cfg.Clock),
+		headers: cfg.Headers,
+		url:     cfg.URL,
+		timeout: cfg.Timeout,
+		delay:   cfg.BaseDelay,
+		retries: cfg.RetryAttempts,
+	}
+	if s.timeout <= 0 {
+		s.timeout = DefaultHTTPTimeout
+	}
+	if s.delay <= 0 {
+		s.delay = DefaultHTTPBaseDelay
+	}
+	if s.retries <= 0 {
+		s.retries = DefaultHTTPRetryAttempts
+	}
+	if s.client == nil {
+		s.client = &http.Client{Timeout: s.timeout}
+	}
+	return s, nil
+}
+
+// Name returns HTTPSinkName
+func (s *HTTPSink) Name() string {
+	return HTTPSinkName
+}
+
+// Write POSTs the record, returning the last error once the attempts are exhausted
+func (s *HTTPSink) Write(ctx context.Context, record []byte) error {
+	delay := s.delay
+	var lastErr error
+	for attempt := 1; attempt <= s.retries; attempt++ {
+		retryable, err := s.post(ctx, record)
+		if err == nil {
+			return nil
+		}
+		lastErr = fmt.Errorf("attempt %d/%d: %w", attempt, s.retries, err)
+		if !retryable || attempt == s.retries {
+			break
+		}
+		if sleepErr := s.clock.Sleep(ctx, delay); sleepErr != nil {
+			return fmt.Errorf("%w (retry aborted: %w)", lastErr, sleepErr)
+		}
+		delay = min(delay*2, DefaultHTTPMaxDelay)
+	}
+	return lastErr
+}
+
+// post sends one attempt and reports whether a failure is worth retrying
+func (s *HTTPSink) post(ctx context.Context, record []byte) (bool, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, s.url, bytes.NewReader(record))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range s.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // body is discarded
+	// Drain the body so the connection is reused
+	io.Copy(io.Discard, resp.Body) //nolint:errcheck // best-effort
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("HTTP %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+}
+
+// Close releases idle connections
+func (s *HTTPSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,287 @@
+// Package bootstrap runs the startup sequence of the adapter: each step runs in
+// a fixed order, readiness is only reported once every step succeeded, and the
+// outcome of each step is kept in a report served by /statusz.
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// Steps of the serve startup sequence, in order
+const (
+	// StepConfig loads and validates the adapter and task configs
+	StepConfig = "config"
+	// StepClients builds the HyperFleet API and transport clients
+	StepClients = "clients"
+	// StepPreflight runs the RBAC preflight checks
+	StepPreflight = "preflight"
+	// StepExecutor builds the executor and recovers journaled executions
+	StepExecutor = "executor"
+	// StepBroker starts the broker subscriptions
+	StepBroker = "broker"
+)
+
+// ServeSteps is the startup sequence of the serve command
+var ServeSteps = []string{StepConfig, StepClients, StepPreflight, StepExecutor, StepBroker}
+
+// Outcomes of a step in the report
+const (
+	OutcomePending   = "pending"
+	OutcomeRunning   = "running"
+	OutcomeRetrying  = "retrying"
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+)
+
+// Retry defaults of retryable steps
+const (
+	// DefaultAttempts is the number of attempts of a retryable step
+	DefaultAttempts = 5
+	// DefaultInitialBackoff is the wait before the second attempt
+	DefaultInitialBackoff = time.Second
+	// DefaultMaxBackoff caps the wait between attempts, doubled after each one
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// StepReport is the outcome of one startup step
+type StepReport struct {
+	Name    string `json:"name"`
+	Outcome string `json:"outcome"`
+	// Attempts is the number of times the step ran
+	Attempts int `json:"attempts,omitempty"`
+	// Duration is the time spent on the step, waits between attempts included
+	Duration        string  `json:"duration,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+	// Error is the error of the last failed attempt
+	Error string `json:"error,omitempty"`
+}
+
+// Report is the state of the startup sequence
+type Report struct {
+	// Ready is true once every step succeeded and readiness was reported
+	Ready bool         `json:"ready"`
+	Steps []StepReport `json:"steps"`
+}
+
+// Config configures a Sequence
+type Config struct {
+	// Steps are the names of the steps, in the order they must run
+	Steps []string
+	// SetReady reports readiness; Complete calls it with true once every step
+	// succeeded, and nothing calls it before
+	SetReady func(ready bool)
+	// Clock times the steps and the waits between attempts (nil uses the real clock)
+	Clock clock.Clock
+	// Attempts is the number of attempts of a retryable step. Zero uses DefaultAttempts.
+	Attempts int
+	// InitialBackoff is the wait before the second attempt. Zero uses DefaultInitialBackoff.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts. Zero uses DefaultMaxBackoff.
+	MaxBackoff time.Duration
+}
+
+// Sequence runs the startup steps in their configured order. A step failing
+// fails the sequence: readiness stays false and later steps are refused.
+// Report is safe for concurrent use with the steps.
+type Sequence struct {
+	config Config
+	clock  clock.Clock
+	log    logger.Logger
+	steps  []StepReport
+	// next is the index of the next step to run
+	next   int
+	failed bool
+	ready  bool
+	mu     sync.Mutex
+}
+
+// NewSequence creates a sequence with every step pending. Readiness is false
+// until Complete: SetReady is only ever called by it.
+func NewSequence(config Config, log logger.Logger) *Sequence {
+	if config.Attempts <= 0 {
+		config.Attempts = DefaultAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = DefaultInitialBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+	steps := make([]StepReport, len(config.Steps))
+	for i, name := range config.Steps {
+		steps[i] = StepReport{Name: name, Outcome: OutcomePending}
+	}
+	return &Sequence{config: config, clock: clock.OrReal(config.Clock), log: log, steps: steps}
+}
+
+// SetLogger replaces the logger of the sequence, e.g. with the logger built
+// from the loaded config
+func (s *Sequence) SetLogger(log logger.Logger) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.log = log
+}
+
+// Run runs the step name once. It must be the next step of the sequence.
+func (s *Sequence) Run(ctx context.Context, name string, step func(context.Context) error) error {
+	return s.run(ctx, name, 1, step)
+}
+
+// RunRetryable runs the step name, retrying it with exponential backoff while
+// it fails, up to the configured attempts or until ctx is done. It must be the
+// next step of the sequence.
+func (s *Sequence) RunRetryable(ctx context.Context, name string, step func(context.Context) error) error {
+	return s.run(ctx, name, s.config.Attempts, step)
+}
+
+// Complete reports readiness once every step succeeded and logs the report.
+// Returns an error, leaving readiness false, when steps did not run or failed.
+func (s *Sequence) Complete(ctx context.Context) error {
+	s.mu.Lock()
+	if s.failed || s.next < len(s.steps) {
+		s.mu.Unlock()
+		return fmt.Errorf("startup sequence incomplete: %d of %d steps succeeded", s.succeeded(), len(s.steps))
+	}
+	s.ready = true
+	s.mu.Unlock()
+
+	s.setReady(true)
+	s.logReport(ctx)
+	return nil
+}
+
+// Report returns a copy of the current state of the sequence
+func (s *Sequence) Report() Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Report{Ready: s.ready, Steps: slices.Clone(s.steps)}
+}
+
+// run runs the step name up to attempts times, recording its outcome
+func (s *Sequence) run(ctx context.Context, name string, attempts int, step func(context.Context) error) error {
+	i, err := s.begin(name)
+	if err != nil {
+		return err
+	}
+
+	start := s.clock.Now()
+	backoff := s.config.InitialBackoff
+	attempt := 0
+	for {
+		attempt++
+		if err = step(ctx); err == nil {
+			s.finish(i, attempt, s.clock.Since(start), nil)
+			return nil
+		}
+		if attempt >= attempts || ctx.Err() != nil {
+			break
+		}
+		s.update(i, OutcomeRetrying, attempt, s.clock.Since(start), err)
+		errCtx := logger.WithErrorField(ctx, err)
+		s.logger().Warnf(errCtx, "Startup step %s failed (attempt %d/%d), retrying in %s",
+			name, attempt, attempts, backoff)
+		if s.clock.Sleep(ctx, backoff) != nil {
+			break
+		}
+		backoff = min(backoff*2, s.config.MaxBackoff)
+		s.update(i, OutcomeRunning, attempt, s.clock.Since(start), err)
+	}
+
+	s.finish(i, attempt, s.clock.Since(start), err)
+	s.logReport(ctx)
+	return fmt.Errorf("startup step %s failed after %d attempt(s): %w", name, attempt, err)
+}
+
+// begin marks the step name running, checking it is the next step
+func (s *Sequence) begin(name string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failed {
+		return 0, fmt.Errorf("startup step %s not run: an earlier step failed", name)
+	}
+	if s.next >= len(s.steps) || s.steps[s.next].Name != name {
+		return 0, fmt.Errorf("startup step %s run out of order", name)
+	}
+	i := s.next
+	s.steps[i].Outcome = OutcomeRunning
+	return i, nil
+}
+
+// update records the state of step i between attempts
+func (s *Sequence) update(i int, outcome string, attempts int, elapsed time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.steps[i].Outcome = outcome
+	s.steps[i].Attempts = attempts
+	s.steps[i].Duration = elapsed.Round(time.Millisecond).String()
+	s.steps[i].DurationSeconds = elapsed.Seconds()
+	if err != nil {
+		s.steps[i].Error = err.Error()
+	}
+}
+
+// finish records the final outcome of step i after attempts
+func (s *Sequence) finish(i int, attempts int, elapsed time.Duration, err error) {
+	if err != nil {
+		s.update(i, OutcomeFailed, attempts, elapsed, err)
+		s.mu.Lock()
+		s.failed = true
+		s.mu.Unlock()
+		return
+	}
+	s.update(i, OutcomeSucceeded, attempts, elapsed, nil)
+	s.mu.Lock()
+	s.steps[i].Error = ""
+	s.next++
+	s.mu.Unlock()
+}
+
+// succeeded returns the number of succeeded steps; s.mu must be held
+func (s *Sequence) succeeded() int {
+	n := 0
+	for _, step := range s.steps {
+		if step.Outcome == OutcomeSucceeded {
+			n++
+		}
+	}
+	return n
+}
+
+// setReady calls the configured SetReady, if any
+func (s *Sequence) setReady(ready bool) {
+	if s.config.SetReady != nil {
+		s.config.SetReady(ready)
+	}
+}
+
+// logger returns the current logger of the sequence
+func (s *Sequence) logger() logger.Logger {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.log
+}
+
+// logReport logs one line per step with its outcome, attempts and duration
+func (s *Sequence) logReport(ctx context.Context) {
+	log := s.logger()
+	for _, step := range s.Report().Steps {
+		stepCtx := logger.WithLogFields(ctx, logger.LogFields{
+			"startup_step":     step.Name,
+			"outcome":          step.Outcome,
+			"attempts":         step.Attempts,
+			"duration_seconds": step.DurationSeconds,
+		})
+		if step.Outcome == OutcomeFailed {
+			log.Errorf(logger.WithLogField(stepCtx, logger.ErrorKey, step.Error), "Startup step %s failed", step.Name)
+			continue
+		}
+		log.Infof(stepCtx, "Startup step %s %s", step.Name, step.Outcome)
+	}
+}
@@ -0,0 +1,179 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readiness records the SetReady calls of a sequence
+type readiness struct {
+	calls []bool
+	mu    sync.Mutex
+}
+
+func (r *readiness) set(ready bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, ready)
+}
+
+func (r *readiness) get() []bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls
+}
+
+func outcomes(report Report) map[string]string {
+	byStep := make(map[string]string, len(report.Steps))
+	for _, step := range report.Steps {
+		byStep[step.Name] = step.Outcome
+	}
+	return byStep
+}
+
+func succeed(context.Context) error { return nil }
+
+func TestSequence_ReadyOnlyOnceEveryStepSucceeded(t *testing.T) {
+	ready := &readiness{}
+	seq := NewSequence(Config{Steps: ServeSteps, SetReady: ready.set}, logger.NewTestLogger())
+	ctx := context.Background()
+
+	for _, step := range ServeSteps {
+		require.NoError(t, seq.Run(ctx, step, func(context.Context) error {
+			assert.Equal(t, OutcomeRunning, outcomes(seq.Report())[step])
+			return nil
+		}))
+		assert.Empty(t, ready.get(), "readiness reported before the sequence completed, after %s", step)
+		assert.False(t, seq.Report().Ready)
+	}
+	require.NoError(t, seq.Complete(ctx))
+
+	assert.Equal(t, []bool{true}, ready.get())
+	report := seq.Report()
+	assert.True(t, report.Ready)
+	for _, step := range report.Steps {
+		assert.Equal(t, OutcomeSucceeded, step.Outcome, step.Name)
+		assert.Equal(t, 1, step.Attempts, step.Name)
+		assert.Empty(t, step.Error, step.Name)
+	}
+}
+
+func TestSequence_FailedStepKeepsReadinessFalse(t *testing.T) {
+	ready := &readiness{}
+	seq := NewSequence(Config{Steps: ServeSteps, SetReady: ready.set}, logger.NewTestLogger())
+	ctx := context.Background()
+
+	require.NoError(t, seq.Run(ctx, StepConfig, succeed))
+	require.NoError(t, seq.Run(ctx, StepClients, succeed))
+	err := seq.Run(ctx, StepPreflight, func(context.Context) error { return errors.New("access denied") })
+	assert.EqualError(t, err, "startup step preflight failed after 1 attempt(s): access denied")
+
+	assert.ErrorContains(t, seq.Run(ctx, StepExecutor, succeed), "an earlier step failed")
+	assert.EqualError(t, seq.Complete(ctx), "startup sequence incomplete: 2 of 5 steps succeeded")
+	assert.Empty(t, ready.get())
+
+	report := seq.Report()
+	assert.False(t, report.Ready)
+	assert.Equal(t, map[string]string{
+		StepConfig:    OutcomeSucceeded,
+		StepClients:   OutcomeSucceeded,
+		StepPreflight: OutcomeFailed,
+		StepExecutor:  OutcomePending,
+		StepBroker:    OutcomePending,
+	}, outcomes(report))
+	assert.Equal(t, "access denied", report.Steps[2].Error)
+}
+
+func TestSequence_StepsRunInOrder(t *testing.T) {
+	ready := &readiness{}
+	seq := NewSequence(Config{Steps: ServeSteps, SetReady: ready.set}, logger.NewTestLogger())
+	ctx := context.Background()
+
+	ran := false
+	err := seq.Run(ctx, StepClients, func(context.Context) error {
+		ran = true
+		return nil
+	})
+	assert.EqualError(t, err, "startup step clients run out of order")
+	assert.False(t, ran)
+	assert.Error(t, seq.Complete(ctx), "no step ran")
+	assert.Empty(t, ready.get())
+}
+
+func TestSequence_RetriesRetryableSteps(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	ready := &readiness{}
+	seq := NewSequence(Config{
+		Steps:          []string{StepClients, StepBroker},
+		SetReady:       ready.set,
+		Clock:          fake,
+		Attempts:       3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     90 * time.Second,
+	}, logger.NewTestLogger())
+	ctx := context.Background()
+
+	attempts := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- seq.RunRetryable(ctx, StepClients, func(context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("connection refused")
+			}
+			return nil
+		})
+	}()
+
+	fake.BlockUntil(1)
+	report := seq.Report()
+	assert.Equal(t, OutcomeRetrying, report.Steps[0].Outcome)
+	assert.Equal(t, 1, report.Steps[0].Attempts)
+	assert.Equal(t, "connection refused", report.Steps[0].Error)
+	fake.Advance(time.Second)
+	fake.BlockUntil(1)
+	fake.Advance(2 * time.Second)
+	require.NoError(t, <-done)
+
+	report = seq.Report()
+	assert.Equal(t, StepReport{
+		Name: StepClients, Outcome: OutcomeSucceeded, Attempts: 3, Duration: "3s", DurationSeconds: 3,
+	}, report.Steps[0])
+	assert.Empty(t, ready.get(), "retrying a step does not report readiness")
+
+	// The broker never comes up: the step fails once its attempts are used up
+	go func() {
+		done <- seq.RunRetryable(ctx, StepBroker, func(context.Context) error { return errors.New("unavailable") })
+	}()
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	fake.BlockUntil(1)
+	fake.Advance(2 * time.Second)
+	assert.EqualError(t, <-done, "startup step broker failed after 3 attempt(s): unavailable")
+	assert.Equal(t, OutcomeFailed, seq.Report().Steps[1].Outcome)
+	assert.Error(t, seq.Complete(ctx))
+	assert.Empty(t, ready.get())
+}
+
+func TestSequence_RetryStopsWhenContextDone(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	seq := NewSequence(Config{Steps: []string{StepBroker}, Clock: fake}, logger.NewTestLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- seq.RunRetryable(ctx, StepBroker, func(context.Context) error { return errors.New("unavailable") })
+	}()
+	fake.BlockUntil(1)
+	cancel()
+	assert.EqualError(t, <-done, "startup step broker failed after 1 attempt(s): unavailable")
+	assert.Equal(t, OutcomeFailed, seq.Report().Steps[0].Outcome)
+}
@@ -0,0 +1,93 @@
+package brokerconsumer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// brokerEnvKeys lists the hyperfleet-broker config keys that can be set through
+// BROKER_* environment variables (key with dots replaced by underscores, upper-cased).
+var brokerEnvKeys = []string{
+	"log_config",
+	"subscriber.parallelism",
+	"broker.type",
+	"broker.rabbitmq.url",
+	"broker.rabbitmq.exchange",
+	"broker.rabbitmq.exchange_type",
+	"broker.rabbitmq.queue",
+	"broker.rabbitmq.routing_key",
+	"broker.rabbitmq.prefetch_count",
+	"broker.rabbitmq.prefetch_size",
+	"broker.rabbitmq.consumer_tag",
+	"broker.googlepubsub.project_id",
+	"broker.googlepubsub.ack_deadline_seconds",
+	"broker.googlepubsub.message_retention_duration",
+	"broker.googlepubsub.expiration_ttl",
+	"broker.googlepubsub.enable_message_ordering",
+	"broker.googlepubsub.retry_min_backoff",
+	"broker.googlepubsub.retry_max_backoff",
+	"broker.googlepubsub.dead_letter_topic",
+	"broker.googlepubsub.dead_letter_max_attempts",
+	"broker.googlepubsub.max_outstanding_messages",
+	"broker.googlepubsub.max_outstanding_bytes",
+	"broker.googlepubsub.num_goroutines",
+	"broker.googlepubsub.create_topic_if_missing",
+	"broker.googlepubsub.create_subscription_if_missing",
+}
+
+// brokerConfigFile returns the broker config file path: BROKER_CONFIG_FILE or ./broker.yaml
+func brokerConfigFile() string {
+	if configFile := os.Getenv("BROKER_CONFIG_FILE"); configFile != "" {
+		return configFile
+	}
+	return "broker.yaml"
+}
+
+// BrokerConfigMap returns the effective hyperfleet-broker configuration as a flat
+// key/value map (broker config file, then BROKER_* env vars, then overrides).
+//
+// The broker library ignores its config file when a subscriber is created from a
+// config map, so per-subscription overrides must be layered on the full config.
+func BrokerConfigMap(overrides map[string]string) (map[string]string, error) {
+	configMap := map[string]string{}
+
+	configFile := brokerConfigFile()
+	if data, err := os.ReadFile(filepath.Clean(configFile)); err == nil {
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse broker config %s: %w", configFile, err)
+		}
+		flattenBrokerConfig("", doc, configMap)
+	}
+
+	for _, key := range brokerEnvKeys {
+		envKey := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		if value := os.Getenv(envKey); value != "" {
+			configMap[key] = value
+		}
+	}
+
+	for key, value := range overrides {
+		configMap[key] = value
+	}
+	return configMap, nil
+}
+
+func flattenBrokerConfig(prefix string, doc map[string]interface{}, out map[string]string) {
+	for key, value := range doc {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenBrokerConfig(key, nested, out)
+			continue
+		}
+		if value != nil {
+			out[key] = fmt.Sprint(value)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package brokerconsumer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/lru"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/openshift-hyperfleet/hyperfleet-broker/broker"
+)
+
+// Dedup store defaults
+const (
+	DefaultDedupMaxEntries    = 10000
+	DefaultDedupTTL           = 1 * time.Hour
+	DefaultDedupFlushInterval = 10 * time.Second
+)
+
+// DedupStore records the IDs of events that were processed and acknowledged,
+// so redelivered copies can be acknowledged without running the executor again.
+type DedupStore interface {
+	// Seen reports whether the event ID was already processed
+	Seen(ctx context.Context, id string) (bool, error)
+	// MarkProcessed records the event ID as processed
+	MarkProcessed(ctx context.Context, id string) error
+	// Close flushes pending state and releases resources
+	Close(ctx context.Context) error
+}
+
+// Dedup acknowledges events whose ID is already in the store without invoking
+// the handler, and records the ID once the handler succeeds.
+// Store errors fail open: the event is treated as not a duplicate, so a broken
+// store can cause reprocessing but never blocks processing.
+func Dedup(store DedupStore, log logger.Logger, recorder *metrics.Recorder) Middleware {
+	return func(next broker.HandlerFunc) broker.HandlerFunc {
+		return func(ctx context.Context, evt *event.Event) error {
+			seen, err := store.Seen(ctx, evt.ID())
+			if err != nil {
+				errCtx := logger.WithErrorField(ctx, err)
+				log.Warnf(errCtx, "Dedup store lookup failed for event %s, processing it anyway", evt.ID())
+			} else if seen {
+				recorder.RecordDuplicateEvent()
+				log.Infof(ctx, "Skipping duplicate event %s", evt.ID())
+				return nil
+			}
+
+			if err := next(ctx, evt); err != nil {
+				return err
+			}
+
+			if err := store.MarkProcessed(ctx, evt.ID()); err != nil {
+				errCtx := logger.WithErrorField(ctx, err)
+				log.Warnf(errCtx, "Failed to record event %s in dedup store", evt.ID())
+			}
+			return nil
+		}
+	}
+}
+
+// MemoryDedupStore is an in-process LRU of processed event IDs with a TTL.
+// It does not survive restarts; see ConfigMapDedupStore for a persistent variant.
+type MemoryDedupStore struct {
+	// processed holds when each event ID was processed
+	processed *lru.Cache[string, time.Time]
+	clock     clock.Clock
+	ttl       time.Duration
+	// mu serializes the writes, which compare timestamps before storing them
+	mu sync.Mutex
+}
+
+var _ DedupStore = (*MemoryDedupStore)(nil)
+
+// NewMemoryDedupStore creates an LRU dedup store. Zero values use the defaults.
+func NewMemoryDedupStore(maxEntries int, ttl time.Duration) *MemoryDedupStore {
+	if maxEntries <= 0 {
+		maxEntries = DefaultDedupMaxEntries
+	}
+	if ttl <= 0 {
+		ttl = DefaultDedupTTL
+	}
+	return &MemoryDedupStore{
+		processed: lru.New[string, time.Time](maxEntries).WithTTL(ttl, nil),
+		clock:     clock.Real,
+		ttl:       ttl,
+	}
+}
+
+// WithClock sets the clock used to timestamp entries and expire them (nil uses the real clock)
+func (s *MemoryDedupStore) WithClock(clk clock.Clock) *MemoryDedupStore {
+	s.clock = clock.OrReal(clk)
+	s.processed.WithTTL(s.ttl, s.clock)
+	return s
+}
+
+// Seen implements DedupStore.Seen
+func (s *MemoryDedupStore) Seen(_ context.Context, id string) (bool, error) {
+	_, ok := s.processed.Peek(id)
+	return ok, nil
+}
+
+// MarkProcessed implements DedupStore.MarkProcessed
+func (s *MemoryDedupStore) MarkProcessed(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock.Now()
+	s.processed.AddAt(id, now, now)
+	return nil
+}
+
+// Close implements DedupStore.Close
+func (s *MemoryDedupStore) Close(_ context.Context) error {
+	return nil
+}
+
+// Len returns the number of entries currently held
+func (s *MemoryDedupStore) Len() int {
+	return s.processed.Len()
+}
+
+// merge adds entries processed elsewhere (e.g. loaded from a persistent store),
+// keeping the most recent timestamp for IDs already present.
+func (s *MemoryDedupStore) merge(entries map[string]time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, processedAt := range entries {
+		if existing, ok := s.processed.Peek(id); ok && !processedAt.After(existing) {
+			continue
+		}
+		if s.clock.Since(processedAt) > s.ttl {
+			continue
+		}
+		s.processed.AddAt(id, processedAt, processedAt)
+	}
+}
@@ -0,0 +1,258 @@
+package brokerconsumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// dedupConfigMapKey is the ConfigMap data key holding the processed event IDs
+const dedupConfigMapKey = "processed-events"
+
+// ConfigMapDedupConfig configures a ConfigMapDedupStore
+type ConfigMapDedupConfig struct {
+	Namespace     string
+	Name          string
+	MaxEntries    int
+	TTL           time.Duration
+	FlushInterval time.Duration
+	// Clock timestamps entries and drives the flush loop (nil uses the real clock)
+	Clock clock.Clock
+}
+
+// ConfigMapDedupStore persists processed event IDs in a ConfigMap so they
+// survive pod restarts.
+//
+// Lookups are served from an in-memory LRU; writes are buffered and flushed to
+// the ConfigMap on a timer, so the hot path never waits on the apiserver.
+// Each flush re-reads the ConfigMap, merges entries written by other replicas,
+// drops entries older than the TTL, keeps at most MaxEntries (newest first) and
+// writes back with optimistic concurrency. On conflict or error the pending IDs
+// are retried on the next flush.
+//
+// Semantics are best-effort: events processed shortly before a crash (within one
+// flush interval) or by another replica since the last flush may be reprocessed.
+// A ConfigMap that cannot be read or parsed never blocks processing; it is
+// treated as empty and overwritten on the next successful flush.
+type ConfigMapDedupStore struct {
+	client  k8sclient.K8sClient
+	log     logger.Logger
+	local   *MemoryDedupStore
+	pending map[string]time.Time
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+	config  ConfigMapDedupConfig
+	mu      sync.Mutex
+	once    sync.Once
+}
+
+var _ DedupStore = (*ConfigMapDedupStore)(nil)
+
+// NewConfigMapDedupStore creates the store, loads existing entries from the
+// ConfigMap (best-effort) and starts the background flush loop.
+func NewConfigMapDedupStore(
+	ctx context.Context,
+	client k8sclient.K8sClient,
+	config ConfigMapDedupConfig,
+	log logger.Logger,
+) (*ConfigMapDedupStore, error) {
+	if client == nil {
+		return nil, fmt.Errorf("kubernetes client is required for the configmap dedup store")
+	}
+	if config.Name == "" || config.Namespace == "" {
+		return nil, fmt.Errorf("configmap dedup store requires both name and namespace")
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultDedupMaxEntries
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultDedupTTL
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultDedupFlushInterval
+	}
+	config.Clock = clock.OrReal(config.Clock)
+
+	s := &ConfigMapDedupStore{
+		client:  client,
+		log:     log,
+		local:   NewMemoryDedupStore(config.MaxEntries, config.TTL).WithClock(config.Clock),
+		pending: make(map[string]time.Time),
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+		config:  config,
+	}
+
+	if entries, err := s.read(ctx); err != nil {
+		errCtx := logger.WithErrorField(ctx, err)
+		log.Warnf(errCtx, "Failed to load dedup store from ConfigMap %s/%s, starting empty",
+			config.Namespace, config.Name)
+	} else {
+		s.local.merge(entries)
+		log.Infof(ctx, "Loaded %d processed event IDs from ConfigMap %s/%s",
+			s.local.Len(), config.Namespace, config.Name)
+	}
+
+	go s.flushLoop()
+	return s, nil
+}
+
+// Seen implements DedupStore.Seen
+func (s *ConfigMapDedupStore) Seen(ctx context.Context, id string) (bool, error) {
+	return s.local.Seen(ctx, id)
+}
+
+// MarkProcessed implements DedupStore.MarkProcessed.
+// The ID is visible to Seen immediately and persisted on the next flush.
+func (s *ConfigMapDedupStore) MarkProcessed(ctx context.Context, id string) error {
+	now := s.config.Clock.Now()
+	s.local.merge(map[string]time.Time{id: now})
+
+	s.mu.Lock()
+	s.pending[id] = now
+	s.mu.Unlock()
+	return nil
+}
+
+// Close stops the flush loop and performs a final flush.
+func (s *ConfigMapDedupStore) Close(ctx context.Context) error {
+	s.once.Do(func() {
+		close(s.stopCh)
+	})
+	<-s.doneCh
+	return s.Flush(ctx)
+}
+
+// Len returns the number of entries currently held in memory
+func (s *ConfigMapDedupStore) Len() int {
+	return s.local.Len()
+}
+
+// Flush writes pending entries to the ConfigMap.
+func (s *ConfigMapDedupStore) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[string]time.Time)
+	s.mu.Unlock()
+
+	if err := s.write(ctx, pending); err != nil {
+		// Requeue for the next flush, keeping newer timestamps recorded meanwhile
+		s.mu.Lock()
+		for id, ts := range pending {
+			if _, ok := s.pending[id]; !ok {
+				s.pending[id] = ts
+			}
+		}
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (s *ConfigMapDedupStore) flushLoop() {
+	defer close(s.doneCh)
+	ticker := s.config.Clock.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C():
+			ctx, cancel := context.WithTimeout(context.Background(), s.config.FlushInterval)
+			if err := s.Flush(ctx); err != nil {
+				errCtx := logger.WithErrorField(ctx, err)
+				s.log.Warnf(errCtx, "Failed to flush dedup store to ConfigMap %s/%s",
+					s.config.Namespace, s.config.Name)
+			}
+			cancel()
+		}
+	}
+}
+
+// read fetches the entries of the ConfigMap, none when it does not exist
+func (s *ConfigMapDedupStore) read(ctx context.Context) (map[string]time.Time, error) {
+	obj, err := k8sclient.GetConfigMap(ctx, s.client, s.config.Namespace, s.config.Name)
+	if err != nil || obj == nil {
+		return map[string]time.Time{}, err
+	}
+	raw, _, _ := unstructured.NestedString(obj.Object, "data", dedupConfigMapKey)
+	return s.decode(ctx, raw), nil
+}
+
+// decode decodes the entries stored in the ConfigMap. Corrupt data is logged
+// and treated as empty.
+func (s *ConfigMapDedupStore) decode(ctx context.Context, raw string) map[string]time.Time {
+	if raw == "" {
+		return map[string]time.Time{}
+	}
+	var stored map[string]int64
+	if err := json.Unmarshal([]byte(raw), &stored); err != nil {
+		errCtx := logger.WithErrorField(ctx, err)
+		s.log.Warnf(errCtx, "Dedup ConfigMap %s/%s has corrupt data, discarding it",
+			s.config.Namespace, s.config.Name)
+		return map[string]time.Time{}
+	}
+
+	entries := make(map[string]time.Time, len(stored))
+	for id, unix := range stored {
+		entries[id] = time.Unix(unix, 0)
+	}
+	return entries
+}
+
+// write merges pending entries with the ConfigMap content, compacts and stores them.
+// Entries written by other replicas are merged into the local LRU as a side effect.
+func (s *ConfigMapDedupStore) write(ctx context.Context, pending map[string]time.Time) error {
+	return k8sclient.UpdateConfigMapData(ctx, s.client, s.config.Namespace, s.config.Name,
+		func(data map[string]string, exists bool) (bool, error) {
+			entries := s.decode(ctx, data[dedupConfigMapKey])
+			s.local.merge(entries)
+			if len(pending) == 0 && exists {
+				return false, nil
+			}
+
+			for id, ts := range pending {
+				if existing, ok := entries[id]; !ok || ts.After(existing) {
+					entries[id] = ts
+				}
+			}
+			raw, err := json.Marshal(s.compact(entries))
+			if err != nil {
+				return false, fmt.Errorf("failed to encode dedup entries: %w", err)
+			}
+			data[dedupConfigMapKey] = string(raw)
+			return true, nil
+		})
+}
+
+// compact drops expired entries and keeps the newest MaxEntries.
+func (s *ConfigMapDedupStore) compact(entries map[string]time.Time) map[string]int64 {
+	now := s.config.Clock.Now()
+	ids := make([]string, 0, len(entries))
+	for id, ts := range entries {
+		if now.Sub(ts) <= s.config.TTL {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return entries[ids[i]].After(entries[ids[j]])
+	})
+	if len(ids) > s.config.MaxEntries {
+		ids = ids[:s.config.MaxEntries]
+	}
+
+	out := make(map[string]int64, len(ids))
+	for _, id := range ids {
+		out[id] = entries[id].Unix()
+	}
+	return out
+}
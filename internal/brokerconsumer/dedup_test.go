@@ -0,0 +1,264 @@
+package brokerconsumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// failingDedupStore returns errors for every operation
+type failingDedupStore struct{}
+
+func (failingDedupStore) Seen(context.Context, string) (bool, error) {
+	return false, errors.New("store unavailable")
+}
+func (failingDedupStore) MarkProcessed(context.Context, string) error {
+	return errors.New("store unavailable")
+}
+func (failingDedupStore) Close(context.Context) error { return nil }
+
+func TestMemoryDedupStore_LRUEviction(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryDedupStore(2, time.Hour)
+
+	require.NoError(t, store.MarkProcessed(ctx, "a"))
+	require.NoError(t, store.MarkProcessed(ctx, "b"))
+	require.NoError(t, store.MarkProcessed(ctx, "c"))
+
+	seen, err := store.Seen(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, seen, "oldest entry should be evicted")
+
+	for _, id := range []string{"b", "c"} {
+		seen, err = store.Seen(ctx, id)
+		require.NoError(t, err)
+		assert.True(t, seen, id)
+	}
+	assert.Equal(t, 2, store.Len())
+}
+
+func TestMemoryDedupStore_TTL(t *testing.T) {
+	ctx := context.Background()
+	fake := clock.NewFake(time.Now())
+	store := NewMemoryDedupStore(10, time.Minute).WithClock(fake)
+
+	require.NoError(t, store.MarkProcessed(ctx, "a"))
+	seen, _ := store.Seen(ctx, "a")
+	assert.True(t, seen)
+
+	fake.Advance(2 * time.Minute)
+	seen, _ = store.Seen(ctx, "a")
+	assert.False(t, seen, "expired entry should not be reported as seen")
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestDedupMiddleware(t *testing.T) {
+	store := NewMemoryDedupStore(10, time.Hour)
+	calls := 0
+	fail := false
+	handler := Dedup(store, logger.NewTestLogger(), nil)(func(ctx context.Context, evt *event.Event) error {
+		calls++
+		if fail {
+			return errors.New("nack")
+		}
+		return nil
+	})
+
+	// Failed handling is not recorded, so the redelivery is processed
+	fail = true
+	assert.Error(t, handler(context.Background(), newTestEvent("evt-1")))
+	fail = false
+	assert.NoError(t, handler(context.Background(), newTestEvent("evt-1")))
+	// Duplicate of an acknowledged event is skipped
+	assert.NoError(t, handler(context.Background(), newTestEvent("evt-1")))
+	assert.Equal(t, 2, calls)
+}
+
+func TestDedupMiddleware_StoreErrorFailsOpen(t *testing.T) {
+	calls := 0
+	handler := Dedup(failingDedupStore{}, logger.NewTestLogger(), nil)(
+		func(ctx context.Context, evt *event.Event) error {
+			calls++
+			return nil
+		})
+
+	assert.NoError(t, handler(context.Background(), newTestEvent("evt-1")))
+	assert.NoError(t, handler(context.Background(), newTestEvent("evt-1")))
+	assert.Equal(t, 2, calls)
+}
+
+func newTestConfigMapStore(t *testing.T, client *k8sclient.MockK8sClient) *ConfigMapDedupStore {
+	t.Helper()
+	store, err := NewConfigMapDedupStore(context.Background(), client, ConfigMapDedupConfig{
+		Namespace:     "hyperfleet",
+		Name:          "adapter-dedup",
+		FlushInterval: time.Hour, // flushed explicitly in tests
+	}, logger.NewTestLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close(context.Background()) })
+	return store
+}
+
+func storedEventIDs(t *testing.T, client *k8sclient.MockK8sClient) map[string]int64 {
+	t.Helper()
+	obj, ok := client.Resources["hyperfleet/adapter-dedup"]
+	require.True(t, ok, "ConfigMap should exist")
+	raw, _, err := unstructured.NestedString(obj.Object, "data", dedupConfigMapKey)
+	require.NoError(t, err)
+	var ids map[string]int64
+	require.NoError(t, json.Unmarshal([]byte(raw), &ids))
+	return ids
+}
+
+func TestConfigMapDedupStore_PersistsAcrossRestarts(t *testing.T) {
+	ctx := context.Background()
+	client := k8sclient.NewMockK8sClient()
+
+	store := newTestConfigMapStore(t, client)
+	require.NoError(t, store.MarkProcessed(ctx, "evt-1"))
+
+	// Visible locally before any flush
+	seen, err := store.Seen(ctx, "evt-1")
+	require.NoError(t, err)
+	assert.True(t, seen)
+	assert.Empty(t, client.Resources, "writes must be buffered")
+
+	require.NoError(t, store.Flush(ctx))
+	assert.Contains(t, storedEventIDs(t, client), "evt-1")
+
+	// A new store (e.g. after a pod restart) loads the persisted IDs
+	restarted := newTestConfigMapStore(t, client)
+	seen, err = restarted.Seen(ctx, "evt-1")
+	require.NoError(t, err)
+	assert.True(t, seen)
+}
+
+func TestConfigMapDedupStore_MergesOtherReplicas(t *testing.T) {
+	ctx := context.Background()
+	client := k8sclient.NewMockK8sClient()
+
+	replicaA := newTestConfigMapStore(t, client)
+	replicaB := newTestConfigMapStore(t, client)
+
+	require.NoError(t, replicaA.MarkProcessed(ctx, "evt-a"))
+	require.NoError(t, replicaA.Flush(ctx))
+	require.NoError(t, replicaB.MarkProcessed(ctx, "evt-b"))
+	require.NoError(t, replicaB.Flush(ctx))
+
+	ids := storedEventIDs(t, client)
+	assert.Contains(t, ids, "evt-a")
+	assert.Contains(t, ids, "evt-b")
+
+	seen, _ := replicaB.Seen(ctx, "evt-a")
+	assert.True(t, seen, "flush should merge entries written by other replicas")
+}
+
+func TestConfigMapDedupStore_CompactsToMaxEntries(t *testing.T) {
+	ctx := context.Background()
+	client := k8sclient.NewMockK8sClient()
+	fake := clock.NewFake(time.Now())
+	store, err := NewConfigMapDedupStore(ctx, client, ConfigMapDedupConfig{
+		Namespace:     "hyperfleet",
+		Name:          "adapter-dedup",
+		MaxEntries:    3,
+		FlushInterval: time.Hour,
+		Clock:         fake,
+	}, logger.NewTestLogger())
+	require.NoError(t, err)
+	defer func() { _ = store.Close(ctx) }()
+
+	for i := 0; i < 5; i++ {
+		fake.Advance(time.Second)
+		require.NoError(t, store.MarkProcessed(ctx, fmt.Sprintf("evt-%d", i)))
+	}
+	require.NoError(t, store.Flush(ctx))
+
+	ids := storedEventIDs(t, client)
+	assert.Len(t, ids, 3)
+	assert.Contains(t, ids, "evt-4")
+	assert.NotContains(t, ids, "evt-0")
+}
+
+func TestConfigMapDedupStore_FlushLoop(t *testing.T) {
+	ctx := context.Background()
+	client := k8sclient.NewMockK8sClient()
+	fake := clock.NewFake(time.Now())
+	store, err := NewConfigMapDedupStore(ctx, client, ConfigMapDedupConfig{
+		Namespace:     "hyperfleet",
+		Name:          "adapter-dedup",
+		FlushInterval: 10 * time.Second,
+		Clock:         fake,
+	}, logger.NewTestLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, store.MarkProcessed(ctx, "evt-1"))
+	fake.BlockUntil(1)
+	fake.Advance(10 * time.Second)
+	require.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return len(store.pending) == 0
+	}, time.Second, 5*time.Millisecond, "the ticker should flush the pending IDs")
+
+	// Close waits for the in-flight flush before the ConfigMap is inspected
+	require.NoError(t, store.Close(ctx))
+	assert.Contains(t, storedEventIDs(t, client), "evt-1")
+}
+
+func TestConfigMapDedupStore_CorruptDataDoesNotBlock(t *testing.T) {
+	ctx := context.Background()
+	client := k8sclient.NewMockK8sClient()
+
+	corrupt := &unstructured.Unstructured{}
+	corrupt.SetGroupVersionKind(k8sclient.ConfigMapGVK)
+	corrupt.SetNamespace("hyperfleet")
+	corrupt.SetName("adapter-dedup")
+	require.NoError(t, unstructured.SetNestedField(corrupt.Object, "{not json", "data", dedupConfigMapKey))
+	client.Resources["hyperfleet/adapter-dedup"] = corrupt
+
+	store := newTestConfigMapStore(t, client)
+	seen, err := store.Seen(ctx, "evt-1")
+	require.NoError(t, err)
+	assert.False(t, seen)
+
+	require.NoError(t, store.MarkProcessed(ctx, "evt-1"))
+	require.NoError(t, store.Flush(ctx))
+	assert.Contains(t, storedEventIDs(t, client), "evt-1", "corrupt data should be overwritten")
+}
+
+func TestConfigMapDedupStore_FlushFailureRequeues(t *testing.T) {
+	ctx := context.Background()
+	client := k8sclient.NewMockK8sClient()
+	store := newTestConfigMapStore(t, client)
+
+	require.NoError(t, store.MarkProcessed(ctx, "evt-1"))
+	client.CreateResourceError = errors.New("apiserver unavailable")
+	assert.Error(t, store.Flush(ctx))
+
+	client.CreateResourceError = nil
+	require.NoError(t, store.Flush(ctx))
+	assert.Contains(t, storedEventIDs(t, client), "evt-1")
+}
+
+func TestNewConfigMapDedupStore_Validation(t *testing.T) {
+	_, err := NewConfigMapDedupStore(context.Background(), nil, ConfigMapDedupConfig{
+		Namespace: "ns", Name: "cm",
+	}, logger.NewTestLogger())
+	assert.Error(t, err)
+
+	_, err = NewConfigMapDedupStore(context.Background(), k8sclient.NewMockK8sClient(), ConfigMapDedupConfig{
+		Namespace: "ns",
+	}, logger.NewTestLogger())
+	assert.Error(t, err)
+}
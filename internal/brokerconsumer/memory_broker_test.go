@@ -0,0 +1,144 @@
+package brokerconsumer
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/delivery"
+	"github.com/openshift-hyperfleet/hyperfleet-broker/broker"
+)
+
+// memoryBroker is an in-memory broker for tests. Published events are encoded
+// like on the wire and decoded again for every subscriber of their topic, which
+// handles them one at a time and acknowledges those its handler accepts. Like
+// Pub/Sub with a dead letter policy, it reports the delivery attempt of each
+// event and redelivers those NACKed until maxDeliveries attempts were made.
+type memoryBroker struct {
+	published   map[string][][]byte
+	subscribers map[string][]chan memoryDelivery
+	acked       []string
+	nacked      []string
+	pending     sync.WaitGroup
+	mu          sync.Mutex
+	// maxDeliveries bounds the delivery attempts of an event; zero delivers once
+	maxDeliveries int
+}
+
+// memoryDelivery is a delivery of an encoded event
+type memoryDelivery struct {
+	data    []byte
+	attempt int
+}
+
+func newMemoryBroker() *memoryBroker {
+	return &memoryBroker{published: map[string][][]byte{}, subscribers: map[string][]chan memoryDelivery{}}
+}
+
+// Publish records evt under topic and delivers it to the topic's subscribers
+func (b *memoryBroker) Publish(_ context.Context, topic string, evt *event.Event) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.published[topic] = append(b.published[topic], data)
+	subscribers := b.subscribers[topic]
+	b.pending.Add(len(subscribers))
+	b.mu.Unlock()
+	for _, deliveries := range subscribers {
+		deliveries <- memoryDelivery{data: data, attempt: 1}
+	}
+	return nil
+}
+
+// Subscriber returns a new subscriber of the broker
+func (b *memoryBroker) Subscriber() broker.Subscriber {
+	return &memorySubscriber{broker: b, errCh: make(chan *broker.SubscriberError), done: make(chan struct{})}
+}
+
+// settle waits until every delivered event is acknowledged or NACKed
+func (b *memoryBroker) settle() {
+	b.pending.Wait()
+}
+
+// events returns the events published to topic
+func (b *memoryBroker) events(topic string) []*event.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	events := make([]*event.Event, 0, len(b.published[topic]))
+	for _, data := range b.published[topic] {
+		evt := event.New()
+		if err := json.Unmarshal(data, &evt); err == nil {
+			events = append(events, &evt)
+		}
+	}
+	return events
+}
+
+// outcomes returns the IDs of the acknowledged and NACKed events
+func (b *memoryBroker) outcomes() (acked, nacked []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.acked...), append([]string(nil), b.nacked...)
+}
+
+func (b *memoryBroker) settled(id string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err != nil {
+		b.nacked = append(b.nacked, id)
+	} else {
+		b.acked = append(b.acked, id)
+	}
+}
+
+// memorySubscriber is a subscriber of a memoryBroker
+type memorySubscriber struct {
+	broker    *memoryBroker
+	errCh     chan *broker.SubscriberError
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *memorySubscriber) Subscribe(ctx context.Context, topic string, handler broker.HandlerFunc) error {
+	deliveries := make(chan memoryDelivery, 16)
+	s.broker.mu.Lock()
+	s.broker.subscribers[topic] = append(s.broker.subscribers[topic], deliveries)
+	s.broker.mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-s.done:
+				return
+			case msg := <-deliveries:
+				evt := event.New()
+				err := json.Unmarshal(msg.data, &evt)
+				if err == nil {
+					err = handler(delivery.WithAttempt(ctx, msg.attempt), &evt)
+				}
+				s.broker.settled(evt.ID(), err)
+				if err != nil && msg.attempt < s.broker.maxDeliveries {
+					s.broker.pending.Add(1)
+					deliveries <- memoryDelivery{data: msg.data, attempt: msg.attempt + 1}
+				}
+				s.broker.pending.Done()
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *memorySubscriber) Errors() <-chan *broker.SubscriberError {
+	return s.errCh
+}
+
+func (s *memorySubscriber) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		close(s.errCh)
+	})
+	return nil
+}
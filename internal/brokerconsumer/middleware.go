@@ -0,0 +1,178 @@
+package brokerconsumer
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/health"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	pkgotel "github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/otel"
+	"github.com/openshift-hyperfleet/hyperfleet-broker/broker"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Handler outcomes reported by the Metrics middleware
+const (
+	OutcomeAck  = "ack"
+	OutcomeNack = "nack"
+)
+
+// Middleware wraps a broker handler with additional behavior.
+type Middleware func(next broker.HandlerFunc) broker.HandlerFunc
+
+type subscriptionContextKey struct{}
+
+// SubscriptionFromContext returns the ID of the subscription the event being
+// handled was received on, as set by the Subscription middleware, or "".
+func SubscriptionFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(subscriptionContextKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// Chain composes middlewares around handler. The first middleware is the
+// outermost, i.e. Chain(h, a, b) invokes a, then b, then h.
+// Nil middlewares are skipped so optional middlewares can be passed directly.
+func Chain(handler broker.HandlerFunc, middlewares ...Middleware) broker.HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] == nil {
+			continue
+		}
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// PanicError is returned by Recoverer's inner handler when a panic is recovered.
+// It is a permanent failure: redelivering the same event would panic again.
+type PanicError struct {
+	Value interface{}
+	Stack string
+}
+
+// Error implements the error interface
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("handler panicked: %v", e.Value)
+}
+
+// Subscription tags each handled event with the subscription it was received on,
+// so the other middlewares label their metrics and logs by subscription.
+// It must be the outermost middleware.
+func Subscription(subscriptionID string) Middleware {
+	return func(next broker.HandlerFunc) broker.HandlerFunc {
+		return func(ctx context.Context, evt *event.Event) error {
+			ctx = context.WithValue(ctx, subscriptionContextKey{}, subscriptionID)
+			ctx = logger.WithSubscription(ctx, subscriptionID)
+			return next(ctx, evt)
+		}
+	}
+}
+
+// Recoverer recovers panics raised by the wrapped handler, logs them with their
+// stack trace and counts them. The event is treated as a permanent failure and
+// acknowledged so a poison message cannot crash the receive goroutine or loop
+// through redelivery forever.
+func Recoverer(log logger.Logger, recorder *metrics.Recorder) Middleware {
+	return func(next broker.HandlerFunc) broker.HandlerFunc {
+		return func(ctx context.Context, evt *event.Event) (err error) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				panicErr := &PanicError{Value: rec, Stack: string(debug.Stack())}
+				recorder.RecordHandlerPanic(SubscriptionFromContext(ctx))
+
+				errCtx := logger.WithErrorField(ctx, panicErr)
+				errCtx = logger.WithLogField(errCtx, logger.StackTraceKey, strings.Split(panicErr.Stack, "\n"))
+				log.Errorf(errCtx, "Recovered panic while handling event %s, acknowledging as permanent failure",
+					evt.ID())
+				err = nil
+			}()
+			return next(ctx, evt)
+		}
+	}
+}
+
+// Logging logs the start and end of each handler invocation with its duration and outcome.
+func Logging(log logger.Logger) Middleware {
+	return func(next broker.HandlerFunc) broker.HandlerFunc {
+		return func(ctx context.Context, evt *event.Event) error {
+			ctx = logger.WithEventID(ctx, evt.ID())
+			log.Debugf(ctx, "Handling event: type=%s source=%s", evt.Type(), evt.Source())
+
+			start := time.Now()
+			err := next(ctx, evt)
+			duration := time.Since(start)
+
+			if err != nil {
+				errCtx := logger.WithErrorField(ctx, err)
+				log.Warnf(errCtx, "Event handling failed: outcome=%s duration=%s", OutcomeNack, duration)
+				return err
+			}
+			log.Debugf(ctx, "Event handled: outcome=%s duration=%s", OutcomeAck, duration)
+			return nil
+		}
+	}
+}
+
+// Metrics records the outcome of each handler invocation, labeled by subscription.
+func Metrics(recorder *metrics.Recorder) Middleware {
+	return func(next broker.HandlerFunc) broker.HandlerFunc {
+		return func(ctx context.Context, evt *event.Event) error {
+			err := next(ctx, evt)
+			if err != nil {
+				recorder.RecordHandlerResult(SubscriptionFromContext(ctx), OutcomeNack)
+			} else {
+				recorder.RecordHandlerResult(SubscriptionFromContext(ctx), OutcomeAck)
+			}
+			return err
+		}
+	}
+}
+
+// Heartbeat beats heartbeat for every message delivered by the receive loop,
+// whatever its outcome, so /livez detects a stalled receive loop.
+func Heartbeat(heartbeat *health.Heartbeat) Middleware {
+	return func(next broker.HandlerFunc) broker.HandlerFunc {
+		return func(ctx context.Context, evt *event.Event) error {
+			heartbeat.Beat()
+			return next(ctx, evt)
+		}
+	}
+}
+
+// Tracing extracts the upstream W3C trace context from the CloudEvent and starts
+// a "Receive" span around the handler, so executor spans become its children.
+func Tracing(tracerName string) Middleware {
+	return func(next broker.HandlerFunc) broker.HandlerFunc {
+		return func(ctx context.Context, evt *event.Event) error {
+			ctx = pkgotel.ExtractTraceContextFromCloudEvent(ctx, evt)
+			ctx, span := otel.Tracer(tracerName).Start(ctx, "Receive",
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(
+					attribute.String("cloudevents.event_id", evt.ID()),
+					attribute.String("cloudevents.event_type", evt.Type()),
+					attribute.String("cloudevents.event_source", evt.Source()),
+				),
+			)
+			defer span.End()
+
+			err := next(ctx, evt)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package brokerconsumer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/health"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/openshift-hyperfleet/hyperfleet-broker/broker"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func init() {
+	// Ensure the global propagator is set for tests
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
+
+func findMetricFamily(t *testing.T, registry *prometheus.Registry, name string) *dto.MetricFamily {
+	t.Helper()
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, f := range families {
+		if f.GetName() == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func TestChain_Order(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware {
+		return func(next broker.HandlerFunc) broker.HandlerFunc {
+			return func(ctx context.Context, evt *event.Event) error {
+				order = append(order, name)
+				return next(ctx, evt)
+			}
+		}
+	}
+
+	handler := Chain(func(ctx context.Context, evt *event.Event) error {
+		order = append(order, "handler")
+		return nil
+	}, mw("first"), nil, mw("second"))
+
+	require.NoError(t, handler(context.Background(), newTestEvent("evt-1")))
+	assert.Equal(t, []string{"first", "second", "handler"}, order)
+}
+
+func TestRecoverer_PanicIsCountedAndAcked(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewRecorder("test-adapter", "v0.1.0", registry)
+	log, capture := logger.NewCaptureLogger()
+
+	calls := 0
+	handler := Chain(func(ctx context.Context, evt *event.Event) error {
+		calls++
+		if evt.ID() == "poison" {
+			panic("template function exploded")
+		}
+		return nil
+	}, Recoverer(log, recorder), Metrics(recorder))
+
+	// Panic is recovered and the event is acknowledged (nil error)
+	assert.NoError(t, handler(context.Background(), newTestEvent("poison")))
+	assert.True(t, capture.Contains("template function exploded"))
+	assert.True(t, capture.Contains("stack_trace"))
+
+	// Consumer keeps working after the panic
+	assert.NoError(t, handler(context.Background(), newTestEvent("healthy")))
+	assert.Equal(t, 2, calls)
+
+	panics := findMetricFamily(t, registry, "hyperfleet_adapter_handler_panics_total")
+	require.NotNil(t, panics)
+	assert.Equal(t, float64(1), panics.GetMetric()[0].GetCounter().GetValue())
+}
+
+func TestMetrics_RecordsOutcome(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewRecorder("test-adapter", "v0.1.0", registry)
+
+	failing := Metrics(recorder)(func(ctx context.Context, evt *event.Event) error {
+		return errors.New("boom")
+	})
+	succeeding := Metrics(recorder)(func(ctx context.Context, evt *event.Event) error {
+		return nil
+	})
+
+	assert.Error(t, failing(context.Background(), newTestEvent("evt-1")))
+	assert.NoError(t, succeeding(context.Background(), newTestEvent("evt-2")))
+	assert.NoError(t, succeeding(context.Background(), newTestEvent("evt-3")))
+
+	family := findMetricFamily(t, registry, "hyperfleet_adapter_handler_results_total")
+	require.NotNil(t, family)
+
+	counts := map[string]float64{}
+	for _, m := range family.GetMetric() {
+		for _, l := range m.GetLabel() {
+			if l.GetName() == "outcome" {
+				counts[l.GetValue()] = m.GetCounter().GetValue()
+			}
+		}
+	}
+	assert.Equal(t, float64(2), counts[OutcomeAck])
+	assert.Equal(t, float64(1), counts[OutcomeNack])
+}
+
+func TestLogging_LogsFailure(t *testing.T) {
+	log, capture := logger.NewCaptureLogger()
+
+	handler := Logging(log)(func(ctx context.Context, evt *event.Event) error {
+		return errors.New("downstream unavailable")
+	})
+
+	assert.Error(t, handler(context.Background(), newTestEvent("evt-42")))
+	assert.True(t, capture.Contains("Event handling failed"))
+	assert.True(t, capture.Contains("evt-42"))
+	assert.True(t, capture.Contains("downstream unavailable"))
+}
+
+func TestHeartbeat_BeatsOnEveryMessage(t *testing.T) {
+	heartbeat := health.NewServer(logger.NewTestLogger(), "0", "test-adapter").Heartbeat("broker", time.Minute)
+
+	handler := Heartbeat(heartbeat)(func(ctx context.Context, evt *event.Event) error {
+		return errors.New("downstream unavailable")
+	})
+
+	require.True(t, heartbeat.Last().IsZero())
+	assert.Error(t, handler(context.Background(), newTestEvent("evt-1")))
+	assert.False(t, heartbeat.Last().IsZero(), "failed messages still prove the receive loop is alive")
+}
+
+func TestTracing_StartsSpan(t *testing.T) {
+	var spanCtx trace.SpanContext
+	handler := Tracing("test-adapter")(func(ctx context.Context, evt *event.Event) error {
+		spanCtx = trace.SpanContextFromContext(ctx)
+		return nil
+	})
+
+	evt := newTestEvent("evt-1")
+	evt.SetExtension("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+
+	require.NoError(t, handler(context.Background(), evt))
+	// Without a configured TracerProvider the span is non-recording, but the
+	// upstream trace ID must still be propagated to the handler.
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", spanCtx.TraceID().String())
+}
@@ -0,0 +1,188 @@
+package brokerconsumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/openshift-hyperfleet/hyperfleet-broker/broker"
+)
+
+// DefaultQueueWorkers is the number of queue workers when QueueConfig.Workers is zero
+const DefaultQueueWorkers = 1
+
+// ErrQueueFull is returned for an event rejected because the queue is full
+var ErrQueueFull = errors.New("event queue is full")
+
+// ErrQueueClosed is returned for an event received once the queue is closing
+var ErrQueueClosed = errors.New("event queue is closed")
+
+// QueueConfig configures a Queue
+type QueueConfig struct {
+	// Clock times the wait of queued events (nil uses the real clock)
+	Clock clock.Clock
+	// Depth is the number of events waiting for a worker. Zero disables the queue.
+	Depth int
+	// Workers is the number of events handled at once. Zero uses DefaultQueueWorkers.
+	Workers int
+	// Reject NACKs an event received while the queue is full instead of waiting for room
+	Reject bool
+}
+
+// queueItem is a received event waiting for a worker
+type queueItem struct {
+	ctx        context.Context
+	evt        *event.Event
+	handle     broker.HandlerFunc
+	enqueuedAt time.Time
+	done       chan error
+}
+
+// Queue is a bounded queue between the broker receive loops and a fixed pool of
+// workers invoking the handler, so the receive settings of the subscriptions
+// (flow_control) and the number of concurrent executions are independent.
+//
+// The receive goroutine of an event waits until a worker has handled it and
+// returns its result, so an event is only acknowledged or NACKed once handled.
+// Workers take events in the order they were queued; events serialized by key,
+// e.g. by the executor's execution_fence, wait for their key on a worker.
+type Queue struct {
+	items    chan *queueItem
+	closing  chan struct{}
+	clock    clock.Clock
+	recorder *metrics.Recorder
+	workers  sync.WaitGroup
+	reject   bool
+	closed   bool
+	once     sync.Once
+	mu       sync.RWMutex
+}
+
+// NewQueue creates a queue and starts its workers.
+// Returns nil (no queue) when config.Depth is zero.
+func NewQueue(config QueueConfig, recorder *metrics.Recorder) (*Queue, error) {
+	if config.Depth < 0 {
+		return nil, fmt.Errorf("queue depth must not be negative, got %d", config.Depth)
+	}
+	if config.Workers < 0 {
+		return nil, fmt.Errorf("queue workers must not be negative, got %d", config.Workers)
+	}
+	if config.Depth == 0 {
+		return nil, nil
+	}
+	workers := config.Workers
+	if workers == 0 {
+		workers = DefaultQueueWorkers
+	}
+
+	q := &Queue{
+		items:    make(chan *queueItem, config.Depth),
+		closing:  make(chan struct{}),
+		clock:    clock.OrReal(config.Clock),
+		recorder: recorder,
+		reject:   config.Reject,
+	}
+	for range workers {
+		q.workers.Go(q.work)
+	}
+	return q, nil
+}
+
+// Len returns the number of events waiting for a worker.
+func (q *Queue) Len() int {
+	return len(q.items)
+}
+
+// Middleware returns a middleware that queues each event for a worker, which
+// invokes the rest of the chain, and returns its result once handled. An event
+// that cannot be queued, because the queue is full with Reject set, is closing,
+// or the message context is done while waiting for room, returns an error so
+// the broker NACKs it and redelivers it later.
+// A nil Queue returns a nil middleware, which Chain skips.
+func (q *Queue) Middleware() Middleware {
+	if q == nil {
+		return nil
+	}
+	return func(next broker.HandlerFunc) broker.HandlerFunc {
+		return func(ctx context.Context, evt *event.Event) error {
+			item := &queueItem{ctx: ctx, evt: evt, handle: next, done: make(chan error, 1)}
+			if err := q.enqueue(item); err != nil {
+				return fmt.Errorf("event %s not queued: %w", evt.ID(), err)
+			}
+			return <-item.done
+		}
+	}
+}
+
+// enqueue queues item, waiting for room unless the queue rejects when full
+func (q *Queue) enqueue(item *queueItem) error {
+	// The read lock keeps Close from closing items while an event is sent
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	item.enqueuedAt = q.clock.Now()
+	if q.reject {
+		select {
+		case q.items <- item:
+		default:
+			q.recorder.RecordQueueRejected()
+			return ErrQueueFull
+		}
+	} else {
+		select {
+		case q.items <- item:
+		case <-item.ctx.Done():
+			return item.ctx.Err()
+		case <-q.closing:
+			return ErrQueueClosed
+		}
+	}
+	q.recorder.IncQueueDepth()
+	return nil
+}
+
+// work handles queued events until the queue is closed and empty
+func (q *Queue) work() {
+	for item := range q.items {
+		q.recorder.DecQueueDepth()
+		q.recorder.ObserveQueueWait(q.clock.Since(item.enqueuedAt))
+		item.done <- item.handle(item.ctx, item.evt)
+	}
+}
+
+// Close stops queueing events, NACKing those received from now on, and waits
+// until the workers have handled the queued events or ctx is done.
+// It is a no-op on a nil Queue.
+func (q *Queue) Close(ctx context.Context) error {
+	if q == nil {
+		return nil
+	}
+	q.once.Do(func() {
+		// Release the events waiting for room before taking the write lock
+		close(q.closing)
+		q.mu.Lock()
+		q.closed = true
+		close(q.items)
+		q.mu.Unlock()
+	})
+
+	drained := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("event queue not drained: %d events left: %w", q.Len(), ctx.Err())
+	}
+}
@@ -0,0 +1,296 @@
+package brokerconsumer
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/executor"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingHandler handles events one step at a time: it reports each event it
+// starts on entered and returns once released
+type blockingHandler struct {
+	entered chan string
+	release chan struct{}
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{entered: make(chan string, 16), release: make(chan struct{})}
+}
+
+func (h *blockingHandler) handle(_ context.Context, evt *event.Event) error {
+	h.entered <- evt.ID()
+	<-h.release
+	return nil
+}
+
+// deliver hands evt to handler on its own goroutine, like a receive loop, and
+// returns the channel its outcome is sent on
+func deliver(handler func(context.Context, *event.Event) error, evt *event.Event) <-chan error {
+	outcome := make(chan error, 1)
+	go func() { outcome <- handler(context.Background(), evt) }()
+	return outcome
+}
+
+func TestNewQueue(t *testing.T) {
+	queue, err := NewQueue(QueueConfig{}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, queue, "a zero depth disables the queue")
+	assert.Nil(t, queue.Middleware())
+	assert.NoError(t, queue.Close(context.Background()))
+
+	_, err = NewQueue(QueueConfig{Depth: -1}, nil)
+	assert.Error(t, err)
+	_, err = NewQueue(QueueConfig{Depth: 1, Workers: -1}, nil)
+	assert.Error(t, err)
+}
+
+func TestQueue_AcknowledgesOnlyOnceHandled(t *testing.T) {
+	queue, err := NewQueue(QueueConfig{Depth: 2}, nil)
+	require.NoError(t, err)
+	defer func() { _ = queue.Close(context.Background()) }()
+	h := newBlockingHandler()
+	handler := Chain(h.handle, queue.Middleware())
+
+	outcome := deliver(handler, newTestEvent("evt-1"))
+	assert.Equal(t, "evt-1", <-h.entered)
+	select {
+	case <-outcome:
+		t.Fatal("the event was settled before its worker handled it")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(h.release)
+	assert.NoError(t, <-outcome)
+}
+
+func TestQueue_RejectsWhenFull(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewRecorder("test-adapter", "v0.1.0", registry)
+	queue, err := NewQueue(QueueConfig{Depth: 1, Workers: 1, Reject: true}, recorder)
+	require.NoError(t, err)
+	h := newBlockingHandler()
+	handler := Chain(h.handle, queue.Middleware())
+
+	first := deliver(handler, newTestEvent("evt-1"))
+	require.Equal(t, "evt-1", <-h.entered)
+	second := deliver(handler, newTestEvent("evt-2"))
+	require.Eventually(t, func() bool { return queue.Len() == 1 }, time.Second, time.Millisecond)
+
+	// The worker is busy and the queue full: the third event is NACKed right away
+	err = <-deliver(handler, newTestEvent("evt-3"))
+	require.ErrorIs(t, err, ErrQueueFull)
+	assert.ErrorContains(t, err, "event evt-3 not queued")
+
+	close(h.release)
+	assert.NoError(t, <-first)
+	assert.NoError(t, <-second)
+	assert.Equal(t, "evt-2", <-h.entered)
+	require.NoError(t, queue.Close(context.Background()))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			switch family.GetName() {
+			case "hyperfleet_adapter_queue_rejected_total":
+				values[family.GetName()] = m.GetCounter().GetValue()
+			case "hyperfleet_adapter_queue_depth":
+				values[family.GetName()] = m.GetGauge().GetValue()
+			case "hyperfleet_adapter_queue_wait_duration_seconds":
+				values[family.GetName()] = float64(m.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{
+		"hyperfleet_adapter_queue_rejected_total":        1,
+		"hyperfleet_adapter_queue_depth":                 0,
+		"hyperfleet_adapter_queue_wait_duration_seconds": 2,
+	}, values)
+}
+
+func TestQueue_BlocksWhenFull(t *testing.T) {
+	queue, err := NewQueue(QueueConfig{Depth: 1, Workers: 1}, nil)
+	require.NoError(t, err)
+	h := newBlockingHandler()
+	handler := Chain(h.handle, queue.Middleware())
+
+	first := deliver(handler, newTestEvent("evt-1"))
+	require.Equal(t, "evt-1", <-h.entered)
+	second := deliver(handler, newTestEvent("evt-2"))
+	require.Eventually(t, func() bool { return queue.Len() == 1 }, time.Second, time.Millisecond)
+
+	// The third event waits for room; its receive context ending NACKs it
+	ctx, cancel := context.WithCancel(context.Background())
+	third := make(chan error, 1)
+	go func() { third <- handler(ctx, newTestEvent("evt-3")) }()
+	select {
+	case <-third:
+		t.Fatal("the event was settled while waiting for room")
+	case <-time.After(50 * time.Millisecond):
+	}
+	cancel()
+	assert.ErrorIs(t, <-third, context.Canceled)
+
+	close(h.release)
+	assert.NoError(t, <-first)
+	assert.NoError(t, <-second)
+	require.NoError(t, queue.Close(context.Background()))
+}
+
+func TestQueue_CloseDrainsQueuedEvents(t *testing.T) {
+	queue, err := NewQueue(QueueConfig{Depth: 2, Workers: 1}, nil)
+	require.NoError(t, err)
+	h := newBlockingHandler()
+	handler := Chain(h.handle, queue.Middleware())
+
+	first := deliver(handler, newTestEvent("evt-1"))
+	require.Equal(t, "evt-1", <-h.entered)
+	second := deliver(handler, newTestEvent("evt-2"))
+	require.Eventually(t, func() bool { return queue.Len() == 1 }, time.Second, time.Millisecond)
+	third := deliver(handler, newTestEvent("evt-3"))
+	require.Eventually(t, func() bool { return queue.Len() == 2 }, time.Second, time.Millisecond)
+	// Waiting for room, released by Close
+	fourth := deliver(handler, newTestEvent("evt-4"))
+
+	closed := make(chan error, 1)
+	go func() { closed <- queue.Close(context.Background()) }()
+	assert.ErrorIs(t, <-fourth, ErrQueueClosed)
+	assert.ErrorIs(t, <-deliver(handler, newTestEvent("evt-5")), ErrQueueClosed,
+		"events received once closing are NACKed")
+
+	// Close times out while the worker is busy, then the queued events run down
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorContains(t, queue.Close(timeoutCtx), "event queue not drained: 2 events left")
+	close(h.release)
+	for _, outcome := range []<-chan error{first, second, third} {
+		assert.NoError(t, <-outcome)
+	}
+	assert.Equal(t, []string{"evt-2", "evt-3"}, []string{<-h.entered, <-h.entered})
+	assert.NoError(t, <-closed)
+}
+
+// fenceTransport reports the name of each manifest it starts applying on
+// entered and applies it once released
+type fenceTransport struct {
+	*k8sclient.MockK8sClient
+	entered chan string
+	proceed chan struct{}
+	mu      sync.Mutex
+}
+
+func (f *fenceTransport) ApplyResource(
+	ctx context.Context,
+	manifestBytes []byte,
+	opts *transportclient.ApplyOptions,
+	target transportclient.TransportContext,
+) (*transportclient.ApplyResult, error) {
+	var manifest struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, err
+	}
+	f.entered <- manifest.Metadata.Name
+	<-f.proceed
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.MockK8sClient.ApplyResource(ctx, manifestBytes, opts, target)
+}
+
+func TestQueue_WithExecutionFence(t *testing.T) {
+	transport := &fenceTransport{
+		MockK8sClient: k8sclient.NewMockK8sClient(),
+		entered:       make(chan string),
+		proceed:       make(chan struct{}),
+	}
+	exec, err := executor.NewBuilder().
+		WithConfig(&configloader.Config{
+			Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+			Params: []configloader.Parameter{
+				{Name: "clusterId", Source: "event.id", Required: true},
+				{Name: "step", Source: "event.step", Required: true},
+			},
+			Resources: []configloader.Resource{{
+				Name: "cm",
+				Manifest: map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "ConfigMap",
+					"metadata": map[string]interface{}{
+						"name": "{{ .clusterId }}-{{ .step }}", "namespace": "default",
+					},
+				},
+			}},
+			ExecutionFence: &configloader.ExecutionFence{Key: "{{ .clusterId }}"},
+		}).
+		WithAPIClient(hyperfleetapi.NewMockClient()).
+		WithTransportClient(transport).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+
+	queue, err := NewQueue(QueueConfig{Depth: 4, Workers: 2}, nil)
+	require.NoError(t, err)
+	handler := Chain(exec.CreateHandler(), queue.Middleware())
+	clusterEvent := func(id, clusterID, step string) *event.Event {
+		evt := newTestEvent(id)
+		require.NoError(t, evt.SetData(event.ApplicationJSON, map[string]string{"id": clusterID, "step": step}))
+		return evt
+	}
+
+	var mu sync.Mutex
+	var settled []string
+	var wg sync.WaitGroup
+	receive := func(evt *event.Event) {
+		wg.Go(func() {
+			if err := handler(context.Background(), evt); err == nil {
+				mu.Lock()
+				settled = append(settled, evt.ID())
+				mu.Unlock()
+			}
+		})
+	}
+
+	receive(clusterEvent("evt-1", "cluster-1", "first"))
+	require.Equal(t, "cluster-1-first", <-transport.entered)
+	receive(clusterEvent("evt-2", "cluster-1", "second"))
+	require.Eventually(t, func() bool { return exec.Stats().InFlight == 2 }, 5*time.Second, time.Millisecond)
+	receive(clusterEvent("evt-3", "cluster-2", "first"))
+	require.Eventually(t, func() bool { return queue.Len() == 1 }, 5*time.Second, time.Millisecond)
+
+	// The second cluster-1 event holds the other worker while it waits for its
+	// key, so the cluster-2 event stays queued behind it
+	select {
+	case name := <-transport.entered:
+		t.Fatalf("%s was applied while cluster-1 was held", name)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	transport.proceed <- struct{}{}
+	// Once the first cluster-1 event is done its key and its worker are free: the
+	// second cluster-1 event runs after the first, in the order received
+	second := []string{<-transport.entered, <-transport.entered}
+	assert.ElementsMatch(t, []string{"cluster-1-second", "cluster-2-first"}, second)
+	transport.proceed <- struct{}{}
+	transport.proceed <- struct{}{}
+	wg.Wait()
+	require.NoError(t, queue.Close(context.Background()))
+
+	assert.ElementsMatch(t, []string{"evt-1", "evt-2", "evt-3"}, settled, "every event is acknowledged")
+}
@@ -0,0 +1,114 @@
+// Package brokerconsumer provides wrappers applied around the event handler
+// before it is handed to the broker subscriber.
+package brokerconsumer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/openshift-hyperfleet/hyperfleet-broker/broker"
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter is a token-bucket limiter shared by all subscriber workers.
+// The broker subscriber already bounds concurrency with its worker pool
+// (subscriber parallelism); the limiter additionally bounds the aggregate
+// rate at which those workers invoke the handler.
+type RateLimiter struct {
+	limiter  *rate.Limiter
+	clock    clock.Clock
+	recorder *metrics.Recorder
+	waiting  atomic.Int64
+}
+
+// NewRateLimiter creates a rate limiter allowing eventsPerSecond sustained
+// throughput with the given burst. A burst of zero defaults to
+// ceil(eventsPerSecond), with a minimum of 1.
+// Returns nil (no limiting) when eventsPerSecond is zero.
+func NewRateLimiter(eventsPerSecond float64, burst int, recorder *metrics.Recorder) (*RateLimiter, error) {
+	if eventsPerSecond < 0 {
+		return nil, fmt.Errorf("rate limit events per second must not be negative, got %v", eventsPerSecond)
+	}
+	if burst < 0 {
+		return nil, fmt.Errorf("rate limit burst must not be negative, got %d", burst)
+	}
+	if eventsPerSecond == 0 {
+		return nil, nil
+	}
+	if burst == 0 {
+		burst = int(math.Max(1, math.Ceil(eventsPerSecond)))
+	}
+
+	return &RateLimiter{
+		limiter:  rate.NewLimiter(rate.Limit(eventsPerSecond), burst),
+		clock:    clock.Real,
+		recorder: recorder,
+	}, nil
+}
+
+// WithClock sets the clock the limiter refills and waits on (nil uses the real clock).
+// It is a no-op on a nil RateLimiter.
+func (r *RateLimiter) WithClock(clk clock.Clock) *RateLimiter {
+	if r != nil {
+		r.clock = clock.OrReal(clk)
+	}
+	return r
+}
+
+// Wait blocks until a token is available or ctx is done.
+// Returns ctx.Err() if the context is canceled while waiting; the token
+// reserved for the abandoned wait is returned to the bucket.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	r.waiting.Add(1)
+	r.recorder.IncRateLimitQueueDepth()
+	start := r.clock.Now()
+	defer func() {
+		r.recorder.ObserveRateLimitWait(r.clock.Since(start))
+		r.waiting.Add(-1)
+		r.recorder.DecRateLimitQueueDepth()
+	}()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	reservation := r.limiter.ReserveN(start, 1)
+	if err := r.clock.Sleep(ctx, reservation.DelayFrom(start)); err != nil {
+		reservation.CancelAt(r.clock.Now())
+		return err
+	}
+	return nil
+}
+
+// Waiting returns the number of callers currently blocked in Wait.
+func (r *RateLimiter) Waiting() int {
+	return int(r.waiting.Load())
+}
+
+// Middleware returns a middleware that waits for a token before invoking the handler.
+// The wait is abandoned when either the message context or shutdownCtx is done,
+// so a full bucket never blocks graceful shutdown. An abandoned wait returns an
+// error so the broker NACKs the message and it is redelivered later.
+// A nil RateLimiter returns a nil middleware, which Chain skips.
+func (r *RateLimiter) Middleware(shutdownCtx context.Context) Middleware {
+	if r == nil {
+		return nil
+	}
+	return func(next broker.HandlerFunc) broker.HandlerFunc {
+		return func(ctx context.Context, evt *event.Event) error {
+			waitCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			stop := context.AfterFunc(shutdownCtx, cancel)
+			defer stop()
+
+			if err := r.Wait(waitCtx); err != nil {
+				return fmt.Errorf("rate limiter wait aborted for event %s: %w", evt.ID(), err)
+			}
+			return next(ctx, evt)
+		}
+	}
+}
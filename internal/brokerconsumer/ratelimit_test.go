@@ -0,0 +1,179 @@
+package brokerconsumer
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEvent(id string) *event.Event {
+	evt := event.New()
+	evt.SetID(id)
+	evt.SetType("com.redhat.hyperfleet.cluster.reconcile")
+	evt.SetSource("test")
+	return &evt
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	tests := []struct {
+		name            string
+		eventsPerSecond float64
+		burst           int
+		expectNil       bool
+		expectError     bool
+		expectedBurst   int
+	}{
+		{name: "zero disables", eventsPerSecond: 0, burst: 10, expectNil: true},
+		{name: "negative rate", eventsPerSecond: -1, expectError: true},
+		{name: "negative burst", eventsPerSecond: 1, burst: -1, expectError: true},
+		{name: "explicit burst", eventsPerSecond: 5, burst: 20, expectedBurst: 20},
+		{name: "default burst", eventsPerSecond: 2.5, expectedBurst: 3},
+		{name: "default burst minimum", eventsPerSecond: 0.1, expectedBurst: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl, err := NewRateLimiter(tt.eventsPerSecond, tt.burst, nil)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.expectNil {
+				assert.Nil(t, rl)
+				return
+			}
+			require.NotNil(t, rl)
+			assert.Equal(t, tt.expectedBurst, rl.limiter.Burst())
+		})
+	}
+}
+
+func TestRateLimiter_NilMiddlewareIsSkipped(t *testing.T) {
+	var rl *RateLimiter
+	assert.Nil(t, rl.Middleware(context.Background()))
+
+	called := false
+	handler := Chain(func(ctx context.Context, evt *event.Event) error {
+		called = true
+		return nil
+	}, rl.Middleware(context.Background()))
+
+	require.NoError(t, handler(context.Background(), newTestEvent("evt-1")))
+	assert.True(t, called)
+}
+
+func TestRateLimiter_MiddlewareThrottles(t *testing.T) {
+	rl, err := NewRateLimiter(20, 1, nil)
+	require.NoError(t, err)
+
+	var calls atomic.Int32
+	handler := rl.Middleware(context.Background())(func(ctx context.Context, evt *event.Event) error {
+		calls.Add(1)
+		return nil
+	})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, handler(context.Background(), newTestEvent("evt")))
+	}
+
+	// First event uses the burst token, the next two wait ~50ms each
+	assert.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestRateLimiter_FakeClock(t *testing.T) {
+	rl, err := NewRateLimiter(1, 1, nil)
+	require.NoError(t, err)
+	fake := clock.NewFake(time.Now())
+	rl.WithClock(fake)
+	ctx := context.Background()
+
+	require.NoError(t, rl.Wait(ctx), "the burst token is available immediately")
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- rl.Wait(ctx) }()
+	fake.BlockUntil(1)
+	fake.Advance(500 * time.Millisecond)
+	assert.Equal(t, 1, rl.Waiting(), "half a token is not enough")
+	fake.Advance(500 * time.Millisecond)
+	require.NoError(t, <-errCh)
+
+	// An abandoned wait returns its token
+	waitCtx, cancel := context.WithCancel(ctx)
+	go func() { errCh <- rl.Wait(waitCtx) }()
+	fake.BlockUntil(1)
+	cancel()
+	assert.ErrorIs(t, <-errCh, context.Canceled)
+	fake.Advance(time.Second)
+	require.NoError(t, rl.Wait(ctx))
+}
+
+func TestRateLimiter_ShutdownAbortsWait(t *testing.T) {
+	rl, err := NewRateLimiter(0.01, 1, nil)
+	require.NoError(t, err)
+
+	shutdownCtx, shutdown := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	handler := rl.Middleware(shutdownCtx)(func(ctx context.Context, evt *event.Event) error {
+		calls.Add(1)
+		return nil
+	})
+
+	// Drain the bucket
+	require.NoError(t, handler(context.Background(), newTestEvent("evt-1")))
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- handler(context.Background(), newTestEvent("evt-2"))
+	}()
+
+	require.Eventually(t, func() bool { return rl.Waiting() == 1 }, time.Second, 5*time.Millisecond)
+	shutdown()
+
+	select {
+	case err := <-errCh:
+		assert.Error(t, err, "aborted wait should NACK the event")
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(2 * time.Second):
+		t.Fatal("shutdown did not abort the rate limiter wait")
+	}
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, 0, rl.Waiting())
+}
+
+func TestRateLimiter_RecordsMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewRecorder("test-adapter", "v0.1.0", registry)
+
+	rl, err := NewRateLimiter(100, 1, recorder)
+	require.NoError(t, err)
+
+	require.NoError(t, rl.Wait(context.Background()))
+	require.NoError(t, rl.Wait(context.Background()))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	found := map[string]bool{}
+	for _, f := range families {
+		switch f.GetName() {
+		case "hyperfleet_adapter_rate_limit_wait_duration_seconds":
+			found[f.GetName()] = true
+			assert.Equal(t, uint64(2), f.GetMetric()[0].GetHistogram().GetSampleCount())
+		case "hyperfleet_adapter_rate_limit_queue_depth":
+			found[f.GetName()] = true
+			assert.Equal(t, float64(0), f.GetMetric()[0].GetGauge().GetValue())
+		}
+	}
+	assert.Len(t, found, 2)
+}
@@ -0,0 +1,90 @@
+package brokerconsumer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/delivery"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/lru"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/openshift-hyperfleet/hyperfleet-broker/broker"
+)
+
+// RedeliveryUnknown labels the redeliveries whose handler error tells no phase
+// or error code
+const RedeliveryUnknown = "unknown"
+
+// redeliveryTrackedEvents bounds the event IDs whose deliveries are counted
+// locally: the events being handled and those NACKed, until they are handled
+// successfully. Beyond it the least recently delivered are forgotten and their
+// next delivery counts as the first.
+const redeliveryTrackedEvents = 10000
+
+// FailureCause is implemented by the handler errors that tell the execution
+// phase and the error code an event failed with, e.g. those of the executor
+type FailureCause interface {
+	FailurePhase() string
+	FailureCode() string
+}
+
+// RedeliveryConfig configures the Redeliveries middleware
+type RedeliveryConfig struct {
+	// WarnAttempts is the delivery attempt from which a NACK is logged as a
+	// warning with its cause. Zero disables the warning.
+	WarnAttempts int
+}
+
+// Redeliveries adds the delivery attempt of each event to its context (see
+// delivery.AttemptFromContext) and to its log fields, and counts the NACKed
+// events by the phase and error code of the FailureCause of the handler error,
+// "unknown" without one. The attempt is the one reported by the broker backend
+// when it reports one. Otherwise the deliveries of each event ID are counted
+// here, until the event is handled successfully: only the deliveries to this
+// process are counted, so an event redelivered to other replicas, or before a
+// restart, has a lower attempt than its broker delivery attempt. A NACK on a
+// delivery attempt of at least config.WarnAttempts is logged as a warning
+// summarizing the cause, ahead of the dead letter policy of the broker giving
+// up on the event.
+func Redeliveries(config RedeliveryConfig, log logger.Logger, recorder *metrics.Recorder) Middleware {
+	deliveries := lru.New[string, int](redeliveryTrackedEvents)
+	return func(next broker.HandlerFunc) broker.HandlerFunc {
+		return func(ctx context.Context, evt *event.Event) error {
+			attempt := delivery.AttemptFromContext(ctx)
+			counted := attempt == delivery.AttemptUnknown
+			if counted {
+				attempt = deliveries.Update(evt.ID(), func(previous int, _ bool) int { return previous + 1 })
+				ctx = delivery.WithAttempt(ctx, attempt)
+			}
+			ctx = logger.WithDeliveryAttempt(ctx, delivery.FormatAttempt(attempt))
+			err := next(ctx, evt)
+			if err == nil {
+				if counted {
+					deliveries.Remove(evt.ID())
+				}
+				return nil
+			}
+
+			phase, code := RedeliveryUnknown, RedeliveryUnknown
+			var cause FailureCause
+			if errors.As(err, &cause) {
+				phase, code = orUnknown(cause.FailurePhase()), orUnknown(cause.FailureCode())
+			}
+			recorder.RecordEventRedelivery(phase, code)
+			if config.WarnAttempts > 0 && attempt >= config.WarnAttempts {
+				errCtx := logger.WithErrorField(ctx, err)
+				log.Warnf(errCtx, "Event %s NACKed on delivery attempt %d (warning from attempt %d): phase=%s error_code=%s",
+					evt.ID(), attempt, config.WarnAttempts, phase, code)
+			}
+			return err
+		}
+	}
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return RedeliveryUnknown
+	}
+	return value
+}
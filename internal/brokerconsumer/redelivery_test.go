@@ -0,0 +1,141 @@
+package brokerconsumer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/delivery"
+	apierrors "github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/errors"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/openshift-hyperfleet/hyperfleet-broker/broker"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testFailure is a handler error telling its phase and error code
+type testFailure struct {
+	phase string
+	code  string
+}
+
+func (f *testFailure) Error() string        { return f.code + " in " + f.phase }
+func (f *testFailure) FailurePhase() string { return f.phase }
+func (f *testFailure) FailureCode() string  { return f.code }
+
+func TestRedeliveries_ThroughInMemoryBroker(t *testing.T) {
+	memory := newMemoryBroker()
+	memory.maxDeliveries = 3
+	factory := func(SubscriptionSpec) (broker.Subscriber, error) { return memory.Subscriber(), nil }
+	registry := metrics.NewTestRegistry()
+	recorder := metrics.NewRecorder("test-adapter", "v0.1.0", registry)
+	log, capture := logger.NewCaptureLogger()
+	group, err := NewSubscriptionGroup(testSpecs, factory, true, logger.NewTestLogger(), recorder, nil)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	attempts := make(map[string][]int)
+	handler := Chain(func(ctx context.Context, evt *event.Event) error {
+		mu.Lock()
+		attempts[evt.ID()] = append(attempts[evt.ID()], delivery.AttemptFromContext(ctx))
+		mu.Unlock()
+		switch evt.ID() {
+		case "api-down":
+			return apierrors.NewRetryAfterError(0, &testFailure{phase: "preconditions", code: "APICallFailed"})
+		case "apply-conflict":
+			if delivery.AttemptFromContext(ctx) < 2 {
+				return &testFailure{phase: "resources", code: "ApplyConflict"}
+			}
+		case "rejected":
+			return errors.New("queue full")
+		}
+		return nil
+	}, Redeliveries(RedeliveryConfig{WarnAttempts: 3}, log, recorder))
+	require.NoError(t, group.Start(context.Background(), handler))
+	defer group.Close(context.Background()) //nolint:errcheck // test cleanup
+
+	for _, id := range []string{"api-down", "apply-conflict", "rejected", "healthy"} {
+		require.NoError(t, memory.Publish(context.Background(), "clusters", newTestEvent(id)))
+	}
+	memory.settle()
+
+	mu.Lock()
+	assert.Equal(t, []int{1, 2, 3}, attempts["api-down"])
+	assert.Equal(t, []int{1, 2}, attempts["apply-conflict"])
+	assert.Equal(t, []int{1}, attempts["healthy"])
+	mu.Unlock()
+
+	redeliveries := findMetricFamily(t, registry, "hyperfleet_adapter_event_redeliveries_total")
+	require.NotNil(t, redeliveries)
+	counts := make(map[string]float64)
+	for _, m := range redeliveries.GetMetric() {
+		labels := make(map[string]string)
+		for _, label := range m.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		counts[labels["phase"]+"/"+labels["error_code"]] = m.GetCounter().GetValue()
+	}
+	assert.Equal(t, map[string]float64{
+		"preconditions/APICallFailed": 3,
+		"resources/ApplyConflict":     1,
+		"unknown/unknown":             3,
+	}, counts)
+
+	warnings := 0
+	for _, line := range strings.Split(capture.Messages(), "\n") {
+		if strings.Contains(line, "NACKed on delivery attempt 3") {
+			warnings++
+		}
+	}
+	assert.Equal(t, 2, warnings, "the NACKs from the third attempt are warned about")
+	assert.True(t, capture.Contains("phase=preconditions error_code=APICallFailed"))
+	assert.True(t, capture.Contains("delivery_attempt=3"))
+}
+
+func TestRedeliveries_CountsAttempts(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewRecorder("test-adapter", "v0.1.0", registry)
+	log, capture := logger.NewCaptureLogger()
+	var attempts []int
+	fail := true
+	handler := Chain(func(ctx context.Context, evt *event.Event) error {
+		attempts = append(attempts, delivery.AttemptFromContext(ctx))
+		log.Infof(ctx, "handling")
+		if !fail {
+			return nil
+		}
+		return apierrors.NewRetryAfterError(time.Second, &testFailure{phase: "post_actions"})
+	}, Redeliveries(RedeliveryConfig{WarnAttempts: 2}, log, recorder))
+
+	require.Error(t, handler(context.Background(), newTestEvent("evt-1")))
+	assert.True(t, capture.Contains("delivery_attempt=1"))
+	assert.False(t, capture.Contains("NACKed on delivery attempt"), "the first attempt is not warned about")
+
+	require.Error(t, handler(context.Background(), newTestEvent("evt-1")))
+	assert.True(t, capture.Contains("NACKed on delivery attempt 2"))
+
+	fail = false
+	require.NoError(t, handler(context.Background(), newTestEvent("evt-1")))
+	require.NoError(t, handler(context.Background(), newTestEvent("evt-1")))
+	require.NoError(t, handler(delivery.WithAttempt(context.Background(), 5), newTestEvent("evt-2")))
+	assert.Equal(t, []int{1, 2, 3, 1, 5}, attempts,
+		"the count restarts after a success and an attempt reported by the backend is kept")
+
+	redeliveries := findMetricFamily(t, registry, "hyperfleet_adapter_event_redeliveries_total")
+	require.NotNil(t, redeliveries)
+	require.Len(t, redeliveries.GetMetric(), 1)
+	for _, label := range redeliveries.GetMetric()[0].GetLabel() {
+		switch label.GetName() {
+		case "phase":
+			assert.Equal(t, "post_actions", label.GetValue())
+		case "error_code":
+			assert.Equal(t, RedeliveryUnknown, label.GetValue(), "an empty error code is unknown")
+		}
+	}
+}
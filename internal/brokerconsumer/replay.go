@@ -0,0 +1,249 @@
+package brokerconsumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"cloud.google.com/go/pubsub/v2/apiv1/pubsubpb"
+	"github.com/googleapis/gax-go/v2"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrReplayNotConfirmed is returned when a replay is requested without explicit confirmation.
+// Seeking a subscription affects every consumer sharing it, not just this adapter instance.
+var ErrReplayNotConfirmed = errors.New(
+	"replay seeks the subscription for all of its consumers; pass --confirm-replay to proceed")
+
+// snapshotNameRegex matches Pub/Sub snapshot IDs: start with a letter, 3-255 characters
+var snapshotNameRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9\-_.~+%]{2,254}$`)
+
+// ReplayTarget is the point a subscription is seeked to before receiving.
+// Exactly one of Time or Snapshot is set.
+type ReplayTarget struct {
+	Time     time.Time
+	Snapshot string
+}
+
+// String returns a human readable description of the target
+func (t ReplayTarget) String() string {
+	if t.Snapshot != "" {
+		return "snapshot " + t.Snapshot
+	}
+	return "time " + t.Time.UTC().Format(time.RFC3339)
+}
+
+// ParseReplayFrom parses a --replay-from value: an RFC3339 timestamp
+// (e.g. 2024-06-01T10:00:00Z) or a snapshot ID / full snapshot resource name.
+// Timestamps in the future are rejected.
+func ParseReplayFrom(value string, now time.Time) (ReplayTarget, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ReplayTarget{}, fmt.Errorf("replay target is empty")
+	}
+
+	if ts, err := time.Parse(time.RFC3339, value); err == nil {
+		if ts.After(now) {
+			return ReplayTarget{}, fmt.Errorf("replay time %s is in the future", value)
+		}
+		return ReplayTarget{Time: ts}, nil
+	}
+
+	snapshotID := value
+	if strings.HasPrefix(value, "projects/") {
+		parts := strings.Split(value, "/")
+		if len(parts) != 4 || parts[2] != "snapshots" {
+			return ReplayTarget{}, fmt.Errorf(
+				"invalid snapshot resource name %q (expected projects/<project>/snapshots/<name>)", value)
+		}
+		snapshotID = parts[3]
+	}
+	if !snapshotNameRegex.MatchString(snapshotID) {
+		return ReplayTarget{}, fmt.Errorf(
+			"invalid replay target %q: must be an RFC3339 timestamp or a snapshot name", value)
+	}
+	return ReplayTarget{Snapshot: value}, nil
+}
+
+// SubscriptionAdmin is the subset of the Pub/Sub subscription admin API used for replay.
+// Implemented by *pubsub.Client.SubscriptionAdminClient.
+type SubscriptionAdmin interface {
+	Seek(ctx context.Context, req *pubsubpb.SeekRequest, opts ...gax.CallOption) (*pubsubpb.SeekResponse, error)
+	CreateSnapshot(
+		ctx context.Context, req *pubsubpb.CreateSnapshotRequest, opts ...gax.CallOption,
+	) (*pubsubpb.Snapshot, error)
+}
+
+// Replayer seeks a Pub/Sub subscription and creates snapshots for later replay.
+type Replayer struct {
+	admin          SubscriptionAdmin
+	log            logger.Logger
+	projectID      string
+	subscriptionID string
+}
+
+// NewReplayer creates a Replayer for the given subscription
+func NewReplayer(admin SubscriptionAdmin, projectID, subscriptionID string, log logger.Logger) (*Replayer, error) {
+	if admin == nil {
+		return nil, fmt.Errorf("subscription admin client is required")
+	}
+	if projectID == "" {
+		return nil, fmt.Errorf("pub/sub project ID is required for replay")
+	}
+	if subscriptionID == "" {
+		return nil, fmt.Errorf("subscription ID is required for replay")
+	}
+	return &Replayer{
+		admin:          admin,
+		log:            log,
+		projectID:      projectID,
+		subscriptionID: subscriptionID,
+	}, nil
+}
+
+// NewPubSubReplayer creates a Replayer backed by a real Pub/Sub client.
+// The returned close function releases the client.
+func NewPubSubReplayer(
+	ctx context.Context, projectID, subscriptionID string, log logger.Logger,
+) (*Replayer, func() error, error) {
+	client, err := pubsub.NewClient(ctx, projectID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create pub/sub client: %w", err)
+	}
+	replayer, err := NewReplayer(client.SubscriptionAdminClient, projectID, subscriptionID, log)
+	if err != nil {
+		_ = client.Close() //nolint:errcheck // best-effort cleanup on construction failure
+		return nil, nil, err
+	}
+	return replayer, client.Close, nil
+}
+
+// Seek moves the subscription to the replay target. Messages published after the
+// target time (or retained in the snapshot) are redelivered, including ones already
+// acknowledged. Refuses to run unless confirmed is true.
+func (r *Replayer) Seek(ctx context.Context, target ReplayTarget, confirmed bool) error {
+	if !confirmed {
+		return ErrReplayNotConfirmed
+	}
+
+	req := &pubsubpb.SeekRequest{Subscription: r.subscriptionName()}
+	if target.Snapshot != "" {
+		req.Target = &pubsubpb.SeekRequest_Snapshot{Snapshot: r.snapshotName(target.Snapshot)}
+	} else {
+		req.Target = &pubsubpb.SeekRequest_Time{Time: timestamppb.New(target.Time)}
+	}
+
+	r.log.Warnf(ctx, "Seeking subscription %s to %s; all consumers of this subscription will receive replayed events",
+		req.Subscription, target)
+	if _, err := r.admin.Seek(ctx, req); err != nil {
+		return fmt.Errorf("failed to seek subscription %s to %s: %w", req.Subscription, target, err)
+	}
+	r.log.Infof(ctx, "Subscription %s seeked to %s", req.Subscription, target)
+	return nil
+}
+
+// CreateSnapshot snapshots the subscription's current acknowledgement state so it can
+// be replayed later with --replay-from=<name>. Returns the full snapshot resource name.
+func (r *Replayer) CreateSnapshot(ctx context.Context, name string) (string, error) {
+	if !snapshotNameRegex.MatchString(name) {
+		return "", fmt.Errorf("invalid snapshot name %q", name)
+	}
+	snapshot, err := r.admin.CreateSnapshot(ctx, &pubsubpb.CreateSnapshotRequest{
+		Name:         r.snapshotName(name),
+		Subscription: r.subscriptionName(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create snapshot %s: %w", name, err)
+	}
+	r.log.Infof(ctx, "Created snapshot %s of subscription %s", snapshot.GetName(), r.subscriptionName())
+	return snapshot.GetName(), nil
+}
+
+// SnapshotHandler returns an admin HTTP handler creating a snapshot on demand:
+// POST ?name=<snapshot>. Responds with the created snapshot name as JSON.
+func (r *Replayer) SnapshotHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if req.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "method not allowed"}) //nolint:errcheck
+			return
+		}
+
+		name := req.URL.Query().Get("name")
+		if name == "" {
+			name = "adapter-" + time.Now().UTC().Format("20060102-150405")
+		}
+
+		snapshot, err := r.CreateSnapshot(req.Context(), name)
+		if err != nil {
+			errCtx := logger.WithErrorField(req.Context(), err)
+			r.log.Errorf(errCtx, "Failed to create snapshot")
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()}) //nolint:errcheck
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"snapshot": snapshot}) //nolint:errcheck
+	}
+}
+
+func (r *Replayer) subscriptionName() string {
+	return fmt.Sprintf("projects/%s/subscriptions/%s", r.projectID, r.subscriptionID)
+}
+
+func (r *Replayer) snapshotName(name string) string {
+	if strings.HasPrefix(name, "projects/") {
+		return name
+	}
+	return fmt.Sprintf("projects/%s/snapshots/%s", r.projectID, name)
+}
+
+// BrokerPubSubProjectID resolves the Pub/Sub project ID the broker library uses:
+// the BROKER_GOOGLEPUBSUB_PROJECT_ID env var, falling back to the broker config
+// file referenced by BROKER_CONFIG_FILE (or ./broker.yaml).
+// Returns an error if the broker is not configured for Google Pub/Sub.
+func BrokerPubSubProjectID() (string, error) {
+	var fileConfig struct {
+		Broker struct {
+			Type         string `yaml:"type"`
+			GooglePubSub struct {
+				ProjectID string `yaml:"project_id"`
+			} `yaml:"googlepubsub"`
+		} `yaml:"broker"`
+	}
+
+	configFile := brokerConfigFile()
+	if data, err := os.ReadFile(filepath.Clean(configFile)); err == nil {
+		if err := yaml.Unmarshal(data, &fileConfig); err != nil {
+			return "", fmt.Errorf("failed to parse broker config %s: %w", configFile, err)
+		}
+	}
+
+	brokerType := fileConfig.Broker.Type
+	if env := os.Getenv("BROKER_TYPE"); env != "" {
+		brokerType = env
+	}
+	if brokerType != "googlepubsub" {
+		return "", fmt.Errorf("replay is only supported for the googlepubsub broker (configured: %q)", brokerType)
+	}
+
+	projectID := fileConfig.Broker.GooglePubSub.ProjectID
+	if env := os.Getenv("BROKER_GOOGLEPUBSUB_PROJECT_ID"); env != "" {
+		projectID = env
+	}
+	if projectID == "" {
+		return "", fmt.Errorf("googlepubsub project_id is not configured")
+	}
+	return projectID, nil
+}
@@ -0,0 +1,162 @@
+package brokerconsumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2/apiv1/pubsubpb"
+	"github.com/googleapis/gax-go/v2"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSubscriptionAdmin records admin API requests
+type fakeSubscriptionAdmin struct {
+	err       error
+	seeks     []*pubsubpb.SeekRequest
+	snapshots []*pubsubpb.CreateSnapshotRequest
+}
+
+func (f *fakeSubscriptionAdmin) Seek(
+	_ context.Context, req *pubsubpb.SeekRequest, _ ...gax.CallOption,
+) (*pubsubpb.SeekResponse, error) {
+	f.seeks = append(f.seeks, req)
+	return &pubsubpb.SeekResponse{}, f.err
+}
+
+func (f *fakeSubscriptionAdmin) CreateSnapshot(
+	_ context.Context, req *pubsubpb.CreateSnapshotRequest, _ ...gax.CallOption,
+) (*pubsubpb.Snapshot, error) {
+	f.snapshots = append(f.snapshots, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &pubsubpb.Snapshot{Name: req.GetName()}, nil
+}
+
+func newTestReplayer(t *testing.T, admin SubscriptionAdmin) *Replayer {
+	t.Helper()
+	r, err := NewReplayer(admin, "my-project", "my-sub", logger.NewTestLogger())
+	require.NoError(t, err)
+	return r
+}
+
+func TestParseReplayFrom(t *testing.T) {
+	now := time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		value        string
+		wantTime     time.Time
+		wantSnapshot string
+		wantErr      bool
+	}{
+		{name: "utc timestamp", value: "2024-06-01T10:00:00Z", wantTime: time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)},
+		{name: "offset timestamp", value: "2024-06-01T12:00:00+02:00",
+			wantTime: time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)},
+		{name: "snapshot name", value: "before-migration", wantSnapshot: "before-migration"},
+		{name: "snapshot resource name", value: "projects/p/snapshots/snap-1",
+			wantSnapshot: "projects/p/snapshots/snap-1"},
+		{name: "future timestamp", value: "2024-06-03T00:00:00Z", wantErr: true},
+		{name: "date without time", value: "2024-06-01", wantErr: true},
+		{name: "empty", value: "  ", wantErr: true},
+		{name: "invalid resource name", value: "projects/p/subscriptions/s", wantErr: true},
+		{name: "invalid snapshot name", value: "1bad name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := ParseReplayFrom(tt.value, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.wantTime.Equal(target.Time), "got time %s", target.Time)
+			assert.Equal(t, tt.wantSnapshot, target.Snapshot)
+		})
+	}
+}
+
+func TestReplayer_SeekRequiresConfirmation(t *testing.T) {
+	admin := &fakeSubscriptionAdmin{}
+	r := newTestReplayer(t, admin)
+
+	err := r.Seek(context.Background(), ReplayTarget{Snapshot: "snap"}, false)
+	assert.ErrorIs(t, err, ErrReplayNotConfirmed)
+	assert.Empty(t, admin.seeks, "admin API must not be called without confirmation")
+}
+
+func TestReplayer_Seek(t *testing.T) {
+	admin := &fakeSubscriptionAdmin{}
+	r := newTestReplayer(t, admin)
+	ts := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	require.NoError(t, r.Seek(context.Background(), ReplayTarget{Time: ts}, true))
+	require.NoError(t, r.Seek(context.Background(), ReplayTarget{Snapshot: "snap"}, true))
+
+	require.Len(t, admin.seeks, 2)
+	assert.Equal(t, "projects/my-project/subscriptions/my-sub", admin.seeks[0].GetSubscription())
+	assert.True(t, ts.Equal(admin.seeks[0].GetTime().AsTime()))
+	assert.Equal(t, "projects/my-project/snapshots/snap", admin.seeks[1].GetSnapshot())
+
+	admin.err = errors.New("permission denied")
+	assert.Error(t, r.Seek(context.Background(), ReplayTarget{Time: ts}, true))
+}
+
+func TestReplayer_SnapshotHandler(t *testing.T) {
+	admin := &fakeSubscriptionAdmin{}
+	handler := newTestReplayer(t, admin).SnapshotHandler()
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/admin/snapshot", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodPost, "/admin/snapshot?name=pre-upgrade", nil))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "projects/my-project/snapshots/pre-upgrade", body["snapshot"])
+	require.Len(t, admin.snapshots, 1)
+	assert.Equal(t, "projects/my-project/subscriptions/my-sub", admin.snapshots[0].GetSubscription())
+
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodPost, "/admin/snapshot?name=1bad", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestBrokerPubSubProjectID(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "broker.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`broker:
+  type: googlepubsub
+  googlepubsub:
+    project_id: file-project
+`), 0o600))
+
+	t.Setenv("BROKER_CONFIG_FILE", configFile)
+	t.Setenv("BROKER_TYPE", "")
+	t.Setenv("BROKER_GOOGLEPUBSUB_PROJECT_ID", "")
+
+	projectID, err := BrokerPubSubProjectID()
+	require.NoError(t, err)
+	assert.Equal(t, "file-project", projectID)
+
+	t.Setenv("BROKER_GOOGLEPUBSUB_PROJECT_ID", "env-project")
+	projectID, err = BrokerPubSubProjectID()
+	require.NoError(t, err)
+	assert.Equal(t, "env-project", projectID)
+
+	t.Setenv("BROKER_TYPE", "rabbitmq")
+	_, err = BrokerPubSubProjectID()
+	assert.Error(t, err)
+}
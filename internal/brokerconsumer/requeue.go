@@ -0,0 +1,110 @@
+package brokerconsumer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	apierrors "github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/errors"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/openshift-hyperfleet/hyperfleet-broker/broker"
+)
+
+// DefaultRequeueMaxDelay caps the redelivery delay a handler can request
+const DefaultRequeueMaxDelay = configloader.DefaultRequeueMaxDelay
+
+// DefaultRequeueMaxHeld bounds the events of a subscription held at once
+const DefaultRequeueMaxHeld = 1
+
+// RequeueConfig configures the Requeue middleware
+type RequeueConfig struct {
+	// Clock times the delay (nil uses the real clock)
+	Clock clock.Clock
+	// MaxDelay caps the requested delay. Zero uses DefaultRequeueMaxDelay.
+	MaxDelay time.Duration
+	// MaxHeld bounds the events of a subscription held at once. Zero uses
+	// DefaultRequeueMaxHeld.
+	MaxHeld int
+}
+
+// Requeue delays the NACK of events whose handler returned a RetryAfterError,
+// so the broker redelivers them no earlier than the requested delay instead of
+// immediately.
+//
+// hyperfleet-broker exposes no per-message redelivery delay for any backend, so
+// the delay is emulated by holding the message: the middleware waits for the
+// requested delay, capped at MaxDelay, and then returns the error. Google Pub/Sub
+// keeps extending the ack deadline of a held message and redelivers it as soon
+// as it is NACKed; RabbitMQ holds the unacknowledged delivery the same way.
+// A held message occupies a handler worker for the whole delay, so at most
+// MaxHeld messages of a subscription are held at once; the others are NACKed
+// right away and redelivered without delay, leaving workers for new events.
+//
+// The wait is abandoned when the message context or shutdownCtx is done, so a
+// held message never delays shutdown; it is NACKed right away instead.
+func Requeue(
+	shutdownCtx context.Context, config RequeueConfig, log logger.Logger, recorder *metrics.Recorder,
+) Middleware {
+	clk := clock.OrReal(config.Clock)
+	maxDelay := config.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultRequeueMaxDelay
+	}
+	maxHeld := config.MaxHeld
+	if maxHeld <= 0 {
+		maxHeld = DefaultRequeueMaxHeld
+	}
+	var mu sync.Mutex
+	held := make(map[string]int)
+
+	return func(next broker.HandlerFunc) broker.HandlerFunc {
+		return func(ctx context.Context, evt *event.Event) error {
+			err := next(ctx, evt)
+			retryErr, ok := apierrors.IsRetryAfterError(err)
+			if !ok || retryErr.Delay <= 0 {
+				return err
+			}
+
+			subscription := SubscriptionFromContext(ctx)
+			mu.Lock()
+			hold := held[subscription] < maxHeld
+			if hold {
+				held[subscription]++
+			}
+			mu.Unlock()
+			if !hold {
+				recorder.RecordRequeueHoldLimit(subscription)
+				log.Infof(ctx, "Requeueing event %s without delay: %d events of the subscription are already held",
+					evt.ID(), maxHeld)
+				return err
+			}
+			defer func() {
+				mu.Lock()
+				held[subscription]--
+				mu.Unlock()
+			}()
+
+			delay := min(retryErr.Delay, maxDelay)
+			capped := retryErr.Delay > maxDelay
+			recorder.ObserveRequeueDelay(subscription, delay, capped)
+			if capped {
+				log.Infof(ctx, "Requeueing event %s in %s (requested %s, capped)", evt.ID(), delay, retryErr.Delay)
+			} else {
+				log.Infof(ctx, "Requeueing event %s in %s", evt.ID(), delay)
+			}
+
+			waitCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			stop := context.AfterFunc(shutdownCtx, cancel)
+			defer stop()
+			if sleepErr := clk.Sleep(waitCtx, delay); sleepErr != nil {
+				log.Infof(ctx, "Requeue delay of event %s interrupted, redelivering it now", evt.ID())
+			}
+			return err
+		}
+	}
+}
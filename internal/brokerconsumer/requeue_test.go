@@ -0,0 +1,147 @@
+package brokerconsumer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	apierrors "github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/errors"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// returning returns a handler that always returns err
+func returning(err error) func(ctx context.Context, evt *event.Event) error {
+	return func(ctx context.Context, evt *event.Event) error { return err }
+}
+
+func TestRequeue_HoldsForTheRequestedDelay(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewRecorder("test-adapter", "v0.1.0", registry)
+	retryErr := apierrors.NewRetryAfterError(30*time.Second, errors.New("upstream not ready"))
+
+	handler := Chain(returning(retryErr),
+		Subscription("cluster-events"),
+		Requeue(context.Background(), RequeueConfig{Clock: fake}, logger.NewTestLogger(), recorder))
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- handler(context.Background(), newTestEvent("evt-1")) }()
+
+	fake.BlockUntil(1)
+	fake.Advance(29 * time.Second)
+	select {
+	case <-errCh:
+		t.Fatal("the event was NACKed before the requested delay")
+	default:
+	}
+	fake.Advance(time.Second)
+	assert.ErrorIs(t, <-errCh, retryErr, "the handler error is returned so the broker NACKs the event")
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var observed bool
+	for _, f := range families {
+		if f.GetName() != "hyperfleet_adapter_requeue_delay_seconds" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			assert.Equal(t, uint64(1), m.GetHistogram().GetSampleCount())
+			assert.Equal(t, float64(30), m.GetHistogram().GetSampleSum())
+			observed = true
+		}
+	}
+	assert.True(t, observed, "the requeue delay should be observed")
+}
+
+func TestRequeue_CapsTheDelay(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	handler := Requeue(context.Background(), RequeueConfig{Clock: fake, MaxDelay: time.Minute},
+		logger.NewTestLogger(), nil)(returning(apierrors.NewRetryAfterError(time.Hour, nil)))
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- handler(context.Background(), newTestEvent("evt-1")) }()
+
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	_, ok := apierrors.IsRetryAfterError(<-errCh)
+	assert.True(t, ok)
+}
+
+func TestRequeue_PassesOtherOutcomesThrough(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	requeue := Requeue(context.Background(), RequeueConfig{Clock: fake}, logger.NewTestLogger(), nil)
+	plainErr := errors.New("boom")
+
+	assert.NoError(t, requeue(returning(nil))(context.Background(), newTestEvent("evt-1")))
+	assert.ErrorIs(t, requeue(returning(plainErr))(context.Background(), newTestEvent("evt-2")), plainErr)
+	assert.Error(t, requeue(returning(apierrors.NewRetryAfterError(0, plainErr)))(
+		context.Background(), newTestEvent("evt-3")), "a zero delay NACKs immediately")
+	assert.Zero(t, fake.Waiters(), "nothing should wait")
+}
+
+func TestRequeue_ShutdownReleasesHeldMessages(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	shutdownCtx, shutdown := context.WithCancel(context.Background())
+	handler := Requeue(shutdownCtx, RequeueConfig{Clock: fake}, logger.NewTestLogger(), nil)(
+		returning(apierrors.NewRetryAfterError(time.Minute, nil)))
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- handler(context.Background(), newTestEvent("evt-1")) }()
+
+	fake.BlockUntil(1)
+	shutdown()
+	select {
+	case err := <-errCh:
+		_, ok := apierrors.IsRetryAfterError(err)
+		assert.True(t, ok, "the event is still NACKed")
+	case <-time.After(2 * time.Second):
+		t.Fatal("shutdown did not release the held message")
+	}
+}
+
+func TestRequeue_BoundsTheHeldMessages(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewRecorder("test-adapter", "v0.1.0", registry)
+	handler := Chain(returning(apierrors.NewRetryAfterError(time.Minute, nil)),
+		Subscription("cluster-events"),
+		Requeue(context.Background(), RequeueConfig{Clock: fake, MaxHeld: 2}, logger.NewTestLogger(), recorder))
+
+	errCh := make(chan error, 2)
+	for _, id := range []string{"evt-1", "evt-2"} {
+		go func() { errCh <- handler(context.Background(), newTestEvent(id)) }()
+	}
+	fake.BlockUntil(2)
+
+	// The third event finds both slots taken and is NACKed right away
+	_, ok := apierrors.IsRetryAfterError(handler(context.Background(), newTestEvent("evt-3")))
+	assert.True(t, ok)
+	assert.Equal(t, 2, fake.Waiters(), "only two events are held")
+
+	fake.Advance(time.Minute)
+	<-errCh
+	<-errCh
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var limited float64
+	for _, f := range families {
+		if f.GetName() == "hyperfleet_adapter_requeue_hold_limit_total" {
+			limited = f.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	assert.Equal(t, float64(1), limited)
+
+	// The released slots hold the next event again
+	go func() { errCh <- handler(context.Background(), newTestEvent("evt-4")) }()
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	<-errCh
+}
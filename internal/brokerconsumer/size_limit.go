@@ -0,0 +1,87 @@
+package brokerconsumer
+
+import (
+	"context"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/openshift-hyperfleet/hyperfleet-broker/broker"
+)
+
+// DefaultDeadLetterDataBytes is the size the data of an oversized event is
+// truncated to before it is sent to the dead letter topic
+const DefaultDeadLetterDataBytes = 4096
+
+// OversizedEventSizeExtension is the CloudEvent extension holding the original
+// data size of an event sent to the dead letter topic
+const OversizedEventSizeExtension = "hyperfleetoriginalsize"
+
+// EventPublisher publishes events to a topic; broker.Publisher implements it
+type EventPublisher interface {
+	Publish(ctx context.Context, topic string, evt *event.Event) error
+}
+
+// SizeLimitConfig configures the SizeLimit middleware
+type SizeLimitConfig struct {
+	// DeadLetter publishes oversized events to DeadLetterTopic. Nil drops them.
+	DeadLetter      EventPublisher
+	DeadLetterTopic string
+	// MaxBytes is the largest accepted event data size
+	MaxBytes int
+	// DeadLetterDataBytes is the size the dead-lettered data is truncated to.
+	// Zero uses DefaultDeadLetterDataBytes.
+	DeadLetterDataBytes int
+}
+
+// SizeLimit acknowledges events whose data exceeds MaxBytes without invoking
+// the handler, so a misbehaving producer cannot make the executor parse and
+// copy huge payloads. Oversized events are logged, counted and, with a dead
+// letter publisher, forwarded with their data truncated.
+//
+// hyperfleet-broker decodes messages before the adapter's handler sees them,
+// so this is the earliest point the adapter can reject an event.
+func SizeLimit(config SizeLimitConfig, log logger.Logger, recorder *metrics.Recorder) Middleware {
+	truncateTo := config.DeadLetterDataBytes
+	if truncateTo <= 0 {
+		truncateTo = DefaultDeadLetterDataBytes
+	}
+
+	return func(next broker.HandlerFunc) broker.HandlerFunc {
+		return func(ctx context.Context, evt *event.Event) error {
+			size := len(evt.Data())
+			if config.MaxBytes <= 0 || size <= config.MaxBytes {
+				return next(ctx, evt)
+			}
+
+			recorder.RecordOversizedEvent(SubscriptionFromContext(ctx))
+			log.Warnf(ctx, "Event %s data is %d bytes, over the %d byte limit, acknowledging without handling",
+				evt.ID(), size, config.MaxBytes)
+			if config.DeadLetter == nil || config.DeadLetterTopic == "" {
+				return nil
+			}
+			if err := config.DeadLetter.Publish(ctx, config.DeadLetterTopic, truncatedEvent(evt, truncateTo)); err != nil {
+				errCtx := logger.WithErrorField(ctx, err)
+				log.Errorf(errCtx, "Failed to publish oversized event %s to dead letter topic %s",
+					evt.ID(), config.DeadLetterTopic)
+			}
+			return nil
+		}
+	}
+}
+
+// truncatedEvent returns a copy of evt with its data cut to maxBytes and the
+// original size in the OversizedEventSizeExtension. The data is sent as
+// text/plain since the cut generally leaves invalid JSON.
+func truncatedEvent(evt *event.Event, maxBytes int) *event.Event {
+	truncated := event.New()
+	truncated.Context = evt.Context.Clone()
+	truncated.SetExtension(OversizedEventSizeExtension, len(evt.Data()))
+	data := evt.Data()
+	if len(data) > maxBytes {
+		data = data[:maxBytes]
+	}
+	//nolint:errcheck // []byte data never fails to encode
+	truncated.SetData("text/plain", append([]byte(nil), data...))
+	return &truncated
+}
@@ -0,0 +1,151 @@
+package brokerconsumer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/openshift-hyperfleet/hyperfleet-broker/broker"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePublisher records the published events
+type fakePublisher struct {
+	err    error
+	events []*event.Event
+	topics []string
+}
+
+func (p *fakePublisher) Publish(_ context.Context, topic string, evt *event.Event) error {
+	p.topics = append(p.topics, topic)
+	p.events = append(p.events, evt)
+	return p.err
+}
+
+func newSizedEvent(t *testing.T, id string, size int) *event.Event {
+	t.Helper()
+	evt := newTestEvent(id)
+	evt.SetExtension("correlationid", "corr-"+id)
+	require.NoError(t, evt.SetData(event.ApplicationJSON, []byte(`"`+strings.Repeat("x", size-2)+`"`)))
+	return evt
+}
+
+func TestSizeLimit_OversizedEventIsNotHandled(t *testing.T) {
+	tests := []struct {
+		publisher  *fakePublisher
+		name       string
+		deadLetter bool
+	}{
+		{name: "dropped"},
+		{name: "dead lettered", publisher: &fakePublisher{}, deadLetter: true},
+		{name: "dead letter failure is acked", publisher: &fakePublisher{err: errors.New("unavailable")}, deadLetter: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscribers := map[string]*fakeSubscriber{
+				"cluster-events":  newFakeSubscriber(nil),
+				"nodepool-events": newFakeSubscriber(nil),
+			}
+			group, _, registry := newTestGroup(t, subscribers, true)
+
+			config := SizeLimitConfig{MaxBytes: 1024, DeadLetterDataBytes: 16}
+			if tt.publisher != nil {
+				config.DeadLetter = tt.publisher
+				config.DeadLetterTopic = "oversized"
+			}
+			var handled []string
+			handler := Chain(func(_ context.Context, evt *event.Event) error {
+				handled = append(handled, evt.ID())
+				return nil
+			}, Metrics(group.recorder), SizeLimit(config, logger.NewTestLogger(), group.recorder))
+			require.NoError(t, group.Start(context.Background(), handler))
+			defer group.Close(context.Background()) //nolint:errcheck // test cleanup
+
+			deliver := subscribers["cluster-events"].handler
+			assert.NoError(t, deliver(context.Background(), newSizedEvent(t, "small", 1024)))
+			assert.NoError(t, deliver(context.Background(), newSizedEvent(t, "huge", 12<<20)),
+				"oversized events are acknowledged")
+
+			assert.Equal(t, []string{"small"}, handled, "the handler never sees the oversized event")
+			oversized := findMetricFamily(t, registry, "hyperfleet_adapter_oversized_events_total")
+			require.NotNil(t, oversized)
+			assert.Equal(t, float64(1), oversized.GetMetric()[0].GetCounter().GetValue())
+			results := findMetricFamily(t, registry, "hyperfleet_adapter_handler_results_total")
+			require.NotNil(t, results)
+			assert.Equal(t, float64(2), results.GetMetric()[0].GetCounter().GetValue())
+
+			if !tt.deadLetter {
+				return
+			}
+			require.Len(t, tt.publisher.events, 1)
+			assert.Equal(t, []string{"oversized"}, tt.publisher.topics)
+			dead := tt.publisher.events[0]
+			assert.Equal(t, "huge", dead.ID())
+			assert.Equal(t, "corr-huge", dead.Extensions()["correlationid"], "extensions are kept")
+			assert.EqualValues(t, 12<<20, dead.Extensions()[OversizedEventSizeExtension])
+			assert.Equal(t, "text/plain", dead.DataContentType())
+			assert.Equal(t, `"`+strings.Repeat("x", 15), string(dead.Data()))
+		})
+	}
+}
+
+func TestSizeLimit_ThroughInMemoryBroker(t *testing.T) {
+	memory := newMemoryBroker()
+	factory := func(SubscriptionSpec) (broker.Subscriber, error) { return memory.Subscriber(), nil }
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewRecorder("test-adapter", "v0.1.0", registry)
+	group, err := NewSubscriptionGroup(testSpecs, factory, true, logger.NewTestLogger(), recorder, nil)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var handled []string
+	config := SizeLimitConfig{MaxBytes: 1024, DeadLetter: memory, DeadLetterTopic: "oversized"}
+	handler := Chain(func(_ context.Context, evt *event.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, evt.ID())
+		return nil
+	}, SizeLimit(config, logger.NewTestLogger(), recorder))
+	require.NoError(t, group.Start(context.Background(), handler))
+	defer group.Close(context.Background()) //nolint:errcheck // test cleanup
+
+	require.NoError(t, memory.Publish(context.Background(), "clusters", newSizedEvent(t, "huge", 1<<20)))
+	require.NoError(t, memory.Publish(context.Background(), "clusters", newSizedEvent(t, "small", 512)))
+	memory.settle()
+
+	mu.Lock()
+	assert.Equal(t, []string{"small"}, handled, "the handler is never invoked for the oversized event")
+	mu.Unlock()
+	acked, nacked := memory.outcomes()
+	assert.Equal(t, []string{"huge", "small"}, acked, "the oversized event is acknowledged")
+	assert.Empty(t, nacked)
+
+	dead := memory.events("oversized")
+	require.Len(t, dead, 1)
+	assert.Equal(t, "huge", dead[0].ID())
+	assert.EqualValues(t, 1<<20, dead[0].Extensions()[OversizedEventSizeExtension])
+	assert.Len(t, dead[0].Data(), DefaultDeadLetterDataBytes)
+
+	oversized := findMetricFamily(t, registry, "hyperfleet_adapter_oversized_events_total")
+	require.NotNil(t, oversized)
+	assert.Equal(t, float64(1), oversized.GetMetric()[0].GetCounter().GetValue())
+}
+
+func TestSizeLimit_Disabled(t *testing.T) {
+	calls := 0
+	handler := Chain(func(context.Context, *event.Event) error {
+		calls++
+		return nil
+	}, SizeLimit(SizeLimitConfig{}, logger.NewTestLogger(), nil))
+
+	require.NoError(t, handler(context.Background(), newSizedEvent(t, "huge", 1<<20)))
+	assert.Equal(t, 1, calls)
+}
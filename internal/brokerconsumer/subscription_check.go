@@ -0,0 +1,88 @@
+package brokerconsumer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/iam/apiv1/iampb"
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BrokerTypeGooglePubSub is the broker type of Google Pub/Sub
+const BrokerTypeGooglePubSub = "googlepubsub"
+
+// requiredSubscriptionPermissions are the Pub/Sub permissions receiving from a subscription needs
+var requiredSubscriptionPermissions = []string{"pubsub.subscriptions.consume"}
+
+// BrokerType returns the broker type the broker library uses, e.g. googlepubsub or rabbitmq
+func BrokerType() (string, error) {
+	configMap, err := BrokerConfigMap(nil)
+	if err != nil {
+		return "", err
+	}
+	return configMap["broker.type"], nil
+}
+
+// SubscriptionPermissionTester is the subset of the Pub/Sub subscription admin API
+// used to check subscriptions. Implemented by *pubsub.Client.SubscriptionAdminClient.
+type SubscriptionPermissionTester interface {
+	TestIamPermissions(
+		ctx context.Context, req *iampb.TestIamPermissionsRequest, opts ...gax.CallOption,
+	) (*iampb.TestIamPermissionsResponse, error)
+}
+
+// CheckSubscriptions checks that every subscription exists and that the caller may
+// consume from it. TestIamPermissions needs no permission itself, so a missing
+// subscriber role is reported as such instead of as a permission error.
+func CheckSubscriptions(
+	ctx context.Context, tester SubscriptionPermissionTester, projectID string, subscriptionIDs []string,
+) error {
+	var problems []string
+	for _, id := range subscriptionIDs {
+		name := fmt.Sprintf("projects/%s/subscriptions/%s", projectID, id)
+		resp, err := tester.TestIamPermissions(ctx, &iampb.TestIamPermissionsRequest{
+			Resource:    name,
+			Permissions: requiredSubscriptionPermissions,
+		})
+		switch {
+		case status.Code(err) == codes.NotFound:
+			problems = append(problems, fmt.Sprintf("subscription %s not found", name))
+			continue
+		case err != nil:
+			return fmt.Errorf("failed to check subscription %s: %w", name, err)
+		}
+
+		granted := make(map[string]bool, len(resp.GetPermissions()))
+		for _, permission := range resp.GetPermissions() {
+			granted[permission] = true
+		}
+		for _, permission := range requiredSubscriptionPermissions {
+			if !granted[permission] {
+				problems = append(problems, fmt.Sprintf("missing permission %s on subscription %s", permission, name))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("pub/sub check failed: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// NewPubSubSubscriptionCheck returns a check of the subscriptions backed by a real
+// Pub/Sub client. The returned close function releases the client.
+func NewPubSubSubscriptionCheck(
+	ctx context.Context, projectID string, subscriptionIDs []string,
+) (func(ctx context.Context) error, func() error, error) {
+	client, err := pubsub.NewClient(ctx, projectID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create pub/sub client: %w", err)
+	}
+	check := func(ctx context.Context) error {
+		return CheckSubscriptions(ctx, client.SubscriptionAdminClient, projectID, subscriptionIDs)
+	}
+	return check, client.Close, nil
+}
@@ -0,0 +1,44 @@
+package brokerconsumer
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/iam/apiv1/iampb"
+	"github.com/googleapis/gax-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakePermissionTester grants permissions per subscription resource name;
+// subscriptions missing from the map do not exist
+type fakePermissionTester struct {
+	granted map[string][]string
+}
+
+func (f *fakePermissionTester) TestIamPermissions(
+	_ context.Context, req *iampb.TestIamPermissionsRequest, _ ...gax.CallOption,
+) (*iampb.TestIamPermissionsResponse, error) {
+	permissions, ok := f.granted[req.GetResource()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "subscription not found")
+	}
+	return &iampb.TestIamPermissionsResponse{Permissions: permissions}, nil
+}
+
+func TestCheckSubscriptions(t *testing.T) {
+	tester := &fakePermissionTester{granted: map[string][]string{
+		"projects/p/subscriptions/ok":        {"pubsub.subscriptions.consume"},
+		"projects/p/subscriptions/no-access": {},
+	}}
+
+	require.NoError(t, CheckSubscriptions(context.Background(), tester, "p", []string{"ok"}))
+
+	err := CheckSubscriptions(context.Background(), tester, "p", []string{"ok", "no-access", "missing"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		"missing permission pubsub.subscriptions.consume on subscription projects/p/subscriptions/no-access")
+	assert.Contains(t, err.Error(), "subscription projects/p/subscriptions/missing not found")
+}
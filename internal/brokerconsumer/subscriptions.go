@@ -0,0 +1,234 @@
+package brokerconsumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/openshift-hyperfleet/hyperfleet-broker/broker"
+)
+
+// SubscriptionSpec identifies a subscription and the topic it is attached to
+type SubscriptionSpec struct {
+	// Settings overrides hyperfleet-broker config keys for this subscription, e.g.
+	// "subscriber.parallelism". Nil uses the broker config unchanged.
+	Settings map[string]string
+	ID       string
+	Topic    string
+}
+
+// SubscriberFactory creates the broker subscriber for a subscription
+type SubscriberFactory func(spec SubscriptionSpec) (broker.Subscriber, error)
+
+// SubscriptionStatusFunc is notified when a subscription starts receiving (healthy)
+// or fails to start or reports an error (unhealthy)
+type SubscriptionStatusFunc func(subscriptionID string, healthy bool)
+
+// NewBrokerSubscriberFactory returns a factory creating hyperfleet-broker subscribers.
+// Subscriptions with Settings get the broker config with their overrides applied.
+func NewBrokerSubscriberFactory(log logger.Logger, brokerMetrics *broker.MetricsRecorder) SubscriberFactory {
+	return func(spec SubscriptionSpec) (broker.Subscriber, error) {
+		if len(spec.Settings) == 0 {
+			return broker.NewSubscriber(log, spec.ID, brokerMetrics)
+		}
+		configMap, err := BrokerConfigMap(spec.Settings)
+		if err != nil {
+			return nil, err
+		}
+		return broker.NewSubscriber(log, spec.ID, brokerMetrics, configMap)
+	}
+}
+
+// SubscriptionGroup runs one receive loop per subscription, all dispatching to the
+// same handler.
+//
+// With failFast, a subscription that fails to start aborts Start and a subscription
+// error is reported on FatalErrors. Otherwise the group runs degraded: failed
+// subscriptions are reported unhealthy and the others keep receiving.
+type SubscriptionGroup struct {
+	log        logger.Logger
+	factory    SubscriberFactory
+	onStatus   SubscriptionStatusFunc
+	recorder   *metrics.Recorder
+	fatalErrCh chan error
+	specs      []SubscriptionSpec
+	running    []runningSubscription
+	mu         sync.Mutex
+	failFast   bool
+}
+
+type runningSubscription struct {
+	subscriber broker.Subscriber
+	id         string
+}
+
+// NewSubscriptionGroup creates a group for the given subscriptions.
+// onStatus and recorder may be nil.
+func NewSubscriptionGroup(
+	specs []SubscriptionSpec,
+	factory SubscriberFactory,
+	failFast bool,
+	log logger.Logger,
+	recorder *metrics.Recorder,
+	onStatus SubscriptionStatusFunc,
+) (*SubscriptionGroup, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("at least one subscription is required")
+	}
+	if factory == nil {
+		return nil, fmt.Errorf("subscriber factory is required")
+	}
+	seen := make(map[string]bool, len(specs))
+	for i, spec := range specs {
+		if spec.ID == "" {
+			return nil, fmt.Errorf("subscriptions[%d]: subscription_id is required", i)
+		}
+		if spec.Topic == "" {
+			return nil, fmt.Errorf("subscriptions[%d]: topic is required", i)
+		}
+		if seen[spec.ID] {
+			return nil, fmt.Errorf("subscriptions[%d]: duplicate subscription_id %q", i, spec.ID)
+		}
+		seen[spec.ID] = true
+	}
+
+	return &SubscriptionGroup{
+		log:        log,
+		factory:    factory,
+		onStatus:   onStatus,
+		recorder:   recorder,
+		fatalErrCh: make(chan error, 1),
+		specs:      specs,
+		failFast:   failFast,
+	}, nil
+}
+
+// Start creates and subscribes every subscription, wrapping handler with the
+// Subscription middleware. Fails if a subscription fails to start in fail-fast
+// mode (already started subscriptions are closed), or if none started.
+func (g *SubscriptionGroup) Start(ctx context.Context, handler broker.HandlerFunc) error {
+	var errs []error
+	for _, spec := range g.specs {
+		subCtx := logger.WithSubscription(ctx, spec.ID)
+		g.log.Infof(subCtx, "Subscribing to topic %s...", spec.Topic)
+
+		subscriber, err := g.start(ctx, spec, handler)
+		if err != nil {
+			g.setStatus(spec.ID, false)
+			if g.failFast {
+				if closeErr := g.Close(ctx); closeErr != nil {
+					errCtx := logger.WithErrorField(ctx, closeErr)
+					g.log.Warnf(errCtx, "Failed to close started subscriptions")
+				}
+				return err
+			}
+			errCtx := logger.WithErrorField(subCtx, err)
+			g.log.Errorf(errCtx, "Subscription failed to start, continuing with the remaining subscriptions")
+			errs = append(errs, err)
+			continue
+		}
+
+		g.mu.Lock()
+		g.running = append(g.running, runningSubscription{subscriber: subscriber, id: spec.ID})
+		g.mu.Unlock()
+		g.setStatus(spec.ID, true)
+		g.log.Infof(subCtx, "Successfully subscribed to topic %s", spec.Topic)
+
+		go g.monitor(subCtx, spec.ID, subscriber)
+	}
+
+	if len(g.Running()) == 0 {
+		return fmt.Errorf("no subscription could be started: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (g *SubscriptionGroup) start(
+	ctx context.Context, spec SubscriptionSpec, handler broker.HandlerFunc,
+) (broker.Subscriber, error) {
+	subscriber, err := g.factory(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscriber for subscription %s: %w", spec.ID, err)
+	}
+	if err := subscriber.Subscribe(ctx, spec.Topic, Chain(handler, Subscription(spec.ID))); err != nil {
+		_ = subscriber.Close() //nolint:errcheck // best-effort cleanup, the subscribe error is reported
+		return nil, fmt.Errorf("failed to subscribe %s to topic %s: %w", spec.ID, spec.Topic, err)
+	}
+	return subscriber, nil
+}
+
+// monitor drains the subscriber's error channel until the subscriber is closed
+func (g *SubscriptionGroup) monitor(ctx context.Context, subscriptionID string, subscriber broker.Subscriber) {
+	for subErr := range subscriber.Errors() {
+		errCtx := logger.WithErrorField(ctx, subErr)
+		g.log.Errorf(errCtx, "Subscription error")
+		if g.failFast {
+			select {
+			case g.fatalErrCh <- subErr:
+			default:
+			}
+			continue
+		}
+		g.setStatus(subscriptionID, false)
+	}
+}
+
+// FatalErrors receives the first subscription error in fail-fast mode.
+// Never receives in degraded mode.
+func (g *SubscriptionGroup) FatalErrors() <-chan error {
+	return g.fatalErrCh
+}
+
+// Running returns the IDs of the subscriptions that are receiving
+func (g *SubscriptionGroup) Running() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ids := make([]string, len(g.running))
+	for i, r := range g.running {
+		ids[i] = r.id
+	}
+	return ids
+}
+
+// Close closes all running subscribers concurrently, letting each drain its
+// in-flight messages, and waits until they are closed or ctx is done.
+func (g *SubscriptionGroup) Close(ctx context.Context) error {
+	g.mu.Lock()
+	running := g.running
+	g.running = nil
+	g.mu.Unlock()
+
+	errCh := make(chan error, len(running))
+	for _, r := range running {
+		go func(r runningSubscription) {
+			if err := r.subscriber.Close(); err != nil {
+				errCh <- fmt.Errorf("failed to close subscription %s: %w", r.id, err)
+				return
+			}
+			errCh <- nil
+		}(r)
+	}
+
+	var errs []error
+	for range running {
+		select {
+		case err := <-errCh:
+			if err != nil {
+				errs = append(errs, err)
+			}
+		case <-ctx.Done():
+			return fmt.Errorf("timed out closing subscriptions: %w", ctx.Err())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (g *SubscriptionGroup) setStatus(subscriptionID string, healthy bool) {
+	g.recorder.SetSubscriptionUp(subscriptionID, healthy)
+	if g.onStatus != nil {
+		g.onStatus(subscriptionID, healthy)
+	}
+}
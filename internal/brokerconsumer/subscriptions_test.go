@@ -0,0 +1,276 @@
+package brokerconsumer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/openshift-hyperfleet/hyperfleet-broker/broker"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSubscriber records Subscribe/Close calls and exposes its handler
+type fakeSubscriber struct {
+	subscribeErr error
+	handler      broker.HandlerFunc
+	errCh        chan *broker.SubscriberError
+	topic        string
+	mu           sync.Mutex
+	closed       bool
+}
+
+func newFakeSubscriber(subscribeErr error) *fakeSubscriber {
+	return &fakeSubscriber{subscribeErr: subscribeErr, errCh: make(chan *broker.SubscriberError, 1)}
+}
+
+func (f *fakeSubscriber) Subscribe(_ context.Context, topic string, handler broker.HandlerFunc) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.topic = topic
+	f.handler = handler
+	return f.subscribeErr
+}
+
+func (f *fakeSubscriber) Errors() <-chan *broker.SubscriberError {
+	return f.errCh
+}
+
+func (f *fakeSubscriber) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.closed {
+		f.closed = true
+		close(f.errCh)
+	}
+	return nil
+}
+
+func (f *fakeSubscriber) isClosed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed
+}
+
+// statusRecorder collects SubscriptionStatusFunc notifications
+type statusRecorder struct {
+	status map[string]bool
+	mu     sync.Mutex
+}
+
+func (s *statusRecorder) set(id string, healthy bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status[id] = healthy
+}
+
+func (s *statusRecorder) get(id string) (bool, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	healthy, ok := s.status[id]
+	return healthy, ok
+}
+
+var testSpecs = []SubscriptionSpec{
+	{ID: "cluster-events", Topic: "clusters"},
+	{ID: "nodepool-events", Topic: "nodepools"},
+}
+
+func newTestGroup(
+	t *testing.T, subscribers map[string]*fakeSubscriber, failFast bool,
+) (*SubscriptionGroup, *statusRecorder, *prometheus.Registry) {
+	t.Helper()
+	factory := func(spec SubscriptionSpec) (broker.Subscriber, error) {
+		sub, ok := subscribers[spec.ID]
+		if !ok {
+			return nil, errors.New("subscription not found")
+		}
+		return sub, nil
+	}
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewRecorder("test-adapter", "v0.1.0", registry)
+	status := &statusRecorder{status: map[string]bool{}}
+
+	group, err := NewSubscriptionGroup(testSpecs, factory, failFast, logger.NewTestLogger(), recorder, status.set)
+	require.NoError(t, err)
+	return group, status, registry
+}
+
+func TestNewSubscriptionGroup_Validation(t *testing.T) {
+	factory := func(SubscriptionSpec) (broker.Subscriber, error) { return newFakeSubscriber(nil), nil }
+	log := logger.NewTestLogger()
+
+	_, err := NewSubscriptionGroup(nil, factory, true, log, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewSubscriptionGroup([]SubscriptionSpec{{ID: "a"}}, factory, true, log, nil, nil)
+	assert.ErrorContains(t, err, "topic is required")
+
+	_, err = NewSubscriptionGroup([]SubscriptionSpec{{ID: "a", Topic: "t"}, {ID: "a", Topic: "u"}},
+		factory, true, log, nil, nil)
+	assert.ErrorContains(t, err, "duplicate subscription_id")
+}
+
+func TestSubscriptionGroup_DispatchesAllSubscriptionsToHandler(t *testing.T) {
+	subscribers := map[string]*fakeSubscriber{
+		"cluster-events":  newFakeSubscriber(nil),
+		"nodepool-events": newFakeSubscriber(nil),
+	}
+	group, status, registry := newTestGroup(t, subscribers, true)
+
+	var mu sync.Mutex
+	received := map[string]string{}
+	handler := Chain(func(ctx context.Context, evt *event.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received[evt.ID()] = SubscriptionFromContext(ctx)
+		return nil
+	}, Metrics(group.recorder))
+
+	require.NoError(t, group.Start(context.Background(), handler))
+	assert.Equal(t, []string{"cluster-events", "nodepool-events"}, group.Running())
+	assert.Equal(t, "clusters", subscribers["cluster-events"].topic)
+	assert.Equal(t, "nodepools", subscribers["nodepool-events"].topic)
+
+	require.NoError(t, subscribers["cluster-events"].handler(context.Background(), newTestEvent("c1")))
+	require.NoError(t, subscribers["nodepool-events"].handler(context.Background(), newTestEvent("n1")))
+	assert.Equal(t, map[string]string{"c1": "cluster-events", "n1": "nodepool-events"}, received)
+
+	for _, id := range []string{"cluster-events", "nodepool-events"} {
+		healthy, ok := status.get(id)
+		assert.True(t, ok && healthy, id)
+	}
+
+	results := findMetricFamily(t, registry, "hyperfleet_adapter_handler_results_total")
+	require.NotNil(t, results)
+	subscriptions := map[string]bool{}
+	for _, m := range results.GetMetric() {
+		for _, l := range m.GetLabel() {
+			if l.GetName() == "subscription" {
+				subscriptions[l.GetValue()] = true
+			}
+		}
+	}
+	assert.Equal(t, map[string]bool{"cluster-events": true, "nodepool-events": true}, subscriptions)
+
+	require.NoError(t, group.Close(context.Background()))
+	assert.True(t, subscribers["cluster-events"].isClosed())
+	assert.True(t, subscribers["nodepool-events"].isClosed())
+	assert.Empty(t, group.Running())
+}
+
+func TestSubscriptionGroup_FailFast(t *testing.T) {
+	subscribers := map[string]*fakeSubscriber{
+		"cluster-events":  newFakeSubscriber(nil),
+		"nodepool-events": newFakeSubscriber(errors.New("subscription does not exist")),
+	}
+	group, status, _ := newTestGroup(t, subscribers, true)
+
+	err := group.Start(context.Background(), func(context.Context, *event.Event) error { return nil })
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nodepool-events")
+	assert.True(t, subscribers["cluster-events"].isClosed(), "started subscriptions are closed on failure")
+	assert.Empty(t, group.Running())
+
+	healthy, ok := status.get("nodepool-events")
+	assert.True(t, ok)
+	assert.False(t, healthy)
+}
+
+func TestSubscriptionGroup_Degraded(t *testing.T) {
+	subscribers := map[string]*fakeSubscriber{
+		"cluster-events": newFakeSubscriber(nil),
+		// nodepool-events is missing: the factory fails
+	}
+	group, status, registry := newTestGroup(t, subscribers, false)
+
+	require.NoError(t, group.Start(context.Background(), func(context.Context, *event.Event) error { return nil }))
+	assert.Equal(t, []string{"cluster-events"}, group.Running())
+
+	healthy, _ := status.get("cluster-events")
+	assert.True(t, healthy)
+	healthy, ok := status.get("nodepool-events")
+	assert.True(t, ok)
+	assert.False(t, healthy)
+
+	up := findMetricFamily(t, registry, "hyperfleet_adapter_subscription_up")
+	require.NotNil(t, up)
+	values := map[string]float64{}
+	for _, m := range up.GetMetric() {
+		for _, l := range m.GetLabel() {
+			if l.GetName() == "subscription" {
+				values[l.GetValue()] = m.GetGauge().GetValue()
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{"cluster-events": 1, "nodepool-events": 0}, values)
+
+	// A runtime error marks the subscription unhealthy without a fatal error
+	subscribers["cluster-events"].errCh <- &broker.SubscriberError{Op: "receive", Err: errors.New("stream reset")}
+	assert.Eventually(t, func() bool {
+		healthy, _ := status.get("cluster-events")
+		return !healthy
+	}, time.Second, 10*time.Millisecond)
+	select {
+	case err := <-group.FatalErrors():
+		t.Fatalf("unexpected fatal error in degraded mode: %v", err)
+	default:
+	}
+
+	require.NoError(t, group.Close(context.Background()))
+}
+
+func TestSubscriptionGroup_NoneStarted(t *testing.T) {
+	group, _, _ := newTestGroup(t, map[string]*fakeSubscriber{}, false)
+	err := group.Start(context.Background(), func(context.Context, *event.Event) error { return nil })
+	assert.ErrorContains(t, err, "no subscription could be started")
+}
+
+func TestSubscriptionGroup_FatalErrors(t *testing.T) {
+	subscribers := map[string]*fakeSubscriber{
+		"cluster-events":  newFakeSubscriber(nil),
+		"nodepool-events": newFakeSubscriber(nil),
+	}
+	group, _, _ := newTestGroup(t, subscribers, true)
+	require.NoError(t, group.Start(context.Background(), func(context.Context, *event.Event) error { return nil }))
+
+	subscribers["nodepool-events"].errCh <- &broker.SubscriberError{Op: "receive", Err: errors.New("stream reset")}
+	select {
+	case err := <-group.FatalErrors():
+		assert.ErrorContains(t, err, "stream reset")
+	case <-time.After(time.Second):
+		t.Fatal("expected a fatal subscription error")
+	}
+	require.NoError(t, group.Close(context.Background()))
+}
+
+func TestBrokerConfigMap(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "broker.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`broker:
+  type: googlepubsub
+  googlepubsub:
+    project_id: file-project
+    max_outstanding_messages: 100
+subscriber:
+  parallelism: 4
+`), 0o600))
+
+	t.Setenv("BROKER_CONFIG_FILE", configFile)
+	t.Setenv("BROKER_GOOGLEPUBSUB_PROJECT_ID", "env-project")
+
+	configMap, err := BrokerConfigMap(map[string]string{"subscriber.parallelism": "16"})
+	require.NoError(t, err)
+	assert.Equal(t, "googlepubsub", configMap["broker.type"])
+	assert.Equal(t, "env-project", configMap["broker.googlepubsub.project_id"], "env overrides the file")
+	assert.Equal(t, "100", configMap["broker.googlepubsub.max_outstanding_messages"])
+	assert.Equal(t, "16", configMap["subscriber.parallelism"], "overrides win")
+}
@@ -0,0 +1,96 @@
+// Package clock abstracts wall-clock time so that time-dependent behavior
+// (retry backoff, TTLs, polling, rate limiting) can be tested by advancing a
+// Fake clock instead of sleeping.
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is a source of time and timers.
+// Components take a Clock in their config or options, nil meaning Real.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// Since returns the time elapsed since t
+	Since(t time.Time) time.Duration
+	// After waits for d to elapse and then sends the current time on the returned channel
+	After(d time.Duration) <-chan time.Time
+	// NewTimer creates a Timer that fires once after d
+	NewTimer(d time.Duration) Timer
+	// NewTicker creates a Ticker that fires every d; d must be positive
+	NewTicker(d time.Duration) Ticker
+	// Sleep pauses for d, returning ctx.Err() as soon as ctx is done
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// Timer is a single event, like time.Timer
+type Timer interface {
+	// C returns the channel on which the time is delivered
+	C() <-chan time.Time
+	// Stop prevents the timer from firing; it reports whether the timer was pending
+	Stop() bool
+	// Reset changes the timer to fire after d; it reports whether the timer was pending
+	Reset(d time.Duration) bool
+}
+
+// Ticker delivers ticks at intervals, like time.Ticker
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered
+	C() <-chan time.Time
+	// Stop turns off the ticker
+	Stop()
+}
+
+// Real is the wall clock
+var Real Clock = realClock{}
+
+// OrReal returns c, or Real when c is nil
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTimer(d time.Duration) Timer         { return realTimer{time.NewTimer(d)} }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	return sleep(ctx, realClock{}, d)
+}
+
+type realTimer struct{ t *time.Timer }
+
+func (t realTimer) C() <-chan time.Time        { return t.t.C }
+func (t realTimer) Stop() bool                 { return t.t.Stop() }
+func (t realTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+
+type realTicker struct{ t *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+func (t realTicker) Stop()               { t.t.Stop() }
+
+// sleep waits on a timer of c for d, or until ctx is done
+func sleep(ctx context.Context, c Clock, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d <= 0 {
+		return nil
+	}
+	timer := c.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
@@ -0,0 +1,187 @@
+package clock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock whose time only moves when the test advances it.
+// Timers, tickers, After and Sleep fire when Advance moves the time past
+// their deadline. Use BlockUntil to wait for the code under test to start
+// waiting before advancing, otherwise the advance may happen first.
+type Fake struct {
+	now     time.Time
+	cond    *sync.Cond
+	waiters []*fakeWaiter
+	mu      sync.Mutex
+}
+
+var _ Clock = (*Fake)(nil)
+
+// fakeWaiter is a pending timer or ticker of a Fake
+type fakeWaiter struct {
+	fireAt time.Time
+	fake   *Fake
+	ch     chan time.Time
+	// period is the interval of a ticker, zero for a timer
+	period time.Duration
+}
+
+// NewFake creates a fake clock set to now
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now implements Clock.Now
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since implements Clock.Since
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// After implements Clock.After
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// NewTimer implements Clock.NewTimer. A timer with d <= 0 fires immediately.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{fake: f, ch: make(chan time.Time, 1)}
+	w.Reset(d)
+	return w
+}
+
+// NewTicker implements Clock.NewTicker
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	w := &fakeWaiter{fake: f, ch: make(chan time.Time, 1), period: d}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.fireAt = f.now.Add(d)
+	f.addLocked(w)
+	return fakeTicker{w}
+}
+
+// Sleep implements Clock.Sleep
+func (f *Fake) Sleep(ctx context.Context, d time.Duration) error {
+	return sleep(ctx, f, d)
+}
+
+// Advance moves the time forward by d, firing the timers and tickers due by then
+// in deadline order. A ticker that missed several ticks fires once, like time.Ticker.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+
+	var due []*fakeWaiter
+	kept := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.fireAt.After(f.now) {
+			kept = append(kept, w)
+		} else {
+			due = append(due, w)
+		}
+	}
+	f.waiters = kept
+
+	sort.SliceStable(due, func(i, j int) bool { return due[i].fireAt.Before(due[j].fireAt) })
+	for _, w := range due {
+		select {
+		case w.ch <- f.now:
+		default:
+			// The previous tick was not received yet; drop this one
+		}
+		if w.period > 0 {
+			for !w.fireAt.After(f.now) {
+				w.fireAt = w.fireAt.Add(w.period)
+			}
+			f.addLocked(w)
+		}
+	}
+	f.cond.Broadcast()
+}
+
+// Waiters returns the number of pending timers and tickers, including the
+// timers of After and Sleep calls
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil blocks until at least n timers and tickers are pending
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+// addLocked adds a pending waiter; callers hold f.mu
+func (f *Fake) addLocked(w *fakeWaiter) {
+	f.waiters = append(f.waiters, w)
+	f.cond.Broadcast()
+}
+
+// removeLocked removes a pending waiter and reports whether it was pending; callers hold f.mu
+func (f *Fake) removeLocked(w *fakeWaiter) bool {
+	for i, pending := range f.waiters {
+		if pending == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			f.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+// C implements Timer.C
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.ch
+}
+
+// Stop implements Timer.Stop
+func (w *fakeWaiter) Stop() bool {
+	w.fake.mu.Lock()
+	defer w.fake.mu.Unlock()
+	return w.fake.removeLocked(w)
+}
+
+// Reset implements Timer.Reset
+func (w *fakeWaiter) Reset(d time.Duration) bool {
+	f := w.fake
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pending := f.removeLocked(w)
+	if d <= 0 {
+		select {
+		case w.ch <- f.now:
+		default:
+		}
+		return pending
+	}
+	w.fireAt = f.now.Add(d)
+	f.addLocked(w)
+	return pending
+}
+
+// fakeTicker is the Ticker view of a periodic fakeWaiter
+type fakeTicker struct{ *fakeWaiter }
+
+// Stop implements Ticker.Stop
+func (t fakeTicker) Stop() {
+	t.fakeWaiter.Stop()
+}
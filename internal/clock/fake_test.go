@@ -0,0 +1,95 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fired reports whether ch has a value ready
+func fired(ch <-chan time.Time) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestFake_Timer(t *testing.T) {
+	f := NewFake(epoch)
+	timer := f.NewTimer(time.Second)
+	assert.Equal(t, 1, f.Waiters())
+
+	f.Advance(999 * time.Millisecond)
+	assert.False(t, fired(timer.C()))
+	f.Advance(time.Millisecond)
+	assert.True(t, fired(timer.C()))
+	assert.Zero(t, f.Waiters())
+	assert.Equal(t, epoch.Add(time.Second), f.Now())
+
+	assert.False(t, timer.Reset(time.Minute), "a fired timer is not pending")
+	assert.True(t, timer.Stop())
+	f.Advance(time.Hour)
+	assert.False(t, fired(timer.C()), "a stopped timer never fires")
+
+	assert.True(t, fired(f.NewTimer(0).C()), "a non-positive timer fires immediately")
+}
+
+func TestFake_Ticker(t *testing.T) {
+	f := NewFake(epoch)
+	ticker := f.NewTicker(10 * time.Second)
+
+	f.Advance(10 * time.Second)
+	assert.True(t, fired(ticker.C()))
+	f.Advance(35 * time.Second)
+	assert.True(t, fired(ticker.C()), "missed ticks are delivered once")
+	assert.False(t, fired(ticker.C()))
+	f.Advance(5 * time.Second)
+	assert.True(t, fired(ticker.C()), "the ticker stays on its period")
+
+	ticker.Stop()
+	f.Advance(time.Minute)
+	assert.False(t, fired(ticker.C()))
+	assert.Zero(t, f.Waiters())
+}
+
+func TestFake_Sleep(t *testing.T) {
+	f := NewFake(epoch)
+
+	done := make(chan error, 1)
+	go func() { done <- f.Sleep(context.Background(), time.Minute) }()
+	f.BlockUntil(1)
+	f.Advance(time.Minute)
+	require.NoError(t, <-done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { done <- f.Sleep(ctx, time.Minute) }()
+	f.BlockUntil(1)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Zero(t, f.Waiters(), "an interrupted sleep releases its timer")
+
+	assert.ErrorIs(t, f.Sleep(ctx, time.Minute), context.Canceled, "a done context returns immediately")
+}
+
+func TestFake_Since(t *testing.T) {
+	f := NewFake(epoch)
+	start := f.Now()
+	f.Advance(90 * time.Second)
+	assert.Equal(t, 90*time.Second, f.Since(start))
+}
+
+func TestReal_Sleep(t *testing.T) {
+	require.NoError(t, Real.Sleep(context.Background(), time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, Real.Sleep(ctx, time.Hour), context.Canceled)
+	assert.Equal(t, Real, OrReal(nil))
+}
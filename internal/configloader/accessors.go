@@ -18,6 +18,16 @@ func BuiltinVariables() []string {
 	return builtinVariables
 }
 
+// resourceSummaryKeys are the keys of the apply summary the post payloads see
+// in resources, next to the resources by name
+var resourceSummaryKeys = []string{"applied", "failedCount", "skippedCount"}
+
+// ResourceSummaryKeys returns the keys of the apply summary in resources,
+// which resources and nested discoveries cannot be named
+func ResourceSummaryKeys() []string {
+	return resourceSummaryKeys
+}
+
 // -----------------------------------------------------------------------------
 // Config Accessors (Unified Configuration)
 // -----------------------------------------------------------------------------
@@ -26,7 +36,9 @@ func BuiltinVariables() []string {
 // in templates and CEL expressions. This includes:
 // - Built-in variables (adapter, now, date)
 // - Parameters from params
-// - Captured variables from preconditions
+// - Constant params from vars
+// - Named segments of the subject patterns
+// - Captured variables and responses from preconditions
 // - Post payloads
 // - Resource aliases (resources.<name>)
 func (c *Config) GetDefinedVariables() map[string]bool {
@@ -48,6 +60,20 @@ func (c *Config) GetDefinedVariables() map[string]bool {
 		}
 	}
 
+	// Constant params from vars
+	for name := range c.Vars {
+		vars[name] = true
+	}
+
+	// Named segments of the subject patterns
+	for _, raw := range c.SubjectPatterns {
+		if pattern, err := ParseSubjectPattern(raw); err == nil {
+			for _, name := range pattern.Names() {
+				vars[name] = true
+			}
+		}
+	}
+
 	// Variables from precondition captures
 	for _, precond := range c.Preconditions {
 		for _, capture := range precond.Capture {
@@ -55,6 +81,9 @@ func (c *Config) GetDefinedVariables() map[string]bool {
 				vars[capture.Name] = true
 			}
 		}
+		if precond.CaptureResponseAs != "" {
+			vars[precond.CaptureResponseAs] = true
+		}
 	}
 
 	// Post payloads
@@ -258,3 +287,39 @@ func normalizeToStringKeyMap(v interface{}) map[string]interface{} {
 		return nil
 	}
 }
+
+// hasTopLevelPhases reports whether the task config has top-level preconditions,
+// resources or post, which form the implicit default workflow
+func (c *AdapterTaskConfig) hasTopLevelPhases() bool {
+	return len(c.Preconditions) > 0 || len(c.Resources) > 0 || c.Post != nil
+}
+
+// DefaultWorkflow returns the workflow run for the events no workflow matches:
+// the workflow without a matcher or, when there is none, the implicit default
+// workflow formed by the top-level preconditions, resources and post
+func (c *Config) DefaultWorkflow() *Workflow {
+	for i := range c.Workflows {
+		if c.Workflows[i].Match == nil {
+			return &c.Workflows[i]
+		}
+	}
+	return &Workflow{
+		Name:          DefaultWorkflowName,
+		Preconditions: c.Preconditions,
+		Resources:     c.Resources,
+		Post:          c.Post,
+	}
+}
+
+// AllWorkflows returns the workflows of the config, including the implicit
+// default workflow when no workflow is the default
+func (c *Config) AllWorkflows() []Workflow {
+	workflows := make([]Workflow, 0, len(c.Workflows)+1)
+	workflows = append(workflows, c.Workflows...)
+	for _, workflow := range c.Workflows {
+		if workflow.Match == nil {
+			return workflows
+		}
+	}
+	return append(workflows, *c.DefaultWorkflow())
+}
@@ -6,13 +6,37 @@ package configloader
 
 // Field names
 const (
-	FieldAdapter       = "adapter"
-	FieldHyperfleetAPI = "hyperfleet_api"
-	FieldKubernetes    = "kubernetes"
-	FieldParams        = "params"
-	FieldPreconditions = "preconditions"
-	FieldResources     = "resources"
-	FieldPost          = "post"
+	FieldAdapter         = "adapter"
+	FieldHyperfleetAPI   = "hyperfleet_api"
+	FieldKubernetes      = "kubernetes"
+	FieldParams          = "params"
+	FieldPreconditions   = "preconditions"
+	FieldResources       = "resources"
+	FieldPost            = "post"
+	FieldRequiredParams  = "required_params"
+	FieldVars            = "vars"
+	FieldExecutionFence  = "execution_fence"
+	FieldNotMetBackoff   = "not_met_backoff"
+	FieldWorkflows       = "workflows"
+	FieldEventFilter     = "event_filter"
+	FieldSchedule        = "schedule"
+	FieldFinalizer       = "finalizer"
+	FieldIdempotency     = "idempotency"
+	FieldSubjectPatterns = "subject_patterns"
+	FieldMaps            = "maps"
+)
+
+// Schedule field names (for schedule)
+const (
+	FieldTimezone = "timezone"
+	FieldWindows  = "windows"
+	FieldStart    = "start"
+)
+
+// Finalizer field names (for finalizer)
+const (
+	FieldOn               = "on"
+	FieldTeardownWorkflow = "teardown_workflow"
 )
 
 // Adapter field names
@@ -28,30 +52,67 @@ const (
 	FieldDescription = "description"
 	FieldRequired    = "required"
 	FieldDefault     = "default"
+	FieldValueFrom   = "value_from"
+	FieldK8sFieldRef = "k8s_field_ref"
+	FieldFieldPath   = "field_path"
+)
+
+// Event schema field names (for event_schemas)
+const (
+	FieldEventSchemas = "event_schemas"
+	FieldEventType    = "event_type"
+	FieldSchema       = "schema"
+	FieldSchemaRef    = "schema_ref"
 )
 
 // Payload field names (for post.payloads)
 const (
-	FieldPayloads = "payloads"
-	FieldBuild    = "build"
-	FieldBuildRef = "build_ref"
+	FieldPayloads       = "payloads"
+	FieldBuild          = "build"
+	FieldBuildRef       = "build_ref"
+	FieldBuildRefDigest = "build_ref_digest"
+)
+
+// Status conditions field names (for post.payloads[].conditions)
+const (
+	FieldPrevious = "previous"
+	FieldStatus   = "status"
+	FieldReason   = "reason"
+	FieldMessage  = "message"
 )
 
 // Precondition field names
 const (
-	FieldAPICall    = "api_call"
-	FieldCapture    = "capture"
-	FieldConditions = "conditions"
-	FieldExpression = "expression"
+	FieldAPICall           = "api_call"
+	FieldCapture           = "capture"
+	FieldCaptureResponseAs = "capture_response_as"
+	FieldConditions        = "conditions"
+	FieldDependsOn         = "depends_on"
+	FieldExpect            = "expect"
+	FieldExpression        = "expression"
+	FieldOnError           = "on_error"
+	FieldReasonLabel       = "reason_label"
 )
 
 // API call field names
 const (
-	FieldMethod  = "method"
-	FieldURL     = "url"
-	FieldTimeout = "timeout"
-	FieldHeaders = "headers"
-	FieldBody    = "body"
+	FieldMethod    = "method"
+	FieldURL       = "url"
+	FieldTarget    = "target"
+	FieldTimeout   = "timeout"
+	FieldHeaders   = "headers"
+	FieldBody      = "body"
+	FieldTransform = "transform"
+)
+
+// API call body field names
+const (
+	FieldBodyType   = "body_type"
+	FieldBodyFrom   = "body_from"
+	FieldBodyMerge  = "body_merge"
+	FieldFormFields = "form_fields"
+	FieldParts      = "parts"
+	FieldContent    = "content"
 )
 
 // Header field names
@@ -62,6 +123,7 @@ const (
 // Condition field names
 const (
 	FieldField    = "field"
+	FieldHeader   = "header"
 	FieldOperator = "operator"
 	FieldValue    = "value"  // Supports any type including lists for operators like "in", "notIn"
 	FieldValues   = "values" // YAML alias for Value - both "value" and "values" are accepted in YAML
@@ -69,10 +131,11 @@ const (
 
 // Transport field names
 const (
-	FieldTransport     = "transport"
-	FieldClient        = "client"
-	FieldMaestro       = "maestro"
-	FieldTargetCluster = "target_cluster"
+	FieldTransport            = "transport"
+	FieldClient               = "client"
+	FieldMaestro              = "maestro"
+	FieldTargetCluster        = "target_cluster"
+	FieldManifestWorkMetadata = "manifest_work_metadata"
 )
 
 // Transport client types
@@ -87,6 +150,9 @@ const (
 	FieldRecreateOnChange  = "recreate_on_change"
 	FieldDiscovery         = "discovery"
 	FieldNestedDiscoveries = "nested_discoveries"
+	FieldManifestRef       = "manifest_ref"
+	FieldManifestRefDigest = "manifest_ref_digest"
+	FieldEnabledIf         = "enabled_if"
 )
 
 // Manifest reference field names
@@ -98,6 +164,7 @@ const (
 const (
 	FieldNamespace   = "namespace"
 	FieldByName      = "by_name"
+	FieldItem        = "item"
 	FieldBySelectors = "by_selectors"
 )
 
@@ -108,11 +175,29 @@ const (
 
 // Post config field names
 const (
-	FieldPostActions = "post_actions"
+	FieldPostActions     = "post_actions"
+	FieldK8sPatch        = "k8s_patch"
+	FieldWebhook         = "webhook"
+	FieldPayload         = "payload"
+	FieldSigning         = "signing"
+	FieldSecret          = "secret"
+	FieldSkipIfUnchanged = "skip_if_unchanged"
+	FieldBufferOnFailure = "buffer_on_failure"
+	FieldKey             = "key"
+)
+
+// Log action field names
+const (
+	FieldLog       = "log"
+	FieldLogFields = "fields"
 )
 
 // Kubernetes manifest field names
 const (
 	FieldAPIVersion = "apiVersion"
 	FieldKind       = "kind"
+	FieldItems      = "items"
 )
+
+// KindList is the kind of a v1 List manifest, whose items are manifests
+const KindList = "List"
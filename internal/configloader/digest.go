@@ -0,0 +1,46 @@
+package configloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+)
+
+// DigestPrefix is the algorithm prefix of a file reference digest
+const DigestPrefix = "sha256:"
+
+// digestPattern matches a file reference digest: sha256: and 64 lowercase hex digits
+var digestPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// ComputeDigest returns the digest of the content of a referenced file, in the
+// form set in build_ref_digest and manifest_ref_digest
+func ComputeDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return DigestPrefix + hex.EncodeToString(sum[:])
+}
+
+// DigestMismatchError is a referenced file whose content does not match the
+// digest pinned in the config
+type DigestMismatchError struct {
+	Path     string
+	Expected string
+	Actual   string
+}
+
+// Error implements error
+func (e *DigestMismatchError) Error() string {
+	return fmt.Sprintf("file %q does not match its digest: expected %s, got %s", e.Path, e.Expected, e.Actual)
+}
+
+// verifyDigest checks the content data of the file at path against digest.
+// An empty digest is not pinned and matches any content.
+func verifyDigest(path string, data []byte, digest string) error {
+	if digest == "" {
+		return nil
+	}
+	if actual := ComputeDigest(data); actual != digest {
+		return &DigestMismatchError{Path: path, Expected: digest, Actual: actual}
+	}
+	return nil
+}
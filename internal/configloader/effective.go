@@ -0,0 +1,102 @@
+package configloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"gopkg.in/yaml.v3"
+)
+
+// SecretPlaceholder replaces secrets and sensitive values in the effective config
+const SecretPlaceholder = "***"
+
+// Effective returns the config the adapter runs with as a self-contained copy
+// that can be printed and loaded again:
+//   - file references are inlined: build_ref as build, schema_ref as schema
+//     (manifest refs are already replaced by the loader)
+//   - secrets are replaced by SecretPlaceholder: the Maestro TLS files, the
+//     credential headers of the HyperFleet API client, the audit HTTP headers
+//     and the defaults of sensitive params
+func (c *Config) Effective() *Config {
+	if c == nil {
+		return nil
+	}
+	copy := *c
+	copy.Sources = nil
+	copy.Clients = redactClients(c.Clients, SecretPlaceholder)
+	copy.Audit = redactAudit(c.Audit, SecretPlaceholder)
+	if headers := c.Clients.HyperfleetAPI.DefaultHeaders; len(headers) > 0 {
+		copy.Clients.HyperfleetAPI.DefaultHeaders = make(map[string]string, len(headers))
+		for name, value := range headers {
+			if hyperfleetapi.IsSecretHeader(name) {
+				value = SecretPlaceholder
+			}
+			copy.Clients.HyperfleetAPI.DefaultHeaders[name] = value
+		}
+	}
+
+	if c.Params != nil {
+		copy.Params = make([]Parameter, len(c.Params))
+		for i, param := range c.Params {
+			if param.Sensitive && param.Default != nil {
+				param.Default = SecretPlaceholder
+			}
+			copy.Params[i] = param
+		}
+	}
+
+	if c.EventSchemas != nil {
+		copy.EventSchemas = make([]EventSchema, len(c.EventSchemas))
+		for i, schema := range c.EventSchemas {
+			if schema.SchemaRef != "" {
+				schema.Schema = schema.SchemaRefContent
+				schema.SchemaRef = ""
+			}
+			copy.EventSchemas[i] = schema
+		}
+	}
+
+	copy.Post = effectivePost(c.Post)
+	if c.Workflows != nil {
+		copy.Workflows = make([]Workflow, len(c.Workflows))
+		for i, workflow := range c.Workflows {
+			workflow.Post = effectivePost(workflow.Post)
+			copy.Workflows[i] = workflow
+		}
+	}
+	return &copy
+}
+
+// effectivePost returns a copy of post with the build_ref payloads inlined
+func effectivePost(post *PostConfig) *PostConfig {
+	if post == nil {
+		return nil
+	}
+	effective := *post
+	if post.Payloads != nil {
+		effective.Payloads = make([]Payload, len(post.Payloads))
+		for i, payload := range post.Payloads {
+			if payload.BuildRef != "" {
+				payload.Build = payload.BuildRefContent
+				payload.BuildRef = ""
+				payload.BuildRefDigest = ""
+			}
+			effective.Payloads[i] = payload
+		}
+	}
+	return &effective
+}
+
+// Hash returns the SHA-256 hex digest of the effective config in YAML. Secrets
+// are replaced before hashing, so the hash identifies a config revision without
+// revealing them.
+func (c *Config) Hash() (string, error) {
+	data, err := yaml.Marshal(c.Effective())
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal effective config: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
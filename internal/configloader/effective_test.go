@@ -0,0 +1,194 @@
+package configloader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestEffectiveRedactsSecrets(t *testing.T) {
+	config := &Config{
+		Params: []Parameter{
+			{Name: "token", Source: "env.TOKEN", Default: "s3cr3t", Sensitive: true},
+			{Name: "region", Source: "env.REGION", Default: "us-east-1"},
+		},
+		Clients: ClientsConfig{
+			Maestro: &MaestroClientConfig{
+				Auth: MaestroAuthConfig{TLSConfig: &TLSConfig{CAFile: "/etc/ca.pem", KeyFile: "/etc/key.pem"}},
+			},
+		},
+		Sources: []string{"/etc/adapter/adapter-config.yaml"},
+		Audit: &AuditConfig{HTTP: &AuditHTTPConfig{
+			URL:     "https://audit.example.com",
+			Headers: map[string]string{"Authorization": "Bearer audit"},
+		}},
+	}
+	config.Clients.HyperfleetAPI.DefaultHeaders = map[string]string{
+		"authorization": "Bearer abc",
+		"x-team":        "fleet",
+	}
+
+	effective := config.Effective()
+
+	assert.Equal(t, SecretPlaceholder, effective.Params[0].Default)
+	assert.Equal(t, "us-east-1", effective.Params[1].Default)
+	assert.Equal(t, SecretPlaceholder, effective.Clients.Maestro.Auth.TLSConfig.CAFile)
+	assert.Equal(t, SecretPlaceholder, effective.Clients.Maestro.Auth.TLSConfig.KeyFile)
+	assert.Empty(t, effective.Clients.Maestro.Auth.TLSConfig.CertFile)
+	assert.Equal(t, SecretPlaceholder, effective.Clients.HyperfleetAPI.DefaultHeaders["authorization"])
+	assert.Equal(t, "fleet", effective.Clients.HyperfleetAPI.DefaultHeaders["x-team"])
+	assert.Nil(t, effective.Sources)
+	assert.Equal(t, SecretPlaceholder, effective.Audit.HTTP.Headers["Authorization"])
+	assert.Equal(t, redactedValue, config.Redacted().Audit.HTTP.Headers["Authorization"])
+
+	// The original config is unchanged
+	assert.Equal(t, "s3cr3t", config.Params[0].Default)
+	assert.Equal(t, "/etc/ca.pem", config.Clients.Maestro.Auth.TLSConfig.CAFile)
+	assert.Equal(t, "Bearer abc", config.Clients.HyperfleetAPI.DefaultHeaders["authorization"])
+	assert.Equal(t, "Bearer audit", config.Audit.HTTP.Headers["Authorization"])
+}
+
+func TestHash(t *testing.T) {
+	config := &Config{Adapter: AdapterInfo{Name: "test"}}
+	hash, err := config.Hash()
+	require.NoError(t, err)
+	assert.Len(t, hash, 64)
+
+	same, err := (&Config{Adapter: AdapterInfo{Name: "test"}, Sources: []string{"/tmp/a.yaml"}}).Hash()
+	require.NoError(t, err)
+	assert.Equal(t, hash, same, "sources do not change the hash")
+
+	other, err := (&Config{Adapter: AdapterInfo{Name: "other"}}).Hash()
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+}
+
+// The printed effective config loads again to an equivalent config
+func TestEffectiveRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	templateDir := filepath.Join(tmpDir, "templates")
+	require.NoError(t, os.MkdirAll(templateDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(templateDir, "status-payload.yaml"), []byte(`
+status: "{{ .status }}"
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(templateDir, "deleted-payload.yaml"), []byte(`
+status: deleted
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(templateDir, "event.schema.yaml"), []byte(`
+type: object
+required: [id]
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(templateDir, "namespace.yaml"), []byte(`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: "ns-{{ .clusterId }}"
+`), 0644))
+
+	adapterYAML := `
+adapter:
+  name: test-adapter
+  version: "0.1.0"
+clients:
+  hyperfleet_api:
+    base_url: "https://test.example.com"
+    timeout: 2s
+    default_headers:
+      authorization: "Bearer abc"
+      x-team: fleet
+  kubernetes:
+    api_version: "v1"
+`
+	taskYAML := `
+event_schemas:
+  - event_type: cluster.created
+    schema_ref: templates/event.schema.yaml
+params:
+  - name: clusterId
+    source: event.id
+    required: true
+  - name: token
+    source: env.TOKEN
+    default: s3cr3t
+    sensitive: true
+resources:
+  - name: namespace
+    manifest:
+      ref: templates/namespace.yaml
+    discovery:
+      by_name: "ns-{{ .clusterId }}"
+post:
+  payloads:
+    - name: statusPayload
+      build_ref: templates/status-payload.yaml
+workflows:
+  - name: teardown
+    match:
+      event_types: [cluster.deleted]
+    post:
+      payloads:
+        - name: statusPayload
+          build_ref: templates/deleted-payload.yaml
+`
+	adapterPath, taskPath := createTestConfigFiles(t, tmpDir, adapterYAML, taskYAML)
+	config, err := LoadConfig(
+		WithAdapterConfigPath(adapterPath),
+		WithTaskConfigPath(taskPath),
+		WithSkipSemanticValidation(),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		adapterPath,
+		taskPath,
+		filepath.Join(templateDir, "namespace.yaml"),
+		filepath.Join(templateDir, "event.schema.yaml"),
+		filepath.Join(templateDir, "status-payload.yaml"),
+		filepath.Join(templateDir, "deleted-payload.yaml"),
+	}, config.Sources)
+
+	printed, err := yaml.Marshal(config.Effective())
+	require.NoError(t, err)
+	assert.NotContains(t, string(printed), "s3cr3t")
+	assert.NotContains(t, string(printed), "Bearer abc")
+	assert.NotContains(t, string(printed), "_ref")
+
+	// Split the printed config back into the adapter and task config files
+	var document map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(printed, &document))
+	adapterDoc, taskDoc := map[string]interface{}{}, map[string]interface{}{}
+	for key, value := range document {
+		switch key {
+		case "adapter", "log", "health", "clients", "debug_config":
+			adapterDoc[key] = value
+		default:
+			taskDoc[key] = value
+		}
+	}
+	reloadDir := t.TempDir()
+	adapterOut, err := yaml.Marshal(adapterDoc)
+	require.NoError(t, err)
+	taskOut, err := yaml.Marshal(taskDoc)
+	require.NoError(t, err)
+	reloadAdapterPath, reloadTaskPath := createTestConfigFiles(t, reloadDir, string(adapterOut), string(taskOut))
+
+	reloaded, err := LoadConfig(
+		WithAdapterConfigPath(reloadAdapterPath),
+		WithTaskConfigPath(reloadTaskPath),
+		WithSkipSemanticValidation(),
+	)
+	require.NoError(t, err)
+
+	reprinted, err := yaml.Marshal(reloaded.Effective())
+	require.NoError(t, err)
+	assert.Equal(t, string(printed), string(reprinted))
+
+	hash, err := config.Hash()
+	require.NoError(t, err)
+	reloadedHash, err := reloaded.Hash()
+	require.NoError(t, err)
+	assert.Equal(t, hash, reloadedHash)
+}
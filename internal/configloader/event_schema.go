@@ -0,0 +1,194 @@
+package configloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// EventSchema associates a JSON Schema with a CloudEvent type.
+// Event data of that type is validated against the schema before parameter extraction.
+//
+// IMPORTANT: Schema and SchemaRef are mutually exclusive - exactly one must be set.
+type EventSchema struct {
+	// Schema is an inline JSON Schema document (written as YAML).
+	// Mutually exclusive with SchemaRef.
+	Schema map[string]interface{} `yaml:"schema,omitempty" validate:"required_without=SchemaRef,excluded_with=SchemaRef"`
+	// SchemaRefContent holds the loaded content from SchemaRef file (populated by loader)
+	SchemaRefContent map[string]interface{} `yaml:"-"`
+	// compiled is the compiled schema (populated by CompileEventSchemas)
+	compiled *jsonschema.Schema
+	// EventType is the CloudEvent type this schema applies to
+	EventType string `yaml:"event_type" validate:"required"`
+	// SchemaRef references an external JSON or YAML file containing the schema.
+	// Mutually exclusive with Schema.
+	SchemaRef string `yaml:"schema_ref,omitempty" validate:"required_without=Schema,excluded_with=Schema"`
+}
+
+// SchemaViolation is a single JSON Schema validation failure of event data
+type SchemaViolation struct {
+	// Pointer is the JSON pointer (RFC 6901) of the offending value, "" for the document root
+	Pointer string `json:"pointer"`
+	// Message describes the violation
+	Message string `json:"message"`
+}
+
+func (v SchemaViolation) String() string {
+	pointer := v.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return fmt.Sprintf("%s: %s", pointer, v.Message)
+}
+
+// SchemaViolationError is returned when event data does not match its event schema
+type SchemaViolationError struct {
+	EventType  string
+	Violations []SchemaViolation
+}
+
+func (e *SchemaViolationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.String()
+	}
+	return fmt.Sprintf("event data does not match schema for event type %q: %s",
+		e.EventType, strings.Join(parts, "; "))
+}
+
+// document returns the schema document from Schema or the loaded SchemaRef content
+func (s *EventSchema) document() map[string]interface{} {
+	if s.Schema != nil {
+		return s.Schema
+	}
+	return s.SchemaRefContent
+}
+
+// Compiled reports whether the schema has been compiled
+func (s *EventSchema) Compiled() bool {
+	return s.compiled != nil
+}
+
+// Validate validates parsed event data against the compiled schema.
+// Returns nil if the data is valid, the schema has not been compiled, or s is nil.
+func (s *EventSchema) Validate(data interface{}) []SchemaViolation {
+	if s == nil || s.compiled == nil {
+		return nil
+	}
+	err := s.compiled.Validate(data)
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return []SchemaViolation{{Message: err.Error()}}
+	}
+	return schemaViolations(validationErr)
+}
+
+// schemaMessagePrinter formats schema violation messages
+var schemaMessagePrinter = message.NewPrinter(language.English)
+
+// schemaViolations flattens a validation error to its leaf violations, sorted by pointer.
+// The causes are walked directly: the basic output loses leaf messages under $ref.
+func schemaViolations(validationErr *jsonschema.ValidationError) []SchemaViolation {
+	var violations []SchemaViolation
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, cause := range e.Causes {
+				walk(cause)
+			}
+			return
+		}
+		pointer := ""
+		for _, token := range e.InstanceLocation {
+			pointer += "/" + strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+		}
+		violations = append(violations, SchemaViolation{
+			Pointer: pointer,
+			Message: e.ErrorKind.LocalizedString(schemaMessagePrinter),
+		})
+	}
+	walk(validationErr)
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Pointer < violations[j].Pointer
+	})
+	return violations
+}
+
+// CompileEventSchemas compiles every event schema that has not been compiled yet.
+// Called once at config load; compile errors are reported per event_schemas entry.
+func CompileEventSchemas(schemas []EventSchema) error {
+	errs := &ValidationErrors{}
+	for i := range schemas {
+		schema := &schemas[i]
+		if schema.compiled != nil {
+			continue
+		}
+		path := fmt.Sprintf("%s[%d].%s", FieldEventSchemas, i, FieldSchema)
+		if schema.SchemaRef != "" {
+			path = fmt.Sprintf("%s[%d].%s", FieldEventSchemas, i, FieldSchemaRef)
+		}
+
+		compiled, err := compileSchemaDocument(schema.EventType, schema.document())
+		if err != nil {
+			errs.Add(path, err.Error())
+			continue
+		}
+		schema.compiled = compiled
+	}
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+// EventSchemaFor returns the schema registered for the event type, or nil if none is.
+func (c *Config) EventSchemaFor(eventType string) *EventSchema {
+	if c == nil || eventType == "" {
+		return nil
+	}
+	for i := range c.EventSchemas {
+		if c.EventSchemas[i].EventType == eventType {
+			return &c.EventSchemas[i]
+		}
+	}
+	return nil
+}
+
+func compileSchemaDocument(eventType string, doc map[string]interface{}) (*jsonschema.Schema, error) {
+	if doc == nil {
+		return nil, fmt.Errorf("schema for event type %q is empty", eventType)
+	}
+
+	// Round-trip through JSON so YAML-decoded values (e.g. int) use JSON types
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("schema for event type %q is not valid JSON: %w", eventType, err)
+	}
+	normalized, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("schema for event type %q is not valid JSON: %w", eventType, err)
+	}
+
+	location := "https://hyperfleet.local/event-schemas/" + url.PathEscape(eventType)
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(location, normalized); err != nil {
+		return nil, fmt.Errorf("invalid schema for event type %q: %w", eventType, err)
+	}
+	compiled, err := compiler.Compile(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema for event type %q: %w", eventType, err)
+	}
+	return compiled, nil
+}
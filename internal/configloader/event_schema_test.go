@@ -0,0 +1,204 @@
+package configloader
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const clusterEventSchemaYAML = `
+type: object
+required: [id, kind]
+additionalProperties: false
+properties:
+  id:
+    type: string
+  kind:
+    type: string
+  href:
+    type: string
+  generation:
+    type: integer
+    minimum: 1
+`
+
+func compiledTestSchema(t *testing.T) *EventSchema {
+	t.Helper()
+	var doc map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(clusterEventSchemaYAML), &doc))
+	schemas := []EventSchema{{EventType: "io.hyperfleet.cluster.updated", Schema: doc}}
+	require.NoError(t, CompileEventSchemas(schemas))
+	require.True(t, schemas[0].Compiled())
+	return &schemas[0]
+}
+
+func parseJSON(t *testing.T, data string) interface{} {
+	t.Helper()
+	var v interface{}
+	require.NoError(t, json.Unmarshal([]byte(data), &v))
+	return v
+}
+
+func TestEventSchemaValidate(t *testing.T) {
+	schema := compiledTestSchema(t)
+
+	tests := []struct {
+		name         string
+		data         string
+		wantPointers []string
+	}{
+		{
+			name: "valid",
+			data: `{"id":"abc","kind":"Cluster","generation":2}`,
+		},
+		{
+			name:         "missing required field",
+			data:         `{"id":"abc"}`,
+			wantPointers: []string{""},
+		},
+		{
+			name:         "type mismatch",
+			data:         `{"id":"abc","kind":"Cluster","generation":"two"}`,
+			wantPointers: []string{"/generation"},
+		},
+		{
+			name:         "additional property",
+			data:         `{"id":"abc","kind":"Cluster","unexpected":true}`,
+			wantPointers: []string{""},
+		},
+		{
+			name:         "multiple violations",
+			data:         `{"id":1,"kind":"Cluster","generation":0}`,
+			wantPointers: []string{"/generation", "/id"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := schema.Validate(parseJSON(t, tt.data))
+			if len(tt.wantPointers) == 0 {
+				assert.Empty(t, violations)
+				return
+			}
+			pointers := make([]string, len(violations))
+			for i, v := range violations {
+				pointers[i] = v.Pointer
+				assert.NotEmpty(t, v.Message)
+			}
+			assert.Equal(t, tt.wantPointers, pointers)
+		})
+	}
+
+	violations := schema.Validate(parseJSON(t, `{"id":"abc","kind":"Cluster","unexpected":true}`))
+	require.Len(t, violations, 1)
+	assert.Contains(t, violations[0].Message, "unexpected")
+
+	violations = schema.Validate(parseJSON(t, `{"id":"abc"}`))
+	require.Len(t, violations, 1)
+	assert.Contains(t, violations[0].Message, "kind")
+}
+
+func TestEventSchemaValidate_NotRegistered(t *testing.T) {
+	config := &Config{EventSchemas: []EventSchema{*compiledTestSchema(t)}}
+
+	assert.NotNil(t, config.EventSchemaFor("io.hyperfleet.cluster.updated"))
+	assert.Nil(t, config.EventSchemaFor("io.hyperfleet.nodepool.updated"))
+	assert.Nil(t, config.EventSchemaFor(""))
+	// A nil schema accepts anything
+	assert.Empty(t, config.EventSchemaFor("io.hyperfleet.nodepool.updated").Validate(parseJSON(t, `{"x":1}`)))
+}
+
+func TestCompileEventSchemas_Errors(t *testing.T) {
+	schemas := []EventSchema{
+		{EventType: "valid", Schema: map[string]interface{}{"type": "object"}},
+		{EventType: "bad-type", Schema: map[string]interface{}{"type": 123}},
+		{EventType: "missing-ref-content", SchemaRef: "schemas/cluster.json"},
+	}
+
+	err := CompileEventSchemas(schemas)
+	require.Error(t, err)
+	var validationErrs *ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
+	require.Equal(t, 2, validationErrs.Count())
+	assert.Equal(t, "event_schemas[1].schema", validationErrs.Errors[0].Path)
+	assert.Equal(t, "event_schemas[2].schema_ref", validationErrs.Errors[1].Path)
+	assert.True(t, schemas[0].Compiled(), "valid schemas are compiled even when others fail")
+}
+
+func TestLoadConfigWithEventSchemas(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "schemas"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "schemas", "nodepool.json"),
+		[]byte(`{"type":"object","required":["id","owner_references"]}`), 0644))
+
+	adapterPath := filepath.Join(tmpDir, "adapter-config.yaml")
+	require.NoError(t, os.WriteFile(adapterPath, []byte(testAdapterConfigYAML), 0644))
+
+	taskYAML := `
+event_schemas:
+  - event_type: "io.hyperfleet.cluster.updated"
+    schema:
+      type: object
+      required: [id]
+  - event_type: "io.hyperfleet.nodepool.updated"
+    schema_ref: "schemas/nodepool.json"
+params:
+  - name: "clusterId"
+    source: "event.id"
+`
+	taskPath := filepath.Join(tmpDir, "task-config.yaml")
+	require.NoError(t, os.WriteFile(taskPath, []byte(taskYAML), 0644))
+
+	config, err := LoadConfig(
+		WithAdapterConfigPath(adapterPath),
+		WithTaskConfigPath(taskPath),
+		WithSkipSemanticValidation(),
+	)
+	require.NoError(t, err)
+	require.Len(t, config.EventSchemas, 2)
+	for _, schema := range config.EventSchemas {
+		assert.True(t, schema.Compiled(), schema.EventType)
+	}
+	assert.NotEmpty(t, config.EventSchemaFor("io.hyperfleet.nodepool.updated").Validate(parseJSON(t, `{"id":"np"}`)))
+
+	// Invalid schema is reported at load time
+	badTaskYAML := `
+event_schemas:
+  - event_type: "io.hyperfleet.cluster.updated"
+    schema:
+      type: [not-a-type]
+`
+	badTaskPath := filepath.Join(tmpDir, "task-config-bad.yaml")
+	require.NoError(t, os.WriteFile(badTaskPath, []byte(badTaskYAML), 0644))
+
+	_, err = LoadConfig(
+		WithAdapterConfigPath(adapterPath),
+		WithTaskConfigPath(badTaskPath),
+		WithSkipSemanticValidation(),
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "event_schemas[0].schema")
+
+	// Duplicate event types and schema+schema_ref are structural errors
+	dupTaskYAML := `
+event_schemas:
+  - event_type: "io.hyperfleet.cluster.updated"
+    schema: {type: object}
+  - event_type: "io.hyperfleet.cluster.updated"
+    schema: {type: object}
+`
+	dupTaskPath := filepath.Join(tmpDir, "task-config-dup.yaml")
+	require.NoError(t, os.WriteFile(dupTaskPath, []byte(dupTaskYAML), 0644))
+	_, err = LoadConfig(
+		WithAdapterConfigPath(adapterPath),
+		WithTaskConfigPath(dupTaskPath),
+		WithSkipSemanticValidation(),
+	)
+	require.Error(t, err)
+}
@@ -0,0 +1,338 @@
+package configloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/criteria"
+)
+
+// JSONSchemaDialect is the JSON Schema draft of the generated config schemas
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Config file types a JSON Schema is generated for
+const (
+	// ConfigTypeAdapter is the deployment config file (AdapterConfig)
+	ConfigTypeAdapter = "adapter"
+	// ConfigTypeTask is the task config file (AdapterTaskConfig)
+	ConfigTypeTask = "task"
+)
+
+// durationType is the Go type of duration fields, written as "10s" or in nanoseconds
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// GenerateJSONSchema returns the JSON Schema of a config file, ConfigTypeAdapter
+// or ConfigTypeTask. The schema is derived from the config structs: properties
+// from the yaml tags, required fields, enums, minimums and mutually exclusive
+// fields from the validate tags, and enums the struct validator does not check
+// from jsonschema:"enum=a b" tags. Unknown properties are rejected, as by the
+// loader.
+func GenerateJSONSchema(configType string) (map[string]interface{}, error) {
+	var root reflect.Type
+	var title string
+	switch configType {
+	case ConfigTypeAdapter:
+		root, title = reflect.TypeOf(AdapterConfig{}), "HyperFleet adapter config"
+	case ConfigTypeTask:
+		root, title = reflect.TypeOf(AdapterTaskConfig{}), "HyperFleet adapter task config"
+	default:
+		return nil, fmt.Errorf("unknown config type %q: must be %q or %q", configType, ConfigTypeAdapter, ConfigTypeTask)
+	}
+
+	g := &schemaGenerator{defs: make(map[string]interface{})}
+	schema := g.structSchema(root)
+	schema["$schema"] = JSONSchemaDialect
+	schema["title"] = title
+	schema["$defs"] = g.defs
+	return schema, nil
+}
+
+type schemaGenerator struct {
+	defs map[string]interface{}
+}
+
+// typeSchema returns the schema of a value of type t: a $ref for structs
+func (g *schemaGenerator) typeSchema(t reflect.Type) map[string]interface{} {
+	if t == durationType {
+		return map[string]interface{}{
+			"type":        []interface{}{"string", "integer"},
+			"description": "Duration such as 10s or 1m30s",
+		}
+	}
+	switch t.Kind() { //nolint:exhaustive // config structs only use these kinds
+	case reflect.Ptr:
+		return g.typeSchema(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.typeSchema(t.Elem())}
+	case reflect.Struct:
+		if _, ok := g.defs[t.Name()]; !ok {
+			// Reserve the name first: struct types may be recursive
+			g.defs[t.Name()] = nil
+			g.defs[t.Name()] = g.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+	default:
+		// interface{}: any value
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema returns the object schema of struct type t
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	// exclusive are sets of fields of which exactly one must be set
+	exclusive := make(map[string][]string)
+	// atLeastOne are sets of fields of which at least one must be set
+	atLeastOne := make(map[string][]string)
+
+	// addFields adds the fields of t; rules are the validate tags of its fields,
+	// skipped for an inlined struct tagged validate:"-"
+	var addFields func(t reflect.Type, rules bool)
+	addFields = func(t reflect.Type, rules bool) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			yamlName, yamlOpts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if field.Anonymous && strings.Contains(yamlOpts, "inline") {
+				addFields(field.Type, rules && field.Tag.Get("validate") != "-")
+				continue
+			}
+			if !field.IsExported() || yamlName == "-" || yamlName == "" {
+				continue
+			}
+
+			property := g.typeSchema(field.Type)
+			// rules after dive apply to the elements, not to the field itself
+			fieldRules, _, _ := strings.Cut(field.Tag.Get("validate"), ",dive")
+			if !rules {
+				fieldRules = ""
+			}
+			for _, rule := range strings.Split(fieldRules, ",") {
+				name, param, _ := strings.Cut(rule, "=")
+				switch name {
+				case "required":
+					required = append(required, yamlName)
+				case "oneof":
+					property["enum"] = stringsToValues(strings.Fields(param))
+				case "gte":
+					if minimum, err := strconv.ParseFloat(param, 64); err == nil && field.Type != durationType {
+						property["minimum"] = minimum
+					}
+				case "min":
+					if minimum, err := strconv.Atoi(param); err == nil && field.Type.Kind() == reflect.Map {
+						property["minProperties"] = minimum
+					}
+				case "validoperator":
+					property["enum"] = stringsToValues(criteria.OperatorStrings())
+				case "resourcename":
+					property["pattern"] = resourceNamePattern.String()
+				case "digest":
+					property["pattern"] = digestPattern.String()
+				case "required_without":
+					pair := []string{yamlName, yamlFieldNameIn(t, param)}
+					sort.Strings(pair)
+					exclusive[strings.Join(pair, ",")] = pair
+				case "required_without_all":
+					set := []string{yamlName}
+					for _, other := range strings.Fields(param) {
+						set = append(set, yamlFieldNameIn(t, other))
+					}
+					sort.Strings(set)
+					atLeastOne[strings.Join(set, ",")] = set
+				}
+			}
+			if enum, ok := strings.CutPrefix(field.Tag.Get("jsonschema"), "enum="); ok {
+				property["enum"] = stringsToValues(strings.Fields(enum))
+			}
+			properties[yamlName] = property
+		}
+	}
+	addFields(t, true)
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	// Condition accepts "value" or its alias "values", see Condition.UnmarshalYAML
+	if t == reflect.TypeOf(Condition{}) {
+		properties["value"] = map[string]interface{}{}
+		properties["values"] = map[string]interface{}{}
+		schema["not"] = map[string]interface{}{"required": []interface{}{"value", "values"}}
+	}
+	if len(required) > 0 {
+		schema["required"] = stringsToValues(required)
+	}
+	// CaptureField has exactly one of field, expression and header, see validateCaptureField
+	if t == reflect.TypeOf(CaptureField{}) {
+		exclusive["expression,field,header"] = []string{FieldExpression, FieldField, FieldHeader}
+	}
+
+	var allOf []interface{}
+	for _, key := range sortedKeys(exclusive) {
+		var oneOf []interface{}
+		for _, name := range exclusive[key] {
+			oneOf = append(oneOf, map[string]interface{}{"required": []interface{}{name}})
+		}
+		allOf = append(allOf, map[string]interface{}{"oneOf": oneOf})
+	}
+	for _, key := range sortedKeys(atLeastOne) {
+		var anyOf []interface{}
+		for _, name := range atLeastOne[key] {
+			anyOf = append(anyOf, map[string]interface{}{"required": []interface{}{name}})
+		}
+		allOf = append(allOf, map[string]interface{}{"anyOf": anyOf})
+	}
+	if len(allOf) > 0 {
+		schema["allOf"] = allOf
+	}
+	return schema
+}
+
+// yamlFieldNameIn returns the yaml name of the field of t named by a validate
+// tag parameter, e.g. "BuildRef" or "ActionBase.APICall"
+func yamlFieldNameIn(t reflect.Type, goPath string) string {
+	for _, goName := range strings.Split(goPath, ".") {
+		field, ok := t.FieldByName(goName)
+		if !ok {
+			return goPath
+		}
+		if field.Anonymous {
+			t = field.Type
+			continue
+		}
+		return extractYamlTagName(field)
+	}
+	return goPath
+}
+
+func stringsToValues(values []string) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var (
+	compiledConfigSchemas   = make(map[string]*jsonschema.Schema)
+	compiledConfigSchemasMu sync.Mutex
+)
+
+// compiledConfigSchema returns the compiled schema of configType, compiled once
+func compiledConfigSchema(configType string) (*jsonschema.Schema, error) {
+	compiledConfigSchemasMu.Lock()
+	defer compiledConfigSchemasMu.Unlock()
+	if compiled, ok := compiledConfigSchemas[configType]; ok {
+		return compiled, nil
+	}
+	schema, err := GenerateJSONSchema(configType)
+	if err != nil {
+		return nil, err
+	}
+	// Round-trip through JSON so the schema uses the JSON types of the validator
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s config schema: %w", configType, err)
+	}
+	normalized, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s config schema: %w", configType, err)
+	}
+	url := "https://hyperfleet.local/config-schemas/" + configType + ".json"
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, normalized); err != nil {
+		return nil, fmt.Errorf("failed to add %s config schema: %w", configType, err)
+	}
+	compiled, err := compiler.Compile(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile %s config schema: %w", configType, err)
+	}
+	compiledConfigSchemas[configType] = compiled
+	return compiled, nil
+}
+
+// ValidateAgainstSchema validates the YAML config file content data against the
+// JSON Schema of configType. Returns a *ValidationErrors listing the violations.
+func ValidateAgainstSchema(configType string, data []byte) error {
+	compiled, err := compiledConfigSchema(configType)
+	if err != nil {
+		return err
+	}
+
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse %s config YAML: %w", configType, err)
+	}
+	// Round-trip through JSON so the validator gets JSON types
+	jsonData, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to convert %s config to JSON: %w", configType, err)
+	}
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to convert %s config to JSON: %w", configType, err)
+	}
+
+	err = compiled.Validate(instance)
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	errs := &ValidationErrors{}
+	for _, violation := range schemaViolations(validationErr) {
+		errs.Add(jsonPointerToPath(violation.Pointer), violation.Message)
+	}
+	return errs
+}
+
+// jsonPointerToPath converts a JSON pointer to the path notation of
+// ValidationError, e.g. /resources/0/name to resources[0].name
+func jsonPointerToPath(pointer string) string {
+	if pointer == "" {
+		return "(root)"
+	}
+	var path strings.Builder
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if _, err := strconv.Atoi(token); err == nil {
+			path.WriteString("[" + token + "]")
+			continue
+		}
+		if path.Len() > 0 {
+			path.WriteString(".")
+		}
+		path.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~"))
+	}
+	return path.String()
+}
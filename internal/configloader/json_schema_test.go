@@ -0,0 +1,209 @@
+package configloader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The config files shipped with the repo must match the generated schemas
+func TestConfigFilesMatchJSONSchema(t *testing.T) {
+	root := filepath.Join("..", "..")
+	files := map[string][]string{
+		ConfigTypeAdapter: {
+			"configs/adapter-config-template.yaml",
+			"charts/examples/kubernetes/adapter-config.yaml",
+			"charts/examples/maestro/adapter-config.yaml",
+			"test/testdata/adapter-config.yaml",
+			"test/testdata/dryrun/dryrun-kubernetes-adapter-config.yaml",
+			"test/testdata/dryrun/dryrun-maestro-adapter-config.yaml",
+		},
+		ConfigTypeTask: {
+			"configs/adapter-task-config-template.yaml",
+			"charts/examples/kubernetes/adapter-task-config.yaml",
+			"charts/examples/maestro/adapter-task-config.yaml",
+			"test/testdata/task-config.yaml",
+			"test/testdata/dryrun/dryrun-kubernetes-task-config.yaml",
+			"test/testdata/dryrun/dryrun-maestro-adapter-task-config.yaml",
+			"test/testdata/dryrun/dryrun-cel-showcase-task-config.yaml",
+		},
+	}
+	for configType, paths := range files {
+		for _, path := range paths {
+			t.Run(path, func(t *testing.T) {
+				data, err := os.ReadFile(filepath.Join(root, path))
+				require.NoError(t, err)
+				assert.NoError(t, ValidateAgainstSchema(configType, data))
+			})
+		}
+	}
+}
+
+func TestValidateAgainstSchema(t *testing.T) {
+	tests := []struct {
+		name      string
+		yaml      string
+		wantPaths []string
+	}{
+		{
+			name: "valid",
+			yaml: `
+params:
+  - name: clusterId
+    source: event.id
+    required: true
+resources:
+  - name: configMap
+    transport:
+      client: kubernetes
+    manifest:
+      apiVersion: v1
+      kind: ConfigMap
+    discovery:
+      by_name: cm-{{ .clusterId }}
+`,
+		},
+		{
+			name: "unknown field",
+			yaml: `
+params:
+  - name: clusterId
+    source: event.id
+    sourc: typo
+`,
+			wantPaths: []string{"params[0]"},
+		},
+		{
+			name: "invalid enum",
+			yaml: `
+preconditions:
+  - name: check
+    conditions:
+      - field: status
+        operator: looksLike
+        value: Ready
+`,
+			wantPaths: []string{"preconditions[0].conditions[0].operator"},
+		},
+		{
+			name: "both build and build_ref",
+			yaml: `
+post:
+  payloads:
+    - name: status
+      build:
+        ok: true
+      build_ref: payload.yaml
+`,
+			wantPaths: []string{"post.payloads[0]"},
+		},
+		{
+			name: "value and values",
+			yaml: `
+preconditions:
+  - name: check
+    conditions:
+      - field: status
+        operator: in
+        value: [a]
+        values: [b]
+`,
+			wantPaths: []string{"preconditions[0].conditions[0]"},
+		},
+		{
+			name: "header capture",
+			yaml: `
+preconditions:
+  - name: clusters
+    api_call:
+      method: GET
+      url: /clusters
+    capture:
+      - name: totalCount
+        source: header
+        header: X-Total-Count
+        default: "0"
+      - name: kind
+        field: kind
+`,
+		},
+		{
+			name: "capture with field and header",
+			yaml: `
+preconditions:
+  - name: clusters
+    api_call:
+      method: GET
+      url: /clusters
+    capture:
+      - name: totalCount
+        source: header
+        header: X-Total-Count
+        field: total
+`,
+			wantPaths: []string{"preconditions[0].capture[0]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAgainstSchema(ConfigTypeTask, []byte(tt.yaml))
+			if len(tt.wantPaths) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var errs *ValidationErrors
+			require.ErrorAs(t, err, &errs)
+			var paths []string
+			for _, e := range errs.Errors {
+				paths = append(paths, e.Path)
+			}
+			for _, want := range tt.wantPaths {
+				assert.Contains(t, paths, want, "errors: %v", errs)
+			}
+		})
+	}
+}
+
+func TestGenerateJSONSchema_UnknownType(t *testing.T) {
+	_, err := GenerateJSONSchema("deployment")
+	assert.Error(t, err)
+}
+
+func TestLoadConfigWithSchemaValidation(t *testing.T) {
+	taskYAML := `
+params:
+  - name: clusterId
+    source: event.id
+`
+	adapterYAML := `
+adapter:
+  name: schema-test
+  version: "0.1.0"
+clients:
+  hyperfleet_api:
+    base_url: https://test.example.com
+    retry_backoff: %s
+`
+	adapterPath, taskPath := createTestConfigFiles(t, t.TempDir(), fmt.Sprintf(adapterYAML, "linear"), taskYAML)
+	_, err := LoadConfig(
+		WithAdapterConfigPath(adapterPath),
+		WithTaskConfigPath(taskPath),
+		WithSkipSemanticValidation(),
+		WithSchemaValidation(),
+	)
+	require.NoError(t, err)
+
+	adapterPath, taskPath = createTestConfigFiles(t, t.TempDir(), fmt.Sprintf(adapterYAML, "fibonacci"), taskYAML)
+	_, err = LoadConfig(
+		WithAdapterConfigPath(adapterPath),
+		WithTaskConfigPath(taskPath),
+		WithSkipSemanticValidation(),
+		WithSchemaValidation(),
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "clients.hyperfleet_api.retry_backoff")
+}
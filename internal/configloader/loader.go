@@ -1,9 +1,13 @@
 package configloader
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/utils"
 	"gopkg.in/yaml.v3"
@@ -33,8 +37,10 @@ type loadOptions struct {
 	adapterConfigPath      string
 	taskConfigPath         string
 	flags                  interface{} // *pflag.FlagSet
+	warnings               *ValidationErrors
 	adapterVersion         string
 	skipSemanticValidation bool
+	schemaValidation       bool
 }
 
 // WithAdapterConfigPath sets the path to the deployment config file
@@ -72,6 +78,22 @@ func WithSkipSemanticValidation() LoadOption {
 	}
 }
 
+// WithSchemaValidation validates the config files against the JSON Schemas
+// generated by GenerateJSONSchema before the struct validation
+func WithSchemaValidation() LoadOption {
+	return func(o *loadOptions) {
+		o.schemaValidation = true
+	}
+}
+
+// WithWarnings collects the semantic validation warnings into warnings.
+// Warnings never make LoadConfig fail.
+func WithWarnings(warnings *ValidationErrors) LoadOption {
+	return func(o *loadOptions) {
+		o.warnings = warnings
+	}
+}
+
 // -----------------------------------------------------------------------------
 // Public API
 // -----------------------------------------------------------------------------
@@ -98,6 +120,12 @@ func LoadConfig(opts ...LoadOption) (*Config, error) {
 		return nil, fmt.Errorf("failed to get base directory for adapter config: %w", errBaseDir)
 	}
 
+	if o.schemaValidation {
+		if err = validateFileAgainstSchema(ConfigTypeAdapter, resolvedAdapterConfigPath); err != nil {
+			return nil, fmt.Errorf("adapter config schema validation failed: %w", err)
+		}
+	}
+
 	// Validate AdapterConfig structure
 	adapterValidator := NewAdapterConfigValidator(adapterCfg, adapterBaseDir)
 	if err = adapterValidator.ValidateStructure(); err != nil {
@@ -117,6 +145,10 @@ func LoadConfig(opts ...LoadOption) (*Config, error) {
 		return nil, fmt.Errorf("failed to load task config: %w", err)
 	}
 
+	if err = expandVarsEnv(taskCfg.Vars); err != nil {
+		return nil, fmt.Errorf("failed to load task config vars: %w", err)
+	}
+
 	// Get base directory from task config path
 	taskConfigPath := o.taskConfigPath
 	if taskConfigPath == "" {
@@ -131,6 +163,17 @@ func LoadConfig(opts ...LoadOption) (*Config, error) {
 		}
 	}
 
+	if o.schemaValidation && taskConfigPath != "" {
+		if err = validateFileAgainstSchema(ConfigTypeTask, taskConfigPath); err != nil {
+			return nil, fmt.Errorf("task config schema validation failed: %w", err)
+		}
+	}
+
+	sources := []string{absPath(resolvedAdapterConfigPath)}
+	if taskConfigPath != "" {
+		sources = append(sources, absPath(taskConfigPath))
+	}
+
 	// Validate AdapterTaskConfig structure
 	taskValidator := NewTaskConfigValidator(taskCfg, taskBaseDir)
 	if err := taskValidator.ValidateStructure(); err != nil {
@@ -143,14 +186,26 @@ func LoadConfig(opts ...LoadOption) (*Config, error) {
 			return nil, fmt.Errorf("task config file reference validation failed: %w", err)
 		}
 
-		if err := loadTaskConfigFileReferences(taskCfg, taskBaseDir); err != nil {
+		var refSources []string
+		refSources, err = loadTaskConfigFileReferences(taskCfg, taskBaseDir)
+		if err != nil {
 			return nil, fmt.Errorf("failed to load task config file references: %w", err)
 		}
+		sources = append(sources, refSources...)
+	}
+
+	// Compile event schemas once so the executor can validate event data
+	if err := CompileEventSchemas(taskCfg.EventSchemas); err != nil {
+		return nil, fmt.Errorf("task config event schema validation failed: %w", err)
 	}
 
 	// Semantic validation for task config (optional)
 	if !o.skipSemanticValidation {
-		if err := taskValidator.ValidateSemantic(); err != nil {
+		err := taskValidator.ValidateSemantic()
+		if o.warnings != nil {
+			o.warnings.Extend(taskValidator.Warnings())
+		}
+		if err != nil {
 			return nil, fmt.Errorf("task config semantic validation failed: %w", err)
 		}
 	}
@@ -160,68 +215,301 @@ func LoadConfig(opts ...LoadOption) (*Config, error) {
 	if config == nil {
 		return nil, fmt.Errorf("failed to merge configurations")
 	}
+	config.Sources = sources
+
+	if err := validateMerged(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate validates a deployment and a task config built in code, like
+// LoadConfig validates the files it loads, and returns their unified Config.
+// Environment variables and CLI flags are not applied, and the task config must
+// not reference files (build_ref, manifest_ref and other *_ref fields).
+func Validate(adapterCfg *AdapterConfig, taskCfg *AdapterTaskConfig) (*Config, error) {
+	if adapterCfg == nil || taskCfg == nil {
+		return nil, fmt.Errorf("adapter config and task config are required")
+	}
+	if err := NewAdapterConfigValidator(adapterCfg, "").ValidateStructure(); err != nil {
+		return nil, fmt.Errorf("adapter config validation failed: %w", err)
+	}
+	if err := expandVarsEnv(taskCfg.Vars); err != nil {
+		return nil, fmt.Errorf("failed to load task config vars: %w", err)
+	}
+	taskValidator := NewTaskConfigValidator(taskCfg, "")
+	if err := taskValidator.ValidateStructure(); err != nil {
+		return nil, fmt.Errorf("task config validation failed: %w", err)
+	}
+	if err := CompileEventSchemas(taskCfg.EventSchemas); err != nil {
+		return nil, fmt.Errorf("task config event schema validation failed: %w", err)
+	}
+	if err := taskValidator.ValidateSemantic(); err != nil {
+		return nil, fmt.Errorf("task config semantic validation failed: %w", err)
+	}
 
+	config := Merge(adapterCfg, taskCfg)
+	if config == nil {
+		return nil, fmt.Errorf("failed to merge configurations")
+	}
+	if err := validateMerged(config); err != nil {
+		return nil, err
+	}
 	return config, nil
 }
 
+// validateMerged runs the validations that span the deployment and task configs
+func validateMerged(config *Config) error {
+	if err := ValidateAPITargets(config); err != nil {
+		return fmt.Errorf("API target validation failed: %w", err)
+	}
+	if err := ValidateNotMetMaxDelay(config); err != nil {
+		return fmt.Errorf("not_met_backoff validation failed: %w", err)
+	}
+	if err := ValidateResourcePolicy(config); err != nil {
+		return fmt.Errorf("resource policy validation failed: %w", err)
+	}
+	return nil
+}
+
 // -----------------------------------------------------------------------------
 // Internal Functions
 // -----------------------------------------------------------------------------
 
-// loadTaskConfigFileReferences loads content from file references into the task config
-func loadTaskConfigFileReferences(config *AdapterTaskConfig, baseDir string) error {
-	// Load manifest.ref in resources
-	for i := range config.Resources {
-		resource := &config.Resources[i]
+// validateFileAgainstSchema validates the config file at path against the JSON Schema of configType
+func validateFileAgainstSchema(configType, path string) error {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("failed to read %s config file %q: %w", configType, path, err)
+	}
+	return ValidateAgainstSchema(configType, data)
+}
+
+// envReference matches a ${NAME} environment variable reference in a var
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandVarsEnv substitutes ${NAME} environment variable references in the strings
+// of vars, recursing into lists and maps. A reference to an unset variable is an error.
+func expandVarsEnv(vars map[string]interface{}) error {
+	var expand func(value interface{}, path string) (interface{}, error)
+	expand = func(value interface{}, path string) (interface{}, error) {
+		switch v := value.(type) {
+		case string:
+			var missing string
+			expanded := envReference.ReplaceAllStringFunc(v, func(ref string) string {
+				name := envReference.FindStringSubmatch(ref)[1]
+				envValue, ok := os.LookupEnv(name)
+				if !ok && missing == "" {
+					missing = name
+				}
+				return envValue
+			})
+			if missing != "" {
+				return nil, fmt.Errorf("%s: environment variable %s not set", path, missing)
+			}
+			return expanded, nil
+		case []interface{}:
+			for i, item := range v {
+				expanded, err := expand(item, fmt.Sprintf("%s[%d]", path, i))
+				if err != nil {
+					return nil, err
+				}
+				v[i] = expanded
+			}
+		case map[string]interface{}:
+			for key, item := range v {
+				expanded, err := expand(item, path+"."+key)
+				if err != nil {
+					return nil, err
+				}
+				v[key] = expanded
+			}
+		}
+		return value, nil
+	}
+	_, err := expand(vars, FieldVars)
+	return err
+}
+
+// loadTaskConfigFileReferences loads content from file references into the task config.
+// Returns the paths of the loaded files.
+func loadTaskConfigFileReferences(config *AdapterTaskConfig, baseDir string) ([]string, error) {
+	sources, err := loadResourceFileReferences(config.Resources, baseDir, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Load schema_ref in event_schemas
+	for i := range config.EventSchemas {
+		schema := &config.EventSchemas[i]
+		if schema.SchemaRef == "" {
+			continue
+		}
+		fullPath, content, err := loadYAMLFile(baseDir, schema.SchemaRef, "")
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d].%s: %w", FieldEventSchemas, i, FieldSchemaRef, err)
+		}
+		schema.SchemaRefContent = content
+		sources = append(sources, fullPath)
+	}
+
+	postSources, err := loadPostFileReferences(config.Post, baseDir, "")
+	if err != nil {
+		return nil, err
+	}
+	sources = append(sources, postSources...)
+
+	for i := range config.Workflows {
+		workflow := &config.Workflows[i]
+		prefix := fmt.Sprintf("%s[%d].", FieldWorkflows, i)
+		resourceSources, err := loadResourceFileReferences(workflow.Resources, baseDir, prefix)
+		if err != nil {
+			return nil, err
+		}
+		postSources, err := loadPostFileReferences(workflow.Post, baseDir, prefix)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(append(sources, resourceSources...), postSources...)
+	}
+	return sources, nil
+}
+
+// loadResourceFileReferences loads the manifest references of the top-level
+// resources or of those of the workflow whose field path is prefix.
+// Returns the paths of the loaded files.
+func loadResourceFileReferences(resources []Resource, baseDir, prefix string) ([]string, error) {
+	var sources []string
+	// Load manifest.ref and manifest_ref in resources
+	for i := range resources {
+		resource := &resources[i]
 		ref := resource.GetManifestRef()
+		field := FieldManifest + "." + FieldRef
+		if resource.ManifestRef != "" {
+			ref = resource.ManifestRef
+			field = FieldManifestRef
+		}
 		if ref == "" {
 			continue
 		}
 
-		content, err := loadYAMLFile(baseDir, ref)
+		fullPath, content, err := loadManifestFile(baseDir, ref, resource.ManifestRefDigest)
 		if err != nil {
-			return fmt.Errorf("%s[%d].%s.%s: %w", FieldResources, i, FieldManifest, FieldRef, err)
+			return nil, fmt.Errorf("%s%s[%d].%s of resource %q: %w", prefix, FieldResources, i, field, resource.Name, err)
 		}
 
 		// Replace manifest with loaded content
 		resource.Manifest = content
+		resource.ManifestRef = ""
+		resource.ManifestRefDigest = ""
+		sources = append(sources, fullPath)
 	}
+	return sources, nil
+}
 
+// loadPostFileReferences loads the build references of the payloads of the
+// top-level post or of the post of the workflow whose field path is prefix.
+// Returns the paths of the loaded files.
+func loadPostFileReferences(post *PostConfig, baseDir, prefix string) ([]string, error) {
+	var sources []string
 	// Load buildRef in post.payloads
-	if config.Post != nil {
-		for i := range config.Post.Payloads {
-			payload := &config.Post.Payloads[i]
+	if post != nil {
+		for i := range post.Payloads {
+			payload := &post.Payloads[i]
 			if payload.BuildRef != "" {
-				content, err := loadYAMLFile(baseDir, payload.BuildRef)
+				fullPath, content, err := loadYAMLFile(baseDir, payload.BuildRef, payload.BuildRefDigest)
 				if err != nil {
-					return fmt.Errorf("%s.%s[%d].%s: %w", FieldPost, FieldPayloads, i, FieldBuildRef, err)
+					return nil, fmt.Errorf("%s%s.%s[%d].%s: %w", prefix, FieldPost, FieldPayloads, i, FieldBuildRef, err)
 				}
 				payload.BuildRefContent = content
+				sources = append(sources, fullPath)
 			}
 		}
 	}
-
-	return nil
+	return sources, nil
 }
 
-// loadYAMLFile loads and parses a YAML file
-func loadYAMLFile(baseDir, refPath string) (map[string]interface{}, error) {
+// loadYAMLFile loads and parses a YAML file, verifying its content against
+// digest unless empty. Returns the resolved path with the content.
+func loadYAMLFile(baseDir, refPath, digest string) (string, map[string]interface{}, error) {
 	fullPath, err := resolvePath(baseDir, refPath)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
 	data, err := os.ReadFile(filepath.Clean(fullPath))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file %q: %w", fullPath, err)
+		return "", nil, fmt.Errorf("failed to read file %q: %w", fullPath, err)
+	}
+	if err := verifyDigest(fullPath, data, digest); err != nil {
+		return "", nil, err
 	}
 
 	var content map[string]interface{}
 	if err := yaml.Unmarshal(data, &content); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML file %q: %w", fullPath, err)
+		return "", nil, fmt.Errorf("failed to parse YAML file %q: %w", fullPath, err)
 	}
 
-	return content, nil
+	return fullPath, content, nil
+}
+
+// loadManifestFile loads a manifest file of one or more YAML documents. Empty and
+// comment-only documents are skipped; several documents are returned as the items
+// of a v1 List. The content is verified against digest unless empty. Returns the
+// resolved path with the manifest.
+func loadManifestFile(baseDir, refPath, digest string) (string, map[string]interface{}, error) {
+	fullPath, err := resolvePath(baseDir, refPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Clean(fullPath))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read file %q: %w", fullPath, err)
+	}
+	if err := verifyDigest(fullPath, data, digest); err != nil {
+		return "", nil, err
+	}
+
+	var items []interface{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for i := 0; ; i++ {
+		var document map[string]interface{}
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to parse YAML document %d of file %q: %w", i, fullPath, err)
+		}
+		if len(document) > 0 {
+			items = append(items, document)
+		}
+	}
+
+	switch len(items) {
+	case 0:
+		return "", nil, fmt.Errorf("file %q contains no manifest", fullPath)
+	case 1:
+		manifest, ok := items[0].(map[string]interface{})
+		if !ok {
+			return "", nil, fmt.Errorf("file %q does not contain a YAML mapping", fullPath)
+		}
+		return fullPath, manifest, nil
+	}
+	return fullPath, map[string]interface{}{
+		FieldAPIVersion: "v1",
+		FieldKind:       KindList,
+		FieldItems:      items,
+	}, nil
+}
+
+// absPath returns the absolute form of path, or path itself if it cannot be resolved
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
 }
 
 // resolvePath resolves a relative path against the base directory and validates
@@ -1,11 +1,14 @@
 package configloader
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
@@ -106,6 +109,44 @@ resources:
 	assert.Equal(t, "testNamespace", config.Resources[0].Name)
 }
 
+func TestLoadConfigVars(t *testing.T) {
+	taskYAML := `
+vars:
+  team: "platform"
+  replicas: 3
+  regions: ["us-east-1", "${TEST_VARS_REGION}"]
+  labels:
+    environment: "${TEST_VARS_ENV}"
+    owner: "team-${TEST_VARS_ENV}-$HOME"
+preconditions:
+  - name: "inProduction"
+    expression: 'labels.environment == "prod" && team == "platform"'
+`
+	adapterPath, taskPath := createTestConfigFiles(t, t.TempDir(), testAdapterConfigYAML, taskYAML)
+	t.Setenv("TEST_VARS_REGION", "eu-west-1")
+	t.Setenv("TEST_VARS_ENV", "prod")
+
+	config, err := LoadConfig(WithAdapterConfigPath(adapterPath), WithTaskConfigPath(taskPath))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"team":     "platform",
+		"replicas": 3,
+		"regions":  []interface{}{"us-east-1", "eu-west-1"},
+		"labels": map[string]interface{}{
+			"environment": "prod",
+			"owner":       "team-prod-$HOME",
+		},
+	}, config.Vars)
+
+	t.Run("unset environment variable", func(t *testing.T) {
+		adapterPath, taskPath := createTestConfigFiles(t, t.TempDir(), testAdapterConfigYAML,
+			"vars:\n  labels:\n    owner: \"${TEST_VARS_UNSET}\"\n")
+		_, err := LoadConfig(WithAdapterConfigPath(adapterPath), WithTaskConfigPath(taskPath))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "vars.labels.owner: environment variable TEST_VARS_UNSET not set")
+	})
+}
+
 func TestLoadConfigMissingAdapterConfig(t *testing.T) {
 	tmpDir := t.TempDir()
 	taskPath := filepath.Join(tmpDir, "task-config.yaml")
@@ -145,6 +186,56 @@ clients:
 	assert.Contains(t, err.Error(), "failed to load task config")
 }
 
+func TestValidate(t *testing.T) {
+	newConfigs := func(t *testing.T) (*AdapterConfig, *AdapterTaskConfig) {
+		var adapterCfg AdapterConfig
+		require.NoError(t, yaml.Unmarshal([]byte(testAdapterConfigYAML), &adapterCfg))
+		var taskCfg AdapterTaskConfig
+		require.NoError(t, yaml.Unmarshal([]byte(`
+params:
+  - name: "clusterId"
+    source: "event.id"
+preconditions:
+  - name: "clusterStatus"
+    api_call:
+      method: "GET"
+      url: "/clusters/{{ .clusterId }}"
+`), &taskCfg))
+		return &adapterCfg, &taskCfg
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		adapterCfg, taskCfg := newConfigs(t)
+		config, err := Validate(adapterCfg, taskCfg)
+		require.NoError(t, err)
+		assert.Equal(t, "test-adapter", config.Adapter.Name)
+		require.Len(t, config.Preconditions, 1)
+		assert.Empty(t, config.Sources)
+	})
+
+	t.Run("invalid adapter config", func(t *testing.T) {
+		adapterCfg, taskCfg := newConfigs(t)
+		adapterCfg.Adapter.Name = ""
+		_, err := Validate(adapterCfg, taskCfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "adapter config validation failed")
+	})
+
+	t.Run("semantic error in task config", func(t *testing.T) {
+		adapterCfg, taskCfg := newConfigs(t)
+		taskCfg.Preconditions[0].APICall.URL = "/clusters/{{ .undefined }}"
+		_, err := Validate(adapterCfg, taskCfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "task config semantic validation failed")
+	})
+
+	t.Run("nil config", func(t *testing.T) {
+		adapterCfg, _ := newConfigs(t)
+		_, err := Validate(adapterCfg, nil)
+		assert.Error(t, err)
+	})
+}
+
 func TestAdapterConfigValidation(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -189,6 +280,172 @@ clients:
 `,
 			wantError: false,
 		},
+		{
+			name: "broker rate limit",
+			yaml: `
+adapter:
+  name: test-adapter
+clients:
+  broker:
+    rate_limit:
+      events_per_second: 10
+      burst: 20
+`,
+			wantError: false,
+		},
+		{
+			name: "audit sinks",
+			yaml: `
+adapter:
+  name: test-adapter
+audit:
+  file:
+    path: /var/log/audit.jsonl
+  http:
+    url: https://audit.example.com/records
+    headers:
+      Authorization: Bearer abc
+    retry_attempts: 5
+`,
+			wantError: false,
+		},
+		{
+			name: "audit http without url",
+			yaml: `
+adapter:
+  name: test-adapter
+audit:
+  http:
+    timeout: 5s
+`,
+			wantError: true,
+			errorMsg:  "audit.http.url is required",
+		},
+		{
+			name: "negative broker rate limit",
+			yaml: `
+adapter:
+  name: test-adapter
+clients:
+  broker:
+    rate_limit:
+      events_per_second: -1
+`,
+			wantError: true,
+			errorMsg:  "clients.broker.rate_limit.events_per_second: must be greater than or equal to 0",
+		},
+		{
+			name: "negative broker rate limit burst",
+			yaml: `
+adapter:
+  name: test-adapter
+clients:
+  broker:
+    rate_limit:
+      events_per_second: 1
+      burst: -5
+`,
+			wantError: true,
+			errorMsg:  "clients.broker.rate_limit.burst",
+		},
+		{
+			name: "negative broker requeue max delay",
+			yaml: `
+adapter:
+  name: test-adapter
+clients:
+  broker:
+    requeue:
+      max_delay: -1m
+`,
+			wantError: true,
+			errorMsg:  "clients.broker.requeue.max_delay",
+		},
+		{
+			name: "invalid broker dedup store",
+			yaml: `
+adapter:
+  name: test-adapter
+clients:
+  broker:
+    dedup:
+      store: redis
+`,
+			wantError: true,
+			errorMsg:  "clients.broker.dedup.store",
+		},
+		{
+			name: "broker subscriptions",
+			yaml: `
+adapter:
+  name: test-adapter
+clients:
+  broker:
+    start_failure_policy: degraded
+    subscriptions:
+      - subscription_id: cluster-events
+        topic: clusters
+      - subscription_id: nodepool-events
+        topic: nodepools
+        flow_control:
+          parallelism: 2
+          max_outstanding_messages: 50
+`,
+			wantError: false,
+		},
+		{
+			name: "broker subscription without topic",
+			yaml: `
+adapter:
+  name: test-adapter
+clients:
+  broker:
+    subscriptions:
+      - subscription_id: cluster-events
+`,
+			wantError: true,
+			errorMsg:  "clients.broker.subscriptions[0].topic",
+		},
+		{
+			name: "duplicate broker subscriptions",
+			yaml: `
+adapter:
+  name: test-adapter
+clients:
+  broker:
+    subscriptions:
+      - subscription_id: cluster-events
+        topic: clusters
+      - subscription_id: cluster-events
+        topic: nodepools
+`,
+			wantError: true,
+			errorMsg:  "clients.broker.subscriptions",
+		},
+		{
+			name: "invalid broker start failure policy",
+			yaml: `
+adapter:
+  name: test-adapter
+clients:
+  broker:
+    start_failure_policy: ignore
+`,
+			wantError: true,
+			errorMsg:  "clients.broker.start_failure_policy",
+		},
+		{
+			name: "negative max event bytes",
+			yaml: `
+adapter:
+  name: test-adapter
+clients:
+  broker:
+    max_event_bytes: -1
+`,
+			wantError: true,
+			errorMsg:  "clients.broker.max_event_bytes",
+		},
 	}
 
 	for _, tt := range tests {
@@ -251,7 +508,7 @@ params:
     required: true
 `,
 			wantError: true,
-			errorMsg:  "source is required",
+			errorMsg:  "must have either 'source' or 'value_from' set",
 		},
 	}
 
@@ -337,6 +594,27 @@ preconditions:
 			wantError: true,
 			errorMsg:  "is invalid (allowed:",
 		},
+		{
+			name: "precondition with retry_after",
+			yaml: `
+preconditions:
+  - name: "clusterReady"
+    expression: "false"
+    retry_after: 30s
+`,
+			wantError: false,
+		},
+		{
+			name: "precondition with negative retry_after",
+			yaml: `
+preconditions:
+  - name: "clusterReady"
+    expression: "false"
+    retry_after: -30s
+`,
+			wantError: true,
+			errorMsg:  "retry_after",
+		},
 	}
 
 	for _, tt := range tests {
@@ -455,6 +733,8 @@ func TestMergeConfigs(t *testing.T) {
 		Resources: []Resource{
 			{Name: "namespace"},
 		},
+		RequiredParams:      []string{"clusterId", "event.generation"},
+		ReportParamFailures: true,
 	}
 
 	merged := Merge(adapterCfg, taskCfg)
@@ -473,6 +753,8 @@ func TestMergeConfigs(t *testing.T) {
 	assert.Equal(t, "checkStatus", merged.Preconditions[0].Name)
 	require.Len(t, merged.Resources, 1)
 	assert.Equal(t, "namespace", merged.Resources[0].Name)
+	assert.Equal(t, []string{"clusterId", "event.generation"}, merged.RequiredParams)
+	assert.True(t, merged.ReportParamFailures)
 }
 
 func TestGetRequiredParams(t *testing.T) {
@@ -520,6 +802,117 @@ func TestGetPreconditionByName(t *testing.T) {
 	assert.Nil(t, precond)
 }
 
+func TestValidateAPITargets(t *testing.T) {
+	config := &Config{
+		Clients: ClientsConfig{HyperfleetAPI: HyperfleetAPIConfig{
+			Targets: []hyperfleetapi.TargetConfig{{Name: "tenant-b", BaseURL: "https://tenant-b.example.com"}},
+		}},
+		Preconditions: []Precondition{
+			{ActionBase: ActionBase{Name: "unset", APICall: &APICall{Method: "GET", URL: "/a"}}},
+			{ActionBase: ActionBase{Name: "default", APICall: &APICall{Method: "GET", URL: "/a", Target: "default"}}},
+			{ActionBase: ActionBase{Name: "tenant", APICall: &APICall{Method: "GET", URL: "/a", Target: "tenant-b"}}},
+			{ActionBase: ActionBase{Name: "templated", APICall: &APICall{Method: "GET", URL: "/a", Target: "{{ .t }}"}}},
+		},
+	}
+	require.NoError(t, ValidateAPITargets(config))
+
+	config.Post = &PostConfig{PostActions: []PostAction{
+		{ActionBase: ActionBase{Name: "report", APICall: &APICall{Method: "POST", URL: "/a", Target: "tenant-c"}}},
+	}}
+	err := ValidateAPITargets(config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		`post.post_actions[0].api_call.target: unknown HyperFleet API target "tenant-c" (valid targets: default, tenant-b)`)
+}
+
+func TestValidateNotMetMaxDelay(t *testing.T) {
+	tests := []struct {
+		name       string
+		backoff    *NotMetBackoff
+		requeueMax time.Duration
+		wantErr    string
+	}{
+		{name: "no backoff", requeueMax: time.Minute},
+		{name: "defaults", backoff: &NotMetBackoff{}},
+		{name: "within the requeue max delay",
+			backoff: &NotMetBackoff{MaxDelay: 10 * time.Minute}, requeueMax: 10 * time.Minute},
+		{name: "beyond the default requeue max delay", backoff: &NotMetBackoff{MaxDelay: 10 * time.Minute},
+			wantErr: "not_met_backoff.max_delay: max delay 10m0s exceeds clients.broker.requeue.max_delay 5m0s"},
+		{name: "default beyond a lower requeue max delay", backoff: &NotMetBackoff{}, requeueMax: time.Minute,
+			wantErr: "max delay 5m0s exceeds clients.broker.requeue.max_delay 1m0s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{NotMetBackoff: tt.backoff}
+			if tt.requeueMax > 0 {
+				config.Clients.Broker.Requeue = &RequeueConfig{MaxDelay: tt.requeueMax}
+			}
+			err := ValidateNotMetMaxDelay(config)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateResourcePolicy(t *testing.T) {
+	manifest := func(kind, namespace string) map[string]interface{} {
+		metadata := map[string]interface{}{"name": "{{ .clusterId }}"}
+		if namespace != "" {
+			metadata["namespace"] = namespace
+		}
+		return map[string]interface{}{"apiVersion": "rbac.authorization.k8s.io/v1", "kind": kind, "metadata": metadata}
+	}
+	config := &Config{
+		Policy: &ResourcePolicy{
+			DeniedKinds:       []string{"ClusterRoleBinding"},
+			AllowedNamespaces: []string{"cluster-*"},
+		},
+		Resources: []Resource{
+			{Name: "role", Manifest: manifest("RoleBinding", "cluster-{{ .clusterId }}")},
+			{Name: "templated", Manifest: manifest("{{ .kind }}", "{{ .namespace }}")},
+		},
+	}
+	require.NoError(t, ValidateResourcePolicy(config), "templated kinds and namespaces are checked at execution")
+
+	config.Resources = append(config.Resources,
+		Resource{Name: "admin", Manifest: manifest("ClusterRoleBinding", "")},
+		Resource{Name: "system", Manifest: manifest("RoleBinding", "kube-system")},
+		Resource{Name: "cluster-scoped", Manifest: manifest("ClusterRole", "")},
+	)
+	config.Workflows = []Workflow{{Name: "teardown", Resources: []Resource{{
+		Name: "work",
+		Transport: &TransportConfig{
+			Client: TransportClientMaestro, Maestro: &MaestroTransportConfig{TargetCluster: "{{ .clusterId }}"},
+		},
+		Manifest: map[string]interface{}{
+			"apiVersion": "work.open-cluster-management.io/v1",
+			"kind":       "ManifestWork",
+			"spec": map[string]interface{}{"workload": map[string]interface{}{
+				"manifests": []interface{}{manifest("RoleBinding", "default")},
+			}},
+		},
+	}}}}
+	config.Policy.AllowedNamespaces = append(config.Policy.AllowedNamespaces, "[")
+	err := ValidateResourcePolicy(config)
+	require.Error(t, err)
+	for _, want := range []string{
+		`policy.allowed_namespaces[1]: invalid namespace pattern "["`,
+		`resources[2].manifest.kind: ClusterRoleBinding.rbac.authorization.k8s.io "{{ .clusterId }}" ` +
+			`violates policy rule denied_kinds`,
+		`resources[3].manifest.metadata.namespace: RoleBinding.rbac.authorization.k8s.io "{{ .clusterId }}" ` +
+			`violates policy rule allowed_namespaces: namespace "kube-system" is not allowed`,
+		`resources[4].manifest.metadata.namespace: ClusterRole.rbac.authorization.k8s.io "{{ .clusterId }}" ` +
+			`violates policy rule cluster_scoped_allowed`,
+		`workflows[0].resources[0].manifest.spec.workload.manifests[0].metadata.namespace`,
+	} {
+		assert.Contains(t, err.Error(), want)
+	}
+}
+
 func TestValidateAdapterVersion(t *testing.T) {
 	config := &AdapterConfig{
 		Adapter: AdapterInfo{
@@ -885,6 +1278,116 @@ post:
 	assert.Equal(t, "templates/status-payload.yaml", config.Post.Payloads[0].BuildRef)
 }
 
+// TestLoadFileReferenceDigests verifies that build_ref and manifest_ref files
+// are checked against their pinned digests
+func TestLoadFileReferenceDigests(t *testing.T) {
+	tmpDir := t.TempDir()
+	templateDir := filepath.Join(tmpDir, "templates")
+	require.NoError(t, os.MkdirAll(templateDir, 0755))
+	payload := []byte("status: \"{{ .status }}\"\n")
+	require.NoError(t, os.WriteFile(filepath.Join(templateDir, "status-payload.yaml"), payload, 0644))
+	deployment := []byte("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: test\n  namespace: test\n")
+	require.NoError(t, os.WriteFile(filepath.Join(templateDir, "deployment.yaml"), deployment, 0644))
+	adapterPath := filepath.Join(tmpDir, "adapter-config.yaml")
+	require.NoError(t, os.WriteFile(adapterPath, []byte(testAdapterConfigYAML), 0644))
+
+	payloadDigest := ComputeDigest(payload)
+	deploymentDigest := ComputeDigest(deployment)
+	otherDigest := ComputeDigest([]byte("other"))
+
+	load := func(t *testing.T, buildRefDigest, manifestRefDigest string) (*Config, error) {
+		taskYAML := fmt.Sprintf(`
+resources:
+  - name: "deployment"
+    manifest:
+      ref: "templates/deployment.yaml"
+    manifest_ref_digest: %q
+    discovery:
+      namespace: "*"
+      by_name: "test"
+post:
+  payloads:
+    - name: "statusPayload"
+      build_ref: "templates/status-payload.yaml"
+      build_ref_digest: %q
+`, manifestRefDigest, buildRefDigest)
+		taskPath := filepath.Join(tmpDir, "task-config.yaml")
+		require.NoError(t, os.WriteFile(taskPath, []byte(taskYAML), 0644))
+		return LoadConfig(
+			WithAdapterConfigPath(adapterPath),
+			WithTaskConfigPath(taskPath),
+			WithSkipSemanticValidation(),
+		)
+	}
+
+	t.Run("matching digests", func(t *testing.T) {
+		config, err := load(t, payloadDigest, deploymentDigest)
+		require.NoError(t, err)
+		assert.Equal(t, "{{ .status }}", config.Post.Payloads[0].BuildRefContent["status"])
+		assert.Empty(t, config.Resources[0].ManifestRefDigest, "the digest is dropped with the loaded ref")
+		effective := config.Effective()
+		assert.Empty(t, effective.Post.Payloads[0].BuildRefDigest)
+	})
+
+	t.Run("unpinned refs", func(t *testing.T) {
+		_, err := load(t, "", "")
+		require.NoError(t, err)
+	})
+
+	t.Run("mismatching build_ref", func(t *testing.T) {
+		_, err := load(t, otherDigest, deploymentDigest)
+		require.Error(t, err)
+		var mismatch *DigestMismatchError
+		require.ErrorAs(t, err, &mismatch)
+		assert.Equal(t, otherDigest, mismatch.Expected)
+		assert.Equal(t, payloadDigest, mismatch.Actual)
+		assert.ErrorContains(t, err, "post.payloads[0].build_ref: file ")
+		assert.ErrorContains(t, err, fmt.Sprintf("does not match its digest: expected %s, got %s",
+			otherDigest, payloadDigest))
+	})
+
+	t.Run("mismatching manifest ref", func(t *testing.T) {
+		_, err := load(t, payloadDigest, otherDigest)
+		require.Error(t, err)
+		assert.ErrorContains(t, err, `resources[0].manifest.ref of resource "deployment": file `)
+		assert.ErrorContains(t, err, fmt.Sprintf("expected %s, got %s", otherDigest, deploymentDigest))
+	})
+
+	t.Run("invalid digest format", func(t *testing.T) {
+		for _, digest := range []string{
+			strings.TrimPrefix(payloadDigest, DigestPrefix),
+			"sha512:" + strings.TrimPrefix(payloadDigest, DigestPrefix),
+			strings.ToUpper(payloadDigest),
+			payloadDigest[:len(payloadDigest)-1],
+		} {
+			_, err := load(t, digest, "")
+			require.Error(t, err, digest)
+			assert.ErrorContains(t, err, "build_ref_digest")
+			assert.ErrorContains(t, err, "must be sha256: followed by 64 lowercase hex digits")
+		}
+	})
+}
+
+func TestValidateFileReferenceDigestWithoutRef(t *testing.T) {
+	config := &AdapterTaskConfig{
+		Resources: []Resource{{
+			Name:              "namespace",
+			Manifest:          map[string]interface{}{"apiVersion": "v1", "kind": "Namespace"},
+			ManifestRefDigest: ComputeDigest([]byte("namespace")),
+		}},
+		Post: &PostConfig{Payloads: []Payload{{
+			Name:           "statusPayload",
+			Build:          map[string]interface{}{"status": "ok"},
+			BuildRefDigest: ComputeDigest([]byte("payload")),
+		}}},
+	}
+	err := NewTaskConfigValidator(config, t.TempDir()).ValidateFileReferences()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "post.payloads[0].build_ref_digest is set without build_ref")
+	assert.ErrorContains(t, err,
+		`resources[0].manifest_ref_digest of resource "namespace" is set without manifest_ref or manifest.ref`)
+}
+
 func TestValidateResourceDiscoveryInTaskConfig(t *testing.T) {
 	// Helper to create a valid task config with given resources
 	configWithResources := func(resources []Resource) *AdapterTaskConfig {
@@ -1376,6 +1879,173 @@ resources:
 	assert.Contains(t, err.Error(), "does not exist")
 }
 
+func TestLoadConfigWithManifestRefField(t *testing.T) {
+	files := map[string]string{
+		"deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: "{{ .clusterId }}"
+spec:
+  replicas: 1
+`,
+		"bundle.yaml": `
+# Namespace first, so the config maps can be created in it
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: "{{ .clusterId }}"
+---
+---
+# comment-only document
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: "{{ .clusterId }}"
+`,
+		"no-metadata.yaml": `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: test
+---
+apiVersion: v1
+kind: ConfigMap
+`,
+		"invalid.yaml": "apiVersion: v1\nkind: [unclosed\n",
+		"empty.yaml":   "# nothing here\n",
+	}
+
+	tests := []struct {
+		name          string
+		manifest      string
+		discoveryItem string
+		source        string
+		expectedKinds []string
+		expectedError string
+	}{
+		{
+			name:          "single document",
+			manifest:      `manifest_ref: "templates/deployment.yaml"`,
+			source:        "deployment.yaml",
+			expectedKinds: []string{"Deployment"},
+		},
+		{
+			name:          "multiple documents load as a list",
+			manifest:      `manifest_ref: "templates/bundle.yaml"`,
+			discoveryItem: "0",
+			source:        "bundle.yaml",
+			expectedKinds: []string{"Namespace", "ConfigMap"},
+		},
+		{
+			name:          "list of several kinds without discovery item",
+			manifest:      `manifest_ref: "templates/bundle.yaml"`,
+			expectedError: "resources[0].discovery.item: item is required for a list of several kinds",
+		},
+		{
+			name:          "discovery item out of range",
+			manifest:      `manifest_ref: "templates/bundle.yaml"`,
+			discoveryItem: "2",
+			expectedError: "resources[0].discovery.item: item 2 is out of range: the list has 2 items",
+		},
+		{
+			name:          "discovery item of a single manifest",
+			manifest:      `manifest_ref: "templates/deployment.yaml"`,
+			discoveryItem: "0",
+			expectedError: "item is only allowed for a manifest of kind List",
+		},
+		{
+			name:          "missing file",
+			manifest:      `manifest_ref: "templates/missing.yaml"`,
+			expectedError: `resources[0].manifest_ref of resource "deployment": referenced file`,
+		},
+		{
+			name:          "directory",
+			manifest:      `manifest_ref: "templates"`,
+			expectedError: "is a directory",
+		},
+		{
+			name:          "invalid YAML",
+			manifest:      `manifest_ref: "templates/invalid.yaml"`,
+			expectedError: `resources[0].manifest_ref of resource "deployment": failed to parse YAML document 0`,
+		},
+		{
+			name:          "no document",
+			manifest:      `manifest_ref: "templates/empty.yaml"`,
+			expectedError: "contains no manifest",
+		},
+		{
+			name:          "list item is validated",
+			manifest:      `manifest_ref: "templates/no-metadata.yaml"`,
+			expectedError: `resources[0].manifest.items[1]: missing required Kubernetes field "metadata"`,
+		},
+		{
+			name: "inline manifest and manifest_ref",
+			manifest: `manifest_ref: "templates/deployment.yaml"
+    manifest:
+      apiVersion: v1
+      kind: Namespace
+      metadata:
+        name: test`,
+			expectedError: "mutually exclusive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			templateDir := filepath.Join(tmpDir, "templates")
+			require.NoError(t, os.MkdirAll(templateDir, 0755))
+			for name, content := range files {
+				require.NoError(t, os.WriteFile(filepath.Join(templateDir, name), []byte(content), 0644))
+			}
+			taskYAML := `
+params:
+  - name: "clusterId"
+    source: "event.id"
+resources:
+  - name: "deployment"
+    ` + tt.manifest + `
+    discovery:
+      by_name: "{{ .clusterId }}"
+`
+			if tt.discoveryItem != "" {
+				taskYAML += "      item: " + tt.discoveryItem + "\n"
+			}
+			adapterPath, taskPath := createTestConfigFiles(t, tmpDir, testAdapterConfigYAML, taskYAML)
+
+			config, err := LoadConfig(WithAdapterConfigPath(adapterPath), WithTaskConfigPath(taskPath))
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			resource := config.Resources[0]
+			assert.Empty(t, resource.ManifestRef, "the loader replaces the reference with the manifest")
+			manifest, err := resource.UnmarshalManifest()
+			require.NoError(t, err)
+			items := []interface{}{manifest}
+			if len(tt.expectedKinds) > 1 {
+				assert.Equal(t, "v1", manifest["apiVersion"])
+				assert.Equal(t, KindList, manifest["kind"])
+				items = manifest["items"].([]interface{})
+			}
+			kinds := make([]string, 0, len(items))
+			for _, item := range items {
+				kinds = append(kinds, item.(map[string]interface{})["kind"].(string))
+			}
+			assert.Equal(t, tt.expectedKinds, kinds)
+
+			assert.Contains(t, config.Sources, filepath.Join(templateDir, tt.source),
+				"the referenced file is a source of the config")
+		})
+	}
+}
+
 func TestLoadConfigWithInlineManifestWork(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -1424,3 +2094,80 @@ resources:
 	assert.Equal(t, "work.open-cluster-management.io/v1", mw["apiVersion"])
 	assert.Equal(t, "ManifestWork", mw["kind"])
 }
+
+func TestBrokerConfigEffectiveSubscriptions(t *testing.T) {
+	assert.Empty(t, (&BrokerConfig{}).EffectiveSubscriptions())
+
+	single := BrokerConfig{SubscriptionID: "cluster-events", Topic: "clusters"}
+	assert.Equal(t, []SubscriptionConfig{{SubscriptionID: "cluster-events", Topic: "clusters"}},
+		single.EffectiveSubscriptions())
+	assert.True(t, single.FailFast())
+
+	multi := BrokerConfig{
+		SubscriptionID:     "ignored",
+		StartFailurePolicy: StartFailureDegraded,
+		Subscriptions: []SubscriptionConfig{
+			{SubscriptionID: "cluster-events", Topic: "clusters"},
+			{SubscriptionID: "nodepool-events", Topic: "nodepools",
+				FlowControl: &FlowControlConfig{Parallelism: 2, MaxOutstandingMessages: 50}},
+		},
+	}
+	subs := multi.EffectiveSubscriptions()
+	require.Len(t, subs, 2)
+	assert.False(t, multi.FailFast())
+	assert.Nil(t, subs[0].FlowControl.BrokerSettings())
+	assert.Equal(t, map[string]string{
+		"subscriber.parallelism":                       "2",
+		"broker.googlepubsub.max_outstanding_messages": "50",
+	}, subs[1].FlowControl.BrokerSettings())
+}
+
+func TestLoadConfigWithMaps(t *testing.T) {
+	taskYAML := `
+maps:
+  - name: phaseStatus
+    entries:
+      Provisioning: INSTALLING
+      Ready: READY
+  - name: regions
+    on_missing: empty
+    entries:
+      us-east-1:
+        datacenter: dc-1
+        zones: [a, b]
+`
+	adapterPath, taskPath := createTestConfigFiles(t, t.TempDir(), testAdapterConfigYAML, taskYAML)
+	config, err := LoadConfig(WithAdapterConfigPath(adapterPath), WithTaskConfigPath(taskPath))
+	require.NoError(t, err)
+	require.Len(t, config.Maps, 2)
+	assert.Equal(t, "READY", config.Maps[0].Entries["Ready"])
+	assert.Equal(t, LookupOnMissingError, config.Maps[0].EffectiveOnMissing())
+	assert.Equal(t, map[string]interface{}{"datacenter": "dc-1", "zones": []interface{}{"a", "b"}},
+		config.Maps[1].Entries["us-east-1"])
+
+	t.Run("duplicate keys", func(t *testing.T) {
+		adapterPath, taskPath := createTestConfigFiles(t, t.TempDir(), testAdapterConfigYAML, `
+maps:
+  - name: phaseStatus
+    entries:
+      Ready: READY
+      Ready: AVAILABLE
+`)
+		_, err := LoadConfig(WithAdapterConfigPath(adapterPath), WithTaskConfigPath(taskPath))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `mapping key "Ready" already defined`)
+	})
+
+	t.Run("duplicate names", func(t *testing.T) {
+		adapterPath, taskPath := createTestConfigFiles(t, t.TempDir(), testAdapterConfigYAML, `
+maps:
+  - name: phaseStatus
+    entries: {Ready: READY}
+  - name: phaseStatus
+    entries: {Failed: FAILED}
+`)
+		_, err := LoadConfig(WithAdapterConfigPath(adapterPath), WithTaskConfigPath(taskPath))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "maps")
+	})
+}
@@ -0,0 +1,120 @@
+package configloader
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Rules of the resource policy, named after their fields
+const (
+	PolicyRuleAllowedKinds         = "allowed_kinds"
+	PolicyRuleDeniedKinds          = "denied_kinds"
+	PolicyRuleAllowedNamespaces    = "allowed_namespaces"
+	PolicyRuleClusterScopedAllowed = "cluster_scoped_allowed"
+)
+
+// PolicyViolation is an object the resource policy does not allow
+type PolicyViolation struct {
+	// Rule is the violated rule, one of the PolicyRule constants
+	Rule string
+	// Kind is the kind of the object, "Kind" in the core group or "Kind.group"
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+// Error implements error
+func (v *PolicyViolation) Error() string {
+	var reason string
+	switch v.Rule {
+	case PolicyRuleDeniedKinds:
+		reason = fmt.Sprintf("kind %s is denied", v.Kind)
+	case PolicyRuleAllowedKinds:
+		reason = fmt.Sprintf("kind %s is not allowed", v.Kind)
+	case PolicyRuleAllowedNamespaces:
+		reason = fmt.Sprintf("namespace %q is not allowed", v.Namespace)
+	case PolicyRuleClusterScopedAllowed:
+		reason = "cluster-scoped objects are not allowed"
+	}
+	return fmt.Sprintf("%s %q violates policy rule %s: %s", v.Kind, v.Name, v.Rule, reason)
+}
+
+// PolicyKind returns the kind of an object as matched by the policy: "Kind"
+// in the core group, "Kind.group" otherwise
+func PolicyKind(apiVersion, kind string) string {
+	group, _, found := strings.Cut(apiVersion, "/")
+	if !found || group == "" {
+		return kind
+	}
+	return kind + "." + group
+}
+
+// Check returns the violation of the object of apiVersion and kind named name
+// in namespace, empty for a cluster-scoped object, or nil if the policy allows
+// it. A nil policy allows any object.
+func (p *ResourcePolicy) Check(apiVersion, kind, name, namespace string) *PolicyViolation {
+	if violation := p.CheckKind(apiVersion, kind, name); violation != nil {
+		return violation
+	}
+	if violation := p.CheckNamespace(namespace); violation != nil {
+		violation.Kind = PolicyKind(apiVersion, kind)
+		violation.Name = name
+		return violation
+	}
+	return nil
+}
+
+// CheckKind returns the violation of the kind rules by the object of
+// apiVersion and kind named name, or nil if they allow it
+func (p *ResourcePolicy) CheckKind(apiVersion, kind, name string) *PolicyViolation {
+	if p == nil {
+		return nil
+	}
+	policyKind := PolicyKind(apiVersion, kind)
+	violation := func(rule string) *PolicyViolation {
+		return &PolicyViolation{Rule: rule, Kind: policyKind, Name: name}
+	}
+	if matchesKind(p.DeniedKinds, kind, policyKind) {
+		return violation(PolicyRuleDeniedKinds)
+	}
+	if len(p.AllowedKinds) > 0 && !matchesKind(p.AllowedKinds, kind, policyKind) {
+		return violation(PolicyRuleAllowedKinds)
+	}
+	return nil
+}
+
+// CheckNamespace returns the violation of the namespace rules by an object in
+// namespace, empty for a cluster-scoped object, or nil if they allow it. The
+// kind and name of the violation are left for the caller.
+func (p *ResourcePolicy) CheckNamespace(namespace string) *PolicyViolation {
+	if p == nil {
+		return nil
+	}
+	if namespace == "" {
+		if !p.ClusterScopedAllowed {
+			return &PolicyViolation{Rule: PolicyRuleClusterScopedAllowed}
+		}
+		return nil
+	}
+	if len(p.AllowedNamespaces) == 0 {
+		return nil
+	}
+	for _, pattern := range p.AllowedNamespaces {
+		if matched, _ := path.Match(pattern, namespace); matched {
+			return nil
+		}
+	}
+	return &PolicyViolation{Rule: PolicyRuleAllowedNamespaces, Namespace: namespace}
+}
+
+// matchesKind reports whether one of entries matches the kind: an entry
+// without a group matches the kind in any group
+func matchesKind(entries []string, kind, policyKind string) bool {
+	for _, entry := range entries {
+		if entry == policyKind || entry == kind {
+			return true
+		}
+	}
+	return false
+}
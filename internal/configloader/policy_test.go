@@ -0,0 +1,55 @@
+package configloader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourcePolicy_Check(t *testing.T) {
+	policy := &ResourcePolicy{
+		AllowedKinds:      []string{"ConfigMap", "Deployment.apps", "ClusterRoleBinding", "Namespace"},
+		DeniedKinds:       []string{"ClusterRoleBinding.rbac.authorization.k8s.io"},
+		AllowedNamespaces: []string{"cluster-*", "hyperfleet"},
+	}
+	tests := []struct {
+		name       string
+		apiVersion string
+		kind       string
+		namespace  string
+		wantRule   string
+	}{
+		{name: "allowed kind in any group", apiVersion: "v1", kind: "ConfigMap", namespace: "cluster-1"},
+		{name: "allowed kind of a group", apiVersion: "apps/v1", kind: "Deployment", namespace: "hyperfleet"},
+		{name: "kind of another group", apiVersion: "extensions/v1beta1", kind: "Deployment", namespace: "hyperfleet",
+			wantRule: PolicyRuleAllowedKinds},
+		{name: "unlisted kind", apiVersion: "v1", kind: "Secret", namespace: "cluster-1",
+			wantRule: PolicyRuleAllowedKinds},
+		{name: "denied kind takes precedence", apiVersion: "rbac.authorization.k8s.io/v1", kind: "ClusterRoleBinding",
+			wantRule: PolicyRuleDeniedKinds},
+		{name: "namespace not allowed", apiVersion: "v1", kind: "ConfigMap", namespace: "kube-system",
+			wantRule: PolicyRuleAllowedNamespaces},
+		{name: "cluster-scoped", apiVersion: "v1", kind: "Namespace", wantRule: PolicyRuleClusterScopedAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violation := policy.Check(tt.apiVersion, tt.kind, "obj", tt.namespace)
+			if tt.wantRule == "" {
+				assert.Nil(t, violation)
+				return
+			}
+			if assert.NotNil(t, violation) {
+				assert.Equal(t, tt.wantRule, violation.Rule)
+				assert.Equal(t, "obj", violation.Name)
+			}
+		})
+	}
+
+	assert.Nil(t, policy.CheckNamespace("cluster-1"))
+	policy.ClusterScopedAllowed = true
+	assert.Nil(t, policy.Check("v1", "Namespace", "cluster-1", ""))
+	assert.Nil(t, (*ResourcePolicy)(nil).Check("rbac.authorization.k8s.io/v1", "ClusterRoleBinding", "admin", ""),
+		"no policy allows any object")
+	assert.EqualError(t, policy.Check("v1", "ConfigMap", "settings", "kube-system"),
+		`ConfigMap "settings" violates policy rule allowed_namespaces: namespace "kube-system" is not allowed`)
+}
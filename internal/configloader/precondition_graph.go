@@ -0,0 +1,177 @@
+package configloader
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+)
+
+// PreconditionCycleError is a dependency cycle between preconditions, which
+// therefore have no execution order
+type PreconditionCycleError struct {
+	// Names are the preconditions of the cycle, each depending on the next and
+	// the last on the first
+	Names []string
+}
+
+func (e *PreconditionCycleError) Error() string {
+	return fmt.Sprintf("preconditions form a dependency cycle: %s -> %s",
+		strings.Join(e.Names, " -> "), e.Names[0])
+}
+
+// PreconditionProducers returns the index of the precondition producing each
+// name later steps can reference: the response stored under the name of an
+// API call precondition, its captures and its capture_response_as
+func PreconditionProducers(preconditions []Precondition) map[string]int {
+	producers := make(map[string]int)
+	for i, precond := range preconditions {
+		if precond.APICall != nil {
+			producers[precond.Name] = i
+		}
+		for _, capture := range precond.Capture {
+			producers[capture.Name] = i
+		}
+		if precond.CaptureResponseAs != "" {
+			producers[precond.CaptureResponseAs] = i
+		}
+	}
+	return producers
+}
+
+// PreconditionDependencies returns the indexes of the preconditions each
+// precondition depends on, in ascending order: those named in its depends_on
+// and those producing a name its templates, expression or condition fields
+// reference. Unknown depends_on names are left out; the validator reports them.
+func PreconditionDependencies(preconditions []Precondition) [][]int {
+	producers := PreconditionProducers(preconditions)
+	indexes := make(map[string]int, len(preconditions))
+	for i, precond := range preconditions {
+		indexes[precond.Name] = i
+	}
+
+	dependencies := make([][]int, len(preconditions))
+	for i, precond := range preconditions {
+		var deps []int
+		for _, name := range precond.DependsOn {
+			if j, ok := indexes[name]; ok && j != i {
+				deps = append(deps, j)
+			}
+		}
+		for _, ref := range preconditionReferences(precond) {
+			if j, ok := producers[referenceRoot(ref)]; ok && j != i {
+				deps = append(deps, j)
+			}
+		}
+		slices.Sort(deps)
+		dependencies[i] = slices.Compact(deps)
+	}
+	return dependencies
+}
+
+// PreconditionLevels returns the indexes of the preconditions in execution
+// order, grouped in levels: the preconditions of a level depend only on those
+// of earlier levels, so they can run in parallel. Within a level the
+// preconditions keep their config order. Returns a *PreconditionCycleError
+// when the dependencies form a cycle.
+func PreconditionLevels(preconditions []Precondition) ([][]int, error) {
+	dependencies := PreconditionDependencies(preconditions)
+	done := make([]bool, len(preconditions))
+	var levels [][]int
+	for remaining := len(preconditions); remaining > 0; {
+		var level []int
+		for i, deps := range dependencies {
+			if !done[i] && !slices.ContainsFunc(deps, func(j int) bool { return !done[j] }) {
+				level = append(level, i)
+			}
+		}
+		if len(level) == 0 {
+			return nil, &PreconditionCycleError{Names: preconditionCycle(preconditions, dependencies, done)}
+		}
+		for _, i := range level {
+			done[i] = true
+		}
+		remaining -= len(level)
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+// preconditionCycle returns the names of a cycle among the preconditions not
+// done, all of which depend on another one not done
+func preconditionCycle(preconditions []Precondition, dependencies [][]int, done []bool) []string {
+	start := slices.Index(done, false)
+	visited := make(map[int]int)
+	var path []int
+	for i := start; ; {
+		if at, ok := visited[i]; ok {
+			path = path[at:]
+			break
+		}
+		visited[i] = len(path)
+		path = append(path, i)
+		next := slices.IndexFunc(dependencies[i], func(j int) bool { return !done[j] })
+		i = dependencies[i][next]
+	}
+	names := make([]string, len(path))
+	for k, i := range path {
+		names[k] = preconditions[i].Name
+	}
+	return names
+}
+
+// preconditionReferences returns the variable references of the templates,
+// expression and condition fields of a precondition
+func preconditionReferences(precond Precondition) []string {
+	var refs []string
+	if precond.Log != nil {
+		refs = append(refs, templateReferences(precond.Log.Message)...)
+	}
+	if call := precond.APICall; call != nil {
+		templates := []string{call.URL, call.Target, call.Body}
+		for _, header := range call.Headers {
+			templates = append(templates, header.Value)
+		}
+		for _, field := range call.FormFields {
+			templates = append(templates, field.Value)
+		}
+		for _, part := range call.Parts {
+			templates = append(templates, part.Content)
+		}
+		for _, template := range templates {
+			refs = append(refs, templateReferences(template)...)
+		}
+	}
+	if precond.Expression != "" {
+		if env := referenceEnv(); env != nil {
+			if ast, issues := env.Parse(strings.TrimSpace(precond.Expression)); issues == nil || issues.Err() == nil {
+				refs = append(refs, celReferences(ast)...)
+			}
+		}
+	}
+	for _, condition := range precond.Conditions {
+		refs = append(refs, condition.Field)
+	}
+	return refs
+}
+
+// referenceRoot returns the variable a reference starts with, e.g. getCluster
+// for getCluster.status.phase or getCluster[0]
+func referenceRoot(ref string) string {
+	if end := strings.IndexAny(ref, ".["); end >= 0 {
+		return ref[:end]
+	}
+	return ref
+}
+
+// referenceEnv is the CEL environment expressions are parsed with to find
+// their references; parsing needs no variable declarations
+var referenceEnv = sync.OnceValue(func() *cel.Env {
+	env, err := cel.NewEnv(cel.OptionalTypes())
+	if err != nil {
+		return nil
+	}
+	return env
+})
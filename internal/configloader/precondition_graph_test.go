@@ -0,0 +1,115 @@
+package configloader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// graphPrecondition is an API call precondition capturing name+"Id" from its
+// response, with url as the URL template
+func graphPrecondition(name, url string, dependsOn ...string) Precondition {
+	return Precondition{
+		ActionBase: ActionBase{Name: name, APICall: &APICall{Method: "GET", URL: url}},
+		Capture:    []CaptureField{{Name: name + "Id", FieldExpressionDef: FieldExpressionDef{Field: "id"}}},
+		DependsOn:  dependsOn,
+	}
+}
+
+func TestPreconditionLevels(t *testing.T) {
+	t.Run("chain declared backwards", func(t *testing.T) {
+		preconditions := []Precondition{
+			graphPrecondition("nodePool", "/node_pools/{{ .clusterId }}"),
+			graphPrecondition("cluster", "/clusters/{{ .tenantId }}"),
+			graphPrecondition("tenant", "/tenants/{{ .orgId }}"),
+		}
+		assert.Equal(t, [][]int{{2}, {1}, {0}}, mustLevels(t, preconditions))
+		assert.Equal(t, [][]int{{1}, {2}, nil}, PreconditionDependencies(preconditions))
+	})
+
+	t.Run("diamond", func(t *testing.T) {
+		preconditions := []Precondition{
+			graphPrecondition("cluster", "/clusters/{{ .id }}"),
+			graphPrecondition("nodePools", "/clusters/{{ .clusterId }}/node_pools"),
+			{
+				ActionBase: ActionBase{Name: "ready"},
+				Expression: `nodePoolsId != "" && statuses.items.size() > 0`,
+			},
+			graphPrecondition("statuses", "/clusters/{{ .clusterId }}/statuses"),
+		}
+		assert.Equal(t, [][]int{{0}, {1, 3}, {2}}, mustLevels(t, preconditions))
+		assert.Equal(t, [][]int{nil, {0}, {1, 3}, {0}}, PreconditionDependencies(preconditions))
+	})
+
+	t.Run("condition fields and depends_on", func(t *testing.T) {
+		preconditions := []Precondition{
+			{ActionBase: ActionBase{Name: "audit"}, Expression: "true", DependsOn: []string{"unknown", "cluster"}},
+			{
+				ActionBase: ActionBase{Name: "check"},
+				Conditions: []Condition{{Field: "cluster.status.phase", Operator: "equals", Value: "Ready"}},
+			},
+			graphPrecondition("cluster", "/clusters/{{ .id }}"),
+		}
+		assert.Equal(t, [][]int{{2}, {0, 1}}, mustLevels(t, preconditions))
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		preconditions := []Precondition{
+			graphPrecondition("first", "/first"),
+			graphPrecondition("a", "/a/{{ .cId }}"),
+			graphPrecondition("b", "/b/{{ .aId }}"),
+			graphPrecondition("c", "/c/{{ .bId }}"),
+		}
+		_, err := PreconditionLevels(preconditions)
+		var cycleErr *PreconditionCycleError
+		require.ErrorAs(t, err, &cycleErr)
+		assert.Equal(t, []string{"a", "c", "b"}, cycleErr.Names)
+		assert.EqualError(t, err, "preconditions form a dependency cycle: a -> c -> b -> a")
+	})
+}
+
+func mustLevels(t *testing.T, preconditions []Precondition) [][]int {
+	t.Helper()
+	levels, err := PreconditionLevels(preconditions)
+	require.NoError(t, err)
+	return levels
+}
+
+func TestValidatePreconditionGraph(t *testing.T) {
+	cfg := baseTaskConfig()
+	cfg.Params = []Parameter{{Name: "id", Source: "event.id"}}
+	cfg.Preconditions = []Precondition{
+		graphPrecondition("nodePools", "/clusters/{{ .clusterId }}/node_pools", "nodePools"),
+		graphPrecondition("cluster", "/clusters/{{ .id }}/{{ .tenantName }}", "tenant"),
+	}
+	cfg.Preconditions[1].Capture[0].Name = "clusterId"
+	v := newTaskValidator(cfg)
+	require.NoError(t, v.ValidateStructure())
+	err := v.ValidateSemantic()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `preconditions[0].depends_on[0]: precondition "nodePools" depends on itself`)
+	assert.Contains(t, err.Error(),
+		`preconditions[1].depends_on[0]: precondition "cluster" depends on "tenant", which is not a precondition`)
+	assert.Contains(t, err.Error(), `preconditions[1].api_call.url: undefined template variable "tenantName" `+
+		`referenced by precondition "cluster": no param, var or precondition capture produces it`)
+	assert.NotContains(t, err.Error(), "cycle")
+
+	cfg.Preconditions = []Precondition{
+		graphPrecondition("a", "/a/{{ .bId }}"),
+		graphPrecondition("b", "/b", "a"),
+	}
+	v = newTaskValidator(cfg)
+	require.NoError(t, v.ValidateStructure())
+	err = v.ValidateSemantic()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "preconditions: preconditions form a dependency cycle: a -> b -> a")
+
+	cfg.Preconditions = []Precondition{
+		graphPrecondition("a", "/a/{{ .bId }}"),
+		graphPrecondition("b", "/b/{{ .id }}"),
+	}
+	v = newTaskValidator(cfg)
+	require.NoError(t, v.ValidateStructure())
+	require.NoError(t, v.ValidateSemantic(), "a reference to a later capture is a dependency")
+}
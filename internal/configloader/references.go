@@ -0,0 +1,268 @@
+package configloader
+
+import (
+	"strings"
+	"text/template/parse"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+)
+
+// templateReferences returns the dotted paths of the params a Go template
+// references: fields of the root dot, including inside if and pipelines, and
+// $.x fields. The bodies of range and with rebind dot, so their fields are
+// not params; their else branches keep the outer dot. Returns nil when the
+// template does not parse.
+func templateReferences(s string) []string {
+	tree := parse.New("template")
+	tree.Mode = parse.SkipFuncCheck
+	trees := make(map[string]*parse.Tree)
+	if _, err := tree.Parse(s, "{{", "}}", trees); err != nil {
+		return nil
+	}
+	var refs []string
+	for _, t := range trees {
+		if t.Root != nil {
+			refs = append(refs, templateNodeReferences(t.Root, true)...)
+		}
+	}
+	return refs
+}
+
+// templateNodeReferences returns the param references under node; rootDot
+// reports whether dot is the template's root there
+func templateNodeReferences(node parse.Node, rootDot bool) []string {
+	var refs []string
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			refs = append(refs, templateNodeReferences(child, rootDot)...)
+		}
+	case *parse.ActionNode:
+		refs = templateNodeReferences(n.Pipe, rootDot)
+	case *parse.IfNode:
+		refs = templateNodeReferences(n.Pipe, rootDot)
+		refs = append(refs, templateNodeReferences(n.List, rootDot)...)
+		refs = append(refs, templateNodeReferences(n.ElseList, rootDot)...)
+	case *parse.RangeNode:
+		refs = templateNodeReferences(n.Pipe, rootDot)
+		refs = append(refs, templateNodeReferences(n.List, false)...)
+		refs = append(refs, templateNodeReferences(n.ElseList, rootDot)...)
+	case *parse.WithNode:
+		refs = templateNodeReferences(n.Pipe, rootDot)
+		refs = append(refs, templateNodeReferences(n.List, false)...)
+		refs = append(refs, templateNodeReferences(n.ElseList, rootDot)...)
+	case *parse.TemplateNode:
+		refs = templateNodeReferences(n.Pipe, rootDot)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			refs = append(refs, templateNodeReferences(cmd, rootDot)...)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			refs = append(refs, templateNodeReferences(arg, rootDot)...)
+		}
+	case *parse.ChainNode:
+		refs = templateNodeReferences(n.Node, rootDot)
+	case *parse.FieldNode:
+		if rootDot {
+			refs = []string{strings.Join(n.Ident, ".")}
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			refs = []string{strings.Join(n.Ident[1:], ".")}
+		}
+	}
+	return refs
+}
+
+// lookupFunction is the name of the function reading the lookup maps, in
+// templates and CEL expressions alike
+const lookupFunction = "lookup"
+
+// templateLookupMaps returns the map names of the lookup calls of a Go
+// template that name their map with a string literal, e.g. "phases" for
+// {{ lookup "phases" .phase }}. Returns nil when the template does not parse.
+func templateLookupMaps(s string) []string {
+	tree := parse.New("template")
+	tree.Mode = parse.SkipFuncCheck
+	trees := make(map[string]*parse.Tree)
+	if _, err := tree.Parse(s, "{{", "}}", trees); err != nil {
+		return nil
+	}
+	var names []string
+	for _, t := range trees {
+		if t.Root != nil {
+			names = append(names, templateNodeLookupMaps(t.Root)...)
+		}
+	}
+	return names
+}
+
+// templateNodeLookupMaps returns the literal map names of the lookup calls under node
+func templateNodeLookupMaps(node parse.Node) []string {
+	var names []string
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			names = append(names, templateNodeLookupMaps(child)...)
+		}
+	case *parse.ActionNode:
+		names = templateNodeLookupMaps(n.Pipe)
+	case *parse.IfNode:
+		names = templateBranchLookupMaps(&n.BranchNode)
+	case *parse.RangeNode:
+		names = templateBranchLookupMaps(&n.BranchNode)
+	case *parse.WithNode:
+		names = templateBranchLookupMaps(&n.BranchNode)
+	case *parse.TemplateNode:
+		names = templateNodeLookupMaps(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			names = append(names, templateNodeLookupMaps(cmd)...)
+		}
+	case *parse.CommandNode:
+		if len(n.Args) > 1 {
+			if ident, ok := n.Args[0].(*parse.IdentifierNode); ok && ident.Ident == lookupFunction {
+				if name, ok := n.Args[1].(*parse.StringNode); ok {
+					names = append(names, name.Text)
+				}
+			}
+		}
+		for _, arg := range n.Args {
+			names = append(names, templateNodeLookupMaps(arg)...)
+		}
+	case *parse.ChainNode:
+		names = templateNodeLookupMaps(n.Node)
+	}
+	return names
+}
+
+func templateBranchLookupMaps(n *parse.BranchNode) []string {
+	names := templateNodeLookupMaps(n.Pipe)
+	names = append(names, templateNodeLookupMaps(n.List)...)
+	return append(names, templateNodeLookupMaps(n.ElseList)...)
+}
+
+// celLookupMaps returns the map names of the lookup() calls of a parsed CEL
+// expression that name their map with a string literal
+func celLookupMaps(ast *cel.Ast) []string {
+	var names []string
+	celast.PreOrderVisit(ast.NativeRep().Expr(), celast.NewExprVisitor(func(e celast.Expr) {
+		if e.Kind() != celast.CallKind {
+			return
+		}
+		call := e.AsCall()
+		if call.FunctionName() != lookupFunction || call.IsMemberFunction() || len(call.Args()) != 2 {
+			return
+		}
+		if arg := call.Args()[0]; arg.Kind() == celast.LiteralKind {
+			if name, ok := arg.AsLiteral().Value().(string); ok {
+				names = append(names, name)
+			}
+		}
+	}))
+	return names
+}
+
+// celReferences returns the dotted paths of the variables a parsed CEL
+// expression references, e.g. clusterStatus.status.phase for a select chain
+// on the clusterStatus variable. The iteration and accumulator variables of
+// macros are not references.
+func celReferences(ast *cel.Ast) []string {
+	var refs []string
+	collectCELReferences(ast.NativeRep().Expr(), map[string]bool{}, &refs)
+	return refs
+}
+
+// collectCELReferences adds the references under e to refs; scope holds the
+// comprehension variables bound at e
+func collectCELReferences(e celast.Expr, scope map[string]bool, refs *[]string) {
+	switch e.Kind() {
+	case celast.IdentKind:
+		if !scope[e.AsIdent()] {
+			*refs = append(*refs, e.AsIdent())
+		}
+	case celast.SelectKind:
+		if path, ok := celSelectPath(e); ok {
+			if root, _, _ := strings.Cut(path, "."); !scope[root] {
+				*refs = append(*refs, path)
+			}
+			return
+		}
+		collectCELReferences(e.AsSelect().Operand(), scope, refs)
+	case celast.CallKind:
+		call := e.AsCall()
+		if call.IsMemberFunction() {
+			collectCELReferences(call.Target(), scope, refs)
+		}
+		for _, arg := range call.Args() {
+			collectCELReferences(arg, scope, refs)
+		}
+	case celast.ListKind:
+		for _, elem := range e.AsList().Elements() {
+			collectCELReferences(elem, scope, refs)
+		}
+	case celast.MapKind:
+		for _, entry := range e.AsMap().Entries() {
+			collectCELReferences(entry.AsMapEntry().Key(), scope, refs)
+			collectCELReferences(entry.AsMapEntry().Value(), scope, refs)
+		}
+	case celast.StructKind:
+		for _, field := range e.AsStruct().Fields() {
+			collectCELReferences(field.AsStructField().Value(), scope, refs)
+		}
+	case celast.ComprehensionKind:
+		comp := e.AsComprehension()
+		collectCELReferences(comp.IterRange(), scope, refs)
+		collectCELReferences(comp.AccuInit(), scope, refs)
+		inner := make(map[string]bool, len(scope)+3)
+		for name := range scope {
+			inner[name] = true
+		}
+		inner[comp.IterVar()] = true
+		inner[comp.AccuVar()] = true
+		if comp.HasIterVar2() {
+			inner[comp.IterVar2()] = true
+		}
+		collectCELReferences(comp.LoopCondition(), inner, refs)
+		collectCELReferences(comp.LoopStep(), inner, refs)
+		// The result sees the accumulator but not the iteration variables
+		result := make(map[string]bool, len(scope)+1)
+		for name := range scope {
+			result[name] = true
+		}
+		result[comp.AccuVar()] = true
+		collectCELReferences(comp.Result(), result, refs)
+	}
+}
+
+// celSelectPath returns the dotted path of a select chain rooted at an
+// identifier, e.g. a.b.c; ok is false when the chain starts at another expression
+func celSelectPath(e celast.Expr) (string, bool) {
+	var fields []string
+	for e.Kind() == celast.SelectKind {
+		fields = append(fields, e.AsSelect().FieldName())
+		e = e.AsSelect().Operand()
+	}
+	if e.Kind() != celast.IdentKind {
+		return "", false
+	}
+	path := e.AsIdent()
+	for i := len(fields) - 1; i >= 0; i-- {
+		path += "." + fields[i]
+	}
+	return path, true
+}
@@ -0,0 +1,127 @@
+package configloader
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     []string
+	}{
+		{name: "field", template: "{{ .clusterId }}", want: []string{"clusterId"}},
+		{name: "nested field with pipeline",
+			template: `{{ .cluster.spec.region | default "us-east-1" | upper }}`,
+			want:     []string{"cluster.spec.region"}},
+		{name: "function arguments and if",
+			template: `{{ if eq .phase "Ready" }}{{ index .labels "team" }}{{ else }}{{ .fallback }}{{ end }}`,
+			want:     []string{"phase", "labels", "fallback"}},
+		{name: "range body rebinds dot",
+			template: `{{ range .nodePools }}{{ .name }}:{{ $.clusterId }}{{ else }}{{ .empty }}{{ end }}`,
+			want:     []string{"nodePools", "clusterId", "empty"}},
+		{name: "with body rebinds dot",
+			template: `{{ with .cluster.status }}{{ .phase }}{{ end }}`,
+			want:     []string{"cluster.status"}},
+		{name: "range variables are not params",
+			template: `{{ range $i, $pool := .nodePools }}{{ $pool.name }}{{ end }}`,
+			want:     []string{"nodePools"}},
+		{name: "chained field", template: `{{ (.cluster).name }}`, want: []string{"cluster"}},
+		{name: "plain text", template: "no templates", want: nil},
+		{name: "does not parse", template: "{{ .clusterId ", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, templateReferences(tt.template))
+		})
+	}
+}
+
+func TestCELReferences(t *testing.T) {
+	env, err := cel.NewEnv(cel.OptionalTypes())
+	require.NoError(t, err)
+	tests := []struct {
+		name string
+		expr string
+		want []string
+	}{
+		{name: "identifier", expr: `clusterId != ""`, want: []string{"clusterId"}},
+		{name: "nested selects",
+			expr: `clusterStatus.status.phase == "Ready" && has(adapter.metadata.name)`,
+			want: []string{"clusterStatus.status.phase", "adapter.metadata.name"}},
+		{name: "index and function calls",
+			expr: `size(resources.clusterNamespace.metadata.labels) > 0 && nodePools[0].name.startsWith(prefix)`,
+			want: []string{"resources.clusterNamespace.metadata.labels", "nodePools", "prefix"}},
+		{name: "macro variables are not references",
+			expr: `cluster.conditions.exists(c, c.type == "Ready" && c.status == wantStatus)`,
+			want: []string{"cluster.conditions", "wantStatus"}},
+		{name: "nested macros",
+			expr: `pools.all(p, p.nodes.map(n, n.zone).exists(z, z == region))`,
+			want: []string{"pools", "region"}},
+		{name: "optional select", expr: `cluster.?spec.orValue(defaults)`, want: []string{"cluster", "defaults"}},
+		{name: "map and list literals",
+			expr: `{"id": clusterId, "zones": [zone, backupZone]}`,
+			want: []string{"clusterId", "zone", "backupZone"}},
+		{name: "select on a call result", expr: `lookup(clusterId).name`, want: []string{"clusterId"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, issues := env.Parse(tt.expr)
+			require.NoError(t, issues.Err())
+			assert.Equal(t, tt.want, celReferences(ast))
+		})
+	}
+}
+
+func TestTemplateLookupMaps(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     []string
+	}{
+		{name: "action", template: `{{ lookup "phaseStatus" .phase }}`, want: []string{"phaseStatus"}},
+		{name: "pipeline and nested call",
+			template: `{{ (lookup "regions" .region).datacenter | upper }}-{{ index (lookup "tiers" .tier) "name" }}`,
+			want:     []string{"regions", "tiers"}},
+		{name: "if and range bodies",
+			template: `{{ if .ready }}{{ lookup "a" .x }}{{ else }}{{ range .items }}{{ lookup "b" . }}{{ end }}{{ end }}`,
+			want:     []string{"a", "b"}},
+		{name: "map name is not a literal", template: `{{ lookup .mapName .key }}`, want: nil},
+		{name: "no lookup", template: "{{ .clusterId }}", want: nil},
+		{name: "does not parse", template: `{{ lookup "a" `, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, templateLookupMaps(tt.template))
+		})
+	}
+}
+
+func TestCELLookupMaps(t *testing.T) {
+	env, err := cel.NewEnv(cel.OptionalTypes())
+	require.NoError(t, err)
+	tests := []struct {
+		name string
+		expr string
+		want []string
+	}{
+		{name: "call", expr: `lookup("phaseStatus", phase) == "READY"`, want: []string{"phaseStatus"}},
+		{name: "nested in a macro and a select",
+			expr: `pools.all(p, lookup("regions", p.region).datacenter != lookup("fallbacks", p.region))`,
+			want: []string{"regions", "fallbacks"}},
+		{name: "map name is not a literal", expr: `lookup(mapName, key)`, want: nil},
+		{name: "member call is not lookup", expr: `cache.lookup("phaseStatus", phase)`, want: nil},
+		{name: "one argument", expr: `lookup(clusterId).name`, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, issues := env.Parse(tt.expr)
+			require.NoError(t, issues.Err())
+			assert.Equal(t, tt.want, celLookupMaps(ast))
+		})
+	}
+}
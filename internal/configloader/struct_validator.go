@@ -76,9 +76,15 @@ func getStructValidator() *validator.Validate {
 			panic(fmt.Sprintf(
 				"failed to register validoperator validation: %v", err))
 		}
+		if err := structValidator.RegisterValidation(
+			"digest", validateDigest); err != nil {
+			panic(fmt.Sprintf(
+				"failed to register digest validation: %v", err))
+		}
 
 		// Register custom struct-level validations
 		structValidator.RegisterStructValidation(validateParameterEnvRequired, Parameter{})
+		structValidator.RegisterStructValidation(validateCaptureField, CaptureField{})
 
 		// Use yaml tag names for field names in errors
 		structValidator.RegisterTagNameFunc(extractYamlTagName)
@@ -102,6 +108,11 @@ func validateOperator(fl validator.FieldLevel) bool {
 	return criteria.IsValidOperator(fl.Field().String())
 }
 
+// validateDigest is a custom validator for file reference digests
+func validateDigest(fl validator.FieldLevel) bool {
+	return digestPattern.MatchString(fl.Field().String())
+}
+
 // validateParameterEnvRequired is a struct-level validator for Parameter.
 // Checks that required env params have their environment variables set.
 func validateParameterEnvRequired(sl validator.StructLevel) {
@@ -123,6 +134,38 @@ func validateParameterEnvRequired(sl validator.StructLevel) {
 	}
 }
 
+// validateCaptureField is a struct-level validator for CaptureField.
+// A header capture has a header and neither field nor expression; any other
+// capture has exactly one of field and expression.
+func validateCaptureField(sl validator.StructLevel) {
+	// type is guaranteed by RegisterStructValidation
+	//nolint:errcheck
+	capture := sl.Current().Interface().(CaptureField)
+
+	if capture.Source == CaptureSourceHeader {
+		if capture.Header == "" {
+			sl.ReportError(capture.Header, FieldHeader, "Header", "required", "")
+		}
+		if capture.Field != "" {
+			sl.ReportError(capture.Field, FieldField, "Field", "excluded_with", "Header")
+		}
+		if capture.Expression != "" {
+			sl.ReportError(capture.Expression, FieldExpression, "Expression", "excluded_with", "Header")
+		}
+		return
+	}
+
+	if capture.Header != "" {
+		sl.ReportError(capture.Source, FieldSource, "Source", "eq", CaptureSourceHeader)
+	}
+	switch {
+	case capture.Field == "" && capture.Expression == "":
+		sl.ReportError(capture.Field, FieldField, "Field", "required_without", "Expression")
+	case capture.Field != "" && capture.Expression != "":
+		sl.ReportError(capture.Field, FieldField, "Field", "excluded_with", "Expression")
+	}
+}
+
 // ValidateStruct validates a struct using go-playground/validator tags.
 // Returns a ValidationErrors with all validation failures.
 func ValidateStruct(s interface{}) *ValidationErrors {
@@ -177,6 +220,8 @@ func formatFullErrorMessage(e validator.FieldError) string {
 	case "validoperator":
 		return fmt.Sprintf("%s: invalid operator %q, must be one of: %s",
 			path, e.Value(), strings.Join(criteria.OperatorStrings(), ", "))
+	case "digest":
+		return fmt.Sprintf("%s %q: must be %s followed by 64 lowercase hex digits", path, e.Value(), DigestPrefix)
 	case "required_without_all":
 		// e.g., "must specify apiCall, expression, or conditions"
 		// Convert params like "ActionBase.APICall Expression Conditions"
@@ -191,6 +236,10 @@ func formatFullErrorMessage(e validator.FieldError) string {
 			cleanParams = append(cleanParams, yamlFieldName(p))
 		}
 		return fmt.Sprintf("%s: must specify %s", parentPath(path), strings.Join(cleanParams, ", "))
+	case "gt":
+		return fmt.Sprintf("%s: must be greater than %s (got %v)", path, e.Param(), e.Value())
+	case "gte":
+		return fmt.Sprintf("%s: must be greater than or equal to %s (got %v)", path, e.Param(), e.Value())
 	case "min":
 		return fmt.Sprintf("%s: must have at least %s element(s)", path, e.Param())
 	case "unique":
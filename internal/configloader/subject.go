@@ -0,0 +1,128 @@
+package configloader
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SubjectPattern is a parsed subject_patterns entry: a slash-separated path of
+// literal segments and named segments, e.g. "tenants/{tenant}/clusters/{clusterId}".
+// A "{name}" segment matches one non-empty segment; a last "{name...}" segment
+// matches the rest of the subject, one segment or more.
+type SubjectPattern struct {
+	raw      string
+	segments []subjectSegment
+}
+
+// subjectSegment is a literal segment when name is empty
+type subjectSegment struct {
+	literal string
+	name    string
+	rest    bool
+}
+
+// ParseSubjectPattern parses a subject pattern. Segment names must be valid
+// variable names, each used once.
+func ParseSubjectPattern(pattern string) (*SubjectPattern, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("subject pattern is empty")
+	}
+	parts := strings.Split(pattern, "/")
+	parsed := &SubjectPattern{raw: pattern, segments: make([]subjectSegment, len(parts))}
+	seen := make(map[string]bool)
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+			if strings.ContainsAny(part, "{}") {
+				return nil, fmt.Errorf("segment %q of subject pattern %q must be a literal or a whole {name}", part, pattern)
+			}
+			if part == "" {
+				return nil, fmt.Errorf("subject pattern %q has an empty segment", pattern)
+			}
+			parsed.segments[i] = subjectSegment{literal: part}
+			continue
+		}
+		name, rest := strings.CutSuffix(part[1:len(part)-1], "...")
+		switch {
+		case !varNamePattern.MatchString(name):
+			return nil, fmt.Errorf("segment %q of subject pattern %q is not a valid variable name", part, pattern)
+		case seen[name]:
+			return nil, fmt.Errorf("segment name %q is used twice in subject pattern %q", name, pattern)
+		case rest && i != len(parts)-1:
+			return nil, fmt.Errorf("wildcard segment %q must be the last of subject pattern %q", part, pattern)
+		}
+		seen[name] = true
+		parsed.segments[i] = subjectSegment{name: name, rest: rest}
+	}
+	return parsed, nil
+}
+
+// String returns the pattern as configured
+func (p *SubjectPattern) String() string {
+	return p.raw
+}
+
+// Names returns the names of the segments of the pattern, in order
+func (p *SubjectPattern) Names() []string {
+	var names []string
+	for _, segment := range p.segments {
+		if segment.name != "" {
+			names = append(names, segment.name)
+		}
+	}
+	return names
+}
+
+// Match matches subject against the pattern and returns the values of its
+// named segments. The segments of subject are URL-decoded before they are
+// compared or captured, so "a%2Fb" is the single segment "a/b"; a wildcard
+// captures its decoded segments joined with "/".
+func (p *SubjectPattern) Match(subject string) (map[string]string, bool) {
+	if subject == "" {
+		return nil, false
+	}
+	parts := strings.Split(subject, "/")
+	decoded := make([]string, len(parts))
+	for i, part := range parts {
+		value, err := url.PathUnescape(part)
+		if err != nil || value == "" {
+			return nil, false
+		}
+		decoded[i] = value
+	}
+
+	values := make(map[string]string)
+	for i, segment := range p.segments {
+		if i >= len(decoded) {
+			return nil, false
+		}
+		switch {
+		case segment.rest:
+			values[segment.name] = strings.Join(decoded[i:], "/")
+			return values, true
+		case segment.name != "":
+			values[segment.name] = decoded[i]
+		case segment.literal != decoded[i]:
+			return nil, false
+		}
+	}
+	if len(decoded) != len(p.segments) {
+		return nil, false
+	}
+	return values, true
+}
+
+// ParsedSubjectPatterns parses the subject patterns of the config, in order.
+// The patterns are checked by validation, so an invalid one is an error only
+// for configs loaded without it.
+func (c *Config) ParsedSubjectPatterns() ([]*SubjectPattern, error) {
+	patterns := make([]*SubjectPattern, 0, len(c.SubjectPatterns))
+	for _, raw := range c.SubjectPatterns {
+		pattern, err := ParseSubjectPattern(raw)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
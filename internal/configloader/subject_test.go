@@ -0,0 +1,55 @@
+package configloader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubjectPattern_Match(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		subject string
+		want    map[string]string
+	}{
+		{name: "named segments", pattern: "tenants/{tenant}/clusters/{clusterId}",
+			subject: "tenants/acme/clusters/c1", want: map[string]string{"tenant": "acme", "clusterId": "c1"}},
+		{name: "literal mismatch", pattern: "tenants/{tenant}/clusters/{clusterId}",
+			subject: "tenants/acme/nodepools/c1"},
+		{name: "too few segments", pattern: "clusters/{clusterId}", subject: "clusters"},
+		{name: "too many segments", pattern: "clusters/{clusterId}", subject: "clusters/c1/status"},
+		{name: "url-encoded segments", pattern: "clusters/{clusterId}", subject: "clusters/c%2F1%20a",
+			want: map[string]string{"clusterId": "c/1 a"}},
+		{name: "encoded literal", pattern: "node pools/{id}", subject: "node%20pools/np1",
+			want: map[string]string{"id": "np1"}},
+		{name: "invalid escape", pattern: "clusters/{clusterId}", subject: "clusters/c%zz"},
+		{name: "empty segment", pattern: "clusters/{clusterId}", subject: "clusters/"},
+		{name: "empty subject", pattern: "{id}", subject: ""},
+		{name: "wildcard", pattern: "clusters/{clusterId}/{rest...}", subject: "clusters/c1/a/b%2Fc",
+			want: map[string]string{"clusterId": "c1", "rest": "a/b/c"}},
+		{name: "wildcard needs a segment", pattern: "clusters/{clusterId}/{rest...}", subject: "clusters/c1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern, err := ParseSubjectPattern(tt.pattern)
+			require.NoError(t, err)
+			got, ok := pattern.Match(tt.subject)
+			assert.Equal(t, tt.want != nil, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseSubjectPattern(t *testing.T) {
+	pattern, err := ParseSubjectPattern("tenants/{tenant}/clusters/{clusterId}/{rest...}")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tenant", "clusterId", "rest"}, pattern.Names())
+	assert.Equal(t, "tenants/{tenant}/clusters/{clusterId}/{rest...}", pattern.String())
+
+	_, err = ParseSubjectPattern("")
+	assert.EqualError(t, err, "subject pattern is empty")
+	_, err = ParseSubjectPattern("clusters/{}")
+	assert.ErrorContains(t, err, "is not a valid variable name")
+}
@@ -2,9 +2,15 @@ package configloader
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/schedule"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/webhook"
 	"gopkg.in/yaml.v3"
 )
 
@@ -14,16 +20,69 @@ type Config struct {
 	Post          *PostConfig    `yaml:"post,omitempty"`
 	Log           LogConfig      `yaml:"log,omitempty"`
 	Adapter       AdapterInfo    `yaml:"adapter"`
+	Health        HealthConfig   `yaml:"health,omitempty"`
+	EventSchemas  []EventSchema  `yaml:"event_schemas,omitempty"`
 	Params        []Parameter    `yaml:"params,omitempty"`
 	Preconditions []Precondition `yaml:"preconditions,omitempty"`
 	Resources     []Resource     `yaml:"resources,omitempty"`
 	Clients       ClientsConfig  `yaml:"clients"`
-	DebugConfig   bool           `yaml:"debug_config,omitempty"`
+	// Audit configures the per-execution audit log (nil disables it)
+	Audit *AuditConfig `yaml:"audit,omitempty"`
+	// Journal configures the execution journal (nil disables it)
+	Journal *JournalConfig `yaml:"journal,omitempty"`
+	// Correlation names the header and annotation carrying the execution correlation ID
+	Correlation CorrelationConfig `yaml:"correlation,omitempty"`
+	// Metrics configures the optional executor metrics
+	Metrics MetricsConfig `yaml:"metrics,omitempty"`
+	// Policy restricts the kinds and namespaces of the applied objects (nil allows any)
+	Policy *ResourcePolicy `yaml:"policy,omitempty"`
+	// FeatureFlags configures the flags of the flag() CEL function (nil: every flag is off)
+	FeatureFlags *FeatureFlagsConfig `yaml:"feature_flags,omitempty"`
+	// PostBuffer configures the queue of buffer_on_failure (nil disables buffering)
+	PostBuffer *PostBufferConfig `yaml:"post_buffer,omitempty"`
+	// Vars are constant params (see AdapterTaskConfig.Vars)
+	Vars map[string]interface{} `yaml:"vars,omitempty"`
+	// RequiredParams are checked after param extraction (see AdapterTaskConfig.RequiredParams)
+	RequiredParams []string `yaml:"required_params,omitempty"`
+	// Sources are the absolute paths of the files the config was loaded from:
+	// the adapter config, the task config and the referenced files (populated by loader)
+	Sources     []string `yaml:"-"`
+	DebugConfig bool     `yaml:"debug_config,omitempty"`
+	// ReportParamFailures runs the post actions after a param extraction failure
+	ReportParamFailures bool `yaml:"report_param_failures,omitempty"`
+	// PreconditionErrorPolicy (see AdapterTaskConfig.PreconditionErrorPolicy)
+	PreconditionErrorPolicy string `yaml:"precondition_error_policy,omitempty"`
+	// PreconditionConcurrency (see AdapterTaskConfig.PreconditionConcurrency)
+	PreconditionConcurrency int `yaml:"precondition_concurrency,omitempty"`
+	// ExecutionFence serializes executions by key (see AdapterTaskConfig.ExecutionFence)
+	ExecutionFence *ExecutionFence `yaml:"execution_fence,omitempty"`
+	// NotMetBackoff escalates repeated not-met executions (see AdapterTaskConfig.NotMetBackoff)
+	NotMetBackoff *NotMetBackoff `yaml:"not_met_backoff,omitempty"`
+	// Workflows are the named workflows selected by event (see AdapterTaskConfig.Workflows)
+	Workflows []Workflow `yaml:"workflows,omitempty"`
+	// EventFilter filters events before execution (see AdapterTaskConfig.EventFilter)
+	EventFilter *EventFilter `yaml:"event_filter,omitempty"`
+	// PlainTextDataKey wraps text/plain event data (see AdapterTaskConfig.PlainTextDataKey)
+	PlainTextDataKey string `yaml:"plain_text_data_key,omitempty"`
+	// Schedule restricts resources to maintenance windows (see AdapterTaskConfig.Schedule)
+	Schedule *Schedule `yaml:"schedule,omitempty"`
+	// Limits bounds the memory retained by an execution (see AdapterTaskConfig.Limits)
+	Limits *Limits `yaml:"limits,omitempty"`
+	// Finalizer is kept on a source object until teardown (see AdapterTaskConfig.Finalizer)
+	Finalizer *Finalizer `yaml:"finalizer,omitempty"`
+	// Idempotency skips stale generations (see AdapterTaskConfig.Idempotency)
+	Idempotency *Idempotency `yaml:"idempotency,omitempty"`
+	// SubjectPatterns extract params from the event subject (see AdapterTaskConfig.SubjectPatterns)
+	SubjectPatterns []string `yaml:"subject_patterns,omitempty"`
+	// SubjectOptional tolerates unmatched subjects (see AdapterTaskConfig.SubjectOptional)
+	SubjectOptional bool `yaml:"subject_optional,omitempty"`
+	// Maps are the lookup tables of lookup() (see AdapterTaskConfig.Maps)
+	Maps []LookupMap `yaml:"maps,omitempty"`
 }
 
 // Merge combines AdapterConfig (deployment) and AdapterTaskConfig (task) into a unified Config.
-// The adapter info and clients come from the deployment config.
-// The params, preconditions, resources, and post-processing come from the task config.
+// The adapter info, health checks and clients come from the deployment config.
+// The event schemas, params, preconditions, resources, and post-processing come from the task config.
 func Merge(adapterCfg *AdapterConfig, taskCfg *AdapterTaskConfig) *Config {
 	if adapterCfg == nil || taskCfg == nil {
 		return nil
@@ -33,11 +92,39 @@ func Merge(adapterCfg *AdapterConfig, taskCfg *AdapterTaskConfig) *Config {
 		Adapter:       adapterCfg.Adapter,
 		Clients:       adapterCfg.Clients,
 		DebugConfig:   adapterCfg.DebugConfig,
+		Health:        adapterCfg.Health,
 		Log:           adapterCfg.Log,
+		Audit:         adapterCfg.Audit,
+		Journal:       adapterCfg.Journal,
+		Correlation:   adapterCfg.Correlation,
+		Metrics:       adapterCfg.Metrics,
+		Policy:        adapterCfg.Policy,
+		FeatureFlags:  adapterCfg.FeatureFlags,
+		PostBuffer:    adapterCfg.PostBuffer,
+		EventSchemas:  taskCfg.EventSchemas,
 		Params:        taskCfg.Params,
+		Vars:          taskCfg.Vars,
 		Preconditions: taskCfg.Preconditions,
 		Resources:     taskCfg.Resources,
 		Post:          taskCfg.Post,
+
+		RequiredParams:      taskCfg.RequiredParams,
+		ReportParamFailures: taskCfg.ReportParamFailures,
+
+		PreconditionErrorPolicy: taskCfg.PreconditionErrorPolicy,
+		PreconditionConcurrency: taskCfg.PreconditionConcurrency,
+		ExecutionFence:          taskCfg.ExecutionFence,
+		NotMetBackoff:           taskCfg.NotMetBackoff,
+		Workflows:               taskCfg.Workflows,
+		EventFilter:             taskCfg.EventFilter,
+		PlainTextDataKey:        taskCfg.PlainTextDataKey,
+		Schedule:                taskCfg.Schedule,
+		Limits:                  taskCfg.Limits,
+		Finalizer:               taskCfg.Finalizer,
+		Idempotency:             taskCfg.Idempotency,
+		SubjectPatterns:         taskCfg.SubjectPatterns,
+		SubjectOptional:         taskCfg.SubjectOptional,
+		Maps:                    taskCfg.Maps,
 	}
 }
 
@@ -49,27 +136,45 @@ func (c *Config) Redacted() *Config {
 		return nil
 	}
 	copy := *c
-	copy.Clients = redactedClients(c.Clients)
+	copy.Clients = redactClients(c.Clients, redactedValue)
+	copy.Audit = redactAudit(c.Audit, redactedValue)
 	return &copy
 }
 
-func redactedClients(clients ClientsConfig) ClientsConfig {
+// redactAudit returns a copy of audit with the audit HTTP header values, which
+// usually carry credentials, replaced by placeholder
+func redactAudit(audit *AuditConfig, placeholder string) *AuditConfig {
+	if audit == nil || audit.HTTP == nil || len(audit.HTTP.Headers) == 0 {
+		return audit
+	}
+	auditCopy := *audit
+	httpCopy := *audit.HTTP
+	httpCopy.Headers = make(map[string]string, len(audit.HTTP.Headers))
+	for name := range audit.HTTP.Headers {
+		httpCopy.Headers[name] = placeholder
+	}
+	auditCopy.HTTP = &httpCopy
+	return &auditCopy
+}
+
+// redactClients returns a copy of clients with the Maestro TLS file paths replaced by placeholder
+func redactClients(clients ClientsConfig, placeholder string) ClientsConfig {
 	copy := clients
 	if clients.Maestro != nil {
 		maestroCopy := *clients.Maestro
 		if maestroCopy.Auth.TLSConfig != nil {
 			tlsCopy := *maestroCopy.Auth.TLSConfig
 			if tlsCopy.CAFile != "" {
-				tlsCopy.CAFile = redactedValue
+				tlsCopy.CAFile = placeholder
 			}
 			if tlsCopy.CertFile != "" {
-				tlsCopy.CertFile = redactedValue
+				tlsCopy.CertFile = placeholder
 			}
 			if tlsCopy.KeyFile != "" {
-				tlsCopy.KeyFile = redactedValue
+				tlsCopy.KeyFile = placeholder
 			}
 			if tlsCopy.HTTPCAFile != "" {
-				tlsCopy.HTTPCAFile = redactedValue
+				tlsCopy.HTTPCAFile = placeholder
 			}
 			maestroCopy.Auth.TLSConfig = &tlsCopy
 		}
@@ -103,22 +208,90 @@ type FieldExpressionDef struct {
 //	status:
 //	  expression: "adapter.?errorMessage.orValue(\"\")"
 //	  default: "success"
+//
+// OnError chooses what a field or expression that fails to evaluate, e.g. on a
+// missing key, yields: null, the default, or no key at all; or whether it fails
+// the payload build.
 type ValueDef struct {
 	// Default value if extraction fails or returns nil
-	Default            any `yaml:"default"`
+	Default            any    `yaml:"default"`
+	OnError            string `yaml:"on_error,omitempty"`
 	FieldExpressionDef `yaml:",inline"`
 }
 
+// Value definition on_error policies
+const (
+	// OnErrorNull sets the value to null
+	OnErrorNull = "null"
+	// OnErrorOmit drops the key from its map, or the element from its list
+	OnErrorOmit = "omit"
+	// OnErrorFail fails the payload build
+	OnErrorFail = "fail"
+	// OnErrorDefault sets the value to the default
+	OnErrorDefault = "default"
+)
+
+// ValidOnErrorPolicies lists the value definition on_error policies
+var ValidOnErrorPolicies = []string{OnErrorNull, OnErrorOmit, OnErrorFail, OnErrorDefault}
+
+// EffectiveOnError returns the on_error policy of the value definition. Without
+// one, a failed evaluation yields the default when set, and null otherwise.
+func (d *ValueDef) EffectiveOnError() string {
+	switch {
+	case d.OnError != "":
+		return d.OnError
+	case d.Default != nil:
+		return OnErrorDefault
+	default:
+		return OnErrorNull
+	}
+}
+
 // ParseValueDef attempts to parse a value as a ValueDef.
 // Returns the parsed ValueDef and true if the value contains either field or expression.
 // Returns nil and false if the value is not a value definition.
 func ParseValueDef(v any) (*ValueDef, bool) {
 	// Must be a map to be a value definition
-	if _, ok := v.(map[string]any); !ok {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	// Fast path: payload builds call this for every map node, and most nodes are
+	// plain objects, or value definitions with string field/expression entries
+	field, hasField := m["field"]
+	expression, hasExpression := m["expression"]
+	if !hasField && !hasExpression {
 		return nil, false
 	}
+	fieldStr, fieldIsString := field.(string)
+	expressionStr, expressionIsString := expression.(string)
+	if (fieldIsString || !hasField) && (expressionIsString || !hasExpression) {
+		if fieldStr == "" && expressionStr == "" {
+			return nil, false
+		}
+		onError, hasOnError := m["on_error"]
+		onErrorStr, onErrorIsString := onError.(string)
+		switch {
+		case !hasOnError || onErrorIsString:
+		case onError == nil:
+			// An unquoted on_error: null
+			onErrorStr = OnErrorNull
+		default:
+			return parseValueDefYAML(m)
+		}
+		return &ValueDef{
+			Default:            m["default"],
+			OnError:            onErrorStr,
+			FieldExpressionDef: FieldExpressionDef{Field: fieldStr, Expression: expressionStr},
+		}, true
+	}
+	return parseValueDefYAML(m)
+}
 
-	// Marshal to YAML then unmarshal to ValueDef
+// parseValueDefYAML parses a value definition by marshaling it to YAML and
+// unmarshaling it into a ValueDef, converting scalar field/expression entries
+func parseValueDefYAML(v map[string]any) (*ValueDef, bool) {
 	data, err := yaml.Marshal(v)
 	if err != nil {
 		return nil, false
@@ -148,17 +321,378 @@ type AdapterInfo struct {
 type LogConfig struct {
 	Level  string `yaml:"level,omitempty" mapstructure:"level"`
 	Format string `yaml:"format,omitempty" mapstructure:"format"`
-	Output string `yaml:"output,omitempty" mapstructure:"output"`
+	// Output is stdout, stderr, file or both (stdout and file)
+	Output string `yaml:"output,omitempty" mapstructure:"output" jsonschema:"enum=stdout stderr file both"`
+	// File configures the log file written with the file and both outputs
+	File LogFileConfig `yaml:"file,omitempty" mapstructure:"file"`
+}
+
+// LogFileConfig configures the rotating log file
+type LogFileConfig struct {
+	Path string `yaml:"path,omitempty" mapstructure:"path"`
+	// MaxSizeMB is the size at which the file is rotated. Default: 100.
+	MaxSizeMB int `yaml:"max_size_mb,omitempty" mapstructure:"max_size_mb"`
+	// MaxBackups is the number of rotated files kept; 0 keeps all of them
+	MaxBackups int `yaml:"max_backups,omitempty" mapstructure:"max_backups"`
+	// MaxAgeDays is the number of days rotated files are kept; 0 keeps them regardless of age
+	MaxAgeDays int  `yaml:"max_age_days,omitempty" mapstructure:"max_age_days"`
+	Compress   bool `yaml:"compress,omitempty" mapstructure:"compress"`
+}
+
+// Correlation ID defaults
+const (
+	DefaultCorrelationHeader     = "X-Correlation-Id"
+	DefaultCorrelationAnnotation = "hyperfleet.io/correlation-id"
+)
+
+// CorrelationConfig names where the correlation ID of an execution is propagated
+type CorrelationConfig struct {
+	// Header is sent with the correlation ID on every HyperFleet API call. Empty uses X-Correlation-Id.
+	Header string `yaml:"header,omitempty" mapstructure:"header"`
+	// Annotation is set to the correlation ID on applied resources and ManifestWorks.
+	// Empty uses hyperfleet.io/correlation-id.
+	Annotation string `yaml:"annotation,omitempty" mapstructure:"annotation"`
+}
+
+// HeaderName returns the correlation ID header, DefaultCorrelationHeader when unset
+func (c CorrelationConfig) HeaderName() string {
+	if c.Header == "" {
+		return DefaultCorrelationHeader
+	}
+	return c.Header
+}
+
+// AnnotationName returns the correlation ID annotation, DefaultCorrelationAnnotation when unset
+func (c CorrelationConfig) AnnotationName() string {
+	if c.Annotation == "" {
+		return DefaultCorrelationAnnotation
+	}
+	return c.Annotation
+}
+
+// MetricsConfig configures the optional executor metrics
+type MetricsConfig struct {
+	// PerStep exports the duration of every precondition, resource and post
+	// action, labeled by its name
+	PerStep bool `yaml:"per_step,omitempty" mapstructure:"per_step"`
+}
+
+// AuditConfig configures the audit log: one record per execution written to
+// the file sink, the HTTP sink, or both
+type AuditConfig struct {
+	// HTTP POSTs each record to an endpoint. Nil disables the HTTP sink.
+	HTTP *AuditHTTPConfig `yaml:"http,omitempty" mapstructure:"http"`
+	// File appends each record as a JSON line to a rotating file. An empty path disables the file sink.
+	File LogFileConfig `yaml:"file,omitempty" mapstructure:"file"`
+	// QueueSize bounds the records waiting to be written. Zero uses the default (1000).
+	QueueSize int `yaml:"queue_size,omitempty" mapstructure:"queue_size" validate:"gte=0"`
+}
+
+// AuditHTTPConfig configures the audit HTTP sink
+type AuditHTTPConfig struct {
+	// Headers are sent with every request, e.g. an Authorization header
+	Headers map[string]string `yaml:"headers,omitempty" mapstructure:"headers"`
+	URL     string            `yaml:"url" mapstructure:"url" validate:"required,url"`
+	// Timeout bounds each attempt. Zero uses the default (5s).
+	Timeout time.Duration `yaml:"timeout,omitempty" mapstructure:"timeout" validate:"gte=0"`
+	// BaseDelay is the delay before the first retry, doubled after each retry. Zero uses the default (500ms).
+	BaseDelay time.Duration `yaml:"base_delay,omitempty" mapstructure:"base_delay" validate:"gte=0"`
+	// RetryAttempts is the number of attempts, including the first. Zero uses the default (3).
+	RetryAttempts int `yaml:"retry_attempts,omitempty" mapstructure:"retry_attempts" validate:"gte=0"`
+}
+
+// HealthConfig configures the dependency checks run by the /readyz endpoint
+type HealthConfig struct {
+	// Checks toggles the individual dependency checks
+	Checks HealthChecksConfig `yaml:"checks,omitempty" mapstructure:"checks"`
+	// HyperfleetAPIPath is the endpoint probed by the hyperfleet_api check,
+	// relative to the API base URL. Empty uses /api/hyperfleet/<version>/clusters?pageSize=1.
+	HyperfleetAPIPath string `yaml:"hyperfleet_api_path,omitempty" mapstructure:"hyperfleet_api_path"`
+	// CheckTimeout bounds each dependency check. Zero uses the default (2s).
+	CheckTimeout time.Duration `yaml:"check_timeout,omitempty" mapstructure:"check_timeout" validate:"gte=0"`
+	// CacheTTL is how long a check result is reused across probes. Zero uses the default (5s).
+	CacheTTL time.Duration `yaml:"cache_ttl,omitempty" mapstructure:"cache_ttl" validate:"gte=0"`
+	// Liveness sets the staleness thresholds of the subsystem heartbeats served by /livez
+	Liveness LivenessConfig `yaml:"liveness,omitempty" mapstructure:"liveness"`
+	// ExecutionHistorySize is the number of recent executions served by /statusz. Zero uses the default (100).
+	ExecutionHistorySize int `yaml:"execution_history_size,omitempty" mapstructure:"execution_history_size" validate:"gte=0"`
+	// CombinedPort serves /metrics from the health server port instead of a separate metrics port
+	CombinedPort bool `yaml:"combined_port,omitempty" mapstructure:"combined_port"`
+}
+
+// LivenessConfig sets how long a subsystem may go without a heartbeat before
+// /livez reports it stale. Zero only reports the last heartbeat.
+type LivenessConfig struct {
+	// BrokerStaleAfter is the longest acceptable gap between received messages
+	BrokerStaleAfter time.Duration `yaml:"broker_stale_after,omitempty" mapstructure:"broker_stale_after" validate:"gte=0"`
+	// ExecutorStaleAfter is the longest acceptable gap between completed executions
+	ExecutorStaleAfter time.Duration `yaml:"executor_stale_after,omitempty" mapstructure:"executor_stale_after" validate:"gte=0"`
+}
+
+// HealthChecksConfig enables or disables each dependency check. Unset checks are enabled.
+type HealthChecksConfig struct {
+	// Broker reports each subscription's receive status
+	Broker *bool `yaml:"broker,omitempty" mapstructure:"broker"`
+	// HyperfleetAPI probes the HyperFleet API
+	HyperfleetAPI *bool `yaml:"hyperfleet_api,omitempty" mapstructure:"hyperfleet_api"`
+	// Kubernetes verifies the API server accepts the client's credentials (Kubernetes transport)
+	Kubernetes *bool `yaml:"kubernetes,omitempty" mapstructure:"kubernetes"`
+	// Maestro probes the Maestro HTTP API (Maestro transport)
+	Maestro *bool `yaml:"maestro,omitempty" mapstructure:"maestro"`
+}
+
+// CheckEnabled reports whether a dependency check toggle is enabled (unset means enabled)
+func CheckEnabled(toggle *bool) bool {
+	return toggle == nil || *toggle
 }
 
 // HyperfleetAPIConfig is the HyperFleet API client configuration.
 // Alias to hyperfleetapi.ClientConfig to ensure shared schema.
 type HyperfleetAPIConfig = hyperfleetapi.ClientConfig
 
+// WebhookClientConfig is the webhook client configuration.
+// Alias to webhook.ClientConfig to ensure shared schema.
+type WebhookClientConfig = webhook.ClientConfig
+
 // BrokerConfig contains broker consumer configuration
 type BrokerConfig struct {
-	SubscriptionID string `yaml:"subscription_id,omitempty" mapstructure:"subscription_id"`
-	Topic          string `yaml:"topic,omitempty" mapstructure:"topic"`
+	// RateLimit throttles handler invocations. Nil or zero values disable rate limiting.
+	RateLimit *RateLimitConfig `yaml:"rate_limit,omitempty" mapstructure:"rate_limit"`
+	// Dedup skips redelivered events that were already processed. Nil disables deduplication.
+	Dedup *DedupConfig `yaml:"dedup,omitempty" mapstructure:"dedup"`
+	// Requeue configures the delayed redelivery of events whose execution asks to be
+	// retried after a delay. Nil uses the defaults.
+	Requeue *RequeueConfig `yaml:"requeue,omitempty" mapstructure:"requeue"`
+	// Queue puts a bounded queue and a fixed pool of workers between the receive
+	// loops and the executor. Nil or a zero depth hands events to the executor directly.
+	Queue *QueueConfig `yaml:"queue,omitempty" mapstructure:"queue"`
+	// Subscriptions lists the subscriptions consumed by this adapter instance, all
+	// dispatching to the same executor. Takes precedence over SubscriptionID/Topic.
+	Subscriptions  []SubscriptionConfig `yaml:"subscriptions,omitempty" mapstructure:"subscriptions" validate:"unique=SubscriptionID,dive"`
+	SubscriptionID string               `yaml:"subscription_id,omitempty" mapstructure:"subscription_id"`
+	Topic          string               `yaml:"topic,omitempty" mapstructure:"topic"`
+	// OversizedDeadLetterTopic receives events over MaxEventBytes, with their data
+	// truncated. Empty drops them.
+	//nolint:lll
+	OversizedDeadLetterTopic string `yaml:"oversized_dead_letter_topic,omitempty" mapstructure:"oversized_dead_letter_topic"`
+	// StartFailurePolicy controls what happens when one subscription fails to start:
+	// "fatal" (default) aborts startup, "degraded" starts the others and reports the
+	// failed one as not ready.
+	StartFailurePolicy string `yaml:"start_failure_policy,omitempty" mapstructure:"start_failure_policy" validate:"omitempty,oneof=fatal degraded"`
+	// MaxEventBytes is the largest accepted event data size. Larger events are
+	// acknowledged without being executed. Zero disables the limit.
+	MaxEventBytes int `yaml:"max_event_bytes,omitempty" mapstructure:"max_event_bytes" validate:"gte=0"`
+	// RedeliveryWarnAttempts is the delivery attempt from which an event NACKed
+	// again is logged as a warning with its cause. Zero disables the warning.
+	//nolint:lll
+	RedeliveryWarnAttempts int `yaml:"redelivery_warn_attempts,omitempty" mapstructure:"redelivery_warn_attempts" validate:"gte=0"`
+}
+
+// Subscription start failure policies
+const (
+	StartFailureFatal    = "fatal"
+	StartFailureDegraded = "degraded"
+)
+
+// SubscriptionConfig is a single broker subscription
+type SubscriptionConfig struct {
+	// FlowControl overrides the broker library's receive settings for this subscription
+	FlowControl    *FlowControlConfig `yaml:"flow_control,omitempty" mapstructure:"flow_control"`
+	SubscriptionID string             `yaml:"subscription_id" mapstructure:"subscription_id" validate:"required"`
+	Topic          string             `yaml:"topic" mapstructure:"topic" validate:"required"`
+}
+
+// FlowControlConfig configures per-subscription receive flow control.
+// Zero values keep the broker config (broker.yaml / BROKER_* env) setting.
+type FlowControlConfig struct {
+	// Parallelism is the number of concurrent handler workers
+	Parallelism int `yaml:"parallelism,omitempty" mapstructure:"parallelism" validate:"gte=0"`
+	// MaxOutstandingMessages bounds unacknowledged messages held by the client (googlepubsub)
+	MaxOutstandingMessages int `yaml:"max_outstanding_messages,omitempty" mapstructure:"max_outstanding_messages" validate:"gte=0"`
+	// MaxOutstandingBytes bounds the size of unacknowledged messages held by the client (googlepubsub)
+	MaxOutstandingBytes int `yaml:"max_outstanding_bytes,omitempty" mapstructure:"max_outstanding_bytes" validate:"gte=0"`
+	// NumGoroutines is the number of streaming pull goroutines (googlepubsub)
+	NumGoroutines int `yaml:"num_goroutines,omitempty" mapstructure:"num_goroutines" validate:"gte=0"`
+	// PrefetchCount is the AMQP prefetch count (rabbitmq)
+	PrefetchCount int `yaml:"prefetch_count,omitempty" mapstructure:"prefetch_count" validate:"gte=0"`
+}
+
+// BrokerSettings returns the flow control overrides as hyperfleet-broker config keys.
+// Returns nil when nothing is overridden.
+func (c *FlowControlConfig) BrokerSettings() map[string]string {
+	if c == nil {
+		return nil
+	}
+	settings := map[string]string{}
+	set := func(key string, value int) {
+		if value > 0 {
+			settings[key] = strconv.Itoa(value)
+		}
+	}
+	set("subscriber.parallelism", c.Parallelism)
+	set("broker.googlepubsub.max_outstanding_messages", c.MaxOutstandingMessages)
+	set("broker.googlepubsub.max_outstanding_bytes", c.MaxOutstandingBytes)
+	set("broker.googlepubsub.num_goroutines", c.NumGoroutines)
+	set("broker.rabbitmq.prefetch_count", c.PrefetchCount)
+	if len(settings) == 0 {
+		return nil
+	}
+	return settings
+}
+
+// EffectiveSubscriptions returns the configured subscriptions. When the
+// subscriptions list is empty, the single subscription_id/topic pair is used.
+func (c *BrokerConfig) EffectiveSubscriptions() []SubscriptionConfig {
+	if len(c.Subscriptions) > 0 {
+		return c.Subscriptions
+	}
+	if c.SubscriptionID == "" && c.Topic == "" {
+		return nil
+	}
+	return []SubscriptionConfig{{SubscriptionID: c.SubscriptionID, Topic: c.Topic}}
+}
+
+// FailFast reports whether a subscription failure aborts the adapter (policy "fatal")
+func (c *BrokerConfig) FailFast() bool {
+	return c.StartFailurePolicy != StartFailureDegraded
+}
+
+// RateLimitConfig configures the token-bucket rate limiter in front of the event handler
+type RateLimitConfig struct {
+	// EventsPerSecond is the sustained rate of handled events. Zero disables rate limiting.
+	EventsPerSecond float64 `yaml:"events_per_second,omitempty" mapstructure:"events_per_second" validate:"gte=0"`
+	// Burst is the bucket size. Zero defaults to ceil(EventsPerSecond).
+	Burst int `yaml:"burst,omitempty" mapstructure:"burst" validate:"gte=0"`
+}
+
+// Enabled reports whether rate limiting is configured
+func (c *RateLimitConfig) Enabled() bool {
+	return c != nil && c.EventsPerSecond > 0
+}
+
+// DefaultRequeueMaxDelay caps the redelivery delay of a requeued event when
+// clients.broker.requeue.max_delay is unset
+const DefaultRequeueMaxDelay = 5 * time.Minute
+
+// RequeueConfig configures delayed redelivery of events whose execution requests a
+// retry after a delay (a precondition with retry_after, or HTTP 429 with Retry-After)
+type RequeueConfig struct {
+	// MaxDelay caps the requested redelivery delay. Zero uses the default (5m).
+	MaxDelay time.Duration `yaml:"max_delay,omitempty" mapstructure:"max_delay" validate:"gte=0"`
+	// MaxHeld bounds the events of a subscription held for their delay at once.
+	// Zero uses the default (1).
+	MaxHeld int `yaml:"max_held,omitempty" mapstructure:"max_held" validate:"gte=0"`
+}
+
+// Queue full policies
+const (
+	QueueFullBlock  = "block"
+	QueueFullReject = "reject"
+)
+
+// QueueConfig configures the bounded queue between the broker receive loops and
+// the executor workers
+type QueueConfig struct {
+	// Depth is the number of received events waiting for a worker. Zero disables the queue.
+	Depth int `yaml:"depth,omitempty" mapstructure:"depth" validate:"gte=0"`
+	// Workers is the number of events executed at once. Zero uses the default (1).
+	Workers int `yaml:"workers,omitempty" mapstructure:"workers" validate:"gte=0"`
+	// WhenFull is what happens to an event received while the queue is full:
+	// "block" (default) waits for room, "reject" NACKs it right away
+	WhenFull string `yaml:"when_full,omitempty" mapstructure:"when_full" validate:"omitempty,oneof=block reject"`
+}
+
+// Enabled reports whether the queue is configured
+func (c *QueueConfig) Enabled() bool {
+	return c != nil && c.Depth > 0
+}
+
+// Dedup store types
+const (
+	DedupStoreMemory    = "memory"
+	DedupStoreConfigMap = "configmap"
+)
+
+// DedupConfig configures deduplication of redelivered events
+type DedupConfig struct {
+	// Store selects the backend: "memory" (lost on restart) or "configmap" (persisted)
+	Store string `yaml:"store" mapstructure:"store" validate:"required,oneof=memory configmap"`
+	// ConfigMapName and ConfigMapNamespace locate the ConfigMap for the "configmap" store
+	ConfigMapName      string `yaml:"configmap_name,omitempty" mapstructure:"configmap_name"`
+	ConfigMapNamespace string `yaml:"configmap_namespace,omitempty" mapstructure:"configmap_namespace"`
+	// MaxEntries bounds the number of remembered event IDs. Zero uses the default (10000).
+	MaxEntries int `yaml:"max_entries,omitempty" mapstructure:"max_entries" validate:"gte=0"`
+	// TTL is how long a processed event ID is remembered. Zero uses the default (1h).
+	TTL time.Duration `yaml:"ttl,omitempty" mapstructure:"ttl"`
+	// FlushInterval is how often the "configmap" store persists new IDs. Zero uses the default (10s).
+	FlushInterval time.Duration `yaml:"flush_interval,omitempty" mapstructure:"flush_interval"`
+}
+
+// Journal store types
+const (
+	JournalStoreFile      = "file"
+	JournalStoreConfigMap = "configmap"
+)
+
+// JournalConfig configures the execution journal: executions whose resources
+// were applied are recorded until their post actions ran, and the post actions
+// of the executions left by a crash are run at startup
+type JournalConfig struct {
+	// Store selects the backend: "file" (a directory on a persistent volume) or "configmap"
+	Store string `yaml:"store" mapstructure:"store" validate:"required,oneof=file configmap"`
+	// Path is the directory of the "file" store
+	Path string `yaml:"path,omitempty" mapstructure:"path"`
+	// ConfigMapName and ConfigMapNamespace locate the ConfigMap of the "configmap"
+	// store, one per adapter instance
+	ConfigMapName      string `yaml:"configmap_name,omitempty" mapstructure:"configmap_name"`
+	ConfigMapNamespace string `yaml:"configmap_namespace,omitempty" mapstructure:"configmap_namespace"`
+	// MaxEntries bounds the number of journaled executions. Zero uses the default (100).
+	MaxEntries int `yaml:"max_entries,omitempty" mapstructure:"max_entries" validate:"gte=0"`
+	// MaxEntryBytes bounds the size of a journaled execution. Zero uses the default (256KiB).
+	MaxEntryBytes int `yaml:"max_entry_bytes,omitempty" mapstructure:"max_entry_bytes" validate:"gte=0"`
+}
+
+// ResourcePolicy restricts the objects the adapter applies, whatever the task
+// config renders: it is checked on the manifests at load where they are not
+// templated, and on every rendered object before it is applied or included in
+// a ManifestWork. An unset list allows anything.
+type ResourcePolicy struct {
+	// AllowedKinds are the kinds that may be applied, "Kind" for any group or
+	// "Kind.group", e.g. "Deployment.apps"
+	AllowedKinds []string `yaml:"allowed_kinds,omitempty" mapstructure:"allowed_kinds" validate:"dive,required"`
+	// DeniedKinds are kinds that may never be applied, in the format of
+	// AllowedKinds. They take precedence over AllowedKinds.
+	DeniedKinds []string `yaml:"denied_kinds,omitempty" mapstructure:"denied_kinds" validate:"dive,required"`
+	// AllowedNamespaces are the namespaces objects may be applied in, as shell
+	// patterns, e.g. "cluster-*"
+	//nolint:lll
+	AllowedNamespaces []string `yaml:"allowed_namespaces,omitempty" mapstructure:"allowed_namespaces" validate:"dive,required"`
+	// ClusterScopedAllowed allows objects without a namespace
+	ClusterScopedAllowed bool `yaml:"cluster_scoped_allowed,omitempty" mapstructure:"cluster_scoped_allowed"`
+}
+
+// FeatureFlagsConfig configures the feature flags seen by the flag() CEL
+// function, e.g. in the enabled_if of resources and post actions
+type FeatureFlagsConfig struct {
+	Source FeatureFlagSource `yaml:"source" mapstructure:"source"`
+}
+
+// FeatureFlagSource locates the ConfigMap of the feature flags: each data key
+// is a flag, its value "true" or "false"
+type FeatureFlagSource struct {
+	ConfigMapName      string `yaml:"configmap_name" mapstructure:"configmap_name" validate:"required"`
+	ConfigMapNamespace string `yaml:"configmap_namespace" mapstructure:"configmap_namespace" validate:"required"`
+	// RefreshInterval is how often the ConfigMap is read again. Zero uses the default (30s).
+	RefreshInterval time.Duration `yaml:"refresh_interval,omitempty" mapstructure:"refresh_interval" validate:"gte=0"`
+}
+
+// PostBufferConfig configures the queue of the post action API calls buffered
+// by buffer_on_failure, replayed in the background until they succeed
+type PostBufferConfig struct {
+	// Path is the directory of the queue, on a volume surviving pod restarts
+	Path string `yaml:"path" mapstructure:"path" validate:"required"`
+	// ReplayInterval is how often the buffered calls are replayed. Zero uses the default (30s).
+	ReplayInterval time.Duration `yaml:"replay_interval,omitempty" mapstructure:"replay_interval" validate:"gte=0"`
 }
 
 // KubernetesConfig contains Kubernetes configuration
@@ -173,14 +707,35 @@ type KubernetesConfig struct {
 }
 
 // Parameter represents a parameter extraction configuration.
-// Parameters are extracted from external sources (event data, env vars) using Source.
+// Parameters are extracted from external sources (event data, env vars) using Source,
+// or from a Kubernetes object using ValueFrom.
 type Parameter struct {
-	Default     interface{} `yaml:"default,omitempty"`
-	Name        string      `yaml:"name" validate:"required"`
-	Source      string      `yaml:"source,omitempty" validate:"required"`
-	Type        string      `yaml:"type,omitempty"`
-	Description string      `yaml:"description,omitempty"`
-	Required    bool        `yaml:"required,omitempty"`
+	Default interface{} `yaml:"default,omitempty"`
+	// ValueFrom reads the value from a Kubernetes object (mutually exclusive with Source)
+	ValueFrom   *ParamValueFrom `yaml:"value_from,omitempty" validate:"omitempty"`
+	Name        string          `yaml:"name" validate:"required"`
+	Source      string          `yaml:"source,omitempty" validate:"required_without=ValueFrom,excluded_with=ValueFrom"`
+	Type        string          `yaml:"type,omitempty"`
+	Description string          `yaml:"description,omitempty"`
+	Required    bool            `yaml:"required,omitempty"`
+	// Sensitive marks the value as a secret that is redacted from logs and /statusz
+	Sensitive bool `yaml:"sensitive,omitempty"`
+}
+
+// ParamValueFrom is a param source read during param extraction
+type ParamValueFrom struct {
+	// K8sFieldRef reads a field of a Kubernetes object
+	K8sFieldRef *K8sFieldRef `yaml:"k8s_field_ref" validate:"required"`
+}
+
+// K8sFieldRef reads one field of a Kubernetes object through the kubernetes
+// transport client. Namespace and Name are Go templates over the vars and the
+// params declared before the param. An object is read once per event, however
+// many params refer to it.
+type K8sFieldRef struct {
+	ObjectRef `yaml:",inline"`
+	// FieldPath is the JSONPath or dot path of the field, e.g. "spec.clusterMetadata.infraID"
+	FieldPath string `yaml:"field_path" validate:"required"`
 }
 
 // Payload represents a dynamically built payload for post-processing.
@@ -201,6 +756,54 @@ type Payload struct {
 	// BuildRef references an external YAML file containing the build definition.
 	// Mutually exclusive with Build.
 	BuildRef string `yaml:"build_ref,omitempty" validate:"required_without=Build,excluded_with=Build"`
+	// BuildRefDigest pins the content of the BuildRef file, "sha256:<hex>". The
+	// loader fails when the file does not match it.
+	BuildRefDigest string `yaml:"build_ref_digest,omitempty" validate:"omitempty,digest"`
+	// Structured stores the built payload in params as a map instead of a JSON string.
+	// A post action body that only references it, e.g. "{{ .clusterStatusPayload }}",
+	// is marshaled once without a template round trip; other templates need toJson.
+	Structured bool `yaml:"structured,omitempty"`
+	// Canonical stores the payload as canonical JSON: keys sorted, numbers in one
+	// form (2.0 is 2) and no insignificant whitespace, so payloads built from the
+	// same values are byte-identical. Mutually exclusive with Structured.
+	Canonical bool `yaml:"canonical,omitempty" validate:"excluded_with=Structured"`
+	// Conditions builds a HyperFleet status conditions array into the built payload
+	Conditions *StatusConditions `yaml:"conditions,omitempty" validate:"omitempty"`
+}
+
+// DefaultStatusConditionsKey is the payload key of the built conditions array
+const DefaultStatusConditionsKey = "conditions"
+
+// StatusConditions declares the HyperFleet status conditions of a payload.
+// The last_transition_time of a condition only changes when its status differs
+// from the one previously reported; otherwise the previous time is kept.
+type StatusConditions struct {
+	// Key is the payload key the conditions array is set under (default "conditions")
+	Key string `yaml:"key,omitempty"`
+	// Previous is the field path of the conditions previously reported, e.g. of a
+	// precondition capture of the current HyperFleet record. Empty or unset means
+	// every condition transitions now.
+	Previous string `yaml:"previous,omitempty"`
+	// Items are the conditions, in the order of the built array
+	Items []StatusCondition `yaml:"items" validate:"required,min=1,dive"`
+}
+
+// EffectiveKey returns the payload key of the conditions array
+func (c *StatusConditions) EffectiveKey() string {
+	if c.Key == "" {
+		return DefaultStatusConditionsKey
+	}
+	return c.Key
+}
+
+// StatusCondition is one condition of a StatusConditions block
+type StatusCondition struct {
+	Type string `yaml:"type" validate:"required"`
+	// Status is a CEL expression returning "True", "False", "Unknown" or a bool
+	Status string `yaml:"status" validate:"required"`
+	// Reason and Message are Go templates over params
+	Reason  string `yaml:"reason,omitempty"`
+	Message string `yaml:"message,omitempty"`
 }
 
 // Validate checks that exactly one of Build or BuildRef is set.
@@ -231,19 +834,119 @@ type Precondition struct {
 	ActionBase `yaml:",inline"`
 	Expression string         `yaml:"expression,omitempty" validate:"required_without_all=ActionBase.APICall Conditions"`
 	Capture    []CaptureField `yaml:"capture,omitempty" validate:"dive"`
+	// CaptureResponseAs stores the whole parsed response body of the API call
+	// as a param of this name, for later steps to navigate
+	CaptureResponseAs string `yaml:"capture_response_as,omitempty"`
 	//nolint:lll
 	Conditions []Condition `yaml:"conditions,omitempty" validate:"dive,required_without_all=ActionBase.APICall Expression"`
+	// RetryAfter asks the broker to redeliver the event after this delay when the
+	// precondition is not met, instead of acknowledging it. Use it for preconditions
+	// that wait for upstream state to catch up. Zero acknowledges the event.
+	RetryAfter time.Duration `yaml:"retry_after,omitempty" validate:"gte=0"`
+	// ReasonLabel classifies the executions where the precondition is not met in
+	// the hyperfleet_adapter_preconditions_not_met_reasons_total metric, e.g.
+	// cluster_not_ready
+	ReasonLabel string `yaml:"reason_label,omitempty"`
+	// DependsOn names the preconditions that run before this one, in addition to
+	// those producing a capture or response its templates, expression or
+	// conditions reference, which are inferred
+	DependsOn []string `yaml:"depends_on,omitempty" validate:"unique,dive,required"`
+	// Expect asserts the shape of the API call response before the captures
+	// run, so upstream contract drift fails loudly instead of capturing nothing
+	Expect *ResponseExpectation `yaml:"expect,omitempty" validate:"omitempty"`
+}
+
+// JSON types of response assertions
+const (
+	ResponseTypeString  = "string"
+	ResponseTypeNumber  = "number"
+	ResponseTypeBoolean = "boolean"
+	ResponseTypeObject  = "object"
+	ResponseTypeArray   = "array"
+)
+
+// ResponseExpectation asserts the shape of the parsed response of a
+// precondition API call, before its transform. Every violated assertion is
+// reported, not only the first.
+type ResponseExpectation struct {
+	Assertions []ResponseAssertion `yaml:"assertions" validate:"required,min=1,dive"`
+	// WarnOnly logs the violations and counts them in
+	// hyperfleet_adapter_api_contract_violations_total instead of failing the
+	// precondition, to monitor drift without breaking processing
+	WarnOnly bool `yaml:"warn_only,omitempty"`
+}
+
+// ResponseAssertion asserts the values at a path of a response
+type ResponseAssertion struct {
+	// Path is a JSONPath or dot notation path, e.g. "spec.region" or
+	// "items[0].id". Each value of a path matching several, e.g.
+	// "{.items[*].id}", is asserted.
+	Path string `yaml:"path" validate:"required"`
+	// Type is the JSON type of the values: string, number, boolean, object or array
+	Type string `yaml:"type,omitempty" validate:"omitempty,oneof=string number boolean object array"`
+	// Required fails the assertion when the path matches no value, or null
+	Required bool `yaml:"required,omitempty"`
 }
 
 // APICall represents an API call configuration
 type APICall struct {
-	Method        string   `yaml:"method" validate:"required,oneof=GET POST PUT PATCH DELETE"`
-	URL           string   `yaml:"url" validate:"required"`
+	Method string `yaml:"method" validate:"required,oneof=GET POST PUT PATCH DELETE"`
+	URL    string `yaml:"url" validate:"required"`
+	// Target selects the HyperFleet API target (clients.hyperfleet_api.targets) the
+	// call is sent to. Go template over params; empty means the default target.
+	Target        string   `yaml:"target,omitempty"`
 	Timeout       string   `yaml:"timeout,omitempty"`
-	RetryBackoff  string   `yaml:"retry_backoff,omitempty"`
+	RetryBackoff  string   `yaml:"retry_backoff,omitempty" jsonschema:"enum=exponential linear constant"`
 	Body          string   `yaml:"body,omitempty"`
 	Headers       []Header `yaml:"headers,omitempty"`
 	RetryAttempts int      `yaml:"retry_attempts,omitempty"`
+	// BodyFrom sends the post payload of this name as the JSON body, marshaled
+	// from its built content instead of embedded in a body template
+	BodyFrom string `yaml:"body_from,omitempty" validate:"excluded_with=Body"`
+	// BodyMerge sends the deep merge of these post payloads as the JSON body,
+	// in order: a later payload wins for a key set by several
+	BodyMerge []string `yaml:"body_merge,omitempty" validate:"excluded_with=Body,excluded_with=BodyFrom,dive,required"`
+	// BodyType is how the body is encoded: "json" (default) sends body as is,
+	// "form" sends form_fields as application/x-www-form-urlencoded and
+	// "multipart" sends parts as multipart/form-data
+	BodyType string `yaml:"body_type,omitempty" validate:"omitempty,oneof=json form multipart"`
+	// FormFields are the fields of a form body, in order
+	FormFields []FormField `yaml:"form_fields,omitempty" validate:"dive"`
+	// Parts are the parts of a multipart body, in order
+	Parts []MultipartPart `yaml:"parts,omitempty" validate:"dive"`
+	// Transform is a CEL expression over the parsed response, the response
+	// variable, whose result replaces the response for the captures and
+	// conditions of a precondition, e.g. response.data
+	Transform string `yaml:"transform,omitempty"`
+}
+
+// API call body types
+const (
+	BodyTypeJSON      = "json"
+	BodyTypeForm      = "form"
+	BodyTypeMultipart = "multipart"
+)
+
+// FormField is a field of a form-encoded API call body
+type FormField struct {
+	Name string `yaml:"name" validate:"required"`
+	// Value is a Go template over params
+	Value string `yaml:"value"`
+	// Sensitive redacts the rendered value from logs
+	Sensitive bool `yaml:"sensitive,omitempty"`
+}
+
+// MultipartPart is a part of a multipart API call body
+type MultipartPart struct {
+	Name string `yaml:"name" validate:"required"`
+	// Filename makes the part a file upload
+	Filename string `yaml:"filename,omitempty"`
+	// ContentType of the part, application/octet-stream by default for files
+	ContentType string `yaml:"content_type,omitempty"`
+	// Content is a Go template over params
+	Content string `yaml:"content"`
+	// Sensitive redacts the rendered content from logs
+	Sensitive bool `yaml:"sensitive,omitempty"`
 }
 
 // Header represents an HTTP header
@@ -254,15 +957,35 @@ type Header struct {
 
 // CaptureField represents a field capture configuration from API response.
 //
-// Supports two modes (mutually exclusive):
+// Supports three modes (mutually exclusive):
 //   - Field: JSONPath expression for simple field extraction (e.g., "{.items[0].name}")
 //   - Expression: CEL expression for complex transformations
 //     (e.g., "response.items.filter(i, i.adapter == 'x')")
+//   - Header: response header, with Source "header" (e.g., "X-Total-Count")
+//
+// Field and Expression are validated by validateCaptureField, since a header
+// capture has neither.
 type CaptureField struct {
-	Name               string `yaml:"name" validate:"required"`
-	FieldExpressionDef `yaml:",inline"`
+	// Default is captured when the response has no value to capture
+	Default            interface{} `yaml:"default,omitempty"`
+	Name               string      `yaml:"name" validate:"required"`
+	FieldExpressionDef `yaml:",inline" validate:"-"`
+	// Source is what the value is captured from: "body" (default) or "header"
+	Source string `yaml:"source,omitempty" validate:"omitempty,oneof=body header"`
+	// Header is the name of the response header to capture (case-insensitive).
+	// A header with several values is captured as a list.
+	Header string `yaml:"header,omitempty"`
+	// Required fails the precondition when the response has no value to capture;
+	// otherwise the capture is skipped
+	Required bool `yaml:"required,omitempty" validate:"excluded_with=Default"`
 }
 
+// Capture sources
+const (
+	CaptureSourceBody   = "body"
+	CaptureSourceHeader = "header"
+)
+
 // Condition represents a structured condition
 type Condition struct {
 	// Populated by UnmarshalYAML from "value" or "values"
@@ -317,6 +1040,28 @@ type TransportConfig struct {
 type MaestroTransportConfig struct {
 	// TargetCluster is the name of the target cluster (consumer) for ManifestWork delivery
 	TargetCluster string `yaml:"target_cluster" validate:"required"`
+	// ManifestWorkMetadata adds labels and annotations to the metadata of every
+	// ManifestWork the resource publishes
+	ManifestWorkMetadata *ManifestWorkMetadata `yaml:"manifest_work_metadata,omitempty"`
+}
+
+// ManifestWork metadata precedences
+const (
+	// MetadataPrecedenceTransport overwrites the labels and annotations of the template
+	MetadataPrecedenceTransport = "transport"
+	// MetadataPrecedenceTemplate keeps the labels and annotations of the template
+	MetadataPrecedenceTemplate = "template"
+)
+
+// ManifestWorkMetadata holds labels and annotations merged into the metadata of
+// a ManifestWork after its template's own. Values are templates rendered with params;
+// keys rendering to an empty value are left out.
+type ManifestWorkMetadata struct {
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	// Precedence decides which value a key set by both the template and this
+	// metadata gets: "transport" (default) or "template"
+	Precedence string `yaml:"precedence,omitempty" validate:"omitempty,oneof=transport template"`
 }
 
 // Resource represents a resource configuration.
@@ -333,6 +1078,16 @@ type Resource struct {
 	// inside a ManifestWork's workload.
 	NestedDiscoveries []NestedDiscovery `yaml:"nested_discoveries,omitempty" validate:"dive"`
 	RecreateOnChange  bool              `yaml:"recreate_on_change,omitempty"`
+	// ManifestRef references an external YAML file holding the manifest, relative
+	// to the task config. A file of several documents is loaded as a v1 List of them.
+	// Mutually exclusive with Manifest, which the loader replaces with the content.
+	ManifestRef string `yaml:"manifest_ref,omitempty" validate:"excluded_with=Manifest"`
+	// ManifestRefDigest pins the content of the manifest_ref or manifest.ref file,
+	// "sha256:<hex>". The loader fails when the file does not match it.
+	ManifestRefDigest string `yaml:"manifest_ref_digest,omitempty" validate:"omitempty,digest"`
+	// EnabledIf is a CEL expression gating the resource, e.g. flag("new-bundle"):
+	// the resource is not applied when it is false. Empty always applies it.
+	EnabledIf string `yaml:"enabled_if,omitempty"`
 }
 
 // NestedDiscovery defines a named discovery for a sub-resource within the parent manifest.
@@ -347,6 +1102,10 @@ type DiscoveryConfig struct {
 	Namespace   string          `yaml:"namespace,omitempty"`
 	//nolint:lll
 	ByName string `yaml:"by_name,omitempty" validate:"required_without=BySelectors,excluded_with=BySelectors"`
+	// Item is the index of the item looked up, with the item's kind, when the
+	// manifest is a v1 List; the first item when unset. Required when the items
+	// are of several kinds.
+	Item *int `yaml:"item,omitempty" validate:"omitempty,gte=0"`
 }
 
 // SelectorConfig represents label selector configuration
@@ -362,13 +1121,107 @@ type PostConfig struct {
 
 // PostAction represents a post-processing action
 type PostAction struct {
-	ActionBase `yaml:",inline"`
+	K8sPatch *K8sPatchAction `yaml:"k8s_patch,omitempty" validate:"omitempty"`
+	// Webhook delivers a post payload to an arbitrary HTTP receiver
+	Webhook *WebhookAction `yaml:"webhook,omitempty" validate:"omitempty"`
+	// SkipIfUnchanged skips the API call when its rendered body is the same as
+	// the last body sent successfully for the same key
+	SkipIfUnchanged *SkipIfUnchanged `yaml:"skip_if_unchanged,omitempty" validate:"omitempty"`
+	ActionBase      `yaml:",inline"`
+	// ContinueOnError runs the remaining post actions when this action fails,
+	// instead of stopping and failing the execution
+	ContinueOnError bool `yaml:"continue_on_error,omitempty"`
+	// EnabledIf is a CEL expression gating the action, e.g. flag("new-status-schema"):
+	// the action is skipped when it is false. Empty always runs it.
+	EnabledIf string `yaml:"enabled_if,omitempty"`
+	// BufferOnFailure buffers the API call when it fails after its retries,
+	// to be replayed in the background, instead of failing the execution
+	BufferOnFailure *BufferOnFailure `yaml:"buffer_on_failure,omitempty" validate:"omitempty"`
+}
+
+// BufferOnFailure configures the buffering of the failed API call of a post
+// action. Only calls that got no response or a retryable status are buffered.
+type BufferOnFailure struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxAge is how long a buffered call is replayed before it is dropped. Zero uses the default (1h).
+	MaxAge time.Duration `yaml:"max_age,omitempty" validate:"gte=0"`
+	// MaxEntries bounds the buffered calls of the action. Zero uses the default (100).
+	MaxEntries int `yaml:"max_entries,omitempty" validate:"gte=0"`
+}
+
+// SkipIfUnchanged configures change detection on the API call of a post action.
+// The body is compared by the hash of its canonical JSON, so key order does not matter.
+type SkipIfUnchanged struct {
+	// Key is a Go template identifying what the body describes, e.g. "{{ .clusterId }}"
+	Key string `yaml:"key" validate:"required"`
+	// TTL is how long a sent body suppresses identical bodies. Zero uses the default (10m).
+	TTL time.Duration `yaml:"ttl,omitempty" validate:"gte=0"`
+}
+
+// Patch types of a k8s_patch post action
+const (
+	PatchTypeMerge     = "merge"
+	PatchTypeJSON      = "json"
+	PatchTypeStrategic = "strategic"
+)
+
+// SubresourceStatus is the status subresource of a k8s_patch post action
+const SubresourceStatus = "status"
+
+// K8sPatchAction patches a Kubernetes object through the kubernetes transport
+// client, e.g. an annotation or a status condition of the cluster CR.
+// Namespace, Name and Body are Go templates. Body is a JSON or YAML patch
+// document, e.g. "{{ .statusPatchPayload }}" for a post payload.
+type K8sPatchAction struct {
+	APIVersion string `yaml:"api_version" validate:"required"`
+	Kind       string `yaml:"kind" validate:"required"`
+	Namespace  string `yaml:"namespace,omitempty"`
+	Name       string `yaml:"name" validate:"required"`
+	// PatchType is merge (default), json or strategic
+	PatchType string `yaml:"patch_type,omitempty" validate:"omitempty,oneof=merge json strategic"`
+	// Subresource patches the status subresource instead of the object when set to status
+	Subresource string `yaml:"subresource,omitempty" validate:"omitempty,oneof=status"`
+	Body        string `yaml:"body" validate:"required"`
+}
+
+// WebhookAction delivers a post payload to an HTTP receiver, e.g. a Slack
+// incoming webhook, through the clients.webhook client. URL and header values
+// are Go templates. The payload is sent as built, signed with HMAC-SHA256 when
+// signing is set.
+type WebhookAction struct {
+	// Signing signs the delivered body so the receiver can verify its origin
+	Signing *WebhookSigning `yaml:"signing,omitempty" validate:"omitempty"`
+	URL     string          `yaml:"url" validate:"required"`
+	// Payload is the name of the post payload delivered as the body
+	Payload string   `yaml:"payload" validate:"required"`
+	Headers []Header `yaml:"headers,omitempty"`
+	// Timeout bounds each delivery attempt, zero uses clients.webhook.timeout
+	Timeout time.Duration `yaml:"timeout,omitempty" validate:"gte=0"`
+	// RetryAttempts is the number of delivery attempts, zero uses clients.webhook.retry_attempts
+	RetryAttempts int `yaml:"retry_attempts,omitempty" validate:"gte=0"`
+}
+
+// WebhookSigning configures the HMAC-SHA256 signature of a webhook body
+type WebhookSigning struct {
+	// Secret is a Go template rendering the shared secret, e.g. "{{ .webhookSecret }}"
+	// for a sensitive param sourced from a Kubernetes Secret
+	Secret string `yaml:"secret" validate:"required"`
+	// Header is the header carrying the signature (default X-Hyperfleet-Signature-256)
+	Header string `yaml:"header,omitempty"`
 }
 
 // LogAction represents a logging action that can be configured in the adapter config
 type LogAction struct {
-	Message string `yaml:"message"`
-	Level   string `yaml:"level,omitempty"` // debug, info, warning, error (default: info)
+	// Fields are structured fields added to the log line. Like payload build
+	// values, a value is a Go template string or a {field|expression, default}
+	// value definition evaluated against the CEL variables.
+	Fields  map[string]interface{} `yaml:"fields,omitempty"`
+	Message string                 `yaml:"message"`
+	// Level is debug, info (default), warn or error; warning is accepted for warn
+	Level string `yaml:"level,omitempty" validate:"omitempty,oneof=debug info warn warning error"`
+	// SampleEvery logs only the first of every SampleEvery executions of the
+	// action. Zero and one log every execution.
+	SampleEvery int `yaml:"sample_every,omitempty" validate:"gte=0"`
 }
 
 // ManifestRef represents a manifest reference
@@ -439,10 +1292,19 @@ func (ve *ValidationErrors) HasErrors() bool {
 // Contains infrastructure settings that can be overridden via environment variables
 // and CLI flags using Viper.
 type AdapterConfig struct {
-	Adapter     AdapterInfo   `yaml:"adapter" mapstructure:"adapter"`
-	Log         LogConfig     `yaml:"log,omitempty" mapstructure:"log"`
-	Clients     ClientsConfig `yaml:"clients" mapstructure:"clients"`
-	DebugConfig bool          `yaml:"debug_config,omitempty" mapstructure:"debug_config"`
+	Adapter     AdapterInfo       `yaml:"adapter" mapstructure:"adapter"`
+	Log         LogConfig         `yaml:"log,omitempty" mapstructure:"log"`
+	Health      HealthConfig      `yaml:"health,omitempty" mapstructure:"health"`
+	Clients     ClientsConfig     `yaml:"clients" mapstructure:"clients"`
+	Audit       *AuditConfig      `yaml:"audit,omitempty" mapstructure:"audit"`
+	Journal     *JournalConfig    `yaml:"journal,omitempty" mapstructure:"journal"`
+	Correlation CorrelationConfig `yaml:"correlation,omitempty" mapstructure:"correlation"`
+	Metrics     MetricsConfig     `yaml:"metrics,omitempty" mapstructure:"metrics"`
+	Policy      *ResourcePolicy   `yaml:"policy,omitempty" mapstructure:"policy"`
+	DebugConfig bool              `yaml:"debug_config,omitempty" mapstructure:"debug_config"`
+	//nolint:lll
+	FeatureFlags *FeatureFlagsConfig `yaml:"feature_flags,omitempty" mapstructure:"feature_flags" validate:"omitempty"`
+	PostBuffer   *PostBufferConfig   `yaml:"post_buffer,omitempty" mapstructure:"post_buffer" validate:"omitempty"`
 }
 
 // ClientsConfig contains configuration for all external clients
@@ -451,6 +1313,8 @@ type ClientsConfig struct {
 	Broker        BrokerConfig         `yaml:"broker,omitempty" mapstructure:"broker"`
 	Kubernetes    KubernetesConfig     `yaml:"kubernetes" mapstructure:"kubernetes"`
 	HyperfleetAPI HyperfleetAPIConfig  `yaml:"hyperfleet_api" mapstructure:"hyperfleet_api"`
+	// Webhook configures the client of webhook post actions
+	Webhook WebhookClientConfig `yaml:"webhook,omitempty" mapstructure:"webhook"`
 }
 
 // MaestroClientConfig contains Maestro client configuration
@@ -490,11 +1354,438 @@ type KeepaliveConfig struct {
 }
 
 // AdapterTaskConfig represents the business logic configuration.
-// Contains params, preconditions, resources, and post-processing actions.
+// Contains event schemas, params, preconditions, resources, and post-processing actions.
 // This config is loaded from YAML without environment variable overrides.
 type AdapterTaskConfig struct {
 	Post          *PostConfig    `yaml:"post,omitempty" validate:"omitempty"`
+	EventSchemas  []EventSchema  `yaml:"event_schemas,omitempty" validate:"unique=EventType,dive"`
 	Params        []Parameter    `yaml:"params,omitempty" validate:"dive"`
 	Preconditions []Precondition `yaml:"preconditions,omitempty" validate:"dive"`
 	Resources     []Resource     `yaml:"resources,omitempty" validate:"unique=Name,dive"`
+	// Vars are constant params: literal values (scalars, lists, maps) by name. ${NAME}
+	// references to environment variables in their strings are substituted at load
+	// time; they are not templates and do not vary per event
+	Vars map[string]interface{} `yaml:"vars,omitempty"`
+	// RequiredParams are param names or event.* paths that must be present and
+	// non-empty after param extraction; all missing ones are reported in one error
+	RequiredParams []string `yaml:"required_params,omitempty" validate:"unique,dive,required"`
+	// ReportParamFailures runs the post actions after a param extraction failure,
+	// so the failure can be reported; preconditions and resources are skipped
+	ReportParamFailures bool `yaml:"report_param_failures,omitempty"`
+	// PreconditionErrorPolicy controls what happens when a precondition fails to
+	// execute: "failFast" (default) stops at the first failure, "collectAll" runs the
+	// remaining preconditions and reports all failures. An unmet precondition always
+	// stops the phase.
+	//nolint:lll
+	PreconditionErrorPolicy string `yaml:"precondition_error_policy,omitempty" validate:"omitempty,oneof=failFast collectAll"`
+	// PreconditionConcurrency is the number of preconditions that run at once when
+	// none depends on another. Zero and one run them one after the other.
+	PreconditionConcurrency int `yaml:"precondition_concurrency,omitempty" validate:"gte=0"`
+	// ExecutionFence lets at most one execution per rendered key run at a time,
+	// e.g. per cluster, so a burst of events for one cluster is not processed in parallel
+	ExecutionFence *ExecutionFence `yaml:"execution_fence,omitempty" validate:"omitempty"`
+	// NotMetBackoff asks for increasing redelivery delays, and logs less, for the
+	// keys whose preconditions stay unmet execution after execution
+	NotMetBackoff *NotMetBackoff `yaml:"not_met_backoff,omitempty" validate:"omitempty"`
+	// Workflows are named sets of preconditions, resources and post actions, each
+	// run for the events its matcher selects. The top-level preconditions,
+	// resources and post form the default workflow when no workflow is the default.
+	Workflows []Workflow `yaml:"workflows,omitempty" validate:"unique=Name,dive"`
+	// EventFilter acknowledges the events it does not allow without executing them
+	EventFilter *EventFilter `yaml:"event_filter,omitempty" validate:"omitempty"`
+	// PlainTextDataKey wraps text/plain event data in an object with the text under
+	// this key, e.g. {"raw": "<data>"}, so params can read it as event.<key>.
+	// Empty leaves text/plain data unsupported.
+	PlainTextDataKey string `yaml:"plain_text_data_key,omitempty"`
+	// Schedule restricts resource application to maintenance windows
+	Schedule *Schedule `yaml:"schedule,omitempty" validate:"omitempty"`
+	// Limits bounds the memory retained by an execution
+	Limits *Limits `yaml:"limits,omitempty" validate:"omitempty"`
+	// Finalizer is a finalizer the adapter keeps on a source object until the
+	// teardown workflow has applied its resources
+	Finalizer *Finalizer `yaml:"finalizer,omitempty" validate:"omitempty"`
+	// Idempotency skips the resources of the events whose generation is not newer
+	// than the last one processed for their key, e.g. redeliveries arriving out of order
+	Idempotency *Idempotency `yaml:"idempotency,omitempty" validate:"omitempty"`
+	// SubjectPatterns are matched in order against the CloudEvent subject during
+	// param extraction, e.g. "tenants/{tenant}/clusters/{clusterId}"; the named
+	// segments of the first match become params. An event whose subject matches
+	// none fails param extraction.
+	SubjectPatterns []string `yaml:"subject_patterns,omitempty" validate:"omitempty,dive,required"`
+	// SubjectOptional lets the events whose subject matches no pattern through,
+	// without the segment params
+	SubjectOptional bool `yaml:"subject_optional,omitempty"`
+	// Maps are named lookup tables, read by the lookup(map, key) function of
+	// CEL expressions and templates, e.g. to translate the phases of the
+	// HyperFleet API into the statuses of another system
+	Maps []LookupMap `yaml:"maps,omitempty" validate:"unique=Name,dive"`
+	// KnownExternalParams are the params, or dotted param prefixes, templates
+	// and CEL expressions may reference although the config does not produce
+	// them, e.g. ones injected by an embedding program. They silence the
+	// unknown reference warnings of validation.
+	KnownExternalParams []string `yaml:"known_external_params,omitempty" validate:"omitempty,dive,required"`
 }
+
+// Finalizer is a finalizer the adapter keeps on a source object, e.g. the
+// cluster CR, so deleting the object waits until the adapter has torn down
+// what it applied for it. Every workflow but the teardown one adds it before
+// applying its resources, and the teardown workflow removes it once its
+// resources are applied.
+type Finalizer struct {
+	// Name is the finalizer, e.g. "hyperfleet.io/adapter-cleanup"
+	Name string `yaml:"name" validate:"required"`
+	// On is the object holding the finalizer
+	On ObjectRef `yaml:"on"`
+	// TeardownWorkflow is the name of the workflow removing the finalizer
+	TeardownWorkflow string `yaml:"teardown_workflow" validate:"required"`
+}
+
+// Idempotency store types
+const (
+	IdempotencyStoreMemory    = "memory"
+	IdempotencyStoreConfigMap = "configmap"
+)
+
+// Idempotency policies: what an execution whose generation ties the last
+// processed one, or has none, does
+const (
+	IdempotencyProcess = "process"
+	IdempotencySkip    = "skip"
+)
+
+// Idempotency post action modes: what the post actions of an execution with a
+// stale generation do
+const (
+	// IdempotencyPostRun runs the post actions, which see the skip reason
+	// StaleGeneration in adapter.skipReason
+	IdempotencyPostRun = "run"
+	// IdempotencyPostSkip skips the post actions
+	IdempotencyPostSkip = "skip"
+)
+
+// Idempotency compares the generation of each event with the last generation
+// successfully processed for its key. The resources of an event whose
+// generation is older, or as old under the skip tie policy, are skipped.
+type Idempotency struct {
+	// GenerationParam is the param or capture holding the generation of the
+	// event, an integer
+	GenerationParam string `yaml:"generation_param" validate:"required"`
+	// KeyParam is the param or capture naming what the event is about, e.g. "clusterId"
+	KeyParam string `yaml:"key_param" validate:"required"`
+	// Store selects the backend of the processed generations: "memory"
+	// (default, lost on restart) or "configmap" (persisted)
+	Store string `yaml:"store,omitempty" validate:"omitempty,oneof=memory configmap"`
+	// ConfigMapName and ConfigMapNamespace locate the ConfigMap of the "configmap" store
+	ConfigMapName      string `yaml:"configmap_name,omitempty"`
+	ConfigMapNamespace string `yaml:"configmap_namespace,omitempty"`
+	// OnTie is what an event whose generation equals the last processed one
+	// does: "skip" (default) or "process"
+	OnTie string `yaml:"on_tie,omitempty" validate:"omitempty,oneof=process skip"`
+	// OnMissing is what an event without a generation or key does: "process"
+	// (default) or "skip"
+	OnMissing string `yaml:"on_missing,omitempty" validate:"omitempty,oneof=process skip"`
+	// PostActions is what the post actions of a stale event do: "run" (default)
+	// or "skip"
+	PostActions string `yaml:"post_actions,omitempty" validate:"omitempty,oneof=run skip"`
+	// MaxKeys bounds the number of keys remembered. Zero uses the default (10000).
+	MaxKeys int `yaml:"max_keys,omitempty" validate:"gte=0"`
+}
+
+// EffectiveStore returns the store type of the idempotency guard
+func (i *Idempotency) EffectiveStore() string {
+	if i.Store == "" {
+		return IdempotencyStoreMemory
+	}
+	return i.Store
+}
+
+// EffectiveOnTie returns the tie policy of the idempotency guard
+func (i *Idempotency) EffectiveOnTie() string {
+	if i.OnTie == "" {
+		return IdempotencySkip
+	}
+	return i.OnTie
+}
+
+// EffectiveOnMissing returns the missing generation policy of the idempotency guard
+func (i *Idempotency) EffectiveOnMissing() string {
+	if i.OnMissing == "" {
+		return IdempotencyProcess
+	}
+	return i.OnMissing
+}
+
+// EffectivePostActions returns the post action mode of the idempotency guard
+func (i *Idempotency) EffectivePostActions() string {
+	if i.PostActions == "" {
+		return IdempotencyPostRun
+	}
+	return i.PostActions
+}
+
+// Lookup map missing key policies: what lookup() returns for a key the map
+// does not have
+const (
+	// LookupOnMissingError fails the expression or template calling lookup()
+	LookupOnMissingError = "error"
+	// LookupOnMissingDefault returns the default of the map
+	LookupOnMissingDefault = "default"
+	// LookupOnMissingEmpty returns an empty string, or an empty object for a
+	// map whose values are objects
+	LookupOnMissingEmpty = "empty"
+)
+
+// LookupMap is a named lookup table of string keys and string or object
+// values. Its entries are inline or read from a ConfigMap when the adapter
+// starts.
+type LookupMap struct {
+	// Name is the name lookup() is called with
+	Name string `yaml:"name" validate:"required"`
+	// Entries are the values by key, all strings or all objects
+	Entries map[string]interface{} `yaml:"entries,omitempty"`
+	// ConfigMapName and ConfigMapNamespace locate the ConfigMap the entries are
+	// read from instead: each data key is a key, its value a string or a JSON object
+	ConfigMapName      string `yaml:"configmap_name,omitempty"`
+	ConfigMapNamespace string `yaml:"configmap_namespace,omitempty"`
+	// OnMissing is what a missing key returns: "error" (default), "default"
+	// or "empty"
+	OnMissing string `yaml:"on_missing,omitempty" validate:"omitempty,oneof=error default empty"`
+	// Default is the value of the missing keys under the "default" policy
+	Default interface{} `yaml:"default,omitempty"`
+}
+
+// FromConfigMap reports whether the entries of the map are read from a ConfigMap
+func (m *LookupMap) FromConfigMap() bool {
+	return m.ConfigMapName != ""
+}
+
+// EffectiveOnMissing returns the missing key policy of the map
+func (m *LookupMap) EffectiveOnMissing() string {
+	if m.OnMissing == "" {
+		return LookupOnMissingError
+	}
+	return m.OnMissing
+}
+
+// CheckLookupValues checks that the values of a lookup map, and its default
+// when not nil, are all strings or all objects
+func CheckLookupValues(entries map[string]interface{}, defaultValue interface{}) error {
+	kind := ""
+	check := func(what string, value interface{}) error {
+		var valueKind string
+		switch value.(type) {
+		case string:
+			valueKind = "string"
+		case map[string]interface{}:
+			valueKind = "object"
+		default:
+			return fmt.Errorf("%s is a %T, not a string or an object", what, value)
+		}
+		if kind != "" && valueKind != kind {
+			return fmt.Errorf("%s is a %s, unlike the other values, which are %ss", what, valueKind, kind)
+		}
+		kind = valueKind
+		return nil
+	}
+	for _, key := range slices.Sorted(maps.Keys(entries)) {
+		if err := check(fmt.Sprintf("value of key %q", key), entries[key]); err != nil {
+			return err
+		}
+	}
+	if defaultValue != nil {
+		return check("default", defaultValue)
+	}
+	return nil
+}
+
+// ObjectRef references a Kubernetes object. Namespace and Name are Go
+// templates; Namespace is empty for cluster-scoped objects.
+type ObjectRef struct {
+	APIVersion string `yaml:"api_version" validate:"required"`
+	Kind       string `yaml:"kind" validate:"required"`
+	Namespace  string `yaml:"namespace,omitempty"`
+	Name       string `yaml:"name" validate:"required"`
+}
+
+// Defaults of the limits of an execution
+const (
+	DefaultMaxEvaluationRecords = 1000
+	DefaultMaxRetainedBytes     = 1024 * 1024
+)
+
+// Limits bounds the memory retained by an execution, which its result holds
+// until it is logged, audited or serialized, and the time it waits on retries
+type Limits struct {
+	// MaxEvaluationRecords caps the condition evaluations recorded by an
+	// execution; the oldest are dropped first (default 1000)
+	MaxEvaluationRecords int `yaml:"max_evaluation_records,omitempty" validate:"gte=0"`
+	// MaxRetainedBytes caps the total size of the API response bodies retained
+	// by an execution across its calls, each also cut to the
+	// clients.hyperfleet_api.max_retained_response_bytes limit (default 1 MiB)
+	MaxRetainedBytes int `yaml:"max_retained_bytes,omitempty" validate:"gte=0"`
+	// KeepRawResponses keeps the parsed precondition API responses in the
+	// params of the result. By default they are dropped once the post actions
+	// ran: captures and the retained response bodies summarize them.
+	KeepRawResponses bool `yaml:"keep_raw_responses,omitempty"`
+	// RetryBudget caps the time the retry loops of an execution wait between
+	// attempts, all steps together. Zero is unlimited.
+	RetryBudget time.Duration `yaml:"retry_budget,omitempty" validate:"gte=0"`
+}
+
+// EvaluationRecordLimit returns MaxEvaluationRecords, or the default when unset
+func (l *Limits) EvaluationRecordLimit() int {
+	if l == nil || l.MaxEvaluationRecords == 0 {
+		return DefaultMaxEvaluationRecords
+	}
+	return l.MaxEvaluationRecords
+}
+
+// RetainedBytesLimit returns MaxRetainedBytes, or the default when unset
+func (l *Limits) RetainedBytesLimit() int {
+	if l == nil || l.MaxRetainedBytes == 0 {
+		return DefaultMaxRetainedBytes
+	}
+	return l.MaxRetainedBytes
+}
+
+// RetryBudgetLimit returns RetryBudget, zero (unlimited) when unset
+func (l *Limits) RetryBudgetLimit() time.Duration {
+	if l == nil {
+		return 0
+	}
+	return l.RetryBudget
+}
+
+// KeepsRawResponses reports whether the result keeps the parsed precondition responses
+func (l *Limits) KeepsRawResponses() bool {
+	return l != nil && l.KeepRawResponses
+}
+
+// Schedule policies: what an execution outside every maintenance window does
+const (
+	// SchedulePolicyDefer skips the preconditions and resources, like an unmet
+	// precondition, and asks for the event to be redelivered when a window opens
+	SchedulePolicyDefer = "defer"
+	// SchedulePolicySkipResources runs the preconditions and post actions and
+	// skips the resources
+	SchedulePolicySkipResources = "skipResources"
+)
+
+// Schedule restricts resource application to maintenance windows, opened at the
+// times of cron expressions on the wall clock of a time zone
+type Schedule struct {
+	// Timezone is the IANA time zone of the window start times, e.g.
+	// "Europe/Berlin" (default UTC)
+	Timezone string `yaml:"timezone,omitempty"`
+	// Policy is what an execution outside every window does: "defer" (default)
+	// or "skipResources"
+	Policy  string           `yaml:"policy,omitempty" validate:"omitempty,oneof=defer skipResources"`
+	Windows []ScheduleWindow `yaml:"windows" validate:"required,min=1,dive"`
+}
+
+// EffectivePolicy returns the policy of the schedule
+func (s *Schedule) EffectivePolicy() string {
+	if s.Policy == "" {
+		return SchedulePolicyDefer
+	}
+	return s.Policy
+}
+
+// Compile parses the time zone and the window start times of the schedule
+func (s *Schedule) Compile() (*schedule.Schedule, error) {
+	loc := time.UTC
+	if s.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(s.Timezone); err != nil {
+			return nil, fmt.Errorf("unknown time zone %q", s.Timezone)
+		}
+	}
+	windows := make([]schedule.Window, len(s.Windows))
+	for i, window := range s.Windows {
+		start, err := schedule.ParseCron(window.Start)
+		if err != nil {
+			return nil, fmt.Errorf("window %d: %w", i, err)
+		}
+		windows[i] = schedule.Window{Start: start, Duration: window.Duration}
+	}
+	return schedule.New(loc, windows...)
+}
+
+// ScheduleWindow is a maintenance window
+type ScheduleWindow struct {
+	// Start is a cron expression of the times the window opens: minute, hour,
+	// day of month, month and day of week, e.g. "0 22 * * FRI"
+	Start string `yaml:"start" validate:"required"`
+	// Duration is how long the window stays open, in elapsed time
+	Duration time.Duration `yaml:"duration" validate:"gt=0"`
+}
+
+// EventFilter selects the events an adapter executes
+type EventFilter struct {
+	// DataContentTypes are the allowed media types of the event data, e.g.
+	// "application/json". Parameters are ignored and events without a
+	// datacontenttype are application/json. Empty allows every type.
+	DataContentTypes []string `yaml:"data_content_types,omitempty" validate:"unique,dive,required"`
+}
+
+// ExecutionFence serializes the executions that render the same key
+type ExecutionFence struct {
+	// Key is a template over the params naming what an execution acts on, e.g. "{{ .clusterId }}".
+	// It is rendered after param extraction, so captures are not available.
+	Key string `yaml:"key" validate:"required"`
+	// Timeout is how long an execution waits for the execution holding its key
+	// before it fails and asks for redelivery after the same delay (default 30s)
+	Timeout time.Duration `yaml:"timeout,omitempty" validate:"gte=0"`
+}
+
+// DefaultWorkflowName is the name of the implicit default workflow formed by
+// the top-level preconditions, resources and post
+const DefaultWorkflowName = "default"
+
+// Workflow is a named set of preconditions, resources and post actions, run for
+// the events its matcher selects
+type Workflow struct {
+	// Match selects the events of the workflow. The workflow without a matcher
+	// is the default workflow, run for the events no other workflow matches.
+	Match         *WorkflowMatch `yaml:"match,omitempty" validate:"omitempty"`
+	Post          *PostConfig    `yaml:"post,omitempty" validate:"omitempty"`
+	Name          string         `yaml:"name" validate:"required"`
+	Preconditions []Precondition `yaml:"preconditions,omitempty" validate:"dive"`
+	Resources     []Resource     `yaml:"resources,omitempty" validate:"unique=Name,dive"`
+}
+
+// WorkflowMatch selects the events of a workflow: those of one of EventTypes
+// for which Expression is true. An unset criterion matches every event.
+type WorkflowMatch struct {
+	// Expression is a CEL expression over the params, evaluated after param extraction
+	Expression string `yaml:"expression,omitempty"`
+	// EventTypes are CloudEvent types, e.g. "com.redhat.hyperfleet.cluster.deleted"
+	EventTypes []string `yaml:"event_types,omitempty" validate:"unique,dive,required"`
+}
+
+// NotMetBackoff escalates the executions of a key whose preconditions are not
+// met many times in a row. The count of a key is reset by its first execution
+// that meets the preconditions or fails.
+type NotMetBackoff struct {
+	// Key is a template over the params naming what an execution acts on, e.g.
+	// "{{ .clusterId }}" (default: the execution_fence key)
+	Key string `yaml:"key,omitempty"`
+	// Threshold is the number of consecutive not-met executions of a key handled
+	// as usual before escalating (default 5)
+	Threshold int `yaml:"threshold,omitempty" validate:"gte=0"`
+	// BaseDelay is the redelivery delay of the first escalated execution, doubled
+	// by each following one (default 30s)
+	BaseDelay time.Duration `yaml:"base_delay,omitempty" validate:"gte=0"`
+	// MaxDelay caps the redelivery delay (default 5m). It cannot exceed the
+	// broker's requeue max delay, which caps every redelivery delay.
+	MaxDelay time.Duration `yaml:"max_delay,omitempty" validate:"gte=0"`
+	// LogSampleEvery logs the not-met outcome of one of every N escalated
+	// executions of a key, at debug level (default 10)
+	LogSampleEvery int `yaml:"log_sample_every,omitempty" validate:"gte=0"`
+}
+
+// Precondition error policies
+const (
+	PreconditionErrorsFailFast   = "failFast"
+	PreconditionErrorsCollectAll = "collectAll"
+)
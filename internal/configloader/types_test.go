@@ -0,0 +1,42 @@
+package configloader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The fast path of ParseValueDef must agree with the YAML round trip it short-circuits
+func TestParseValueDef(t *testing.T) {
+	tests := []struct {
+		value  any
+		name   string
+		wantOK bool
+	}{
+		{name: "not a map", value: "status"},
+		{name: "plain object", value: map[string]any{"type": "Applied", "status": "True"}},
+		{name: "field", value: map[string]any{"field": "status.phase"}, wantOK: true},
+		{
+			name:   "expression with default",
+			value:  map[string]any{"expression": "a + b", "default": map[string]any{"x": []any{1, "two"}}},
+			wantOK: true,
+		},
+		{name: "extra keys", value: map[string]any{"field": "a", "note": "ignored"}, wantOK: true},
+		{name: "empty field", value: map[string]any{"field": "", "default": 1}},
+		{name: "numeric field", value: map[string]any{"field": 42}, wantOK: true},
+		{name: "null field", value: map[string]any{"field": nil, "expression": "a"}, wantOK: true},
+		{name: "object field", value: map[string]any{"field": map[string]any{"name": "x"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseValueDef(tt.value)
+			assert.Equal(t, tt.wantOK, ok)
+			if m, isMap := tt.value.(map[string]any); isMap {
+				want, wantOK := parseValueDefYAML(m)
+				assert.Equal(t, wantOK, ok, "fast path and YAML round trip disagree")
+				assert.Equal(t, want, got)
+			}
+		})
+	}
+}
@@ -1,22 +1,33 @@
 package configloader
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"mime"
 	"os"
+	"path"
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/google/cel-go/cel"
 
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/criteria"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/schedule"
 )
 
 // templateVarRegex matches Go template variables like {{ .varName }} or {{ .nested.var }}
 var templateVarRegex = regexp.MustCompile(`\{\{\s*\.([a-zA-Z_][a-zA-Z0-9_\.]*)\s*(?:\|[^}]*)?\}\}`)
 
+// varNamePattern matches var names usable in templates and CEL expressions
+var varNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
 // -----------------------------------------------------------------------------
 // Validators
 // -----------------------------------------------------------------------------
@@ -53,22 +64,39 @@ func (v *AdapterConfigValidator) ValidateStructure() error {
 
 // TaskConfigValidator validates AdapterTaskConfig (task configuration)
 type TaskConfigValidator struct {
-	config      *AdapterTaskConfig
-	errors      *ValidationErrors
-	definedVars map[string]bool
-	celEnv      *cel.Env
-	baseDir     string
+	config   *AdapterTaskConfig
+	errors   *ValidationErrors
+	warnings *ValidationErrors
+	// optionalParams are the params that may be unset at runtime: not
+	// required and without a default
+	optionalParams map[string]bool
+	definedVars    map[string]bool
+	// producedNames are the names produced outside definedVars: the
+	// preconditions storing their API response and the resources, including
+	// nested discoveries, under resources
+	producedNames map[string]bool
+	celEnv        *cel.Env
+	baseDir       string
+	// precondition is the name of the precondition whose templates are being validated
+	precondition string
 }
 
 // NewTaskConfigValidator creates a validator for AdapterTaskConfig
 func NewTaskConfigValidator(config *AdapterTaskConfig, baseDir string) *TaskConfigValidator {
 	return &TaskConfigValidator{
-		config:  config,
-		baseDir: baseDir,
-		errors:  &ValidationErrors{},
+		config:   config,
+		baseDir:  baseDir,
+		errors:   &ValidationErrors{},
+		warnings: &ValidationErrors{},
 	}
 }
 
+// Warnings returns the findings of ValidateSemantic that do not make the
+// config invalid, e.g. templates referencing a param that may be unset
+func (v *TaskConfigValidator) Warnings() *ValidationErrors {
+	return v.warnings
+}
+
 // ValidateStructure validates the structural requirements of AdapterTaskConfig
 func (v *TaskConfigValidator) ValidateStructure() error {
 	if v.config == nil {
@@ -89,13 +117,43 @@ func (v *TaskConfigValidator) ValidateFileReferences() error {
 		return nil
 	}
 
+	errors := v.validatePhaseFileReferences(v.config.Resources, v.config.Post, "")
+	for i, workflow := range v.config.Workflows {
+		prefix := fmt.Sprintf("%s[%d].", FieldWorkflows, i)
+		errors = append(errors, v.validatePhaseFileReferences(workflow.Resources, workflow.Post, prefix)...)
+	}
+
+	// Validate schema_ref in event_schemas
+	for i, schema := range v.config.EventSchemas {
+		if schema.SchemaRef != "" {
+			path := fmt.Sprintf("%s[%d].%s", FieldEventSchemas, i, FieldSchemaRef)
+			if err := v.validateFileExists(schema.SchemaRef, path); err != nil {
+				errors = append(errors, err.Error())
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("file reference errors:\n  - %s", strings.Join(errors, "\n  - "))
+	}
+	return nil
+}
+
+// validatePhaseFileReferences returns the errors of the manifest references of
+// resources and the build references of the post payloads, the phases of the
+// top-level workflow or of the workflow whose field path is prefix
+func (v *TaskConfigValidator) validatePhaseFileReferences(resources []Resource, post *PostConfig, prefix string) []string {
 	var errors []string
 
 	// Validate build_ref in post.payloads
-	if v.config.Post != nil {
-		for i, payload := range v.config.Post.Payloads {
+	if post != nil {
+		for i, payload := range post.Payloads {
+			if payload.BuildRefDigest != "" && payload.BuildRef == "" {
+				errors = append(errors, fmt.Sprintf("%s%s.%s[%d].%s is set without %s",
+					prefix, FieldPost, FieldPayloads, i, FieldBuildRefDigest, FieldBuildRef))
+			}
 			if payload.BuildRef != "" {
-				path := fmt.Sprintf("%s.%s[%d].%s", FieldPost, FieldPayloads, i, FieldBuildRef)
+				path := fmt.Sprintf("%s%s.%s[%d].%s", prefix, FieldPost, FieldPayloads, i, FieldBuildRef)
 				if err := v.validateFileExists(payload.BuildRef, path); err != nil {
 					errors = append(errors, err.Error())
 				}
@@ -103,21 +161,27 @@ func (v *TaskConfigValidator) ValidateFileReferences() error {
 		}
 	}
 
-	// Validate manifest.ref in resources
-	for i, resource := range v.config.Resources {
+	// Validate manifest.ref and manifest_ref in resources
+	for i, resource := range resources {
 		ref := resource.GetManifestRef()
+		if resource.ManifestRefDigest != "" && ref == "" && resource.ManifestRef == "" {
+			errors = append(errors, fmt.Sprintf("%s%s[%d].%s of resource %q is set without %s or %s.%s",
+				prefix, FieldResources, i, FieldManifestRefDigest, resource.Name, FieldManifestRef, FieldManifest, FieldRef))
+		}
 		if ref != "" {
-			path := fmt.Sprintf("%s[%d].%s.%s", FieldResources, i, FieldManifest, FieldRef)
+			path := fmt.Sprintf("%s%s[%d].%s.%s", prefix, FieldResources, i, FieldManifest, FieldRef)
 			if err := v.validateFileExists(ref, path); err != nil {
 				errors = append(errors, err.Error())
 			}
 		}
+		if resource.ManifestRef != "" {
+			path := fmt.Sprintf("%s%s[%d].%s of resource %q", prefix, FieldResources, i, FieldManifestRef, resource.Name)
+			if err := v.validateFileExists(resource.ManifestRef, path); err != nil {
+				errors = append(errors, err.Error())
+			}
+		}
 	}
-
-	if len(errors) > 0 {
-		return fmt.Errorf("file reference errors:\n  - %s", strings.Join(errors, "\n  - "))
-	}
-	return nil
+	return errors
 }
 
 func (v *TaskConfigValidator) validateFileExists(refPath, configPath string) error {
@@ -158,12 +222,29 @@ func (v *TaskConfigValidator) ValidateSemantic() error {
 	}
 
 	// Run all semantic validators
+	v.validateRequiredParams()
+	v.validateParamValueFrom()
+	v.validateVars()
+	v.validateEventFilter()
+	v.validateNotMetBackoff()
+	v.validateSchedule()
+	v.validateFinalizer()
+	v.validateIdempotency()
+	v.validateSubjectPatterns()
+	v.validateMaps()
+	v.validateCaptureResponseAs()
+	v.validatePreconditionGraph()
+	v.validateReasonLabels()
+	v.validateResourceNames()
 	v.validateTransportConfig()
 	v.validateConditionValues()
 	v.validateCaptureFieldExpressions()
+	v.validateResponseExpectations()
 	v.validateTemplateVariables()
 	v.validateCELExpressions()
+	v.validatePayloadConditions()
 	v.validateK8sManifests()
+	v.validateWorkflows()
 
 	if v.errors.HasErrors() {
 		return v.errors
@@ -173,6 +254,67 @@ func (v *TaskConfigValidator) ValidateSemantic() error {
 
 func (v *TaskConfigValidator) collectDefinedVariables() {
 	v.definedVars = v.config.GetDefinedVariables()
+	v.optionalParams = make(map[string]bool)
+	for _, p := range v.config.Params {
+		// A param listed in required_params is checked at runtime like a required param
+		if p.Name != "" && !p.Required && p.Default == nil && !slices.Contains(v.config.RequiredParams, p.Name) {
+			v.optionalParams[p.Name] = true
+		}
+	}
+	// A subject segment param is unset when a pattern without it matches, or when
+	// none matches an optional subject
+	for name, count := range v.config.subjectParamCounts() {
+		if v.config.SubjectOptional || count < len(v.config.SubjectPatterns) {
+			v.optionalParams[name] = true
+		}
+	}
+	v.producedNames = make(map[string]bool)
+	for _, precond := range v.config.Preconditions {
+		if precond.APICall != nil {
+			v.producedNames[precond.Name] = true
+		}
+	}
+	for _, r := range v.config.Resources {
+		v.producedNames[FieldResources+"."+r.Name] = true
+		for _, nd := range r.NestedDiscoveries {
+			v.producedNames[FieldResources+"."+nd.Name] = true
+		}
+	}
+	for _, key := range ResourceSummaryKeys() {
+		v.producedNames[FieldResources+"."+key] = true
+	}
+}
+
+// subjectParamCounts returns the segment names of the valid subject patterns,
+// with the number of patterns naming each
+func (c *AdapterTaskConfig) subjectParamCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, raw := range c.SubjectPatterns {
+		if pattern, err := ParseSubjectPattern(raw); err == nil {
+			for _, name := range pattern.Names() {
+				counts[name]++
+			}
+		}
+	}
+	return counts
+}
+
+// validateResourceNames checks that no resource or nested discovery takes the
+// name of a key of the apply summary, which would hide it from post payloads
+func (v *TaskConfigValidator) validateResourceNames() {
+	for i, resource := range v.config.Resources {
+		path := fmt.Sprintf("%s[%d]", FieldResources, i)
+		if slices.Contains(ResourceSummaryKeys(), resource.Name) {
+			v.errors.Add(path+"."+FieldName,
+				fmt.Sprintf("%q is reserved for the apply summary of the resources", resource.Name))
+		}
+		for j, nd := range resource.NestedDiscoveries {
+			if slices.Contains(ResourceSummaryKeys(), nd.Name) {
+				v.errors.Add(fmt.Sprintf("%s.%s[%d].%s", path, FieldNestedDiscoveries, j, FieldName),
+					fmt.Sprintf("%q is reserved for the apply summary of the resources", nd.Name))
+			}
+		}
+	}
 }
 
 // GetDefinedVariables returns all variables defined in the task config
@@ -195,6 +337,16 @@ func (c *AdapterTaskConfig) GetDefinedVariables() map[string]bool {
 		}
 	}
 
+	// Constant params from vars
+	for name := range c.Vars {
+		vars[name] = true
+	}
+
+	// Named segments of the subject patterns
+	for name := range c.subjectParamCounts() {
+		vars[name] = true
+	}
+
 	// Variables from precondition captures
 	for _, precond := range c.Preconditions {
 		for _, capture := range precond.Capture {
@@ -202,6 +354,9 @@ func (c *AdapterTaskConfig) GetDefinedVariables() map[string]bool {
 				vars[capture.Name] = true
 			}
 		}
+		if precond.CaptureResponseAs != "" {
+			vars[precond.CaptureResponseAs] = true
+		}
 	}
 
 	// Post payloads
@@ -259,6 +414,409 @@ func (v *TaskConfigValidator) initCELEnv() error {
 	return nil
 }
 
+// validateRequiredParams checks that required_params reference declared params or event.* paths
+func (v *TaskConfigValidator) validateRequiredParams() {
+	declared := make(map[string]bool, len(v.config.Params))
+	for _, p := range v.config.Params {
+		declared[p.Name] = true
+	}
+	for i, name := range v.config.RequiredParams {
+		if declared[name] || (strings.HasPrefix(name, "event.") && len(name) > len("event.")) {
+			continue
+		}
+		v.errors.Add(fmt.Sprintf("%s[%d]", FieldRequiredParams, i),
+			fmt.Sprintf("%q is neither a declared param nor an event.* path", name))
+	}
+}
+
+// validateVars checks that vars are identifiers that do not shadow a built-in
+// variable or a declared param
+func (v *TaskConfigValidator) validateVars() {
+	declared := make(map[string]bool, len(v.config.Params))
+	for _, p := range v.config.Params {
+		declared[p.Name] = true
+	}
+	for _, name := range slices.Sorted(maps.Keys(v.config.Vars)) {
+		path := FieldVars + "." + name
+		switch {
+		case !varNamePattern.MatchString(name):
+			v.errors.Add(path, fmt.Sprintf("%q is not a valid variable name", name))
+		case slices.Contains(BuiltinVariables(), name):
+			v.errors.Add(path, fmt.Sprintf("%q collides with a built-in variable", name))
+		case declared[name]:
+			v.errors.Add(path, fmt.Sprintf("%q collides with a declared param of the same name", name))
+		}
+	}
+}
+
+// validateEventFilter checks that the event filter data content types are media types
+// and that plain_text_data_key is a variable name
+func (v *TaskConfigValidator) validateEventFilter() {
+	if v.config.EventFilter != nil {
+		for i, contentType := range v.config.EventFilter.DataContentTypes {
+			mediaType, _, err := mime.ParseMediaType(contentType)
+			if err == nil && !strings.Contains(mediaType, "/") {
+				err = errors.New("missing subtype")
+			}
+			if err != nil {
+				v.errors.Add(fmt.Sprintf("%s.data_content_types[%d]", FieldEventFilter, i),
+					fmt.Sprintf("%q is not a media type: %v", contentType, err))
+			}
+		}
+	}
+	if key := v.config.PlainTextDataKey; key != "" && !varNamePattern.MatchString(key) {
+		v.errors.Add("plain_text_data_key", fmt.Sprintf("%q is not a valid variable name", key))
+	}
+}
+
+// validateNotMetBackoff checks that not_met_backoff has a key, its own or the
+// execution_fence one, and a base delay within the max delay
+func (v *TaskConfigValidator) validateNotMetBackoff() {
+	backoff := v.config.NotMetBackoff
+	if backoff == nil {
+		return
+	}
+	if backoff.Key == "" && v.config.ExecutionFence == nil {
+		v.errors.Add(FieldNotMetBackoff+"."+FieldKey, "key is required without execution_fence")
+	}
+	if backoff.MaxDelay > 0 && backoff.BaseDelay > backoff.MaxDelay {
+		v.errors.Add(FieldNotMetBackoff+".base_delay", "base_delay must not exceed max_delay")
+	}
+}
+
+// validateSchedule checks the time zone and the window start times of the schedule
+func (v *TaskConfigValidator) validateSchedule() {
+	sched := v.config.Schedule
+	if sched == nil {
+		return
+	}
+	if sched.Timezone != "" {
+		if _, err := time.LoadLocation(sched.Timezone); err != nil {
+			v.errors.Add(FieldSchedule+"."+FieldTimezone, fmt.Sprintf("unknown time zone %q", sched.Timezone))
+		}
+	}
+	for i, window := range sched.Windows {
+		if _, err := schedule.ParseCron(window.Start); err != nil {
+			v.errors.Add(fmt.Sprintf("%s.%s[%d].%s", FieldSchedule, FieldWindows, i, FieldStart), err.Error())
+		}
+	}
+}
+
+// validateParamValueFrom checks the k8s_field_ref params. Params are extracted
+// in order, so their namespace and name templates may only reference the
+// built-in variables, the vars and the params declared before them.
+func (v *TaskConfigValidator) validateParamValueFrom() {
+	available := make(map[string]bool)
+	for _, name := range BuiltinVariables() {
+		available[name] = true
+	}
+	for name := range v.config.Vars {
+		available[name] = true
+	}
+	declared := make(map[string]bool, len(v.config.Params))
+	for _, p := range v.config.Params {
+		declared[p.Name] = true
+	}
+	for i, p := range v.config.Params {
+		if p.ValueFrom != nil && p.ValueFrom.K8sFieldRef != nil {
+			ref := p.ValueFrom.K8sFieldRef
+			basePath := fmt.Sprintf("%s[%d].%s.%s", FieldParams, i, FieldValueFrom, FieldK8sFieldRef)
+			for _, field := range []struct{ name, template string }{
+				{FieldNamespace, ref.Namespace}, {FieldName, ref.Name},
+			} {
+				path := basePath + "." + field.name
+				v.validateTemplateString(field.template, path)
+				for _, reference := range templateReferences(field.template) {
+					root, _, _ := strings.Cut(reference, ".")
+					switch {
+					case available[root]:
+					case root == p.Name:
+						v.errors.Add(path, fmt.Sprintf("param %q references itself", p.Name))
+					case declared[root]:
+						v.errors.Add(path, fmt.Sprintf(
+							"param %q is declared after param %q: params are extracted in order", root, p.Name))
+					case v.definedVars[root]:
+						v.errors.Add(path, fmt.Sprintf("%q is not available during param extraction", root))
+					}
+				}
+			}
+			if _, err := criteria.ExtractField(map[string]interface{}{}, ref.FieldPath); ref.FieldPath != "" && err != nil {
+				v.errors.Add(basePath+"."+FieldFieldPath, err.Error())
+			}
+		}
+		available[p.Name] = true
+	}
+}
+
+// validateFinalizer checks that the teardown workflow of the finalizer exists and
+// that the object reference uses defined variables
+func (v *TaskConfigValidator) validateFinalizer() {
+	finalizer := v.config.Finalizer
+	if finalizer == nil {
+		return
+	}
+	if !strings.Contains(finalizer.Name, "/") {
+		v.warnings.Add(FieldFinalizer+"."+FieldName,
+			fmt.Sprintf("finalizer %q is not domain-qualified, e.g. \"hyperfleet.io/%s\"", finalizer.Name, finalizer.Name))
+	}
+	if !slices.ContainsFunc(v.config.Workflows, func(w Workflow) bool { return w.Name == finalizer.TeardownWorkflow }) {
+		v.errors.Add(FieldFinalizer+"."+FieldTeardownWorkflow,
+			fmt.Sprintf("workflow %q is not defined: the finalizer would never be removed", finalizer.TeardownWorkflow))
+	}
+	onPath := FieldFinalizer + "." + FieldOn
+	v.validateTemplateString(finalizer.On.Namespace, onPath+"."+FieldNamespace)
+	v.validateTemplateString(finalizer.On.Name, onPath+"."+FieldName)
+}
+
+// validateIdempotency checks that the idempotency params are defined and that
+// the configmap store is located
+func (v *TaskConfigValidator) validateIdempotency() {
+	idempotency := v.config.Idempotency
+	if idempotency == nil {
+		return
+	}
+	params := []struct{ field, name string }{
+		{"generation_param", idempotency.GenerationParam},
+		{"key_param", idempotency.KeyParam},
+	}
+	for _, param := range params {
+		if param.name != "" && !v.definedVars[param.name] {
+			v.errors.Add(FieldIdempotency+"."+param.field, fmt.Sprintf("%q is neither a param nor a capture", param.name))
+		}
+	}
+	if idempotency.EffectiveStore() == IdempotencyStoreConfigMap &&
+		(idempotency.ConfigMapName == "" || idempotency.ConfigMapNamespace == "") {
+		v.errors.Add(FieldIdempotency+".store", "the configmap store requires configmap_name and configmap_namespace")
+	}
+}
+
+// validateSubjectPatterns checks that the subject patterns parse and that
+// their segment names do not collide with the params, vars or built-in variables
+func (v *TaskConfigValidator) validateSubjectPatterns() {
+	taken := make(map[string]string)
+	for _, name := range BuiltinVariables() {
+		taken[name] = "a built-in variable"
+	}
+	for _, p := range v.config.Params {
+		taken[p.Name] = "a declared param"
+	}
+	for name := range v.config.Vars {
+		taken[name] = "a var"
+	}
+	for i, raw := range v.config.SubjectPatterns {
+		path := fmt.Sprintf("%s[%d]", FieldSubjectPatterns, i)
+		pattern, err := ParseSubjectPattern(raw)
+		if err != nil {
+			v.errors.Add(path, err.Error())
+			continue
+		}
+		for _, name := range pattern.Names() {
+			if taken[name] != "" {
+				v.errors.Add(path, fmt.Sprintf("segment %q collides with %s of the same name", name, taken[name]))
+			}
+		}
+	}
+	if v.config.SubjectOptional && len(v.config.SubjectPatterns) == 0 {
+		v.warnings.Add("subject_optional", "subject_optional has no effect without subject_patterns")
+	}
+}
+
+// validateMaps checks that each lookup map has either inline entries or a
+// ConfigMap, that its values are all strings or all objects and that its
+// default goes with its missing key policy
+func (v *TaskConfigValidator) validateMaps() {
+	for i, m := range v.config.Maps {
+		path := fmt.Sprintf("%s[%d]", FieldMaps, i)
+		switch {
+		case m.FromConfigMap() && len(m.Entries) > 0:
+			v.errors.Add(path, "entries and configmap_name are mutually exclusive")
+		case m.FromConfigMap() && m.ConfigMapNamespace == "":
+			v.errors.Add(path, "configmap_name requires configmap_namespace")
+		case !m.FromConfigMap() && len(m.Entries) == 0:
+			v.errors.Add(path, "requires entries or configmap_name")
+		}
+		if err := CheckLookupValues(m.Entries, m.Default); err != nil {
+			v.errors.Add(path, err.Error())
+		}
+		switch {
+		case m.EffectiveOnMissing() == LookupOnMissingDefault && m.Default == nil:
+			v.errors.Add(path+".on_missing", "on_missing: default requires a default value")
+		case m.EffectiveOnMissing() != LookupOnMissingDefault && m.Default != nil:
+			v.warnings.Add(path+"."+FieldDefault,
+				fmt.Sprintf("default has no effect with on_missing: %s", m.EffectiveOnMissing()))
+		}
+	}
+}
+
+// validateLookupMaps reports the lookup() calls found under path whose map,
+// named by a string literal, is not one of the maps of the config
+func (v *TaskConfigValidator) validateLookupMaps(names []string, path string) {
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if !slices.ContainsFunc(v.config.Maps, func(m LookupMap) bool { return m.Name == name }) {
+			v.errors.Add(path, fmt.Sprintf("lookup() references unknown map %q", name))
+		}
+	}
+}
+
+// validateWorkflows checks the workflow matchers, that at most one workflow is
+// the default and that no event type selects two workflows, then validates the
+// phases of every workflow like the top-level ones
+func (v *TaskConfigValidator) validateWorkflows() {
+	var defaultWorkflow string
+	typeWorkflows := make(map[string]string)
+	for i, workflow := range v.config.Workflows {
+		path := fmt.Sprintf("%s[%d]", FieldWorkflows, i)
+		match := workflow.Match
+		switch {
+		case match == nil && defaultWorkflow != "":
+			v.errors.Add(path+".match", fmt.Sprintf("workflows %q and %q are both the default: only one may have no match",
+				defaultWorkflow, workflow.Name))
+		case match == nil && v.config.hasTopLevelPhases():
+			v.errors.Add(path+".match", "the top-level preconditions, resources and post form the default workflow: "+
+				"a workflow without match is not allowed with them")
+		case match == nil:
+			defaultWorkflow = workflow.Name
+		case workflow.Name == DefaultWorkflowName:
+			v.errors.Add(path+"."+FieldName, fmt.Sprintf("%q is reserved for the default workflow", DefaultWorkflowName))
+		case len(match.EventTypes) == 0 && match.Expression == "":
+			v.errors.Add(path+".match", "match requires event_types or expression")
+		}
+		if match != nil && match.Expression != "" {
+			v.validateCELExpression(match.Expression, path+".match."+FieldExpression)
+		}
+		// Types matched without an expression would always select both workflows
+		if match != nil && match.Expression == "" {
+			for _, eventType := range match.EventTypes {
+				if other, ok := typeWorkflows[eventType]; ok {
+					v.errors.Add(path+".match.event_types", fmt.Sprintf("event type %q also selects workflow %q", eventType, other))
+				}
+				typeWorkflows[eventType] = workflow.Name
+			}
+		}
+		v.validateWorkflowPhases(workflow, path)
+	}
+}
+
+// validateWorkflowPhases validates the phases of workflow as the top-level
+// phases of a task config with the same params, reporting the findings under path
+func (v *TaskConfigValidator) validateWorkflowPhases(workflow Workflow, path string) {
+	phases := *v.config
+	phases.Preconditions = workflow.Preconditions
+	phases.Resources = workflow.Resources
+	phases.Post = workflow.Post
+	phases.Workflows = nil
+	sub := NewTaskConfigValidator(&phases, v.baseDir)
+	_ = sub.ValidateSemantic()
+
+	// The findings outside the phases are the task config's own, already reported
+	prefixed := func(findings, into *ValidationErrors) {
+		for _, finding := range findings.Errors {
+			if isPhasePath(finding.Path) {
+				into.Add(path+"."+finding.Path, finding.Message)
+			}
+		}
+	}
+	prefixed(sub.errors, v.errors)
+	prefixed(sub.warnings, v.warnings)
+}
+
+// isPhasePath reports whether a finding path is inside the preconditions,
+// resources or post of a task config
+func isPhasePath(path string) bool {
+	for _, field := range []string{FieldPreconditions, FieldResources, FieldPost} {
+		if path == field || strings.HasPrefix(path, field+".") || strings.HasPrefix(path, field+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCaptureResponseAs checks that capture_response_as names are identifiers
+// of preconditions with an API call that do not collide with another variable.
+// Preconditions store their response under their own name, so those collide too.
+func (v *TaskConfigValidator) validateCaptureResponseAs() {
+	taken := make(map[string]string)
+	for _, name := range BuiltinVariables() {
+		taken[name] = "a built-in variable"
+	}
+	for _, p := range v.config.Params {
+		taken[p.Name] = "a declared param"
+	}
+	for name := range v.config.Vars {
+		taken[name] = "a var"
+	}
+	if v.config.Post != nil {
+		for _, payload := range v.config.Post.Payloads {
+			taken[payload.Name] = "a post payload"
+		}
+	}
+	for _, precond := range v.config.Preconditions {
+		taken[precond.Name] = "a precondition name"
+		for _, capture := range precond.Capture {
+			taken[capture.Name] = "a capture"
+		}
+	}
+
+	for i, precond := range v.config.Preconditions {
+		name := precond.CaptureResponseAs
+		if name == "" {
+			continue
+		}
+		path := fmt.Sprintf("%s[%d].%s", FieldPreconditions, i, FieldCaptureResponseAs)
+		switch {
+		case precond.APICall == nil:
+			v.errors.Add(path, "capture_response_as requires an api_call")
+		case !varNamePattern.MatchString(name):
+			v.errors.Add(path, fmt.Sprintf("%q is not a valid variable name", name))
+		case taken[name] != "":
+			v.errors.Add(path, fmt.Sprintf("%q collides with %s of the same name", name, taken[name]))
+		default:
+			taken[name] = "another capture_response_as"
+		}
+	}
+}
+
+// validateReasonLabels checks that the reason labels of the preconditions are
+// plain identifiers, not templates: they are metric label values
+func (v *TaskConfigValidator) validateReasonLabels() {
+	for i, precond := range v.config.Preconditions {
+		if label := precond.ReasonLabel; label != "" && !varNamePattern.MatchString(label) {
+			v.errors.Add(fmt.Sprintf("%s[%d].%s", FieldPreconditions, i, FieldReasonLabel),
+				fmt.Sprintf("%q is not a valid reason label: use letters, digits and underscores", label))
+		}
+	}
+}
+
+// validatePreconditionGraph checks that depends_on names other preconditions
+// and that the dependencies between preconditions, named or inferred from the
+// captures they reference, have no cycle
+func (v *TaskConfigValidator) validatePreconditionGraph() {
+	names := make(map[string]bool, len(v.config.Preconditions))
+	for _, precond := range v.config.Preconditions {
+		names[precond.Name] = true
+	}
+	for i, precond := range v.config.Preconditions {
+		for j, name := range precond.DependsOn {
+			path := fmt.Sprintf("%s[%d].%s[%d]", FieldPreconditions, i, FieldDependsOn, j)
+			switch {
+			case name == precond.Name:
+				v.errors.Add(path, fmt.Sprintf("precondition %q depends on itself", name))
+			case !names[name]:
+				v.errors.Add(path, fmt.Sprintf("precondition %q depends on %q, which is not a precondition",
+					precond.Name, name))
+			}
+		}
+	}
+	if _, err := PreconditionLevels(v.config.Preconditions); err != nil {
+		v.errors.Add(FieldPreconditions, err.Error())
+	}
+}
+
 func (v *TaskConfigValidator) validateTransportConfig() {
 	for i, resource := range v.config.Resources {
 		basePath := fmt.Sprintf("%s[%d]", FieldResources, i)
@@ -295,6 +853,17 @@ func (v *TaskConfigValidator) validateTransportConfig() {
 						maestroPath+"."+FieldTargetCluster)
 				}
 
+				// Validate template variables in manifest_work_metadata values
+				if metadata := resource.Transport.Maestro.ManifestWorkMetadata; metadata != nil {
+					metadataPath := maestroPath + "." + FieldManifestWorkMetadata
+					for key, value := range metadata.Labels {
+						v.validateTemplateString(value, metadataPath+".labels."+key)
+					}
+					for key, value := range metadata.Annotations {
+						v.validateTemplateString(value, metadataPath+".annotations."+key)
+					}
+				}
+
 				// Validate manifest is set for maestro transport
 				if resource.Manifest == nil {
 					v.errors.Add(basePath+"."+FieldManifest,
@@ -347,23 +916,105 @@ func (v *TaskConfigValidator) validateCaptureFieldExpressions() {
 		for j, capture := range precond.Capture {
 			if capture.Expression != "" && v.celEnv != nil {
 				path := fmt.Sprintf("%s[%d].%s[%d].%s", FieldPreconditions, i, FieldCapture, j, FieldExpression)
-				v.validateCELExpression(capture.Expression, path)
+				// Capture expressions are evaluated against the response, not the params
+				v.parseCELExpression(capture.Expression, path)
+			}
+		}
+	}
+}
+
+// validateResponseExpectations checks that expect is set on preconditions with
+// an API call and that its paths parse
+func (v *TaskConfigValidator) validateResponseExpectations() {
+	for i, precond := range v.config.Preconditions {
+		if precond.Expect == nil {
+			continue
+		}
+		path := fmt.Sprintf("%s[%d].%s", FieldPreconditions, i, FieldExpect)
+		if precond.APICall == nil {
+			v.errors.Add(path, "expect requires an api_call")
+			continue
+		}
+		for j, assertion := range precond.Expect.Assertions {
+			assertionPath := fmt.Sprintf("%s.assertions[%d]", path, j)
+			if err := criteria.ParseFieldPath(assertion.Path); err != nil {
+				v.errors.Add(assertionPath+".path", err.Error())
+			}
+			if assertion.Type == "" && !assertion.Required {
+				v.warnings.Add(assertionPath, "asserts nothing: set a type or required")
 			}
 		}
 	}
 }
 
+// validateAPICall checks the templates of an API call and that its body matches
+// its body_type: a json body has no form_fields or parts, a form body only
+// form_fields and a multipart body only parts
+func (v *TaskConfigValidator) validateAPICall(apiCall *APICall, basePath string) {
+	v.validateTemplateString(apiCall.URL, basePath+"."+FieldURL)
+	v.validateTemplateString(apiCall.Target, basePath+"."+FieldTarget)
+	v.validateTemplateString(apiCall.Body, basePath+"."+FieldBody)
+	for j, header := range apiCall.Headers {
+		v.validateTemplateString(header.Value,
+			fmt.Sprintf("%s.%s[%d].%s", basePath, FieldHeaders, j, FieldHeaderValue))
+	}
+	for j, field := range apiCall.FormFields {
+		v.validateTemplateString(field.Value,
+			fmt.Sprintf("%s.%s[%d].%s", basePath, FieldFormFields, j, FieldHeaderValue))
+	}
+	for j, part := range apiCall.Parts {
+		v.validateTemplateString(part.Content, fmt.Sprintf("%s.%s[%d].%s", basePath, FieldParts, j, FieldContent))
+	}
+
+	v.validateBodyPayloads(apiCall, basePath)
+
+	bodyTypePath := basePath + "." + FieldBodyType
+	hasPayloadBody := apiCall.BodyFrom != "" || len(apiCall.BodyMerge) > 0
+	switch apiCall.BodyType {
+	case BodyTypeForm:
+		if hasPayloadBody {
+			v.errors.Add(bodyTypePath, "body_type form cannot be combined with body_from or body_merge")
+		}
+		if apiCall.Body != "" {
+			v.errors.Add(bodyTypePath, "body_type form sends form_fields and cannot be combined with a body")
+		}
+		if len(apiCall.Parts) > 0 {
+			v.errors.Add(bodyTypePath, "body_type form cannot have parts")
+		}
+	case BodyTypeMultipart:
+		if hasPayloadBody {
+			v.errors.Add(bodyTypePath, "body_type multipart cannot be combined with body_from or body_merge")
+		}
+		if apiCall.Body != "" {
+			v.errors.Add(bodyTypePath, "body_type multipart sends parts and cannot be combined with a body")
+		}
+		if len(apiCall.FormFields) > 0 {
+			v.errors.Add(bodyTypePath, "body_type multipart cannot have form_fields")
+		}
+	default:
+		if len(apiCall.FormFields) > 0 {
+			v.errors.Add(basePath+"."+FieldFormFields, "form_fields require body_type form")
+		}
+		if len(apiCall.Parts) > 0 {
+			v.errors.Add(basePath+"."+FieldParts, "parts require body_type multipart")
+		}
+	}
+}
+
 func (v *TaskConfigValidator) validateTemplateVariables() {
+	if v.config.ExecutionFence != nil {
+		v.validateTemplateString(v.config.ExecutionFence.Key, FieldExecutionFence+"."+FieldKey)
+	}
+	if backoff := v.config.NotMetBackoff; backoff != nil && backoff.Key != "" {
+		v.validateTemplateString(backoff.Key, FieldNotMetBackoff+"."+FieldKey)
+	}
+
 	// Validate precondition API call URLs and bodies
 	for i, precond := range v.config.Preconditions {
 		if precond.APICall != nil {
-			basePath := fmt.Sprintf("%s[%d].%s", FieldPreconditions, i, FieldAPICall)
-			v.validateTemplateString(precond.APICall.URL, basePath+"."+FieldURL)
-			v.validateTemplateString(precond.APICall.Body, basePath+"."+FieldBody)
-			for j, header := range precond.APICall.Headers {
-				v.validateTemplateString(header.Value,
-					fmt.Sprintf("%s.%s[%d].%s", basePath, FieldHeaders, j, FieldHeaderValue))
-			}
+			v.precondition = precond.Name
+			v.validateAPICall(precond.APICall, fmt.Sprintf("%s[%d].%s", FieldPreconditions, i, FieldAPICall))
+			v.precondition = ""
 		}
 	}
 
@@ -407,13 +1058,27 @@ func (v *TaskConfigValidator) validateTemplateVariables() {
 	if v.config.Post != nil {
 		for i, action := range v.config.Post.PostActions {
 			if action.APICall != nil {
-				basePath := fmt.Sprintf("%s.%s[%d].%s", FieldPost, FieldPostActions, i, FieldAPICall)
-				v.validateTemplateString(action.APICall.URL, basePath+"."+FieldURL)
-				v.validateTemplateString(action.APICall.Body, basePath+"."+FieldBody)
-				for j, header := range action.APICall.Headers {
-					v.validateTemplateString(header.Value,
-						fmt.Sprintf("%s.%s[%d].%s", basePath, FieldHeaders, j, FieldHeaderValue))
+				v.validateAPICall(action.APICall, fmt.Sprintf("%s.%s[%d].%s", FieldPost, FieldPostActions, i, FieldAPICall))
+			}
+			if action.K8sPatch != nil {
+				basePath := fmt.Sprintf("%s.%s[%d].%s", FieldPost, FieldPostActions, i, FieldK8sPatch)
+				v.validateTemplateString(action.K8sPatch.Namespace, basePath+"."+FieldNamespace)
+				v.validateTemplateString(action.K8sPatch.Name, basePath+"."+FieldName)
+				v.validateTemplateString(action.K8sPatch.Body, basePath+"."+FieldBody)
+			}
+			if action.Webhook != nil {
+				v.validateWebhook(action.Webhook, fmt.Sprintf("%s.%s[%d].%s", FieldPost, FieldPostActions, i, FieldWebhook))
+			}
+			if action.SkipIfUnchanged != nil {
+				basePath := fmt.Sprintf("%s.%s[%d].%s", FieldPost, FieldPostActions, i, FieldSkipIfUnchanged)
+				if action.APICall == nil {
+					v.errors.Add(basePath, "skip_if_unchanged requires an api_call")
 				}
+				v.validateTemplateString(action.SkipIfUnchanged.Key, basePath+"."+FieldKey)
+			}
+			if action.BufferOnFailure != nil && action.APICall == nil {
+				v.errors.Add(fmt.Sprintf("%s.%s[%d].%s", FieldPost, FieldPostActions, i, FieldBufferOnFailure),
+					"buffer_on_failure requires an api_call")
 			}
 		}
 
@@ -426,6 +1091,87 @@ func (v *TaskConfigValidator) validateTemplateVariables() {
 			}
 		}
 	}
+
+	for _, fields := range v.logActionFields() {
+		v.validateTemplateMap(fields.values, fields.path)
+	}
+}
+
+// validateBodyPayloads checks that the payloads of body_from and body_merge
+// are post payloads, which preconditions run before
+func (v *TaskConfigValidator) validateBodyPayloads(apiCall *APICall, basePath string) {
+	payloads := make(map[string]bool)
+	if v.config.Post != nil {
+		for _, payload := range v.config.Post.Payloads {
+			payloads[payload.Name] = true
+		}
+	}
+	check := func(name, path string) {
+		switch {
+		case v.precondition != "":
+			v.errors.Add(path, fmt.Sprintf("payload %q is built after the preconditions run", name))
+		case !payloads[name]:
+			v.errors.Add(path, fmt.Sprintf("payload %q is not a post payload", name))
+		}
+	}
+	if apiCall.BodyFrom != "" {
+		check(apiCall.BodyFrom, basePath+"."+FieldBodyFrom)
+	}
+	for j, name := range apiCall.BodyMerge {
+		check(name, fmt.Sprintf("%s.%s[%d]", basePath, FieldBodyMerge, j))
+	}
+}
+
+// validateWebhook validates the templates of a webhook post action and that its
+// payload is a post payload
+func (v *TaskConfigValidator) validateWebhook(hook *WebhookAction, basePath string) {
+	v.validateTemplateString(hook.URL, basePath+"."+FieldURL)
+	for j, header := range hook.Headers {
+		v.validateTemplateString(header.Value,
+			fmt.Sprintf("%s.%s[%d].%s", basePath, FieldHeaders, j, FieldHeaderValue))
+	}
+	if hook.Signing != nil {
+		v.validateTemplateString(hook.Signing.Secret, basePath+"."+FieldSigning+"."+FieldSecret)
+	}
+	if hook.Payload == "" {
+		return
+	}
+	for _, payload := range v.config.Post.Payloads {
+		if payload.Name == hook.Payload {
+			return
+		}
+	}
+	v.errors.Add(basePath+"."+FieldPayload, fmt.Sprintf("payload %q is not a post payload", hook.Payload))
+}
+
+// logFields are the structured fields of a log action and their config path
+type logFields struct {
+	values map[string]interface{}
+	path   string
+}
+
+// logActionFields returns the fields of the precondition and post action log actions
+func (v *TaskConfigValidator) logActionFields() []logFields {
+	var fields []logFields
+	for i, precond := range v.config.Preconditions {
+		if precond.Log != nil && len(precond.Log.Fields) > 0 {
+			fields = append(fields, logFields{
+				values: precond.Log.Fields,
+				path:   fmt.Sprintf("%s[%d].%s.%s", FieldPreconditions, i, FieldLog, FieldLogFields),
+			})
+		}
+	}
+	if v.config.Post != nil {
+		for i, action := range v.config.Post.PostActions {
+			if action.Log != nil && len(action.Log.Fields) > 0 {
+				fields = append(fields, logFields{
+					values: action.Log.Fields,
+					path:   fmt.Sprintf("%s.%s[%d].%s.%s", FieldPost, FieldPostActions, i, FieldLog, FieldLogFields),
+				})
+			}
+		}
+	}
+	return fields
 }
 
 func (v *TaskConfigValidator) validateTemplateString(s string, path string) {
@@ -433,15 +1179,39 @@ func (v *TaskConfigValidator) validateTemplateString(s string, path string) {
 		return
 	}
 
+	undefined := make(map[string]bool)
 	matches := templateVarRegex.FindAllStringSubmatch(s, -1)
 	for _, match := range matches {
 		if len(match) > 1 {
 			varName := match[1]
 			if !v.isVariableDefined(varName) {
-				v.errors.Add(path, fmt.Sprintf("undefined template variable %q", varName))
+				message := fmt.Sprintf("undefined template variable %q", varName)
+				if v.precondition != "" {
+					message += fmt.Sprintf(" referenced by precondition %q: no param, var or precondition capture "+
+						"produces it", v.precondition)
+				}
+				v.errors.Add(path, message)
+				undefined[varName] = true
+				continue
 			}
+			if root, _, _ := strings.Cut(varName, "."); v.optionalParams[root] {
+				v.warnings.Add(path, fmt.Sprintf(
+					"template variable %q references param %q, which is not required and has no default",
+					varName, root))
+			}
+		}
+	}
+
+	// The parse tree also finds the references the pattern does not match,
+	// e.g. in if, range and with pipelines or function arguments
+	var refs []string
+	for _, ref := range templateReferences(s) {
+		if !undefined[ref] {
+			refs = append(refs, ref)
 		}
 	}
+	v.warnUnknownReferences(refs, "template reference", path)
+	v.validateLookupMaps(templateLookupMaps(s), path)
 }
 
 func (v *TaskConfigValidator) isVariableDefined(varName string) bool {
@@ -499,9 +1269,19 @@ func (v *TaskConfigValidator) validateCELExpressions() {
 			path := fmt.Sprintf("%s[%d].%s", FieldPreconditions, i, FieldExpression)
 			v.validateCELExpression(precond.Expression, path)
 		}
+		if precond.APICall != nil && precond.APICall.Transform != "" {
+			path := fmt.Sprintf("%s[%d].%s.%s", FieldPreconditions, i, FieldAPICall, FieldTransform)
+			v.parseCELExpression(precond.APICall.Transform, path)
+		}
 	}
 
 	if v.config.Post != nil {
+		for i, action := range v.config.Post.PostActions {
+			if action.APICall != nil && action.APICall.Transform != "" {
+				v.errors.Add(fmt.Sprintf("%s.%s[%d].%s.%s", FieldPost, FieldPostActions, i, FieldAPICall, FieldTransform),
+					"transform only applies to the api_call of a precondition")
+			}
+		}
 		for i, payload := range v.config.Post.Payloads {
 			if payload.Build != nil {
 				if buildMap, ok := payload.Build.(map[string]interface{}); ok {
@@ -510,22 +1290,122 @@ func (v *TaskConfigValidator) validateCELExpressions() {
 			}
 		}
 	}
+
+	for _, fields := range v.logActionFields() {
+		v.validateBuildExpressions(fields.values, fields.path)
+	}
+
+	for i, resource := range v.config.Resources {
+		v.validateCELExpression(resource.EnabledIf, fmt.Sprintf("%s[%d].%s", FieldResources, i, FieldEnabledIf))
+	}
+	if v.config.Post != nil {
+		for i, action := range v.config.Post.PostActions {
+			v.validateCELExpression(action.EnabledIf,
+				fmt.Sprintf("%s.%s[%d].%s", FieldPost, FieldPostActions, i, FieldEnabledIf))
+		}
+	}
+}
+
+// validatePayloadConditions checks the status conditions of the post payloads:
+// unique condition types, their status expressions and reason and message
+// templates, that previous starts with a defined variable and that the key is
+// not also set by the build
+func (v *TaskConfigValidator) validatePayloadConditions() {
+	if v.config.Post == nil {
+		return
+	}
+	for i, payload := range v.config.Post.Payloads {
+		conditions := payload.Conditions
+		if conditions == nil {
+			continue
+		}
+		path := fmt.Sprintf("%s.%s[%d].%s", FieldPost, FieldPayloads, i, FieldConditions)
+		if buildMap, ok := payload.Build.(map[string]interface{}); ok {
+			if _, set := buildMap[conditions.EffectiveKey()]; set {
+				v.errors.Add(path+"."+FieldKey, fmt.Sprintf("key %q is also set by build", conditions.EffectiveKey()))
+			}
+		}
+		// A JSONPath expression is checked when it is evaluated
+		if previous := conditions.Previous; previous != "" && !strings.HasPrefix(previous, "{") &&
+			!v.isVariableDefined(previous) {
+			v.errors.Add(path+"."+FieldPrevious, fmt.Sprintf("undefined variable %q", previous))
+		}
+		seen := make(map[string]bool, len(conditions.Items))
+		for j, item := range conditions.Items {
+			itemPath := fmt.Sprintf("%s.%s[%d]", path, FieldItems, j)
+			if seen[item.Type] {
+				v.errors.Add(itemPath+"."+FieldType, fmt.Sprintf("duplicate condition type %q", item.Type))
+			}
+			seen[item.Type] = true
+			if v.celEnv != nil {
+				v.validateCELExpression(item.Status, itemPath+"."+FieldStatus)
+			}
+			v.validateTemplateString(item.Reason, itemPath+"."+FieldReason)
+			v.validateTemplateString(item.Message, itemPath+"."+FieldMessage)
+		}
+	}
 }
 
+// validateCELExpression checks that a CEL expression evaluated against the
+// params parses and warns about the variables it references that no param provides
 func (v *TaskConfigValidator) validateCELExpression(expr string, path string) {
+	if ast := v.parseCELExpression(expr, path); ast != nil {
+		v.warnUnknownReferences(celReferences(ast), "CEL reference", path)
+	}
+}
+
+// parseCELExpression parses a CEL expression, reporting a parse error under
+// path. Returns nil when expr is empty or does not parse.
+func (v *TaskConfigValidator) parseCELExpression(expr string, path string) *cel.Ast {
 	if expr == "" {
-		return
+		return nil
 	}
 
 	expr = strings.TrimSpace(expr)
 
-	_, issues := v.celEnv.Parse(expr)
+	ast, issues := v.celEnv.Parse(expr)
 	if issues != nil && issues.Err() != nil {
 		v.errors.Add(path, fmt.Sprintf("CEL parse error: %v", issues.Err()))
+		return nil
+	}
+	v.validateLookupMaps(celLookupMaps(ast), path)
+	return ast
+}
+
+// warnUnknownReferences warns about the references that no param, capture,
+// var, payload, precondition response or resource provides and that
+// known_external_params does not cover
+func (v *TaskConfigValidator) warnUnknownReferences(refs []string, kind string, path string) {
+	seen := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		if seen[ref] || v.isReferenceKnown(ref) {
+			continue
+		}
+		seen[ref] = true
+		v.warnings.Add(path, fmt.Sprintf("unknown %s %q", kind, ref))
+	}
+}
+
+// isReferenceKnown reports whether a template or CEL reference resolves to a
+// produced name or is covered by known_external_params
+func (v *TaskConfigValidator) isReferenceKnown(ref string) bool {
+	for _, external := range v.config.KnownExternalParams {
+		if ref == external || strings.HasPrefix(ref, external+".") {
+			return true
+		}
+	}
+	root, rest, _ := strings.Cut(ref, ".")
+	if root == FieldResources {
+		name, _, _ := strings.Cut(rest, ".")
+		return name == "" || v.producedNames[FieldResources+"."+name]
 	}
+	return v.definedVars[root] || v.producedNames[root]
 }
 
 func (v *TaskConfigValidator) validateBuildExpressions(m map[string]interface{}, path string) {
+	if valueDef, ok := ParseValueDef(m); ok {
+		v.validateOnError(valueDef, path)
+	}
 	for key, value := range m {
 		currentPath := fmt.Sprintf("%s.%s", path, key)
 		switch val := value.(type) {
@@ -546,6 +1426,18 @@ func (v *TaskConfigValidator) validateBuildExpressions(m map[string]interface{},
 	}
 }
 
+// validateOnError checks the on_error policy of a value definition
+func (v *TaskConfigValidator) validateOnError(valueDef *ValueDef, path string) {
+	switch {
+	case valueDef.OnError == "":
+	case !slices.Contains(ValidOnErrorPolicies, valueDef.OnError):
+		v.errors.Add(path+"."+FieldOnError, fmt.Sprintf("invalid on_error %q, must be one of: %s",
+			valueDef.OnError, strings.Join(ValidOnErrorPolicies, ", ")))
+	case valueDef.OnError == OnErrorDefault && valueDef.Default == nil:
+		v.errors.Add(path+"."+FieldOnError, "on_error: default requires a default value")
+	}
+}
+
 func (v *TaskConfigValidator) validateK8sManifests() {
 	for i, resource := range v.config.Resources {
 		// Skip K8s manifest validation for maestro transport — manifest holds ManifestWork content
@@ -564,13 +1456,63 @@ func (v *TaskConfigValidator) validateK8sManifests() {
 				if ref == "" {
 					v.errors.Add(path+"."+FieldRef, "manifest ref cannot be empty")
 				}
+			} else if items, isList := listItems(manifest); isList {
+				for j, item := range items {
+					itemPath := fmt.Sprintf("%s.%s[%d]", path, FieldItems, j)
+					if itemManifest, ok := item.(map[string]interface{}); ok {
+						v.validateK8sManifest(itemManifest, itemPath)
+					} else {
+						v.errors.Add(itemPath, "list item must be a manifest")
+					}
+				}
+				v.validateListDiscovery(resource.Discovery, items, fmt.Sprintf("%s[%d].%s", FieldResources, i, FieldDiscovery))
 			} else {
 				v.validateK8sManifest(manifest, path)
+				if resource.Discovery != nil && resource.Discovery.Item != nil {
+					v.errors.Add(fmt.Sprintf("%s[%d].%s.%s", FieldResources, i, FieldDiscovery, FieldItem),
+						"item is only allowed for a manifest of kind List")
+				}
 			}
 		}
 	}
 }
 
+// validateListDiscovery checks that the discovery of a List manifest selects one
+// of its items, and selects it explicitly when the items are of several kinds
+func (v *TaskConfigValidator) validateListDiscovery(discovery *DiscoveryConfig, items []interface{}, path string) {
+	if discovery == nil {
+		return
+	}
+	// Items are counted like they are applied, without the empty ones
+	count := 0
+	kinds := make(map[string]bool)
+	for _, item := range items {
+		if itemManifest, ok := item.(map[string]interface{}); ok && len(itemManifest) > 0 {
+			count++
+			kinds[fmt.Sprintf("%v/%v", itemManifest[FieldAPIVersion], itemManifest[FieldKind])] = true
+		}
+	}
+	if discovery.Item != nil {
+		if *discovery.Item >= count {
+			v.errors.Add(path+"."+FieldItem, fmt.Sprintf("item %d is out of range: the list has %d items",
+				*discovery.Item, count))
+		}
+		return
+	}
+	if len(kinds) > 1 {
+		v.errors.Add(path+"."+FieldItem, "item is required for a list of several kinds, to select the item discovered")
+	}
+}
+
+// listItems returns the items of a v1 List manifest
+func listItems(manifest map[string]interface{}) ([]interface{}, bool) {
+	if manifest[FieldAPIVersion] != "v1" || manifest[FieldKind] != KindList {
+		return nil, false
+	}
+	items, _ := manifest[FieldItems].([]interface{})
+	return items, true
+}
+
 func (v *TaskConfigValidator) validateK8sManifest(manifest map[string]interface{}, path string) {
 	requiredFields := []string{FieldAPIVersion, FieldKind, "metadata"}
 
@@ -611,6 +1553,155 @@ func isSliceOrArray(value interface{}) bool {
 	return kind == reflect.Slice || kind == reflect.Array
 }
 
+// ValidateAPITargets validates that the API calls with a literal target name
+// select a target configured in clients.hyperfleet_api.targets. Templated targets
+// are resolved per event and checked at execution.
+func ValidateAPITargets(config *Config) error {
+	targets := hyperfleetapi.TargetNames(config.Clients.HyperfleetAPI.Targets)
+	errs := &ValidationErrors{}
+	check := func(apiCall *APICall, path string) {
+		if apiCall == nil || apiCall.Target == "" || strings.Contains(apiCall.Target, "{{") {
+			return
+		}
+		if !slices.Contains(targets, apiCall.Target) {
+			errs.Add(path+"."+FieldTarget, fmt.Sprintf("unknown HyperFleet API target %q (valid targets: %s)",
+				apiCall.Target, strings.Join(targets, ", ")))
+		}
+	}
+	checkPhases := func(preconditions []Precondition, post *PostConfig, prefix string) {
+		for i, precond := range preconditions {
+			check(precond.APICall, fmt.Sprintf("%s%s[%d].%s", prefix, FieldPreconditions, i, FieldAPICall))
+		}
+		if post != nil {
+			for i, action := range post.PostActions {
+				check(action.APICall, fmt.Sprintf("%s%s.%s[%d].%s", prefix, FieldPost, FieldPostActions, i, FieldAPICall))
+			}
+		}
+	}
+	checkPhases(config.Preconditions, config.Post, "")
+	for i, workflow := range config.Workflows {
+		checkPhases(workflow.Preconditions, workflow.Post, fmt.Sprintf("%s[%d].", FieldWorkflows, i))
+	}
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+// ValidateNotMetMaxDelay validates that the max delay of not_met_backoff, or its
+// default, does not exceed clients.broker.requeue.max_delay, which caps every
+// redelivery delay: the escalation would silently stop growing at the broker cap.
+func ValidateNotMetMaxDelay(config *Config) error {
+	backoff := config.NotMetBackoff
+	if backoff == nil {
+		return nil
+	}
+	notMetMax := backoff.MaxDelay
+	if notMetMax <= 0 {
+		notMetMax = DefaultRequeueMaxDelay
+	}
+	requeueMax := DefaultRequeueMaxDelay
+	if rq := config.Clients.Broker.Requeue; rq != nil && rq.MaxDelay > 0 {
+		requeueMax = rq.MaxDelay
+	}
+	if notMetMax <= requeueMax {
+		return nil
+	}
+	errs := &ValidationErrors{}
+	errs.Add(FieldNotMetBackoff+".max_delay", fmt.Sprintf(
+		"max delay %s exceeds clients.broker.requeue.max_delay %s, which caps every redelivery delay",
+		notMetMax, requeueMax))
+	return errs
+}
+
+// ValidateResourcePolicy validates the patterns of the resource policy and checks
+// the manifests of the resources against it where they are not templated: the
+// kind when apiVersion and kind are literal, the namespace when it is literal
+// or unset. Templated values are resolved per event and checked at execution.
+func ValidateResourcePolicy(config *Config) error {
+	policy := config.Policy
+	if policy == nil {
+		return nil
+	}
+	errs := &ValidationErrors{}
+	for i, pattern := range policy.AllowedNamespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs.Add(fmt.Sprintf("policy.%s[%d]", PolicyRuleAllowedNamespaces, i),
+				fmt.Sprintf("invalid namespace pattern %q: %v", pattern, err))
+		}
+	}
+	checkResources := func(resources []Resource, prefix string) {
+		for i, resource := range resources {
+			manifest, ok := resource.Manifest.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			manifestPath := fmt.Sprintf("%s%s[%d].%s", prefix, FieldResources, i, FieldManifest)
+			if resource.IsMaestroTransport() {
+				spec, _ := manifest["spec"].(map[string]interface{})
+				workload, _ := spec["workload"].(map[string]interface{})
+				workloadManifests, _ := workload["manifests"].([]interface{})
+				for j, m := range workloadManifests {
+					if object, ok := m.(map[string]interface{}); ok {
+						checkPolicyManifest(policy, object, fmt.Sprintf("%s.spec.workload.manifests[%d]", manifestPath, j), errs)
+					}
+				}
+				continue
+			}
+			checkPolicyManifest(policy, manifest, manifestPath, errs)
+		}
+	}
+	checkResources(config.Resources, "")
+	for i, workflow := range config.Workflows {
+		checkResources(workflow.Resources, fmt.Sprintf("%s[%d].", FieldWorkflows, i))
+	}
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+// checkPolicyManifest checks a manifest, or the items of a v1 List manifest,
+// against the resource policy where its values are not templated
+func checkPolicyManifest(
+	policy *ResourcePolicy, manifest map[string]interface{}, fieldPath string, errs *ValidationErrors,
+) {
+	if items, isList := listItems(manifest); isList {
+		for j, item := range items {
+			if itemManifest, ok := item.(map[string]interface{}); ok && len(itemManifest) > 0 {
+				checkPolicyManifest(policy, itemManifest, fmt.Sprintf("%s.%s[%d]", fieldPath, FieldItems, j), errs)
+			}
+		}
+		return
+	}
+	isLiteral := func(value interface{}) bool {
+		s, ok := value.(string)
+		return ok && !strings.Contains(s, "{{")
+	}
+	apiVersion, kind := manifest[FieldAPIVersion], manifest[FieldKind]
+	metadata, _ := manifest["metadata"].(map[string]interface{})
+	name, _ := metadata[FieldName].(string)
+	if isLiteral(apiVersion) && isLiteral(kind) {
+		if violation := policy.CheckKind(apiVersion.(string), kind.(string), name); violation != nil {
+			errs.Add(fieldPath+"."+FieldKind, violation.Error())
+			return
+		}
+	}
+	namespace, hasNamespace := metadata[FieldNamespace]
+	if hasNamespace && !isLiteral(namespace) {
+		return
+	}
+	namespaceValue, _ := namespace.(string)
+	if violation := policy.CheckNamespace(namespaceValue); violation != nil {
+		violation.Name = name
+		violation.Kind, _ = kind.(string)
+		if isLiteral(apiVersion) && isLiteral(kind) {
+			violation.Kind = PolicyKind(apiVersion.(string), kind.(string))
+		}
+		errs.Add(fieldPath+".metadata."+FieldNamespace, violation.Error())
+	}
+}
+
 // ValidateAdapterVersion validates that the config's adapter version is compatible
 // with the expected adapter version. Only major and minor versions are compared;
 // patch version differences are allowed (patch releases are bug fixes only).
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/criteria"
 	"github.com/stretchr/testify/assert"
@@ -153,6 +154,30 @@ func TestValidateTemplateVariables(t *testing.T) {
 		require.NoError(t, v.ValidateSemantic())
 	})
 
+	t.Run("optional param without default is a warning", func(t *testing.T) {
+		cfg := baseTaskConfig()
+		cfg.Params = []Parameter{
+			{Name: "clusterId", Source: "event.id", Required: true},
+			{Name: "region", Source: "env.REGION", Default: "us-east-1"},
+			{Name: "apiUrl", Source: "env.API_URL"},
+		}
+		cfg.Preconditions = []Precondition{{
+			ActionBase: ActionBase{
+				Name: "checkCluster",
+				APICall: &APICall{
+					Method: "GET",
+					URL:    "{{ .apiUrl }}/clusters/{{ .clusterId }}?region={{ .region }}",
+				},
+			},
+		}}
+		v := newTaskValidator(cfg)
+		require.NoError(t, v.ValidateStructure())
+		require.NoError(t, v.ValidateSemantic())
+		require.Equal(t, 1, v.Warnings().Count())
+		assert.Equal(t, "preconditions[0].api_call.url", v.Warnings().Errors[0].Path)
+		assert.Contains(t, v.Warnings().First(), `param "apiUrl"`)
+	})
+
 	t.Run("undefined variable in URL", func(t *testing.T) {
 		cfg := baseTaskConfig()
 		cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}}
@@ -191,6 +216,31 @@ func TestValidateTemplateVariables(t *testing.T) {
 		assert.Contains(t, err.Error(), "undefined template variable \"undefinedVar\"")
 	})
 
+	t.Run("k8s_patch post action", func(t *testing.T) {
+		cfg := baseTaskConfig()
+		cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}}
+		cfg.Post = &PostConfig{PostActions: []PostAction{{
+			ActionBase: ActionBase{Name: "annotate"},
+			K8sPatch: &K8sPatchAction{
+				APIVersion: "hyperfleet.io/v1",
+				Kind:       "Cluster",
+				Name:       "{{ .clusterId }}",
+				PatchType:  PatchTypeMerge,
+				Body:       `{"metadata":{"annotations":{"hyperfleet.io/last-event":"{{ .undefinedVar }}"}}}`,
+			},
+		}}}
+		v := newTaskValidator(cfg)
+		require.NoError(t, v.ValidateStructure())
+		err := v.ValidateSemantic()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "post.post_actions[0].k8s_patch.body")
+		assert.Contains(t, err.Error(), "undefined template variable \"undefinedVar\"")
+
+		cfg.Post.PostActions[0].K8sPatch.PatchType = "apply"
+		v = newTaskValidator(cfg)
+		assert.Error(t, v.ValidateStructure(), "patch_type must be merge, json or strategic")
+	})
+
 	t.Run("captured variable is available for resources", func(t *testing.T) {
 		cfg := baseTaskConfig()
 		cfg.Params = []Parameter{{Name: "apiUrl", Source: "env.API_URL"}}
@@ -219,6 +269,99 @@ func TestValidateTemplateVariables(t *testing.T) {
 	})
 }
 
+func TestValidateRequiredParams(t *testing.T) {
+	cfg := baseTaskConfig()
+	cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}}
+	cfg.RequiredParams = []string{"clusterId", "event.generation"}
+	v := newTaskValidator(cfg)
+	require.NoError(t, v.ValidateStructure())
+	require.NoError(t, v.ValidateSemantic())
+
+	cfg.RequiredParams = []string{"clusterId", "consumerName", "event."}
+	v = newTaskValidator(cfg)
+	require.NoError(t, v.ValidateStructure())
+	err := v.ValidateSemantic()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `required_params[1]: "consumerName" is neither a declared param nor an event.* path`)
+	assert.Contains(t, err.Error(), `required_params[2]`)
+
+	cfg.RequiredParams = []string{"clusterId", "clusterId"}
+	v = newTaskValidator(cfg)
+	assert.Error(t, v.ValidateStructure(), "duplicate entries are rejected")
+}
+
+func TestValidateParamValueFrom(t *testing.T) {
+	fieldRef := func(name, fieldPath string) *ParamValueFrom {
+		return &ParamValueFrom{K8sFieldRef: &K8sFieldRef{
+			ObjectRef: ObjectRef{APIVersion: "hive.openshift.io/v1", Kind: "ClusterDeployment",
+				Namespace: "{{ .clusterId }}", Name: name},
+			FieldPath: fieldPath,
+		}}
+	}
+	cfg := baseTaskConfig()
+	cfg.Params = []Parameter{
+		{Name: "clusterId", Source: "event.id"},
+		{Name: "infraId", ValueFrom: fieldRef("cd-{{ .clusterId }}", "spec.clusterMetadata.infraID")},
+	}
+	v := newTaskValidator(cfg)
+	require.NoError(t, v.ValidateStructure())
+	require.NoError(t, v.ValidateSemantic())
+
+	cfg.Params = []Parameter{
+		{Name: "infraId", ValueFrom: fieldRef("cd-{{ .clusterId }}", "spec.clusterMetadata.infraID")},
+		{Name: "region", ValueFrom: fieldRef("{{ .region }}", "{.spec.platform[")},
+		{Name: "phase", ValueFrom: fieldRef("{{ .clusterPhase }}", "status.phase")},
+		{Name: "clusterId", Source: "event.id"},
+	}
+	cfg.Preconditions = []Precondition{{
+		ActionBase: ActionBase{Name: "clusterStatus", APICall: &APICall{Method: "GET", URL: "/clusters"}},
+		Capture:    []CaptureField{{Name: "clusterPhase", FieldExpressionDef: FieldExpressionDef{Field: "status.phase"}}},
+	}}
+	v = newTaskValidator(cfg)
+	require.NoError(t, v.ValidateStructure())
+	err := v.ValidateSemantic()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `params[0].value_from.k8s_field_ref.name: `+
+		`param "clusterId" is declared after param "infraId": params are extracted in order`)
+	assert.Contains(t, err.Error(), `params[1].value_from.k8s_field_ref.name: param "region" references itself`)
+	assert.Contains(t, err.Error(), `params[1].value_from.k8s_field_ref.field_path`)
+	assert.Contains(t, err.Error(), `params[2].value_from.k8s_field_ref.name: `+
+		`"clusterPhase" is not available during param extraction`)
+
+	cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id", ValueFrom: fieldRef("cd", "metadata.name")}}
+	v = newTaskValidator(cfg)
+	assert.Error(t, v.ValidateStructure(), "source and value_from are exclusive")
+}
+
+func TestValidateVars(t *testing.T) {
+	cfg := baseTaskConfig()
+	cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}}
+	cfg.Vars = map[string]interface{}{
+		"team":   "platform",
+		"labels": map[string]interface{}{"environment": "prod"},
+	}
+	cfg.Preconditions = []Precondition{{
+		ActionBase: ActionBase{
+			Name:    "check",
+			APICall: &APICall{Method: "GET", URL: "/teams/{{ .team }}/clusters/{{ .clusterId }}"},
+		},
+		Expression: `labels.environment == "prod"`,
+	}}
+	v := newTaskValidator(cfg)
+	require.NoError(t, v.ValidateStructure())
+	require.NoError(t, v.ValidateSemantic(), "vars are defined variables of templates and CEL")
+
+	cfg.Vars = map[string]interface{}{"clusterId": "c1", "adapter": "a", "bad-name": 1, "team": "platform"}
+	v = newTaskValidator(cfg)
+	require.NoError(t, v.ValidateStructure())
+	err := v.ValidateSemantic()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `vars.clusterId: "clusterId" collides with a declared param of the same name`)
+	assert.Contains(t, err.Error(), `vars.adapter: "adapter" collides with a built-in variable`)
+	assert.Contains(t, err.Error(), `vars.bad-name: "bad-name" is not a valid variable name`)
+	assert.NotContains(t, err.Error(), "vars.team")
+}
+
 func TestValidateCELExpressions(t *testing.T) {
 	// Helper to create config with a CEL expression precondition
 	withExpression := func(expr string) *AdapterTaskConfig {
@@ -254,6 +397,975 @@ func TestValidateCELExpressions(t *testing.T) {
 	})
 }
 
+func TestValidateEnabledIf(t *testing.T) {
+	withEnabledIf := func(resourceExpr, actionExpr string) *AdapterTaskConfig {
+		cfg := baseTaskConfig()
+		cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}}
+		cfg.Post = &PostConfig{PostActions: []PostAction{{
+			ActionBase: ActionBase{Name: "report", Log: &LogAction{Message: "done"}},
+			EnabledIf:  actionExpr,
+		}}}
+		cfg.Resources = []Resource{{
+			Name: "testNs",
+			Manifest: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Namespace",
+				"metadata":   map[string]interface{}{"name": "ns-{{ .clusterId }}"},
+			},
+			Discovery: &DiscoveryConfig{Namespace: "*", ByName: "ns-{{ .clusterId }}"},
+			EnabledIf: resourceExpr,
+		}}
+		return cfg
+	}
+
+	t.Run("valid flag() expressions", func(t *testing.T) {
+		v := newTaskValidator(withEnabledIf(`flag("apply")`, `flag("report") && clusterPhase == "Ready"`))
+		require.NoError(t, v.ValidateStructure())
+		require.NoError(t, v.ValidateSemantic())
+	})
+
+	t.Run("invalid CEL in a resource", func(t *testing.T) {
+		v := newTaskValidator(withEnabledIf(`flag("apply") ====`, ""))
+		require.NoError(t, v.ValidateStructure())
+		err := v.ValidateSemantic()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "resources[0].enabled_if")
+	})
+
+	t.Run("invalid CEL in a post action", func(t *testing.T) {
+		v := newTaskValidator(withEnabledIf("", `flag("report") ====`))
+		require.NoError(t, v.ValidateStructure())
+		err := v.ValidateSemantic()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "post.post_actions[0].enabled_if")
+	})
+}
+
+func TestValidateLogActions(t *testing.T) {
+	withLog := func(log *LogAction) *AdapterTaskConfig {
+		cfg := baseTaskConfig()
+		cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}}
+		cfg.Preconditions = []Precondition{{ActionBase: ActionBase{Name: "check", Log: log}, Expression: "true"}}
+		return cfg
+	}
+
+	t.Run("valid levels, fields and sampling", func(t *testing.T) {
+		for _, level := range []string{"", "debug", "info", "warn", "warning", "error"} {
+			cfg := withLog(&LogAction{
+				Message: "checking {{ .clusterId }}",
+				Level:   level,
+				Fields: map[string]interface{}{
+					"cluster": "{{ .clusterId }}",
+					"length":  map[string]interface{}{"expression": "size(clusterId)"},
+				},
+				SampleEvery: 10,
+			})
+			v := newTaskValidator(cfg)
+			require.NoError(t, v.ValidateStructure(), "level %q", level)
+			require.NoError(t, v.ValidateSemantic(), "level %q", level)
+		}
+	})
+
+	t.Run("invalid level", func(t *testing.T) {
+		err := newTaskValidator(withLog(&LogAction{Message: "checking", Level: "verbose"})).ValidateStructure()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "level")
+	})
+
+	t.Run("negative sample_every", func(t *testing.T) {
+		err := newTaskValidator(withLog(&LogAction{Message: "checking", SampleEvery: -1})).ValidateStructure()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "sample_every")
+	})
+
+	t.Run("undefined variable in a field", func(t *testing.T) {
+		v := newTaskValidator(withLog(&LogAction{Fields: map[string]interface{}{"cluster": "{{ .undefined }}"}}))
+		require.NoError(t, v.ValidateStructure())
+		err := v.ValidateSemantic()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "undefined")
+	})
+
+	t.Run("invalid CEL in a field", func(t *testing.T) {
+		v := newTaskValidator(withLog(&LogAction{
+			Fields: map[string]interface{}{"broken": map[string]interface{}{"expression": "clusterId ==== 1"}},
+		}))
+		require.NoError(t, v.ValidateStructure())
+		err := v.ValidateSemantic()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "CEL parse error")
+	})
+}
+
+func TestValidateSkipIfUnchanged(t *testing.T) {
+	withAction := func(action PostAction) *AdapterTaskConfig {
+		cfg := baseTaskConfig()
+		cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}}
+		cfg.Post = &PostConfig{PostActions: []PostAction{action}}
+		return cfg
+	}
+	apiCall := &APICall{Method: "POST", URL: "/clusters/{{ .clusterId }}/statuses", Body: "{}"}
+
+	t.Run("valid", func(t *testing.T) {
+		v := newTaskValidator(withAction(PostAction{
+			ActionBase:      ActionBase{Name: "report", APICall: apiCall},
+			SkipIfUnchanged: &SkipIfUnchanged{Key: "{{ .clusterId }}", TTL: time.Minute},
+		}))
+		require.NoError(t, v.ValidateStructure())
+		require.NoError(t, v.ValidateSemantic())
+	})
+
+	t.Run("key is required", func(t *testing.T) {
+		err := newTaskValidator(withAction(PostAction{
+			ActionBase:      ActionBase{Name: "report", APICall: apiCall},
+			SkipIfUnchanged: &SkipIfUnchanged{},
+		})).ValidateStructure()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "key")
+	})
+
+	t.Run("requires an api_call", func(t *testing.T) {
+		v := newTaskValidator(withAction(PostAction{
+			ActionBase:      ActionBase{Name: "report", Log: &LogAction{Message: "done"}},
+			SkipIfUnchanged: &SkipIfUnchanged{Key: "{{ .clusterId }}"},
+		}))
+		require.NoError(t, v.ValidateStructure())
+		err := v.ValidateSemantic()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "skip_if_unchanged requires an api_call")
+	})
+
+	t.Run("undefined variable in the key", func(t *testing.T) {
+		v := newTaskValidator(withAction(PostAction{
+			ActionBase:      ActionBase{Name: "report", APICall: apiCall},
+			SkipIfUnchanged: &SkipIfUnchanged{Key: "{{ .undefined }}"},
+		}))
+		require.NoError(t, v.ValidateStructure())
+		err := v.ValidateSemantic()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "skip_if_unchanged.key")
+	})
+}
+
+func TestValidatePayloadCanonical(t *testing.T) {
+	withPayload := func(payload Payload) *AdapterTaskConfig {
+		cfg := baseTaskConfig()
+		cfg.Post = &PostConfig{Payloads: []Payload{payload}}
+		return cfg
+	}
+	build := map[string]interface{}{"status": "ok"}
+
+	require.NoError(t, newTaskValidator(withPayload(Payload{Name: "status", Build: build, Canonical: true})).
+		ValidateStructure())
+
+	err := newTaskValidator(withPayload(Payload{Name: "status", Build: build, Canonical: true, Structured: true})).
+		ValidateStructure()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "'canonical' and 'structured' are mutually exclusive")
+}
+
+func TestValidateBufferOnFailure(t *testing.T) {
+	withAction := func(action PostAction) *AdapterTaskConfig {
+		cfg := baseTaskConfig()
+		cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}}
+		cfg.Post = &PostConfig{PostActions: []PostAction{action}}
+		return cfg
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		v := newTaskValidator(withAction(PostAction{
+			ActionBase: ActionBase{Name: "report", APICall: &APICall{
+				Method: "POST", URL: "/clusters/{{ .clusterId }}/statuses", Body: "{}",
+			}},
+			BufferOnFailure: &BufferOnFailure{Enabled: true, MaxAge: time.Hour, MaxEntries: 10},
+		}))
+		require.NoError(t, v.ValidateStructure())
+		require.NoError(t, v.ValidateSemantic())
+	})
+
+	t.Run("requires an api_call", func(t *testing.T) {
+		v := newTaskValidator(withAction(PostAction{
+			ActionBase:      ActionBase{Name: "report", Log: &LogAction{Message: "done"}},
+			BufferOnFailure: &BufferOnFailure{Enabled: true},
+		}))
+		require.NoError(t, v.ValidateStructure())
+		err := v.ValidateSemantic()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "buffer_on_failure requires an api_call")
+	})
+}
+
+func TestValidateWebhook(t *testing.T) {
+	tests := []struct {
+		webhook *WebhookAction
+		name    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			webhook: &WebhookAction{
+				URL: "https://hooks.example.com/{{ .clusterId }}", Payload: "notification",
+				Signing: &WebhookSigning{Secret: "{{ .webhookSecret }}"}, Timeout: time.Second,
+			},
+		},
+		{name: "url is required", webhook: &WebhookAction{Payload: "notification"}, wantErr: "url is required"},
+		{
+			name:    "undefined payload",
+			webhook: &WebhookAction{URL: "https://hooks.example.com", Payload: "missing"},
+			wantErr: `payload "missing" is not a post payload`,
+		},
+		{
+			name: "undefined variable in the signing secret",
+			webhook: &WebhookAction{
+				URL: "https://hooks.example.com", Payload: "notification",
+				Signing: &WebhookSigning{Secret: "{{ .undefined }}"},
+			},
+			wantErr: "webhook.signing.secret",
+		},
+		{
+			name: "negative retry attempts",
+			webhook: &WebhookAction{
+				URL: "https://hooks.example.com", Payload: "notification", RetryAttempts: -1,
+			},
+			wantErr: "retry_attempts",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseTaskConfig()
+			cfg.Params = []Parameter{
+				{Name: "clusterId", Source: "event.id"},
+				{Name: "webhookSecret", Source: "secret.hooks.webhook.token", Sensitive: true},
+			}
+			cfg.Post = &PostConfig{
+				Payloads: []Payload{{Name: "notification", Build: map[string]interface{}{"text": "done"}}},
+				PostActions: []PostAction{{
+					ActionBase: ActionBase{Name: "notify"},
+					Webhook:    tt.webhook,
+				}},
+			}
+			v := newTaskValidator(cfg)
+			err := v.ValidateStructure()
+			if err == nil {
+				err = v.ValidateSemantic()
+			}
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateExecutionFence(t *testing.T) {
+	withFence := func(fence *ExecutionFence) *AdapterTaskConfig {
+		cfg := baseTaskConfig()
+		cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}}
+		cfg.ExecutionFence = fence
+		return cfg
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		v := newTaskValidator(withFence(&ExecutionFence{Key: "{{ .clusterId }}", Timeout: time.Minute}))
+		require.NoError(t, v.ValidateStructure())
+		require.NoError(t, v.ValidateSemantic())
+	})
+
+	t.Run("key is required", func(t *testing.T) {
+		err := newTaskValidator(withFence(&ExecutionFence{})).ValidateStructure()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "execution_fence.key is required")
+	})
+
+	t.Run("negative timeout", func(t *testing.T) {
+		err := newTaskValidator(withFence(&ExecutionFence{Key: "{{ .clusterId }}", Timeout: -time.Second})).
+			ValidateStructure()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "execution_fence.timeout")
+	})
+
+	t.Run("undefined variable in the key", func(t *testing.T) {
+		v := newTaskValidator(withFence(&ExecutionFence{Key: "{{ .undefined }}"}))
+		require.NoError(t, v.ValidateStructure())
+		err := v.ValidateSemantic()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "execution_fence.key")
+	})
+}
+
+func TestValidateNotMetBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		fence   *ExecutionFence
+		backoff *NotMetBackoff
+		wantErr string
+	}{
+		{name: "own key", backoff: &NotMetBackoff{Key: "{{ .clusterId }}", Threshold: 3}},
+		{name: "execution_fence key", fence: &ExecutionFence{Key: "{{ .clusterId }}"}, backoff: &NotMetBackoff{}},
+		{name: "no key", backoff: &NotMetBackoff{}, wantErr: "not_met_backoff.key"},
+		{name: "undefined variable in the key", backoff: &NotMetBackoff{Key: "{{ .undefined }}"},
+			wantErr: "not_met_backoff.key"},
+		{name: "base delay beyond max delay",
+			backoff: &NotMetBackoff{Key: "{{ .clusterId }}", BaseDelay: time.Hour, MaxDelay: time.Minute},
+			wantErr: "not_met_backoff.base_delay"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseTaskConfig()
+			cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}}
+			cfg.ExecutionFence = tt.fence
+			cfg.NotMetBackoff = tt.backoff
+			v := newTaskValidator(cfg)
+			require.NoError(t, v.ValidateStructure())
+			err := v.ValidateSemantic()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateIdempotency(t *testing.T) {
+	tests := []struct {
+		name        string
+		idempotency *Idempotency
+		wantErr     string
+	}{
+		{name: "memory store", idempotency: &Idempotency{GenerationParam: "generation", KeyParam: "clusterId"}},
+		{name: "configmap store", idempotency: &Idempotency{GenerationParam: "generation", KeyParam: "clusterId",
+			Store: IdempotencyStoreConfigMap, ConfigMapName: "generations", ConfigMapNamespace: "hyperfleet"}},
+		{name: "configmap store without name",
+			idempotency: &Idempotency{GenerationParam: "generation", KeyParam: "clusterId", Store: IdempotencyStoreConfigMap},
+			wantErr:     "idempotency.store: the configmap store requires configmap_name and configmap_namespace"},
+		{name: "undefined generation param", idempotency: &Idempotency{GenerationParam: "gen", KeyParam: "clusterId"},
+			wantErr: `idempotency.generation_param: "gen" is neither a param nor a capture`},
+		{name: "no key param", idempotency: &Idempotency{GenerationParam: "generation"},
+			wantErr: "idempotency.key_param is required"},
+		{name: "unknown tie policy", idempotency: &Idempotency{GenerationParam: "generation", KeyParam: "clusterId",
+			OnTie: "ignore"}, wantErr: `idempotency.on_tie "ignore" is invalid`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseTaskConfig()
+			cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}, {Name: "generation", Source: "event.generation"}}
+			cfg.Idempotency = tt.idempotency
+			v := newTaskValidator(cfg)
+			err := v.ValidateStructure()
+			if err == nil {
+				err = v.ValidateSemantic()
+			}
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateSubjectPatterns(t *testing.T) {
+	tests := []struct {
+		name        string
+		patterns    []string
+		optional    bool
+		url         string
+		wantErr     string
+		wantWarning string
+	}{
+		{name: "patterns", patterns: []string{"tenants/{tenant}/clusters/{clusterId}", "clusters/{clusterId}"},
+			url: "/clusters/{{ .clusterId }}?tenant={{ .tenant }}", wantWarning: `param "tenant"`},
+		{name: "wildcard", patterns: []string{"clusters/{clusterId}/{rest...}"}},
+		{name: "optional subject", patterns: []string{"clusters/{clusterId}"}, optional: true,
+			wantWarning: `param "clusterId"`},
+		{name: "optional without patterns", optional: true, url: "/clusters?region={{ .region }}",
+			wantWarning: "subject_optional has no effect without subject_patterns"},
+		{name: "invalid name", patterns: []string{"clusters/{clusterId}", "clusters/{cluster-id}"},
+			wantErr: `subject_patterns[1]: segment "{cluster-id}" of subject pattern "clusters/{cluster-id}" ` +
+				"is not a valid variable name"},
+		{name: "wildcard not last", patterns: []string{"clusters/{rest...}/status"},
+			wantErr: `wildcard segment "{rest...}" must be the last`},
+		{name: "partial segment", patterns: []string{"clusters/c-{clusterId}"},
+			wantErr: `segment "c-{clusterId}" of subject pattern "clusters/c-{clusterId}" must be a literal or a whole {name}`},
+		{name: "empty segment", patterns: []string{"clusters//{clusterId}"}, wantErr: "has an empty segment"},
+		{name: "duplicate name", patterns: []string{"{clusterId}/{clusterId}"},
+			wantErr: `segment name "clusterId" is used twice`},
+		{name: "collides with a param", patterns: []string{"regions/{region}/clusters/{clusterId}"},
+			wantErr: `subject_patterns[0]: segment "region" collides with a declared param of the same name`},
+		{name: "collides with a built-in", patterns: []string{"adapters/{adapter}/clusters/{clusterId}"},
+			wantErr: `segment "adapter" collides with a built-in variable of the same name`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseTaskConfig()
+			cfg.Params = []Parameter{{Name: "region", Source: "env.REGION"}}
+			cfg.SubjectPatterns = tt.patterns
+			cfg.SubjectOptional = tt.optional
+			url := tt.url
+			if url == "" {
+				url = "/clusters/{{ .clusterId }}"
+			}
+			cfg.Preconditions = []Precondition{{
+				ActionBase: ActionBase{Name: "checkCluster", APICall: &APICall{Method: "GET", URL: url}},
+			}}
+			v := newTaskValidator(cfg)
+			err := v.ValidateStructure()
+			if err == nil {
+				err = v.ValidateSemantic()
+			}
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+			if tt.wantWarning != "" {
+				assert.Contains(t, v.Warnings().Error(), tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	window := ScheduleWindow{Start: "0 22 * * FRI", Duration: 4 * time.Hour}
+	tests := []struct {
+		name     string
+		schedule *Schedule
+		wantErr  string
+	}{
+		{name: "defer in a time zone", schedule: &Schedule{Timezone: "Europe/Berlin", Windows: []ScheduleWindow{window}}},
+		{name: "skipResources", schedule: &Schedule{Policy: SchedulePolicySkipResources, Windows: []ScheduleWindow{window}}},
+		{name: "unknown time zone", schedule: &Schedule{Timezone: "Mars/Olympus", Windows: []ScheduleWindow{window}},
+			wantErr: `schedule.timezone: unknown time zone "Mars/Olympus"`},
+		{name: "invalid start", schedule: &Schedule{Windows: []ScheduleWindow{window, {Start: "0 22 * *", Duration: time.Hour}}},
+			wantErr: "schedule.windows[1].start"},
+		{name: "unknown policy", schedule: &Schedule{Policy: "later", Windows: []ScheduleWindow{window}},
+			wantErr: `schedule.policy "later" is invalid`},
+		{name: "no windows", schedule: &Schedule{}, wantErr: "schedule.windows is required"},
+		{name: "zero duration", schedule: &Schedule{Windows: []ScheduleWindow{{Start: "0 22 * * *"}}},
+			wantErr: "schedule.windows[0].duration: must be greater than 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseTaskConfig()
+			cfg.Schedule = tt.schedule
+			v := newTaskValidator(cfg)
+			err := v.ValidateStructure()
+			if err == nil {
+				err = v.ValidateSemantic()
+			}
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateFinalizer(t *testing.T) {
+	on := ObjectRef{APIVersion: "hyperfleet.io/v1", Kind: "Cluster", Name: "{{ .clusterId }}"}
+	tests := []struct {
+		name        string
+		finalizer   *Finalizer
+		wantErr     string
+		wantWarning string
+	}{
+		{name: "teardown workflow", finalizer: &Finalizer{Name: "hyperfleet.io/cleanup", On: on, TeardownWorkflow: "teardown"}},
+		{name: "undefined teardown workflow",
+			finalizer: &Finalizer{Name: "hyperfleet.io/cleanup", On: on, TeardownWorkflow: "delete"},
+			wantErr:   `finalizer.teardown_workflow: workflow "delete" is not defined`},
+		{name: "undefined variable in the object name",
+			finalizer: &Finalizer{Name: "hyperfleet.io/cleanup", TeardownWorkflow: "teardown",
+				On: ObjectRef{APIVersion: "v1", Kind: "ConfigMap", Name: "{{ .undefined }}"}},
+			wantErr: "finalizer.on.name"},
+		{name: "no object kind",
+			finalizer: &Finalizer{Name: "hyperfleet.io/cleanup", TeardownWorkflow: "teardown",
+				On: ObjectRef{APIVersion: "v1", Name: "cluster"}},
+			wantErr: "finalizer.on.kind is required"},
+		{name: "finalizer name without a domain",
+			finalizer:   &Finalizer{Name: "cleanup", On: on, TeardownWorkflow: "teardown"},
+			wantWarning: `finalizer "cleanup" is not domain-qualified`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseTaskConfig()
+			cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}}
+			cfg.Workflows = []Workflow{
+				{Name: "teardown", Match: &WorkflowMatch{EventTypes: []string{"cluster.deleted"}}},
+				{Name: "reconcile"},
+			}
+			cfg.Finalizer = tt.finalizer
+			v := newTaskValidator(cfg)
+			err := v.ValidateStructure()
+			if err == nil {
+				err = v.ValidateSemantic()
+			}
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+			if tt.wantWarning != "" {
+				assert.Contains(t, v.Warnings().Error(), tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestValidateResponseExpectations(t *testing.T) {
+	apiCall := &APICall{Method: "GET", URL: "/clusters/c1"}
+	tests := []struct {
+		name        string
+		apiCall     *APICall
+		expect      *ResponseExpectation
+		wantErr     string
+		wantWarning string
+	}{
+		{name: "dot and JSONPath paths", apiCall: apiCall, expect: &ResponseExpectation{Assertions: []ResponseAssertion{
+			{Path: "status.conditions[0].type", Type: "string", Required: true},
+			{Path: "{.items[*].id}", Type: "string"},
+		}}},
+		{name: "without an API call", expect: &ResponseExpectation{Assertions: []ResponseAssertion{{Path: "id"}}},
+			wantErr: "preconditions[0].expect: expect requires an api_call"},
+		{name: "invalid path", apiCall: apiCall,
+			expect:  &ResponseExpectation{Assertions: []ResponseAssertion{{Path: "items[0", Required: true}}},
+			wantErr: "preconditions[0].expect.assertions[0].path: invalid field path 'items[0'"},
+		{name: "unknown type", apiCall: apiCall,
+			expect:  &ResponseExpectation{Assertions: []ResponseAssertion{{Path: "id", Type: "integer"}}},
+			wantErr: "type"},
+		{name: "no assertions", apiCall: apiCall, expect: &ResponseExpectation{WarnOnly: true},
+			wantErr: "assertions"},
+		{name: "assertion asserting nothing", apiCall: apiCall,
+			expect:      &ResponseExpectation{Assertions: []ResponseAssertion{{Path: "id"}}},
+			wantWarning: "preconditions[0].expect.assertions[0]: asserts nothing: set a type or required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseTaskConfig()
+			cfg.Preconditions = []Precondition{{
+				ActionBase: ActionBase{Name: "cluster", APICall: tt.apiCall},
+				Expression: "true",
+				Expect:     tt.expect,
+			}}
+			v := newTaskValidator(cfg)
+			err := v.ValidateStructure()
+			if err == nil {
+				err = v.ValidateSemantic()
+			}
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+			if tt.wantWarning != "" {
+				assert.Contains(t, v.Warnings().Error(), tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestValidateWorkflows(t *testing.T) {
+	deleted := &WorkflowMatch{EventTypes: []string{"cluster.deleted"}}
+	tests := []struct {
+		name      string
+		topLevel  []Precondition
+		workflows []Workflow
+		wantErr   string
+	}{
+		{name: "matched and default workflows", workflows: []Workflow{
+			{Name: "teardown", Match: deleted},
+			{Name: "reconcile"},
+		}},
+		{name: "matched workflows with the top-level default",
+			topLevel: []Precondition{{ActionBase: ActionBase{Name: "ready"}, Expression: "true"}},
+			workflows: []Workflow{
+				{Name: "teardown", Match: deleted},
+				{Name: "paused", Match: &WorkflowMatch{Expression: "clusterId == 'paused'"}},
+			}},
+		{name: "two default workflows", workflows: []Workflow{{Name: "a"}, {Name: "b"}},
+			wantErr: `workflows[1].match: workflows "a" and "b" are both the default`},
+		{name: "default workflow with top-level phases",
+			topLevel:  []Precondition{{ActionBase: ActionBase{Name: "ready"}, Expression: "true"}},
+			workflows: []Workflow{{Name: "reconcile"}},
+			wantErr:   "workflows[0].match: the top-level preconditions"},
+		{name: "reserved name", workflows: []Workflow{{Name: DefaultWorkflowName, Match: deleted}},
+			wantErr: `workflows[0].name: "default" is reserved`},
+		{name: "empty match", workflows: []Workflow{{Name: "teardown", Match: &WorkflowMatch{}}},
+			wantErr: "match requires event_types or expression"},
+		{name: "invalid match expression",
+			workflows: []Workflow{{Name: "teardown", Match: &WorkflowMatch{Expression: "clusterId =="}}},
+			wantErr:   "workflows[0].match.expression"},
+		{name: "event type selecting two workflows",
+			workflows: []Workflow{{Name: "teardown", Match: deleted}, {Name: "cleanup", Match: deleted}},
+			wantErr:   `event type "cluster.deleted" also selects workflow "teardown"`},
+		{name: "event type narrowed by an expression", workflows: []Workflow{
+			{Name: "teardown", Match: deleted},
+			{Name: "orphaned", Match: &WorkflowMatch{EventTypes: deleted.EventTypes, Expression: "clusterId == 'x'"}},
+		}},
+		{name: "invalid workflow phase", workflows: []Workflow{{
+			Name: "teardown", Match: deleted,
+			Preconditions: []Precondition{{ActionBase: ActionBase{Name: "gone"}, Expression: "clusterId =="}},
+		}}, wantErr: "workflows[0].preconditions[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseTaskConfig()
+			cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}}
+			cfg.Preconditions = tt.topLevel
+			cfg.Workflows = tt.workflows
+			v := newTaskValidator(cfg)
+			require.NoError(t, v.ValidateStructure())
+			err := v.ValidateSemantic()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidatePayloadConditions(t *testing.T) {
+	ready := StatusCondition{Type: "Ready", Status: `clusterId != ""`, Reason: "Reconciled", Message: "{{ .clusterId }}"}
+	tests := []struct {
+		name       string
+		build      interface{}
+		conditions *StatusConditions
+		wantErr    string
+	}{
+		{name: "valid conditions",
+			conditions: &StatusConditions{Previous: "currentStatus.conditions", Items: []StatusCondition{ready}}},
+		{name: "jsonpath previous", conditions: &StatusConditions{
+			Previous: "{.currentStatus.conditions}", Items: []StatusCondition{ready}}},
+		{name: "no items", conditions: &StatusConditions{}, wantErr: "items"},
+		{name: "missing status", conditions: &StatusConditions{Items: []StatusCondition{{Type: "Ready"}}},
+			wantErr: "status"},
+		{name: "duplicate type", conditions: &StatusConditions{Items: []StatusCondition{ready, ready}},
+			wantErr: `post.payloads[0].conditions.items[1].type: duplicate condition type "Ready"`},
+		{name: "invalid status expression",
+			conditions: &StatusConditions{Items: []StatusCondition{{Type: "Ready", Status: "clusterId =="}}},
+			wantErr:    "post.payloads[0].conditions.items[0].status: CEL parse error"},
+		{name: "undefined reason variable", conditions: &StatusConditions{
+			Items: []StatusCondition{{Type: "Ready", Status: "true", Reason: "{{ .missing }}"}}},
+			wantErr: `post.payloads[0].conditions.items[0].reason: undefined template variable "missing"`},
+		{name: "undefined previous variable",
+			conditions: &StatusConditions{Previous: "missing.conditions", Items: []StatusCondition{ready}},
+			wantErr:    `post.payloads[0].conditions.previous: undefined variable "missing.conditions"`},
+		{name: "key also set by build", build: map[string]interface{}{"conditions": []interface{}{}},
+			conditions: &StatusConditions{Items: []StatusCondition{ready}},
+			wantErr:    `post.payloads[0].conditions.key: key "conditions" is also set by build`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseTaskConfig()
+			cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}}
+			cfg.Preconditions = []Precondition{{
+				ActionBase:        ActionBase{Name: "getStatus", APICall: &APICall{Method: "GET", URL: "/status"}},
+				CaptureResponseAs: "currentStatus",
+			}}
+			build := tt.build
+			if build == nil {
+				build = map[string]interface{}{"adapter": "test"}
+			}
+			cfg.Post = &PostConfig{Payloads: []Payload{{Name: "statusPayload", Build: build, Conditions: tt.conditions}}}
+			v := newTaskValidator(cfg)
+			err := v.ValidateStructure()
+			if err == nil {
+				err = v.ValidateSemantic()
+			}
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateOnError(t *testing.T) {
+	tests := []struct {
+		name     string
+		valueDef map[string]interface{}
+		wantErr  string
+	}{
+		{name: "omit", valueDef: map[string]interface{}{"field": "clusterId", "on_error": "omit"}},
+		{name: "fail", valueDef: map[string]interface{}{"expression": "clusterId", "on_error": "fail"}},
+		{name: "default with a default",
+			valueDef: map[string]interface{}{"field": "clusterId", "on_error": "default", "default": "none"}},
+		{name: "unquoted null", valueDef: map[string]interface{}{"field": "clusterId", "on_error": nil}},
+		{name: "unknown policy", valueDef: map[string]interface{}{"field": "clusterId", "on_error": "skip"},
+			wantErr: `post.payloads[0].build.spec.value.on_error: invalid on_error "skip"`},
+		{name: "default without a default",
+			valueDef: map[string]interface{}{"field": "clusterId", "on_error": "default"},
+			wantErr:  "post.payloads[0].build.spec.value.on_error: on_error: default requires a default value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseTaskConfig()
+			cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}}
+			cfg.Post = &PostConfig{Payloads: []Payload{{
+				Name:  "statusPayload",
+				Build: map[string]interface{}{"spec": map[string]interface{}{"value": tt.valueDef}},
+			}}}
+			v := newTaskValidator(cfg)
+			err := v.ValidateStructure()
+			if err == nil {
+				err = v.ValidateSemantic()
+			}
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateResponseTransform(t *testing.T) {
+	tests := []struct {
+		name       string
+		transform  string
+		postAction bool
+		wantErr    string
+	}{
+		{name: "valid", transform: `response.items.filter(i, i.kind == "nodepool")`},
+		{name: "parse error", transform: "response.data ==",
+			wantErr: "preconditions[0].api_call.transform: CEL parse error"},
+		{name: "on a post action", transform: "response.data", postAction: true,
+			wantErr: "post.post_actions[0].api_call.transform: transform only applies to the api_call of a precondition"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseTaskConfig()
+			apiCall := &APICall{Method: "GET", URL: "/clusters", Transform: tt.transform}
+			if tt.postAction {
+				cfg.Post = &PostConfig{PostActions: []PostAction{{ActionBase: ActionBase{Name: "report", APICall: apiCall}}}}
+			} else {
+				cfg.Preconditions = []Precondition{{ActionBase: ActionBase{Name: "getClusters", APICall: apiCall}}}
+			}
+			v := newTaskValidator(cfg)
+			err := v.ValidateStructure()
+			if err == nil {
+				err = v.ValidateSemantic()
+			}
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateEventFilter(t *testing.T) {
+	tests := []struct {
+		name             string
+		contentTypes     []string
+		plainTextDataKey string
+		wantErr          string
+	}{
+		{name: "valid", contentTypes: []string{"application/json", "text/plain; charset=utf-8"}, plainTextDataKey: "raw"},
+		{name: "not a media type", contentTypes: []string{"json"}, wantErr: "event_filter.data_content_types[0]"},
+		{name: "invalid plain text key", plainTextDataKey: "raw-text", wantErr: "plain_text_data_key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseTaskConfig()
+			cfg.EventFilter = &EventFilter{DataContentTypes: tt.contentTypes}
+			cfg.PlainTextDataKey = tt.plainTextDataKey
+			v := newTaskValidator(cfg)
+			require.NoError(t, v.ValidateStructure())
+			err := v.ValidateSemantic()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateAPICallBodyType(t *testing.T) {
+	tests := []struct {
+		name    string
+		apiCall APICall
+		wantErr string
+	}{
+		{
+			name: "form fields",
+			apiCall: APICall{BodyType: BodyTypeForm, FormFields: []FormField{
+				{Name: "cluster", Value: "{{ .clusterId }}"},
+			}},
+		},
+		{
+			name: "multipart parts",
+			apiCall: APICall{BodyType: BodyTypeMultipart, Parts: []MultipartPart{
+				{Name: "kubeconfig", Filename: "kubeconfig.yaml", Content: "cluster: {{ .clusterId }}"},
+			}},
+		},
+		{
+			name:    "form with a raw body",
+			apiCall: APICall{BodyType: BodyTypeForm, Body: `{"id":"{{ .clusterId }}"}`},
+			wantErr: "cannot be combined with a body",
+		},
+		{
+			name:    "multipart with a raw body",
+			apiCall: APICall{BodyType: BodyTypeMultipart, Body: `{"id":"{{ .clusterId }}"}`},
+			wantErr: "cannot be combined with a body",
+		},
+		{
+			name:    "form fields without body_type",
+			apiCall: APICall{FormFields: []FormField{{Name: "cluster", Value: "x"}}},
+			wantErr: "form_fields require body_type form",
+		},
+		{
+			name:    "undefined variable in a form field",
+			apiCall: APICall{BodyType: BodyTypeForm, FormFields: []FormField{{Name: "cluster", Value: "{{ .undefined }}"}}},
+			wantErr: "form_fields[0].value",
+		},
+		{
+			name:    "undefined variable in a part",
+			apiCall: APICall{BodyType: BodyTypeMultipart, Parts: []MultipartPart{{Name: "kc", Content: "{{ .undefined }}"}}},
+			wantErr: "parts[0].content",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseTaskConfig()
+			cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}}
+			apiCall := tt.apiCall
+			apiCall.Method = "POST"
+			apiCall.URL = "/legacy"
+			cfg.Post = &PostConfig{PostActions: []PostAction{{ActionBase: ActionBase{Name: "report", APICall: &apiCall}}}}
+			v := newTaskValidator(cfg)
+			require.NoError(t, v.ValidateStructure())
+			err := v.ValidateSemantic()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateAPICallBodyPayloads(t *testing.T) {
+	newConfig := func(apiCall APICall) *AdapterTaskConfig {
+		cfg := baseTaskConfig()
+		apiCall.Method = "POST"
+		apiCall.URL = "/status"
+		cfg.Post = &PostConfig{
+			Payloads: []Payload{
+				{Name: "statusPayload", Build: map[string]interface{}{"status": "True"}},
+				{Name: "detailsPayload", Build: map[string]interface{}{"details": "none"}},
+			},
+			PostActions: []PostAction{{ActionBase: ActionBase{Name: "report", APICall: &apiCall}}},
+		}
+		return cfg
+	}
+
+	v := newTaskValidator(newConfig(APICall{BodyMerge: []string{"statusPayload", "detailsPayload"}}))
+	require.NoError(t, v.ValidateStructure())
+	require.NoError(t, v.ValidateSemantic())
+
+	for _, apiCall := range []APICall{
+		{Body: "{}", BodyFrom: "statusPayload"},
+		{Body: "{}", BodyMerge: []string{"statusPayload"}},
+		{BodyFrom: "statusPayload", BodyMerge: []string{"detailsPayload"}},
+	} {
+		v = newTaskValidator(newConfig(apiCall))
+		err := v.ValidateStructure()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "are mutually exclusive")
+	}
+
+	cfg := newConfig(APICall{BodyFrom: "unknownPayload", BodyType: BodyTypeForm})
+	cfg.Preconditions = []Precondition{{
+		ActionBase: ActionBase{Name: "notify", APICall: &APICall{Method: "POST", URL: "/notify", BodyFrom: "statusPayload"}},
+	}}
+	v = newTaskValidator(cfg)
+	require.NoError(t, v.ValidateStructure())
+	err := v.ValidateSemantic()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		`post.post_actions[0].api_call.body_from: payload "unknownPayload" is not a post payload`)
+	assert.Contains(t, err.Error(), "body_type form cannot be combined with body_from or body_merge")
+	assert.Contains(t, err.Error(),
+		`preconditions[0].api_call.body_from: payload "statusPayload" is built after the preconditions run`)
+}
+
+func TestValidateCaptureResponseAs(t *testing.T) {
+	apiCall := &APICall{Method: "GET", URL: "/clusters/{{ .clusterId }}"}
+	withCapture := func(name string, call *APICall) *AdapterTaskConfig {
+		cfg := baseTaskConfig()
+		cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}}
+		cfg.Vars = map[string]interface{}{"team": "platform"}
+		cfg.Preconditions = []Precondition{
+			{
+				ActionBase: ActionBase{Name: "getCluster", APICall: apiCall},
+				Capture:    []CaptureField{{Name: "phase", FieldExpressionDef: FieldExpressionDef{Field: "status.phase"}}},
+			},
+			{ActionBase: ActionBase{Name: "getRecord", APICall: call}, CaptureResponseAs: name},
+			{
+				ActionBase: ActionBase{Name: "recordReady"},
+				Conditions: []Condition{{Field: "clusterRecord.status.phase", Operator: "equals", Value: "Ready"}},
+			},
+		}
+		return cfg
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		v := newTaskValidator(withCapture("clusterRecord", apiCall))
+		require.NoError(t, v.ValidateStructure())
+		require.NoError(t, v.ValidateSemantic(), "later steps can reference the capture")
+	})
+
+	tests := []struct {
+		name    string
+		capture string
+		apiCall *APICall
+		wantErr string
+	}{
+		{name: "param", capture: "clusterId", apiCall: apiCall, wantErr: `"clusterId" collides with a declared param`},
+		{name: "var", capture: "team", apiCall: apiCall, wantErr: `"team" collides with a var`},
+		{name: "capture", capture: "phase", apiCall: apiCall, wantErr: `"phase" collides with a capture`},
+		{
+			name: "precondition name", capture: "getCluster", apiCall: apiCall,
+			wantErr: `"getCluster" collides with a precondition name`,
+		},
+		{name: "built-in", capture: "adapter", apiCall: apiCall, wantErr: `"adapter" collides with a built-in variable`},
+		{name: "invalid name", capture: "cluster-record", apiCall: apiCall, wantErr: "is not a valid variable name"},
+		{name: "no api_call", capture: "clusterRecord", wantErr: "capture_response_as requires an api_call"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := withCapture(tt.capture, tt.apiCall)
+			if tt.apiCall == nil {
+				cfg.Preconditions[1].Expression = "true"
+			}
+			err := newTaskValidator(cfg).ValidateSemantic()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "preconditions[1].capture_response_as")
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
 func TestValidateK8sManifests(t *testing.T) {
 	// Helper to create config with a resource manifest
 	withResource := func(manifest map[string]interface{}) *AdapterTaskConfig {
@@ -532,6 +1644,36 @@ func TestValidateCaptureFields(t *testing.T) {
 		assert.Contains(t, err.Error(), "must have either")
 	})
 
+	t.Run("valid header capture", func(t *testing.T) {
+		cfg := withCapture([]CaptureField{
+			{Name: "totalCount", Source: CaptureSourceHeader, Header: "X-Total-Count", Default: "0"},
+			{Name: "requestId", Source: CaptureSourceHeader, Header: "X-Request-Id", Required: true},
+		})
+		v := newTaskValidator(cfg)
+		require.NoError(t, v.ValidateStructure())
+		require.NoError(t, v.ValidateSemantic())
+	})
+
+	t.Run("invalid header captures", func(t *testing.T) {
+		tests := []struct {
+			capture CaptureField
+			want    string
+		}{
+			{CaptureField{Name: "c", Source: CaptureSourceHeader}, "capture[0].header is required"},
+			{CaptureField{Name: "c", Source: CaptureSourceHeader, Header: "ETag",
+				FieldExpressionDef: FieldExpressionDef{Field: "etag"}}, "'field' and 'header' are mutually exclusive"},
+			{CaptureField{Name: "c", Header: "ETag"}, `capture[0].source "" (expected: "header")`},
+			{CaptureField{Name: "c", Source: "trailer", Header: "ETag"}, `"trailer" is invalid (allowed: body, header)`},
+			{CaptureField{Name: "c", Source: CaptureSourceHeader, Header: "ETag", Required: true, Default: "x"},
+				"'required' and 'default' are mutually exclusive"},
+		}
+		for _, tt := range tests {
+			err := newTaskValidator(withCapture([]CaptureField{tt.capture})).ValidateStructure()
+			require.Error(t, err, tt.want)
+			assert.Contains(t, err.Error(), tt.want)
+		}
+	})
+
 	t.Run("invalid - capture name missing", func(t *testing.T) {
 		cfg := withCapture([]CaptureField{{FieldExpressionDef: FieldExpressionDef{Field: "name"}}})
 		err := newTaskValidator(cfg).ValidateStructure()
@@ -814,6 +1956,43 @@ func TestValidateTransportConfig(t *testing.T) {
 		assert.Contains(t, err.Error(), "undefined template variable \"undefinedVar\"")
 	})
 
+	t.Run("maestro transport with manifest_work_metadata", func(t *testing.T) {
+		cfg := baseTaskConfig()
+		cfg.Resources = []Resource{{
+			Name: "testMW",
+			Transport: &TransportConfig{
+				Client: TransportClientMaestro,
+				Maestro: &MaestroTransportConfig{
+					TargetCluster: "cluster1",
+					ManifestWorkMetadata: &ManifestWorkMetadata{
+						Labels:      map[string]string{"hyperfleet.io/event-id": "{{ .eventId }}"},
+						Annotations: map[string]string{"hyperfleet.io/cluster-id": "{{ .clusterId }}"},
+						Precedence:  "newest",
+					},
+				},
+			},
+			Manifest: map[string]interface{}{
+				"apiVersion": "work.open-cluster-management.io/v1",
+				"kind":       "ManifestWork",
+				"metadata":   map[string]interface{}{"name": "test"},
+			},
+			Discovery: &DiscoveryConfig{ByName: "test"},
+		}}
+		v := newTaskValidator(cfg)
+		require.Error(t, v.ValidateStructure(), "precedence is transport or template")
+
+		cfg.Resources[0].Transport.Maestro.ManifestWorkMetadata.Precedence = MetadataPrecedenceTemplate
+		v = newTaskValidator(cfg)
+		require.NoError(t, v.ValidateStructure())
+		err := v.ValidateSemantic()
+		require.Error(t, err)
+		metadataPath := "resources[0].transport.maestro.manifest_work_metadata"
+		assert.Contains(t, err.Error(),
+			metadataPath+".labels.hyperfleet.io/event-id: undefined template variable \"eventId\"")
+		assert.Contains(t, err.Error(),
+			metadataPath+".annotations.hyperfleet.io/cluster-id: undefined template variable \"clusterId\"")
+	})
+
 	t.Run("maestro transport skips K8s manifest validation", func(t *testing.T) {
 		// Maestro resources use manifest for ManifestWork content
 		// should skip K8s apiVersion/kind validation
@@ -944,3 +2123,200 @@ func TestValidateFileReferencesManifestRef(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateUnknownReferences(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		expression   string
+		external     []string
+		wantWarnings []string
+	}{
+		{name: "produced references",
+			url:        `{{ .apiUrl }}/clusters?zones={{ range .zones }}{{ . }},{{ end }}`,
+			expression: `checkCluster.status.phase == "Ready" && clusterName != "" && adapter.name != ""`},
+		{name: "unknown template references",
+			url: `{{ .apiUrl }}/clusters{{ if .filter }}?q={{ index .query "name" }}{{ end }}`,
+			wantWarnings: []string{
+				`preconditions[0].api_call.url: unknown template reference "filter"`,
+				`preconditions[0].api_call.url: unknown template reference "query"`,
+			}},
+		{name: "unknown CEL references",
+			url:        `{{ .apiUrl }}/clusters`,
+			expression: `upstream.cluster.ready && zones.all(z, z.name != region) && resources.missing.ready`,
+			wantWarnings: []string{
+				`preconditions[0].expression: unknown CEL reference "upstream.cluster.ready"`,
+				`preconditions[0].expression: unknown CEL reference "region"`,
+				`preconditions[0].expression: unknown CEL reference "resources.missing.ready"`,
+			}},
+		{name: "known external params",
+			url:        `{{ .apiUrl }}/clusters{{ with .tenant.id }}/{{ . }}{{ end }}`,
+			expression: `upstream.cluster.ready && region != ""`,
+			external:   []string{"tenant", "upstream.cluster", "region"}},
+		{name: "external prefix does not cover siblings",
+			url:        `{{ .apiUrl }}/clusters`,
+			expression: `upstream.clusterId != ""`,
+			external:   []string{"upstream.cluster"},
+			wantWarnings: []string{
+				`preconditions[0].expression: unknown CEL reference "upstream.clusterId"`,
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseTaskConfig()
+			cfg.Params = []Parameter{
+				{Name: "apiUrl", Source: "event.api_url", Required: true},
+				{Name: "zones", Source: "event.zones", Required: true},
+			}
+			cfg.KnownExternalParams = tt.external
+			cfg.Preconditions = []Precondition{{
+				ActionBase: ActionBase{
+					Name:    "checkCluster",
+					APICall: &APICall{Method: "GET", URL: tt.url},
+				},
+				Capture:    []CaptureField{{Name: "clusterName", FieldExpressionDef: FieldExpressionDef{Field: "name"}}},
+				Expression: tt.expression,
+			}}
+			v := newTaskValidator(cfg)
+			require.NoError(t, v.ValidateStructure())
+			require.NoError(t, v.ValidateSemantic())
+
+			var warnings []string
+			for _, warning := range v.Warnings().Errors {
+				warnings = append(warnings, warning.Path+": "+warning.Message)
+			}
+			assert.Equal(t, tt.wantWarnings, warnings)
+		})
+	}
+}
+
+func TestValidateReasonLabels(t *testing.T) {
+	cfg := baseTaskConfig()
+	cfg.Preconditions = []Precondition{
+		{ActionBase: ActionBase{Name: "ready"}, Expression: "true", ReasonLabel: "cluster_not_ready"},
+		{ActionBase: ActionBase{Name: "fresh"}, Expression: "true", ReasonLabel: "{{ .reason }}"},
+	}
+	v := newTaskValidator(cfg)
+	require.NoError(t, v.ValidateStructure())
+	err := v.ValidateSemantic()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `preconditions[1].reason_label: "{{ .reason }}" is not a valid reason label`)
+	assert.NotContains(t, err.Error(), "preconditions[0]")
+}
+
+func TestValidateResourceNames(t *testing.T) {
+	cfg := baseTaskConfig()
+	configMap := map[string]interface{}{
+		"apiVersion": "v1", "kind": "ConfigMap",
+		"metadata": map[string]interface{}{"name": "settings", "namespace": "default"},
+	}
+	cfg.Resources = []Resource{
+		{Name: "applied", Manifest: configMap, Discovery: &DiscoveryConfig{ByName: "settings"}},
+		{
+			Name:      "settings",
+			Manifest:  configMap,
+			Discovery: &DiscoveryConfig{ByName: "settings"},
+			NestedDiscoveries: []NestedDiscovery{{
+				Name:      "failedCount",
+				Discovery: &DiscoveryConfig{ByName: "settings"},
+			}},
+		},
+	}
+	cfg.Post = &PostConfig{Payloads: []Payload{{
+		Name: "status",
+		Build: map[string]interface{}{
+			"outcome": map[string]interface{}{
+				"expression": `resources.skippedCount > 0 ? "Skipped" : string(size(resources.applied))`,
+			},
+		},
+	}}}
+	v := newTaskValidator(cfg)
+	require.NoError(t, v.ValidateStructure())
+	err := v.ValidateSemantic()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `resources[0].name: "applied" is reserved for the apply summary of the resources`)
+	assert.Contains(t, err.Error(),
+		`resources[1].nested_discoveries[0].name: "failedCount" is reserved for the apply summary of the resources`)
+	assert.Empty(t, v.Warnings().Errors, "the apply summary keys are known references")
+}
+
+func TestValidateMaps(t *testing.T) {
+	phaseStatus := LookupMap{Name: "phaseStatus", Entries: map[string]interface{}{"Ready": "READY"}}
+	tests := []struct {
+		name        string
+		maps        []LookupMap
+		url         string
+		expression  string
+		wantErr     string
+		wantWarning string
+	}{
+		{name: "inline and ConfigMap maps",
+			maps: []LookupMap{phaseStatus, {Name: "regions", ConfigMapName: "region-map",
+				ConfigMapNamespace: "hyperfleet", OnMissing: LookupOnMissingEmpty}},
+			url:        `/clusters/{{ .clusterId }}?dc={{ (lookup "regions" .clusterId).datacenter }}`,
+			expression: `lookup("phaseStatus", clusterId) == "READY"`},
+		{name: "nested object values", maps: []LookupMap{{Name: "regions", Entries: map[string]interface{}{
+			"us-east-1": map[string]interface{}{"network": map[string]interface{}{"zones": []interface{}{"a"}}},
+		}}}},
+		{name: "unknown map in a template", maps: []LookupMap{phaseStatus},
+			url:     `/clusters/{{ lookup "phases" .clusterId }}`,
+			wantErr: `lookup() references unknown map "phases"`},
+		{name: "unknown map in CEL", maps: []LookupMap{phaseStatus},
+			expression: `lookup("regions", clusterId) != ""`,
+			wantErr:    `lookup() references unknown map "regions"`},
+		{name: "entries and ConfigMap", maps: []LookupMap{{Name: "phaseStatus", ConfigMapName: "phases",
+			ConfigMapNamespace: "hyperfleet", Entries: phaseStatus.Entries}},
+			wantErr: "entries and configmap_name are mutually exclusive"},
+		{name: "ConfigMap without namespace", maps: []LookupMap{{Name: "phaseStatus", ConfigMapName: "phases"}},
+			wantErr: "configmap_name requires configmap_namespace"},
+		{name: "no entries", maps: []LookupMap{{Name: "phaseStatus"}},
+			wantErr: "requires entries or configmap_name"},
+		{name: "mixed values", maps: []LookupMap{{Name: "phaseStatus", Entries: map[string]interface{}{
+			"Failed": map[string]interface{}{"status": "FAILED"}, "Ready": "READY"}}},
+			wantErr: `value of key "Ready" is a string, unlike the other values, which are objects`},
+		{name: "value of another kind", maps: []LookupMap{{Name: "replicas", Entries: map[string]interface{}{"small": 1}}},
+			wantErr: `value of key "small" is a int, not a string or an object`},
+		{name: "default policy without default",
+			maps:    []LookupMap{{Name: "tiers", OnMissing: LookupOnMissingDefault, Entries: phaseStatus.Entries}},
+			wantErr: "on_missing: default requires a default value"},
+		{name: "default of another kind", maps: []LookupMap{{Name: "tiers", OnMissing: LookupOnMissingDefault,
+			Default: map[string]interface{}{"name": "standard"}, Entries: phaseStatus.Entries}},
+			wantErr: "unlike the other values"},
+		{name: "unused default", maps: []LookupMap{{Name: "tiers", Default: "standard", Entries: phaseStatus.Entries}},
+			wantWarning: "default has no effect with on_missing: error"},
+		{name: "invalid policy",
+			maps:    []LookupMap{{Name: "tiers", OnMissing: "ignore", Entries: phaseStatus.Entries}},
+			wantErr: "on_missing"},
+		{name: "duplicate names", maps: []LookupMap{phaseStatus, phaseStatus}, wantErr: "maps"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseTaskConfig()
+			cfg.Maps = tt.maps
+			url := tt.url
+			if url == "" {
+				url = "/clusters/{{ .clusterId }}"
+			}
+			cfg.Params = []Parameter{{Name: "clusterId", Source: "event.id"}}
+			cfg.Preconditions = []Precondition{{
+				ActionBase: ActionBase{Name: "checkCluster", APICall: &APICall{Method: "GET", URL: url}},
+				Expression: tt.expression,
+			}}
+			v := newTaskValidator(cfg)
+			err := v.ValidateStructure()
+			if err == nil {
+				err = v.ValidateSemantic()
+			}
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+			if tt.wantWarning != "" {
+				require.Error(t, v.Warnings())
+				assert.Contains(t, v.Warnings().Error(), tt.wantWarning)
+			}
+		})
+	}
+}
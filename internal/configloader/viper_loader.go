@@ -44,10 +44,22 @@ var viperKeyMappings = map[string]string{
 	"clients::hyperfleet_api::max_delay":               "API_MAX_DELAY",
 	"clients::broker::subscription_id":                 "BROKER_SUBSCRIPTION_ID",
 	"clients::broker::topic":                           "BROKER_TOPIC",
+	"clients::broker::rate_limit::events_per_second":   "BROKER_RATE_LIMIT_EVENTS_PER_SECOND",
+	"clients::broker::rate_limit::burst":               "BROKER_RATE_LIMIT_BURST",
+	"clients::broker::start_failure_policy":            "BROKER_START_FAILURE_POLICY",
+	"clients::broker::requeue::max_delay":              "BROKER_REQUEUE_MAX_DELAY",
+	"clients::broker::requeue::max_held":               "BROKER_REQUEUE_MAX_HELD",
+	"clients::broker::queue::depth":                    "BROKER_QUEUE_DEPTH",
+	"clients::broker::queue::workers":                  "BROKER_QUEUE_WORKERS",
+	"clients::broker::queue::when_full":                "BROKER_QUEUE_WHEN_FULL",
+	"clients::broker::max_event_bytes":                 "BROKER_MAX_EVENT_BYTES",
+	"clients::broker::redelivery_warn_attempts":        "BROKER_REDELIVERY_WARN_ATTEMPTS",
 	"clients::kubernetes::kube_config_path":            "KUBERNETES_KUBE_CONFIG_PATH",
 	"clients::kubernetes::api_version":                 "KUBERNETES_API_VERSION",
 	"clients::kubernetes::qps":                         "KUBERNETES_QPS",
 	"clients::kubernetes::burst":                       "KUBERNETES_BURST",
+	"health::combined_port":                            "HEALTH_COMBINED_PORT",
+	"metrics::per_step":                                "METRICS_PER_STEP",
 }
 
 // cliFlags defines mappings from CLI flag names to config paths
@@ -78,6 +90,9 @@ var cliFlags = map[string]string{
 	"hyperfleet-api-max-delay":           "clients::hyperfleet_api::max_delay",
 	"broker-subscription-id":             "clients::broker::subscription_id",
 	"broker-topic":                       "clients::broker::topic",
+	"broker-rate-limit":                  "clients::broker::rate_limit::events_per_second",
+	"broker-rate-limit-burst":            "clients::broker::rate_limit::burst",
+	"broker-start-failure-policy":        "clients::broker::start_failure_policy",
 	"kubernetes-kube-config-path":        "clients::kubernetes::kube_config_path",
 	"kubernetes-api-version":             "clients::kubernetes::api_version",
 	"kubernetes-qps":                     "clients::kubernetes::qps",
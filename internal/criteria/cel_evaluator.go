@@ -68,6 +68,8 @@ func buildCELOptions(ctx *EvaluationContext) []cel.EnvOption {
 	// Enable optional types for optional chaining syntax (e.g., a.?b.?c)
 	options = append(options, cel.OptionalTypes())
 	options = append(options, customCELFunctions()...)
+	options = append(options, flagFunction(ctx.Flags()))
+	options = append(options, lookupFunction(ctx.Lookup()))
 
 	// Get a snapshot of the data for thread safety
 	data := ctx.Data()
@@ -79,6 +81,58 @@ func buildCELOptions(ctx *EvaluationContext) []cel.EnvOption {
 	return options
 }
 
+// flagFunction registers flag(name), the value of a feature flag looked up in
+// flags; it is false for unknown flags and without flags
+func flagFunction(flags FlagLookup) cel.EnvOption {
+	return cel.Function("flag",
+		cel.Overload(
+			"flag_string",
+			[]*cel.Type{cel.StringType},
+			cel.BoolType,
+			cel.UnaryBinding(func(name ref.Val) ref.Val {
+				flagName, ok := name.Value().(string)
+				if !ok {
+					return types.NewErr("flag() name must be a string")
+				}
+				if flags == nil {
+					return types.False
+				}
+				return types.Bool(flags(flagName))
+			}),
+		),
+	)
+}
+
+// lookupFunction registers lookup(mapName, key), the value of key in a map of
+// the config looked up with lookup; it is an error without maps
+func lookupFunction(lookup MapLookup) cel.EnvOption {
+	return cel.Function("lookup",
+		cel.Overload(
+			"lookup_string_string",
+			[]*cel.Type{cel.StringType, cel.StringType},
+			cel.DynType,
+			cel.BinaryBinding(func(mapName, key ref.Val) ref.Val {
+				name, ok := mapName.Value().(string)
+				if !ok {
+					return types.NewErr("lookup() map name must be a string")
+				}
+				keyValue, ok := key.Value().(string)
+				if !ok {
+					return types.NewErr("lookup() key must be a string")
+				}
+				if lookup == nil {
+					return types.NewErr("lookup() unknown map %q", name)
+				}
+				value, err := lookup(name, keyValue)
+				if err != nil {
+					return types.NewErr("lookup() %v", err)
+				}
+				return types.DefaultTypeAdapter.NativeToValue(value)
+			}),
+		),
+	)
+}
+
 // customCELFunctions registers helper functions used by config expressions.
 // These helpers are primarily for payload construction where deeply nested
 // resources/discoveries can be difficult to inspect safely.
@@ -149,6 +203,44 @@ func unwrapCELValue(value ref.Val) (interface{}, bool) {
 	return value.Value(), true
 }
 
+// NativeValue converts the value of a CEL result to plain Go values: lists and
+// maps built by CEL, e.g. by filter or map literals, hold CEL values, which are
+// converted recursively to []interface{} and map[string]interface{}
+func NativeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case types.Null:
+		return nil
+	case ref.Val:
+		return NativeValue(v.Value())
+	case []ref.Val:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = NativeValue(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = NativeValue(item)
+		}
+		return out
+	case map[ref.Val]ref.Val:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[fmt.Sprint(NativeValue(key))] = NativeValue(item)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = NativeValue(item)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
 // digValue safely traverses map/list structures using dot-separated paths.
 // Returns (nil, false) when a path segment does not exist.
 func digValue(root interface{}, path string) (interface{}, bool) {
@@ -2,6 +2,7 @@ package criteria
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -368,6 +369,70 @@ func TestCELEvaluatorCustomFunctions(t *testing.T) {
 	})
 }
 
+func TestCELEvaluatorFlagFunction(t *testing.T) {
+	ctx := NewEvaluationContext()
+	ctx.Set("env", "prod")
+	ctx.SetFlags(func(name string) bool { return name == "canary" })
+
+	evaluator, err := newCELEvaluator(ctx)
+	require.NoError(t, err)
+
+	result, err := evaluator.EvaluateSafe(`flag("canary") && env == "prod"`)
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Value)
+
+	result, err = evaluator.EvaluateSafe(`flag("unknown")`)
+	require.NoError(t, err)
+	assert.Equal(t, false, result.Value)
+
+	t.Run("without flags every flag is off", func(t *testing.T) {
+		evaluator, err := newCELEvaluator(NewEvaluationContext())
+		require.NoError(t, err)
+		result, err := evaluator.EvaluateSafe(`flag("canary")`)
+		require.NoError(t, err)
+		assert.Equal(t, false, result.Value)
+	})
+}
+
+func TestCELEvaluatorLookupFunction(t *testing.T) {
+	ctx := NewEvaluationContext()
+	ctx.Set("phase", "Provisioning")
+	ctx.SetLookup(func(mapName, key string) (interface{}, error) {
+		switch {
+		case mapName == "phases" && key == "Provisioning":
+			return "INSTALLING", nil
+		case mapName == "regions":
+			return map[string]interface{}{"datacenter": "dc-" + key}, nil
+		}
+		return nil, fmt.Errorf("key %q not found in map %q", key, mapName)
+	})
+
+	evaluator, err := newCELEvaluator(ctx)
+	require.NoError(t, err)
+
+	result, err := evaluator.EvaluateSafe(`lookup("phases", phase)`)
+	require.NoError(t, err)
+	assert.Equal(t, "INSTALLING", result.Value)
+
+	result, err = evaluator.EvaluateSafe(`lookup("regions", "us-east-1").datacenter`)
+	require.NoError(t, err)
+	assert.Equal(t, "dc-us-east-1", result.Value)
+
+	result, err = evaluator.EvaluateSafe(`lookup("phases", "Ready")`)
+	require.NoError(t, err)
+	require.Error(t, result.Error)
+	assert.Contains(t, result.Error.Error(), `key "Ready" not found in map "phases"`)
+
+	t.Run("without maps every lookup fails", func(t *testing.T) {
+		evaluator, err := newCELEvaluator(NewEvaluationContext())
+		require.NoError(t, err)
+		result, err := evaluator.EvaluateSafe(`lookup("phases", "Ready")`)
+		require.NoError(t, err)
+		require.Error(t, result.Error)
+		assert.Contains(t, result.Error.Error(), `unknown map "phases"`)
+	})
+}
+
 // TestEvaluateSafeErrorHandling tests how EvaluateSafe handles various error scenarios
 // and how callers can use the result to make decisions at a higher level
 func TestEvaluateSafeErrorHandling(t *testing.T) {
@@ -443,3 +508,33 @@ func TestEvaluateSafeErrorHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestNativeValue(t *testing.T) {
+	ctx := NewEvaluationContext()
+	ctx.Set("items", []interface{}{
+		map[string]interface{}{"kind": "nodepool", "name": "np-1"},
+		map[string]interface{}{"kind": "machine", "name": "m-1"},
+	})
+	evaluator, err := NewEvaluator(context.Background(), ctx, logger.NewTestLogger())
+	require.NoError(t, err)
+
+	tests := []struct {
+		want       interface{}
+		expression string
+	}{
+		{expression: `items.filter(i, i.kind == "nodepool")`,
+			want: []interface{}{map[string]interface{}{"kind": "nodepool", "name": "np-1"}}},
+		{expression: `{"names": items.map(i, i.name), "none": null}`,
+			want: map[string]interface{}{"names": []interface{}{"np-1", "m-1"}, "none": nil}},
+		{expression: `items[1]`, want: map[string]interface{}{"kind": "machine", "name": "m-1"}},
+		{expression: `size(items)`, want: int64(2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			result, err := evaluator.EvaluateCEL(tt.expression)
+			require.NoError(t, err)
+			require.NoError(t, result.Error)
+			assert.Equal(t, tt.want, NativeValue(result.Value))
+		})
+	}
+}
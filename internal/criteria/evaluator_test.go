@@ -817,6 +817,39 @@ func TestExtractFieldJSONPath(t *testing.T) {
 	}
 }
 
+func TestExtractMatches(t *testing.T) {
+	data := map[string]interface{}{
+		"spec":  map[string]interface{}{"region": nil, "nodes": float64(3)},
+		"items": []interface{}{map[string]interface{}{"name": "a"}, map[string]interface{}{"name": "b"}},
+	}
+	tests := []struct {
+		name    string
+		field   string
+		want    []interface{}
+		wantErr bool
+	}{
+		{name: "value", field: "spec.nodes", want: []interface{}{float64(3)}},
+		{name: "null", field: "spec.region", want: []interface{}{nil}},
+		{name: "missing key", field: "spec.location"},
+		{name: "list as one match", field: "items", want: []interface{}{data["items"]}},
+		{name: "array index", field: "items[1].name", want: []interface{}{"b"}},
+		{name: "array index out of range", field: "items[5].name"},
+		{name: "wildcard", field: "{.items[*].name}", want: []interface{}{"a", "b"}},
+		{name: "invalid path", field: "{.items[}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := ExtractMatches(data, tt.field)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, matches)
+		})
+	}
+}
+
 func TestExtractFieldFunction(t *testing.T) {
 	// Test the convenience ExtractField function
 	data := map[string]interface{}{
@@ -29,15 +29,7 @@ func ExtractField(data interface{}, field string) (*FieldResult, error) {
 		return result, fmt.Errorf("empty field path")
 	}
 
-	// Convert simple dot notation to JSONPath format
-	// e.g., "metadata.name" → "{.metadata.name}"
-	jsonPath := field
-	if !strings.HasPrefix(jsonPath, "{") {
-		if !strings.HasPrefix(jsonPath, ".") {
-			jsonPath = "." + jsonPath
-		}
-		jsonPath = "{" + jsonPath + "}"
-	}
+	jsonPath := toJSONPath(field)
 
 	// Create JSONPath parser
 	// AllowMissingKeys(false) ensures we get errors for missing fields (backward compatible)
@@ -91,3 +83,60 @@ func ExtractField(data interface{}, field string) (*FieldResult, error) {
 
 	return result, nil
 }
+
+// toJSONPath converts simple dot notation to JSONPath format,
+// e.g., "metadata.name" → "{.metadata.name}"
+func toJSONPath(field string) string {
+	if strings.HasPrefix(field, "{") {
+		return field
+	}
+	if !strings.HasPrefix(field, ".") {
+		field = "." + field
+	}
+	return "{" + field + "}"
+}
+
+// ParseFieldPath checks that field is a valid field path of ExtractField
+func ParseFieldPath(field string) error {
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return fmt.Errorf("empty field path")
+	}
+	if err := jsonpath.New("field-path").Parse(toJSONPath(field)); err != nil {
+		return fmt.Errorf("invalid field path '%s': %w", field, err)
+	}
+	return nil
+}
+
+// ExtractMatches returns every value field matches in data, in document order,
+// and none when the path does not exist. Unlike ExtractField, a path matching a
+// list returns the list as one match, so the matches can be type checked.
+// A JSON null is matched as nil. Only an invalid path is an error.
+func ExtractMatches(data interface{}, field string) ([]interface{}, error) {
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return nil, fmt.Errorf("empty field path")
+	}
+	jp := jsonpath.New("field-matches").AllowMissingKeys(false)
+	if err := jp.Parse(toJSONPath(field)); err != nil {
+		return nil, fmt.Errorf("invalid field path '%s': %w", field, err)
+	}
+	results, err := jp.FindResults(data)
+	if err != nil {
+		// A missing key or an index out of range
+		return nil, nil
+	}
+	var matches []interface{}
+	for _, r := range results {
+		for _, v := range r {
+			if !v.IsValid() {
+				matches = append(matches, nil)
+				continue
+			}
+			if v.CanInterface() {
+				matches = append(matches, v.Interface())
+			}
+		}
+	}
+	return matches, nil
+}
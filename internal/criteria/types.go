@@ -67,10 +67,54 @@ type EvaluationContext struct {
 	// version tracks modifications to detect when CEL evaluator needs recreation
 	// This ensures the CEL environment stays in sync with the context data
 	version int64
+	// flags looks up the feature flags of the flag() CEL function, nil for none
+	flags FlagLookup
+	// lookup reads the maps of the lookup() CEL function, nil for none
+	lookup MapLookup
 	// mu protects concurrent access to data and version
 	mu sync.RWMutex
 }
 
+// FlagLookup returns the value of the feature flag name for the flag() CEL
+// function; unknown flags are false
+type FlagLookup func(name string) bool
+
+// SetFlags sets the feature flags seen by the flag() CEL function of the
+// expressions evaluated in the context. Without flags, flag() is false.
+func (c *EvaluationContext) SetFlags(flags FlagLookup) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flags = flags
+	c.version++
+}
+
+// Flags returns the feature flags set with SetFlags
+func (c *EvaluationContext) Flags() FlagLookup {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.flags
+}
+
+// MapLookup returns the value of key in the map mapName for the lookup() CEL
+// function, failing for an unknown map and, depending on the map, a missing key
+type MapLookup func(mapName, key string) (interface{}, error)
+
+// SetLookup sets the maps read by the lookup() CEL function of the expressions
+// evaluated in the context. Without maps, every lookup() fails.
+func (c *EvaluationContext) SetLookup(lookup MapLookup) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lookup = lookup
+	c.version++
+}
+
+// Lookup returns the maps set with SetLookup
+func (c *EvaluationContext) Lookup() MapLookup {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lookup
+}
+
 // NewEvaluationContext creates a new evaluation context
 func NewEvaluationContext() *EvaluationContext {
 	return &EvaluationContext{
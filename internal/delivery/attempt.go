@@ -0,0 +1,43 @@
+// Package delivery carries the broker delivery attempt of the event being
+// handled, from the broker backend that reports it, or the broker consumer
+// middleware that counts the deliveries of each event, to the executor.
+package delivery
+
+import (
+	"context"
+	"strconv"
+)
+
+// AttemptUnknown is the delivery attempt of the messages whose broker backend
+// does not report one
+const AttemptUnknown = 0
+
+type attemptKey struct{}
+
+// WithAttempt returns ctx carrying the delivery attempt of the message being
+// handled, 1 for its first delivery. Set by the broker backends that count
+// deliveries, or else by the Redeliveries broker consumer middleware.
+func WithAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// AttemptFromContext returns the delivery attempt of the message being handled,
+// or AttemptUnknown outside of the broker consumer, e.g. in run-once mode.
+// hyperfleet-broker hands handlers the CloudEvent without the metadata of its
+// message, so neither the delivery attempt of Google Pub/Sub nor the
+// redelivered flag of RabbitMQ reaches the adapter through it: their attempts
+// are counted by the adapter.
+func AttemptFromContext(ctx context.Context) int {
+	if attempt, ok := ctx.Value(attemptKey{}).(int); ok && attempt > 0 {
+		return attempt
+	}
+	return AttemptUnknown
+}
+
+// FormatAttempt formats a delivery attempt for logs, "unknown" for AttemptUnknown
+func FormatAttempt(attempt int) string {
+	if attempt == AttemptUnknown {
+		return "unknown"
+	}
+	return strconv.Itoa(attempt)
+}
@@ -0,0 +1,20 @@
+package delivery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttempt(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, AttemptUnknown, AttemptFromContext(ctx))
+	assert.Equal(t, "unknown", FormatAttempt(AttemptFromContext(ctx)))
+
+	ctx = WithAttempt(ctx, 3)
+	assert.Equal(t, 3, AttemptFromContext(ctx))
+	assert.Equal(t, "3", FormatAttempt(AttemptFromContext(ctx)))
+
+	assert.Equal(t, AttemptUnknown, AttemptFromContext(WithAttempt(ctx, 0)), "a backend without a count")
+}
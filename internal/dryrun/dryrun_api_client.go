@@ -27,7 +27,10 @@ type RequestRecord struct {
 type DryrunAPIClient struct {
 	endpoints []compiledEndpoint
 	Requests  []RequestRecord
-	mu        sync.Mutex
+	// Targets are the API targets accepted by Target. Requests to every target
+	// are matched against the same responses.
+	Targets []hyperfleetapi.TargetConfig
+	mu      sync.Mutex
 }
 
 type compiledEndpoint struct {
@@ -195,3 +198,16 @@ func (c *DryrunAPIClient) Delete(
 func (c *DryrunAPIClient) BaseURL() string {
 	return "http://mock-api"
 }
+
+// Target returns the client itself for the default target and the names in Targets
+func (c *DryrunAPIClient) Target(name string) (hyperfleetapi.Client, error) {
+	if name == hyperfleetapi.DefaultTarget {
+		return c, nil
+	}
+	for _, target := range c.Targets {
+		if target.Name == name {
+			return c, nil
+		}
+	}
+	return nil, &hyperfleetapi.UnknownTargetError{Name: name, Valid: hyperfleetapi.TargetNames(c.Targets)}
+}
@@ -0,0 +1,43 @@
+package dryrun
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/webhook"
+)
+
+// WebhookRecord stores a webhook delivery recorded by the dry-run client.
+type WebhookRecord struct {
+	// Host is the host of the webhook URL, whose path may carry credentials
+	Host string
+	Body []byte
+	// Signed reports whether the body would have been signed
+	Signed bool
+}
+
+// DryrunWebhookClient implements executor.WebhookClient by recording
+// deliveries without sending them. Every delivery succeeds with 200.
+type DryrunWebhookClient struct {
+	Records []WebhookRecord
+	mu      sync.Mutex
+}
+
+// NewDryrunWebhookClient creates a new DryrunWebhookClient.
+func NewDryrunWebhookClient() *DryrunWebhookClient {
+	return &DryrunWebhookClient{Records: make([]WebhookRecord, 0)}
+}
+
+// Deliver records the delivery.
+func (c *DryrunWebhookClient) Deliver(_ context.Context, req *webhook.Request) (*webhook.Delivery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	record := WebhookRecord{Body: req.Body, Signed: len(req.Secret) > 0}
+	if parsed, err := url.Parse(req.URL); err == nil {
+		record.Host = parsed.Host
+	}
+	c.Records = append(c.Records, record)
+	return &webhook.Delivery{StatusCode: http.StatusOK, Attempts: 1}, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2/event"
 )
@@ -29,3 +30,39 @@ func LoadCloudEvent(path string) (*cloudevents.Event, error) {
 
 	return &evt, nil
 }
+
+// LoadCloudEventOrData reads a CloudEvent from a JSON file like LoadCloudEvent.
+// When the file holds a bare data payload instead (a JSON object without
+// "specversion"), it is wrapped in a new event of eventType and source, which
+// are then required.
+func LoadCloudEventOrData(path, eventType, source string) (*cloudevents.Event, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event file %q: %w", path, err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse event file %q: %w", path, err)
+	}
+	if _, structured := fields["specversion"]; structured {
+		return LoadCloudEvent(path)
+	}
+	if eventType == "" || source == "" {
+		return nil, fmt.Errorf("%q is not a structured CloudEvent (no specversion): "+
+			"an event type and source are required to wrap it as event data", path)
+	}
+
+	evt := cloudevents.New()
+	evt.SetID(fmt.Sprintf("run-once-%d", time.Now().UnixNano()))
+	evt.SetType(eventType)
+	evt.SetSource(source)
+	evt.SetTime(time.Now())
+	if err := evt.SetData(cloudevents.ApplicationJSON, json.RawMessage(data)); err != nil {
+		return nil, fmt.Errorf("failed to set event data from %q: %w", path, err)
+	}
+	if err := evt.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid CloudEvent wrapping %q: %w", path, err)
+	}
+	return &evt, nil
+}
@@ -52,3 +52,50 @@ func TestLoadCloudEvent_InvalidJSON(t *testing.T) {
 		assert.Contains(t, err.Error(), "failed to parse")
 	})
 }
+
+func TestLoadCloudEventOrData(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("loads a structured CloudEvent", func(t *testing.T) {
+		path := writeEventFile(t, dir, "event.json",
+			`{"specversion":"1.0","id":"test-123","type":"com.example.test","source":"/test","data":{"id":"c1"}}`)
+
+		evt, err := LoadCloudEventOrData(path, "ignored.type", "/ignored")
+
+		require.NoError(t, err)
+		assert.Equal(t, "test-123", evt.ID())
+		assert.Equal(t, "com.example.test", evt.Type())
+	})
+
+	t.Run("wraps a bare data payload", func(t *testing.T) {
+		path := writeEventFile(t, dir, "data.json", `{"id":"c1","kind":"Cluster","generation":2}`)
+
+		evt, err := LoadCloudEventOrData(path, "com.example.cluster", "/clusters/c1")
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, evt.ID())
+		assert.Equal(t, "com.example.cluster", evt.Type())
+		assert.Equal(t, "/clusters/c1", evt.Source())
+		var data map[string]interface{}
+		require.NoError(t, evt.DataAs(&data))
+		assert.Equal(t, "Cluster", data["kind"])
+	})
+
+	t.Run("bare payload requires type and source", func(t *testing.T) {
+		path := writeEventFile(t, dir, "data-only.json", `{"id":"c1"}`)
+
+		_, err := LoadCloudEventOrData(path, "com.example.cluster", "")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a structured CloudEvent")
+	})
+
+	t.Run("returns error for invalid JSON", func(t *testing.T) {
+		path := writeEventFile(t, dir, "bad.json", `{not json}`)
+
+		_, err := LoadCloudEventOrData(path, "com.example.cluster", "/clusters/c1")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to parse")
+	})
+}
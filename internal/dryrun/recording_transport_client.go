@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/manifest"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -16,6 +17,7 @@ const (
 	operationApply    = "apply"
 	operationGet      = "get"
 	operationDiscover = "discover"
+	operationPatch    = "patch"
 )
 
 // TransportRecord stores details of a transport client operation.
@@ -25,7 +27,7 @@ type TransportRecord struct {
 	Namespace string
 	Name      string
 	GVK       schema.GroupVersionKind
-	Operation string // operationApply, operationGet, operationDiscover
+	Operation string // operationApply, operationGet, operationDiscover, operationPatch
 	Manifest  []byte
 }
 
@@ -159,6 +161,37 @@ func (c *DryrunTransportClient) GetResource(
 	return obj.DeepCopy(), nil
 }
 
+// PatchResource records the patch of a k8s_patch post action without applying it.
+// Returns the stored resource, or a stub with the GVK, namespace and name when
+// the resource was not applied in this dry run.
+func (c *DryrunTransportClient) PatchResource(
+	ctx context.Context,
+	gvk schema.GroupVersionKind,
+	namespace, name string,
+	patchData []byte,
+	_ *k8sclient.PatchOptions,
+) (*unstructured.Unstructured, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Records = append(c.Records, TransportRecord{
+		Operation: operationPatch,
+		GVK:       gvk,
+		Namespace: namespace,
+		Name:      name,
+		Manifest:  patchData,
+	})
+
+	if obj, exists := c.resources[resourceKey(gvk, namespace, name)]; exists {
+		return obj.DeepCopy(), nil
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj, nil
+}
+
 // DiscoverResources returns resources from the in-memory store filtered by discovery config.
 func (c *DryrunTransportClient) DiscoverResources(
 	ctx context.Context,
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/executor"
 )
 
@@ -19,6 +20,8 @@ type ExecutionTrace struct {
 	Result    *executor.ExecutionResult
 	APIClient *DryrunAPIClient
 	Transport *DryrunTransportClient
+	// Webhooks records the webhook deliveries, nil without webhook actions
+	Webhooks  *DryrunWebhookClient
 	EventID   string
 	EventType string
 	Verbose   bool
@@ -26,16 +29,19 @@ type ExecutionTrace struct {
 
 // TraceJSON is the JSON-serializable representation of the execution trace.
 type TraceJSON struct {
-	Event               TraceEvent             `json:"event"`
-	Status              string                 `json:"status"`
-	Params              map[string]interface{} `json:"params,omitempty"`
-	Preconditions       []TracePrecondition    `json:"preconditions,omitempty"`
-	Resources           []TraceResource        `json:"resources,omitempty"`
-	DiscoveredResources map[string]interface{} `json:"discoveredResources,omitempty"`
-	PostActions         []TracePostAction      `json:"postActions,omitempty"`
-	Errors              map[string]string      `json:"errors,omitempty"`
-	APIRequests         []TraceAPIRequest      `json:"apiRequests,omitempty"`
-	TransportOps        []TraceTransportOp     `json:"transportOperations,omitempty"`
+	Event               TraceEvent                     `json:"event"`
+	Status              string                         `json:"status"`
+	Workflow            string                         `json:"workflow,omitempty"`
+	Params              map[string]interface{}         `json:"params,omitempty"`
+	SchemaViolations    []configloader.SchemaViolation `json:"schemaViolations,omitempty"`
+	Preconditions       []TracePrecondition            `json:"preconditions,omitempty"`
+	Resources           []TraceResource                `json:"resources,omitempty"`
+	DiscoveredResources map[string]interface{}         `json:"discoveredResources,omitempty"`
+	PostActions         []TracePostAction              `json:"postActions,omitempty"`
+	Errors              map[string]string              `json:"errors,omitempty"`
+	APIRequests         []TraceAPIRequest              `json:"apiRequests,omitempty"`
+	TransportOps        []TraceTransportOp             `json:"transportOperations,omitempty"`
+	Webhooks            []TraceWebhook                 `json:"webhookDeliveries,omitempty"`
 }
 
 // TraceEvent is the JSON representation of the event.
@@ -72,6 +78,13 @@ type TracePostAction struct {
 	Skipped bool   `json:"skipped,omitempty"`
 }
 
+// TraceWebhook is the JSON representation of a recorded webhook delivery.
+type TraceWebhook struct {
+	Host   string `json:"host"`
+	Body   string `json:"body,omitempty"`
+	Signed bool   `json:"signed,omitempty"`
+}
+
 // TraceAPIRequest is the JSON representation of a recorded API request.
 type TraceAPIRequest struct {
 	Request    string `json:"requestBody,omitempty"`
@@ -99,6 +112,15 @@ func (t *ExecutionTrace) FormatText() string {
 	b.WriteString("========================\n")
 	fmt.Fprintf(&b, "Event: id=%s type=%s\n\n", t.EventID, t.EventType)
 
+	// Event schema validation (only reported when it failed)
+	if len(result.SchemaViolations) > 0 {
+		fmt.Fprintf(&b, "Schema Validation .......................... %s\n", statusFailed)
+		for _, v := range result.SchemaViolations {
+			fmt.Fprintf(&b, "  %s\n", v)
+		}
+		b.WriteString("\n")
+	}
+
 	// Phase 1: Parameter Extraction
 	paramStatus := statusSuccess
 	if _, ok := result.Errors[executor.PhaseParamExtraction]; ok {
@@ -114,6 +136,18 @@ func (t *ExecutionTrace) FormatText() string {
 	}
 	b.WriteString("\n")
 
+	// Workflow selection (only reported for configs declaring workflows)
+	if _, ok := result.PhaseDurations[executor.PhaseWorkflowSelection]; ok {
+		if err, failed := result.Errors[executor.PhaseWorkflowSelection]; failed {
+			fmt.Fprintf(&b, "Workflow Selection ......................... %s\n", statusFailed)
+			fmt.Fprintf(&b, "  Error: %v\n", err)
+		} else {
+			fmt.Fprintf(&b, "Workflow Selection ......................... %s\n", statusSuccess)
+			fmt.Fprintf(&b, "  workflow         = %s\n", result.Workflow)
+		}
+		b.WriteString("\n")
+	}
+
 	// Phase 2: Preconditions
 	precondStatus := statusSuccess
 	precondDetail := ""
@@ -127,6 +161,7 @@ func (t *ExecutionTrace) FormatText() string {
 	fmt.Fprintf(&b, "Phase 2: Preconditions ..................... %s%s\n", precondStatus, precondDetail)
 
 	apiReqIdx := 0
+	webhookIdx := 0
 	for i, pr := range result.PreconditionResults {
 		status := "PASS"
 		if pr.Status == executor.StatusFailed {
@@ -253,6 +288,19 @@ func (t *ExecutionTrace) FormatText() string {
 			apiReqIdx++
 		}
 
+		if pa.Webhook != nil && t.Webhooks != nil && webhookIdx < len(t.Webhooks.Records) {
+			rec := t.Webhooks.Records[webhookIdx]
+			fmt.Fprintf(&b, "    Webhook: POST %s -> %d (signed=%t)\n", rec.Host, pa.Webhook.StatusCode, rec.Signed)
+			if t.Verbose {
+				fmt.Fprintf(&b, "    [verbose] Webhook body:\n      %s\n", prettyJSON(rec.Body))
+			}
+			webhookIdx++
+		}
+
+		if pa.K8sPatchMade {
+			fmt.Fprintf(&b, "    K8s Patch: resourceVersion=%q\n", pa.ResourceVersion)
+		}
+
 		if pa.Error != nil {
 			fmt.Fprintf(&b, "    Error: %v\n", pa.Error)
 		}
@@ -274,9 +322,13 @@ func (t *ExecutionTrace) FormatJSON() ([]byte, error) {
 	result := t.Result
 
 	trace := TraceJSON{
-		Event:  TraceEvent{ID: t.EventID, Type: t.EventType},
-		Status: string(result.Status),
-		Params: result.Params,
+		Event:            TraceEvent{ID: t.EventID, Type: t.EventType},
+		Status:           string(result.Status),
+		Params:           result.Params,
+		SchemaViolations: result.SchemaViolations,
+	}
+	if _, ok := result.PhaseDurations[executor.PhaseWorkflowSelection]; ok {
+		trace.Workflow = result.Workflow
 	}
 
 	// Discovered resources (from discovery phase, used in payload CEL)
@@ -370,6 +422,17 @@ func (t *ExecutionTrace) FormatJSON() ([]byte, error) {
 		trace.TransportOps = append(trace.TransportOps, op)
 	}
 
+	// Webhook deliveries
+	if t.Webhooks != nil {
+		for _, rec := range t.Webhooks.Records {
+			tw := TraceWebhook{Host: rec.Host, Signed: rec.Signed}
+			if t.Verbose {
+				tw.Body = string(rec.Body)
+			}
+			trace.Webhooks = append(trace.Webhooks, tw)
+		}
+	}
+
 	return json.MarshalIndent(trace, "", "  ")
 }
 
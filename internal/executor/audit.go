@@ -0,0 +1,141 @@
+package executor
+
+import (
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/audit"
+)
+
+// AuditRecord builds the audit record of an execution that finished now and
+// took duration. Values of env-sourced and sensitive params are redacted from
+// the params and from every message and URL that may quote them.
+func (e *Executor) AuditRecord(evt *event.Event, result *ExecutionResult, duration time.Duration) audit.Record {
+	redact := e.redactor(result)
+	finished := e.clock.Now()
+	record := audit.Record{
+		SchemaVersion: audit.SchemaVersion,
+		Adapter:       e.config.Config.Adapter.Name,
+		Event: audit.Event{
+			ID:     evt.ID(),
+			Type:   evt.Type(),
+			Source: evt.Source(),
+		},
+		StartedAt:     finished.Add(-duration),
+		FinishedAt:    finished,
+		DurationMs:    duration.Milliseconds(),
+		Status:        executionOutcome(result),
+		Phase:         string(result.CurrentPhase),
+		SkipReason:    redact(result.SkipReason),
+		TraceID:       result.TraceID,
+		CorrelationID: result.CorrelationID,
+		ExecutionKey:  redact(result.ExecutionKey),
+		Workflow:      result.Workflow,
+		Params:        e.redactedParams(result, redact),
+	}
+	if err := primaryError(result); err != nil {
+		record.Error = redact(err.Error())
+		record.ErrorCode = string(ErrorCodeOf(err))
+	}
+
+	for _, precond := range result.PreconditionResults {
+		entry := audit.Precondition{
+			StartedAt:  precond.StartedAt,
+			Name:       precond.Name,
+			Outcome:    audit.OutcomeNotMet,
+			DurationMs: precond.Duration.Milliseconds(),
+		}
+		switch {
+		case precond.Status == StatusFailed:
+			entry.Outcome = audit.OutcomeFailed
+			if precond.Error != nil {
+				entry.Error = redact(precond.Error.Error())
+			}
+		case precond.Matched:
+			entry.Outcome = audit.OutcomeMet
+		}
+		record.Preconditions = append(record.Preconditions, entry)
+	}
+	for _, res := range result.ResourceResults {
+		entry := audit.Resource{
+			StartedAt:    res.StartedAt,
+			DurationMs:   res.Duration.Milliseconds(),
+			Name:         res.Name,
+			APIVersion:   res.APIVersion,
+			Kind:         res.Kind,
+			Namespace:    res.Namespace,
+			ResourceName: res.ResourceName,
+			Operation:    string(res.Operation),
+			ContentHash:  res.ContentHash,
+			Status:       string(res.Status),
+		}
+		if res.Error != nil {
+			entry.Error = redact(res.Error.Error())
+		}
+		record.Resources = append(record.Resources, entry)
+	}
+	for _, action := range result.PostActionResults {
+		entry := audit.PostAction{
+			StartedAt:  action.StartedAt,
+			Name:       action.Name,
+			Status:     string(action.Status),
+			DurationMs: action.Duration.Milliseconds(),
+		}
+		switch {
+		case action.Skipped:
+			entry.Status = "skipped"
+		case action.Buffered:
+			entry.Status = "buffered"
+		}
+		if action.Error != nil {
+			entry.Error = redact(action.Error.Error())
+		}
+		record.PostActions = append(record.PostActions, entry)
+	}
+	if len(result.PhaseDurations) > 0 {
+		record.PhaseDurationsMs = make(map[string]int64, len(result.PhaseDurations))
+		for phase, d := range result.PhaseDurations {
+			record.PhaseDurationsMs[string(phase)] = d.Milliseconds()
+		}
+	}
+	for _, call := range result.APICalls {
+		record.APICalls = append(record.APICalls, audit.APICall{
+			Phase:      string(call.Phase),
+			Step:       call.Step,
+			Method:     call.Method,
+			URL:        redact(call.URL),
+			Target:     call.Target,
+			StatusCode: call.StatusCode,
+			Error:      redact(call.Error),
+			RequestID:  call.RequestID,
+			LatencyMs:  call.Latency.Milliseconds(),
+			Attempts:   call.Attempts,
+		})
+	}
+	return record
+}
+
+// redactedParams returns a copy of the execution params with the values of the
+// redacted params replaced, and redact applied to the other string values.
+// The built-in config param is left out: it is the same for every execution.
+func (e *Executor) redactedParams(result *ExecutionResult, redact func(string) string) map[string]interface{} {
+	if len(result.Params) == 0 {
+		return nil
+	}
+	params := make(map[string]interface{}, len(result.Params))
+	for name, value := range result.Params {
+		if name == "config" {
+			continue
+		}
+		if s, ok := value.(string); ok {
+			value = redact(s)
+		}
+		params[name] = value
+	}
+	for _, param := range e.config.Config.Params {
+		if _, ok := params[param.Name]; ok && isRedactedParam(param) {
+			params[param.Name] = redactedPlaceholder
+		}
+	}
+	return params
+}
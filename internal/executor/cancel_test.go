@@ -0,0 +1,173 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/brokerconsumer"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/eventtest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cancellingAPIClient cancels the execution context while each call is in flight
+type cancellingAPIClient struct {
+	*hyperfleetapi.MockClient
+	cancel context.CancelFunc
+}
+
+func (c *cancellingAPIClient) Get(
+	ctx context.Context, url string, opts ...hyperfleetapi.RequestOption,
+) (*hyperfleetapi.Response, error) {
+	c.cancel()
+	return c.MockClient.Get(ctx, url, opts...)
+}
+
+func (c *cancellingAPIClient) Post(
+	ctx context.Context, url string, body []byte, opts ...hyperfleetapi.RequestOption,
+) (*hyperfleetapi.Response, error) {
+	c.cancel()
+	return c.MockClient.Post(ctx, url, body, opts...)
+}
+
+func cancelTestConfig() *configloader.Config {
+	configMap := func(name string) configloader.Resource {
+		return configloader.Resource{
+			Name: name,
+			Manifest: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+			},
+		}
+	}
+	return &configloader.Config{
+		Adapter:   configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		Params:    []configloader.Parameter{{Name: "clusterId", Source: "event.id", Required: true}},
+		Resources: []configloader.Resource{configMap("first"), configMap("second")},
+		Post: &configloader.PostConfig{PostActions: []configloader.PostAction{
+			{ActionBase: configloader.ActionBase{Name: "report", APICall: &configloader.APICall{
+				Method: "POST", URL: "/clusters/{{ .clusterId }}/statuses", Body: "{}",
+			}}},
+			{ActionBase: configloader.ActionBase{Name: "reportAgain", APICall: &configloader.APICall{
+				Method: "POST", URL: "/clusters/{{ .clusterId }}/statuses", Body: "{}",
+			}}},
+		}},
+	}
+}
+
+func TestCancellation_Preconditions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	apiClient := &cancellingAPIClient{MockClient: newMockAPIClient(), cancel: cancel}
+	apiClient.GetResponse = &hyperfleetapi.Response{StatusCode: 200, Status: "200 OK", Body: []byte(`{}`)}
+	transport := k8sclient.NewMockK8sClient()
+
+	config := cancelTestConfig()
+	getCluster := &configloader.APICall{Method: "GET", URL: "/clusters/{{ .clusterId }}"}
+	config.Preconditions = []configloader.Precondition{
+		{ActionBase: configloader.ActionBase{Name: "getCluster", APICall: getCluster}},
+		{ActionBase: configloader.ActionBase{Name: "getAgain", APICall: getCluster}},
+	}
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(apiClient).
+		WithTransportClient(transport).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+
+	result := exec.Execute(ctx, map[string]interface{}{"id": "cluster-1"})
+
+	assert.Equal(t, StatusFailed, result.Status)
+	require.NotNil(t, result.Cancelled)
+	assert.Equal(t, PhasePreconditions, result.Cancelled.Phase)
+	assert.Equal(t, 0, result.Cancelled.LastCompleted, "getCluster completed before the cancellation was seen")
+	assert.ErrorIs(t, result.Cancelled, context.Canceled)
+	assert.Len(t, apiClient.Requests, 1, "no further precondition nor post action is called")
+	assert.Empty(t, transport.Resources, "no resource is applied")
+	assert.Equal(t, ErrorCodeCancelled, ErrorCodeOf(primaryError(result)))
+}
+
+func TestCancellation_Resources(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	transport := newSlowTransport()
+	apiClient := newMockAPIClient()
+	exec, err := NewBuilder().
+		WithConfig(cancelTestConfig()).
+		WithAPIClient(apiClient).
+		WithTransportClient(transport).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+
+	done := make(chan *ExecutionResult)
+	go func() { done <- exec.Execute(ctx, map[string]interface{}{"id": "cluster-1"}) }()
+
+	// Cancel while the first apply is in progress, then let it finish
+	<-transport.entered
+	cancel()
+	transport.proceed <- struct{}{}
+	result := <-done
+
+	require.NotNil(t, result.Cancelled)
+	assert.Equal(t, PhaseResources, result.Cancelled.Phase)
+	assert.Equal(t, 0, result.Cancelled.LastCompleted)
+	require.Len(t, result.ResourceResults, 1, "the second resource is not applied")
+	assert.Equal(t, "first", result.ResourceResults[0].Name)
+	assert.Empty(t, apiClient.Requests, "no post action is called")
+	assert.True(t, errors.Is(result.Errors[PhasePostActions], ErrCancelled), "post actions are cancelled before running")
+}
+
+func TestCancellation_PostActions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	apiClient := &cancellingAPIClient{MockClient: newMockAPIClient(), cancel: cancel}
+	exec, err := NewBuilder().
+		WithConfig(cancelTestConfig()).
+		WithAPIClient(apiClient).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+
+	result := exec.Execute(ctx, map[string]interface{}{"id": "cluster-1"})
+
+	require.NotNil(t, result.Cancelled)
+	assert.Equal(t, PhasePostActions, result.Cancelled.Phase)
+	assert.Equal(t, 0, result.Cancelled.LastCompleted)
+	assert.Len(t, apiClient.Requests, 1, "reportAgain is not called")
+	assert.Len(t, result.ResourceResults, 2)
+}
+
+func TestCancellation_HandlerNacks(t *testing.T) {
+	exec, err := NewBuilder().
+		WithConfig(cancelTestConfig()).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	evt := eventtest.NewEvent().WithDataJSON(map[string]interface{}{"id": "cluster-1"}).Build()
+	err = exec.CreateHandler()(ctx, evt)
+
+	require.Error(t, err, "cancelled executions are redelivered")
+	assert.ErrorIs(t, err, ErrCancelled)
+	var cancelErr *CancelledError
+	require.ErrorAs(t, err, &cancelErr)
+	assert.Equal(t, PhaseResources, cancelErr.Phase)
+	assert.Equal(t, -1, cancelErr.LastCompleted)
+	var cause brokerconsumer.FailureCause
+	require.ErrorAs(t, err, &cause)
+	assert.Equal(t, string(PhaseResources), cause.FailurePhase())
+	assert.Equal(t, string(ErrorCodeCancelled), cause.FailureCode())
+}
@@ -0,0 +1,149 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// canonicalJSON returns the canonical JSON of value: object keys sorted, no
+// insignificant whitespace, strings without HTML escaping, and numbers in one
+// form, integers without fraction or exponent (2.0 is 2) and other numbers in
+// their shortest round-trip form. Arrays keep their order. Equal values always
+// marshal to the same bytes, whatever their Go types.
+func canonicalJSON(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeCanonicalJSON(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCanonicalJSON(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case string:
+		return writeCanonicalString(buf, v)
+	case json.Number:
+		return writeCanonicalNumber(buf, v)
+	case float64:
+		return writeCanonicalFloat(buf, v)
+	case float32:
+		return writeCanonicalFloat(buf, float64(v))
+	case int:
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case int32:
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case int64:
+		buf.WriteString(strconv.FormatInt(v, 10))
+	case uint:
+		buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint32:
+		buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint64:
+		buf.WriteString(strconv.FormatUint(v, 10))
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalString(buf, key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeCanonicalJSON(buf, v[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		// Other types, e.g. typed maps and slices, go through their JSON
+		// encoding and are canonicalized like decoded JSON
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var decoded interface{}
+		if err := decoder.Decode(&decoded); err != nil {
+			return err
+		}
+		return writeCanonicalJSON(buf, decoded)
+	}
+	return nil
+}
+
+// writeCanonicalString writes s as a JSON string, escaping only what JSON requires
+func writeCanonicalString(buf *bytes.Buffer, s string) error {
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(s); err != nil {
+		return err
+	}
+	// Encode terminates the value with a newline
+	buf.Write(bytes.TrimSuffix(encoded.Bytes(), []byte("\n")))
+	return nil
+}
+
+// writeCanonicalNumber writes a decoded JSON number: integers exactly, others
+// as float64
+func writeCanonicalNumber(buf *bytes.Buffer, n json.Number) error {
+	if i, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+		buf.WriteString(strconv.FormatInt(i, 10))
+		return nil
+	}
+	if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+		buf.WriteString(strconv.FormatUint(u, 10))
+		return nil
+	}
+	f, err := strconv.ParseFloat(n.String(), 64)
+	if err != nil {
+		return fmt.Errorf("invalid JSON number %q: %w", n, err)
+	}
+	return writeCanonicalFloat(buf, f)
+}
+
+// writeCanonicalFloat writes f like JavaScript's Number.prototype.toString:
+// integral values below 1e21 without fraction, others in their shortest form
+func writeCanonicalFloat(buf *bytes.Buffer, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("unsupported number %v", f)
+	}
+	if f == 0 {
+		// Negative zero is written as 0
+		buf.WriteByte('0')
+		return nil
+	}
+	// encoding/json formats floats like JavaScript
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	return nil
+}
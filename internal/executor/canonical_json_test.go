@@ -0,0 +1,151 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "sorted keys", value: map[string]interface{}{"b": 1, "a": map[string]interface{}{"d": true, "c": nil}},
+			want: `{"a":{"c":null,"d":true},"b":1}`},
+		{name: "array order kept", value: []interface{}{"c", "a", "b"}, want: `["c","a","b"]`},
+		{name: "integral float", value: 2.0, want: `2`},
+		{name: "fraction", value: 1.0 / 3, want: `0.3333333333333333`},
+		{name: "small float", value: 1e-7, want: `1e-7`},
+		{name: "large float", value: 1e21, want: `1e+21`},
+		{name: "negative zero", value: math.Copysign(0, -1), want: `0`},
+		{name: "json number integer", value: json.Number("2.0"), want: `2`},
+		{name: "json number exponent", value: json.Number("1E3"), want: `1000`},
+		{name: "json number large integer", value: json.Number("18446744073709551615"), want: `18446744073709551615`},
+		{name: "integers", value: []interface{}{int(-1), int32(2), int64(3), uint64(4)}, want: `[-1,2,3,4]`},
+		{name: "no HTML escaping", value: "<b>&</b>", want: `"<b>&</b>"`},
+		{name: "control characters", value: "a\"b\\c\nd\u0001", want: `"a\"b\\c\nd\u0001"`},
+		{name: "typed map", value: map[string]string{"b": "2", "a": "1"}, want: `{"a":"1","b":"2"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := canonicalJSON(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+
+	_, err := canonicalJSON(math.NaN())
+	assert.Error(t, err)
+	_, err = canonicalJSON(map[string]interface{}{"n": math.Inf(1)})
+	assert.Error(t, err)
+}
+
+// TestCanonicalJSON_Golden pins the canonical output of a representative payload
+func TestCanonicalJSON_Golden(t *testing.T) {
+	golden := filepath.Join("testdata", "payload", "canonical.golden")
+	data, err := os.ReadFile(filepath.Join("testdata", "payload", "canonical.yaml"))
+	require.NoError(t, err)
+	var build map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(data, &build))
+
+	pae := testPAE()
+	execCtx := clusterStatusExecCtx()
+	payload := configloader.Payload{Name: "canonicalPayload", Build: build, Canonical: true}
+	require.NoError(t, pae.buildPostPayloads(context.Background(), pae.log, []configloader.Payload{payload}, execCtx))
+	got, ok := execCtx.ParamsSnapshot()[payload.Name].(string)
+	require.True(t, ok, "payload should be stored as json string in params")
+
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(golden, []byte(got+"\n"), 0o600))
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, strings.TrimSuffix(string(want), "\n"), got)
+
+	// The change detection hash does not depend on the formatting
+	plain := configloader.Payload{Name: "plainPayload", Build: build}
+	require.NoError(t, pae.buildPostPayloads(context.Background(), pae.log, []configloader.Payload{plain}, execCtx))
+	plainJSON := execCtx.ParamsSnapshot()[plain.Name].(string)
+	assert.NotEqual(t, got, plainJSON)
+	assert.Equal(t, bodyHash([]byte(got)), bodyHash([]byte(plainJSON)))
+}
+
+// TestCanonicalJSON_SameInputsSameBytes builds random payloads twice from the
+// same values, inserting the map keys in different orders, and checks that
+// they always serialize identically, also after a JSON round trip
+func TestCanonicalJSON_SameInputsSameBytes(t *testing.T) {
+	rng := rand.New(rand.NewPCG(4216, 1))
+	for i := 0; i < 500; i++ {
+		seed := rng.Uint64()
+		first, err := canonicalJSON(randomValue(rand.New(rand.NewPCG(seed, 0)), 4, false))
+		require.NoError(t, err)
+		second, err := canonicalJSON(randomValue(rand.New(rand.NewPCG(seed, 0)), 4, true))
+		require.NoError(t, err)
+		require.Equal(t, string(first), string(second), "seed %d", seed)
+
+		var decoded interface{}
+		require.NoError(t, json.Unmarshal(first, &decoded), "seed %d", seed)
+		again, err := canonicalJSON(decoded)
+		require.NoError(t, err)
+		require.Equal(t, string(first), string(again), "seed %d: canonical JSON is a fixed point", seed)
+	}
+}
+
+// randomValue returns a random JSON value of up to depth levels. The values
+// only depend on rng; reverse inserts the keys of maps in reverse order.
+func randomValue(rng *rand.Rand, depth int, reverse bool) interface{} {
+	kind := rng.IntN(8)
+	if depth == 0 {
+		kind = rng.IntN(6)
+	}
+	switch kind {
+	case 0:
+		return nil
+	case 1:
+		return rng.IntN(2) == 0
+	case 2:
+		return rng.Int64N(1<<40) - 1<<39
+	case 3:
+		return float64(rng.IntN(1000)) / 8
+	case 4:
+		return rng.NormFloat64() * math.Pow(10, float64(rng.IntN(40)-20))
+	case 5:
+		return fmt.Sprintf("s<%d>&é", rng.IntN(100))
+	case 6:
+		items := make([]interface{}, rng.IntN(5))
+		for i := range items {
+			items[i] = randomValue(rng, depth-1, reverse)
+		}
+		return items
+	default:
+		n := rng.IntN(6)
+		keys := make([]string, n)
+		values := make([]interface{}, n)
+		for i := range keys {
+			keys[i] = fmt.Sprintf("k%d-%d", i, rng.IntN(20))
+			values[i] = randomValue(rng, depth-1, reverse)
+		}
+		m := make(map[string]interface{}, n)
+		for i := range keys {
+			j := i
+			if reverse {
+				j = n - 1 - i
+			}
+			m[keys[j]] = values[j]
+		}
+		return m
+	}
+}
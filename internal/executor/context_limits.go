@@ -0,0 +1,80 @@
+package executor
+
+import (
+	"bytes"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+)
+
+// limits returns the spec.limits of the execution, nil for the defaults
+func (ec *ExecutionContext) limits() *configloader.Limits {
+	if ec.Config == nil {
+		return nil
+	}
+	return ec.Config.Limits
+}
+
+// retainedResponseLimit returns the size at which a retained API response body
+// is cut: clients.hyperfleet_api.max_retained_response_bytes
+func retainedResponseLimit(execCtx *ExecutionContext) int {
+	if execCtx.Config == nil {
+		return hyperfleetapi.DefaultMaxRetainedResponseBytes
+	}
+	return execCtx.Config.Clients.HyperfleetAPI.RetainedResponseLimit()
+}
+
+// retainResponse returns the part of an API response body kept by the execution,
+// and whether it was cut. A body is cut to the retained response limit and to
+// what is left of the limits.max_retained_bytes budget of the execution, which
+// it is charged to. A cut body is copied so the full body is not kept alive.
+func (ec *ExecutionContext) retainResponse(body []byte) ([]byte, bool) {
+	limit := retainedResponseLimit(ec)
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	if remaining := ec.limits().RetainedBytesLimit() - ec.retainedBytes; remaining < limit {
+		limit = max(remaining, 0)
+	}
+	if len(body) <= limit {
+		ec.retainedBytes += len(body)
+		return body, false
+	}
+	ec.retainedBytes += limit
+	if limit == 0 {
+		return nil, true
+	}
+	return bytes.Clone(body[:limit]), true
+}
+
+// RetainedBytes returns the size of the API response bodies retained by the execution
+func (ec *ExecutionContext) RetainedBytes() int {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+	return ec.retainedBytes
+}
+
+// setRawResponse stores the parsed API response of a precondition under its
+// name, for later conditions and payloads to navigate until Compact
+func (ec *ExecutionContext) setRawResponse(name string, response interface{}) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	ec.params[name] = response
+	ec.rawResponses = append(ec.rawResponses, name)
+}
+
+// Compact drops what the execution no longer needs once its phases ran: the
+// parsed precondition API responses, summarized by the captures and the retained
+// response bodies, unless limits.keep_raw_responses is set. Called before the
+// result is finalized, so results held for logging, auditing or serialization
+// do not keep whole responses alive.
+func (ec *ExecutionContext) Compact() {
+	if ec.limits().KeepsRawResponses() {
+		return
+	}
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	for _, name := range ec.rawResponses {
+		delete(ec.params, name)
+	}
+	ec.rawResponses = nil
+}
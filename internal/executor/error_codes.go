@@ -0,0 +1,248 @@
+package executor
+
+import (
+	"context"
+	"errors"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/retrybudget"
+	apierrors "github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ErrorCode is the machine-readable code of an execution error, for clients
+// such as the HyperFleet UI that render distinct messages per failure kind.
+// Available to post payloads as adapter.executionError.code.
+type ErrorCode string
+
+const (
+	// ErrorCodeEventInvalid is event data that is malformed or violates its event schema
+	ErrorCodeEventInvalid ErrorCode = "EventInvalid"
+	// ErrorCodeEventTooLarge is event data over the clients.broker.max_event_bytes limit
+	ErrorCodeEventTooLarge ErrorCode = "EventTooLarge"
+	// ErrorCodeParamMissing is a required param that could not be extracted
+	ErrorCodeParamMissing ErrorCode = "ParamMissing"
+	// ErrorCodeParamInvalid is a required param that could not be converted to its type
+	ErrorCodeParamInvalid ErrorCode = "ParamInvalid"
+	// ErrorCodeSubjectMismatch is an event subject that matches none of the subject_patterns
+	ErrorCodeSubjectMismatch ErrorCode = "SubjectMismatch"
+	// ErrorCodeParamLookupFailed is the object of a k8s_field_ref param that could not be read
+	ErrorCodeParamLookupFailed ErrorCode = "ParamLookupFailed"
+	// ErrorCodeAPICallFailed is a HyperFleet API call that got no response
+	ErrorCodeAPICallFailed ErrorCode = "APICallFailed"
+	// ErrorCodeAPIUnexpectedStatus is a HyperFleet API call answered with a non-2xx status
+	ErrorCodeAPIUnexpectedStatus ErrorCode = "APIUnexpectedStatus"
+	// ErrorCodeAPIResponseInvalid is a HyperFleet API response that is not valid JSON
+	ErrorCodeAPIResponseInvalid ErrorCode = "APIResponseInvalid"
+	// ErrorCodeAPIResponseTooLarge is a response captured with capture_response_as that is
+	// over the clients.hyperfleet_api.max_retained_response_bytes limit
+	ErrorCodeAPIResponseTooLarge ErrorCode = "APIResponseTooLarge"
+	// ErrorCodeAPIContractViolation is a precondition API response violating an
+	// assertion of its expect block
+	ErrorCodeAPIContractViolation ErrorCode = "APIContractViolation"
+	// ErrorCodeAPITargetUnknown is an API call whose target resolved to an unconfigured name
+	ErrorCodeAPITargetUnknown ErrorCode = "APITargetUnknown"
+	// ErrorCodeCaptureMissing is a required capture that found no value in the API response
+	ErrorCodeCaptureMissing ErrorCode = "CaptureMissing"
+	// ErrorCodeCELCompileError is a CEL expression or environment that failed to compile
+	ErrorCodeCELCompileError ErrorCode = "CELCompileError"
+	// ErrorCodeCELEvaluationError is a CEL expression that failed at evaluation
+	ErrorCodeCELEvaluationError ErrorCode = "CELEvaluationError"
+	// ErrorCodeConditionEvaluationError is a structured condition that failed to evaluate
+	ErrorCodeConditionEvaluationError ErrorCode = "ConditionEvaluationError"
+	// ErrorCodeTemplateError is a Go template that failed to render
+	ErrorCodeTemplateError ErrorCode = "TemplateError"
+	// ErrorCodeManifestInvalid is a manifest rejected as invalid by the API server
+	ErrorCodeManifestInvalid ErrorCode = "ManifestInvalid"
+	// ErrorCodePolicyViolation is a rendered object the adapter's resource policy does not allow
+	ErrorCodePolicyViolation ErrorCode = "PolicyViolation"
+	// ErrorCodeApplyConflict is an apply that failed on a conflicting update
+	ErrorCodeApplyConflict ErrorCode = "ApplyConflict"
+	// ErrorCodeApplyFailed is any other failure to apply a resource
+	ErrorCodeApplyFailed ErrorCode = "ApplyFailed"
+	// ErrorCodeDiscoveryFailed is a resource that could not be discovered after apply
+	ErrorCodeDiscoveryFailed ErrorCode = "DiscoveryFailed"
+	// ErrorCodeTransportNotConfigured is a resource without a transport client
+	ErrorCodeTransportNotConfigured ErrorCode = "TransportNotConfigured"
+	// ErrorCodePatchFailed is a k8s_patch post action that failed to patch its object
+	ErrorCodePatchFailed ErrorCode = "PatchFailed"
+	// ErrorCodeWebhookFailed is a webhook post action whose delivery failed
+	ErrorCodeWebhookFailed ErrorCode = "WebhookFailed"
+	// ErrorCodePayloadBuildFailed is a post payload that failed to build
+	ErrorCodePayloadBuildFailed ErrorCode = "PayloadBuildFailed"
+	// ErrorCodeExecutionFenceTimeout is an execution that timed out waiting for another
+	// execution with the same execution_fence key
+	ErrorCodeExecutionFenceTimeout ErrorCode = "ExecutionFenceTimeout"
+	// ErrorCodeGenerationInvalid is an idempotency generation param that is not an integer
+	ErrorCodeGenerationInvalid ErrorCode = "GenerationInvalid"
+	// ErrorCodeIdempotencyCheckFailed is an idempotency store that could not be read
+	ErrorCodeIdempotencyCheckFailed ErrorCode = "IdempotencyCheckFailed"
+	// ErrorCodeWorkflowAmbiguous is an event selected by more than one workflow
+	ErrorCodeWorkflowAmbiguous ErrorCode = "WorkflowAmbiguous"
+	// ErrorCodeRetryBudgetExhausted is a retry denied because the execution spent its
+	// limits.retry_budget; the event is redelivered
+	ErrorCodeRetryBudgetExhausted ErrorCode = "RetryBudgetExhausted"
+	// ErrorCodeTimeout is an operation that exceeded its deadline
+	ErrorCodeTimeout ErrorCode = "Timeout"
+	// ErrorCodeCancelled is an execution stopped by the cancellation of its context
+	ErrorCodeCancelled ErrorCode = "Cancelled"
+	// ErrorCodeInternal is an error outside the other codes
+	ErrorCodeInternal ErrorCode = "Internal"
+)
+
+// ErrorCodes lists every ErrorCode
+var ErrorCodes = []ErrorCode{
+	ErrorCodeEventInvalid,
+	ErrorCodeEventTooLarge,
+	ErrorCodeParamMissing,
+	ErrorCodeParamInvalid,
+	ErrorCodeSubjectMismatch,
+	ErrorCodeParamLookupFailed,
+	ErrorCodeAPICallFailed,
+	ErrorCodeAPIUnexpectedStatus,
+	ErrorCodeAPIResponseInvalid,
+	ErrorCodeAPIResponseTooLarge,
+	ErrorCodeAPIContractViolation,
+	ErrorCodeAPITargetUnknown,
+	ErrorCodeCaptureMissing,
+	ErrorCodeCELCompileError,
+	ErrorCodeCELEvaluationError,
+	ErrorCodeConditionEvaluationError,
+	ErrorCodeTemplateError,
+	ErrorCodeManifestInvalid,
+	ErrorCodePolicyViolation,
+	ErrorCodeApplyConflict,
+	ErrorCodeApplyFailed,
+	ErrorCodeDiscoveryFailed,
+	ErrorCodeTransportNotConfigured,
+	ErrorCodePatchFailed,
+	ErrorCodeWebhookFailed,
+	ErrorCodePayloadBuildFailed,
+	ErrorCodeExecutionFenceTimeout,
+	ErrorCodeGenerationInvalid,
+	ErrorCodeIdempotencyCheckFailed,
+	ErrorCodeWorkflowAmbiguous,
+	ErrorCodeRetryBudgetExhausted,
+	ErrorCodeTimeout,
+	ErrorCodeCancelled,
+	ErrorCodeInternal,
+}
+
+// ErrorCodeOf returns ErrorCodeRetryBudgetExhausted for a retry denied by the
+// retry budget, whatever step it failed, otherwise the code of the
+// *ExecutorError in err's chain, ErrorCodeEventInvalid for event schema
+// violations, ErrorCodeTemplateError for template failures, ErrorCodeTimeout,
+// ErrorCodeCancelled or ErrorCodeInternal for other errors, "" for nil
+func ErrorCodeOf(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	if _, ok := retrybudget.IsExhausted(err); ok {
+		return ErrorCodeRetryBudgetExhausted
+	}
+	var execErr *ExecutorError
+	if errors.As(err, &execErr) && execErr.Code != "" {
+		return execErr.Code
+	}
+	var schemaErr *configloader.SchemaViolationError
+	if errors.As(err, &schemaErr) {
+		return ErrorCodeEventInvalid
+	}
+	if _, ok := IsTemplateError(err); ok {
+		return ErrorCodeTemplateError
+	}
+	if isTimeout(err) {
+		return ErrorCodeTimeout
+	}
+	if errors.Is(err, ErrCancelled) || errors.Is(err, context.Canceled) {
+		return ErrorCodeCancelled
+	}
+	return ErrorCodeInternal
+}
+
+// isTimeout reports whether err is a deadline exceeded locally or by the Kubernetes API server
+func isTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || k8serrors.IsTimeout(err) || k8serrors.IsServerTimeout(err)
+}
+
+// apiCallErrorCode classifies a failed HyperFleet API call
+func apiCallErrorCode(err error) ErrorCode {
+	if _, ok := IsTemplateError(err); ok {
+		return ErrorCodeTemplateError
+	}
+	if _, ok := hyperfleetapi.IsUnknownTargetError(err); ok {
+		return ErrorCodeAPITargetUnknown
+	}
+	if apiErr, ok := apierrors.IsAPIError(err); ok {
+		switch {
+		case apiErr.IsTimeout():
+			return ErrorCodeTimeout
+		case apiErr.StatusCode > 0:
+			return ErrorCodeAPIUnexpectedStatus
+		}
+	}
+	if isTimeout(err) {
+		return ErrorCodeTimeout
+	}
+	return ErrorCodeAPICallFailed
+}
+
+// applyErrorCode classifies a failed resource apply
+func applyErrorCode(err error) ErrorCode {
+	switch {
+	case isTimeout(err):
+		return ErrorCodeTimeout
+	case k8serrors.IsConflict(err):
+		return ErrorCodeApplyConflict
+	case k8serrors.IsInvalid(err), k8serrors.IsBadRequest(err):
+		return ErrorCodeManifestInvalid
+	default:
+		return ErrorCodeApplyFailed
+	}
+}
+
+// patchErrorCode classifies a failed k8s_patch post action
+func patchErrorCode(err error) ErrorCode {
+	switch {
+	case isTimeout(err):
+		return ErrorCodeTimeout
+	case k8serrors.IsConflict(err):
+		return ErrorCodeApplyConflict
+	case k8serrors.IsInvalid(err), k8serrors.IsBadRequest(err):
+		return ErrorCodeManifestInvalid
+	default:
+		return ErrorCodePatchFailed
+	}
+}
+
+// payloadBuildErrorCode classifies a post payload that failed to build
+func payloadBuildErrorCode(err error) ErrorCode {
+	if _, ok := IsTemplateError(err); ok {
+		return ErrorCodeTemplateError
+	}
+	return ErrorCodePayloadBuildFailed
+}
+
+// discoveryErrorCode classifies a failed resource discovery
+func discoveryErrorCode(err error) ErrorCode {
+	if _, ok := IsTemplateError(err); ok {
+		return ErrorCodeTemplateError
+	}
+	if isTimeout(err) {
+		return ErrorCodeTimeout
+	}
+	return ErrorCodeDiscoveryFailed
+}
+
+// celErrorCode classifies a failed CEL expression
+func celErrorCode(err error) ErrorCode {
+	if celErr, ok := apierrors.IsCELError(err); ok && !celErr.IsEval() {
+		return ErrorCodeCELCompileError
+	}
+	var envErr *apierrors.CELEnvError
+	if errors.As(err, &envErr) {
+		return ErrorCodeCELCompileError
+	}
+	return ErrorCodeCELEvaluationError
+}
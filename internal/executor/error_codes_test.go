@@ -0,0 +1,154 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	apierrors "github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Every construction of ExecutionError and ExecutorError in the package sets an
+// error code, so clients never get an execution error without one
+func TestErrorConstructionSitesSetCode(t *testing.T) {
+	fset := token.NewFileSet()
+	entries, err := os.ReadDir(".")
+	require.NoError(t, err)
+
+	sites := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		require.NoError(t, err)
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.CompositeLit:
+				ident, ok := n.Type.(*ast.Ident)
+				if !ok || (ident.Name != "ExecutionError" && ident.Name != "ExecutorError") {
+					return true
+				}
+				sites++
+				code := compositeField(n, "Code")
+				assert.True(t, code != nil && !isEmptyString(code),
+					"%s: %s literal without a Code", fset.Position(n.Pos()), ident.Name)
+			case *ast.CallExpr:
+				var fn string
+				switch f := n.Fun.(type) {
+				case *ast.Ident:
+					fn = f.Name
+				case *ast.SelectorExpr:
+					fn = f.Sel.Name
+				}
+				switch {
+				case fn == "NewExecutorError":
+					sites++
+					assert.False(t, isEmptyString(n.Args[1]), "%s: NewExecutorError with an empty code",
+						fset.Position(n.Pos()))
+				case fn == "SetError" && len(n.Args) == 3:
+					sites++
+					assert.False(t, isEmptyString(n.Args[2]), "%s: SetError with an empty code",
+						fset.Position(n.Pos()))
+				}
+			}
+			return true
+		})
+	}
+	assert.Greater(t, sites, 20, "construction sites were found")
+}
+
+func compositeField(lit *ast.CompositeLit, name string) ast.Expr {
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok && key.Name == name {
+				return kv.Value
+			}
+		}
+	}
+	return nil
+}
+
+func isEmptyString(expr ast.Expr) bool {
+	lit, ok := expr.(*ast.BasicLit)
+	return ok && lit.Kind == token.STRING && (lit.Value == `""` || lit.Value == "``")
+}
+
+func TestErrorCodesAreUnique(t *testing.T) {
+	seen := make(map[ErrorCode]bool)
+	for _, code := range ErrorCodes {
+		assert.NotEmpty(t, code)
+		assert.False(t, seen[code], "duplicate code %s", code)
+		seen[code] = true
+	}
+}
+
+func TestErrorCodeClassification(t *testing.T) {
+	gr := schema.GroupResource{Resource: "configmaps"}
+	statusErr := apierrors.NewAPIError("GET", "http://api/clusters/1", 404, "404 Not Found", nil, 1, 0,
+		errors.New("not found"))
+	networkErr := apierrors.NewAPIError("GET", "http://api/clusters/1", 0, "", nil, 3, 0,
+		errors.New("connection refused"))
+	timeoutErr := apierrors.NewAPIError("GET", "http://api/clusters/1", 0, "", nil, 1, 0,
+		context.DeadlineExceeded)
+
+	tests := []struct {
+		name     string
+		classify func(error) ErrorCode
+		err      error
+		want     ErrorCode
+	}{
+		{"API status", apiCallErrorCode, statusErr, ErrorCodeAPIUnexpectedStatus},
+		{"API no response", apiCallErrorCode, networkErr, ErrorCodeAPICallFailed},
+		{"API timeout", apiCallErrorCode, timeoutErr, ErrorCodeTimeout},
+		{"API target unknown", apiCallErrorCode, fmt.Errorf("resolve: %w", &hyperfleetapi.UnknownTargetError{Name: "x"}),
+			ErrorCodeAPITargetUnknown},
+		{"apply conflict", applyErrorCode, k8serrors.NewConflict(gr, "cm", errors.New("modified")),
+			ErrorCodeApplyConflict},
+		{"apply invalid", applyErrorCode, fmt.Errorf("apply: %w", k8serrors.NewInvalid(
+			schema.GroupKind{Kind: "ConfigMap"}, "cm", nil)), ErrorCodeManifestInvalid},
+		{"apply bad request", applyErrorCode, k8serrors.NewBadRequest("bad"), ErrorCodeManifestInvalid},
+		{"apply server timeout", applyErrorCode, k8serrors.NewServerTimeout(gr, "create", 1), ErrorCodeTimeout},
+		{"apply other", applyErrorCode, k8serrors.NewForbidden(gr, "cm", errors.New("rbac")),
+			ErrorCodeApplyFailed},
+		{"patch conflict", patchErrorCode, k8serrors.NewConflict(gr, "cm", errors.New("modified")),
+			ErrorCodeApplyConflict},
+		{"patch invalid", patchErrorCode, k8serrors.NewBadRequest("bad"), ErrorCodeManifestInvalid},
+		{"patch not found", patchErrorCode, k8serrors.NewNotFound(gr, "cm"), ErrorCodePatchFailed},
+		{"discovery", discoveryErrorCode, errors.New("not found"), ErrorCodeDiscoveryFailed},
+		{"discovery timeout", discoveryErrorCode, context.DeadlineExceeded, ErrorCodeTimeout},
+		{"CEL parse", celErrorCode, apierrors.NewCELParseError("a ==", nil), ErrorCodeCELCompileError},
+		{"CEL program", celErrorCode, apierrors.NewCELProgramError("a", errors.New("x")), ErrorCodeCELCompileError},
+		{"CEL env", celErrorCode, apierrors.NewCELEnvError("failed to initialize", errors.New("x")),
+			ErrorCodeCELCompileError},
+		{"CEL evaluation", celErrorCode, apierrors.NewCELEvalError("a", errors.New("no such key")),
+			ErrorCodeCELEvaluationError},
+		{"executor error", ErrorCodeOf, fmt.Errorf("resource execution failed: %w",
+			NewExecutorError(PhaseResources, ErrorCodeApplyConflict, "cm", "failed to apply resource", nil)),
+			ErrorCodeApplyConflict},
+		{"schema violation", ErrorCodeOf, &configloader.SchemaViolationError{EventType: "cluster.created"},
+			ErrorCodeEventInvalid},
+		{"deadline", ErrorCodeOf, fmt.Errorf("wrapped: %w", context.DeadlineExceeded), ErrorCodeTimeout},
+		{"other", ErrorCodeOf, errors.New("boom"), ErrorCodeInternal},
+		{"nil", ErrorCodeOf, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.classify(tt.err))
+		})
+	}
+}
@@ -1,23 +1,41 @@
 package executor
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mime"
 	"reflect"
 	"strings"
 
 	"time"
 
 	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/google/uuid"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/audit"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/delivery"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/featureflags"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/idempotency"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/journal"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/lookup"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/postbuffer"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/retrybudget"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/schedule"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/webhook"
+	apierrors "github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/errors"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/health"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
 	pkgotel "github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/otel"
-	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/yaml"
 )
 
 // NewExecutor creates a new Executor with the given configuration
@@ -26,15 +44,94 @@ func NewExecutor(config *ExecutorConfig) (*Executor, error) {
 		return nil, err
 	}
 
+	// No-op for schemas already compiled at config load
+	if err := configloader.CompileEventSchemas(config.Config.EventSchemas); err != nil {
+		return nil, fmt.Errorf("invalid event schemas: %w", err)
+	}
+
+	compiled, err := compileConfig(config.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.WebhookClient == nil {
+		client, err := webhook.NewClient(config.Config.Clients.Webhook, nil, config.Logger, config.Clock)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook client: %w", err)
+		}
+		config.WebhookClient = client
+	}
+
+	runtime := RuntimeMetadataFromEnv("")
+	if config.Runtime != nil {
+		runtime = *config.Runtime
+	}
+
+	generations := config.Idempotency
+	if idem := config.Config.Idempotency; idem != nil && generations == nil {
+		generations = idempotency.NewMemoryStore(idem.MaxKeys)
+	}
+
+	tables := config.LookupTables
+	if tables == nil {
+		if tables, err = lookup.New(config.Config.Maps); err != nil {
+			return nil, fmt.Errorf("invalid maps: %w", err)
+		}
+	}
+
+	clk := clock.OrReal(config.Clock)
 	return &Executor{
 		config:             config,
 		precondExecutor:    newPreconditionExecutor(config),
+		precondLevels:      compiled.precondLevels,
+		subjectPatterns:    compiled.subjectPatterns,
 		resourceExecutor:   newResourceExecutor(config),
 		postActionExecutor: newPostActionExecutor(config),
 		log:                config.Logger,
+		clock:              clk,
+		runtime:            runtime,
+		stats:              &executorStats{startedAt: clk.Now(), cacheSizes: make(map[string]func() int)},
+		fence:              newExecutionFence(DefaultExecutionFenceMaxKeys, clk),
+		notMet:             newNotMetTracker(DefaultNotMetMaxKeys, clk),
+		schedule:           compiled.schedule,
+		idempotency:        generations,
+		lookup:             tables,
+		templateFuncs:      lookupTemplateFuncs(tables),
 	}, nil
 }
 
+// compiledConfig holds the parts of a config the executor compiles once
+type compiledConfig struct {
+	schedule        *schedule.Schedule
+	precondLevels   map[string][][]int
+	subjectPatterns []*configloader.SubjectPattern
+}
+
+// compileConfig compiles the maintenance schedule, parses the subject patterns
+// and orders the preconditions of every workflow. Semantic validation does the
+// same at config load, but configs may be loaded without it, so their errors
+// are reported again here.
+func compileConfig(cfg *configloader.Config) (*compiledConfig, error) {
+	compiled := &compiledConfig{precondLevels: make(map[string][][]int)}
+	var err error
+	if cfg.Schedule != nil {
+		if compiled.schedule, err = cfg.Schedule.Compile(); err != nil {
+			return nil, fmt.Errorf("invalid schedule: %w", err)
+		}
+	}
+	if compiled.subjectPatterns, err = cfg.ParsedSubjectPatterns(); err != nil {
+		return nil, fmt.Errorf("invalid subject patterns: %w", err)
+	}
+
+	for _, workflow := range cfg.AllWorkflows() {
+		compiled.precondLevels[workflow.Name], err = configloader.PreconditionLevels(workflow.Preconditions)
+		if err != nil {
+			return nil, fmt.Errorf("invalid preconditions of workflow %q: %w", workflow.Name, err)
+		}
+	}
+	return compiled, nil
+}
+
 func validateExecutorConfig(config *ExecutorConfig) error {
 	if config == nil {
 		return fmt.Errorf("config is required")
@@ -61,24 +158,166 @@ func validateExecutorConfig(config *ExecutorConfig) error {
 // Execute processes event data according to the adapter configuration
 // The caller is responsible for:
 // - Adding event ID to context for logging correlation using logger.WithEventID()
+//
+// Event schemas are not applied since the event type is unknown; use ExecuteEvent for CloudEvents.
 func (e *Executor) Execute(ctx context.Context, data interface{}) *ExecutionResult {
+	return e.execute(ctx, eventMeta{}, data)
+}
+
+// ExecuteEvent processes a CloudEvent according to the adapter configuration.
+// The event data is decoded according to its data content type (JSON or YAML) and
+// validated against the event schema registered for the event type, if any. Events
+// whose content type is not allowed by event_filter are skipped without executing.
+func (e *Executor) ExecuteEvent(ctx context.Context, evt *event.Event) *ExecutionResult {
+	return e.execute(ctx, eventMetaOf(evt), evt.Data())
+}
+
+// eventMeta is the CloudEvent metadata of an execution, empty for Execute
+type eventMeta struct {
+	id            string
+	eventType     string
+	contentType   string
+	correlationID string
+	subject       string
+}
+
+// eventMetaOf returns the metadata of evt
+func eventMetaOf(evt *event.Event) eventMeta {
+	return eventMeta{
+		id:            evt.ID(),
+		eventType:     evt.Type(),
+		contentType:   evt.DataContentType(),
+		correlationID: CorrelationIDOf(evt),
+		subject:       evt.Subject(),
+	}
+}
+
+// CorrelationIDExtension is the CloudEvent extension whose value is adopted as
+// the correlation ID of the execution
+const CorrelationIDExtension = "correlationid"
+
+// CorrelationIDOf returns the correlation ID carried by the CorrelationIDExtension
+// of evt, empty if it has none
+func CorrelationIDOf(evt *event.Event) string {
+	if correlationID, ok := evt.Extensions()[CorrelationIDExtension].(string); ok {
+		return strings.TrimSpace(correlationID)
+	}
+	return ""
+}
+
+func (e *Executor) execute(
+	ctx context.Context, meta eventMeta, data interface{},
+) *ExecutionResult {
+	if meta.id != "" {
+		ctx = logger.WithEventID(ctx, meta.id)
+	}
+	// Every execution is correlated: a correlation ID not set by the event producer is generated
+	if meta.correlationID == "" {
+		meta.correlationID = uuid.NewString()
+	}
+	ctx = logger.WithCorrelationID(ctx, meta.correlationID)
 	// Start OTel span and add trace context to logs
-	ctx, span := e.startTracedExecution(ctx)
-	defer span.End()
+	ctx, span := e.startTracedExecution(ctx, meta)
 
-	// Parse event data
-	eventData, rawData, err := ParseEventData(data)
-	if err != nil {
-		parseErr := fmt.Errorf("failed to parse event data: %w", err)
-		errCtx := logger.WithErrorField(ctx, parseErr)
-		e.log.Errorf(errCtx, "Failed to parse event data")
+	// Decremented even if execution panics
+	e.inFlight.Add(1)
+	defer e.inFlight.Add(-1)
+	started := e.clock.Now()
+	result := e.executePhases(ctx, meta, data)
+	result.Duration = e.clock.Since(started)
+	if result.ExecutionContext != nil {
+		result.RetryBudget = result.ExecutionContext.retryBudget.Report()
+		result.FeatureFlags = result.ExecutionContext.flags
+	}
+	e.observeStepDurations(result)
+	result.TraceID = traceIDOf(ctx)
+	result.CorrelationID = meta.correlationID
+	result.DeliveryAttempt = delivery.AttemptFromContext(ctx)
+	e.finishExecution(result)
+	var err error
+	if result.Status == StatusFailed {
+		err = primaryError(result)
+	}
+	endSpan(span, executionOutcome(result), err)
+	return result
+}
+
+// executePhases runs the execution phases, each in a child span of ctx's span.
+func (e *Executor) executePhases(
+	ctx context.Context, meta eventMeta, data interface{},
+) *ExecutionResult {
+	dataContentType := eventDataMediaType(meta.contentType)
+
+	// The broker consumer rejects oversized events before they reach the
+	// executor; checked again for the other entry points
+	if err := e.checkEventSize(data); err != nil {
+		errCtx := logger.WithErrorField(ctx, err)
+		e.log.Errorf(errCtx, "Event data rejected")
 		return &ExecutionResult{
-			Status:       StatusFailed,
-			CurrentPhase: PhaseParamExtraction,
-			Errors:       map[ExecutionPhase]error{PhaseParamExtraction: parseErr},
+			Status:          StatusFailed,
+			CurrentPhase:    PhaseParamExtraction,
+			Errors:          map[ExecutionPhase]error{PhaseParamExtraction: err},
+			DataContentType: dataContentType,
 		}
 	}
 
+	// Events whose data content type is not allowed are acknowledged without executing
+	if !e.allowsDataContentType(dataContentType) {
+		return e.filteredEvent(ctx, dataContentType)
+	}
+	decision := DataAccepted
+	if key := e.config.Config.PlainTextDataKey; key != "" && dataContentType == ContentTypePlainText {
+		data, decision = wrapPlainTextData(data, key), DataWrapped
+	}
+	ctx = logger.WithDataContentType(ctx, dataContentType)
+
+	// Decode non-JSON payloads up front so every later phase sees the same JSON document
+	data, dataErr := normalizeEventData(data, meta.contentType)
+	if dataErr == nil {
+		// Validate event data against the schema registered for the event type before
+		// decoding it, so type mismatches are reported as violations with JSON pointers.
+		// Violations are permanent: redelivering the same event cannot succeed.
+		schemaCtx, schemaSpan := e.startPhaseSpan(logger.WithDataDecision(ctx, string(decision)), PhaseSchemaValidation)
+		if violations := e.validateEventSchema(meta.eventType, data); len(violations) > 0 {
+			schemaErr := &configloader.SchemaViolationError{EventType: meta.eventType, Violations: violations}
+			errCtx := logger.WithErrorField(schemaCtx, schemaErr)
+			e.log.Errorf(errCtx, "Phase %s: FAILED", PhaseSchemaValidation)
+			endSpan(schemaSpan, string(StatusFailed), schemaErr)
+			return &ExecutionResult{
+				Status:           StatusFailed,
+				CurrentPhase:     PhaseSchemaValidation,
+				Errors:           map[ExecutionPhase]error{PhaseSchemaValidation: schemaErr},
+				SchemaViolations: violations,
+				DataContentType:  dataContentType,
+				DataDecision:     decision,
+			}
+		}
+		endSpan(schemaSpan, string(StatusSuccess), nil)
+	}
+
+	// Parse event data
+	var eventData *EventData
+	var rawData map[string]interface{}
+	if dataErr == nil {
+		eventData, rawData, dataErr = ParseEventData(data)
+	}
+	// Data of an allowed content type that cannot be decoded is a permanent failure.
+	// With report_param_failures it is reported by the post actions like a param
+	// extraction failure, with empty event data.
+	var paramErr error
+	if dataErr != nil {
+		decision = DataInvalid
+		failure := e.eventDataFailure(logger.WithDataDecision(ctx, string(decision)), dataErr)
+		failure.DataContentType = dataContentType
+		failure.DataDecision = decision
+		if !e.config.Config.ReportParamFailures {
+			return failure
+		}
+		eventData, rawData = &EventData{}, make(map[string]interface{})
+		paramErr = failure.Errors[PhaseParamExtraction]
+	}
+	ctx = logger.WithDataDecision(ctx, string(decision))
+
 	// This is intended to set OwnerReferences and ResourceID for the event when it exists
 	// For example, when a NodePool event arrived
 	// the logger will set the cluster_id=owner_id, nodepool_id=resource_id, resource_type=nodepool
@@ -92,49 +331,150 @@ func (e *Executor) Execute(ctx context.Context, data interface{}) *ExecutionResu
 	}
 
 	execCtx := NewExecutionContext(ctx, rawData, e.config.Config)
+	execCtx.clock = e.clock
+	execCtx.flags = e.config.FeatureFlags.Snapshot()
+	execCtx.lookup, execCtx.templateFuncs = e.lookup, e.templateFuncs
+	execCtx.Adapter.Runtime = e.runtime
+	execCtx.Adapter.CorrelationID = meta.correlationID
 
 	// Initialize execution result
 	result := &ExecutionResult{
-		Status:       StatusSuccess,
-		Params:       make(map[string]interface{}),
-		Errors:       make(map[ExecutionPhase]error),
-		CurrentPhase: PhaseParamExtraction,
+		Status:          StatusSuccess,
+		Params:          make(map[string]interface{}),
+		Errors:          make(map[ExecutionPhase]error),
+		PhaseDurations:  make(map[ExecutionPhase]time.Duration),
+		CurrentPhase:    PhaseParamExtraction,
+		DataContentType: dataContentType,
+		DataDecision:    decision,
 	}
 
 	e.log.Info(ctx, "Processing event")
 
-	// Phase 1: Parameter Extraction
-	e.log.Infof(ctx, "Phase %s: RUNNING", result.CurrentPhase)
-	if paramErr := e.executeParamExtraction(execCtx); paramErr != nil {
+	// Phase 1: Parameter Extraction (skip for invalid event data, which already failed it)
+	phaseCtx, phaseSpan := e.startPhaseSpan(ctx, PhaseParamExtraction)
+	e.log.Infof(phaseCtx, "Phase %s: RUNNING", result.CurrentPhase)
+	started := e.clock.Now()
+	skipReason := "EventDataInvalid"
+	if paramErr == nil {
+		paramErr = e.executeParamExtraction(execCtx, meta.subject)
+		skipReason = "ParameterExtractionFailed"
+	}
+	result.PhaseDurations[PhaseParamExtraction] = e.clock.Since(started)
+	if paramErr != nil {
 		result.Status = StatusFailed
 		result.Errors[PhaseParamExtraction] = paramErr
-		execCtx.SetError("ParameterExtractionFailed", paramErr.Error())
+		execCtx.SetError(skipReason, paramErr.Error(), ErrorCodeOf(paramErr))
 		resErr := fmt.Errorf("parameter extraction failed: %w", paramErr)
-		errCtx := logger.WithErrorField(ctx, resErr)
+		errCtx := logger.WithErrorField(phaseCtx, resErr)
 		e.log.Errorf(errCtx, "Phase %s: FAILED", PhaseParamExtraction)
+		endSpan(phaseSpan, string(StatusFailed), paramErr)
 		result.ExecutionContext = execCtx
-		result.Params = execCtx.Params
-		return result
+		result.Params = execCtx.ParamsSnapshot()
+		if !e.config.Config.ReportParamFailures {
+			return result
+		}
+		// Skip preconditions and resources, but run the post actions to report the failure
+		result.ResourcesSkipped = true
+		result.SkipReason = skipReason
+		execCtx.Adapter.ResourcesSkipped = true
+		execCtx.Adapter.SkipReason = paramErr.Error()
+	} else {
+		e.log.Debugf(phaseCtx, "Parameter extraction completed: extracted %d params", len(execCtx.ParamsSnapshot()))
+		endSpan(phaseSpan, string(StatusSuccess), nil)
 	}
-	result.Params = execCtx.Params
-	e.log.Debugf(ctx, "Parameter extraction completed: extracted %d params", len(execCtx.Params))
 
-	// Phase 2: Preconditions
+	// Select the workflow whose phases run. After a reported param extraction
+	// failure, the post actions of the default workflow report it.
+	workflow := e.config.Config.DefaultWorkflow()
+	if len(e.config.Config.Workflows) > 0 && result.Errors[PhaseParamExtraction] == nil {
+		started = e.clock.Now()
+		selected, selectErr := e.selectWorkflow(ctx, meta.eventType, execCtx)
+		result.PhaseDurations[PhaseWorkflowSelection] = e.clock.Since(started)
+		if selectErr != nil {
+			result.Status = StatusFailed
+			result.CurrentPhase = PhaseWorkflowSelection
+			result.Errors[PhaseWorkflowSelection] = selectErr
+			result.ExecutionContext = execCtx
+			result.Params = execCtx.ParamsSnapshot()
+			return result
+		}
+		workflow = selected
+	}
+	result.Workflow = workflow.Name
+	execCtx.SetWorkflow(workflow.Name)
+
+	// Wait for the other executions with the same key, then hold it until the end
+	if fence := e.config.Config.ExecutionFence; fence != nil && result.Errors[PhaseParamExtraction] == nil {
+		started = e.clock.Now()
+		release, fenceErr := e.enterExecutionFence(ctx, fence, execCtx, result)
+		result.PhaseDurations[PhaseExecutionFence] = e.clock.Since(started)
+		if fenceErr != nil {
+			result.Status = StatusFailed
+			result.CurrentPhase = PhaseExecutionFence
+			result.Errors[PhaseExecutionFence] = fenceErr
+			result.ExecutionContext = execCtx
+			result.Params = execCtx.ParamsSnapshot()
+			result.RetryAfter = retryAfterOf(fenceErr)
+			return result
+		}
+		defer release()
+	}
+
+	// Track the not-met streak of the key, reset by any other outcome. Once the
+	// streak is past the threshold, the preconditions log at debug level, and so
+	// does the rest of the execution if they are not met again.
+	var notMetKey string
+	var escalated bool
+	if result.Errors[PhaseParamExtraction] == nil {
+		notMetKey = e.renderNotMetKey(ctx, execCtx)
+		escalated = notMetKey != "" && e.notMet.count(notMetKey) >= notMetThreshold(e.config.Config.NotMetBackoff)
+	}
+
+	// Outside every maintenance window, the defer policy skips the preconditions
+	// and asks for redelivery when the next window opens, while skipResources
+	// skips only the resources
+	var gate scheduleGate
+	if result.Errors[PhaseParamExtraction] == nil {
+		gate = e.evaluateSchedule(ctx, execCtx)
+	}
+	deferred := e.schedule != nil && gate.deferred(e.config.Config.Schedule.EffectivePolicy())
+	if deferred {
+		skipOutsideSchedule(result, execCtx, gate)
+		result.RetryAfter = gate.retryAfter()
+	}
+
+	// Phase 2: Preconditions (skip after a reported param extraction failure or outside the schedule)
 	result.CurrentPhase = PhasePreconditions
-	preconditions := e.config.Config.Preconditions
-	e.log.Infof(ctx, "Phase %s: RUNNING - %d configured", result.CurrentPhase, len(preconditions))
-	precondOutcome := e.precondExecutor.ExecuteAll(ctx, preconditions, execCtx)
-	result.PreconditionResults = precondOutcome.Results
+	e.enterPhase(result.CurrentPhase)
+	preconditions := workflow.Preconditions
+	phaseCtx, phaseSpan = e.startPhaseSpan(ctx, result.CurrentPhase)
+	e.log.Infof(phaseCtx, "Phase %s: RUNNING - %d configured", result.CurrentPhase, len(preconditions))
+	var precondOutcome *PreconditionsOutcome
+	if result.Errors[PhaseParamExtraction] == nil && !deferred {
+		precondCtx := phaseCtx
+		if escalated {
+			precondCtx = logger.WithInfoAsDebug(phaseCtx)
+		}
+		started = e.clock.Now()
+		precondOutcome = e.precondExecutor.ExecuteAll(precondCtx, preconditions, e.precondLevels[workflow.Name], execCtx)
+		result.PhaseDurations[PhasePreconditions] = e.clock.Since(started)
+		result.PreconditionResults = precondOutcome.Results
+		result.PreconditionErrors = precondOutcome.Errors
+	}
 
 	switch {
+	case precondOutcome == nil:
+		e.log.Infof(phaseCtx, "Phase %s: SKIPPED - %s", result.CurrentPhase, result.SkipReason)
+		endSpan(phaseSpan, SpanStatusSkipped, nil)
 	case precondOutcome.Error != nil:
 		// Process execution error: precondition evaluation failed
 		result.Status = StatusFailed
 		precondErr := fmt.Errorf("precondition evaluation failed: error=%w", precondOutcome.Error)
 		result.Errors[result.CurrentPhase] = precondErr
-		execCtx.SetError("PreconditionFailed", precondOutcome.Error.Error())
-		errCtx := logger.WithErrorField(ctx, precondOutcome.Error)
+		execCtx.SetError("PreconditionFailed", precondOutcome.Error.Error(), ErrorCodeOf(precondOutcome.Error))
+		errCtx := logger.WithErrorField(phaseCtx, precondOutcome.Error)
 		e.log.Errorf(errCtx, "Phase %s: FAILED", result.CurrentPhase)
+		endSpan(phaseSpan, string(StatusFailed), precondOutcome.Error)
 		result.ResourcesSkipped = true
 		result.SkipReason = "PreconditionFailed"
 		// Set skip metadata on adapter context without overwriting the failed execution status
@@ -147,59 +487,131 @@ func (e *Executor) Execute(ctx context.Context, data interface{}) *ExecutionResu
 		// Business outcome: precondition not satisfied
 		result.ResourcesSkipped = true
 		result.SkipReason = precondOutcome.NotMetReason
+		result.RetryAfter = precondOutcome.RetryAfter
 		execCtx.SetSkipped("PreconditionNotMet", precondOutcome.NotMetReason)
-		e.log.Infof(ctx, "Phase %s: SUCCESS - NOT_MET - %s", result.CurrentPhase, precondOutcome.NotMetReason)
+		if e.escalateNotMet(phaseCtx, result, notMetKey, precondOutcome.NotMetReason) {
+			ctx = logger.WithInfoAsDebug(ctx)
+		}
+		endSpan(phaseSpan, SpanStatusNotMet, nil)
 	default:
 		// All preconditions matched
-		e.log.Infof(ctx, "Phase %s: SUCCESS - MET - %d passed", result.CurrentPhase, len(precondOutcome.Results))
+		e.log.Infof(phaseCtx, "Phase %s: SUCCESS - MET - %d passed", result.CurrentPhase, len(precondOutcome.Results))
+		endSpan(phaseSpan, string(StatusSuccess), nil)
+		if gate.closed {
+			skipOutsideSchedule(result, execCtx, gate)
+		}
+	}
+
+	// Skip the resources of an event whose generation is not newer than the last
+	// one processed for its key, e.g. a redelivery arriving out of order
+	var guard generationGuard
+	idemConfig := e.config.Config.Idempotency
+	if idemConfig != nil && !result.ResourcesSkipped {
+		var guardErr error
+		guard, guardErr = e.checkGeneration(ctx, idemConfig, execCtx)
+		switch {
+		case guardErr != nil:
+			result.Status = StatusFailed
+			result.Errors[PhaseResources] = fmt.Errorf("idempotency check failed: %w", guardErr)
+			execCtx.SetError("ResourceFailed", guardErr.Error(), ErrorCodeOf(guardErr))
+			e.log.Errorf(logger.WithErrorField(ctx, guardErr), "Idempotency check: FAILED")
+			result.ResourcesSkipped = true
+			result.SkipReason = "IdempotencyCheckFailed"
+			execCtx.Adapter.ResourcesSkipped = true
+			execCtx.Adapter.SkipReason = guardErr.Error()
+		case guard.stale:
+			e.log.Infof(ctx, "Idempotency check: STALE - %s", guard.message)
+			skipStaleGeneration(result, execCtx, guard)
+		}
 	}
 
 	// Phase 3: Resources (skip if preconditions not met or previous error)
 	result.CurrentPhase = PhaseResources
-	resources := e.config.Config.Resources
-	e.log.Infof(ctx, "Phase %s: RUNNING - %d configured", result.CurrentPhase, len(resources))
+	e.enterPhase(result.CurrentPhase)
+	resources := workflow.Resources
+	phaseCtx, phaseSpan = e.startPhaseSpan(ctx, result.CurrentPhase)
+	e.log.Infof(phaseCtx, "Phase %s: RUNNING - %d configured", result.CurrentPhase, len(resources))
 	if !result.ResourcesSkipped {
-		resourceResults, resourceErr := e.resourceExecutor.ExecuteAll(ctx, resources, execCtx)
+		started = e.clock.Now()
+		resourceResults, resourceErr := e.resourceExecutor.ExecuteAll(phaseCtx, resources, execCtx)
+		result.PhaseDurations[PhaseResources] = e.clock.Since(started)
 		result.ResourceResults = resourceResults
 
 		if resourceErr != nil {
 			result.Status = StatusFailed
 			resErr := fmt.Errorf("resource execution failed: %w", resourceErr)
 			result.Errors[result.CurrentPhase] = resErr
-			execCtx.SetError("ResourceFailed", resourceErr.Error())
-			errCtx := logger.WithErrorField(ctx, resourceErr)
+			execCtx.SetError("ResourceFailed", resourceErr.Error(), ErrorCodeOf(resourceErr))
+			errCtx := logger.WithErrorField(phaseCtx, resourceErr)
 			e.log.Errorf(errCtx, "Phase %s: FAILED", result.CurrentPhase)
+			endSpan(phaseSpan, string(StatusFailed), resourceErr)
 			// Continue to post actions for error reporting
 		} else {
-			e.log.Infof(ctx, "Phase %s: SUCCESS - %d processed", result.CurrentPhase, len(resourceResults))
+			e.log.Infof(phaseCtx, "Phase %s: SUCCESS - %d processed", result.CurrentPhase, len(resourceResults))
+			endSpan(phaseSpan, string(StatusSuccess), nil)
 		}
 	} else {
-		e.log.Infof(ctx, "Phase %s: SKIPPED - %s", result.CurrentPhase, result.SkipReason)
+		e.log.Infof(phaseCtx, "Phase %s: SKIPPED - %s", result.CurrentPhase, result.SkipReason)
+		endSpan(phaseSpan, SpanStatusSkipped, nil)
 	}
+	execCtx.SetResourceSummary(summarizeResources(resources, result.ResourceResults))
 
-	// Phase 4: Post Actions (always execute for error reporting)
+	// Phase 4: Post Actions (always execute for error reporting). Once resources
+	// were applied, the execution is journaled until its post actions ran, so
+	// they are recovered if the adapter stops in between.
+	var journalID string
+	if len(result.ResourceResults) > 0 {
+		journalID = e.journalExecution(ctx, meta, execCtx)
+	}
 	result.CurrentPhase = PhasePostActions
-	postConfig := e.config.Config.Post
+	e.enterPhase(result.CurrentPhase)
+	postConfig := workflow.Post
+	if guard.stale && idemConfig.EffectivePostActions() == configloader.IdempotencyPostSkip {
+		e.log.Infof(ctx, "Post actions skipped for the stale generation")
+		postConfig = nil
+	}
 	postActionCount := 0
 	if postConfig != nil {
 		postActionCount = len(postConfig.PostActions)
 	}
-	e.log.Infof(ctx, "Phase %s: RUNNING - %d configured", result.CurrentPhase, postActionCount)
-	postResults, err := e.postActionExecutor.ExecuteAll(ctx, postConfig, execCtx)
+	phaseCtx, phaseSpan = e.startPhaseSpan(ctx, result.CurrentPhase)
+	e.log.Infof(phaseCtx, "Phase %s: RUNNING - %d configured", result.CurrentPhase, postActionCount)
+	started = e.clock.Now()
+	postResults, err := e.postActionExecutor.ExecuteAll(phaseCtx, postConfig, execCtx)
+	result.PhaseDurations[PhasePostActions] = e.clock.Since(started)
 	result.PostActionResults = postResults
+	// A failed post action is retried by the redelivery of the event, not by recovery
+	e.clearJournal(ctx, journalID)
 
 	if err != nil {
 		result.Status = StatusFailed
 		postErr := fmt.Errorf("post action execution failed: %w", err)
 		result.Errors[result.CurrentPhase] = postErr
-		errCtx := logger.WithErrorField(ctx, err)
+		errCtx := logger.WithErrorField(phaseCtx, err)
 		e.log.Errorf(errCtx, "Phase %s: FAILED", result.CurrentPhase)
+		endSpan(phaseSpan, string(StatusFailed), err)
 	} else {
-		e.log.Infof(ctx, "Phase %s: SUCCESS - %d executed", result.CurrentPhase, len(postResults))
+		e.log.Infof(phaseCtx, "Phase %s: SUCCESS - %d executed", result.CurrentPhase, len(postResults))
+		endSpan(phaseSpan, string(StatusSuccess), nil)
 	}
 
 	// Finalize
+	execCtx.Compact()
 	result.ExecutionContext = execCtx
+	result.Params = execCtx.ParamsSnapshot()
+	result.APICalls = execCtx.GetAPICalls()
+	result.PayloadReports = execCtx.GetPayloadReports()
+	if result.Status == StatusFailed {
+		result.RetryAfter = retryAfterOf(primaryError(result))
+		result.Cancelled = firstCancellation(result)
+	}
+	if result.Status == StatusSuccess && !result.ResourcesSkipped {
+		e.recordGeneration(ctx, guard)
+	}
+	// A deferred execution, whose preconditions did not run, neither extends nor resets the streak
+	if notMetKey != "" && (result.Status == StatusFailed || (precondOutcome != nil && precondOutcome.AllMatched)) {
+		e.notMet.reset(notMetKey)
+	}
 
 	if result.Status == StatusSuccess {
 		e.log.Infof(ctx,
@@ -218,42 +630,101 @@ func (e *Executor) Execute(ctx context.Context, data interface{}) *ExecutionResu
 	return result
 }
 
-// executeParamExtraction extracts parameters from the event and environment
-func (e *Executor) executeParamExtraction(execCtx *ExecutionContext) error {
+// enterPhase calls the beforePhase hook, if any
+func (e *Executor) enterPhase(phase ExecutionPhase) {
+	if e.beforePhase != nil {
+		e.beforePhase(phase)
+	}
+}
+
+// enterExecutionFence renders the execution key into result and waits for the
+// key to be free. The wait is limited by the fence timeout; an execution that
+// times out asks for redelivery after the timeout.
+func (e *Executor) enterExecutionFence(
+	ctx context.Context, fence *configloader.ExecutionFence, execCtx *ExecutionContext, result *ExecutionResult,
+) (func(), error) {
+	phaseCtx, phaseSpan := e.startPhaseSpan(ctx, PhaseExecutionFence)
+	key, err := renderTemplateAt("execution_fence.key", fence.Key, execCtx.templateParams())
+	if err != nil {
+		fenceErr := NewExecutorError(PhaseExecutionFence, ErrorCodeTemplateError, "key", "failed to render key", err)
+		e.log.Errorf(logger.WithErrorField(phaseCtx, fenceErr), "Phase %s: FAILED", PhaseExecutionFence)
+		endSpan(phaseSpan, string(StatusFailed), fenceErr)
+		return nil, fenceErr
+	}
+	result.ExecutionKey = key
+	execCtx.executionKey = key
+
+	timeout := fence.Timeout
+	if timeout == 0 {
+		timeout = DefaultExecutionFenceTimeout
+	}
+	started := e.clock.Now()
+	release, err := e.fence.acquire(ctx, key, timeout)
+	waited := e.clock.Since(started)
+	e.config.MetricsRecorder.ObserveExecutionFenceWait(waited)
+	if err != nil {
+		code := ErrorCodeExecutionFenceTimeout
+		if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+			code = ErrorCodeOf(ctxErr)
+		} else {
+			err = apierrors.NewRetryAfterError(timeout, err)
+		}
+		fenceErr := NewExecutorError(PhaseExecutionFence, code, "acquire",
+			fmt.Sprintf("failed to acquire key %q after waiting %s", key, waited), err)
+		e.log.Errorf(logger.WithErrorField(phaseCtx, fenceErr), "Phase %s: FAILED", PhaseExecutionFence)
+		endSpan(phaseSpan, string(StatusFailed), fenceErr)
+		return nil, fenceErr
+	}
+	e.log.Debugf(phaseCtx, "Phase %s: SUCCESS - waited %s for key %q", PhaseExecutionFence, waited, key)
+	endSpan(phaseSpan, string(StatusSuccess), nil)
+	return release, nil
+}
+
+// executeParamExtraction extracts parameters from the event subject, the event and environment
+func (e *Executor) executeParamExtraction(execCtx *ExecutionContext, subject string) error {
 	configMap, err := configToMap(e.config.Config)
 	if err != nil {
-		return NewExecutorError(PhaseParamExtraction, "config", "failed to marshal config", err)
+		return NewExecutorError(PhaseParamExtraction, ErrorCodeInternal, "config", "failed to marshal config", err)
 	}
 
 	// Use a redacted config map for template-accessible params to avoid exposing sensitive
 	// values (e.g. TLS cert paths) in rendered manifests or logs.
 	redactedMap, err := configToMap(e.config.Config.Redacted())
 	if err != nil {
-		return NewExecutorError(PhaseParamExtraction, "config", "failed to marshal redacted config", err)
+		return NewExecutorError(PhaseParamExtraction, ErrorCodeInternal, "config",
+			"failed to marshal redacted config", err)
 	}
 
 	addAdapterParams(e.config.Config, execCtx, redactedMap)
+	if err = extractSubjectParams(e.config.Config, e.subjectPatterns, execCtx, subject); err != nil {
+		return err
+	}
 
 	// config.* param sources resolve against the real (unredacted) config so that
 	// sensitive fields like cert paths can still be explicitly extracted when needed.
-	return extractConfigParams(e.config.Config, execCtx, configMap)
+	objects := newFieldRefObjects(execCtx.Ctx, e.config.TransportClient)
+	if err = extractConfigParams(e.config.Config, execCtx, configMap, objects); err != nil {
+		return err
+	}
+	return checkRequiredParams(e.config.Config, execCtx)
 }
 
 // startTracedExecution creates an OTel span and adds trace context to logs.
-// Returns the enriched context and span. Caller must call span.End() when done.
+// Returns the enriched context and span. Caller must end the span when done.
 //
 // This method:
 //   - Creates an OTel span with trace_id and span_id (for distributed tracing)
 //   - Adds trace_id and span_id to logger context (for log correlation)
 //   - The trace context is automatically propagated to outgoing HTTP requests
-func (e *Executor) startTracedExecution(ctx context.Context) (context.Context, trace.Span) {
-	componentName := e.config.Config.Adapter.Name
-	ctx, span := otel.Tracer(componentName).Start(ctx, "Execute")
-
-	// Add trace_id and span_id to logger context for log correlation
-	ctx = logger.WithOTelTraceContext(ctx)
-
-	return ctx, span
+func (e *Executor) startTracedExecution(ctx context.Context, meta eventMeta) (context.Context, trace.Span) {
+	attrs := make([]attribute.KeyValue, 0, 2)
+	if meta.id != "" {
+		attrs = append(attrs, attribute.String(AttrEventID, meta.id))
+	}
+	if meta.eventType != "" {
+		attrs = append(attrs, attribute.String(AttrEventType, meta.eventType))
+	}
+	return startSpan(ctx, e.config, "Execute", attrs...)
 }
 
 // CreateHandler creates an event handler function that can be used with the broker subscriber
@@ -262,6 +733,8 @@ func (e *Executor) startTracedExecution(ctx context.Context) (context.Context, t
 // Error handling strategy:
 // - All failures are logged but the message is ACKed (return nil)
 // - This prevents infinite retry loops for non-recoverable errors (e.g., 400 Bad Request, invalid data)
+// - An execution that requests a delayed redelivery returns a RetryAfterError (delayed NACK)
+// - An execution cancelled mid-phase returns its CancelledError (NACK), so it is redelivered
 func (e *Executor) CreateHandler() func(ctx context.Context, evt *event.Event) error {
 	return func(ctx context.Context, evt *event.Event) error {
 		// Add event ID to context for logging correlation
@@ -269,28 +742,74 @@ func (e *Executor) CreateHandler() func(ctx context.Context, evt *event.Event) e
 
 		// Extract W3C trace context from CloudEvent extensions (if present)
 		// This enables distributed tracing when upstream services (e.g., Sentinel)
-		// include traceparent/tracestate in the CloudEvent
-		ctx = pkgotel.ExtractTraceContextFromCloudEvent(ctx, evt)
+		// include traceparent/tracestate in the CloudEvent.
+		// Skipped when a receive middleware already started a span for this event.
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			ctx = pkgotel.ExtractTraceContextFromCloudEvent(ctx, evt)
+		}
 
 		// Log event metadata
 		e.log.Infof(ctx, "Event received: id=%s type=%s source=%s time=%s",
 			evt.ID(), evt.Type(), evt.Source(), evt.Time())
 
-		start := time.Now()
-		result := e.Execute(ctx, evt.Data())
-		duration := time.Since(start)
+		eventType := e.metricEventType(evt.Type())
+		result, duration := e.executeTracked(ctx, evt, eventType)
 
-		e.recordMetrics(result, duration)
+		e.recordMetrics(evt, eventType, result, duration)
+		e.config.ExecutionHistory.Record(e.Summarize(evt, result, duration))
+		if e.config.Auditor != nil {
+			e.config.Auditor.Record(e.AuditRecord(evt, result, duration))
+		}
+		e.config.Heartbeat.Beat()
 
 		e.log.Infof(ctx, "Event processed: type=%s source=%s time=%s",
 			evt.Type(), evt.Source(), evt.Time())
 
+		if result.Cancelled != nil {
+			// The execution stopped partway, so the event is redelivered to finish it
+			e.log.Warnf(ctx, "Execution cancelled in phase %s, requesting redelivery", result.Cancelled.Phase)
+			return &redeliveryError{err: result.Cancelled, phase: result.Cancelled.Phase, code: ErrorCodeCancelled}
+		}
+		if result.RetryAfter > 0 {
+			e.log.Infof(ctx, "Requesting redelivery in %s", result.RetryAfter)
+			return redeliveryOf(result)
+		}
 		return nil
 	}
 }
 
+// InFlight returns the number of executions running now, those of the handlers
+// created with CreateHandler and of direct Execute and ExecuteEvent calls.
+// Stats reports the same count.
+func (e *Executor) InFlight() int64 {
+	return e.inFlight.Load()
+}
+
+// executeTracked runs ExecuteEvent and returns its wall time. The events in
+// flight gauge is decremented even if execution panics.
+func (e *Executor) executeTracked(
+	ctx context.Context, evt *event.Event, eventType string,
+) (*ExecutionResult, time.Duration) {
+	e.config.MetricsRecorder.AddEventsInFlight(eventType, 1)
+	defer e.config.MetricsRecorder.AddEventsInFlight(eventType, -1)
+
+	start := e.clock.Now()
+	result := e.ExecuteEvent(ctx, evt)
+	return result, e.clock.Since(start)
+}
+
+// metricEventType returns the event_type metric label of an event type: the
+// type itself if a schema is configured for it, metrics.OtherEventType otherwise,
+// so unexpected types cannot grow the label cardinality.
+func (e *Executor) metricEventType(eventType string) string {
+	if e.config.Config.EventSchemaFor(eventType) != nil {
+		return eventType
+	}
+	return metrics.OtherEventType
+}
+
 // recordMetrics records Prometheus metrics based on the execution result.
-func (e *Executor) recordMetrics(result *ExecutionResult, duration time.Duration) {
+func (e *Executor) recordMetrics(evt *event.Event, eventType string, result *ExecutionResult, duration time.Duration) {
 	recorder := e.config.MetricsRecorder
 	if recorder == nil {
 		return
@@ -298,49 +817,339 @@ func (e *Executor) recordMetrics(result *ExecutionResult, duration time.Duration
 
 	recorder.ObserveProcessingDuration(duration)
 
+	status := executionOutcome(result)
+	if result.Status == StatusFailed {
+		for phase, err := range result.Errors {
+			recorder.RecordError(string(phase), string(ErrorCodeOf(err)))
+		}
+	}
+	recorder.RecordEventProcessed(status)
+	recorder.ObserveEventProcessing(status, eventType, duration)
+	if result.Workflow != "" {
+		recorder.RecordWorkflowExecution(result.Workflow, status)
+	}
+
+	// The CloudEvent time attribute is optional
+	if producedAt := evt.Time(); !producedAt.IsZero() {
+		recorder.ObserveEventE2ELatency(status, eventType, e.clock.Since(producedAt))
+	}
+}
+
+// executionOutcome classifies an execution result as "success", "skipped" or "failed"
+func executionOutcome(result *ExecutionResult) string {
 	switch {
 	case result.Status == StatusFailed:
-		recorder.RecordEventProcessed("failed")
-		for phase := range result.Errors {
-			recorder.RecordError(string(phase))
-		}
+		return "failed"
 	case result.ResourcesSkipped:
-		recorder.RecordEventProcessed("skipped")
+		return "skipped"
 	default:
-		recorder.RecordEventProcessed("success")
+		return "success"
 	}
 }
 
-// ParseEventData parses event data from various input types into structured EventData and raw map.
-// Accepts: []byte (JSON), map[string]interface{}, or any JSON-serializable type.
-// Returns: structured EventData, raw map for flexible access, and any error.
-func ParseEventData(data interface{}) (*EventData, map[string]interface{}, error) {
-	if data == nil {
-		return &EventData{}, make(map[string]interface{}), nil
+// primaryError returns the error of the phase execution ended in, or any
+// phase error if that phase succeeded, nil if no phase failed
+func primaryError(result *ExecutionResult) error {
+	if err := result.Errors[result.CurrentPhase]; err != nil {
+		return err
+	}
+	for _, err := range result.Errors {
+		return err
 	}
+	return nil
+}
 
-	var jsonBytes []byte
-	var err error
+// redeliveryOf returns the error asking for the redelivery of the event of
+// result after result.RetryAfter, with the phase and error code of its primary
+// error. Without one, the redelivery was asked by an unmet precondition or by
+// the maintenance schedule deferring the resources.
+func redeliveryOf(result *ExecutionResult) error {
+	cause := primaryError(result)
+	if cause == nil {
+		phase := PhasePreconditions
+		if result.SkipReason == SkipReasonOutsideSchedule {
+			phase = PhaseResources
+		}
+		return &redeliveryError{
+			err:   apierrors.NewRetryAfterError(result.RetryAfter, errors.New(result.SkipReason)),
+			phase: phase,
+		}
+	}
+	phase := result.CurrentPhase
+	for errPhase, err := range result.Errors {
+		if err == cause {
+			phase = errPhase
+			break
+		}
+	}
+	return &redeliveryError{
+		err:   apierrors.NewRetryAfterError(result.RetryAfter, cause),
+		phase: phase,
+		code:  ErrorCodeOf(cause),
+	}
+}
 
-	switch v := data.(type) {
-	case []byte:
-		if len(v) == 0 {
-			return &EventData{}, make(map[string]interface{}), nil
+// observeStepDurations records the duration of every precondition, resource
+// and post action of result when metrics.per_step is enabled
+func (e *Executor) observeStepDurations(result *ExecutionResult) {
+	recorder := e.config.MetricsRecorder
+	if recorder == nil || !e.config.Config.Metrics.PerStep {
+		return
+	}
+	for _, r := range result.PreconditionResults {
+		recorder.ObserveStepDuration(string(PhasePreconditions), r.Name, r.Duration)
+	}
+	for _, r := range result.ResourceResults {
+		recorder.ObserveStepDuration(string(PhaseResources), r.Name, r.Duration)
+	}
+	for _, r := range result.PostActionResults {
+		recorder.ObserveStepDuration(string(PhasePostActions), r.Name, r.Duration)
+	}
+}
+
+// firstCancellation returns the CancelledError of the first phase stopped by
+// context cancellation, nil if none was. The phases after it are cancelled
+// before running any item.
+func firstCancellation(result *ExecutionResult) *CancelledError {
+	for _, phase := range []ExecutionPhase{PhasePreconditions, PhaseResources, PhasePostActions} {
+		var cancelErr *CancelledError
+		if errors.As(result.Errors[phase], &cancelErr) {
+			return cancelErr
 		}
-		jsonBytes = v
-	case map[string]interface{}:
-		// Already a map, marshal to JSON for struct conversion
-		jsonBytes, err = json.Marshal(v)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to marshal map data: error=%w", err)
+	}
+	return nil
+}
+
+// renderNotMetKey renders the not_met_backoff key, falling back to the
+// execution_fence key. It returns an empty key, which is not tracked, without
+// not_met_backoff or when the key fails to render.
+func (e *Executor) renderNotMetKey(ctx context.Context, execCtx *ExecutionContext) string {
+	backoff := e.config.Config.NotMetBackoff
+	if backoff == nil {
+		return ""
+	}
+	keyTemplate, keyPath := backoff.Key, "not_met_backoff.key"
+	if keyTemplate == "" && e.config.Config.ExecutionFence != nil {
+		keyTemplate, keyPath = e.config.Config.ExecutionFence.Key, "execution_fence.key"
+	}
+	key, err := renderTemplateAt(keyPath, keyTemplate, execCtx.templateParams())
+	if err != nil {
+		e.log.Warnf(logger.WithErrorField(ctx, err), "Failed to render not_met_backoff key, not tracking the execution")
+		return ""
+	}
+	return key
+}
+
+// escalateNotMet counts the not-met execution of key and logs its outcome.
+// The executions past the not_met_backoff threshold ask for an escalating
+// redelivery delay and are only logged one in LogSampleEvery, at debug level;
+// it reports whether the execution is one of them.
+func (e *Executor) escalateNotMet(ctx context.Context, result *ExecutionResult, key, reason string) bool {
+	if key == "" {
+		e.log.Infof(ctx, "Phase %s: SUCCESS - NOT_MET - %s", result.CurrentPhase, reason)
+		return false
+	}
+	count := e.notMet.record(key, reason)
+	step := escalation(e.config.Config.NotMetBackoff, count)
+	if step.delay == 0 {
+		e.log.Infof(ctx, "Phase %s: SUCCESS - NOT_MET - %s", result.CurrentPhase, reason)
+		return false
+	}
+	result.RetryAfter = max(result.RetryAfter, step.delay)
+	if step.logged {
+		e.log.Debugf(ctx, "Phase %s: SUCCESS - NOT_MET - %s (not met %d times in a row for key %q, redelivery in %s)",
+			result.CurrentPhase, reason, count, key, result.RetryAfter)
+	}
+	return true
+}
+
+// retryAfterOf returns the redelivery delay requested by err: the delay of a
+// RetryAfterError or the Retry-After of an API error, zero when neither is set
+func retryAfterOf(err error) time.Duration {
+	// A retry denied by the retry budget is left to a redelivery after its wait
+	if exhausted, ok := retrybudget.IsExhausted(err); ok {
+		return exhausted.Delay
+	}
+	if retryErr, ok := apierrors.IsRetryAfterError(err); ok {
+		return retryErr.Delay
+	}
+	if apiErr, ok := apierrors.IsAPIError(err); ok {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
+
+// Summarize builds the /statusz summary of an execution. Values of
+// env-sourced and sensitive params are redacted from the reason, which may
+// quote them in error messages.
+func (e *Executor) Summarize(
+	evt *event.Event, result *ExecutionResult, duration time.Duration,
+) health.ExecutionSummary {
+	reason := result.SkipReason
+	var errorCode ErrorCode
+	if err := primaryError(result); err != nil {
+		reason = err.Error()
+		errorCode = ErrorCodeOf(err)
+	}
+	reason = e.redactor(result)(reason)
+
+	return health.ExecutionSummary{
+		Timestamp: e.clock.Now(),
+		EventID:   evt.ID(),
+		EventType: evt.Type(),
+		Status:    executionOutcome(result),
+		Phase:     string(result.CurrentPhase),
+		Reason:    reason,
+		ErrorCode: string(errorCode),
+		Duration:  duration.Round(time.Millisecond).String(),
+		TraceID:   result.TraceID,
+	}
+}
+
+// redactedPlaceholder replaces redacted param values
+const redactedPlaceholder = "[REDACTED]"
+
+// isRedactedParam reports whether the value of a declared param is redacted
+// from /statusz and audit records: env-sourced and sensitive params
+func isRedactedParam(param configloader.Parameter) bool {
+	return strings.HasPrefix(param.Source, "env.") || param.Sensitive
+}
+
+// redactor returns a function replacing the values of the redacted params of
+// an execution in strings, e.g. error messages quoting them
+func (e *Executor) redactor(result *ExecutionResult) func(string) string {
+	var secrets []string
+	for _, param := range e.config.Config.Params {
+		if !isRedactedParam(param) {
+			continue
 		}
+		if value, ok := result.Params[param.Name].(string); ok && value != "" {
+			secrets = append(secrets, value)
+		}
+	}
+	return func(s string) string {
+		for _, secret := range secrets {
+			s = strings.ReplaceAll(s, secret, redactedPlaceholder)
+		}
+		return s
+	}
+}
+
+// checkEventSize fails with ErrorCodeEventTooLarge when raw event data exceeds
+// the clients.broker.max_event_bytes limit. Redelivering the event cannot succeed.
+func (e *Executor) checkEventSize(data interface{}) error {
+	limit := e.config.Config.Clients.Broker.MaxEventBytes
+	if limit <= 0 {
+		return nil
+	}
+	var size int
+	switch d := data.(type) {
+	case []byte:
+		size = len(d)
+	case string:
+		size = len(d)
 	default:
-		// Try to marshal any other type
-		jsonBytes, err = json.Marshal(v)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to marshal data: error=%w", err)
+		return nil
+	}
+	if size <= limit {
+		return nil
+	}
+	return NewExecutorError(PhaseParamExtraction, ErrorCodeEventTooLarge, "event_data",
+		fmt.Sprintf("event data is %d bytes, over the %d byte limit", size, limit), nil)
+}
+
+// allowsDataContentType reports whether event_filter.data_content_types allows
+// event data of the media type. Every type is allowed without a filter.
+func (e *Executor) allowsDataContentType(mediaType string) bool {
+	filter := e.config.Config.EventFilter
+	if filter == nil || len(filter.DataContentTypes) == 0 {
+		return true
+	}
+	for _, allowed := range filter.DataContentTypes {
+		if eventDataMediaType(allowed) == mediaType {
+			return true
 		}
 	}
+	return false
+}
+
+// filteredEvent builds the result of an event whose data content type is not
+// allowed: the resources are skipped and no phase runs, so the event is acknowledged
+func (e *Executor) filteredEvent(ctx context.Context, dataContentType string) *ExecutionResult {
+	ctx = logger.WithDataContentType(ctx, dataContentType)
+	ctx = logger.WithDataDecision(ctx, string(DataFiltered))
+	e.log.Infof(ctx, "Event filtered: data content type %q is not allowed", dataContentType)
+	e.config.MetricsRecorder.RecordFilteredEvent()
+	return &ExecutionResult{
+		Status:           StatusSuccess,
+		CurrentPhase:     PhaseParamExtraction,
+		Errors:           make(map[ExecutionPhase]error),
+		ResourcesSkipped: true,
+		SkipReason:       fmt.Sprintf("data content type %q is not allowed by event_filter", dataContentType),
+		DataContentType:  dataContentType,
+		DataDecision:     DataFiltered,
+	}
+}
+
+// wrapPlainTextData wraps raw text/plain event data in an object with the text
+// under key. Data that is not raw has already been decoded and is returned unchanged.
+func wrapPlainTextData(data interface{}, key string) interface{} {
+	switch raw := data.(type) {
+	case []byte:
+		return map[string]interface{}{key: string(raw)}
+	case string:
+		return map[string]interface{}{key: raw}
+	default:
+		return data
+	}
+}
+
+// eventDataFailure builds the failed result for event data that cannot be decoded or parsed
+func (e *Executor) eventDataFailure(ctx context.Context, err error) *ExecutionResult {
+	parseErr := NewExecutorError(PhaseParamExtraction, ErrorCodeEventInvalid, "event_data",
+		"failed to parse event data", err)
+	errCtx := logger.WithErrorField(ctx, parseErr)
+	e.log.Errorf(errCtx, "Failed to parse event data")
+	e.config.MetricsRecorder.RecordEventDecodeError("invalid_data")
+	return &ExecutionResult{
+		Status:       StatusFailed,
+		CurrentPhase: PhaseParamExtraction,
+		Errors:       map[ExecutionPhase]error{PhaseParamExtraction: parseErr},
+	}
+}
+
+// validateEventSchema validates event data against the schema registered for the event type.
+// Returns nil when no schema is registered or the data cannot be decoded as JSON
+// (the latter is reported by ParseEventData).
+func (e *Executor) validateEventSchema(eventType string, data interface{}) []configloader.SchemaViolation {
+	schema := e.config.Config.EventSchemaFor(eventType)
+	if schema == nil {
+		return nil
+	}
+	jsonBytes, err := eventDataJSON(data)
+	if err != nil {
+		return nil
+	}
+	var doc interface{}
+	if len(jsonBytes) == 0 {
+		doc = map[string]interface{}{}
+	} else if err := json.Unmarshal(jsonBytes, &doc); err != nil {
+		return nil
+	}
+	return schema.Validate(doc)
+}
+
+// ParseEventData parses event data from various input types into structured EventData and raw map.
+// Accepts: []byte (JSON), map[string]interface{}, or any JSON-serializable type.
+// Returns: structured EventData, raw map for flexible access, and any error.
+func ParseEventData(data interface{}) (*EventData, map[string]interface{}, error) {
+	jsonBytes, err := eventDataJSON(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(jsonBytes) == 0 {
+		return &EventData{}, make(map[string]interface{}), nil
+	}
 
 	// Parse into structured EventData
 	var eventData EventData
@@ -357,6 +1166,96 @@ func ParseEventData(data interface{}) (*EventData, map[string]interface{}, error
 	return &eventData, rawData, nil
 }
 
+// ParseEventDataAs is ParseEventData for data of the given content type.
+// Raw YAML data is converted to the JSON document it denotes first, so params,
+// CEL expressions and templates see exactly what an equivalent JSON event produces.
+func ParseEventDataAs(data interface{}, contentType string) (*EventData, map[string]interface{}, error) {
+	data, err := normalizeEventData(data, contentType)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ParseEventData(data)
+}
+
+// normalizeEventData converts raw event data of the given content type to JSON bytes.
+// Data that is not raw bytes has already been decoded and is returned unchanged.
+func normalizeEventData(data interface{}, contentType string) (interface{}, error) {
+	raw, ok := data.([]byte)
+	if !ok {
+		return data, nil
+	}
+	switch eventDataFormat(contentType) {
+	case ContentTypeJSON:
+		return raw, nil
+	case ContentTypeYAML:
+		// YAMLToJSON resolves anchors and merge keys and stringifies non-string keys
+		jsonBytes, err := yaml.YAMLToJSON(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse YAML event data: error=%w", err)
+		}
+		if bytes.Equal(bytes.TrimSpace(jsonBytes), []byte("null")) {
+			// An empty YAML document is empty data, like an empty JSON payload
+			return nil, nil
+		}
+		return jsonBytes, nil
+	default:
+		return nil, fmt.Errorf("unsupported event data content type %q, supported types: %s, %s",
+			contentType, ContentTypeJSON, ContentTypeYAML)
+	}
+}
+
+// eventDataFormat maps a data content type to ContentTypeJSON or ContentTypeYAML,
+// or returns the bare media type when it is neither. An empty content type is JSON.
+func eventDataFormat(contentType string) string {
+	mediaType := eventDataMediaType(contentType)
+	switch {
+	case mediaType == ContentTypeJSON, mediaType == "text/json", strings.HasSuffix(mediaType, "+json"):
+		return ContentTypeJSON
+	case mediaType == ContentTypeYAML, mediaType == "application/x-yaml", mediaType == "text/yaml",
+		mediaType == "text/x-yaml", strings.HasSuffix(mediaType, "+yaml"):
+		return ContentTypeYAML
+	default:
+		return mediaType
+	}
+}
+
+// eventDataMediaType returns the lowercase media type of a data content type
+// without parameters. An empty content type is ContentTypeJSON.
+func eventDataMediaType(contentType string) string {
+	if strings.TrimSpace(contentType) == "" {
+		return ContentTypeJSON
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
+
+// eventDataJSON returns event data as JSON bytes. Nil and empty data return nil bytes.
+func eventDataJSON(data interface{}) ([]byte, error) {
+	switch v := data.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return v, nil
+	case map[string]interface{}:
+		// Already a map, marshal to JSON for struct conversion
+		jsonBytes, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal map data: error=%w", err)
+		}
+		return jsonBytes, nil
+	default:
+		// Try to marshal any other type
+		jsonBytes, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal data: error=%w", err)
+		}
+		return jsonBytes, nil
+	}
+}
+
 // ExecutorBuilder provides a fluent interface for building an Executor
 type ExecutorBuilder struct {
 	config *ExecutorConfig
@@ -399,6 +1298,78 @@ func (b *ExecutorBuilder) WithMetricsRecorder(recorder *metrics.Recorder) *Execu
 	return b
 }
 
+// WithHeartbeat sets the heartbeat beaten after every completed execution
+func (b *ExecutorBuilder) WithHeartbeat(heartbeat *health.Heartbeat) *ExecutorBuilder {
+	b.config.Heartbeat = heartbeat
+	return b
+}
+
+// WithExecutionHistory sets the history recording a summary of every completed execution
+func (b *ExecutorBuilder) WithExecutionHistory(history *health.ExecutionHistory) *ExecutorBuilder {
+	b.config.ExecutionHistory = history
+	return b
+}
+
+// WithAuditor sets the auditor writing execution audit records
+func (b *ExecutorBuilder) WithAuditor(auditor *audit.Auditor) *ExecutorBuilder {
+	b.config.Auditor = auditor
+	return b
+}
+
+// WithRuntimeMetadata sets the metadata identifying this adapter instance in the adapter map
+func (b *ExecutorBuilder) WithRuntimeMetadata(runtime RuntimeMetadata) *ExecutorBuilder {
+	b.config.Runtime = &runtime
+	return b
+}
+
+// WithWebhookClient sets the client delivering webhook post actions
+// (default: a client built from clients.webhook)
+func (b *ExecutorBuilder) WithWebhookClient(client WebhookClient) *ExecutorBuilder {
+	b.config.WebhookClient = client
+	return b
+}
+
+// WithJournal sets the journal recording the executions whose post actions
+// have not run yet, recovered by RecoverJournal
+func (b *ExecutorBuilder) WithJournal(store journal.Store) *ExecutorBuilder {
+	b.config.Journal = store
+	return b
+}
+
+// WithFeatureFlags sets the feature flags of the flag() CEL function
+// (default: none, every flag is off)
+func (b *ExecutorBuilder) WithFeatureFlags(store *featureflags.Store) *ExecutorBuilder {
+	b.config.FeatureFlags = store
+	return b
+}
+
+// WithPostBuffer sets the buffer of the failed API calls of the post actions
+// with buffer_on_failure (default: none, they fail like the others)
+func (b *ExecutorBuilder) WithPostBuffer(buffer *postbuffer.Buffer) *ExecutorBuilder {
+	b.config.PostBuffer = buffer
+	return b
+}
+
+// WithIdempotencyStore sets the store of the processed generations of the
+// idempotency guard (default: an in-memory store)
+func (b *ExecutorBuilder) WithIdempotencyStore(store idempotency.Store) *ExecutorBuilder {
+	b.config.Idempotency = store
+	return b
+}
+
+// WithLookupTables sets the maps of lookup(), e.g. loaded with their
+// ConfigMaps (default: the inline maps of the config)
+func (b *ExecutorBuilder) WithLookupTables(tables *lookup.Tables) *ExecutorBuilder {
+	b.config.LookupTables = tables
+	return b
+}
+
+// WithClock sets the clock timing executions (default: the real clock)
+func (b *ExecutorBuilder) WithClock(clk clock.Clock) *ExecutorBuilder {
+	b.config.Clock = clk
+	return b
+}
+
 // Build creates the Executor
 func (b *ExecutorBuilder) Build() (*Executor, error) {
 	return NewExecutor(b.config)
@@ -3,14 +3,32 @@ package executor
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/google/uuid"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/audit"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/brokerconsumer"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/criteria"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/delivery"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/retrybudget"
+	apierrors "github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/errors"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/eventtest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/health"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
 	"github.com/prometheus/client_golang/prometheus"
@@ -112,7 +130,7 @@ func TestExecutionContext(t *testing.T) {
 	execCtx := NewExecutionContext(ctx, eventData, nil)
 
 	assert.Equal(t, "test-cluster", execCtx.EventData["id"])
-	assert.Empty(t, execCtx.Params)
+	assert.Empty(t, execCtx.ParamsSnapshot())
 	assert.Empty(t, execCtx.Resources)
 	assert.Equal(t, string(StatusSuccess), execCtx.Adapter.ExecutionStatus)
 }
@@ -120,11 +138,12 @@ func TestExecutionContext(t *testing.T) {
 func TestExecutionContext_SetError(t *testing.T) {
 	ctx := context.Background()
 	execCtx := NewExecutionContext(ctx, map[string]interface{}{}, nil)
-	execCtx.SetError("TestReason", "Test message")
+	execCtx.SetError("TestReason", "Test message", ErrorCodeAPICallFailed)
 
 	assert.Equal(t, string(StatusFailed), execCtx.Adapter.ExecutionStatus)
 	assert.Equal(t, "TestReason", execCtx.Adapter.ErrorReason)
 	assert.Equal(t, "Test message", execCtx.Adapter.ErrorMessage)
+	assert.Equal(t, ErrorCodeAPICallFailed, execCtx.GetExecutionError().Code)
 }
 
 func TestExecutionContext_EvaluationTracking(t *testing.T) {
@@ -132,14 +151,14 @@ func TestExecutionContext_EvaluationTracking(t *testing.T) {
 	execCtx := NewExecutionContext(ctx, map[string]interface{}{}, nil)
 
 	// Verify evaluations are empty initially
-	assert.Empty(t, execCtx.Evaluations, "expected empty evaluations initially")
+	assert.Empty(t, execCtx.GetEvaluations(), "expected empty evaluations initially")
 
 	// Add a CEL evaluation
 	execCtx.AddCELEvaluation(PhasePreconditions, "check-status", "status == 'active'", true)
 
-	require.Len(t, execCtx.Evaluations, 1, "evaluation")
+	require.Len(t, execCtx.GetEvaluations(), 1, "evaluation")
 
-	eval := execCtx.Evaluations[0]
+	eval := execCtx.GetEvaluations()[0]
 	assert.Equal(t, PhasePreconditions, eval.Phase)
 	assert.Equal(t, "check-status", eval.Name)
 	assert.Equal(t, EvaluationTypeCEL, eval.EvaluationType)
@@ -165,9 +184,9 @@ func TestExecutionContext_EvaluationTracking(t *testing.T) {
 	}
 	execCtx.AddConditionsEvaluation(PhasePreconditions, "check-replicas", true, fieldResults)
 
-	require.Len(t, execCtx.Evaluations, 2, "evaluations")
+	require.Len(t, execCtx.GetEvaluations(), 2, "evaluations")
 
-	condEval := execCtx.Evaluations[1]
+	condEval := execCtx.GetEvaluations()[1]
 	assert.Equal(t, EvaluationTypeConditions, condEval.EvaluationType)
 	assert.Len(t, condEval.FieldResults, 2)
 
@@ -179,6 +198,47 @@ func TestExecutionContext_EvaluationTracking(t *testing.T) {
 	assert.Equal(t, 3, condEval.FieldResults["replicas"].FieldValue)
 }
 
+// Concurrent writers and readers of params, evaluations and the execution error,
+// as parallel preconditions or resources would be; the acceptance gate is -race
+func TestExecutionContext_ConcurrentAccess(t *testing.T) {
+	execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+	const writers, writes = 8, 100
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < writes; i++ {
+				execCtx.SetParam(fmt.Sprintf("param-%d-%d", w, i), i)
+				execCtx.AddCELEvaluation(PhasePreconditions, fmt.Sprintf("check-%d", w), "true", i%2 == 0)
+				execCtx.SetExecutionError(&ExecutionError{Phase: string(PhaseResources), Code: ErrorCodeInternal})
+			}
+		}(w)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < writes; i++ {
+				_, _ = execCtx.GetParam(fmt.Sprintf("param-%d-%d", w, i))
+				snapshot := execCtx.ParamsSnapshot()
+				snapshot["local"] = true // a snapshot is a copy
+				_ = execCtx.GetCELVariables()
+				_ = execCtx.GetFailedEvaluations()
+				_ = execCtx.GetExecutionError()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	params := execCtx.ParamsSnapshot()
+	assert.Len(t, params, writers*writes)
+	assert.NotContains(t, params, "local")
+	assert.Len(t, execCtx.GetEvaluations(), writers*writes)
+	assert.Len(t, execCtx.GetEvaluationsByPhase(PhasePreconditions), writers*writes)
+	assert.Len(t, execCtx.GetFailedEvaluations(), writers*writes/2)
+	require.NotNil(t, execCtx.GetExecutionError())
+	assert.Equal(t, ErrorCodeInternal, execCtx.GetExecutionError().Code)
+}
+
 func TestExecutionContext_GetEvaluationsByPhase(t *testing.T) {
 	ctx := context.Background()
 	execCtx := NewExecutionContext(ctx, map[string]interface{}{}, nil)
@@ -223,8 +283,26 @@ func TestExecutionContext_GetFailedEvaluations(t *testing.T) {
 	assert.True(t, names["failed-2"], "failed-2")
 }
 
+func TestExecutionContext_EvaluationLimit(t *testing.T) {
+	config := &configloader.Config{Limits: &configloader.Limits{MaxEvaluationRecords: 3}}
+	execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, config)
+
+	for i := range 5 {
+		execCtx.AddCELEvaluation(PhasePreconditions, fmt.Sprintf("precond-%d", i), "true", i%2 == 0)
+	}
+
+	var names []string
+	for _, eval := range execCtx.GetEvaluations() {
+		names = append(names, eval.Name)
+	}
+	assert.Equal(t, []string{"precond-2", "precond-3", "precond-4"}, names, "the oldest records are dropped first")
+	assert.Equal(t, 2, execCtx.EvaluationsDropped())
+	require.Len(t, execCtx.GetFailedEvaluations(), 1)
+	assert.Equal(t, "precond-3", execCtx.GetFailedEvaluations()[0].Name)
+}
+
 func TestExecutorError(t *testing.T) {
-	err := NewExecutorError(PhasePreconditions, "test-step", "test message", nil)
+	err := NewExecutorError(PhasePreconditions, ErrorCodeInternal, "test-step", "test message", nil)
 
 	expected := "[preconditions] test-step: test message"
 	if err.Error() != expected {
@@ -232,7 +310,7 @@ func TestExecutorError(t *testing.T) {
 	}
 
 	// With wrapped error
-	wrappedErr := NewExecutorError(PhaseResources, "create", "failed to create", context.Canceled)
+	wrappedErr := NewExecutorError(PhaseResources, ErrorCodeApplyFailed, "create", "failed to create", context.Canceled)
 	assert.Equal(t, context.Canceled, wrappedErr.Unwrap())
 }
 
@@ -290,17 +368,144 @@ func TestExecute_ParamExtraction(t *testing.T) {
 	}
 }
 
+func TestExecute_Vars(t *testing.T) {
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+		Vars: map[string]interface{}{
+			"team":   "platform",
+			"region": "us-east-1",
+			"labels": map[string]interface{}{"environment": "prod", "tier": "gold"},
+		},
+		// region collides with a var: the validator rejects this, but an extracted
+		// param takes precedence over a var at runtime
+		Params: []configloader.Parameter{{Name: "region", Source: "event.region"}},
+		Preconditions: []configloader.Precondition{{
+			ActionBase: configloader.ActionBase{Name: "inProduction"},
+			Expression: `labels.environment == "prod" && labels.tier == "gold" && team == "platform"`,
+		}},
+	}
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+
+	result := exec.Execute(context.Background(), map[string]interface{}{"region": "eu-west-1"})
+	require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+	assert.False(t, result.ResourcesSkipped, "the precondition over nested vars should be met")
+	assert.Equal(t, "platform", result.Params["team"])
+	assert.Equal(t, "eu-west-1", result.Params["region"], "the extracted param overrides the var")
+
+	result = exec.Execute(context.Background(), map[string]interface{}{})
+	assert.Equal(t, "us-east-1", result.Params["region"], "the var applies when the param is not extracted")
+}
+
+func TestExecute_RequiredParams(t *testing.T) {
+	newConfig := func(reportParamFailures bool) *configloader.Config {
+		return &configloader.Config{
+			Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+			Params: []configloader.Parameter{
+				{Name: "clusterId", Source: "event.id"},
+				{Name: "consumerName", Source: "event.consumer_name"},
+				{Name: "region", Source: "event.region"},
+			},
+			RequiredParams:      []string{"clusterId", "consumerName", "region", "event.generation"},
+			ReportParamFailures: reportParamFailures,
+			Preconditions: []configloader.Precondition{
+				{ActionBase: configloader.ActionBase{Name: "check"}, Expression: "true"},
+			},
+			Post: &configloader.PostConfig{
+				Payloads: []configloader.Payload{{Name: "statusPayload", Build: map[string]interface{}{
+					"code": map[string]interface{}{"expression": "adapter.executionError.code"},
+				}}},
+				PostActions: []configloader.PostAction{{ActionBase: configloader.ActionBase{
+					Name: "report",
+					APICall: &configloader.APICall{
+						Method: "POST", URL: "http://api/status", Body: "{{ .statusPayload }}",
+					},
+				}}},
+			},
+		}
+	}
+	eventData := map[string]interface{}{"id": "cluster-1", "consumer_name": ""}
+
+	t.Run("all missing params in one error", func(t *testing.T) {
+		mockAPI := newMockAPIClient()
+		exec, err := NewBuilder().
+			WithConfig(newConfig(false)).
+			WithAPIClient(mockAPI).
+			WithTransportClient(k8sclient.NewMockK8sClient()).
+			WithLogger(logger.NewTestLogger()).
+			Build()
+		require.NoError(t, err)
+
+		result := exec.Execute(context.Background(), eventData)
+		assert.Equal(t, StatusFailed, result.Status)
+		assert.Equal(t, PhaseParamExtraction, result.CurrentPhase)
+		paramErr := result.Errors[PhaseParamExtraction]
+		require.Error(t, paramErr)
+		assert.Equal(t, ErrorCodeParamMissing, ErrorCodeOf(paramErr))
+		assert.Contains(t, paramErr.Error(),
+			"missing required params: consumerName (source event.consumer_name), region (source event.region), "+
+				"event.generation")
+		assert.Empty(t, result.PreconditionResults)
+		assert.Empty(t, result.PostActionResults, "post actions do not run without report_param_failures")
+		assert.Empty(t, mockAPI.Requests)
+	})
+
+	t.Run("report_param_failures runs the post actions", func(t *testing.T) {
+		mockAPI := newMockAPIClient()
+		exec, err := NewBuilder().
+			WithConfig(newConfig(true)).
+			WithAPIClient(mockAPI).
+			WithTransportClient(k8sclient.NewMockK8sClient()).
+			WithLogger(logger.NewTestLogger()).
+			Build()
+		require.NoError(t, err)
+
+		result := exec.Execute(context.Background(), eventData)
+		assert.Equal(t, StatusFailed, result.Status)
+		require.Error(t, result.Errors[PhaseParamExtraction])
+		assert.Empty(t, result.PreconditionResults, "preconditions are skipped")
+		assert.Empty(t, result.ResourceResults)
+		assert.True(t, result.ResourcesSkipped)
+		assert.Equal(t, "ParameterExtractionFailed", result.SkipReason)
+
+		require.Len(t, result.PostActionResults, 1)
+		assert.Equal(t, StatusSuccess, result.PostActionResults[0].Status)
+		requests := mockAPI.Requests
+		require.Len(t, requests, 1)
+		assert.JSONEq(t, `{"code":"ParamMissing"}`, string(requests[0].Body))
+	})
+
+	t.Run("present params pass", func(t *testing.T) {
+		exec, err := NewBuilder().
+			WithConfig(newConfig(false)).
+			WithAPIClient(newMockAPIClient()).
+			WithTransportClient(k8sclient.NewMockK8sClient()).
+			WithLogger(logger.NewTestLogger()).
+			Build()
+		require.NoError(t, err)
+
+		result := exec.Execute(context.Background(), map[string]interface{}{
+			"id": "cluster-1", "consumer_name": "consumer-1", "region": "us-east-1", "generation": 2,
+		})
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, StatusSuccess, result.Status)
+	})
+}
+
 func TestParamExtractor(t *testing.T) {
 	t.Setenv("TEST_ENV", "env-value")
 
-	evt := event.New()
 	eventData := map[string]interface{}{
 		"id": "test-cluster",
 		"nested": map[string]interface{}{
 			"value": "nested-value",
 		},
 	}
-	_ = evt.SetData(event.ApplicationJSON, eventData)
 
 	tests := []struct {
 		expectValue interface{}
@@ -398,7 +603,7 @@ func TestParamExtractor(t *testing.T) {
 			// Extract params using pure function
 			configMap, err := configToMap(config)
 			require.NoError(t, err)
-			err = extractConfigParams(config, execCtx, configMap)
+			err = extractConfigParams(config, execCtx, configMap, newFieldRefObjects(context.Background(), nil))
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -408,8 +613,8 @@ func TestParamExtractor(t *testing.T) {
 			require.NoError(t, err)
 
 			if tt.expectKey != "" {
-				if execCtx.Params[tt.expectKey] != tt.expectValue {
-					t.Errorf("expected %s=%v, got %v", tt.expectKey, tt.expectValue, execCtx.Params[tt.expectKey])
+				if value, _ := execCtx.GetParam(tt.expectKey); value != tt.expectValue {
+					t.Errorf("expected %s=%v, got %v", tt.expectKey, tt.expectValue, value)
 				}
 			}
 		})
@@ -642,6 +847,103 @@ func TestPrecondition_CustomCELFunctions(t *testing.T) {
 }
 
 // TestSequentialExecution_Resources tests that resources stop on first failure
+// TestPrecondition_HeaderCapture verifies that response headers are captured into
+// params case-insensitively, and the default and required handling of a missing value
+func TestPrecondition_HeaderCapture(t *testing.T) {
+	headerCapture := func(name, header string) configloader.CaptureField {
+		return configloader.CaptureField{Name: name, Source: configloader.CaptureSourceHeader, Header: header}
+	}
+	withDefault := headerCapture("etag", "ETag")
+	withDefault.Default = "none"
+	required := headerCapture("requestId", "X-Request-Id")
+	required.Required = true
+
+	tests := []struct {
+		name          string
+		capture       []configloader.CaptureField
+		expectParams  map[string]interface{}
+		expectMissing []string
+		expectError   string
+	}{
+		{
+			name: "single and multi-valued headers",
+			capture: []configloader.CaptureField{
+				headerCapture("totalCount", "x-total-count"),
+				headerCapture("links", "Link"),
+				{Name: "kind", FieldExpressionDef: configloader.FieldExpressionDef{Field: "kind"}},
+			},
+			expectParams: map[string]interface{}{
+				"totalCount": "42",
+				"links":      []interface{}{"</clusters?page=2>; rel=next", "</clusters?page=5>; rel=last"},
+				"kind":       "ClusterList",
+			},
+		},
+		{
+			name:          "missing optional header is skipped",
+			capture:       []configloader.CaptureField{headerCapture("requestId", "X-Request-Id")},
+			expectMissing: []string{"requestId"},
+		},
+		{
+			name:         "missing header takes the default",
+			capture:      []configloader.CaptureField{withDefault},
+			expectParams: map[string]interface{}{"etag": "none"},
+		},
+		{
+			name:        "missing required header fails",
+			capture:     []configloader.CaptureField{required},
+			expectError: `required capture 'requestId' has no value: response has no header "X-Request-Id"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := newMockAPIClient()
+			mockClient.GetResponse = &hyperfleetapi.Response{
+				StatusCode: 200,
+				Status:     "200 OK",
+				Body:       []byte(`{"kind":"ClusterList"}`),
+				Headers: map[string][]string{
+					"X-Total-Count": {"42"},
+					"Link":          {"</clusters?page=2>; rel=next", "</clusters?page=5>; rel=last"},
+				},
+			}
+			config := &configloader.Config{
+				Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+				Preconditions: []configloader.Precondition{{
+					ActionBase: configloader.ActionBase{
+						Name:    "clusters",
+						APICall: &configloader.APICall{Method: "GET", URL: "/clusters"},
+					},
+					Capture: tt.capture,
+				}},
+			}
+			exec, err := NewBuilder().
+				WithConfig(config).
+				WithAPIClient(mockClient).
+				WithTransportClient(k8sclient.NewMockK8sClient()).
+				WithLogger(logger.NewTestLogger()).
+				Build()
+			require.NoError(t, err)
+
+			result := exec.Execute(context.Background(), map[string]interface{}{})
+			if tt.expectError != "" {
+				require.Equal(t, StatusFailed, result.Status)
+				assert.ErrorContains(t, result.Errors[PhasePreconditions], tt.expectError)
+				assert.Equal(t, ErrorCodeCaptureMissing, ErrorCodeOf(result.Errors[PhasePreconditions]))
+				return
+			}
+			require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+			for name, value := range tt.expectParams {
+				assert.Equal(t, value, result.Params[name], name)
+				assert.Equal(t, value, result.PreconditionResults[0].CapturedFields[name], name)
+			}
+			for _, name := range tt.expectMissing {
+				assert.NotContains(t, result.Params, name)
+			}
+		})
+	}
+}
+
 func TestSequentialExecution_Resources(t *testing.T) {
 	// Note: This test uses dry-run mode and focuses on the sequential logic
 	// without requiring a real K8s cluster. Resource sequential execution is better
@@ -911,15 +1213,13 @@ func TestCreateHandler_MetricsRecording(t *testing.T) {
 
 			handler := exec.CreateHandler()
 
-			evt := event.New()
-			evt.SetID("test-event-1")
-			evt.SetType("com.hyperfleet.test")
-			evt.SetSource("test")
-			eventData := map[string]interface{}{"id": "cluster-1"}
-			eventBytes, _ := json.Marshal(eventData)
-			_ = evt.SetData(event.ApplicationJSON, eventBytes)
+			evt := eventtest.NewEvent().
+				WithID("test-event-1").
+				WithType("com.hyperfleet.test").
+				WithDataJSON(map[string]interface{}{"id": "cluster-1"}).
+				Build()
 
-			err = handler(context.Background(), &evt)
+			err = handler(context.Background(), evt)
 			require.NoError(t, err, "handler should always return nil")
 
 			// Verify events_processed_total
@@ -961,15 +1261,13 @@ func TestCreateHandler_MetricsRecording_Failed(t *testing.T) {
 
 	handler := exec.CreateHandler()
 
-	evt := event.New()
-	evt.SetID("test-event-fail")
-	evt.SetType("com.hyperfleet.test")
-	evt.SetSource("test")
-	eventData := map[string]interface{}{"id": "cluster-1"}
-	eventBytes, _ := json.Marshal(eventData)
-	_ = evt.SetData(event.ApplicationJSON, eventBytes)
+	evt := eventtest.NewEvent().
+		WithID("test-event-fail").
+		WithType("com.hyperfleet.test").
+		WithDataJSON(map[string]interface{}{"id": "cluster-1"}).
+		Build()
 
-	err = handler(context.Background(), &evt)
+	err = handler(context.Background(), evt)
 	require.NoError(t, err, "handler should always return nil even on failure")
 
 	families, err := registry.Gather()
@@ -984,10 +1282,18 @@ func TestCreateHandler_MetricsRecording_Failed(t *testing.T) {
 	assert.Equal(t, float64(1), errorCount, "expected 1 param_extraction error")
 }
 
-// TestCreateHandler_NilMetricsRecorder verifies handler works without a metrics recorder
-func TestCreateHandler_NilMetricsRecorder(t *testing.T) {
+// TestCreateHandler_EventLatencyMetrics verifies per-type latency metrics and that
+// event types without a configured schema are bucketed into "other"
+func TestCreateHandler_EventLatencyMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewRecorder("test-adapter", "v0.1.0", registry)
+
 	config := &configloader.Config{
 		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		EventSchemas: []configloader.EventSchema{{
+			EventType: "io.hyperfleet.cluster.updated",
+			Schema:    map[string]interface{}{"type": "object"},
+		}},
 	}
 
 	exec, err := NewBuilder().
@@ -995,116 +1301,1754 @@ func TestCreateHandler_NilMetricsRecorder(t *testing.T) {
 		WithAPIClient(newMockAPIClient()).
 		WithTransportClient(k8sclient.NewMockK8sClient()).
 		WithLogger(logger.NewTestLogger()).
+		WithMetricsRecorder(recorder).
 		Build()
 	require.NoError(t, err)
-
 	handler := exec.CreateHandler()
 
-	evt := event.New()
-	evt.SetID("test-event-nil")
-	evt.SetType("com.hyperfleet.test")
-	evt.SetSource("test")
-	_ = evt.SetData(event.ApplicationJSON, []byte(`{"id":"cluster-1"}`))
+	newEvent := func(id, eventType string, producedAt time.Time) *event.Event {
+		return eventtest.NewEvent().
+			WithID(id).
+			WithType(eventType).
+			WithTime(producedAt).
+			WithDataJSON(`{"id":"cluster-1"}`).
+			Build()
+	}
 
-	assert.NotPanics(t, func() {
-		_ = handler(context.Background(), &evt)
-	}, "handler with nil MetricsRecorder should not panic")
-}
+	ctx := context.Background()
+	now := time.Now()
+	require.NoError(t, handler(ctx, newEvent("e1", "io.hyperfleet.cluster.updated", now.Add(-2*time.Second))))
+	require.NoError(t, handler(ctx, newEvent("e2", "io.example.unexpected", now.Add(-time.Second))))
+	// Produced in the future: clock skew
+	require.NoError(t, handler(ctx, newEvent("e3", "io.example.another", now.Add(time.Hour))))
+	// No time attribute
+	require.NoError(t, handler(ctx, newEvent("e4", "io.hyperfleet.cluster.updated", time.Time{})))
 
-// TestPreconditionAPIFailure_ExecutionStatusRemainsFailed verifies that when a precondition
-// API call fails, adapter.executionStatus stays "failed" and is not overwritten to "success".
-// This is a regression test for a bug where SetSkipped() was called after SetError(),
-// resetting executionStatus and causing Health CEL expressions to evaluate incorrectly.
-func TestPreconditionAPIFailure_ExecutionStatusRemainsFailed(t *testing.T) {
-	// Configure mock to return an error on GET (simulating precondition API failure)
-	mockClient := newMockAPIClient()
-	mockClient.GetError = fmt.Errorf("connection refused")
-	mockClient.GetResponse = nil
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	histogramCounts := func(name string) map[string]uint64 {
+		counts := map[string]uint64{}
+		family := findFamily(families, name)
+		require.NotNil(t, family, name)
+		for _, m := range family.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "event_type" {
+					counts[l.GetValue()] += m.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+		return counts
+	}
+
+	assert.Equal(t, map[string]uint64{"io.hyperfleet.cluster.updated": 2, metrics.OtherEventType: 2},
+		histogramCounts("hyperfleet_adapter_event_processing_seconds"),
+		"unexpected event types are bucketed into other")
+	assert.Equal(t, map[string]uint64{"io.hyperfleet.cluster.updated": 1, metrics.OtherEventType: 2},
+		histogramCounts("hyperfleet_adapter_event_e2e_latency_seconds"),
+		"events without a time attribute are not observed")
+	assert.Equal(t, float64(1),
+		getCounterValue(t, families, "hyperfleet_adapter_event_clock_skew_total", "event_type", metrics.OtherEventType))
+
+	inFlight := findFamily(families, "hyperfleet_adapter_events_in_flight")
+	require.NotNil(t, inFlight)
+	for _, m := range inFlight.GetMetric() {
+		assert.Zero(t, m.GetGauge().GetValue(), "no event is in flight after the handler returns")
+	}
+	assert.Zero(t, exec.InFlight())
+}
 
+// TestCreateHandler_ExecutionHistory verifies completed executions are summarized in the history
+func TestCreateHandler_ExecutionHistory(t *testing.T) {
 	config := &configloader.Config{
-		Adapter: configloader.AdapterInfo{
-			Name:    "test-adapter",
-			Version: "1.0.0",
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		Preconditions: []configloader.Precondition{
+			{ActionBase: configloader.ActionBase{Name: "check"}, Expression: "false"},
 		},
-		Clients: configloader.ClientsConfig{
-			HyperfleetAPI: configloader.HyperfleetAPIConfig{
-				BaseURL: "http://mock-api:8000",
-				Version: "v1",
-			},
+	}
+	history := health.NewExecutionHistory(10)
+
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		WithExecutionHistory(history).
+		Build()
+	require.NoError(t, err)
+
+	evt := eventtest.NewEvent().
+		WithID("test-event-history").
+		WithType("com.hyperfleet.test").
+		WithDataJSON(`{"id":"cluster-1"}`).
+		Build()
+	require.NoError(t, exec.CreateHandler()(context.Background(), evt))
+
+	recent := history.Recent("")
+	require.Len(t, recent, 1)
+	assert.Equal(t, "test-event-history", recent[0].EventID)
+	assert.Equal(t, "com.hyperfleet.test", recent[0].EventType)
+	assert.Equal(t, "skipped", recent[0].Status)
+	assert.NotEmpty(t, recent[0].Reason)
+	assert.NotEmpty(t, recent[0].Duration)
+}
+
+// TestExecutor_Clock verifies executions are timed on the injected clock
+func TestExecutor_Clock(t *testing.T) {
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		Preconditions: []configloader.Precondition{
+			{ActionBase: configloader.ActionBase{Name: "check"}, Expression: "false"},
 		},
+	}
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	history := health.NewExecutionHistory(10)
+
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		WithExecutionHistory(history).
+		WithClock(clock.NewFake(now)).
+		Build()
+	require.NoError(t, err)
+
+	result := exec.Execute(context.Background(), map[string]interface{}{})
+	evaluations := result.ExecutionContext.GetEvaluations()
+	require.Len(t, evaluations, 1)
+	assert.Equal(t, now, evaluations[0].Timestamp)
+
+	evt := eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build()
+	require.NoError(t, exec.CreateHandler()(context.Background(), evt))
+	recent := history.Recent("")
+	require.Len(t, recent, 1)
+	assert.Equal(t, now, recent[0].Timestamp)
+	assert.Equal(t, "0s", recent[0].Duration, "the fake clock does not move during the execution")
+}
+
+// TestExecutionSummary_RedactsEnvParams verifies env-sourced param values never appear in the summary
+func TestExecutionSummary_RedactsEnvParams(t *testing.T) {
+	exec := &Executor{clock: clock.Real, config: &ExecutorConfig{Config: &configloader.Config{
 		Params: []configloader.Parameter{
-			{Name: "clusterId", Source: "event.id", Required: true},
+			{Name: "token", Source: "env.API_TOKEN"},
+			{Name: "clusterId", Source: "event.id"},
 		},
-		Preconditions: []configloader.Precondition{
-			{
+	}}}
+
+	evt := eventtest.NewEvent().WithID("evt-1").Build()
+	result := &ExecutionResult{
+		Status:       StatusFailed,
+		CurrentPhase: PhasePreconditions,
+		Params:       map[string]interface{}{"token": "s3cret-token", "clusterId": "cluster-1"},
+		Errors: map[ExecutionPhase]error{
+			PhasePreconditions: errors.New("GET /clusters/cluster-1?token=s3cret-token: 500"),
+		},
+	}
+
+	summary := exec.Summarize(evt, result, time.Second)
+	assert.Equal(t, "failed", summary.Status)
+	assert.Equal(t, string(PhasePreconditions), summary.Phase)
+	assert.Equal(t, "GET /clusters/cluster-1?token=[REDACTED]: 500", summary.Reason)
+	assert.Equal(t, string(ErrorCodeInternal), summary.ErrorCode)
+}
+
+// TestExecute_CorrelationID verifies the correlation ID of an execution is
+// adopted from the event or generated, and reaches every API call, applied
+// resource and post payload
+func TestExecute_CorrelationID(t *testing.T) {
+	newConfig := func(correlation configloader.CorrelationConfig) *configloader.Config {
+		return &configloader.Config{
+			Adapter:     configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+			Correlation: correlation,
+			Preconditions: []configloader.Precondition{{
 				ActionBase: configloader.ActionBase{
-					Name: "clusterStatus",
-					APICall: &configloader.APICall{
-						Method:  "GET",
-						URL:     "/clusters/{{ .clusterId }}",
-						Timeout: "2s",
+					Name:    "clusterStatus",
+					APICall: &configloader.APICall{Method: "GET", URL: "/clusters/cluster-1"},
+				},
+			}},
+			Resources: []configloader.Resource{{
+				Name: "cm",
+				Manifest: map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "ConfigMap",
+					"metadata": map[string]interface{}{
+						"name":        "cm",
+						"namespace":   "default",
+						"annotations": map[string]interface{}{"team": "platform"},
 					},
 				},
+			}},
+			Post: &configloader.PostConfig{
+				Payloads: []configloader.Payload{{Name: "statusPayload", Build: map[string]interface{}{
+					"correlationId": map[string]interface{}{"expression": "adapter.correlationId"},
+				}}},
+				PostActions: []configloader.PostAction{{ActionBase: configloader.ActionBase{
+					Name: "report",
+					APICall: &configloader.APICall{
+						Method: "POST", URL: "/clusters/cluster-1/statuses", Body: "{{ .statusPayload }}",
+					},
+				}}},
 			},
+		}
+	}
+	run := func(t *testing.T, config *configloader.Config, evt *event.Event) (
+		*ExecutionResult, *hyperfleetapi.MockClient, *k8sclient.MockK8sClient,
+	) {
+		apiClient := newMockAPIClient()
+		apiClient.GetResponse = &hyperfleetapi.Response{StatusCode: 200, Status: "200 OK", Body: []byte(`{}`)}
+		k8sClient := k8sclient.NewMockK8sClient()
+		exec, err := NewBuilder().
+			WithConfig(config).
+			WithAPIClient(apiClient).
+			WithTransportClient(k8sClient).
+			WithLogger(logger.NewTestLogger()).
+			Build()
+		require.NoError(t, err)
+		result := exec.ExecuteEvent(context.Background(), evt)
+		require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+		return result, apiClient, k8sClient
+	}
+	assertPropagated := func(
+		t *testing.T, result *ExecutionResult, apiClient *hyperfleetapi.MockClient, k8sClient *k8sclient.MockK8sClient,
+		header, annotation, correlationID string,
+	) {
+		require.Len(t, apiClient.Requests, 2)
+		for _, req := range apiClient.Requests {
+			assert.Equal(t, correlationID, req.Headers[header], "%s %s", req.Method, req.URL)
+		}
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal(apiClient.Requests[1].Body, &payload))
+		assert.Equal(t, correlationID, payload["correlationId"])
+
+		applied := k8sClient.Resources["default/cm"]
+		require.NotNil(t, applied)
+		assert.Equal(t, map[string]string{"team": "platform", annotation: correlationID}, applied.GetAnnotations())
+		assert.Equal(t, correlationID, result.CorrelationID)
+	}
+
+	t.Run("adopted from the event", func(t *testing.T) {
+		evt := eventtest.NewEvent().
+			WithExtension(CorrelationIDExtension, "corr-123").
+			WithDataJSON(`{"id":"cluster-1"}`).
+			Build()
+		result, apiClient, k8sClient := run(t, newConfig(configloader.CorrelationConfig{}), evt)
+		assertPropagated(t, result, apiClient, k8sClient,
+			configloader.DefaultCorrelationHeader, configloader.DefaultCorrelationAnnotation, "corr-123")
+	})
+
+	t.Run("generated with configured names", func(t *testing.T) {
+		evt := eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build()
+		correlation := configloader.CorrelationConfig{Header: "X-Request-Id", Annotation: "example.com/request-id"}
+		result, apiClient, k8sClient := run(t, newConfig(correlation), evt)
+		_, err := uuid.Parse(result.CorrelationID)
+		require.NoError(t, err, "a UUID is generated")
+		assertPropagated(t, result, apiClient, k8sClient, "X-Request-Id", "example.com/request-id", result.CorrelationID)
+		assert.NotContains(t, apiClient.Requests[0].Headers, configloader.DefaultCorrelationHeader)
+	})
+}
+
+// recordingAuditSink keeps the audit records written by an Auditor
+type recordingAuditSink struct {
+	records []audit.Record
+	mu      sync.Mutex
+}
+
+func (s *recordingAuditSink) Name() string { return "recording" }
+
+func (s *recordingAuditSink) Write(_ context.Context, data []byte) error {
+	var record audit.Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *recordingAuditSink) Close() error { return nil }
+
+// TestCreateHandler_AuditRecord verifies every execution is audited with its
+// preconditions, applied resources and API calls, and redacted params
+func TestCreateHandler_AuditRecord(t *testing.T) {
+	t.Setenv("AUDIT_TEST_TOKEN", "s3cret-token")
+	apiClient := newMockAPIClient()
+	apiClient.GetResponse = &hyperfleetapi.Response{StatusCode: 200, Status: "200 OK", Body: []byte(`{}`)}
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+		Params: []configloader.Parameter{
+			{Name: "clusterId", Source: "event.id"},
+			{Name: "token", Source: "env.AUDIT_TEST_TOKEN"},
 		},
+		Preconditions: []configloader.Precondition{{
+			ActionBase: configloader.ActionBase{
+				Name: "clusterStatus",
+				APICall: &configloader.APICall{
+					Method: "GET",
+					URL:    "/clusters/{{ .clusterId }}?token={{ .token }}",
+				},
+			},
+		}},
+		Resources: []configloader.Resource{{
+			Name: "cm",
+			Manifest: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "cm-{{ .clusterId }}", "namespace": "default"},
+			},
+		}},
 	}
+	sink := &recordingAuditSink{}
+	auditor := audit.New(audit.Config{Logger: logger.NewTestLogger(), Sinks: []audit.Sink{sink}})
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
 
 	exec, err := NewBuilder().
 		WithConfig(config).
-		WithAPIClient(mockClient).
+		WithAPIClient(apiClient).
 		WithTransportClient(k8sclient.NewMockK8sClient()).
 		WithLogger(logger.NewTestLogger()).
+		WithAuditor(auditor).
+		WithClock(clock.NewFake(now)).
 		Build()
 	require.NoError(t, err)
 
-	ctx := logger.WithEventID(context.Background(), "test-precond-fail")
-	result := exec.Execute(ctx, map[string]interface{}{"id": "cluster-123"})
+	evt := eventtest.NewEvent().
+		WithID("evt-audit").
+		WithType("com.hyperfleet.test").
+		WithSource("/clusters/cluster-1").
+		WithDataJSON(`{"id":"cluster-1"}`).
+		Build()
+	require.NoError(t, exec.CreateHandler()(context.Background(), evt))
+	require.NoError(t, auditor.Close(context.Background()))
+
+	require.Len(t, sink.records, 1)
+	record := sink.records[0]
+	assert.Equal(t, audit.SchemaVersion, record.SchemaVersion)
+	assert.Equal(t, "test-adapter", record.Adapter)
+	assert.Equal(t, audit.Event{ID: "evt-audit", Type: "com.hyperfleet.test", Source: "/clusters/cluster-1"}, record.Event)
+	assert.Equal(t, "success", record.Status)
+	assert.True(t, record.FinishedAt.Equal(now))
+	assert.True(t, record.StartedAt.Equal(now))
+
+	assert.Equal(t, "cluster-1", record.Params["clusterId"])
+	assert.Equal(t, "[REDACTED]", record.Params["token"])
+	assert.NotContains(t, record.Params, "config")
+
+	assert.Equal(t, []audit.Precondition{{StartedAt: now, Name: "clusterStatus", Outcome: audit.OutcomeMet}},
+		record.Preconditions)
+
+	require.Len(t, record.Resources, 1)
+	resource := record.Resources[0]
+	assert.Equal(t, "v1", resource.APIVersion)
+	assert.Equal(t, "ConfigMap", resource.Kind)
+	assert.Equal(t, "default", resource.Namespace)
+	assert.Equal(t, "cm-cluster-1", resource.ResourceName)
+	assert.Equal(t, "success", resource.Status)
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, resource.ContentHash)
+
+	require.Len(t, record.APICalls, 1)
+	assert.Equal(t, audit.APICall{
+		Phase:      string(PhasePreconditions),
+		Step:       "clusterStatus",
+		Method:     "GET",
+		URL:        "/clusters/cluster-1?token=[REDACTED]",
+		Target:     hyperfleetapi.DefaultTarget,
+		StatusCode: 200,
+	}, record.APICalls[0])
+}
 
-	// Verify overall result status is failed
-	assert.Equal(t, StatusFailed, result.Status, "expected overall status to be failed")
-	assert.True(t, result.ResourcesSkipped, "resources should be skipped on precondition failure")
+// TestCreateHandler_NilMetricsRecorder verifies handler works without a metrics recorder
+func TestCreateHandler_NilMetricsRecorder(t *testing.T) {
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+	}
 
-	// Critical assertion: verify adapter.executionStatus is "failed", not "success"
-	require.NotNil(t, result.ExecutionContext, "execution context should be present")
-	assert.Equal(t, string(StatusFailed), result.ExecutionContext.Adapter.ExecutionStatus,
-		"adapter.executionStatus must remain 'failed' after precondition API failure")
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
 
-	// Verify error information is preserved
-	assert.Equal(t, "PreconditionFailed", result.ExecutionContext.Adapter.ErrorReason,
-		"adapter.errorReason should be 'PreconditionFailed'")
-	assert.NotEmpty(t, result.ExecutionContext.Adapter.ErrorMessage,
-		"adapter.errorMessage should contain the error details")
+	handler := exec.CreateHandler()
 
-	// Verify skip metadata is also set (for CEL expressions that check resourcesSkipped)
-	assert.True(t, result.ExecutionContext.Adapter.ResourcesSkipped,
-		"adapter.resourcesSkipped should be true")
-	assert.NotEmpty(t, result.ExecutionContext.Adapter.SkipReason,
-		"adapter.skipReason should be set")
-}
+	evt := eventtest.NewEvent().
+		WithID("test-event-nil").
+		WithType("com.hyperfleet.test").
+		WithDataJSON(`{"id":"cluster-1"}`).
+		Build()
 
-// helper functions for metrics assertions
+	assert.NotPanics(t, func() {
+		_ = handler(context.Background(), evt)
+	}, "handler with nil MetricsRecorder should not panic")
+}
 
-func findFamily(families []*dto.MetricFamily, name string) *dto.MetricFamily {
-	for _, f := range families {
-		if f.GetName() == name {
-			return f
-		}
+// TestCreateHandler_Heartbeat verifies the heartbeat is beaten after an execution completes
+func TestCreateHandler_Heartbeat(t *testing.T) {
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
 	}
-	return nil
+	heartbeat := health.NewServer(logger.NewTestLogger(), "0", "test-adapter").Heartbeat("executor", time.Minute)
+
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		WithHeartbeat(heartbeat).
+		Build()
+	require.NoError(t, err)
+	require.True(t, heartbeat.Last().IsZero())
+
+	evt := eventtest.NewEvent().
+		WithID("test-event-heartbeat").
+		WithType("com.hyperfleet.test").
+		WithDataJSON(`{"id":"cluster-1"}`).
+		Build()
+
+	require.NoError(t, exec.CreateHandler()(context.Background(), evt))
+	assert.False(t, heartbeat.Last().IsZero(), "completed execution should beat the heartbeat")
 }
 
-func getCounterValue(t *testing.T, families []*dto.MetricFamily, metricName, labelName, labelValue string) float64 {
-	t.Helper()
-	family := findFamily(families, metricName)
-	if family == nil {
-		t.Fatalf("metric %s not found", metricName)
-	}
-	for _, m := range family.GetMetric() {
-		for _, l := range m.GetLabel() {
-			if l.GetName() == labelName && l.GetValue() == labelValue {
-				return m.GetCounter().GetValue()
-			}
-		}
+// TestCreateHandler_NonJSONData verifies non-JSON event data fails gracefully and is counted as a decode error
+func TestCreateHandler_NonJSONData(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewRecorder("test-adapter", "v0.1.0", registry)
+
+	exec, err := NewBuilder().
+		WithConfig(&configloader.Config{
+			Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		}).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		WithMetricsRecorder(recorder).
+		Build()
+	require.NoError(t, err)
+
+	evt := eventtest.NewEvent().
+		WithID("test-event-text").
+		WithType("com.hyperfleet.test").
+		WithData("text/plain", []byte("cluster-1 updated")).
+		Build()
+
+	require.NoError(t, exec.CreateHandler()(context.Background(), evt), "non-JSON data must be acknowledged")
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	assert.Equal(t, float64(1),
+		getCounterValue(t, families, "hyperfleet_adapter_event_decode_errors_total", "reason", "invalid_data"))
+	assert.Equal(t, float64(1),
+		getCounterValue(t, families, "hyperfleet_adapter_events_processed_total", "status", "failed"))
+}
+
+// TestLogAction_FieldsAndSampling verifies log action fields are evaluated and
+// sampled log actions only log the first of every sample_every executions
+func TestLogAction_FieldsAndSampling(t *testing.T) {
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		Params:  []configloader.Parameter{{Name: "clusterId", Source: "event.id"}},
+		Preconditions: []configloader.Precondition{{
+			ActionBase: configloader.ActionBase{
+				Name: "announce",
+				Log: &configloader.LogAction{
+					Message: "Reconciling {{ .clusterId }}",
+					Level:   "warn",
+					Fields: map[string]interface{}{
+						"cluster":  "{{ .clusterId }}",
+						"suffixed": map[string]interface{}{"expression": "clusterId + \"-suffix\""},
+						"fallback": map[string]interface{}{"field": "missing", "default": "none"},
+					},
+				},
+			},
+			Expression: "true",
+		}},
+		Post: &configloader.PostConfig{PostActions: []configloader.PostAction{{ActionBase: configloader.ActionBase{
+			Name: "sampled",
+			Log:  &configloader.LogAction{Message: "Sampled execution", SampleEvery: 3},
+		}}}},
+	}
+	log, capture := logger.NewCaptureLogger()
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(log).
+		Build()
+	require.NoError(t, err)
+
+	for i := 0; i < 7; i++ {
+		evt := eventtest.NewEvent().WithDataJSON(`{"id":"cluster-` + strconv.Itoa(i) + `"}`).Build()
+		result := exec.ExecuteEvent(context.Background(), evt)
+		require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+	}
+
+	lines := strings.Split(capture.Messages(), "\n")
+	var announced, sampled []string
+	for _, line := range lines {
+		switch {
+		case strings.Contains(line, "Reconciling cluster-0"):
+			announced = append(announced, line)
+		case strings.Contains(line, "Sampled execution"):
+			sampled = append(sampled, line)
+		}
+	}
+	require.Len(t, announced, 1)
+	assert.Contains(t, announced[0], "level=WARN")
+	assert.Contains(t, announced[0], "cluster=cluster-0")
+	assert.Contains(t, announced[0], "suffixed=cluster-0-suffix")
+	assert.Contains(t, announced[0], "fallback=none")
+	assert.NotContains(t, announced[0], "{{", "fields are rendered")
+	assert.Len(t, sampled, 3, "executions 1, 4 and 7 are logged")
+}
+
+// TestExecuteEvent_EventTooLarge verifies event data over the size limit fails before it is parsed
+func TestExecuteEvent_EventTooLarge(t *testing.T) {
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		Clients: configloader.ClientsConfig{Broker: configloader.BrokerConfig{MaxEventBytes: 64}},
+		Params:  []configloader.Parameter{{Name: "clusterId", Source: "event.id"}},
+	}
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+
+	small := exec.ExecuteEvent(context.Background(), eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build())
+	assert.Equal(t, StatusSuccess, small.Status)
+
+	data := `{"id":"cluster-1","logs":"` + strings.Repeat("x", 100) + `"}`
+	result := exec.ExecuteEvent(context.Background(), eventtest.NewEvent().WithDataJSON(data).Build())
+	require.Equal(t, StatusFailed, result.Status)
+	assert.Equal(t, PhaseParamExtraction, result.CurrentPhase)
+	err = result.Errors[PhaseParamExtraction]
+	assert.Equal(t, ErrorCodeEventTooLarge, ErrorCodeOf(err))
+	assert.ErrorContains(t, err, fmt.Sprintf("event data is %d bytes, over the 64 byte limit", len(data)))
+	assert.Empty(t, result.Params, "params are not extracted")
+}
+
+// TestAPIResponse_Truncated verifies retained API response bodies are cut to the configured limit
+func TestAPIResponse_Truncated(t *testing.T) {
+	body := `{"status":"ok","logs":"` + strings.Repeat("x", 100) + `"}`
+	apiClient := newMockAPIClient()
+	apiClient.GetResponse = &hyperfleetapi.Response{StatusCode: 200, Status: "200 OK", Body: []byte(body)}
+	apiClient.PostResponse = &hyperfleetapi.Response{StatusCode: 200, Status: "200 OK", Body: []byte(`{}`)}
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		Clients: configloader.ClientsConfig{
+			HyperfleetAPI: configloader.HyperfleetAPIConfig{MaxRetainedResponseBytes: 32},
+		},
+		Preconditions: []configloader.Precondition{{
+			ActionBase: configloader.ActionBase{
+				Name:    "clusterStatus",
+				APICall: &configloader.APICall{Method: "GET", URL: "/clusters/cluster-1"},
+			},
+			Capture: []configloader.CaptureField{{Name: "status", FieldExpressionDef: configloader.FieldExpressionDef{
+				Field: "status",
+			}}},
+		}},
+		Post: &configloader.PostConfig{PostActions: []configloader.PostAction{{ActionBase: configloader.ActionBase{
+			Name:    "report",
+			APICall: &configloader.APICall{Method: "POST", URL: "/clusters/cluster-1/statuses", Body: `{}`},
+		}}}},
+	}
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(apiClient).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+
+	result := exec.ExecuteEvent(context.Background(), eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build())
+	require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+
+	precond := result.PreconditionResults[0]
+	assert.Equal(t, body[:32], string(precond.APIResponse))
+	assert.True(t, precond.APIResponseTruncated)
+	assert.Equal(t, "ok", precond.CapturedFields["status"], "captures use the full body")
+
+	post := result.PostActionResults[0]
+	assert.Equal(t, `{}`, string(post.APIResponse))
+	assert.False(t, post.APIResponseTruncated)
+}
+
+// TestExecutionContext_RetainedBytesLimit verifies an execution calling an API
+// with a large response retains no more than limits.max_retained_bytes of it
+func TestExecutionContext_RetainedBytesLimit(t *testing.T) {
+	const retainedLimit = 96 * 1024
+	body := `{"status":"ok","logs":"` + strings.Repeat("x", 5*1024*1024) + `"}`
+	newExecutor := func(t *testing.T, limits *configloader.Limits) *Executor {
+		apiClient := newMockAPIClient()
+		apiClient.GetResponse = &hyperfleetapi.Response{StatusCode: 200, Status: "200 OK", Body: []byte(body)}
+		apiClient.PostResponse = &hyperfleetapi.Response{StatusCode: 200, Status: "200 OK", Body: []byte(body)}
+		config := &configloader.Config{
+			Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+			Preconditions: []configloader.Precondition{{
+				ActionBase: configloader.ActionBase{
+					Name:    "clusterStatus",
+					APICall: &configloader.APICall{Method: "GET", URL: "/clusters/cluster-1"},
+				},
+				Capture: []configloader.CaptureField{{Name: "status", FieldExpressionDef: configloader.FieldExpressionDef{
+					Field: "status",
+				}}},
+				Expression: `clusterStatus.status == "ok"`,
+			}},
+			Post: &configloader.PostConfig{PostActions: []configloader.PostAction{{ActionBase: configloader.ActionBase{
+				Name:    "report",
+				APICall: &configloader.APICall{Method: "POST", URL: "/clusters/cluster-1/statuses", Body: `{}`},
+			}}}},
+			Limits: limits,
+		}
+		exec, err := NewBuilder().
+			WithConfig(config).
+			WithAPIClient(apiClient).
+			WithTransportClient(k8sclient.NewMockK8sClient()).
+			WithLogger(logger.NewTestLogger()).
+			Build()
+		require.NoError(t, err)
+		return exec
+	}
+
+	result := newExecutor(t, &configloader.Limits{MaxRetainedBytes: retainedLimit}).
+		ExecuteEvent(context.Background(), eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build())
+	require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+	assert.Equal(t, "ok", result.Params["status"], "captures use the full body")
+
+	// The first response is cut to the 64 KiB response limit, the second to what is left
+	assert.Len(t, result.PreconditionResults[0].APIResponse, hyperfleetapi.DefaultMaxRetainedResponseBytes)
+	assert.Len(t, result.PostActionResults[0].APIResponse, retainedLimit-hyperfleetapi.DefaultMaxRetainedResponseBytes)
+	assert.True(t, result.PostActionResults[0].APIResponseTruncated)
+	assert.Equal(t, retainedLimit, result.ExecutionContext.RetainedBytes())
+
+	// Compacted: the parsed response is dropped once the phases ran
+	assert.NotContains(t, result.Params, "clusterStatus")
+	serialized, err := json.Marshal(result)
+	require.NoError(t, err)
+	// Each retained body is serialized with its step and in the API calls
+	assert.Less(t, len(serialized), 2*retainedLimit+16*1024, "the result stays within the retained bytes")
+
+	kept := newExecutor(t, &configloader.Limits{MaxRetainedBytes: retainedLimit, KeepRawResponses: true}).
+		ExecuteEvent(context.Background(), eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build())
+	require.Equal(t, StatusSuccess, kept.Status, "errors: %v", kept.Errors)
+	assert.Contains(t, kept.Params, "clusterStatus")
+	serialized, err = json.Marshal(kept)
+	require.NoError(t, err)
+	assert.Greater(t, len(serialized), 5*1024*1024, "the parsed response is serialized with the params")
+}
+
+// TestCaptureResponseAs verifies a later precondition and post payloads
+// navigate the response captured whole by an earlier precondition
+func TestCaptureResponseAs(t *testing.T) {
+	newConfig := func(wantPhase string, maxBytes int) *configloader.Config {
+		return &configloader.Config{
+			Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+			Clients: configloader.ClientsConfig{
+				HyperfleetAPI: configloader.HyperfleetAPIConfig{MaxRetainedResponseBytes: maxBytes},
+			},
+			Preconditions: []configloader.Precondition{
+				{
+					ActionBase: configloader.ActionBase{
+						Name:    "getCluster",
+						APICall: &configloader.APICall{Method: "GET", URL: "/clusters/cluster-1"},
+					},
+					CaptureResponseAs: "clusterRecord",
+				},
+				{
+					ActionBase: configloader.ActionBase{Name: "clusterPhase"},
+					Conditions: []configloader.Condition{
+						{Field: "clusterRecord.status.phase", Operator: "equals", Value: wantPhase},
+						{Field: "clusterRecord.spec.replicas", Operator: "greaterThan", Value: 2},
+					},
+				},
+			},
+			Post: &configloader.PostConfig{
+				Payloads: []configloader.Payload{{Name: "statusPayload", Build: map[string]interface{}{
+					"region": map[string]interface{}{"expression": "clusterRecord.spec.region"},
+					"phase":  "{{ .clusterRecord.status.phase }}",
+				}}},
+				PostActions: []configloader.PostAction{{ActionBase: configloader.ActionBase{
+					Name:    "report",
+					APICall: &configloader.APICall{Method: "POST", URL: "/statuses", Body: "{{ .statusPayload }}"},
+				}}},
+			},
+		}
+	}
+	execute := func(t *testing.T, config *configloader.Config) (*ExecutionResult, *hyperfleetapi.MockClient) {
+		apiClient := newMockAPIClient()
+		apiClient.GetResponse = &hyperfleetapi.Response{StatusCode: 200, Status: "200 OK", Body: []byte(
+			`{"spec":{"region":"us-east-1","replicas":3},"status":{"phase":"Ready"}}`)}
+		exec, err := NewBuilder().
+			WithConfig(config).
+			WithAPIClient(apiClient).
+			WithTransportClient(k8sclient.NewMockK8sClient()).
+			WithLogger(logger.NewTestLogger()).
+			Build()
+		require.NoError(t, err)
+		return exec.ExecuteEvent(context.Background(), eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build()),
+			apiClient
+	}
+
+	t.Run("later steps navigate the capture", func(t *testing.T) {
+		result, apiClient := execute(t, newConfig("Ready", 0))
+		require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+		require.False(t, result.ResourcesSkipped, "the second precondition is met")
+
+		record, ok := result.Params["clusterRecord"].(map[string]interface{})
+		require.True(t, ok, "the capture is the parsed map, not a JSON string")
+		assert.Equal(t, "us-east-1", record["spec"].(map[string]interface{})["region"])
+		assert.Equal(t, record, result.PreconditionResults[0].CapturedFields["clusterRecord"])
+
+		require.Len(t, apiClient.Requests, 2, "the cluster is fetched once")
+		assert.JSONEq(t, `{"region":"us-east-1","phase":"Ready"}`, string(apiClient.Requests[1].Body))
+	})
+
+	t.Run("conditions on the capture are evaluated", func(t *testing.T) {
+		result, _ := execute(t, newConfig("Provisioning", 0))
+		require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+		assert.True(t, result.ResourcesSkipped, "the second precondition is not met")
+		assert.Contains(t, result.SkipReason, "clusterPhase")
+	})
+
+	t.Run("response over the retained limit", func(t *testing.T) {
+		result, _ := execute(t, newConfig("Ready", 16))
+		require.Equal(t, StatusFailed, result.Status)
+		err := result.Errors[PhasePreconditions]
+		assert.Equal(t, ErrorCodeAPIResponseTooLarge, ErrorCodeOf(err))
+		assert.Contains(t, err.Error(), "16 byte limit")
+		assert.NotContains(t, result.Params, "clusterRecord")
+	})
+}
+
+func TestResponseTransform(t *testing.T) {
+	const body = `{"data":{"status":{"phase":"Ready"},"items":[` +
+		`{"kind":"nodepool","name":"np-1"},{"kind":"machine","name":"m-1"},{"kind":"nodepool","name":"np-2"}]},` +
+		`"meta":{"requestId":"abc"}}`
+	execute := func(t *testing.T, precondition configloader.Precondition, check configloader.Precondition) *ExecutionResult {
+		apiClient := newMockAPIClient()
+		apiClient.GetResponse = &hyperfleetapi.Response{StatusCode: 200, Status: "200 OK", Body: []byte(body)}
+		exec, err := NewBuilder().
+			WithConfig(&configloader.Config{
+				Adapter:       configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+				Preconditions: []configloader.Precondition{precondition, check},
+			}).
+			WithAPIClient(apiClient).
+			WithTransportClient(k8sclient.NewMockK8sClient()).
+			WithLogger(logger.NewTestLogger()).
+			Build()
+		require.NoError(t, err)
+		return exec.ExecuteEvent(context.Background(), eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build())
+	}
+	getCluster := func(transform string) configloader.Precondition {
+		return configloader.Precondition{ActionBase: configloader.ActionBase{
+			Name:    "getCluster",
+			APICall: &configloader.APICall{Method: "GET", URL: "/clusters/cluster-1", Transform: transform},
+		}}
+	}
+
+	t.Run("unwrapped object", func(t *testing.T) {
+		precondition := getCluster("response.data")
+		precondition.Capture = []configloader.CaptureField{{Name: "phase", FieldExpressionDef: configloader.FieldExpressionDef{
+			Field: "status.phase",
+		}}}
+		precondition.Conditions = []configloader.Condition{{Field: "phase", Operator: "equals", Value: "Ready"}}
+		result := execute(t, precondition, configloader.Precondition{
+			ActionBase: configloader.ActionBase{Name: "checkPhase"},
+			Conditions: []configloader.Condition{{Field: "getCluster.status.phase", Operator: "equals", Value: "Ready"}},
+		})
+		require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+		assert.False(t, result.ResourcesSkipped, "the conditions see the transformed response")
+		assert.Equal(t, "Ready", result.Params["phase"])
+		assert.JSONEq(t, body, string(result.PreconditionResults[0].APIResponse), "the raw body is retained")
+	})
+
+	t.Run("filtered list", func(t *testing.T) {
+		precondition := getCluster(`response.data.items.filter(i, i.kind == "nodepool")`)
+		precondition.CaptureResponseAs = "nodePools"
+		precondition.Capture = []configloader.CaptureField{{Name: "firstNodePool", FieldExpressionDef: configloader.FieldExpressionDef{
+			Expression: "response[0].name",
+		}}}
+		result := execute(t, precondition, configloader.Precondition{
+			ActionBase: configloader.ActionBase{Name: "twoNodePools"},
+			Expression: `size(getCluster) == 2 && nodePools.all(p, p.kind == "nodepool")`,
+		})
+		require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+		assert.False(t, result.ResourcesSkipped, "the list has the two node pools")
+		assert.Equal(t, "np-1", result.Params["firstNodePool"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"kind": "nodepool", "name": "np-1"},
+			map[string]interface{}{"kind": "nodepool", "name": "np-2"},
+		}, result.Params["nodePools"])
+	})
+
+	t.Run("transform error", func(t *testing.T) {
+		result := execute(t, getCluster("response.missing.items"), configloader.Precondition{
+			ActionBase: configloader.ActionBase{Name: "never"},
+		})
+		require.Equal(t, StatusFailed, result.Status)
+		err := result.Errors[PhasePreconditions]
+		assert.Equal(t, ErrorCodeCELEvaluationError, ErrorCodeOf(err))
+		assert.Contains(t, err.Error(), "getCluster")
+		assert.Contains(t, err.Error(), "response transform failed")
+	})
+}
+
+// TestExecuteEvent_SchemaValidation verifies event data is validated against the schema for its type
+func TestExecuteEvent_SchemaValidation(t *testing.T) {
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		EventSchemas: []configloader.EventSchema{{
+			EventType: "io.hyperfleet.cluster.updated",
+			Schema: map[string]interface{}{
+				"type":                 "object",
+				"required":             []interface{}{"id"},
+				"additionalProperties": false,
+				"properties": map[string]interface{}{
+					"id": map[string]interface{}{"type": "string"},
+				},
+			},
+		}},
+		Params: []configloader.Parameter{{Name: "clusterId", Source: "event.id"}},
+	}
+
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+
+	newEvent := func(eventType, data string) *event.Event {
+		return eventtest.NewEvent().WithID("evt-1").WithType(eventType).WithDataJSON(data).Build()
+	}
+
+	t.Run("violations stop execution before param extraction", func(t *testing.T) {
+		result := exec.ExecuteEvent(context.Background(),
+			newEvent("io.hyperfleet.cluster.updated", `{"id":42,"extra":true}`))
+
+		assert.Equal(t, StatusFailed, result.Status)
+		assert.Equal(t, PhaseSchemaValidation, result.CurrentPhase)
+		require.Len(t, result.SchemaViolations, 2)
+		assert.Equal(t, "", result.SchemaViolations[0].Pointer)
+		assert.Equal(t, "/id", result.SchemaViolations[1].Pointer)
+		var schemaErr *configloader.SchemaViolationError
+		assert.ErrorAs(t, result.Errors[PhaseSchemaValidation], &schemaErr)
+		assert.Empty(t, result.Params)
+	})
+
+	t.Run("valid data passes", func(t *testing.T) {
+		result := exec.ExecuteEvent(context.Background(),
+			newEvent("io.hyperfleet.cluster.updated", `{"id":"cluster-1"}`))
+		assert.Equal(t, StatusSuccess, result.Status)
+		assert.Equal(t, "cluster-1", result.Params["clusterId"])
+	})
+
+	t.Run("event type without schema passes through", func(t *testing.T) {
+		result := exec.ExecuteEvent(context.Background(),
+			newEvent("io.hyperfleet.nodepool.updated", `{"id":"np-1","extra":true}`))
+		assert.Equal(t, StatusSuccess, result.Status)
+		assert.Empty(t, result.SchemaViolations)
+	})
+}
+
+// readEventFixture reads an event payload from testdata/events
+func readEventFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "events", name))
+	require.NoError(t, err)
+	return data
+}
+
+func TestParseEventDataAs(t *testing.T) {
+	jsonData, jsonRaw, err := ParseEventDataAs(readEventFixture(t, "cluster.json"), ContentTypeJSON)
+	require.NoError(t, err)
+
+	t.Run("YAML is indistinguishable from the equivalent JSON", func(t *testing.T) {
+		for _, contentType := range []string{
+			"application/yaml", "application/x-yaml", "text/yaml", "application/yaml; charset=utf-8",
+		} {
+			yamlData, yamlRaw, err := ParseEventDataAs(readEventFixture(t, "cluster.yaml"), contentType)
+			require.NoError(t, err, contentType)
+			assert.Equal(t, jsonData, yamlData, contentType)
+			assert.Equal(t, jsonRaw, yamlRaw, contentType)
+		}
+
+		spec := jsonRaw["spec"].(map[string]interface{})
+		assert.Equal(t, float64(3), spec["replicas"], "numbers decode as JSON numbers")
+		assert.Equal(t, map[string]interface{}{"443": "https", "6443": "api"}, spec["ports"],
+			"numeric keys are stringified")
+		nodePools := spec["nodePools"].([]interface{})
+		assert.Equal(t, spec["labels"], nodePools[1].(map[string]interface{})["labels"], "merge keys are resolved")
+	})
+
+	t.Run("JSON content types", func(t *testing.T) {
+		for _, contentType := range []string{"", "application/json", "text/json", "application/cloudevents+json"} {
+			_, raw, err := ParseEventDataAs(readEventFixture(t, "cluster.json"), contentType)
+			require.NoError(t, err, contentType)
+			assert.Equal(t, jsonRaw, raw, contentType)
+		}
+	})
+
+	t.Run("empty data is an empty map", func(t *testing.T) {
+		for _, tc := range []struct {
+			contentType string
+			data        []byte
+		}{
+			{ContentTypeJSON, nil},
+			{ContentTypeYAML, nil},
+			{ContentTypeYAML, []byte("# nothing here\n")},
+			{ContentTypeYAML, []byte("~")},
+		} {
+			eventData, raw, err := ParseEventDataAs(tc.data, tc.contentType)
+			require.NoError(t, err, "%s %q", tc.contentType, tc.data)
+			assert.Equal(t, &EventData{}, eventData)
+			assert.Equal(t, map[string]interface{}{}, raw)
+		}
+	})
+
+	t.Run("decoded data ignores the content type", func(t *testing.T) {
+		_, raw, err := ParseEventDataAs(map[string]interface{}{"id": "cluster-1"}, "text/plain")
+		require.NoError(t, err)
+		assert.Equal(t, "cluster-1", raw["id"])
+	})
+
+	t.Run("invalid YAML", func(t *testing.T) {
+		_, _, err := ParseEventDataAs([]byte("id: [unterminated"), ContentTypeYAML)
+		assert.ErrorContains(t, err, "failed to parse YAML event data")
+	})
+
+	t.Run("unsupported content type lists the supported ones", func(t *testing.T) {
+		_, _, err := ParseEventDataAs([]byte("cluster-1 updated"), "text/plain")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `"text/plain"`)
+		assert.Contains(t, err.Error(), ContentTypeJSON)
+		assert.Contains(t, err.Error(), ContentTypeYAML)
+	})
+}
+
+// TestExecuteEvent_YAMLData verifies a YAML event drives params, CEL and templates exactly like its JSON twin
+func TestExecuteEvent_YAMLData(t *testing.T) {
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		Params: []configloader.Parameter{
+			{Name: "clusterId", Source: "event.id", Required: true},
+			{Name: "replicas", Source: "event.spec.replicas"},
+			{Name: "labels", Source: "event.spec.labels"},
+			{Name: "nodePools", Source: "event.spec.nodePools"},
+		},
+		Preconditions: []configloader.Precondition{{
+			ActionBase: configloader.ActionBase{Name: "gold"},
+			Expression: `replicas == 3 && labels.tier == "gold" && size(nodePools) == 2`,
+		}},
+	}
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+
+	execute := func(contentType, fixture string) *ExecutionResult {
+		evt := eventtest.NewEvent().
+			WithID("evt-1").
+			WithType("io.hyperfleet.cluster.updated").
+			WithExtension(CorrelationIDExtension, "corr-1").
+			WithData(contentType, readEventFixture(t, fixture)).
+			Build()
+		return exec.ExecuteEvent(context.Background(), evt)
+	}
+
+	jsonResult := execute(ContentTypeJSON, "cluster.json")
+	yamlResult := execute(ContentTypeYAML, "cluster.yaml")
+	require.Equal(t, StatusSuccess, yamlResult.Status, "errors: %v", yamlResult.Errors)
+	assert.False(t, yamlResult.ResourcesSkipped, "the CEL precondition should match")
+	assert.Equal(t, jsonResult.Params, yamlResult.Params)
+
+	rendered, err := renderTemplate("{{ .clusterId }}/{{ .replicas }}/{{ .labels.team }}", yamlResult.Params)
+	require.NoError(t, err)
+	assert.Equal(t, "cluster-1/3/platform", rendered)
+
+	t.Run("unsupported content type fails param extraction", func(t *testing.T) {
+		evt := eventtest.NewEvent().WithData("text/csv", []byte("id,kind\ncluster-1,Cluster")).Build()
+		result := exec.ExecuteEvent(context.Background(), evt)
+		assert.Equal(t, StatusFailed, result.Status)
+		assert.Equal(t, PhaseParamExtraction, result.CurrentPhase)
+		var execErr *ExecutorError
+		require.ErrorAs(t, result.Errors[PhaseParamExtraction], &execErr)
+		assert.Equal(t, ErrorCodeEventInvalid, execErr.Code)
+		assert.ErrorContains(t, execErr, "unsupported event data content type")
+	})
+}
+
+// TestExecuteEvent_DataContentTypes verifies the decision taken for event data
+// by content type: accepted, wrapped as text, filtered, or invalid and reported
+func TestExecuteEvent_DataContentTypes(t *testing.T) {
+	tests := []struct {
+		name             string
+		contentType      string
+		data             []byte
+		allowed          []string
+		plainTextDataKey string
+		wantDecision     DataDecision
+		wantContentType  string
+		wantStatus       ExecutionStatus
+		wantClusterID    interface{}
+		wantReported     bool
+	}{
+		{
+			name:            "JSON without filter is accepted",
+			contentType:     "application/json; charset=utf-8",
+			data:            []byte(`{"id":"cluster-1"}`),
+			wantDecision:    DataAccepted,
+			wantContentType: ContentTypeJSON,
+			wantStatus:      StatusSuccess,
+			wantClusterID:   "cluster-1",
+		},
+		{
+			name:            "missing content type is JSON",
+			data:            []byte(`{"id":"cluster-1"}`),
+			allowed:         []string{ContentTypeJSON},
+			wantDecision:    DataAccepted,
+			wantContentType: ContentTypeJSON,
+			wantStatus:      StatusSuccess,
+			wantClusterID:   "cluster-1",
+		},
+		{
+			name:             "plain text is wrapped under the key",
+			contentType:      "text/plain; charset=utf-8",
+			data:             []byte("cluster-1"),
+			allowed:          []string{ContentTypeJSON, ContentTypePlainText},
+			plainTextDataKey: "raw",
+			wantDecision:     DataWrapped,
+			wantContentType:  ContentTypePlainText,
+			wantStatus:       StatusSuccess,
+			wantClusterID:    "cluster-1",
+		},
+		{
+			name:            "disallowed content type is filtered",
+			contentType:     "application/protobuf",
+			data:            []byte{0x0a, 0x09},
+			allowed:         []string{ContentTypeJSON},
+			wantDecision:    DataFiltered,
+			wantContentType: "application/protobuf",
+			wantStatus:      StatusSuccess,
+		},
+		{
+			name:            "allowed content type that cannot be decoded is reported",
+			contentType:     "application/protobuf",
+			data:            []byte{0x0a, 0x09},
+			allowed:         []string{"application/protobuf"},
+			wantDecision:    DataInvalid,
+			wantContentType: "application/protobuf",
+			wantStatus:      StatusFailed,
+			wantReported:    true,
+		},
+		{
+			name:            "malformed JSON is reported",
+			contentType:     ContentTypeJSON,
+			data:            []byte(`{"id":`),
+			wantDecision:    DataInvalid,
+			wantContentType: ContentTypeJSON,
+			wantStatus:      StatusFailed,
+			wantReported:    true,
+		},
+		{
+			name:            "plain text without a key is reported",
+			contentType:     ContentTypePlainText,
+			data:            []byte("cluster-1"),
+			wantDecision:    DataInvalid,
+			wantContentType: ContentTypePlainText,
+			wantStatus:      StatusFailed,
+			wantReported:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &configloader.Config{
+				Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+				Params: []configloader.Parameter{
+					{Name: "clusterId", Source: "event.id"},
+					{Name: "rawId", Source: "event.raw"},
+				},
+				PlainTextDataKey:    tt.plainTextDataKey,
+				ReportParamFailures: true,
+				Post: &configloader.PostConfig{
+					PostActions: []configloader.PostAction{{ActionBase: configloader.ActionBase{
+						Name:    "report",
+						APICall: &configloader.APICall{Method: "POST", URL: "http://api/status"},
+					}}},
+				},
+			}
+			if tt.allowed != nil {
+				config.EventFilter = &configloader.EventFilter{DataContentTypes: tt.allowed}
+			}
+			registry := prometheus.NewRegistry()
+			mockAPI := newMockAPIClient()
+			exec, err := NewBuilder().
+				WithConfig(config).
+				WithAPIClient(mockAPI).
+				WithTransportClient(k8sclient.NewMockK8sClient()).
+				WithLogger(logger.NewTestLogger()).
+				WithMetricsRecorder(metrics.NewRecorder("test-adapter", "v0.1.0", registry)).
+				Build()
+			require.NoError(t, err)
+
+			evt := eventtest.NewEvent().WithData(tt.contentType, tt.data).Build()
+			result := exec.ExecuteEvent(context.Background(), evt)
+
+			assert.Equal(t, tt.wantStatus, result.Status, "errors: %v", result.Errors)
+			assert.Equal(t, tt.wantDecision, result.DataDecision)
+			assert.Equal(t, tt.wantContentType, result.DataContentType)
+			families, err := registry.Gather()
+			require.NoError(t, err)
+
+			switch tt.wantDecision {
+			case DataAccepted:
+				assert.Equal(t, tt.wantClusterID, result.Params["clusterId"])
+			case DataWrapped:
+				assert.Equal(t, tt.wantClusterID, result.Params["rawId"])
+			case DataFiltered:
+				assert.True(t, result.ResourcesSkipped)
+				assert.Empty(t, result.PostActionResults, "filtered events do not execute")
+				assert.Empty(t, mockAPI.Requests)
+				assert.Equal(t, float64(1),
+					getCounterValue(t, families, "hyperfleet_adapter_filtered_events_total", "component", "test-adapter"))
+			case DataInvalid:
+				assert.Equal(t, ErrorCodeEventInvalid, ErrorCodeOf(result.Errors[PhaseParamExtraction]))
+				assert.Equal(t, "EventDataInvalid", result.SkipReason)
+				assert.Equal(t, float64(1),
+					getCounterValue(t, families, "hyperfleet_adapter_event_decode_errors_total", "reason", "invalid_data"))
+			}
+			if tt.wantReported {
+				require.Len(t, result.PostActionResults, 1, "invalid data is reported by the post actions")
+				assert.Len(t, mockAPI.Requests, 1)
+			}
+		})
+	}
+}
+
+// TestPreconditionAPIFailure_ExecutionStatusRemainsFailed verifies that when a precondition
+// API call fails, adapter.executionStatus stays "failed" and is not overwritten to "success".
+// This is a regression test for a bug where SetSkipped() was called after SetError(),
+// resetting executionStatus and causing Health CEL expressions to evaluate incorrectly.
+func TestPreconditionAPIFailure_ExecutionStatusRemainsFailed(t *testing.T) {
+	// Configure mock to return an error on GET (simulating precondition API failure)
+	mockClient := newMockAPIClient()
+	mockClient.GetError = fmt.Errorf("connection refused")
+	mockClient.GetResponse = nil
+
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{
+			Name:    "test-adapter",
+			Version: "1.0.0",
+		},
+		Clients: configloader.ClientsConfig{
+			HyperfleetAPI: configloader.HyperfleetAPIConfig{
+				BaseURL: "http://mock-api:8000",
+				Version: "v1",
+			},
+		},
+		Params: []configloader.Parameter{
+			{Name: "clusterId", Source: "event.id", Required: true},
+		},
+		Preconditions: []configloader.Precondition{
+			{
+				ActionBase: configloader.ActionBase{
+					Name: "clusterStatus",
+					APICall: &configloader.APICall{
+						Method:  "GET",
+						URL:     "/clusters/{{ .clusterId }}",
+						Timeout: "2s",
+					},
+				},
+			},
+		},
+	}
+
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(mockClient).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+
+	ctx := logger.WithEventID(context.Background(), "test-precond-fail")
+	result := exec.Execute(ctx, map[string]interface{}{"id": "cluster-123"})
+
+	// Verify overall result status is failed
+	assert.Equal(t, StatusFailed, result.Status, "expected overall status to be failed")
+	assert.True(t, result.ResourcesSkipped, "resources should be skipped on precondition failure")
+
+	// Critical assertion: verify adapter.executionStatus is "failed", not "success"
+	require.NotNil(t, result.ExecutionContext, "execution context should be present")
+	assert.Equal(t, string(StatusFailed), result.ExecutionContext.Adapter.ExecutionStatus,
+		"adapter.executionStatus must remain 'failed' after precondition API failure")
+
+	// Verify error information is preserved
+	assert.Equal(t, "PreconditionFailed", result.ExecutionContext.Adapter.ErrorReason,
+		"adapter.errorReason should be 'PreconditionFailed'")
+	assert.NotEmpty(t, result.ExecutionContext.Adapter.ErrorMessage,
+		"adapter.errorMessage should contain the error details")
+
+	// Verify skip metadata is also set (for CEL expressions that check resourcesSkipped)
+	assert.True(t, result.ExecutionContext.Adapter.ResourcesSkipped,
+		"adapter.resourcesSkipped should be true")
+	assert.NotEmpty(t, result.ExecutionContext.Adapter.SkipReason,
+		"adapter.skipReason should be set")
+}
+
+// TestPreconditionErrorPolicy verifies that the collectAll policy runs every
+// precondition after execution errors and reports them all, while an unmet
+// precondition still stops the phase
+func TestPreconditionErrorPolicy(t *testing.T) {
+	apiPrecondition := func(name string) configloader.Precondition {
+		return configloader.Precondition{ActionBase: configloader.ActionBase{
+			Name:    name,
+			APICall: &configloader.APICall{Method: "GET", URL: "/clusters/" + name},
+		}}
+	}
+	exprPrecondition := func(name, expr string) configloader.Precondition {
+		return configloader.Precondition{ActionBase: configloader.ActionBase{Name: name}, Expression: expr}
+	}
+
+	tests := []struct {
+		name          string
+		policy        string
+		preconditions []configloader.Precondition
+		expectResults int
+		expectErrors  []string
+		expectMessage string
+		expectNotMet  bool
+	}{
+		{
+			name:   "fail fast by default",
+			policy: "",
+			preconditions: []configloader.Precondition{
+				apiPrecondition("clusterStatus"), apiPrecondition("nodePoolStatus"), exprPrecondition("ready", "true"),
+			},
+			expectResults: 1,
+			expectErrors:  []string{"clusterStatus"},
+			expectMessage: "[preconditions] clusterStatus: API call failed",
+		},
+		{
+			name:   "collect all",
+			policy: configloader.PreconditionErrorsCollectAll,
+			preconditions: []configloader.Precondition{
+				apiPrecondition("clusterStatus"), exprPrecondition("ready", "true"), apiPrecondition("nodePoolStatus"),
+			},
+			expectResults: 3,
+			expectErrors:  []string{"clusterStatus", "nodePoolStatus"},
+			expectMessage: "[preconditions] clusterStatus, nodePoolStatus: 2 preconditions failed",
+		},
+		{
+			name:   "collect all stops at an unmet precondition",
+			policy: configloader.PreconditionErrorsCollectAll,
+			preconditions: []configloader.Precondition{
+				exprPrecondition("ready", "false"), apiPrecondition("clusterStatus"),
+			},
+			expectResults: 1,
+			expectNotMet:  true,
+		},
+		{
+			name:   "collect all reports errors before an unmet precondition",
+			policy: configloader.PreconditionErrorsCollectAll,
+			preconditions: []configloader.Precondition{
+				apiPrecondition("clusterStatus"), exprPrecondition("ready", "false"), apiPrecondition("nodePoolStatus"),
+			},
+			expectResults: 2,
+			expectErrors:  []string{"clusterStatus"},
+			expectMessage: "[preconditions] clusterStatus: API call failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := newMockAPIClient()
+			mockClient.GetError = fmt.Errorf("connection refused")
+			mockClient.GetResponse = nil
+			k8sClient := k8sclient.NewMockK8sClient()
+
+			config := &configloader.Config{
+				Adapter:                 configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+				Preconditions:           tt.preconditions,
+				PreconditionErrorPolicy: tt.policy,
+				Resources: []configloader.Resource{{
+					Name: "cm",
+					Manifest: map[string]interface{}{
+						"apiVersion": "v1",
+						"kind":       "ConfigMap",
+						"metadata":   map[string]interface{}{"name": "cm", "namespace": "default"},
+					},
+				}},
+			}
+			exec, err := NewBuilder().
+				WithConfig(config).
+				WithAPIClient(mockClient).
+				WithTransportClient(k8sClient).
+				WithLogger(logger.NewTestLogger()).
+				Build()
+			require.NoError(t, err)
+
+			result := exec.Execute(context.Background(), map[string]interface{}{})
+			assert.Len(t, result.PreconditionResults, tt.expectResults)
+			assert.True(t, result.ResourcesSkipped, "resources are skipped")
+			assert.Empty(t, result.ResourceResults)
+
+			if tt.expectNotMet {
+				assert.Equal(t, StatusSuccess, result.Status)
+				assert.Empty(t, result.PreconditionErrors)
+				return
+			}
+			require.Equal(t, StatusFailed, result.Status)
+			assert.ErrorContains(t, result.Errors[PhasePreconditions], tt.expectMessage)
+			assert.Equal(t, ErrorCodeAPICallFailed, ErrorCodeOf(result.Errors[PhasePreconditions]))
+			require.Len(t, result.PreconditionErrors, len(tt.expectErrors))
+
+			data, err := json.Marshal(result)
+			require.NoError(t, err)
+			var out struct {
+				PreconditionErrors []struct {
+					Step string    `json:"step"`
+					Code ErrorCode `json:"code"`
+				} `json:"precondition_errors"`
+			}
+			require.NoError(t, json.Unmarshal(data, &out))
+			require.Len(t, out.PreconditionErrors, len(tt.expectErrors))
+			for i, step := range tt.expectErrors {
+				assert.Equal(t, step, out.PreconditionErrors[i].Step)
+				assert.Equal(t, ErrorCodeAPICallFailed, out.PreconditionErrors[i].Code)
+			}
+		})
+	}
+}
+
+// TestCreateHandler_RetryAfter verifies that an unmet precondition with retry_after and an
+// API failure carrying a Retry-After delay both make the handler request a delayed redelivery
+func TestCreateHandler_RetryAfter(t *testing.T) {
+	rateLimited := newMockAPIClient()
+	rateLimited.GetError = apierrors.NewAPIError("GET", "/clusters/cluster-1", 429, "429 Too Many Requests",
+		nil, 3, time.Second, fmt.Errorf("rate limited"))
+	rateLimited.GetError.(*apierrors.APIError).RetryAfter = 20 * time.Second
+	rateLimited.GetResponse = nil
+
+	tests := []struct {
+		apiClient    *hyperfleetapi.MockClient
+		name         string
+		precondition configloader.Precondition
+		expectDelay  time.Duration
+		expectCode   ErrorCode
+	}{
+		{
+			name:      "unmet precondition with retry_after",
+			apiClient: newMockAPIClient(),
+			precondition: configloader.Precondition{
+				ActionBase: configloader.ActionBase{Name: "clusterReady"},
+				Expression: "false",
+				RetryAfter: 45 * time.Second,
+			},
+			expectDelay: 45 * time.Second,
+			expectCode:  "",
+		},
+		{
+			name:      "unmet precondition without retry_after",
+			apiClient: newMockAPIClient(),
+			precondition: configloader.Precondition{
+				ActionBase: configloader.ActionBase{Name: "clusterReady"},
+				Expression: "false",
+			},
+		},
+		{
+			name:      "API call rate limited with Retry-After",
+			apiClient: rateLimited,
+			precondition: configloader.Precondition{
+				ActionBase: configloader.ActionBase{
+					Name:    "clusterStatus",
+					APICall: &configloader.APICall{Method: "GET", URL: "/clusters/cluster-1"},
+				},
+			},
+			expectDelay: 20 * time.Second,
+			expectCode:  ErrorCodeAPICallFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &configloader.Config{
+				Adapter:       configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+				Preconditions: []configloader.Precondition{tt.precondition},
+			}
+			exec, err := NewBuilder().
+				WithConfig(config).
+				WithAPIClient(tt.apiClient).
+				WithTransportClient(k8sclient.NewMockK8sClient()).
+				WithLogger(logger.NewTestLogger()).
+				Build()
+			require.NoError(t, err)
+
+			evt := eventtest.NewEvent().WithID("evt-retry").WithDataJSON(map[string]interface{}{}).Build()
+			result := exec.ExecuteEvent(delivery.WithAttempt(context.Background(), 2), evt)
+			assert.Equal(t, tt.expectDelay, result.RetryAfter)
+			assert.Equal(t, 2, result.DeliveryAttempt)
+
+			err = exec.CreateHandler()(context.Background(), evt)
+			if tt.expectDelay == 0 {
+				assert.NoError(t, err, "the event is acknowledged")
+				return
+			}
+			retryErr, ok := apierrors.IsRetryAfterError(err)
+			require.True(t, ok, "expected a RetryAfterError, got %v", err)
+			assert.Equal(t, tt.expectDelay, retryErr.Delay)
+			var cause brokerconsumer.FailureCause
+			require.ErrorAs(t, err, &cause, "the redelivery is counted by phase and error code")
+			assert.Equal(t, string(PhasePreconditions), cause.FailurePhase())
+			assert.Equal(t, string(tt.expectCode), cause.FailureCode())
+		})
+	}
+}
+
+// TestExecute_APITargets verifies that an API call is sent to the target its
+// template selects and that an unknown target fails with the valid options
+func TestExecute_APITargets(t *testing.T) {
+	tests := []struct {
+		name         string
+		tenant       string
+		expectTarget string
+		expectError  string
+	}{
+		{name: "configured target", tenant: "tenant-b", expectTarget: "tenant-b"},
+		{name: "default target", tenant: hyperfleetapi.DefaultTarget, expectTarget: hyperfleetapi.DefaultTarget},
+		{name: "unknown target", tenant: "tenant-c", expectError: `unknown HyperFleet API target "tenant-c"`},
+		{name: "empty target", tenant: "", expectError: `unknown HyperFleet API target ""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			okResponse := &hyperfleetapi.Response{StatusCode: 200, Status: "200 OK", Body: []byte(`{}`)}
+			tenantClient := newMockAPIClient()
+			tenantClient.GetResponse = okResponse
+			apiClient := newMockAPIClient()
+			apiClient.GetResponse = okResponse
+			apiClient.Targets = map[string]*hyperfleetapi.MockClient{"tenant-b": tenantClient}
+			registry := prometheus.NewRegistry()
+			recorder := metrics.NewRecorder("test-adapter", "v0.1.0", registry)
+
+			config := &configloader.Config{
+				Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+				Params:  []configloader.Parameter{{Name: "tenant", Source: "event.tenant"}},
+				Preconditions: []configloader.Precondition{{
+					ActionBase: configloader.ActionBase{
+						Name: "clusterStatus",
+						APICall: &configloader.APICall{
+							Method: "GET",
+							URL:    "/clusters/cluster-1",
+							Target: "{{ .tenant }}",
+						},
+					},
+				}},
+			}
+			exec, err := NewBuilder().
+				WithConfig(config).
+				WithAPIClient(apiClient).
+				WithTransportClient(k8sclient.NewMockK8sClient()).
+				WithLogger(logger.NewTestLogger()).
+				WithMetricsRecorder(recorder).
+				Build()
+			require.NoError(t, err)
+
+			result := exec.Execute(context.Background(), map[string]interface{}{"tenant": tt.tenant})
+			require.Len(t, result.PreconditionResults, 1)
+
+			if tt.expectError != "" {
+				assert.Equal(t, StatusFailed, result.Status)
+				assert.Equal(t, ErrorCodeAPITargetUnknown, ErrorCodeOf(result.Errors[PhasePreconditions]))
+				assert.ErrorContains(t, result.Errors[PhasePreconditions], tt.expectError)
+				assert.ErrorContains(t, result.Errors[PhasePreconditions], "valid targets: default, tenant-b")
+				assert.Empty(t, apiClient.Requests)
+				assert.Empty(t, tenantClient.Requests)
+				return
+			}
+
+			require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+			assert.Equal(t, tt.expectTarget, result.PreconditionResults[0].APITarget)
+			routed, other := apiClient, tenantClient
+			if tt.expectTarget == "tenant-b" {
+				routed, other = tenantClient, apiClient
+			}
+			assert.Len(t, routed.Requests, 1)
+			assert.Empty(t, other.Requests)
+
+			families, err := registry.Gather()
+			require.NoError(t, err)
+			assert.Equal(t, float64(1),
+				getCounterValue(t, families, "hyperfleet_adapter_api_calls_total", "target", tt.expectTarget))
+		})
+	}
+}
+
+// helper functions for metrics assertions
+
+func findFamily(families []*dto.MetricFamily, name string) *dto.MetricFamily {
+	for _, f := range families {
+		if f.GetName() == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func getCounterValue(t *testing.T, families []*dto.MetricFamily, metricName, labelName, labelValue string) float64 {
+	t.Helper()
+	family := findFamily(families, metricName)
+	if family == nil {
+		t.Fatalf("metric %s not found", metricName)
+	}
+	for _, m := range family.GetMetric() {
+		for _, l := range m.GetLabel() {
+			if l.GetName() == labelName && l.GetValue() == labelValue {
+				return m.GetCounter().GetValue()
+			}
+		}
 	}
 	return 0
 }
+
+// TestStepLogFields_APICallFailure verifies that lines logged deep inside a
+// failing API call carry the phase, step and event ID as structured fields
+func TestStepLogFields_APICallFailure(t *testing.T) {
+	mockClient := newMockAPIClient()
+	mockClient.GetError = fmt.Errorf("connection refused")
+	mockClient.PostError = fmt.Errorf("connection reset")
+
+	apiCall := func(method string) *configloader.APICall {
+		return &configloader.APICall{Method: method, URL: "/clusters/{{ .clusterId }}", Body: `{"id":"{{ .clusterId }}"}`}
+	}
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+		Clients: configloader.ClientsConfig{
+			HyperfleetAPI: configloader.HyperfleetAPIConfig{BaseURL: "http://mock-api:8000", Version: "v1"},
+		},
+		Params: []configloader.Parameter{{Name: "clusterId", Source: "event.id", Required: true}},
+		Preconditions: []configloader.Precondition{{
+			ActionBase: configloader.ActionBase{
+				Name:    "clusterStatus",
+				Log:     &configloader.LogAction{Message: "Checking cluster {{ .clusterId }}"},
+				APICall: apiCall("GET"),
+			},
+		}},
+		Post: &configloader.PostConfig{
+			PostActions: []configloader.PostAction{{
+				ActionBase: configloader.ActionBase{Name: "reportStatus", APICall: apiCall("POST")},
+			}},
+		},
+	}
+
+	log, capture := logger.NewCaptureLogger()
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(mockClient).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(log).
+		Build()
+	require.NoError(t, err)
+
+	evt := eventtest.NewEvent().
+		WithID("evt-fields").
+		WithDataJSON(map[string]interface{}{"id": "cluster-123"}).
+		Build()
+	result := exec.ExecuteEvent(context.Background(), evt)
+	require.Equal(t, StatusFailed, result.Status)
+
+	lines := strings.Split(capture.Messages(), "\n")
+	lineWith := func(message string) string {
+		for _, line := range lines {
+			if strings.Contains(line, message) {
+				return line
+			}
+		}
+		t.Fatalf("no log line contains %q:\n%s", message, capture.Messages())
+		return ""
+	}
+
+	for _, tt := range []struct {
+		message string
+		phase   ExecutionPhase
+		step    string
+	}{
+		{message: "[config] Checking cluster cluster-123", phase: PhasePreconditions, step: "clusterStatus"},
+		{message: "Making API call: GET", phase: PhasePreconditions, step: "clusterStatus"},
+		{message: "API call failed: connection refused", phase: PhasePreconditions, step: "clusterStatus"},
+		{message: "Precondition[clusterStatus] evaluated: FAILED", phase: PhasePreconditions, step: "clusterStatus"},
+		{message: `msg="Request failed"`, phase: PhasePostActions, step: "reportStatus"},
+		{message: "PostAction[reportStatus] processed: FAILED", phase: PhasePostActions, step: "reportStatus"},
+	} {
+		line := lineWith(tt.message)
+		assert.Contains(t, line, "phase="+string(tt.phase), tt.message)
+		assert.Contains(t, line, "step="+tt.step, tt.message)
+		assert.Contains(t, line, "event_id=evt-fields", tt.message)
+	}
+}
+
+// TestCreateHandler_ResourcePolicy verifies that a templated kind, which the
+// load-time policy check cannot resolve, is rejected once rendered: nothing is
+// applied, the event is acknowledged and the violation is counted by rule
+func TestCreateHandler_ResourcePolicy(t *testing.T) {
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		Params: []configloader.Parameter{
+			{Name: "clusterId", Source: "event.id", Required: true},
+			{Name: "kind", Source: "event.kind", Required: true},
+		},
+		Resources: []configloader.Resource{{
+			Name: "binding",
+			Manifest: map[string]interface{}{
+				"apiVersion": "rbac.authorization.k8s.io/v1",
+				"kind":       "{{ .kind }}",
+				"metadata":   map[string]interface{}{"name": "{{ .clusterId }}-admin", "namespace": "{{ .clusterId }}"},
+			},
+		}},
+		Policy: &configloader.ResourcePolicy{
+			DeniedKinds:       []string{"ClusterRoleBinding.rbac.authorization.k8s.io"},
+			AllowedNamespaces: []string{"cluster-*"},
+		},
+	}
+	require.NoError(t, configloader.ValidateResourcePolicy(config), "the kind is only known at runtime")
+
+	registry := prometheus.NewRegistry()
+	transport := &targetRecordingClient{MockK8sClient: k8sclient.NewMockK8sClient()}
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(transport).
+		WithLogger(logger.NewTestLogger()).
+		WithMetricsRecorder(metrics.NewRecorder("test-adapter", "v0.1.0", registry)).
+		Build()
+	require.NoError(t, err)
+
+	evt := eventtest.NewEvent().
+		WithID("evt-policy").
+		WithType("com.hyperfleet.test").
+		WithDataJSON(map[string]interface{}{"id": "cluster-1", "kind": "ClusterRoleBinding"}).
+		Build()
+	// The violation is permanent: the handler acknowledges the event
+	require.NoError(t, exec.CreateHandler()(context.Background(), evt))
+	assert.Empty(t, transport.targets, "nothing is applied")
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	assert.Equal(t, float64(1),
+		getCounterValue(t, families, "hyperfleet_adapter_policy_violations_total", "rule", "denied_kinds"))
+	assert.Equal(t, float64(1), getCounterValue(t, families, "hyperfleet_adapter_errors_total", "code", "PolicyViolation"))
+
+	result := exec.ExecuteEvent(context.Background(), evt)
+	require.Equal(t, StatusFailed, result.Status)
+	resourceErr := result.Errors[PhaseResources]
+	assert.Equal(t, ErrorCodePolicyViolation, ErrorCodeOf(resourceErr))
+	assert.ErrorContains(t, resourceErr, `ClusterRoleBinding.rbac.authorization.k8s.io "cluster-1-admin" `+
+		`violates policy rule denied_kinds: kind ClusterRoleBinding.rbac.authorization.k8s.io is denied`)
+
+	// The same manifest rendering an allowed kind is applied
+	evt = eventtest.NewEvent().
+		WithID("evt-allowed").
+		WithType("com.hyperfleet.test").
+		WithDataJSON(map[string]interface{}{"id": "cluster-1", "kind": "RoleBinding"}).
+		Build()
+	result = exec.ExecuteEvent(context.Background(), evt)
+	require.Equal(t, StatusSuccess, result.Status, result.Errors)
+	assert.Len(t, transport.targets, 1)
+}
+
+// TestCreateHandler_RetryBudget verifies that the retry loops of an execution
+// share limits.retry_budget: the precondition retry spends most of it, so the
+// post action retry is denied and the event is redelivered instead
+func TestCreateHandler_RetryBudget(t *testing.T) {
+	var preconditionCalls, postCalls int
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodGet {
+			preconditionCalls++
+			if preconditionCalls == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		postCalls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	fake := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	apiClient, err := hyperfleetapi.NewClient(logger.NewTestLogger(),
+		hyperfleetapi.WithBaseURL(server.URL),
+		hyperfleetapi.WithClock(fake),
+		hyperfleetapi.WithRetryAttempts(3),
+		hyperfleetapi.WithRetryBackoff(hyperfleetapi.BackoffConstant),
+		hyperfleetapi.WithBaseDelay(10*time.Second),
+		hyperfleetapi.WithMaxDelay(10*time.Second),
+	)
+	require.NoError(t, err)
+
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+		Limits:  &configloader.Limits{RetryBudget: 15 * time.Second},
+		Preconditions: []configloader.Precondition{{ActionBase: configloader.ActionBase{
+			Name:    "clusterStatus",
+			APICall: &configloader.APICall{Method: http.MethodGet, URL: "/clusters/cluster-1"},
+		}}},
+		Post: &configloader.PostConfig{
+			PostActions: []configloader.PostAction{{ActionBase: configloader.ActionBase{
+				Name:    "report",
+				APICall: &configloader.APICall{Method: http.MethodPost, URL: "/clusters/cluster-1/statuses"},
+			}}},
+		},
+	}
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(apiClient).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+
+	done := make(chan *ExecutionResult, 1)
+	go func() { done <- exec.Execute(context.Background(), map[string]interface{}{}) }()
+	// The precondition retry waits on the clock; the post action retry is denied
+	// without waiting
+	fake.BlockUntil(1)
+	fake.Advance(10 * time.Second)
+	result := <-done
+
+	assert.Equal(t, 2, preconditionCalls)
+	assert.Equal(t, 1, postCalls, "the post action is not retried")
+	require.Len(t, result.PreconditionResults, 1)
+	assert.Equal(t, StatusSuccess, result.PreconditionResults[0].Status)
+	spent := result.PreconditionResults[0].RetryWait
+	assert.InDelta(t, 9.5, spent.Seconds(), 0.5, "the precondition retry waits 9-10s")
+
+	require.Len(t, result.PostActionResults, 1)
+	assert.Zero(t, result.PostActionResults[0].RetryWait)
+	postErr := result.Errors[PhasePostActions]
+	assert.Equal(t, ErrorCodeRetryBudgetExhausted, ErrorCodeOf(postErr))
+	assert.ErrorContains(t, postErr, "retry budget of 15s exhausted: the retry of post_actions/report needs a")
+	assert.InDelta(t, 9.5, result.RetryAfter.Seconds(), 0.5, "redelivered after the denied wait")
+	assert.Equal(t, &retrybudget.Report{Budget: 15 * time.Second, Spent: spent, Exhausted: true}, result.RetryBudget)
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), fmt.Sprintf(`"retry_wait_ms":%d`, spent.Milliseconds()))
+	assert.Contains(t, string(data), fmt.Sprintf(`"retry_budget":{"budget_ms":15000,"spent_ms":%d,"exhausted":true}`,
+		spent.Milliseconds()))
+}
@@ -0,0 +1,66 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/criteria"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// SkipReasonDisabled is the SkipReason of a post action whose enabled_if is false
+const SkipReasonDisabled = "disabled"
+
+// flagLookup returns the flag() lookup of the flags taken when the execution
+// started. An unknown flag is false, and warned about once per execution.
+func (ec *ExecutionContext) flagLookup(ctx context.Context, log logger.Logger) criteria.FlagLookup {
+	return func(name string) bool {
+		enabled, known := ec.flags.Lookup(name)
+		if known {
+			return enabled
+		}
+		ec.mu.Lock()
+		warned := ec.unknownFlags[name]
+		if !warned {
+			if ec.unknownFlags == nil {
+				ec.unknownFlags = make(map[string]bool)
+			}
+			ec.unknownFlags[name] = true
+		}
+		ec.mu.Unlock()
+		if !warned {
+			log.Warnf(ctx, "Unknown feature flag %q is off", name)
+		}
+		return false
+	}
+}
+
+// enabledIf evaluates the enabled_if expression of a resource or post action
+// against the CEL variables and flags of the execution. An empty expression is
+// enabled; one that does not return a bool is an error.
+func enabledIf(ctx context.Context, log logger.Logger, expression string, execCtx *ExecutionContext) (bool, error) {
+	if strings.TrimSpace(expression) == "" {
+		return true, nil
+	}
+	evalCtx := criteria.NewEvaluationContext()
+	evalCtx.SetVariablesFromMap(execCtx.GetCELVariables())
+	evalCtx.SetFlags(execCtx.flagLookup(ctx, log))
+	evalCtx.SetLookup(execCtx.lookupFunc())
+	evaluator, err := criteria.NewEvaluator(ctx, evalCtx, log)
+	if err != nil {
+		return false, fmt.Errorf("failed to create evaluator: %w", err)
+	}
+	result, err := evaluator.EvaluateCEL(strings.TrimSpace(expression))
+	if err != nil {
+		return false, err
+	}
+	if result.Error != nil {
+		return false, result.Error
+	}
+	enabled, ok := result.Value.(bool)
+	if !ok {
+		return false, fmt.Errorf("enabled_if %q returned %s, not a bool", expression, result.ValueType)
+	}
+	return enabled, nil
+}
@@ -0,0 +1,114 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/featureflags"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/eventtest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func setFlags(client *k8sclient.MockK8sClient, flags map[string]interface{}) {
+	client.Resources["hyperfleet/adapter-flags"] = &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "adapter-flags", "namespace": "hyperfleet"},
+		"data":       flags,
+	}}
+}
+
+// newFlagTestExecutor creates an executor whose cm resource is gated on the
+// "apply" flag and report post action on the "report" flag
+func newFlagTestExecutor(t *testing.T, flagClient *k8sclient.MockK8sClient) (*Executor, *featureflags.Store) {
+	t.Setenv("JOURNAL_TEST_TOKEN", "s3cret")
+	config := journalTestConfig()
+	config.Resources[0].EnabledIf = `flag("apply")`
+	config.Post.PostActions[0].EnabledIf = `flag("report")`
+
+	store, err := featureflags.NewStore(context.Background(), flagClient, featureflags.Config{
+		Namespace: "hyperfleet",
+		Name:      "adapter-flags",
+		Clock:     clock.NewFake(time.Now()),
+	}, logger.NewTestLogger())
+	require.NoError(t, err)
+	t.Cleanup(store.Close)
+
+	apiClient := newMockAPIClient()
+	apiClient.GetResponse = &hyperfleetapi.Response{StatusCode: 200, Status: "200 OK", Body: []byte(`{"generation":3}`)}
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(apiClient).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		WithFeatureFlags(store).
+		Build()
+	require.NoError(t, err)
+	return exec, store
+}
+
+func TestFeatureFlags_FlagFlippedMidExecution(t *testing.T) {
+	flagClient := k8sclient.NewMockK8sClient()
+	setFlags(flagClient, map[string]interface{}{"apply": "true", "report": "true"})
+	exec, store := newFlagTestExecutor(t, flagClient)
+
+	// The flags are turned off while the resources are applied
+	exec.beforePhase = func(phase ExecutionPhase) {
+		if phase == PhaseResources {
+			setFlags(flagClient, map[string]interface{}{"apply": "false", "report": "false"})
+			require.NoError(t, store.Refresh(context.Background()))
+		}
+	}
+	evt := eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build()
+	result := exec.ExecuteEvent(context.Background(), evt)
+
+	require.Equal(t, StatusSuccess, result.Status, "error: %v", result.Errors)
+	assert.Equal(t, featureflags.Snapshot{"apply": true, "report": true}, result.FeatureFlags)
+	require.Len(t, result.ResourceResults, 1, "the in-flight execution applies its resource")
+	require.Len(t, result.PostActionResults, 1)
+	assert.False(t, result.PostActionResults[0].Skipped, "the in-flight execution runs its post action")
+
+	// The next execution sees the flipped flags
+	exec.beforePhase = nil
+	result = exec.ExecuteEvent(context.Background(), evt)
+
+	require.Equal(t, StatusSuccess, result.Status, "error: %v", result.Errors)
+	assert.Empty(t, result.ResourceResults)
+	require.Len(t, result.PostActionResults, 1)
+	assert.True(t, result.PostActionResults[0].Skipped)
+	assert.Equal(t, SkipReasonDisabled, result.PostActionResults[0].SkipReason)
+	assert.Equal(t, 1, result.ExecutionContext.ResourceSummary().SkippedCount,
+		"a disabled resource is counted as skipped")
+}
+
+func TestFeatureFlags_UnknownFlagIsOff(t *testing.T) {
+	flagClient := k8sclient.NewMockK8sClient()
+	setFlags(flagClient, map[string]interface{}{"report": "true"})
+	exec, _ := newFlagTestExecutor(t, flagClient)
+
+	evt := eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build()
+	result := exec.ExecuteEvent(context.Background(), evt)
+
+	require.Equal(t, StatusSuccess, result.Status, "error: %v", result.Errors)
+	assert.Empty(t, result.ResourceResults, "the resource gated on an unknown flag is disabled")
+	require.Len(t, result.PostActionResults, 1)
+	assert.False(t, result.PostActionResults[0].Skipped)
+}
+
+func TestFeatureFlags_InvalidEnabledIf(t *testing.T) {
+	exec, _ := newFlagTestExecutor(t, k8sclient.NewMockK8sClient())
+	exec.config.Config.Resources[0].EnabledIf = `"yes"`
+
+	evt := eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build()
+	result := exec.ExecuteEvent(context.Background(), evt)
+
+	require.Equal(t, StatusFailed, result.Status)
+	assert.Equal(t, ErrorCodeCELEvaluationError, ErrorCodeOf(result.Errors[PhaseResources]))
+}
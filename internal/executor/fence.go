@@ -0,0 +1,120 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+)
+
+// execution_fence defaults
+const (
+	DefaultExecutionFenceTimeout = 30 * time.Second
+	DefaultExecutionFenceMaxKeys = 10000
+)
+
+// errFenceTimeout is returned by executionFence.acquire when the key stays held for the whole timeout
+var errFenceTimeout = errors.New("timed out waiting for the execution holding the key")
+
+// errFenceFull is returned by executionFence.acquire when it already tracks its maximum of keys
+var errFenceFull = errors.New("too many keys in use")
+
+// fenceEntry is the slot of a key and the number of executions holding or waiting for it
+type fenceEntry struct {
+	slot chan struct{}
+	refs int
+}
+
+// executionFence lets at most one execution per key run at a time. An entry
+// is evicted as soon as no execution holds or waits for its key, so the map
+// only tracks the keys in use, and at most maxKeys of them.
+type executionFence struct {
+	entries map[string]*fenceEntry
+	clock   clock.Clock
+	maxKeys int
+	mu      sync.Mutex
+}
+
+func newExecutionFence(maxKeys int, clk clock.Clock) *executionFence {
+	if maxKeys <= 0 {
+		maxKeys = DefaultExecutionFenceMaxKeys
+	}
+	return &executionFence{
+		entries: make(map[string]*fenceEntry),
+		clock:   clock.OrReal(clk),
+		maxKeys: maxKeys,
+	}
+}
+
+// acquire waits up to timeout for key to be free and holds it. The returned
+// release frees the key and must be called once the execution is done.
+func (f *executionFence) acquire(ctx context.Context, key string, timeout time.Duration) (func(), error) {
+	entry, err := f.ref(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Uncontended keys are taken without starting a timer
+	select {
+	case entry.slot <- struct{}{}:
+		return func() { f.release(key, entry) }, nil
+	default:
+	}
+
+	timer := f.clock.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case entry.slot <- struct{}{}:
+		return func() { f.release(key, entry) }, nil
+	case <-timer.C():
+		f.unref(key, entry)
+		return nil, errFenceTimeout
+	case <-ctx.Done():
+		f.unref(key, entry)
+		return nil, ctx.Err()
+	}
+}
+
+// ref returns the entry of key, adding it if no execution uses the key
+func (f *executionFence) ref(key string) (*fenceEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entry, ok := f.entries[key]
+	if !ok {
+		if len(f.entries) >= f.maxKeys {
+			return nil, fmt.Errorf("%w: %d", errFenceFull, f.maxKeys)
+		}
+		entry = &fenceEntry{slot: make(chan struct{}, 1)}
+		f.entries[key] = entry
+	}
+	entry.refs++
+	return entry, nil
+}
+
+// unref drops a reference to the entry of key, evicting it when it was the last
+func (f *executionFence) unref(key string, entry *fenceEntry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entry.refs--
+	if entry.refs == 0 {
+		delete(f.entries, key)
+	}
+}
+
+// release frees the key held by an execution
+func (f *executionFence) release(key string, entry *fenceEntry) {
+	<-entry.slot
+	f.unref(key, entry)
+}
+
+// len returns the number of keys held or waited for
+func (f *executionFence) len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.entries)
+}
@@ -0,0 +1,203 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowTransport is a transport whose applies wait for the test to let them proceed
+type slowTransport struct {
+	*k8sclient.MockK8sClient
+	// entered receives a value as every apply starts
+	entered chan struct{}
+	proceed chan struct{}
+	mu      sync.Mutex
+}
+
+func newSlowTransport() *slowTransport {
+	return &slowTransport{
+		MockK8sClient: k8sclient.NewMockK8sClient(),
+		entered:       make(chan struct{}),
+		proceed:       make(chan struct{}),
+	}
+}
+
+func (s *slowTransport) ApplyResource(
+	ctx context.Context,
+	manifestBytes []byte,
+	opts *transportclient.ApplyOptions,
+	target transportclient.TransportContext,
+) (*transportclient.ApplyResult, error) {
+	s.entered <- struct{}{}
+	<-s.proceed
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.MockK8sClient.ApplyResource(ctx, manifestBytes, opts, target)
+}
+
+func fenceTestConfig(timeout time.Duration) *configloader.Config {
+	return &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		Params:  []configloader.Parameter{{Name: "clusterId", Source: "event.id", Required: true}},
+		Resources: []configloader.Resource{{
+			Name: "cm",
+			Manifest: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "cm-{{ .clusterId }}", "namespace": "default"},
+			},
+		}},
+		ExecutionFence: &configloader.ExecutionFence{Key: "{{ .clusterId }}", Timeout: timeout},
+	}
+}
+
+func TestExecutionFence_SerializesExecutionsWithTheSameKey(t *testing.T) {
+	transport := newSlowTransport()
+	registry := prometheus.NewRegistry()
+	exec, err := NewBuilder().
+		WithConfig(fenceTestConfig(0)).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(transport).
+		WithLogger(logger.NewTestLogger()).
+		WithMetricsRecorder(metrics.NewRecorder("test-adapter", "v0.1.0", registry)).
+		Build()
+	require.NoError(t, err)
+
+	results := make([]*ExecutionResult, 3)
+	var wg sync.WaitGroup
+	run := func(i int, clusterID string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = exec.Execute(context.Background(), map[string]interface{}{"id": clusterID})
+		}()
+	}
+
+	run(0, "cluster-1")
+	<-transport.entered
+	run(1, "cluster-2")
+	<-transport.entered
+	// cluster-1 is held by the first execution, which is still applying
+	run(2, "cluster-1")
+	require.Eventually(t, func() bool { return exec.Stats().InFlight == 3 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, int64(3), exec.InFlight(), "InFlight and Stats read one counter")
+	select {
+	case <-transport.entered:
+		t.Fatal("the second cluster-1 execution ran alongside the first")
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.Equal(t, 2, exec.Stats().CacheSizes[CacheExecutionFence])
+
+	transport.proceed <- struct{}{}
+	transport.proceed <- struct{}{}
+	<-transport.entered
+	transport.proceed <- struct{}{}
+	wg.Wait()
+
+	for i, clusterID := range []string{"cluster-1", "cluster-2", "cluster-1"} {
+		require.Equal(t, StatusSuccess, results[i].Status, "execution %d: %v", i, results[i].Errors)
+		assert.Equal(t, clusterID, results[i].ExecutionKey)
+	}
+	assert.Zero(t, exec.Stats().CacheSizes[CacheExecutionFence], "idle keys are evicted")
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var waits *dto.Histogram
+	for _, family := range families {
+		if family.GetName() == "hyperfleet_adapter_execution_fence_wait_seconds" {
+			waits = family.GetMetric()[0].GetHistogram()
+		}
+	}
+	require.NotNil(t, waits)
+	assert.Equal(t, uint64(3), waits.GetSampleCount())
+	assert.GreaterOrEqual(t, waits.GetSampleSum(), 0.1, "the second cluster-1 execution waited for the first")
+}
+
+func TestExecutionFence_Timeout(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	transport := newSlowTransport()
+	exec, err := NewBuilder().
+		WithConfig(fenceTestConfig(time.Minute)).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(transport).
+		WithLogger(logger.NewTestLogger()).
+		WithClock(fake).
+		Build()
+	require.NoError(t, err)
+
+	holder := make(chan *ExecutionResult)
+	go func() { holder <- exec.Execute(context.Background(), map[string]interface{}{"id": "cluster-1"}) }()
+	<-transport.entered
+
+	waiter := make(chan *ExecutionResult)
+	go func() { waiter <- exec.Execute(context.Background(), map[string]interface{}{"id": "cluster-1"}) }()
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+
+	result := <-waiter
+	assert.Equal(t, StatusFailed, result.Status)
+	assert.Equal(t, PhaseExecutionFence, result.CurrentPhase)
+	assert.Equal(t, ErrorCodeExecutionFenceTimeout, ErrorCodeOf(result.Errors[PhaseExecutionFence]))
+	assert.Equal(t, time.Minute, result.RetryAfter, "the execution asks for redelivery")
+	assert.Equal(t, "cluster-1", result.ExecutionKey)
+	assert.Empty(t, result.ResourceResults)
+
+	transport.proceed <- struct{}{}
+	assert.Equal(t, StatusSuccess, (<-holder).Status)
+}
+
+func TestExecutionFence_KeyRenderFailure(t *testing.T) {
+	config := fenceTestConfig(0)
+	config.ExecutionFence.Key = "{{ .missing }}"
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+
+	result := exec.Execute(context.Background(), map[string]interface{}{"id": "cluster-1"})
+	assert.Equal(t, StatusFailed, result.Status)
+	assert.Equal(t, ErrorCodeTemplateError, ErrorCodeOf(result.Errors[PhaseExecutionFence]))
+	assert.Zero(t, result.RetryAfter, "a key that cannot render never will")
+}
+
+func TestExecutionFenceAcquire(t *testing.T) {
+	fence := newExecutionFence(2, nil)
+
+	release, err := fence.acquire(context.Background(), "a", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 1, fence.len())
+	release()
+	assert.Zero(t, fence.len(), "released keys are evicted")
+
+	releaseA, err := fence.acquire(context.Background(), "a", time.Second)
+	require.NoError(t, err)
+	releaseB, err := fence.acquire(context.Background(), "b", time.Second)
+	require.NoError(t, err)
+	_, err = fence.acquire(context.Background(), "c", time.Second)
+	assert.ErrorIs(t, err, errFenceFull)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = fence.acquire(ctx, "a", time.Minute)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	releaseA()
+	releaseB()
+	assert.Zero(t, fence.len(), "waiters that gave up hold no reference")
+}
@@ -0,0 +1,154 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/manifest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/retrybudget"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Operations of the resource results of the finalizer
+const (
+	OperationAddFinalizer    manifest.Operation = "addFinalizer"
+	OperationRemoveFinalizer manifest.Operation = "removeFinalizer"
+)
+
+// finalizerPatcher is implemented by transport clients that read and patch
+// objects in place: the kubernetes transport client
+type finalizerPatcher interface {
+	resourcePatcher
+	GetResource(
+		ctx context.Context,
+		gvk schema.GroupVersionKind,
+		namespace, name string,
+		target transportclient.TransportContext,
+	) (*unstructured.Unstructured, error)
+}
+
+// finalizer returns the spec.finalizer of the adapter, nil without one
+func (re *ResourceExecutor) finalizer() *configloader.Finalizer {
+	if re.config.Config == nil {
+		return nil
+	}
+	return re.config.Config.Finalizer
+}
+
+// executeFinalizer adds the finalizer to the object it is on, or removes it from
+// the object for the teardown workflow. The result, named after the finalizer,
+// records the operation. An object that is not found is skipped, and so is
+// adding the finalizer to an object being deleted, which Kubernetes forbids.
+// The patch carries the resourceVersion the finalizers were read at, so a
+// concurrent update fails it with a conflict and it is retried on a fresh read.
+func (re *ResourceExecutor) executeFinalizer(
+	ctx context.Context,
+	finalizer *configloader.Finalizer,
+	remove bool,
+	execCtx *ExecutionContext,
+) (ResourceResult, error) {
+	log := stepLogger(re.log, PhaseResources, finalizer.Name)
+	result := ResourceResult{
+		StartedAt:  execCtx.now(),
+		Name:       finalizer.Name,
+		Kind:       finalizer.On.Kind,
+		APIVersion: finalizer.On.APIVersion,
+		Status:     StatusSuccess,
+		Operation:  OperationAddFinalizer,
+	}
+	if remove {
+		result.Operation = OperationRemoveFinalizer
+	}
+	failed := func(code ErrorCode, reason string, err error) (ResourceResult, error) {
+		result.Status = StatusFailed
+		result.Error = err
+		result.Duration = execCtx.now().Sub(result.StartedAt)
+		return result, NewExecutorError(PhaseResources, code, finalizer.Name, reason, err)
+	}
+
+	patcher, ok := re.client.(finalizerPatcher)
+	if !ok {
+		return failed(ErrorCodeTransportNotConfigured, "finalizer requires the kubernetes transport client",
+			fmt.Errorf("transport client does not patch objects"))
+	}
+	gvk, err := k8sclient.GVKFromKindAndAPIVersion(finalizer.On.Kind, finalizer.On.APIVersion)
+	if err != nil {
+		return failed(ErrorCodeManifestInvalid, "invalid finalizer api_version", err)
+	}
+	params := execCtx.templateParams()
+	if result.Namespace, err = renderTemplateAt("finalizer.on.namespace", finalizer.On.Namespace, params); err != nil {
+		return failed(ErrorCodeTemplateError, "failed to render finalizer namespace", err)
+	}
+	if result.ResourceName, err = renderTemplateAt("finalizer.on.name", finalizer.On.Name, params); err != nil {
+		return failed(ErrorCodeTemplateError, "failed to render finalizer name", err)
+	}
+
+	err = retrybudget.RetryOnConflict(ctx, func() error {
+		obj, err := patcher.GetResource(ctx, gvk, result.Namespace, result.ResourceName, nil)
+		if err != nil {
+			return err
+		}
+		finalizers := obj.GetFinalizers()
+		has := slices.Contains(finalizers, finalizer.Name)
+		switch {
+		case has != remove:
+			result.Operation = manifest.OperationSkip
+			result.OperationReason = "finalizer already present"
+			if remove {
+				result.OperationReason = "finalizer already removed"
+			}
+			return nil
+		case !remove && obj.GetDeletionTimestamp() != nil:
+			result.Operation = manifest.OperationSkip
+			result.OperationReason = "object is being deleted"
+			return nil
+		case remove:
+			finalizers = slices.DeleteFunc(finalizers, func(f string) bool { return f == finalizer.Name })
+			result.OperationReason = "teardown workflow applied its resources"
+		default:
+			finalizers = append(finalizers, finalizer.Name)
+			result.OperationReason = "finalizer missing"
+		}
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"resourceVersion": obj.GetResourceVersion(),
+				"finalizers":      append([]string{}, finalizers...),
+			},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = patcher.PatchResource(ctx, gvk, result.Namespace, result.ResourceName, patch, nil)
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		log.Warnf(ctx, "Finalizer %s: %s %s/%s not found, skipped",
+			finalizer.Name, gvk.Kind, result.Namespace, result.ResourceName)
+		result.Operation = manifest.OperationSkip
+		result.OperationReason = "object not found"
+		err = nil
+	}
+	if err != nil {
+		return failed(patchErrorCode(err), fmt.Sprintf("failed to %s finalizer on %s %s/%s",
+			finalizerVerb(remove), gvk.Kind, result.Namespace, result.ResourceName), err)
+	}
+	result.Duration = execCtx.now().Sub(result.StartedAt)
+	log.Infof(ctx, "Finalizer %s on %s %s/%s: %s (%s)", finalizer.Name, gvk.Kind,
+		result.Namespace, result.ResourceName, result.Operation, result.OperationReason)
+	return result, nil
+}
+
+// finalizerVerb returns the verb of a finalizer operation in error messages
+func finalizerVerb(remove bool) string {
+	if remove {
+		return "remove"
+	}
+	return "add"
+}
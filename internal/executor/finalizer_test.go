@@ -0,0 +1,248 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/manifest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/eventtest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const testFinalizer = "hyperfleet.io/adapter-cleanup"
+
+// finalizerConfig is a config keeping testFinalizer on the cluster CR of the
+// event, with a teardown workflow for cluster.deleted events
+func finalizerConfig() *configloader.Config {
+	configMap := func(name string) []configloader.Resource {
+		return []configloader.Resource{{
+			Name: name,
+			Manifest: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": name, "namespace": "clusters"},
+			},
+		}}
+	}
+	return &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		Params: []configloader.Parameter{
+			{Name: "clusterId", Source: "event.id", Required: true},
+		},
+		Finalizer: &configloader.Finalizer{
+			Name: testFinalizer,
+			On: configloader.ObjectRef{
+				APIVersion: "hyperfleet.io/v1", Kind: "Cluster", Namespace: "clusters", Name: "{{ .clusterId }}",
+			},
+			TeardownWorkflow: "teardown",
+		},
+		Workflows: []configloader.Workflow{
+			{
+				Name:      "teardown",
+				Match:     &configloader.WorkflowMatch{EventTypes: []string{"cluster.deleted"}},
+				Resources: configMap("teardown"),
+			},
+			{Name: "reconcile", Resources: configMap("reconcile")},
+		},
+	}
+}
+
+// clusterCR is the cluster CR of cluster-1 with finalizers
+func clusterCR(finalizers ...string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{Group: "hyperfleet.io", Version: "v1", Kind: "Cluster"})
+	obj.SetNamespace("clusters")
+	obj.SetName("cluster-1")
+	obj.SetResourceVersion("42")
+	obj.SetFinalizers(finalizers)
+	return obj
+}
+
+// patchedFinalizers returns the metadata of a recorded finalizer patch
+func patchedFinalizers(t *testing.T, patch k8sclient.MockPatch) (string, []string) {
+	t.Helper()
+	var body struct {
+		Metadata struct {
+			ResourceVersion string   `json:"resourceVersion"`
+			Finalizers      []string `json:"finalizers"`
+		} `json:"metadata"`
+	}
+	require.NoError(t, json.Unmarshal(patch.Data, &body))
+	return body.Metadata.ResourceVersion, body.Metadata.Finalizers
+}
+
+func TestFinalizer(t *testing.T) {
+	deletionTime := metav1.Now()
+	tests := []struct {
+		cr              *unstructured.Unstructured
+		name            string
+		eventType       string
+		wantOperation   manifest.Operation
+		wantReason      string
+		wantFinalizers  []string
+		wantResultIndex int
+	}{
+		{
+			name:           "added before the resources",
+			eventType:      "cluster.created",
+			cr:             clusterCR("other.io/keep"),
+			wantOperation:  OperationAddFinalizer,
+			wantReason:     "finalizer missing",
+			wantFinalizers: []string{"other.io/keep", testFinalizer},
+		},
+		{
+			name:          "already present",
+			eventType:     "cluster.updated",
+			cr:            clusterCR(testFinalizer),
+			wantOperation: manifest.OperationSkip,
+			wantReason:    "finalizer already present",
+		},
+		{
+			name:      "not added to an object being deleted",
+			eventType: "cluster.updated",
+			cr: func() *unstructured.Unstructured {
+				cr := clusterCR("other.io/keep")
+				cr.SetDeletionTimestamp(&deletionTime)
+				return cr
+			}(),
+			wantOperation: manifest.OperationSkip,
+			wantReason:    "object is being deleted",
+		},
+		{
+			name:          "object not found",
+			eventType:     "cluster.created",
+			wantOperation: manifest.OperationSkip,
+			wantReason:    "object not found",
+		},
+		{
+			name:            "removed after the teardown resources",
+			eventType:       "cluster.deleted",
+			cr:              clusterCR(testFinalizer, "other.io/keep"),
+			wantOperation:   OperationRemoveFinalizer,
+			wantReason:      "teardown workflow applied its resources",
+			wantFinalizers:  []string{"other.io/keep"},
+			wantResultIndex: 1,
+		},
+		{
+			name:            "already removed",
+			eventType:       "cluster.deleted",
+			cr:              clusterCR(),
+			wantOperation:   manifest.OperationSkip,
+			wantReason:      "finalizer already removed",
+			wantResultIndex: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8s := k8sclient.NewMockK8sClient()
+			if tt.cr != nil {
+				k8s.Resources["clusters/cluster-1"] = tt.cr
+			}
+			exec, err := NewBuilder().
+				WithConfig(finalizerConfig()).
+				WithAPIClient(newMockAPIClient()).
+				WithTransportClient(k8s).
+				WithLogger(logger.NewTestLogger()).
+				Build()
+			require.NoError(t, err)
+
+			result := exec.ExecuteEvent(context.Background(),
+				eventtest.NewEvent().WithType(tt.eventType).WithDataJSON(`{"id":"cluster-1"}`).Build())
+			require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+			require.Len(t, result.ResourceResults, 2)
+
+			finalizerResult := result.ResourceResults[tt.wantResultIndex]
+			assert.Equal(t, testFinalizer, finalizerResult.Name)
+			assert.Equal(t, "Cluster", finalizerResult.Kind)
+			assert.Equal(t, "clusters", finalizerResult.Namespace)
+			assert.Equal(t, "cluster-1", finalizerResult.ResourceName)
+			assert.Equal(t, tt.wantOperation, finalizerResult.Operation)
+			assert.Equal(t, tt.wantReason, finalizerResult.OperationReason)
+
+			if tt.wantFinalizers == nil {
+				assert.Empty(t, k8s.Patches)
+				return
+			}
+			require.Len(t, k8s.Patches, 1)
+			resourceVersion, finalizers := patchedFinalizers(t, k8s.Patches[0])
+			assert.Equal(t, "42", resourceVersion, "the patch is conditional on the version read")
+			assert.Equal(t, tt.wantFinalizers, finalizers)
+		})
+	}
+}
+
+func TestFinalizer_Failures(t *testing.T) {
+	gr := schema.GroupResource{Group: "hyperfleet.io", Resource: "clusters"}
+	tests := []struct {
+		setup       func(k8s *k8sclient.MockK8sClient)
+		name        string
+		eventType   string
+		wantCode    ErrorCode
+		wantResults int
+		wantPatches int
+	}{
+		{
+			name:      "conflicts are retried on a fresh read",
+			eventType: "cluster.created",
+			setup: func(k8s *k8sclient.MockK8sClient) {
+				k8s.PatchResourceError = apierrors.NewConflict(gr, "cluster-1", errors.New("object was modified"))
+			},
+			wantCode:    ErrorCodeApplyConflict,
+			wantResults: 1,
+			wantPatches: 5,
+		},
+		{
+			name:      "resources are not applied without the finalizer",
+			eventType: "cluster.created",
+			setup: func(k8s *k8sclient.MockK8sClient) {
+				k8s.PatchResourceError = apierrors.NewForbidden(gr, "cluster-1", errors.New("denied"))
+			},
+			wantCode:    ErrorCodePatchFailed,
+			wantResults: 1,
+			wantPatches: 1,
+		},
+		{
+			name:      "not removed when the teardown fails",
+			eventType: "cluster.deleted",
+			setup: func(k8s *k8sclient.MockK8sClient) {
+				k8s.ApplyResourceError = errors.New("apply failed")
+			},
+			wantCode:    ErrorCodeApplyFailed,
+			wantResults: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8s := k8sclient.NewMockK8sClient()
+			k8s.Resources["clusters/cluster-1"] = clusterCR(testFinalizer)
+			if tt.eventType != "cluster.deleted" {
+				k8s.Resources["clusters/cluster-1"] = clusterCR()
+			}
+			tt.setup(k8s)
+			exec, err := NewBuilder().
+				WithConfig(finalizerConfig()).
+				WithAPIClient(newMockAPIClient()).
+				WithTransportClient(k8s).
+				WithLogger(logger.NewTestLogger()).
+				Build()
+			require.NoError(t, err)
+
+			result := exec.ExecuteEvent(context.Background(),
+				eventtest.NewEvent().WithType(tt.eventType).WithDataJSON(`{"id":"cluster-1"}`).Build())
+			require.Equal(t, StatusFailed, result.Status)
+			assert.Equal(t, tt.wantCode, ErrorCodeOf(result.Errors[PhaseResources]))
+			assert.Len(t, result.ResourceResults, tt.wantResults)
+			assert.Len(t, k8s.Patches, tt.wantPatches)
+		})
+	}
+}
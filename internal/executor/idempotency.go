@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// SkipReasonStaleGeneration is the SkipReason of an execution whose resources
+// were skipped because its generation is not newer than the last one processed
+// for its idempotency key
+const SkipReasonStaleGeneration = "StaleGeneration"
+
+// generationGuard is the outcome of the idempotency check of an execution
+type generationGuard struct {
+	// key is the rendered idempotency key, empty without a key or generation
+	key string
+	// message explains a stale outcome
+	message string
+	// generation is the generation of the event
+	generation int64
+	// stale is set when the resources are skipped
+	stale bool
+}
+
+// checkGeneration compares the generation of the execution with the last one
+// processed for its key. An event without a key or generation is stale under
+// the skip on_missing policy, an event as old as the processed generation under
+// the skip on_tie policy.
+func (e *Executor) checkGeneration(
+	ctx context.Context, idempotency *configloader.Idempotency, execCtx *ExecutionContext,
+) (generationGuard, error) {
+	keyValue, hasKey := execCtx.GetParam(idempotency.KeyParam)
+	generationValue, hasGeneration := execCtx.GetParam(idempotency.GenerationParam)
+	key := ""
+	if hasKey && keyValue != nil {
+		key = fmt.Sprint(keyValue)
+	}
+	if key == "" || !hasGeneration || generationValue == nil || generationValue == "" {
+		if idempotency.EffectiveOnMissing() == configloader.IdempotencySkip {
+			return generationGuard{stale: true, message: fmt.Sprintf(
+				"missing %s or %s", idempotency.KeyParam, idempotency.GenerationParam)}, nil
+		}
+		e.log.Debugf(ctx, "Idempotency check skipped: missing %s or %s",
+			idempotency.KeyParam, idempotency.GenerationParam)
+		return generationGuard{}, nil
+	}
+	generation, err := convertToInt64(generationValue)
+	if err != nil {
+		return generationGuard{}, NewExecutorError(PhaseResources, ErrorCodeGenerationInvalid,
+			idempotency.GenerationParam, fmt.Sprintf("generation %v is not an integer", generationValue), err)
+	}
+
+	guard := generationGuard{key: key, generation: generation}
+	processed, found, err := e.idempotency.Get(ctx, key)
+	if err != nil {
+		return generationGuard{}, NewExecutorError(PhaseResources, ErrorCodeIdempotencyCheckFailed,
+			"idempotency", "failed to read the processed generation", err)
+	}
+	switch {
+	case !found || generation > processed:
+		return guard, nil
+	case generation == processed && idempotency.EffectiveOnTie() == configloader.IdempotencyProcess:
+		return guard, nil
+	}
+	guard.stale = true
+	guard.message = fmt.Sprintf("generation %d of %q is not newer than the processed generation %d",
+		generation, key, processed)
+	return guard, nil
+}
+
+// recordGeneration records the generation of a fully successful execution as
+// the processed generation of its key. A failure is only logged: the execution
+// is done, and the next event for the key is compared with an older generation.
+func (e *Executor) recordGeneration(ctx context.Context, guard generationGuard) {
+	if guard.key == "" || guard.stale {
+		return
+	}
+	if err := e.idempotency.Record(ctx, guard.key, guard.generation); err != nil {
+		e.log.Warnf(logger.WithErrorField(ctx, err), "Failed to record generation %d of %q",
+			guard.generation, guard.key)
+	}
+}
+
+// skipStaleGeneration skips the resources of an execution with a stale generation
+func skipStaleGeneration(result *ExecutionResult, execCtx *ExecutionContext, guard generationGuard) {
+	result.ResourcesSkipped = true
+	result.SkipReason = SkipReasonStaleGeneration
+	execCtx.SetSkipped(SkipReasonStaleGeneration, guard.message)
+}
@@ -0,0 +1,180 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/idempotency"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// idempotencyTestConfig applies a ConfigMap per cluster and reports a status,
+// guarded by the generation of the event
+func idempotencyTestConfig(guard configloader.Idempotency) *configloader.Config {
+	guard.GenerationParam, guard.KeyParam = "generation", "clusterId"
+	return &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+		Params: []configloader.Parameter{
+			{Name: "clusterId", Source: "event.id"},
+			{Name: "generation", Source: "event.generation"},
+		},
+		Resources: []configloader.Resource{{
+			Name: "cm",
+			Manifest: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "cm-{{ .clusterId }}", "namespace": "default"},
+			},
+			Discovery: &configloader.DiscoveryConfig{Namespace: "default", ByName: "cm-{{ .clusterId }}"},
+		}},
+		Post: &configloader.PostConfig{
+			PostActions: []configloader.PostAction{{ActionBase: configloader.ActionBase{
+				Name:    "report",
+				APICall: &configloader.APICall{Method: "POST", URL: "/clusters/{{ .clusterId }}/statuses", Body: "{}"},
+			}}},
+		},
+		Idempotency: &guard,
+	}
+}
+
+func newIdempotencyTestExecutor(
+	t *testing.T, config *configloader.Config, apiClient hyperfleetapi.Client, tc *k8sclient.MockK8sClient,
+	store idempotency.Store,
+) *Executor {
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(apiClient).
+		WithTransportClient(tc).
+		WithLogger(logger.NewTestLogger()).
+		WithIdempotencyStore(store).
+		Build()
+	require.NoError(t, err)
+	return exec
+}
+
+func executeGeneration(exec *Executor, generation interface{}) *ExecutionResult {
+	data := map[string]interface{}{"id": "cluster-1"}
+	if generation != nil {
+		data["generation"] = generation
+	}
+	return exec.Execute(context.Background(), data)
+}
+
+func TestIdempotency_OutOfOrderGenerations(t *testing.T) {
+	apiClient := newMockAPIClient()
+	exec := newIdempotencyTestExecutor(t, idempotencyTestConfig(configloader.Idempotency{}),
+		apiClient, k8sclient.NewMockK8sClient(), nil)
+
+	// Generation 3 arrives first; the redeliveries of 1 and 2 arrive after it
+	for _, step := range []struct {
+		generation int
+		wantStale  bool
+	}{{3, false}, {1, true}, {2, true}, {3, true}, {4, false}} {
+		result := executeGeneration(exec, step.generation)
+		require.Equal(t, StatusSuccess, result.Status, "generation %d: %v", step.generation, result.Errors)
+		assert.Equal(t, step.wantStale, result.ResourcesSkipped, "generation %d", step.generation)
+		if step.wantStale {
+			assert.Equal(t, SkipReasonStaleGeneration, result.SkipReason)
+			assert.Empty(t, result.ResourceResults)
+			assert.Contains(t, result.ExecutionContext.Adapter.SkipReason, "is not newer than the processed generation")
+		}
+		require.Len(t, result.PostActionResults, 1, "the post actions of stale generations run by default")
+	}
+	assert.Len(t, apiClient.Requests, 5)
+}
+
+func TestIdempotency_Policies(t *testing.T) {
+	tests := []struct {
+		name           string
+		guard          configloader.Idempotency
+		first, second  interface{}
+		wantStale      bool
+		wantPostAction bool
+	}{
+		{name: "tie skipped by default", first: 2, second: 2, wantStale: true, wantPostAction: true},
+		{name: "tie processed", guard: configloader.Idempotency{OnTie: configloader.IdempotencyProcess},
+			first: 2, second: 2, wantPostAction: true},
+		{name: "missing generation processed by default", first: 2, second: nil, wantPostAction: true},
+		{name: "missing generation skipped", guard: configloader.Idempotency{OnMissing: configloader.IdempotencySkip},
+			first: 2, second: nil, wantStale: true, wantPostAction: true},
+		{name: "post actions of stale generations skipped",
+			guard: configloader.Idempotency{PostActions: configloader.IdempotencyPostSkip},
+			first: 2, second: 1, wantStale: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := newIdempotencyTestExecutor(t, idempotencyTestConfig(tt.guard),
+				newMockAPIClient(), k8sclient.NewMockK8sClient(), nil)
+			require.Equal(t, StatusSuccess, executeGeneration(exec, tt.first).Status)
+
+			result := executeGeneration(exec, tt.second)
+			require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+			assert.Equal(t, tt.wantStale, result.ResourcesSkipped)
+			assert.Equal(t, tt.wantPostAction, len(result.PostActionResults) == 1)
+		})
+	}
+}
+
+func TestIdempotency_RecordsOnlyFullySuccessfulExecutions(t *testing.T) {
+	tc := k8sclient.NewMockK8sClient()
+	apiClient := newMockAPIClient()
+	exec := newIdempotencyTestExecutor(t, idempotencyTestConfig(configloader.Idempotency{}), apiClient, tc, nil)
+
+	tc.ApplyResourceError = errors.New("apply failed")
+	require.Equal(t, StatusFailed, executeGeneration(exec, 5).Status)
+	tc.ApplyResourceError = nil
+	apiClient.PostError = errors.New("report failed")
+	require.Equal(t, StatusFailed, executeGeneration(exec, 5).Status)
+	apiClient.PostError = nil
+
+	result := executeGeneration(exec, 4)
+	require.Equal(t, StatusSuccess, result.Status)
+	assert.False(t, result.ResourcesSkipped, "failed executions do not record their generation")
+}
+
+func TestIdempotency_GenerationCapturedAsString(t *testing.T) {
+	config := idempotencyTestConfig(configloader.Idempotency{})
+	config.Params[1].Source = "event.labels.generation"
+	exec := newIdempotencyTestExecutor(t, config, newMockAPIClient(), k8sclient.NewMockK8sClient(), nil)
+	execute := func(generation string) *ExecutionResult {
+		return exec.Execute(context.Background(), map[string]interface{}{
+			"id": "cluster-1", "labels": map[string]interface{}{"generation": generation},
+		})
+	}
+
+	require.False(t, execute("10").ResourcesSkipped)
+	assert.Equal(t, SkipReasonStaleGeneration, execute("9").SkipReason, "compared as integers")
+
+	result := execute("three")
+	require.Equal(t, StatusFailed, result.Status)
+	assert.True(t, result.ResourcesSkipped)
+	assert.Equal(t, ErrorCodeGenerationInvalid, ErrorCodeOf(result.Errors[PhaseResources]))
+	require.Len(t, result.PostActionResults, 1, "the post actions report the failure")
+}
+
+func TestIdempotency_ConfigMapStoreSurvivesRestart(t *testing.T) {
+	tc := k8sclient.NewMockK8sClient()
+	config := idempotencyTestConfig(configloader.Idempotency{
+		Store: configloader.IdempotencyStoreConfigMap, ConfigMapName: "generations", ConfigMapNamespace: "hyperfleet",
+	})
+	start := func() *Executor {
+		store, err := idempotency.NewConfigMapStore(tc, "hyperfleet", "generations", 0, logger.NewTestLogger())
+		require.NoError(t, err)
+		return newIdempotencyTestExecutor(t, config, newMockAPIClient(), tc, store)
+	}
+
+	require.False(t, executeGeneration(start(), 3).ResourcesSkipped)
+
+	// The restarted adapter still knows generation 3 was processed
+	restarted := start()
+	result := executeGeneration(restarted, 2)
+	require.Equal(t, StatusSuccess, result.Status)
+	assert.Equal(t, SkipReasonStaleGeneration, result.SkipReason)
+	assert.False(t, executeGeneration(restarted, 4).ResourcesSkipped)
+}
@@ -0,0 +1,216 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/journal"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// journaledExecution is what the post actions of an execution need, journaled
+// once its resources are applied. The redacted params, env-sourced and
+// sensitive, and the config param are not journaled: they are extracted again
+// from the event data on recovery.
+type journaledExecution struct {
+	EventData map[string]interface{}            `json:"event_data"`
+	Params    map[string]interface{}            `json:"params"`
+	Resources map[string]map[string]interface{} `json:"resources,omitempty"`
+	// ResourceSummary is absent from the entries journaled before it existed
+	ResourceSummary *ResourceSummary `json:"resource_summary,omitempty"`
+	Adapter         journaledAdapter `json:"adapter"`
+	EventID         string           `json:"event_id,omitempty"`
+	EventType       string           `json:"event_type,omitempty"`
+	// Subject is the event subject, whose segments are params of subject_patterns
+	Subject  string `json:"subject,omitempty"`
+	Workflow string `json:"workflow"`
+}
+
+// journaledAdapter is the adapter metadata of a journaled execution
+type journaledAdapter struct {
+	ExecutionError   *ExecutionError `json:"execution_error,omitempty"`
+	ExecutionStatus  string          `json:"execution_status"`
+	ErrorReason      string          `json:"error_reason,omitempty"`
+	ErrorMessage     string          `json:"error_message,omitempty"`
+	SkipReason       string          `json:"skip_reason,omitempty"`
+	CorrelationID    string          `json:"correlation_id,omitempty"`
+	DeferredUntil    string          `json:"deferred_until,omitempty"`
+	ResourcesSkipped bool            `json:"resources_skipped,omitempty"`
+}
+
+// journalExecution records an execution whose resources were applied, before
+// its post actions run. Returns the ID of the entry, empty when the execution
+// is not journaled: without a journal, or when the entry cannot be written,
+// which is logged and leaves the post actions to the redelivery of the event.
+func (e *Executor) journalExecution(
+	ctx context.Context, meta eventMeta, execCtx *ExecutionContext,
+) string {
+	store := e.config.Journal
+	if store == nil {
+		return ""
+	}
+	redacted := make(map[string]bool)
+	for _, param := range e.config.Config.Params {
+		if isRedactedParam(param) {
+			redacted[param.Name] = true
+		}
+	}
+	params := execCtx.ParamsSnapshot()
+	for name := range params {
+		if redacted[name] || name == "config" {
+			delete(params, name)
+		}
+	}
+	execCtx.mu.RLock()
+	state := journaledExecution{
+		EventData: execCtx.EventData,
+		Params:    params,
+		Resources: make(map[string]map[string]interface{}, len(execCtx.Resources)),
+		Adapter: journaledAdapter{
+			ExecutionError:   execCtx.Adapter.executionError,
+			ExecutionStatus:  execCtx.Adapter.ExecutionStatus,
+			ErrorReason:      execCtx.Adapter.ErrorReason,
+			ErrorMessage:     execCtx.Adapter.ErrorMessage,
+			SkipReason:       execCtx.Adapter.SkipReason,
+			CorrelationID:    execCtx.Adapter.CorrelationID,
+			DeferredUntil:    execCtx.Adapter.DeferredUntil,
+			ResourcesSkipped: execCtx.Adapter.ResourcesSkipped,
+		},
+		ResourceSummary: execCtx.resourceSummary,
+		EventID:         meta.id,
+		EventType:       meta.eventType,
+		Subject:         meta.subject,
+		Workflow:        execCtx.Adapter.Workflow,
+	}
+	for name, value := range execCtx.Resources {
+		if obj, ok := value.(*unstructured.Unstructured); ok && obj != nil {
+			state.Resources[name] = obj.Object
+		}
+	}
+	execCtx.mu.RUnlock()
+
+	entry := journal.Entry{ID: uuid.NewString(), RecordedAt: e.clock.Now()}
+	data, err := json.Marshal(state)
+	if err == nil {
+		entry.Data = data
+		err = store.Put(ctx, entry)
+	}
+	if err != nil {
+		e.log.Warnf(logger.WithErrorField(ctx, err),
+			"Failed to journal the execution, its post actions are not recovered after a crash")
+		return ""
+	}
+	return entry.ID
+}
+
+// clearJournal removes the journal entry of an execution whose post actions ran
+func (e *Executor) clearJournal(ctx context.Context, id string) {
+	if id == "" {
+		return
+	}
+	if err := e.config.Journal.Delete(ctx, id); err != nil {
+		e.log.Warnf(logger.WithErrorField(ctx, err),
+			"Failed to clear journal entry %s, its post actions run again on the next start", id)
+	}
+}
+
+// RecoverJournal runs the post actions of the journaled executions, those
+// whose resources were applied by an adapter that stopped before their post
+// actions ran. Each entry is removed once its post actions succeed; entries
+// whose post actions fail are kept for the next start. Returns the number of
+// recovered executions and the errors of the others. Call it before consuming
+// events.
+func (e *Executor) RecoverJournal(ctx context.Context) (int, error) {
+	store := e.config.Journal
+	if store == nil {
+		return 0, nil
+	}
+	entries, err := store.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the execution journal: %w", err)
+	}
+	if len(entries) > 0 {
+		e.log.Infof(ctx, "Recovering the post actions of %d journaled execution(s)", len(entries))
+	}
+	recovered := 0
+	var errs []error
+	for _, entry := range entries {
+		if err := e.recoverEntry(ctx, entry); err != nil {
+			e.log.Errorf(logger.WithErrorField(ctx, err), "Failed to recover journaled execution %s", entry.ID)
+			errs = append(errs, fmt.Errorf("journal entry %s: %w", entry.ID, err))
+			continue
+		}
+		recovered++
+		if err := store.Delete(ctx, entry.ID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to clear journal entry %s: %w", entry.ID, err))
+		}
+	}
+	return recovered, errors.Join(errs...)
+}
+
+// recoverEntry rebuilds the execution context of a journal entry and runs the
+// post actions of its workflow
+func (e *Executor) recoverEntry(ctx context.Context, entry journal.Entry) error {
+	var state journaledExecution
+	if err := json.Unmarshal(entry.Data, &state); err != nil {
+		return fmt.Errorf("failed to decode the journaled execution: %w", err)
+	}
+	var workflow *configloader.Workflow
+	for _, candidate := range e.config.Config.AllWorkflows() {
+		if candidate.Name == state.Workflow {
+			workflow = &candidate
+			break
+		}
+	}
+	if workflow == nil {
+		return fmt.Errorf("workflow %q is no longer configured", state.Workflow)
+	}
+
+	if state.EventID != "" {
+		ctx = logger.WithEventID(ctx, state.EventID)
+	}
+	ctx = logger.WithCorrelationID(ctx, state.Adapter.CorrelationID)
+	if state.EventData == nil {
+		state.EventData = make(map[string]interface{})
+	}
+	execCtx := NewExecutionContext(ctx, state.EventData, e.config.Config)
+	execCtx.clock = e.clock
+	execCtx.flags = e.config.FeatureFlags.Snapshot()
+	execCtx.lookup, execCtx.templateFuncs = e.lookup, e.templateFuncs
+	execCtx.Adapter.Runtime = e.runtime
+	execCtx.Adapter.CorrelationID = state.Adapter.CorrelationID
+	// Restores the params that are not journaled; the journaled ones, including
+	// the captures of the preconditions, are set over them
+	if err := e.executeParamExtraction(execCtx, state.Subject); err != nil {
+		e.log.Warnf(logger.WithErrorField(ctx, err),
+			"Params of journaled execution %s were not all extracted again", entry.ID)
+	}
+	for name, value := range state.Params {
+		execCtx.SetParam(name, value)
+	}
+	for name, object := range state.Resources {
+		execCtx.Resources[name] = &unstructured.Unstructured{Object: object}
+	}
+	execCtx.SetResourceSummary(state.ResourceSummary)
+	execCtx.Adapter.executionError = state.Adapter.ExecutionError
+	execCtx.Adapter.ExecutionStatus = state.Adapter.ExecutionStatus
+	execCtx.Adapter.ErrorReason = state.Adapter.ErrorReason
+	execCtx.Adapter.ErrorMessage = state.Adapter.ErrorMessage
+	execCtx.Adapter.SkipReason = state.Adapter.SkipReason
+	execCtx.Adapter.DeferredUntil = state.Adapter.DeferredUntil
+	execCtx.Adapter.ResourcesSkipped = state.Adapter.ResourcesSkipped
+	execCtx.SetWorkflow(workflow.Name)
+
+	e.log.Infof(ctx, "Running the post actions of journaled execution %s recorded at %s",
+		entry.ID, entry.RecordedAt.Format(time.RFC3339))
+	if _, err := e.postActionExecutor.ExecuteAll(ctx, workflow.Post, execCtx); err != nil {
+		return fmt.Errorf("post action execution failed: %w", err)
+	}
+	return nil
+}
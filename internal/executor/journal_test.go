@@ -0,0 +1,183 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/journal"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/eventtest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// crash is the panic value of a simulated crash
+type crash struct{ phase ExecutionPhase }
+
+func journalTestConfig() *configloader.Config {
+	return &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+		Params: []configloader.Parameter{
+			{Name: "clusterId", Source: "event.id"},
+			{Name: "token", Source: "env.JOURNAL_TEST_TOKEN"},
+		},
+		Preconditions: []configloader.Precondition{{
+			ActionBase: configloader.ActionBase{
+				Name:    "clusterStatus",
+				APICall: &configloader.APICall{Method: "GET", URL: "/clusters/{{ .clusterId }}"},
+			},
+			Capture: []configloader.CaptureField{
+				{Name: "generation", FieldExpressionDef: configloader.FieldExpressionDef{Field: "generation"}},
+			},
+		}},
+		Resources: []configloader.Resource{{
+			Name: "cm",
+			Manifest: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "cm-{{ .clusterId }}", "namespace": "default"},
+			},
+			Discovery: &configloader.DiscoveryConfig{Namespace: "default", ByName: "cm-{{ .clusterId }}"},
+		}},
+		Post: &configloader.PostConfig{
+			Payloads: []configloader.Payload{{Name: "statusPayload", Build: map[string]interface{}{
+				"generation": map[string]interface{}{"expression": "generation"},
+				"configMap":  map[string]interface{}{"expression": "resources.cm.metadata.name"},
+				"token":      map[string]interface{}{"expression": "token"},
+			}}},
+			PostActions: []configloader.PostAction{{ActionBase: configloader.ActionBase{
+				Name: "report",
+				APICall: &configloader.APICall{
+					Method: "POST", URL: "/clusters/{{ .clusterId }}/statuses", Body: "{{ .statusPayload }}",
+				},
+			}}},
+		},
+	}
+}
+
+func newJournalTestExecutor(t *testing.T, store journal.Store) (*Executor, *hyperfleetapi.MockClient) {
+	t.Setenv("JOURNAL_TEST_TOKEN", "s3cret")
+	apiClient := newMockAPIClient()
+	apiClient.GetResponse = &hyperfleetapi.Response{StatusCode: 200, Status: "200 OK", Body: []byte(`{"generation":3}`)}
+	exec, err := NewBuilder().
+		WithConfig(journalTestConfig()).
+		WithAPIClient(apiClient).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		WithJournal(store).
+		Build()
+	require.NoError(t, err)
+	return exec, apiClient
+}
+
+// executeUntilCrash runs the execution of an event and crashes it before phase
+func executeUntilCrash(t *testing.T, exec *Executor, phase ExecutionPhase) {
+	exec.beforePhase = func(current ExecutionPhase) {
+		if current == phase {
+			panic(crash{phase: phase})
+		}
+	}
+	evt := eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build()
+	defer func() {
+		assert.Equal(t, crash{phase: phase}, recover())
+	}()
+	exec.ExecuteEvent(context.Background(), evt)
+}
+
+func posts(apiClient *hyperfleetapi.MockClient) []*hyperfleetapi.Request {
+	var requests []*hyperfleetapi.Request
+	for _, req := range apiClient.Requests {
+		if req.Method == "POST" {
+			requests = append(requests, req)
+		}
+	}
+	return requests
+}
+
+func TestJournal_RecoversPostActionsAfterCrash(t *testing.T) {
+	ctx := context.Background()
+	store, err := journal.NewFileStore(t.TempDir(), journal.Limits{}, logger.NewTestLogger())
+	require.NoError(t, err)
+
+	crashed, crashedAPI := newJournalTestExecutor(t, store)
+	executeUntilCrash(t, crashed, PhasePostActions)
+	assert.Empty(t, posts(crashedAPI), "the crash happened before the status post")
+
+	entries, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.NotContains(t, string(entries[0].Data), "s3cret", "env-sourced params are not journaled")
+
+	restarted, restartedAPI := newJournalTestExecutor(t, store)
+	recovered, err := restarted.RecoverJournal(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, recovered)
+
+	requests := posts(restartedAPI)
+	require.Len(t, requests, 1, "the status post runs exactly once")
+	assert.Equal(t, "/clusters/cluster-1/statuses", requests[0].URL)
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(requests[0].Body, &payload))
+	assert.Equal(t, map[string]interface{}{
+		"generation": float64(3), "configMap": "cm-cluster-1", "token": "s3cret",
+	}, payload)
+
+	entries, err = store.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// Another restart has nothing to recover
+	again, againAPI := newJournalTestExecutor(t, store)
+	recovered, err = again.RecoverJournal(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, recovered)
+	assert.Empty(t, posts(againAPI))
+}
+
+func TestJournal_CrashBeforeResourcesIsNotJournaled(t *testing.T) {
+	store, err := journal.NewFileStore(t.TempDir(), journal.Limits{}, logger.NewTestLogger())
+	require.NoError(t, err)
+	exec, _ := newJournalTestExecutor(t, store)
+	executeUntilCrash(t, exec, PhaseResources)
+
+	entries, err := store.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the event is redelivered, nothing was applied")
+}
+
+func TestJournal_ClearedAfterPostActions(t *testing.T) {
+	store, err := journal.NewFileStore(t.TempDir(), journal.Limits{}, logger.NewTestLogger())
+	require.NoError(t, err)
+	exec, apiClient := newJournalTestExecutor(t, store)
+	evt := eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build()
+	result := exec.ExecuteEvent(context.Background(), evt)
+	require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+	assert.Len(t, posts(apiClient), 1)
+
+	entries, err := store.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestJournal_FailedRecoveryIsKept(t *testing.T) {
+	ctx := context.Background()
+	store, err := journal.NewFileStore(t.TempDir(), journal.Limits{}, logger.NewTestLogger())
+	require.NoError(t, err)
+	crashed, _ := newJournalTestExecutor(t, store)
+	executeUntilCrash(t, crashed, PhasePostActions)
+
+	restarted, restartedAPI := newJournalTestExecutor(t, store)
+	restartedAPI.PostError = errors.New("connection refused")
+	recovered, err := restarted.RecoverJournal(ctx)
+	require.ErrorContains(t, err, "connection refused")
+	assert.Zero(t, recovered)
+
+	entries, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the post actions run again on the next start")
+}
@@ -0,0 +1,111 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/criteria"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// fieldRefObjects reads the objects of the k8s_field_ref params of an
+// execution, each object at most once
+type fieldRefObjects struct {
+	ctx     context.Context
+	client  transportclient.TransportClient
+	objects map[fieldRefKey]fieldRefObject
+}
+
+type fieldRefKey struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+	name      string
+}
+
+// fieldRefObject is the result of reading an object, kept for the other params
+type fieldRefObject struct {
+	obj *unstructured.Unstructured
+	err error
+}
+
+// fieldRefLookupError is an object of a k8s_field_ref param that could not be
+// read for another reason than not existing. It fails the param extraction
+// whatever the required and default of the param: the value is unknown, not absent.
+type fieldRefLookupError struct {
+	err  error
+	code ErrorCode
+}
+
+func (e *fieldRefLookupError) Error() string { return e.err.Error() }
+
+func (e *fieldRefLookupError) Unwrap() error { return e.err }
+
+func newFieldRefObjects(ctx context.Context, client transportclient.TransportClient) *fieldRefObjects {
+	return &fieldRefObjects{ctx: ctx, client: client, objects: make(map[fieldRefKey]fieldRefObject)}
+}
+
+// extract returns the field of ref, with its namespace and name rendered with
+// params. Returns nil when the object has the field set to null. An object or
+// a field that does not exist is an error.
+func (o *fieldRefObjects) extract(ref *configloader.K8sFieldRef, params map[string]interface{}) (interface{}, error) {
+	// Only the kubernetes transport client reads objects of the adapter's cluster
+	if _, ok := o.client.(resourcePatcher); !ok {
+		return nil, &fieldRefLookupError{
+			err:  fmt.Errorf("k8s_field_ref requires the kubernetes transport client"),
+			code: ErrorCodeTransportNotConfigured,
+		}
+	}
+	gvk, err := k8sclient.GVKFromKindAndAPIVersion(ref.Kind, ref.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid k8s_field_ref api_version: %w", err)
+	}
+	key := fieldRefKey{gvk: gvk}
+	if key.namespace, err = renderTemplateAt("value_from.k8s_field_ref.namespace", ref.Namespace, params); err != nil {
+		return nil, fmt.Errorf("failed to render k8s_field_ref namespace: %w", err)
+	}
+	if key.name, err = renderTemplateAt("value_from.k8s_field_ref.name", ref.Name, params); err != nil {
+		return nil, fmt.Errorf("failed to render k8s_field_ref name: %w", err)
+	}
+
+	object, ok := o.objects[key]
+	if !ok {
+		object.obj, object.err = o.client.GetResource(o.ctx, gvk, key.namespace, key.name, nil)
+		o.objects[key] = object
+	}
+	if object.err != nil {
+		if k8serrors.IsNotFound(object.err) {
+			return nil, fmt.Errorf("%s %s not found", gvk.Kind, objectPath(key.namespace, key.name))
+		}
+		code := ErrorCodeParamLookupFailed
+		if isTimeout(object.err) {
+			code = ErrorCodeTimeout
+		}
+		return nil, &fieldRefLookupError{
+			err:  fmt.Errorf("failed to get %s %s: %w", gvk.Kind, objectPath(key.namespace, key.name), object.err),
+			code: code,
+		}
+	}
+
+	result, err := criteria.ExtractField(object.obj.Object, ref.FieldPath)
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("field %q of %s %s: %w",
+			ref.FieldPath, gvk.Kind, objectPath(key.namespace, key.name), result.Error)
+	}
+	return result.Value, nil
+}
+
+// objectPath returns namespace/name, or name for a cluster-scoped object
+func objectPath(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
@@ -0,0 +1,123 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// countingK8sClient counts the GetResource calls of a mock client
+type countingK8sClient struct {
+	*k8sclient.MockK8sClient
+	gets int
+}
+
+func (c *countingK8sClient) GetResource(
+	ctx context.Context, gvk schema.GroupVersionKind, namespace, name string, target transportclient.TransportContext,
+) (*unstructured.Unstructured, error) {
+	c.gets++
+	return c.MockK8sClient.GetResource(ctx, gvk, namespace, name, target)
+}
+
+func fieldRefParam(name, objectName, fieldPath string) configloader.Parameter {
+	return configloader.Parameter{Name: name, ValueFrom: &configloader.ParamValueFrom{
+		K8sFieldRef: &configloader.K8sFieldRef{
+			ObjectRef: configloader.ObjectRef{
+				APIVersion: "hive.openshift.io/v1", Kind: "ClusterDeployment",
+				Namespace: "{{ .clusterId }}", Name: objectName,
+			},
+			FieldPath: fieldPath,
+		},
+	}}
+}
+
+func TestExtractConfigParams_K8sFieldRef(t *testing.T) {
+	newClient := func() *countingK8sClient {
+		mock := k8sclient.NewMockK8sClient()
+		mock.Resources["cluster-1/cd-cluster-1"] = &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "hive.openshift.io/v1",
+			"kind":       "ClusterDeployment",
+			"metadata":   map[string]interface{}{"name": "cd-cluster-1", "namespace": "cluster-1"},
+			"spec": map[string]interface{}{
+				"clusterMetadata": map[string]interface{}{"infraID": "cluster-1-x7k2p"},
+				"platform":        map[string]interface{}{"aws": map[string]interface{}{"region": "us-east-1"}},
+			},
+		}}
+		return &countingK8sClient{MockK8sClient: mock}
+	}
+	extract := func(client transportclient.TransportClient, params ...configloader.Parameter) (*ExecutionContext, error) {
+		config := &configloader.Config{Params: append(
+			[]configloader.Parameter{{Name: "clusterId", Source: "event.id"}}, params...)}
+		execCtx := NewExecutionContext(context.Background(), map[string]interface{}{"id": "cluster-1"}, config)
+		objects := newFieldRefObjects(context.Background(), client)
+		return execCtx, extractConfigParams(config, execCtx, map[string]interface{}{}, objects)
+	}
+
+	t.Run("fields of an object read once", func(t *testing.T) {
+		client := newClient()
+		execCtx, err := extract(client,
+			fieldRefParam("infraId", "cd-{{ .clusterId }}", "spec.clusterMetadata.infraID"),
+			fieldRefParam("region", "cd-{{ .clusterId }}", "{.spec.platform.aws.region}"),
+		)
+		require.NoError(t, err)
+		infraID, _ := execCtx.GetParam("infraId")
+		region, _ := execCtx.GetParam("region")
+		assert.Equal(t, "cluster-1-x7k2p", infraID)
+		assert.Equal(t, "us-east-1", region)
+		assert.Equal(t, 1, client.gets)
+	})
+
+	t.Run("missing object or field takes the default", func(t *testing.T) {
+		missingObject := fieldRefParam("infraId", "missing", "spec.clusterMetadata.infraID")
+		missingObject.Default = "none"
+		missingField := fieldRefParam("baseDomain", "cd-{{ .clusterId }}", "spec.baseDomain")
+		missingField.Default = "example.com"
+		optional := fieldRefParam("pullSecret", "cd-{{ .clusterId }}", "spec.pullSecretRef.name")
+
+		execCtx, err := extract(newClient(), missingObject, missingField, optional)
+		require.NoError(t, err)
+		infraID, _ := execCtx.GetParam("infraId")
+		baseDomain, _ := execCtx.GetParam("baseDomain")
+		_, found := execCtx.GetParam("pullSecret")
+		assert.Equal(t, "none", infraID)
+		assert.Equal(t, "example.com", baseDomain)
+		assert.False(t, found)
+	})
+
+	t.Run("missing required object", func(t *testing.T) {
+		param := fieldRefParam("infraId", "missing", "spec.clusterMetadata.infraID")
+		param.Required = true
+		_, err := extract(newClient(), param)
+		require.Error(t, err)
+		assert.Equal(t, ErrorCodeParamMissing, ErrorCodeOf(err))
+		assert.ErrorContains(t, err, "ClusterDeployment cluster-1/missing not found")
+	})
+
+	t.Run("failed read fails optional params", func(t *testing.T) {
+		client := newClient()
+		client.GetResourceError = apierrors.NewForbidden(
+			schema.GroupResource{Group: "hive.openshift.io", Resource: "clusterdeployments"}, "cd-cluster-1",
+			errors.New("RBAC denied"))
+		param := fieldRefParam("infraId", "cd-{{ .clusterId }}", "spec.clusterMetadata.infraID")
+		param.Default = "none"
+		_, err := extract(client, param)
+		require.Error(t, err)
+		assert.Equal(t, ErrorCodeParamLookupFailed, ErrorCodeOf(err))
+		assert.True(t, apierrors.IsForbidden(err))
+	})
+
+	t.Run("without the kubernetes transport", func(t *testing.T) {
+		_, err := extract(nil, fieldRefParam("infraId", "cd-{{ .clusterId }}", "spec.clusterMetadata.infraID"))
+		require.Error(t, err)
+		assert.Equal(t, ErrorCodeTransportNotConfigured, ErrorCodeOf(err))
+	})
+}
@@ -0,0 +1,41 @@
+package executor
+
+import (
+	"maps"
+	"text/template"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/criteria"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/lookup"
+)
+
+// templateFuncsParam is the key of the template data holding the template
+// functions bound to the maps of the execution. It is not a valid param name,
+// so it never shadows a param.
+const templateFuncsParam = "$templateFuncs"
+
+// lookupTemplateFuncs returns templateFuncs with the lookup function of
+// tables, nil without maps. It is created once per executor, so the templates
+// of its executions are parsed once.
+func lookupTemplateFuncs(tables *lookup.Tables) template.FuncMap {
+	if tables.Len() == 0 {
+		return nil
+	}
+	funcs := maps.Clone(templateFuncs)
+	funcs["lookup"] = tables.Lookup
+	return funcs
+}
+
+// lookupFunc returns the lookup() of the maps of the execution
+func (ec *ExecutionContext) lookupFunc() criteria.MapLookup {
+	return ec.lookup.Lookup
+}
+
+// templateParams returns a snapshot of the params as the data of the
+// templates of the execution, carrying the template functions bound to its maps
+func (ec *ExecutionContext) templateParams() map[string]interface{} {
+	params := ec.ParamsSnapshot()
+	if ec.templateFuncs != nil {
+		params[templateFuncsParam] = ec.templateFuncs
+	}
+	return params
+}
@@ -0,0 +1,108 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/eventtest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lookupTestConfig translates the phase of the cluster through the
+// phaseStatus map and its region through the regions map
+func lookupTestConfig() *configloader.Config {
+	config := journalTestConfig()
+	config.Maps = []configloader.LookupMap{
+		{Name: "phaseStatus", Entries: map[string]interface{}{"Provisioning": "INSTALLING", "Ready": "READY"}},
+		{Name: "regions", OnMissing: configloader.LookupOnMissingEmpty, Entries: map[string]interface{}{
+			"us-east-1": map[string]interface{}{
+				"datacenter": "dc-1",
+				"network":    map[string]interface{}{"zones": []interface{}{"a", "b"}},
+			},
+		}},
+	}
+	config.Preconditions[0].Capture = []configloader.CaptureField{
+		{Name: "status", FieldExpressionDef: configloader.FieldExpressionDef{
+			Expression: `lookup("phaseStatus", phase)`}},
+		{Name: "phase", FieldExpressionDef: configloader.FieldExpressionDef{Field: "phase"}},
+		{Name: "region", FieldExpressionDef: configloader.FieldExpressionDef{Field: "region"}},
+	}
+	config.Preconditions[0].Expression = `lookup("regions", region).datacenter != ""`
+	metadata := config.Resources[0].Manifest.(map[string]interface{})["metadata"].(map[string]interface{})
+	metadata["labels"] = map[string]interface{}{
+		"datacenter": `{{ (lookup "regions" .region).datacenter }}`,
+		"status":     `{{ lookup "phaseStatus" .phase | lower }}`,
+	}
+	config.Post.Payloads[0].Build = map[string]interface{}{
+		"status":  map[string]interface{}{"expression": "status"},
+		"network": map[string]interface{}{"expression": `lookup("regions", region).network`},
+	}
+	return config
+}
+
+func newLookupTestExecutor(
+	t *testing.T, cluster string,
+) (*Executor, *hyperfleetapi.MockClient, *k8sclient.MockK8sClient) {
+	t.Setenv("JOURNAL_TEST_TOKEN", "s3cret")
+	apiClient := newMockAPIClient()
+	apiClient.GetResponse = &hyperfleetapi.Response{StatusCode: 200, Status: "200 OK", Body: []byte(cluster)}
+	k8sClient := k8sclient.NewMockK8sClient()
+	exec, err := NewBuilder().
+		WithConfig(lookupTestConfig()).
+		WithAPIClient(apiClient).
+		WithTransportClient(k8sClient).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+	return exec, apiClient, k8sClient
+}
+
+func TestLookupMaps(t *testing.T) {
+	exec, apiClient, k8sClient := newLookupTestExecutor(t, `{"phase":"Ready","region":"us-east-1"}`)
+	evt := eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build()
+	result := exec.ExecuteEvent(context.Background(), evt)
+	require.Equal(t, StatusSuccess, result.Status, "error: %v", result.Errors)
+
+	cm := k8sClient.Resources["default/cm-cluster-1"]
+	require.NotNil(t, cm)
+	assert.Equal(t, map[string]string{"datacenter": "dc-1", "status": "ready"}, cm.GetLabels())
+
+	reports := posts(apiClient)
+	require.Len(t, reports, 1)
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(reports[0].Body, &payload))
+	assert.Equal(t, map[string]interface{}{
+		"status":  "READY",
+		"network": map[string]interface{}{"zones": []interface{}{"a", "b"}},
+	}, payload)
+}
+
+func TestLookupMaps_MissingKey(t *testing.T) {
+	t.Run("error policy fails the execution", func(t *testing.T) {
+		exec, _, k8sClient := newLookupTestExecutor(t, `{"phase":"Deleting","region":"us-east-1"}`)
+		evt := eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build()
+		result := exec.ExecuteEvent(context.Background(), evt)
+
+		require.Equal(t, StatusFailed, result.Status)
+		require.Error(t, result.Errors[PhaseResources])
+		assert.Contains(t, result.Errors[PhaseResources].Error(),
+			`error calling lookup: key not found: "Deleting" in map "phaseStatus"`)
+		assert.Empty(t, k8sClient.Resources)
+	})
+
+	t.Run("empty policy", func(t *testing.T) {
+		exec, _, k8sClient := newLookupTestExecutor(t, `{"phase":"Ready","region":"eu-west-1"}`)
+		evt := eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build()
+		result := exec.ExecuteEvent(context.Background(), evt)
+
+		require.Equal(t, StatusSuccess, result.Status, "error: %v", result.Errors)
+		assert.True(t, result.ResourcesSkipped, "the precondition is not met for an unknown region")
+		assert.Empty(t, k8sClient.Resources)
+	})
+}
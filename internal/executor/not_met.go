@@ -0,0 +1,149 @@
+package executor
+
+import (
+	"sort"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/lru"
+)
+
+// not_met_backoff defaults
+const (
+	DefaultNotMetThreshold      = 5
+	DefaultNotMetBaseDelay      = 30 * time.Second
+	DefaultNotMetMaxDelay       = configloader.DefaultRequeueMaxDelay
+	DefaultNotMetLogSampleEvery = 10
+	DefaultNotMetMaxKeys        = 10000
+)
+
+// notMetStatsLimit is the number of keys, with the most consecutive not-met
+// executions first, listed in ExecutorStats.NotMet
+const notMetStatsLimit = 50
+
+// NotMetKeyStats is the not-met streak of an execution key
+type NotMetKeyStats struct {
+	LastNotMetAt time.Time `json:"last_not_met_at"`
+	Key          string    `json:"key"`
+	LastReason   string    `json:"last_reason"`
+	// Count is the number of consecutive not-met executions of the key
+	Count int `json:"count"`
+}
+
+// notMetEntry is the not-met streak of a key
+type notMetEntry struct {
+	at     time.Time
+	key    string
+	reason string
+	count  int
+}
+
+// notMetTracker is an LRU of the consecutive not-met executions of each key.
+// Only keys in a streak are held: a key is dropped by reset.
+type notMetTracker struct {
+	entries *lru.Cache[string, notMetEntry]
+	clock   clock.Clock
+}
+
+func newNotMetTracker(maxKeys int, clk clock.Clock) *notMetTracker {
+	if maxKeys <= 0 {
+		maxKeys = DefaultNotMetMaxKeys
+	}
+	return &notMetTracker{
+		entries: lru.New[string, notMetEntry](maxKeys),
+		clock:   clock.OrReal(clk),
+	}
+}
+
+// record counts a not-met execution of key and returns the consecutive count,
+// evicting the least recently not-met keys beyond capacity
+func (t *notMetTracker) record(key, reason string) int {
+	entry := t.entries.Update(key, func(entry notMetEntry, _ bool) notMetEntry {
+		return notMetEntry{key: key, count: entry.count + 1, reason: reason, at: t.clock.Now()}
+	})
+	return entry.count
+}
+
+// count returns the consecutive not-met executions of key
+func (t *notMetTracker) count(key string) int {
+	entry, _ := t.entries.Peek(key)
+	return entry.count
+}
+
+// reset ends the streak of key
+func (t *notMetTracker) reset(key string) {
+	t.entries.Remove(key)
+}
+
+// len returns the number of keys in a streak
+func (t *notMetTracker) len() int {
+	return t.entries.Len()
+}
+
+// top returns the streaks of at most limit keys, longest first
+func (t *notMetTracker) top(limit int) []NotMetKeyStats {
+	entries := t.entries.Values()
+	stats := make([]NotMetKeyStats, 0, len(entries))
+	for _, entry := range entries {
+		stats = append(stats, NotMetKeyStats{
+			Key: entry.key, Count: entry.count, LastReason: entry.reason, LastNotMetAt: entry.at,
+		})
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].Count > stats[j].Count })
+	if len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats
+}
+
+// notMetEscalation is how a not-met execution is handled
+type notMetEscalation struct {
+	// delay is the redelivery delay to request, zero below the threshold
+	delay time.Duration
+	// logged reports whether the not-met outcome is logged, at info level below
+	// the threshold and at debug level above it
+	logged bool
+}
+
+// escalation returns how the count-th consecutive not-met execution of a key
+// is handled: the executions up to the threshold as usual, the following ones
+// with a delay doubling from the base delay up to the max delay and with one
+// log every LogSampleEvery executions
+func escalation(backoff *configloader.NotMetBackoff, count int) notMetEscalation {
+	threshold := notMetThreshold(backoff)
+	if count <= threshold {
+		return notMetEscalation{logged: true}
+	}
+	baseDelay, maxDelay := backoff.BaseDelay, backoff.MaxDelay
+	if baseDelay <= 0 {
+		baseDelay = DefaultNotMetBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = DefaultNotMetMaxDelay
+	}
+	every := backoff.LogSampleEvery
+	if every <= 0 {
+		every = DefaultNotMetLogSampleEvery
+	}
+
+	escalated := count - threshold - 1
+	delay := baseDelay
+	for i := 0; i < escalated && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return notMetEscalation{delay: delay, logged: escalated%every == 0}
+}
+
+// notMetThreshold returns the number of consecutive not-met executions of a
+// key handled as usual
+func notMetThreshold(backoff *configloader.NotMetBackoff) int {
+	if backoff.Threshold <= 0 {
+		return DefaultNotMetThreshold
+	}
+	return backoff.Threshold
+}
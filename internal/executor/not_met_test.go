@@ -0,0 +1,141 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscalation(t *testing.T) {
+	backoff := &configloader.NotMetBackoff{
+		Threshold: 2, BaseDelay: time.Second, MaxDelay: 5 * time.Second, LogSampleEvery: 3,
+	}
+	tests := []struct {
+		name    string
+		backoff *configloader.NotMetBackoff
+		count   int
+		want    notMetEscalation
+	}{
+		{name: "below threshold", backoff: backoff, count: 1, want: notMetEscalation{logged: true}},
+		{name: "at threshold", backoff: backoff, count: 2, want: notMetEscalation{logged: true}},
+		{name: "first escalated", backoff: backoff, count: 3, want: notMetEscalation{delay: time.Second, logged: true}},
+		{name: "doubled", backoff: backoff, count: 4, want: notMetEscalation{delay: 2 * time.Second}},
+		{name: "capped", backoff: backoff, count: 6, want: notMetEscalation{delay: 5 * time.Second, logged: true}},
+		{name: "long streak", backoff: backoff, count: 1000, want: notMetEscalation{delay: 5 * time.Second}},
+		{name: "defaults", backoff: &configloader.NotMetBackoff{}, count: 6,
+			want: notMetEscalation{delay: DefaultNotMetBaseDelay, logged: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, escalation(tt.backoff, tt.count))
+		})
+	}
+}
+
+func TestNotMetTracker_EvictsLeastRecentlyNotMet(t *testing.T) {
+	tracker := newNotMetTracker(2, nil)
+	tracker.record("a", "not ready")
+	tracker.record("a", "not ready")
+	tracker.record("b", "not ready")
+	tracker.record("a", "still not ready")
+	tracker.record("c", "not ready")
+
+	assert.Equal(t, 2, tracker.len())
+	top := tracker.top(notMetStatsLimit)
+	require.Len(t, top, 2)
+	assert.Equal(t, "a", top[0].Key)
+	assert.Equal(t, 3, top[0].Count)
+	assert.Equal(t, "still not ready", top[0].LastReason)
+	assert.Equal(t, "c", top[1].Key)
+
+	tracker.reset("a")
+	assert.Equal(t, 1, tracker.len())
+}
+
+func TestNotMetBackoff_EscalatesConsecutiveNotMet(t *testing.T) {
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		Params: []configloader.Parameter{
+			{Name: "clusterId", Source: "event.id", Required: true},
+			{Name: "ready", Source: "event.ready"},
+		},
+		Preconditions: []configloader.Precondition{
+			{ActionBase: configloader.ActionBase{Name: "ready"}, Expression: "ready == true"},
+		},
+		NotMetBackoff: &configloader.NotMetBackoff{
+			Key: "{{ .clusterId }}", Threshold: 5, BaseDelay: time.Second, MaxDelay: 8 * time.Second, LogSampleEvery: 5,
+		},
+	}
+	log, capture := logger.NewCaptureLogger()
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(log).
+		Build()
+	require.NoError(t, err)
+
+	notReady := map[string]interface{}{"id": "cluster-1", "ready": false}
+	var delays []time.Duration
+	for i := 0; i < 20; i++ {
+		result := exec.Execute(context.Background(), notReady)
+		require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+		require.True(t, result.ResourcesSkipped)
+		delays = append(delays, result.RetryAfter)
+	}
+
+	s := time.Second
+	assert.Equal(t, []time.Duration{
+		0, 0, 0, 0, 0, s, 2 * s, 4 * s, 8 * s, 8 * s,
+		8 * s, 8 * s, 8 * s, 8 * s, 8 * s, 8 * s, 8 * s, 8 * s, 8 * s, 8 * s,
+	}, delays)
+
+	// Every full-rate execution logs its precondition, phase and execution outcomes at info level
+	var preconditionLogs, phaseLogs, finishedLogs, sampledLogs int
+	for _, line := range strings.Split(capture.Messages(), "\n") {
+		info := strings.Contains(line, "level=INFO")
+		switch {
+		case info && strings.Contains(line, "Precondition[ready] evaluated: NOT_MET"):
+			preconditionLogs++
+		case info && strings.Contains(line, "SUCCESS - NOT_MET"):
+			phaseLogs++
+		case info && strings.Contains(line, "Event execution finished"):
+			finishedLogs++
+		case strings.Contains(line, "level=DEBUG") && strings.Contains(line, "times in a row"):
+			sampledLogs++
+		}
+	}
+	assert.Equal(t, 5, preconditionLogs, "the executions up to the threshold are logged at full rate")
+	assert.Equal(t, 5, phaseLogs, "the executions up to the threshold are logged at full rate")
+	assert.Equal(t, 5, finishedLogs, "the executions up to the threshold are logged at full rate")
+	assert.Equal(t, 3, sampledLogs, "one in 5 of the 15 escalated executions is logged")
+
+	// Another key has its own streak
+	result := exec.Execute(context.Background(), map[string]interface{}{"id": "cluster-2", "ready": false})
+	assert.Zero(t, result.RetryAfter)
+
+	stats := exec.Stats()
+	assert.Equal(t, 2, stats.CacheSizes[CacheNotMet])
+	require.Len(t, stats.NotMet, 2)
+	assert.Equal(t, "cluster-1", stats.NotMet[0].Key)
+	assert.Equal(t, 20, stats.NotMet[0].Count)
+	assert.NotEmpty(t, stats.NotMet[0].LastReason)
+
+	// The first execution meeting the preconditions resets the streak
+	result = exec.Execute(context.Background(), map[string]interface{}{"id": "cluster-1", "ready": true})
+	require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+	require.False(t, result.ResourcesSkipped)
+	stats = exec.Stats()
+	require.Len(t, stats.NotMet, 1)
+	assert.Equal(t, "cluster-2", stats.NotMet[0].Key)
+
+	result = exec.Execute(context.Background(), notReady)
+	assert.Zero(t, result.RetryAfter, "a reset key starts a new streak")
+}
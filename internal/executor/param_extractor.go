@@ -1,32 +1,83 @@
 package executor
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/utils"
 )
 
-// extractConfigParams extracts all configured parameters and populates execCtx.Params
+// extractSubjectParams sets the named segments of the first of patterns matching
+// subject as params of execCtx. A subject matching none of them fails, unless
+// subject_optional; an empty subject matches none.
+func extractSubjectParams(
+	config *configloader.Config,
+	patterns []*configloader.SubjectPattern,
+	execCtx *ExecutionContext,
+	subject string,
+) error {
+	if len(patterns) == 0 {
+		return nil
+	}
+	for _, pattern := range patterns {
+		if values, ok := pattern.Match(subject); ok {
+			for name, value := range values {
+				execCtx.SetParam(name, value)
+			}
+			return nil
+		}
+	}
+	if config.SubjectOptional {
+		return nil
+	}
+	quoted := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		quoted[i] = fmt.Sprintf("%q", pattern.String())
+	}
+	return NewExecutorError(PhaseParamExtraction, ErrorCodeSubjectMismatch, "subject",
+		fmt.Sprintf("subject %q matches none of the subject patterns %s", subject, strings.Join(quoted, ", ")), nil)
+}
+
+// extractConfigParams extracts all configured parameters and sets them as params of execCtx,
+// in order, reading the objects of k8s_field_ref params with objects.
 // This is a pure function that directly modifies execCtx for simplicity
 func extractConfigParams(
 	config *configloader.Config,
 	execCtx *ExecutionContext,
 	configMap map[string]interface{},
+	objects *fieldRefObjects,
 ) error {
-	for _, param := range config.Params {
-		value, err := extractParam(param, execCtx.EventData, configMap)
+	for i, param := range config.Params {
+		var value interface{}
+		var err error
+		if param.ValueFrom != nil && param.ValueFrom.K8sFieldRef != nil {
+			value, err = objects.extract(param.ValueFrom.K8sFieldRef, execCtx.templateParams())
+			err = atPathf(err, "params[%d]", i)
+		} else {
+			value, err = extractParam(param, execCtx.EventData, configMap)
+		}
 		if err != nil {
+			var lookupErr *fieldRefLookupError
+			if errors.As(err, &lookupErr) {
+				return NewExecutorError(PhaseParamExtraction, lookupErr.code, param.Name,
+					fmt.Sprintf("failed to read parameter '%s' from %s", param.Name, paramSource(param)), lookupErr.err)
+			}
+			if _, ok := IsTemplateError(err); ok && param.Required {
+				return NewExecutorError(PhaseParamExtraction, ErrorCodeTemplateError, param.Name,
+					fmt.Sprintf("failed to render required parameter '%s'", param.Name), err)
+			}
 			if param.Required {
-				return NewExecutorError(PhaseParamExtraction, param.Name,
-					fmt.Sprintf("failed to extract required parameter '%s' from source '%s'", param.Name, param.Source), err)
+				return NewExecutorError(PhaseParamExtraction, ErrorCodeParamMissing, param.Name, fmt.Sprintf(
+					"failed to extract required parameter '%s' from source '%s'", param.Name, paramSource(param)), err)
 			}
 			// Use default for non-required params if extraction fails
 			if param.Default != nil {
-				execCtx.Params[param.Name] = param.Default
+				execCtx.SetParam(param.Name, param.Default)
 			}
 			continue
 		}
@@ -45,12 +96,12 @@ func extractConfigParams(
 			converted, convErr := convertParamType(value, param.Type)
 			if convErr != nil {
 				if param.Required {
-					return NewExecutorError(PhaseParamExtraction, param.Name,
+					return NewExecutorError(PhaseParamExtraction, ErrorCodeParamInvalid, param.Name,
 						fmt.Sprintf("failed to convert parameter '%s' to type '%s'", param.Name, param.Type), convErr)
 				}
 				// Use default for non-required params if conversion fails
 				if param.Default != nil {
-					execCtx.Params[param.Name] = param.Default
+					execCtx.SetParam(param.Name, param.Default)
 				}
 				continue
 			}
@@ -58,13 +109,63 @@ func extractConfigParams(
 		}
 
 		if value != nil {
-			execCtx.Params[param.Name] = value
+			execCtx.SetParam(param.Name, value)
+			if param.Sensitive {
+				logger.RegisterSecret(fmt.Sprint(value))
+			}
 		}
 	}
 
 	return nil
 }
 
+// checkRequiredParams checks that every entry of config.RequiredParams, a param
+// name or an event.* path, is present and non-empty. Returns a single error
+// listing all missing entries, with the configured source of params.
+func checkRequiredParams(config *configloader.Config, execCtx *ExecutionContext) error {
+	sources := make(map[string]string, len(config.Params))
+	for _, param := range config.Params {
+		sources[param.Name] = paramSource(param)
+	}
+
+	var missing []string
+	for _, name := range config.RequiredParams {
+		if source, ok := sources[name]; ok {
+			if value, _ := execCtx.GetParam(name); isEmptyParam(value) {
+				missing = append(missing, fmt.Sprintf("%s (source %s)", name, source))
+			}
+			continue
+		}
+		value, err := utils.GetNestedValue(execCtx.EventData, strings.TrimPrefix(name, "event."))
+		if err != nil || isEmptyParam(value) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return NewExecutorError(PhaseParamExtraction, ErrorCodeParamMissing, "required_params",
+		fmt.Sprintf("missing required params: %s", strings.Join(missing, ", ")), nil)
+}
+
+// paramSource describes where a param is extracted from, for error messages
+func paramSource(param configloader.Parameter) string {
+	if param.ValueFrom != nil && param.ValueFrom.K8sFieldRef != nil {
+		ref := param.ValueFrom.K8sFieldRef
+		return fmt.Sprintf("k8s_field_ref %s %s %s", ref.Kind, objectPath(ref.Namespace, ref.Name), ref.FieldPath)
+	}
+	return param.Source
+}
+
+// isEmptyParam reports whether a param value is absent or an empty string
+func isEmptyParam(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	s, ok := value.(string)
+	return ok && s == ""
+}
+
 // extractParam extracts a single parameter based on its source
 func extractParam(
 	param configloader.Parameter,
@@ -117,13 +218,27 @@ func extractFromEnv(envVar string) (interface{}, error) {
 	return value, nil
 }
 
-// addAdapterParams adds adapter info and the full config map to execCtx.Params
+// addAdapterParams adds the vars, adapter info, the runtime metadata of execCtx and
+// the full config map to the params of execCtx.
+//
+// Params are set in increasing order of precedence, each overriding the previous
+// ones of the same name: vars, then the built-in adapter and config params, then
+// extracted params, then precondition captures. The validator rejects vars that
+// collide with a built-in variable or a declared param, so the precedence only
+// matters for configs loaded without semantic validation.
 func addAdapterParams(config *configloader.Config, execCtx *ExecutionContext, configMap map[string]interface{}) {
-	execCtx.Params["adapter"] = map[string]interface{}{
+	for name, value := range config.Vars {
+		execCtx.SetParam(name, value)
+	}
+	adapter := map[string]interface{}{
 		"name":    config.Adapter.Name,
 		"version": config.Adapter.Version,
+
+		AdapterKeyCorrelationID: execCtx.Adapter.CorrelationID,
 	}
-	execCtx.Params["config"] = configMap
+	execCtx.Adapter.Runtime.addTo(adapter)
+	execCtx.SetParam("adapter", adapter)
+	execCtx.SetParam("config", configMap)
 }
 
 // convertParamType converts a value to the specified type.
@@ -1,8 +1,11 @@
 package executor
 
 import (
+	"context"
 	"testing"
 
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -200,3 +203,20 @@ func TestConvertToBool(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractConfigParams_SensitiveRegistersSecret(t *testing.T) {
+	t.Setenv("TEST_SENSITIVE_PARAM", "sensitive-param-value")
+	t.Setenv("TEST_PLAIN_PARAM", "plain-param-value")
+	config := &configloader.Config{Params: []configloader.Parameter{
+		{Name: "password", Source: "env.TEST_SENSITIVE_PARAM", Sensitive: true},
+		{Name: "region", Source: "env.TEST_PLAIN_PARAM"},
+	}}
+	execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+
+	objects := newFieldRefObjects(context.Background(), nil)
+	require.NoError(t, extractConfigParams(config, execCtx, map[string]interface{}{}, objects))
+	password, _ := execCtx.GetParam("password")
+	assert.Equal(t, "sensitive-param-value", password)
+	assert.Equal(t, logger.RedactedPlaceholder, logger.DefaultRedactor().Redact("sensitive-param-value"))
+	assert.Equal(t, "plain-param-value", logger.DefaultRedactor().Redact("plain-param-value"))
+}
@@ -4,25 +4,71 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"strings"
 
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/criteria"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/postbuffer"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
 )
 
 // PostActionExecutor executes post-processing actions
 type PostActionExecutor struct {
-	apiClient hyperfleetapi.Client
-	log       logger.Logger
+	apiClient       hyperfleetapi.Client
+	transportClient transportclient.TransportClient
+	recorder        *metrics.Recorder
+	log             logger.Logger
+	// logSampler samples log actions with sample_every across executions
+	logSampler *logSampler
+	// unchanged holds the body hashes last sent by skip_if_unchanged actions
+	unchanged *unchangedCache
+	// webhookClient delivers webhook actions
+	webhookClient WebhookClient
+	// buffer buffers the failed API calls of the actions with buffer_on_failure
+	buffer *postbuffer.Buffer
+}
+
+// resourcePatcher is implemented by transport clients that patch objects in place,
+// such as the kubernetes client; maestro only applies ManifestWorks
+type resourcePatcher interface {
+	PatchResource(
+		ctx context.Context,
+		gvk schema.GroupVersionKind,
+		namespace, name string,
+		patchData []byte,
+		opts *k8sclient.PatchOptions,
+	) (*unstructured.Unstructured, error)
+}
+
+// patchTypes maps the patch_type of a k8s_patch action to its Kubernetes patch type
+var patchTypes = map[string]types.PatchType{
+	"":                              types.MergePatchType,
+	configloader.PatchTypeMerge:     types.MergePatchType,
+	configloader.PatchTypeJSON:      types.JSONPatchType,
+	configloader.PatchTypeStrategic: types.StrategicMergePatchType,
 }
 
 // newPostActionExecutor creates a new post-action executor
 // NOTE: Caller (NewExecutor) is responsible for config validation
 func newPostActionExecutor(config *ExecutorConfig) *PostActionExecutor {
 	return &PostActionExecutor{
-		apiClient: config.APIClient,
-		log:       config.Logger,
+		apiClient:       config.APIClient,
+		transportClient: config.TransportClient,
+		recorder:        config.MetricsRecorder,
+		log:             config.Logger,
+		logSampler:      newLogSampler(),
+		unchanged:       newUnchangedCache(DefaultUnchangedMaxEntries, config.Clock),
+		webhookClient:   config.WebhookClient,
+		buffer:          config.PostBuffer,
 	}
 }
 
@@ -37,93 +83,162 @@ func (pae *PostActionExecutor) ExecuteAll(
 		return []PostActionResult{}, nil
 	}
 
+	if cancelErr := cancelled(ctx, PhasePostActions, -1); cancelErr != nil {
+		return []PostActionResult{}, cancelErr
+	}
+
 	// Step 1: Build post payloads (like clusterStatusPayload)
 	if len(postConfig.Payloads) > 0 {
-		pae.log.Infof(ctx, "Building %d post payloads", len(postConfig.Payloads))
-		if err := pae.buildPostPayloads(ctx, postConfig.Payloads, execCtx); err != nil {
+		log := stepLogger(pae.log, PhasePostActions, "build_payloads")
+		log.Infof(ctx, "Building %d post payloads", len(postConfig.Payloads))
+		if err := pae.buildPostPayloads(ctx, log, postConfig.Payloads, execCtx); err != nil {
 			errCtx := logger.WithErrorField(ctx, err)
-			pae.log.Errorf(errCtx, "Failed to build post payloads")
-			execCtx.Adapter.ExecutionError = &ExecutionError{
+			log.Errorf(errCtx, "Failed to build post payloads")
+			code := payloadBuildErrorCode(err)
+			execCtx.SetExecutionError(&ExecutionError{
 				Phase:   string(PhasePostActions),
 				Step:    "build_payloads",
 				Message: err.Error(),
-			}
+				Code:    code,
+			})
 			return []PostActionResult{}, NewExecutorError(
-				PhasePostActions, "build_payloads", "failed to build post payloads", err)
+				PhasePostActions, code, "build_payloads", "failed to build post payloads", err)
 		}
 		for _, payload := range postConfig.Payloads {
-			pae.log.Debugf(ctx, "payload[%s] built successfully", payload.Name)
+			log.Debugf(ctx, "payload[%s] built successfully", payload.Name)
 		}
 	}
 
-	// Step 2: Execute post actions (sequential - stop on first failure)
+	// Step 2: Execute post actions (sequential - stop on first failure unless continue_on_error)
 	results := make([]PostActionResult, 0, len(postConfig.PostActions))
-	for _, action := range postConfig.PostActions {
-		result, err := pae.executePostAction(ctx, action, execCtx)
+	for i, action := range postConfig.PostActions {
+		if cancelErr := cancelled(ctx, PhasePostActions, i-1); cancelErr != nil {
+			return results, cancelErr
+		}
+		log := stepLogger(pae.log, PhasePostActions, action.Name)
+		started := execCtx.now()
+		stepCtx := execCtx.stepContext(ctx, PhasePostActions, action.Name)
+		result, err := pae.executePostAction(stepCtx, log, action, execCtx)
+		err = execCtx.atStepPath(err, "post.post_actions[%d]", i)
+		result.StartedAt, result.Duration = started, execCtx.now().Sub(started)
+		result.RetryWait = execCtx.retryWait(PhasePostActions, action.Name)
 		results = append(results, result)
+		if err != nil {
+			// A call interrupted by the cancellation is reported as the cancellation
+			if cancelErr := cancelled(ctx, PhasePostActions, i-1); cancelErr != nil {
+				return results, cancelErr
+			}
+		}
 
+		if err != nil && action.ContinueOnError {
+			errCtx := logger.WithErrorField(ctx, err)
+			log.Warnf(errCtx, "PostAction[%s] processed: FAILED, continuing (continue_on_error)", action.Name)
+			continue
+		}
 		if err != nil {
 			errCtx := logger.WithErrorField(ctx, err)
-			pae.log.Errorf(errCtx, "PostAction[%s] processed: FAILED", action.Name)
+			log.Errorf(errCtx, "PostAction[%s] processed: FAILED", action.Name)
 
 			// Set ExecutionError for failed post action
-			execCtx.Adapter.ExecutionError = &ExecutionError{
+			execCtx.SetExecutionError(&ExecutionError{
 				Phase:   string(PhasePostActions),
 				Step:    action.Name,
 				Message: err.Error(),
-			}
+				Code:    ErrorCodeOf(err),
+			})
 
 			// Stop execution - don't run remaining post actions
 			return results, err
 		}
-		pae.log.Infof(ctx, "PostAction[%s] processed: SUCCESS - status=%s", action.Name, result.Status)
+		log.Infof(ctx, "PostAction[%s] processed: SUCCESS - status=%s", action.Name, result.Status)
 	}
 
 	return results, nil
 }
 
-// buildPostPayloads builds all post payloads and stores them in execCtx.Params
+// buildPostPayloads builds all post payloads and stores them in the params of execCtx
 // Payloads are complex structures built from CEL expressions and templates
 func (pae *PostActionExecutor) buildPostPayloads(
 	ctx context.Context,
+	log logger.Logger,
 	payloads []configloader.Payload,
 	execCtx *ExecutionContext,
 ) error {
-	// Create evaluation context with all CEL variables (params, adapter, resources)
+	// Create evaluation context with all CEL variables (params, adapter, resources),
+	// the apply summary of the resources phase set in resources
+	variables := execCtx.GetCELVariables()
+	if resources, ok := variables["resources"].(map[string]interface{}); ok {
+		maps.Copy(resources, execCtx.ResourceSummary().celValues())
+	}
 	evalCtx := criteria.NewEvaluationContext()
-	evalCtx.SetVariablesFromMap(execCtx.GetCELVariables())
+	evalCtx.SetVariablesFromMap(variables)
+	evalCtx.SetFlags(execCtx.flagLookup(ctx, log))
+	evalCtx.SetLookup(execCtx.lookupFunc())
 
-	evaluator, err := criteria.NewEvaluator(ctx, evalCtx, pae.log)
+	evaluator, err := criteria.NewEvaluator(ctx, evalCtx, log)
 	if err != nil {
 		return fmt.Errorf("failed to create evaluator: %w", err)
 	}
 
-	for _, payload := range payloads {
+	for i, payload := range payloads {
 		// Determine build source (inline Build or BuildRef)
 		var buildDef any
+		buildPath := fmt.Sprintf("post.payloads[%d].build", i)
 		switch {
 		case payload.Build != nil:
 			buildDef = payload.Build
 		case payload.BuildRefContent != nil:
 			buildDef = payload.BuildRefContent
+			buildPath = fmt.Sprintf("post.payloads[%d].build_ref", i)
 		default:
 			return fmt.Errorf("payload '%s' has neither Build nor BuildRefContent", payload.Name)
 		}
 
 		// Build the payload
-		builtPayload, err := pae.buildPayload(ctx, buildDef, evaluator, execCtx.Params)
+		// Snapshot per payload, so a payload can reference the payloads built before it
+		params := execCtx.templateParams()
+		report := &PayloadBuildReport{Payload: payload.Name}
+		builtPayload, err := (&payloadBuild{
+			ctx: ctx, log: log, evaluator: evaluator, params: params, report: report,
+		}).build(buildDef)
+		execCtx.AddPayloadReport(report)
 		if err != nil {
-			return fmt.Errorf("failed to build payload '%s': %w", payload.Name, err)
+			return fmt.Errorf("failed to build payload '%s': %w", payload.Name, execCtx.atStepPath(err, "%s", buildPath))
+		}
+
+		if payload.Conditions != nil {
+			builtMap, ok := builtPayload.(map[string]any)
+			if !ok {
+				return fmt.Errorf("payload '%s' has conditions but does not build a map", payload.Name)
+			}
+			conditions, err := buildStatusConditions(ctx, log, payload.Conditions, evaluator, params, execCtx.now())
+			if err != nil {
+				return fmt.Errorf("failed to build conditions of payload '%s': %w",
+					payload.Name, execCtx.atStepPath(err, "post.payloads[%d]", i))
+			}
+			builtMap[payload.Conditions.EffectiveKey()] = conditions
+		}
+
+		// Structured payloads stay maps, for bodies that reference them directly
+		// and for navigation in CEL expressions and templates
+		if payload.Structured {
+			execCtx.SetParam(payload.Name, builtPayload)
+			continue
 		}
 
 		// Convert to JSON for template rendering (templates will render maps as "map[...]" otherwise)
-		jsonBytes, err := json.Marshal(builtPayload)
+		var jsonBytes []byte
+		if payload.Canonical {
+			jsonBytes, err = canonicalJSON(builtPayload)
+		} else {
+			jsonBytes, err = json.Marshal(builtPayload)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to marshal payload '%s' to JSON: %w", payload.Name, err)
 		}
 
 		// Store as JSON string in params for use in post action templates
-		execCtx.Params[payload.Name] = string(jsonBytes)
+		execCtx.SetParam(payload.Name, string(jsonBytes))
 	}
 
 	return nil
@@ -131,106 +246,192 @@ func (pae *PostActionExecutor) buildPostPayloads(
 
 // buildPayload builds a payload from a build definition
 // The build definition can contain expressions that need to be evaluated
-func (pae *PostActionExecutor) buildPayload(
+func buildPayload(
 	ctx context.Context,
+	log logger.Logger,
 	build any,
 	evaluator *criteria.Evaluator,
 	params map[string]any,
 ) (any, error) {
-	switch v := build.(type) {
-	case map[string]any:
-		return pae.buildMapPayload(ctx, v, evaluator, params)
-	case map[any]any:
-		converted := convertToStringKeyMap(v)
-		return pae.buildMapPayload(ctx, converted, evaluator, params)
-	default:
-		return build, nil
-	}
+	return (&payloadBuild{ctx: ctx, log: log, evaluator: evaluator, params: params}).build(build)
 }
 
 // buildMapPayload builds a map payload, evaluating expressions as needed
-func (pae *PostActionExecutor) buildMapPayload(
+func buildMapPayload(
 	ctx context.Context,
+	log logger.Logger,
 	m map[string]any,
 	evaluator *criteria.Evaluator,
 	params map[string]any,
 ) (map[string]any, error) {
-	result := make(map[string]any)
+	return (&payloadBuild{ctx: ctx, log: log, evaluator: evaluator, params: params}).mapValue("", m)
+}
+
+// processValue processes a value, evaluating expressions as needed
+func processValue(
+	ctx context.Context,
+	log logger.Logger,
+	v any,
+	evaluator *criteria.Evaluator,
+	params map[string]any,
+) (any, error) {
+	value, _, err := (&payloadBuild{ctx: ctx, log: log, evaluator: evaluator, params: params}).value("", v)
+	return value, err
+}
+
+// payloadBuild evaluates a build definition. The value definitions whose field
+// or expression fails are handled by their on_error policy and recorded in
+// report, when set.
+type payloadBuild struct {
+	ctx       context.Context
+	log       logger.Logger
+	evaluator *criteria.Evaluator
+	params    map[string]any
+	report    *PayloadBuildReport
+}
+
+// build builds a payload from a build definition
+func (b *payloadBuild) build(build any) (any, error) {
+	switch v := build.(type) {
+	case map[string]any:
+		return b.mapValue("", v)
+	case map[any]any:
+		return b.mapValue("", convertToStringKeyMap(v))
+	default:
+		return build, nil
+	}
+}
+
+// mapValue builds the map at path, leaving out the keys of omitted values
+func (b *payloadBuild) mapValue(path string, m map[string]any) (map[string]any, error) {
+	result := make(map[string]any, len(m))
 
 	for k, v := range m {
 		// Render the key
-		renderedKey, err := renderTemplate(k, params)
+		renderedKey, err := renderTemplateAt(joinPayloadPath(path, k), k, b.params)
 		if err != nil {
 			return nil, fmt.Errorf("failed to render key '%s': %w", k, err)
 		}
 
 		// Process the value
-		processedValue, err := pae.processValue(ctx, v, evaluator, params)
+		processedValue, keep, err := b.value(joinPayloadPath(path, renderedKey), v)
 		if err != nil {
 			return nil, fmt.Errorf("failed to process value for key '%s': %w", k, err)
 		}
-
-		result[renderedKey] = processedValue
+		if keep {
+			result[renderedKey] = processedValue
+		}
 	}
 
 	return result, nil
 }
 
-// processValue processes a value, evaluating expressions as needed
-func (pae *PostActionExecutor) processValue(
-	ctx context.Context,
-	v any,
-	evaluator *criteria.Evaluator,
-	params map[string]any,
-) (any, error) {
+// value processes the value at path, evaluating expressions as needed. keep is
+// false for a value definition omitted by on_error: omit.
+func (b *payloadBuild) value(path string, v any) (value any, keep bool, err error) {
 	switch val := v.(type) {
 	case map[string]any:
 		// Check if this is a value definition: { field: "...", default: ... } or { expression: "...", default: ... }
 		if valueDef, ok := configloader.ParseValueDef(val); ok {
-			result, err := evaluator.ExtractValue(valueDef.Field, valueDef.Expression)
-			// err indicates parse error - fail fast (bug in config)
-			if err != nil {
-				return nil, err
-			}
-			// If value is nil (field not found or empty), use default
-			if result.Value == nil {
-				if valueDef.Default != nil {
-					pae.log.Debugf(ctx, "Using default value for '%s': %v", result.Source, valueDef.Default)
-				}
-				return valueDef.Default, nil
-			}
-			return result.Value, nil
+			return b.valueDef(path, valueDef)
 		}
 
 		// Recursively process nested maps
-		return pae.buildMapPayload(ctx, val, evaluator, params)
+		m, err := b.mapValue(path, val)
+		return m, true, err
 
 	case map[any]any:
-		converted := convertToStringKeyMap(val)
-		return pae.processValue(ctx, converted, evaluator, params)
+		return b.value(path, convertToStringKeyMap(val))
 
 	case []any:
-		result := make([]any, len(val))
+		result := make([]any, 0, len(val))
 		for i, item := range val {
-			processed, err := pae.processValue(ctx, item, evaluator, params)
+			processed, keep, err := b.value(fmt.Sprintf("%s[%d]", path, i), item)
 			if err != nil {
-				return nil, err
+				return nil, false, err
+			}
+			if keep {
+				result = append(result, processed)
 			}
-			result[i] = processed
 		}
-		return result, nil
+		return result, true, nil
 
 	case string:
-		return renderTemplate(val, params)
+		rendered, err := renderTemplateAt(path, val, b.params)
+		return rendered, true, err
+
+	default:
+		return v, true, nil
+	}
+}
+
+// valueDef evaluates the value definition at path. A field or expression that
+// fails to evaluate is handled by the on_error policy of the definition.
+func (b *payloadBuild) valueDef(path string, valueDef *configloader.ValueDef) (any, bool, error) {
+	value, source, evalErr, err := b.extract(valueDef)
+	// err indicates parse error - fail fast (bug in config)
+	if err != nil {
+		return nil, false, err
+	}
+	if evalErr != nil {
+		policy := valueDef.EffectiveOnError()
+		b.report.add(PayloadValueError{Path: path, Source: source, Policy: policy, Reason: evalErr.Error()})
+		b.log.Debugf(b.ctx, "Value '%s' failed to evaluate, on_error=%s: %v", path, policy, evalErr)
+		switch policy {
+		case configloader.OnErrorFail:
+			return nil, false, fmt.Errorf("'%s' failed to evaluate (on_error: fail): %w", source, evalErr)
+		case configloader.OnErrorOmit:
+			return nil, false, nil
+		case configloader.OnErrorNull:
+			return nil, true, nil
+		}
+	}
+	// If value is nil (field not found or empty), use default
+	if value == nil {
+		if valueDef.Default != nil {
+			b.log.Debugf(b.ctx, "Using default value for '%s': %v", source, valueDef.Default)
+		}
+		return valueDef.Default, true, nil
+	}
+	return value, true, nil
+}
 
+// extract evaluates the field or expression of a value definition. evalErr is
+// the evaluation error of a valid field or expression, e.g. a missing key,
+// while err is an invalid one.
+func (b *payloadBuild) extract(valueDef *configloader.ValueDef) (value any, source string, evalErr, err error) {
+	field := strings.TrimSpace(valueDef.Field)
+	expression := strings.TrimSpace(valueDef.Expression)
+	switch {
+	case field != "" && expression != "":
+		return nil, "", nil, fmt.Errorf("field and expression are mutually exclusive; only one should be specified")
+	case expression != "":
+		result, err := b.evaluator.EvaluateCEL(expression)
+		if err != nil {
+			return nil, expression, nil, fmt.Errorf("CEL evaluation failed: %w", err)
+		}
+		return result.Value, expression, result.Error, nil
 	default:
-		return v, nil
+		result, err := b.evaluator.EvaluateField(field)
+		if err != nil {
+			return nil, field, nil, fmt.Errorf("field extraction failed: %w", err)
+		}
+		return result.Value, field, result.Error, nil
 	}
 }
 
+// joinPayloadPath returns the path of key in the map at path
+func joinPayloadPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
 // executePostAction executes a single post-action
 func (pae *PostActionExecutor) executePostAction(
 	ctx context.Context,
+	log logger.Logger,
 	action configloader.PostAction,
 	execCtx *ExecutionContext,
 ) (PostActionResult, error) {
@@ -239,14 +440,71 @@ func (pae *PostActionExecutor) executePostAction(
 		Status: StatusSuccess,
 	}
 
+	enabled, err := enabledIf(ctx, log, action.EnabledIf, execCtx)
+	if err != nil {
+		result.Status = StatusFailed
+		result.Error = err
+		return result, NewExecutorError(PhasePostActions, ErrorCodeCELEvaluationError, action.Name,
+			"failed to evaluate enabled_if", err)
+	}
+	if !enabled {
+		log.Infof(ctx, "PostAction[%s] skipped: enabled_if is false", action.Name)
+		result.Skipped = true
+		result.SkipReason = SkipReasonDisabled
+		return result, nil
+	}
+
 	// Execute log action if configured
-	if action.Log != nil {
-		ExecuteLogAction(ctx, action.Log, execCtx, pae.log)
+	if action.Log != nil && pae.logSampler.sample(action.Name, action.Log.SampleEvery) {
+		ExecuteLogAction(ctx, action.Log, execCtx, log)
 	}
 
 	// Execute API call if configured
 	if action.APICall != nil {
-		if err := pae.executeAPICall(ctx, action.APICall, execCtx, &result); err != nil {
+		cacheKey, hash, err := unchangedKey(action, execCtx)
+		if err != nil {
+			result.Status = StatusFailed
+			result.Error = err
+			return result, NewExecutorError(PhasePostActions, ErrorCodeTemplateError, action.Name,
+				"failed to render skip_if_unchanged", err)
+		}
+		ttl := DefaultUnchangedTTL
+		if action.SkipIfUnchanged != nil && action.SkipIfUnchanged.TTL > 0 {
+			ttl = action.SkipIfUnchanged.TTL
+		}
+		if cacheKey != "" && pae.unchanged.unchanged(cacheKey, hash, ttl) {
+			log.Infof(ctx, "PostAction[%s] skipped: body unchanged since the last call", action.Name)
+			pae.recorder.RecordUnchangedPostAction(action.Name)
+			result.Skipped = true
+			result.SkipReason = SkipReasonUnchanged
+			return result, nil
+		}
+		if err := pae.executeAPICall(ctx, log, action.APICall, execCtx, &result); err != nil {
+			if cacheKey != "" {
+				pae.unchanged.invalidate(cacheKey)
+			}
+			if !pae.bufferFailedCall(ctx, log, action, execCtx, &result, err) {
+				return result, err
+			}
+		} else if cacheKey != "" {
+			pae.unchanged.store(cacheKey, hash)
+		}
+	}
+
+	// Execute Kubernetes patch if configured
+	if action.K8sPatch != nil {
+		if err := pae.executeK8sPatch(ctx, log, action.K8sPatch, execCtx, &result); err != nil {
+			result.Status = StatusFailed
+			result.Error = err
+			return result, err
+		}
+	}
+
+	// Deliver the webhook if configured
+	if action.Webhook != nil {
+		if err := pae.executeWebhook(ctx, log, action.Webhook, execCtx, &result); err != nil {
+			result.Status = StatusFailed
+			result.Error = err
 			return result, err
 		}
 	}
@@ -254,19 +512,110 @@ func (pae *PostActionExecutor) executePostAction(
 	return result, nil
 }
 
+// unchangedKey renders the skip_if_unchanged key of action, prefixed with the
+// action name so each action has its own entries, and hashes its rendered body.
+// It returns an empty key when the action has no skip_if_unchanged.
+func unchangedKey(action configloader.PostAction, execCtx *ExecutionContext) (string, string, error) {
+	if action.SkipIfUnchanged == nil {
+		return "", "", nil
+	}
+	params := execCtx.templateParams()
+	key, err := renderTemplateAt("skip_if_unchanged.key", action.SkipIfUnchanged.Key, params)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to render key: %w", err)
+	}
+	body, err := renderAPICallBody(action.APICall, params)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to render body: %w", atPath("api_call", err))
+	}
+	return action.Name + "/" + key, bodyHash(body.fingerprint), nil
+}
+
+// executeK8sPatch renders and applies a k8s_patch action through the transport client
+// and records the resourceVersion of the patched object in the result
+func (pae *PostActionExecutor) executeK8sPatch(
+	ctx context.Context,
+	log logger.Logger,
+	patch *configloader.K8sPatchAction,
+	execCtx *ExecutionContext,
+	result *PostActionResult,
+) error {
+	patcher, ok := pae.transportClient.(resourcePatcher)
+	if !ok {
+		return NewExecutorError(PhasePostActions, ErrorCodeTransportNotConfigured, result.Name,
+			"k8s_patch requires the kubernetes transport client", nil)
+	}
+
+	gvk, err := k8sclient.GVKFromKindAndAPIVersion(patch.Kind, patch.APIVersion)
+	if err != nil {
+		return NewExecutorError(PhasePostActions, ErrorCodeManifestInvalid, result.Name,
+			"invalid k8s_patch api_version", err)
+	}
+	params := execCtx.templateParams()
+	namespace, err := renderTemplateAt("k8s_patch.namespace", patch.Namespace, params)
+	if err != nil {
+		return NewExecutorError(PhasePostActions, ErrorCodeTemplateError, result.Name,
+			"failed to render k8s_patch namespace", err)
+	}
+	name, err := renderTemplateAt("k8s_patch.name", patch.Name, params)
+	if err != nil {
+		return NewExecutorError(PhasePostActions, ErrorCodeTemplateError, result.Name,
+			"failed to render k8s_patch name", err)
+	}
+	patchData, isJSON, err := renderBody(patch.Body, params)
+	if err = atPath("k8s_patch.body", err); err != nil {
+		return NewExecutorError(PhasePostActions, ErrorCodeTemplateError, result.Name,
+			"failed to render k8s_patch body", err)
+	}
+	if !isJSON {
+		// JSON is valid YAML, so this accepts both
+		patchData, err = yaml.YAMLToJSON(patchData)
+		if err != nil {
+			return NewExecutorError(PhasePostActions, ErrorCodeManifestInvalid, result.Name,
+				"k8s_patch body is not valid JSON or YAML", err)
+		}
+	}
+
+	log.Debugf(ctx, "Patching %s %s/%s (type=%s subresource=%s)",
+		gvk.Kind, namespace, name, patchTypes[patch.PatchType], patch.Subresource)
+	result.K8sPatchMade = true
+	patched, err := patcher.PatchResource(ctx, gvk, namespace, name, patchData, &k8sclient.PatchOptions{
+		PatchType:   patchTypes[patch.PatchType],
+		Subresource: patch.Subresource,
+	})
+	if err != nil {
+		return NewExecutorError(PhasePostActions, patchErrorCode(err), result.Name,
+			fmt.Sprintf("failed to patch %s %s/%s", gvk.Kind, namespace, name), err)
+	}
+	if patched != nil {
+		result.ResourceVersion = patched.GetResourceVersion()
+	}
+	return nil
+}
+
 // executeAPICall executes an API call and populates the result with response details
 func (pae *PostActionExecutor) executeAPICall(
 	ctx context.Context,
+	log logger.Logger,
 	apiCall *configloader.APICall,
 	execCtx *ExecutionContext,
 	result *PostActionResult,
 ) error {
-	resp, url, err := ExecuteAPICall(ctx, apiCall, execCtx, pae.apiClient, pae.log)
+	resp, url, record, err := executeTargetedAPICall(
+		ctx, PhasePostActions, result.Name, apiCall, execCtx, pae.apiClient, pae.recorder, log)
+	result.APITarget = record.Target
+	result.APICall = record
+	if _, ok := hyperfleetapi.IsUnknownTargetError(err); ok {
+		result.Status = StatusFailed
+		result.Error = err
+		return NewExecutorError(PhasePostActions, ErrorCodeAPITargetUnknown, result.Name, "API call failed", err)
+	}
 	result.APICallMade = true
 
-	// Capture response details if available (even if err != nil)
+	// Capture response details if available (even if err != nil); the body is
+	// the one retained by the API call record
 	if resp != nil {
-		result.APIResponse = resp.Body
+		result.APIResponse, result.APIResponseTruncated = record.ResponseBody, record.ResponseTruncated
 		result.HTTPStatus = resp.StatusCode
 	}
 
@@ -281,7 +630,7 @@ func (pae *PostActionExecutor) executeAPICall(
 			errorContext = "API call returned non-success status"
 		}
 
-		return NewExecutorError(PhasePostActions, result.Name, errorContext, validationErr)
+		return NewExecutorError(PhasePostActions, apiCallErrorCode(validationErr), result.Name, errorContext, validationErr)
 	}
 
 	return nil
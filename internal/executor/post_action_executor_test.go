@@ -3,19 +3,38 @@ package executor
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/criteria"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+	apierrors "github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/errors"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 )
 
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
 // testPAE creates a PostActionExecutor for tests
 func testPAE() *PostActionExecutor {
 	return newPostActionExecutor(&ExecutorConfig{
@@ -100,7 +119,7 @@ func TestBuildPayload(t *testing.T) {
 			evaluator, err := criteria.NewEvaluator(context.Background(), evalCtx, pae.log)
 			assert.NoError(t, err)
 
-			result, err := pae.buildPayload(context.Background(), tt.build, evaluator, tt.params)
+			result, err := buildPayload(context.Background(), pae.log, tt.build, evaluator, tt.params)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -179,7 +198,7 @@ func TestBuildMapPayload(t *testing.T) {
 			}
 			evaluator, err := criteria.NewEvaluator(context.Background(), evalCtx, pae.log)
 			require.NoError(t, err)
-			result, err := pae.buildMapPayload(context.Background(), tt.input, evaluator, tt.params)
+			result, err := buildMapPayload(context.Background(), pae.log, tt.input, evaluator, tt.params)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -289,7 +308,7 @@ func TestProcessValue(t *testing.T) {
 			}
 			evaluator, err := criteria.NewEvaluator(context.Background(), evalCtx, pae.log)
 			require.NoError(t, err)
-			result, err := pae.processValue(context.Background(), tt.value, evaluator, tt.params)
+			result, err := processValue(context.Background(), pae.log, tt.value, evaluator, tt.params)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -395,8 +414,6 @@ func TestPostActionExecutor_ExecuteAll(t *testing.T) {
 				Logger:    logger.NewTestLogger(),
 			})
 
-			evt := event.New()
-			evt.SetID("test-event")
 			execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
 
 			results, err := pae.ExecuteAll(
@@ -628,7 +645,9 @@ func TestExecuteAPICall(t *testing.T) {
 			}
 
 			execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
-			execCtx.Params = tt.params
+			for name, value := range tt.params {
+				execCtx.SetParam(name, value)
+			}
 
 			resp, url, err := ExecuteAPICall(
 				context.Background(),
@@ -661,6 +680,138 @@ func TestExecuteAPICall(t *testing.T) {
 	}
 }
 
+// TestExecuteAPICall_FormAndMultipartBodies verifies form and multipart bodies are
+// rendered and sent encoded, with sensitive values left out of the logged payload
+func TestExecuteAPICall_FormAndMultipartBodies(t *testing.T) {
+	var form map[string][]string
+	var files map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Content-Type"), hyperfleetapi.ContentTypeMultipart) {
+			require.NoError(t, r.ParseMultipartForm(1<<20))
+			form = r.MultipartForm.Value
+			files = make(map[string]string)
+			for name, headers := range r.MultipartForm.File {
+				f, err := headers[0].Open()
+				require.NoError(t, err)
+				content, err := io.ReadAll(f)
+				require.NoError(t, err)
+				files[name+"/"+headers[0].Filename] = string(content)
+			}
+		} else {
+			require.NoError(t, r.ParseForm())
+			form = r.PostForm
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client, err := hyperfleetapi.NewClient(logger.NewTestLogger(), hyperfleetapi.WithBaseURL(server.URL))
+	require.NoError(t, err)
+
+	execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+	execCtx.SetParam("clusterId", "cluster-1")
+	execCtx.SetParam("token", "s3cret")
+
+	t.Run("form", func(t *testing.T) {
+		_, _, err := ExecuteAPICall(context.Background(), &configloader.APICall{
+			Method:   http.MethodPost,
+			URL:      server.URL + "/legacy",
+			BodyType: configloader.BodyTypeForm,
+			FormFields: []configloader.FormField{
+				{Name: "cluster", Value: "{{ .clusterId }}"},
+				{Name: "token", Value: "{{ .token }}", Sensitive: true},
+			},
+		}, execCtx, client, logger.NewTestLogger())
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{"cluster": {"cluster-1"}, "token": {"s3cret"}}, form)
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		_, _, err := ExecuteAPICall(context.Background(), &configloader.APICall{
+			Method:   http.MethodPut,
+			URL:      server.URL + "/upload",
+			BodyType: configloader.BodyTypeMultipart,
+			Parts: []configloader.MultipartPart{
+				{Name: "cluster", Content: "{{ .clusterId }}"},
+				{Name: "kubeconfig", Filename: "kubeconfig.yaml", Content: "cluster: {{ .clusterId }}"},
+			},
+		}, execCtx, client, logger.NewTestLogger())
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{"cluster": {"cluster-1"}}, form)
+		assert.Equal(t, map[string]string{"kubeconfig/kubeconfig.yaml": "cluster: cluster-1"}, files)
+	})
+
+	t.Run("sensitive values are redacted from the payload description", func(t *testing.T) {
+		body, err := renderAPICallBody(&configloader.APICall{
+			BodyType: configloader.BodyTypeForm,
+			FormFields: []configloader.FormField{
+				{Name: "cluster", Value: "{{ .clusterId }}"},
+				{Name: "token", Value: "{{ .token }}", Sensitive: true},
+			},
+		}, execCtx.ParamsSnapshot())
+		require.NoError(t, err)
+		assert.Equal(t, "cluster=cluster-1&token=[REDACTED]", body.description)
+	})
+}
+
+func TestExecuteAPICall_BodyFromPayloads(t *testing.T) {
+	var body []byte
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		contentType = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client, err := hyperfleetapi.NewClient(logger.NewTestLogger(), hyperfleetapi.WithBaseURL(server.URL))
+	require.NoError(t, err)
+
+	pae := testPAE()
+	execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+	execCtx.SetParam("reason", "Install failed:\n\t\"quota exceeded\" in us-east-1")
+	require.NoError(t, pae.buildPostPayloads(context.Background(), pae.log, []configloader.Payload{
+		{Name: "statusPayload", Build: map[string]interface{}{
+			"status":  "False",
+			"message": map[string]interface{}{"expression": "reason"},
+			"details": map[string]interface{}{"region": "us-east-1", "retries": 1},
+		}},
+		{Name: "overridePayload", Structured: true, Build: map[string]interface{}{
+			"status":  "Unknown",
+			"details": map[string]interface{}{"retries": 2},
+		}},
+	}, execCtx))
+
+	call := func(apiCall configloader.APICall) error {
+		apiCall.Method, apiCall.URL = http.MethodPost, server.URL+"/status"
+		_, _, err := ExecuteAPICall(context.Background(), &apiCall, execCtx, client, logger.NewTestLogger())
+		return err
+	}
+
+	// Embedding the payload in a larger template breaks on its escaped characters
+	require.NoError(t, call(configloader.APICall{Body: `{"report": "{{ .statusPayload }}"}`}))
+	assert.False(t, json.Valid(body))
+
+	require.NoError(t, call(configloader.APICall{BodyFrom: "statusPayload"}))
+	assert.Equal(t, hyperfleetapi.ContentTypeJSON, contentType)
+	var sent map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &sent))
+	assert.Equal(t, "Install failed:\n\t\"quota exceeded\" in us-east-1", sent["message"])
+
+	require.NoError(t, call(configloader.APICall{BodyMerge: []string{"statusPayload", "overridePayload"}}))
+	sent = nil
+	require.NoError(t, json.Unmarshal(body, &sent))
+	assert.Equal(t, map[string]interface{}{
+		"status":  "Unknown",
+		"message": "Install failed:\n\t\"quota exceeded\" in us-east-1",
+		"details": map[string]interface{}{"region": "us-east-1", "retries": float64(2)},
+	}, sent, "later payloads win, nested objects are merged")
+	assert.Equal(t, map[string]interface{}{"retries": 2},
+		execCtx.ParamsSnapshot()["overridePayload"].(map[string]interface{})["details"],
+		"the payloads themselves are left as built")
+
+	err = call(configloader.APICall{BodyMerge: []string{"statusPayload", "missingPayload"}})
+	assert.ErrorContains(t, err, "payload 'missingPayload' was not built")
+}
+
 func TestBuildPostPayloads_WithResourceDiscoveryCELHelpers(t *testing.T) {
 	pae := testPAE()
 	execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
@@ -689,10 +840,10 @@ func TestBuildPostPayloads_WithResourceDiscoveryCELHelpers(t *testing.T) {
 		},
 	}
 
-	err := pae.buildPostPayloads(context.Background(), payloads, execCtx)
+	err := pae.buildPostPayloads(context.Background(), pae.log, payloads, execCtx)
 	require.NoError(t, err)
 
-	rawPayload, ok := execCtx.Params["inspectPayload"].(string)
+	rawPayload, ok := execCtx.ParamsSnapshot()["inspectPayload"].(string)
 	require.True(t, ok, "payload should be stored as json string in params")
 
 	var built map[string]interface{}
@@ -701,3 +852,614 @@ func TestBuildPostPayloads_WithResourceDiscoveryCELHelpers(t *testing.T) {
 	assert.Contains(t, built["resourceSnapshot"], `"manifestWork"`)
 	assert.Contains(t, built["resourceSnapshot"], `"clusterClaim"`)
 }
+
+func TestBuildPostPayloads_OnError(t *testing.T) {
+	missing := func(onError string, def any) map[string]interface{} {
+		m := map[string]interface{}{"expression": "cluster.missing", "default": def}
+		if onError != "" {
+			m["on_error"] = onError
+		}
+		return m
+	}
+	tests := []struct {
+		build      map[string]interface{}
+		want       map[string]interface{}
+		name       string
+		wantErr    string
+		wantErrors []PayloadValueError
+	}{
+		{
+			name:  "null by default",
+			build: map[string]interface{}{"value": missing("", nil)},
+			want:  map[string]interface{}{"value": nil},
+			wantErrors: []PayloadValueError{
+				{Path: "value", Source: "cluster.missing", Policy: configloader.OnErrorNull},
+			},
+		},
+		{
+			name:  "default when one is set",
+			build: map[string]interface{}{"value": missing("", "unknown")},
+			want:  map[string]interface{}{"value": "unknown"},
+			wantErrors: []PayloadValueError{
+				{Path: "value", Source: "cluster.missing", Policy: configloader.OnErrorDefault},
+			},
+		},
+		{
+			name:  "null overriding the default",
+			build: map[string]interface{}{"value": missing(configloader.OnErrorNull, "unknown")},
+			want:  map[string]interface{}{"value": nil},
+			wantErrors: []PayloadValueError{
+				{Path: "value", Source: "cluster.missing", Policy: configloader.OnErrorNull},
+			},
+		},
+		{
+			name: "omitted in nested maps and lists",
+			build: map[string]interface{}{
+				"name": map[string]interface{}{"field": "cluster.name"},
+				"spec": map[string]interface{}{
+					"region": missing(configloader.OnErrorOmit, nil),
+					"labels": []interface{}{
+						"first",
+						missing(configloader.OnErrorOmit, nil),
+						map[string]interface{}{"owner": map[string]interface{}{
+							"field": "cluster.owner", "on_error": configloader.OnErrorOmit,
+						}},
+					},
+				},
+			},
+			want: map[string]interface{}{
+				"name": "c1",
+				"spec": map[string]interface{}{"labels": []interface{}{"first", map[string]interface{}{}}},
+			},
+			wantErrors: []PayloadValueError{
+				{Path: "spec.labels[1]", Source: "cluster.missing", Policy: configloader.OnErrorOmit},
+				{Path: "spec.labels[2].owner", Source: "cluster.owner", Policy: configloader.OnErrorOmit},
+				{Path: "spec.region", Source: "cluster.missing", Policy: configloader.OnErrorOmit},
+			},
+		},
+		{
+			name:    "fail",
+			build:   map[string]interface{}{"spec": map[string]interface{}{"value": missing(configloader.OnErrorFail, nil)}},
+			wantErr: "'cluster.missing' failed to evaluate (on_error: fail)",
+			wantErrors: []PayloadValueError{
+				{Path: "spec.value", Source: "cluster.missing", Policy: configloader.OnErrorFail},
+			},
+		},
+		{
+			name:  "no error",
+			build: map[string]interface{}{"value": map[string]interface{}{"field": "cluster.name", "on_error": "fail"}},
+			want:  map[string]interface{}{"value": "c1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pae := testPAE()
+			execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+			execCtx.SetParam("cluster", map[string]interface{}{"name": "c1"})
+
+			err := pae.buildPostPayloads(context.Background(), pae.log, []configloader.Payload{
+				{Name: "statusPayload", Structured: true, Build: tt.build},
+			}, execCtx)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				built, _ := execCtx.GetParam("statusPayload")
+				assert.Equal(t, tt.want, built)
+			}
+
+			reports := execCtx.GetPayloadReports()
+			require.Len(t, reports, 1, "the report is recorded even when the build fails")
+			assert.Equal(t, "statusPayload", reports[0].Payload)
+			require.Len(t, reports[0].Errors, len(tt.wantErrors))
+			for i, want := range tt.wantErrors {
+				got := reports[0].Errors[i]
+				assert.NotEmpty(t, got.Reason)
+				got.Reason = ""
+				assert.Equal(t, want, got)
+			}
+		})
+	}
+}
+
+func TestExecutor_PayloadBuildFailure(t *testing.T) {
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		Params:  []configloader.Parameter{{Name: "clusterId", Source: "event.id", Required: true}},
+		Post: &configloader.PostConfig{
+			Payloads: []configloader.Payload{{
+				Name: "statusPayload",
+				Build: map[string]interface{}{
+					"region": map[string]interface{}{"expression": "clusterId.region", "on_error": "fail"},
+				},
+			}},
+		},
+	}
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+
+	result := exec.Execute(context.Background(), map[string]interface{}{"id": "cluster-1"})
+	assert.Equal(t, StatusFailed, result.Status)
+	assert.Equal(t, ErrorCodePayloadBuildFailed, ErrorCodeOf(result.Errors[PhasePostActions]))
+	require.Len(t, result.PayloadReports, 1)
+	require.Len(t, result.PayloadReports[0].Errors, 1)
+	assert.Equal(t, "region", result.PayloadReports[0].Errors[0].Path)
+	assert.Equal(t, configloader.OnErrorFail, result.PayloadReports[0].Errors[0].Policy)
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"payload_reports":[{"payload":"statusPayload","errors":[{"path":"region"`)
+}
+
+func TestExecuteK8sPatch(t *testing.T) {
+	cluster := &unstructured.Unstructured{}
+	cluster.SetAPIVersion("hyperfleet.io/v1")
+	cluster.SetKind("Cluster")
+	cluster.SetNamespace("clusters")
+	cluster.SetName("cluster-123")
+	cluster.SetResourceVersion("42")
+
+	postConfig := &configloader.PostConfig{
+		Payloads: []configloader.Payload{
+			{Name: "statusPatch", Build: map[string]interface{}{
+				"status": map[string]interface{}{"phase": "{{ .phase }}"},
+			}},
+		},
+		PostActions: []configloader.PostAction{
+			{
+				ActionBase: configloader.ActionBase{Name: "annotate"},
+				K8sPatch: &configloader.K8sPatchAction{
+					APIVersion: "hyperfleet.io/v1",
+					Kind:       "Cluster",
+					Namespace:  "clusters",
+					Name:       "{{ .clusterId }}",
+					Body:       "metadata:\n  annotations:\n    hyperfleet.io/last-event: \"{{ .eventId }}\"\n",
+				},
+			},
+			{
+				ActionBase: configloader.ActionBase{Name: "update-status"},
+				K8sPatch: &configloader.K8sPatchAction{
+					APIVersion:  "hyperfleet.io/v1",
+					Kind:        "Cluster",
+					Namespace:   "clusters",
+					Name:        "{{ .clusterId }}",
+					PatchType:   configloader.PatchTypeMerge,
+					Subresource: configloader.SubresourceStatus,
+					Body:        "{{ .statusPatch }}",
+				},
+			},
+		},
+	}
+
+	k8sClient := k8sclient.NewMockK8sClient()
+	k8sClient.PatchResourceResult = cluster
+	pae := newPostActionExecutor(&ExecutorConfig{
+		APIClient:       newMockAPIClient(),
+		TransportClient: k8sClient,
+		Logger:          logger.NewTestLogger(),
+	})
+	execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+	execCtx.SetParam("clusterId", "cluster-123")
+	execCtx.SetParam("eventId", "evt-1")
+	execCtx.SetParam("phase", "Ready")
+
+	results, err := pae.ExecuteAll(context.Background(), postConfig, execCtx)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.Equal(t, StatusSuccess, result.Status)
+		assert.True(t, result.K8sPatchMade)
+		assert.Equal(t, "42", result.ResourceVersion)
+	}
+
+	require.Len(t, k8sClient.Patches, 2)
+	annotate := k8sClient.Patches[0]
+	assert.Equal(t, "Cluster", annotate.GVK.Kind)
+	assert.Equal(t, "clusters", annotate.Namespace)
+	assert.Equal(t, "cluster-123", annotate.Name)
+	assert.JSONEq(t, `{"metadata":{"annotations":{"hyperfleet.io/last-event":"evt-1"}}}`, string(annotate.Data),
+		"the YAML body is sent as JSON")
+	assert.Equal(t, types.MergePatchType, annotate.Options.PatchType, "merge is the default patch type")
+	assert.Empty(t, annotate.Options.Subresource)
+
+	status := k8sClient.Patches[1]
+	assert.JSONEq(t, `{"status":{"phase":"Ready"}}`, string(status.Data), "the body is built from a payload")
+	assert.Equal(t, configloader.SubresourceStatus, status.Options.Subresource)
+}
+
+func TestExecuteK8sPatch_Failures(t *testing.T) {
+	gr := schema.GroupResource{Group: "hyperfleet.io", Resource: "clusters"}
+	patchAction := func(name string, continueOnError bool) configloader.PostAction {
+		return configloader.PostAction{
+			ActionBase:      configloader.ActionBase{Name: name},
+			ContinueOnError: continueOnError,
+			K8sPatch: &configloader.K8sPatchAction{
+				APIVersion: "hyperfleet.io/v1",
+				Kind:       "Cluster",
+				Name:       "cluster-123",
+				PatchType:  configloader.PatchTypeJSON,
+				Body:       `[{"op":"test","path":"/metadata/resourceVersion","value":"1"}]`,
+			},
+		}
+	}
+	logAction := configloader.PostAction{ActionBase: configloader.ActionBase{
+		Name: "log-done",
+		Log:  &configloader.LogAction{Message: "done"},
+	}}
+
+	tests := []struct {
+		transport       transportclient.TransportClient
+		name            string
+		wantCode        ErrorCode
+		postActions     []configloader.PostAction
+		expectedResults int
+		expectError     bool
+	}{
+		{
+			name: "conflict stops post actions",
+			transport: &k8sclient.MockK8sClient{
+				PatchResourceError: k8serrors.NewConflict(gr, "cluster-123", errors.New("modified")),
+			},
+			postActions:     []configloader.PostAction{patchAction("patch", false), logAction},
+			expectedResults: 1,
+			expectError:     true,
+			wantCode:        ErrorCodeApplyConflict,
+		},
+		{
+			name: "continue_on_error runs the remaining post actions",
+			transport: &k8sclient.MockK8sClient{
+				PatchResourceError: k8serrors.NewNotFound(gr, "cluster-123"),
+			},
+			postActions:     []configloader.PostAction{patchAction("patch", true), logAction},
+			expectedResults: 2,
+			expectError:     false,
+			wantCode:        ErrorCodePatchFailed,
+		},
+		{
+			name:            "transport without patch support",
+			transport:       nil,
+			postActions:     []configloader.PostAction{patchAction("patch", false)},
+			expectedResults: 1,
+			expectError:     true,
+			wantCode:        ErrorCodeTransportNotConfigured,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pae := newPostActionExecutor(&ExecutorConfig{
+				APIClient:       newMockAPIClient(),
+				TransportClient: tt.transport,
+				Logger:          logger.NewTestLogger(),
+			})
+			execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+
+			results, err := pae.ExecuteAll(context.Background(),
+				&configloader.PostConfig{PostActions: tt.postActions}, execCtx)
+			require.Len(t, results, tt.expectedResults)
+			assert.Equal(t, StatusFailed, results[0].Status)
+			assert.Equal(t, tt.wantCode, ErrorCodeOf(results[0].Error))
+
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, ErrorCodeOf(err))
+				require.NotNil(t, execCtx.GetExecutionError())
+				assert.Equal(t, tt.wantCode, execCtx.GetExecutionError().Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.Nil(t, execCtx.GetExecutionError(), "a continued failure does not fail the execution")
+			assert.Equal(t, StatusSuccess, results[1].Status)
+		})
+	}
+}
+
+// loadClusterStatusPayload loads the cluster_status payload build of testdata/payload,
+// decoded with yaml.v3 as the config loader does
+func loadClusterStatusPayload(tb testing.TB, structured bool) configloader.Payload {
+	tb.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "payload", "cluster_status.yaml"))
+	require.NoError(tb, err)
+	var build map[string]interface{}
+	require.NoError(tb, yaml.Unmarshal(data, &build))
+	return configloader.Payload{Name: "clusterStatusPayload", Build: build, Structured: structured}
+}
+
+// clusterStatusExecCtx returns an execution context with the params and resources
+// referenced by the cluster_status payload build
+func clusterStatusExecCtx() *ExecutionContext {
+	execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+	for name, value := range map[string]interface{}{
+		"adapter":     map[string]interface{}{"name": "cluster-adapter"},
+		"timestamp":   "2026-01-02T03:04:05Z",
+		"clusterId":   "cluster-123",
+		"clusterName": "prod-east",
+		"region":      "us-east-1",
+		"tier":        "premium",
+		"version":     "4.17.3",
+		"replicas":    int64(3),
+		"generation":  int64(7),
+		"nodes":       []interface{}{"node-a", "node-b", "node-c"},
+	} {
+		execCtx.SetParam(name, value)
+	}
+	execCtx.Resources["clusterNamespace"] = &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "cluster-123"},
+		"status":   map[string]interface{}{"phase": "Active"},
+	}}
+	return execCtx
+}
+
+// TestBuildPostPayloads_Golden pins the output of the payload building path,
+// so optimizations of it keep the built payloads byte for byte identical.
+// Structured payloads marshal to the same document as JSON string payloads.
+func TestBuildPostPayloads_Golden(t *testing.T) {
+	golden := filepath.Join("testdata", "payload", "cluster_status.golden")
+
+	t.Run("json", func(t *testing.T) {
+		pae := testPAE()
+		execCtx := clusterStatusExecCtx()
+		payload := loadClusterStatusPayload(t, false)
+
+		require.NoError(t, pae.buildPostPayloads(context.Background(), pae.log, []configloader.Payload{payload}, execCtx))
+		got, ok := execCtx.ParamsSnapshot()[payload.Name].(string)
+		require.True(t, ok, "payload should be stored as json string in params")
+
+		if *updateGolden {
+			require.NoError(t, os.WriteFile(golden, []byte(got+"\n"), 0o600))
+		}
+		want, err := os.ReadFile(golden)
+		require.NoError(t, err)
+		assert.Equal(t, strings.TrimSuffix(string(want), "\n"), got)
+	})
+
+	t.Run("structured", func(t *testing.T) {
+		pae := testPAE()
+		execCtx := clusterStatusExecCtx()
+		payload := loadClusterStatusPayload(t, true)
+
+		require.NoError(t, pae.buildPostPayloads(context.Background(), pae.log, []configloader.Payload{payload}, execCtx))
+		built, ok := execCtx.ParamsSnapshot()[payload.Name].(map[string]interface{})
+		require.True(t, ok, "structured payload should be stored as a map in params")
+
+		body, isJSON, err := renderBody("{{ .clusterStatusPayload }}", execCtx.ParamsSnapshot())
+		require.NoError(t, err)
+		assert.True(t, isJSON)
+		want, err := os.ReadFile(golden)
+		require.NoError(t, err)
+		assert.Equal(t, strings.TrimSuffix(string(want), "\n"), string(body))
+
+		rendered, err := renderTemplate("{{ .clusterStatusPayload.cluster.spec.network.dns.domain }}", execCtx.ParamsSnapshot())
+		require.NoError(t, err)
+		assert.Equal(t, "prod-east.example.com", rendered)
+		assert.Equal(t, int64(6), built["cluster"].(map[string]interface{})["spec"].(map[string]interface{})["replicas"])
+	})
+}
+
+func TestRenderBody(t *testing.T) {
+	params := map[string]interface{}{
+		"structured": map[string]interface{}{"message": "say \"hi\"\nbye"},
+		"list":       []interface{}{"a", 1},
+		"text":       `{"a":1}`,
+		"count":      3,
+	}
+	tests := []struct {
+		name   string
+		body   string
+		want   string
+		isJSON bool
+	}{
+		{name: "structured param", body: "{{ .structured }}", want: `{"message":"say \"hi\"\nbye"}`, isJSON: true},
+		{name: "trim markers and spaces", body: " {{- .list -}}\n", want: `["a",1]`, isJSON: true},
+		{name: "string param renders as text", body: "{{ .text }}", want: `{"a":1}`},
+		{name: "scalar param renders as text", body: "{{ .count }}", want: "3"},
+		{name: "larger template renders as text", body: `{"n": {{ .count }}}`, want: `{"n": 3}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, isJSON, err := renderBody(tt.body, params)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+			assert.Equal(t, tt.isJSON, isJSON)
+		})
+	}
+
+	_, _, err := renderBody("{{ .missing }}", params)
+	assert.Error(t, err)
+}
+
+// BenchmarkBuildPostPayloads measures building a realistic status payload per event,
+// stored as a JSON string or as a structured map
+func BenchmarkBuildPostPayloads(b *testing.B) {
+	for _, bm := range []struct {
+		name       string
+		structured bool
+	}{
+		{name: "json"},
+		{name: "structured", structured: true},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			pae := newPostActionExecutor(&ExecutorConfig{Logger: logger.NewTestLogger()})
+			payloads := []configloader.Payload{loadClusterStatusPayload(b, bm.structured)}
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := pae.buildPostPayloads(ctx, pae.log, payloads, clusterStatusExecCtx()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteAll_SkipIfUnchanged(t *testing.T) {
+	postConfig := &configloader.PostConfig{
+		PostActions: []configloader.PostAction{{
+			ActionBase: configloader.ActionBase{
+				Name: "reportStatus",
+				APICall: &configloader.APICall{
+					Method: "POST",
+					URL:    "/clusters/{{ .clusterId }}/statuses",
+					Body:   `{"cluster_id": "{{ .clusterId }}", "phase": "{{ .phase }}"}`,
+				},
+			},
+			SkipIfUnchanged: &configloader.SkipIfUnchanged{Key: "{{ .clusterId }}", TTL: 10 * time.Minute},
+		}},
+	}
+
+	fake := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	apiClient := newMockAPIClient()
+	registry := prometheus.NewRegistry()
+	pae := newPostActionExecutor(&ExecutorConfig{
+		APIClient:       apiClient,
+		Logger:          logger.NewTestLogger(),
+		MetricsRecorder: metrics.NewRecorder("test-adapter", "v0.1.0", registry),
+		Clock:           fake,
+	})
+	execute := func(clusterID, phase string) (PostActionResult, error) {
+		execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+		execCtx.SetParam("clusterId", clusterID)
+		execCtx.SetParam("phase", phase)
+		results, err := pae.ExecuteAll(context.Background(), postConfig, execCtx)
+		require.Len(t, results, 1)
+		return results[0], err
+	}
+
+	result, err := execute("cluster-1", "Ready")
+	require.NoError(t, err)
+	assert.True(t, result.APICallMade)
+	assert.False(t, result.Skipped)
+
+	result, err = execute("cluster-1", "Ready")
+	require.NoError(t, err)
+	assert.True(t, result.Skipped, "the body is unchanged")
+	assert.Equal(t, SkipReasonUnchanged, result.SkipReason)
+	assert.False(t, result.APICallMade)
+	assert.Len(t, apiClient.Requests, 1)
+
+	_, err = execute("cluster-2", "Ready")
+	require.NoError(t, err)
+	assert.Len(t, apiClient.Requests, 2, "each key has its own entry")
+
+	_, err = execute("cluster-1", "Provisioning")
+	require.NoError(t, err)
+	assert.Len(t, apiClient.Requests, 3, "a changed body is sent")
+
+	// A failed send invalidates the entry, so the retry is not suppressed
+	apiClient.PostError = errors.New("connection reset")
+	_, err = execute("cluster-1", "Ready")
+	require.Error(t, err)
+	apiClient.PostError = nil
+	result, err = execute("cluster-1", "Ready")
+	require.NoError(t, err)
+	assert.True(t, result.APICallMade, "the retry after a failure is sent")
+	assert.Len(t, apiClient.Requests, 5)
+
+	fake.Advance(10 * time.Minute)
+	result, err = execute("cluster-1", "Ready")
+	require.NoError(t, err)
+	assert.True(t, result.APICallMade, "the entry expired")
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var skipped float64
+	for _, family := range families {
+		if family.GetName() == "hyperfleet_adapter_post_actions_unchanged_total" {
+			skipped = family.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	assert.Equal(t, float64(1), skipped)
+}
+
+func TestExecuteAll_APICallRecord(t *testing.T) {
+	postConfig := &configloader.PostConfig{
+		PostActions: []configloader.PostAction{{
+			ActionBase: configloader.ActionBase{
+				Name: "reportStatus",
+				APICall: &configloader.APICall{
+					Method: "POST",
+					URL:    "/clusters/{{ .clusterId }}/statuses?token={{ .token }}&dry_run=false",
+					Body:   `{"cluster_id": "{{ .clusterId }}"}`,
+				},
+			},
+		}},
+	}
+
+	tests := []struct {
+		postResponse *hyperfleetapi.Response
+		postError    error
+		expected     APICallRecord
+		name         string
+		expectError  bool
+	}{
+		{
+			name: "response",
+			postResponse: &hyperfleetapi.Response{
+				StatusCode: http.StatusCreated,
+				Status:     "201 Created",
+				Headers:    map[string][]string{"X-Request-Id": {"req-42"}},
+				Body:       []byte(`{"id":"status-1"}`),
+				Attempts:   2,
+			},
+			expected: APICallRecord{
+				StatusCode:   http.StatusCreated,
+				Attempts:     2,
+				RequestID:    "req-42",
+				ResponseBody: []byte(`{"id":"status-1"}`),
+			},
+		},
+		{
+			name: "retries exhausted",
+			postError: apierrors.NewAPIError("POST", "/clusters/cluster-1/statuses", 0, "", nil, 3, 0,
+				errors.New("connection reset")),
+			expected:    APICallRecord{Attempts: 3},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiClient := newMockAPIClient()
+			apiClient.PostResponse = tt.postResponse
+			apiClient.PostError = tt.postError
+			pae := newPostActionExecutor(&ExecutorConfig{APIClient: apiClient, Logger: logger.NewTestLogger()})
+
+			execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+			execCtx.SetParam("clusterId", "cluster-1")
+			execCtx.SetParam("token", "s3cret")
+			results, err := pae.ExecuteAll(context.Background(), postConfig, execCtx)
+			if tt.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Len(t, results, 1)
+			require.Len(t, apiClient.Requests, 1)
+			assert.Contains(t, apiClient.Requests[0].URL, "token=s3cret", "the request itself carries the token")
+
+			record := results[0].APICall
+			require.NotNil(t, record)
+			assert.Equal(t, PhasePostActions, record.Phase)
+			assert.Equal(t, "reportStatus", record.Step)
+			assert.Equal(t, "POST", record.Method)
+			assert.Equal(t, hyperfleetapi.DefaultTarget, record.Target)
+			assert.Contains(t, record.URL, "/clusters/cluster-1/statuses?token=[REDACTED]&dry_run=false")
+			assert.NotContains(t, record.URL, "s3cret")
+			assert.Equal(t, tt.expected.StatusCode, record.StatusCode)
+			assert.Equal(t, tt.expected.Attempts, record.Attempts)
+			assert.Equal(t, tt.expected.RequestID, record.RequestID)
+			assert.Equal(t, tt.expected.ResponseBody, record.ResponseBody)
+			assert.Equal(t, tt.expectError, record.Error != "")
+
+			assert.Equal(t, []APICallRecord{*record}, execCtx.GetAPICalls(), "the execution aggregates the record")
+		})
+	}
+}
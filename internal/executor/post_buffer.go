@@ -0,0 +1,101 @@
+package executor
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/postbuffer"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// bufferFailedCall buffers the API call of action that failed with err, when
+// the action has buffer_on_failure, and marks result Buffered. It returns
+// false, leaving the failure to the caller, when the action does not buffer,
+// when a replay would fail the same way (a template error or a status that
+// is not retryable), or when the call cannot be buffered, e.g. because the
+// buffer is full or its disk is.
+func (pae *PostActionExecutor) bufferFailedCall(
+	ctx context.Context,
+	log logger.Logger,
+	action configloader.PostAction,
+	execCtx *ExecutionContext,
+	result *PostActionResult,
+	err error,
+) bool {
+	buffer := action.BufferOnFailure
+	if pae.buffer == nil || buffer == nil || !buffer.Enabled || result.APICall == nil || result.APICall.request == nil {
+		return false
+	}
+	if status := result.APICall.StatusCode; status != 0 && !(&hyperfleetapi.Response{StatusCode: status}).IsRetryable() {
+		return false
+	}
+	request := result.APICall.request
+	for _, field := range request.FormFields {
+		if field.Sensitive {
+			log.Warnf(ctx, "PostAction[%s] call not buffered: form field %q is sensitive", action.Name, field.Name)
+			return false
+		}
+	}
+	for _, part := range request.Parts {
+		if part.Sensitive {
+			log.Warnf(ctx, "PostAction[%s] call not buffered: multipart part %q is sensitive", action.Name, part.Name)
+			return false
+		}
+	}
+
+	key := execCtx.executionKey
+	if key == "" {
+		key = request.URL
+	}
+	call := postbuffer.Call{
+		Action:     action.Name,
+		Key:        key,
+		Target:     result.APICall.Target,
+		Method:     request.Method,
+		URL:        request.URL,
+		Headers:    bufferedHeaders(ctx, log, action.Name, request.Headers, execCtx),
+		Body:       request.Body,
+		FormFields: request.FormFields,
+		Parts:      request.Parts,
+		Timeout:    request.Timeout,
+		MaxAge:     buffer.MaxAge,
+		LastError:  err.Error(),
+	}
+	if putErr := pae.buffer.Put(ctx, call, buffer.MaxEntries); putErr != nil {
+		log.Warnf(logger.WithErrorField(ctx, putErr), "PostAction[%s] call could not be buffered", action.Name)
+		return false
+	}
+	log.Warnf(logger.WithErrorField(ctx, err), "PostAction[%s] call failed, buffered for replay", action.Name)
+	result.Status = StatusSuccess
+	result.Error = nil
+	result.Buffered = true
+	return true
+}
+
+// bufferedHeaders returns the headers of a buffered call: those carrying the
+// value of a redacted param, env-sourced or sensitive, are left out so no
+// secret is written to the buffer, and the replay is sent without them
+func bufferedHeaders(
+	ctx context.Context, log logger.Logger, action string, headers map[string]string, execCtx *ExecutionContext,
+) map[string]string {
+	var secrets []string
+	params := execCtx.ParamsSnapshot()
+	for _, param := range execCtx.Config.Params {
+		if value, ok := params[param.Name].(string); ok && value != "" && isRedactedParam(param) {
+			secrets = append(secrets, value)
+		}
+	}
+	buffered := make(map[string]string, len(headers))
+	for name, value := range headers {
+		if slices.ContainsFunc(secrets, func(secret string) bool { return strings.Contains(value, secret) }) {
+			log.Warnf(ctx, "PostAction[%s] buffered call: header %q carries a redacted param, replayed without it",
+				action, name)
+			continue
+		}
+		buffered[name] = value
+	}
+	return buffered
+}
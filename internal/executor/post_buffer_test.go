@@ -0,0 +1,140 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/journal"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/postbuffer"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/eventtest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fullStore is a journal.Store on a full disk
+type fullStore struct {
+	journal.Store
+}
+
+func (fullStore) Put(context.Context, journal.Entry) error {
+	return errors.New("no space left on device")
+}
+
+// newBufferTestExecutor creates an executor whose report post action buffers
+// its failed calls in a buffer on store
+func newBufferTestExecutor(
+	t *testing.T, store journal.Store,
+) (*Executor, *postbuffer.Buffer, *hyperfleetapi.MockClient) {
+	t.Setenv("JOURNAL_TEST_TOKEN", "s3cret")
+	config := journalTestConfig()
+	config.Post.PostActions[0].BufferOnFailure = &configloader.BufferOnFailure{Enabled: true}
+	config.Post.PostActions[0].APICall.Headers = []configloader.Header{
+		{Name: "Authorization", Value: "Bearer {{ .token }}"},
+		{Name: "X-Cluster", Value: "{{ .clusterId }}"},
+	}
+
+	apiClient := newMockAPIClient()
+	apiClient.GetResponse = &hyperfleetapi.Response{StatusCode: 200, Status: "200 OK", Body: []byte(`{"generation":3}`)}
+	buffer, err := postbuffer.New(postbuffer.Config{Store: store, Client: apiClient}, logger.NewTestLogger())
+	require.NoError(t, err)
+	// Replays are driven by the test
+	buffer.Close()
+
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(apiClient).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		WithPostBuffer(buffer).
+		Build()
+	require.NoError(t, err)
+	return exec, buffer, apiClient
+}
+
+func TestPostBuffer_ReplaysAfterOutage(t *testing.T) {
+	store, err := journal.NewFileStore(t.TempDir(), journal.Limits{}, logger.NewTestLogger())
+	require.NoError(t, err)
+	exec, buffer, apiClient := newBufferTestExecutor(t, store)
+
+	// The status endpoint is down
+	apiClient.PostError = errors.New("connection refused")
+	evt := eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build()
+	result := exec.ExecuteEvent(context.Background(), evt)
+
+	require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+	require.Len(t, result.PostActionResults, 1)
+	assert.True(t, result.PostActionResults[0].Buffered)
+	assert.Equal(t, StatusSuccess, result.PostActionResults[0].Status)
+	entries, err := store.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.NotContains(t, string(entries[0].Data), "Bearer s3cret", "a redacted header is not buffered")
+
+	// It recovers
+	apiClient.PostError = nil
+	replayed, err := buffer.Replay(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+	replay := apiClient.GetLastRequest()
+	assert.Equal(t, "POST", replay.Method)
+	assert.Equal(t, "/clusters/cluster-1/statuses", replay.URL)
+	assert.JSONEq(t, `{"generation":3,"configMap":"cm-cluster-1","token":"s3cret"}`, string(replay.Body))
+	assert.Equal(t, "cluster-1", replay.Headers["X-Cluster"])
+	assert.NotContains(t, replay.Headers, "Authorization")
+
+	entries, err = store.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestPostBuffer_NotBuffered(t *testing.T) {
+	tests := []struct {
+		name  string
+		store func(t *testing.T) journal.Store
+		post  func(apiClient *hyperfleetapi.MockClient)
+	}{
+		{
+			name: "disk full",
+			store: func(t *testing.T) journal.Store {
+				store, err := journal.NewFileStore(t.TempDir(), journal.Limits{}, logger.NewTestLogger())
+				require.NoError(t, err)
+				return fullStore{Store: store}
+			},
+			post: func(apiClient *hyperfleetapi.MockClient) {
+				apiClient.PostError = errors.New("connection refused")
+			},
+		},
+		{
+			name: "status not retryable",
+			store: func(t *testing.T) journal.Store {
+				store, err := journal.NewFileStore(t.TempDir(), journal.Limits{}, logger.NewTestLogger())
+				require.NoError(t, err)
+				return store
+			},
+			post: func(apiClient *hyperfleetapi.MockClient) {
+				apiClient.PostResponse = &hyperfleetapi.Response{StatusCode: 400, Status: "400 Bad Request"}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec, _, apiClient := newBufferTestExecutor(t, tt.store(t))
+			tt.post(apiClient)
+
+			evt := eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build()
+			result := exec.ExecuteEvent(context.Background(), evt)
+
+			require.Equal(t, StatusFailed, result.Status)
+			require.Len(t, result.PostActionResults, 1)
+			assert.False(t, result.PostActionResults[0].Buffered)
+			assert.Equal(t, StatusFailed, result.PostActionResults[0].Status)
+			assert.Error(t, result.Errors[PhasePostActions])
+		})
+	}
+}
@@ -3,66 +3,125 @@ package executor
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/criteria"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
 )
 
 // PreconditionExecutor evaluates preconditions
 type PreconditionExecutor struct {
 	apiClient hyperfleetapi.Client
+	recorder  *metrics.Recorder
 	log       logger.Logger
+	// logSampler samples log actions with sample_every across executions
+	logSampler *logSampler
+	// collectAll runs the remaining preconditions after an execution error
+	collectAll bool
+	// concurrency is the number of independent preconditions run at once
+	concurrency int
 }
 
 // newPreconditionExecutor creates a new precondition executor
 // NOTE: Caller (NewExecutor) is responsible for config validation
 func newPreconditionExecutor(config *ExecutorConfig) *PreconditionExecutor {
 	return &PreconditionExecutor{
-		apiClient: config.APIClient,
-		log:       config.Logger,
+		apiClient:   config.APIClient,
+		recorder:    config.MetricsRecorder,
+		log:         config.Logger,
+		collectAll:  config.Config.PreconditionErrorPolicy == configloader.PreconditionErrorsCollectAll,
+		logSampler:  newLogSampler(),
+		concurrency: config.Config.PreconditionConcurrency,
 	}
 }
 
-// ExecuteAll executes all preconditions in sequence
-// Returns a high-level outcome with match status and individual results
+// ExecuteAll executes the preconditions level by level, in the order of levels
+// (see configloader.PreconditionLevels), or in config order when levels is nil.
+// Up to precondition_concurrency preconditions of a level run at once; their
+// outcomes are handled in config order.
+// An execution error stops the phase, unless the collectAll error policy is
+// configured: then the remaining preconditions still run and all errors are
+// aggregated. An unmet precondition always stops the phase.
 func (pe *PreconditionExecutor) ExecuteAll(
 	ctx context.Context,
 	preconditions []configloader.Precondition,
+	levels [][]int,
 	execCtx *ExecutionContext,
 ) *PreconditionsOutcome {
 	results := make([]PreconditionResult, 0, len(preconditions))
+	var errs []error
+	var failed []string
 
-	for _, precond := range preconditions {
-		result, err := pe.executePrecondition(ctx, precond, execCtx)
-		results = append(results, result)
+	for _, batch := range pe.batches(len(preconditions), levels) {
+		if cancelErr := cancelled(ctx, PhasePreconditions, len(results)-1); cancelErr != nil {
+			return &PreconditionsOutcome{Results: results, Error: cancelErr}
+		}
+		batchResults, batchErrs := pe.executeBatch(ctx, preconditions, batch, execCtx)
+
+		stop := false
+		for k, i := range batch {
+			precond := preconditions[i]
+			result, err := batchResults[k], batchErrs[k]
+			results = append(results, result)
+			if stop {
+				// Ran concurrently with the precondition that stopped the phase
+				continue
+			}
+			log := stepLogger(pe.log, PhasePreconditions, precond.Name)
 
-		if err != nil {
-			// Execution error (API call failed, parse error, etc.)
-			errCtx := logger.WithErrorField(ctx, err)
-			pe.log.Errorf(errCtx, "Precondition[%s] evaluated: FAILED", precond.Name)
-			return &PreconditionsOutcome{
-				AllMatched: false,
-				Results:    results,
-				Error:      err,
+			if err != nil {
+				// A call interrupted by the cancellation is not a failure of the precondition
+				if cancelErr := cancelled(ctx, PhasePreconditions, len(results)-2); cancelErr != nil {
+					return &PreconditionsOutcome{Results: results, Error: cancelErr}
+				}
+				// Execution error (API call failed, parse error, etc.)
+				errCtx := logger.WithErrorField(ctx, err)
+				log.Errorf(errCtx, "Precondition[%s] evaluated: FAILED - %s", precond.Name, formatConditionDetails(result))
+				errs = append(errs, err)
+				failed = append(failed, precond.Name)
+				stop = !pe.collectAll
+				continue
 			}
-		}
 
-		if !result.Matched {
-			// Business outcome: precondition not satisfied
-			pe.log.Infof(ctx, "Precondition[%s] evaluated: NOT_MET - %s", precond.Name, formatConditionDetails(result))
-			return &PreconditionsOutcome{
-				AllMatched:   false,
-				Results:      results,
-				Error:        nil,
-				NotMetReason: fmt.Sprintf("precondition '%s' not met: %s", precond.Name, formatConditionDetails(result)),
+			if !result.Matched {
+				// Business outcome: precondition not satisfied
+				log.Infof(ctx, "Precondition[%s] evaluated: NOT_MET - %s", precond.Name, formatConditionDetails(result))
+				pe.recorder.RecordPreconditionNotMet(precond.Name, precond.ReasonLabel)
+				if len(errs) > 0 {
+					// Execution errors of earlier preconditions take precedence
+					stop = true
+					continue
+				}
+				results = append(results, batchResults[k+1:]...)
+				return &PreconditionsOutcome{
+					AllMatched:   false,
+					Results:      results,
+					Error:        nil,
+					NotMetReason: fmt.Sprintf("precondition '%s' not met: %s", precond.Name, formatConditionDetails(result)),
+					RetryAfter:   precond.RetryAfter,
+				}
 			}
+
+			log.Infof(ctx, "Precondition[%s] evaluated: MET", precond.Name)
+		}
+		if stop {
+			break
 		}
+	}
 
-		pe.log.Infof(ctx, "Precondition[%s] evaluated: MET", precond.Name)
+	if len(errs) > 0 {
+		return &PreconditionsOutcome{
+			AllMatched: false,
+			Results:    results,
+			Error:      joinPreconditionErrors(failed, errs),
+			Errors:     errs,
+		}
 	}
 
 	// All preconditions matched
@@ -73,9 +132,61 @@ func (pe *PreconditionExecutor) ExecuteAll(
 	}
 }
 
+// batches splits the levels of n preconditions into the batches that run at
+// once: a single precondition without precondition_concurrency
+func (pe *PreconditionExecutor) batches(n int, levels [][]int) [][]int {
+	if levels == nil {
+		levels = [][]int{make([]int, n)}
+		for i := range levels[0] {
+			levels[0][i] = i
+		}
+	}
+	size := max(pe.concurrency, 1)
+	var batches [][]int
+	for _, level := range levels {
+		for start := 0; start < len(level); start += size {
+			batches = append(batches, level[start:min(start+size, len(level))])
+		}
+	}
+	return batches
+}
+
+// executeBatch executes the preconditions of a batch, concurrently when there
+// are several, and returns their results and errors in batch order
+func (pe *PreconditionExecutor) executeBatch(
+	ctx context.Context,
+	preconditions []configloader.Precondition,
+	batch []int,
+	execCtx *ExecutionContext,
+) ([]PreconditionResult, []error) {
+	results := make([]PreconditionResult, len(batch))
+	errs := make([]error, len(batch))
+	execute := func(k int) {
+		precond := preconditions[batch[k]]
+		log := stepLogger(pe.log, PhasePreconditions, precond.Name)
+		started := execCtx.now()
+		stepCtx := execCtx.stepContext(ctx, PhasePreconditions, precond.Name)
+		results[k], errs[k] = pe.executePrecondition(stepCtx, log, precond, execCtx)
+		errs[k] = execCtx.atStepPath(errs[k], "preconditions[%d]", batch[k])
+		results[k].StartedAt, results[k].Duration = started, execCtx.now().Sub(started)
+		results[k].RetryWait = execCtx.retryWait(PhasePreconditions, precond.Name)
+	}
+	if len(batch) == 1 {
+		execute(0)
+		return results, errs
+	}
+	var wg sync.WaitGroup
+	for k := range batch {
+		wg.Go(func() { execute(k) })
+	}
+	wg.Wait()
+	return results, errs
+}
+
 // executePrecondition executes a single precondition
 func (pe *PreconditionExecutor) executePrecondition(
 	ctx context.Context,
+	log logger.Logger,
 	precond configloader.Precondition,
 	execCtx *ExecutionContext,
 ) (PreconditionResult, error) {
@@ -86,76 +197,115 @@ func (pe *PreconditionExecutor) executePrecondition(
 	}
 
 	// Step 1: Execute log action if configured
-	if precond.Log != nil {
-		ExecuteLogAction(ctx, precond.Log, execCtx, pe.log)
+	if precond.Log != nil && pe.logSampler.sample(precond.Name, precond.Log.SampleEvery) {
+		ExecuteLogAction(ctx, precond.Log, execCtx, log)
 	}
 
 	// Step 2: Make API call if configured
 	if precond.APICall != nil {
-		apiResult, err := pe.executeAPICall(ctx, precond.APICall, execCtx)
+		resp, record, err := pe.executeAPICall(ctx, log, precond.Name, precond.APICall, execCtx)
+		result.APITarget = record.Target
+		result.APICall = record
 		if err != nil {
 			result.Status = StatusFailed
 			result.Error = err
 
+			pe.recorder.RecordPreconditionAPIFailure(precond.Name)
+
 			// Set ExecutionError for API call failure
-			execCtx.Adapter.ExecutionError = &ExecutionError{
+			code := apiCallErrorCode(err)
+			execCtx.SetExecutionError(&ExecutionError{
 				Phase:   string(PhasePreconditions),
 				Step:    precond.Name,
 				Message: err.Error(),
-			}
+				Code:    code,
+			})
 
-			return result, NewExecutorError(PhasePreconditions, precond.Name, "API call failed", err)
+			return result, NewExecutorError(PhasePreconditions, code, precond.Name, "API call failed", err)
 		}
 		result.APICallMade = true
-		result.APIResponse = apiResult
+		// The body retained by the API call record
+		result.APIResponse, result.APIResponseTruncated = record.ResponseBody, record.ResponseTruncated
 
 		// Parse response as JSON
 		var responseData map[string]interface{}
-		if err := json.Unmarshal(apiResult, &responseData); err != nil {
+		if err := json.Unmarshal(resp.Body, &responseData); err != nil {
 			result.Status = StatusFailed
 			result.Error = fmt.Errorf("failed to parse API response as JSON: %w", err)
 
 			// Set ExecutionError for parse failure
-			execCtx.Adapter.ExecutionError = &ExecutionError{
+			execCtx.SetExecutionError(&ExecutionError{
 				Phase:   string(PhasePreconditions),
 				Step:    precond.Name,
 				Message: err.Error(),
+				Code:    ErrorCodeAPIResponseInvalid,
+			})
+
+			return result, NewExecutorError(PhasePreconditions, ErrorCodeAPIResponseInvalid, precond.Name,
+				"failed to parse API response", err)
+		}
+
+		// The shape of the response is asserted before its transform and captures
+		if precond.Expect != nil {
+			if err := pe.checkResponseContract(ctx, log, precond, responseData); err != nil {
+				result.Status = StatusFailed
+				result.Error = err
+				execCtx.SetExecutionError(&ExecutionError{
+					Phase:   string(PhasePreconditions),
+					Step:    precond.Name,
+					Message: err.Error(),
+					Code:    ErrorCodeAPIContractViolation,
+				})
+				return result, err
 			}
+		}
 
-			return result, NewExecutorError(PhasePreconditions, precond.Name, "failed to parse API response", err)
+		// The transformed response replaces the response for captures and
+		// conditions; the retained body stays the raw one
+		var response interface{} = responseData
+		if precond.APICall.Transform != "" {
+			transformed, err := transformResponse(ctx, log, precond.APICall.Transform, responseData, execCtx.lookupFunc())
+			if err != nil {
+				result.Status = StatusFailed
+				result.Error = err
+				code := celErrorCode(err)
+				execCtx.SetExecutionError(&ExecutionError{
+					Phase:   string(PhasePreconditions),
+					Step:    precond.Name,
+					Message: err.Error(),
+					Code:    code,
+				})
+				return result, NewExecutorError(PhasePreconditions, code, precond.Name,
+					"response transform failed", err)
+			}
+			response = transformed
 		}
 
 		// Store full response under precondition name for condition digging
 		// e.g., conditions can access "check-cluster.status.conditions"
-		execCtx.Params[precond.Name] = responseData
+		execCtx.setRawResponse(precond.Name, response)
+
+		// Store the whole response under its capture_response_as name, within the
+		// limit on retained responses
+		if precond.CaptureResponseAs != "" {
+			if limit := retainedResponseLimit(execCtx); len(resp.Body) > limit {
+				err := NewExecutorError(PhasePreconditions, ErrorCodeAPIResponseTooLarge, precond.Name,
+					fmt.Sprintf("response of %d bytes is over the %d byte limit of capture_response_as '%s'",
+						len(resp.Body), limit, precond.CaptureResponseAs), nil)
+				result.Status = StatusFailed
+				result.Error = err
+				return result, err
+			}
+			result.CapturedFields[precond.CaptureResponseAs] = response
+			execCtx.SetParam(precond.CaptureResponseAs, response)
+		}
 
 		// Capture fields from response
 		if len(precond.Capture) > 0 {
-			pe.log.Debugf(ctx, "Capturing %d fields from API response", len(precond.Capture))
-
-			// Create evaluator with response data only
-			// Both field (JSONPath) and expression (CEL) work on the same source
-			captureCtx := criteria.NewEvaluationContext()
-			captureCtx.SetVariablesFromMap(responseData)
-
-			captureEvaluator, evalErr := criteria.NewEvaluator(ctx, captureCtx, pe.log)
-			if evalErr != nil {
-				pe.log.Warnf(ctx, "Failed to create capture evaluator: %v", evalErr)
-			} else {
-				for _, capture := range precond.Capture {
-					extractResult, err := captureEvaluator.ExtractValue(capture.Field, capture.Expression)
-					if err != nil {
-						return result, err
-					}
-					// Error is not nil when there is field missing that is not a bug, but a valid use case
-					if extractResult.Error != nil {
-						pe.log.Warnf(ctx, "Failed to capture '%s' with error: %v", capture.Name, extractResult.Error)
-						continue
-					}
-					result.CapturedFields[capture.Name] = extractResult.Value
-					execCtx.Params[capture.Name] = extractResult.Value
-					pe.log.Debugf(ctx, "Captured %s = %v (from %s)", capture.Name, extractResult.Value, extractResult.Source)
-				}
+			if err := captureFields(ctx, log, precond, resp.Headers, response, &result, execCtx); err != nil {
+				result.Status = StatusFailed
+				result.Error = err
+				return result, err
 			}
 		}
 	}
@@ -165,25 +315,29 @@ func (pe *PreconditionExecutor) executePrecondition(
 	// Note: resources will be empty during preconditions since they haven't been created yet
 	evalCtx := criteria.NewEvaluationContext()
 	evalCtx.SetVariablesFromMap(execCtx.GetCELVariables())
+	evalCtx.SetFlags(execCtx.flagLookup(ctx, log))
+	evalCtx.SetLookup(execCtx.lookupFunc())
 
-	evaluator, err := criteria.NewEvaluator(ctx, evalCtx, pe.log)
+	evaluator, err := criteria.NewEvaluator(ctx, evalCtx, log)
 	if err != nil {
 		result.Status = StatusFailed
 		result.Error = err
-		return result, NewExecutorError(PhasePreconditions, precond.Name, "failed to create evaluator", err)
+		return result, NewExecutorError(PhasePreconditions, ErrorCodeInternal, precond.Name,
+			"failed to create evaluator", err)
 	}
 
 	// Evaluate using structured conditions or CEL expression
 	switch {
 	case len(precond.Conditions) > 0:
-		pe.log.Debugf(ctx, "Evaluating %d structured conditions", len(precond.Conditions))
+		log.Debugf(ctx, "Evaluating %d structured conditions", len(precond.Conditions))
 		condDefs := ToConditionDefs(precond.Conditions)
 
 		condResult, err := evaluator.EvaluateConditions(condDefs)
 		if err != nil {
 			result.Status = StatusFailed
 			result.Error = err
-			return result, NewExecutorError(PhasePreconditions, precond.Name, "condition evaluation failed", err)
+			return result, NewExecutorError(PhasePreconditions, ErrorCodeConditionEvaluationError, precond.Name,
+				"condition evaluation failed", err)
 		}
 
 		result.Matched = condResult.Matched
@@ -192,9 +346,9 @@ func (pe *PreconditionExecutor) executePrecondition(
 		// Log individual condition results
 		for _, cr := range condResult.Results {
 			if cr.Matched {
-				pe.log.Debugf(ctx, "Condition: %s %s %v = %v (matched)", cr.Field, cr.Operator, cr.ExpectedValue, cr.FieldValue)
+				log.Debugf(ctx, "Condition: %s %s %v = %v (matched)", cr.Field, cr.Operator, cr.ExpectedValue, cr.FieldValue)
 			} else {
-				pe.log.Debugf(ctx, "Condition: %s %s %v = %v (not matched)", cr.Field, cr.Operator, cr.ExpectedValue, cr.FieldValue)
+				log.Debugf(ctx, "Condition: %s %s %v = %v (not matched)", cr.Field, cr.Operator, cr.ExpectedValue, cr.FieldValue)
 			}
 		}
 
@@ -206,43 +360,178 @@ func (pe *PreconditionExecutor) executePrecondition(
 		execCtx.AddConditionsEvaluation(PhasePreconditions, precond.Name, condResult.Matched, fieldResults)
 	case precond.Expression != "":
 		// Evaluate CEL expression
-		pe.log.Debugf(ctx, "Evaluating CEL expression: %s", strings.TrimSpace(precond.Expression))
+		log.Debugf(ctx, "Evaluating CEL expression: %s", strings.TrimSpace(precond.Expression))
 		celResult, err := evaluator.EvaluateCEL(strings.TrimSpace(precond.Expression))
 		if err != nil {
 			result.Status = StatusFailed
 			result.Error = err
-			return result, NewExecutorError(PhasePreconditions, precond.Name, "CEL expression evaluation failed", err)
+			return result, NewExecutorError(PhasePreconditions, celErrorCode(err), precond.Name,
+				"CEL expression evaluation failed", err)
 		}
 
 		result.Matched = celResult.Matched
 		result.CELResult = celResult
-		pe.log.Debugf(ctx, "CEL result: matched=%v value=%v", celResult.Matched, celResult.Value)
+		log.Debugf(ctx, "CEL result: matched=%v value=%v", celResult.Matched, celResult.Value)
 
 		// Record CEL evaluation in execution context
 		execCtx.AddCELEvaluation(PhasePreconditions, precond.Name, precond.Expression, celResult.Matched)
 	default:
 		// No conditions specified - consider it matched
-		pe.log.Debugf(ctx, "No conditions specified, auto-matched")
+		log.Debugf(ctx, "No conditions specified, auto-matched")
 		result.Matched = true
 	}
 
 	return result, nil
 }
 
-// executeAPICall executes an API call and returns the response body for field capture
+// transformResponse evaluates the transform expression of an API call over the
+// parsed response, bound to the response variable, with the maps of lookup
+func transformResponse(
+	ctx context.Context, log logger.Logger, transform string, responseData map[string]interface{},
+	lookup criteria.MapLookup,
+) (interface{}, error) {
+	evalCtx := criteria.NewEvaluationContext()
+	evalCtx.Set("response", responseData)
+	evalCtx.SetLookup(lookup)
+	evaluator, err := criteria.NewEvaluator(ctx, evalCtx, log)
+	if err != nil {
+		return nil, err
+	}
+	celResult, err := evaluator.EvaluateCEL(strings.TrimSpace(transform))
+	if err != nil {
+		return nil, err
+	}
+	if celResult.Error != nil {
+		return nil, celResult.Error
+	}
+	return criteria.NativeValue(celResult.Value), nil
+}
+
+// captureFields captures the fields of precond from the response headers and body
+// into the captured fields of result and the params of execCtx. A capture without
+// a value takes its default, fails if required, and is skipped otherwise. The
+// fields of an object response are variables of the capture expressions; a
+// response transformed into another value, e.g. a list, is the response variable.
+func captureFields(
+	ctx context.Context,
+	log logger.Logger,
+	precond configloader.Precondition,
+	headers map[string][]string,
+	response interface{},
+	result *PreconditionResult,
+	execCtx *ExecutionContext,
+) error {
+	log.Debugf(ctx, "Capturing %d fields from API response", len(precond.Capture))
+
+	// Create evaluator with response data only
+	// Both field (JSONPath) and expression (CEL) work on the same source
+	captureCtx := criteria.NewEvaluationContext()
+	captureCtx.SetLookup(execCtx.lookupFunc())
+	if responseData, ok := response.(map[string]interface{}); ok {
+		captureCtx.SetVariablesFromMap(responseData)
+	} else {
+		captureCtx.Set("response", response)
+	}
+	captureEvaluator, evalErr := criteria.NewEvaluator(ctx, captureCtx, log)
+	if evalErr != nil {
+		log.Warnf(ctx, "Failed to create capture evaluator: %v", evalErr)
+	}
+
+	for _, capture := range precond.Capture {
+		var value interface{}
+		var missing error
+		source := capture.Source
+		switch {
+		case capture.Source == configloader.CaptureSourceHeader:
+			source = "header " + capture.Header
+			var ok bool
+			if value, ok = headerValue(headers, capture.Header); !ok {
+				missing = fmt.Errorf("response has no header %q", capture.Header)
+			}
+		case evalErr != nil:
+			continue
+		default:
+			extractResult, err := captureEvaluator.ExtractValue(capture.Field, capture.Expression)
+			if err != nil {
+				return err
+			}
+			// Error is not nil when there is field missing that is not a bug, but a valid use case
+			value, missing, source = extractResult.Value, extractResult.Error, extractResult.Source
+		}
+
+		if missing != nil {
+			switch {
+			case capture.Default != nil:
+				log.Debugf(ctx, "Capturing default of '%s': %v", capture.Name, missing)
+				value = capture.Default
+			case capture.Required:
+				return NewExecutorError(PhasePreconditions, ErrorCodeCaptureMissing, precond.Name,
+					fmt.Sprintf("required capture '%s' has no value", capture.Name), missing)
+			default:
+				log.Warnf(ctx, "Failed to capture '%s' with error: %v", capture.Name, missing)
+				continue
+			}
+		}
+		result.CapturedFields[capture.Name] = value
+		execCtx.SetParam(capture.Name, value)
+		log.Debugf(ctx, "Captured %s = %v (from %s)", capture.Name, value, source)
+	}
+	return nil
+}
+
+// headerValue returns the value of the header name, matched case-insensitively:
+// a string, or a list of strings for a header with several values
+func headerValue(headers map[string][]string, name string) (interface{}, bool) {
+	var values []string
+	for key, keyValues := range headers {
+		if strings.EqualFold(key, name) {
+			values = append(values, keyValues...)
+		}
+	}
+	switch len(values) {
+	case 0:
+		return nil, false
+	case 1:
+		return values[0], true
+	}
+	list := make([]interface{}, len(values))
+	for i, value := range values {
+		list[i] = value
+	}
+	return list, true
+}
+
+// executeAPICall executes an API call and returns the response for field capture
+// and the record of the call
 func (pe *PreconditionExecutor) executeAPICall(
 	ctx context.Context,
+	log logger.Logger,
+	name string,
 	apiCall *configloader.APICall,
 	execCtx *ExecutionContext,
-) ([]byte, error) {
-	resp, url, err := ExecuteAPICall(ctx, apiCall, execCtx, pe.apiClient, pe.log)
+) (*hyperfleetapi.Response, *APICallRecord, error) {
+	resp, url, record, err := executeTargetedAPICall(
+		ctx, PhasePreconditions, name, apiCall, execCtx, pe.apiClient, pe.recorder, log)
+	if _, ok := hyperfleetapi.IsUnknownTargetError(err); ok {
+		return nil, record, err
+	}
 
 	// Validate response - returns APIError with full metadata if validation fails
 	if validationErr := ValidateAPIResponse(resp, err, apiCall.Method, url); validationErr != nil {
-		return nil, validationErr
+		return nil, record, validationErr
 	}
 
-	return resp.Body, nil
+	return resp, record, nil
+}
+
+// joinPreconditionErrors returns the single error of errs, or an ExecutorError
+// listing the failed preconditions. The aggregate has the code of the first error.
+func joinPreconditionErrors(failed []string, errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return NewExecutorError(PhasePreconditions, ErrorCodeOf(errs[0]), strings.Join(failed, ", "),
+		fmt.Sprintf("%d preconditions failed", len(errs)), errors.Join(errs...))
 }
 
 // formatConditionDetails formats condition evaluation details for error messages
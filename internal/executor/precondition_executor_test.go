@@ -0,0 +1,198 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func capturingPrecondition(name, url, capture, field string) configloader.Precondition {
+	return configloader.Precondition{
+		ActionBase: configloader.ActionBase{Name: name, APICall: &configloader.APICall{Method: "GET", URL: url}},
+		Capture: []configloader.CaptureField{
+			{Name: capture, FieldExpressionDef: configloader.FieldExpressionDef{Field: field}},
+		},
+	}
+}
+
+func newPreconditionTestExecutor(
+	t *testing.T, config *configloader.Config, handler http.HandlerFunc, recorder ...*metrics.Recorder,
+) *Executor {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client, err := hyperfleetapi.NewClient(logger.NewTestLogger(),
+		hyperfleetapi.WithBaseURL(server.URL), hyperfleetapi.WithRetryAttempts(1))
+	require.NoError(t, err)
+	config.Adapter = configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"}
+	exec := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(client).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger())
+	if len(recorder) > 0 {
+		exec = exec.WithMetricsRecorder(recorder[0])
+	}
+	built, err := exec.Build()
+	require.NoError(t, err)
+	return built
+}
+
+func TestPreconditionDependencies_ChainRunsInDependencyOrder(t *testing.T) {
+	responses := map[string]string{
+		"/tenants/acme":                `{"id":"t1"}`,
+		"/tenants/t1/clusters/primary": `{"id":"c1"}`,
+		"/clusters/c1/node_pools":      `{"total":2}`,
+	}
+	var mu sync.Mutex
+	var paths []string
+	config := &configloader.Config{
+		// Declared in the reverse of their dependency order
+		Preconditions: []configloader.Precondition{
+			capturingPrecondition("nodePools", "/clusters/{{ .clusterId }}/node_pools", "poolCount", "total"),
+			capturingPrecondition("cluster", "/tenants/{{ .tenantId }}/clusters/primary", "clusterId", "id"),
+			capturingPrecondition("tenant", "/tenants/acme", "tenantId", "id"),
+		},
+	}
+	exec := newPreconditionTestExecutor(t, config, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	})
+
+	result := exec.Execute(context.Background(), map[string]interface{}{})
+	require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+	assert.Equal(t, []string{"/tenants/acme", "/tenants/t1/clusters/primary", "/clusters/c1/node_pools"}, paths)
+	names := make([]string, 0, len(result.PreconditionResults))
+	for _, r := range result.PreconditionResults {
+		names = append(names, r.Name)
+	}
+	assert.Equal(t, []string{"tenant", "cluster", "nodePools"}, names)
+	assert.EqualValues(t, 2, result.Params["poolCount"])
+}
+
+func TestPreconditionDependencies_IndependentRunConcurrently(t *testing.T) {
+	// A diamond: the two preconditions on the cluster run together
+	config := &configloader.Config{
+		PreconditionConcurrency: 2,
+		Preconditions: []configloader.Precondition{
+			capturingPrecondition("cluster", "/clusters/c1", "clusterId", "id"),
+			capturingPrecondition("nodePools", "/clusters/{{ .clusterId }}/node_pools", "poolCount", "total"),
+			capturingPrecondition("statuses", "/clusters/{{ .clusterId }}/statuses", "statusCount", "total"),
+			{
+				ActionBase: configloader.ActionBase{Name: "ready"},
+				Expression: "poolCount > 0 && statusCount > 0",
+			},
+		},
+	}
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	bothArrived := make(chan struct{})
+	exec := newPreconditionTestExecutor(t, config, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/clusters/c1" {
+			_, _ = w.Write([]byte(`{"id":"c1"}`))
+			return
+		}
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		if inFlight == 2 {
+			close(bothArrived)
+		}
+		mu.Unlock()
+		select {
+		case <-bothArrived:
+		case <-time.After(5 * time.Second):
+		}
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"total":1}`))
+	})
+
+	result := exec.Execute(context.Background(), map[string]interface{}{})
+	require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+	assert.Equal(t, 2, maxInFlight)
+	require.Len(t, result.PreconditionResults, 4)
+	assert.Equal(t, "cluster", result.PreconditionResults[0].Name)
+	assert.Equal(t, "ready", result.PreconditionResults[3].Name)
+}
+
+func TestPreconditionDependencies_CycleFailsExecutorCreation(t *testing.T) {
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+		Preconditions: []configloader.Precondition{
+			capturingPrecondition("a", "/a/{{ .bId }}", "aId", "id"),
+			capturingPrecondition("b", "/b/{{ .aId }}", "bId", "id"),
+		},
+	}
+	_, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	assert.ErrorContains(t, err, `invalid preconditions of workflow "default": `+
+		`preconditions form a dependency cycle: a -> b -> a`)
+}
+
+func TestPreconditionMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewRecorder("test-adapter", "v0.1.0", registry)
+	config := &configloader.Config{
+		Preconditions: []configloader.Precondition{
+			capturingPrecondition("cluster", "/clusters/c1", "clusterPhase", "phase"),
+			{
+				ActionBase:  configloader.ActionBase{Name: "clusterReady"},
+				Expression:  `clusterPhase == "Ready"`,
+				ReasonLabel: "cluster_not_ready",
+			},
+			{
+				ActionBase: configloader.ActionBase{Name: "clusterNotDeleting"},
+				Conditions: []configloader.Condition{{Field: "clusterPhase", Operator: "notEquals", Value: "Deleting"}},
+			},
+		},
+	}
+	phase := ""
+	exec := newPreconditionTestExecutor(t, config, func(w http.ResponseWriter, _ *http.Request) {
+		if phase == "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"phase":"` + phase + `"}`))
+	}, recorder)
+
+	for _, phase = range []string{"Provisioning", "Ready", "Provisioning", "", "Deleting"} {
+		exec.Execute(context.Background(), map[string]interface{}{})
+	}
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	notMet := func(precondition string) float64 {
+		return getCounterValue(t, families, "hyperfleet_adapter_preconditions_not_met_total",
+			"precondition", precondition)
+	}
+	assert.Equal(t, float64(3), notMet("clusterReady"), "Provisioning, Provisioning and Deleting")
+	assert.Zero(t, notMet("clusterNotDeleting"), "clusterReady stops the phase first")
+	assert.Equal(t, float64(3), getCounterValue(t, families, "hyperfleet_adapter_preconditions_not_met_reasons_total",
+		"reason", "cluster_not_ready"))
+	assert.Equal(t, float64(1), getCounterValue(t, families, "hyperfleet_adapter_preconditions_api_failures_total",
+		"precondition", "cluster"))
+}
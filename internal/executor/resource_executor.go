@@ -2,8 +2,14 @@ package executor
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/mitchellh/copystructure"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
@@ -11,15 +17,18 @@ import (
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/manifest"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"go.opentelemetry.io/otel/attribute"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // ResourceExecutor creates and updates Kubernetes resources
 type ResourceExecutor struct {
 	client transportclient.TransportClient
 	log    logger.Logger
+	config *ExecutorConfig
 }
 
 // newResourceExecutor creates a new resource executor
@@ -28,6 +37,7 @@ func newResourceExecutor(config *ExecutorConfig) *ResourceExecutor {
 	return &ResourceExecutor{
 		client: config.TransportClient,
 		log:    config.Logger,
+		config: config,
 	}
 }
 
@@ -43,10 +53,61 @@ func (re *ResourceExecutor) ExecuteAll(
 	}
 	results := make([]ResourceResult, 0, len(resources))
 
-	for _, resource := range resources {
-		result, err := re.executeResource(ctx, resource, execCtx)
+	// The finalizer is added before any resource is applied, so deleting the
+	// object waits for the teardown, and removed once the teardown is applied
+	finalizer := re.finalizer()
+	teardown := finalizer != nil && execCtx.Adapter.Workflow == finalizer.TeardownWorkflow
+	if finalizer != nil && !teardown {
+		result, err := re.executeFinalizer(
+			execCtx.stepContext(ctx, PhaseResources, finalizer.Name), finalizer, false, execCtx)
+		result.RetryWait = execCtx.retryWait(PhaseResources, finalizer.Name)
 		results = append(results, result)
+		if err != nil {
+			return results, err
+		}
+	}
+
+	for i, resource := range resources {
+		if cancelErr := cancelled(ctx, PhaseResources, i-1); cancelErr != nil {
+			return results, cancelErr
+		}
+		resourceLog := stepLogger(re.log, PhaseResources, resource.Name)
+		enabled, err := enabledIf(ctx, resourceLog, resource.EnabledIf, execCtx)
+		if err != nil {
+			return results, NewExecutorError(PhaseResources, ErrorCodeCELEvaluationError, resource.Name,
+				"failed to evaluate enabled_if", err)
+		}
+		if !enabled {
+			// A disabled resource has no result: the apply summary counts it as skipped
+			resourceLog.Infof(ctx, "Resource[%s] skipped: enabled_if is false", resource.Name)
+			continue
+		}
+		resourceResults, err := re.executeResource(execCtx.stepContext(ctx, PhaseResources, resource.Name),
+			resourceLog, resource, execCtx)
+		err = execCtx.atStepPath(err, "resources[%d]", i)
+		if len(resourceResults) > 0 {
+			// The items of a List share the wait of their resource, reported on the first
+			resourceResults[0].RetryWait = execCtx.retryWait(PhaseResources, resource.Name)
+		}
+		results = append(results, resourceResults...)
 
+		if err != nil {
+			// An apply interrupted by the cancellation is reported as the cancellation
+			if cancelErr := cancelled(ctx, PhaseResources, i-1); cancelErr != nil {
+				return results, cancelErr
+			}
+			return results, err
+		}
+	}
+
+	if teardown {
+		if cancelErr := cancelled(ctx, PhaseResources, len(resources)-1); cancelErr != nil {
+			return results, cancelErr
+		}
+		result, err := re.executeFinalizer(
+			execCtx.stepContext(ctx, PhaseResources, finalizer.Name), finalizer, true, execCtx)
+		result.RetryWait = execCtx.retryWait(PhaseResources, finalizer.Name)
+		results = append(results, result)
 		if err != nil {
 			return results, err
 		}
@@ -58,156 +119,362 @@ func (re *ResourceExecutor) ExecuteAll(
 // executeResource creates or updates a single resource via the transport client.
 // For k8s transport: renders manifest template → marshals to JSON → calls ApplyResource(bytes)
 // For maestro transport: renders manifestWork template → marshals to JSON → calls ApplyResource(bytes)
+// A k8s manifest rendering to a v1 List is applied item by item in declared order,
+// each with its own result named "<resource>[i]"; the first failed item fails the
+// resource. Discovery runs once every item is applied, looks up the kind of the
+// item selected by discovery.item, the first by default, and reports to its result.
 func (re *ResourceExecutor) executeResource(
 	ctx context.Context,
+	log logger.Logger,
 	resource configloader.Resource,
 	execCtx *ExecutionContext,
-) (ResourceResult, error) {
+) ([]ResourceResult, error) {
 	result := ResourceResult{
-		Name:   resource.Name,
-		Status: StatusSuccess,
+		StartedAt: execCtx.now(),
+		Name:      resource.Name,
+		Status:    StatusSuccess,
+	}
+	failed := func(err error) []ResourceResult {
+		result.Status = StatusFailed
+		result.Error = err
+		result.Duration = execCtx.now().Sub(result.StartedAt)
+		return []ResourceResult{result}
 	}
 
 	transportClient := re.client
 	if transportClient == nil {
-		result.Status = StatusFailed
-		result.Error = fmt.Errorf("transport client not configured for %s", resource.GetTransportClient())
-		return result, NewExecutorError(PhaseResources, resource.Name, "transport client not configured", result.Error)
+		err := fmt.Errorf("transport client not configured for %s", resource.GetTransportClient())
+		return failed(err), NewExecutorError(PhaseResources, ErrorCodeTransportNotConfigured, resource.Name,
+			"transport client not configured", err)
 	}
 
-	// Step 1: Render the manifest/manifestWork to bytes
-	re.log.Debugf(ctx, "Rendering manifest template for resource %s", resource.Name)
-	renderedBytes, err := re.renderToBytes(ctx, resource, execCtx)
+	// Step 1: Render the manifest/manifestWork to bytes, one per List item
+	log.Debugf(ctx, "Rendering manifest template for resource %s", resource.Name)
+	manifests, err := re.renderManifests(ctx, log, resource, execCtx)
 	if err != nil {
-		result.Status = StatusFailed
-		result.Error = err
-		return result, NewExecutorError(PhaseResources, resource.Name, "failed to render manifest", err)
+		return failed(err), NewExecutorError(PhaseResources, ErrorCodeTemplateError, resource.Name,
+			"failed to render manifest", err)
 	}
-
-	// Step 2: Extract resource identity from rendered manifest for result reporting
-	var obj unstructured.Unstructured
-	if unmarshalErr := json.Unmarshal(renderedBytes, &obj.Object); unmarshalErr == nil {
-		result.Kind = obj.GetKind()
-		result.Namespace = obj.GetNamespace()
-		result.ResourceName = obj.GetName()
+	if violation := re.checkPolicy(resource, manifests); violation != nil {
+		re.config.MetricsRecorder.RecordPolicyViolation(violation.Rule)
+		log.Errorf(logger.WithErrorField(ctx, violation), "Resource[%s] rejected by the resource policy", resource.Name)
+		return failed(violation), NewExecutorError(PhaseResources, ErrorCodePolicyViolation, resource.Name,
+			"resource policy violation", violation)
 	}
 
-	// Step 3: Prepare apply options
+	// Step 2: Prepare apply options
 	var applyOpts *transportclient.ApplyOptions
 	if resource.RecreateOnChange {
 		applyOpts = &transportclient.ApplyOptions{RecreateOnChange: true}
 	}
 
-	// Step 4: Build transport context (nil for k8s, *maestroclient.TransportContext for maestro)
+	// Step 3: Build transport context (nil for k8s, *maestroclient.TransportContext for maestro)
 	var transportTarget transportclient.TransportContext
 	if resource.IsMaestroTransport() && resource.Transport.Maestro != nil {
-		targetCluster, tplErr := renderTemplate(resource.Transport.Maestro.TargetCluster, execCtx.Params)
+		targetCluster, tplErr := renderTemplateAt("transport.maestro.target_cluster",
+			resource.Transport.Maestro.TargetCluster, execCtx.templateParams())
 		if tplErr != nil {
-			result.Status = StatusFailed
-			result.Error = tplErr
-			return result, NewExecutorError(PhaseResources, resource.Name, "failed to render targetCluster template", tplErr)
+			return failed(tplErr), NewExecutorError(PhaseResources, ErrorCodeTemplateError, resource.Name,
+				"failed to render targetCluster template", tplErr)
 		}
-		transportTarget = &maestroclient.TransportContext{
+		maestroTarget := &maestroclient.TransportContext{
 			ConsumerName: targetCluster,
 		}
+		if metadata := resource.Transport.Maestro.ManifestWorkMetadata; metadata != nil {
+			params := execCtx.templateParams()
+			var metaErr error
+			if maestroTarget.Labels, metaErr = renderWorkMetadata(ctx, log, resource.Name, "label",
+				metadata.Labels, params); metaErr == nil {
+				maestroTarget.Annotations, metaErr = renderWorkMetadata(ctx, log, resource.Name, "annotation",
+					metadata.Annotations, params)
+			}
+			if metaErr != nil {
+				return failed(metaErr), NewExecutorError(PhaseResources, ErrorCodeTemplateError, resource.Name,
+					"failed to render manifest_work_metadata", metaErr)
+			}
+			maestroTarget.KeepTemplateMetadata = metadata.Precedence == configloader.MetadataPrecedenceTemplate
+		}
+		transportTarget = maestroTarget
+	}
+
+	// Step 4: Apply every rendered manifest in order
+	results := make([]ResourceResult, 0, len(manifests))
+	for i, rendered := range manifests {
+		itemResult := result
+		if i > 0 {
+			itemResult.StartedAt = execCtx.now()
+		}
+		if len(manifests) > 1 {
+			itemResult.Name = fmt.Sprintf("%s[%d]", resource.Name, i)
+		}
+		err := re.applyManifest(ctx, log, &itemResult, rendered, applyOpts, transportTarget, execCtx)
+		itemResult.Duration = execCtx.now().Sub(itemResult.StartedAt)
+		results = append(results, itemResult)
+		if err != nil {
+			return results, err
+		}
+	}
+
+	// Step 5: Post-apply discovery — find the applied resource and store in execCtx for CEL evaluation
+	item := 0
+	if resource.Discovery != nil && resource.Discovery.Item != nil {
+		item = *resource.Discovery.Item
+	}
+	if item >= len(manifests) {
+		err := fmt.Errorf("discovery item %d is out of range: the manifest has %d items", item, len(manifests))
+		last := &results[len(results)-1]
+		last.Status = StatusFailed
+		last.Error = err
+		execCtx.SetExecutionError(&ExecutionError{
+			Phase:   string(PhaseResources),
+			Step:    resource.Name,
+			Message: err.Error(),
+			Code:    ErrorCodeDiscoveryFailed,
+		})
+		return results, NewExecutorError(PhaseResources, ErrorCodeDiscoveryFailed, resource.Name,
+			"failed to discover resource after apply", err)
+	}
+	discovered := &results[item]
+	discoveryStarted := execCtx.now()
+	err = re.discoverApplied(ctx, log, resource, discovered, manifests[item].object.GroupVersionKind(),
+		transportTarget, execCtx)
+	discovered.Duration += execCtx.now().Sub(discoveryStarted)
+	return results, err
+}
+
+// checkPolicy checks the rendered objects of a resource against the resource
+// policy, before any of them is applied: the manifests, or the workload
+// manifests of a ManifestWork, which itself is not checked
+func (re *ResourceExecutor) checkPolicy(
+	resource configloader.Resource,
+	manifests []renderedManifest,
+) *configloader.PolicyViolation {
+	if re.config.Config == nil || re.config.Config.Policy == nil {
+		return nil
+	}
+	policy := re.config.Config.Policy
+	check := func(object *unstructured.Unstructured) *configloader.PolicyViolation {
+		return policy.Check(object.GetAPIVersion(), object.GetKind(), object.GetName(), object.GetNamespace())
+	}
+	for _, rendered := range manifests {
+		if !resource.IsMaestroTransport() {
+			if violation := check(&rendered.object); violation != nil {
+				return violation
+			}
+			continue
+		}
+		workload, _, _ := unstructured.NestedSlice(rendered.object.Object, "spec", "workload", "manifests")
+		for _, m := range workload {
+			if object, ok := m.(map[string]interface{}); ok {
+				if violation := check(&unstructured.Unstructured{Object: object}); violation != nil {
+					return violation
+				}
+			}
+		}
 	}
+	return nil
+}
 
-	// Step 5: Call transport client ApplyResource with rendered bytes
-	applyResult, err := transportClient.ApplyResource(ctx, renderedBytes, applyOpts, transportTarget)
+// renderWorkMetadata renders the values of the ManifestWork labels or
+// annotations of a resource, kind naming which. A key rendering to an empty
+// value is left out with a warning. Keys, and the values of labels, must have
+// the Kubernetes syntax.
+func renderWorkMetadata(
+	ctx context.Context,
+	log logger.Logger,
+	resourceName, kind string,
+	templates map[string]string,
+	params map[string]interface{},
+) (map[string]string, error) {
+	if len(templates) == 0 {
+		return nil, nil
+	}
+	rendered := make(map[string]string, len(templates))
+	for _, key := range slices.Sorted(maps.Keys(templates)) {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid %s key %q: %s", kind, key, strings.Join(errs, "; "))
+		}
+		value, err := renderTemplate(templates[key], params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s %q: %w", kind, key, atPathf(err,
+				"transport.maestro.manifest_work_metadata.%ss.%s", kind, key))
+		}
+		if value == "" {
+			log.Warnf(ctx, "Resource[%s] ManifestWork %s %q rendered empty: left out", resourceName, kind, key)
+			continue
+		}
+		if kind == "label" {
+			if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid value %q of label %q: %s", value, key, strings.Join(errs, "; "))
+			}
+		}
+		rendered[key] = value
+	}
+	return rendered, nil
+}
+
+// applyManifest applies a rendered manifest with the transport client and
+// records the identity of the manifest and the operation in result
+func (re *ResourceExecutor) applyManifest(
+	ctx context.Context,
+	log logger.Logger,
+	result *ResourceResult,
+	rendered renderedManifest,
+	applyOpts *transportclient.ApplyOptions,
+	transportTarget transportclient.TransportContext,
+	execCtx *ExecutionContext,
+) error {
+	// Resource identity from the rendered manifest for result reporting
+	result.ContentHash = rendered.hash
+	result.APIVersion = rendered.object.GetAPIVersion()
+	result.Kind = rendered.object.GetKind()
+	result.Namespace = rendered.object.GetNamespace()
+	result.ResourceName = rendered.object.GetName()
+
+	applyCtx, applySpan := startSpan(ctx, re.config, "ApplyResource",
+		attribute.String(AttrResource, result.Name),
+		attribute.String(AttrK8sKind, result.Kind),
+		attribute.String(AttrK8sName, result.ResourceName),
+	)
+	applyResult, err := re.client.ApplyResource(applyCtx, rendered.data, applyOpts, transportTarget)
 	if err != nil {
+		endSpan(applySpan, string(StatusFailed), err)
 		result.Status = StatusFailed
 		result.Error = err
-		execCtx.Adapter.ExecutionError = &ExecutionError{
+		code := applyErrorCode(err)
+		execCtx.SetExecutionError(&ExecutionError{
 			Phase:   string(PhaseResources),
-			Step:    resource.Name,
+			Step:    result.Name,
 			Message: err.Error(),
-		}
+			Code:    code,
+		})
 		errCtx := logger.WithK8sResult(ctx, "FAILED")
 		errCtx = logger.WithErrorField(errCtx, err)
-		re.log.Errorf(errCtx, "Resource[%s] processed: FAILED", resource.Name)
-		return result, NewExecutorError(PhaseResources, resource.Name, "failed to apply resource", err)
+		log.Errorf(errCtx, "Resource[%s] processed: FAILED", result.Name)
+		return NewExecutorError(PhaseResources, code, result.Name, "failed to apply resource", err)
 	}
 
-	// Step 6: Extract result
 	result.Operation = applyResult.Operation
 	result.OperationReason = applyResult.Reason
+	endSpan(applySpan, string(result.Operation), nil)
 
 	successCtx := logger.WithK8sResult(ctx, "SUCCESS")
-	re.log.Infof(successCtx, "Resource[%s] processed: operation=%s reason=%s",
-		resource.Name, result.Operation, result.OperationReason)
+	log.Infof(successCtx, "Resource[%s] processed: operation=%s reason=%s",
+		result.Name, result.Operation, result.OperationReason)
+	return nil
+}
 
-	// Step 7: Post-apply discovery — find the applied resource and store in execCtx for CEL evaluation
-	if resource.Discovery != nil {
-		discovered, discoverErr := re.discoverResource(ctx, resource, execCtx, transportTarget)
-		if discoverErr != nil {
+// discoverApplied discovers the applied resource, with its nested discoveries, and
+// stores it in execCtx under the resource name. A failure is recorded in result.
+func (re *ResourceExecutor) discoverApplied(
+	ctx context.Context,
+	log logger.Logger,
+	resource configloader.Resource,
+	result *ResourceResult,
+	gvk schema.GroupVersionKind,
+	transportTarget transportclient.TransportContext,
+	execCtx *ExecutionContext,
+) error {
+	if resource.Discovery == nil {
+		return nil
+	}
+	discoverCtx, discoverSpan := startSpan(ctx, re.config, "DiscoverResource",
+		attribute.String(AttrResource, resource.Name))
+	discovered, discoverErr := re.discoverResource(discoverCtx, resource, gvk, execCtx, transportTarget)
+	if discoverErr != nil {
+		endSpan(discoverSpan, string(StatusFailed), discoverErr)
+		result.Status = StatusFailed
+		result.Error = discoverErr
+		code := discoveryErrorCode(discoverErr)
+		execCtx.SetExecutionError(&ExecutionError{
+			Phase:   string(PhaseResources),
+			Step:    resource.Name,
+			Message: discoverErr.Error(),
+			Code:    code,
+		})
+		errCtx := logger.WithK8sResult(ctx, "FAILED")
+		errCtx = logger.WithErrorField(errCtx, discoverErr)
+		log.Errorf(errCtx, "Resource[%s] discovery after apply failed: %v", resource.Name, discoverErr)
+		return NewExecutorError(
+			PhaseResources, code, resource.Name, "failed to discover resource after apply", discoverErr)
+	}
+	endSpan(discoverSpan, string(StatusSuccess), nil)
+	if discovered == nil {
+		return nil
+	}
+
+	// Always store the discovered top-level resource by resource name.
+	// Nested discoveries are added as independent entries keyed by nested name.
+	execCtx.Resources[resource.Name] = discovered
+	log.Debugf(ctx, "Resource[%s] discovered and stored in context", resource.Name)
+
+	// Nested discoveries — find sub-resources within the discovered parent (e.g., ManifestWork)
+	if len(resource.NestedDiscoveries) == 0 {
+		return nil
+	}
+	nestedResults := re.discoverNestedResources(ctx, log, resource, execCtx, discovered)
+	for nestedName, nestedObj := range nestedResults {
+		if nestedName == resource.Name {
+			log.Warnf(ctx,
+				"Nested discovery %q has the same name as parent resource; skipping to avoid overwriting parent",
+				nestedName)
+			continue
+		}
+		if nestedObj == nil {
+			continue
+		}
+		if _, exists := execCtx.Resources[nestedName]; exists {
+			collisionErr := fmt.Errorf(
+				"nested discovery key collision: %q already exists in context",
+				nestedName,
+			)
 			result.Status = StatusFailed
-			result.Error = discoverErr
-			execCtx.Adapter.ExecutionError = &ExecutionError{
+			result.Error = collisionErr
+			execCtx.SetExecutionError(&ExecutionError{
 				Phase:   string(PhaseResources),
 				Step:    resource.Name,
-				Message: discoverErr.Error(),
-			}
-			errCtx := logger.WithK8sResult(ctx, "FAILED")
-			errCtx = logger.WithErrorField(errCtx, discoverErr)
-			re.log.Errorf(errCtx, "Resource[%s] discovery after apply failed: %v", resource.Name, discoverErr)
-			return result, NewExecutorError(
-				PhaseResources, resource.Name, "failed to discover resource after apply", discoverErr)
-		}
-		if discovered != nil {
-			// Always store the discovered top-level resource by resource name.
-			// Nested discoveries are added as independent entries keyed by nested name.
-			execCtx.Resources[resource.Name] = discovered
-			re.log.Debugf(ctx, "Resource[%s] discovered and stored in context", resource.Name)
-
-			// Step 8: Nested discoveries — find sub-resources within the discovered parent (e.g., ManifestWork)
-			if len(resource.NestedDiscoveries) > 0 {
-				nestedResults := re.discoverNestedResources(ctx, resource, execCtx, discovered)
-				for nestedName, nestedObj := range nestedResults {
-					if nestedName == resource.Name {
-						re.log.Warnf(ctx,
-							"Nested discovery %q has the same name as parent resource; skipping to avoid overwriting parent",
-							nestedName)
-						continue
-					}
-					if nestedObj == nil {
-						continue
-					}
-					if _, exists := execCtx.Resources[nestedName]; exists {
-						collisionErr := fmt.Errorf(
-							"nested discovery key collision: %q already exists in context",
-							nestedName,
-						)
-						result.Status = StatusFailed
-						result.Error = collisionErr
-						execCtx.Adapter.ExecutionError = &ExecutionError{
-							Phase:   string(PhaseResources),
-							Step:    resource.Name,
-							Message: collisionErr.Error(),
-						}
-						return result, NewExecutorError(
-							PhaseResources, resource.Name,
-							"duplicate resource context key",
-							collisionErr,
-						)
-					}
-					execCtx.Resources[nestedName] = nestedObj
-				}
-				re.log.Debugf(ctx, "Resource[%s] discovered with %d nested resources added to context",
-					resource.Name, len(nestedResults))
-			}
+				Message: collisionErr.Error(),
+				Code:    ErrorCodeDiscoveryFailed,
+			})
+			return NewExecutorError(
+				PhaseResources, ErrorCodeDiscoveryFailed, resource.Name,
+				"duplicate resource context key",
+				collisionErr,
+			)
 		}
+		execCtx.Resources[nestedName] = nestedObj
 	}
+	log.Debugf(ctx, "Resource[%s] discovered with %d nested resources added to context",
+		resource.Name, len(nestedResults))
+	return nil
+}
 
-	return result, nil
+// contentHash returns the "sha256:<hex>" digest of a rendered manifest
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
 }
 
-// renderToBytes renders the resource's manifest template to JSON bytes.
-// The manifest holds either a K8s resource or a ManifestWork depending on transport type.
-func (re *ResourceExecutor) renderToBytes(
+// renderedManifest is a manifest rendered for the transport client
+type renderedManifest struct {
+	// object is the rendered manifest
+	object unstructured.Unstructured
+	// hash is the content hash of data, taken before the correlation annotation
+	hash string
+	// data is the JSON of object
+	data []byte
+}
+
+// renderManifests renders the resource's manifest template to JSON, expanding a
+// v1 List into its items in order. The manifest holds either a K8s resource or a
+// ManifestWork depending on transport type; the v1 Lists among the manifests of
+// a ManifestWork workload are replaced by their items.
+func (re *ResourceExecutor) renderManifests(
 	ctx context.Context,
+	log logger.Logger,
 	resource configloader.Resource,
 	execCtx *ExecutionContext,
-) ([]byte, error) {
+) ([]renderedManifest, error) {
 	if resource.Manifest == nil {
 		return nil, fmt.Errorf("no manifest specified for resource %s", resource.Name)
 	}
@@ -226,30 +493,110 @@ func (re *ResourceExecutor) renderToBytes(
 	}
 
 	// Deep copy to avoid modifying the original
-	manifestData = deepCopyMap(ctx, manifestData, re.log)
+	manifestData = deepCopyMap(ctx, manifestData, log)
 
 	// Render all template strings in the manifest
-	renderedData, err := renderManifestTemplates(manifestData, execCtx.Params)
+	renderedData, err := renderManifestTemplates(manifestData, execCtx.templateParams())
 	if err != nil {
-		return nil, fmt.Errorf("failed to render manifest templates: %w", err)
+		return nil, fmt.Errorf("failed to render manifest templates: %w", atPath("manifest", err))
 	}
 
-	// Marshal to JSON bytes
-	data, err := json.Marshal(renderedData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal rendered manifest: %w", err)
+	var objects []map[string]interface{}
+	if resource.IsMaestroTransport() {
+		if err := expandWorkloadLists(renderedData); err != nil {
+			return nil, fmt.Errorf("failed to expand ManifestWork workload: %w", err)
+		}
+		objects = []map[string]interface{}{renderedData}
+	} else if objects, err = expandList(renderedData); err != nil {
+		return nil, err
+	}
+
+	manifests := make([]renderedManifest, 0, len(objects))
+	for _, object := range objects {
+		// Marshal to JSON bytes. The hash is taken before the correlation annotation
+		// is added, so it only changes when the rendered content does.
+		data, err := json.Marshal(object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal rendered manifest: %w", err)
+		}
+		hash := contentHash(data)
+		if annotateCorrelationID(object, execCtx) {
+			if data, err = json.Marshal(object); err != nil {
+				return nil, fmt.Errorf("failed to marshal rendered manifest: %w", err)
+			}
+		}
+		manifests = append(manifests, renderedManifest{
+			object: unstructured.Unstructured{Object: object},
+			hash:   hash,
+			data:   data,
+		})
 	}
+	return manifests, nil
+}
 
-	return data, nil
+// expandList returns the items of a rendered v1 List manifest in order, or the
+// manifest itself if it is not a List. Empty items are skipped; a List without
+// any other item is an error, as it would apply nothing.
+func expandList(object map[string]interface{}) ([]map[string]interface{}, error) {
+	if object["apiVersion"] != "v1" || object["kind"] != configloader.KindList {
+		return []map[string]interface{}{object}, nil
+	}
+	items, _ := object["items"].([]interface{})
+	expanded := make([]map[string]interface{}, 0, len(items))
+	for i, item := range items {
+		if item == nil {
+			continue
+		}
+		itemObject, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("list item %d is not a manifest: %T", i, item)
+		}
+		if len(itemObject) > 0 {
+			expanded = append(expanded, itemObject)
+		}
+	}
+	if len(expanded) == 0 {
+		return nil, errors.New("list manifest has no items")
+	}
+	return expanded, nil
+}
+
+// expandWorkloadLists replaces the v1 List manifests of a rendered ManifestWork
+// workload with their items, so each item is a manifest of the workload
+func expandWorkloadLists(work map[string]interface{}) error {
+	spec, _ := work["spec"].(map[string]interface{})
+	workload, _ := spec["workload"].(map[string]interface{})
+	manifests, ok := workload["manifests"].([]interface{})
+	if !ok {
+		return nil
+	}
+	expanded := make([]interface{}, 0, len(manifests))
+	for i, m := range manifests {
+		object, ok := m.(map[string]interface{})
+		if !ok {
+			expanded = append(expanded, m)
+			continue
+		}
+		items, err := expandList(object)
+		if err != nil {
+			return fmt.Errorf("manifest %d: %w", i, err)
+		}
+		for _, item := range items {
+			expanded = append(expanded, item)
+		}
+	}
+	workload["manifests"] = expanded
+	return nil
 }
 
 // discoverResource discovers the applied resource using the discovery config.
 // For k8s transport: discovers the K8s resource by name or label selector.
 // For maestro transport: discovers the ManifestWork by name or label selector.
-// The discovered resource is stored in execCtx.Resources for post-action CEL evaluation.
+// gvk is the kind to discover, that of the first manifest applied for the resource.
 func (re *ResourceExecutor) discoverResource(
 	ctx context.Context,
 	resource configloader.Resource,
+	gvk schema.GroupVersionKind,
 	execCtx *ExecutionContext,
 	transportTarget transportclient.TransportContext,
 ) (*unstructured.Unstructured, error) {
@@ -259,22 +606,19 @@ func (re *ResourceExecutor) discoverResource(
 	}
 
 	// Render discovery namespace template
-	namespace, err := renderTemplate(discovery.Namespace, execCtx.Params)
+	params := execCtx.templateParams()
+	namespace, err := renderTemplateAt("discovery.namespace", discovery.Namespace, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to render namespace template: %w", err)
 	}
 
 	// Discover by name
 	if discovery.ByName != "" {
-		name, err := renderTemplate(discovery.ByName, execCtx.Params)
+		name, err := renderTemplateAt("discovery.by_name", discovery.ByName, params)
 		if err != nil {
 			return nil, fmt.Errorf("failed to render byName template: %w", err)
 		}
 
-		// For maestro: use ManifestWork GVK
-		// For k8s: parse the rendered manifest to get GVK
-		gvk := re.resolveGVK(resource)
-
 		return re.client.GetResource(ctx, gvk, namespace, name, transportTarget)
 	}
 
@@ -282,11 +626,12 @@ func (re *ResourceExecutor) discoverResource(
 	if discovery.BySelectors != nil && len(discovery.BySelectors.LabelSelector) > 0 {
 		renderedLabels := make(map[string]string)
 		for k, v := range discovery.BySelectors.LabelSelector {
-			renderedK, err := renderTemplate(k, execCtx.Params)
+			path := "discovery.by_selectors.label_selector." + k
+			renderedK, err := renderTemplateAt(path, k, params)
 			if err != nil {
 				return nil, fmt.Errorf("failed to render label key template: %w", err)
 			}
-			renderedV, err := renderTemplate(v, execCtx.Params)
+			renderedV, err := renderTemplateAt(path, v, params)
 			if err != nil {
 				return nil, fmt.Errorf("failed to render label value template: %w", err)
 			}
@@ -299,8 +644,6 @@ func (re *ResourceExecutor) discoverResource(
 			LabelSelector: labelSelector,
 		}
 
-		gvk := re.resolveGVK(resource)
-
 		list, err := re.client.DiscoverResources(ctx, gvk, discoveryConfig, transportTarget)
 		if err != nil {
 			return nil, err
@@ -320,21 +663,23 @@ func (re *ResourceExecutor) discoverResource(
 // Each nestedDiscovery is matched against the parent's nested manifests using manifest.DiscoverNestedManifest.
 func (re *ResourceExecutor) discoverNestedResources(
 	ctx context.Context,
+	log logger.Logger,
 	resource configloader.Resource,
 	execCtx *ExecutionContext,
 	parent *unstructured.Unstructured,
 ) map[string]*unstructured.Unstructured {
 	nestedResults := make(map[string]*unstructured.Unstructured)
 
-	for _, nd := range resource.NestedDiscoveries {
+	params := execCtx.templateParams()
+	for i, nd := range resource.NestedDiscoveries {
 		if nd.Discovery == nil {
 			continue
 		}
 
 		// Build discovery config with rendered templates
-		discoveryConfig, err := re.buildNestedDiscoveryConfig(nd.Discovery, execCtx.Params)
-		if err != nil {
-			re.log.Warnf(ctx, "Resource[%s] nested discovery[%s] failed to build config: %v",
+		discoveryConfig, err := re.buildNestedDiscoveryConfig(nd.Discovery, params)
+		if err = atPathf(err, "nested_discoveries[%d]", i); err != nil {
+			log.Warnf(ctx, "Resource[%s] nested discovery[%s] failed to build config: %v",
 				resource.Name, nd.Name, err)
 			continue
 		}
@@ -342,13 +687,13 @@ func (re *ResourceExecutor) discoverNestedResources(
 		// Search within the parent resource
 		list, err := manifest.DiscoverNestedManifest(parent, discoveryConfig)
 		if err != nil {
-			re.log.Warnf(ctx, "Resource[%s] nested discovery[%s] failed: %v",
+			log.Warnf(ctx, "Resource[%s] nested discovery[%s] failed: %v",
 				resource.Name, nd.Name, err)
 			continue
 		}
 
 		if len(list.Items) == 0 {
-			re.log.Debugf(ctx, "Resource[%s] nested discovery[%s] found no matches",
+			log.Debugf(ctx, "Resource[%s] nested discovery[%s] found no matches",
 				resource.Name, nd.Name)
 			continue
 		}
@@ -358,7 +703,7 @@ func (re *ResourceExecutor) discoverNestedResources(
 		if best != nil {
 			manifest.EnrichWithResourceStatus(parent, best)
 			nestedResults[nd.Name] = best
-			re.log.Debugf(ctx, "Resource[%s] nested discovery[%s] found: %s/%s",
+			log.Debugf(ctx, "Resource[%s] nested discovery[%s] found: %s/%s",
 				resource.Name, nd.Name, best.GetKind(), best.GetName())
 		}
 	}
@@ -371,13 +716,13 @@ func (re *ResourceExecutor) buildNestedDiscoveryConfig(
 	discovery *configloader.DiscoveryConfig,
 	params map[string]interface{},
 ) (*manifest.DiscoveryConfig, error) {
-	namespace, err := renderTemplate(discovery.Namespace, params)
+	namespace, err := renderTemplateAt("discovery.namespace", discovery.Namespace, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to render namespace template: %w", err)
 	}
 
 	if discovery.ByName != "" {
-		name, err := renderTemplate(discovery.ByName, params)
+		name, err := renderTemplateAt("discovery.by_name", discovery.ByName, params)
 		if err != nil {
 			return nil, fmt.Errorf("failed to render byName template: %w", err)
 		}
@@ -390,11 +735,12 @@ func (re *ResourceExecutor) buildNestedDiscoveryConfig(
 	if discovery.BySelectors != nil && len(discovery.BySelectors.LabelSelector) > 0 {
 		renderedLabels := make(map[string]string)
 		for k, v := range discovery.BySelectors.LabelSelector {
-			renderedK, err := renderTemplate(k, params)
+			path := "discovery.by_selectors.label_selector." + k
+			renderedK, err := renderTemplateAt(path, k, params)
 			if err != nil {
 				return nil, fmt.Errorf("failed to render label key template: %w", err)
 			}
-			renderedV, err := renderTemplate(v, params)
+			renderedV, err := renderTemplateAt(path, v, params)
 			if err != nil {
 				return nil, fmt.Errorf("failed to render label value template: %w", err)
 			}
@@ -409,25 +755,6 @@ func (re *ResourceExecutor) buildNestedDiscoveryConfig(
 	return nil, fmt.Errorf("discovery must specify byName or bySelectors")
 }
 
-// resolveGVK extracts the GVK from the resource's manifest.
-// Works for both K8s resources and ManifestWorks since both have apiVersion and kind.
-func (re *ResourceExecutor) resolveGVK(resource configloader.Resource) schema.GroupVersionKind {
-	manifestData, ok := resource.Manifest.(map[string]interface{})
-	if !ok {
-		return schema.GroupVersionKind{}
-	}
-	apiVersion, ok1 := manifestData["apiVersion"].(string)
-	kind, ok2 := manifestData["kind"].(string)
-	if !ok1 || !ok2 {
-		return schema.GroupVersionKind{}
-	}
-	gv, err := schema.ParseGroupVersion(apiVersion)
-	if err != nil {
-		return schema.GroupVersionKind{}
-	}
-	return gv.WithKind(kind)
-}
-
 // convertToStringKeyMap converts map[interface{}]interface{} to map[string]interface{}
 func convertToStringKeyMap(m map[interface{}]interface{}) map[string]interface{} {
 	result := make(map[string]interface{})
@@ -461,9 +788,11 @@ func convertSlice(s []interface{}) []interface{} {
 	return result
 }
 
-// deepCopyMap creates a deep copy of a map using github.com/mitchellh/copystructure.
-// This handles non-JSON-serializable types (channels, functions, time.Time, etc.)
-// and preserves type information (e.g., int64 stays int64, not float64).
+// deepCopyMap creates a deep copy of a map.
+// JSON-like maps, as decoded from YAML manifests, are copied by a direct walk.
+// Other maps go through github.com/mitchellh/copystructure, which handles
+// non-JSON-serializable types (channels, functions, time.Time, etc.).
+// Both preserve type information (e.g., int64 stays int64, not float64).
 // If deep copy fails, it falls back to a shallow copy and logs a warning.
 // WARNING: Shallow copy means nested maps/slices will share references with the original,
 // which could lead to unexpected mutations.
@@ -472,6 +801,10 @@ func deepCopyMap(ctx context.Context, m map[string]interface{}, log logger.Logge
 		return nil
 	}
 
+	if result, ok := deepCopyJSONMap(m); ok {
+		return result
+	}
+
 	copied, err := copystructure.Copy(m)
 	if err != nil {
 		// Fallback to shallow copy if deep copy fails
@@ -496,6 +829,67 @@ func deepCopyMap(ctx context.Context, m map[string]interface{}, log logger.Logge
 	return result
 }
 
+// deepCopyJSONMap deep copies a map holding only JSON-like values: nested
+// map[string]interface{} and []interface{}, strings, bools, numbers and nil.
+// It reports false on any other value type, leaving the copy to copystructure.
+func deepCopyJSONMap(m map[string]interface{}) (map[string]interface{}, bool) {
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		copied, ok := deepCopyJSONValue(v)
+		if !ok {
+			return nil, false
+		}
+		result[k] = copied
+	}
+	return result, true
+}
+
+// deepCopyJSONValue deep copies a JSON-like value, see deepCopyJSONMap
+func deepCopyJSONValue(v interface{}) (interface{}, bool) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if val == nil {
+			return val, true
+		}
+		return deepCopyJSONMap(val)
+	case []interface{}:
+		if val == nil {
+			return val, true
+		}
+		result := make([]interface{}, len(val))
+		for i, item := range val {
+			copied, ok := deepCopyJSONValue(item)
+			if !ok {
+				return nil, false
+			}
+			result[i] = copied
+		}
+		return result, true
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return val, true
+	default:
+		return nil, false
+	}
+}
+
+// annotateCorrelationID sets the correlation annotation of the rendered top-level
+// object (the resource, or the ManifestWork with the Maestro transport) to the
+// correlation ID of the execution, and reports whether it was set
+func annotateCorrelationID(obj map[string]interface{}, execCtx *ExecutionContext) bool {
+	correlationID := execCtx.Adapter.CorrelationID
+	if correlationID == "" {
+		return false
+	}
+	u := unstructured.Unstructured{Object: obj}
+	annotations := u.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[execCtx.Config.Correlation.AnnotationName()] = correlationID
+	u.SetAnnotations(annotations)
+	return true
+}
+
 // renderManifestTemplates recursively renders all template strings in a manifest
 func renderManifestTemplates(
 	data map[string]interface{},
@@ -506,12 +900,12 @@ func renderManifestTemplates(
 	for k, v := range data {
 		renderedKey, err := renderTemplate(k, params)
 		if err != nil {
-			return nil, fmt.Errorf("failed to render key '%s': %w", k, err)
+			return nil, fmt.Errorf("failed to render key '%s': %w", k, atPath(k, err))
 		}
 
 		renderedValue, err := renderValue(v, params)
 		if err != nil {
-			return nil, fmt.Errorf("failed to render value for key '%s': %w", k, err)
+			return nil, fmt.Errorf("failed to render value for key '%s': %w", k, atPath(k, err))
 		}
 
 		result[renderedKey] = renderedValue
@@ -535,7 +929,7 @@ func renderValue(v interface{}, params map[string]interface{}) (interface{}, err
 		for i, item := range val {
 			rendered, err := renderValue(item, params)
 			if err != nil {
-				return nil, err
+				return nil, atPathf(err, "[%d]", i)
 			}
 			result[i] = rendered
 		}
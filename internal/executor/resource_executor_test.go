@@ -2,17 +2,23 @@ package executor
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/mitchellh/copystructure"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/maestroclient"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/manifest"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 const (
@@ -248,9 +254,116 @@ func TestDeepCopyMap_RealWorldContext(t *testing.T) {
 	assert.Equal(t, "{{ .namespace }}", originalMetadata["name"])
 }
 
+// The direct walk of JSON-like maps must produce what copystructure produces
+func TestDeepCopyMap_MatchesCopystructure(t *testing.T) {
+	original := map[string]interface{}{
+		"int":     42,
+		"int64":   int64(7),
+		"uint8":   uint8(1),
+		"float32": float32(1.5),
+		"empty":   map[string]interface{}{},
+		"nilMap":  map[string]interface{}(nil),
+		"nilList": []interface{}(nil),
+		"list": []interface{}{
+			map[string]interface{}{"name": "a", "ports": []interface{}{80, 443}},
+			nil,
+		},
+	}
+
+	want, err := copystructure.Copy(original)
+	require.NoError(t, err)
+	copied := deepCopyMap(context.Background(), original, logger.NewTestLogger())
+	assert.Equal(t, want, copied)
+
+	copiedPorts := copied["list"].([]interface{})[0].(map[string]interface{})["ports"].([]interface{})
+	copiedPorts[0] = 8080
+	originalPorts := original["list"].([]interface{})[0].(map[string]interface{})["ports"].([]interface{})
+	assert.Equal(t, 80, originalPorts[0], "Original nested slice should not be modified")
+}
+
+// A value outside the JSON-like types, even deeply nested, falls back to copystructure
+func TestDeepCopyMap_NestedExoticType(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	original := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": []interface{}{map[string]interface{}{"created": created}},
+		},
+	}
+
+	copied := deepCopyMap(context.Background(), original, logger.NewTestLogger())
+	annotations := copied["metadata"].(map[string]interface{})["annotations"].([]interface{})
+	assert.Equal(t, created, annotations[0].(map[string]interface{})["created"])
+
+	annotations[0] = "replaced"
+	originalAnnotations := original["metadata"].(map[string]interface{})["annotations"].([]interface{})
+	assert.IsType(t, map[string]interface{}{}, originalAnnotations[0], "Original nested slice should not be modified")
+}
+
+// largeManifest returns a manifest template of about size bytes of JSON,
+// a Deployment-like object with many templated containers
+func largeManifest(size int) map[string]interface{} {
+	var containers []interface{}
+	for i := 0; ; i++ {
+		containers = append(containers, map[string]interface{}{
+			"name":  fmt.Sprintf("container-%d", i),
+			"image": "quay.io/hyperfleet/workload:{{ .version }}",
+			"args":  []interface{}{"--cluster={{ .clusterId }}", "--verbose", fmt.Sprintf("--shard=%d", i)},
+			"env": []interface{}{
+				map[string]interface{}{"name": "CLUSTER_ID", "value": "{{ .clusterId }}"},
+				map[string]interface{}{"name": "REGION", "value": "{{ .region }}"},
+			},
+			"ports":     []interface{}{map[string]interface{}{"containerPort": 8080 + i, "protocol": "TCP"}},
+			"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "500m", "memory": "256Mi"}},
+		})
+		if i%50 != 0 {
+			continue
+		}
+		if data, err := json.Marshal(containers); err != nil || len(data) >= size {
+			break
+		}
+	}
+	return map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "{{ .clusterId }}-workload",
+			"namespace": "{{ .clusterId }}",
+			"labels":    map[string]interface{}{"app": "workload", "hyperfleet.io/cluster-id": "{{ .clusterId }}"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": 3,
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{"containers": containers},
+			},
+		},
+	}
+}
+
+// BenchmarkDeepCopyMap compares copystructure, the previous implementation,
+// with deepCopyMap on a 400KB manifest template
+func BenchmarkDeepCopyMap(b *testing.B) {
+	template := largeManifest(400 << 10)
+	log := logger.NewTestLogger()
+
+	b.Run("copystructure", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := copystructure.Copy(template); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("deepCopyMap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			deepCopyMap(context.Background(), template, log)
+		}
+	})
+}
+
 // TestResourceExecutor_ExecuteAll_DiscoveryFailure verifies that when discovery fails after a successful apply,
 // the error is logged and notified: ExecuteAll returns an error, result is failed,
-// and execCtx.Adapter.ExecutionError is set.
+// and execCtx.GetExecutionError() is set.
 func TestResourceExecutor_ExecuteAll_DiscoveryFailure(t *testing.T) {
 	discoveryErr := errors.New("discovery failed: resource not found")
 	mock := k8sclient.NewMockK8sClient()
@@ -293,10 +406,11 @@ func TestResourceExecutor_ExecuteAll_DiscoveryFailure(t *testing.T) {
 	assert.Equal(t, StatusFailed, results[0].Status, "result status should be failed")
 	require.NotNil(t, results[0].Error)
 	assert.Contains(t, results[0].Error.Error(), "discovery failed", "result error should describe discovery failure")
-	require.NotNil(t, execCtx.Adapter.ExecutionError, "ExecutionError should be set for notification")
-	assert.Equal(t, string(PhaseResources), execCtx.Adapter.ExecutionError.Phase)
-	assert.Equal(t, resource.Name, execCtx.Adapter.ExecutionError.Step)
-	assert.Contains(t, execCtx.Adapter.ExecutionError.Message, "discovery failed")
+	require.NotNil(t, execCtx.GetExecutionError(), "ExecutionError should be set for notification")
+	assert.Equal(t, string(PhaseResources), execCtx.GetExecutionError().Phase)
+	assert.Equal(t, resource.Name, execCtx.GetExecutionError().Step)
+	assert.Contains(t, execCtx.GetExecutionError().Message, "discovery failed")
+	assert.Equal(t, ErrorCodeDiscoveryFailed, execCtx.GetExecutionError().Code)
 }
 
 func TestResourceExecutor_ExecuteAll_StoresNestedDiscoveriesByName(t *testing.T) {
@@ -425,3 +539,314 @@ func TestResourceExecutor_ExecuteAll_StoresNestedDiscoveriesByName(t *testing.T)
 	v0 := values[0].(map[string]interface{})
 	assert.Equal(t, "data", v0["name"])
 }
+
+// bundleItems returns a templated Namespace, an empty document and two ConfigMaps
+// in it, as the items of a v1 List
+func bundleItems() []interface{} {
+	configMap := func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "{{ .clusterId }}"},
+			"data":       map[string]interface{}{"cluster_id": "{{ .clusterId }}"},
+		}
+	}
+	return []interface{}{
+		map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata":   map[string]interface{}{"name": "{{ .clusterId }}"},
+		},
+		map[string]interface{}{},
+		configMap("settings"),
+		configMap("quotas"),
+	}
+}
+
+func TestResourceExecutor_ExecuteAll_ExpandsList(t *testing.T) {
+	list := map[string]interface{}{"apiVersion": "v1", "kind": configloader.KindList, "items": bundleItems()}
+	resources := []configloader.Resource{
+		{
+			Name:      "bundle",
+			Manifest:  list,
+			Discovery: &configloader.DiscoveryConfig{ByName: "{{ .clusterId }}"},
+		},
+		{
+			Name: "work",
+			Transport: &configloader.TransportConfig{
+				Client:  configloader.TransportClientMaestro,
+				Maestro: &configloader.MaestroTransportConfig{TargetCluster: "{{ .clusterId }}"},
+			},
+			Manifest: map[string]interface{}{
+				"apiVersion": "work.open-cluster-management.io/v1",
+				"kind":       "ManifestWork",
+				"metadata":   map[string]interface{}{"name": "work-{{ .clusterId }}", "namespace": "{{ .clusterId }}"},
+				"spec": map[string]interface{}{
+					"workload": map[string]interface{}{"manifests": []interface{}{list}},
+				},
+			},
+		},
+	}
+
+	mock := k8sclient.NewMockK8sClient()
+	re := newResourceExecutor(&ExecutorConfig{TransportClient: mock, Logger: logger.NewTestLogger()})
+	execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+	execCtx.SetParam("clusterId", "cluster-1")
+
+	results, err := re.ExecuteAll(context.Background(), resources, execCtx)
+	require.NoError(t, err)
+
+	type applied struct{ name, kind, namespace, resourceName string }
+	var got []applied
+	for _, result := range results {
+		assert.Equal(t, StatusSuccess, result.Status)
+		assert.Equal(t, manifest.OperationCreate, result.Operation)
+		assert.NotEmpty(t, result.ContentHash)
+		got = append(got, applied{result.Name, result.Kind, result.Namespace, result.ResourceName})
+	}
+	assert.Equal(t, []applied{
+		{"bundle[0]", "Namespace", "", "cluster-1"},
+		{"bundle[1]", "ConfigMap", "cluster-1", "settings"},
+		{"bundle[2]", "ConfigMap", "cluster-1", "quotas"},
+		{"work", "ManifestWork", "cluster-1", "work-cluster-1"},
+	}, got, "each item is applied in order with its own result; the empty document is skipped")
+	assert.NotEqual(t, results[1].ContentHash, results[2].ContentHash)
+
+	assert.Equal(t, "cluster-1", mock.Resources["cluster-1/settings"].Object["data"].(map[string]interface{})["cluster_id"])
+	require.Contains(t, mock.Resources, "cluster-1/quotas")
+	discovered, ok := execCtx.Resources["bundle"].(*unstructured.Unstructured)
+	require.True(t, ok, "discovery finds the kind of the first item")
+	assert.Equal(t, "Namespace", discovered.GetKind())
+
+	work := mock.Resources["cluster-1/work-cluster-1"]
+	require.NotNil(t, work)
+	workloadManifests, _, err := unstructured.NestedSlice(work.Object, "spec", "workload", "manifests")
+	require.NoError(t, err)
+	require.Len(t, workloadManifests, 3, "the List is expanded into the manifests of the workload")
+	assert.Equal(t, "Namespace", workloadManifests[0].(map[string]interface{})["kind"])
+	assert.Equal(t, "quotas",
+		workloadManifests[2].(map[string]interface{})["metadata"].(map[string]interface{})["name"])
+
+	// The config manifest is left as is for the next event
+	assert.Len(t, list["items"], 4)
+	assert.Equal(t, "{{ .clusterId }}",
+		list["items"].([]interface{})[0].(map[string]interface{})["metadata"].(map[string]interface{})["name"])
+}
+
+// gvkRecordingClient records the kinds the resources are looked up with
+type gvkRecordingClient struct {
+	*k8sclient.MockK8sClient
+	lookups []string
+}
+
+func (c *gvkRecordingClient) GetResource(
+	ctx context.Context, gvk schema.GroupVersionKind, namespace, name string, target transportclient.TransportContext,
+) (*unstructured.Unstructured, error) {
+	c.lookups = append(c.lookups, gvk.Kind)
+	return c.MockK8sClient.GetResource(ctx, gvk, namespace, name, target)
+}
+
+func TestResourceExecutor_ExecuteAll_ListDiscoveryItem(t *testing.T) {
+	client := &gvkRecordingClient{MockK8sClient: k8sclient.NewMockK8sClient()}
+	re := newResourceExecutor(&ExecutorConfig{TransportClient: client, Logger: logger.NewTestLogger()})
+	execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+	execCtx.SetParam("clusterId", "cluster-1")
+
+	item := 1
+	resources := []configloader.Resource{{
+		Name:     "bundle",
+		Manifest: map[string]interface{}{"apiVersion": "v1", "kind": configloader.KindList, "items": bundleItems()},
+		Discovery: &configloader.DiscoveryConfig{
+			Namespace: "{{ .clusterId }}", ByName: "settings", Item: &item,
+		},
+	}}
+	results, err := re.ExecuteAll(context.Background(), resources, execCtx)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, []string{"ConfigMap"}, client.lookups, "discovery uses the kind of the selected item")
+	discovered, ok := execCtx.Resources["bundle"].(*unstructured.Unstructured)
+	require.True(t, ok)
+	assert.Equal(t, "ConfigMap", discovered.GetKind())
+	assert.Equal(t, "settings", discovered.GetName())
+
+	// An item beyond the rendered items fails the resource
+	item = 3
+	execCtx = NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+	execCtx.SetParam("clusterId", "cluster-1")
+	results, err = re.ExecuteAll(context.Background(), resources, execCtx)
+	require.Error(t, err)
+	assert.Equal(t, ErrorCodeDiscoveryFailed, ErrorCodeOf(err))
+	assert.Equal(t, StatusFailed, results[len(results)-1].Status)
+}
+
+func TestResourceExecutor_ExecuteAll_ListItemFailure(t *testing.T) {
+	mock := k8sclient.NewMockK8sClient()
+	mock.ApplyResourceError = errors.New("admission webhook denied the request")
+	re := newResourceExecutor(&ExecutorConfig{TransportClient: mock, Logger: logger.NewTestLogger()})
+	execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+	execCtx.SetParam("clusterId", "cluster-1")
+
+	resources := []configloader.Resource{
+		{Name: "bundle", Manifest: map[string]interface{}{
+			"apiVersion": "v1", "kind": configloader.KindList, "items": bundleItems(),
+		}},
+		{Name: "after", Manifest: map[string]interface{}{
+			"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "after"},
+		}},
+	}
+	results, err := re.ExecuteAll(context.Background(), resources, execCtx)
+	require.Error(t, err)
+	require.Len(t, results, 1, "the failed item stops the remaining items and resources")
+	assert.Equal(t, "bundle[0]", results[0].Name)
+	assert.Equal(t, StatusFailed, results[0].Status)
+	assert.Equal(t, "bundle[0]", execCtx.GetExecutionError().Step)
+
+	_, err = expandList(map[string]interface{}{
+		"apiVersion": "v1", "kind": configloader.KindList, "items": []interface{}{map[string]interface{}{}},
+	})
+	require.EqualError(t, err, "list manifest has no items")
+	_, err = expandList(map[string]interface{}{
+		"apiVersion": "v1", "kind": configloader.KindList, "items": []interface{}{"kind: Namespace"},
+	})
+	require.EqualError(t, err, "list item 0 is not a manifest: string")
+}
+
+// targetRecordingClient records the transport targets resources are applied with
+type targetRecordingClient struct {
+	*k8sclient.MockK8sClient
+	targets []transportclient.TransportContext
+}
+
+func (c *targetRecordingClient) ApplyResource(
+	ctx context.Context, manifestBytes []byte, opts *transportclient.ApplyOptions,
+	target transportclient.TransportContext,
+) (*transportclient.ApplyResult, error) {
+	c.targets = append(c.targets, target)
+	return c.MockK8sClient.ApplyResource(ctx, manifestBytes, opts, target)
+}
+
+func TestResourceExecutor_ExecuteAll_ManifestWorkMetadata(t *testing.T) {
+	workResource := func(metadata *configloader.ManifestWorkMetadata) []configloader.Resource {
+		return []configloader.Resource{{
+			Name: "work",
+			Transport: &configloader.TransportConfig{
+				Client: configloader.TransportClientMaestro,
+				Maestro: &configloader.MaestroTransportConfig{
+					TargetCluster: "{{ .clusterId }}", ManifestWorkMetadata: metadata,
+				},
+			},
+			Manifest: map[string]interface{}{
+				"apiVersion": "work.open-cluster-management.io/v1",
+				"kind":       "ManifestWork",
+				"metadata":   map[string]interface{}{"name": "work-{{ .clusterId }}"},
+			},
+		}}
+	}
+	execute := func(metadata *configloader.ManifestWorkMetadata) (*targetRecordingClient, []ResourceResult, error) {
+		client := &targetRecordingClient{MockK8sClient: k8sclient.NewMockK8sClient()}
+		re := newResourceExecutor(&ExecutorConfig{TransportClient: client, Logger: logger.NewTestLogger()})
+		execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+		execCtx.SetParam("clusterId", "cluster-1")
+		execCtx.SetParam("eventId", "")
+		results, err := re.ExecuteAll(context.Background(), workResource(metadata), execCtx)
+		return client, results, err
+	}
+
+	client, _, err := execute(&configloader.ManifestWorkMetadata{
+		Labels: map[string]string{
+			"hyperfleet.io/cluster-id": "{{ .clusterId }}",
+			"hyperfleet.io/event-id":   "{{ .eventId }}",
+		},
+		Annotations: map[string]string{"hyperfleet.io/adapter-version": "1.2.0"},
+		Precedence:  configloader.MetadataPrecedenceTemplate,
+	})
+	require.NoError(t, err)
+	require.Len(t, client.targets, 1)
+	target, ok := client.targets[0].(*maestroclient.TransportContext)
+	require.True(t, ok)
+	assert.Equal(t, "cluster-1", target.ConsumerName)
+	assert.Equal(t, map[string]string{"hyperfleet.io/cluster-id": "cluster-1"}, target.Labels,
+		"a label rendering empty is left out")
+	assert.Equal(t, map[string]string{"hyperfleet.io/adapter-version": "1.2.0"}, target.Annotations)
+	assert.True(t, target.KeepTemplateMetadata)
+
+	for name, metadata := range map[string]*configloader.ManifestWorkMetadata{
+		`invalid label key "hyperfleet.io/"`:           {Labels: map[string]string{"hyperfleet.io/": "x"}},
+		`invalid value "cluster 1" of label "cluster"`: {Labels: map[string]string{"cluster": "cluster 1"}},
+		`invalid annotation key "-adapter"`:            {Annotations: map[string]string{"-adapter": "x"}},
+	} {
+		client, results, err := execute(metadata)
+		require.Error(t, err, name)
+		assert.ErrorContains(t, err, name)
+		assert.Equal(t, ErrorCodeTemplateError, ErrorCodeOf(err))
+		assert.Equal(t, StatusFailed, results[0].Status)
+		assert.Empty(t, client.targets, "nothing is published")
+	}
+}
+
+func TestResourceExecutor_ExecuteAll_PolicyChecksWorkloadManifests(t *testing.T) {
+	policy := &configloader.ResourcePolicy{AllowedNamespaces: []string{"cluster-*"}}
+	work := func(manifests ...interface{}) []configloader.Resource {
+		return []configloader.Resource{{
+			Name: "work",
+			Transport: &configloader.TransportConfig{
+				Client:  configloader.TransportClientMaestro,
+				Maestro: &configloader.MaestroTransportConfig{TargetCluster: "{{ .clusterId }}"},
+			},
+			Manifest: map[string]interface{}{
+				"apiVersion": "work.open-cluster-management.io/v1",
+				"kind":       "ManifestWork",
+				"metadata":   map[string]interface{}{"name": "work-{{ .clusterId }}"},
+				"spec":       map[string]interface{}{"workload": map[string]interface{}{"manifests": manifests}},
+			},
+		}}
+	}
+	configMap := func(namespace string) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "v1", "kind": "ConfigMap",
+			"metadata": map[string]interface{}{"name": "settings", "namespace": namespace},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		manifests []interface{}
+		wantErr   string
+	}{
+		{name: "allowed namespace", manifests: []interface{}{configMap("cluster-{{ .clusterId }}")}},
+		{name: "namespace not allowed",
+			manifests: []interface{}{configMap("cluster-1"), configMap("{{ .systemNamespace }}")},
+			wantErr:   `ConfigMap "settings" violates policy rule allowed_namespaces: namespace "kube-system" is not allowed`},
+		{name: "cluster-scoped",
+			manifests: []interface{}{map[string]interface{}{
+				"apiVersion": "v1", "kind": "Namespace", "metadata": map[string]interface{}{"name": "cluster-1"},
+			}},
+			wantErr: `Namespace "cluster-1" violates policy rule cluster_scoped_allowed`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &targetRecordingClient{MockK8sClient: k8sclient.NewMockK8sClient()}
+			re := newResourceExecutor(&ExecutorConfig{
+				Config:          &configloader.Config{Policy: policy},
+				TransportClient: client,
+				Logger:          logger.NewTestLogger(),
+			})
+			execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+			execCtx.SetParam("clusterId", "1")
+			execCtx.SetParam("systemNamespace", "kube-system")
+
+			results, err := re.ExecuteAll(context.Background(), work(tt.manifests...), execCtx)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				assert.Len(t, client.targets, 1)
+				return
+			}
+			require.Error(t, err)
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.Equal(t, ErrorCodePolicyViolation, ErrorCodeOf(err))
+			assert.Equal(t, StatusFailed, results[0].Status)
+			assert.Empty(t, client.targets, "the ManifestWork is not published")
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package executor
+
+import (
+	"strings"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/manifest"
+)
+
+// Operations of the applied resources in the apply summary
+const (
+	AppliedCreated   = "created"
+	AppliedUpdated   = "updated"
+	AppliedUnchanged = "unchanged"
+)
+
+// AppliedResource is one entry of resources.applied: an object the resources
+// phase applied, or failed to apply
+type AppliedResource struct {
+	// Resource is the resource name from config, "<resource>[i]" for a List item
+	Resource  string `json:"resource"`
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	// Operation is created, updated or unchanged; empty when the apply failed
+	// before the object was reached
+	Operation string `json:"operation,omitempty"`
+	// Error is the error of a failed apply
+	Error string `json:"error,omitempty"`
+}
+
+// ResourceSummary summarizes the resources phase for the post payloads, which
+// see it in resources next to the discovered resources
+type ResourceSummary struct {
+	// Applied are the objects applied, or failed to apply, in order
+	Applied []AppliedResource `json:"applied"`
+	// FailedCount is the number of failed applies
+	FailedCount int `json:"failed_count"`
+	// SkippedCount is the number of configured resources not applied: all of
+	// them when the phase was skipped, the disabled ones and those after a failed
+	// one otherwise
+	SkippedCount int `json:"skipped_count"`
+}
+
+// summarizeResources summarizes the results of the resources phase of the
+// configured resources, including the results of a partially failed phase
+func summarizeResources(resources []configloader.Resource, results []ResourceResult) *ResourceSummary {
+	summary := &ResourceSummary{Applied: make([]AppliedResource, 0, len(results))}
+	reported := make(map[string]bool, len(results))
+	for _, result := range results {
+		applied := AppliedResource{
+			Resource:  result.Name,
+			Kind:      result.Kind,
+			Name:      result.ResourceName,
+			Namespace: result.Namespace,
+		}
+		if result.Status == StatusFailed {
+			summary.FailedCount++
+			if result.Error != nil {
+				applied.Error = result.Error.Error()
+			}
+		} else {
+			applied.Operation = appliedOperation(result.Operation)
+		}
+		summary.Applied = append(summary.Applied, applied)
+		// List items are reported as "<resource>[i]"
+		name, _, _ := strings.Cut(result.Name, "[")
+		reported[name] = true
+	}
+	for _, resource := range resources {
+		if !reported[resource.Name] {
+			summary.SkippedCount++
+		}
+	}
+	return summary
+}
+
+// appliedOperation returns the apply summary operation of a resource operation
+func appliedOperation(op manifest.Operation) string {
+	switch op {
+	case manifest.OperationCreate:
+		return AppliedCreated
+	case manifest.OperationSkip:
+		return AppliedUnchanged
+	default:
+		// Updates, recreations and finalizer patches change an existing object
+		return AppliedUpdated
+	}
+}
+
+// celValues returns the summary as the CEL values set in resources
+func (s *ResourceSummary) celValues() map[string]interface{} {
+	if s == nil {
+		s = &ResourceSummary{}
+	}
+	applied := make([]interface{}, len(s.Applied))
+	for i, a := range s.Applied {
+		applied[i] = map[string]interface{}{
+			"resource":  a.Resource,
+			"kind":      a.Kind,
+			"name":      a.Name,
+			"namespace": a.Namespace,
+			"operation": a.Operation,
+			"error":     a.Error,
+		}
+	}
+	return map[string]interface{}{
+		"applied":      applied,
+		"failedCount":  int64(s.FailedCount),
+		"skippedCount": int64(s.SkippedCount),
+	}
+}
@@ -0,0 +1,168 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/manifest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// summaryResources are the configured resources of the apply summary tests
+var summaryResources = []configloader.Resource{{Name: "namespace"}, {Name: "configs"}, {Name: "deployment"}}
+
+func appliedResult(name, kind, resourceName string, op manifest.Operation) ResourceResult {
+	return ResourceResult{
+		Name: name, Kind: kind, ResourceName: resourceName, Namespace: "cluster-123",
+		Status: StatusSuccess, Operation: op,
+	}
+}
+
+// TestBuildPostPayloads_ApplySummaryGolden pins the payloads built from the
+// apply summary when every resource is created, when the phase partially
+// failed, and when the resources were skipped
+func TestBuildPostPayloads_ApplySummaryGolden(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "payload", "apply_summary.yaml"))
+	require.NoError(t, err)
+	var build map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(data, &build))
+	payload := configloader.Payload{Name: "statusPayload", Build: build}
+
+	tests := []struct {
+		name    string
+		results []ResourceResult
+	}{
+		{
+			name: "all_created",
+			results: []ResourceResult{
+				appliedResult("namespace", "Namespace", "cluster-123", manifest.OperationCreate),
+				appliedResult("configs[0]", "ConfigMap", "settings", manifest.OperationCreate),
+				appliedResult("configs[1]", "Secret", "credentials", manifest.OperationCreate),
+				appliedResult("deployment", "Deployment", "agent", manifest.OperationCreate),
+			},
+		},
+		{
+			name: "mixed",
+			results: []ResourceResult{
+				appliedResult("namespace", "Namespace", "cluster-123", manifest.OperationSkip),
+				appliedResult("configs[0]", "ConfigMap", "settings", manifest.OperationUpdate),
+				{
+					Name: "configs[1]", Kind: "Secret", ResourceName: "credentials", Namespace: "cluster-123",
+					Status: StatusFailed, Error: errors.New("secrets \"credentials\" is forbidden"),
+				},
+			},
+		},
+		{name: "all_skipped"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pae := testPAE()
+			execCtx := clusterStatusExecCtx()
+			execCtx.SetResourceSummary(summarizeResources(summaryResources, tt.results))
+
+			require.NoError(t, pae.buildPostPayloads(context.Background(), pae.log, []configloader.Payload{payload}, execCtx))
+			got, ok := execCtx.ParamsSnapshot()[payload.Name].(string)
+			require.True(t, ok, "payload should be stored as json string in params")
+
+			golden := filepath.Join("testdata", "payload", "apply_summary_"+tt.name+".golden")
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(golden, []byte(got+"\n"), 0o600))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, strings.TrimSuffix(string(want), "\n"), got)
+		})
+	}
+}
+
+func TestBuildPostPayloads_ApplySummaryBeforeResources(t *testing.T) {
+	pae := testPAE()
+	execCtx := clusterStatusExecCtx()
+	payload := configloader.Payload{Name: "statusPayload", Build: map[string]interface{}{
+		"applied": map[string]interface{}{"expression": "size(resources.applied)"},
+		"skipped": map[string]interface{}{"expression": "resources.skippedCount"},
+	}}
+
+	require.NoError(t, pae.buildPostPayloads(context.Background(), pae.log, []configloader.Payload{payload}, execCtx))
+	assert.JSONEq(t, `{"applied":0,"skipped":0}`, execCtx.ParamsSnapshot()[payload.Name].(string),
+		"the summary is empty, not missing, without a resources phase")
+}
+
+// failingApplyClient fails the apply of the manifests named failName
+type failingApplyClient struct {
+	*k8sclient.MockK8sClient
+	failName string
+}
+
+func (c *failingApplyClient) ApplyResource(
+	ctx context.Context, manifestBytes []byte, opts *transportclient.ApplyOptions,
+	target transportclient.TransportContext,
+) (*transportclient.ApplyResult, error) {
+	if strings.Contains(string(manifestBytes), `"name":"`+c.failName+`"`) {
+		return nil, errors.New("admission webhook denied the request")
+	}
+	return c.MockK8sClient.ApplyResource(ctx, manifestBytes, opts, target)
+}
+
+func TestExecute_ApplySummaryOfPartiallyFailedResources(t *testing.T) {
+	configMap := func(name string) configloader.Resource {
+		return configloader.Resource{
+			Name: name,
+			Manifest: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+			},
+		}
+	}
+	exec, err := NewBuilder().
+		WithConfig(&configloader.Config{
+			Adapter:   configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+			Resources: []configloader.Resource{configMap("first"), configMap("second"), configMap("third")},
+			Post: &configloader.PostConfig{Payloads: []configloader.Payload{{
+				Name: "statusPayload",
+				Build: map[string]interface{}{
+					"applied": map[string]interface{}{"expression": "resources.applied"},
+					"failed":  map[string]interface{}{"expression": "resources.failedCount"},
+					"skipped": map[string]interface{}{"expression": "resources.skippedCount"},
+				},
+			}}},
+		}).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(&failingApplyClient{MockK8sClient: k8sclient.NewMockK8sClient(), failName: "second"}).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+
+	result := exec.Execute(context.Background(), map[string]interface{}{})
+	require.Equal(t, StatusFailed, result.Status)
+	require.Contains(t, result.Errors, PhaseResources)
+
+	var built struct {
+		Applied []map[string]interface{} `json:"applied"`
+		Failed  int                      `json:"failed"`
+		Skipped int                      `json:"skipped"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Params["statusPayload"].(string)), &built))
+	require.Len(t, built.Applied, 2)
+	assert.Equal(t, map[string]interface{}{
+		"resource": "first", "kind": "ConfigMap", "name": "first", "namespace": "default",
+		"operation": "created", "error": "",
+	}, built.Applied[0])
+	assert.Equal(t, "second", built.Applied[1]["resource"])
+	assert.Empty(t, built.Applied[1]["operation"])
+	assert.Contains(t, built.Applied[1]["error"], "admission webhook denied the request")
+	assert.Equal(t, 1, built.Failed)
+	assert.Equal(t, 1, built.Skipped, "third is not applied after second failed")
+}
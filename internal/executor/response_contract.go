@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/criteria"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// contractViolation is an assertion of an expect block violated by a response
+type contractViolation struct {
+	path    string
+	message string
+}
+
+func (v contractViolation) String() string {
+	return v.path + " " + v.message
+}
+
+// checkResponseContract asserts the expect block of a precondition against
+// its parsed response. The violations are logged and counted; unless the block
+// is warn_only they fail the precondition, all of them listed in one error.
+func (pe *PreconditionExecutor) checkResponseContract(
+	ctx context.Context, log logger.Logger, precond configloader.Precondition, response map[string]interface{},
+) error {
+	violations := responseViolations(precond.Expect, response)
+	if len(violations) == 0 {
+		return nil
+	}
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		pe.recorder.RecordAPIContractViolation(precond.Name, violation.path)
+		messages[i] = violation.String()
+	}
+	if precond.Expect.WarnOnly {
+		for _, message := range messages {
+			log.Warnf(ctx, "Precondition[%s] response violates its expected shape: %s", precond.Name, message)
+		}
+		return nil
+	}
+	return NewExecutorError(PhasePreconditions, ErrorCodeAPIContractViolation, precond.Name,
+		fmt.Sprintf("response violates %d expect assertion(s): %s", len(violations), strings.Join(messages, "; ")),
+		nil)
+}
+
+// responseViolations returns the assertions of expect violated by response, in
+// the order of the assertions
+func responseViolations(expect *configloader.ResponseExpectation, response map[string]interface{}) []contractViolation {
+	var violations []contractViolation
+	for _, assertion := range expect.Assertions {
+		matches, err := criteria.ExtractMatches(response, assertion.Path)
+		if err != nil {
+			// Paths are validated with the config
+			violations = append(violations, contractViolation{path: assertion.Path, message: err.Error()})
+			continue
+		}
+		present := 0
+		for i, value := range matches {
+			if value == nil {
+				continue
+			}
+			present++
+			if got := jsonType(value); assertion.Type != "" && got != assertion.Type {
+				message := fmt.Sprintf("is %s, expected %s", got, assertion.Type)
+				if len(matches) > 1 {
+					message = fmt.Sprintf("value %d is %s, expected %s", i, got, assertion.Type)
+				}
+				violations = append(violations, contractViolation{path: assertion.Path, message: message})
+			}
+		}
+		if assertion.Required && present == 0 {
+			message := "is missing"
+			if len(matches) > 0 {
+				message = "is null"
+			}
+			violations = append(violations, contractViolation{path: assertion.Path, message: message})
+		}
+	}
+	return violations
+}
+
+// jsonType returns the JSON type name of a value decoded from JSON
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return configloader.ResponseTypeString
+	case bool:
+		return configloader.ResponseTypeBoolean
+	case float64, float32, int, int32, int64, json.Number:
+		return configloader.ResponseTypeNumber
+	case map[string]interface{}:
+		return configloader.ResponseTypeObject
+	case []interface{}:
+		return configloader.ResponseTypeArray
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
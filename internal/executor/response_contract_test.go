@@ -0,0 +1,138 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const contractTestResponse = `{
+	"id": "c1",
+	"generation": 3,
+	"labels": null,
+	"spec": {"region": 7},
+	"status": {"conditions": [{"type": "Ready", "status": "True"}, {"type": "Available", "status": false}]}
+}`
+
+func contractTestConfig(expect *configloader.ResponseExpectation) *configloader.Config {
+	precond := capturingPrecondition("cluster", "/clusters/c1", "clusterId", "id")
+	precond.Expect = expect
+	return &configloader.Config{Preconditions: []configloader.Precondition{precond}}
+}
+
+func serveContractTestResponse(w http.ResponseWriter, _ *http.Request) {
+	_, _ = w.Write([]byte(contractTestResponse))
+}
+
+func TestResponseContract(t *testing.T) {
+	tests := []struct {
+		name       string
+		assertions []configloader.ResponseAssertion
+		wantError  string
+	}{
+		{
+			name: "matching response",
+			assertions: []configloader.ResponseAssertion{
+				{Path: "id", Type: "string", Required: true},
+				{Path: "generation", Type: "number"},
+				{Path: "status.conditions", Type: "array", Required: true},
+				{Path: "status.conditions[0].type", Type: "string", Required: true},
+				{Path: "spec", Type: "object"},
+				{Path: "labels", Type: "object"},
+				{Path: "deletedTime", Type: "string"},
+			},
+		},
+		{
+			name:       "type mismatch",
+			assertions: []configloader.ResponseAssertion{{Path: "spec.region", Type: "string"}},
+			wantError:  "response violates 1 expect assertion(s): spec.region is number, expected string",
+		},
+		{
+			name:       "missing path",
+			assertions: []configloader.ResponseAssertion{{Path: "status.phase", Type: "string", Required: true}},
+			wantError:  "status.phase is missing",
+		},
+		{
+			name:       "required null",
+			assertions: []configloader.ResponseAssertion{{Path: "labels", Required: true}},
+			wantError:  "labels is null",
+		},
+		{
+			name: "array index paths",
+			assertions: []configloader.ResponseAssertion{
+				{Path: "status.conditions[1].status", Type: "string"},
+				{Path: "status.conditions[5].type", Required: true},
+			},
+			wantError: "status.conditions[1].status is boolean, expected string; status.conditions[5].type is missing",
+		},
+		{
+			name:       "every match of a wildcard path",
+			assertions: []configloader.ResponseAssertion{{Path: "{.status.conditions[*].status}", Type: "string"}},
+			wantError:  "{.status.conditions[*].status} value 1 is boolean, expected string",
+		},
+		{
+			name: "all violations listed",
+			assertions: []configloader.ResponseAssertion{
+				{Path: "id", Type: "number"},
+				{Path: "generation", Type: "number"},
+				{Path: "spec", Type: "array"},
+				{Path: "status.phase", Required: true},
+			},
+			wantError: "response violates 3 expect assertion(s): id is string, expected number; " +
+				"spec is object, expected array; status.phase is missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := contractTestConfig(&configloader.ResponseExpectation{Assertions: tt.assertions})
+			exec := newPreconditionTestExecutor(t, config, serveContractTestResponse)
+
+			result := exec.Execute(context.Background(), map[string]interface{}{})
+			if tt.wantError == "" {
+				require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+				clusterID, _ := result.ExecutionContext.GetParam("clusterId")
+				assert.Equal(t, "c1", clusterID)
+				return
+			}
+			require.Equal(t, StatusFailed, result.Status)
+			err := result.Errors[PhasePreconditions]
+			assert.Equal(t, ErrorCodeAPIContractViolation, ErrorCodeOf(err))
+			assert.ErrorContains(t, err, tt.wantError)
+			_, captured := result.ExecutionContext.GetParam("clusterId")
+			assert.False(t, captured, "the captures do not run")
+			require.NotNil(t, result.ExecutionContext.GetExecutionError())
+			assert.Equal(t, ErrorCodeAPIContractViolation, result.ExecutionContext.GetExecutionError().Code)
+		})
+	}
+}
+
+func TestResponseContract_WarnOnly(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewRecorder("test-adapter", "v0.1.0", registry)
+	config := contractTestConfig(&configloader.ResponseExpectation{
+		WarnOnly: true,
+		Assertions: []configloader.ResponseAssertion{
+			{Path: "spec.region", Type: "string"},
+			{Path: "status.phase", Required: true},
+		},
+	})
+	exec := newPreconditionTestExecutor(t, config, serveContractTestResponse, recorder)
+
+	result := exec.Execute(context.Background(), map[string]interface{}{})
+	require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+	clusterID, _ := result.ExecutionContext.GetParam("clusterId")
+	assert.Equal(t, "c1", clusterID)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, path := range []string{"spec.region", "status.phase"} {
+		assert.Equal(t, float64(1), getCounterValue(t, families, "hyperfleet_adapter_api_contract_violations_total",
+			"path", path), path)
+	}
+}
@@ -0,0 +1,267 @@
+package executor
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/webhook"
+)
+
+// resultJSON is the JSON form of ExecutionResult. Errors are their messages and codes;
+// the execution context is left out, and API responses only appear, cut to the
+// retained size, in the API call records.
+type resultJSON struct {
+	Params             map[string]interface{}         `json:"params,omitempty"`
+	Errors             map[ExecutionPhase]string      `json:"errors,omitempty"`
+	ErrorCodes         map[ExecutionPhase]ErrorCode   `json:"error_codes,omitempty"`
+	PhaseDurationsMs   map[ExecutionPhase]int64       `json:"phase_durations_ms,omitempty"`
+	Status             ExecutionStatus                `json:"status"`
+	Phase              ExecutionPhase                 `json:"phase"`
+	TraceID            string                         `json:"trace_id,omitempty"`
+	CorrelationID      string                         `json:"correlation_id,omitempty"`
+	ExecutionKey       string                         `json:"execution_key,omitempty"`
+	Workflow           string                         `json:"workflow,omitempty"`
+	DataContentType    string                         `json:"data_content_type,omitempty"`
+	DataDecision       DataDecision                   `json:"data_decision,omitempty"`
+	SkipReason         string                         `json:"skip_reason,omitempty"`
+	SchemaViolations   []configloader.SchemaViolation `json:"schema_violations,omitempty"`
+	Preconditions      []preconditionResultJSON       `json:"preconditions,omitempty"`
+	PreconditionErrors []stepErrorJSON                `json:"precondition_errors,omitempty"`
+	Resources          []resourceResultJSON           `json:"resources,omitempty"`
+	PostActions        []postActionResultJSON         `json:"post_actions,omitempty"`
+	APICalls           []apiCallJSON                  `json:"api_calls,omitempty"`
+	PayloadReports     []PayloadBuildReport           `json:"payload_reports,omitempty"`
+	RetryBudget        *retryBudgetJSON               `json:"retry_budget,omitempty"`
+	FeatureFlags       map[string]bool                `json:"feature_flags,omitempty"`
+	DurationMs         int64                          `json:"duration_ms"`
+	ResourcesSkipped   bool                           `json:"resources_skipped"`
+}
+
+type preconditionResultJSON struct {
+	StartedAt      time.Time              `json:"started_at"`
+	CapturedFields map[string]interface{} `json:"captured_fields,omitempty"`
+	APICall        *apiCallJSON           `json:"api_call,omitempty"`
+	Name           string                 `json:"name"`
+	Status         ExecutionStatus        `json:"status"`
+	Error          string                 `json:"error,omitempty"`
+	APITarget      string                 `json:"api_target,omitempty"`
+	DurationMs     int64                  `json:"duration_ms"`
+	RetryWaitMs    int64                  `json:"retry_wait_ms,omitempty"`
+	Matched        bool                   `json:"matched"`
+	APICallMade    bool                   `json:"api_call_made"`
+}
+
+// stepErrorJSON is the JSON form of the error of a step
+type stepErrorJSON struct {
+	Step  string    `json:"step,omitempty"`
+	Code  ErrorCode `json:"code"`
+	Error string    `json:"error"`
+}
+
+type resourceResultJSON struct {
+	StartedAt    time.Time       `json:"started_at"`
+	Name         string          `json:"name"`
+	Kind         string          `json:"kind,omitempty"`
+	Namespace    string          `json:"namespace,omitempty"`
+	ResourceName string          `json:"resource_name,omitempty"`
+	Operation    string          `json:"operation,omitempty"`
+	Reason       string          `json:"reason,omitempty"`
+	Status       ExecutionStatus `json:"status"`
+	Error        string          `json:"error,omitempty"`
+	DurationMs   int64           `json:"duration_ms"`
+	RetryWaitMs  int64           `json:"retry_wait_ms,omitempty"`
+}
+
+type postActionResultJSON struct {
+	StartedAt       time.Time       `json:"started_at"`
+	APICall         *apiCallJSON    `json:"api_call,omitempty"`
+	Name            string          `json:"name"`
+	Status          ExecutionStatus `json:"status"`
+	SkipReason      string          `json:"skip_reason,omitempty"`
+	Error           string          `json:"error,omitempty"`
+	ResourceVersion string          `json:"resource_version,omitempty"`
+	APITarget       string          `json:"api_target,omitempty"`
+	HTTPStatus      int             `json:"http_status,omitempty"`
+	DurationMs      int64           `json:"duration_ms"`
+	RetryWaitMs     int64           `json:"retry_wait_ms,omitempty"`
+	Skipped         bool            `json:"skipped"`
+	APICallMade     bool            `json:"api_call_made"`
+	K8sPatchMade    bool            `json:"k8s_patch_made,omitempty"`
+	Buffered        bool            `json:"buffered,omitempty"`
+	Webhook         *webhookJSON    `json:"webhook,omitempty"`
+}
+
+// retryBudgetJSON is the JSON form of the use of the retry budget
+type retryBudgetJSON struct {
+	BudgetMs  int64 `json:"budget_ms,omitempty"`
+	SpentMs   int64 `json:"spent_ms"`
+	Exhausted bool  `json:"exhausted,omitempty"`
+}
+
+// webhookJSON is the JSON form of a webhook delivery
+type webhookJSON struct {
+	StatusCode int   `json:"status_code,omitempty"`
+	Attempts   int   `json:"attempts"`
+	LatencyMs  int64 `json:"latency_ms"`
+}
+
+// apiCallJSON is the JSON form of APICallRecord
+type apiCallJSON struct {
+	Phase             ExecutionPhase `json:"phase"`
+	Step              string         `json:"step"`
+	Method            string         `json:"method"`
+	URL               string         `json:"url,omitempty"`
+	Target            string         `json:"target,omitempty"`
+	Error             string         `json:"error,omitempty"`
+	RequestID         string         `json:"request_id,omitempty"`
+	ResponseBody      string         `json:"response_body,omitempty"`
+	StatusCode        int            `json:"status_code,omitempty"`
+	LatencyMs         int64          `json:"latency_ms"`
+	Attempts          int            `json:"attempts,omitempty"`
+	ResponseTruncated bool           `json:"response_truncated,omitempty"`
+}
+
+func newAPICallJSON(call *APICallRecord) *apiCallJSON {
+	if call == nil {
+		return nil
+	}
+	return &apiCallJSON{
+		Phase:             call.Phase,
+		Step:              call.Step,
+		Method:            call.Method,
+		URL:               call.URL,
+		Target:            call.Target,
+		Error:             call.Error,
+		RequestID:         call.RequestID,
+		ResponseBody:      string(call.ResponseBody),
+		StatusCode:        call.StatusCode,
+		LatencyMs:         call.Latency.Milliseconds(),
+		Attempts:          call.Attempts,
+		ResponseTruncated: call.ResponseTruncated,
+	}
+}
+
+// MarshalJSON serializes the outcome of the execution and of every step
+func (r *ExecutionResult) MarshalJSON() ([]byte, error) {
+	out := resultJSON{
+		Params:           r.Params,
+		Status:           r.Status,
+		Phase:            r.CurrentPhase,
+		TraceID:          r.TraceID,
+		CorrelationID:    r.CorrelationID,
+		ExecutionKey:     r.ExecutionKey,
+		Workflow:         r.Workflow,
+		DataContentType:  r.DataContentType,
+		DataDecision:     r.DataDecision,
+		SkipReason:       r.SkipReason,
+		SchemaViolations: r.SchemaViolations,
+		DurationMs:       r.Duration.Milliseconds(),
+		ResourcesSkipped: r.ResourcesSkipped,
+	}
+	if len(r.PhaseDurations) > 0 {
+		out.PhaseDurationsMs = make(map[ExecutionPhase]int64, len(r.PhaseDurations))
+		for phase, d := range r.PhaseDurations {
+			out.PhaseDurationsMs[phase] = d.Milliseconds()
+		}
+	}
+	if len(r.Errors) > 0 {
+		out.Errors = make(map[ExecutionPhase]string, len(r.Errors))
+		out.ErrorCodes = make(map[ExecutionPhase]ErrorCode, len(r.Errors))
+		for phase, err := range r.Errors {
+			out.Errors[phase] = errorString(err)
+			out.ErrorCodes[phase] = ErrorCodeOf(err)
+		}
+	}
+	for _, pr := range r.PreconditionResults {
+		out.Preconditions = append(out.Preconditions, preconditionResultJSON{
+			StartedAt:      pr.StartedAt,
+			DurationMs:     pr.Duration.Milliseconds(),
+			RetryWaitMs:    pr.RetryWait.Milliseconds(),
+			CapturedFields: pr.CapturedFields,
+			APICall:        newAPICallJSON(pr.APICall),
+			Name:           pr.Name,
+			Status:         pr.Status,
+			Error:          errorString(pr.Error),
+			APITarget:      pr.APITarget,
+			Matched:        pr.Matched,
+			APICallMade:    pr.APICallMade,
+		})
+	}
+	for _, err := range r.PreconditionErrors {
+		stepErr := stepErrorJSON{Code: ErrorCodeOf(err), Error: err.Error()}
+		var execErr *ExecutorError
+		if errors.As(err, &execErr) {
+			stepErr.Step = execErr.Step
+		}
+		out.PreconditionErrors = append(out.PreconditionErrors, stepErr)
+	}
+	for _, rr := range r.ResourceResults {
+		out.Resources = append(out.Resources, resourceResultJSON{
+			StartedAt:    rr.StartedAt,
+			DurationMs:   rr.Duration.Milliseconds(),
+			RetryWaitMs:  rr.RetryWait.Milliseconds(),
+			Name:         rr.Name,
+			Kind:         rr.Kind,
+			Namespace:    rr.Namespace,
+			ResourceName: rr.ResourceName,
+			Operation:    string(rr.Operation),
+			Reason:       rr.OperationReason,
+			Status:       rr.Status,
+			Error:        errorString(rr.Error),
+		})
+	}
+	for _, pa := range r.PostActionResults {
+		out.PostActions = append(out.PostActions, postActionResultJSON{
+			StartedAt:       pa.StartedAt,
+			DurationMs:      pa.Duration.Milliseconds(),
+			RetryWaitMs:     pa.RetryWait.Milliseconds(),
+			APICall:         newAPICallJSON(pa.APICall),
+			Name:            pa.Name,
+			Status:          pa.Status,
+			SkipReason:      pa.SkipReason,
+			Error:           errorString(pa.Error),
+			ResourceVersion: pa.ResourceVersion,
+			APITarget:       pa.APITarget,
+			HTTPStatus:      pa.HTTPStatus,
+			Skipped:         pa.Skipped,
+			APICallMade:     pa.APICallMade,
+			K8sPatchMade:    pa.K8sPatchMade,
+			Buffered:        pa.Buffered,
+			Webhook:         newWebhookJSON(pa.Webhook),
+		})
+	}
+	for i := range r.APICalls {
+		out.APICalls = append(out.APICalls, *newAPICallJSON(&r.APICalls[i]))
+	}
+	out.PayloadReports = r.PayloadReports
+	if budget := r.RetryBudget; budget != nil && (budget.Budget > 0 || budget.Spent > 0) {
+		out.RetryBudget = &retryBudgetJSON{
+			BudgetMs:  budget.Budget.Milliseconds(),
+			SpentMs:   budget.Spent.Milliseconds(),
+			Exhausted: budget.Exhausted,
+		}
+	}
+	out.FeatureFlags = r.FeatureFlags
+	return json.Marshal(out)
+}
+
+// newWebhookJSON returns the JSON form of a webhook delivery, nil for none
+func newWebhookJSON(delivery *webhook.Delivery) *webhookJSON {
+	if delivery == nil {
+		return nil
+	}
+	return &webhookJSON{
+		StatusCode: delivery.StatusCode,
+		Attempts:   delivery.Attempts,
+		LatencyMs:  delivery.Latency.Milliseconds(),
+	}
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,118 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/manifest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionResult_MarshalJSON(t *testing.T) {
+	result := &ExecutionResult{
+		Params: map[string]interface{}{"clusterId": "c1"},
+		Errors: map[ExecutionPhase]error{PhasePostActions: fmt.Errorf("post action execution failed: %w",
+			NewExecutorError(PhasePostActions, ErrorCodeAPIUnexpectedStatus, "reportStatus", "status 500", nil))},
+		Status:           StatusFailed,
+		CurrentPhase:     PhasePostActions,
+		PhaseDurations:   map[ExecutionPhase]time.Duration{PhasePostActions: 1500 * time.Millisecond},
+		Duration:         2 * time.Second,
+		ExecutionContext: NewExecutionContext(context.Background(), map[string]interface{}{"id": "c1"}, nil),
+		PreconditionResults: []PreconditionResult{{
+			Name: "clusterStatus", Status: StatusSuccess, Matched: true, APICallMade: true,
+			CapturedFields: map[string]interface{}{"phase": "Ready"},
+			APIResponse:    []byte(`{"raw":true}`),
+		}},
+		ResourceResults: []ResourceResult{{
+			Name: "ns", Kind: "Namespace", ResourceName: "cluster-c1", Status: StatusSuccess,
+			Operation: manifest.OperationCreate, OperationReason: "resource not found",
+		}},
+		PostActionResults: []PostActionResult{{
+			StartedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), Duration: 1500 * time.Millisecond,
+			Name: "reportStatus", Status: StatusFailed, Error: errors.New("status 500"),
+			HTTPStatus: 500, APICallMade: true,
+		}},
+	}
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	var out map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, "failed", out["status"])
+	assert.Equal(t, "post_actions", out["phase"])
+	assert.Equal(t, map[string]interface{}{
+		"post_actions": "post action execution failed: [post_actions] reportStatus: status 500",
+	}, out["errors"])
+	assert.Equal(t, map[string]interface{}{"post_actions": "APIUnexpectedStatus"}, out["error_codes"])
+	assert.Equal(t, map[string]interface{}{"clusterId": "c1"}, out["params"])
+	assert.Equal(t, float64(2000), out["duration_ms"])
+	assert.Equal(t, map[string]interface{}{"post_actions": float64(1500)}, out["phase_durations_ms"])
+	assert.NotContains(t, string(data), "raw", "raw API responses are left out")
+
+	precondition := out["preconditions"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, true, precondition["matched"])
+	assert.Equal(t, map[string]interface{}{"phase": "Ready"}, precondition["captured_fields"])
+
+	resource := out["resources"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "create", resource["operation"])
+	assert.Equal(t, "cluster-c1", resource["resource_name"])
+
+	postAction := out["post_actions"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "status 500", postAction["error"])
+	assert.Equal(t, float64(500), postAction["http_status"])
+	assert.Equal(t, "2026-03-01T12:00:00Z", postAction["started_at"])
+	assert.Equal(t, float64(1500), postAction["duration_ms"])
+}
+
+// BenchmarkExecutionResult_MarshalJSON compares the serialization of the result of
+// an execution calling an API with a 1 MiB response, compacted and not
+func BenchmarkExecutionResult_MarshalJSON(b *testing.B) {
+	body := []byte(`{"status":"ok","logs":"` + strings.Repeat("x", 1024*1024) + `"}`)
+	for _, bm := range []struct {
+		name   string
+		limits *configloader.Limits
+	}{
+		{name: "compacted"},
+		{name: "raw responses kept", limits: &configloader.Limits{KeepRawResponses: true}},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			apiClient := newMockAPIClient()
+			apiClient.GetResponse = &hyperfleetapi.Response{StatusCode: 200, Status: "200 OK", Body: body}
+			exec, err := NewBuilder().
+				WithConfig(&configloader.Config{
+					Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+					Preconditions: []configloader.Precondition{{ActionBase: configloader.ActionBase{
+						Name:    "clusterStatus",
+						APICall: &configloader.APICall{Method: "GET", URL: "/clusters/cluster-1"},
+					}}},
+					Limits: bm.limits,
+				}).
+				WithAPIClient(apiClient).
+				WithTransportClient(k8sclient.NewMockK8sClient()).
+				WithLogger(logger.NewTestLogger()).
+				Build()
+			require.NoError(b, err)
+			result := exec.Execute(context.Background(), map[string]interface{}{"id": "cluster-1"})
+			require.Equal(b, StatusSuccess, result.Status, "errors: %v", result.Errors)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := json.Marshal(result); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
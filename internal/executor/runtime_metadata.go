@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"os"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/version"
+)
+
+// Downward API environment variables read by RuntimeMetadataFromEnv
+const (
+	EnvPodName      = "POD_NAME"
+	EnvPodNamespace = "POD_NAMESPACE"
+	EnvNodeName     = "NODE_NAME"
+)
+
+// Keys of the runtime metadata in the adapter map of templates and CEL
+// expressions, e.g. adapter.podName. The keys are always present; unknown
+// values are empty strings so expressions referencing them never fail.
+const (
+	RuntimeKeyPodName      = "podName"
+	RuntimeKeyPodNamespace = "podNamespace"
+	RuntimeKeyNodeName     = "nodeName"
+	RuntimeKeyBuildVersion = "buildVersion"
+	RuntimeKeyBuildCommit  = "buildCommit"
+	RuntimeKeyConfigHash   = "configHash"
+)
+
+// AdapterKeyCorrelationID is the key of the execution correlation ID in the
+// adapter map of templates and CEL expressions: adapter.correlationId
+const AdapterKeyCorrelationID = "correlationId"
+
+// AdapterKeyWorkflow is the key of the name of the selected workflow in the
+// adapter map of templates and CEL expressions: adapter.workflow
+const AdapterKeyWorkflow = "workflow"
+
+// AdapterKeyDeferredUntil is the key of the RFC3339 time the next maintenance
+// window opens, set outside every window of the schedule, in the adapter map of
+// templates and CEL expressions: adapter.deferredUntil
+const AdapterKeyDeferredUntil = "deferredUntil"
+
+// RuntimeMetadata identifies the adapter instance processing an event, so post
+// payloads can tell which pod, build and configuration produced a status
+type RuntimeMetadata struct {
+	PodName      string
+	PodNamespace string
+	NodeName     string
+	BuildVersion string
+	BuildCommit  string
+	ConfigHash   string
+}
+
+// RuntimeMetadataFromEnv returns the runtime metadata of this process: pod,
+// namespace and node from the downward API environment variables, build
+// version and commit from the ldflags variables, and the given config hash
+func RuntimeMetadataFromEnv(configHash string) RuntimeMetadata {
+	return RuntimeMetadata{
+		PodName:      os.Getenv(EnvPodName),
+		PodNamespace: os.Getenv(EnvPodNamespace),
+		NodeName:     os.Getenv(EnvNodeName),
+		BuildVersion: version.Version,
+		BuildCommit:  version.Commit,
+		ConfigHash:   configHash,
+	}
+}
+
+// addTo sets the runtime metadata keys in the adapter map m
+func (r RuntimeMetadata) addTo(m map[string]interface{}) {
+	m[RuntimeKeyPodName] = r.PodName
+	m[RuntimeKeyPodNamespace] = r.PodNamespace
+	m[RuntimeKeyNodeName] = r.NodeName
+	m[RuntimeKeyBuildVersion] = r.BuildVersion
+	m[RuntimeKeyBuildCommit] = r.BuildCommit
+	m[RuntimeKeyConfigHash] = r.ConfigHash
+}
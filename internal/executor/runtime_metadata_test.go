@@ -0,0 +1,135 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unsetEnv unsets key for the duration of the test
+func unsetEnv(t *testing.T, key string) {
+	t.Setenv(key, "")
+	require.NoError(t, os.Unsetenv(key))
+}
+
+func TestRuntimeMetadataFromEnv(t *testing.T) {
+	t.Run("populated", func(t *testing.T) {
+		t.Setenv(EnvPodName, "adapter-7d9f-x2k4p")
+		t.Setenv(EnvPodNamespace, "hyperfleet")
+		t.Setenv(EnvNodeName, "worker-1")
+
+		runtime := RuntimeMetadataFromEnv("abc123")
+		assert.Equal(t, RuntimeMetadata{
+			PodName:      "adapter-7d9f-x2k4p",
+			PodNamespace: "hyperfleet",
+			NodeName:     "worker-1",
+			BuildVersion: version.Version,
+			BuildCommit:  version.Commit,
+			ConfigHash:   "abc123",
+		}, runtime)
+	})
+
+	t.Run("bare", func(t *testing.T) {
+		unsetEnv(t, EnvPodName)
+		unsetEnv(t, EnvPodNamespace)
+		unsetEnv(t, EnvNodeName)
+
+		runtime := RuntimeMetadataFromEnv("")
+		assert.Empty(t, runtime.PodName)
+		assert.Empty(t, runtime.PodNamespace)
+		assert.Empty(t, runtime.NodeName)
+		assert.Empty(t, runtime.ConfigHash)
+		assert.Equal(t, version.Version, runtime.BuildVersion)
+	})
+}
+
+// The runtime keys are present in the adapter map of params and CEL expressions,
+// with empty strings when the environment provides nothing
+func TestExecute_RuntimeMetadata(t *testing.T) {
+	tests := []struct {
+		setup      func(t *testing.T, builder *ExecutorBuilder)
+		expected   map[string]interface{}
+		name       string
+		expression string
+	}{
+		{
+			name: "populated",
+			setup: func(t *testing.T, builder *ExecutorBuilder) {
+				t.Setenv(EnvPodName, "adapter-0")
+				t.Setenv(EnvPodNamespace, "hyperfleet")
+				t.Setenv(EnvNodeName, "worker-1")
+				builder.WithRuntimeMetadata(RuntimeMetadataFromEnv("abc123"))
+			},
+			expression: `adapter.podName == "adapter-0" && adapter.configHash == "abc123"`,
+			expected: map[string]interface{}{
+				RuntimeKeyPodName:      "adapter-0",
+				RuntimeKeyPodNamespace: "hyperfleet",
+				RuntimeKeyNodeName:     "worker-1",
+				RuntimeKeyBuildVersion: version.Version,
+				RuntimeKeyBuildCommit:  version.Commit,
+				RuntimeKeyConfigHash:   "abc123",
+			},
+		},
+		{
+			name: "bare",
+			setup: func(t *testing.T, _ *ExecutorBuilder) {
+				unsetEnv(t, EnvPodName)
+				unsetEnv(t, EnvPodNamespace)
+				unsetEnv(t, EnvNodeName)
+			},
+			expression: `adapter.podName == "" && adapter.nodeName == "" && adapter.configHash == ""`,
+			expected: map[string]interface{}{
+				RuntimeKeyPodName:      "",
+				RuntimeKeyPodNamespace: "",
+				RuntimeKeyNodeName:     "",
+				RuntimeKeyBuildVersion: version.Version,
+				RuntimeKeyBuildCommit:  version.Commit,
+				RuntimeKeyConfigHash:   "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &configloader.Config{
+				Adapter: configloader.AdapterInfo{
+					Name:    "test-adapter",
+					Version: "1.0.0",
+				},
+				Preconditions: []configloader.Precondition{
+					{ActionBase: configloader.ActionBase{Name: "check-runtime"}, Expression: tt.expression},
+				},
+			}
+			builder := NewBuilder().
+				WithConfig(config).
+				WithAPIClient(newMockAPIClient()).
+				WithTransportClient(k8sclient.NewMockK8sClient()).
+				WithLogger(logger.NewTestLogger())
+			tt.setup(t, builder)
+			exec, err := builder.Build()
+			require.NoError(t, err)
+
+			result := exec.Execute(logger.WithEventID(context.Background(), "test-runtime"), map[string]interface{}{})
+			require.Empty(t, result.Errors)
+
+			require.Len(t, result.PreconditionResults, 1)
+			assert.True(t, result.PreconditionResults[0].Matched, "runtime keys are available to CEL")
+
+			params, ok := result.Params["adapter"].(map[string]interface{})
+			require.True(t, ok)
+			assert.Equal(t, "test-adapter", params["name"])
+			adapter := adapterMetadataToMap(&result.ExecutionContext.Adapter)
+			for key, want := range tt.expected {
+				assert.Equal(t, want, params[key], "params adapter.%s", key)
+				assert.Equal(t, want, adapter[key], "metadata adapter.%s", key)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+)
+
+// SkipReasonOutsideSchedule is the SkipReason of an execution whose resources
+// were skipped outside every maintenance window of the schedule
+const SkipReasonOutsideSchedule = "OutsideMaintenanceWindow"
+
+// scheduleGate is the outcome of the schedule of an execution
+type scheduleGate struct {
+	// next is when the next window opens, zero if none opens within the next years
+	next time.Time
+	// now is when the schedule was evaluated
+	now time.Time
+	// closed is set outside every window
+	closed bool
+}
+
+// deferred reports whether the execution is deferred until the next window:
+// closed under the defer policy
+func (g scheduleGate) deferred(policy string) bool {
+	return g.closed && policy == configloader.SchedulePolicyDefer
+}
+
+// retryAfter returns the delay until the next window opens, zero if none opens
+func (g scheduleGate) retryAfter() time.Duration {
+	if g.next.IsZero() {
+		return 0
+	}
+	return g.next.Sub(g.now)
+}
+
+// evaluateSchedule evaluates the schedule at the executor clock's now. Outside
+// every window, the next window opening is logged and exposed to payloads as
+// adapter.deferredUntil.
+func (e *Executor) evaluateSchedule(ctx context.Context, execCtx *ExecutionContext) scheduleGate {
+	gate := scheduleGate{now: e.clock.Now()}
+	if e.schedule == nil {
+		return gate
+	}
+	if open, _ := e.schedule.Open(gate.now); open {
+		return gate
+	}
+	gate.closed = true
+	next, ok := e.schedule.NextOpen(gate.now)
+	if !ok {
+		e.log.Warnf(ctx, "Outside the maintenance windows: no window opens within the next years")
+		return gate
+	}
+	gate.next = next
+	execCtx.SetDeferredUntil(next)
+	e.log.Infof(ctx, "Outside the maintenance windows: next window opens at %s (policy=%s)",
+		next.UTC().Format(time.RFC3339), e.config.Config.Schedule.EffectivePolicy())
+	return gate
+}
+
+// skipOutsideSchedule skips the resources of an execution outside every window
+func skipOutsideSchedule(result *ExecutionResult, execCtx *ExecutionContext, gate scheduleGate) {
+	message := "outside the maintenance windows"
+	if !gate.next.IsZero() {
+		message = fmt.Sprintf("outside the maintenance windows until %s", gate.next.UTC().Format(time.RFC3339))
+	}
+	result.ResourcesSkipped = true
+	result.SkipReason = SkipReasonOutsideSchedule
+	result.DeferredUntil = gate.next
+	execCtx.SetSkipped(SkipReasonOutsideSchedule, message)
+}
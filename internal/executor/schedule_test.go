@@ -0,0 +1,122 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule_GatesResources(t *testing.T) {
+	// A window from 22:00 Friday to 02:00 Saturday in Berlin; 2026-01-02 is a Friday
+	sched := &configloader.Schedule{
+		Timezone: "Europe/Berlin",
+		Windows:  []configloader.ScheduleWindow{{Start: "0 22 * * FRI", Duration: 4 * time.Hour}},
+	}
+	nextWindow := time.Date(2026, 1, 2, 21, 0, 0, 0, time.UTC) // 22:00 CET
+	tests := []struct {
+		now               time.Time
+		name              string
+		policy            string
+		wantSkipReason    string
+		wantDeferredUntil string
+		wantRetryAfter    time.Duration
+		wantResourcesSkip bool
+		wantPreconditions bool
+	}{
+		{
+			name:              "defer outside the windows",
+			now:               time.Date(2026, 1, 2, 20, 0, 0, 0, time.UTC),
+			wantResourcesSkip: true,
+			wantSkipReason:    SkipReasonOutsideSchedule,
+			wantDeferredUntil: "2026-01-02T21:00:00Z",
+			wantRetryAfter:    time.Hour,
+		},
+		{
+			name:              "defer inside a window spanning midnight",
+			now:               time.Date(2026, 1, 3, 0, 30, 0, 0, time.UTC),
+			wantPreconditions: true,
+		},
+		{
+			name:              "skipResources outside the windows",
+			policy:            configloader.SchedulePolicySkipResources,
+			now:               time.Date(2026, 1, 2, 20, 0, 0, 0, time.UTC),
+			wantPreconditions: true,
+			wantResourcesSkip: true,
+			wantSkipReason:    SkipReasonOutsideSchedule,
+			wantDeferredUntil: "2026-01-02T21:00:00Z",
+		},
+		{
+			name:              "skipResources inside a window",
+			policy:            configloader.SchedulePolicySkipResources,
+			now:               nextWindow,
+			wantPreconditions: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policySched := *sched
+			policySched.Policy = tt.policy
+			config := &configloader.Config{
+				Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+				Params: []configloader.Parameter{
+					{Name: "clusterId", Source: "event.id", Required: true},
+				},
+				Preconditions: []configloader.Precondition{
+					{ActionBase: configloader.ActionBase{Name: "always"}, Expression: "true"},
+				},
+				Schedule: &policySched,
+			}
+			exec, err := NewBuilder().
+				WithConfig(config).
+				WithAPIClient(newMockAPIClient()).
+				WithTransportClient(k8sclient.NewMockK8sClient()).
+				WithLogger(logger.NewTestLogger()).
+				WithClock(clock.NewFake(tt.now)).
+				Build()
+			require.NoError(t, err)
+
+			result := exec.Execute(context.Background(), map[string]interface{}{"id": "cluster-1"})
+			require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+			assert.Equal(t, tt.wantResourcesSkip, result.ResourcesSkipped)
+			assert.Equal(t, tt.wantSkipReason, result.SkipReason)
+			assert.Equal(t, tt.wantRetryAfter, result.RetryAfter)
+			assert.Equal(t, tt.wantPreconditions, len(result.PreconditionResults) == 1)
+
+			// Exposed to CEL expressions and templates
+			celAdapter := result.ExecutionContext.GetCELVariables()["adapter"].(map[string]interface{})
+			assert.Equal(t, tt.wantDeferredUntil, celAdapter[AdapterKeyDeferredUntil])
+			paramAdapter := result.Params["adapter"].(map[string]interface{})
+			if tt.wantDeferredUntil == "" {
+				assert.NotContains(t, paramAdapter, AdapterKeyDeferredUntil)
+				assert.True(t, result.DeferredUntil.IsZero())
+				return
+			}
+			assert.Equal(t, tt.wantDeferredUntil, paramAdapter[AdapterKeyDeferredUntil])
+			assert.Equal(t, nextWindow, result.DeferredUntil.UTC())
+			assert.Contains(t, result.ExecutionContext.Adapter.SkipReason, "until 2026-01-02T21:00:00Z")
+		})
+	}
+}
+
+func TestNewExecutor_InvalidSchedule(t *testing.T) {
+	_, err := NewBuilder().
+		WithConfig(&configloader.Config{
+			Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+			Schedule: &configloader.Schedule{
+				Windows: []configloader.ScheduleWindow{{Start: "0 25 * * *", Duration: time.Hour}},
+			},
+		}).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid schedule: window 0")
+}
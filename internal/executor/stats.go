@@ -0,0 +1,191 @@
+package executor
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Built-in cache names of ExecutorStats.CacheSizes
+const (
+	CacheTemplates       = "templates"
+	CacheUnchangedBodies = "unchanged_bodies"
+	CacheExecutionFence  = "execution_fence"
+	CacheNotMet          = "not_met"
+)
+
+// ExecutorStats is a snapshot of the runtime counters of an Executor since it was built
+type ExecutorStats struct {
+	StartedAt time.Time `json:"started_at"`
+	// ConfigLoadedAt is when the executor's config was loaded. The config is
+	// loaded once at startup and changes require a restart, so this is also
+	// the last reload.
+	ConfigLoadedAt time.Time `json:"config_loaded_at"`
+	// LastErrorAt is when the last failed execution finished, zero if none failed
+	LastErrorAt time.Time `json:"last_error_at,omitempty"`
+	// CacheSizes are the entries held by the built-in caches and by the caches
+	// registered with RegisterCacheSize
+	CacheSizes map[string]int `json:"cache_sizes"`
+	// NotMet lists the not_met_backoff keys whose preconditions were not met in
+	// their last executions, the longest streaks first. All the keys are counted
+	// in CacheSizes.
+	NotMet []NotMetKeyStats `json:"not_met,omitempty"`
+	// LastError is the error of the last failed execution
+	LastError     string `json:"last_error,omitempty"`
+	LastErrorCode string `json:"last_error_code,omitempty"`
+	Succeeded     uint64 `json:"succeeded"`
+	Skipped       uint64 `json:"skipped"`
+	Failed        uint64 `json:"failed"`
+	// InFlight is the number of executions running now
+	InFlight int64 `json:"in_flight"`
+}
+
+// Total returns the number of finished executions
+func (s ExecutorStats) Total() uint64 {
+	return s.Succeeded + s.Skipped + s.Failed
+}
+
+// lastError is the error of a failed execution
+type lastError struct {
+	at      time.Time
+	message string
+	code    ErrorCode
+}
+
+// executorStats holds the counters behind Executor.Stats. Executions only
+// touch atomics; the cache size functions are locked on registration and read.
+type executorStats struct {
+	lastError  atomic.Pointer[lastError]
+	cacheSizes map[string]func() int
+	startedAt  time.Time
+	succeeded  atomic.Uint64
+	skipped    atomic.Uint64
+	failed     atomic.Uint64
+	mu         sync.RWMutex
+}
+
+// finishExecution counts a finished execution in the stats. The error of a
+// failed execution is redacted like in /statusz summaries.
+func (e *Executor) finishExecution(result *ExecutionResult) {
+	switch executionOutcome(result) {
+	case "failed":
+		e.stats.failed.Add(1)
+		last := &lastError{at: e.clock.Now()}
+		if err := primaryError(result); err != nil {
+			last.message = e.redactor(result)(err.Error())
+			last.code = ErrorCodeOf(err)
+		}
+		e.stats.lastError.Store(last)
+	case "skipped":
+		e.stats.skipped.Add(1)
+	default:
+		e.stats.succeeded.Add(1)
+	}
+}
+
+// RegisterCacheSize adds a cache whose size is reported by Stats under name,
+// such as the broker consumer's dedup store. size must be safe for concurrent use.
+func (e *Executor) RegisterCacheSize(name string, size func() int) {
+	e.stats.mu.Lock()
+	defer e.stats.mu.Unlock()
+	e.stats.cacheSizes[name] = size
+}
+
+// Stats returns a snapshot of the executor's runtime counters. It is safe to
+// call concurrently with executions. The counts of a snapshot taken while
+// executions finish may be from slightly different instants.
+func (e *Executor) Stats() ExecutorStats {
+	stats := ExecutorStats{
+		StartedAt:      e.stats.startedAt,
+		ConfigLoadedAt: e.stats.startedAt,
+		Succeeded:      e.stats.succeeded.Load(),
+		Skipped:        e.stats.skipped.Load(),
+		Failed:         e.stats.failed.Load(),
+		InFlight:       e.InFlight(),
+		CacheSizes: map[string]int{
+			CacheTemplates:       templateCache.Len(),
+			CacheUnchangedBodies: e.postActionExecutor.unchanged.len(),
+			CacheExecutionFence:  e.fence.len(),
+			CacheNotMet:          e.notMet.len(),
+		},
+		NotMet: e.notMet.top(notMetStatsLimit),
+	}
+	if last := e.stats.lastError.Load(); last != nil {
+		stats.LastErrorAt = last.at
+		stats.LastError = last.message
+		stats.LastErrorCode = string(last.code)
+	}
+
+	e.stats.mu.RLock()
+	defer e.stats.mu.RUnlock()
+	for name, size := range e.stats.cacheSizes {
+		stats.CacheSizes[name] = size()
+	}
+	return stats
+}
+
+// statsCollector exposes Executor.Stats as Prometheus metrics, read at scrape time
+type statsCollector struct {
+	executor       *Executor
+	executions     *prometheus.Desc
+	inFlight       *prometheus.Desc
+	cacheEntries   *prometheus.Desc
+	lastError      *prometheus.Desc
+	configLoadedAt *prometheus.Desc
+}
+
+// StatsCollector returns a Prometheus collector of the executor's Stats, so
+// /metrics reports the same numbers as /statusz. Register it once per executor.
+func (e *Executor) StatsCollector(component, version string) prometheus.Collector {
+	labels := prometheus.Labels{"component": component, "version": version}
+	return &statsCollector{
+		executor: e,
+		executions: prometheus.NewDesc("hyperfleet_adapter_executor_executions_total",
+			"Total number of executions finished by the executor by status", []string{"status"}, labels),
+		inFlight: prometheus.NewDesc("hyperfleet_adapter_executor_in_flight",
+			"Number of executions currently running in the executor", nil, labels),
+		cacheEntries: prometheus.NewDesc("hyperfleet_adapter_executor_cache_entries",
+			"Number of entries held by the executor caches", []string{"cache"}, labels),
+		lastError: prometheus.NewDesc("hyperfleet_adapter_executor_last_error_timestamp_seconds",
+			"Unix time of the last failed execution, 0 if none failed", nil, labels),
+		configLoadedAt: prometheus.NewDesc("hyperfleet_adapter_executor_config_loaded_timestamp_seconds",
+			"Unix time the executor config was loaded", nil, labels),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.executions
+	ch <- c.inFlight
+	ch <- c.cacheEntries
+	ch <- c.lastError
+	ch <- c.configLoadedAt
+}
+
+// Collect implements prometheus.Collector
+func (c *statsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.executor.Stats()
+	ch <- prometheus.MustNewConstMetric(c.executions, prometheus.CounterValue, float64(stats.Succeeded), "success")
+	ch <- prometheus.MustNewConstMetric(c.executions, prometheus.CounterValue, float64(stats.Skipped), "skipped")
+	ch <- prometheus.MustNewConstMetric(c.executions, prometheus.CounterValue, float64(stats.Failed), "failed")
+	ch <- prometheus.MustNewConstMetric(c.inFlight, prometheus.GaugeValue, float64(stats.InFlight))
+
+	names := make([]string, 0, len(stats.CacheSizes))
+	for name := range stats.CacheSizes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ch <- prometheus.MustNewConstMetric(c.cacheEntries, prometheus.GaugeValue, float64(stats.CacheSizes[name]), name)
+	}
+
+	var lastError float64
+	if !stats.LastErrorAt.IsZero() {
+		lastError = float64(stats.LastErrorAt.Unix())
+	}
+	ch <- prometheus.MustNewConstMetric(c.lastError, prometheus.GaugeValue, lastError)
+	ch <- prometheus.MustNewConstMetric(c.configLoadedAt, prometheus.GaugeValue, float64(stats.ConfigLoadedAt.Unix()))
+}
@@ -0,0 +1,89 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats_ConcurrentExecutions(t *testing.T) {
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		Params:  []configloader.Parameter{{Name: "clusterId", Source: "event.id", Required: true}},
+		Preconditions: []configloader.Precondition{{
+			ActionBase: configloader.ActionBase{Name: "notSkipped"},
+			Expression: `clusterId != "skip"`,
+		}},
+	}
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+	exec.RegisterCacheSize("dedup", func() int { return 7 })
+
+	// Every third execution succeeds, is skipped or fails on the missing required param
+	events := []map[string]interface{}{{"id": "cluster-1"}, {"id": "skip"}, {}}
+	const executions = 300
+
+	var wg sync.WaitGroup
+	for i := 0; i < executions; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			exec.Execute(context.Background(), events[i%len(events)])
+			// Snapshots are taken while executions run
+			_ = exec.Stats()
+		}(i)
+	}
+	wg.Wait()
+
+	stats := exec.Stats()
+	assert.Equal(t, uint64(executions/3), stats.Succeeded)
+	assert.Equal(t, uint64(executions/3), stats.Skipped)
+	assert.Equal(t, uint64(executions/3), stats.Failed)
+	assert.Equal(t, uint64(executions), stats.Total())
+	assert.Zero(t, stats.InFlight)
+	assert.Equal(t, string(ErrorCodeParamMissing), stats.LastErrorCode)
+	assert.Contains(t, stats.LastError, "clusterId")
+	assert.False(t, stats.LastErrorAt.IsZero())
+	assert.False(t, stats.StartedAt.IsZero())
+	assert.Equal(t, 7, stats.CacheSizes["dedup"])
+	assert.Contains(t, stats.CacheSizes, CacheTemplates)
+	assert.Contains(t, stats.CacheSizes, CacheUnchangedBodies)
+	assert.Contains(t, stats.CacheSizes, CacheExecutionFence)
+	assert.Contains(t, stats.CacheSizes, CacheNotMet)
+
+	registry := metrics.NewTestRegistry()
+	require.NoError(t, registry.Register(exec.StatsCollector("test-adapter", "v0.1.0")))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	byStatus := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "hyperfleet_adapter_executor_executions_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "status" {
+					byStatus[label.GetValue()] = m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{"success": 100, "skipped": 100, "failed": 100}, byStatus,
+		"/metrics reports the same numbers as Stats")
+	assert.Equal(t, 7, testutil.CollectAndCount(exec.StatsCollector("test-adapter", "v0.1.0"),
+		"hyperfleet_adapter_executor_cache_entries", "hyperfleet_adapter_executor_in_flight",
+		"hyperfleet_adapter_executor_last_error_timestamp_seconds"))
+}
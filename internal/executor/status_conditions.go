@@ -0,0 +1,144 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/criteria"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// Statuses of a HyperFleet status condition
+const (
+	ConditionStatusTrue    = "True"
+	ConditionStatusFalse   = "False"
+	ConditionStatusUnknown = "Unknown"
+)
+
+// Keys of a built HyperFleet status condition
+const (
+	conditionKeyType               = "type"
+	conditionKeyStatus             = "status"
+	conditionKeyReason             = "reason"
+	conditionKeyMessage            = "message"
+	conditionKeyLastTransitionTime = "last_transition_time"
+)
+
+// reportedCondition is the status and transition time of a condition previously reported
+type reportedCondition struct {
+	status         string
+	transitionTime string
+}
+
+// buildStatusConditions builds the conditions array of a status conditions block,
+// in the order of its items. A condition keeps the last_transition_time previously
+// reported for its type while its status is unchanged and transitions at now otherwise.
+func buildStatusConditions(
+	ctx context.Context,
+	log logger.Logger,
+	conditions *configloader.StatusConditions,
+	evaluator *criteria.Evaluator,
+	params map[string]any,
+	now time.Time,
+) ([]any, error) {
+	previous, err := previousConditions(conditions.Previous, evaluator)
+	if err != nil {
+		return nil, err
+	}
+
+	transitioned := now.UTC().Format(time.RFC3339)
+	built := make([]any, 0, len(conditions.Items))
+	for i, item := range conditions.Items {
+		status, err := conditionStatus(item.Status, evaluator)
+		if err != nil {
+			return nil, fmt.Errorf("condition %q: %w", item.Type, err)
+		}
+		reason, err := renderTemplateAt(fmt.Sprintf("conditions.items[%d].reason", i), item.Reason, params)
+		if err != nil {
+			return nil, fmt.Errorf("condition %q: failed to render reason: %w", item.Type, err)
+		}
+		message, err := renderTemplateAt(fmt.Sprintf("conditions.items[%d].message", i), item.Message, params)
+		if err != nil {
+			return nil, fmt.Errorf("condition %q: failed to render message: %w", item.Type, err)
+		}
+
+		transitionTime := transitioned
+		if reported, ok := previous[item.Type]; ok && reported.status == status && reported.transitionTime != "" {
+			transitionTime = reported.transitionTime
+		} else if ok {
+			log.Debugf(ctx, "Condition %s transitioned from %q to %q", item.Type, reported.status, status)
+		}
+
+		built = append(built, map[string]any{
+			conditionKeyType:               item.Type,
+			conditionKeyStatus:             status,
+			conditionKeyReason:             reason,
+			conditionKeyMessage:            message,
+			conditionKeyLastTransitionTime: transitionTime,
+		})
+	}
+	return built, nil
+}
+
+// previousConditions returns the conditions at the field path previous by type.
+// Conditions that are not found, as on the first report, are none.
+func previousConditions(previous string, evaluator *criteria.Evaluator) (map[string]reportedCondition, error) {
+	reported := map[string]reportedCondition{}
+	if previous == "" {
+		return reported, nil
+	}
+	result, err := evaluator.ExtractValue(previous, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract previous conditions: %w", err)
+	}
+	if result.Value == nil {
+		return reported, nil
+	}
+	// A CEL capture may hold a list of another element type
+	items := reflect.ValueOf(result.Value)
+	if items.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("previous conditions %q are a %T, not a list", previous, result.Value)
+	}
+	for i := range items.Len() {
+		condition, ok := items.Index(i).Interface().(map[string]any)
+		if !ok {
+			continue
+		}
+		conditionType, _ := condition[conditionKeyType].(string)
+		if conditionType == "" {
+			continue
+		}
+		status, _ := condition[conditionKeyStatus].(string)
+		transitionTime, _ := condition[conditionKeyLastTransitionTime].(string)
+		reported[conditionType] = reportedCondition{status: status, transitionTime: transitionTime}
+	}
+	return reported, nil
+}
+
+// conditionStatus evaluates a status expression. A bool is "True" or "False",
+// and an expression that fails at runtime, e.g. on a missing field, is "Unknown".
+func conditionStatus(expression string, evaluator *criteria.Evaluator) (string, error) {
+	result, err := evaluator.ExtractValue("", expression)
+	if err != nil {
+		return "", err
+	}
+	switch status := result.Value.(type) {
+	case nil:
+		return ConditionStatusUnknown, nil
+	case bool:
+		if status {
+			return ConditionStatusTrue, nil
+		}
+		return ConditionStatusFalse, nil
+	case string:
+		switch status {
+		case ConditionStatusTrue, ConditionStatusFalse, ConditionStatusUnknown:
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("status %v is not %q, %q, %q or a bool",
+		result.Value, ConditionStatusTrue, ConditionStatusFalse, ConditionStatusUnknown)
+}
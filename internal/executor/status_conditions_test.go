@@ -0,0 +1,175 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/criteria"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// loadStatusConditionsPayload loads the status_conditions payload of testdata/payload
+func loadStatusConditionsPayload(t *testing.T) configloader.Payload {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "payload", "status_conditions.yaml"))
+	require.NoError(t, err)
+	var payload configloader.Payload
+	require.NoError(t, yaml.Unmarshal(data, &payload))
+	payload.Name = "clusterStatusPayload"
+	return payload
+}
+
+// reported returns a previously reported condition
+func reported(conditionType, status, transitionTime string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":                 conditionType,
+		"status":               status,
+		"reason":               "Previous",
+		"message":              "previously reported",
+		"last_transition_time": transitionTime,
+	}
+}
+
+// TestBuildPostPayloads_StatusConditionsGolden pins the conditions built on the
+// first report, on a report without status change and on a status flip, with
+// the execution clock fixed at 2026-01-02T03:04:05Z
+func TestBuildPostPayloads_StatusConditionsGolden(t *testing.T) {
+	const earlier = "2025-12-31T23:00:00Z"
+	tests := []struct {
+		previous       interface{}
+		name           string
+		namespacePhase string
+	}{
+		{name: "first_report", namespacePhase: "Active"},
+		{
+			name:           "no_change",
+			namespacePhase: "Active",
+			previous: []interface{}{
+				reported("Available", "True", earlier),
+				reported("Applied", "True", earlier),
+				reported("Health", "Unknown", earlier),
+			},
+		},
+		{
+			name:           "status_flip",
+			namespacePhase: "Terminating",
+			previous: []interface{}{
+				reported("Applied", "True", earlier),
+				reported("Available", "True", earlier),
+				reported("Health", "True", earlier),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pae := testPAE()
+			execCtx := clusterStatusExecCtx()
+			execCtx.clock = clock.NewFake(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+			execCtx.Resources["clusterNamespace"] = &unstructured.Unstructured{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "cluster-123"},
+				"status":   map[string]interface{}{"phase": tt.namespacePhase},
+			}}
+			if tt.previous != nil {
+				execCtx.SetParam("currentStatus", map[string]interface{}{"conditions": tt.previous})
+			}
+			payload := loadStatusConditionsPayload(t)
+
+			require.NoError(t, pae.buildPostPayloads(context.Background(), pae.log, []configloader.Payload{payload}, execCtx))
+			got, ok := execCtx.ParamsSnapshot()[payload.Name].(string)
+			require.True(t, ok, "payload should be stored as json string in params")
+
+			golden := filepath.Join("testdata", "payload", "status_conditions_"+tt.name+".golden")
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(golden, []byte(got+"\n"), 0o600))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, strings.TrimSuffix(string(want), "\n"), got)
+		})
+	}
+}
+
+func TestBuildPostPayloads_StatusConditionsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		build    interface{}
+		previous interface{}
+		status   string
+		wantErr  string
+	}{
+		{
+			name:    "payload is not a map",
+			build:   "text",
+			status:  "true",
+			wantErr: "does not build a map",
+		},
+		{
+			name:     "previous conditions are not a list",
+			build:    map[string]interface{}{},
+			previous: "Ready",
+			status:   "true",
+			wantErr:  "not a list",
+		},
+		{
+			name:    "status is not a condition status",
+			build:   map[string]interface{}{},
+			status:  `"Ready"`,
+			wantErr: `condition "Ready": status Ready is not "True", "False", "Unknown" or a bool`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pae := testPAE()
+			execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+			if tt.previous != nil {
+				execCtx.SetParam("currentStatus", map[string]interface{}{"conditions": tt.previous})
+			}
+			payload := configloader.Payload{
+				Name:  "statusPayload",
+				Build: tt.build,
+				Conditions: &configloader.StatusConditions{
+					Previous: "currentStatus.conditions",
+					Items:    []configloader.StatusCondition{{Type: "Ready", Status: tt.status}},
+				},
+			}
+
+			err := pae.buildPostPayloads(context.Background(), pae.log, []configloader.Payload{payload}, execCtx)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestConditionStatus(t *testing.T) {
+	evalCtx := criteria.NewEvaluationContext()
+	evalCtx.Set("phase", "Active")
+	evaluator, err := criteria.NewEvaluator(context.Background(), evalCtx, logger.NewTestLogger())
+	require.NoError(t, err)
+
+	tests := []struct {
+		expression string
+		want       string
+	}{
+		{expression: `phase == "Active"`, want: ConditionStatusTrue},
+		{expression: `phase != "Active"`, want: ConditionStatusFalse},
+		{expression: `phase == "Active" ? "Unknown" : "False"`, want: ConditionStatusUnknown},
+		{expression: `missing.field == "x"`, want: ConditionStatusUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			got, err := conditionStatus(tt.expression, evaluator)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
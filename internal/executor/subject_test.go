@@ -0,0 +1,118 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/eventtest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubjectParams(t *testing.T) {
+	patterns := []string{"tenants/{tenant}/clusters/{clusterId}", "clusters/{clusterId}", "paths/{path...}"}
+	tests := []struct {
+		name      string
+		patterns  []string
+		optional  bool
+		subject   string
+		want      map[string]string
+		wantError string
+	}{
+		{
+			name:     "first matching pattern",
+			patterns: patterns,
+			subject:  "tenants/acme/clusters/c1",
+			want:     map[string]string{"tenant": "acme", "clusterId": "c1"},
+		},
+		{
+			name:     "later pattern",
+			patterns: patterns,
+			subject:  "clusters/c2",
+			want:     map[string]string{"clusterId": "c2"},
+		},
+		{
+			name:     "url-encoded segment",
+			patterns: patterns,
+			subject:  "tenants/acme%20corp/clusters/c%2F42",
+			want:     map[string]string{"tenant": "acme corp", "clusterId": "c/42"},
+		},
+		{
+			name:     "wildcard",
+			patterns: patterns,
+			subject:  "paths/a/b%2Fc/d",
+			want:     map[string]string{"path": "a/b/c/d"},
+		},
+		{
+			name:    "no patterns",
+			subject: "",
+			want:    map[string]string{},
+		},
+		{
+			name:     "no match",
+			patterns: patterns,
+			subject:  "tenants/acme/nodepools/np1",
+			wantError: `subject "tenants/acme/nodepools/np1" matches none of the subject patterns ` +
+				`"tenants/{tenant}/clusters/{clusterId}", "clusters/{clusterId}", "paths/{path...}"`,
+		},
+		{
+			name:      "empty subject",
+			patterns:  []string{"clusters/{clusterId}"},
+			subject:   "",
+			wantError: `subject "" matches none of the subject patterns "clusters/{clusterId}"`,
+		},
+		{
+			name:     "empty subject with optional subject",
+			patterns: []string{"clusters/{clusterId}"},
+			optional: true,
+			subject:  "",
+			want:     map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &configloader.Config{SubjectPatterns: tt.patterns, SubjectOptional: tt.optional}
+			exec, err := NewBuilder().
+				WithConfig(config).
+				WithAPIClient(newMockAPIClient()).
+				WithTransportClient(k8sclient.NewMockK8sClient()).
+				WithLogger(logger.NewTestLogger()).
+				Build()
+			require.NoError(t, err)
+
+			evt := eventtest.NewEvent().WithSubject(tt.subject).WithDataJSON(`{"id":"cluster-1"}`).Build()
+			result := exec.ExecuteEvent(context.Background(), evt)
+			if tt.wantError != "" {
+				require.Equal(t, StatusFailed, result.Status)
+				err := result.Errors[PhaseParamExtraction]
+				assert.Equal(t, ErrorCodeSubjectMismatch, ErrorCodeOf(err))
+				assert.ErrorContains(t, err, tt.wantError)
+				return
+			}
+			require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+			for name, want := range tt.want {
+				got, _ := result.ExecutionContext.GetParam(name)
+				assert.Equal(t, want, got, name)
+			}
+			for _, name := range []string{"tenant", "clusterId", "path"} {
+				if _, ok := tt.want[name]; !ok {
+					_, set := result.ExecutionContext.GetParam(name)
+					assert.False(t, set, "%s is not set", name)
+				}
+			}
+		})
+	}
+}
+
+func TestNewExecutor_InvalidSubjectPattern(t *testing.T) {
+	_, err := NewBuilder().
+		WithConfig(&configloader.Config{SubjectPatterns: []string{"clusters/{cluster id}"}}).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	assert.ErrorContains(t, err, "invalid subject patterns")
+}
@@ -0,0 +1,135 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxTemplateErrorLength is the longest template text quoted by a TemplateError
+const maxTemplateErrorLength = 120
+
+// missingKeyPattern matches the error of a template referencing a missing map key
+var missingKeyPattern = regexp.MustCompile(`map has no entry for key "([^"]*)"`)
+
+// TemplateError is a Go template of the config that failed to render. It is
+// classified as ErrorCodeTemplateError wherever it surfaces.
+type TemplateError struct {
+	// Path is the config field of the template, e.g. resources[2].manifest.metadata.name,
+	// as much of it as the code that rendered it knows
+	Path string
+	// Template is the template text, truncated to 120 characters
+	Template string
+	// MissingKey is the key the template referenced that was missing from the
+	// params, empty when the template failed otherwise
+	MissingKey string
+	// Err is the error of the template package
+	Err error
+}
+
+// Error implements error
+func (e *TemplateError) Error() string {
+	var msg string
+	if e.MissingKey != "" {
+		msg = fmt.Sprintf("template %q references missing key %q", e.Template, e.MissingKey)
+	} else {
+		msg = fmt.Sprintf("template %q failed: %v", e.Template, e.Err)
+	}
+	if e.Path == "" {
+		return msg
+	}
+	return e.Path + ": " + msg
+}
+
+// Unwrap returns the error of the template package
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// IsTemplateError returns the *TemplateError in err's chain
+func IsTemplateError(err error) (*TemplateError, bool) {
+	var tmplErr *TemplateError
+	if errors.As(err, &tmplErr) {
+		return tmplErr, true
+	}
+	return nil, false
+}
+
+// newTemplateError returns the TemplateError of templateStr failing with err
+func newTemplateError(templateStr string, err error) *TemplateError {
+	tmplErr := &TemplateError{Template: truncateTemplate(templateStr), Err: err}
+	if match := missingKeyPattern.FindStringSubmatch(err.Error()); match != nil {
+		tmplErr.MissingKey = match[1]
+	}
+	return tmplErr
+}
+
+// atPath prefixes the path of the TemplateError in err's chain with prefix, the
+// config field the failed template is under, and returns err. Each caller
+// rendering a part of the config adds the part it knows, so the path is
+// complete once the error reaches the step.
+func atPath(prefix string, err error) error {
+	if tmplErr, ok := IsTemplateError(err); ok {
+		tmplErr.Path = joinTemplatePath(prefix, tmplErr.Path)
+	}
+	return err
+}
+
+// atPathf is atPath with a formatted prefix
+func atPathf(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return atPath(fmt.Sprintf(format, args...), err)
+}
+
+// atStepPath prefixes the path of the TemplateError in err's chain with the
+// config path of a step of the workflow of the execution, formatted from
+// format: the step itself for the default workflow, under workflows[i] for the
+// others. It returns err.
+func (ec *ExecutionContext) atStepPath(err error, format string, args ...interface{}) error {
+	if _, ok := IsTemplateError(err); !ok {
+		return err
+	}
+	path := fmt.Sprintf(format, args...)
+	if ec.Config != nil {
+		for i := range ec.Config.Workflows {
+			if ec.Config.Workflows[i].Name == ec.Adapter.Workflow {
+				path = fmt.Sprintf("workflows[%d].%s", i, path)
+				break
+			}
+		}
+	}
+	return atPath(path, err)
+}
+
+// renderTemplateAt renders templateStr like renderTemplate, its error naming
+// path as the config field of the template
+func renderTemplateAt(path, templateStr string, data map[string]interface{}) (string, error) {
+	rendered, err := renderTemplate(templateStr, data)
+	return rendered, atPath(path, err)
+}
+
+// joinTemplatePath joins the path of a field under prefix
+func joinTemplatePath(prefix, path string) string {
+	switch {
+	case prefix == "":
+		return path
+	case path == "":
+		return prefix
+	case strings.HasPrefix(path, "["):
+		return prefix + path
+	default:
+		return prefix + "." + path
+	}
+}
+
+// truncateTemplate returns templateStr cut to maxTemplateErrorLength characters
+func truncateTemplate(templateStr string) string {
+	runes := []rune(templateStr)
+	if len(runes) <= maxTemplateErrorLength {
+		return templateStr
+	}
+	return string(runes[:maxTemplateErrorLength-3]) + "..."
+}
@@ -0,0 +1,194 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/eventtest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTemplate_TemplateError(t *testing.T) {
+	_, err := renderTemplateAt("resources[0].manifest.metadata.name", "cm-{{ .missing }}",
+		map[string]interface{}{"clusterId": "c1"})
+	tmplErr, ok := IsTemplateError(err)
+	require.True(t, ok, "expected a *TemplateError, got %v", err)
+	assert.Equal(t, "resources[0].manifest.metadata.name", tmplErr.Path)
+	assert.Equal(t, "cm-{{ .missing }}", tmplErr.Template)
+	assert.Equal(t, "missing", tmplErr.MissingKey)
+	assert.EqualError(t, err,
+		`resources[0].manifest.metadata.name: template "cm-{{ .missing }}" references missing key "missing"`)
+	assert.Equal(t, ErrorCodeTemplateError, ErrorCodeOf(err))
+
+	_, err = renderTemplate("{{ .clusterId | nope }}", nil)
+	tmplErr, ok = IsTemplateError(err)
+	require.True(t, ok, "expected a *TemplateError, got %v", err)
+	assert.Empty(t, tmplErr.MissingKey)
+	assert.Contains(t, err.Error(), `template "{{ .clusterId | nope }}" failed: `)
+	assert.Contains(t, err.Error(), `function "nope" not defined`)
+
+	long := "{{ .missing }}" + strings.Repeat("x", 200)
+	_, err = renderTemplate(long, nil)
+	tmplErr, ok = IsTemplateError(err)
+	require.True(t, ok)
+	assert.Len(t, tmplErr.Template, maxTemplateErrorLength)
+	assert.True(t, strings.HasSuffix(tmplErr.Template, "..."))
+}
+
+func TestAtPath(t *testing.T) {
+	err := atPath("metadata.labels", newTemplateError("{{ .a }}", errors.New("boom")))
+	err = atPath("[1]", err)
+	err = atPath("items", err)
+	err = atPath("resources[0].manifest", fmtWrap(err))
+	tmplErr, ok := IsTemplateError(err)
+	require.True(t, ok)
+	assert.Equal(t, "resources[0].manifest.items[1].metadata.labels", tmplErr.Path)
+
+	plain := errors.New("not a template")
+	assert.Same(t, plain, atPath("resources[0]", plain))
+	assert.NoError(t, atPathf(nil, "resources[%d]", 0))
+}
+
+func fmtWrap(err error) error {
+	return errors.Join(errors.New("context"), err)
+}
+
+func TestTemplateErrors_ConfigPathInExecutorError(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(config *configloader.Config)
+		phase  ExecutionPhase
+		path   string
+	}{
+		{
+			name: "precondition api_call url",
+			modify: func(config *configloader.Config) {
+				config.Preconditions[0].APICall.URL = "/clusters/{{ .missingId }}"
+			},
+			phase: PhasePreconditions,
+			path:  "preconditions[0].api_call.url",
+		},
+		{
+			name: "manifest field",
+			modify: func(config *configloader.Config) {
+				config.Resources[0].Manifest.(map[string]interface{})["metadata"] = map[string]interface{}{
+					"name": "cm-{{ .clusterId }}", "namespace": "default",
+					"labels": map[string]interface{}{"owner": "{{ .missingOwner }}"},
+				}
+			},
+			phase: PhaseResources,
+			path:  "resources[0].manifest.metadata.labels.owner",
+		},
+		{
+			name: "manifest list item",
+			modify: func(config *configloader.Config) {
+				config.Resources[0].Manifest.(map[string]interface{})["data"] = map[string]interface{}{
+					"hosts": []interface{}{"a", "{{ .missingHost }}"},
+				}
+			},
+			phase: PhaseResources,
+			path:  "resources[0].manifest.data.hosts[1]",
+		},
+		{
+			name: "discovery by_name",
+			modify: func(config *configloader.Config) {
+				config.Resources[0].Discovery.ByName = "cm-{{ .missingName }}"
+			},
+			phase: PhaseResources,
+			path:  "resources[0].discovery.by_name",
+		},
+		{
+			name: "payload build value",
+			modify: func(config *configloader.Config) {
+				config.Post.Payloads[0].Build.(map[string]interface{})["spec"] = map[string]interface{}{"owner": "{{ .missingOwner }}"}
+			},
+			phase: PhasePostActions,
+			path:  "post.payloads[0].build.spec.owner",
+		},
+		{
+			name: "payload build key",
+			modify: func(config *configloader.Config) {
+				config.Post.Payloads[0].Build.(map[string]interface{})["{{ .missingKey }}"] = "value"
+			},
+			phase: PhasePostActions,
+			path:  "post.payloads[0].build.{{ .missingKey }}",
+		},
+		{
+			name: "post action api_call url",
+			modify: func(config *configloader.Config) {
+				config.Post.PostActions[0].APICall.URL = "/clusters/{{ .missingId }}/statuses"
+			},
+			phase: PhasePostActions,
+			path:  "post.post_actions[0].api_call.url",
+		},
+		{
+			name: "post action api_call body",
+			modify: func(config *configloader.Config) {
+				config.Post.PostActions[0].APICall.Body = `{"id": "{{ .missingId }}"}`
+			},
+			phase: PhasePostActions,
+			path:  "post.post_actions[0].api_call.body",
+		},
+		{
+			name: "post action api_call header",
+			modify: func(config *configloader.Config) {
+				config.Post.PostActions[0].APICall.Headers = []configloader.Header{
+					{Name: "X-Owner", Value: "{{ .missingOwner }}"},
+				}
+			},
+			phase: PhasePostActions,
+			path:  "post.post_actions[0].api_call.headers[0].value",
+		},
+		{
+			name: "workflow resource",
+			modify: func(config *configloader.Config) {
+				config.Resources[0].Discovery.ByName = "cm-{{ .missingName }}"
+				config.Workflows = []configloader.Workflow{{
+					Name:          "main",
+					Preconditions: config.Preconditions,
+					Resources:     config.Resources,
+					Post:          config.Post,
+				}}
+			},
+			phase: PhaseResources,
+			path:  "workflows[0].resources[0].discovery.by_name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JOURNAL_TEST_TOKEN", "s3cret")
+			config := journalTestConfig()
+			tt.modify(config)
+			apiClient := newMockAPIClient()
+			apiClient.GetResponse = &hyperfleetapi.Response{
+				StatusCode: 200, Status: "200 OK", Body: []byte(`{"generation":3}`),
+			}
+			exec, err := NewBuilder().
+				WithConfig(config).
+				WithAPIClient(apiClient).
+				WithTransportClient(k8sclient.NewMockK8sClient()).
+				WithLogger(logger.NewTestLogger()).
+				Build()
+			require.NoError(t, err)
+
+			evt := eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build()
+			result := exec.ExecuteEvent(context.Background(), evt)
+
+			require.Equal(t, StatusFailed, result.Status)
+			phaseErr := result.Errors[tt.phase]
+			require.Error(t, phaseErr, "errors: %v", result.Errors)
+			var execErr *ExecutorError
+			require.ErrorAs(t, phaseErr, &execErr)
+			assert.Contains(t, execErr.Error(), tt.path+": template ")
+			assert.Equal(t, ErrorCodeTemplateError, ErrorCodeOf(phaseErr))
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// advancingAPIClient advances the fake clock by each call's latency
+type advancingAPIClient struct {
+	*hyperfleetapi.MockClient
+	clock   *clock.Fake
+	latency time.Duration
+}
+
+func (c *advancingAPIClient) Get(
+	ctx context.Context, url string, opts ...hyperfleetapi.RequestOption,
+) (*hyperfleetapi.Response, error) {
+	c.clock.Advance(c.latency)
+	return c.MockClient.Get(ctx, url, opts...)
+}
+
+func (c *advancingAPIClient) Post(
+	ctx context.Context, url string, body []byte, opts ...hyperfleetapi.RequestOption,
+) (*hyperfleetapi.Response, error) {
+	c.clock.Advance(c.latency)
+	return c.MockClient.Post(ctx, url, body, opts...)
+}
+
+// advancingTransport advances the fake clock by each apply's latency
+type advancingTransport struct {
+	*k8sclient.MockK8sClient
+	clock   *clock.Fake
+	latency time.Duration
+}
+
+func (a *advancingTransport) ApplyResource(
+	ctx context.Context,
+	manifestBytes []byte,
+	opts *transportclient.ApplyOptions,
+	target transportclient.TransportContext,
+) (*transportclient.ApplyResult, error) {
+	a.clock.Advance(a.latency)
+	return a.MockK8sClient.ApplyResource(ctx, manifestBytes, opts, target)
+}
+
+func timingTestExecutor(t *testing.T, perStep bool, registry *prometheus.Registry) (*Executor, time.Time) {
+	t.Helper()
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(t0)
+	apiClient := &advancingAPIClient{MockClient: newMockAPIClient(), clock: fake, latency: 100 * time.Millisecond}
+	apiClient.GetResponse = &hyperfleetapi.Response{StatusCode: 200, Status: "200 OK", Body: []byte(`{}`)}
+
+	config := cancelTestConfig()
+	config.Preconditions = []configloader.Precondition{{ActionBase: configloader.ActionBase{
+		Name: "getCluster", APICall: &configloader.APICall{Method: "GET", URL: "/clusters/{{ .clusterId }}"},
+	}}}
+	config.Metrics.PerStep = perStep
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(apiClient).
+		WithTransportClient(&advancingTransport{
+			MockK8sClient: k8sclient.NewMockK8sClient(), clock: fake, latency: 2 * time.Second,
+		}).
+		WithLogger(logger.NewTestLogger()).
+		WithClock(fake).
+		WithMetricsRecorder(metrics.NewRecorder("test-adapter", "v0.1.0", registry)).
+		Build()
+	require.NoError(t, err)
+	return exec, t0
+}
+
+func TestExecutionResult_Timing(t *testing.T) {
+	exec, t0 := timingTestExecutor(t, false, prometheus.NewRegistry())
+
+	result := exec.Execute(context.Background(), map[string]interface{}{"id": "cluster-1"})
+	require.Equal(t, StatusSuccess, result.Status, "%v", result.Errors)
+
+	require.Len(t, result.PreconditionResults, 1)
+	assert.Equal(t, t0, result.PreconditionResults[0].StartedAt)
+	assert.Equal(t, 100*time.Millisecond, result.PreconditionResults[0].Duration)
+
+	require.Len(t, result.ResourceResults, 2)
+	assert.Equal(t, t0.Add(100*time.Millisecond), result.ResourceResults[0].StartedAt)
+	assert.Equal(t, 2*time.Second, result.ResourceResults[0].Duration)
+	assert.Equal(t, t0.Add(2100*time.Millisecond), result.ResourceResults[1].StartedAt)
+	assert.Equal(t, 2*time.Second, result.ResourceResults[1].Duration)
+
+	require.Len(t, result.PostActionResults, 2)
+	assert.Equal(t, t0.Add(4100*time.Millisecond), result.PostActionResults[0].StartedAt)
+	assert.Equal(t, 100*time.Millisecond, result.PostActionResults[0].Duration)
+	assert.Equal(t, t0.Add(4200*time.Millisecond), result.PostActionResults[1].StartedAt)
+
+	assert.Equal(t, map[ExecutionPhase]time.Duration{
+		PhaseParamExtraction: 0,
+		PhasePreconditions:   100 * time.Millisecond,
+		PhaseResources:       4 * time.Second,
+		PhasePostActions:     200 * time.Millisecond,
+	}, result.PhaseDurations)
+	assert.Equal(t, 4300*time.Millisecond, result.Duration)
+}
+
+func TestExecutionResult_PerStepMetrics(t *testing.T) {
+	stepDurations := func(perStep bool) map[string]uint64 {
+		registry := prometheus.NewRegistry()
+		exec, _ := timingTestExecutor(t, perStep, registry)
+		result := exec.Execute(context.Background(), map[string]interface{}{"id": "cluster-1"})
+		require.Equal(t, StatusSuccess, result.Status, "%v", result.Errors)
+
+		families, err := registry.Gather()
+		require.NoError(t, err)
+		counts := map[string]uint64{}
+		for _, family := range families {
+			if family.GetName() != "hyperfleet_adapter_step_duration_seconds" {
+				continue
+			}
+			for _, m := range family.GetMetric() {
+				counts[labelValue(m, "phase")+"/"+labelValue(m, "step")] = m.GetHistogram().GetSampleCount()
+			}
+		}
+		return counts
+	}
+
+	assert.Empty(t, stepDurations(false), "per-step durations are opt-in")
+	assert.Equal(t, map[string]uint64{
+		"preconditions/getCluster": 1,
+		"resources/first":          1,
+		"resources/second":         1,
+		"post_actions/report":      1,
+		"post_actions/reportAgain": 1,
+	}, stepDurations(true))
+}
+
+func labelValue(m *dto.Metric, name string) string {
+	for _, label := range m.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span attribute keys of executor spans. The event attributes match the ones
+// set by the broker consumer's Receive span.
+const (
+	AttrEventID   = "cloudevents.event_id"
+	AttrEventType = "cloudevents.event_type"
+	AttrPhase     = "hyperfleet.phase"
+	// AttrStatus is the outcome of the span: success, skipped or failed for
+	// the execution, success, failed, not_met or skipped for phases, and the
+	// apply operation for resources
+	AttrStatus   = "hyperfleet.status"
+	AttrResource = "hyperfleet.resource"
+	AttrK8sKind  = "k8s.kind"
+	AttrK8sName  = "k8s.name"
+)
+
+// Span statuses of phases that neither succeeded nor failed
+const (
+	// SpanStatusNotMet is the preconditions phase status when a precondition is not met
+	SpanStatusNotMet = "not_met"
+	// SpanStatusSkipped is the resources phase status when resources were skipped
+	SpanStatusSkipped = "skipped"
+)
+
+// defaultTracerName names the tracer when the executor has no adapter config
+const defaultTracerName = "hyperfleet-adapter"
+
+// startSpan starts a span named name with the tracer of the adapter and adds
+// the new span ID to the logger context, so logs inside the span correlate with it.
+func startSpan(
+	ctx context.Context, config *ExecutorConfig, name string, attrs ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	tracerName := defaultTracerName
+	if config != nil && config.Config != nil {
+		tracerName = config.Config.Adapter.Name
+	}
+	ctx, span := otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+	return logger.WithOTelTraceContext(ctx), span
+}
+
+// startPhaseSpan starts the child span of an execution phase
+func (e *Executor) startPhaseSpan(ctx context.Context, phase ExecutionPhase) (context.Context, trace.Span) {
+	return startSpan(ctx, e.config, "Phase "+string(phase), attribute.String(AttrPhase, string(phase)))
+}
+
+// endSpan records the status of a span and its error, if any, and ends it
+func endSpan(span trace.Span, status string, err error) {
+	span.SetAttributes(attribute.String(AttrStatus, status))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceIDOf returns the trace ID of the span in ctx, or "" if there is none,
+// e.g. when tracing is disabled and the event carried no traceparent
+func traceIDOf(ctx context.Context) string {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.HasTraceID() {
+		return ""
+	}
+	return spanCtx.TraceID().String()
+}
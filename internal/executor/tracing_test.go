@@ -0,0 +1,202 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/eventtest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/health"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newSpanRecorder installs a TracerProvider exporting to memory for the
+// duration of the test
+func newSpanRecorder(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+		_ = tp.Shutdown(context.Background()) //nolint:errcheck // test cleanup
+	})
+	return exporter
+}
+
+// spansByName indexes the recorded spans; span names are unique per execution
+// except ApplyResource, so the last one wins
+func spansByName(exporter *tracetest.InMemoryExporter) map[string]tracetest.SpanStub {
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	return spans
+}
+
+func spanAttr(span tracetest.SpanStub, key string) string {
+	for _, attr := range span.Attributes {
+		if attr.Key == attribute.Key(key) {
+			return attr.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func newTracingEvent(id string) *event.Event {
+	return eventtest.NewEvent().WithID(id).WithDataJSON(map[string]interface{}{"id": "cluster-1"}).Build()
+}
+
+func TestExecuteEvent_Spans(t *testing.T) {
+	tests := []struct {
+		name           string
+		preconditions  []configloader.Precondition
+		wantExecStatus string
+		wantPhases     map[string]string
+	}{
+		{
+			name:           "resources applied",
+			wantExecStatus: "success",
+			wantPhases: map[string]string{
+				string(PhaseSchemaValidation): string(StatusSuccess),
+				string(PhaseParamExtraction):  string(StatusSuccess),
+				string(PhasePreconditions):    string(StatusSuccess),
+				string(PhaseResources):        string(StatusSuccess),
+				string(PhasePostActions):      string(StatusSuccess),
+			},
+		},
+		{
+			name: "precondition not met",
+			preconditions: []configloader.Precondition{
+				{ActionBase: configloader.ActionBase{Name: "check"}, Expression: "false"},
+			},
+			wantExecStatus: "skipped",
+			wantPhases: map[string]string{
+				string(PhasePreconditions): SpanStatusNotMet,
+				string(PhaseResources):     SpanStatusSkipped,
+				string(PhasePostActions):   string(StatusSuccess),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := newSpanRecorder(t)
+			config := &configloader.Config{
+				Adapter:       configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+				Preconditions: tt.preconditions,
+				Resources: []configloader.Resource{{
+					Name: "configmap",
+					Manifest: map[string]interface{}{
+						"apiVersion": "v1",
+						"kind":       "ConfigMap",
+						"metadata":   map[string]interface{}{"name": "test-cm", "namespace": "default"},
+					},
+				}},
+			}
+			exec, err := NewBuilder().
+				WithConfig(config).
+				WithAPIClient(newMockAPIClient()).
+				WithTransportClient(k8sclient.NewMockK8sClient()).
+				WithLogger(logger.NewTestLogger()).
+				Build()
+			require.NoError(t, err)
+
+			result := exec.ExecuteEvent(context.Background(), newTracingEvent("evt-trace"))
+
+			spans := spansByName(exporter)
+			root, ok := spans["Execute"]
+			require.True(t, ok, "Execute span recorded")
+			assert.False(t, root.Parent.IsValid(), "Execute is the root span")
+			assert.Equal(t, "evt-trace", spanAttr(root, AttrEventID))
+			assert.Equal(t, "com.redhat.hyperfleet.cluster.reconcile", spanAttr(root, AttrEventType))
+			assert.Equal(t, tt.wantExecStatus, spanAttr(root, AttrStatus))
+			assert.Equal(t, root.SpanContext.TraceID().String(), result.TraceID)
+
+			for phase, status := range tt.wantPhases {
+				span, ok := spans["Phase "+phase]
+				require.True(t, ok, "span of phase %s recorded", phase)
+				assert.Equal(t, root.SpanContext.SpanID(), span.Parent.SpanID(), "phase %s is a child of Execute", phase)
+				assert.Equal(t, phase, spanAttr(span, AttrPhase))
+				assert.Equal(t, status, spanAttr(span, AttrStatus), "status of phase %s", phase)
+			}
+
+			apply, applied := spans["ApplyResource"]
+			assert.Equal(t, !result.ResourcesSkipped, applied)
+			if applied {
+				assert.Equal(t, spans["Phase "+string(PhaseResources)].SpanContext.SpanID(), apply.Parent.SpanID())
+				assert.Equal(t, "configmap", spanAttr(apply, AttrResource))
+				assert.Equal(t, "ConfigMap", spanAttr(apply, AttrK8sKind))
+				assert.Equal(t, "test-cm", spanAttr(apply, AttrK8sName))
+			}
+		})
+	}
+}
+
+func TestExecuteEvent_FailedPhaseSpan(t *testing.T) {
+	exporter := newSpanRecorder(t)
+	config := &configloader.Config{
+		Adapter:   configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+		Resources: []configloader.Resource{{Name: "no-manifest"}},
+	}
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+
+	result := exec.ExecuteEvent(context.Background(), newTracingEvent("evt-failed"))
+	require.Equal(t, StatusFailed, result.Status)
+
+	spans := spansByName(exporter)
+	assert.Equal(t, "failed", spanAttr(spans["Execute"], AttrStatus))
+	assert.Equal(t, codes.Error, spans["Execute"].Status.Code)
+	resources := spans["Phase "+string(PhaseResources)]
+	assert.Equal(t, string(StatusFailed), spanAttr(resources, AttrStatus))
+	assert.Equal(t, codes.Error, resources.Status.Code)
+	assert.NotEmpty(t, resources.Events, "the error is recorded as a span event")
+}
+
+func TestCreateHandler_ContinuesUpstreamTrace(t *testing.T) {
+	exporter := newSpanRecorder(t)
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+	}
+	history := health.NewExecutionHistory(1)
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		WithExecutionHistory(history).
+		Build()
+	require.NoError(t, err)
+
+	const upstreamTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	evt := newTracingEvent("evt-upstream")
+	evt.SetExtension("traceparent", "00-"+upstreamTraceID+"-00f067aa0ba902b7-01")
+
+	require.NoError(t, exec.CreateHandler()(context.Background(), evt))
+
+	root, ok := spansByName(exporter)["Execute"]
+	require.True(t, ok)
+	assert.Equal(t, upstreamTraceID, root.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", root.Parent.SpanID().String())
+	require.Len(t, history.Recent(""), 1)
+	assert.Equal(t, upstreamTraceID, history.Recent("")[0].TraceID)
+}
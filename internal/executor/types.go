@@ -2,14 +2,32 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
 	"time"
 
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/audit"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/criteria"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/featureflags"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/idempotency"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/journal"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/lookup"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/manifest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/postbuffer"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/retrybudget"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/schedule"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/webhook"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/health"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -19,8 +37,14 @@ import (
 type ExecutionPhase string
 
 const (
+	// PhaseSchemaValidation is the event data schema validation phase
+	PhaseSchemaValidation ExecutionPhase = "schema_validation"
 	// PhaseParamExtraction is the parameter extraction phase
 	PhaseParamExtraction ExecutionPhase = "param_extraction"
+	// PhaseWorkflowSelection is the selection of the workflow of the event
+	PhaseWorkflowSelection ExecutionPhase = "workflow_selection"
+	// PhaseExecutionFence is the wait for other executions with the same execution_fence key
+	PhaseExecutionFence ExecutionPhase = "execution_fence"
 	// PhasePreconditions is the precondition evaluation phase
 	PhasePreconditions ExecutionPhase = "preconditions"
 	// PhaseResources is the resource creation/update phase
@@ -46,6 +70,29 @@ type ResourceRef struct {
 	Href string `json:"href,omitempty"`
 }
 
+// Event data content types accepted by ExecuteEvent. Events without a data content type are JSON.
+const (
+	ContentTypeJSON = "application/json"
+	ContentTypeYAML = "application/yaml"
+	// ContentTypePlainText data is accepted when the task config sets plain_text_data_key
+	ContentTypePlainText = "text/plain"
+)
+
+// DataDecision is what the executor did with the event data based on its content type
+type DataDecision string
+
+const (
+	// DataAccepted data was decoded as JSON or YAML
+	DataAccepted DataDecision = "accepted"
+	// DataWrapped text/plain data was wrapped under the plain_text_data_key
+	DataWrapped DataDecision = "wrapped"
+	// DataFiltered data has a content type not allowed by event_filter.data_content_types;
+	// the event is acknowledged without executing
+	DataFiltered DataDecision = "filtered"
+	// DataInvalid data has an allowed content type but could not be decoded
+	DataInvalid DataDecision = "invalid"
+)
+
 // EventData represents the data payload of a HyperFleet CloudEvent
 type EventData struct {
 	OwnerReferences *ResourceRef `json:"owner_references,omitempty"`
@@ -67,15 +114,68 @@ type ExecutorConfig struct {
 	Logger logger.Logger
 	// MetricsRecorder records adapter-level Prometheus metrics (nil disables recording)
 	MetricsRecorder *metrics.Recorder
+	// Heartbeat is beaten after every completed execution (nil disables it)
+	Heartbeat *health.Heartbeat
+	// ExecutionHistory records a redacted summary of every completed execution (nil disables it)
+	ExecutionHistory *health.ExecutionHistory
+	// Auditor writes the audit record of every completed execution (nil disables it)
+	Auditor *audit.Auditor
+	// Runtime identifies this adapter instance in the adapter map (nil reads it from the
+	// environment without a config hash)
+	Runtime *RuntimeMetadata
+	// Clock times executions and evaluations (nil uses the real clock)
+	Clock clock.Clock
+	// WebhookClient delivers webhook post actions (nil creates one from clients.webhook)
+	WebhookClient WebhookClient
+	// Journal records the executions whose post actions have not run yet (nil disables it)
+	Journal journal.Store
+	// FeatureFlags are the flags of the flag() CEL function, snapshotted by each
+	// execution when it starts (nil: every flag is off)
+	FeatureFlags *featureflags.Store
+	// PostBuffer buffers the failed API calls of the post actions with
+	// buffer_on_failure (nil fails them like the others)
+	PostBuffer *postbuffer.Buffer
+	// Idempotency keeps the processed generations of the idempotency guard (nil
+	// uses an in-memory store when the config has an idempotency guard)
+	Idempotency idempotency.Store
+	// LookupTables are the maps of lookup(), with the entries of the ConfigMap
+	// backed ones (nil builds them from the inline maps of the config)
+	LookupTables *lookup.Tables
 }
 
 // Executor processes CloudEvents according to the adapter configuration
 type Executor struct {
-	config             *ExecutorConfig
-	precondExecutor    *PreconditionExecutor
-	resourceExecutor   *ResourceExecutor
+	config           *ExecutorConfig
+	precondExecutor  *PreconditionExecutor
+	resourceExecutor *ResourceExecutor
+	// precondLevels are the precondition levels of every workflow, by name
+	precondLevels map[string][][]int
+	// subjectPatterns are the parsed subject_patterns, tried in order
+	subjectPatterns    []*configloader.SubjectPattern
 	postActionExecutor *PostActionExecutor
 	log                logger.Logger
+	clock              clock.Clock
+	runtime            RuntimeMetadata
+	// stats are the counters behind Stats
+	stats *executorStats
+	// fence serializes executions by their execution_fence key
+	fence *executionFence
+	// notMet tracks the consecutive not-met executions of the not_met_backoff keys
+	notMet *notMetTracker
+	// schedule is the compiled maintenance schedule, nil without one
+	schedule *schedule.Schedule
+	// idempotency keeps the processed generations, nil without an idempotency guard
+	idempotency idempotency.Store
+	// lookup are the maps of lookup()
+	lookup *lookup.Tables
+	// templateFuncs are the template functions with lookup bound to the maps,
+	// nil without maps
+	templateFuncs template.FuncMap
+	// inFlight counts the executions running now, read by InFlight and Stats
+	inFlight atomic.Int64
+	// beforePhase is called before the preconditions, resources and post
+	// actions phases run, e.g. by tests stopping an execution between phases
+	beforePhase func(phase ExecutionPhase)
 }
 
 // ExecutionResult contains the result of processing an event
@@ -86,24 +186,74 @@ type ExecutionResult struct {
 	Params map[string]interface{}
 	// Errors contains errors keyed by the phase where they occurred
 	Errors map[ExecutionPhase]error
+	// SchemaViolations lists event schema violations when schema validation failed
+	SchemaViolations []configloader.SchemaViolation
 	// SkipReason is why resources were skipped (e.g., "precondition not met")
 	SkipReason string
+	// TraceID is the OpenTelemetry trace ID of the execution, empty when the
+	// execution was not traced
+	TraceID string
+	// CorrelationID identifies the execution across the adapter logs, the
+	// HyperFleet API calls and the applied resources
+	CorrelationID string
+	// DeliveryAttempt is the broker delivery attempt of the event, 1 for its first
+	// delivery, delivery.AttemptUnknown outside of the broker consumer
+	DeliveryAttempt int
+	// ExecutionKey is the rendered execution_fence key, empty without a fence
+	ExecutionKey string
+	// Workflow is the name of the workflow whose phases ran, empty when the
+	// execution ended before the workflow was selected
+	Workflow string
+	// DataContentType is the media type of the event data, application/json
+	// for events without a data content type
+	DataContentType string
+	// DataDecision is what was done with the event data based on its content type
+	DataDecision DataDecision
+	// Cancelled records where the execution stopped when its context was
+	// cancelled mid-phase, nil if it was not
+	Cancelled *CancelledError
+	// PhaseDurations is the time spent in each phase that ran
+	PhaseDurations map[ExecutionPhase]time.Duration
+	// Duration is the total time of the execution
+	Duration time.Duration
+	// RetryBudget is the use of the retry budget (limits.retry_budget) by the
+	// steps, nil when the execution ended before they ran
+	RetryBudget *retrybudget.Report
+	// FeatureFlags are the feature flags the execution saw, taken when it started
+	FeatureFlags featureflags.Snapshot
 	// Status is the overall execution status (runtime perspective)
 	Status ExecutionStatus
 	// CurrentPhase is the phase where execution ended (or is currently)
 	CurrentPhase ExecutionPhase
 	// PreconditionResults contains results of precondition evaluations
 	PreconditionResults []PreconditionResult
+	// PreconditionErrors contains the error of every failed precondition
+	// (see PreconditionsOutcome.Errors)
+	PreconditionErrors []error
 	// ResourceResults contains results of resource operations
 	ResourceResults []ResourceResult
 	// PostActionResults contains results of post-action executions
 	PostActionResults []PostActionResult
+	// APICalls lists the HyperFleet API calls made by the execution, in call order
+	APICalls []APICallRecord
+	// PayloadReports lists, for every post payload built, the values whose field
+	// or expression failed to evaluate and the on_error policy applied
+	PayloadReports []PayloadBuildReport
+	// RetryAfter asks for the event to be redelivered after this delay instead of
+	// being acknowledged: set by an unmet precondition with retry_after, or by a
+	// failure whose cause requested a delay (e.g. HTTP 429 with Retry-After)
+	RetryAfter time.Duration
+	// DeferredUntil is when the next maintenance window opens, set when the
+	// resources were skipped outside every window of the schedule
+	DeferredUntil time.Time
 	// ResourcesSkipped indicates if resources were skipped (business outcome)
 	ResourcesSkipped bool
 }
 
 // PreconditionResult contains the result of a single precondition evaluation
 type PreconditionResult struct {
+	// StartedAt is when the precondition started
+	StartedAt time.Time
 	// Error is the error if Status is StatusFailed
 	Error error
 	// CapturedFields contains fields captured from the API response
@@ -112,9 +262,12 @@ type PreconditionResult struct {
 	CELResult *criteria.CELResult
 	// Name is the precondition name
 	Name string
+	// APITarget is the HyperFleet API target of the API call, if configured
+	APITarget string
 	// Status is the result status
 	Status ExecutionStatus
-	// APIResponse contains the raw API response (if APICallMade)
+	// APIResponse contains the raw API response (if APICallMade), cut to the
+	// clients.hyperfleet_api.max_retained_response_bytes limit
 	APIResponse []byte
 	// ConditionResults contains individual condition evaluation results
 	ConditionResults []criteria.EvaluationResult
@@ -122,10 +275,20 @@ type PreconditionResult struct {
 	Matched bool
 	// APICallMade indicates if an API call was made
 	APICallMade bool
+	// APICall records the API call, nil if none was made
+	APICall *APICallRecord
+	// Duration is how long the precondition took
+	Duration time.Duration
+	// RetryWait is the time the step waited between retries, debited from the retry budget
+	RetryWait time.Duration
+	// APIResponseTruncated indicates that APIResponse was cut
+	APIResponseTruncated bool
 }
 
 // ResourceResult contains the result of a single resource operation
 type ResourceResult struct {
+	// StartedAt is when the resource operation started
+	StartedAt time.Time
 	// Error is the error if Status is StatusFailed
 	Error error
 	// Name is the resource name from config
@@ -136,6 +299,10 @@ type ResourceResult struct {
 	Namespace string
 	// ResourceName is the actual K8s resource name
 	ResourceName string
+	// APIVersion is the apiVersion of the rendered manifest
+	APIVersion string
+	// ContentHash is the sha256 digest of the rendered manifest ("sha256:<hex>")
+	ContentHash string
 	// OperationReason explains why this operation was performed
 	// Examples: "resource not found", "generation changed from 1 to 2",
 	// "generation 1 unchanged", "recreate_on_change=true"
@@ -144,29 +311,57 @@ type ResourceResult struct {
 	Status ExecutionStatus
 	// Operation is the operation performed (create, update, recreate, skip)
 	Operation manifest.Operation
+	// Duration is how long the resource operation took
+	Duration time.Duration
+	// RetryWait is the time the step waited between retries, debited from the retry budget
+	RetryWait time.Duration
 }
 
 // PostActionResult contains the result of a single post-action execution
 type PostActionResult struct {
+	// StartedAt is when the post action started
+	StartedAt time.Time
 	// Error is the error if Status is StatusFailed
 	Error error
 	// Name is the post-action name
 	Name string
 	// SkipReason is the reason for skipping
 	SkipReason string
+	// APITarget is the HyperFleet API target of the API call, if configured
+	APITarget string
 	// Status is the result status
 	Status ExecutionStatus
-	// APIResponse contains the raw API response (if APICallMade)
+	// APIResponse contains the raw API response (if APICallMade), cut to the
+	// clients.hyperfleet_api.max_retained_response_bytes limit
 	APIResponse []byte
+	// ResourceVersion is the resourceVersion of the object patched by a k8s_patch action
+	ResourceVersion string
 	// HTTPStatus is the HTTP status code of the API response
 	HTTPStatus int
+	// APICall records the API call, nil if none was made
+	APICall *APICallRecord
+	// Webhook is the delivery of a webhook action, nil if none was made
+	Webhook *webhook.Delivery
+	// Duration is how long the post action took
+	Duration time.Duration
+	// RetryWait is the time the step waited between retries, debited from the retry budget
+	RetryWait time.Duration
 	// Skipped indicates if the action was skipped due to when condition
 	Skipped bool
 	// APICallMade indicates if an API call was made
 	APICallMade bool
+	// K8sPatchMade indicates if a Kubernetes patch was made
+	K8sPatchMade bool
+	// APIResponseTruncated indicates that APIResponse was cut
+	APIResponseTruncated bool
+	// Buffered indicates the API call failed and was buffered by buffer_on_failure,
+	// to be replayed in the background
+	Buffered bool
 }
 
-// ExecutionContext holds runtime context during execution
+// ExecutionContext holds runtime context during execution.
+// Params, the execution error, the evaluation and API call records are guarded by a mutex:
+// access them through the methods, which are safe for concurrent use.
 type ExecutionContext struct {
 	// Ctx is the Go context
 	Ctx context.Context
@@ -174,18 +369,50 @@ type ExecutionContext struct {
 	Config *configloader.Config
 	// EventData is the parsed event data payload
 	EventData map[string]interface{}
-	// Params holds extracted parameters and captured fields
+	// params holds extracted parameters and captured fields
 	// - Populated during param extraction phase with event/env data
 	// - Populated during precondition phase with captured API response fields
-	Params map[string]interface{}
+	// - Populated during post actions with built payloads
+	params map[string]interface{}
 	// Resources holds discovered resources keyed by resource name.
 	// Nested discoveries are also added as top-level entries keyed by nested discovery name.
 	// Values are expected to be *unstructured.Unstructured.
 	Resources map[string]interface{}
-	// Evaluations tracks all condition evaluations for debugging/auditing
-	Evaluations []EvaluationRecord
+	// evaluations tracks the condition evaluations for debugging/auditing, a ring
+	// buffer of the last limits.max_evaluation_records whose oldest is at evalNext
+	evaluations []EvaluationRecord
+	evalNext    int
+	// evalDropped counts the evaluations overwritten in the ring buffer
+	evalDropped int
+	// apiCalls records the HyperFleet API calls made by the execution
+	apiCalls []APICallRecord
+	// payloadReports records the builds of the post payloads
+	payloadReports []PayloadBuildReport
+	// resourceSummary summarizes the resources phase for the post payloads
+	resourceSummary *ResourceSummary
+	// retainedBytes is the size of the API response bodies retained by the execution
+	retainedBytes int
+	// retryBudget is the retry wait time shared by the steps (limits.retry_budget)
+	retryBudget *retrybudget.Budget
+	// flags are the feature flags taken when the execution started, seen by
+	// every flag() of the execution whatever the flags became since
+	flags featureflags.Snapshot
+	// unknownFlags are the unknown flags flag() was called with, warned about once
+	unknownFlags map[string]bool
+	// lookup are the maps of lookup() (see Executor.lookup)
+	lookup *lookup.Tables
+	// templateFuncs are the template functions of the execution (see Executor.templateFuncs)
+	templateFuncs template.FuncMap
+	// executionKey is the rendered execution_fence key, empty without a fence
+	executionKey string
+	// rawResponses are the names of the params holding parsed precondition
+	// API responses, dropped by Compact
+	rawResponses []string
 	// Adapter holds adapter execution metadata
 	Adapter AdapterMetadata
+	// clock timestamps evaluations
+	clock clock.Clock
+	mu    sync.RWMutex
 }
 
 // EvaluationRecord tracks a single condition evaluation during execution
@@ -207,6 +434,66 @@ type EvaluationRecord struct {
 	Matched bool
 }
 
+// PayloadBuildReport reports the build of a post payload
+type PayloadBuildReport struct {
+	// Payload is the name of the payload
+	Payload string `json:"payload"`
+	// Errors are the values whose field or expression failed to evaluate, by path
+	Errors []PayloadValueError `json:"errors,omitempty"`
+}
+
+// PayloadValueError records a payload value whose field or expression failed
+// to evaluate
+type PayloadValueError struct {
+	// Path is the path of the value in the payload, e.g. conditions[0].reason
+	Path string `json:"path"`
+	// Source is the field or expression of the value
+	Source string `json:"source"`
+	// Policy is the on_error policy applied
+	Policy string `json:"policy"`
+	// Reason is the evaluation error
+	Reason string `json:"reason"`
+}
+
+// add records a value error, a no-op on a nil report
+func (r *PayloadBuildReport) add(valueErr PayloadValueError) {
+	if r != nil {
+		r.Errors = append(r.Errors, valueErr)
+	}
+}
+
+// APICallRecord records a HyperFleet API call made during execution. The URL
+// has the values of credential query parameters redacted.
+type APICallRecord struct {
+	// Phase is the execution phase that made the call
+	Phase ExecutionPhase
+	// Step is the name of the precondition or post action that made the call
+	Step string
+	// Method is the HTTP method
+	Method string
+	// URL is the rendered URL, empty if the call failed before rendering it
+	URL string
+	// Target is the HyperFleet API target the call was sent to
+	Target string
+	// Error is the error of the call, empty on success
+	Error string
+	// RequestID is the X-Request-Id header of the response, empty if none was received
+	RequestID string
+	// ResponseBody is the response body, cut to the
+	// clients.hyperfleet_api.max_retained_response_bytes limit
+	ResponseBody []byte
+	// StatusCode is the HTTP status code of the response, 0 if none was received
+	StatusCode int
+	// Latency is how long the call took, including retries
+	Latency time.Duration
+	// Attempts is how many attempts were made, including retries
+	Attempts int
+	// ResponseTruncated indicates that ResponseBody was cut
+	ResponseTruncated bool
+	// request is the rendered request, nil if the call failed before it was rendered
+	request *hyperfleetapi.Request
+}
+
 // EvaluationType indicates the type of evaluation performed
 type EvaluationType string
 
@@ -219,8 +506,9 @@ const (
 
 // AdapterMetadata holds adapter execution metadata for CEL expressions
 type AdapterMetadata struct {
-	// ExecutionError contains detailed error information if execution failed
-	ExecutionError *ExecutionError `json:"executionError,omitempty"`
+	// executionError contains detailed error information if execution failed,
+	// see ExecutionContext.GetExecutionError
+	executionError *ExecutionError
 	// ExecutionStatus is the overall execution status (runtime perspective: "success", "failed")
 	ExecutionStatus string
 	// ErrorReason is the error reason if failed (process execution errors only)
@@ -229,8 +517,17 @@ type AdapterMetadata struct {
 	ErrorMessage string
 	// SkipReason is why resources were skipped (e.g., "precondition not met")
 	SkipReason string `json:"skipReason,omitempty"`
+	// CorrelationID is the correlation ID of the execution
+	CorrelationID string `json:"correlationId,omitempty"`
+	// Workflow is the name of the selected workflow
+	Workflow string `json:"workflow,omitempty"`
+	// DeferredUntil is the RFC3339 time the next maintenance window opens, set
+	// outside every window of the schedule
+	DeferredUntil string `json:"deferredUntil,omitempty"`
 	// ResourcesSkipped indicates if resources were skipped (business outcome)
 	ResourcesSkipped bool `json:"resourcesSkipped,omitempty"`
+	// Runtime identifies the adapter instance processing the event
+	Runtime RuntimeMetadata `json:"-"`
 }
 
 // ExecutionError represents a structured execution error
@@ -241,6 +538,8 @@ type ExecutionError struct {
 	Step string `json:"step"`
 	// Message is the error message (includes all relevant details)
 	Message string `json:"message"`
+	// Code is the machine-readable error code
+	Code ErrorCode `json:"code"`
 }
 
 // NewExecutionContext creates a new execution context
@@ -249,19 +548,46 @@ func NewExecutionContext(
 	eventData map[string]interface{},
 	config *configloader.Config,
 ) *ExecutionContext {
+	var limits *configloader.Limits
+	if config != nil {
+		limits = config.Limits
+	}
 	return &ExecutionContext{
 		Ctx:         ctx,
 		Config:      config,
 		EventData:   eventData,
-		Params:      make(map[string]interface{}),
+		params:      make(map[string]interface{}),
 		Resources:   make(map[string]interface{}),
-		Evaluations: make([]EvaluationRecord, 0),
+		evaluations: make([]EvaluationRecord, 0),
+		retryBudget: retrybudget.New(limits.RetryBudgetLimit()),
 		Adapter: AdapterMetadata{
 			ExecutionStatus: string(StatusSuccess),
 		},
+		clock: clock.Real,
 	}
 }
 
+// stepContext returns ctx carrying the retry budget of the execution, debited
+// on behalf of the step of phase
+func (ec *ExecutionContext) stepContext(ctx context.Context, phase ExecutionPhase, step string) context.Context {
+	return ec.retryBudget.WithStep(ctx, retryStep(phase, step))
+}
+
+// retryWait returns the retry wait time reserved by the step of phase
+func (ec *ExecutionContext) retryWait(phase ExecutionPhase, step string) time.Duration {
+	return ec.retryBudget.Spent(retryStep(phase, step))
+}
+
+// retryStep names the step of phase in the retry budget, e.g. "preconditions/clusterStatus"
+func retryStep(phase ExecutionPhase, step string) string {
+	return string(phase) + "/" + step
+}
+
+// now returns the current time of the execution clock
+func (ec *ExecutionContext) now() time.Time {
+	return clock.OrReal(ec.clock).Now()
+}
+
 // AddEvaluation records a condition evaluation result
 func (ec *ExecutionContext) AddEvaluation(
 	phase ExecutionPhase,
@@ -271,15 +597,39 @@ func (ec *ExecutionContext) AddEvaluation(
 	matched bool,
 	fieldResults map[string]criteria.EvaluationResult,
 ) {
-	ec.Evaluations = append(ec.Evaluations, EvaluationRecord{
+	record := EvaluationRecord{
 		Phase:          phase,
 		Name:           name,
 		EvaluationType: evalType,
 		Expression:     expression,
 		Matched:        matched,
 		FieldResults:   fieldResults,
-		Timestamp:      time.Now(),
-	})
+		Timestamp:      ec.now(),
+	}
+	limit := ec.limits().EvaluationRecordLimit()
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	if len(ec.evaluations) < limit {
+		ec.evaluations = append(ec.evaluations, record)
+		return
+	}
+	// Full: overwrite the oldest record
+	ec.evaluations[ec.evalNext] = record
+	ec.evalNext = (ec.evalNext + 1) % len(ec.evaluations)
+	ec.evalDropped++
+}
+
+// orderedEvaluations returns the evaluation records from the oldest, the caller holding ec.mu
+func (ec *ExecutionContext) orderedEvaluations() []EvaluationRecord {
+	return append(slices.Clone(ec.evaluations[ec.evalNext:]), ec.evaluations[:ec.evalNext]...)
+}
+
+// EvaluationsDropped returns how many of the oldest evaluation records were
+// dropped over the limits.max_evaluation_records limit
+func (ec *ExecutionContext) EvaluationsDropped() int {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+	return ec.evalDropped
 }
 
 // AddCELEvaluation is a convenience method for recording CEL expression evaluations
@@ -297,10 +647,19 @@ func (ec *ExecutionContext) AddConditionsEvaluation(
 	ec.AddEvaluation(phase, name, EvaluationTypeConditions, "", matched, fieldResults)
 }
 
+// GetEvaluations returns a copy of the retained evaluation records, in the order they were added
+func (ec *ExecutionContext) GetEvaluations() []EvaluationRecord {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+	return ec.orderedEvaluations()
+}
+
 // GetEvaluationsByPhase returns all evaluations for a specific phase
 func (ec *ExecutionContext) GetEvaluationsByPhase(phase ExecutionPhase) []EvaluationRecord {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
 	var results []EvaluationRecord
-	for _, eval := range ec.Evaluations {
+	for _, eval := range ec.orderedEvaluations() {
 		if eval.Phase == phase {
 			results = append(results, eval)
 		}
@@ -310,8 +669,10 @@ func (ec *ExecutionContext) GetEvaluationsByPhase(phase ExecutionPhase) []Evalua
 
 // GetFailedEvaluations returns all evaluations that did not match
 func (ec *ExecutionContext) GetFailedEvaluations() []EvaluationRecord {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
 	var results []EvaluationRecord
-	for _, eval := range ec.Evaluations {
+	for _, eval := range ec.orderedEvaluations() {
 		if !eval.Matched {
 			results = append(results, eval)
 		}
@@ -319,19 +680,106 @@ func (ec *ExecutionContext) GetFailedEvaluations() []EvaluationRecord {
 	return results
 }
 
+// AddAPICall records a HyperFleet API call
+func (ec *ExecutionContext) AddAPICall(call APICallRecord) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	ec.apiCalls = append(ec.apiCalls, call)
+}
+
+// GetAPICalls returns a copy of the recorded HyperFleet API calls
+func (ec *ExecutionContext) GetAPICalls() []APICallRecord {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+	return slices.Clone(ec.apiCalls)
+}
+
+// AddPayloadReport records the build of a post payload, its errors sorted by path
+func (ec *ExecutionContext) AddPayloadReport(report *PayloadBuildReport) {
+	slices.SortStableFunc(report.Errors, func(a, b PayloadValueError) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	ec.payloadReports = append(ec.payloadReports, *report)
+}
+
+// GetPayloadReports returns a copy of the recorded post payload builds
+func (ec *ExecutionContext) GetPayloadReports() []PayloadBuildReport {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+	return slices.Clone(ec.payloadReports)
+}
+
+// SetResourceSummary sets the summary of the resources phase
+func (ec *ExecutionContext) SetResourceSummary(summary *ResourceSummary) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	ec.resourceSummary = summary
+}
+
+// ResourceSummary returns the summary of the resources phase, nil before it ran
+func (ec *ExecutionContext) ResourceSummary() *ResourceSummary {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+	return ec.resourceSummary
+}
+
+// GetParam returns the param with the given name and whether it is set
+func (ec *ExecutionContext) GetParam(name string) (interface{}, bool) {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+	value, ok := ec.params[name]
+	return value, ok
+}
+
+// SetParam sets the param with the given name
+func (ec *ExecutionContext) SetParam(name string, value interface{}) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	ec.params[name] = value
+}
+
+// ParamsSnapshot returns a copy of the params, e.g. as template data.
+// The copy is shallow: param values are shared and must not be modified.
+func (ec *ExecutionContext) ParamsSnapshot() map[string]interface{} {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+	return maps.Clone(ec.params)
+}
+
+// GetExecutionError returns the error of the step that failed the execution, if any
+func (ec *ExecutionContext) GetExecutionError() *ExecutionError {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+	return ec.Adapter.executionError
+}
+
+// SetExecutionError records the error of the step that failed the execution
+func (ec *ExecutionContext) SetExecutionError(execErr *ExecutionError) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	ec.Adapter.executionError = execErr
+}
+
 // SetError sets the error status in adapter metadata (for runtime failures)
-func (ec *ExecutionContext) SetError(reason, message string) {
+func (ec *ExecutionContext) SetError(reason, message string, code ErrorCode) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
 	ec.Adapter.ExecutionStatus = string(StatusFailed)
 	ec.Adapter.ErrorReason = reason
 	ec.Adapter.ErrorMessage = message
-	ec.Adapter.ExecutionError = &ExecutionError{
+	ec.Adapter.executionError = &ExecutionError{
 		Phase:   reason,
 		Message: message,
+		Code:    code,
 	}
 }
 
 // SetSkipped sets the status to indicate execution was skipped (not an error)
 func (ec *ExecutionContext) SetSkipped(reason, message string) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
 	// Execution was successful, but resources were skipped due to business logic
 	ec.Adapter.ExecutionStatus = string(StatusSuccess)
 	ec.Adapter.ResourcesSkipped = true
@@ -341,13 +789,43 @@ func (ec *ExecutionContext) SetSkipped(reason, message string) {
 	}
 }
 
+// SetWorkflow records the selected workflow in the adapter metadata and in the
+// adapter param of templates
+func (ec *ExecutionContext) SetWorkflow(name string) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	ec.Adapter.Workflow = name
+	// Param values are shared with snapshots, so the adapter map is replaced
+	if adapter, ok := ec.params["adapter"].(map[string]interface{}); ok {
+		adapter = maps.Clone(adapter)
+		adapter[AdapterKeyWorkflow] = name
+		ec.params["adapter"] = adapter
+	}
+}
+
+// SetDeferredUntil records when the next maintenance window opens in the adapter
+// metadata and in the adapter param of templates
+func (ec *ExecutionContext) SetDeferredUntil(next time.Time) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	ec.Adapter.DeferredUntil = next.UTC().Format(time.RFC3339)
+	// Param values are shared with snapshots, so the adapter map is replaced
+	if adapter, ok := ec.params["adapter"].(map[string]interface{}); ok {
+		adapter = maps.Clone(adapter)
+		adapter[AdapterKeyDeferredUntil] = ec.Adapter.DeferredUntil
+		ec.params["adapter"] = adapter
+	}
+}
+
 // GetCELVariables returns all variables for CEL evaluation.
 // This includes Params, adapter metadata, and resources.
 func (ec *ExecutionContext) GetCELVariables() map[string]interface{} {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
 	result := make(map[string]interface{})
 
 	// Copy all params
-	for k, v := range ec.Params {
+	for k, v := range ec.params {
 		result[k] = v
 	}
 
@@ -377,15 +855,83 @@ func (ec *ExecutionContext) GetCELVariables() map[string]interface{} {
 	return result
 }
 
+// ErrCancelled matches the CancelledError of a phase stopped by context cancellation
+var ErrCancelled = errors.New("execution cancelled")
+
+// CancelledError is returned by a phase executor whose context was cancelled
+// before all its items ran. Items after LastCompleted were not executed.
+type CancelledError struct {
+	// Cause is the context error: context.Canceled or context.DeadlineExceeded
+	Cause error
+	Phase ExecutionPhase
+	// LastCompleted is the index of the last item of the phase that completed, -1 if none did
+	LastCompleted int
+}
+
+// cancelled returns the CancelledError of phase if ctx is done, nil otherwise
+func cancelled(ctx context.Context, phase ExecutionPhase, lastCompleted int) *CancelledError {
+	if err := ctx.Err(); err != nil {
+		return &CancelledError{Cause: err, Phase: phase, LastCompleted: lastCompleted}
+	}
+	return nil
+}
+
+func (e *CancelledError) Error() string {
+	return fmt.Sprintf("[%s] cancelled after item %d: %v", e.Phase, e.LastCompleted, e.Cause)
+}
+
+func (e *CancelledError) Unwrap() error {
+	return e.Cause
+}
+
+// Is matches ErrCancelled
+func (e *CancelledError) Is(target error) bool {
+	return target == ErrCancelled
+}
+
+// redeliveryError is the error of an execution whose event is NACKed for
+// redelivery. It implements brokerconsumer.FailureCause, so the redeliveries
+// are counted by the phase and error code the execution failed with.
+type redeliveryError struct {
+	err   error
+	phase ExecutionPhase
+	code  ErrorCode
+}
+
+func (e *redeliveryError) Error() string {
+	return e.err.Error()
+}
+
+func (e *redeliveryError) Unwrap() error {
+	return e.err
+}
+
+// FailurePhase implements brokerconsumer.FailureCause
+func (e *redeliveryError) FailurePhase() string {
+	return string(e.phase)
+}
+
+// FailureCode implements brokerconsumer.FailureCause. It is empty for the
+// redeliveries asked without an error, e.g. by a precondition's retry_after.
+func (e *redeliveryError) FailureCode() string {
+	return string(e.code)
+}
+
 // ExecutorError represents an error during execution
 type ExecutorError struct {
 	Err     error
 	Phase   ExecutionPhase
+	Code    ErrorCode
 	Step    string
 	Message string
 }
 
 func (e *ExecutorError) Error() string {
+	// The wrappers of a template error were formatted before its path was
+	// complete: the template error itself names the failed field
+	if tmplErr, ok := IsTemplateError(e.Err); ok {
+		return fmt.Sprintf("[%s] %s: %s: %v", e.Phase, e.Step, e.Message, tmplErr)
+	}
 	if e.Err != nil {
 		return fmt.Sprintf("[%s] %s: %s: %v", e.Phase, e.Step, e.Message, e.Err)
 	}
@@ -397,9 +943,10 @@ func (e *ExecutorError) Unwrap() error {
 }
 
 // NewExecutorError creates a new executor error
-func NewExecutorError(phase ExecutionPhase, step, message string, err error) *ExecutorError {
+func NewExecutorError(phase ExecutionPhase, code ErrorCode, step, message string, err error) *ExecutorError {
 	return &ExecutorError{
 		Phase:   phase,
+		Code:    code,
 		Step:    step,
 		Message: message,
 		Err:     err,
@@ -409,10 +956,15 @@ func NewExecutorError(phase ExecutionPhase, step, message string, err error) *Ex
 // PreconditionsOutcome represents the high-level result of precondition evaluation
 type PreconditionsOutcome struct {
 	// Error contains execution errors (API failures, parse errors, etc.)
-	// nil if preconditions were evaluated successfully, even if not matched
+	// nil if preconditions were evaluated successfully, even if not matched.
+	// With the collectAll error policy it aggregates all Errors.
 	Error error
+	// Errors contains the error of every failed precondition, in order
+	Errors []error
 	// NotMetReason provides details when AllMatched is false
 	NotMetReason string
+	// RetryAfter is the redelivery delay requested by the unmet precondition, zero for none
+	RetryAfter time.Duration
 	// Results contains individual precondition results
 	Results []PreconditionResult
 	// AllMatched indicates whether all preconditions were satisfied (business outcome)
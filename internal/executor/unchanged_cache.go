@@ -0,0 +1,91 @@
+package executor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/lru"
+)
+
+// skip_if_unchanged defaults
+const (
+	DefaultUnchangedTTL        = 10 * time.Minute
+	DefaultUnchangedMaxEntries = 10000
+)
+
+// SkipReasonUnchanged is the SkipReason of a post action skipped by skip_if_unchanged
+const SkipReasonUnchanged = "unchanged"
+
+// unchangedEntry is the hash of the last body sent for a key
+type unchangedEntry struct {
+	sentAt time.Time
+	hash   string
+}
+
+// unchangedCache is an LRU of the body hashes last sent by skip_if_unchanged
+// post actions, keyed by action name and rendered key
+type unchangedCache struct {
+	entries *lru.Cache[string, unchangedEntry]
+	clock   clock.Clock
+}
+
+func newUnchangedCache(maxEntries int, clk clock.Clock) *unchangedCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultUnchangedMaxEntries
+	}
+	return &unchangedCache{
+		entries: lru.New[string, unchangedEntry](maxEntries),
+		clock:   clock.OrReal(clk),
+	}
+}
+
+// unchanged reports whether hash is the hash last stored for key, less than ttl ago
+func (c *unchangedCache) unchanged(key, hash string, ttl time.Duration) bool {
+	entry, ok := c.entries.Peek(key)
+	if !ok {
+		return false
+	}
+	if c.clock.Since(entry.sentAt) >= ttl {
+		c.entries.Remove(key)
+		return false
+	}
+	return entry.hash == hash
+}
+
+// store records hash as the last body sent for key, evicting the least recently
+// sent entries beyond capacity
+func (c *unchangedCache) store(key, hash string) {
+	c.entries.Add(key, unchangedEntry{hash: hash, sentAt: c.clock.Now()})
+}
+
+// invalidate forgets the body last sent for key, so a failed send is never
+// taken as the state the receiver holds
+func (c *unchangedCache) invalidate(key string) {
+	c.entries.Remove(key)
+}
+
+// len returns the number of entries held
+func (c *unchangedCache) len() int {
+	return c.entries.Len()
+}
+
+// bodyHash returns the hex sha256 of body. JSON bodies are hashed in canonical
+// form (see canonicalJSON), so bodies that only differ in key order or
+// formatting hash identically.
+func bodyHash(body []byte) string {
+	// UseNumber keeps large integers exact
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err == nil && !decoder.More() {
+		if canonical, marshalErr := canonicalJSON(decoded); marshalErr == nil {
+			body = canonical
+		}
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyHash(t *testing.T) {
+	base := bodyHash([]byte(`{"conditions":[{"type":"Ready","status":"True"}],"observed_generation":3}`))
+
+	tests := []struct {
+		name string
+		body string
+		same bool
+	}{
+		{
+			name: "key order",
+			body: `{"observed_generation":3,"conditions":[{"status":"True","type":"Ready"}]}`,
+			same: true,
+		},
+		{
+			name: "whitespace",
+			body: "{\n  \"conditions\": [ {\"type\": \"Ready\", \"status\": \"True\"} ],\n  \"observed_generation\": 3\n}",
+			same: true,
+		},
+		{name: "changed value", body: `{"conditions":[{"type":"Ready","status":"False"}],"observed_generation":3}`},
+		{name: "array order", body: `{"conditions":[{"status":"True"},{"type":"Ready"}],"observed_generation":3}`},
+		{name: "not JSON", body: `conditions: Ready`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bodyHash([]byte(tt.body))
+			if tt.same {
+				assert.Equal(t, base, got)
+			} else {
+				assert.NotEqual(t, base, got)
+			}
+		})
+	}
+
+	assert.NotEqual(t, bodyHash([]byte(`{"id":9007199254740993}`)), bodyHash([]byte(`{"id":9007199254740992}`)),
+		"large integers are hashed exactly")
+	assert.Equal(t, bodyHash([]byte(`plain text`)), bodyHash([]byte(`plain text`)))
+}
+
+func TestUnchangedCache(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	cache := newUnchangedCache(2, fake)
+
+	assert.False(t, cache.unchanged("a", "h1", time.Minute), "nothing sent yet")
+	cache.store("a", "h1")
+	assert.True(t, cache.unchanged("a", "h1", time.Minute))
+	assert.False(t, cache.unchanged("a", "h2", time.Minute), "a different body is sent")
+
+	fake.Advance(time.Minute)
+	assert.False(t, cache.unchanged("a", "h1", time.Minute), "the entry expired")
+
+	cache.store("a", "h1")
+	cache.invalidate("a")
+	assert.False(t, cache.unchanged("a", "h1", time.Minute), "invalidated")
+
+	cache.store("a", "h1")
+	cache.store("b", "h1")
+	cache.store("c", "h1")
+	assert.False(t, cache.unchanged("a", "h1", time.Minute), "the least recently sent entry is evicted")
+	assert.True(t, cache.unchanged("b", "h1", time.Minute))
+	assert.True(t, cache.unchanged("c", "h1", time.Minute))
+}
@@ -3,12 +3,15 @@ package executor
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"path"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -17,6 +20,8 @@ import (
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
 	apierrors "github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/errors"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/utils"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -35,8 +40,19 @@ func ToConditionDefs(conditions []configloader.Condition) []criteria.ConditionDe
 	return defs
 }
 
+// stepLogger derives the logger of a step (precondition, resource or post
+// action) from log, so every line emitted while executing the step, including
+// by API calls, template rendering and CEL evaluation, carries its phase and name
+func stepLogger(log logger.Logger, phase ExecutionPhase, step string) logger.Logger {
+	return log.WithFields(map[string]interface{}{
+		logger.PhaseKey: string(phase),
+		logger.StepKey:  step,
+	})
+}
+
 // ExecuteLogAction executes a log action with the given context
-// The message is rendered as a Go template with access to all params
+// The message is rendered as a Go template with access to all params, and the
+// fields are built like payload build values and added to the log line.
 // This is a shared utility function used by both PreconditionExecutor and PostActionExecutor
 func ExecuteLogAction(
 	ctx context.Context,
@@ -44,18 +60,29 @@ func ExecuteLogAction(
 	execCtx *ExecutionContext,
 	log logger.Logger,
 ) {
-	if logAction == nil || logAction.Message == "" {
+	if logAction == nil || (logAction.Message == "" && len(logAction.Fields) == 0) {
 		return
 	}
 
 	// Render the message template
-	message, err := renderTemplate(logAction.Message, execCtx.Params)
+	params := execCtx.templateParams()
+	message, err := renderTemplateAt("log.message", logAction.Message, params)
 	if err != nil {
 		errCtx := logger.WithErrorField(ctx, err)
 		log.Errorf(errCtx, "failed to render log message")
 		return
 	}
 
+	if len(logAction.Fields) > 0 {
+		fields, fieldsErr := buildLogFields(ctx, log, logAction.Fields, execCtx, params)
+		if fieldsErr != nil {
+			errCtx := logger.WithErrorField(ctx, fieldsErr)
+			log.Errorf(errCtx, "failed to build log fields")
+			return
+		}
+		log = log.WithFields(fields)
+	}
+
 	// Log at the specified level (default: info)
 	level := strings.ToLower(logAction.Level)
 	if level == "" {
@@ -77,6 +104,49 @@ func ExecuteLogAction(
 
 }
 
+// buildLogFields evaluates the fields of a log action against the CEL variables
+// and params of the execution
+func buildLogFields(
+	ctx context.Context,
+	log logger.Logger,
+	fields map[string]interface{},
+	execCtx *ExecutionContext,
+	params map[string]interface{},
+) (map[string]interface{}, error) {
+	evalCtx := criteria.NewEvaluationContext()
+	evalCtx.SetVariablesFromMap(execCtx.GetCELVariables())
+	evalCtx.SetFlags(execCtx.flagLookup(ctx, log))
+	evalCtx.SetLookup(execCtx.lookupFunc())
+	evaluator, err := criteria.NewEvaluator(ctx, evalCtx, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create evaluator: %w", err)
+	}
+	return buildMapPayload(ctx, log, fields, evaluator, params)
+}
+
+// logSampler counts the executions of sampled log actions across executions
+type logSampler struct {
+	counts map[string]uint64
+	mu     sync.Mutex
+}
+
+func newLogSampler() *logSampler {
+	return &logSampler{counts: make(map[string]uint64)}
+}
+
+// sample reports whether this execution of the log action of step is logged:
+// the first of every `every` executions is. A nil sampler logs every execution.
+func (s *logSampler) sample(step string, every int) bool {
+	if s == nil || every <= 1 {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.counts[step]
+	s.counts[step] = n + 1
+	return n%uint64(every) == 0
+}
+
 // ExecuteAPICall executes an API call with the given configuration and returns the response and rendered URL
 // This is a shared utility function used by both PreconditionExecutor and PostActionExecutor
 // On error, it returns an APIError with full context (method, URL, status, body, attempts, duration)
@@ -88,14 +158,28 @@ func ExecuteAPICall(
 	apiClient hyperfleetapi.Client,
 	log logger.Logger,
 ) (*hyperfleetapi.Response, string, error) {
+	resp, url, _, err := sendAPICall(ctx, apiCall, execCtx, apiClient, log)
+	return resp, url, err
+}
+
+// sendAPICall is ExecuteAPICall, also returning the rendered request, nil when
+// the call failed before it was rendered
+func sendAPICall(
+	ctx context.Context,
+	apiCall *configloader.APICall,
+	execCtx *ExecutionContext,
+	apiClient hyperfleetapi.Client,
+	log logger.Logger,
+) (*hyperfleetapi.Response, string, *hyperfleetapi.Request, error) {
 	if apiCall == nil {
-		return nil, "", fmt.Errorf("apiCall is nil")
+		return nil, "", nil, fmt.Errorf("apiCall is nil")
 	}
 
 	// First render the URL template to resolve variables like {{ .hyperfleetApiBaseUrl }}
-	renderedURL, err := renderTemplate(apiCall.URL, execCtx.Params)
+	params := execCtx.templateParams()
+	renderedURL, err := renderTemplateAt("url", apiCall.URL, params)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to render URL template: %w", err)
+		return nil, "", nil, fmt.Errorf("failed to render URL template: %w", err)
 	}
 
 	// Then build the final URL - this handles absolute URLs vs relative paths
@@ -107,11 +191,15 @@ func ExecuteAPICall(
 	opts := make([]hyperfleetapi.RequestOption, 0)
 
 	// Add headers
+	// The correlation header is set first so an API call can override it
 	headers := make(map[string]string)
-	for _, h := range apiCall.Headers {
-		headerValue, headerErr := renderTemplate(h.Value, execCtx.Params)
+	if correlationID := execCtx.Adapter.CorrelationID; correlationID != "" {
+		headers[execCtx.Config.Correlation.HeaderName()] = correlationID
+	}
+	for i, h := range apiCall.Headers {
+		headerValue, headerErr := renderTemplateAt(fmt.Sprintf("headers[%d].value", i), h.Value, params)
 		if headerErr != nil {
-			return nil, url, fmt.Errorf("failed to render header '%s' template: %w", h.Name, headerErr)
+			return nil, url, nil, fmt.Errorf("failed to render header '%s' template: %w", h.Name, headerErr)
 		}
 		headers[h.Name] = headerValue
 	}
@@ -140,19 +228,30 @@ func ExecuteAPICall(
 
 	// Execute request based on method
 	var resp *hyperfleetapi.Response
-	switch strings.ToUpper(apiCall.Method) {
+	method := strings.ToUpper(apiCall.Method)
+	var body *apiCallBody
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		body, err = renderAPICallBody(apiCall, params)
+		if err != nil {
+			return nil, url, nil, err
+		}
+		opts = append(opts, body.options...)
+		log.Debugf(ctx, "API call payload: %s %s payload=%s", apiCall.Method, url, body.description)
+	}
+	// The rendered request, buffered by buffer_on_failure when the call fails
+	request := &hyperfleetapi.Request{Method: method, URL: url}
+	if body != nil {
+		request.Body = body.raw
+	}
+	for _, opt := range opts {
+		opt(request)
+	}
+	switch method {
 	case http.MethodGet:
 		resp, err = apiClient.Get(ctx, url, opts...)
 	case http.MethodPost:
-		body := []byte(apiCall.Body)
-		if apiCall.Body != "" {
-			body, err = renderTemplateBytes(apiCall.Body, execCtx.Params)
-			if err != nil {
-				return nil, url, fmt.Errorf("failed to render body template: %w", err)
-			}
-		}
-		log.Debugf(ctx, "API call payload: %s %s payload=%s", apiCall.Method, url, string(body))
-		resp, err = apiClient.Post(ctx, url, body, opts...)
+		resp, err = apiClient.Post(ctx, url, body.raw, opts...)
 		// Log body on failure for debugging
 		if err != nil || (resp != nil && !resp.IsSuccess()) {
 			var logErr error
@@ -165,29 +264,13 @@ func ExecuteAPICall(
 			log.Error(errCtx, "Request failed")
 		}
 	case http.MethodPut:
-		body := []byte(apiCall.Body)
-		if apiCall.Body != "" {
-			body, err = renderTemplateBytes(apiCall.Body, execCtx.Params)
-			if err != nil {
-				return nil, "", fmt.Errorf("failed to render body template: %w", err)
-			}
-		}
-		log.Debugf(ctx, "API call payload: %s %s payload=%s", apiCall.Method, url, string(body))
-		resp, err = apiClient.Put(ctx, url, body, opts...)
+		resp, err = apiClient.Put(ctx, url, body.raw, opts...)
 	case http.MethodPatch:
-		body := []byte(apiCall.Body)
-		if apiCall.Body != "" {
-			body, err = renderTemplateBytes(apiCall.Body, execCtx.Params)
-			if err != nil {
-				return nil, "", fmt.Errorf("failed to render body template: %w", err)
-			}
-		}
-		log.Debugf(ctx, "API call payload: %s %s payload=%s", apiCall.Method, url, string(body))
-		resp, err = apiClient.Patch(ctx, url, body, opts...)
+		resp, err = apiClient.Patch(ctx, url, body.raw, opts...)
 	case http.MethodDelete:
 		resp, err = apiClient.Delete(ctx, url, opts...)
 	default:
-		return nil, url, fmt.Errorf("unsupported HTTP method: %s", apiCall.Method)
+		return nil, url, nil, fmt.Errorf("unsupported HTTP method: %s", apiCall.Method)
 	}
 
 	if err != nil {
@@ -206,7 +289,8 @@ func ExecuteAPICall(
 				resp.Duration,
 				err,
 			)
-			return resp, url, apiErr
+			apiErr.RetryAfter = retryAfterOf(err)
+			return resp, url, request, apiErr
 		} else {
 			log.Warnf(ctx, "API call failed: %v", err)
 			// No response - create APIError with minimal context
@@ -220,16 +304,114 @@ func ExecuteAPICall(
 				0,
 				err,
 			)
-			return resp, url, apiErr
+			apiErr.RetryAfter = retryAfterOf(err)
+			return resp, url, request, apiErr
 		}
 	}
 	if resp == nil {
 		nilErr := fmt.Errorf("API client returned nil response without error")
-		return nil, url, apierrors.NewAPIError(apiCall.Method, url, 0, "", nil, 0, 0, nilErr)
+		return nil, url, request, apierrors.NewAPIError(apiCall.Method, url, 0, "", nil, 0, 0, nilErr)
 	}
 
 	log.Infof(ctx, "API call completed: %d %s", resp.StatusCode, resp.Status)
-	return resp, url, nil
+	return resp, url, request, nil
+}
+
+// ResolveAPITarget renders the target of apiCall and returns its name and client.
+// An unset target selects the default target. A target that renders to a name
+// that is not configured, including an empty one, fails with an
+// *hyperfleetapi.UnknownTargetError naming the value and the valid targets,
+// so a missing tenant never silently falls back to the default target.
+func ResolveAPITarget(
+	apiCall *configloader.APICall,
+	execCtx *ExecutionContext,
+	apiClient hyperfleetapi.Client,
+) (string, hyperfleetapi.Client, error) {
+	if apiCall.Target == "" {
+		return hyperfleetapi.DefaultTarget, apiClient, nil
+	}
+	name, err := renderTemplateAt("target", apiCall.Target, execCtx.templateParams())
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to render API target template: %w", err)
+	}
+	name = strings.TrimSpace(name)
+	client, err := apiClient.Target(name)
+	if err != nil {
+		return name, nil, err
+	}
+	return name, client, nil
+}
+
+// executeTargetedAPICall resolves the target of apiCall and executes the call on
+// it, adding the target to the log fields and counting the call per target.
+// The call is recorded in execCtx under the given phase and step, and the
+// record is returned with the response.
+// Returns: response, renderedURL, record, error
+func executeTargetedAPICall(
+	ctx context.Context,
+	phase ExecutionPhase,
+	step string,
+	apiCall *configloader.APICall,
+	execCtx *ExecutionContext,
+	apiClient hyperfleetapi.Client,
+	recorder *metrics.Recorder,
+	log logger.Logger,
+) (*hyperfleetapi.Response, string, *APICallRecord, error) {
+	record := &APICallRecord{Phase: phase, Step: step, Method: apiCall.Method}
+	target, client, err := ResolveAPITarget(apiCall, execCtx, apiClient)
+	if err = atPath("api_call", err); err != nil {
+		record.Target = target
+		record.Error = err.Error()
+		execCtx.AddAPICall(*record)
+		return nil, "", record, err
+	}
+	ctx = logger.WithAPITarget(ctx, target)
+	started := execCtx.now()
+	resp, url, request, err := sendAPICall(ctx, apiCall, execCtx, client, log)
+	err = atPath("api_call", err)
+	record.request = request
+	record.Latency = execCtx.now().Sub(started)
+	outcome := "success"
+	if err != nil || resp == nil || !resp.IsSuccess() {
+		outcome = "failed"
+	}
+	recorder.RecordAPICall(target, outcome)
+
+	record.URL = hyperfleetapi.RedactURL(url)
+	record.Target = target
+	record.Attempts = apiCallAttempts(resp, err)
+	if resp != nil {
+		record.StatusCode = resp.StatusCode
+		record.RequestID = http.Header(resp.Headers).Get(RequestIDHeader)
+		record.ResponseBody, record.ResponseTruncated = execCtx.retainResponse(resp.Body)
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	execCtx.AddAPICall(*record)
+	return resp, url, record, err
+}
+
+// RequestIDHeader is the response header recorded as the request ID of an API call
+const RequestIDHeader = "X-Request-Id"
+
+// apiCallAttempts returns the attempts made by an API call: those of its
+// response, or of the innermost API error reporting them when it has none
+func apiCallAttempts(resp *hyperfleetapi.Response, err error) int {
+	if resp != nil && resp.Attempts > 0 {
+		return resp.Attempts
+	}
+	for err != nil {
+		apiErr, ok := apierrors.IsAPIError(err)
+		if !ok {
+			break
+		}
+		if apiErr.Attempts > 0 {
+			return apiErr.Attempts
+		}
+		err = apiErr.Err
+	}
+	return 0
 }
 
 // buildHyperfleetAPICallURL builds a full HyperFleet API URL when a relative path is provided.
@@ -345,7 +527,9 @@ func ValidateAPIResponse(resp *hyperfleetapi.Response, err error, method, url st
 	return nil
 }
 
-// renderTemplate renders a Go template string with the given data
+// templateCache caches the templates parsed by renderTemplate
+var templateCache = utils.NewTemplateCache(utils.DefaultTemplateCacheSize)
+
 // templateFuncs provides common functions for Go templates
 var templateFuncs = template.FuncMap{
 	// Time functions
@@ -427,8 +611,18 @@ var templateFuncs = template.FuncMap{
 	"string": func(v interface{}) string {
 		return fmt.Sprintf("%v", v)
 	},
+	// Encoding functions, e.g. to embed a built payload (a JSON string param) in a manifest
+	"toJson":   utils.ToJSON,
+	"fromJson": utils.FromJSON,
+	"toYaml":   utils.ToYAML,
+	"fromYaml": utils.FromYAML,
+	"indent":   utils.Indent,
+	"nindent":  utils.Nindent,
 }
 
+// renderTemplate renders a Go template string with the given data. It fails
+// with a *TemplateError, whose path the caller adds with atPath. Data made by
+// templateParams also carries the lookup function of the execution.
 // This is a shared utility used across preconditions, resources, and post-actions
 func renderTemplate(templateStr string, data map[string]interface{}) (string, error) {
 	// If no template delimiters, return as-is
@@ -436,14 +630,18 @@ func renderTemplate(templateStr string, data map[string]interface{}) (string, er
 		return templateStr, nil
 	}
 
-	tmpl, err := template.New("template").Funcs(templateFuncs).Option("missingkey=error").Parse(templateStr)
+	funcs := templateFuncs
+	if bound, ok := data[templateFuncsParam].(template.FuncMap); ok {
+		funcs = bound
+	}
+	tmpl, err := templateCache.Parse(templateStr, funcs, "missingkey=error")
 	if err != nil {
-		return "", fmt.Errorf("failed to parse template: %w", err)
+		return "", newTemplateError(templateStr, err)
 	}
 
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute template: %w", err)
+		return "", newTemplateError(templateStr, err)
 	}
 
 	return buf.String(), nil
@@ -458,6 +656,152 @@ func renderTemplateBytes(templateStr string, data map[string]interface{}) ([]byt
 	return []byte(result), nil
 }
 
+// paramRefPattern matches a template that is a single param reference, e.g. "{{ .statusPayload }}"
+var paramRefPattern = regexp.MustCompile(`^\{\{-?\s*\.([A-Za-z_][A-Za-z0-9_]*)\s*-?\}\}$`)
+
+// renderBody renders an api_call or k8s_patch body template. A body that is a single
+// reference to a structured param, such as a structured post payload, is marshaled to
+// JSON directly instead of being rendered as text; isJSON reports that case.
+func renderBody(body string, params map[string]interface{}) (rendered []byte, isJSON bool, err error) {
+	if match := paramRefPattern.FindStringSubmatch(strings.TrimSpace(body)); match != nil {
+		switch value := params[match[1]].(type) {
+		case map[string]interface{}, []interface{}:
+			rendered, err = json.Marshal(value)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to marshal param '%s' to JSON: %w", match[1], err)
+			}
+			return rendered, true, nil
+		}
+	}
+	rendered, err = renderTemplateBytes(body, params)
+	return rendered, false, err
+}
+
+// apiCallBody is the rendered body of an API call: the raw body, or the
+// request options sending its form fields or multipart parts
+type apiCallBody struct {
+	raw     []byte
+	options []hyperfleetapi.RequestOption
+	// description is the body for logs, with sensitive values redacted
+	description string
+	// fingerprint identifies the body content for skip_if_unchanged
+	fingerprint []byte
+}
+
+// renderAPICallBody renders the body of apiCall according to its body_type.
+// Form field values and multipart part contents are Go templates over params.
+func renderAPICallBody(apiCall *configloader.APICall, params map[string]interface{}) (*apiCallBody, error) {
+	switch apiCall.BodyType {
+	case configloader.BodyTypeForm:
+		fields := make([]hyperfleetapi.FormField, 0, len(apiCall.FormFields))
+		for i, field := range apiCall.FormFields {
+			value, err := renderTemplateAt(fmt.Sprintf("form_fields[%d].value", i), field.Value, params)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render form field '%s' template: %w", field.Name, err)
+			}
+			fields = append(fields, hyperfleetapi.FormField{Name: field.Name, Value: value, Sensitive: field.Sensitive})
+		}
+		encoded, _ := hyperfleetapi.EncodeForm(fields)
+		return &apiCallBody{
+			options:     []hyperfleetapi.RequestOption{hyperfleetapi.WithFormFields(fields)},
+			description: hyperfleetapi.DescribeFormFields(fields),
+			fingerprint: encoded,
+		}, nil
+	case configloader.BodyTypeMultipart:
+		parts := make([]hyperfleetapi.MultipartPart, 0, len(apiCall.Parts))
+		for i, part := range apiCall.Parts {
+			content, err := renderTemplateBytes(part.Content, params)
+			if err = atPathf(err, "parts[%d].content", i); err != nil {
+				return nil, fmt.Errorf("failed to render multipart part '%s' template: %w", part.Name, err)
+			}
+			parts = append(parts, hyperfleetapi.MultipartPart{
+				Name:        part.Name,
+				Filename:    part.Filename,
+				ContentType: part.ContentType,
+				Content:     content,
+				Sensitive:   part.Sensitive,
+			})
+		}
+		// The encoded body has a random boundary, so the parts themselves are fingerprinted
+		fingerprint, err := json.Marshal(parts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal multipart parts: %w", err)
+		}
+		return &apiCallBody{
+			options:     []hyperfleetapi.RequestOption{hyperfleetapi.WithMultipartParts(parts)},
+			description: hyperfleetapi.DescribeMultipartParts(parts),
+			fingerprint: fingerprint,
+		}, nil
+	default:
+		if apiCall.BodyFrom != "" || len(apiCall.BodyMerge) > 0 {
+			raw, err := payloadBody(apiCall, params)
+			if err != nil {
+				return nil, err
+			}
+			return &apiCallBody{
+				raw:         raw,
+				options:     []hyperfleetapi.RequestOption{hyperfleetapi.WithJSONBody(raw)},
+				description: string(raw),
+				fingerprint: raw,
+			}, nil
+		}
+		raw := []byte(apiCall.Body)
+		if apiCall.Body != "" {
+			var err error
+			raw, _, err = renderBody(apiCall.Body, params)
+			if err = atPath("body", err); err != nil {
+				return nil, fmt.Errorf("failed to render body template: %w", err)
+			}
+		}
+		return &apiCallBody{raw: raw, description: string(raw), fingerprint: raw}, nil
+	}
+}
+
+// payloadBody returns the JSON body of the post payloads of body_from or
+// body_merge. The payload of body_from is sent as built; those of body_merge are
+// deep merged in order, a later payload winning for a key set by several.
+func payloadBody(apiCall *configloader.APICall, params map[string]interface{}) ([]byte, error) {
+	if apiCall.BodyFrom != "" {
+		return webhookBody(apiCall.BodyFrom, params)
+	}
+	merged := map[string]interface{}{}
+	for _, name := range apiCall.BodyMerge {
+		value := params[name]
+		if built, ok := value.(string); ok {
+			if err := json.Unmarshal([]byte(built), &value); err != nil {
+				return nil, fmt.Errorf("payload '%s' is not JSON: %w", name, err)
+			}
+		}
+		switch payload := value.(type) {
+		case nil:
+			return nil, fmt.Errorf("payload '%s' was not built", name)
+		case map[string]interface{}:
+			merged = deepMerge(merged, payload)
+		default:
+			return nil, fmt.Errorf("payload '%s' of body_merge is not an object: %T", name, payload)
+		}
+	}
+	return json.Marshal(merged)
+}
+
+// deepMerge returns dst with the entries of src set, merging the maps set in
+// both recursively. The maps of src are copied, never shared with dst.
+func deepMerge(dst, src map[string]interface{}) map[string]interface{} {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		if !srcIsMap {
+			dst[key] = value
+			continue
+		}
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if !dstIsMap {
+			dstMap = map[string]interface{}{}
+		}
+		dst[key] = deepMerge(dstMap, srcMap)
+	}
+	return dst
+}
+
 // executionErrorToMap converts an ExecutionError struct to a map for CEL evaluation
 // Returns nil if the ExecutionError pointer is nil
 func executionErrorToMap(execErr *ExecutionError) interface{} {
@@ -469,6 +813,7 @@ func executionErrorToMap(execErr *ExecutionError) interface{} {
 		"phase":   execErr.Phase,
 		"step":    execErr.Step,
 		"message": execErr.Message,
+		"code":    string(execErr.Code),
 	}
 }
 
@@ -478,12 +823,17 @@ func adapterMetadataToMap(adapter *AdapterMetadata) map[string]interface{} {
 		return map[string]interface{}{}
 	}
 
-	return map[string]interface{}{
-		"executionStatus":  adapter.ExecutionStatus,
-		"resourcesSkipped": adapter.ResourcesSkipped,
-		"skipReason":       adapter.SkipReason,
-		"errorReason":      adapter.ErrorReason,
-		"errorMessage":     adapter.ErrorMessage,
-		"executionError":   executionErrorToMap(adapter.ExecutionError),
+	result := map[string]interface{}{
+		"executionStatus":       adapter.ExecutionStatus,
+		"resourcesSkipped":      adapter.ResourcesSkipped,
+		"skipReason":            adapter.SkipReason,
+		"errorReason":           adapter.ErrorReason,
+		"errorMessage":          adapter.ErrorMessage,
+		"executionError":        executionErrorToMap(adapter.executionError),
+		AdapterKeyCorrelationID: adapter.CorrelationID,
+		AdapterKeyWorkflow:      adapter.Workflow,
+		AdapterKeyDeferredUntil: adapter.DeferredUntil,
 	}
+	adapter.Runtime.addTo(result)
+	return result
 }
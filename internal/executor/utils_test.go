@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -12,9 +14,11 @@ import (
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
 	apierrors "github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/errors"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
 )
 
 func TestValidateAPIResponse_NilError_SuccessResponse(t *testing.T) {
@@ -552,6 +556,146 @@ func TestRenderTemplateBytes(t *testing.T) {
 	}
 }
 
+func TestRenderTemplate_EncodingFunctions(t *testing.T) {
+	params := map[string]interface{}{
+		"statusPayload": `{"adapter":"test-adapter","status":{"phase":"Ready","generation":3}}`,
+		"clusterYaml":   "spec:\n  region: us-east-1\n",
+		"labels":        map[string]interface{}{"app": "web"},
+		"text":          "line1\nline2",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{"nested field of a JSON param", `{{ (fromJson .statusPayload).status.phase }}`, "Ready"},
+		{"nested field of a YAML param", `{{ (fromYaml .clusterYaml).spec.region }}`, "us-east-1"},
+		{"toJson", `{{ toJson .labels }}`, `{"app":"web"}`},
+		{"toYaml without trailing newline", `{{ toYaml .labels }}`, "app: web"},
+		{"indent", `{{ indent 2 .text }}`, "  line1\n  line2"},
+		{"nindent", `key:{{ nindent 2 .text }}`, "key:\n  line1\n  line2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := renderTemplate(tt.template, params)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("built payload embedded in a ConfigMap", func(t *testing.T) {
+		configMap := map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "status"},
+			"data": map[string]interface{}{
+				"status.json": "{{ .statusPayload }}",
+				"status.yaml": "{{ .statusPayload | fromJson | toYaml }}",
+				"phase":       "{{ (fromJson .statusPayload).status.phase }}",
+			},
+		}
+		rendered, err := renderManifestTemplates(configMap, params)
+		require.NoError(t, err)
+		data, ok := rendered["data"].(map[string]interface{})
+		require.True(t, ok)
+		assert.JSONEq(t, params["statusPayload"].(string), data["status.json"].(string))
+		assert.Equal(t, "adapter: test-adapter\nstatus:\n  generation: 3\n  phase: Ready", data["status.yaml"])
+		assert.Equal(t, "Ready", data["phase"])
+	})
+
+	t.Run("built payload embedded in a YAML document", func(t *testing.T) {
+		document, err := renderTemplate(
+			"data:\n  status.yaml: |{{ .statusPayload | fromJson | toYaml | nindent 4 }}\n", params)
+		require.NoError(t, err)
+		var parsed struct {
+			Data map[string]string `json:"data"`
+		}
+		require.NoError(t, yaml.Unmarshal([]byte(document), &parsed))
+		assert.Equal(t, "adapter: test-adapter\nstatus:\n  generation: 3\n  phase: Ready\n", parsed.Data["status.yaml"])
+	})
+
+	t.Run("errors name the function and quote the input", func(t *testing.T) {
+		_, err := renderTemplate(`{{ fromJson .text }}`, params)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error calling fromJson")
+		assert.Contains(t, err.Error(), `invalid JSON "line1\nline2"`)
+
+		long := strings.Repeat("x", 100)
+		_, err = renderTemplate(`{{ fromYaml .long }}`, map[string]interface{}{"long": "key: [" + long})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error calling fromYaml: invalid YAML")
+		assert.Contains(t, err.Error(), `"...`, "long input is truncated")
+		assert.NotContains(t, err.Error(), long)
+	})
+}
+
+// Concurrent renders of the same template share one cached parsed template; run with -race
+func TestRenderTemplate_Concurrent(t *testing.T) {
+	const text = `{{ .clusterId | lower }}-{{ .index }}`
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				got, err := renderTemplate(text, map[string]interface{}{"clusterId": "CLS", "index": i})
+				if err != nil {
+					errs <- err
+					return
+				}
+				if want := fmt.Sprintf("cls-%d", i); got != want {
+					errs <- fmt.Errorf("got %q, want %q", got, want)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+// BenchmarkRenderManifestTemplates renders a manifest with 50 templated fields,
+// parsing every template as before the template cache, and from the cache
+func BenchmarkRenderManifestTemplates(b *testing.B) {
+	data := map[string]interface{}{}
+	for i := 0; i < 50; i++ {
+		data[fmt.Sprintf("field%d", i)] = fmt.Sprintf(`{{ .clusterId }}-{{ .region | upper }}-%d`, i)
+	}
+	manifest := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "{{ .clusterId }}-config", "namespace": "{{ .clusterId }}"},
+		"data":       data,
+	}
+	params := map[string]interface{}{"clusterId": "cluster-123", "region": "us-east-1"}
+
+	for _, bm := range []struct {
+		cache *utils.TemplateCache
+		name  string
+	}{
+		{name: "uncached", cache: utils.NewTemplateCache(0)},
+		{name: "cached", cache: utils.NewTemplateCache(utils.DefaultTemplateCacheSize)},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			defaultCache := templateCache
+			templateCache = bm.cache
+			b.Cleanup(func() { templateCache = defaultCache })
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := renderManifestTemplates(manifest, params); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 // TestExecutionErrorToMap tests conversion of ExecutionError to map
 func TestExecutionErrorToMap(t *testing.T) {
 	tests := []struct {
@@ -570,11 +714,13 @@ func TestExecutionErrorToMap(t *testing.T) {
 				Phase:   "preconditions",
 				Step:    "check-cluster",
 				Message: "Cluster not found",
+				Code:    ErrorCodeAPIUnexpectedStatus,
 			},
 			expected: map[string]interface{}{
 				"phase":   "preconditions",
 				"step":    "check-cluster",
 				"message": "Cluster not found",
+				"code":    "APIUnexpectedStatus",
 			},
 		},
 		{
@@ -588,6 +734,7 @@ func TestExecutionErrorToMap(t *testing.T) {
 				"phase":   "",
 				"step":    "",
 				"message": "",
+				"code":    "",
 			},
 		},
 	}
@@ -606,6 +753,7 @@ func TestExecutionErrorToMap(t *testing.T) {
 			assert.Equal(t, expectedMap["phase"], resultMap["phase"])
 			assert.Equal(t, expectedMap["step"], resultMap["step"])
 			assert.Equal(t, expectedMap["message"], resultMap["message"])
+			assert.Equal(t, expectedMap["code"], resultMap["code"])
 		})
 	}
 }
@@ -630,7 +778,7 @@ func TestAdapterMetadataToMap(t *testing.T) {
 				SkipReason:       "",
 				ErrorReason:      "",
 				ErrorMessage:     "",
-				ExecutionError:   nil,
+				executionError:   nil,
 			},
 			expected: map[string]interface{}{
 				"executionStatus":  "success",
@@ -649,7 +797,7 @@ func TestAdapterMetadataToMap(t *testing.T) {
 				SkipReason:       "Precondition 'check-status' not met",
 				ErrorReason:      "",
 				ErrorMessage:     "",
-				ExecutionError:   nil,
+				executionError:   nil,
 			},
 			expected: map[string]interface{}{
 				"executionStatus":  "success",
@@ -668,10 +816,11 @@ func TestAdapterMetadataToMap(t *testing.T) {
 				SkipReason:       "",
 				ErrorReason:      "APIError",
 				ErrorMessage:     "API returned 500",
-				ExecutionError: &ExecutionError{
+				executionError: &ExecutionError{
 					Phase:   "preconditions",
 					Step:    "fetch-cluster",
 					Message: "Connection refused",
+					Code:    ErrorCodeAPICallFailed,
 				},
 			},
 			expected: map[string]interface{}{
@@ -684,6 +833,7 @@ func TestAdapterMetadataToMap(t *testing.T) {
 					"phase":   "preconditions",
 					"step":    "fetch-cluster",
 					"message": "Connection refused",
+					"code":    "APICallFailed",
 				},
 			},
 		},
@@ -707,6 +857,7 @@ func TestAdapterMetadataToMap(t *testing.T) {
 				assert.Equal(t, expectedErr["phase"], resultErr["phase"])
 				assert.Equal(t, expectedErr["step"], resultErr["step"])
 				assert.Equal(t, expectedErr["message"], resultErr["message"])
+				assert.Equal(t, expectedErr["code"], resultErr["code"])
 			}
 		})
 	}
@@ -773,7 +924,10 @@ func TestExecuteLogAction(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			log := logger.NewTestLogger()
-			execCtx := &ExecutionContext{Params: tt.params}
+			execCtx := NewExecutionContext(context.Background(), map[string]interface{}{}, nil)
+			for name, value := range tt.params {
+				execCtx.SetParam(name, value)
+			}
 
 			// This should not panic
 			ExecuteLogAction(context.Background(), tt.logAction, execCtx, log)
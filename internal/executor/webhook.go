@@ -0,0 +1,98 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/webhook"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// WebhookClient delivers the payloads of webhook post actions
+type WebhookClient interface {
+	Deliver(ctx context.Context, req *webhook.Request) (*webhook.Delivery, error)
+}
+
+// executeWebhook renders a webhook action and delivers its payload, recording
+// the delivery in the result
+func (pae *PostActionExecutor) executeWebhook(
+	ctx context.Context,
+	log logger.Logger,
+	hook *configloader.WebhookAction,
+	execCtx *ExecutionContext,
+	result *PostActionResult,
+) error {
+	params := execCtx.templateParams()
+	target, err := renderTemplateAt("webhook.url", hook.URL, params)
+	if err != nil {
+		return NewExecutorError(PhasePostActions, ErrorCodeTemplateError, result.Name,
+			"failed to render webhook url", err)
+	}
+	headers := make(map[string]string, len(hook.Headers))
+	for i, header := range hook.Headers {
+		path := fmt.Sprintf("webhook.headers[%d].value", i)
+		if headers[header.Name], err = renderTemplateAt(path, header.Value, params); err != nil {
+			return NewExecutorError(PhasePostActions, ErrorCodeTemplateError, result.Name,
+				fmt.Sprintf("failed to render webhook header '%s'", header.Name), err)
+		}
+	}
+	body, err := webhookBody(hook.Payload, params)
+	if err != nil {
+		return NewExecutorError(PhasePostActions, ErrorCodePayloadBuildFailed, result.Name,
+			"failed to encode webhook payload", err)
+	}
+	req := &webhook.Request{
+		URL:           target,
+		Headers:       headers,
+		Body:          body,
+		Timeout:       hook.Timeout,
+		RetryAttempts: hook.RetryAttempts,
+	}
+	if hook.Signing != nil {
+		secret, err := renderTemplateAt("webhook.signing.secret", hook.Signing.Secret, params)
+		if err != nil {
+			return NewExecutorError(PhasePostActions, ErrorCodeTemplateError, result.Name,
+				"failed to render webhook signing secret", err)
+		}
+		req.Secret = []byte(secret)
+		req.SignatureHeader = hook.Signing.Header
+	}
+
+	// Webhook URLs often carry their credentials, e.g. Slack's, so only the host is logged
+	log.Debugf(ctx, "Delivering payload %s to webhook %s", hook.Payload, webhookHost(target))
+	delivery, err := pae.webhookClient.Deliver(ctx, req)
+	result.Webhook = delivery
+	if delivery != nil {
+		result.HTTPStatus = delivery.StatusCode
+	}
+	if err != nil {
+		return NewExecutorError(PhasePostActions, ErrorCodeWebhookFailed, result.Name,
+			fmt.Sprintf("failed to deliver webhook to %s", webhookHost(target)), err)
+	}
+	return nil
+}
+
+// webhookBody returns the JSON of the post payload named payload: a structured
+// payload is marshaled, a payload built as a JSON string is sent as is
+func webhookBody(payload string, params map[string]interface{}) ([]byte, error) {
+	switch value := params[payload].(type) {
+	case string:
+		return []byte(value), nil
+	case nil:
+		return nil, fmt.Errorf("payload '%s' was not built", payload)
+	default:
+		return json.Marshal(value)
+	}
+}
+
+// webhookHost returns the host of a webhook URL for logs and errors
+func webhookHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "<invalid url>"
+	}
+	return parsed.Host
+}
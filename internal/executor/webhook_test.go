@@ -0,0 +1,126 @@
+package executor
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookConfig is a config delivering the notification payload to url, signed
+// with the WEBHOOK_SECRET env var in the X-Signature header
+func webhookConfig(url string, structured bool) *configloader.Config {
+	return &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		Clients: configloader.ClientsConfig{
+			Webhook: configloader.WebhookClientConfig{RetryAttempts: 2, BaseDelay: time.Millisecond},
+		},
+		Params: []configloader.Parameter{
+			{Name: "clusterId", Source: "event.id", Required: true},
+			{Name: "webhookSecret", Source: "env.WEBHOOK_SECRET", Required: true, Sensitive: true},
+		},
+		Post: &configloader.PostConfig{
+			Payloads: []configloader.Payload{{
+				Name:       "notification",
+				Structured: structured,
+				Build:      map[string]interface{}{"text": "Cluster {{ .clusterId }} reconciled"},
+			}},
+			PostActions: []configloader.PostAction{{
+				ActionBase: configloader.ActionBase{Name: "notify"},
+				Webhook: &configloader.WebhookAction{
+					URL:     url + "/hooks/{{ .clusterId }}",
+					Payload: "notification",
+					Headers: []configloader.Header{{Name: "X-Cluster", Value: "{{ .clusterId }}"}},
+					Signing: &configloader.WebhookSigning{Secret: "{{ .webhookSecret }}", Header: "X-Signature"},
+				},
+			}},
+		},
+	}
+}
+
+func TestWebhookPostAction(t *testing.T) {
+	t.Setenv("WEBHOOK_SECRET", "shared-secret")
+	tests := []struct {
+		name       string
+		statuses   []int
+		structured bool
+		wantCode   ErrorCode
+		wantStatus int
+		wantCalls  int
+	}{
+		{name: "payload built as JSON string", statuses: []int{http.StatusOK}, wantStatus: http.StatusOK, wantCalls: 1},
+		{name: "structured payload", structured: true, statuses: []int{http.StatusOK},
+			wantStatus: http.StatusOK, wantCalls: 1},
+		{name: "retried server error", statuses: []int{http.StatusBadGateway, http.StatusAccepted},
+			wantStatus: http.StatusAccepted, wantCalls: 2},
+		{name: "failed delivery", statuses: []int{http.StatusServiceUnavailable},
+			wantCode: ErrorCodeWebhookFailed, wantStatus: http.StatusServiceUnavailable, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var requests []*http.Request
+			var bodies [][]byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				mu.Lock()
+				defer mu.Unlock()
+				requests = append(requests, r)
+				bodies = append(bodies, body)
+				w.WriteHeader(tt.statuses[min(len(requests), len(tt.statuses))-1])
+			}))
+			defer server.Close()
+
+			exec, err := NewBuilder().
+				WithConfig(webhookConfig(server.URL, tt.structured)).
+				WithAPIClient(newMockAPIClient()).
+				WithTransportClient(k8sclient.NewMockK8sClient()).
+				WithLogger(logger.NewTestLogger()).
+				Build()
+			require.NoError(t, err)
+
+			result := exec.Execute(context.Background(), map[string]interface{}{"id": "cluster-1"})
+			require.Len(t, result.PostActionResults, 1)
+			delivery := result.PostActionResults[0].Webhook
+			require.NotNil(t, delivery)
+			assert.Equal(t, tt.wantStatus, delivery.StatusCode)
+			assert.Equal(t, tt.wantCalls, delivery.Attempts)
+			assert.Equal(t, tt.wantCode, ErrorCodeOf(result.Errors[PhasePostActions]))
+			if tt.wantCode == "" {
+				assert.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+			}
+
+			require.Len(t, requests, tt.wantCalls)
+			for i, r := range requests {
+				assert.Equal(t, "/hooks/cluster-1", r.URL.Path)
+				assert.Equal(t, "cluster-1", r.Header.Get("X-Cluster"))
+				var payload map[string]interface{}
+				require.NoError(t, json.Unmarshal(bodies[i], &payload))
+				assert.Equal(t, "Cluster cluster-1 reconciled", payload["text"])
+
+				// The receiver verifies the signature over the exact bytes it got
+				mac := hmac.New(sha256.New, []byte("shared-secret"))
+				mac.Write(bodies[i])
+				assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get("X-Signature"))
+			}
+
+			data, err := json.Marshal(result)
+			require.NoError(t, err)
+			assert.Contains(t, string(data), `"webhook":{"status_code"`)
+		})
+	}
+}
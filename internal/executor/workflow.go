@@ -0,0 +1,89 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/criteria"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// selectWorkflow returns the workflow of the event: the only workflow whose
+// matcher selects it, or the default workflow when none does. An event selected
+// by several workflows is an error, as is a matcher expression that fails.
+func (e *Executor) selectWorkflow(
+	ctx context.Context, eventType string, execCtx *ExecutionContext,
+) (*configloader.Workflow, error) {
+	phaseCtx, phaseSpan := e.startPhaseSpan(ctx, PhaseWorkflowSelection)
+	selected, err := e.matchWorkflows(phaseCtx, eventType, execCtx)
+	if err != nil {
+		e.log.Errorf(logger.WithErrorField(phaseCtx, err), "Phase %s: FAILED", PhaseWorkflowSelection)
+		endSpan(phaseSpan, string(StatusFailed), err)
+		return nil, err
+	}
+
+	var workflow *configloader.Workflow
+	switch len(selected) {
+	case 0:
+		workflow = e.config.Config.DefaultWorkflow()
+	case 1:
+		workflow = selected[0]
+	default:
+		names := make([]string, len(selected))
+		for i, w := range selected {
+			names[i] = w.Name
+		}
+		err = NewExecutorError(PhaseWorkflowSelection, ErrorCodeWorkflowAmbiguous, "match",
+			fmt.Sprintf("event matches workflows %s", strings.Join(names, ", ")), nil)
+		e.log.Errorf(logger.WithErrorField(phaseCtx, err), "Phase %s: FAILED", PhaseWorkflowSelection)
+		endSpan(phaseSpan, string(StatusFailed), err)
+		return nil, err
+	}
+	e.log.Infof(phaseCtx, "Phase %s: SUCCESS - %s", PhaseWorkflowSelection, workflow.Name)
+	endSpan(phaseSpan, string(StatusSuccess), nil)
+	return workflow, nil
+}
+
+// matchWorkflows returns the workflows whose matcher selects the event
+func (e *Executor) matchWorkflows(
+	ctx context.Context, eventType string, execCtx *ExecutionContext,
+) ([]*configloader.Workflow, error) {
+	var evaluator *criteria.Evaluator
+	var selected []*configloader.Workflow
+	for i := range e.config.Config.Workflows {
+		workflow := &e.config.Config.Workflows[i]
+		match := workflow.Match
+		if match == nil {
+			continue
+		}
+		if len(match.EventTypes) > 0 && !slices.Contains(match.EventTypes, eventType) {
+			continue
+		}
+		if match.Expression != "" {
+			if evaluator == nil {
+				evalCtx := criteria.NewEvaluationContext()
+				evalCtx.SetVariablesFromMap(execCtx.GetCELVariables())
+				evalCtx.SetLookup(execCtx.lookupFunc())
+				var err error
+				evaluator, err = criteria.NewEvaluator(ctx, evalCtx, e.log)
+				if err != nil {
+					return nil, NewExecutorError(PhaseWorkflowSelection, ErrorCodeInternal, workflow.Name,
+						"failed to create evaluator", err)
+				}
+			}
+			result, err := evaluator.EvaluateCEL(strings.TrimSpace(match.Expression))
+			if err != nil {
+				return nil, NewExecutorError(PhaseWorkflowSelection, celErrorCode(err), workflow.Name,
+					"match expression evaluation failed", err)
+			}
+			if !result.Matched {
+				continue
+			}
+		}
+		selected = append(selected, workflow)
+	}
+	return selected, nil
+}
@@ -0,0 +1,133 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/eventtest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// workflowPrecondition is met when the workflow named name was selected
+func workflowPrecondition(name string) []configloader.Precondition {
+	return []configloader.Precondition{{
+		ActionBase: configloader.ActionBase{Name: name},
+		Expression: `adapter.workflow == "` + name + `"`,
+	}}
+}
+
+func TestSelectWorkflow(t *testing.T) {
+	deleted := []string{"cluster.deleted"}
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		Params: []configloader.Parameter{
+			{Name: "clusterId", Source: "event.id", Required: true},
+		},
+		Preconditions: workflowPrecondition(configloader.DefaultWorkflowName),
+		Workflows: []configloader.Workflow{
+			{
+				Name:          "teardown",
+				Match:         &configloader.WorkflowMatch{EventTypes: deleted},
+				Preconditions: workflowPrecondition("teardown"),
+			},
+			{
+				Name:          "orphaned",
+				Match:         &configloader.WorkflowMatch{EventTypes: deleted, Expression: `clusterId == "orphan"`},
+				Preconditions: workflowPrecondition("orphaned"),
+			},
+			{
+				Name:          "paused",
+				Match:         &configloader.WorkflowMatch{Expression: `clusterId.startsWith("paused-")`},
+				Preconditions: workflowPrecondition("paused"),
+			},
+		},
+	}
+	registry := prometheus.NewRegistry()
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		WithMetricsRecorder(metrics.NewRecorder("test-adapter", "v0.1.0", registry)).
+		Build()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		eventType    string
+		clusterID    string
+		wantWorkflow string
+		wantCode     ErrorCode
+	}{
+		{name: "event type", eventType: "cluster.deleted", clusterID: "cluster-1", wantWorkflow: "teardown"},
+		{name: "expression", eventType: "cluster.updated", clusterID: "paused-1", wantWorkflow: "paused"},
+		{name: "default", eventType: "cluster.updated", clusterID: "cluster-1",
+			wantWorkflow: configloader.DefaultWorkflowName},
+		{name: "ambiguous", eventType: "cluster.deleted", clusterID: "orphan", wantCode: ErrorCodeWorkflowAmbiguous},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evt := eventtest.NewEvent().
+				WithType(tt.eventType).
+				WithDataJSON(map[string]interface{}{"id": tt.clusterID}).
+				Build()
+			result := exec.ExecuteEvent(context.Background(), evt)
+			if tt.wantCode != "" {
+				require.Equal(t, StatusFailed, result.Status)
+				assert.Equal(t, PhaseWorkflowSelection, result.CurrentPhase)
+				assert.Equal(t, tt.wantCode, ErrorCodeOf(result.Errors[PhaseWorkflowSelection]))
+				assert.Contains(t, result.Errors[PhaseWorkflowSelection].Error(), "teardown, orphaned")
+				assert.Empty(t, result.Workflow)
+				assert.Empty(t, result.PreconditionResults, "no phase of any workflow runs")
+				return
+			}
+			require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+			assert.Equal(t, tt.wantWorkflow, result.Workflow)
+			require.Len(t, result.PreconditionResults, 1, "only the phases of the selected workflow run")
+			assert.Equal(t, tt.wantWorkflow, result.PreconditionResults[0].Name)
+			assert.True(t, result.PreconditionResults[0].Matched, "adapter.workflow is the selected workflow")
+			assert.Contains(t, result.PhaseDurations, PhaseWorkflowSelection)
+		})
+	}
+
+	handler := exec.CreateHandler()
+	evt := eventtest.NewEvent().
+		WithType("cluster.deleted").
+		WithDataJSON(map[string]interface{}{"id": "cluster-1"}).
+		Build()
+	require.NoError(t, handler(context.Background(), evt))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	assert.Equal(t, float64(1),
+		getCounterValue(t, families, "hyperfleet_adapter_workflow_executions_total", "workflow", "teardown"))
+}
+
+func TestSelectWorkflow_ImplicitDefault(t *testing.T) {
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "v0.1.0"},
+		Params: []configloader.Parameter{
+			{Name: "clusterId", Source: "event.id", Required: true},
+		},
+		Preconditions: workflowPrecondition(configloader.DefaultWorkflowName),
+	}
+	exec, err := NewBuilder().
+		WithConfig(config).
+		WithAPIClient(newMockAPIClient()).
+		WithTransportClient(k8sclient.NewMockK8sClient()).
+		WithLogger(logger.NewTestLogger()).
+		Build()
+	require.NoError(t, err)
+
+	result := exec.Execute(context.Background(), map[string]interface{}{"id": "cluster-1"})
+	require.Equal(t, StatusSuccess, result.Status, "errors: %v", result.Errors)
+	assert.Equal(t, configloader.DefaultWorkflowName, result.Workflow)
+	assert.NotContains(t, result.PhaseDurations, PhaseWorkflowSelection, "configs without workflows select nothing")
+	require.Len(t, result.PreconditionResults, 1)
+	assert.True(t, result.PreconditionResults[0].Matched)
+}
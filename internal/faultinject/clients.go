@@ -0,0 +1,285 @@
+package faultinject
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/manifest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// HeaderFaultRule names the response header carrying the ID of the rule that
+// answered an api request
+const HeaderFaultRule = "X-Hyperfleet-Fault-Rule"
+
+// Operations of k8s and transport calls, matched by Rule.Method
+const (
+	OperationApply    = "apply"
+	OperationGet      = "get"
+	OperationDiscover = "discover"
+	OperationCreate   = "create"
+	OperationUpdate   = "update"
+	OperationPatch    = "patch"
+	OperationDelete   = "delete"
+)
+
+// RoundTripper returns a round tripper injecting the api rules into the
+// requests sent through next (nil uses http.DefaultTransport). Installed in
+// the HyperFleet API client with hyperfleetapi.WithTransportWrapper, the injected
+// failures go through the client's retries and classification like real ones.
+func (i *Injector) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &roundTripper{injector: i, next: next}
+}
+
+type roundTripper struct {
+	injector *Injector
+	next     http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	f := t.injector.inject(ctx, TargetAPI, req.Method, hyperfleetapi.RedactURL(req.URL.String()))
+	if f == nil {
+		return t.next.RoundTrip(req)
+	}
+	if err := t.injector.delay(ctx, f); err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+	if !f.fail {
+		return t.next.RoundTrip(req)
+	}
+	// A round tripper must close the request body, even on errors
+	closeRequestBody(req)
+	if f.rule.Type == FailureError {
+		return nil, &InjectedError{RuleID: f.rule.ID}
+	}
+	body := fmt.Sprintf(`{"kind":"Error","reason":"fault injected by rule %s"}`, f.rule.ID)
+	return &http.Response{
+		StatusCode: f.rule.StatusCode,
+		Status:     fmt.Sprintf("%d %s", f.rule.StatusCode, http.StatusText(f.rule.StatusCode)),
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: http.Header{
+			"Content-Type":  []string{"application/json"},
+			HeaderFaultRule: []string{f.rule.ID},
+		},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close() //nolint:errcheck // the request is not sent
+	}
+}
+
+// WrapTransportClient returns tc with the transport rules injected into its
+// apply, get and discover calls. A Kubernetes client is wrapped into a
+// k8sclient.K8sClient that also injects the k8s rules into every call, so the
+// executor still patches objects through it.
+func (i *Injector) WrapTransportClient(tc transportclient.TransportClient) transportclient.TransportClient {
+	if k8sClient, ok := tc.(k8sclient.K8sClient); ok {
+		return &k8sClientFaults{
+			transportClientFaults: transportClientFaults{
+				injector: i, next: tc, targets: []Target{TargetK8s, TargetTransport},
+			},
+			next: k8sClient,
+		}
+	}
+	return &transportClientFaults{injector: i, next: tc, targets: []Target{TargetTransport}}
+}
+
+// transportClientFaults injects the rules of targets into a transport client
+type transportClientFaults struct {
+	injector *Injector
+	next     transportclient.TransportClient
+	targets  []Target
+}
+
+var _ transportclient.TransportClient = (*transportClientFaults)(nil)
+
+// before runs the rules of targets on a call and returns its injected error,
+// nil when the call proceeds
+func (t *transportClientFaults) before(
+	ctx context.Context, targets []Target, operation string, gvk schema.GroupVersionKind, namespace, name string,
+) error {
+	subject := fmt.Sprintf("%s/%s %s/%s", gvk.GroupVersion().String(), gvk.Kind, namespace, name)
+	for _, target := range targets {
+		f := t.injector.inject(ctx, target, operation, subject)
+		if f == nil {
+			continue
+		}
+		if err := t.injector.delay(ctx, f); err != nil {
+			return err
+		}
+		if f.fail {
+			return f.k8sError(operation, gvk, name)
+		}
+		return nil
+	}
+	return nil
+}
+
+// ApplyResource implements transportclient.TransportClient
+func (t *transportClientFaults) ApplyResource(
+	ctx context.Context,
+	manifestBytes []byte,
+	opts *transportclient.ApplyOptions,
+	target transportclient.TransportContext,
+) (*transportclient.ApplyResult, error) {
+	gvk, namespace, name := manifestIdentity(manifestBytes)
+	if err := t.before(ctx, t.targets, OperationApply, gvk, namespace, name); err != nil {
+		return nil, err
+	}
+	return t.next.ApplyResource(ctx, manifestBytes, opts, target)
+}
+
+// GetResource implements transportclient.TransportClient
+func (t *transportClientFaults) GetResource(
+	ctx context.Context,
+	gvk schema.GroupVersionKind,
+	namespace, name string,
+	target transportclient.TransportContext,
+) (*unstructured.Unstructured, error) {
+	if err := t.before(ctx, t.targets, OperationGet, gvk, namespace, name); err != nil {
+		return nil, err
+	}
+	return t.next.GetResource(ctx, gvk, namespace, name, target)
+}
+
+// DiscoverResources implements transportclient.TransportClient
+func (t *transportClientFaults) DiscoverResources(
+	ctx context.Context,
+	gvk schema.GroupVersionKind,
+	discovery manifest.Discovery,
+	target transportclient.TransportContext,
+) (*unstructured.UnstructuredList, error) {
+	err := t.before(ctx, t.targets, OperationDiscover, gvk, discovery.GetNamespace(), discovery.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return t.next.DiscoverResources(ctx, gvk, discovery, target)
+}
+
+// k8sClientFaults injects the k8s rules into the Kubernetes specific calls of
+// a k8sclient.K8sClient, and the k8s and transport rules into the others
+type k8sClientFaults struct {
+	transportClientFaults
+	next k8sclient.K8sClient
+}
+
+var _ k8sclient.K8sClient = (*k8sClientFaults)(nil)
+
+// k8sOnly are the targets of the Kubernetes specific calls
+var k8sOnly = []Target{TargetK8s}
+
+// ApplyManifest implements k8sclient.K8sClient
+func (k *k8sClientFaults) ApplyManifest(
+	ctx context.Context,
+	newManifest *unstructured.Unstructured,
+	existing *unstructured.Unstructured,
+	opts *k8sclient.ApplyOptions,
+) (*k8sclient.ApplyResult, error) {
+	err := k.before(ctx, k8sOnly, OperationApply,
+		newManifest.GroupVersionKind(), newManifest.GetNamespace(), newManifest.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return k.next.ApplyManifest(ctx, newManifest, existing, opts)
+}
+
+// CreateResource implements k8sclient.K8sClient
+func (k *k8sClientFaults) CreateResource(
+	ctx context.Context, obj *unstructured.Unstructured,
+) (*unstructured.Unstructured, error) {
+	err := k.before(ctx, k8sOnly, OperationCreate, obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return k.next.CreateResource(ctx, obj)
+}
+
+// UpdateResource implements k8sclient.K8sClient
+func (k *k8sClientFaults) UpdateResource(
+	ctx context.Context, obj *unstructured.Unstructured,
+) (*unstructured.Unstructured, error) {
+	err := k.before(ctx, k8sOnly, OperationUpdate, obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return k.next.UpdateResource(ctx, obj)
+}
+
+// DeleteResource implements k8sclient.K8sClient
+func (k *k8sClientFaults) DeleteResource(
+	ctx context.Context, gvk schema.GroupVersionKind, namespace, name string,
+) error {
+	if err := k.before(ctx, k8sOnly, OperationDelete, gvk, namespace, name); err != nil {
+		return err
+	}
+	return k.next.DeleteResource(ctx, gvk, namespace, name)
+}
+
+// PatchResource implements k8sclient.K8sClient
+func (k *k8sClientFaults) PatchResource(
+	ctx context.Context,
+	gvk schema.GroupVersionKind,
+	namespace, name string,
+	patchData []byte,
+	opts *k8sclient.PatchOptions,
+) (*unstructured.Unstructured, error) {
+	if err := k.before(ctx, k8sOnly, OperationPatch, gvk, namespace, name); err != nil {
+		return nil, err
+	}
+	return k.next.PatchResource(ctx, gvk, namespace, name, patchData, opts)
+}
+
+// k8sError returns the error of a k8s or transport call failed by f, shaped
+// like the error of the API server so the executor classifies it as a real one
+func (f *fault) k8sError(verb string, gvk schema.GroupVersionKind, name string) error {
+	message := fmt.Sprintf("fault injected by rule %s", f.rule.ID)
+	resource := schema.GroupResource{Group: gvk.Group, Resource: strings.ToLower(gvk.Kind)}
+	switch f.rule.Type {
+	case FailureError:
+		return &InjectedError{RuleID: f.rule.ID}
+	case FailureConflict:
+		return apierrors.NewConflict(resource, name, errors.New(message))
+	default:
+		return apierrors.NewGenericServerResponse(f.rule.StatusCode, verb, resource, name, message, 0, false)
+	}
+}
+
+// manifestIdentity returns the GVK, namespace and name of a rendered manifest,
+// zero values for a manifest that does not parse
+func manifestIdentity(manifestBytes []byte) (gvk schema.GroupVersionKind, namespace, name string) {
+	var object struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+	}
+	if err := yaml.Unmarshal(manifestBytes, &object); err != nil {
+		return gvk, "", ""
+	}
+	return schema.FromAPIVersionAndKind(object.APIVersion, object.Kind), object.Metadata.Namespace, object.Metadata.Name
+}
@@ -0,0 +1,184 @@
+package faultinject
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/executor"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/eventtest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// faultsTestConfig gets the cluster, applies a ConfigMap and reports its status
+func faultsTestConfig() *configloader.Config {
+	return &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "test-adapter", Version: "1.0.0"},
+		Params:  []configloader.Parameter{{Name: "clusterId", Source: "event.id"}},
+		Preconditions: []configloader.Precondition{{ActionBase: configloader.ActionBase{
+			Name:    "clusterStatus",
+			APICall: &configloader.APICall{Method: "GET", URL: "/clusters/{{ .clusterId }}"},
+		}}},
+		Resources: []configloader.Resource{{
+			Name: "cm",
+			Manifest: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "cm-{{ .clusterId }}", "namespace": "default"},
+			},
+			Discovery: &configloader.DiscoveryConfig{Namespace: "default", ByName: "cm-{{ .clusterId }}"},
+		}},
+		Post: &configloader.PostConfig{
+			PostActions: []configloader.PostAction{{ActionBase: configloader.ActionBase{
+				Name: "report",
+				APICall: &configloader.APICall{
+					Method: "POST", URL: "/clusters/{{ .clusterId }}/statuses", Body: `{"ok":true}`,
+				},
+			}}},
+		},
+	}
+}
+
+// faultsTestRun executes an event with the real HyperFleet API client against
+// a test server and the mock Kubernetes client, both with injector installed.
+// It returns the result and the number of requests the server received.
+func faultsTestRun(t *testing.T, injector *Injector) (*executor.ExecutionResult, int) {
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"cluster-1"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	log := logger.NewTestLogger()
+	apiClient, err := hyperfleetapi.NewClient(log,
+		hyperfleetapi.WithBaseURL(server.URL),
+		hyperfleetapi.WithRetryAttempts(3),
+		hyperfleetapi.WithBaseDelay(time.Millisecond),
+		hyperfleetapi.WithMaxDelay(time.Millisecond),
+		hyperfleetapi.WithTransport(injector.RoundTripper(nil)),
+	)
+	require.NoError(t, err)
+	exec, err := executor.NewBuilder().
+		WithConfig(faultsTestConfig()).
+		WithAPIClient(apiClient).
+		WithTransportClient(injector.WrapTransportClient(k8sclient.NewMockK8sClient())).
+		WithLogger(log).
+		Build()
+	require.NoError(t, err)
+
+	evt := eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build()
+	return exec.ExecuteEvent(context.Background(), evt), int(received.Load())
+}
+
+func TestFaults_APIStatusIsRetried(t *testing.T) {
+	injector := newTestInjector(nil, nil)
+	_, err := injector.Add(context.Background(), Rule{
+		Target: TargetAPI, Method: "GET", Match: "/clusters/cluster-1$", Type: FailureStatus, StatusCode: 503, Count: 2,
+	})
+	require.NoError(t, err)
+
+	result, received := faultsTestRun(t, injector)
+
+	require.Equal(t, executor.StatusSuccess, result.Status, "the client retries injected 503s like real ones")
+	require.Len(t, result.PreconditionResults, 1)
+	assert.Equal(t, 3, result.PreconditionResults[0].APICall.Attempts)
+	assert.Equal(t, 2, received, "the injected attempts never reached the server")
+	assert.Empty(t, injector.Report(), "the rule is removed after its count")
+}
+
+func TestFaults_ClassifiedLikeRealFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      Rule
+		wantPhase executor.ExecutionPhase
+		wantCode  executor.ErrorCode
+	}{
+		{
+			name:      "api status on every attempt",
+			rule:      Rule{Target: TargetAPI, Method: "GET", Type: FailureStatus, StatusCode: 500},
+			wantPhase: executor.PhasePreconditions,
+			wantCode:  executor.ErrorCodeAPIUnexpectedStatus,
+		},
+		{
+			name:      "api connection error",
+			rule:      Rule{Target: TargetAPI, Type: FailureError},
+			wantPhase: executor.PhasePreconditions,
+			wantCode:  executor.ErrorCodeAPICallFailed,
+		},
+		{
+			name:      "api status of the status report",
+			rule:      Rule{Target: TargetAPI, Method: "POST", Match: "/statuses$", Type: FailureStatus, StatusCode: 400},
+			wantPhase: executor.PhasePostActions,
+			wantCode:  executor.ErrorCodeAPIUnexpectedStatus,
+		},
+		{
+			name:      "k8s conflict on apply",
+			rule:      Rule{Target: TargetK8s, Method: "apply", Match: "^v1/ConfigMap default/cm-", Type: FailureConflict},
+			wantPhase: executor.PhaseResources,
+			wantCode:  executor.ErrorCodeApplyConflict,
+		},
+		{
+			name:      "transport timeout",
+			rule:      Rule{Target: TargetTransport, Method: "apply", Type: FailureStatus, StatusCode: 504},
+			wantPhase: executor.PhaseResources,
+			wantCode:  executor.ErrorCodeTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			injector := newTestInjector(nil, nil)
+			_, err := injector.Add(context.Background(), tt.rule)
+			require.NoError(t, err)
+
+			result, _ := faultsTestRun(t, injector)
+
+			require.Equal(t, executor.StatusFailed, result.Status)
+			require.Contains(t, result.Errors, tt.wantPhase)
+			assert.Equal(t, tt.wantCode, executor.ErrorCodeOf(result.Errors[tt.wantPhase]))
+		})
+	}
+}
+
+func TestFaults_LatencyLetsTheCallProceed(t *testing.T) {
+	injector := newTestInjector(nil, nil)
+	_, err := injector.Add(context.Background(), Rule{Target: TargetTransport, Type: FailureLatency, Latency: "20ms"})
+	require.NoError(t, err)
+
+	result, _ := faultsTestRun(t, injector)
+
+	require.Equal(t, executor.StatusSuccess, result.Status)
+	require.Len(t, result.ResourceResults, 1)
+	assert.GreaterOrEqual(t, result.ResourceResults[0].Duration, 20*time.Millisecond)
+}
+
+func TestWrapTransportClient_K8sCalls(t *testing.T) {
+	ctx := context.Background()
+	injector := newTestInjector(nil, nil)
+	_, err := injector.Add(ctx, Rule{Target: TargetK8s, Method: "patch", Type: FailureConflict, Count: 1})
+	require.NoError(t, err)
+	_, err = injector.Add(ctx, Rule{Target: TargetTransport, Method: "patch", Type: FailureError})
+	require.NoError(t, err)
+
+	wrapped, ok := injector.WrapTransportClient(k8sclient.NewMockK8sClient()).(k8sclient.K8sClient)
+	require.True(t, ok, "a wrapped Kubernetes client still patches objects")
+	gvk := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+
+	_, err = wrapped.PatchResource(ctx, gvk, "default", "cm-1", []byte(`{}`), nil)
+	assert.True(t, apierrors.IsConflict(err), "got %v", err)
+	_, err = wrapped.PatchResource(ctx, gvk, "default", "cm-1", []byte(`{}`), nil)
+	var injected *InjectedError
+	assert.False(t, errors.As(err, &injected), "transport rules skip the Kubernetes specific calls")
+}
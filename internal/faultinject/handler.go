@@ -0,0 +1,64 @@
+package faultinject
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// maxRuleBytes bounds the body of a posted rule
+const maxRuleBytes = 16 << 10
+
+// RulesResponse is the response of GET and DELETE /admin/faults
+type RulesResponse struct {
+	// Rules are the active rules in the order they were added
+	Rules []Rule `json:"rules"`
+}
+
+// Handler returns the admin HTTP handler of the rules:
+//
+//	GET    lists the active rules
+//	POST   adds the Rule of the JSON body and responds with it, 201
+//	DELETE ?id=<id> removes a rule, without id every rule
+func (i *Injector) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		ctx := req.Context()
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			decoder := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxRuleBytes))
+			decoder.DisallowUnknownFields()
+			var spec Rule
+			if err := decoder.Decode(&spec); err != nil {
+				writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+				return
+			}
+			added, err := i.Add(ctx, spec)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(added) //nolint:errcheck
+			return
+		case http.MethodDelete:
+			if id := req.URL.Query().Get("id"); id == "" {
+				i.Clear(ctx)
+			} else if !i.Remove(ctx, id) {
+				writeError(w, http.StatusNotFound, "no active rule "+id)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(RulesResponse{Rules: i.Report()}) //nolint:errcheck
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message}) //nolint:errcheck
+}
@@ -0,0 +1,340 @@
+// Package faultinject injects failures into the clients of a running adapter,
+// for chaos testing in staging: HyperFleet API errors, Kubernetes conflicts,
+// slow Maestro publishes. Rules are added at runtime through the handler of
+// /admin/faults and expire on their own. The serve command only wires the
+// package in builds with the faultinject build tag and with
+// --enable-fault-injection, so production images cannot inject failures.
+package faultinject
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// Target is the client a rule injects failures into
+type Target string
+
+const (
+	// TargetAPI injects into the HTTP requests of the HyperFleet API client,
+	// below its retries: an injected retryable status is retried like a real one
+	TargetAPI Target = "api"
+	// TargetK8s injects into every call of the Kubernetes transport client,
+	// including the create, update, patch and delete of k8s_patch and finalizers
+	TargetK8s Target = "k8s"
+	// TargetTransport injects into the apply, get and discover calls of the
+	// transport client, Kubernetes or Maestro
+	TargetTransport Target = "transport"
+)
+
+// FailureType is the failure a rule injects
+type FailureType string
+
+const (
+	// FailureStatus answers with StatusCode: an HTTP response for api rules, an
+	// API server status error for k8s and transport rules
+	FailureStatus FailureType = "status"
+	// FailureConflict answers with 409 Conflict
+	FailureConflict FailureType = "conflict"
+	// FailureError fails the call without a response, like a refused connection
+	FailureError FailureType = "error"
+	// FailureLatency only delays the call by Latency; the call then proceeds
+	FailureLatency FailureType = "latency"
+)
+
+// Limits of the rules
+const (
+	// DefaultTTL is how long a rule without ttl stays active
+	DefaultTTL = 10 * time.Minute
+	// MaxTTL is the longest ttl of a rule
+	MaxTTL = time.Hour
+	// DefaultStatusCode is the status of a status rule without status_code
+	DefaultStatusCode = 500
+	// MaxRules is the number of rules active at once
+	MaxRules = 50
+)
+
+// Rule is a failure injection rule, as posted to /admin/faults and reported in
+// /statusz
+type Rule struct {
+	// ID identifies the rule, assigned when it is added
+	ID string `json:"id"`
+	// Target is the client the rule injects into
+	Target Target `json:"target"`
+	// Match is a regular expression matched against the URL of api requests and
+	// against "<apiVersion>/<Kind> <namespace>/<name>" of k8s and transport
+	// calls, e.g. "v1/ConfigMap hyperfleet/cluster-1-config". Empty matches every call.
+	Match string `json:"match,omitempty"`
+	// Method is the HTTP method of api requests or the operation of k8s and
+	// transport calls (apply, get, discover, create, update, patch, delete) the
+	// rule applies to. Empty applies to all.
+	Method string `json:"method,omitempty"`
+	// Type is the injected failure
+	Type FailureType `json:"type"`
+	// StatusCode is the status of status rules, DefaultStatusCode when unset
+	StatusCode int `json:"status_code,omitempty"`
+	// Probability is the chance a matching call fails, in (0, 1]; 0 means 1
+	Probability float64 `json:"probability,omitempty"`
+	// After is the number of matching calls let through before the first
+	// injection: after 2 fails the 3rd matching call
+	After int `json:"after,omitempty"`
+	// Count is the number of injections after which the rule is removed, 0 for
+	// no limit
+	Count int `json:"count,omitempty"`
+	// Latency delays the call, e.g. "2s": latency rules then let it proceed,
+	// the others fail it
+	Latency string `json:"latency,omitempty"`
+	// TTL is how long the rule stays active, e.g. "5m", DefaultTTL when unset
+	TTL string `json:"ttl,omitempty"`
+	// ExpiresAt is when the rule is removed
+	ExpiresAt time.Time `json:"expires_at"`
+	// Matched is the number of calls the rule matched
+	Matched int `json:"matched"`
+	// Injected is the number of failures the rule injected
+	Injected int `json:"injected"`
+}
+
+// InjectedError is the error of a call failed by a rule
+type InjectedError struct {
+	// RuleID is the ID of the rule that failed the call
+	RuleID string
+}
+
+// Error implements the error interface
+func (e *InjectedError) Error() string {
+	return fmt.Sprintf("connection refused: fault injected by rule %s", e.RuleID)
+}
+
+// Config configures an Injector
+type Config struct {
+	// Clock expires the rules and delays the calls (nil uses the real clock)
+	Clock clock.Clock
+	// Random returns a number in [0, 1) drawn for rules with a probability
+	// below 1 (nil uses math/rand/v2)
+	Random func() float64
+}
+
+// rule is an active Rule with its compiled match and durations
+type rule struct {
+	Rule
+	match   *regexp.Regexp
+	latency time.Duration
+}
+
+// fault is the outcome of a call matched by a rule
+type fault struct {
+	rule    Rule
+	latency time.Duration
+	// fail is unset for latency rules, which let the call proceed
+	fail bool
+}
+
+// Injector holds the active rules and decides which calls fail. All methods
+// are safe for concurrent use.
+type Injector struct {
+	config Config
+	log    logger.Logger
+	rules  []*rule
+	nextID int
+	mu     sync.Mutex
+}
+
+// New creates an Injector without rules
+func New(config Config, log logger.Logger) *Injector {
+	config.Clock = clock.OrReal(config.Clock)
+	if config.Random == nil {
+		config.Random = rand.Float64
+	}
+	return &Injector{config: config, log: log}
+}
+
+// Add validates and activates rule, returning it with its ID and expiry
+func (i *Injector) Add(ctx context.Context, spec Rule) (Rule, error) {
+	r, ttl, err := compileRule(spec)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.pruneLocked(ctx)
+	if len(i.rules) >= MaxRules {
+		return Rule{}, fmt.Errorf("at most %d fault injection rules may be active", MaxRules)
+	}
+	i.nextID++
+	r.ID = strconv.Itoa(i.nextID)
+	r.ExpiresAt = i.config.Clock.Now().Add(ttl)
+	r.Matched, r.Injected = 0, 0
+	i.rules = append(i.rules, r)
+	i.log.Warnf(ctx, "Fault injection rule %s added: target=%s type=%s match=%q method=%q expires_at=%s",
+		r.ID, r.Target, r.Type, r.Match, r.Method, r.ExpiresAt.Format(time.RFC3339))
+	return r.Rule, nil
+}
+
+// Remove deactivates the rule id and reports whether it was active
+func (i *Injector) Remove(ctx context.Context, id string) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for n, r := range i.rules {
+		if r.ID == id {
+			i.rules = append(i.rules[:n:n], i.rules[n+1:]...)
+			i.log.Infof(ctx, "Fault injection rule %s removed", id)
+			return true
+		}
+	}
+	return false
+}
+
+// Clear deactivates every rule and returns how many were active
+func (i *Injector) Clear(ctx context.Context) int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	cleared := len(i.rules)
+	i.rules = nil
+	if cleared > 0 {
+		i.log.Infof(ctx, "Fault injection rules cleared: %d removed", cleared)
+	}
+	return cleared
+}
+
+// Report returns the active rules in the order they were added, for /statusz.
+// It is never nil, so an enabled injector without rules reports [].
+func (i *Injector) Report() []Rule {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.pruneLocked(context.Background())
+	report := make([]Rule, 0, len(i.rules))
+	for _, r := range i.rules {
+		report = append(report, r.Rule)
+	}
+	return report
+}
+
+// inject returns the fault of the first active rule of target matching the call
+// and firing, nil when the call proceeds untouched. Rules past their count are
+// removed.
+func (i *Injector) inject(ctx context.Context, target Target, method, subject string) *fault {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.pruneLocked(ctx)
+	for n, r := range i.rules {
+		if !r.matches(target, method, subject) {
+			continue
+		}
+		r.Matched++
+		if r.Matched <= r.After {
+			continue
+		}
+		if r.Probability > 0 && r.Probability < 1 && i.config.Random() >= r.Probability {
+			continue
+		}
+		r.Injected++
+		injected := &fault{rule: r.Rule, latency: r.latency, fail: r.Type != FailureLatency}
+		if r.Count > 0 && r.Injected >= r.Count {
+			i.rules = append(i.rules[:n:n], i.rules[n+1:]...)
+			i.log.Infof(ctx, "Fault injection rule %s removed after %d injection(s)", r.ID, r.Injected)
+		}
+		i.log.Warnf(ctx, "Fault injected by rule %s: %s %s %s", r.ID, target, method, subject)
+		return injected
+	}
+	return nil
+}
+
+// delay waits for the latency of f, failing when ctx is done first
+func (i *Injector) delay(ctx context.Context, f *fault) error {
+	if f.latency <= 0 {
+		return nil
+	}
+	return i.config.Clock.Sleep(ctx, f.latency)
+}
+
+// pruneLocked removes the expired rules
+func (i *Injector) pruneLocked(ctx context.Context) {
+	now := i.config.Clock.Now()
+	kept := i.rules[:0]
+	for _, r := range i.rules {
+		if now.Before(r.ExpiresAt) {
+			kept = append(kept, r)
+			continue
+		}
+		i.log.Infof(ctx, "Fault injection rule %s expired after %d injection(s)", r.ID, r.Injected)
+	}
+	clear(i.rules[len(kept):])
+	i.rules = kept
+}
+
+func (r *rule) matches(target Target, method, subject string) bool {
+	if r.Target != target {
+		return false
+	}
+	if r.Method != "" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	return r.match == nil || r.match.MatchString(subject)
+}
+
+// compileRule validates spec and returns the rule and its ttl
+func compileRule(spec Rule) (*rule, time.Duration, error) {
+	r := &rule{Rule: spec}
+	switch r.Target {
+	case TargetAPI, TargetK8s, TargetTransport:
+	default:
+		return nil, 0, fmt.Errorf("invalid target %q: must be one of %s, %s, %s",
+			r.Target, TargetAPI, TargetK8s, TargetTransport)
+	}
+	switch r.Type {
+	case FailureStatus, FailureConflict, FailureError, FailureLatency:
+	default:
+		return nil, 0, fmt.Errorf("invalid type %q: must be one of %s, %s, %s, %s",
+			r.Type, FailureStatus, FailureConflict, FailureError, FailureLatency)
+	}
+	if r.Match != "" {
+		match, err := regexp.Compile(r.Match)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid match: %w", err)
+		}
+		r.match = match
+	}
+	switch {
+	case r.Type == FailureConflict:
+		r.StatusCode = 409
+	case r.Type != FailureStatus:
+		r.StatusCode = 0
+	case r.StatusCode == 0:
+		r.StatusCode = DefaultStatusCode
+	case r.StatusCode < 400 || r.StatusCode > 599:
+		return nil, 0, fmt.Errorf("invalid status_code %d: must be between 400 and 599", r.StatusCode)
+	}
+	if r.Probability < 0 || r.Probability > 1 {
+		return nil, 0, fmt.Errorf("invalid probability %v: must be between 0 and 1", r.Probability)
+	}
+	if r.After < 0 || r.Count < 0 {
+		return nil, 0, fmt.Errorf("after and count must not be negative")
+	}
+	if r.Latency != "" {
+		latency, err := time.ParseDuration(r.Latency)
+		if err != nil || latency <= 0 {
+			return nil, 0, fmt.Errorf("invalid latency %q: must be a positive duration", r.Latency)
+		}
+		r.latency = latency
+	}
+	if r.Type == FailureLatency && r.latency == 0 {
+		return nil, 0, fmt.Errorf("latency rules require a latency")
+	}
+	ttl := DefaultTTL
+	if r.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(r.TTL); err != nil || ttl <= 0 || ttl > MaxTTL {
+			return nil, 0, fmt.Errorf("invalid ttl %q: must be a positive duration up to %s", r.TTL, MaxTTL)
+		}
+	}
+	return r, ttl, nil
+}
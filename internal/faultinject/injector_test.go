@@ -0,0 +1,169 @@
+package faultinject
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestInjector(clk clock.Clock, random func() float64) *Injector {
+	return New(Config{Clock: clk, Random: random}, logger.NewTestLogger())
+}
+
+func TestInjector_Add_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr string
+	}{
+		{name: "status", rule: Rule{Target: TargetAPI, Type: FailureStatus, StatusCode: 503}},
+		{name: "conflict", rule: Rule{Target: TargetK8s, Type: FailureConflict, Match: "ConfigMap"}},
+		{name: "latency", rule: Rule{Target: TargetTransport, Type: FailureLatency, Latency: "2s"}},
+		{name: "unknown target", rule: Rule{Target: "broker", Type: FailureError}, wantErr: "invalid target"},
+		{name: "unknown type", rule: Rule{Target: TargetAPI, Type: "panic"}, wantErr: "invalid type"},
+		{name: "bad match", rule: Rule{Target: TargetAPI, Type: FailureError, Match: "("}, wantErr: "invalid match"},
+		{name: "success status", rule: Rule{Target: TargetAPI, Type: FailureStatus, StatusCode: 200},
+			wantErr: "invalid status_code"},
+		{name: "probability over 1", rule: Rule{Target: TargetAPI, Type: FailureError, Probability: 1.5},
+			wantErr: "invalid probability"},
+		{name: "negative count", rule: Rule{Target: TargetAPI, Type: FailureError, Count: -1},
+			wantErr: "must not be negative"},
+		{name: "latency without duration", rule: Rule{Target: TargetAPI, Type: FailureLatency},
+			wantErr: "require a latency"},
+		{name: "ttl over max", rule: Rule{Target: TargetAPI, Type: FailureError, TTL: "2h"}, wantErr: "invalid ttl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			injector := newTestInjector(nil, nil)
+			_, err := injector.Add(context.Background(), tt.rule)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestInjector_AfterAndCount(t *testing.T) {
+	ctx := context.Background()
+	injector := newTestInjector(nil, nil)
+	added, err := injector.Add(ctx, Rule{
+		Target: TargetAPI, Type: FailureStatus, Match: "/clusters/", Method: "get", After: 1, Count: 2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1", added.ID)
+	assert.Equal(t, DefaultStatusCode, added.StatusCode)
+
+	assert.Nil(t, injector.inject(ctx, TargetAPI, "POST", "https://api/clusters/c1"), "other method")
+	assert.Nil(t, injector.inject(ctx, TargetAPI, "GET", "https://api/nodepools/n1"), "other URL")
+	assert.Nil(t, injector.inject(ctx, TargetK8s, "GET", "https://api/clusters/c1"), "other target")
+	assert.Nil(t, injector.inject(ctx, TargetAPI, "GET", "https://api/clusters/c1"), "first call passes")
+	for n := 0; n < 2; n++ {
+		f := injector.inject(ctx, TargetAPI, "GET", "https://api/clusters/c1")
+		require.NotNil(t, f, "call %d fails", n+2)
+		assert.True(t, f.fail)
+		assert.Equal(t, "1", f.rule.ID)
+	}
+	assert.Nil(t, injector.inject(ctx, TargetAPI, "GET", "https://api/clusters/c1"), "count reached")
+	assert.Empty(t, injector.Report(), "the rule is removed once its count is reached")
+}
+
+func TestInjector_Probability(t *testing.T) {
+	ctx := context.Background()
+	draws := []float64{0.9, 0.1}
+	injector := newTestInjector(nil, func() float64 {
+		draw := draws[0]
+		draws = draws[1:]
+		return draw
+	})
+	_, err := injector.Add(ctx, Rule{Target: TargetAPI, Type: FailureError, Probability: 0.5})
+	require.NoError(t, err)
+
+	assert.Nil(t, injector.inject(ctx, TargetAPI, "GET", "/a"), "draw over the probability")
+	assert.NotNil(t, injector.inject(ctx, TargetAPI, "GET", "/a"), "draw under the probability")
+	report := injector.Report()
+	require.Len(t, report, 1)
+	assert.Equal(t, 2, report[0].Matched)
+	assert.Equal(t, 1, report[0].Injected)
+}
+
+func TestInjector_Expiry(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	injector := newTestInjector(clk, nil)
+	short, err := injector.Add(ctx, Rule{Target: TargetAPI, Type: FailureError, TTL: "1m"})
+	require.NoError(t, err)
+	_, err = injector.Add(ctx, Rule{Target: TargetK8s, Type: FailureConflict})
+	require.NoError(t, err)
+	assert.Equal(t, clk.Now().Add(time.Minute), short.ExpiresAt)
+
+	clk.Advance(time.Minute)
+	assert.Nil(t, injector.inject(ctx, TargetAPI, "GET", "/a"), "expired rules inject nothing")
+	report := injector.Report()
+	require.Len(t, report, 1)
+	assert.Equal(t, TargetK8s, report[0].Target)
+
+	clk.Advance(DefaultTTL)
+	assert.Empty(t, injector.Report())
+	assert.NotNil(t, injector.Report(), "reported as [] while enabled")
+}
+
+func TestInjector_Handler(t *testing.T) {
+	injector := newTestInjector(nil, nil)
+	handler := injector.Handler()
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	w := serve(http.MethodPost, "/admin/faults",
+		`{"target":"api","match":"/statuses$","type":"status","status_code":503,"count":3,"ttl":"5m"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var added Rule
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&added))
+	assert.Equal(t, "1", added.ID)
+	assert.Equal(t, 503, added.StatusCode)
+
+	w = serve(http.MethodPost, "/admin/faults", `{"target":"k8s","type":"conflict"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = serve(http.MethodPost, "/admin/faults", `{"target":"api","type":"error","counts":3}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "unknown fields are rejected")
+	w = serve(http.MethodPost, "/admin/faults", `{"target":"api","type":"status","status_code":302}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid status_code")
+
+	w = serve(http.MethodGet, "/admin/faults", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var response RulesResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.Rules, 2)
+
+	w = serve(http.MethodDelete, "/admin/faults?id=1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	response = RulesResponse{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.Rules, 1)
+	assert.Equal(t, "2", response.Rules[0].ID)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/admin/faults?id=1", "").Code)
+
+	w = serve(http.MethodDelete, "/admin/faults", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"rules":[]}`, w.Body.String())
+
+	w = serve(http.MethodPut, "/admin/faults", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, POST, DELETE", w.Header().Get("Allow"))
+}
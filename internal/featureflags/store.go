@@ -0,0 +1,226 @@
+// Package featureflags serves boolean feature flags read from a ConfigMap, so
+// resources and post actions can be turned on and off per environment without
+// editing the task config. Each data key of the ConfigMap is a flag, its value
+// parsed as a bool. The Store refreshes the flags on an interval; an execution
+// takes a Snapshot when it starts, so a flag flipped by a refresh never changes
+// the flags an execution already sees.
+package featureflags
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DefaultRefreshInterval is how often the flags are read again by default
+const DefaultRefreshInterval = 30 * time.Second
+
+var configMapGVK = schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"}
+
+// Snapshot is the value of every known flag at one point in time. It is never
+// modified once taken, so it is safe for concurrent use.
+type Snapshot map[string]bool
+
+// Lookup returns the value of the flag name and whether it is known
+func (s Snapshot) Lookup(name string) (enabled, known bool) {
+	enabled, known = s[name]
+	return enabled, known
+}
+
+// Config configures a Store
+type Config struct {
+	// Namespace and Name locate the ConfigMap holding the flags
+	Namespace string
+	Name      string
+	// RefreshInterval is how often the ConfigMap is read. Zero uses DefaultRefreshInterval.
+	RefreshInterval time.Duration
+	// Clock drives the refresh loop and timestamps refreshes (nil uses the real clock)
+	Clock clock.Clock
+}
+
+// Report is the state of the store served by /statusz
+type Report struct {
+	// Source is the ConfigMap of the flags, "namespace/name"
+	Source string `json:"source"`
+	// Flags are the current flag values
+	Flags map[string]bool `json:"flags"`
+	// RefreshedAt is the time of the last successful refresh, zero before it
+	RefreshedAt time.Time `json:"refreshed_at,omitempty"`
+	// Error is the error of the last refresh, empty when it succeeded
+	Error string `json:"error,omitempty"`
+}
+
+// Store holds the flags read from the ConfigMap. A ConfigMap that cannot be
+// read keeps the flags of the last successful refresh; one that does not exist
+// has no flags. All methods are safe for concurrent use.
+type Store struct {
+	client      k8sclient.K8sClient
+	log         logger.Logger
+	config      Config
+	flags       Snapshot
+	refreshedAt time.Time
+	lastErr     error
+	stopCh      chan struct{}
+	doneCh      chan struct{}
+	mu          sync.RWMutex
+	once        sync.Once
+}
+
+// NewStore creates the store, reads the flags once (best-effort) and starts the
+// refresh loop, stopped by Close
+func NewStore(ctx context.Context, client k8sclient.K8sClient, config Config, log logger.Logger) (*Store, error) {
+	if client == nil {
+		return nil, fmt.Errorf("kubernetes client is required for the feature flags ConfigMap")
+	}
+	if config.Namespace == "" || config.Name == "" {
+		return nil, fmt.Errorf("feature flags ConfigMap requires both name and namespace")
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = DefaultRefreshInterval
+	}
+	config.Clock = clock.OrReal(config.Clock)
+
+	s := &Store{
+		client: client,
+		log:    log,
+		config: config,
+		flags:  Snapshot{},
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	if err := s.Refresh(ctx); err != nil {
+		errCtx := logger.WithErrorField(ctx, err)
+		log.Warnf(errCtx, "Failed to read feature flags, every flag is off until the next refresh")
+	}
+	go s.refreshLoop()
+	return s, nil
+}
+
+// Snapshot returns the current flags. A nil store has no flags.
+func (s *Store) Snapshot() Snapshot {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.flags
+}
+
+// Report returns the state of the store for /statusz
+func (s *Store) Report() Report {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	report := Report{
+		Source:      s.config.Namespace + "/" + s.config.Name,
+		Flags:       maps.Clone(s.flags),
+		RefreshedAt: s.refreshedAt,
+	}
+	if s.lastErr != nil {
+		report.Error = s.lastErr.Error()
+	}
+	return report
+}
+
+// Refresh reads the flags from the ConfigMap. A value that is not a bool is
+// logged and leaves its flag unknown.
+func (s *Store) Refresh(ctx context.Context) error {
+	flags, err := s.read(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastErr = err
+	if err != nil {
+		return err
+	}
+	if changed := changedFlags(s.flags, flags); len(changed) > 0 {
+		s.log.Infof(ctx, "Feature flags changed: %s", strings.Join(changed, ", "))
+	}
+	// The previous snapshot may still be used by executions: it is replaced, never modified
+	s.flags = flags
+	s.refreshedAt = s.config.Clock.Now()
+	return nil
+}
+
+// Close stops the refresh loop. Closing a nil store does nothing.
+func (s *Store) Close() {
+	if s == nil {
+		return
+	}
+	s.once.Do(func() {
+		close(s.stopCh)
+		<-s.doneCh
+	})
+}
+
+func (s *Store) refreshLoop() {
+	defer close(s.doneCh)
+	ticker := s.config.Clock.NewTicker(s.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C():
+			ctx, cancel := context.WithTimeout(context.Background(), s.config.RefreshInterval)
+			if err := s.Refresh(ctx); err != nil {
+				errCtx := logger.WithErrorField(ctx, err)
+				s.log.Warnf(errCtx, "Failed to refresh feature flags from ConfigMap %s/%s, keeping the last ones",
+					s.config.Namespace, s.config.Name)
+			}
+			cancel()
+		}
+	}
+}
+
+// read returns the flags of the ConfigMap, none when it does not exist
+func (s *Store) read(ctx context.Context) (Snapshot, error) {
+	obj, err := s.client.GetResource(ctx, configMapGVK, s.config.Namespace, s.config.Name, nil)
+	if apierrors.IsNotFound(err) {
+		return Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feature flags ConfigMap %s/%s: %w", s.config.Namespace, s.config.Name, err)
+	}
+	data, _, _ := unstructured.NestedStringMap(obj.Object, "data")
+	flags := make(Snapshot, len(data))
+	for name, value := range data {
+		enabled, parseErr := strconv.ParseBool(strings.TrimSpace(value))
+		if parseErr != nil {
+			s.log.Warnf(ctx, "Ignoring feature flag %q of ConfigMap %s/%s: value %q is not a bool",
+				name, s.config.Namespace, s.config.Name, value)
+			continue
+		}
+		flags[name] = enabled
+	}
+	return flags, nil
+}
+
+// changedFlags returns the flags whose value differs between old and current,
+// as "name=value" sorted by name, "name removed" for the flags no longer set
+func changedFlags(old, current Snapshot) []string {
+	var changed []string
+	for name, enabled := range current {
+		if previous, known := old[name]; !known || previous != enabled {
+			changed = append(changed, fmt.Sprintf("%s=%t", name, enabled))
+		}
+	}
+	for name := range old {
+		if _, known := current[name]; !known {
+			changed = append(changed, name+" removed")
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
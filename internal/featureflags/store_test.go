@@ -0,0 +1,108 @@
+package featureflags
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func flagsConfigMap(data map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "adapter-flags", "namespace": "hyperfleet"},
+		"data":       data,
+	}}
+}
+
+func newTestStore(t *testing.T, client *k8sclient.MockK8sClient, fake *clock.Fake) *Store {
+	t.Helper()
+	store, err := NewStore(context.Background(), client, Config{
+		Namespace:       "hyperfleet",
+		Name:            "adapter-flags",
+		RefreshInterval: time.Minute,
+		Clock:           fake,
+	}, logger.NewTestLogger())
+	require.NoError(t, err)
+	t.Cleanup(store.Close)
+	return store
+}
+
+func TestStore_ReadsFlags(t *testing.T) {
+	client := k8sclient.NewMockK8sClient()
+	client.Resources["hyperfleet/adapter-flags"] = flagsConfigMap(map[string]interface{}{
+		"canary":   "true",
+		"legacy":   " False ",
+		"rollout":  "maybe",
+		"verbose":  "1",
+		"disabled": "0",
+	})
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := newTestStore(t, client, fake)
+
+	assert.Equal(t, Snapshot{"canary": true, "legacy": false, "verbose": true, "disabled": false}, store.Snapshot())
+	enabled, known := store.Snapshot().Lookup("rollout")
+	assert.False(t, enabled)
+	assert.False(t, known, "a value that is not a bool leaves its flag unknown")
+
+	report := store.Report()
+	assert.Equal(t, "hyperfleet/adapter-flags", report.Source)
+	assert.Equal(t, fake.Now(), report.RefreshedAt)
+	assert.Empty(t, report.Error)
+}
+
+func TestStore_RefreshReplacesTheSnapshot(t *testing.T) {
+	client := k8sclient.NewMockK8sClient()
+	client.Resources["hyperfleet/adapter-flags"] = flagsConfigMap(map[string]interface{}{"canary": "false"})
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := newTestStore(t, client, fake)
+
+	before := store.Snapshot()
+	client.Resources["hyperfleet/adapter-flags"] = flagsConfigMap(map[string]interface{}{"canary": "true"})
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+
+	assert.Eventually(t, func() bool { return store.Snapshot()["canary"] }, time.Second, time.Millisecond)
+	assert.Equal(t, Snapshot{"canary": false}, before, "a snapshot taken before the refresh is unchanged")
+}
+
+func TestStore_MissingConfigMapHasNoFlags(t *testing.T) {
+	store := newTestStore(t, k8sclient.NewMockK8sClient(), clock.NewFake(time.Now()))
+	assert.Empty(t, store.Snapshot())
+	assert.Empty(t, store.Report().Error)
+}
+
+func TestStore_FailedRefreshKeepsTheFlags(t *testing.T) {
+	client := k8sclient.NewMockK8sClient()
+	client.Resources["hyperfleet/adapter-flags"] = flagsConfigMap(map[string]interface{}{"canary": "true"})
+	store := newTestStore(t, client, clock.NewFake(time.Now()))
+
+	client.GetResourceError = errors.New("connection refused")
+	err := store.Refresh(context.Background())
+	require.Error(t, err)
+
+	assert.Equal(t, Snapshot{"canary": true}, store.Snapshot())
+	assert.Contains(t, store.Report().Error, "connection refused")
+}
+
+func TestNewStore_Validation(t *testing.T) {
+	log := logger.NewTestLogger()
+	_, err := NewStore(context.Background(), nil, Config{Namespace: "ns", Name: "flags"}, log)
+	assert.Error(t, err)
+	_, err = NewStore(context.Background(), k8sclient.NewMockK8sClient(), Config{Name: "flags"}, log)
+	assert.Error(t, err)
+}
+
+func TestStore_NilStore(t *testing.T) {
+	var store *Store
+	assert.Nil(t, store.Snapshot())
+	store.Close()
+}
@@ -0,0 +1,190 @@
+// Package httpx builds the HTTP transports of the adapter's API clients. A
+// Pool hands every client the same tuned transport, so the HyperFleet API
+// client, its targets and the webhook client reuse one connection pool; only
+// clients with different TLS settings get a transport of their own.
+package httpx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"sync"
+	"time"
+)
+
+// Defaults of TransportConfig
+const (
+	DefaultMaxIdleConns        = 100
+	DefaultMaxIdleConnsPerHost = 16
+	DefaultIdleConnTimeout     = 90 * time.Second
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+)
+
+// Client names of the connection metrics
+const (
+	ClientHyperfleetAPI = "hyperfleet_api"
+	ClientWebhook       = "webhook"
+)
+
+// TransportConfig tunes the connection pool of the transports of a Pool
+// (clients.hyperfleet_api.transport). Zero values use the defaults.
+type TransportConfig struct {
+	// MaxIdleConns bounds the idle connections kept across all hosts
+	MaxIdleConns int `yaml:"max_idle_conns,omitempty" mapstructure:"max_idle_conns" validate:"gte=0"`
+	// MaxIdleConnsPerHost bounds the idle connections kept per host
+	//nolint:lll
+	MaxIdleConnsPerHost int `yaml:"max_idle_conns_per_host,omitempty" mapstructure:"max_idle_conns_per_host" validate:"gte=0"`
+	// IdleConnTimeout closes the connections idle for longer
+	IdleConnTimeout time.Duration `yaml:"idle_conn_timeout,omitempty" mapstructure:"idle_conn_timeout" validate:"gte=0"`
+	// TLSHandshakeTimeout bounds the TLS handshake of new connections
+	//nolint:lll
+	TLSHandshakeTimeout time.Duration `yaml:"tls_handshake_timeout,omitempty" mapstructure:"tls_handshake_timeout" validate:"gte=0"`
+	// DisableHTTP2 keeps the connections on HTTP/1.1
+	DisableHTTP2 bool `yaml:"disable_http2,omitempty" mapstructure:"disable_http2"`
+}
+
+// TLSConfig is the TLS settings of a client that does not use the system CAs
+// as they are. Clients with equal settings share a transport.
+type TLSConfig struct {
+	// CAFile is a PEM bundle of CAs trusted in addition to the system pool
+	CAFile string `yaml:"ca_file,omitempty" mapstructure:"ca_file"`
+	// InsecureSkipVerify disables the verification of server certificates
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify,omitempty" mapstructure:"insecure_skip_verify"`
+}
+
+// ConnObserver observes the connections of the requests sent through a Pool.
+// Implemented by metrics.Recorder.
+type ConnObserver interface {
+	// ObserveHTTPConnection is called once per request with whether it reused
+	// an idle connection
+	ObserveHTTPConnection(client string, reused bool)
+}
+
+// Pool hands out the transports of the API clients: one shared transport for
+// the clients with the default TLS settings and one per distinct TLS settings,
+// each created on first use and kept for the life of the pool.
+type Pool struct {
+	observer   ConnObserver
+	transports map[TLSConfig]*http.Transport
+	config     TransportConfig
+	mu         sync.Mutex
+}
+
+// NewPool creates a pool of transports tuned by config (nil uses the
+// defaults), whose connections are observed by observer unless nil
+func NewPool(config *TransportConfig, observer ConnObserver) *Pool {
+	pool := &Pool{observer: observer, transports: make(map[TLSConfig]*http.Transport)}
+	if config != nil {
+		pool.config = *config
+	}
+	return pool
+}
+
+// Transport returns the transport of the TLS settings tlsConfig, the shared
+// one when nil or zero. It fails when the CA file cannot be loaded.
+func (p *Pool) Transport(tlsConfig *TLSConfig) (*http.Transport, error) {
+	var key TLSConfig
+	if tlsConfig != nil {
+		key = *tlsConfig
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if transport, ok := p.transports[key]; ok {
+		return transport, nil
+	}
+	transport, err := p.newTransport(key)
+	if err != nil {
+		return nil, err
+	}
+	p.transports[key] = transport
+	return transport, nil
+}
+
+// RoundTripper returns the transport of tlsConfig as Transport does, reporting
+// the connections of its requests to the observer of the pool as those of client
+func (p *Pool) RoundTripper(client string, tlsConfig *TLSConfig) (http.RoundTripper, error) {
+	transport, err := p.Transport(tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	if p.observer == nil {
+		return transport, nil
+	}
+	return &observedTransport{next: transport, client: client, observer: p.observer}, nil
+}
+
+// newTransport clones http.DefaultTransport, keeping its proxy and dialer
+// settings, with the pool tuning and the TLS settings key applied
+func (p *Pool) newTransport(key TLSConfig) (*http.Transport, error) {
+	//nolint:errcheck // http.DefaultTransport is an *http.Transport unless replaced
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = orDefault(p.config.MaxIdleConns, DefaultMaxIdleConns)
+	transport.MaxIdleConnsPerHost = orDefault(p.config.MaxIdleConnsPerHost, DefaultMaxIdleConnsPerHost)
+	transport.IdleConnTimeout = orDefault(p.config.IdleConnTimeout, DefaultIdleConnTimeout)
+	transport.TLSHandshakeTimeout = orDefault(p.config.TLSHandshakeTimeout, DefaultTLSHandshakeTimeout)
+	if key != (TLSConfig{}) {
+		tlsConfig, err := loadTLSConfig(key)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	if p.config.DisableHTTP2 {
+		transport.ForceAttemptHTTP2 = false
+		// A non-nil empty map disables the HTTP/2 upgrade of TLS connections
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	return transport, nil
+}
+
+// loadTLSConfig builds the client TLS config of settings
+func loadTLSConfig(settings TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: settings.InsecureSkipVerify, //nolint:gosec // opted into by configuration
+	}
+	if settings.CAFile == "" {
+		return tlsConfig, nil
+	}
+	pem, err := os.ReadFile(settings.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("CA file %s contains no PEM certificates", settings.CAFile)
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+func orDefault[T int | time.Duration](value, defaultValue T) T {
+	if value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// observedTransport reports whether each request got a new or reused connection
+type observedTransport struct {
+	next     http.RoundTripper
+	observer ConnObserver
+	client   string
+}
+
+// RoundTrip implements http.RoundTripper. The trace composes with one already
+// on the request context.
+func (t *observedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.observer.ObserveHTTPConnection(t.client, info.Reused)
+		},
+	}
+	return t.next.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+}
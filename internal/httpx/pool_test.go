@@ -0,0 +1,132 @@
+package httpx
+
+import (
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connRecorder records the observed connections as "client:new" or "client:reused"
+type connRecorder struct {
+	conns []string
+	mu    sync.Mutex
+}
+
+func (r *connRecorder) ObserveHTTPConnection(client string, reused bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	connection := client + ":new"
+	if reused {
+		connection = client + ":reused"
+	}
+	r.conns = append(r.conns, connection)
+}
+
+// get sends a GET to url through transport and drains the body, so the
+// connection goes back to the pool; it returns whether the connection was reused
+func get(t *testing.T, transport http.RoundTripper, url string) bool {
+	t.Helper()
+	var reused bool
+	trace := &httptrace.ClientTrace{GotConn: func(info httptrace.GotConnInfo) { reused = info.Reused }}
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(t.Context(), trace), http.MethodGet, url, nil)
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	require.NoError(t, resp.Body.Close())
+	return reused
+}
+
+// writeCAFile writes the certificate of a TLS test server as a CA file
+func writeCAFile(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0o600))
+	return path
+}
+
+func TestPool_ReusesConnectionsAcrossCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	observer := &connRecorder{}
+	pool := NewPool(nil, observer)
+
+	api, err := pool.RoundTripper(ClientHyperfleetAPI, nil)
+	require.NoError(t, err)
+	webhook, err := pool.RoundTripper(ClientWebhook, &TLSConfig{})
+	require.NoError(t, err)
+
+	assert.False(t, get(t, api, server.URL), "the first call dials")
+	assert.True(t, get(t, api, server.URL), "the trace of the caller composes with the pool's")
+	assert.True(t, get(t, webhook, server.URL), "clients with the default TLS settings share the connections")
+	assert.Equal(t, []string{"hyperfleet_api:new", "hyperfleet_api:reused", "webhook:reused"}, observer.conns)
+}
+
+func TestPool_IsolatesTLSSettings(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	pool := NewPool(nil, nil)
+	private := &TLSConfig{CAFile: writeCAFile(t, server)}
+
+	shared, err := pool.Transport(nil)
+	require.NoError(t, err)
+	dedicated, err := pool.Transport(private)
+	require.NoError(t, err)
+	assert.NotSame(t, shared, dedicated, "other TLS settings get a transport of their own")
+	again, err := pool.Transport(&TLSConfig{CAFile: private.CAFile})
+	require.NoError(t, err)
+	assert.Same(t, dedicated, again, "equal TLS settings share a transport")
+
+	_, err = (&http.Client{Transport: shared}).Get(server.URL)
+	require.Error(t, err, "the shared transport does not trust the private CA")
+	assert.False(t, get(t, dedicated, server.URL))
+	assert.True(t, get(t, dedicated, server.URL))
+}
+
+func TestPool_Tuning(t *testing.T) {
+	pool := NewPool(&TransportConfig{
+		MaxIdleConnsPerHost: 64,
+		IdleConnTimeout:     time.Minute,
+		DisableHTTP2:        true,
+	}, nil)
+	transport, err := pool.Transport(nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMaxIdleConns, transport.MaxIdleConns)
+	assert.Equal(t, 64, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+	assert.Equal(t, DefaultTLSHandshakeTimeout, transport.TLSHandshakeTimeout)
+	assert.False(t, transport.ForceAttemptHTTP2)
+	assert.NotNil(t, transport.TLSNextProto)
+	assert.Empty(t, transport.TLSNextProto)
+
+	insecure, err := pool.Transport(&TLSConfig{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	assert.Equal(t, 64, insecure.MaxIdleConnsPerHost, "the tuning applies to every transport")
+	assert.True(t, insecure.TLSClientConfig.InsecureSkipVerify)
+}
+
+func TestPool_InvalidCAFile(t *testing.T) {
+	pool := NewPool(nil, nil)
+	_, err := pool.Transport(&TLSConfig{CAFile: "/nonexistent/ca.pem"})
+	assert.ErrorContains(t, err, "failed to read CA file")
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+	_, err = pool.RoundTripper(ClientWebhook, &TLSConfig{CAFile: notPEM})
+	assert.ErrorContains(t, err, "contains no PEM certificates")
+}
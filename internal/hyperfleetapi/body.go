@@ -0,0 +1,197 @@
+package hyperfleetapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"strings"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// Content types of encoded request bodies
+const (
+	ContentTypeJSON      = "application/json"
+	ContentTypeForm      = "application/x-www-form-urlencoded"
+	ContentTypeMultipart = "multipart/form-data"
+)
+
+// redactedValue replaces sensitive values in request body descriptions
+const redactedValue = "[REDACTED]"
+
+// FormField is a field of an application/x-www-form-urlencoded request body
+type FormField struct {
+	Name  string
+	Value string
+	// Sensitive redacts the value from logs
+	Sensitive bool
+}
+
+// MultipartPart is a part of a multipart/form-data request body
+type MultipartPart struct {
+	Name string
+	// Filename makes the part a file upload
+	Filename string
+	// ContentType of the part; empty leaves it unset for fields and
+	// application/octet-stream for files
+	ContentType string
+	Content     []byte
+	// Sensitive redacts the content from logs
+	Sensitive bool
+}
+
+// WithFormFields sends the fields as an application/x-www-form-urlencoded body,
+// replacing the request body
+func WithFormFields(fields []FormField) RequestOption {
+	return func(r *Request) {
+		r.FormFields = fields
+	}
+}
+
+// WithMultipartParts sends the parts as a multipart/form-data body, replacing
+// the request body
+func WithMultipartParts(parts []MultipartPart) RequestOption {
+	return func(r *Request) {
+		r.Parts = parts
+	}
+}
+
+// encodeRequestBody returns req with its form fields or multipart parts encoded
+// into the body and the matching Content-Type header set. A request without
+// either is returned unchanged. The body is encoded once, so every retry sends
+// the same multipart boundary. Sensitive values are registered with the logger
+// so they are redacted if they are ever logged.
+func encodeRequestBody(req *Request) (*Request, error) {
+	if req.FormFields == nil && req.Parts == nil {
+		return req, nil
+	}
+	if req.FormFields != nil && req.Parts != nil {
+		return nil, errors.New("request has both form fields and multipart parts")
+	}
+	encoded := *req
+	encoded.Headers = make(map[string]string, len(req.Headers)+1)
+	for k, v := range req.Headers {
+		encoded.Headers[k] = v
+	}
+	var contentType string
+	if req.FormFields != nil {
+		encoded.Body, contentType = EncodeForm(req.FormFields)
+	} else {
+		var err error
+		encoded.Body, contentType, err = EncodeMultipart(req.Parts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// The encoding owns the Content-Type: a multipart body is unreadable without its boundary
+	for name := range encoded.Headers {
+		if strings.EqualFold(name, "Content-Type") {
+			delete(encoded.Headers, name)
+		}
+	}
+	encoded.Headers["Content-Type"] = contentType
+	return &encoded, nil
+}
+
+// EncodeForm encodes fields as an application/x-www-form-urlencoded body in
+// their order and returns it with its content type
+func EncodeForm(fields []FormField) ([]byte, string) {
+	var b strings.Builder
+	for i, field := range fields {
+		if field.Sensitive {
+			logger.RegisterSecret(field.Value)
+		}
+		if i > 0 {
+			b.WriteByte('&')
+		}
+		b.WriteString(url.QueryEscape(field.Name))
+		b.WriteByte('=')
+		b.WriteString(url.QueryEscape(field.Value))
+	}
+	return []byte(b.String()), ContentTypeForm
+}
+
+// EncodeMultipart encodes parts as a multipart/form-data body and returns it
+// with its content type, which carries the boundary
+func EncodeMultipart(parts []MultipartPart) ([]byte, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, part := range parts {
+		if part.Sensitive {
+			logger.RegisterSecret(string(part.Content))
+		}
+		header := make(textproto.MIMEHeader)
+		disposition := fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(part.Name))
+		contentType := part.ContentType
+		if part.Filename != "" {
+			disposition += fmt.Sprintf(`; filename="%s"`, escapeQuotes(part.Filename))
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+		}
+		header.Set("Content-Disposition", disposition)
+		if contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create multipart part %q: %w", part.Name, err)
+		}
+		if _, err := w.Write(part.Content); err != nil {
+			return nil, "", fmt.Errorf("failed to write multipart part %q: %w", part.Name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to close multipart body: %w", err)
+	}
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+// quoteEscaper escapes the quoted strings of a Content-Disposition header, as mime/multipart does
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
+// DescribeFormFields returns the fields for logs, with sensitive values redacted
+func DescribeFormFields(fields []FormField) string {
+	described := make([]string, 0, len(fields))
+	for _, field := range fields {
+		value := field.Value
+		if field.Sensitive {
+			value = redactedValue
+		}
+		described = append(described, field.Name+"="+value)
+	}
+	return strings.Join(described, "&")
+}
+
+// DescribeMultipartParts returns the parts for logs: their names, file names,
+// content types and sizes. The content of fields is included unless sensitive;
+// the content of files is left out.
+func DescribeMultipartParts(parts []MultipartPart) string {
+	described := make([]string, 0, len(parts))
+	for _, part := range parts {
+		var b strings.Builder
+		b.WriteString(part.Name)
+		if part.Filename != "" {
+			fmt.Fprintf(&b, " filename=%q", part.Filename)
+		}
+		if part.ContentType != "" {
+			fmt.Fprintf(&b, " content_type=%s", part.ContentType)
+		}
+		fmt.Fprintf(&b, " bytes=%d", len(part.Content))
+		switch {
+		case part.Sensitive:
+			b.WriteString(" content=" + redactedValue)
+		case part.Filename == "":
+			fmt.Fprintf(&b, " content=%q", part.Content)
+		}
+		described = append(described, b.String())
+	}
+	return "[" + strings.Join(described, ", ") + "]"
+}
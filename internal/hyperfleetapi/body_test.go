@@ -0,0 +1,134 @@
+package hyperfleetapi
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientFormBody(t *testing.T) {
+	var contentType string
+	var form map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		require.NoError(t, r.ParseForm())
+		form = r.PostForm
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(testLog(), WithBaseURL(server.URL))
+	require.NoError(t, err)
+
+	fields := []FormField{
+		{Name: "cluster_id", Value: "cluster-1"},
+		{Name: "note", Value: "a&b=c d"},
+		{Name: "token", Value: "s3cret", Sensitive: true},
+	}
+	// A JSON Content-Type set by the caller is replaced by the form's
+	_, err = client.Post(context.Background(), "/legacy", nil,
+		WithHeader("Content-Type", ContentTypeJSON), WithFormFields(fields))
+	require.NoError(t, err)
+
+	assert.Equal(t, ContentTypeForm, contentType)
+	assert.Equal(t, map[string][]string{
+		"cluster_id": {"cluster-1"},
+		"note":       {"a&b=c d"},
+		"token":      {"s3cret"},
+	}, form)
+}
+
+func TestClientMultipartBody(t *testing.T) {
+	type receivedPart struct {
+		name, filename, contentType, content string
+	}
+	var mu sync.Mutex
+	var boundaries []string
+	var received []receivedPart
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		assert.Equal(t, ContentTypeMultipart, mediaType)
+		boundaries = append(boundaries, params["boundary"])
+
+		reader, err := r.MultipartReader()
+		require.NoError(t, err)
+		received = nil
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			content, err := io.ReadAll(part)
+			require.NoError(t, err)
+			received = append(received, receivedPart{
+				name: part.FormName(), filename: part.FileName(),
+				contentType: part.Header.Get("Content-Type"), content: string(content),
+			})
+		}
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(testLog(), WithBaseURL(server.URL), WithBaseDelay(time.Millisecond))
+	require.NoError(t, err)
+
+	kubeconfig := "apiVersion: v1\nkind: Config\n"
+	resp, err := client.Put(context.Background(), "/upload", nil, WithMultipartParts([]MultipartPart{
+		{Name: "cluster_id", Content: []byte("cluster-1")},
+		{Name: "kubeconfig", Filename: "kubeconfig.yaml", ContentType: "application/yaml", Content: []byte(kubeconfig)},
+		{Name: "ca", Filename: `ca "root".pem`, Content: []byte("-----BEGIN-----"), Sensitive: true},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	assert.Equal(t, []receivedPart{
+		{name: "cluster_id", content: "cluster-1"},
+		{name: "kubeconfig", filename: "kubeconfig.yaml", contentType: "application/yaml", content: kubeconfig},
+		{name: "ca", filename: `ca "root".pem`, contentType: "application/octet-stream", content: "-----BEGIN-----"},
+	}, received)
+	require.Len(t, boundaries, 2)
+	assert.Equal(t, boundaries[0], boundaries[1], "retries send the same encoded body")
+}
+
+func TestClientFormAndMultipartBody(t *testing.T) {
+	client, err := NewClient(testLog(), WithBaseURL("http://localhost"))
+	require.NoError(t, err)
+
+	_, err = client.Post(context.Background(), "/test", nil,
+		WithFormFields([]FormField{{Name: "a"}}), WithMultipartParts([]MultipartPart{{Name: "b"}}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "both form fields and multipart parts")
+}
+
+func TestDescribeBodies(t *testing.T) {
+	assert.Equal(t, "cluster_id=cluster-1&token=[REDACTED]", DescribeFormFields([]FormField{
+		{Name: "cluster_id", Value: "cluster-1"},
+		{Name: "token", Value: "s3cret", Sensitive: true},
+	}))
+	assert.Equal(t,
+		`[cluster_id bytes=9 content="cluster-1", kubeconfig filename="kc.yaml" bytes=4, `+
+			`key bytes=6 content=[REDACTED]]`,
+		DescribeMultipartParts([]MultipartPart{
+			{Name: "cluster_id", Content: []byte("cluster-1")},
+			{Name: "kubeconfig", Filename: "kc.yaml", Content: []byte("kind")},
+			{Name: "key", Content: []byte("s3cret"), Sensitive: true},
+		}))
+}
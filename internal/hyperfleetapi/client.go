@@ -10,10 +10,16 @@ import (
 	"math"
 	"math/big"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/httpx"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/retrybudget"
 	apierrors "github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/errors"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/version"
@@ -34,9 +40,19 @@ const (
 
 // httpClient implements the Client interface
 type httpClient struct {
-	client *http.Client
-	config *ClientConfig
-	log    logger.Logger
+	client  *http.Client
+	config  *ClientConfig
+	log     logger.Logger
+	clock   clock.Clock
+	targets *targetSet
+	// transport is the round tripper of the created HTTP client, set with WithTransport
+	transport http.RoundTripper
+	// pool provides the transports of the created HTTP clients, set with WithTransportPool
+	pool *httpx.Pool
+	// wrapTransport wraps every transport taken from pool, set with WithTransportWrapper
+	wrapTransport func(http.RoundTripper) http.RoundTripper
+	// gzipAccepted is set once the server advertised gzip request bodies
+	gzipAccepted atomic.Bool
 }
 
 // ClientOption is a functional option for configuring the client
@@ -49,6 +65,39 @@ func WithHTTPClient(client *http.Client) ClientOption {
 	}
 }
 
+// WithTransport sets the round tripper of the HTTP client created by NewClient,
+// e.g. to wrap http.DefaultTransport. Ignored with WithHTTPClient.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *httpClient) {
+		c.transport = transport
+	}
+}
+
+// WithTransportPool sets the pool providing the transports of the HTTP clients
+// created by NewClient and of its targets, e.g. to share the connections with
+// other clients. Without it NewClient creates a pool from the Transport config.
+func WithTransportPool(pool *httpx.Pool) ClientOption {
+	return func(c *httpClient) {
+		c.pool = pool
+	}
+}
+
+// WithTransportWrapper wraps every transport taken from the transport pool,
+// those of the targets included, e.g. to inject faults. Ignored with
+// WithTransport and WithHTTPClient for the default target.
+func WithTransportWrapper(wrap func(http.RoundTripper) http.RoundTripper) ClientOption {
+	return func(c *httpClient) {
+		c.wrapTransport = wrap
+	}
+}
+
+// WithTargets sets the additional named API targets
+func WithTargets(targets []TargetConfig) ClientOption {
+	return func(c *httpClient) {
+		c.config.Targets = targets
+	}
+}
+
 // WithConfig sets the client configuration
 func WithConfig(config *ClientConfig) ClientOption {
 	return func(c *httpClient) {
@@ -103,6 +152,21 @@ func WithMaxDelay(delay time.Duration) ClientOption {
 	}
 }
 
+// WithClock sets the clock used for retry backoff and request durations (default: the real clock)
+func WithClock(clk clock.Clock) ClientOption {
+	return func(c *httpClient) {
+		c.clock = clock.OrReal(clk)
+	}
+}
+
+// WithCompressRequests enables gzip compression of large request bodies once
+// the server advertises support for them
+func WithCompressRequests(enabled bool) ClientOption {
+	return func(c *httpClient) {
+		c.config.CompressRequests = enabled
+	}
+}
+
 // WithBaseURL sets the base URL for all API requests
 func WithBaseURL(baseURL string) ClientOption {
 	return func(c *httpClient) {
@@ -124,6 +188,7 @@ func NewClient(log logger.Logger, opts ...ClientOption) (Client, error) {
 	c := &httpClient{
 		config: DefaultClientConfig(),
 		log:    log,
+		clock:  clock.Real,
 	}
 
 	// Apply options (including WithBaseURL if provided by caller)
@@ -143,16 +208,112 @@ func NewClient(log logger.Logger, opts ...ClientOption) (Client, error) {
 			EnvBaseURL)
 	}
 
+	registerSecretHeaders(c.config.DefaultHeaders)
+
+	if c.pool == nil {
+		c.pool = httpx.NewPool(c.config.Transport, nil)
+	}
+
 	// Create HTTP client if not provided
 	if c.client == nil {
+		transport := c.transport
+		if transport == nil {
+			var err error
+			if transport, err = c.poolTransport(nil); err != nil {
+				return nil, err
+			}
+		}
 		c.client = &http.Client{
-			Timeout: c.config.Timeout,
+			Timeout:   c.config.Timeout,
+			Transport: transport,
+		}
+	}
+
+	// The transports of the targets are created now, so an unreadable CA file
+	// fails here rather than on the first call to the target
+	for _, target := range c.config.Targets {
+		if target.TLS == nil {
+			continue
+		}
+		if _, err := c.pool.Transport(target.TLS); err != nil {
+			return nil, fmt.Errorf("invalid TLS settings of HyperFleet API target %q: %w", target.Name, err)
 		}
 	}
 
+	c.targets = newTargetSet(c, c.config.Targets)
+
 	return c, nil
 }
 
+// poolTransport returns the transport of the TLS settings tlsConfig from the
+// pool of c, wrapped by the transport wrapper
+func (c *httpClient) poolTransport(tlsConfig *httpx.TLSConfig) (http.RoundTripper, error) {
+	transport, err := c.pool.RoundTripper(httpx.ClientHyperfleetAPI, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	if c.wrapTransport != nil {
+		transport = c.wrapTransport(transport)
+	}
+	return transport, nil
+}
+
+// secretHeaderNames are substrings of the lowercase names of headers whose
+// values are credentials
+var secretHeaderNames = []string{"authorization", "token", "api-key", "apikey", "secret", "cookie"}
+
+// IsSecretHeader reports whether the value of the header name is a credential
+func IsSecretHeader(name string) bool {
+	lower := strings.ToLower(name)
+	for _, secret := range secretHeaderNames {
+		if strings.Contains(lower, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactURL returns rawURL for logs and records, with the values of query
+// parameters named like credentials (see IsSecretHeader), e.g. ?token=...,
+// replaced and the password of its userinfo masked as by url.URL.Redacted.
+// The query keeps its order.
+func RedactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	_, redacted := parsed.User.Password()
+	if parsed.RawQuery != "" {
+		pairs := strings.Split(parsed.RawQuery, "&")
+		for i, pair := range pairs {
+			key, _, hasValue := strings.Cut(pair, "=")
+			name, err := url.QueryUnescape(key)
+			if err != nil {
+				name = key
+			}
+			if hasValue && IsSecretHeader(name) {
+				pairs[i] = key + "=" + redactedValue
+				redacted = true
+			}
+		}
+		parsed.RawQuery = strings.Join(pairs, "&")
+	}
+	if !redacted {
+		return rawURL
+	}
+	return parsed.Redacted()
+}
+
+// registerSecretHeaders registers the values of credential headers with the
+// logger, so they are redacted if a request or error message is ever logged
+func registerSecretHeaders(headers map[string]string) {
+	for name, value := range headers {
+		if IsSecretHeader(name) {
+			logger.RegisterSecret(value)
+		}
+	}
+}
+
 // BaseURLFromEnv returns the base URL from environment variable
 func BaseURLFromEnv() string {
 	return os.Getenv(EnvBaseURL)
@@ -176,6 +337,11 @@ func (c *httpClient) Do(ctx context.Context, req *Request) (*Response, error) {
 	if req == nil {
 		return nil, errors.New("request cannot be nil")
 	}
+	encoded, err := encodeRequestBody(req)
+	if err != nil {
+		return nil, apierrors.NewAPIError(req.Method, req.URL, 0, "", nil, 0, 0, err)
+	}
+	req = encoded
 
 	// Determine retry configuration
 	retryAttempts := c.config.RetryAttempts
@@ -194,13 +360,16 @@ func (c *httpClient) Do(ctx context.Context, req *Request) (*Response, error) {
 
 	var lastErr error
 	var lastResp *Response
-	startTime := time.Now()
+	startTime := c.clock.Now()
+	// attempts is the number of attempts made, fewer than retryAttempts when
+	// the retry budget denied a retry
+	attempts := retryAttempts
 
 	for attempt := 1; attempt <= retryAttempts; attempt++ {
 		// Check context before each attempt
 		if err := ctx.Err(); err != nil {
 			return nil, apierrors.NewAPIError(req.Method, req.URL, 0, "", nil, attempt,
-				time.Since(startTime), fmt.Errorf("context canceled: %w", err))
+				c.clock.Since(startTime), fmt.Errorf("context canceled: %w", err))
 		}
 
 		resp, err := c.doRequest(ctx, req)
@@ -209,7 +378,7 @@ func (c *httpClient) Do(ctx context.Context, req *Request) (*Response, error) {
 			c.log.Warnf(ctx, "HyperFleet API request failed (attempt %d/%d): %v", attempt, retryAttempts, err)
 		} else {
 			resp.Attempts = attempt
-			resp.Duration = time.Since(startTime)
+			resp.Duration = c.clock.Since(startTime)
 
 			// Success or non-retryable error
 			if resp.IsSuccess() || !resp.IsRetryable() {
@@ -225,35 +394,63 @@ func (c *httpClient) Do(ctx context.Context, req *Request) (*Response, error) {
 		// Don't sleep after the last attempt
 		if attempt < retryAttempts {
 			delay := c.calculateBackoff(attempt, backoffStrategy)
+			// The wait is denied once the retry budget of the execution is spent
+			if budgetErr := retrybudget.Reserve(ctx, delay); budgetErr != nil {
+				c.log.Warnf(ctx, "HyperFleet API request not retried: %v", budgetErr)
+				lastErr = fmt.Errorf("%w; last attempt: %w", budgetErr, lastErr)
+				attempts = attempt
+				break
+			}
 			c.log.Infof(ctx, "Retrying in %v...", delay)
 
-			select {
-			case <-ctx.Done():
+			if err := c.clock.Sleep(ctx, delay); err != nil {
 				return nil, apierrors.NewAPIError(req.Method, req.URL, 0, "", nil, attempt,
-					time.Since(startTime), fmt.Errorf("context canceled during retry: %w", ctx.Err()))
-			case <-time.After(delay):
-				// Continue to next attempt
+					c.clock.Since(startTime), fmt.Errorf("context canceled during retry: %w", err))
 			}
 		}
 	}
 
-	// All retries exhausted - return APIError with full details
-	duration := time.Since(startTime)
+	// All retries exhausted or denied by the retry budget - return APIError with full details
+	duration := c.clock.Since(startTime)
 	if lastResp != nil {
 		lastResp.Duration = duration
-		return lastResp, apierrors.NewAPIError(
+		apiErr := apierrors.NewAPIError(
 			req.Method,
 			req.URL,
 			lastResp.StatusCode,
 			lastResp.Status,
 			lastResp.Body,
-			retryAttempts,
+			attempts,
 			duration,
 			lastErr,
 		)
+		apiErr.RetryAfter = retryAfter(lastResp, c.clock.Now())
+		return lastResp, apiErr
 	}
 
-	return nil, apierrors.NewAPIError(req.Method, req.URL, 0, "", nil, retryAttempts, duration, lastErr)
+	return nil, apierrors.NewAPIError(req.Method, req.URL, 0, "", nil, attempts, duration, lastErr)
+}
+
+// retryAfter returns the delay requested by the Retry-After header of a 429 or 503
+// response, in delay-seconds or HTTP-date form. Returns zero when absent or invalid.
+func retryAfter(resp *Response, now time.Time) time.Duration {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0
+	}
+	value := strings.TrimSpace(http.Header(resp.Headers).Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
 }
 
 // resolveURL resolves the request URL by prepending base URL if the URL is relative.
@@ -272,8 +469,22 @@ func (c *httpClient) resolveURL(url string) string {
 	return baseURL + url
 }
 
-// doRequest performs a single HTTP request without retry logic
+// doRequest performs a single HTTP request without retry logic. A body sent
+// uncompressed and rejected with 415 by a server advertising gzip request
+// bodies is sent again compressed (RFC 7694).
 func (c *httpClient) doRequest(ctx context.Context, req *Request) (*Response, error) {
+	compress := c.shouldCompress(req)
+	resp, err := c.sendRequest(ctx, req, compress)
+	if err != nil || compress || resp.StatusCode != http.StatusUnsupportedMediaType || !c.shouldCompress(req) {
+		return resp, err
+	}
+	c.log.Debugf(ctx, "HyperFleet API requires compressed request bodies, sending %s %s compressed",
+		req.Method, req.URL)
+	return c.sendRequest(ctx, req, true)
+}
+
+// sendRequest sends req once, its body gzip-compressed when compress is set
+func (c *httpClient) sendRequest(ctx context.Context, req *Request, compress bool) (*Response, error) {
 	// Resolve URL (prepend base URL if relative)
 	resolvedURL := c.resolveURL(req.URL)
 
@@ -303,7 +514,15 @@ func (c *httpClient) doRequest(ctx context.Context, req *Request) (*Response, er
 	// Create HTTP request
 	var body io.Reader
 	if len(req.Body) > 0 {
-		body = bytes.NewReader(req.Body)
+		reqBody := req.Body
+		if compress {
+			compressed, err := gzipBody(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			reqBody = compressed
+		}
+		body = bytes.NewReader(reqBody)
 	}
 
 	httpReq, err := http.NewRequestWithContext(reqCtx, req.Method, resolvedURL, body)
@@ -323,7 +542,15 @@ func (c *httpClient) doRequest(ctx context.Context, req *Request) (*Response, er
 
 	// Set default Content-Type for requests with body
 	if len(req.Body) > 0 && httpReq.Header.Get("Content-Type") == "" {
-		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Content-Type", ContentTypeJSON)
+	}
+
+	if compress {
+		httpReq.Header.Set("Content-Encoding", "gzip")
+	}
+	// Ask for compressed responses (respect explicit caller override)
+	if httpReq.Header.Get("Accept-Encoding") == "" {
+		httpReq.Header.Set("Accept-Encoding", acceptEncoding)
 	}
 
 	// Set User-Agent header (respect explicit caller override)
@@ -352,12 +579,29 @@ func (c *httpClient) doRequest(ctx context.Context, req *Request) (*Response, er
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
+	if acceptsGzip(httpResp.Header) {
+		c.gzipAccepted.Store(true)
+	}
+
+	// Decompress the body; the headers then describe the decompressed body
+	headers := httpResp.Header
+	wireBytes := len(respBody)
+	if encoding := headers.Get("Content-Encoding"); encoding != "" {
+		respBody, err = decodeBody(encoding, respBody)
+		if err != nil {
+			return nil, err
+		}
+		headers = headers.Clone()
+		headers.Del("Content-Encoding")
+		headers.Del("Content-Length")
+	}
 
 	response := &Response{
 		StatusCode: httpResp.StatusCode,
 		Status:     httpResp.Status,
-		Headers:    httpResp.Header,
+		Headers:    headers,
 		Body:       respBody,
+		WireBytes:  wireBytes,
 	}
 
 	c.log.Debugf(ctx, "HyperFleet API response: %d %s", response.StatusCode, response.Status)
@@ -477,3 +721,28 @@ func (c *httpClient) Delete(ctx context.Context, url string, opts ...RequestOpti
 func (c *httpClient) BaseURL() string {
 	return c.config.BaseURL
 }
+
+// Target returns the client of the named API target
+func (c *httpClient) Target(name string) (Client, error) {
+	if c.targets == nil {
+		c.targets = newTargetSet(c, c.config.Targets)
+	}
+	return c.targets.client(name)
+}
+
+// Ping verifies the HyperFleet API is reachable by requesting url once, without
+// retries. Any response counts as reachable except server errors (5xx) and
+// authentication failures (401, 403).
+func Ping(ctx context.Context, client Client, url string) error {
+	resp, err := client.Get(ctx, url, WithRequestRetryAttempts(1))
+	if err != nil {
+		return fmt.Errorf("HyperFleet API check failed: %w", err)
+	}
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError,
+		resp.StatusCode == http.StatusUnauthorized,
+		resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("HyperFleet API check failed: HTTP %s", resp.Status)
+	}
+	return nil
+}
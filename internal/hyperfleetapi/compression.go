@@ -0,0 +1,98 @@
+package hyperfleetapi
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// acceptEncoding is the Accept-Encoding header of the requests. Setting it
+// disables the transparent gzip decompression of net/http, so the client
+// decompresses the bodies itself, whatever the transport.
+const acceptEncoding = "gzip, deflate"
+
+// compressRequestMinBytes is the smallest request body compressed when
+// compress_requests is enabled; smaller bodies gain nothing
+const compressRequestMinBytes = 1024
+
+// decodeBody returns body decoded from the content encoding of a response.
+// Bodies without a content encoding, or with identity, are returned as-is.
+func decodeBody(encoding string, body []byte) ([]byte, error) {
+	var reader io.ReadCloser
+	var err error
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		reader, err = gzip.NewReader(bytes.NewReader(body))
+	case "deflate":
+		// "deflate" is zlib-wrapped per RFC 9110, but some servers send raw deflate
+		reader, err = zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			reader, err = flate.NewReader(bytes.NewReader(body)), nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported response content encoding %q", encoding)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s response body: %w", encoding, err)
+	}
+	defer reader.Close() //nolint:errcheck // in-memory reader
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s response body: %w", encoding, err)
+	}
+	return decoded, nil
+}
+
+// gzipBody returns body compressed with gzip
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(body); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// acceptsGzip reports whether the Accept-Encoding header of a response
+// advertises gzip request bodies (RFC 7694)
+func acceptsGzip(header http.Header) bool {
+	for _, value := range header.Values("Accept-Encoding") {
+		for _, coding := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(coding, ";")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name != "gzip" && name != "x-gzip" {
+				continue
+			}
+			if q := strings.ReplaceAll(strings.TrimSpace(params), " ", ""); q == "q=0" || q == "q=0.0" {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// shouldCompress reports whether the body of req is sent gzip-compressed: with
+// compress_requests enabled, once the server advertised gzip request bodies,
+// for bodies of compressRequestMinBytes or more not encoded by the caller
+func (c *httpClient) shouldCompress(req *Request) bool {
+	if !c.config.CompressRequests || !c.gzipAccepted.Load() || len(req.Body) < compressRequestMinBytes {
+		return false
+	}
+	for name := range req.Headers {
+		if strings.EqualFold(name, "Content-Encoding") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,204 @@
+package hyperfleetapi
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// largeList is a list response worth compressing
+var largeList = `{"items":[` + strings.Repeat(`{"id":"cluster","status":"Ready"},`, 200) + `{"id":"last"}]}`
+
+func compress(t *testing.T, encoding string, body []byte) []byte {
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+	switch encoding {
+	case "gzip":
+		writer = gzip.NewWriter(&buf)
+	case "deflate":
+		writer = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		var err error
+		writer, err = flate.NewWriter(&buf, flate.DefaultCompression)
+		require.NoError(t, err)
+	}
+	_, err := writer.Write(body)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestClientDecompressesResponses(t *testing.T) {
+	tests := []struct {
+		name            string
+		contentEncoding string
+		compression     string
+	}{
+		{name: "gzip", contentEncoding: "gzip", compression: "gzip"},
+		{name: "zlib deflate", contentEncoding: "deflate", compression: "deflate"},
+		{name: "raw deflate", contentEncoding: "deflate", compression: "raw-deflate"},
+		{name: "identity", contentEncoding: "", compression: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := []byte(largeList)
+			if tt.compression != "" {
+				body = compress(t, tt.compression, body)
+			}
+			var acceptEncoding string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				acceptEncoding = r.Header.Get("Accept-Encoding")
+				if tt.contentEncoding != "" {
+					w.Header().Set("Content-Encoding", tt.contentEncoding)
+				}
+				_, _ = w.Write(body)
+			}))
+			defer server.Close()
+
+			// A transport without automatic decompression, as any custom transport
+			// once Accept-Encoding is set
+			httpClient := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+			client, err := NewClient(testLog(), WithBaseURL(server.URL), WithHTTPClient(httpClient))
+			require.NoError(t, err)
+			resp, err := client.Get(context.Background(), "/clusters")
+			require.NoError(t, err)
+
+			assert.Equal(t, "gzip, deflate", acceptEncoding)
+			assert.True(t, json.Valid(resp.Body), "body: %q", resp.Body)
+			assert.Equal(t, largeList, string(resp.Body))
+			assert.Equal(t, len(body), resp.WireBytes)
+			assert.Empty(t, http.Header(resp.Headers).Get("Content-Encoding"))
+		})
+	}
+}
+
+func TestClientCorruptCompressedResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write([]byte(largeList))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(testLog(), WithBaseURL(server.URL), WithRetryAttempts(1))
+	require.NoError(t, err)
+	_, err = client.Get(context.Background(), "/clusters")
+	require.ErrorContains(t, err, "failed to decompress gzip response body")
+}
+
+// compressionRequiredServer rejects uncompressed request bodies with 415,
+// advertising gzip, and records the bodies it accepts
+func compressionRequiredServer(t *testing.T, accepted *[]string, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		w.Header().Set("Accept-Encoding", "gzip")
+		if r.Method == http.MethodGet {
+			return
+		}
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		reader, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		*accepted = append(*accepted, string(body))
+		w.WriteHeader(http.StatusCreated)
+	}))
+}
+
+func TestClientCompressesRequests(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("after the server advertised gzip", func(t *testing.T) {
+		var accepted []string
+		var requests int
+		server := compressionRequiredServer(t, &accepted, &requests)
+		defer server.Close()
+		client, err := NewClient(testLog(), WithBaseURL(server.URL), WithCompressRequests(true))
+		require.NoError(t, err)
+
+		_, err = client.Get(ctx, "/clusters")
+		require.NoError(t, err)
+		resp, err := client.Post(ctx, "/statuses", []byte(largeList))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, []string{largeList}, accepted)
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("sent again compressed when rejected", func(t *testing.T) {
+		var accepted []string
+		var requests int
+		server := compressionRequiredServer(t, &accepted, &requests)
+		defer server.Close()
+		client, err := NewClient(testLog(), WithBaseURL(server.URL), WithCompressRequests(true))
+		require.NoError(t, err)
+
+		resp, err := client.Post(ctx, "/statuses", []byte(largeList))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, 1, resp.Attempts)
+		assert.Equal(t, []string{largeList}, accepted)
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("small bodies are not compressed", func(t *testing.T) {
+		var accepted []string
+		var requests int
+		server := compressionRequiredServer(t, &accepted, &requests)
+		defer server.Close()
+		client, err := NewClient(testLog(), WithBaseURL(server.URL), WithCompressRequests(true))
+		require.NoError(t, err)
+
+		resp, err := client.Post(ctx, "/statuses", []byte(`{"ok":true}`))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+		assert.Empty(t, accepted)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		var accepted []string
+		var requests int
+		server := compressionRequiredServer(t, &accepted, &requests)
+		defer server.Close()
+		client, err := NewClient(testLog(), WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		_, err = client.Get(ctx, "/clusters")
+		require.NoError(t, err)
+		resp, err := client.Post(ctx, "/statuses", []byte(largeList))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+		assert.Empty(t, accepted)
+	})
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := map[string]bool{
+		"":                  false,
+		"gzip":              true,
+		"br, GZIP":          true,
+		"gzip;q=0":          false,
+		"gzip; q=0.5":       true,
+		"identity, deflate": false,
+	}
+	for value, expected := range tests {
+		header := http.Header{}
+		if value != "" {
+			header.Set("Accept-Encoding", value)
+		}
+		assert.Equal(t, expected, acceptsGzip(header), "Accept-Encoding: %q", value)
+	}
+}
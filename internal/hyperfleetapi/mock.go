@@ -2,6 +2,7 @@ package hyperfleetapi
 
 import (
 	"context"
+	"sort"
 )
 
 // MockClient implements Client for testing.
@@ -34,6 +35,10 @@ type MockClient struct {
 	DeleteResponse *Response
 	DeleteError    error
 
+	// Targets maps API target names to their mock clients. Target returns an
+	// UnknownTargetError for names that are neither in Targets nor the default.
+	Targets map[string]*MockClient
+
 	// Requests records all requests made to this mock for verification
 	Requests []*Request
 }
@@ -69,6 +74,7 @@ func (m *MockClient) Do(ctx context.Context, req *Request) (*Response, error) {
 // Get implements Client.Get
 func (m *MockClient) Get(ctx context.Context, url string, opts ...RequestOption) (*Response, error) {
 	req := &Request{Method: "GET", URL: url}
+	applyRequestOptions(req, opts)
 	m.Requests = append(m.Requests, req)
 	if m.GetError != nil {
 		return nil, m.GetError
@@ -79,6 +85,7 @@ func (m *MockClient) Get(ctx context.Context, url string, opts ...RequestOption)
 // Post implements Client.Post
 func (m *MockClient) Post(ctx context.Context, url string, body []byte, opts ...RequestOption) (*Response, error) {
 	req := &Request{Method: "POST", URL: url, Body: body}
+	applyRequestOptions(req, opts)
 	m.Requests = append(m.Requests, req)
 	if m.PostError != nil {
 		return nil, m.PostError
@@ -89,6 +96,7 @@ func (m *MockClient) Post(ctx context.Context, url string, body []byte, opts ...
 // Put implements Client.Put
 func (m *MockClient) Put(ctx context.Context, url string, body []byte, opts ...RequestOption) (*Response, error) {
 	req := &Request{Method: "PUT", URL: url, Body: body}
+	applyRequestOptions(req, opts)
 	m.Requests = append(m.Requests, req)
 	if m.PutError != nil {
 		return nil, m.PutError
@@ -99,6 +107,7 @@ func (m *MockClient) Put(ctx context.Context, url string, body []byte, opts ...R
 // Patch implements Client.Patch
 func (m *MockClient) Patch(ctx context.Context, url string, body []byte, opts ...RequestOption) (*Response, error) {
 	req := &Request{Method: "PATCH", URL: url, Body: body}
+	applyRequestOptions(req, opts)
 	m.Requests = append(m.Requests, req)
 	if m.PatchError != nil {
 		return nil, m.PatchError
@@ -109,6 +118,7 @@ func (m *MockClient) Patch(ctx context.Context, url string, body []byte, opts ..
 // Delete implements Client.Delete
 func (m *MockClient) Delete(ctx context.Context, url string, opts ...RequestOption) (*Response, error) {
 	req := &Request{Method: "DELETE", URL: url}
+	applyRequestOptions(req, opts)
 	m.Requests = append(m.Requests, req)
 	if m.DeleteError != nil {
 		return nil, m.DeleteError
@@ -116,11 +126,37 @@ func (m *MockClient) Delete(ctx context.Context, url string, opts ...RequestOpti
 	return m.DeleteResponse, nil
 }
 
+// applyRequestOptions applies opts to a recorded request, so tests can verify
+// the headers of a call. The request body is kept.
+func applyRequestOptions(req *Request, opts []RequestOption) {
+	body := req.Body
+	for _, opt := range opts {
+		opt(req)
+	}
+	req.Body = body
+}
+
 // BaseURL implements Client.BaseURL
 func (m *MockClient) BaseURL() string {
 	return m.BaseURLValue
 }
 
+// Target implements Client.Target
+func (m *MockClient) Target(name string) (Client, error) {
+	if name == DefaultTarget {
+		return m, nil
+	}
+	if target, ok := m.Targets[name]; ok {
+		return target, nil
+	}
+	names := make([]string, 0, len(m.Targets))
+	for targetName := range m.Targets {
+		names = append(names, targetName)
+	}
+	sort.Strings(names)
+	return nil, &UnknownTargetError{Name: name, Valid: append([]string{DefaultTarget}, names...)}
+}
+
 // Reset clears all recorded requests
 func (m *MockClient) Reset() {
 	m.Requests = make([]*Request, 0)
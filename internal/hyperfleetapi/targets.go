@@ -0,0 +1,130 @@
+package hyperfleetapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DefaultTarget is the name of the API target configured by the client's own
+// base URL and default headers
+const DefaultTarget = "default"
+
+// UnknownTargetError is returned for an API target name that is not configured.
+// It is permanent: retrying the call cannot succeed.
+type UnknownTargetError struct {
+	// Name is the requested target name
+	Name string
+	// Valid lists the configured target names, DefaultTarget first
+	Valid []string
+}
+
+// Error implements the error interface
+func (e *UnknownTargetError) Error() string {
+	return fmt.Sprintf("unknown HyperFleet API target %q (valid targets: %s)", e.Name, strings.Join(e.Valid, ", "))
+}
+
+// IsUnknownTargetError checks if an error is an UnknownTargetError and returns it.
+// This function supports wrapped errors via errors.As.
+func IsUnknownTargetError(err error) (*UnknownTargetError, bool) {
+	var targetErr *UnknownTargetError
+	if errors.As(err, &targetErr) {
+		return targetErr, true
+	}
+	return nil, false
+}
+
+// TargetNames returns DefaultTarget followed by the names of targets
+func TargetNames(targets []TargetConfig) []string {
+	names := make([]string, 0, len(targets)+1)
+	names = append(names, DefaultTarget)
+	for _, target := range targets {
+		names = append(names, target.Name)
+	}
+	return names
+}
+
+// targetSet holds the clients of the configured API targets. It is shared by
+// the default client and the target clients derived from it; target clients
+// are built on first use.
+type targetSet struct {
+	root    *httpClient
+	configs map[string]TargetConfig
+	clients map[string]*httpClient
+	names   []string
+	mu      sync.Mutex
+}
+
+func newTargetSet(root *httpClient, targets []TargetConfig) *targetSet {
+	set := &targetSet{
+		root:    root,
+		configs: make(map[string]TargetConfig, len(targets)),
+		clients: make(map[string]*httpClient, len(targets)),
+		names:   TargetNames(targets),
+	}
+	for _, target := range targets {
+		set.configs[target.Name] = target
+	}
+	return set
+}
+
+// client returns the client of the named target, building it on first use
+func (s *targetSet) client(name string) (*httpClient, error) {
+	if name == DefaultTarget {
+		return s.root, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if client, ok := s.clients[name]; ok {
+		return client, nil
+	}
+	target, ok := s.configs[name]
+	if !ok {
+		return nil, &UnknownTargetError{Name: name, Valid: s.names}
+	}
+	client, err := s.root.forTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	s.clients[name] = client
+	return client, nil
+}
+
+// forTarget derives the client of target from c. The derived client shares the
+// retry settings, clock and logger of c, and its HTTP client unless the target
+// has TLS settings: it then gets an HTTP client with the same timeout over the
+// pool transport of its TLS settings.
+func (c *httpClient) forTarget(target TargetConfig) (*httpClient, error) {
+	config := *c.config
+	config.BaseURL = target.BaseURL
+	config.Targets = nil
+	config.DefaultHeaders = make(map[string]string, len(c.config.DefaultHeaders)+len(target.DefaultHeaders))
+	for key, value := range c.config.DefaultHeaders {
+		config.DefaultHeaders[key] = value
+	}
+	for key, value := range target.DefaultHeaders {
+		config.DefaultHeaders[key] = value
+	}
+	registerSecretHeaders(target.DefaultHeaders)
+
+	client := c.client
+	if target.TLS != nil {
+		transport, err := c.poolTransport(target.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS settings of HyperFleet API target %q: %w", target.Name, err)
+		}
+		client = &http.Client{Timeout: c.client.Timeout, Transport: transport}
+	}
+
+	return &httpClient{
+		client:        client,
+		config:        &config,
+		log:           c.log,
+		clock:         c.clock,
+		targets:       c.targets,
+		pool:          c.pool,
+		wrapTransport: c.wrapTransport,
+	}, nil
+}
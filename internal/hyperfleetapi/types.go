@@ -3,6 +3,8 @@ package hyperfleetapi
 import (
 	"context"
 	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/httpx"
 )
 
 // -----------------------------------------------------------------------------
@@ -28,6 +30,8 @@ const (
 	DefaultRetryBackoff  = BackoffExponential
 	DefaultBaseDelay     = 1 * time.Second
 	DefaultMaxDelay      = 30 * time.Second
+	// DefaultMaxRetainedResponseBytes caps the response bodies kept in execution results
+	DefaultMaxRetainedResponseBytes = 64 * 1024
 )
 
 // -----------------------------------------------------------------------------
@@ -44,15 +48,52 @@ type ClientConfig struct {
 	// Version is the HyperFleet API version (e.g., "v1")
 	Version string `yaml:"version,omitempty" mapstructure:"version"`
 	// RetryBackoff is the backoff strategy for retries
-	RetryBackoff BackoffStrategy `yaml:"retry_backoff,omitempty" mapstructure:"retry_backoff"`
+	RetryBackoff BackoffStrategy `yaml:"retry_backoff,omitempty" mapstructure:"retry_backoff" jsonschema:"enum=exponential linear constant"`
 	// Timeout is the HTTP client timeout for requests
 	Timeout time.Duration `yaml:"timeout,omitempty" mapstructure:"timeout"`
 	// BaseDelay is the initial delay for retry backoff
 	BaseDelay time.Duration `yaml:"base_delay,omitempty" mapstructure:"base_delay"`
 	// MaxDelay is the maximum delay for retry backoff
 	MaxDelay time.Duration `yaml:"max_delay,omitempty" mapstructure:"max_delay"`
+	// Transport tunes the connection pool shared by this client, its targets and
+	// the webhook client. Nil uses the defaults.
+	Transport *httpx.TransportConfig `yaml:"transport,omitempty" mapstructure:"transport"`
+	// Targets are additional named API endpoints, e.g. one per tenant, selected per
+	// API call. Their clients share the timeouts and retry settings, and the
+	// transport unless their TLS settings differ.
+	Targets []TargetConfig `yaml:"targets,omitempty" mapstructure:"targets" validate:"unique=Name,dive"`
 	// RetryAttempts is the number of retry attempts for failed requests
 	RetryAttempts int `yaml:"retry_attempts,omitempty" mapstructure:"retry_attempts"`
+	// CompressRequests sends request bodies of 1KiB or more gzip-compressed once
+	// the server advertises gzip request bodies in an Accept-Encoding response header
+	CompressRequests bool `yaml:"compress_requests,omitempty" mapstructure:"compress_requests"`
+	// MaxRetainedResponseBytes caps the response body kept in precondition and
+	// post action results; longer bodies are truncated. Zero uses
+	// DefaultMaxRetainedResponseBytes.
+	//nolint:lll
+	MaxRetainedResponseBytes int `yaml:"max_retained_response_bytes,omitempty" mapstructure:"max_retained_response_bytes" validate:"gte=0"`
+}
+
+// RetainedResponseLimit returns MaxRetainedResponseBytes, or the default when unset
+func (c *ClientConfig) RetainedResponseLimit() int {
+	if c.MaxRetainedResponseBytes > 0 {
+		return c.MaxRetainedResponseBytes
+	}
+	return DefaultMaxRetainedResponseBytes
+}
+
+// TargetConfig is a named API endpoint with its own credentials
+type TargetConfig struct {
+	// DefaultHeaders are added to all requests to this target, over the client's
+	// default headers (e.g. a tenant-specific Authorization header)
+	DefaultHeaders map[string]string `yaml:"default_headers,omitempty" mapstructure:"default_headers"`
+	// Name selects the target in an API call's target field
+	Name string `yaml:"name" mapstructure:"name" validate:"required,ne=default"`
+	// BaseURL is the base URL of the target's API requests
+	BaseURL string `yaml:"base_url" mapstructure:"base_url" validate:"required"`
+	// TLS gives the target a transport of its own with these TLS settings, e.g.
+	// the CA of a tenant's private endpoint. Nil shares the default transport.
+	TLS *httpx.TLSConfig `yaml:"tls,omitempty" mapstructure:"tls"`
 }
 
 // DefaultClientConfig returns a ClientConfig with default values
@@ -86,6 +127,10 @@ type Request struct {
 	URL string
 	// Body is the request body (for POST, PUT, PATCH)
 	Body []byte
+	// FormFields are sent as an application/x-www-form-urlencoded body instead of Body
+	FormFields []FormField
+	// Parts are sent as a multipart/form-data body instead of Body
+	Parts []MultipartPart
 	// Timeout overrides the client timeout for this request
 	Timeout time.Duration
 }
@@ -129,7 +174,7 @@ func WithJSONBody(body []byte) RequestOption {
 		if r.Headers == nil {
 			r.Headers = make(map[string]string)
 		}
-		r.Headers["Content-Type"] = "application/json"
+		r.Headers["Content-Type"] = ContentTypeJSON
 	}
 }
 
@@ -164,7 +209,8 @@ type Response struct {
 	Headers map[string][]string
 	// Status is the HTTP status string (e.g., "200 OK")
 	Status string
-	// Body is the response body
+	// Body is the response body, decompressed when it was sent with a gzip or
+	// deflate content encoding
 	Body []byte
 	// Duration is how long the request took
 	Duration time.Duration
@@ -172,6 +218,8 @@ type Response struct {
 	StatusCode int
 	// Attempts is how many attempts were made (including retries)
 	Attempts int
+	// WireBytes is the size of the body as received, before decompression
+	WireBytes int
 }
 
 // IsSuccess returns true if the response status code is 2xx
@@ -237,4 +285,9 @@ type Client interface {
 
 	// BaseURL returns the configured base URL for API requests
 	BaseURL() string
+
+	// Target returns the client of the named API target; DefaultTarget is the
+	// client's own endpoint. Other names, including an empty one, fail with an
+	// *UnknownTargetError unless they are configured targets.
+	Target(name string) (Client, error)
 }
@@ -0,0 +1,95 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// configMapKeyPattern matches the keys usable as ConfigMap data keys as they are
+var configMapKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]{1,200}$`)
+
+// ConfigMapStore keeps the generations in a ConfigMap, one data key per key,
+// so they survive restarts. Reads and writes go to the apiserver, the writes
+// with optimistic concurrency, so replicas sharing the ConfigMap see the
+// generations recorded by each other.
+type ConfigMapStore struct {
+	client    k8sclient.K8sClient
+	log       logger.Logger
+	namespace string
+	name      string
+	maxKeys   int
+}
+
+var _ Store = (*ConfigMapStore)(nil)
+
+// NewConfigMapStore creates a store in the ConfigMap namespace/name, created on
+// the first Record, of at most maxKeys keys (zero uses DefaultMaxKeys)
+func NewConfigMapStore(
+	client k8sclient.K8sClient, namespace, name string, maxKeys int, log logger.Logger,
+) (*ConfigMapStore, error) {
+	if client == nil {
+		return nil, fmt.Errorf("kubernetes client is required for the configmap idempotency store")
+	}
+	if namespace == "" || name == "" {
+		return nil, fmt.Errorf("configmap idempotency store requires both name and namespace")
+	}
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+	return &ConfigMapStore{client: client, log: log, namespace: namespace, name: name, maxKeys: maxKeys}, nil
+}
+
+// Get implements Store.Get. A corrupt value is logged and reads as no generation.
+func (s *ConfigMapStore) Get(ctx context.Context, key string) (int64, bool, error) {
+	obj, err := k8sclient.GetConfigMap(ctx, s.client, s.namespace, s.name)
+	if err != nil || obj == nil {
+		return 0, false, err
+	}
+	raw, found, _ := unstructured.NestedString(obj.Object, "data", dataKey(key))
+	if !found {
+		return 0, false, nil
+	}
+	generation, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		s.log.Warnf(logger.WithErrorField(ctx, err), "Ignoring corrupt generation of key %q in ConfigMap %s/%s",
+			key, s.namespace, s.name)
+		return 0, false, nil
+	}
+	return generation, true, nil
+}
+
+// Record implements Store.Record
+func (s *ConfigMapStore) Record(ctx context.Context, key string, generation int64) error {
+	key = dataKey(key)
+	return k8sclient.UpdateConfigMapData(ctx, s.client, s.namespace, s.name,
+		func(data map[string]string, _ bool) (bool, error) {
+			raw, recorded := data[key]
+			if recorded {
+				if current, err := strconv.ParseInt(raw, 10, 64); err == nil && current >= generation {
+					return false, nil
+				}
+			} else if len(data) >= s.maxKeys {
+				return false, fmt.Errorf("%w: %d keys", ErrFull, len(data))
+			}
+			data[key] = strconv.FormatInt(generation, 10)
+			return true, nil
+		})
+}
+
+// dataKey returns the ConfigMap data key of key: key itself when it is a valid
+// data key, otherwise a hash of it
+func dataKey(key string) string {
+	if configMapKeyPattern.MatchString(key) {
+		return key
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "sha256-" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,67 @@
+// Package idempotency remembers the last generation successfully processed for
+// each key, e.g. per cluster, so the executor can skip the events that arrive
+// out of order with an older generation than one already applied.
+package idempotency
+
+import (
+	"context"
+	"errors"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/lru"
+)
+
+// DefaultMaxKeys is the number of keys a store remembers when unset
+const DefaultMaxKeys = 10000
+
+// ErrFull is returned by Record when a persisted store holds its maximum
+// number of keys and the key is not one of them
+var ErrFull = errors.New("idempotency store is full")
+
+// Store keeps the last processed generation of each key. Generations only move
+// forward: recording a generation lower than the recorded one keeps the
+// recorded one, so concurrent executions cannot move a key back.
+type Store interface {
+	// Get returns the generation recorded for key, false when none is
+	Get(ctx context.Context, key string) (int64, bool, error)
+	// Record records generation for key unless a higher one is recorded
+	Record(ctx context.Context, key string, generation int64) error
+}
+
+// MemoryStore keeps the generations in memory, lost on restart. The least
+// recently recorded keys are forgotten beyond the maximum number of keys.
+type MemoryStore struct {
+	generations *lru.Cache[string, int64]
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty in-memory store of at most maxKeys keys
+// (zero uses DefaultMaxKeys)
+func NewMemoryStore(maxKeys int) *MemoryStore {
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+	return &MemoryStore{generations: lru.New[string, int64](maxKeys)}
+}
+
+// Get implements Store.Get
+func (s *MemoryStore) Get(_ context.Context, key string) (int64, bool, error) {
+	generation, ok := s.generations.Peek(key)
+	return generation, ok, nil
+}
+
+// Record implements Store.Record
+func (s *MemoryStore) Record(_ context.Context, key string, generation int64) error {
+	s.generations.Update(key, func(recorded int64, found bool) int64 {
+		if found {
+			return max(recorded, generation)
+		}
+		return generation
+	})
+	return nil
+}
+
+// Len returns the number of keys remembered
+func (s *MemoryStore) Len() int {
+	return s.generations.Len()
+}
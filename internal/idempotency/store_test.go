@@ -0,0 +1,83 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stores returns the stores under test, each opened twice on the same backing
+// storage to check what survives a restart; the memory store starts empty
+func stores(t *testing.T, maxKeys int) map[string]func() Store {
+	client := k8sclient.NewMockK8sClient()
+	return map[string]func() Store{
+		"memory": func() Store { return NewMemoryStore(maxKeys) },
+		"configmap": func() Store {
+			store, err := NewConfigMapStore(client, "hyperfleet", "adapter-generations", maxKeys, logger.NewTestLogger())
+			require.NoError(t, err)
+			return store
+		},
+	}
+}
+
+func TestStore_GenerationsOnlyMoveForward(t *testing.T) {
+	ctx := context.Background()
+	for name, open := range stores(t, 0) {
+		t.Run(name, func(t *testing.T) {
+			store := open()
+			_, found, err := store.Get(ctx, "cluster-1")
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			require.NoError(t, store.Record(ctx, "cluster-1", 3))
+			require.NoError(t, store.Record(ctx, "cluster-1", 2), "a lower generation keeps the recorded one")
+			require.NoError(t, store.Record(ctx, "cluster/2 with spaces", 7))
+
+			generation, found, err := store.Get(ctx, "cluster-1")
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, int64(3), generation)
+			generation, _, err = store.Get(ctx, "cluster/2 with spaces")
+			require.NoError(t, err)
+			assert.Equal(t, int64(7), generation, "keys that are not data keys are hashed")
+
+			require.NoError(t, store.Record(ctx, "cluster-1", 4))
+			generation, _, err = store.Get(ctx, "cluster-1")
+			require.NoError(t, err)
+			assert.Equal(t, int64(4), generation)
+		})
+	}
+}
+
+func TestConfigMapStore_SurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	open := stores(t, 0)["configmap"]
+	require.NoError(t, open().Record(ctx, "cluster-1", 5))
+
+	generation, found, err := open().Get(ctx, "cluster-1")
+	require.NoError(t, err)
+	assert.True(t, found, "a restarted adapter finds the recorded generations")
+	assert.Equal(t, int64(5), generation)
+}
+
+func TestStore_MaxKeys(t *testing.T) {
+	ctx := context.Background()
+
+	memory := NewMemoryStore(2)
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, memory.Record(ctx, key, 1))
+	}
+	assert.Equal(t, 2, memory.Len())
+	_, found, _ := memory.Get(ctx, "a")
+	assert.False(t, found, "the least recently recorded key is forgotten")
+
+	configMap := stores(t, 2)["configmap"]()
+	require.NoError(t, configMap.Record(ctx, "a", 1))
+	require.NoError(t, configMap.Record(ctx, "b", 1))
+	require.ErrorIs(t, configMap.Record(ctx, "c", 1), ErrFull)
+	require.NoError(t, configMap.Record(ctx, "a", 2), "recorded keys are still updated")
+}
@@ -0,0 +1,110 @@
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// configMapMaxDataBytes bounds the data of the journal ConfigMap, below the
+// 1MiB limit of a Kubernetes object to leave room for its metadata
+const configMapMaxDataBytes = 1000 * 1024
+
+// ConfigMapStore keeps the entries in a ConfigMap, one data key per entry.
+// Every write goes to the apiserver with optimistic concurrency, so an entry
+// is stored once Put returns. Each adapter instance needs its own ConfigMap:
+// an instance recovers every entry it finds.
+type ConfigMapStore struct {
+	client    k8sclient.K8sClient
+	log       logger.Logger
+	namespace string
+	name      string
+	limits    Limits
+}
+
+var _ Store = (*ConfigMapStore)(nil)
+
+// NewConfigMapStore creates a store in the ConfigMap namespace/name, created on
+// the first Put
+func NewConfigMapStore(
+	client k8sclient.K8sClient, namespace, name string, limits Limits, log logger.Logger,
+) (*ConfigMapStore, error) {
+	if client == nil {
+		return nil, fmt.Errorf("kubernetes client is required for the configmap journal store")
+	}
+	if namespace == "" || name == "" {
+		return nil, fmt.Errorf("configmap journal store requires both name and namespace")
+	}
+	return &ConfigMapStore{
+		client: client, log: log, namespace: namespace, name: name, limits: limits.withDefaults(),
+	}, nil
+}
+
+// Put implements Store.Put
+func (s *ConfigMapStore) Put(ctx context.Context, entry Entry) error {
+	data, err := s.limits.encode(entry)
+	if err != nil {
+		return err
+	}
+	return k8sclient.UpdateConfigMapData(ctx, s.client, s.namespace, s.name,
+		func(entries map[string]string, _ bool) (bool, error) {
+			if _, ok := entries[entry.ID]; !ok && len(entries) >= s.limits.MaxEntries {
+				return false, fmt.Errorf("%w: %d entries", ErrFull, len(entries))
+			}
+			size := len(data)
+			for id, raw := range entries {
+				if id != entry.ID {
+					size += len(raw)
+				}
+			}
+			if size > configMapMaxDataBytes {
+				return false, fmt.Errorf("%w: ConfigMap data would be %d bytes", ErrFull, size)
+			}
+			entries[entry.ID] = string(data)
+			return true, nil
+		})
+}
+
+// Delete implements Store.Delete
+func (s *ConfigMapStore) Delete(ctx context.Context, id string) error {
+	return k8sclient.UpdateConfigMapData(ctx, s.client, s.namespace, s.name,
+		func(entries map[string]string, _ bool) (bool, error) {
+			if _, ok := entries[id]; !ok {
+				return false, nil
+			}
+			delete(entries, id)
+			return true, nil
+		})
+}
+
+// List implements Store.List. Entries that cannot be decoded are logged and
+// skipped.
+func (s *ConfigMapStore) List(ctx context.Context) ([]Entry, error) {
+	obj, err := k8sclient.GetConfigMap(ctx, s.client, s.namespace, s.name)
+	if err != nil || obj == nil {
+		return nil, err
+	}
+	data, _, _ := unstructured.NestedStringMap(obj.Object, "data")
+	entries := make([]Entry, 0, len(data))
+	for id, raw := range data {
+		var entry Entry
+		if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+			s.log.Warnf(logger.WithErrorField(ctx, err), "Skipping corrupt journal entry %s in ConfigMap %s/%s",
+				id, s.namespace, s.name)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].RecordedAt.Equal(entries[j].RecordedAt) {
+			return entries[i].ID < entries[j].ID
+		}
+		return entries[i].RecordedAt.Before(entries[j].RecordedAt)
+	})
+	return entries, nil
+}
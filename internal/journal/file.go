@@ -0,0 +1,161 @@
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// entryFileSuffix is the suffix of the entry files in the journal directory
+const entryFileSuffix = ".json"
+
+// FileStore keeps one file per entry in a directory, e.g. on a volume that
+// survives pod restarts. An entry is written to a temporary file that is
+// synced and renamed over the entry file, and the directory is synced after
+// every change, so a crash leaves either the old or the new entry.
+type FileStore struct {
+	log    logger.Logger
+	dir    string
+	limits Limits
+	mu     sync.Mutex
+}
+
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore creates a store in dir, creating the directory if needed
+func NewFileStore(dir string, limits Limits, log logger.Logger) (*FileStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("journal directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	return &FileStore{log: log, dir: dir, limits: limits.withDefaults()}, nil
+}
+
+// Put implements Store.Put
+func (s *FileStore) Put(_ context.Context, entry Entry) error {
+	data, err := s.limits.encode(entry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.entryPath(entry.ID)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		names, err := s.entryNames()
+		if err != nil {
+			return err
+		}
+		if len(names) >= s.limits.MaxEntries {
+			return fmt.Errorf("%w: %d entries", ErrFull, len(names))
+		}
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".entry-*")
+	if err != nil {
+		return fmt.Errorf("failed to create journal entry file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // gone once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck // already failing
+		return fmt.Errorf("failed to write journal entry %s: %w", entry.ID, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close() //nolint:errcheck // already failing
+		return fmt.Errorf("failed to sync journal entry %s: %w", entry.ID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write journal entry %s: %w", entry.ID, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write journal entry %s: %w", entry.ID, err)
+	}
+	return s.syncDir()
+}
+
+// Delete implements Store.Delete
+func (s *FileStore) Delete(_ context.Context, id string) error {
+	if !idPattern.MatchString(id) {
+		return fmt.Errorf("invalid journal entry ID %q", id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.entryPath(id)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete journal entry %s: %w", id, err)
+	}
+	return s.syncDir()
+}
+
+// List implements Store.List. Entry files that cannot be decoded are logged
+// and skipped.
+func (s *FileStore) List(ctx context.Context) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names, err := s.entryNames()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(s.dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read journal entry %s: %w", name, err)
+		}
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			s.log.Warnf(logger.WithErrorField(ctx, err), "Skipping corrupt journal entry %s", name)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].RecordedAt.Before(entries[j].RecordedAt)
+	})
+	return entries, nil
+}
+
+func (s *FileStore) entryPath(id string) string {
+	return filepath.Join(s.dir, id+entryFileSuffix)
+}
+
+// entryNames returns the names of the entry files
+func (s *FileStore) entryNames() ([]string, error) {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal directory: %w", err)
+	}
+	var names []string
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if dirEntry.Type().IsRegular() && !strings.HasPrefix(name, ".") && strings.HasSuffix(name, entryFileSuffix) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// syncDir makes the renames and removals in the directory durable
+func (s *FileStore) syncDir() error {
+	dir, err := os.Open(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to sync journal directory: %w", err)
+	}
+	defer dir.Close() //nolint:errcheck // read-only
+	if err := dir.Sync(); err != nil {
+		return fmt.Errorf("failed to sync journal directory: %w", err)
+	}
+	return nil
+}
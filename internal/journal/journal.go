@@ -0,0 +1,83 @@
+// Package journal persists the executions whose resources were applied but
+// whose post actions have not run yet, so an adapter restarted after a crash
+// can complete them. Entries are opaque to the stores: the executor encodes
+// what the post actions need.
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Defaults of the journal limits
+const (
+	DefaultMaxEntries    = 100
+	DefaultMaxEntryBytes = 256 * 1024
+)
+
+var (
+	// ErrFull is returned by Put when the journal holds MaxEntries entries
+	ErrFull = errors.New("journal is full")
+	// ErrEntryTooLarge is returned by Put for an entry over MaxEntryBytes
+	ErrEntryTooLarge = errors.New("journal entry is too large")
+)
+
+// idPattern matches the IDs usable as file names and ConfigMap keys
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Entry is a journaled execution
+type Entry struct {
+	RecordedAt time.Time `json:"recorded_at"`
+	ID         string    `json:"id"`
+	// Data is what the post actions of the execution need, encoded by the executor
+	Data json.RawMessage `json:"data"`
+}
+
+// Store persists journal entries. Put and Delete are durable when they return.
+type Store interface {
+	// Put writes the entry, replacing the entry with the same ID
+	Put(ctx context.Context, entry Entry) error
+	// Delete removes the entry with the ID, if any
+	Delete(ctx context.Context, id string) error
+	// List returns the entries, oldest first
+	List(ctx context.Context) ([]Entry, error)
+}
+
+// Limits bounds the size of a journal
+type Limits struct {
+	// MaxEntries is the number of entries kept. Zero uses the default (100).
+	MaxEntries int
+	// MaxEntryBytes is the largest encoded entry. Zero uses the default (256KiB).
+	MaxEntryBytes int
+}
+
+// withDefaults returns the limits with the zero ones set to their default
+func (l Limits) withDefaults() Limits {
+	if l.MaxEntries <= 0 {
+		l.MaxEntries = DefaultMaxEntries
+	}
+	if l.MaxEntryBytes <= 0 {
+		l.MaxEntryBytes = DefaultMaxEntryBytes
+	}
+	return l
+}
+
+// encode returns the JSON of an entry within the limits
+func (l Limits) encode(entry Entry) ([]byte, error) {
+	if !idPattern.MatchString(entry.ID) {
+		return nil, fmt.Errorf("invalid journal entry ID %q", entry.ID)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode journal entry %s: %w", entry.ID, err)
+	}
+	if len(data) > l.MaxEntryBytes {
+		return nil, fmt.Errorf("%w: entry %s is %d bytes, over the %d byte limit",
+			ErrEntryTooLarge, entry.ID, len(data), l.MaxEntryBytes)
+	}
+	return data, nil
+}
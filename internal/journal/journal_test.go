@@ -0,0 +1,125 @@
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var recordedAt = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+func testEntry(id string, offset time.Duration) Entry {
+	return Entry{ID: id, RecordedAt: recordedAt.Add(offset), Data: json.RawMessage(`{"clusterId":"` + id + `"}`)}
+}
+
+// stores returns the stores under test, each opened twice on the same
+// backing storage to check what survives a restart
+func stores(t *testing.T, limits Limits) map[string]func() Store {
+	dir := t.TempDir()
+	client := k8sclient.NewMockK8sClient()
+	return map[string]func() Store{
+		"file": func() Store {
+			store, err := NewFileStore(dir, limits, logger.NewTestLogger())
+			require.NoError(t, err)
+			return store
+		},
+		"configmap": func() Store {
+			store, err := NewConfigMapStore(client, "hyperfleet", "adapter-journal", limits, logger.NewTestLogger())
+			require.NoError(t, err)
+			return store
+		},
+	}
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	for name, open := range stores(t, Limits{}) {
+		t.Run(name, func(t *testing.T) {
+			store := open()
+			entries, err := store.List(ctx)
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+
+			require.NoError(t, store.Put(ctx, testEntry("exec-2", time.Second)))
+			require.NoError(t, store.Put(ctx, testEntry("exec-1", 0)))
+			replaced := testEntry("exec-2", time.Second)
+			replaced.Data = json.RawMessage(`{"clusterId":"replaced"}`)
+			require.NoError(t, store.Put(ctx, replaced))
+
+			// A restarted adapter finds the entries, oldest first
+			entries, err = open().List(ctx)
+			require.NoError(t, err)
+			require.Len(t, entries, 2)
+			assert.Equal(t, "exec-1", entries[0].ID)
+			assert.True(t, recordedAt.Equal(entries[0].RecordedAt))
+			assert.JSONEq(t, `{"clusterId":"replaced"}`, string(entries[1].Data))
+
+			require.NoError(t, store.Delete(ctx, "exec-1"))
+			require.NoError(t, store.Delete(ctx, "exec-1"))
+			entries, err = open().List(ctx)
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			assert.Equal(t, "exec-2", entries[0].ID)
+		})
+	}
+}
+
+func TestStore_Limits(t *testing.T) {
+	ctx := context.Background()
+	for name, open := range stores(t, Limits{MaxEntries: 2, MaxEntryBytes: 200}) {
+		t.Run(name, func(t *testing.T) {
+			store := open()
+			require.NoError(t, store.Put(ctx, testEntry("exec-1", 0)))
+			require.NoError(t, store.Put(ctx, testEntry("exec-2", 0)))
+			// Replacing an entry of a full journal is allowed
+			require.NoError(t, store.Put(ctx, testEntry("exec-2", time.Second)))
+
+			err := store.Put(ctx, testEntry("exec-3", 0))
+			require.ErrorIs(t, err, ErrFull)
+
+			large := testEntry("exec-1", 0)
+			large.Data = json.RawMessage(`"` + strings.Repeat("x", 200) + `"`)
+			require.ErrorIs(t, store.Put(ctx, large), ErrEntryTooLarge)
+
+			assert.ErrorContains(t, store.Put(ctx, testEntry("../escape", 0)), "invalid journal entry ID")
+
+			entries, err := store.List(ctx)
+			require.NoError(t, err)
+			assert.Len(t, entries, 2)
+		})
+	}
+}
+
+func TestFileStore_SkipsTemporaryAndCorruptFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFileStore(dir, Limits{}, logger.NewTestLogger())
+	require.NoError(t, err)
+	require.NoError(t, store.Put(ctx, testEntry("exec-1", 0)))
+
+	// A crash while writing leaves a temporary file, never a partial entry file
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".entry-123"), []byte(`{"id":`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "corrupt.json"), []byte(`{"id":`), 0o600))
+
+	entries, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "exec-1", entries[0].ID)
+}
+
+func TestConfigMapStore_DeleteWithoutConfigMap(t *testing.T) {
+	client := k8sclient.NewMockK8sClient()
+	store, err := NewConfigMapStore(client, "hyperfleet", "adapter-journal", Limits{}, logger.NewTestLogger())
+	require.NoError(t, err)
+	require.NoError(t, store.Delete(context.Background(), "exec-1"))
+	assert.Empty(t, client.Resources, "no ConfigMap is created to delete nothing")
+}
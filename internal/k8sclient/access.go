@@ -0,0 +1,183 @@
+package k8sclient
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	apperrors "github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/errors"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// AccessCheck is a verb on a resource kind the adapter needs RBAC permission for
+type AccessCheck struct {
+	GVK schema.GroupVersionKind
+	// Namespace is the namespace of the resources; empty checks all namespaces
+	Namespace string
+	Verb      string
+	// Subresource is the subresource the verb applies to, e.g. status
+	Subresource string
+}
+
+func (a AccessCheck) String() string {
+	scope := "in all namespaces"
+	if a.Namespace != "" {
+		scope = "in namespace " + a.Namespace
+	}
+	kind := a.GVK.Kind
+	if a.Subresource != "" {
+		kind += "/" + a.Subresource
+	}
+	return fmt.Sprintf("%s %s %s", a.Verb, kind, scope)
+}
+
+// DeniedAccess is an access check that failed
+type DeniedAccess struct {
+	// Resource is the resource the kind maps to, zero when Unserved
+	Resource schema.GroupVersionResource
+	AccessCheck
+	// ClusterScoped reports whether the kind is cluster-scoped
+	ClusterScoped bool
+	// Unserved reports that the API server does not serve the kind, which no
+	// permission grants
+	Unserved bool
+}
+
+func (d DeniedAccess) String() string {
+	if d.Unserved {
+		return fmt.Sprintf("%s: kind not served by the API server", d.AccessCheck)
+	}
+	return d.AccessCheck.String()
+}
+
+// AccessDeniedError lists every access check that failed
+type AccessDeniedError struct {
+	Denied []DeniedAccess
+}
+
+func (e *AccessDeniedError) Error() string {
+	denied := make([]string, 0, len(e.Denied))
+	for _, d := range e.Denied {
+		denied = append(denied, d.String())
+	}
+	return "missing permissions: " + strings.Join(denied, "; ")
+}
+
+// SuggestedRBAC returns the YAML of the roles granting the denied accesses: a
+// ClusterRole for cluster-scoped kinds and accesses in all namespaces, and a
+// Role per namespace, all named name. Unserved kinds are left out.
+func (e *AccessDeniedError) SuggestedRBAC(name string) (string, error) {
+	rules := make(map[string][]rbacv1.PolicyRule)
+	var namespaces []string
+	for _, d := range e.Denied {
+		if d.Unserved {
+			continue
+		}
+		namespace := d.Namespace
+		if d.ClusterScoped {
+			namespace = ""
+		}
+		if _, ok := rules[namespace]; !ok {
+			namespaces = append(namespaces, namespace)
+		}
+		rules[namespace] = addRule(rules[namespace], d)
+	}
+	slices.Sort(namespaces)
+
+	var docs []string
+	for _, namespace := range namespaces {
+		var role any
+		if namespace == "" {
+			role = &rbacv1.ClusterRole{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"},
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Rules:      rules[namespace],
+			}
+		} else {
+			role = &rbacv1.Role{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "Role"},
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Rules:      rules[namespace],
+			}
+		}
+		data, err := yaml.Marshal(role)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal suggested role: %w", err)
+		}
+		docs = append(docs, string(data))
+	}
+	return strings.Join(docs, "---\n"), nil
+}
+
+// addRule adds the verb of a denied access to the rule of its resource
+func addRule(rules []rbacv1.PolicyRule, d DeniedAccess) []rbacv1.PolicyRule {
+	resource := d.Resource.Resource
+	if d.Subresource != "" {
+		resource += "/" + d.Subresource
+	}
+	for i := range rules {
+		if rules[i].APIGroups[0] == d.Resource.Group && rules[i].Resources[0] == resource {
+			if !slices.Contains(rules[i].Verbs, d.Verb) {
+				rules[i].Verbs = append(rules[i].Verbs, d.Verb)
+			}
+			return rules
+		}
+	}
+	return append(rules, rbacv1.PolicyRule{
+		APIGroups: []string{d.Resource.Group},
+		Resources: []string{resource},
+		Verbs:     []string{d.Verb},
+	})
+}
+
+// CheckAccess reviews every access with a SelfSubjectAccessReview. Returns an
+// *AccessDeniedError listing the denied accesses and the kinds the API server
+// does not serve, or the error of a review that could not be made.
+func (c *Client) CheckAccess(ctx context.Context, checks []AccessCheck) error {
+	var denied []DeniedAccess
+	for _, check := range checks {
+		mapping, err := c.client.RESTMapper().RESTMapping(check.GVK.GroupKind(), check.GVK.Version)
+		if meta.IsNoMatchError(err) {
+			denied = append(denied, DeniedAccess{AccessCheck: check, Unserved: true})
+			continue
+		}
+		if err != nil {
+			return apperrors.KubernetesError("failed to map %s: %v", check.GVK, err)
+		}
+		clusterScoped := mapping.Scope.Name() == meta.RESTScopeNameRoot
+		namespace := check.Namespace
+		if clusterScoped {
+			namespace = ""
+		}
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   namespace,
+					Verb:        check.Verb,
+					Group:       mapping.Resource.Group,
+					Version:     mapping.Resource.Version,
+					Resource:    mapping.Resource.Resource,
+					Subresource: check.Subresource,
+				},
+			},
+		}
+		if err := c.client.Create(ctx, review); err != nil {
+			return apperrors.KubernetesError("access review for %s failed: %v", check, err)
+		}
+		if !review.Status.Allowed {
+			denied = append(denied, DeniedAccess{
+				AccessCheck: check, Resource: mapping.Resource, ClusterScoped: clusterScoped,
+			})
+		}
+	}
+	if len(denied) > 0 {
+		return &AccessDeniedError{Denied: denied}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package k8sclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// newAccessTestClient returns a client whose access reviews are answered by
+// respond, serving Namespaces, ConfigMaps and Jobs
+func newAccessTestClient(
+	respond func(attrs *authorizationv1.ResourceAttributes) (bool, error),
+) (*Client, *[]authorizationv1.ResourceAttributes) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(CommonResourceKinds.Namespace, meta.RESTScopeRoot)
+	mapper.Add(CommonResourceKinds.ConfigMap, meta.RESTScopeNamespace)
+	mapper.Add(CommonResourceKinds.Job, meta.RESTScopeNamespace)
+
+	var reviews []authorizationv1.ResourceAttributes
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(runtime.NewScheme()).
+		WithRESTMapper(mapper).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(_ context.Context, _ client.WithWatch, obj client.Object, _ ...client.CreateOption) error {
+				review, ok := obj.(*authorizationv1.SelfSubjectAccessReview)
+				if !ok {
+					return errors.New("unexpected object")
+				}
+				reviews = append(reviews, *review.Spec.ResourceAttributes)
+				allowed, err := respond(review.Spec.ResourceAttributes)
+				review.Status.Allowed = allowed
+				return err
+			},
+		}).
+		Build()
+	log, _ := logger.NewLogger(logger.Config{Level: "error", Output: "stdout", Format: "json"})
+	return &Client{client: fakeClient, log: log}, &reviews
+}
+
+func TestCheckAccess(t *testing.T) {
+	checks := []AccessCheck{
+		{GVK: CommonResourceKinds.Namespace, Namespace: "ignored", Verb: "create"},
+		{GVK: CommonResourceKinds.ConfigMap, Namespace: "config", Verb: "update"},
+		{GVK: CommonResourceKinds.Job, Namespace: "jobs", Verb: "patch", Subresource: "status"},
+	}
+
+	t.Run("allowed", func(t *testing.T) {
+		c, reviews := newAccessTestClient(func(*authorizationv1.ResourceAttributes) (bool, error) {
+			return true, nil
+		})
+		require.NoError(t, c.CheckAccess(context.Background(), checks))
+		assert.Equal(t, []authorizationv1.ResourceAttributes{
+			{Verb: "create", Version: "v1", Resource: "namespaces"},
+			{Namespace: "config", Verb: "update", Version: "v1", Resource: "configmaps"},
+			{Namespace: "jobs", Verb: "patch", Group: "batch", Version: "v1", Resource: "jobs", Subresource: "status"},
+		}, *reviews)
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		c, _ := newAccessTestClient(func(attrs *authorizationv1.ResourceAttributes) (bool, error) {
+			return attrs.Resource == "configmaps", nil
+		})
+		unserved := AccessCheck{GVK: schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}, Verb: "get"}
+		err := c.CheckAccess(context.Background(), append(checks, unserved))
+
+		var denied *AccessDeniedError
+		require.ErrorAs(t, err, &denied)
+		assert.Equal(t, "missing permissions: create Namespace in namespace ignored; "+
+			"patch Job/status in namespace jobs; get Widget in all namespaces: kind not served by the API server",
+			err.Error())
+		require.Len(t, denied.Denied, 3)
+		assert.True(t, denied.Denied[0].ClusterScoped)
+		assert.True(t, denied.Denied[2].Unserved)
+
+		roles, err := denied.SuggestedRBAC("my-adapter")
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: my-adapter
+rules:
+- apiGroups:
+  - ""
+  resources:
+  - namespaces
+  verbs:
+  - create
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: my-adapter
+  namespace: jobs
+rules:
+- apiGroups:
+  - batch
+  resources:
+  - jobs/status
+  verbs:
+  - patch
+`, roles)
+	})
+
+	t.Run("API unavailable", func(t *testing.T) {
+		c, reviews := newAccessTestClient(func(*authorizationv1.ResourceAttributes) (bool, error) {
+			return false, errors.New("connection refused")
+		})
+		err := c.CheckAccess(context.Background(), checks)
+		require.Error(t, err)
+		assert.NotErrorAs(t, err, new(*AccessDeniedError))
+		assert.Contains(t, err.Error(), "access review for create Namespace in namespace ignored failed")
+		assert.Len(t, *reviews, 1)
+	})
+}
+
+func TestSuggestedRBAC_MergesVerbs(t *testing.T) {
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	denied := &AccessDeniedError{Denied: []DeniedAccess{
+		{AccessCheck: AccessCheck{GVK: CommonResourceKinds.ConfigMap, Verb: "get"}, Resource: configMaps},
+		{AccessCheck: AccessCheck{GVK: CommonResourceKinds.ConfigMap, Verb: "list"}, Resource: configMaps},
+		{AccessCheck: AccessCheck{GVK: CommonResourceKinds.ConfigMap, Verb: "get"}, Resource: configMaps},
+	}}
+	roles, err := denied.SuggestedRBAC("my-adapter")
+	require.NoError(t, err)
+	assert.Contains(t, roles, "kind: ClusterRole")
+	assert.Contains(t, roles, "verbs:\n  - get\n  - list\n")
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/retrybudget"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
 	apperrors "github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/errors"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -114,6 +116,21 @@ func NewClientFromConfig(ctx context.Context, restConfig *rest.Config, log logge
 	}, nil
 }
 
+// Ping verifies the API server is reachable and accepts the client's credentials
+// by creating a SelfSubjectAccessReview, which every authenticated identity may do.
+// The review verdict itself is ignored.
+func (c *Client) Ping(ctx context.Context) error {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{Verb: "get", Resource: "namespaces"},
+		},
+	}
+	if err := c.client.Create(ctx, review); err != nil {
+		return apperrors.KubernetesError("kubernetes API server check failed: %v", err)
+	}
+	return nil
+}
+
 // CreateResource creates a Kubernetes resource from an unstructured object
 func (c *Client) CreateResource(
 	ctx context.Context, obj *unstructured.Unstructured,
@@ -289,9 +306,17 @@ func (c *Client) DeleteResource(ctx context.Context, gvk schema.GroupVersionKind
 	return nil
 }
 
+// PatchOptions configures PatchResource
+type PatchOptions struct {
+	// PatchType defaults to types.MergePatchType
+	PatchType types.PatchType
+	// Subresource patches a subresource such as "status" instead of the object
+	Subresource string
+}
+
 // PatchResource applies a patch to a Kubernetes resource
 //
-// This performs a JSON merge patch (RFC 7386), updating only the specified fields
+// By default this performs a JSON merge patch (RFC 7386), updating only the specified fields
 // while preserving other fields. This is safer than UpdateResource for
 // concurrent modifications.
 //
@@ -310,18 +335,31 @@ func (c *Client) DeleteResource(ctx context.Context, gvk schema.GroupVersionKind
 //   - You have the complete resource and want to replace it entirely
 //   - You're making complex multi-field changes
 //
+// opts selects a JSON patch (RFC 6902) or strategic merge patch, and a subresource
+// such as status. A patch rejected with a conflict, e.g. a JSON patch "test"
+// operation racing a concurrent update, is retried with client-go's default backoff.
+//
 // Example:
 //
 //	patchData := []byte(`{"metadata":{"labels":{"new-label":"value"}}}`)
-//	patched, err := client.PatchResource(ctx, gvk, "default", "my-cm", patchData)
+//	patched, err := client.PatchResource(ctx, gvk, "default", "my-cm", patchData, nil)
 func (c *Client) PatchResource(
 	ctx context.Context,
 	gvk schema.GroupVersionKind,
 	namespace, name string,
 	patchData []byte,
+	opts *PatchOptions,
 ) (*unstructured.Unstructured, error) {
-	// Parse patch data to validate JSON
-	var patchObj map[string]interface{}
+	if opts == nil {
+		opts = &PatchOptions{}
+	}
+	patchType := opts.PatchType
+	if patchType == "" {
+		patchType = types.MergePatchType
+	}
+
+	// Parse patch data to validate JSON: an object, or an array of operations for a JSON patch
+	var patchObj interface{}
 	if err := json.Unmarshal(patchData, &patchObj); err != nil {
 		return nil, apperrors.KubernetesError("invalid patch data: %v", err)
 	}
@@ -332,11 +370,15 @@ func (c *Client) PatchResource(
 	obj.SetNamespace(namespace)
 	obj.SetName(name)
 
-	// Apply the patch using JSON merge patch type
-	// This is equivalent to kubectl patch with --type=merge
-	patch := client.RawPatch(types.MergePatchType, patchData)
+	// Equivalent to kubectl patch --type=merge|json|strategic [--subresource=status]
+	patch := client.RawPatch(patchType, patchData)
 
-	err := c.client.Patch(ctx, obj, patch)
+	err := retrybudget.RetryOnConflict(ctx, func() error {
+		if opts.Subresource != "" {
+			return c.client.SubResource(opts.Subresource).Patch(ctx, obj, patch)
+		}
+		return c.client.Patch(ctx, obj, patch)
+	})
 	if err != nil {
 		// Don't wrap NotFound errors so callers can check for them
 		if apierrors.IsNotFound(err) {
@@ -0,0 +1,90 @@
+package k8sclient
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ConfigMapGVK is the GroupVersionKind of ConfigMaps
+var ConfigMapGVK = schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"}
+
+// configMapWriteAttempts is the number of attempts of a ConfigMap write
+// conflicting with a concurrent one
+const configMapWriteAttempts = 3
+
+// ConfigMapChange changes the data of a ConfigMap in place; exists tells
+// whether the ConfigMap exists yet. It returns false to write nothing.
+type ConfigMapChange func(data map[string]string, exists bool) (bool, error)
+
+// GetConfigMap returns the ConfigMap namespace/name, nil when it does not exist
+func GetConfigMap(ctx context.Context, client K8sClient, namespace, name string) (*unstructured.Unstructured, error) {
+	obj, err := client.GetResource(ctx, ConfigMapGVK, namespace, name, nil)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ConfigMap %s/%s: %w", namespace, name, err)
+	}
+	return obj, nil
+}
+
+// UpdateConfigMapData reads the ConfigMap namespace/name, applies change to
+// its data and writes it back with optimistic concurrency. A ConfigMap that
+// does not exist is created, labeled as managed by the adapter. The state the
+// adapter keeps in ConfigMaps, e.g. the dedup, journal and idempotency stores,
+// is written this way. A write conflicting with a concurrent one is retried
+// from the read, so change may be applied more than once. Its errors are
+// returned as they are.
+func UpdateConfigMapData(ctx context.Context, client K8sClient, namespace, name string, change ConfigMapChange) error {
+	var err error
+	for attempt := 0; attempt < configMapWriteAttempts; attempt++ {
+		err = tryUpdateConfigMapData(ctx, client, namespace, name, change)
+		if !apierrors.IsConflict(err) && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func tryUpdateConfigMapData(
+	ctx context.Context, client K8sClient, namespace, name string, change ConfigMapChange,
+) error {
+	obj, err := GetConfigMap(ctx, client, namespace, name)
+	if err != nil {
+		return err
+	}
+	exists := obj != nil
+	if !exists {
+		obj = &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(ConfigMapGVK)
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		obj.SetLabels(map[string]string{"app.kubernetes.io/managed-by": "hyperfleet-adapter"})
+	} else {
+		obj = obj.DeepCopy()
+	}
+	data, _, _ := unstructured.NestedStringMap(obj.Object, "data")
+	if data == nil {
+		data = make(map[string]string)
+	}
+	write, err := change(data, exists)
+	if err != nil || !write {
+		return err
+	}
+	if err := unstructured.SetNestedStringMap(obj.Object, data, "data"); err != nil {
+		return err
+	}
+	if exists {
+		_, err = client.UpdateResource(ctx, obj)
+	} else {
+		_, err = client.CreateResource(ctx, obj)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write ConfigMap %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
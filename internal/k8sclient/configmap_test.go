@@ -0,0 +1,95 @@
+package k8sclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// conflictingClient fails the first conflicts updates with a Conflict error
+type conflictingClient struct {
+	*MockK8sClient
+	conflicts int
+}
+
+func (c *conflictingClient) UpdateResource(
+	ctx context.Context, obj *unstructured.Unstructured,
+) (*unstructured.Unstructured, error) {
+	if c.conflicts > 0 {
+		c.conflicts--
+		return nil, apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, obj.GetName(), nil)
+	}
+	return c.MockK8sClient.UpdateResource(ctx, obj)
+}
+
+func set(key, value string) ConfigMapChange {
+	return func(data map[string]string, _ bool) (bool, error) {
+		data[key] = value
+		return true, nil
+	}
+}
+
+func TestGetConfigMap_NotFound(t *testing.T) {
+	obj, err := GetConfigMap(context.Background(), NewMockK8sClient(), "ns", "state")
+	require.NoError(t, err)
+	assert.Nil(t, obj)
+}
+
+func TestUpdateConfigMapData(t *testing.T) {
+	ctx := context.Background()
+	client := NewMockK8sClient()
+
+	require.NoError(t, UpdateConfigMapData(ctx, client, "ns", "state", set("a", "1")))
+	obj := client.Resources["ns/state"]
+	require.NotNil(t, obj)
+	assert.Equal(t, "hyperfleet-adapter", obj.GetLabels()["app.kubernetes.io/managed-by"])
+
+	var sawExisting bool
+	require.NoError(t, UpdateConfigMapData(ctx, client, "ns", "state",
+		func(data map[string]string, exists bool) (bool, error) {
+			sawExisting = exists
+			data["b"] = "2"
+			return true, nil
+		}))
+	assert.True(t, sawExisting)
+	data, _, _ := unstructured.NestedStringMap(client.Resources["ns/state"].Object, "data")
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, data)
+}
+
+func TestUpdateConfigMapData_NoWrite(t *testing.T) {
+	client := NewMockK8sClient()
+	err := UpdateConfigMapData(context.Background(), client, "ns", "state",
+		func(map[string]string, bool) (bool, error) { return false, nil })
+	require.NoError(t, err)
+	assert.Empty(t, client.Resources, "no ConfigMap is created when the change writes nothing")
+}
+
+func TestUpdateConfigMapData_ChangeError(t *testing.T) {
+	errChange := errors.New("full")
+	client := NewMockK8sClient()
+	err := UpdateConfigMapData(context.Background(), client, "ns", "state",
+		func(map[string]string, bool) (bool, error) { return false, errChange })
+	require.ErrorIs(t, err, errChange)
+	assert.Empty(t, client.Resources)
+}
+
+func TestUpdateConfigMapData_RetriesConflicts(t *testing.T) {
+	ctx := context.Background()
+	client := &conflictingClient{MockK8sClient: NewMockK8sClient()}
+	require.NoError(t, UpdateConfigMapData(ctx, client, "ns", "state", set("a", "1")))
+
+	client.conflicts = configMapWriteAttempts - 1
+	require.NoError(t, UpdateConfigMapData(ctx, client, "ns", "state", set("a", "2")))
+	data, _, _ := unstructured.NestedStringMap(client.Resources["ns/state"].Object, "data")
+	assert.Equal(t, "2", data["a"])
+
+	client.conflicts = configMapWriteAttempts
+	err := UpdateConfigMapData(ctx, client, "ns", "state", set("a", "3"))
+	assert.True(t, apierrors.IsConflict(err), "the conflict is returned once the attempts are exhausted")
+}
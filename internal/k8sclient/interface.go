@@ -54,6 +54,17 @@ type K8sClient interface {
 		gvk schema.GroupVersionKind,
 		namespace, name string,
 	) error
+
+	// PatchResource patches a Kubernetes resource by GVK, namespace, and name.
+	// Nil opts performs a JSON merge patch of the object.
+	// Returns the patched resource.
+	PatchResource(
+		ctx context.Context,
+		gvk schema.GroupVersionKind,
+		namespace, name string,
+		patchData []byte,
+		opts *PatchOptions,
+	) (*unstructured.Unstructured, error)
 }
 
 // Ensure Client implements K8sClient interface
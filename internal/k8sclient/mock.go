@@ -27,12 +27,26 @@ type MockK8sClient struct {
 	UpdateResourceResult *unstructured.Unstructured
 	UpdateResourceError  error
 	DeleteResourceError  error
+	PatchResourceResult  *unstructured.Unstructured
+	PatchResourceError   error
 	ApplyManifestResult  *ApplyResult
 	ApplyManifestError   error
 	ApplyResourceResult  *ApplyResult
 	ApplyResourceError   error
 	DiscoverResult       *unstructured.UnstructuredList
 	DiscoverError        error
+
+	// Patches records the PatchResource calls
+	Patches []MockPatch
+}
+
+// MockPatch is a PatchResource call recorded by MockK8sClient
+type MockPatch struct {
+	Options   *PatchOptions
+	Namespace string
+	Name      string
+	GVK       schema.GroupVersionKind
+	Data      []byte
 }
 
 // NewMockK8sClient creates a new mock K8s client for testing
@@ -117,6 +131,27 @@ func (m *MockK8sClient) DeleteResource(
 	return nil
 }
 
+// PatchResource implements K8sClient.PatchResource
+// By default it returns the stored resource with the patch recorded in Patches.
+func (m *MockK8sClient) PatchResource(
+	ctx context.Context,
+	gvk schema.GroupVersionKind,
+	namespace, name string,
+	patchData []byte,
+	opts *PatchOptions,
+) (*unstructured.Unstructured, error) {
+	m.Patches = append(m.Patches, MockPatch{
+		GVK: gvk, Namespace: namespace, Name: name, Data: patchData, Options: opts,
+	})
+	if m.PatchResourceError != nil {
+		return nil, m.PatchResourceError
+	}
+	if m.PatchResourceResult != nil {
+		return m.PatchResourceResult, nil
+	}
+	return m.GetResource(ctx, gvk, namespace, name, nil)
+}
+
 // ApplyManifest implements K8sClient.ApplyManifest
 func (m *MockK8sClient) ApplyManifest(
 	ctx context.Context,
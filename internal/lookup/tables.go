@@ -0,0 +1,176 @@
+// Package lookup serves the maps of the task config to the lookup(map, key)
+// function of CEL expressions and templates, e.g. to translate the phases of
+// the HyperFleet API into the statuses of another system. The entries of a map
+// are inline in the config or read from a ConfigMap once, when the adapter
+// starts; the Tables are never modified afterwards.
+package lookup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ErrMissingKey is returned by Lookup for a key the map does not have, under
+// the "error" missing key policy
+var ErrMissingKey = errors.New("key not found")
+
+var configMapGVK = schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"}
+
+// ConfigMapGetter reads the ConfigMaps of the maps with a configmap_name.
+// Implemented by the transport clients.
+type ConfigMapGetter interface {
+	GetResource(
+		ctx context.Context,
+		gvk schema.GroupVersionKind,
+		namespace, name string,
+		target transportclient.TransportContext,
+	) (*unstructured.Unstructured, error)
+}
+
+// table is a map and its missing key policy
+type table struct {
+	entries   map[string]interface{}
+	onMissing string
+	// missing is the value of the missing keys under the default and empty policies
+	missing interface{}
+}
+
+// Tables are the maps of a config by name. They are safe for concurrent use.
+type Tables struct {
+	tables map[string]*table
+}
+
+// New builds the tables of maps with inline entries. It fails for a map read
+// from a ConfigMap, which requires Load.
+func New(maps []configloader.LookupMap) (*Tables, error) {
+	return Load(context.Background(), maps, nil)
+}
+
+// Load builds the tables of maps, reading the entries of the maps with a
+// configmap_name through client. A ConfigMap that cannot be read, or whose
+// values are not all strings or all JSON objects, fails the load.
+func Load(ctx context.Context, maps []configloader.LookupMap, client ConfigMapGetter) (*Tables, error) {
+	t := &Tables{tables: make(map[string]*table, len(maps))}
+	for _, m := range maps {
+		entries := m.Entries
+		if m.FromConfigMap() {
+			if client == nil {
+				return nil, fmt.Errorf("map %q is read from ConfigMap %s/%s, but no Kubernetes client is available",
+					m.Name, m.ConfigMapNamespace, m.ConfigMapName)
+			}
+			var err error
+			if entries, err = readConfigMap(ctx, client, m.ConfigMapNamespace, m.ConfigMapName); err != nil {
+				return nil, fmt.Errorf("map %q: %w", m.Name, err)
+			}
+		}
+		if err := configloader.CheckLookupValues(entries, m.Default); err != nil {
+			return nil, fmt.Errorf("map %q: %w", m.Name, err)
+		}
+		t.tables[m.Name] = &table{
+			entries:   entries,
+			onMissing: m.EffectiveOnMissing(),
+			missing:   missingValue(m, entries),
+		}
+	}
+	return t, nil
+}
+
+// Len returns the number of maps. A nil Tables has none.
+func (t *Tables) Len() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.tables)
+}
+
+// Lookup returns the value of key in the map mapName, or what the missing key
+// policy of the map returns when it has no such key. Objects are returned as
+// copies, which callers may modify.
+func (t *Tables) Lookup(mapName, key string) (interface{}, error) {
+	var tbl *table
+	if t != nil {
+		tbl = t.tables[mapName]
+	}
+	if tbl == nil {
+		return nil, fmt.Errorf("unknown map %q", mapName)
+	}
+	value, ok := tbl.entries[key]
+	if !ok {
+		if tbl.onMissing == configloader.LookupOnMissingError {
+			return nil, fmt.Errorf("%w: %q in map %q", ErrMissingKey, key, mapName)
+		}
+		value = tbl.missing
+	}
+	return copyValue(value), nil
+}
+
+// missingValue returns the value of the missing keys of m: its default, or
+// the empty value of the kind of its entries, which are all of one kind
+func missingValue(m configloader.LookupMap, entries map[string]interface{}) interface{} {
+	if m.EffectiveOnMissing() == configloader.LookupOnMissingDefault {
+		return m.Default
+	}
+	for _, value := range entries {
+		if _, isObject := value.(map[string]interface{}); isObject {
+			return map[string]interface{}{}
+		}
+	}
+	return ""
+}
+
+// readConfigMap returns the data of a ConfigMap as entries: a value starting
+// with "{" is decoded as a JSON object, the others are strings
+func readConfigMap(
+	ctx context.Context, client ConfigMapGetter, namespace, name string,
+) (map[string]interface{}, error) {
+	obj, err := client.GetResource(ctx, configMapGVK, namespace, name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ConfigMap %s/%s: %w", namespace, name, err)
+	}
+	data, _, err := unstructured.NestedStringMap(obj.Object, "data")
+	if err != nil {
+		return nil, fmt.Errorf("invalid data of ConfigMap %s/%s: %w", namespace, name, err)
+	}
+	entries := make(map[string]interface{}, len(data))
+	for key, raw := range data {
+		if !strings.HasPrefix(strings.TrimSpace(raw), "{") {
+			entries[key] = raw
+			continue
+		}
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &object); err != nil {
+			return nil, fmt.Errorf("value of key %q of ConfigMap %s/%s is not a JSON object: %w",
+				key, namespace, name, err)
+		}
+		entries[key] = object
+	}
+	return entries, nil
+}
+
+// copyValue returns a deep copy of the objects and lists of value
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = copyValue(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = copyValue(item)
+		}
+		return out
+	default:
+		return value
+	}
+}
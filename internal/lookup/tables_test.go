@@ -0,0 +1,164 @@
+package lookup
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func configMap(data map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "region-map", "namespace": "hyperfleet"},
+		"data":       data,
+	}}
+}
+
+func TestLookup(t *testing.T) {
+	tables, err := New([]configloader.LookupMap{
+		{Name: "phaseStatus", Entries: map[string]interface{}{"Provisioning": "INSTALLING", "Ready": "READY"}},
+		{Name: "regions", OnMissing: configloader.LookupOnMissingEmpty, Entries: map[string]interface{}{
+			"us-east-1": map[string]interface{}{
+				"datacenter": "dc-1",
+				"network":    map[string]interface{}{"zones": []interface{}{"a", "b"}},
+			},
+		}},
+		{Name: "tiers", OnMissing: configloader.LookupOnMissingDefault, Default: "standard",
+			Entries: map[string]interface{}{"enterprise": "premium"}},
+		{Name: "labels", OnMissing: configloader.LookupOnMissingEmpty,
+			Entries: map[string]interface{}{"team": "platform"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, tables.Len())
+
+	tests := []struct {
+		name    string
+		mapName string
+		key     string
+		want    interface{}
+		wantErr string
+	}{
+		{name: "string value", mapName: "phaseStatus", key: "Ready", want: "READY"},
+		{name: "nested object value", mapName: "regions", key: "us-east-1", want: map[string]interface{}{
+			"datacenter": "dc-1",
+			"network":    map[string]interface{}{"zones": []interface{}{"a", "b"}},
+		}},
+		{name: "missing key error", mapName: "phaseStatus", key: "Deleting",
+			wantErr: `key not found: "Deleting" in map "phaseStatus"`},
+		{name: "missing key default", mapName: "tiers", key: "basic", want: "standard"},
+		{name: "missing key empty string", mapName: "labels", key: "owner", want: ""},
+		{name: "missing key empty object", mapName: "regions", key: "eu-west-1", want: map[string]interface{}{}},
+		{name: "unknown map", mapName: "zones", key: "a", wantErr: `unknown map "zones"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tables.Lookup(tt.mapName, tt.key)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err = tables.Lookup("phaseStatus", "Deleting")
+	assert.True(t, errors.Is(err, ErrMissingKey))
+
+	t.Run("objects are copies", func(t *testing.T) {
+		region, err := tables.Lookup("regions", "us-east-1")
+		require.NoError(t, err)
+		network := region.(map[string]interface{})["network"].(map[string]interface{})
+		network["zones"].([]interface{})[0] = "z"
+		region.(map[string]interface{})["datacenter"] = "dc-9"
+
+		again, err := tables.Lookup("regions", "us-east-1")
+		require.NoError(t, err)
+		assert.Equal(t, "dc-1", again.(map[string]interface{})["datacenter"])
+		assert.Equal(t, []interface{}{"a", "b"},
+			again.(map[string]interface{})["network"].(map[string]interface{})["zones"])
+	})
+}
+
+func TestLookup_NilTables(t *testing.T) {
+	var tables *Tables
+	assert.Equal(t, 0, tables.Len())
+	_, err := tables.Lookup("phaseStatus", "Ready")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown map "phaseStatus"`)
+}
+
+func TestNew_MixedValues(t *testing.T) {
+	_, err := New([]configloader.LookupMap{{Name: "phaseStatus", Entries: map[string]interface{}{
+		"Ready":  "READY",
+		"Failed": map[string]interface{}{"status": "FAILED"},
+	}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `map "phaseStatus"`)
+}
+
+func TestLoad_ConfigMap(t *testing.T) {
+	maps := []configloader.LookupMap{{
+		Name:               "regions",
+		ConfigMapName:      "region-map",
+		ConfigMapNamespace: "hyperfleet",
+		OnMissing:          configloader.LookupOnMissingEmpty,
+	}}
+
+	t.Run("JSON objects", func(t *testing.T) {
+		client := k8sclient.NewMockK8sClient()
+		client.Resources["hyperfleet/region-map"] = configMap(map[string]interface{}{
+			"us-east-1": `{"datacenter": "dc-1", "zones": ["a", "b"]}`,
+			"eu-west-1": ` {"datacenter": "dc-2"}`,
+		})
+		tables, err := Load(context.Background(), maps, client)
+		require.NoError(t, err)
+
+		got, err := tables.Lookup("regions", "us-east-1")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"datacenter": "dc-1", "zones": []interface{}{"a", "b"}}, got)
+		got, err = tables.Lookup("regions", "ap-south-1")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{}, got)
+	})
+
+	t.Run("strings", func(t *testing.T) {
+		client := k8sclient.NewMockK8sClient()
+		client.Resources["hyperfleet/region-map"] = configMap(map[string]interface{}{"us-east-1": "dc-1"})
+		tables, err := Load(context.Background(), maps, client)
+		require.NoError(t, err)
+		got, err := tables.Lookup("regions", "us-east-1")
+		require.NoError(t, err)
+		assert.Equal(t, "dc-1", got)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		client := k8sclient.NewMockK8sClient()
+		client.Resources["hyperfleet/region-map"] = configMap(map[string]interface{}{"us-east-1": "{dc-1"})
+		_, err := Load(context.Background(), maps, client)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `value of key "us-east-1" of ConfigMap hyperfleet/region-map is not a JSON object`)
+	})
+
+	t.Run("missing ConfigMap", func(t *testing.T) {
+		client := k8sclient.NewMockK8sClient()
+		client.GetResourceError = errors.New("not found")
+		_, err := Load(context.Background(), maps, client)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `map "regions": failed to read ConfigMap hyperfleet/region-map: not found`)
+	})
+
+	t.Run("without a client", func(t *testing.T) {
+		_, err := New(maps)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no Kubernetes client is available")
+	})
+}
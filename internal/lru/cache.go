@@ -0,0 +1,188 @@
+// Package lru provides a bounded least recently used cache, safe for
+// concurrent use, whose entries can expire after a TTL. It backs the caches
+// and stores of the adapter that remember a bounded number of keys, e.g. the
+// dedup store of event IDs and the parsed template cache.
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+)
+
+// Cache holds at most maxEntries entries. Adding an entry beyond capacity
+// evicts the least recently used one: the one least recently added, updated
+// or read with Get. With a TTL, an entry stored more than the TTL ago is
+// expired: reading it misses and drops it.
+type Cache[K comparable, V any] struct {
+	entries    map[K]*list.Element
+	order      *list.List // front = most recently used
+	clock      clock.Clock
+	ttl        time.Duration
+	maxEntries int
+	mu         sync.Mutex
+}
+
+// entry is a value and when it was stored
+type entry[K comparable, V any] struct {
+	storedAt time.Time
+	key      K
+	value    V
+}
+
+// New creates a cache of at most maxEntries entries that never expire.
+// A cache with maxEntries <= 0 holds nothing.
+func New[K comparable, V any](maxEntries int) *Cache[K, V] {
+	return &Cache[K, V]{
+		entries:    make(map[K]*list.Element),
+		order:      list.New(),
+		clock:      clock.Real,
+		maxEntries: maxEntries,
+	}
+}
+
+// WithTTL expires the entries stored more than ttl ago, as told by clk (nil
+// uses the real clock). A ttl <= 0 keeps them until they are evicted.
+func (c *Cache[K, V]) WithTTL(ttl time.Duration, clk clock.Clock) *Cache[K, V] {
+	c.ttl = ttl
+	c.clock = clock.OrReal(clk)
+	return c
+}
+
+// Get returns the value of key and marks it as the most recently used
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem := c.lookup(key)
+	if elem == nil {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(elem)
+	return entryOf[K, V](elem).value, true
+}
+
+// Peek returns the value of key without marking it as used
+func (c *Cache[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem := c.lookup(key)
+	if elem == nil {
+		var zero V
+		return zero, false
+	}
+	return entryOf[K, V](elem).value, true
+}
+
+// Add stores value for key, now
+func (c *Cache[K, V]) Add(key K, value V) {
+	c.AddAt(key, value, c.clock.Now())
+}
+
+// AddAt stores value for key as stored at storedAt, e.g. for an entry loaded
+// from a persistent store, whose TTL runs from when it was first stored
+func (c *Cache[K, V]) AddAt(key K, value V, storedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store(key, value, storedAt)
+}
+
+// Update stores the value returned by update for the value of key, found or
+// not, and returns it. The read and the write are atomic.
+func (c *Cache[K, V]) Update(key K, update func(value V, found bool) V) V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var value V
+	elem := c.lookup(key)
+	if elem != nil {
+		value = entryOf[K, V](elem).value
+	}
+	value = update(value, elem != nil)
+	c.store(key, value, c.clock.Now())
+	return value
+}
+
+// Remove drops key
+func (c *Cache[K, V]) Remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+	}
+}
+
+// Len returns the number of entries held, expired ones included until they
+// are read or evicted
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Values returns the values of the entries that have not expired, the most
+// recently used first
+func (c *Cache[K, V]) Values() []V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	values := make([]V, 0, c.order.Len())
+	for elem := c.order.Front(); elem != nil; elem = elem.Next() {
+		if entry := entryOf[K, V](elem); !c.expired(entry) {
+			values = append(values, entry.value)
+		}
+	}
+	return values
+}
+
+// lookup returns the element of key, nil when it is missing or expired, in
+// which case it is dropped. Caller must hold c.mu.
+func (c *Cache[K, V]) lookup(key K) *list.Element {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if c.expired(entryOf[K, V](elem)) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil
+	}
+	return elem
+}
+
+// store inserts or replaces the entry of key as the most recently used, and
+// evicts the least recently used entries beyond capacity. Caller must hold c.mu.
+func (c *Cache[K, V]) store(key K, value V, storedAt time.Time) {
+	if c.maxEntries <= 0 {
+		return
+	}
+	if elem, ok := c.entries[key]; ok {
+		entry := entryOf[K, V](elem)
+		entry.value, entry.storedAt = value, storedAt
+		c.order.MoveToFront(elem)
+	} else {
+		c.entries[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, storedAt: storedAt})
+	}
+
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, entryOf[K, V](oldest).key)
+	}
+}
+
+// expired reports whether entry was stored more than the TTL ago
+func (c *Cache[K, V]) expired(entry *entry[K, V]) bool {
+	return c.ttl > 0 && c.clock.Since(entry.storedAt) > c.ttl
+}
+
+func entryOf[K comparable, V any](elem *list.Element) *entry[K, V] {
+	//nolint:errcheck // list only holds *entry[K, V]
+	return elem.Value.(*entry[K, V])
+}
@@ -0,0 +1,80 @@
+package lru
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := New[string, int](2)
+	cache.Add("a", 1)
+	cache.Add("b", 2)
+
+	_, ok := cache.Get("a") // a is now the most recently used
+	assert.True(t, ok)
+	cache.Add("c", 3)
+
+	_, ok = cache.Peek("b")
+	assert.False(t, ok, "b is evicted")
+	value, ok := cache.Peek("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, []int{3, 1}, cache.Values())
+
+	_, _ = cache.Peek("a") // Peek does not mark a as used
+	cache.Add("d", 4)
+	_, ok = cache.Peek("a")
+	assert.False(t, ok, "a is evicted")
+}
+
+func TestCache_TTL(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	cache := New[string, string](10).WithTTL(time.Minute, fake)
+	cache.AddAt("loaded", "x", fake.Now().Add(-50*time.Second))
+	cache.Add("fresh", "y")
+
+	fake.Advance(30 * time.Second)
+	_, ok := cache.Peek("loaded")
+	assert.False(t, ok, "the TTL runs from when the entry was stored")
+	assert.Equal(t, 1, cache.Len(), "a missed expired entry is dropped")
+	assert.Equal(t, []string{"y"}, cache.Values())
+
+	fake.Advance(31 * time.Second)
+	_, ok = cache.Get("fresh")
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCache_Update(t *testing.T) {
+	cache := New[string, int](10)
+	increment := func(count int, _ bool) int { return count + 1 }
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cache.Update("key", increment)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 51, cache.Update("key", func(count int, found bool) int {
+		assert.True(t, found)
+		return count + 1
+	}))
+	cache.Remove("key")
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCache_Disabled(t *testing.T) {
+	cache := New[string, int](0)
+	cache.Add("a", 1)
+	assert.Equal(t, 1, cache.Update("b", func(int, bool) int { return 1 }))
+	assert.Equal(t, 0, cache.Len(), "a cache without capacity holds nothing")
+}
@@ -397,9 +397,23 @@ func readTokenFile(path string) (string, error) {
 	if trimmed == "" {
 		return "", fmt.Errorf("token file %s is empty or contains only whitespace", path)
 	}
+	logger.RegisterSecret(trimmed)
 	return trimmed, nil
 }
 
+// Ping verifies the Maestro HTTP API is reachable and accepts the client's
+// credentials by listing a single consumer.
+func (c *Client) Ping(ctx context.Context) error {
+	_, resp, err := c.maestroAPIClient.DefaultAPI.ApiMaestroV1ConsumersGet(ctx).Size(1).Execute()
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close() //nolint:errcheck // body already consumed by the generated client
+	}
+	if err != nil {
+		return apperrors.MaestroError("maestro API check failed: %v", err)
+	}
+	return nil
+}
+
 // Close closes the gRPC connection
 func (c *Client) Close() error {
 	if c.grpcOptions != nil && c.grpcOptions.Dialer != nil {
@@ -424,6 +438,13 @@ type TransportContext struct {
 	// ConsumerName is the target cluster name (Maestro consumer).
 	// Required for all Maestro operations.
 	ConsumerName string
+	// Labels and Annotations are merged into the metadata of the ManifestWork
+	// ApplyResource publishes
+	Labels      map[string]string
+	Annotations map[string]string
+	// KeepTemplateMetadata keeps the value of the ManifestWork for a key also in
+	// Labels or Annotations, which by default overwrite it
+	KeepTemplateMetadata bool
 }
 
 // resolveTransportContext extracts the maestro TransportContext
@@ -487,6 +508,7 @@ func (c *Client) ApplyResource(
 
 	// Set namespace to consumer name
 	work.Namespace = consumerName
+	mergeWorkMetadata(work, transportCtx)
 
 	// Apply the ManifestWork (create or update with generation comparison)
 	result, err := c.ApplyManifestWork(ctx, consumerName, work)
@@ -637,6 +659,31 @@ func parseManifestWork(data []byte) (*workv1.ManifestWork, error) {
 	return work, nil
 }
 
+// mergeWorkMetadata merges the labels and annotations of the transport context
+// into the metadata of work
+func mergeWorkMetadata(work *workv1.ManifestWork, transportCtx *TransportContext) {
+	work.Labels = mergeStringMap(work.Labels, transportCtx.Labels, transportCtx.KeepTemplateMetadata)
+	work.Annotations = mergeStringMap(work.Annotations, transportCtx.Annotations, transportCtx.KeepTemplateMetadata)
+}
+
+// mergeStringMap returns dst with the entries of src added, keeping the value
+// of dst for a key of both when keepExisting is set
+func mergeStringMap(dst, src map[string]string, keepExisting bool) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for key, value := range src {
+		if _, exists := dst[key]; exists && keepExisting {
+			continue
+		}
+		dst[key] = value
+	}
+	return dst
+}
+
 // determineOperation determines the operation that was performed based on the ManifestWork.
 
 // manifestToUnstructured converts a workv1.Manifest to an unstructured object.
@@ -132,6 +132,51 @@ func TestParseManifestWork_PreservesMetadata(t *testing.T) {
 	assert.Equal(t, "annotation", parsed.Annotations["extra"])
 }
 
+func TestParseManifestWork_PreservesMetadataMergedWithTransportMetadata(t *testing.T) {
+	mw := newTestManifestWork("my-manifestwork", []workv1.Manifest{
+		{RawExtension: runtime.RawExtension{Raw: bareNamespaceJSON(t, "ns")}},
+	})
+	mw.Labels["hyperfleet.io/cluster-id"] = "from-template"
+	data, err := json.Marshal(mw)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name      string
+		keep      bool
+		clusterID string
+	}{
+		{name: "transport metadata overwrites the template", keep: false, clusterID: "cluster-1"},
+		{name: "template metadata kept", keep: true, clusterID: "from-template"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parseManifestWork(data)
+			require.NoError(t, err)
+			mergeWorkMetadata(parsed, &TransportContext{
+				ConsumerName:         "cluster-1",
+				Labels:               map[string]string{"hyperfleet.io/cluster-id": "cluster-1", "added": "label"},
+				Annotations:          map[string]string{constants.AnnotationGeneration: "2"},
+				KeepTemplateMetadata: tt.keep,
+			})
+
+			assert.Equal(t, "true", parsed.Labels["test"])
+			assert.Equal(t, "label", parsed.Labels["added"])
+			assert.Equal(t, tt.clusterID, parsed.Labels["hyperfleet.io/cluster-id"])
+			generation := "2"
+			if tt.keep {
+				generation = "1"
+			}
+			assert.Equal(t, generation, parsed.Annotations[constants.AnnotationGeneration])
+		})
+	}
+
+	// A work without labels or annotations gets those of the transport context
+	parsed, err := parseManifestWork([]byte(`{"metadata":{"name":"bare"}}`))
+	require.NoError(t, err)
+	mergeWorkMetadata(parsed, &TransportContext{Labels: map[string]string{"added": "label"}})
+	assert.Equal(t, map[string]string{"added": "label"}, parsed.Labels)
+	assert.Nil(t, parsed.Annotations)
+}
+
 func TestParseManifestWork_MultipleManifests(t *testing.T) {
 	nsJSON := bareNamespaceJSON(t, "cluster-abc")
 	cmJSON := mustJSON(t, map[string]interface{}{
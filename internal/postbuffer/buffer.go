@@ -0,0 +1,296 @@
+// Package postbuffer keeps the API calls of the post actions with
+// buffer_on_failure that failed after their retries, and replays them in the
+// background until they succeed or expire, so an outage of the status
+// endpoint does not fail events. Calls are persisted in a journal.Store and
+// survive restarts. Calls with the same key are replayed in the order they
+// were buffered: a call is not replayed before the older ones of its key
+// succeeded, expired or were rejected.
+package postbuffer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/journal"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+)
+
+// Defaults of the buffer
+const (
+	DefaultReplayInterval = 30 * time.Second
+	DefaultMaxAge         = time.Hour
+	DefaultMaxEntries     = 100
+)
+
+// maxBackoffShift caps the backoff of a call failing again at 32 replay intervals
+const maxBackoffShift = 5
+
+// Outcomes of a buffered call, the outcome label of hyperfleet_adapter_post_buffer_calls_total
+const (
+	OutcomeBuffered = "buffered"
+	OutcomeReplayed = "replayed"
+	OutcomeExpired  = "expired"
+	OutcomeRejected = "rejected"
+)
+
+// ErrFull is returned by Put when the action has its maximum of buffered calls
+var ErrFull = errors.New("post buffer is full")
+
+// Call is a rendered API call of a post action
+type Call struct {
+	BufferedAt time.Time `json:"buffered_at"`
+	// NextAttemptAt is when the call is replayed next, zero for the next replay
+	NextAttemptAt time.Time `json:"next_attempt_at,omitempty"`
+	Action        string    `json:"action"`
+	// Key orders the calls: those with the same key are replayed in order
+	Key string `json:"key"`
+	// Target is the HyperFleet API target of the call
+	Target  string            `json:"target"`
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    []byte            `json:"body,omitempty"`
+	// FormFields and Parts are the form and multipart bodies
+	FormFields []hyperfleetapi.FormField     `json:"form_fields,omitempty"`
+	Parts      []hyperfleetapi.MultipartPart `json:"parts,omitempty"`
+	LastError  string                        `json:"last_error,omitempty"`
+	Timeout    time.Duration                 `json:"timeout,omitempty"`
+	// MaxAge is how long the call is replayed before it is dropped
+	MaxAge time.Duration `json:"max_age"`
+	// Attempts is the number of replays of the call
+	Attempts int `json:"attempts"`
+}
+
+// request returns the request of a replay of the call, a single attempt
+func (c *Call) request() *hyperfleetapi.Request {
+	attempts := 1
+	return &hyperfleetapi.Request{
+		Method:        c.Method,
+		URL:           c.URL,
+		Headers:       c.Headers,
+		Body:          c.Body,
+		FormFields:    c.FormFields,
+		Parts:         c.Parts,
+		Timeout:       c.Timeout,
+		RetryAttempts: &attempts,
+	}
+}
+
+// Config configures a Buffer
+type Config struct {
+	// Store persists the buffered calls
+	Store journal.Store
+	// Client sends the replays, to the target of each call
+	Client hyperfleetapi.Client
+	// Recorder counts the outcomes of the calls (nil records nothing)
+	Recorder *metrics.Recorder
+	// Clock drives the replay loop and times the calls (nil uses the real clock)
+	Clock clock.Clock
+	// ReplayInterval is how often the buffered calls are replayed, and the
+	// backoff of a call failing a first replay. Zero uses DefaultReplayInterval.
+	ReplayInterval time.Duration
+}
+
+// Buffer holds the buffered calls and replays them on an interval. All
+// methods are safe for concurrent use.
+type Buffer struct {
+	config Config
+	log    logger.Logger
+	stopCh chan struct{}
+	doneCh chan struct{}
+	// putMu makes the bound check of Put and its write atomic
+	putMu sync.Mutex
+	// replayMu serializes the replays
+	replayMu sync.Mutex
+	once     sync.Once
+}
+
+// New creates the buffer and starts the replay loop, stopped by Close
+func New(config Config, log logger.Logger) (*Buffer, error) {
+	if config.Store == nil {
+		return nil, fmt.Errorf("post buffer store is required")
+	}
+	if config.Client == nil {
+		return nil, fmt.Errorf("post buffer API client is required")
+	}
+	if config.ReplayInterval <= 0 {
+		config.ReplayInterval = DefaultReplayInterval
+	}
+	config.Clock = clock.OrReal(config.Clock)
+	b := &Buffer{
+		config: config,
+		log:    log,
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	go b.replayLoop()
+	return b, nil
+}
+
+// Put buffers call. It fails with ErrFull when the action of the call has
+// maxEntries buffered calls, and with the error of the store when the call
+// cannot be persisted.
+func (b *Buffer) Put(ctx context.Context, call Call, maxEntries int) error {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	if call.MaxAge <= 0 {
+		call.MaxAge = DefaultMaxAge
+	}
+	call.BufferedAt = b.config.Clock.Now()
+
+	b.putMu.Lock()
+	defer b.putMu.Unlock()
+	entries, err := b.config.Store.List(ctx)
+	if err != nil {
+		return err
+	}
+	buffered := 0
+	for _, entry := range entries {
+		var other Call
+		if json.Unmarshal(entry.Data, &other) == nil && other.Action == call.Action {
+			buffered++
+		}
+	}
+	if buffered >= maxEntries {
+		return fmt.Errorf("%w: action %s has %d buffered calls", ErrFull, call.Action, buffered)
+	}
+	// Version 7 UUIDs sort by creation, ordering the calls buffered at the same time
+	id, err := uuid.NewV7()
+	if err != nil {
+		return err
+	}
+	if err := b.write(ctx, journal.Entry{ID: id.String(), RecordedAt: call.BufferedAt}, call); err != nil {
+		return err
+	}
+	b.config.Recorder.RecordPostBufferCall(call.Action, OutcomeBuffered)
+	return nil
+}
+
+// Replay sends the buffered calls that are due, oldest first. A call that
+// succeeds, is older than its max age, or is rejected with a status that is
+// not retryable is removed; one that fails again is replayed after a backoff,
+// and holds back the calls of its key. Returns the number of calls sent
+// successfully.
+func (b *Buffer) Replay(ctx context.Context) (int, error) {
+	b.replayMu.Lock()
+	defer b.replayMu.Unlock()
+	entries, err := b.config.Store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	replayed := 0
+	var errs []error
+	held := make(map[string]bool)
+	for _, entry := range entries {
+		var call Call
+		if err := json.Unmarshal(entry.Data, &call); err != nil {
+			b.log.Warnf(logger.WithErrorField(ctx, err), "Dropping corrupt buffered call %s", entry.ID)
+			errs = append(errs, b.config.Store.Delete(ctx, entry.ID))
+			continue
+		}
+		now := b.config.Clock.Now()
+		if now.Sub(call.BufferedAt) > call.MaxAge {
+			b.log.Warnf(ctx, "Dropping the call of post action %s buffered at %s: older than %s (last error: %s)",
+				call.Action, call.BufferedAt.Format(time.RFC3339), call.MaxAge, call.LastError)
+			b.config.Recorder.RecordPostBufferCall(call.Action, OutcomeExpired)
+			errs = append(errs, b.config.Store.Delete(ctx, entry.ID))
+			continue
+		}
+		if held[call.Key] || now.Before(call.NextAttemptAt) {
+			held[call.Key] = true
+			continue
+		}
+
+		outcome, sendErr := b.send(ctx, &call)
+		switch outcome {
+		case OutcomeReplayed:
+			replayed++
+			b.log.Infof(ctx, "Replayed the call of post action %s buffered at %s",
+				call.Action, call.BufferedAt.Format(time.RFC3339))
+		case OutcomeRejected:
+			b.log.Warnf(logger.WithErrorField(ctx, sendErr), "Dropping the call of post action %s buffered at %s: rejected",
+				call.Action, call.BufferedAt.Format(time.RFC3339))
+		default:
+			held[call.Key] = true
+			call.Attempts++
+			call.LastError = sendErr.Error()
+			call.NextAttemptAt = now.Add(b.config.ReplayInterval << min(call.Attempts-1, maxBackoffShift))
+			errs = append(errs, b.write(ctx, entry, call))
+			continue
+		}
+		b.config.Recorder.RecordPostBufferCall(call.Action, outcome)
+		errs = append(errs, b.config.Store.Delete(ctx, entry.ID))
+	}
+	return replayed, errors.Join(errs...)
+}
+
+// Close stops the replay loop. Closing a nil buffer does nothing.
+func (b *Buffer) Close() {
+	if b == nil {
+		return
+	}
+	b.once.Do(func() {
+		close(b.stopCh)
+		<-b.doneCh
+	})
+}
+
+// send replays call, returning OutcomeReplayed, OutcomeRejected for a status
+// that is not retryable, or "" with the error of a call to replay again
+func (b *Buffer) send(ctx context.Context, call *Call) (string, error) {
+	client, err := b.config.Client.Target(call.Target)
+	if err != nil {
+		return OutcomeRejected, err
+	}
+	resp, err := client.Do(ctx, call.request())
+	switch {
+	case resp != nil && resp.IsSuccess():
+		return OutcomeReplayed, nil
+	case resp != nil && !resp.IsRetryable():
+		return OutcomeRejected, fmt.Errorf("%s %s returned %d %s", call.Method, call.URL, resp.StatusCode, resp.Status)
+	case err != nil:
+		return "", err
+	case resp == nil:
+		return "", fmt.Errorf("%s %s returned no response", call.Method, call.URL)
+	default:
+		return "", fmt.Errorf("%s %s returned %d %s", call.Method, call.URL, resp.StatusCode, resp.Status)
+	}
+}
+
+// write persists call as the data of entry
+func (b *Buffer) write(ctx context.Context, entry journal.Entry, call Call) error {
+	data, err := json.Marshal(call)
+	if err != nil {
+		return err
+	}
+	entry.Data = data
+	return b.config.Store.Put(ctx, entry)
+}
+
+func (b *Buffer) replayLoop() {
+	defer close(b.doneCh)
+	ticker := b.config.Clock.NewTicker(b.config.ReplayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stopCh:
+			return
+		case <-ticker.C():
+			ctx, cancel := context.WithTimeout(context.Background(), b.config.ReplayInterval)
+			if _, err := b.Replay(ctx); err != nil {
+				b.log.Warnf(logger.WithErrorField(ctx, err), "Failed to replay buffered post action calls")
+			}
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package postbuffer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/journal"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingClient fails the calls to the URLs in fail with a 503
+type failingClient struct {
+	*hyperfleetapi.MockClient
+	fail map[string]bool
+}
+
+func (c *failingClient) Do(ctx context.Context, req *hyperfleetapi.Request) (*hyperfleetapi.Response, error) {
+	c.Requests = append(c.Requests, req)
+	if c.fail[req.URL] {
+		return &hyperfleetapi.Response{StatusCode: 503, Status: "503 Service Unavailable"}, nil
+	}
+	return &hyperfleetapi.Response{StatusCode: 200, Status: "200 OK"}, nil
+}
+
+func (c *failingClient) Target(string) (hyperfleetapi.Client, error) {
+	return c, nil
+}
+
+func newTestStore(t *testing.T) journal.Store {
+	store, err := journal.NewFileStore(t.TempDir(), journal.Limits{}, logger.NewTestLogger())
+	require.NoError(t, err)
+	return store
+}
+
+// newTestBuffer creates a buffer whose replays are driven by the test
+func newTestBuffer(t *testing.T, store journal.Store, client hyperfleetapi.Client) (*Buffer, *clock.Fake) {
+	fake := clock.NewFake(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+	buffer, err := New(Config{Store: store, Client: client, Clock: fake, ReplayInterval: time.Minute},
+		logger.NewTestLogger())
+	require.NoError(t, err)
+	buffer.Close()
+	return buffer, fake
+}
+
+func testCall(action, key, url string) Call {
+	return Call{Action: action, Key: key, Target: hyperfleetapi.DefaultTarget, Method: "POST", URL: url,
+		Body: []byte(`{"ok":true}`)}
+}
+
+func urls(requests []*hyperfleetapi.Request) []string {
+	var urls []string
+	for _, req := range requests {
+		urls = append(urls, req.URL)
+	}
+	return urls
+}
+
+func TestBuffer_ReplayAfterOutage(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	client := hyperfleetapi.NewMockClient()
+	buffer, fake := newTestBuffer(t, store, client)
+
+	require.NoError(t, buffer.Put(ctx, testCall("report", "c1", "/clusters/c1/statuses"), 0))
+	client.DoError = errors.New("connection refused")
+
+	replayed, err := buffer.Replay(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, replayed)
+	entries, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "a call failing again stays buffered")
+	assert.Contains(t, string(entries[0].Data), `"attempts":1`)
+	assert.Contains(t, string(entries[0].Data), "connection refused")
+
+	// The call backs off for a replay interval
+	client.DoError = nil
+	client.DoResponse = &hyperfleetapi.Response{StatusCode: 201, Status: "201 Created"}
+	replayed, err = buffer.Replay(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, replayed)
+
+	fake.Advance(time.Minute)
+	replayed, err = buffer.Replay(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+	require.Len(t, client.Requests, 2)
+	last := client.GetLastRequest()
+	assert.Equal(t, "POST", last.Method)
+	assert.Equal(t, `{"ok":true}`, string(last.Body))
+	require.NotNil(t, last.RetryAttempts)
+	assert.Equal(t, 1, *last.RetryAttempts, "a replay is a single attempt")
+
+	entries, err = store.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestBuffer_OrderPerKey(t *testing.T) {
+	ctx := context.Background()
+	client := &failingClient{MockClient: hyperfleetapi.NewMockClient(), fail: map[string]bool{"/a/1": true}}
+	buffer, fake := newTestBuffer(t, newTestStore(t), client)
+
+	require.NoError(t, buffer.Put(ctx, testCall("report", "a", "/a/1"), 0))
+	fake.Advance(time.Second)
+	require.NoError(t, buffer.Put(ctx, testCall("report", "b", "/b/1"), 0))
+	fake.Advance(time.Second)
+	require.NoError(t, buffer.Put(ctx, testCall("report", "a", "/a/2"), 0))
+
+	replayed, err := buffer.Replay(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+	assert.Equal(t, []string{"/a/1", "/b/1"}, urls(client.Requests),
+		"the failing call holds back the later call of its key, not those of other keys")
+
+	client.fail = nil
+	client.Reset()
+	fake.Advance(time.Minute)
+	replayed, err = buffer.Replay(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, replayed)
+	assert.Equal(t, []string{"/a/1", "/a/2"}, urls(client.Requests))
+}
+
+func TestBuffer_DropsExpiredAndRejectedCalls(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	client := hyperfleetapi.NewMockClient()
+	buffer, fake := newTestBuffer(t, store, client)
+
+	expiring := testCall("report", "c1", "/clusters/c1/statuses")
+	expiring.MaxAge = time.Minute
+	require.NoError(t, buffer.Put(ctx, expiring, 0))
+	fake.Advance(2 * time.Minute)
+
+	replayed, err := buffer.Replay(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, replayed)
+	assert.Empty(t, client.Requests, "an expired call is not sent")
+
+	require.NoError(t, buffer.Put(ctx, testCall("report", "c2", "/clusters/c2/statuses"), 0))
+	client.DoResponse = &hyperfleetapi.Response{StatusCode: 400, Status: "400 Bad Request"}
+	replayed, err = buffer.Replay(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, replayed)
+	assert.Len(t, client.Requests, 1)
+
+	entries, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "expired and rejected calls are dropped")
+}
+
+func TestBuffer_MaxEntriesPerAction(t *testing.T) {
+	ctx := context.Background()
+	buffer, _ := newTestBuffer(t, newTestStore(t), hyperfleetapi.NewMockClient())
+
+	require.NoError(t, buffer.Put(ctx, testCall("report", "c1", "/a"), 2))
+	require.NoError(t, buffer.Put(ctx, testCall("report", "c1", "/b"), 2))
+	assert.ErrorIs(t, buffer.Put(ctx, testCall("report", "c1", "/c"), 2), ErrFull)
+	assert.NoError(t, buffer.Put(ctx, testCall("notify", "c1", "/c"), 2),
+		"the bound is per action")
+}
+
+func TestBuffer_ReplayLoop(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	fake := clock.NewFake(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+	buffer, err := New(Config{Store: store, Client: hyperfleetapi.NewMockClient(), Clock: fake,
+		ReplayInterval: time.Minute}, logger.NewTestLogger())
+	require.NoError(t, err)
+	defer buffer.Close()
+
+	require.NoError(t, buffer.Put(ctx, testCall("report", "c1", "/clusters/c1/statuses"), 0))
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	assert.Eventually(t, func() bool {
+		entries, err := store.List(ctx)
+		return err == nil && len(entries) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(Config{Client: hyperfleetapi.NewMockClient()}, logger.NewTestLogger())
+	assert.Error(t, err)
+	_, err = New(Config{Store: newTestStore(t)}, logger.NewTestLogger())
+	assert.Error(t, err)
+
+	var buffer *Buffer
+	buffer.Close()
+}
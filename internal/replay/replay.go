@@ -0,0 +1,286 @@
+// Package replay feeds events exported as JSON lines, e.g. from Pub/Sub or a
+// dead letter queue, through the executor for bulk reprocessing.
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/brokerconsumer"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/executor"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/health"
+)
+
+// Statuses of replayed lines, in addition to the executor outcomes
+const (
+	StatusSuccess = "success"
+	StatusSkipped = "skipped"
+	StatusFailed  = "failed"
+	// StatusInvalid is the status of a line that is not a valid CloudEvent
+	StatusInvalid = "invalid"
+)
+
+// maxLineSize is the size of the longest event line accepted
+const maxLineSize = 16 * 1024 * 1024
+
+// Executor executes events and summarizes their results, implemented by
+// *executor.Executor
+type Executor interface {
+	ExecuteEvent(ctx context.Context, evt *event.Event) *executor.ExecutionResult
+	Summarize(evt *event.Event, result *executor.ExecutionResult, duration time.Duration) health.ExecutionSummary
+}
+
+// Options configures a replay
+type Options struct {
+	// FilterTypes limits the replay to events of these types; empty replays all events
+	FilterTypes []string
+	// Concurrency is the number of events executed at once. Default: 1.
+	Concurrency int
+	// Rate is the maximum number of events started per second; 0 is unlimited
+	Rate float64
+	// FailFast stops reading events after the first failed or invalid one.
+	// Events already started complete.
+	FailFast bool
+}
+
+// LineResult is the summary written for every replayed line
+type LineResult struct {
+	health.ExecutionSummary
+	// Line is the line number of the event in the input
+	Line int `json:"line"`
+	// duration is the execution duration, 0 for invalid lines
+	duration time.Duration
+}
+
+// Report aggregates the results of a replay
+type Report struct {
+	// Counts is the number of lines by status
+	Counts map[string]int `json:"counts"`
+	// Filtered is the number of events skipped by Options.FilterTypes
+	Filtered int `json:"filtered"`
+	// P50 and P99 are percentiles of the execution durations
+	P50 time.Duration `json:"p50"`
+	P99 time.Duration `json:"p99"`
+	// Stopped reports whether the replay stopped early because of FailFast
+	Stopped bool `json:"stopped"`
+}
+
+// Failures returns the number of failed and invalid lines
+func (r *Report) Failures() int {
+	return r.Counts[StatusFailed] + r.Counts[StatusInvalid]
+}
+
+func (r *Report) String() string {
+	total := 0
+	for _, count := range r.Counts {
+		total += count
+	}
+	s := fmt.Sprintf("Replayed %d event(s): success=%d skipped=%d failed=%d invalid=%d filtered=%d, "+
+		"duration p50=%s p99=%s", total, r.Counts[StatusSuccess], r.Counts[StatusSkipped],
+		r.Counts[StatusFailed], r.Counts[StatusInvalid], r.Filtered,
+		r.P50.Round(time.Microsecond), r.P99.Round(time.Microsecond))
+	if r.Stopped {
+		s += " (stopped after the first failure)"
+	}
+	return s
+}
+
+// ParseRate parses a rate of events such as "10/s", "600/m" or "3600/h".
+// A bare number is per second; "" and "0" are unlimited.
+func ParseRate(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	count, unit, _ := strings.Cut(s, "/")
+	n, err := strconv.ParseFloat(count, 64)
+	if err != nil || n < 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, fmt.Errorf("invalid rate %q: must be a non-negative number of events per s, m or h", s)
+	}
+	switch unit {
+	case "", "s":
+		return n, nil
+	case "m":
+		return n / 60, nil
+	case "h":
+		return n / 3600, nil
+	default:
+		return 0, fmt.Errorf("invalid rate %q: unit must be s, m or h", s)
+	}
+}
+
+type job struct {
+	evt  *event.Event
+	line int
+}
+
+// Run executes the events read from in, one structured CloudEvent JSON per
+// line, and writes a LineResult JSON line to out for every event, in
+// completion order. Empty lines are ignored. Failed events do not stop the
+// replay unless opts.FailFast is set. Returns an error if reading the input
+// or writing the output fails.
+func Run(ctx context.Context, in io.Reader, out io.Writer, exec Executor, opts Options) (*Report, error) {
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	// A burst of 1 spreads events evenly instead of starting a second's worth at once
+	limiter, err := brokerconsumer.NewRateLimiter(opts.Rate, 1, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// readCtx stops reading on fail-fast or output errors; started events
+	// run with ctx and complete
+	readCtx, stopReading := context.WithCancel(ctx)
+	defer stopReading()
+
+	jobs := make(chan job)
+	results := make(chan LineResult)
+	report := &Report{Counts: make(map[string]int)}
+	var readErr error
+	var readers sync.WaitGroup
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		defer close(jobs)
+		readErr = readEvents(readCtx, in, opts.FilterTypes, limiter, jobs, results, &report.Filtered)
+	}()
+
+	var workers sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for j := range jobs {
+				start := time.Now()
+				result := exec.ExecuteEvent(ctx, j.evt)
+				duration := time.Since(start)
+				results <- LineResult{
+					ExecutionSummary: exec.Summarize(j.evt, result, duration),
+					Line:             j.line,
+					duration:         duration,
+				}
+			}
+		}()
+	}
+	go func() {
+		readers.Wait()
+		workers.Wait()
+		close(results)
+	}()
+
+	var durations []time.Duration
+	encoder := json.NewEncoder(out)
+	var writeErr error
+	for result := range results {
+		report.Counts[result.Status]++
+		if result.Status != StatusInvalid {
+			durations = append(durations, result.duration)
+		}
+		if writeErr == nil {
+			if writeErr = encoder.Encode(result); writeErr != nil {
+				stopReading()
+			}
+		}
+		if opts.FailFast && (result.Status == StatusFailed || result.Status == StatusInvalid) {
+			report.Stopped = true
+			stopReading()
+		}
+	}
+
+	report.P50 = percentile(durations, 50)
+	report.P99 = percentile(durations, 99)
+	if writeErr != nil {
+		return report, fmt.Errorf("failed to write result: %w", writeErr)
+	}
+	if readErr != nil {
+		return report, readErr
+	}
+	return report, ctx.Err()
+}
+
+// readEvents parses the lines of in and sends the events to jobs, at the rate
+// of limiter. Invalid lines are reported to results directly.
+func readEvents(
+	ctx context.Context, in io.Reader, filterTypes []string, limiter *brokerconsumer.RateLimiter,
+	jobs chan<- job, results chan<- LineResult, filtered *int,
+) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := strings.TrimSpace(scanner.Text())
+		if data == "" {
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		var evt event.Event
+		err := json.Unmarshal([]byte(data), &evt)
+		if err == nil {
+			err = evt.Validate()
+		}
+		if err != nil {
+			results <- invalidLine(line, err)
+			continue
+		}
+		if len(filterTypes) > 0 && !slices.Contains(filterTypes, evt.Type()) {
+			*filtered++
+			continue
+		}
+
+		if limiter != nil {
+			if limiter.Wait(ctx) != nil {
+				return nil
+			}
+		}
+		select {
+		case jobs <- job{evt: &evt, line: line}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read events: %w", err)
+	}
+	return nil
+}
+
+func invalidLine(line int, err error) LineResult {
+	return LineResult{
+		ExecutionSummary: health.ExecutionSummary{
+			Timestamp: time.Now(),
+			Status:    StatusInvalid,
+			Reason:    fmt.Sprintf("invalid CloudEvent: %v", err),
+		},
+		Line: line,
+	}
+}
+
+// percentile returns the nearest-rank percentile p of durations, 0 when empty
+func percentile(durations []time.Duration, p int) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,215 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/executor"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeExecutor fails events of type "fail" and tracks concurrency
+type fakeExecutor struct {
+	executed      []string
+	delay         time.Duration
+	running       atomic.Int32
+	maxConcurrent atomic.Int32
+	mu            sync.Mutex
+}
+
+func (f *fakeExecutor) ExecuteEvent(_ context.Context, evt *event.Event) *executor.ExecutionResult {
+	running := f.running.Add(1)
+	defer f.running.Add(-1)
+	for {
+		current := f.maxConcurrent.Load()
+		if running <= current || f.maxConcurrent.CompareAndSwap(current, running) {
+			break
+		}
+	}
+	time.Sleep(f.delay)
+
+	f.mu.Lock()
+	f.executed = append(f.executed, evt.ID())
+	f.mu.Unlock()
+
+	result := &executor.ExecutionResult{Status: executor.StatusSuccess, CurrentPhase: executor.PhasePostActions}
+	if evt.Type() == "fail" {
+		result.Status = executor.StatusFailed
+		result.Errors = map[executor.ExecutionPhase]error{executor.PhasePostActions: errors.New("boom")}
+	}
+	return result
+}
+
+func (f *fakeExecutor) Summarize(
+	evt *event.Event, result *executor.ExecutionResult, duration time.Duration,
+) health.ExecutionSummary {
+	status := StatusSuccess
+	if result.Status == executor.StatusFailed {
+		status = StatusFailed
+	}
+	return health.ExecutionSummary{
+		EventID: evt.ID(), EventType: evt.Type(), Status: status,
+		Phase: string(result.CurrentPhase), Duration: duration.String(),
+	}
+}
+
+func eventLine(id, eventType string) string {
+	return fmt.Sprintf(`{"specversion":"1.0","id":%q,"type":%q,"source":"/test","data":{"id":%q}}`,
+		id, eventType, id)
+}
+
+func readResults(t *testing.T, out *bytes.Buffer) []LineResult {
+	t.Helper()
+	var results []LineResult
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		var result LineResult
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &result), scanner.Text())
+		results = append(results, result)
+	}
+	return results
+}
+
+func TestRun(t *testing.T) {
+	input := strings.Join([]string{
+		eventLine("e1", "cluster"),
+		"",
+		eventLine("e2", "fail"),
+		`{"id":"no-specversion"}`,
+		eventLine("e3", "nodepool"),
+		eventLine("e4", "cluster"),
+	}, "\n")
+	exec := &fakeExecutor{}
+	out := &bytes.Buffer{}
+
+	report, err := Run(context.Background(), strings.NewReader(input), out, exec, Options{Concurrency: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{StatusSuccess: 3, StatusFailed: 1, StatusInvalid: 1}, report.Counts)
+	assert.Equal(t, 2, report.Failures())
+	assert.False(t, report.Stopped)
+
+	byLine := map[int]LineResult{}
+	for _, result := range readResults(t, out) {
+		byLine[result.Line] = result
+	}
+	require.Len(t, byLine, 5)
+	assert.Equal(t, "e1", byLine[1].EventID)
+	assert.Equal(t, StatusFailed, byLine[3].Status)
+	assert.Equal(t, StatusInvalid, byLine[4].Status)
+	assert.Contains(t, byLine[4].Reason, "invalid CloudEvent")
+}
+
+func TestRun_FilterTypes(t *testing.T) {
+	input := strings.Join([]string{eventLine("e1", "cluster"), eventLine("e2", "nodepool")}, "\n")
+	exec := &fakeExecutor{}
+
+	report, err := Run(context.Background(), strings.NewReader(input), &bytes.Buffer{}, exec,
+		Options{FilterTypes: []string{"nodepool"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"e2"}, exec.executed)
+	assert.Equal(t, 1, report.Filtered)
+}
+
+func TestRun_FailFast(t *testing.T) {
+	lines := []string{eventLine("e1", "fail")}
+	for i := 2; i <= 20; i++ {
+		lines = append(lines, eventLine(fmt.Sprintf("e%d", i), "cluster"))
+	}
+	exec := &fakeExecutor{delay: 5 * time.Millisecond}
+
+	report, err := Run(context.Background(), strings.NewReader(strings.Join(lines, "\n")), &bytes.Buffer{}, exec,
+		Options{FailFast: true})
+	require.NoError(t, err)
+
+	assert.True(t, report.Stopped)
+	assert.Equal(t, 1, report.Counts[StatusFailed])
+	assert.Less(t, len(exec.executed), 20, "reading stops after the failure")
+}
+
+func TestRun_ConcurrencyAndRate(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 8; i++ {
+		lines = append(lines, eventLine(fmt.Sprintf("e%d", i), "cluster"))
+	}
+	input := strings.Join(lines, "\n")
+
+	t.Run("concurrency", func(t *testing.T) {
+		exec := &fakeExecutor{delay: 20 * time.Millisecond}
+		_, err := Run(context.Background(), strings.NewReader(input), &bytes.Buffer{}, exec, Options{Concurrency: 4})
+		require.NoError(t, err)
+		assert.Len(t, exec.executed, 8)
+		assert.LessOrEqual(t, exec.maxConcurrent.Load(), int32(4))
+		assert.Greater(t, exec.maxConcurrent.Load(), int32(1))
+	})
+
+	t.Run("rate", func(t *testing.T) {
+		exec := &fakeExecutor{}
+		start := time.Now()
+		// One event every 20ms
+		_, err := Run(context.Background(), strings.NewReader(input), &bytes.Buffer{}, exec, Options{Rate: 50})
+		require.NoError(t, err)
+		assert.Len(t, exec.executed, 8)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("rate limits", func(t *testing.T) {
+		exec := &fakeExecutor{}
+		start := time.Now()
+		// One event every 50ms: 7 waits
+		_, err := Run(context.Background(), strings.NewReader(input), &bytes.Buffer{}, exec, Options{Rate: 20})
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+	})
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{in: "", want: 0},
+		{in: "10", want: 10},
+		{in: "10/s", want: 10},
+		{in: "120/m", want: 2},
+		{in: "3600/h", want: 1},
+		{in: "10/d", wantErr: true},
+		{in: "-1/s", wantErr: true},
+		{in: "fast", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseRate(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var durations []time.Duration
+	for i := 100; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, 50*time.Millisecond, percentile(durations, 50))
+	assert.Equal(t, 99*time.Millisecond, percentile(durations, 99))
+	assert.Equal(t, time.Duration(0), percentile(nil, 50))
+}
@@ -0,0 +1,156 @@
+// Package retrybudget bounds the time an execution spends waiting between
+// retries. The retry loops of the HyperFleet API, webhook and Kubernetes clients
+// run independently of each other, so a worst-case execution could otherwise
+// retry in series for longer than any deadline. A Budget is shared by every
+// step of an execution: each retry loop reserves its wait from the budget in its
+// context before sleeping, and gives up with an *ExhaustedError once the budget
+// cannot cover the wait.
+package retrybudget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+)
+
+// Budget is the retry wait time of an execution. A zero budget is unlimited:
+// it only accounts the waits of each step. It is safe for concurrent use by
+// steps running at once.
+type Budget struct {
+	steps     map[string]time.Duration
+	total     time.Duration
+	spent     time.Duration
+	exhausted bool
+	mu        sync.Mutex
+}
+
+// Report is the use of a budget, for the execution result
+type Report struct {
+	// Budget is the configured budget, zero for unlimited
+	Budget time.Duration
+	// Spent is the wait time reserved by the retry loops of every step
+	Spent time.Duration
+	// Exhausted is true once a retry was denied for lack of budget
+	Exhausted bool
+}
+
+// ExhaustedError is a retry denied because the budget cannot cover its wait.
+// The retried operation stops with its last error; the event is meant to be
+// redelivered by the broker instead, after Delay.
+type ExhaustedError struct {
+	// Step is the step whose retry was denied
+	Step string
+	// Delay is the wait the retry needed
+	Delay time.Duration
+	// Remaining is what was left of the budget
+	Remaining time.Duration
+	// Budget is the configured budget
+	Budget time.Duration
+}
+
+// Error implements error
+func (e *ExhaustedError) Error() string {
+	return fmt.Sprintf("retry budget of %s exhausted: the retry of %s needs a %s wait, %s left",
+		e.Budget, e.Step, e.Delay, e.Remaining)
+}
+
+// IsExhausted returns the *ExhaustedError in err's chain
+func IsExhausted(err error) (*ExhaustedError, bool) {
+	var exhausted *ExhaustedError
+	if errors.As(err, &exhausted) {
+		return exhausted, true
+	}
+	return nil, false
+}
+
+// New creates a budget of total wait time; zero or less is unlimited
+func New(total time.Duration) *Budget {
+	return &Budget{total: max(total, 0), steps: make(map[string]time.Duration)}
+}
+
+// account is the budget of a context, debited on behalf of a step
+type account struct {
+	budget *Budget
+	step   string
+}
+
+type accountKey struct{}
+
+// WithStep returns a context carrying the budget, whose retry waits are
+// debited on behalf of step. A nil budget returns ctx.
+func (b *Budget) WithStep(ctx context.Context, step string) context.Context {
+	if b == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, accountKey{}, account{budget: b, step: step})
+}
+
+// Reserve debits delay from the budget of ctx before a retry loop waits for it.
+// It returns an *ExhaustedError, debiting nothing, when the remaining budget is
+// less than delay. Without a budget in ctx every retry is allowed.
+func Reserve(ctx context.Context, delay time.Duration) error {
+	acct, ok := ctx.Value(accountKey{}).(account)
+	if !ok {
+		return nil
+	}
+	b := acct.budget
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.total > 0 && b.spent+delay > b.total {
+		b.exhausted = true
+		return &ExhaustedError{Step: acct.step, Delay: delay, Remaining: b.total - b.spent, Budget: b.total}
+	}
+	b.spent += delay
+	b.steps[acct.step] += delay
+	return nil
+}
+
+// Spent returns the wait time reserved on behalf of step
+func (b *Budget) Spent(step string) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.steps[step]
+}
+
+// Report returns the use of the budget, nil for a nil budget
+func (b *Budget) Report() *Report {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return &Report{Budget: b.total, Spent: b.spent, Exhausted: b.exhausted}
+}
+
+// RetryOnConflict runs fn like retry.RetryOnConflict with retry.DefaultRetry,
+// reserving the wait before each retry from the budget of ctx. A denied retry
+// returns the *ExhaustedError joined with the conflict.
+func RetryOnConflict(ctx context.Context, fn func() error) error {
+	backoff := retry.DefaultRetry
+	delay := backoff.Duration
+	var conflict error
+	attempt := 0
+	retriable := func(err error) bool {
+		_, exhausted := IsExhausted(err)
+		return !exhausted && apierrors.IsConflict(err)
+	}
+	return retry.OnError(backoff, retriable, func() error {
+		if attempt > 0 {
+			if err := Reserve(ctx, delay); err != nil {
+				return fmt.Errorf("%w; last attempt: %w", err, conflict)
+			}
+			delay = time.Duration(float64(delay) * backoff.Factor)
+		}
+		attempt++
+		conflict = fn()
+		return conflict
+	})
+}
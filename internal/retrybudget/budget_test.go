@@ -0,0 +1,101 @@
+package retrybudget
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestReserve_DebitsTheStepUntilExhausted(t *testing.T) {
+	budget := New(10 * time.Second)
+	ctx := context.Background()
+	preconditions := budget.WithStep(ctx, "preconditions/cluster")
+	postActions := budget.WithStep(ctx, "post_actions/report")
+
+	require.NoError(t, Reserve(preconditions, 4*time.Second))
+	require.NoError(t, Reserve(postActions, 5*time.Second))
+	assert.Equal(t, 4*time.Second, budget.Spent("preconditions/cluster"))
+	assert.Equal(t, 5*time.Second, budget.Spent("post_actions/report"))
+
+	err := Reserve(postActions, 2*time.Second)
+	exhausted, ok := IsExhausted(err)
+	require.True(t, ok, "expected an *ExhaustedError, got %v", err)
+	assert.Equal(t, &ExhaustedError{
+		Step: "post_actions/report", Delay: 2 * time.Second, Remaining: time.Second, Budget: 10 * time.Second,
+	}, exhausted)
+	assert.EqualError(t, err,
+		"retry budget of 10s exhausted: the retry of post_actions/report needs a 2s wait, 1s left")
+	assert.Equal(t, 5*time.Second, budget.Spent("post_actions/report"), "a denied retry debits nothing")
+
+	// A wait that still fits is allowed after a denial
+	require.NoError(t, Reserve(preconditions, time.Second))
+	assert.Equal(t, &Report{Budget: 10 * time.Second, Spent: 10 * time.Second, Exhausted: true}, budget.Report())
+}
+
+func TestReserve_UnlimitedBudgetOnlyAccounts(t *testing.T) {
+	budget := New(0)
+	ctx := budget.WithStep(context.Background(), "resources/deployment")
+	for range 10 {
+		require.NoError(t, Reserve(ctx, time.Minute))
+	}
+	assert.Equal(t, 10*time.Minute, budget.Spent("resources/deployment"))
+	assert.Equal(t, &Report{Spent: 10 * time.Minute}, budget.Report())
+}
+
+func TestReserve_WithoutBudget(t *testing.T) {
+	var budget *Budget
+	ctx := budget.WithStep(context.Background(), "step")
+	assert.NoError(t, Reserve(ctx, time.Hour))
+	assert.Zero(t, budget.Spent("step"))
+	assert.Nil(t, budget.Report())
+}
+
+func TestRetryOnConflict(t *testing.T) {
+	conflict := apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "cm", errors.New("modified"))
+
+	t.Run("retries conflicts within the budget", func(t *testing.T) {
+		budget := New(time.Minute)
+		ctx := budget.WithStep(context.Background(), "resources/cm")
+		calls := 0
+		err := RetryOnConflict(ctx, func() error {
+			calls++
+			if calls < 3 {
+				return conflict
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		assert.Positive(t, budget.Spent("resources/cm"))
+	})
+
+	t.Run("stops when the budget is exhausted", func(t *testing.T) {
+		budget := New(time.Millisecond)
+		ctx := budget.WithStep(context.Background(), "resources/cm")
+		calls := 0
+		err := RetryOnConflict(ctx, func() error {
+			calls++
+			return conflict
+		})
+		assert.Equal(t, 1, calls)
+		_, exhausted := IsExhausted(err)
+		assert.True(t, exhausted)
+		assert.True(t, apierrors.IsConflict(err), "the last conflict is kept in the error")
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		calls := 0
+		err := RetryOnConflict(context.Background(), func() error {
+			calls++
+			return errors.New("boom")
+		})
+		assert.EqualError(t, err, "boom")
+		assert.Equal(t, 1, calls)
+	})
+}
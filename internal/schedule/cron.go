@@ -0,0 +1,227 @@
+// Package schedule evaluates maintenance windows: periods of a fixed duration
+// opened at the times matched by cron expressions, in a time zone.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	// Embed the time zone database, so windows load in images without one
+	_ "time/tzdata"
+)
+
+// maxSearchYears bounds the search for the next match of an expression, such as
+// "0 0 30 2 *", that matches rarely or never
+const maxSearchYears = 5
+
+// maxTransitionGap bounds the wall clock time skipped by a time zone transition
+const maxTransitionGap = 2 * time.Hour
+
+// Cron is a parsed cron expression of 5 fields: minute, hour, day of month,
+// month and day of week. Fields accept *, values, ranges a-b, steps */n and
+// a-b/n, and comma-separated lists. Months and days of week accept their
+// three-letter English names; Sunday is 0 or 7.
+//
+// As in cron, when both the day of month and the day of week are restricted,
+// a day matching either one matches.
+type Cron struct {
+	expr   string
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// domAll and dowAll are set when the field starts with *, e.g. * or */2
+	domAll bool
+	dowAll bool
+}
+
+// cronField is the range and value names of a cron expression field
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Day of week 7 is Sunday too, folded into 0 after parsing
+	dowField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// ParseCron parses a 5-field cron expression
+func ParseCron(expr string) (*Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+	c := &Cron{expr: expr, domAll: strings.HasPrefix(fields[2], "*"), dowAll: strings.HasPrefix(fields[4], "*")}
+	for i, spec := range []struct {
+		bits  *uint64
+		field cronField
+	}{
+		{&c.minute, minuteField},
+		{&c.hour, hourField},
+		{&c.dom, domField},
+		{&c.month, monthField},
+		{&c.dow, dowField},
+	} {
+		bits, err := parseField(fields[i], spec.field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+		}
+		*spec.bits = bits
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow = c.dow&^(1<<7) | 1
+	}
+	return c, nil
+}
+
+// String returns the expression c was parsed from
+func (c *Cron) String() string {
+	return c.expr
+}
+
+// parseField parses a comma-separated list of a field into a bit set of its values
+func parseField(value string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepExpr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%s: invalid step %q", field.name, stepExpr)
+			}
+			step = n
+		}
+
+		var low, high int
+		switch lowExpr, highExpr, isRange := strings.Cut(rangeExpr, "-"); {
+		case rangeExpr == "*":
+			low, high = field.min, field.max
+		case isRange:
+			var err error
+			if low, err = field.value(lowExpr); err != nil {
+				return 0, err
+			}
+			if high, err = field.value(highExpr); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("%s: range %q is reversed", field.name, rangeExpr)
+			}
+		default:
+			var err error
+			if low, err = field.value(lowExpr); err != nil {
+				return 0, err
+			}
+			high = low
+			// A single value with a step, e.g. "5/15", runs to the end of the range
+			if hasStep {
+				high = field.max
+			}
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value parses a value of the field, a number or a name
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid value %q", f.name, s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s: value %d is out of range %d-%d", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first time after t matched by c on the wall clock of loc.
+// A matched wall time skipped by a daylight saving time transition, e.g. 02:30
+// when clocks jump from 02:00 to 03:00, is the same offset after the transition
+// (03:30). A wall time repeated when clocks fall back matches once. It returns
+// false when c matches no time within the next years.
+func (c *Cron) Next(t time.Time, loc *time.Location) (time.Time, bool) {
+	local := t.In(loc)
+	// Start before the wall time of t, so a wall time skipped just before t,
+	// which is after t once shifted past the transition, is not missed
+	wall := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), 0, 0, time.UTC).
+		Add(-maxTransitionGap)
+	for {
+		var ok bool
+		if wall, ok = c.nextWall(wall); !ok {
+			return time.Time{}, false
+		}
+		next := inLocation(wall, loc)
+		if next.After(t) {
+			return next, true
+		}
+	}
+}
+
+// inLocation returns the time of the wall clock time wall in loc. A wall time
+// skipped by a transition is the same offset after it: time.Date may resolve it
+// with either offset, and the later of the two times is after the transition.
+func inLocation(wall time.Time, loc *time.Location) time.Time {
+	t := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), 0, 0, loc)
+	local := t.In(loc)
+	if local.Hour() == wall.Hour() && local.Minute() == wall.Minute() {
+		return t
+	}
+	_, offset := local.Zone()
+	if shifted := wall.Add(-time.Duration(offset) * time.Second); shifted.After(t) {
+		return shifted.In(loc)
+	}
+	return t
+}
+
+// nextWall returns the first minute after wall matched by c. Wall clock times
+// are represented in UTC, which has no daylight saving time.
+func (c *Cron) nextWall(wall time.Time) (time.Time, bool) {
+	t := wall.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + maxSearchYears
+	for t.Year() <= limit {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// dayMatches reports whether the day of t matches the day of month and day of week fields
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAll || c.dowAll {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,153 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return loc
+}
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{expr: "* * * * *"},
+		{expr: "0 22 * * FRI"},
+		{expr: "*/15 9-17 * * mon-fri"},
+		{expr: "0 0 1,15 * *"},
+		{expr: "30 4 * jan-mar,dec 7"},
+		{expr: "5/20 * * * *"},
+		{expr: "0 22 * *", wantErr: "expected 5 fields, got 4"},
+		{expr: "60 * * * *", wantErr: "minute: value 60 is out of range 0-59"},
+		{expr: "0 0 0 * *", wantErr: "day of month: value 0 is out of range 1-31"},
+		{expr: "0 5-1 * * *", wantErr: `hour: range "5-1" is reversed`},
+		{expr: "*/0 * * * *", wantErr: `minute: invalid step "0"`},
+		{expr: "0 0 * * funday", wantErr: `day of week: invalid value "funday"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expr, c.String())
+		})
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// 2026-01-02 is a Friday
+	from := time.Date(2026, 1, 2, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		name string
+		expr string
+		want time.Time
+	}{
+		{name: "every minute", expr: "* * * * *", want: time.Date(2026, 1, 2, 10, 8, 0, 0, time.UTC)},
+		{name: "later today", expr: "0 22 * * *", want: time.Date(2026, 1, 2, 22, 0, 0, 0, time.UTC)},
+		{name: "tomorrow", expr: "0 9 * * *", want: time.Date(2026, 1, 3, 9, 0, 0, 0, time.UTC)},
+		{name: "step", expr: "*/15 * * * *", want: time.Date(2026, 1, 2, 10, 15, 0, 0, time.UTC)},
+		{name: "day of week", expr: "0 1 * * MON", want: time.Date(2026, 1, 5, 1, 0, 0, 0, time.UTC)},
+		{name: "sunday as 7", expr: "0 1 * * 7", want: time.Date(2026, 1, 4, 1, 0, 0, 0, time.UTC)},
+		{name: "next month", expr: "0 0 1 * *", want: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "next year", expr: "0 0 1 1 *", want: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "leap day", expr: "0 0 29 2 *", want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Either the 15th or a Sunday, whichever comes first
+		{name: "day of month or day of week", expr: "0 0 15 * SUN", want: time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)},
+		{name: "day of month with every day of week", expr: "0 0 15 * *",
+			want: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			require.NoError(t, err)
+			got, ok := c.Next(from, time.UTC)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("never", func(t *testing.T) {
+		c, err := ParseCron("0 0 30 2 *")
+		require.NoError(t, err)
+		_, ok := c.Next(from, time.UTC)
+		assert.False(t, ok)
+	})
+}
+
+// TestCronNext_DST covers the daylight saving time transitions of 2026 in
+// New York: clocks jump from 02:00 EST to 03:00 EDT on March 8 and fall back
+// from 02:00 EDT to 01:00 EST on November 1
+func TestCronNext_DST(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{
+			name:  "skipped wall time matches after the jump",
+			expr:  "30 2 * * *",
+			after: time.Date(2026, 3, 8, 1, 0, 0, 0, ny),
+			want:  time.Date(2026, 3, 8, 7, 30, 0, 0, time.UTC), // 03:30 EDT
+		},
+		{
+			name:  "skipped wall time matches once",
+			expr:  "30 2 * * *",
+			after: time.Date(2026, 3, 8, 7, 30, 0, 0, time.UTC),
+			want:  time.Date(2026, 3, 9, 6, 30, 0, 0, time.UTC), // 02:30 EDT
+		},
+		{
+			name:  "day shortened by the jump",
+			expr:  "0 12 * * *",
+			after: time.Date(2026, 3, 7, 12, 0, 0, 0, ny),
+			want:  time.Date(2026, 3, 8, 16, 0, 0, 0, time.UTC), // 12:00 EDT, 23 hours later
+		},
+		{
+			name:  "repeated wall time matches its first occurrence",
+			expr:  "30 1 * * *",
+			after: time.Date(2026, 11, 1, 0, 0, 0, 0, ny),
+			want:  time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC), // 01:30 EDT
+		},
+		{
+			name:  "repeated wall time matches once",
+			expr:  "30 1 * * *",
+			after: time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC),
+			want:  time.Date(2026, 11, 2, 6, 30, 0, 0, time.UTC), // 01:30 EST the next day
+		},
+		{
+			name:  "repeated hour is not matched again",
+			expr:  "45 1 * * *",
+			after: time.Date(2026, 11, 1, 6, 10, 0, 0, time.UTC), // 01:10 EST, the second 01:10
+			want:  time.Date(2026, 11, 2, 6, 45, 0, 0, time.UTC),
+		},
+		{
+			name:  "day lengthened by the fall back",
+			expr:  "0 12 * * *",
+			after: time.Date(2026, 10, 31, 12, 0, 0, 0, ny),
+			want:  time.Date(2026, 11, 1, 17, 0, 0, 0, time.UTC), // 12:00 EST, 25 hours later
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			require.NoError(t, err)
+			got, ok := c.Next(tt.after, ny)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, got.UTC())
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+)
+
+// Window is open for Duration from every time matched by Start
+type Window struct {
+	Start    *Cron
+	Duration time.Duration
+}
+
+// Schedule is a set of windows on the wall clock of a time zone. Durations are
+// elapsed time: a window of 4h spanning a daylight saving time transition
+// closes 4 hours after it opened, whatever the wall clock shows then.
+type Schedule struct {
+	loc     *time.Location
+	windows []Window
+}
+
+// New returns the schedule of windows in loc; a nil loc is UTC
+func New(loc *time.Location, windows ...Window) (*Schedule, error) {
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("schedule has no windows")
+	}
+	for i, window := range windows {
+		if window.Start == nil {
+			return nil, fmt.Errorf("window %d has no start", i)
+		}
+		if window.Duration <= 0 {
+			return nil, fmt.Errorf("window %d: duration must be positive, got %s", i, window.Duration)
+		}
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &Schedule{loc: loc, windows: windows}, nil
+}
+
+// Location returns the time zone of the schedule
+func (s *Schedule) Location() *time.Location {
+	return s.loc
+}
+
+// Open reports whether a window is open at now and, if so, when the last of the
+// windows open at now closes
+func (s *Schedule) Open(now time.Time) (bool, time.Time) {
+	var open bool
+	var closes time.Time
+	for _, window := range s.windows {
+		// The window is open when it started within its duration before now
+		start, ok := window.Start.Next(now.Add(-window.Duration), s.loc)
+		if !ok || start.After(now) {
+			continue
+		}
+		// A later start of the same window may be open at now too, and close later
+		for {
+			next, ok := window.Start.Next(start, s.loc)
+			if !ok || next.After(now) {
+				break
+			}
+			start = next
+		}
+		open = true
+		if end := start.Add(window.Duration); end.After(closes) {
+			closes = end
+		}
+	}
+	return open, closes
+}
+
+// NextOpen returns when the first window opens after now. It returns false when
+// no window opens within the next years.
+func (s *Schedule) NextOpen(now time.Time) (time.Time, bool) {
+	var next time.Time
+	var found bool
+	for _, window := range s.windows {
+		start, ok := window.Start.Next(now, s.loc)
+		if ok && (!found || start.Before(next)) {
+			next, found = start, true
+		}
+	}
+	return next, found
+}
@@ -0,0 +1,152 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustWindow(t *testing.T, expr string, duration time.Duration) Window {
+	t.Helper()
+	start, err := ParseCron(expr)
+	require.NoError(t, err)
+	return Window{Start: start, Duration: duration}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(time.UTC)
+	assert.EqualError(t, err, "schedule has no windows")
+
+	_, err = New(time.UTC, Window{Duration: time.Hour})
+	assert.EqualError(t, err, "window 0 has no start")
+
+	_, err = New(time.UTC, mustWindow(t, "0 22 * * *", 0))
+	assert.EqualError(t, err, "window 0: duration must be positive, got 0s")
+
+	s, err := New(nil, mustWindow(t, "0 22 * * *", time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, s.Location())
+}
+
+func TestSchedule(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	tests := []struct {
+		now        time.Time
+		wantCloses time.Time
+		wantNext   time.Time
+		loc        *time.Location
+		name       string
+		windows    []Window
+		wantOpen   bool
+	}{
+		{
+			// 2026-01-02 is a Friday
+			name:     "before a window spanning midnight",
+			windows:  []Window{mustWindow(t, "0 22 * * FRI", 4*time.Hour)},
+			now:      time.Date(2026, 1, 2, 21, 59, 0, 0, time.UTC),
+			wantNext: time.Date(2026, 1, 2, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "window opening",
+			windows:    []Window{mustWindow(t, "0 22 * * FRI", 4*time.Hour)},
+			now:        time.Date(2026, 1, 2, 22, 0, 0, 0, time.UTC),
+			wantOpen:   true,
+			wantCloses: time.Date(2026, 1, 3, 2, 0, 0, 0, time.UTC),
+			wantNext:   time.Date(2026, 1, 9, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "after midnight in a window opened the day before",
+			windows:    []Window{mustWindow(t, "0 22 * * FRI", 4*time.Hour)},
+			now:        time.Date(2026, 1, 3, 1, 30, 0, 0, time.UTC),
+			wantOpen:   true,
+			wantCloses: time.Date(2026, 1, 3, 2, 0, 0, 0, time.UTC),
+			wantNext:   time.Date(2026, 1, 9, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "window closing",
+			windows:  []Window{mustWindow(t, "0 22 * * FRI", 4*time.Hour)},
+			now:      time.Date(2026, 1, 3, 2, 0, 0, 0, time.UTC),
+			wantNext: time.Date(2026, 1, 9, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "time zone",
+			windows:    []Window{mustWindow(t, "0 9 * * *", time.Hour)},
+			loc:        tokyo,
+			now:        time.Date(2026, 1, 2, 0, 30, 0, 0, time.UTC), // 09:30 JST
+			wantOpen:   true,
+			wantCloses: time.Date(2026, 1, 2, 1, 0, 0, 0, time.UTC),
+			wantNext:   time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "overlapping windows close with the last",
+			windows: []Window{
+				mustWindow(t, "0 22 * * *", 2*time.Hour),
+				mustWindow(t, "0 23 * * *", 2*time.Hour),
+			},
+			now:        time.Date(2026, 1, 2, 23, 30, 0, 0, time.UTC),
+			wantOpen:   true,
+			wantCloses: time.Date(2026, 1, 3, 1, 0, 0, 0, time.UTC),
+			wantNext:   time.Date(2026, 1, 3, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "window reopened while open",
+			windows:    []Window{mustWindow(t, "0 * * * *", 90*time.Minute)},
+			now:        time.Date(2026, 1, 2, 10, 45, 0, 0, time.UTC),
+			wantOpen:   true,
+			wantCloses: time.Date(2026, 1, 2, 11, 30, 0, 0, time.UTC),
+			wantNext:   time.Date(2026, 1, 2, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			// 00:00 EDT on November 1, 2026 plus 4 hours is 03:00 EST
+			name:       "window spanning the fall back lasts its duration",
+			windows:    []Window{mustWindow(t, "0 0 * * SUN", 4*time.Hour)},
+			loc:        ny,
+			now:        time.Date(2026, 11, 1, 7, 30, 0, 0, time.UTC), // 02:30 EST
+			wantOpen:   true,
+			wantCloses: time.Date(2026, 11, 1, 8, 0, 0, 0, time.UTC),
+			wantNext:   time.Date(2026, 11, 8, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "window spanning the fall back closed by the wall clock hour",
+			windows:  []Window{mustWindow(t, "0 0 * * SUN", 4*time.Hour)},
+			loc:      ny,
+			now:      time.Date(2026, 11, 1, 8, 0, 0, 0, time.UTC), // 03:00 EST
+			wantNext: time.Date(2026, 11, 8, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			// 01:00 EST on March 8, 2026 plus 2 hours is 04:00 EDT
+			name:       "window spanning the spring forward lasts its duration",
+			windows:    []Window{mustWindow(t, "0 1 * * SUN", 2*time.Hour)},
+			loc:        ny,
+			now:        time.Date(2026, 3, 8, 7, 30, 0, 0, time.UTC), // 03:30 EDT
+			wantOpen:   true,
+			wantCloses: time.Date(2026, 3, 8, 8, 0, 0, 0, time.UTC),
+			wantNext:   time.Date(2026, 3, 15, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "window starting in the skipped hour opens after the jump",
+			windows:  []Window{mustWindow(t, "30 2 * * *", time.Hour)},
+			loc:      ny,
+			now:      time.Date(2026, 3, 8, 7, 0, 0, 0, time.UTC), // 03:00 EDT
+			wantNext: time.Date(2026, 3, 8, 7, 30, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(tt.loc, tt.windows...)
+			require.NoError(t, err)
+
+			open, closes := s.Open(tt.now)
+			assert.Equal(t, tt.wantOpen, open)
+			if tt.wantOpen {
+				assert.Equal(t, tt.wantCloses, closes.UTC())
+			}
+			next, ok := s.NextOpen(tt.now)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantNext, next.UTC())
+		})
+	}
+}
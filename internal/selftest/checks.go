@@ -0,0 +1,278 @@
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/brokerconsumer"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/maestroclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Names of the checks
+const (
+	CheckHyperfleetAPI  = "hyperfleet_api"
+	CheckKubernetes     = "kubernetes"
+	CheckKubernetesRBAC = "kubernetes_rbac"
+	CheckMaestro        = "maestro"
+	CheckBroker         = "broker"
+)
+
+// DependencyChecks returns the checks of the HyperFleet API and of the
+// transport client. Readiness is set from the health.checks toggles.
+func DependencyChecks(
+	config *configloader.Config, apiClient hyperfleetapi.Client, tc transportclient.TransportClient,
+) []Check {
+	checks := []Check{{
+		Name: CheckHyperfleetAPI,
+		Run: func(ctx context.Context) error {
+			return hyperfleetapi.Ping(ctx, apiClient, HyperfleetAPIPingPath(config))
+		},
+		Remediation: "check clients.hyperfleet_api.base_url and the API credentials; " +
+			"the adapter must be able to GET " + HyperfleetAPIPingPath(config),
+		Readiness: configloader.CheckEnabled(config.Health.Checks.HyperfleetAPI),
+	}}
+
+	switch client := tc.(type) {
+	case *k8sclient.Client:
+		checks = append(checks, Check{
+			Name:        CheckKubernetes,
+			Run:         client.Ping,
+			Remediation: "check clients.kubernetes.kube_config_path or the in-cluster ServiceAccount token",
+			Readiness:   configloader.CheckEnabled(config.Health.Checks.Kubernetes),
+		})
+	case *maestroclient.Client:
+		checks = append(checks, Check{
+			Name: CheckMaestro,
+			Run:  client.Ping,
+			Remediation: "check clients.maestro.http_server_address and the Maestro TLS settings; " +
+				"the Maestro HTTP API must be reachable from the adapter",
+			Readiness: configloader.CheckEnabled(config.Health.Checks.Maestro),
+		})
+	}
+	return checks
+}
+
+// HyperfleetAPIPingPath returns the endpoint probed by the hyperfleet_api check
+func HyperfleetAPIPingPath(config *configloader.Config) string {
+	if config.Health.HyperfleetAPIPath != "" {
+		return config.Health.HyperfleetAPIPath
+	}
+	apiVersion := config.Clients.HyperfleetAPI.Version
+	if apiVersion == "" {
+		apiVersion = "v1"
+	}
+	return fmt.Sprintf("/api/hyperfleet/%s/clusters?pageSize=1", apiVersion)
+}
+
+// RBACCheck returns the check that the Kubernetes identity of the adapter has
+// the permissions the config needs, logging the roles granting the missing ones
+// at debug level. Skipped with the Maestro transport.
+func RBACCheck(config *configloader.Config, tc transportclient.TransportClient, log logger.Logger) Check {
+	check := Check{
+		Name: CheckKubernetesRBAC,
+		Remediation: "grant the missing verbs to the adapter ServiceAccount with a Role or ClusterRole " +
+			"bound to it",
+	}
+	checker, ok := tc.(AccessChecker)
+	if !ok {
+		check.SkipReason = "the transport is not Kubernetes"
+		return check
+	}
+	access := RequiredAccess(config)
+	check.Run = func(ctx context.Context) error {
+		return checkRBAC(ctx, checker, access, config.Adapter.Name, log)
+	}
+	return check
+}
+
+// RequiredAccess derives the RBAC permissions the Kubernetes calls of every
+// workflow of the config need: get, create and update on every manifest kind,
+// list when it is discovered by selectors, and delete with recreate_on_change;
+// patch on the object of every k8s_patch post action; get and patch on the
+// object of the finalizer; get on the object of every k8s_field_ref param. Templated namespaces are checked in all namespaces;
+// templated kinds are left out.
+func RequiredAccess(config *configloader.Config) []k8sclient.AccessCheck {
+	seen := make(map[k8sclient.AccessCheck]bool)
+	var access []k8sclient.AccessCheck
+	addCheck := func(check k8sclient.AccessCheck) {
+		if isTemplated(check.Namespace) || check.Namespace == "*" {
+			check.Namespace = ""
+		}
+		if !seen[check] {
+			seen[check] = true
+			access = append(access, check)
+		}
+	}
+	add := func(gvk schema.GroupVersionKind, namespace, verb string) {
+		addCheck(k8sclient.AccessCheck{GVK: gvk, Namespace: namespace, Verb: verb})
+	}
+
+	var resources []*configloader.Resource
+	var postActions []configloader.PostAction
+	for _, workflow := range config.AllWorkflows() {
+		for i := range workflow.Resources {
+			resources = append(resources, &workflow.Resources[i])
+		}
+		if workflow.Post != nil {
+			postActions = append(postActions, workflow.Post.PostActions...)
+		}
+	}
+	for _, resource := range resources {
+		if resource.IsMaestroTransport() {
+			continue
+		}
+		manifest, err := resource.UnmarshalManifest()
+		if err != nil || manifest == nil {
+			continue
+		}
+		for _, item := range listItems(manifest) {
+			apiVersion, _ := item["apiVersion"].(string)
+			kind, _ := item["kind"].(string)
+			gvk, ok := parseGVK(apiVersion, kind)
+			if !ok {
+				continue
+			}
+
+			namespace := manifestNamespace(item)
+			if resource.Discovery != nil && resource.Discovery.Namespace != "" {
+				namespace = resource.Discovery.Namespace
+			}
+
+			verbs := []string{"get", "create", "update"}
+			if resource.Discovery != nil && resource.Discovery.BySelectors != nil {
+				verbs = append(verbs, "list")
+			}
+			if resource.RecreateOnChange {
+				verbs = append(verbs, "delete")
+			}
+			for _, verb := range verbs {
+				add(gvk, namespace, verb)
+			}
+		}
+	}
+
+	for _, action := range postActions {
+		if patch := action.K8sPatch; patch != nil {
+			if gvk, ok := parseGVK(patch.APIVersion, patch.Kind); ok {
+				addCheck(k8sclient.AccessCheck{
+					GVK: gvk, Namespace: patch.Namespace, Verb: "patch", Subresource: patch.Subresource,
+				})
+			}
+		}
+	}
+	if finalizer := config.Finalizer; finalizer != nil {
+		if gvk, ok := parseGVK(finalizer.On.APIVersion, finalizer.On.Kind); ok {
+			add(gvk, finalizer.On.Namespace, "get")
+			add(gvk, finalizer.On.Namespace, "patch")
+		}
+	}
+	for _, param := range config.Params {
+		if param.ValueFrom == nil || param.ValueFrom.K8sFieldRef == nil {
+			continue
+		}
+		ref := param.ValueFrom.K8sFieldRef
+		if gvk, ok := parseGVK(ref.APIVersion, ref.Kind); ok {
+			add(gvk, ref.Namespace, "get")
+		}
+	}
+
+	sort.SliceStable(access, func(i, j int) bool {
+		return access[i].GVK.String() < access[j].GVK.String()
+	})
+	return access
+}
+
+// listItems returns the items of a v1 List manifest, which are applied one by
+// one, or the manifest itself
+func listItems(manifest map[string]interface{}) []map[string]interface{} {
+	if manifest["apiVersion"] != "v1" || manifest["kind"] != configloader.KindList {
+		return []map[string]interface{}{manifest}
+	}
+	items, _ := manifest["items"].([]interface{})
+	manifests := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if itemManifest, ok := item.(map[string]interface{}); ok {
+			manifests = append(manifests, itemManifest)
+		}
+	}
+	return manifests
+}
+
+// BrokerCheck returns the check that every subscription of the config exists
+// and may be consumed from. The returned close function releases the Pub/Sub
+// client. Skipped for brokers other than Google Pub/Sub.
+func BrokerCheck(ctx context.Context, config *configloader.Config) (Check, func() error, error) {
+	check := Check{
+		Name: CheckBroker,
+		Remediation: "check clients.broker subscription IDs and the Pub/Sub project, and grant the adapter " +
+			"roles/pubsub.subscriber on each subscription",
+	}
+	noop := func() error { return nil }
+
+	brokerType, err := brokerconsumer.BrokerType()
+	if err != nil {
+		return check, noop, err
+	}
+	if brokerType != brokerconsumer.BrokerTypeGooglePubSub {
+		check.SkipReason = fmt.Sprintf("only the %s broker is checked (configured: %q)",
+			brokerconsumer.BrokerTypeGooglePubSub, brokerType)
+		return check, noop, nil
+	}
+
+	var subscriptionIDs []string
+	for _, sub := range config.Clients.Broker.EffectiveSubscriptions() {
+		subscriptionIDs = append(subscriptionIDs, sub.SubscriptionID)
+	}
+	if len(subscriptionIDs) == 0 {
+		check.SkipReason = "no subscription is configured"
+		return check, noop, nil
+	}
+
+	projectID, err := brokerconsumer.BrokerPubSubProjectID()
+	if err != nil {
+		return check, noop, err
+	}
+	run, closeClient, err := brokerconsumer.NewPubSubSubscriptionCheck(ctx, projectID, subscriptionIDs)
+	if err != nil {
+		return check, noop, err
+	}
+	check.Run = run
+	return check, closeClient, nil
+}
+
+func manifestNamespace(manifest map[string]interface{}) string {
+	metadata, ok := manifest["metadata"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	namespace, ok := metadata["namespace"].(string)
+	if !ok {
+		return ""
+	}
+	return namespace
+}
+
+// parseGVK returns the kind of an apiVersion and kind, false when either is
+// empty, templated or invalid
+func parseGVK(apiVersion, kind string) (schema.GroupVersionKind, bool) {
+	if apiVersion == "" || kind == "" || isTemplated(apiVersion) || isTemplated(kind) {
+		return schema.GroupVersionKind{}, false
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return schema.GroupVersionKind{}, false
+	}
+	return gv.WithKind(kind), true
+}
+
+func isTemplated(s string) bool {
+	return strings.Contains(s, "{{")
+}
@@ -0,0 +1,81 @@
+package selftest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// Modes of the startup RBAC preflight (--preflight)
+const (
+	// PreflightOff skips the preflight
+	PreflightOff = "off"
+	// PreflightWarn logs the missing permissions and starts anyway
+	PreflightWarn = "warn"
+	// PreflightEnforce fails the startup on missing permissions
+	PreflightEnforce = "enforce"
+)
+
+// ValidPreflightModes lists the modes of the startup RBAC preflight
+var ValidPreflightModes = []string{PreflightOff, PreflightWarn, PreflightEnforce}
+
+// AccessChecker reviews the RBAC permissions of the adapter identity, e.g. *k8sclient.Client
+type AccessChecker interface {
+	CheckAccess(ctx context.Context, checks []k8sclient.AccessCheck) error
+}
+
+// Preflight checks at startup that the Kubernetes identity of the adapter has
+// every permission RequiredAccess derives from the config, logging the roles
+// granting the missing ones at debug level. With PreflightEnforce a failed
+// check, including a review the API server could not answer, is returned;
+// with PreflightWarn it is only logged. Skipped with the Maestro transport.
+func Preflight(
+	ctx context.Context,
+	config *configloader.Config,
+	tc transportclient.TransportClient,
+	mode string,
+	log logger.Logger,
+) error {
+	if mode == PreflightOff {
+		return nil
+	}
+	checker, ok := tc.(AccessChecker)
+	if !ok {
+		log.Debug(ctx, "RBAC preflight skipped: the transport is not Kubernetes")
+		return nil
+	}
+
+	access := RequiredAccess(config)
+	err := checkRBAC(ctx, checker, access, config.Adapter.Name, log)
+	switch {
+	case err == nil:
+		log.Infof(ctx, "RBAC preflight passed: %d permission(s) checked", len(access))
+		return nil
+	case mode == PreflightEnforce:
+		return fmt.Errorf("RBAC preflight failed: %w", err)
+	default:
+		errCtx := logger.WithErrorField(ctx, err)
+		log.Warnf(errCtx, "RBAC preflight failed, starting anyway (--preflight=%s)", mode)
+		return nil
+	}
+}
+
+// checkRBAC reviews access, logging the roles granting the denied accesses at
+// debug level
+func checkRBAC(
+	ctx context.Context, checker AccessChecker, access []k8sclient.AccessCheck, roleName string, log logger.Logger,
+) error {
+	err := checker.CheckAccess(ctx, access)
+	var denied *k8sclient.AccessDeniedError
+	if errors.As(err, &denied) {
+		if roles, rbacErr := denied.SuggestedRBAC(roleName); rbacErr == nil && roles != "" {
+			log.Debugf(ctx, "Roles granting the missing permissions:\n%s", roles)
+		}
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+// Package selftest checks the connectivity and permissions of the adapter's
+// dependencies. The checks are shared by the self-test command and the
+// readiness endpoint of the health server.
+package selftest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/health"
+)
+
+// Statuses of a check result
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// DefaultTimeout bounds each check of a self-test
+const DefaultTimeout = 10 * time.Second
+
+// Check is a named dependency check
+type Check struct {
+	// Run probes the dependency; nil when the check is skipped
+	Run health.CheckFunc
+	// Name identifies the check, e.g. hyperfleet_api
+	Name string
+	// Remediation tells the operator what to look at when the check fails
+	Remediation string
+	// SkipReason is why the check is not run, when Run is nil
+	SkipReason string
+	// Readiness reports whether /readyz runs the check as well
+	Readiness bool
+}
+
+// Result is the outcome of a check
+type Result struct {
+	Name        string        `json:"name"`
+	Status      string        `json:"status"`
+	Error       string        `json:"error,omitempty"`
+	Remediation string        `json:"remediation,omitempty"`
+	Duration    time.Duration `json:"duration_ns"`
+}
+
+// Report is the outcome of a self-test
+type Report struct {
+	Results []Result `json:"checks"`
+	Passed  bool     `json:"passed"`
+}
+
+// Run runs the checks concurrently, each bounded by timeout, and returns their
+// results in the order of checks. The report passes when no check failed.
+func Run(ctx context.Context, checks []Check, timeout time.Duration) *Report {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	report := &Report{Results: make([]Result, len(checks)), Passed: true}
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		if check.Run == nil {
+			report.Results[i] = Result{Name: check.Name, Status: StatusSkip, Error: check.SkipReason}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			start := time.Now()
+			err := check.Run(checkCtx)
+			result := Result{Name: check.Name, Status: StatusPass, Duration: time.Since(start)}
+			if err != nil {
+				result.Status = StatusFail
+				result.Error = err.Error()
+				result.Remediation = check.Remediation
+			}
+			report.Results[i] = result
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, result := range report.Results {
+		if result.Status == StatusFail {
+			report.Passed = false
+		}
+	}
+	return report
+}
+
+// WriteText writes the report as a table, followed by the error and
+// remediation of every failed check
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "CHECK\tSTATUS\tDURATION") //nolint:errcheck // flushed below
+	for _, result := range r.Results {
+		//nolint:errcheck // flushed below
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", result.Name, result.Status, result.Duration.Round(time.Millisecond))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, result := range r.Results {
+		switch result.Status {
+		case StatusFail:
+			if _, err := fmt.Fprintf(w, "\n%s failed: %s\n  hint: %s\n",
+				result.Name, result.Error, result.Remediation); err != nil {
+				return err
+			}
+		case StatusSkip:
+			if _, err := fmt.Fprintf(w, "\n%s skipped: %s\n", result.Name, result.Error); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal self-test report: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
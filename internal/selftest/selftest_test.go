@@ -0,0 +1,254 @@
+package selftest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestRun(t *testing.T) {
+	checks := []Check{
+		{Name: "ok", Run: func(context.Context) error { return nil }},
+		{Name: "broken", Run: func(context.Context) error { return errors.New("refused") }, Remediation: "fix it"},
+		{Name: "slow", Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+		{Name: "skipped", SkipReason: "not configured"},
+	}
+
+	report := Run(context.Background(), checks, 50*time.Millisecond)
+
+	require.Len(t, report.Results, 4)
+	assert.False(t, report.Passed)
+	assert.Equal(t, StatusPass, report.Results[0].Status)
+	assert.Equal(t, StatusFail, report.Results[1].Status)
+	assert.Equal(t, "refused", report.Results[1].Error)
+	assert.Equal(t, "fix it", report.Results[1].Remediation)
+	assert.Equal(t, StatusFail, report.Results[2].Status)
+	assert.ErrorContains(t, context.DeadlineExceeded, report.Results[2].Error)
+	assert.Equal(t, StatusSkip, report.Results[3].Status)
+	assert.Equal(t, "not configured", report.Results[3].Error)
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteText(&out))
+	assert.Contains(t, out.String(), "broken failed: refused\n  hint: fix it")
+	assert.Contains(t, out.String(), "skipped skipped: not configured")
+}
+
+func TestRun_PassesWithSkippedChecks(t *testing.T) {
+	report := Run(context.Background(), []Check{
+		{Name: "ok", Run: func(context.Context) error { return nil }},
+		{Name: "skipped", SkipReason: "not configured"},
+	}, time.Second)
+	assert.True(t, report.Passed)
+}
+
+func TestRequiredAccess(t *testing.T) {
+	config := &configloader.Config{
+		Resources: []configloader.Resource{
+			{
+				Name: "namespace",
+				Manifest: map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "Namespace",
+					"metadata":   map[string]interface{}{"name": "{{ .clusterId }}"},
+				},
+				Discovery: &configloader.DiscoveryConfig{ByName: "{{ .clusterId }}"},
+			},
+			{
+				Name: "job",
+				Manifest: map[string]interface{}{
+					"apiVersion": "batch/v1",
+					"kind":       "Job",
+					"metadata":   map[string]interface{}{"namespace": "jobs"},
+				},
+				Discovery: &configloader.DiscoveryConfig{
+					BySelectors: &configloader.SelectorConfig{LabelSelector: map[string]string{"app": "x"}},
+				},
+				RecreateOnChange: true,
+			},
+			{
+				Name: "bundle",
+				Manifest: map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       configloader.KindList,
+					"items": []interface{}{
+						map[string]interface{}{
+							"apiVersion": "v1",
+							"kind":       "Namespace",
+							"metadata":   map[string]interface{}{"name": "{{ .clusterId }}"},
+						},
+						map[string]interface{}{
+							"apiVersion": "v1",
+							"kind":       "ConfigMap",
+							"metadata":   map[string]interface{}{"name": "settings", "namespace": "config"},
+						},
+					},
+				},
+				Discovery: &configloader.DiscoveryConfig{ByName: "{{ .clusterId }}"},
+			},
+			{
+				Name:      "work",
+				Transport: &configloader.TransportConfig{Client: configloader.TransportClientMaestro},
+				Manifest:  map[string]interface{}{"apiVersion": "work.open-cluster-management.io/v1", "kind": "ManifestWork"},
+				Discovery: &configloader.DiscoveryConfig{ByName: "work"},
+			},
+		},
+		Workflows: []configloader.Workflow{
+			{
+				Name:  "teardown",
+				Match: &configloader.WorkflowMatch{EventTypes: []string{"cluster.deleted"}},
+				Resources: []configloader.Resource{
+					{
+						Name: "credentials",
+						Manifest: map[string]interface{}{
+							"apiVersion": "v1",
+							"kind":       "Secret",
+							"metadata":   map[string]interface{}{"name": "credentials", "namespace": "config"},
+						},
+						Discovery: &configloader.DiscoveryConfig{ByName: "credentials"},
+					},
+				},
+			},
+		},
+	}
+
+	job := schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}
+	namespace := schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}
+	configMap := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+	secret := schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+	assert.Equal(t, []k8sclient.AccessCheck{
+		{GVK: configMap, Namespace: "config", Verb: "get"},
+		{GVK: configMap, Namespace: "config", Verb: "create"},
+		{GVK: configMap, Namespace: "config", Verb: "update"},
+		{GVK: namespace, Verb: "get"},
+		{GVK: namespace, Verb: "create"},
+		{GVK: namespace, Verb: "update"},
+		{GVK: secret, Namespace: "config", Verb: "get"},
+		{GVK: secret, Namespace: "config", Verb: "create"},
+		{GVK: secret, Namespace: "config", Verb: "update"},
+		{GVK: job, Namespace: "jobs", Verb: "get"},
+		{GVK: job, Namespace: "jobs", Verb: "create"},
+		{GVK: job, Namespace: "jobs", Verb: "update"},
+		{GVK: job, Namespace: "jobs", Verb: "list"},
+		{GVK: job, Namespace: "jobs", Verb: "delete"},
+	}, RequiredAccess(config))
+}
+
+func TestRequiredAccess_PatchesAndFinalizer(t *testing.T) {
+	config := &configloader.Config{
+		Params: []configloader.Parameter{
+			{Name: "clusterId", Source: "event.id"},
+			{Name: "infraId", ValueFrom: &configloader.ParamValueFrom{K8sFieldRef: &configloader.K8sFieldRef{
+				ObjectRef: configloader.ObjectRef{
+					APIVersion: "hive.openshift.io/v1", Kind: "ClusterDeployment", Namespace: "hive", Name: "cd",
+				},
+				FieldPath: "spec.clusterMetadata.infraID",
+			}}},
+		},
+		Finalizer: &configloader.Finalizer{
+			Name: "hyperfleet.io/cleanup",
+			On:   configloader.ObjectRef{APIVersion: "hyperfleet.io/v1", Kind: "Cluster", Name: "{{ .clusterId }}"},
+		},
+		Post: &configloader.PostConfig{PostActions: []configloader.PostAction{
+			{K8sPatch: &configloader.K8sPatchAction{
+				APIVersion: "batch/v1", Kind: "Job", Namespace: "{{ .namespace }}", Name: "job", Subresource: "status",
+			}},
+			{K8sPatch: &configloader.K8sPatchAction{APIVersion: "v1", Kind: "{{ .kind }}", Name: "templated"}},
+			{ActionBase: configloader.ActionBase{Name: "api", APICall: &configloader.APICall{Method: "GET", URL: "/"}}},
+		}},
+	}
+
+	cluster := schema.GroupVersionKind{Group: "hyperfleet.io", Version: "v1", Kind: "Cluster"}
+	job := schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}
+	clusterDeployment := schema.GroupVersionKind{Group: "hive.openshift.io", Version: "v1", Kind: "ClusterDeployment"}
+	assert.Equal(t, []k8sclient.AccessCheck{
+		{GVK: job, Verb: "patch", Subresource: "status"},
+		{GVK: clusterDeployment, Namespace: "hive", Verb: "get"},
+		{GVK: cluster, Verb: "get"},
+		{GVK: cluster, Verb: "patch"},
+	}, RequiredAccess(config))
+}
+
+// accessChecker is a Kubernetes transport client whose access reviews return err
+type accessChecker struct {
+	*k8sclient.MockK8sClient
+	err    error
+	checks []k8sclient.AccessCheck
+}
+
+func (a *accessChecker) CheckAccess(_ context.Context, checks []k8sclient.AccessCheck) error {
+	a.checks = checks
+	return a.err
+}
+
+func TestPreflight(t *testing.T) {
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "my-adapter"},
+		Resources: []configloader.Resource{{
+			Name: "namespace",
+			Manifest: map[string]interface{}{
+				"apiVersion": "v1", "kind": "Namespace", "metadata": map[string]interface{}{"name": "ns"},
+			},
+		}},
+	}
+	denied := &k8sclient.AccessDeniedError{Denied: []k8sclient.DeniedAccess{{
+		AccessCheck:   k8sclient.AccessCheck{GVK: schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, Verb: "create"},
+		Resource:      schema.GroupVersionResource{Version: "v1", Resource: "namespaces"},
+		ClusterScoped: true,
+	}}}
+	tests := []struct {
+		err        error
+		name       string
+		mode       string
+		wantErr    string
+		wantChecks int
+	}{
+		{name: "allowed", mode: PreflightEnforce, wantChecks: 3},
+		{name: "denied and enforced", mode: PreflightEnforce, err: denied, wantChecks: 3,
+			wantErr: "RBAC preflight failed: missing permissions: create Namespace in all namespaces"},
+		{name: "denied with a warning", mode: PreflightWarn, err: denied, wantChecks: 3},
+		{name: "API unavailable and enforced", mode: PreflightEnforce, err: errors.New("connection refused"),
+			wantChecks: 3, wantErr: "connection refused"},
+		{name: "API unavailable with a warning", mode: PreflightWarn, err: errors.New("connection refused"),
+			wantChecks: 3},
+		{name: "off", mode: PreflightOff, err: denied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &accessChecker{MockK8sClient: k8sclient.NewMockK8sClient(), err: tt.err}
+			err := Preflight(context.Background(), config, checker, tt.mode, logger.NewTestLogger())
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Len(t, checker.checks, tt.wantChecks)
+		})
+	}
+}
+
+func TestHyperfleetAPIPingPath(t *testing.T) {
+	config := &configloader.Config{}
+	assert.Equal(t, "/api/hyperfleet/v1/clusters?pageSize=1", HyperfleetAPIPingPath(config))
+
+	config.Health.HyperfleetAPIPath = "/healthz"
+	assert.Equal(t, "/healthz", HyperfleetAPIPingPath(config))
+}
+
+func TestPreflight_NotKubernetes(t *testing.T) {
+	err := Preflight(context.Background(), &configloader.Config{}, k8sclient.NewMockK8sClient(), PreflightEnforce,
+		logger.NewTestLogger())
+	require.NoError(t, err)
+}
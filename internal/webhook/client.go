@@ -0,0 +1,227 @@
+// Package webhook delivers post action payloads to arbitrary HTTP receivers,
+// such as Slack incoming webhooks or generic notification endpoints. Its client
+// is separate from the HyperFleet API client, so its TLS and retry settings do
+// not change how the adapter talks to the API.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/httpx"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/retrybudget"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// Defaults of ClientConfig
+const (
+	DefaultTimeout       = 10 * time.Second
+	DefaultRetryAttempts = 3
+	DefaultBaseDelay     = 500 * time.Millisecond
+	DefaultMaxDelay      = 10 * time.Second
+)
+
+// DefaultSignatureHeader is the header carrying the body signature
+const DefaultSignatureHeader = "X-Hyperfleet-Signature-256"
+
+// SignaturePrefix prefixes the hex-encoded HMAC-SHA256 of a signature header value
+const SignaturePrefix = "sha256="
+
+// maxDrainedResponseBytes bounds the response body read to reuse the connection
+const maxDrainedResponseBytes = 64 * 1024
+
+// ClientConfig configures the webhook client (clients.webhook). Zero values use the defaults.
+type ClientConfig struct {
+	// CAFile is a PEM bundle of CAs trusted for HTTPS receivers, in addition to the system pool
+	CAFile string `yaml:"ca_file,omitempty" mapstructure:"ca_file"`
+	// Timeout bounds each delivery attempt
+	Timeout time.Duration `yaml:"timeout,omitempty" mapstructure:"timeout" validate:"gte=0"`
+	// BaseDelay is the delay before the first retry, doubled for every later one
+	BaseDelay time.Duration `yaml:"base_delay,omitempty" mapstructure:"base_delay" validate:"gte=0"`
+	// MaxDelay caps the delay between retries
+	MaxDelay time.Duration `yaml:"max_delay,omitempty" mapstructure:"max_delay" validate:"gte=0"`
+	// RetryAttempts is the number of delivery attempts
+	RetryAttempts int `yaml:"retry_attempts,omitempty" mapstructure:"retry_attempts" validate:"gte=0"`
+	// InsecureSkipVerify disables the verification of receiver certificates
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify,omitempty" mapstructure:"insecure_skip_verify"`
+}
+
+// Request is a payload to deliver
+type Request struct {
+	// Headers are sent with every attempt
+	Headers map[string]string
+	URL     string
+	// SignatureHeader is the header carrying the signature, DefaultSignatureHeader if empty
+	SignatureHeader string
+	// Body is the delivered payload, sent as application/json unless Headers sets Content-Type
+	Body []byte
+	// Secret signs Body with HMAC-SHA256 when set
+	Secret []byte
+	// Timeout overrides the client timeout of each attempt when positive
+	Timeout time.Duration
+	// RetryAttempts overrides the client retry attempts when positive
+	RetryAttempts int
+}
+
+// Delivery is the outcome of a delivery
+type Delivery struct {
+	// StatusCode is the HTTP status of the last response, zero if none was received
+	StatusCode int
+	// Attempts is the number of attempts made
+	Attempts int
+	// Latency is how long the delivery took, retries included
+	Latency time.Duration
+}
+
+// Client delivers webhook requests with retries
+type Client struct {
+	httpClient *http.Client
+	clock      clock.Clock
+	log        logger.Logger
+	config     ClientConfig
+}
+
+// NewClient creates a webhook client over a transport of pool, which it shares
+// with the other clients of the pool unless it has TLS settings (nil creates a
+// pool of its own). It fails when the CA file cannot be loaded.
+func NewClient(config ClientConfig, pool *httpx.Pool, log logger.Logger, clk clock.Clock) (*Client, error) {
+	if pool == nil {
+		pool = httpx.NewPool(nil, nil)
+	}
+	var tlsConfig *httpx.TLSConfig
+	if config.CAFile != "" || config.InsecureSkipVerify {
+		tlsConfig = &httpx.TLSConfig{CAFile: config.CAFile, InsecureSkipVerify: config.InsecureSkipVerify}
+	}
+	transport, err := pool.RoundTripper(httpx.ClientWebhook, tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook TLS settings: %w", err)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.RetryAttempts <= 0 {
+		config.RetryAttempts = DefaultRetryAttempts
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = DefaultBaseDelay
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = DefaultMaxDelay
+	}
+	return &Client{
+		httpClient: &http.Client{Transport: transport},
+		clock:      clock.OrReal(clk),
+		log:        log,
+		config:     config,
+	}, nil
+}
+
+// Sign returns the signature header value of body: SignaturePrefix followed by
+// the hex-encoded HMAC-SHA256 of body under secret
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return SignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliver posts the request body to its URL. Network errors, 408, 429 and 5xx
+// responses are retried with exponential backoff; other statuses are final.
+// The body and its signature are the same on every attempt. A delivery that
+// does not end with a 2xx response returns an error along with the Delivery.
+func (c *Client) Deliver(ctx context.Context, req *Request) (*Delivery, error) {
+	attempts := c.config.RetryAttempts
+	if req.RetryAttempts > 0 {
+		attempts = req.RetryAttempts
+	}
+	timeout := c.config.Timeout
+	if req.Timeout > 0 {
+		timeout = req.Timeout
+	}
+	signatureHeader := req.SignatureHeader
+	if signatureHeader == "" {
+		signatureHeader = DefaultSignatureHeader
+	}
+	var signature string
+	if len(req.Secret) > 0 {
+		signature = Sign(req.Secret, req.Body)
+	}
+
+	delivery := &Delivery{}
+	start := c.clock.Now()
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		delivery.Attempts = attempt
+		statusCode, err := c.attempt(ctx, req, timeout, signatureHeader, signature)
+		delivery.StatusCode = statusCode
+		delivery.Latency = c.clock.Since(start)
+		if err == nil {
+			return delivery, nil
+		}
+		lastErr = err
+		if !retryable(statusCode) || ctx.Err() != nil {
+			break
+		}
+		if attempt < attempts {
+			delay := min(c.config.BaseDelay<<(attempt-1), c.config.MaxDelay)
+			if budgetErr := retrybudget.Reserve(ctx, delay); budgetErr != nil {
+				delivery.Latency = c.clock.Since(start)
+				return delivery, fmt.Errorf("webhook delivery stopped after %d attempt(s): %w; last attempt: %w",
+					attempt, budgetErr, err)
+			}
+			c.log.Warnf(ctx, "Webhook delivery failed (attempt %d/%d), retrying in %s: %v",
+				attempt, attempts, delay, err)
+			if err := c.clock.Sleep(ctx, delay); err != nil {
+				return delivery, fmt.Errorf("webhook delivery cancelled during retry: %w", err)
+			}
+		}
+	}
+	delivery.Latency = c.clock.Since(start)
+	return delivery, fmt.Errorf("webhook delivery failed after %d attempt(s): %w", delivery.Attempts, lastErr)
+}
+
+// attempt makes one delivery attempt, returning the response status, zero if
+// none was received, and an error unless it is 2xx
+func (c *Client) attempt(
+	ctx context.Context, req *Request, timeout time.Duration, signatureHeader, signature string,
+) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for name, value := range req.Headers {
+		httpReq.Header.Set(name, value)
+	}
+	if signature != "" {
+		httpReq.Header.Set(signatureHeader, signature)
+	}
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // the body is only drained
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainedResponseBytes))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryable reports whether a failed attempt with statusCode is retried: no
+// response, request timeout, too many requests or a server error
+func retryable(statusCode int) bool {
+	return statusCode == 0 ||
+		statusCode == http.StatusRequestTimeout ||
+		statusCode == http.StatusTooManyRequests ||
+		statusCode >= 500
+}
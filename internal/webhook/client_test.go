@@ -0,0 +1,204 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver is a webhook receiver answering with statuses in turn, the last one repeated
+type receiver struct {
+	server   *httptest.Server
+	bodies   [][]byte
+	headers  []http.Header
+	statuses []int
+	mu       sync.Mutex
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	r := &receiver{statuses: statuses}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.bodies = append(r.bodies, body)
+		r.headers = append(r.headers, req.Header.Clone())
+		w.WriteHeader(r.statuses[min(len(r.bodies), len(r.statuses))-1])
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func newTestClient(t *testing.T, config ClientConfig) *Client {
+	config.BaseDelay = time.Millisecond
+	client, err := NewClient(config, nil, logger.NewTestLogger(), nil)
+	require.NoError(t, err)
+	return client
+}
+
+// verify is how a receiver checks a signature: recompute the HMAC over the exact
+// body it received and compare in constant time
+func verify(secret, body []byte, signature string) bool {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	expected := SignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func TestDeliver_Signature(t *testing.T) {
+	secret := []byte("shared-secret")
+	body := []byte(`{"text":"cluster-1 is Ready","id":"cluster-1"}`)
+	tests := []struct {
+		name       string
+		header     string
+		wantHeader string
+	}{
+		{name: "default header", wantHeader: DefaultSignatureHeader},
+		{name: "configured header", header: "X-Signature", wantHeader: "X-Signature"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recv := newReceiver(t, http.StatusInternalServerError, http.StatusNoContent)
+			client := newTestClient(t, ClientConfig{})
+
+			delivery, err := client.Deliver(context.Background(), &Request{
+				URL:             recv.server.URL,
+				Body:            body,
+				Headers:         map[string]string{"X-Source": "adapter"},
+				Secret:          secret,
+				SignatureHeader: tt.header,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNoContent, delivery.StatusCode)
+			assert.Equal(t, 2, delivery.Attempts)
+			assert.Positive(t, delivery.Latency)
+
+			// Every attempt delivers the same body, signed over its exact bytes
+			require.Len(t, recv.bodies, 2)
+			for i, delivered := range recv.bodies {
+				assert.Equal(t, body, delivered)
+				signature := recv.headers[i].Get(tt.wantHeader)
+				assert.True(t, verify(secret, delivered, signature), "signature %q", signature)
+				assert.False(t, verify([]byte("other-secret"), delivered, signature))
+				assert.Equal(t, "adapter", recv.headers[i].Get("X-Source"))
+				assert.Equal(t, "application/json", recv.headers[i].Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestDeliver_Unsigned(t *testing.T) {
+	recv := newReceiver(t, http.StatusOK)
+	client := newTestClient(t, ClientConfig{})
+
+	_, err := client.Deliver(context.Background(), &Request{URL: recv.server.URL, Body: []byte(`{}`)})
+	require.NoError(t, err)
+	assert.Empty(t, recv.headers[0].Get(DefaultSignatureHeader))
+}
+
+func TestDeliver_Retries(t *testing.T) {
+	tests := []struct {
+		name          string
+		statuses      []int
+		config        ClientConfig
+		retryAttempts int
+		wantStatus    int
+		wantAttempts  int
+		wantErr       bool
+	}{
+		{
+			name:         "server errors are retried",
+			statuses:     []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusOK},
+			wantStatus:   http.StatusOK,
+			wantAttempts: 3,
+		},
+		{
+			name:         "retries are exhausted",
+			statuses:     []int{http.StatusServiceUnavailable},
+			config:       ClientConfig{RetryAttempts: 2},
+			wantStatus:   http.StatusServiceUnavailable,
+			wantAttempts: 2,
+			wantErr:      true,
+		},
+		{
+			name:          "request retry attempts override the client",
+			statuses:      []int{http.StatusServiceUnavailable},
+			config:        ClientConfig{RetryAttempts: 2},
+			retryAttempts: 4,
+			wantStatus:    http.StatusServiceUnavailable,
+			wantAttempts:  4,
+			wantErr:       true,
+		},
+		{
+			name:         "client errors are final",
+			statuses:     []int{http.StatusUnauthorized, http.StatusOK},
+			wantStatus:   http.StatusUnauthorized,
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recv := newReceiver(t, tt.statuses...)
+			client := newTestClient(t, tt.config)
+
+			delivery, err := client.Deliver(context.Background(), &Request{
+				URL: recv.server.URL, Body: []byte(`{}`), RetryAttempts: tt.retryAttempts,
+			})
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantStatus, delivery.StatusCode)
+			assert.Equal(t, tt.wantAttempts, delivery.Attempts)
+			assert.Len(t, recv.bodies, tt.wantAttempts)
+		})
+	}
+}
+
+func TestDeliver_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	client := newTestClient(t, ClientConfig{RetryAttempts: 2})
+
+	delivery, err := client.Deliver(context.Background(), &Request{
+		URL: server.URL, Body: []byte(`{}`), Timeout: 20 * time.Millisecond,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "deadline exceeded")
+	assert.Equal(t, 0, delivery.StatusCode)
+	assert.Equal(t, 2, delivery.Attempts)
+}
+
+func TestNewClient_CAFile(t *testing.T) {
+	_, err := NewClient(ClientConfig{CAFile: "/nonexistent/ca.pem"}, nil, logger.NewTestLogger(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook TLS settings: failed to read CA file")
+
+	notPEM := t.TempDir() + "/ca.pem"
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+	_, err = NewClient(ClientConfig{CAFile: notPEM}, nil, logger.NewTestLogger(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "contains no PEM certificates")
+}
@@ -0,0 +1,58 @@
+// Package adapter is the supported Go API for embedding the adapter execution
+// engine in another binary, instead of running the adapter as a deployment.
+//
+// An embedder loads or builds a config, creates an Executor with the clients of
+// its HyperFleet API and of the backend the resources are applied to, and
+// passes it CloudEvents:
+//
+//	config, err := adapter.Load("adapter-config.yaml", "adapter-task-config.yaml")
+//	...
+//	exec, err := adapter.NewBuilder().
+//		WithConfig(config).
+//		WithAPIClient(apiClient).
+//		WithTransportClient(k8sClient).
+//		WithLogger(log).
+//		Build()
+//	...
+//	result := exec.ExecuteEvent(ctx, evt)
+//
+// The types of the package are aliases of the types the adapter itself uses, so
+// values pass between the two without conversion. Only the identifiers declared
+// here are supported: the other exported fields and methods of the aliased types
+// may change between releases. FakeAPIClient and FakeK8sClient are in-memory
+// clients for tests and examples.
+package adapter
+
+import (
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+)
+
+// Config is the unified adapter configuration: a deployment config merged with
+// a task config
+type Config = configloader.Config
+
+// AdapterConfig is the deployment config (adapter-config.yaml): the adapter
+// identity, its clients, logging and health settings
+type AdapterConfig = configloader.AdapterConfig
+
+// TaskConfig is the task config (adapter-task-config.yaml): params,
+// preconditions, resources and post actions
+type TaskConfig = configloader.AdapterTaskConfig
+
+// Load loads the deployment and task config files, validates them and returns
+// their unified Config. The HYPERFLEET_ADAPTER_* environment variables override
+// the deployment config like they do for the adapter binary, and files
+// referenced by the task config are resolved relative to it.
+func Load(adapterConfigPath, taskConfigPath string) (*Config, error) {
+	return configloader.LoadConfig(
+		configloader.WithAdapterConfigPath(adapterConfigPath),
+		configloader.WithTaskConfigPath(taskConfigPath),
+	)
+}
+
+// Validate validates a deployment and a task config built in code and returns
+// their unified Config. Environment variables are not applied, and the task
+// config must not reference files.
+func Validate(adapterConfig *AdapterConfig, taskConfig *TaskConfig) (*Config, error) {
+	return configloader.Validate(adapterConfig, taskConfig)
+}
@@ -0,0 +1,80 @@
+package adapter
+
+import (
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/manifest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/transportclient"
+)
+
+// APIClient is the client of the HyperFleet API, used by the api_call of
+// preconditions and post actions
+type APIClient = hyperfleetapi.Client
+
+// APIRequest is a request of an APIClient
+type APIRequest = hyperfleetapi.Request
+
+// APIResponse is the response to an APIRequest
+type APIResponse = hyperfleetapi.Response
+
+// APIRequestOption sets an option of an APIRequest
+type APIRequestOption = hyperfleetapi.RequestOption
+
+// DefaultAPITarget is the name APIClient.Target resolves to the client itself
+const DefaultAPITarget = hyperfleetapi.DefaultTarget
+
+// TransportClient applies the rendered resources and discovers them, through
+// the Kubernetes API or Maestro ManifestWorks
+type TransportClient = transportclient.TransportClient
+
+// TransportContext is the per-request routing of a TransportClient, nil for Kubernetes
+type TransportContext = transportclient.TransportContext
+
+// ApplyOptions are the options of TransportClient.ApplyResource
+type ApplyOptions = transportclient.ApplyOptions
+
+// ApplyResult is the outcome of TransportClient.ApplyResource
+type ApplyResult = transportclient.ApplyResult
+
+// Operation is the operation ApplyResource performed
+type Operation = manifest.Operation
+
+// Operations of an ApplyResult
+const (
+	OperationCreate   = manifest.OperationCreate
+	OperationUpdate   = manifest.OperationUpdate
+	OperationRecreate = manifest.OperationRecreate
+	OperationSkip     = manifest.OperationSkip
+)
+
+// Discovery selects the resources of TransportClient.DiscoverResources
+type Discovery = manifest.Discovery
+
+// DiscoveryConfig is a Discovery by name or by label selector
+type DiscoveryConfig = manifest.DiscoveryConfig
+
+// K8sClient is a TransportClient applying resources through the Kubernetes
+// API, with the create, update, patch and delete operations of k8s_patch and
+// finalizer steps
+type K8sClient = k8sclient.K8sClient
+
+// K8sPatchOptions are the options of K8sClient.PatchResource
+type K8sPatchOptions = k8sclient.PatchOptions
+
+// FakeAPIClient is an in-memory APIClient returning configured responses and
+// recording the requests it receives
+type FakeAPIClient = hyperfleetapi.MockClient
+
+// NewFakeAPIClient returns a FakeAPIClient answering every request with 200 OK
+func NewFakeAPIClient() *FakeAPIClient {
+	return hyperfleetapi.NewMockClient()
+}
+
+// FakeK8sClient is an in-memory K8sClient storing the applied resources by
+// "namespace/name"
+type FakeK8sClient = k8sclient.MockK8sClient
+
+// NewFakeK8sClient returns an empty FakeK8sClient
+func NewFakeK8sClient() *FakeK8sClient {
+	return k8sclient.NewMockK8sClient()
+}
@@ -0,0 +1,119 @@
+package adapter_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/adapter"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/eventtest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// newLogger returns a logger keeping the example output clean
+func newLogger() logger.Logger {
+	log, err := logger.NewLogger(logger.Config{Level: "error", Output: "stderr"})
+	if err != nil {
+		panic(err)
+	}
+	return log
+}
+
+// Executes an event against config files, with fake clients standing in for
+// the HyperFleet API and the Kubernetes API
+func Example() {
+	config, err := adapter.Load("testdata/adapter-config.yaml", "testdata/adapter-task-config.yaml")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	apiClient := adapter.NewFakeAPIClient()
+	apiClient.GetResponse = &adapter.APIResponse{
+		StatusCode: 200, Status: "200 OK", Body: []byte(`{"name":"production"}`),
+	}
+	k8sClient := adapter.NewFakeK8sClient()
+
+	exec, err := adapter.NewBuilder().
+		WithConfig(config).
+		WithAPIClient(apiClient).
+		WithTransportClient(k8sClient).
+		WithLogger(newLogger()).
+		Build()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	evt := eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1","generation":2}`).Build()
+	result := exec.ExecuteEvent(context.Background(), evt)
+
+	fmt.Println("status:", result.Status)
+	for _, resource := range result.ResourceResults {
+		fmt.Printf("resource %s: %s %s/%s\n", resource.Name, resource.Operation, resource.Kind, resource.ResourceName)
+	}
+	applied := k8sClient.Resources["hyperfleet/cluster-1-config"]
+	fmt.Println("cluster_name:", applied.Object["data"].(map[string]interface{})["cluster_name"])
+	status := apiClient.GetLastRequest()
+	fmt.Println("reported:", status.Method, status.URL, string(status.Body))
+	// Output:
+	// status: success
+	// resource clusterConfig: create ConfigMap/cluster-1-config
+	// cluster_name: production
+	// reported: POST /api/hyperfleet/v1/clusters/cluster-1/statuses {"adapter":"example-adapter","observed_generation":2}
+}
+
+// Validates a config built in code, and reports a failed execution
+func ExampleValidate() {
+	adapterConfig := &adapter.AdapterConfig{}
+	adapterConfig.Adapter.Name = "inline-adapter"
+	adapterConfig.Adapter.Version = "0.1.0"
+	adapterConfig.Clients.HyperfleetAPI.BaseURL = "https://hyperfleet.example.com"
+	adapterConfig.Clients.HyperfleetAPI.Timeout = 10 * time.Second
+	adapterConfig.Clients.Kubernetes.APIVersion = "v1"
+
+	taskConfig := &adapter.TaskConfig{}
+	if err := yaml.Unmarshal([]byte(`
+params:
+  - name: clusterId
+    source: event.id
+    required: true
+preconditions:
+  - name: fetch-cluster
+    api_call:
+      method: GET
+      url: "/api/hyperfleet/v1/clusters/{{ .clusterId }}"
+`), taskConfig); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	config, err := adapter.Validate(adapterConfig, taskConfig)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	apiClient := adapter.NewFakeAPIClient()
+	apiClient.GetResponse = &adapter.APIResponse{StatusCode: 503, Status: "503 Service Unavailable"}
+	exec, err := adapter.NewExecutor(adapter.ExecutorConfig{
+		Config:          config,
+		APIClient:       apiClient,
+		TransportClient: adapter.NewFakeK8sClient(),
+		Logger:          newLogger(),
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	evt := eventtest.NewEvent().WithDataJSON(`{"id":"cluster-1"}`).Build()
+	result := exec.ExecuteEvent(context.Background(), evt)
+
+	fmt.Println("status:", result.Status)
+	fmt.Println("error code:", adapter.ErrorCodeOf(result.Errors[adapter.PhasePreconditions]))
+	// Output:
+	// status: failed
+	// error code: APIUnexpectedStatus
+}
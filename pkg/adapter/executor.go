@@ -0,0 +1,144 @@
+package adapter
+
+import (
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/executor"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
+)
+
+// Executor runs the phases of the config against CloudEvents. Its supported
+// methods are Execute, ExecuteEvent and CreateHandler, the broker handler of
+// the adapter binary. It is safe for concurrent use.
+type Executor = executor.Executor
+
+// ExecutionResult is the result of an execution: its status, the results of
+// each step and the errors by phase
+type ExecutionResult = executor.ExecutionResult
+
+// PreconditionResult is the result of a precondition of an execution
+type PreconditionResult = executor.PreconditionResult
+
+// ResourceResult is the result of a resource of an execution
+type ResourceResult = executor.ResourceResult
+
+// PostActionResult is the result of a post action of an execution
+type PostActionResult = executor.PostActionResult
+
+// ExecutionStatus is the status of an execution or of one of its steps
+type ExecutionStatus = executor.ExecutionStatus
+
+// Statuses of an execution. An execution whose preconditions were not met
+// succeeds with ExecutionResult.ResourcesSkipped.
+const (
+	StatusSuccess = executor.StatusSuccess
+	StatusFailed  = executor.StatusFailed
+)
+
+// ExecutionPhase is a phase of an execution, the key of ExecutionResult.Errors
+type ExecutionPhase = executor.ExecutionPhase
+
+// Phases of an execution
+const (
+	PhaseSchemaValidation  = executor.PhaseSchemaValidation
+	PhaseParamExtraction   = executor.PhaseParamExtraction
+	PhaseWorkflowSelection = executor.PhaseWorkflowSelection
+	PhaseExecutionFence    = executor.PhaseExecutionFence
+	PhasePreconditions     = executor.PhasePreconditions
+	PhaseResources         = executor.PhaseResources
+	PhasePostActions       = executor.PhasePostActions
+)
+
+// ErrorCode classifies the error of an execution, e.g. API_CALL_FAILED
+type ErrorCode = executor.ErrorCode
+
+// ErrorCodeOf returns the ErrorCode of an error of ExecutionResult.Errors, ""
+// for nil
+func ErrorCodeOf(err error) ErrorCode {
+	return executor.ErrorCodeOf(err)
+}
+
+// RuntimeMetadata identifies the process running the executor in the adapter
+// metadata of post payloads
+type RuntimeMetadata = executor.RuntimeMetadata
+
+// ExecutorConfig configures an Executor
+type ExecutorConfig struct {
+	// Config is the unified configuration, from Load or Validate (required)
+	Config *Config
+	// APIClient calls the HyperFleet API (required)
+	APIClient APIClient
+	// TransportClient applies the resources (required)
+	TransportClient TransportClient
+	// Logger logs the executions (required)
+	Logger logger.Logger
+	// MetricsRecorder records the adapter metrics (nil records none)
+	MetricsRecorder *metrics.Recorder
+	// Runtime identifies the process in the adapter metadata (nil reads it
+	// from the environment)
+	Runtime *RuntimeMetadata
+}
+
+// NewExecutor creates an Executor. Features of the adapter binary that need
+// their own infrastructure, such as the audit log, the execution journal and
+// the post buffer, are off.
+func NewExecutor(config ExecutorConfig) (*Executor, error) {
+	return executor.NewExecutor(&executor.ExecutorConfig{
+		Config:          config.Config,
+		APIClient:       config.APIClient,
+		TransportClient: config.TransportClient,
+		Logger:          config.Logger,
+		MetricsRecorder: config.MetricsRecorder,
+		Runtime:         config.Runtime,
+	})
+}
+
+// Builder builds an Executor with a fluent interface
+type Builder struct {
+	config ExecutorConfig
+}
+
+// NewBuilder creates a new Builder
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// WithConfig sets the unified configuration
+func (b *Builder) WithConfig(config *Config) *Builder {
+	b.config.Config = config
+	return b
+}
+
+// WithAPIClient sets the HyperFleet API client
+func (b *Builder) WithAPIClient(client APIClient) *Builder {
+	b.config.APIClient = client
+	return b
+}
+
+// WithTransportClient sets the client applying the resources
+func (b *Builder) WithTransportClient(client TransportClient) *Builder {
+	b.config.TransportClient = client
+	return b
+}
+
+// WithLogger sets the logger
+func (b *Builder) WithLogger(log logger.Logger) *Builder {
+	b.config.Logger = log
+	return b
+}
+
+// WithMetricsRecorder sets the metrics recorder
+func (b *Builder) WithMetricsRecorder(recorder *metrics.Recorder) *Builder {
+	b.config.MetricsRecorder = recorder
+	return b
+}
+
+// WithRuntimeMetadata sets the runtime metadata of the adapter metadata
+func (b *Builder) WithRuntimeMetadata(runtime RuntimeMetadata) *Builder {
+	b.config.Runtime = &runtime
+	return b
+}
+
+// Build creates the Executor
+func (b *Builder) Build() (*Executor, error) {
+	return NewExecutor(b.config)
+}
@@ -0,0 +1,415 @@
+// Package adaptertest runs adapter configs against directories of fixtures and
+// compares what the adapter would do with golden files, so that config
+// regressions fail go test.
+//
+// A fixture directory holds the dry-run inputs of one scenario:
+//
+//	adapter-config.yaml        adapter deployment config (required)
+//	adapter-task-config.yaml   adapter task config (required)
+//	event.json                 CloudEvent to execute, or
+//	events/*.json              CloudEvents executed in name order against the same fakes
+//	api-responses.json         scripted HyperFleet API responses (optional)
+//	discovery-overrides.json   server-populated state of applied resources (optional)
+//	env.yaml                   environment variables set for the run (optional)
+//	golden/                    expected outputs
+//
+// The golden directory holds result.yaml (the outcome of every event),
+// api-requests.yaml (every HyperFleet API request with its decoded body) and one
+// file per manifest sent to the transport client, such as rendered resources,
+// ManifestWorks and k8s_patch patches. Run go test with -update to rewrite them.
+//
+// The package registers the -update flag, so test packages that import it must
+// not define their own.
+package adaptertest
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/dryrun"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/executor"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"sigs.k8s.io/yaml"
+)
+
+// Files of a fixture directory
+const (
+	AdapterConfigFile      = "adapter-config.yaml"
+	TaskConfigFile         = "adapter-task-config.yaml"
+	EventFile              = "event.json"
+	EventsDir              = "events"
+	APIResponsesFile       = "api-responses.json"
+	DiscoveryOverridesFile = "discovery-overrides.json"
+	EnvFile                = "env.yaml"
+	GoldenDir              = "golden"
+)
+
+// Golden files written for every fixture, next to one file per transport manifest
+const (
+	resultGoldenFile      = "result.yaml"
+	apiRequestsGoldenFile = "api-requests.yaml"
+)
+
+// timestampPlaceholder replaces RFC 3339 timestamps in golden files, such as
+// observed_time rendered with the now template function
+const timestampPlaceholder = "<timestamp>"
+
+var update = flag.Bool("update", false, "update the golden files of adaptertest fixtures")
+
+var timestampPattern = regexp.MustCompile(
+	`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`)
+
+// unsafeFileChars are replaced in the golden file names derived from manifests
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// runtimeMetadata is the fixed runtime metadata of fixture runs, so golden
+// files do not depend on the environment or the build
+var runtimeMetadata = executor.RuntimeMetadata{
+	PodName:      "adaptertest",
+	PodNamespace: "adaptertest",
+	NodeName:     "adaptertest",
+	BuildVersion: "adaptertest",
+	BuildCommit:  "adaptertest",
+	ConfigHash:   "adaptertest",
+}
+
+// eventOutcome is the summary of one execution in result.yaml
+type eventOutcome struct {
+	Errors           map[string]string `json:"errors,omitempty"`
+	EventID          string            `json:"event_id"`
+	Status           string            `json:"status"`
+	SkipReason       string            `json:"skip_reason,omitempty"`
+	Preconditions    []stepOutcome     `json:"preconditions,omitempty"`
+	Resources        []stepOutcome     `json:"resources,omitempty"`
+	PostActions      []stepOutcome     `json:"post_actions,omitempty"`
+	ResourcesSkipped bool              `json:"resources_skipped"`
+}
+
+// stepOutcome is the summary of a precondition, resource or post action
+type stepOutcome struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Operation string `json:"operation,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Matched   *bool  `json:"matched,omitempty"`
+	Skipped   bool   `json:"skipped,omitempty"`
+}
+
+// apiRequest is a HyperFleet API request in api-requests.yaml
+type apiRequest struct {
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body,omitempty"`
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+}
+
+// RunAll runs every subdirectory of root as a fixture, each in its own subtest
+func RunAll(t *testing.T, root string) {
+	t.Helper()
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatalf("failed to read fixtures directory: %v", err)
+	}
+	found := false
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		found = true
+		dir := filepath.Join(root, entry.Name())
+		t.Run(entry.Name(), func(t *testing.T) {
+			Run(t, dir)
+		})
+	}
+	if !found {
+		t.Fatalf("no fixtures in %s", root)
+	}
+}
+
+// Run executes the events of the fixture in dir with fake clients and compares
+// the outputs with the golden files of the fixture
+func Run(t *testing.T, dir string) {
+	t.Helper()
+	setEnv(t, dir)
+
+	config, err := configloader.LoadConfig(
+		configloader.WithAdapterConfigPath(filepath.Join(dir, AdapterConfigFile)),
+		configloader.WithTaskConfigPath(filepath.Join(dir, TaskConfigFile)),
+	)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	apiClient := newAPIClient(t, dir)
+	apiClient.Targets = config.Clients.HyperfleetAPI.Targets
+	transportClient := newTransportClient(t, dir)
+	exec, err := executor.NewBuilder().
+		WithConfig(config).
+		WithAPIClient(apiClient).
+		WithTransportClient(transportClient).
+		WithLogger(logger.NewTestLogger()).
+		WithRuntimeMetadata(runtimeMetadata).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+
+	var outcomes []eventOutcome
+	for _, evt := range loadEvents(t, dir) {
+		result := exec.ExecuteEvent(context.Background(), evt)
+		outcomes = append(outcomes, summarize(evt, result))
+	}
+
+	outputs := map[string][]byte{
+		resultGoldenFile:      marshalGolden(t, outcomes),
+		apiRequestsGoldenFile: marshalGolden(t, apiRequests(apiClient)),
+	}
+	for name, data := range transportManifests(t, transportClient) {
+		outputs[name] = data
+	}
+	compareGolden(t, filepath.Join(dir, GoldenDir), outputs)
+}
+
+// setEnv sets the variables of the env file of the fixture for the test
+func setEnv(t *testing.T, dir string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, EnvFile))
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", EnvFile, err)
+	}
+	var env map[string]string
+	if err := yaml.Unmarshal(data, &env); err != nil {
+		t.Fatalf("failed to parse %s: %v", EnvFile, err)
+	}
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+}
+
+// newAPIClient returns a fake HyperFleet API client answering with the scripted
+// responses of the fixture; requests without a scripted response get a 200
+func newAPIClient(t *testing.T, dir string) *dryrun.DryrunAPIClient {
+	t.Helper()
+	var responses *dryrun.DryrunResponsesFile
+	path := filepath.Join(dir, APIResponsesFile)
+	if _, err := os.Stat(path); err == nil {
+		responses, err = dryrun.LoadDryrunResponses(path)
+		if err != nil {
+			t.Fatalf("failed to load API responses: %v", err)
+		}
+	}
+	client, err := dryrun.NewDryrunAPIClient(responses)
+	if err != nil {
+		t.Fatalf("failed to create API client: %v", err)
+	}
+	return client
+}
+
+// newTransportClient returns a recording transport client with the discovery
+// overrides of the fixture
+func newTransportClient(t *testing.T, dir string) *dryrun.DryrunTransportClient {
+	t.Helper()
+	path := filepath.Join(dir, DiscoveryOverridesFile)
+	if _, err := os.Stat(path); err != nil {
+		return dryrun.NewDryrunTransportClient()
+	}
+	overrides, err := dryrun.LoadDiscoveryOverrides(path)
+	if err != nil {
+		t.Fatalf("failed to load discovery overrides: %v", err)
+	}
+	return dryrun.NewDryrunTransportClientWithOverrides(overrides)
+}
+
+// loadEvents returns the event of the fixture, or its events in name order.
+// Events without a correlation ID get their event ID as correlation ID, so
+// golden files do not depend on generated IDs.
+func loadEvents(t *testing.T, dir string) []*event.Event {
+	t.Helper()
+	paths := []string{filepath.Join(dir, EventFile)}
+	if _, err := os.Stat(paths[0]); err != nil {
+		paths, err = filepath.Glob(filepath.Join(dir, EventsDir, "*.json"))
+		if err != nil || len(paths) == 0 {
+			t.Fatalf("fixture has neither %s nor %s/*.json", EventFile, EventsDir)
+		}
+		sort.Strings(paths)
+	}
+
+	events := make([]*event.Event, 0, len(paths))
+	for _, path := range paths {
+		evt, err := dryrun.LoadCloudEvent(path)
+		if err != nil {
+			t.Fatalf("failed to load event: %v", err)
+		}
+		if executor.CorrelationIDOf(evt) == "" {
+			evt.SetExtension(executor.CorrelationIDExtension, evt.ID())
+		}
+		events = append(events, evt)
+	}
+	return events
+}
+
+// summarize returns the outcome of an execution for result.yaml
+func summarize(evt *event.Event, result *executor.ExecutionResult) eventOutcome {
+	outcome := eventOutcome{
+		EventID:          evt.ID(),
+		Status:           string(result.Status),
+		SkipReason:       result.SkipReason,
+		ResourcesSkipped: result.ResourcesSkipped,
+	}
+	if len(result.Errors) > 0 {
+		outcome.Errors = make(map[string]string, len(result.Errors))
+		for phase, err := range result.Errors {
+			outcome.Errors[string(phase)] = err.Error()
+		}
+	}
+	for _, pr := range result.PreconditionResults {
+		matched := pr.Matched
+		outcome.Preconditions = append(outcome.Preconditions, stepOutcome{
+			Name:    pr.Name,
+			Status:  string(pr.Status),
+			Matched: &matched,
+			Error:   errorString(pr.Error),
+		})
+	}
+	for _, rr := range result.ResourceResults {
+		outcome.Resources = append(outcome.Resources, stepOutcome{
+			Name:      rr.Name,
+			Status:    string(rr.Status),
+			Operation: string(rr.Operation),
+			Error:     errorString(rr.Error),
+		})
+	}
+	for _, par := range result.PostActionResults {
+		outcome.PostActions = append(outcome.PostActions, stepOutcome{
+			Name:    par.Name,
+			Status:  string(par.Status),
+			Skipped: par.Skipped,
+			Error:   errorString(par.Error),
+		})
+	}
+	return outcome
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// apiRequests returns the requests recorded by the API client, with JSON bodies decoded
+func apiRequests(client *dryrun.DryrunAPIClient) []apiRequest {
+	requests := make([]apiRequest, 0, len(client.Requests))
+	for _, record := range client.Requests {
+		request := apiRequest{
+			Method:  record.Method,
+			URL:     record.URL,
+			Headers: record.Headers,
+		}
+		if len(record.Body) > 0 {
+			var body interface{}
+			if err := json.Unmarshal(record.Body, &body); err != nil {
+				body = string(record.Body)
+			}
+			request.Body = body
+		}
+		requests = append(requests, request)
+	}
+	return requests
+}
+
+// transportManifests returns a golden file per manifest sent to the transport
+// client, named after the order, operation, kind and name of the operation
+func transportManifests(t *testing.T, client *dryrun.DryrunTransportClient) map[string][]byte {
+	t.Helper()
+	files := make(map[string][]byte)
+	for _, record := range client.Records {
+		if len(record.Manifest) == 0 {
+			continue
+		}
+		name := fmt.Sprintf("%02d-%s-%s-%s.yaml",
+			len(files)+1, record.Operation, strings.ToLower(record.GVK.Kind), record.Name)
+		data, err := yaml.JSONToYAML(record.Manifest)
+		if err != nil {
+			t.Fatalf("failed to convert %s manifest of %s to YAML: %v", record.Operation, record.Name, err)
+		}
+		files[unsafeFileChars.ReplaceAllString(name, "_")] = scrub(data)
+	}
+	return files
+}
+
+func marshalGolden(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal golden output: %v", err)
+	}
+	return scrub(data)
+}
+
+// scrub replaces the values that change from run to run
+func scrub(data []byte) []byte {
+	return timestampPattern.ReplaceAll(data, []byte(timestampPlaceholder))
+}
+
+// compareGolden compares outputs with the files of the golden directory, or
+// replaces the directory with outputs when -update is set
+func compareGolden(t *testing.T, dir string, outputs map[string][]byte) {
+	t.Helper()
+	if *update {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatalf("failed to remove golden files: %v", err)
+		}
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			t.Fatalf("failed to create golden directory: %v", err)
+		}
+		for name, data := range outputs {
+			if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+				t.Fatalf("failed to write golden file: %v", err)
+			}
+		}
+		return
+	}
+
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("missing golden file %s (run go test with -update to create it): %v", path, err)
+			continue
+		}
+		if got := outputs[name]; string(got) != string(want) {
+			t.Errorf("%s differs from the golden file (run go test with -update to accept):\n--- want\n%s\n--- got\n%s",
+				path, want, got)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if _, ok := outputs[entry.Name()]; !ok {
+			t.Errorf("stale golden file %s is no longer produced (run go test with -update to remove it)",
+				filepath.Join(dir, entry.Name()))
+		}
+	}
+}
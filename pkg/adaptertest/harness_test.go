@@ -0,0 +1,8 @@
+package adaptertest
+
+import "testing"
+
+// TestFixtures runs the example fixtures under testdata
+func TestFixtures(t *testing.T) {
+	RunAll(t, "testdata")
+}
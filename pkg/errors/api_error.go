@@ -26,6 +26,9 @@ type APIError struct {
 	ResponseBody []byte
 	// Duration is the total duration including retries
 	Duration time.Duration
+	// RetryAfter is the delay requested by the server's Retry-After header on the
+	// last response (429 or 503), zero when absent
+	RetryAfter time.Duration
 	// StatusCode is the HTTP status code (0 if request failed before getting response)
 	StatusCode int
 	// Attempts is how many attempts were made (including retries)
@@ -0,0 +1,50 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Retry After Error Type
+// -----------------------------------------------------------------------------
+
+// RetryAfterError asks the broker consumer to redeliver the event after Delay
+// instead of immediately. Phase code returns it when an immediate retry cannot
+// succeed, e.g. the HyperFleet API answered 429 with a Retry-After header or a
+// precondition is waiting for upstream state to catch up.
+type RetryAfterError struct {
+	// Err is the underlying error
+	Err error
+	// Delay is how long to wait before the event is redelivered
+	Delay time.Duration
+}
+
+// Error implements the error interface
+func (e *RetryAfterError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("retry after %s", e.Delay)
+	}
+	return fmt.Sprintf("%v (retry after %s)", e.Err, e.Delay)
+}
+
+// Unwrap returns the underlying error for errors.Is/As support
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// NewRetryAfterError creates a RetryAfterError requesting redelivery after delay
+func NewRetryAfterError(delay time.Duration, err error) *RetryAfterError {
+	return &RetryAfterError{Delay: delay, Err: err}
+}
+
+// IsRetryAfterError checks if an error is a RetryAfterError and returns it.
+// This function supports wrapped errors via errors.As.
+func IsRetryAfterError(err error) (*RetryAfterError, bool) {
+	var retryErr *RetryAfterError
+	if errors.As(err, &retryErr) {
+		return retryErr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,29 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryAfterError(t *testing.T) {
+	cause := errors.New("rate limited")
+	err := NewRetryAfterError(30*time.Second, cause)
+
+	assert.Equal(t, "rate limited (retry after 30s)", err.Error())
+	assert.Equal(t, "retry after 1m0s", NewRetryAfterError(time.Minute, nil).Error())
+	assert.ErrorIs(t, err, cause)
+
+	retryErr, ok := IsRetryAfterError(fmt.Errorf("phase failed: %w", err))
+	require.True(t, ok, "wrapped RetryAfterError should be found")
+	assert.Equal(t, 30*time.Second, retryErr.Delay)
+
+	_, ok = IsRetryAfterError(cause)
+	assert.False(t, ok)
+	_, ok = IsRetryAfterError(nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,106 @@
+// Package eventtest builds CloudEvents for tests, so tests do not repeat the
+// ID, type, source and data content type boilerplate of event.New.
+package eventtest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// Defaults of the events built by NewEvent
+const (
+	DefaultID     = "test-event"
+	DefaultType   = "com.redhat.hyperfleet.cluster.reconcile"
+	DefaultSource = "test"
+)
+
+// Builder builds a CloudEvent. The first error of a With method is kept and
+// reported by Build.
+type Builder struct {
+	err error
+	evt event.Event
+}
+
+// NewEvent returns a builder of a valid event with the default ID, type and
+// source and no data
+func NewEvent() *Builder {
+	evt := event.New()
+	evt.SetID(DefaultID)
+	evt.SetType(DefaultType)
+	evt.SetSource(DefaultSource)
+	return &Builder{evt: evt}
+}
+
+// WithID sets the event ID
+func (b *Builder) WithID(id string) *Builder {
+	b.evt.SetID(id)
+	return b
+}
+
+// WithType sets the event type
+func (b *Builder) WithType(eventType string) *Builder {
+	b.evt.SetType(eventType)
+	return b
+}
+
+// WithSource sets the event source
+func (b *Builder) WithSource(source string) *Builder {
+	b.evt.SetSource(source)
+	return b
+}
+
+// WithSubject sets the event subject
+func (b *Builder) WithSubject(subject string) *Builder {
+	b.evt.SetSubject(subject)
+	return b
+}
+
+// WithTime sets the time the event was produced
+func (b *Builder) WithTime(t time.Time) *Builder {
+	b.evt.SetTime(t)
+	return b
+}
+
+// WithDataJSON sets the data to v encoded as JSON, with the application/json
+// content type. v may also be a JSON document as a []byte or string.
+func (b *Builder) WithDataJSON(v interface{}) *Builder {
+	if s, ok := v.(string); ok {
+		v = []byte(s)
+	}
+	return b.setData(event.ApplicationJSON, v)
+}
+
+// WithData sets raw data with the given content type, e.g. to build events
+// whose data is not JSON
+func (b *Builder) WithData(contentType string, data []byte) *Builder {
+	return b.setData(contentType, data)
+}
+
+// WithExtension sets a CloudEvents extension attribute; an invalid name or
+// value is reported by Build
+func (b *Builder) WithExtension(name string, value interface{}) *Builder {
+	b.evt.SetExtension(name, value)
+	return b
+}
+
+func (b *Builder) setData(contentType string, v interface{}) *Builder {
+	if err := b.evt.SetData(contentType, v); err != nil && b.err == nil {
+		b.err = fmt.Errorf("event data: %w", err)
+	}
+	return b
+}
+
+// Build returns the event. Build panics when a With method failed or the event
+// is not a valid CloudEvent, which is a bug in the test.
+func (b *Builder) Build() *event.Event {
+	if b.err != nil {
+		panic(fmt.Sprintf("eventtest: %v", b.err))
+	}
+	if err := b.evt.Validate(); err != nil {
+		panic(fmt.Sprintf("eventtest: invalid event: %v", err))
+	}
+	evt := b.evt.Clone()
+	return &evt
+}
@@ -0,0 +1,109 @@
+package eventtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEvent(t *testing.T) {
+	produced := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	evt := NewEvent().
+		WithID("evt-1").
+		WithType("io.hyperfleet.cluster.updated").
+		WithSource("/clusters/abc").
+		WithSubject("abc").
+		WithTime(produced).
+		WithDataJSON(map[string]interface{}{"id": "abc"}).
+		WithExtension("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01").
+		Build()
+
+	assert.Equal(t, "evt-1", evt.ID())
+	assert.Equal(t, "io.hyperfleet.cluster.updated", evt.Type())
+	assert.Equal(t, "/clusters/abc", evt.Source())
+	assert.Equal(t, "abc", evt.Subject())
+	assert.Equal(t, produced, evt.Time())
+	assert.Equal(t, event.ApplicationJSON, evt.DataContentType())
+	assert.JSONEq(t, `{"id":"abc"}`, string(evt.Data()))
+	assert.Contains(t, evt.Extensions(), "traceparent")
+}
+
+func TestNewEvent_Defaults(t *testing.T) {
+	evt := NewEvent().Build()
+	assert.Equal(t, DefaultID, evt.ID())
+	assert.Equal(t, DefaultType, evt.Type())
+	assert.Equal(t, DefaultSource, evt.Source())
+	assert.Empty(t, evt.Data())
+	require.NoError(t, evt.Validate())
+}
+
+func TestBuilder_Data(t *testing.T) {
+	t.Run("JSON document", func(t *testing.T) {
+		evt := NewEvent().WithDataJSON(`{"id":"abc"}`).Build()
+		assert.Equal(t, event.ApplicationJSON, evt.DataContentType())
+		assert.Equal(t, `{"id":"abc"}`, string(evt.Data()))
+	})
+
+	t.Run("raw data", func(t *testing.T) {
+		evt := NewEvent().WithData("text/plain", []byte("cluster abc updated")).Build()
+		assert.Equal(t, "text/plain", evt.DataContentType())
+		assert.Equal(t, "cluster abc updated", string(evt.Data()))
+	})
+
+	t.Run("unencodable data", func(t *testing.T) {
+		builder := NewEvent().WithDataJSON(map[string]interface{}{"ch": make(chan int)})
+		assert.Panics(t, func() { builder.Build() })
+	})
+}
+
+func TestBuilder_InvalidEvent(t *testing.T) {
+	assert.Panics(t, func() { NewEvent().WithID("").Build() }, "an event needs an ID")
+	assert.Panics(t, func() { NewEvent().WithExtension("Not-Valid", "x").Build() }, "extension names are lowercase alphanumeric")
+}
+
+// Built events are independent of the builder
+func TestBuilder_BuildCopies(t *testing.T) {
+	builder := NewEvent().WithID("first")
+	first := builder.Build()
+	second := builder.WithID("second").Build()
+	assert.Equal(t, "first", first.ID())
+	assert.Equal(t, "second", second.ID())
+}
+
+func TestClusterEvents(t *testing.T) {
+	tests := []struct {
+		builder      *Builder
+		expectedType string
+		expectedID   string
+	}{
+		{ClusterCreated("abc123", 1), "io.hyperfleet.cluster.created", "abc123-created-1"},
+		{ClusterUpdated("abc123", 5), "io.hyperfleet.cluster.updated", "abc123-updated-5"},
+		{ClusterDeleted("abc123", 6), "io.hyperfleet.cluster.deleted", "abc123-deleted-6"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expectedType, func(t *testing.T) {
+			evt := tt.builder.Build()
+			assert.Equal(t, tt.expectedType, evt.Type())
+			assert.Equal(t, tt.expectedID, evt.ID())
+			assert.Equal(t, "/api/hyperfleet/v1/clusters/abc123", evt.Source())
+			assert.Equal(t, event.ApplicationJSON, evt.DataContentType())
+		})
+	}
+
+	evt := ClusterUpdated("abc123", 5).Build()
+	assert.JSONEq(t,
+		`{"id":"abc123","kind":"Cluster","href":"/api/hyperfleet/v1/clusters/abc123","generation":5}`,
+		string(evt.Data()))
+}
+
+func TestLoad(t *testing.T) {
+	evt := Load(t, "testdata/cluster-updated.json")
+	expected := ClusterUpdated("abc123", 5).Build()
+	assert.Equal(t, expected.ID(), evt.ID())
+	assert.Equal(t, expected.Type(), evt.Type())
+	assert.Equal(t, expected.Source(), evt.Source())
+	assert.JSONEq(t, string(expected.Data()), string(evt.Data()))
+}
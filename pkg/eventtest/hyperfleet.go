@@ -0,0 +1,42 @@
+package eventtest
+
+import "fmt"
+
+// Actions of the HyperFleet cluster events built by ClusterEvent
+const (
+	ActionCreated = "created"
+	ActionUpdated = "updated"
+	ActionDeleted = "deleted"
+)
+
+// ClusterEvent returns a builder of a HyperFleet cluster event, such as
+// io.hyperfleet.cluster.updated, whose data references the cluster by id,
+// kind, href and generation
+func ClusterEvent(action, clusterID string, generation int64) *Builder {
+	href := "/api/hyperfleet/v1/clusters/" + clusterID
+	return NewEvent().
+		WithID(fmt.Sprintf("%s-%s-%d", clusterID, action, generation)).
+		WithType("io.hyperfleet.cluster." + action).
+		WithSource(href).
+		WithDataJSON(map[string]interface{}{
+			"id":         clusterID,
+			"kind":       "Cluster",
+			"href":       href,
+			"generation": generation,
+		})
+}
+
+// ClusterCreated returns a builder of a cluster created event
+func ClusterCreated(clusterID string, generation int64) *Builder {
+	return ClusterEvent(ActionCreated, clusterID, generation)
+}
+
+// ClusterUpdated returns a builder of a cluster updated event
+func ClusterUpdated(clusterID string, generation int64) *Builder {
+	return ClusterEvent(ActionUpdated, clusterID, generation)
+}
+
+// ClusterDeleted returns a builder of a cluster deleted event
+func ClusterDeleted(clusterID string, generation int64) *Builder {
+	return ClusterEvent(ActionDeleted, clusterID, generation)
+}
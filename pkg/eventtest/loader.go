@@ -0,0 +1,28 @@
+package eventtest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// Load reads a CloudEvent in the CloudEvents JSON format from a fixture file,
+// failing the test when the file cannot be read or is not a valid event
+func Load(tb testing.TB, path string) *event.Event {
+	tb.Helper()
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		tb.Fatalf("failed to read event file: %v", err)
+	}
+	var evt event.Event
+	if err := json.Unmarshal(data, &evt); err != nil {
+		tb.Fatalf("failed to parse CloudEvent from %q: %v", path, err)
+	}
+	if err := evt.Validate(); err != nil {
+		tb.Fatalf("invalid CloudEvent in %q: %v", path, err)
+	}
+	return &evt
+}
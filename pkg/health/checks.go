@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCheckTimeout bounds a single dependency check
+	DefaultCheckTimeout = 2 * time.Second
+	// DefaultCheckCacheTTL is how long a dependency check result is reused, so
+	// frequent kubelet probes do not hammer dependencies
+	DefaultCheckCacheTTL = 5 * time.Second
+)
+
+// CheckFunc probes a dependency. A nil error means the dependency is healthy.
+type CheckFunc func(ctx context.Context) error
+
+// dependencyCheck is a registered CheckFunc with its cached result
+type dependencyCheck struct {
+	checkedAt time.Time
+	check     CheckFunc
+	err       error
+}
+
+// dependencyChecks runs registered checks concurrently and caches their results
+type dependencyChecks struct {
+	checks   map[string]*dependencyCheck
+	timeout  time.Duration
+	cacheTTL time.Duration
+	// mu guards checks; held while probing so concurrent probes share one run
+	mu sync.Mutex
+}
+
+func newDependencyChecks() *dependencyChecks {
+	return &dependencyChecks{
+		checks:   make(map[string]*dependencyCheck),
+		timeout:  DefaultCheckTimeout,
+		cacheTTL: DefaultCheckCacheTTL,
+	}
+}
+
+// RegisterCheck registers a dependency check run by /readyz. Checks run
+// concurrently, each bounded by the check timeout, and their results are cached
+// for the check cache TTL. Registering a name again replaces the check.
+func (s *Server) RegisterCheck(name string, check CheckFunc) {
+	s.dependencies.mu.Lock()
+	defer s.dependencies.mu.Unlock()
+	s.dependencies.checks[name] = &dependencyCheck{check: check}
+}
+
+// SetCheckTimings sets the per-check timeout and the result cache TTL of
+// registered checks. Zero values keep the current setting.
+func (s *Server) SetCheckTimings(timeout, cacheTTL time.Duration) {
+	s.dependencies.mu.Lock()
+	defer s.dependencies.mu.Unlock()
+	if timeout > 0 {
+		s.dependencies.timeout = timeout
+	}
+	if cacheTTL > 0 {
+		s.dependencies.cacheTTL = cacheTTL
+	}
+}
+
+// run returns the result of every registered check, probing the ones whose
+// cached result has expired.
+func (d *dependencyChecks) run(ctx context.Context) map[string]error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	var wg sync.WaitGroup
+	for _, c := range d.checks {
+		if !c.checkedAt.IsZero() && now.Sub(c.checkedAt) < d.cacheTTL {
+			continue
+		}
+		wg.Add(1)
+		go func(c *dependencyCheck) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, d.timeout)
+			defer cancel()
+			c.err = c.check(checkCtx)
+			c.checkedAt = time.Now()
+		}(c)
+	}
+	wg.Wait()
+
+	results := make(map[string]error, len(d.checks))
+	for name, c := range d.checks {
+		results[name] = c.err
+	}
+	return results
+}
@@ -0,0 +1,105 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newReadyServer() *Server {
+	server := NewServer(&mockLogger{}, "8080", "test-adapter")
+	server.SetConfigLoaded()
+	server.SetBrokerReady(true)
+	return server
+}
+
+func getReadyz(t *testing.T, server *Server) (int, ReadyResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	server.readyzHandler(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var response ReadyResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	return w.Code, response
+}
+
+func TestRegisterCheck_FailingCheckReturns503(t *testing.T) {
+	server := newReadyServer()
+	server.RegisterCheck("hyperfleet_api", func(context.Context) error { return nil })
+	server.RegisterCheck("kubernetes", func(context.Context) error { return errors.New("401 Unauthorized") })
+
+	code, response := getReadyz(t, server)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "error", response.Status)
+	assert.Equal(t, CheckOK, response.Checks["hyperfleet_api"])
+	assert.Equal(t, CheckError, response.Checks["kubernetes"])
+	assert.Equal(t, map[string]string{"kubernetes": "401 Unauthorized"}, response.Errors)
+	assert.False(t, server.IsReady())
+}
+
+func TestRegisterCheck_AllPassing(t *testing.T) {
+	server := newReadyServer()
+	server.RegisterCheck("hyperfleet_api", func(context.Context) error { return nil })
+
+	code, response := getReadyz(t, server)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, CheckOK, response.Checks["hyperfleet_api"])
+	assert.Empty(t, response.Errors)
+}
+
+func TestRegisterCheck_ResultsAreCached(t *testing.T) {
+	server := newReadyServer()
+	server.SetCheckTimings(0, 50*time.Millisecond)
+
+	var calls atomic.Int32
+	server.RegisterCheck("maestro", func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	for i := 0; i < 5; i++ {
+		getReadyz(t, server)
+	}
+	assert.Equal(t, int32(1), calls.Load(), "probes within the cache TTL reuse the result")
+
+	time.Sleep(60 * time.Millisecond)
+	getReadyz(t, server)
+	assert.Equal(t, int32(2), calls.Load(), "expired results are re-probed")
+}
+
+func TestRegisterCheck_Timeout(t *testing.T) {
+	server := newReadyServer()
+	server.SetCheckTimings(20*time.Millisecond, 0)
+	server.RegisterCheck("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	start := time.Now()
+	code, response := getReadyz(t, server)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, response.Errors["slow"], "deadline exceeded")
+}
+
+func TestRegisterCheck_ShuttingDownSkipsChecks(t *testing.T) {
+	server := newReadyServer()
+	var calls atomic.Int32
+	server.RegisterCheck("hyperfleet_api", func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+	server.SetShuttingDown(true)
+
+	code, response := getReadyz(t, server)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "server is shutting down", response.Message)
+	assert.Zero(t, calls.Load())
+}
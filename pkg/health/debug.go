@@ -0,0 +1,132 @@
+package health
+
+import (
+	"context"
+	"crypto/subtle"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// DebugServer provides opt-in runtime debug endpoints: the net/http/pprof
+// handlers under /debug/pprof/ and expvar variables under /debug/vars.
+type DebugServer struct {
+	server *httpServer
+	log    logger.Logger
+	// vars are the variables published with Publish, served next to the global
+	// expvar variables (cmdline, memstats)
+	vars map[string]expvar.Var
+	mu   sync.RWMutex
+}
+
+// NewDebugServer creates a new debug server. When token is not empty every
+// request must carry it as "Authorization: Bearer <token>".
+func NewDebugServer(log logger.Logger, port string, token string) *DebugServer {
+	logger.RegisterSecret(token)
+	s := &DebugServer{
+		log:  log,
+		vars: make(map[string]expvar.Var),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/vars", s.varsHandler)
+
+	s.server = newHTTPServer(port, requireBearerToken(token, mux))
+	return s
+}
+
+// Publish exposes fn's result as the /debug/vars variable name. Unlike
+// expvar.Publish, variables are scoped to the server so a name can be
+// published again, e.g. by a new server in tests. Safe to call on a nil
+// *DebugServer, so callers need not check whether the server is enabled.
+func (s *DebugServer) Publish(name string, fn func() any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vars[name] = expvar.Func(fn)
+}
+
+// Start binds the debug server listener and serves in a goroutine.
+// Returns an error if the port cannot be bound.
+func (s *DebugServer) Start(ctx context.Context) error {
+	if err := s.server.start(ctx, s.log, "Debug server"); err != nil {
+		return err
+	}
+	s.log.Warnf(ctx, "Started debug server on %s", s.Addr())
+	return nil
+}
+
+// Addr returns the address the debug server listens on. After Start it
+// carries the actual port, e.g. when created with port "0".
+func (s *DebugServer) Addr() string {
+	return s.server.addr()
+}
+
+// Shutdown gracefully shuts down the debug server.
+func (s *DebugServer) Shutdown(ctx context.Context) error {
+	s.log.Info(ctx, "Shutting down debug server...")
+	return s.server.shutdown(ctx)
+}
+
+// varsHandler serves the global expvar variables and the published ones in
+// the expvar JSON format.
+func (s *DebugServer) varsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := make(map[string]string)
+	expvar.Do(func(kv expvar.KeyValue) {
+		vars[kv.Key] = kv.Value.String()
+	})
+	s.mu.RLock()
+	for name, v := range s.vars {
+		vars[name] = v.String()
+	}
+	s.mu.RUnlock()
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("{\n")
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString(",\n")
+		}
+		fmt.Fprintf(&b, "%q: %s", name, vars[name])
+	}
+	b.WriteString("\n}\n")
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = w.Write([]byte(b.String())) //nolint:errcheck // best-effort response
+}
+
+// requireBearerToken rejects requests without the bearer token. An empty token
+// disables authentication.
+func requireBearerToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,105 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveDebug(server *DebugServer, path, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	server.server.srv.Handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestDebugServer_Vars(t *testing.T) {
+	server := NewDebugServer(&mockLogger{}, "0", "")
+	server.Publish("in_flight_executions", func() any { return 3 })
+	server.Publish("subscriptions", func() any { return []string{"clusters"} })
+
+	w := serveDebug(server, "/debug/vars", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var vars map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &vars))
+	assert.JSONEq(t, "3", string(vars["in_flight_executions"]))
+	assert.JSONEq(t, `["clusters"]`, string(vars["subscriptions"]))
+	assert.Contains(t, vars, "memstats", "global expvar variables are served too")
+}
+
+func TestDebugServer_PublishTwice(t *testing.T) {
+	server := NewDebugServer(&mockLogger{}, "0", "")
+	server.Publish("cache_entries", func() any { return 1 })
+	assert.NotPanics(t, func() {
+		server.Publish("cache_entries", func() any { return 2 })
+	})
+	assert.NotPanics(t, func() {
+		NewDebugServer(&mockLogger{}, "0", "").Publish("cache_entries", func() any { return 3 })
+	})
+
+	var disabled *DebugServer
+	assert.NotPanics(t, func() {
+		disabled.Publish("cache_entries", func() any { return 4 })
+	})
+}
+
+func TestDebugServer_Pprof(t *testing.T) {
+	server := NewDebugServer(&mockLogger{}, "0", "")
+	w := serveDebug(server, "/debug/pprof/", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "goroutine")
+}
+
+func TestDebugServer_BearerToken(t *testing.T) {
+	server := NewDebugServer(&mockLogger{}, "0", "s3cret")
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantCode      int
+	}{
+		{name: "missing token", wantCode: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer wrong", wantCode: http.StatusUnauthorized},
+		{name: "not a bearer token", authorization: "s3cret", wantCode: http.StatusUnauthorized},
+		{name: "valid token", authorization: "Bearer s3cret", wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, path := range []string{"/debug/vars", "/debug/pprof/"} {
+				assert.Equal(t, tt.wantCode, serveDebug(server, path, tt.authorization).Code, path)
+			}
+		})
+	}
+}
+
+func TestDebugServer_ShutdownReleasesListener(t *testing.T) {
+	server := NewDebugServer(&mockLogger{}, "0", "")
+	require.NoError(t, server.Start(context.Background()))
+
+	baseURL := localURL(t, server.Addr())
+	resp, err := http.Get(baseURL + "/debug/vars")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, server.Shutdown(ctx))
+
+	_, port, err := net.SplitHostPort(server.Addr())
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", ":"+port)
+	require.NoError(t, err, "the port is released after shutdown")
+	_ = listener.Close()
+}
@@ -0,0 +1,66 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// httpServer is an http.Server whose listener is bound synchronously by start,
+// so bind errors such as a port already in use are returned to the caller
+// instead of being logged from the serve goroutine.
+type httpServer struct {
+	srv      *http.Server
+	listener net.Listener
+	mu       sync.Mutex
+}
+
+func newHTTPServer(port string, handler http.Handler) *httpServer {
+	return &httpServer{
+		srv: &http.Server{
+			Addr:              ":" + port,
+			Handler:           handler,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}
+}
+
+// start binds the listener, then serves in a goroutine. name identifies the
+// server in log messages.
+func (h *httpServer) start(ctx context.Context, log logger.Logger, name string) error {
+	listener, err := net.Listen("tcp", h.srv.Addr)
+	if err != nil {
+		return fmt.Errorf("%s failed to listen on %s: %w", name, h.srv.Addr, err)
+	}
+	h.mu.Lock()
+	h.listener = listener
+	h.mu.Unlock()
+
+	go func() {
+		if err := h.srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			errCtx := logger.WithErrorField(ctx, err)
+			log.Errorf(errCtx, "%s error", name)
+		}
+	}()
+	return nil
+}
+
+// addr returns the bound address once started, which carries the actual port
+// when started on port 0, and the configured address before.
+func (h *httpServer) addr() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.listener != nil {
+		return h.listener.Addr().String()
+	}
+	return h.srv.Addr
+}
+
+func (h *httpServer) shutdown(ctx context.Context) error {
+	return h.srv.Shutdown(ctx)
+}
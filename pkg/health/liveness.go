@@ -0,0 +1,141 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Liveness statuses reported by /livez
+const (
+	LivenessOK    = "ok"
+	LivenessStale = "stale"
+)
+
+// Heartbeat records the liveness of a subsystem. The subsystem calls Beat
+// whenever it makes progress; /livez reports it stale once no beat was seen
+// for longer than its staleness threshold.
+type Heartbeat struct {
+	registeredAt time.Time
+	name         string
+	staleAfter   time.Duration
+	// last is the time of the last beat in Unix nanoseconds, 0 if none yet
+	last atomic.Int64
+}
+
+// Beat records progress of the subsystem. Safe to call on a nil *Heartbeat.
+func (h *Heartbeat) Beat() {
+	if h == nil {
+		return
+	}
+	h.last.Store(time.Now().UnixNano())
+}
+
+// Last returns the time of the last beat, or the zero time if none was recorded
+func (h *Heartbeat) Last() time.Time {
+	if h == nil {
+		return time.Time{}
+	}
+	last := h.last.Load()
+	if last == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, last)
+}
+
+// stale reports whether no beat was seen within the staleness threshold.
+// A subsystem that never beat is measured from its registration time.
+func (h *Heartbeat) stale(now time.Time) bool {
+	if h.staleAfter <= 0 {
+		return false
+	}
+	ref := h.Last()
+	if ref.IsZero() {
+		ref = h.registeredAt
+	}
+	return now.Sub(ref) > h.staleAfter
+}
+
+// SubsystemLiveness is the /livez report of a single heartbeat
+type SubsystemLiveness struct {
+	LastBeat   *time.Time `json:"last_beat,omitempty"`
+	Status     string     `json:"status"`
+	Age        string     `json:"age,omitempty"`
+	StaleAfter string     `json:"stale_after,omitempty"`
+}
+
+// LivezResponse represents the JSON response for the /livez endpoint
+type LivezResponse struct {
+	Subsystems map[string]SubsystemLiveness `json:"subsystems,omitempty"`
+	Status     string                       `json:"status"`
+	Message    string                       `json:"message,omitempty"`
+}
+
+// heartbeats holds the registered subsystem heartbeats
+type heartbeats struct {
+	byName map[string]*Heartbeat
+	mu     sync.RWMutex
+}
+
+// Heartbeat registers a subsystem heartbeat served by /livez and returns it.
+// staleAfter is the longest acceptable gap between beats; zero only reports the
+// last beat without ever failing. Registering a name again returns the existing
+// heartbeat.
+func (s *Server) Heartbeat(name string, staleAfter time.Duration) *Heartbeat {
+	s.heartbeats.mu.Lock()
+	defer s.heartbeats.mu.Unlock()
+	if h, ok := s.heartbeats.byName[name]; ok {
+		return h
+	}
+	h := &Heartbeat{name: name, staleAfter: staleAfter, registeredAt: time.Now()}
+	s.heartbeats.byName[name] = h
+	return h
+}
+
+// livezHandler handles verbose liveness probe requests.
+// Returns 503 with per-subsystem details if any heartbeat is stale.
+// Unlike /healthz, a stalled subsystem makes the kubelet restart the process.
+func (s *Server) livezHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	now := time.Now()
+	response := LivezResponse{Status: LivenessOK}
+	var stale []string
+
+	s.heartbeats.mu.RLock()
+	if len(s.heartbeats.byName) > 0 {
+		response.Subsystems = make(map[string]SubsystemLiveness, len(s.heartbeats.byName))
+	}
+	for name, h := range s.heartbeats.byName {
+		report := SubsystemLiveness{Status: LivenessOK}
+		if last := h.Last(); !last.IsZero() {
+			report.LastBeat = &last
+			report.Age = now.Sub(last).Round(time.Millisecond).String()
+		}
+		if h.staleAfter > 0 {
+			report.StaleAfter = h.staleAfter.String()
+		}
+		if h.stale(now) {
+			report.Status = LivenessStale
+			stale = append(stale, name)
+		}
+		response.Subsystems[name] = report
+	}
+	s.heartbeats.mu.RUnlock()
+
+	if len(stale) == 0 {
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(response) //nolint:errcheck // best-effort response
+		return
+	}
+
+	sort.Strings(stale)
+	response.Status = "error"
+	response.Message = "stale subsystems: " + strings.Join(stale, ", ")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	_ = json.NewEncoder(w).Encode(response) //nolint:errcheck // best-effort response
+}
@@ -0,0 +1,105 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getLivez(t *testing.T, server *Server) (int, LivezResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	server.livezHandler(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	var response LivezResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	return w.Code, response
+}
+
+func TestLivez_NoHeartbeats(t *testing.T) {
+	server := NewServer(&mockLogger{}, "8080", "test-adapter")
+
+	code, response := getLivez(t, server)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, LivenessOK, response.Status)
+	assert.Empty(t, response.Subsystems)
+}
+
+func TestLivez_FreshHeartbeats(t *testing.T) {
+	server := NewServer(&mockLogger{}, "8080", "test-adapter")
+	server.Heartbeat("broker", time.Minute).Beat()
+	server.Heartbeat("executor", 0)
+
+	code, response := getLivez(t, server)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, LivenessOK, response.Status)
+
+	broker := response.Subsystems["broker"]
+	assert.Equal(t, LivenessOK, broker.Status)
+	assert.NotNil(t, broker.LastBeat)
+	assert.Equal(t, "1m0s", broker.StaleAfter)
+
+	executor := response.Subsystems["executor"]
+	assert.Equal(t, LivenessOK, executor.Status, "a zero threshold never reports stale")
+	assert.Nil(t, executor.LastBeat)
+	assert.Empty(t, executor.StaleAfter)
+}
+
+func TestLivez_StaleHeartbeatReturns503(t *testing.T) {
+	server := NewServer(&mockLogger{}, "8080", "test-adapter")
+	broker := server.Heartbeat("broker", 20*time.Millisecond)
+	broker.Beat()
+	executor := server.Heartbeat("executor", time.Minute)
+	executor.Beat()
+
+	time.Sleep(40 * time.Millisecond)
+
+	code, response := getLivez(t, server)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "error", response.Status)
+	assert.Equal(t, "stale subsystems: broker", response.Message)
+	assert.Equal(t, LivenessStale, response.Subsystems["broker"].Status)
+	assert.Equal(t, LivenessOK, response.Subsystems["executor"].Status)
+
+	broker.Beat()
+	code, _ = getLivez(t, server)
+	assert.Equal(t, http.StatusOK, code, "a new beat recovers the subsystem")
+}
+
+func TestLivez_NeverBeatIsMeasuredFromRegistration(t *testing.T) {
+	server := NewServer(&mockLogger{}, "8080", "test-adapter")
+	server.Heartbeat("broker", 20*time.Millisecond)
+
+	code, _ := getLivez(t, server)
+	assert.Equal(t, http.StatusOK, code, "a subsystem gets a grace period after registration")
+
+	time.Sleep(40 * time.Millisecond)
+	code, response := getLivez(t, server)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, LivenessStale, response.Subsystems["broker"].Status)
+}
+
+func TestHeartbeat_RegisterTwiceReturnsSameHeartbeat(t *testing.T) {
+	server := NewServer(&mockLogger{}, "8080", "test-adapter")
+	assert.Same(t, server.Heartbeat("broker", time.Minute), server.Heartbeat("broker", time.Second))
+}
+
+func TestHeartbeat_NilSafe(t *testing.T) {
+	var heartbeat *Heartbeat
+	assert.NotPanics(t, heartbeat.Beat)
+	assert.True(t, heartbeat.Last().IsZero())
+}
+
+func TestHealthz_IgnoresStaleHeartbeats(t *testing.T) {
+	server := NewServer(&mockLogger{}, "8080", "test-adapter")
+	server.Heartbeat("broker", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	w := httptest.NewRecorder()
+	server.healthzHandler(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
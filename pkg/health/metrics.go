@@ -3,20 +3,20 @@ package health
 import (
 	"context"
 	"net/http"
-	"time"
 
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/metrics"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // MetricsServer provides HTTP metrics endpoint for Prometheus.
 type MetricsServer struct {
-	server    *http.Server
+	server    *httpServer
+	handler   http.Handler
 	log       logger.Logger
 	buildInfo *prometheus.GaugeVec
 	upGauge   prometheus.Gauge
-	port      string
 }
 
 // MetricsConfig holds configuration for metrics registration.
@@ -24,17 +24,35 @@ type MetricsConfig struct {
 	Component string
 	Version   string
 	Commit    string
+	BuildDate string
+	// BearerToken, when set, is required as "Authorization: Bearer <token>"
+	// on every scrape
+	BearerToken string
+	// BearerTokenFile is read for the bearer token instead of BearerToken,
+	// and re-read when it changes
+	BearerTokenFile string
+	// Registerer registers the metrics of the server, prometheus.DefaultRegisterer when nil
+	Registerer prometheus.Registerer
+	// Gatherer is served on /metrics, prometheus.DefaultGatherer when nil
+	Gatherer prometheus.Gatherer
 }
 
 // NewMetricsServer creates a new metrics server with required HyperFleet metrics.
-func NewMetricsServer(log logger.Logger, port string, cfg MetricsConfig) *MetricsServer {
+// Returns an error if the bearer token file cannot be read. Creating a second
+// server on the same registry reuses the metrics of the first.
+func NewMetricsServer(log logger.Logger, port string, cfg MetricsConfig) (*MetricsServer, error) {
+	token, err := newBearerToken(cfg.BearerToken, cfg.BearerTokenFile)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create build_info metric per HyperFleet metrics standard
 	buildInfo := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "hyperfleet_adapter_build_info",
 			Help: "Build information for the adapter",
 		},
-		[]string{"component", "version", "commit"},
+		[]string{"component", "version", "commit", "build_date"},
 	)
 
 	// Create up metric per HyperFleet metrics standard
@@ -49,50 +67,76 @@ func NewMetricsServer(log logger.Logger, port string, cfg MetricsConfig) *Metric
 		},
 	)
 
+	reg, gatherer := cfg.Registerer, cfg.Gatherer
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	if gatherer == nil {
+		gatherer = prometheus.DefaultGatherer
+	}
+
 	// Register metrics
-	prometheus.MustRegister(buildInfo)
-	prometheus.MustRegister(upGauge)
+	buildInfo = metrics.Register(reg, buildInfo)
+	upGauge = metrics.Register(reg, upGauge)
 
 	// Set build_info to 1 (this is an info metric)
-	buildInfo.WithLabelValues(cfg.Component, cfg.Version, cfg.Commit).Set(1)
+	buildInfo.WithLabelValues(cfg.Component, cfg.Version, cfg.Commit, cfg.BuildDate).Set(1)
 
 	// Set up to 1 (adapter is running)
 	upGauge.Set(1)
 
-	mux := http.NewServeMux()
-	mux.Handle("/metrics", promhttp.Handler())
+	handler := newMetricsHandler(log, reg, gatherer, token)
 
 	return &MetricsServer{
 		log:       log,
-		port:      port,
 		upGauge:   upGauge,
 		buildInfo: buildInfo,
-		server: &http.Server{
-			Addr:              ":" + port,
-			Handler:           mux,
-			ReadHeaderTimeout: 5 * time.Second,
-		},
-	}
+		handler:   handler,
+		server:    newHTTPServer(port, handler),
+	}, nil
 }
 
-// Start starts the metrics server in a goroutine.
-func (s *MetricsServer) Start(ctx context.Context) error {
-	s.log.Infof(ctx, "Starting metrics server on port %s", s.port)
+// newMetricsHandler serves /metrics from gatherer. The response is gzip
+// encoded when the scraper accepts it, which Prometheus always does.
+func newMetricsHandler(
+	log logger.Logger, reg prometheus.Registerer, gatherer prometheus.Gatherer, token *bearerToken,
+) http.Handler {
+	metricsHandler := promhttp.InstrumentMetricHandler(reg, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{
+		OfferedCompressions: []promhttp.Compression{promhttp.Identity, promhttp.Gzip},
+	}))
 
-	go func() {
-		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errCtx := logger.WithErrorField(ctx, err)
-			s.log.Errorf(errCtx, "Metrics server error")
-		}
-	}()
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", requireMetricsToken(token, log, metricsHandler))
+	return mux
+}
+
+// Handler returns the handler serving /metrics, so it can be mounted on
+// another server when only one port is available.
+func (s *MetricsServer) Handler() http.Handler {
+	return s.handler
+}
 
+// Start binds the metrics server listener and serves in a goroutine.
+// Returns an error if the port cannot be bound.
+func (s *MetricsServer) Start(ctx context.Context) error {
+	if err := s.server.start(ctx, s.log, "Metrics server"); err != nil {
+		return err
+	}
+	s.log.Infof(ctx, "Started metrics server on %s", s.Addr())
 	return nil
 }
 
-// Shutdown gracefully shuts down the metrics server.
+// Addr returns the address the metrics server listens on. After Start it
+// carries the actual port, e.g. when created with port "0".
+func (s *MetricsServer) Addr() string {
+	return s.server.addr()
+}
+
+// Shutdown gracefully shuts down the metrics server. Safe to call when the
+// server was never started, e.g. when its handler is mounted elsewhere.
 func (s *MetricsServer) Shutdown(ctx context.Context) error {
 	s.log.Info(ctx, "Shutting down metrics server...")
 	// Set up to 0 during shutdown
 	s.upGauge.Set(0)
-	return s.server.Shutdown(ctx)
+	return s.server.shutdown(ctx)
 }
@@ -0,0 +1,109 @@
+package health
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+)
+
+// bearerToken is the token expected by the metrics endpoint. A token read from
+// a file is re-read when the file changes, so a rotated Secret is picked up
+// without a restart.
+type bearerToken struct {
+	modTime time.Time
+	file    string
+	token   string
+	size    int64
+	mu      sync.Mutex
+}
+
+// newBearerToken returns the token source, or nil when authentication
+// is disabled. file takes precedence over token. The file is read once
+// here so that a missing or empty file fails at startup.
+func newBearerToken(token, file string) (*bearerToken, error) {
+	if file == "" {
+		if token == "" {
+			return nil, nil
+		}
+		logger.RegisterSecret(token)
+		return &bearerToken{token: token}, nil
+	}
+	b := &bearerToken{file: file}
+	if _, err := b.get(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// get returns the current token, re-reading the file when its modification
+// time or size changed since the last read.
+func (b *bearerToken) get() (string, error) {
+	if b.file == "" {
+		return b.token, nil
+	}
+
+	// Stat follows symlinks, so the atomic symlink swap of a mounted Secret
+	// shows up as a change
+	info, err := os.Stat(b.file)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat metrics token file: %w", err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.token != "" && info.ModTime().Equal(b.modTime) && info.Size() == b.size {
+		return b.token, nil
+	}
+
+	data, err := os.ReadFile(filepath.Clean(b.file))
+	if err != nil {
+		return "", fmt.Errorf("failed to read metrics token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("metrics token file %s is empty", b.file)
+	}
+	logger.RegisterSecret(token)
+	b.token = token
+	b.modTime = info.ModTime()
+	b.size = info.Size()
+	return b.token, nil
+}
+
+// requireMetricsToken rejects scrapes without the bearer token: 401 when the
+// Authorization header is missing or not a bearer token, 403 when the token
+// does not match. A nil token disables authentication.
+func requireMetricsToken(token *bearerToken, log logger.Logger, next http.Handler) http.Handler {
+	if token == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		presented, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || presented == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		expected, err := token.get()
+		if err != nil {
+			// Fail closed: keep rejecting scrapes until the file is readable again
+			errCtx := logger.WithErrorField(r.Context(), err)
+			log.Errorf(errCtx, "Failed to load metrics bearer token")
+			http.Error(w, "metrics token unavailable", http.StatusInternalServerError)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(expected)) != 1 {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
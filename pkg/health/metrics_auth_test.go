@@ -0,0 +1,126 @@
+package health
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestMetricsHandler serves an isolated registry holding one gauge
+func newTestMetricsHandler(t *testing.T, token *bearerToken) http.Handler {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "hyperfleet_adapter_up", Help: "test"})
+	registry.MustRegister(gauge)
+	gauge.Set(1)
+	return newMetricsHandler(&mockLogger{}, registry, registry, token)
+}
+
+func scrape(handler http.Handler, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestMetricsHandler_BearerToken(t *testing.T) {
+	token, err := newBearerToken("s3cret", "")
+	require.NoError(t, err)
+	handler := newTestMetricsHandler(t, token)
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantCode      int
+	}{
+		{name: "missing header", wantCode: http.StatusUnauthorized},
+		{name: "not a bearer token", authorization: "Basic czNjcmV0", wantCode: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer wrong", wantCode: http.StatusForbidden},
+		{name: "correct token", authorization: "Bearer s3cret", wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := scrape(handler, tt.authorization)
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode == http.StatusUnauthorized {
+				assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+			}
+			if tt.wantCode == http.StatusOK {
+				assert.Contains(t, w.Body.String(), "hyperfleet_adapter_up 1")
+			}
+		})
+	}
+}
+
+func TestMetricsHandler_NoToken(t *testing.T) {
+	token, err := newBearerToken("", "")
+	require.NoError(t, err)
+	assert.Nil(t, token)
+	assert.Equal(t, http.StatusOK, scrape(newTestMetricsHandler(t, token), "").Code)
+}
+
+func TestMetricsHandler_TokenFileRotation(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(file, []byte("first\n"), 0o600))
+
+	token, err := newBearerToken("ignored", file)
+	require.NoError(t, err)
+	handler := newTestMetricsHandler(t, token)
+	assert.Equal(t, http.StatusOK, scrape(handler, "Bearer first").Code)
+	assert.Equal(t, http.StatusForbidden, scrape(handler, "Bearer ignored").Code, "the file takes precedence")
+
+	require.NoError(t, os.WriteFile(file, []byte("second\n"), 0o600))
+	// Make the change visible on filesystems with a coarse modification time
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(file, later, later))
+	assert.Equal(t, http.StatusForbidden, scrape(handler, "Bearer first").Code)
+	assert.Equal(t, http.StatusOK, scrape(handler, "Bearer second").Code)
+
+	require.NoError(t, os.Remove(file))
+	assert.Equal(t, http.StatusInternalServerError, scrape(handler, "Bearer second").Code,
+		"scrapes fail closed while the token file is unreadable")
+}
+
+func TestNewBearerToken_InvalidFile(t *testing.T) {
+	_, err := newBearerToken("", filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+
+	empty := filepath.Join(t.TempDir(), "empty")
+	require.NoError(t, os.WriteFile(empty, []byte(" \n"), 0o600))
+	_, err = newBearerToken("", empty)
+	assert.Error(t, err)
+}
+
+func TestMetricsHandler_Gzip(t *testing.T) {
+	handler := newTestMetricsHandler(t, nil)
+
+	plain := scrape(handler, "")
+	require.Equal(t, http.StatusOK, plain.Code)
+	assert.Empty(t, plain.Header().Get("Content-Encoding"))
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+
+	reader, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Contains(t, string(decompressed), "hyperfleet_adapter_up 1")
+	assert.Less(t, w.Body.Len(), plain.Body.Len(), "the compressed payload is smaller")
+}
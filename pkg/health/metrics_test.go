@@ -1,6 +1,7 @@
 package health
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -25,7 +26,7 @@ func TestBrokerMetricsExposedOnMetricsEndpoint(t *testing.T) {
 			Name: "hyperfleet_adapter_build_info",
 			Help: "Build information for the adapter",
 		},
-		[]string{"component", "version", "commit"},
+		[]string{"component", "version", "commit", "build_date"},
 	)
 	upGauge := prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -39,7 +40,7 @@ func TestBrokerMetricsExposedOnMetricsEndpoint(t *testing.T) {
 	)
 	registry.MustRegister(buildInfo)
 	registry.MustRegister(upGauge)
-	buildInfo.WithLabelValues("test-adapter", "v0.1.0-test", "abc123").Set(1)
+	buildInfo.WithLabelValues("test-adapter", "v0.1.0-test", "abc123", "2026-01-01T00:00:00Z").Set(1)
 	upGauge.Set(1)
 
 	// Register broker metrics with the same registry (same as main.go does via DefaultRegisterer)
@@ -87,7 +88,7 @@ func TestAdapterMetricsExposedOnMetricsEndpoint(t *testing.T) {
 			Name: "hyperfleet_adapter_build_info",
 			Help: "Build information for the adapter",
 		},
-		[]string{"component", "version", "commit"},
+		[]string{"component", "version", "commit", "build_date"},
 	)
 	upGauge := prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -101,7 +102,7 @@ func TestAdapterMetricsExposedOnMetricsEndpoint(t *testing.T) {
 	)
 	registry.MustRegister(buildInfo)
 	registry.MustRegister(upGauge)
-	buildInfo.WithLabelValues("test-adapter", "v0.1.0-test", "abc123").Set(1)
+	buildInfo.WithLabelValues("test-adapter", "v0.1.0-test", "abc123", "2026-01-01T00:00:00Z").Set(1)
 	upGauge.Set(1)
 
 	// Register adapter event metrics using the same registry
@@ -112,7 +113,7 @@ func TestAdapterMetricsExposedOnMetricsEndpoint(t *testing.T) {
 	recorder.RecordEventProcessed("failed")
 	recorder.RecordEventProcessed("skipped")
 	recorder.ObserveProcessingDuration(500 * time.Millisecond)
-	recorder.RecordError("preconditions")
+	recorder.RecordError("preconditions", "APICallFailed")
 
 	// Serve metrics from the shared registry
 	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
@@ -159,3 +160,55 @@ func TestAdapterMetricsExposedOnMetricsEndpoint(t *testing.T) {
 	assert.Contains(t, metricsOutput, `version="v0.1.0-test"`,
 		"version label should be in output")
 }
+
+func TestMetricsServer_CombinedPortAndAddr(t *testing.T) {
+	registry := metrics.NewTestRegistry()
+	metricsServer, err := NewMetricsServer(&mockLogger{}, "0", MetricsConfig{
+		Component:  "test-adapter",
+		Version:    "v0.1.0-test",
+		Commit:     "abc123",
+		BuildDate:  "2026-01-01T00:00:00Z",
+		Registerer: registry,
+		Gatherer:   registry,
+	})
+	require.NoError(t, err)
+
+	// Combined mode: /metrics is served by the health server
+	healthServer := NewServer(&mockLogger{}, "0", "test-adapter")
+	healthServer.Handle("/metrics", metricsServer.Handler())
+	w := httptest.NewRecorder()
+	healthServer.server.srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `hyperfleet_adapter_build_info{build_date="2026-01-01T00:00:00Z"`)
+
+	// Standalone mode on an ephemeral port
+	require.NoError(t, metricsServer.Start(context.Background()))
+	resp, err := http.Get(localURL(t, metricsServer.Addr()) + "/metrics")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, metricsServer.Shutdown(context.Background()))
+}
+
+func TestMetricsServer_SharedRegistry(t *testing.T) {
+	registry := metrics.NewTestRegistry()
+	cfg := MetricsConfig{
+		Component:  "test-adapter",
+		Version:    "v0.1.0-test",
+		Commit:     "abc123",
+		BuildDate:  "2026-01-01T00:00:00Z",
+		Registerer: registry,
+		Gatherer:   registry,
+	}
+	first, err := NewMetricsServer(&mockLogger{}, "0", cfg)
+	require.NoError(t, err)
+	second, err := NewMetricsServer(&mockLogger{}, "0", cfg)
+	require.NoError(t, err, "a second server on the same registry does not panic")
+	assert.Same(t, first.upGauge, second.upGauge, "the servers share the registered metrics")
+
+	w := httptest.NewRecorder()
+	second.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `hyperfleet_adapter_up{component="test-adapter",version="v0.1.0-test"} 1`)
+}
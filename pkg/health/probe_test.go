@@ -0,0 +1,74 @@
+package health
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbe(t *testing.T) {
+	server := NewServer(&mockLogger{}, "0", "test-adapter")
+	require.NoError(t, server.Start(context.Background()))
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	_, port, err := net.SplitHostPort(server.Addr())
+	require.NoError(t, err)
+
+	opts := ProbeOptions{Endpoint: "healthz", Port: port, Timeout: 2 * time.Second}
+	assert.NoError(t, Probe(context.Background(), opts))
+
+	// Not ready until the config is loaded and the broker is connected
+	opts.Endpoint = "readyz"
+	err = Probe(context.Background(), opts)
+	var probeErr *ProbeError
+	require.ErrorAs(t, err, &probeErr)
+	assert.Equal(t, http.StatusServiceUnavailable, probeErr.StatusCode)
+	assert.Contains(t, string(probeErr.Body), `"status"`)
+
+	server.SetConfigLoaded()
+	server.SetBrokerReady(true)
+	assert.NoError(t, Probe(context.Background(), opts))
+
+	opts.Endpoint = "statusz"
+	assert.ErrorContains(t, Probe(context.Background(), opts), "unknown health endpoint")
+}
+
+func TestProbeConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+
+	err = Probe(context.Background(), ProbeOptions{Endpoint: "livez", Port: port, Timeout: time.Second})
+	require.Error(t, err)
+	var probeErr *ProbeError
+	assert.NotErrorAs(t, err, &probeErr)
+}
+
+func TestProbeTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/livez", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	opts := ProbeOptions{Endpoint: "livez", Host: serverURL.Hostname(), Port: serverURL.Port(), TLS: true}
+	assert.ErrorContains(t, Probe(context.Background(), opts), "certificate",
+		"the self-signed certificate is rejected by default")
+
+	opts.InsecureSkipVerify = true
+	assert.NoError(t, Probe(context.Background(), opts))
+}
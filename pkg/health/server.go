@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/version"
 )
 
 // CheckStatus represents the status of a single health check.
@@ -29,70 +30,101 @@ type HealthResponse struct {
 
 // ReadyResponse represents the JSON response for /readyz endpoint per HyperFleet standard.
 type ReadyResponse struct {
-	Checks  map[string]CheckStatus `json:"checks,omitempty"`
-	Status  string                 `json:"status"`
-	Message string                 `json:"message,omitempty"`
+	Checks map[string]CheckStatus `json:"checks,omitempty"`
+	// Errors holds the failure message of each failed dependency check
+	Errors  map[string]string `json:"errors,omitempty"`
+	Status  string            `json:"status"`
+	Message string            `json:"message,omitempty"`
 }
 
 // Server provides HTTP health check endpoints.
 type Server struct {
-	log        logger.Logger
-	server     *http.Server
-	checks     map[string]CheckStatus
-	port       string
+	log    logger.Logger
+	server *httpServer
+	mux    *http.ServeMux
+	checks map[string]CheckStatus
+	// dependencies are the checks registered with RegisterCheck
+	dependencies *dependencyChecks
+	// heartbeats are the subsystem heartbeats registered with Heartbeat
+	heartbeats *heartbeats
 	component  string
 	configYAML []byte // set only when debug_config is true
-	mu         sync.RWMutex
+	// executionHistory is served by /statusz, set with SetExecutionHistory
+	executionHistory *ExecutionHistory
+	// statsProvider fills the stats of /statusz, set with SetStatsProvider
+	statsProvider func() any
+	// startupReportProvider fills the startup of /statusz, set with SetStartupReportProvider
+	startupReportProvider func() any
+	// featureFlagsProvider fills the feature_flags of /statusz, set with SetFeatureFlagsProvider
+	featureFlagsProvider func() any
+	// faultsProvider fills the faults of /statusz, set with SetFaultsProvider
+	faultsProvider func() any
+	// createdAt is when the server was created, the start of the startup duration
+	createdAt time.Time
+	mu        sync.RWMutex
 	// shuttingDown is an atomic flag that indicates the server is shutting down.
 	// When true, /readyz immediately returns 503 regardless of other checks.
 	// This follows the HyperFleet Graceful Shutdown Standard.
 	shuttingDown atomic.Bool
+	// startedAfter is the startup duration latched by MarkStarted, 0 while starting
+	startedAfter atomic.Int64
 }
 
 // NewServer creates a new health check server.
 func NewServer(log logger.Logger, port string, component string) *Server {
 	s := &Server{
 		log:       log,
-		port:      port,
 		component: component,
 		checks: map[string]CheckStatus{
 			"config": CheckError,
 			"broker": CheckError,
 		},
+		dependencies: newDependencyChecks(),
+		heartbeats:   &heartbeats{byName: make(map[string]*Heartbeat)},
+		createdAt:    time.Now(),
 	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", s.healthzHandler)
 	mux.HandleFunc("/readyz", s.readyzHandler)
+	mux.HandleFunc("/livez", s.livezHandler)
+	mux.HandleFunc("/startupz", s.startupzHandler)
+	mux.HandleFunc("/statusz", s.statuszHandler)
+	mux.HandleFunc("/version", s.versionHandler)
 	mux.HandleFunc("/config", s.configHandler)
 
-	s.server = &http.Server{
-		Addr:              ":" + port,
-		Handler:           mux,
-		ReadHeaderTimeout: 5 * time.Second,
-	}
+	s.server = newHTTPServer(port, mux)
+	s.mux = mux
 
 	return s
 }
 
-// Start starts the health server in a goroutine.
-func (s *Server) Start(ctx context.Context) error {
-	s.log.Infof(ctx, "Starting health server on port %s", s.port)
-
-	go func() {
-		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errCtx := logger.WithErrorField(ctx, err)
-			s.log.Errorf(errCtx, "Health server error")
-		}
-	}()
+// Handle registers an additional handler on the health server, e.g. an
+// opt-in admin endpoint. Safe to call after Start.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
 
+// Start binds the health server listener and serves in a goroutine.
+// Returns an error if the port cannot be bound.
+func (s *Server) Start(ctx context.Context) error {
+	if err := s.server.start(ctx, s.log, "Health server"); err != nil {
+		return err
+	}
+	s.log.Infof(ctx, "Started health server on %s", s.Addr())
 	return nil
 }
 
+// Addr returns the address the health server listens on. After Start it
+// carries the actual port, e.g. when created with port "0".
+func (s *Server) Addr() string {
+	return s.server.addr()
+}
+
 // Shutdown gracefully shuts down the health server.
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.log.Info(ctx, "Shutting down health server...")
-	return s.server.Shutdown(ctx)
+	return s.server.shutdown(ctx)
 }
 
 // SetCheck sets the status of a specific health check.
@@ -111,6 +143,26 @@ func (s *Server) SetBrokerReady(ready bool) {
 	}
 }
 
+// SetSubscriptionReady sets the "broker:<subscriptionID>" check status.
+// Every subscription must be ready for /readyz to pass.
+func (s *Server) SetSubscriptionReady(subscriptionID string, ready bool) {
+	if ready {
+		s.SetCheck("broker:"+subscriptionID, CheckOK)
+	} else {
+		s.SetCheck("broker:"+subscriptionID, CheckError)
+	}
+}
+
+// SetStartupComplete sets the "startup" check status, failed until the startup
+// sequence completes. The check only exists once this is called.
+func (s *Server) SetStartupComplete(complete bool) {
+	if complete {
+		s.SetCheck("startup", CheckOK)
+	} else {
+		s.SetCheck("startup", CheckError)
+	}
+}
+
 // SetConfigLoaded marks the config check as ok.
 func (s *Server) SetConfigLoaded() {
 	s.SetCheck("config", CheckOK)
@@ -138,31 +190,61 @@ func (s *Server) IsShuttingDown() bool {
 }
 
 // IsReady returns true if all checks are passing and server is not shutting down.
+// Registered dependency checks are run (or their cached results reused).
 func (s *Server) IsReady() bool {
 	// Check shutdown flag first (atomic, no lock needed)
 	if s.shuttingDown.Load() {
 		return false
 	}
+	_, _, ready := s.evaluateChecks(context.Background())
+	return ready
+}
 
+// evaluateChecks returns the status of every static and registered check, the
+// failure messages of failed registered checks, and whether all checks pass.
+func (s *Server) evaluateChecks(ctx context.Context) (map[string]CheckStatus, map[string]string, bool) {
 	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	for _, status := range s.checks {
+	checks := make(map[string]CheckStatus, len(s.checks))
+	allOK := true
+	for name, status := range s.checks {
+		checks[name] = status
 		if status != CheckOK {
-			return false
+			allOK = false
 		}
 	}
-	return true
+	s.mu.RUnlock()
+
+	var failures map[string]string
+	for name, err := range s.dependencies.run(ctx) {
+		if err == nil {
+			checks[name] = CheckOK
+			continue
+		}
+		checks[name] = CheckError
+		if failures == nil {
+			failures = make(map[string]string)
+		}
+		failures[name] = err.Error()
+		allOK = false
+	}
+	return checks, failures, allOK
 }
 
 // healthzHandler handles liveness probe requests.
-// Returns 200 OK if the process is alive.
+// Returns 200 OK if the process is alive. Subsystem progress is reported by /livez.
 func (s *Server) healthzHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(HealthResponse{Status: "ok"}) //nolint:errcheck // best-effort response
 }
 
+// versionHandler serves the build version information as JSON.
+func (s *Server) versionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(version.Info()) //nolint:errcheck // best-effort response
+}
+
 // readyzHandler handles readiness probe requests.
 // Returns 200 OK with detailed checks if all checks pass,
 // 503 Service Unavailable if shutting down or any check fails.
@@ -181,16 +263,7 @@ func (s *Server) readyzHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.mu.RLock()
-	checks := make(map[string]CheckStatus, len(s.checks))
-	allOK := true
-	for name, status := range s.checks {
-		checks[name] = status
-		if status != CheckOK {
-			allOK = false
-		}
-	}
-	s.mu.RUnlock()
+	checks, failures, allOK := s.evaluateChecks(r.Context())
 
 	if allOK {
 		w.WriteHeader(http.StatusOK)
@@ -208,6 +281,7 @@ func (s *Server) readyzHandler(w http.ResponseWriter, r *http.Request) {
 		Status:  "error",
 		Message: "not ready",
 		Checks:  checks,
+		Errors:  failures,
 	})
 }
 
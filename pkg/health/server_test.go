@@ -3,12 +3,14 @@ package health
 import (
 	"context"
 	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/version"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -50,6 +52,23 @@ func TestHealthzHandler(t *testing.T) {
 	assert.Empty(t, response.Message)
 }
 
+func TestVersionHandler(t *testing.T) {
+	server := NewServer(&mockLogger{}, "8080", "test-adapter")
+
+	w := httptest.NewRecorder()
+	server.versionHandler(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var response version.VersionInfo
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, version.Info(), response)
+	assert.NotEmpty(t, response.Version)
+	assert.NotEmpty(t, response.Commit)
+	assert.NotEmpty(t, response.BuildDate)
+}
+
 func TestReadyzHandler_NotReady(t *testing.T) {
 	server := NewServer(&mockLogger{}, "8080", "test-adapter")
 	// By default, checks are in error state
@@ -120,6 +139,29 @@ func TestSetBrokerReady(t *testing.T) {
 	assert.False(t, server.IsReady())
 }
 
+func TestSetSubscriptionReady(t *testing.T) {
+	server := NewServer(&mockLogger{}, "8080", "test-adapter")
+	server.SetConfigLoaded()
+	server.SetBrokerReady(true)
+
+	server.SetSubscriptionReady("cluster-events", true)
+	server.SetSubscriptionReady("nodepool-events", false)
+	assert.False(t, server.IsReady(), "one failed subscription makes the adapter not ready")
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	w := httptest.NewRecorder()
+	server.readyzHandler(w, req)
+
+	var response ReadyResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, CheckOK, response.Checks["broker:cluster-events"])
+	assert.Equal(t, CheckError, response.Checks["broker:nodepool-events"])
+
+	server.SetSubscriptionReady("nodepool-events", true)
+	assert.True(t, server.IsReady())
+}
+
 func TestSetCheck(t *testing.T) {
 	server := NewServer(&mockLogger{}, "8080", "test-adapter")
 
@@ -284,10 +326,17 @@ func TestReadyzHandler_ShuttingDownPriority(t *testing.T) {
 	assert.Equal(t, "server is shutting down", response.Message)
 }
 
+// localURL returns the base URL of a server listening on addr
+func localURL(t *testing.T, addr string) string {
+	t.Helper()
+	_, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	require.NotEqual(t, "0", port, "Addr returns the bound port")
+	return "http://localhost:" + port
+}
+
 func TestServerLifecycle_StartAndShutdown(t *testing.T) {
-	// Use a unique port to avoid conflicts
-	port := "18080"
-	server := NewServer(&mockLogger{}, port, "test-adapter")
+	server := NewServer(&mockLogger{}, "0", "test-adapter")
 
 	ctx := context.Background()
 
@@ -295,11 +344,11 @@ func TestServerLifecycle_StartAndShutdown(t *testing.T) {
 	err := server.Start(ctx)
 	require.NoError(t, err)
 
-	// Give the server time to start listening
-	time.Sleep(50 * time.Millisecond)
+	// Start binds the listener synchronously, so the server is reachable right away
+	baseURL := localURL(t, server.Addr())
 
 	// Verify server is listening by making an HTTP request to /healthz
-	resp, err := http.Get("http://localhost:" + port + "/healthz")
+	resp, err := http.Get(baseURL + "/healthz")
 	require.NoError(t, err)
 	defer func() { _ = resp.Body.Close() }()
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
@@ -316,17 +365,13 @@ func TestServerLifecycle_StartAndShutdown(t *testing.T) {
 	err = server.Shutdown(shutdownCtx)
 	require.NoError(t, err)
 
-	// Give the server time to fully shutdown
-	time.Sleep(50 * time.Millisecond)
-
 	// Verify server stopped accepting connections
-	_, err = http.Get("http://localhost:" + port + "/healthz")
+	_, err = http.Get(baseURL + "/healthz")
 	assert.Error(t, err, "expected connection refused after shutdown")
 }
 
 func TestServerLifecycle_ReadyzWhileRunning(t *testing.T) {
-	port := "18081"
-	server := NewServer(&mockLogger{}, port, "test-adapter")
+	server := NewServer(&mockLogger{}, "0", "test-adapter")
 
 	ctx := context.Background()
 
@@ -339,13 +384,13 @@ func TestServerLifecycle_ReadyzWhileRunning(t *testing.T) {
 		_ = server.Shutdown(shutdownCtx)
 	}()
 
-	// Give the server time to start listening
-	time.Sleep(50 * time.Millisecond)
+	// Start binds the listener synchronously, so the server is reachable right away
+	baseURL := localURL(t, server.Addr())
 
 	// Initially not ready (checks are in error state)
 	assert.False(t, server.IsReady())
 
-	resp, err := http.Get("http://localhost:" + port + "/readyz")
+	resp, err := http.Get(baseURL + "/readyz")
 	require.NoError(t, err)
 	defer func() { _ = resp.Body.Close() }()
 	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
@@ -355,15 +400,14 @@ func TestServerLifecycle_ReadyzWhileRunning(t *testing.T) {
 	server.SetBrokerReady(true)
 	assert.True(t, server.IsReady())
 
-	resp2, err := http.Get("http://localhost:" + port + "/readyz")
+	resp2, err := http.Get(baseURL + "/readyz")
 	require.NoError(t, err)
 	defer func() { _ = resp2.Body.Close() }()
 	assert.Equal(t, http.StatusOK, resp2.StatusCode)
 }
 
 func TestServerLifecycle_GracefulShutdownStateTransitions(t *testing.T) {
-	port := "18082"
-	server := NewServer(&mockLogger{}, port, "test-adapter")
+	server := NewServer(&mockLogger{}, "0", "test-adapter")
 
 	ctx := context.Background()
 
@@ -376,8 +420,8 @@ func TestServerLifecycle_GracefulShutdownStateTransitions(t *testing.T) {
 		_ = server.Shutdown(shutdownCtx)
 	}()
 
-	// Give the server time to start listening
-	time.Sleep(50 * time.Millisecond)
+	// Start binds the listener synchronously, so the server is reachable right away
+	baseURL := localURL(t, server.Addr())
 
 	// Set server to ready state
 	server.SetConfigLoaded()
@@ -388,7 +432,7 @@ func TestServerLifecycle_GracefulShutdownStateTransitions(t *testing.T) {
 	assert.False(t, server.IsShuttingDown())
 
 	// Verify /readyz returns 200
-	resp, err := http.Get("http://localhost:" + port + "/readyz")
+	resp, err := http.Get(baseURL + "/readyz")
 	require.NoError(t, err)
 	defer func() { _ = resp.Body.Close() }()
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
@@ -401,7 +445,7 @@ func TestServerLifecycle_GracefulShutdownStateTransitions(t *testing.T) {
 	assert.False(t, server.IsReady()) // IsReady should return false when shutting down
 
 	// Verify /readyz now returns 503 with shutdown message
-	resp2, err := http.Get("http://localhost:" + port + "/readyz")
+	resp2, err := http.Get(baseURL + "/readyz")
 	require.NoError(t, err)
 	defer func() { _ = resp2.Body.Close() }()
 	assert.Equal(t, http.StatusServiceUnavailable, resp2.StatusCode)
@@ -413,15 +457,14 @@ func TestServerLifecycle_GracefulShutdownStateTransitions(t *testing.T) {
 	assert.Equal(t, "server is shutting down", readyResp.Message)
 
 	// Verify /healthz still returns 200 (liveness should work during shutdown)
-	resp3, err := http.Get("http://localhost:" + port + "/healthz")
+	resp3, err := http.Get(baseURL + "/healthz")
 	require.NoError(t, err)
 	defer func() { _ = resp3.Body.Close() }()
 	assert.Equal(t, http.StatusOK, resp3.StatusCode)
 }
 
 func TestServerLifecycle_ShutdownTimeout(t *testing.T) {
-	port := "18083"
-	server := NewServer(&mockLogger{}, port, "test-adapter")
+	server := NewServer(&mockLogger{}, "0", "test-adapter")
 
 	ctx := context.Background()
 
@@ -429,9 +472,6 @@ func TestServerLifecycle_ShutdownTimeout(t *testing.T) {
 	err := server.Start(ctx)
 	require.NoError(t, err)
 
-	// Give the server time to start listening
-	time.Sleep(50 * time.Millisecond)
-
 	// Shutdown with a very short timeout (should still succeed for idle server)
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
@@ -439,3 +479,30 @@ func TestServerLifecycle_ShutdownTimeout(t *testing.T) {
 	err = server.Shutdown(shutdownCtx)
 	require.NoError(t, err)
 }
+
+func TestServerLifecycle_PortInUse(t *testing.T) {
+	first := NewServer(&mockLogger{}, "0", "test-adapter")
+	require.NoError(t, first.Start(context.Background()))
+	defer func() { _ = first.Shutdown(context.Background()) }()
+
+	_, port, err := net.SplitHostPort(first.Addr())
+	require.NoError(t, err)
+
+	second := NewServer(&mockLogger{}, port, "test-adapter")
+	err = second.Start(context.Background())
+	require.Error(t, err, "bind errors are returned synchronously")
+	assert.Contains(t, err.Error(), "address already in use")
+}
+
+func TestHandle_RegistersAdditionalEndpoint(t *testing.T) {
+	server := NewServer(&mockLogger{}, "8080", "test-adapter")
+	server.Handle("/admin/test", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/test", nil)
+	w := httptest.NewRecorder()
+	server.server.srv.Handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+}
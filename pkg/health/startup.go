@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// StartupResponse represents the JSON response for the /startupz endpoint
+type StartupResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+	// Duration is the time from server creation to MarkStarted, or the time
+	// elapsed so far while starting
+	Duration        string  `json:"duration"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// MarkStarted latches the startup probe: /startupz returns 200 from now on,
+// whatever readiness reports later. Returns the startup duration, measured
+// from server creation; later calls return the duration of the first one.
+func (s *Server) MarkStarted() time.Duration {
+	s.startedAfter.CompareAndSwap(0, int64(time.Since(s.createdAt)))
+	return time.Duration(s.startedAfter.Load())
+}
+
+// IsStarted returns true once MarkStarted was called.
+func (s *Server) IsStarted() bool {
+	return s.startedAfter.Load() != 0
+}
+
+// WaitUntilReady blocks until IsReady passes, polling every interval, so
+// callers can MarkStarted after the first successful dependency check.
+// Returns ctx.Err() if ctx is done first.
+func (s *Server) WaitUntilReady(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if s.IsReady() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// startupzHandler handles startup probe requests.
+// Returns 503 until MarkStarted is called, then 200 forever, so readiness
+// flaps after startup never fail the startup probe.
+func (s *Server) startupzHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if s.IsStarted() {
+		duration := time.Duration(s.startedAfter.Load())
+		w.WriteHeader(http.StatusOK)
+		//nolint:errcheck // best-effort response
+		_ = json.NewEncoder(w).Encode(StartupResponse{
+			Status:          "ok",
+			Duration:        duration.Round(time.Millisecond).String(),
+			DurationSeconds: duration.Seconds(),
+		})
+		return
+	}
+
+	elapsed := time.Since(s.createdAt)
+	w.WriteHeader(http.StatusServiceUnavailable)
+	//nolint:errcheck // best-effort response
+	_ = json.NewEncoder(w).Encode(StartupResponse{
+		Status:          "error",
+		Message:         "starting",
+		Duration:        elapsed.Round(time.Millisecond).String(),
+		DurationSeconds: elapsed.Seconds(),
+	})
+}
@@ -0,0 +1,77 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getStartupz(t *testing.T, server *Server) (int, StartupResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	server.startupzHandler(w, httptest.NewRequest(http.MethodGet, "/startupz", nil))
+	var response StartupResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	return w.Code, response
+}
+
+func TestStartupz_NotStarted(t *testing.T) {
+	server := newReadyServer()
+
+	code, response := getStartupz(t, server)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "starting", response.Message)
+	assert.False(t, server.IsStarted())
+}
+
+func TestStartupz_LatchesAfterMarkStarted(t *testing.T) {
+	server := newReadyServer()
+	time.Sleep(10 * time.Millisecond)
+
+	duration := server.MarkStarted()
+	assert.GreaterOrEqual(t, duration, 10*time.Millisecond)
+	assert.True(t, server.IsStarted())
+
+	code, response := getStartupz(t, server)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", response.Status)
+	assert.InDelta(t, duration.Seconds(), response.DurationSeconds, 1e-9)
+
+	// Readiness flaps after startup do not fail the startup probe
+	server.SetBrokerReady(false)
+	server.SetShuttingDown(true)
+	code, _ = getStartupz(t, server)
+	assert.Equal(t, http.StatusOK, code)
+
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, duration, server.MarkStarted(), "later calls keep the first startup duration")
+}
+
+func TestWaitUntilReady(t *testing.T) {
+	server := NewServer(&mockLogger{}, "8080", "test-adapter")
+	server.SetConfigLoaded()
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		server.SetBrokerReady(true)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, server.WaitUntilReady(ctx, 5*time.Millisecond))
+	assert.True(t, server.IsReady())
+}
+
+func TestWaitUntilReady_ContextDone(t *testing.T) {
+	server := NewServer(&mockLogger{}, "8080", "test-adapter")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, server.WaitUntilReady(ctx, 5*time.Millisecond), context.DeadlineExceeded)
+}
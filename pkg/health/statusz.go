@@ -0,0 +1,191 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultExecutionHistorySize is the number of executions kept for /statusz
+const DefaultExecutionHistorySize = 100
+
+// ExecutionSummary is the redacted summary of one event execution served by
+// /statusz. It must never carry extracted params.
+type ExecutionSummary struct {
+	Timestamp time.Time `json:"timestamp"`
+	EventID   string    `json:"event_id"`
+	EventType string    `json:"event_type"`
+	// Status is success, skipped or failed
+	Status string `json:"status"`
+	// Phase is the execution phase reached
+	Phase string `json:"phase"`
+	// Reason is the skip reason or the error of a failed execution
+	Reason string `json:"reason,omitempty"`
+	// ErrorCode is the error code of a failed execution
+	ErrorCode string `json:"error_code,omitempty"`
+	Duration  string `json:"duration"`
+	// TraceID is the trace of the execution, empty when it was not traced
+	TraceID string `json:"trace_id,omitempty"`
+}
+
+// StatuszResponse represents the JSON response for the /statusz endpoint
+type StatuszResponse struct {
+	// Startup is the report of the startup sequence, when a startup report provider is set
+	Startup any `json:"startup,omitempty"`
+	// Stats are the executor's runtime counters, when a stats provider is set
+	Stats any `json:"stats,omitempty"`
+	// FeatureFlags are the current feature flags, when a feature flags provider is set
+	FeatureFlags any `json:"feature_flags,omitempty"`
+	// Faults are the active fault injection rules, when a faults provider is set
+	Faults any `json:"faults,omitempty"`
+	// Executions are the most recent executions, newest first
+	Executions []ExecutionSummary `json:"executions"`
+	Size       int                `json:"size"`
+}
+
+// ExecutionHistory is a fixed-size ring buffer of the most recent execution
+// summaries. Recording copies the summary into a preallocated slot, so it does
+// not allocate. All methods are safe for concurrent use and nil-safe.
+type ExecutionHistory struct {
+	entries []ExecutionSummary
+	// next is the slot the next summary is written to
+	next  int
+	count int
+	mu    sync.Mutex
+}
+
+// NewExecutionHistory creates a history keeping the last size executions.
+// A size <= 0 uses DefaultExecutionHistorySize.
+func NewExecutionHistory(size int) *ExecutionHistory {
+	if size <= 0 {
+		size = DefaultExecutionHistorySize
+	}
+	return &ExecutionHistory{entries: make([]ExecutionSummary, size)}
+}
+
+// Record adds a summary, overwriting the oldest one when the history is full.
+func (h *ExecutionHistory) Record(summary ExecutionSummary) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries[h.next] = summary
+	h.next = (h.next + 1) % len(h.entries)
+	if h.count < len(h.entries) {
+		h.count++
+	}
+}
+
+// Recent returns the recorded summaries, newest first. A non-empty status
+// keeps only the summaries with that status.
+func (h *ExecutionHistory) Recent(status string) []ExecutionSummary {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	recent := make([]ExecutionSummary, 0, h.count)
+	for i := 1; i <= h.count; i++ {
+		summary := h.entries[(h.next-i+len(h.entries))%len(h.entries)]
+		if status != "" && summary.Status != status {
+			continue
+		}
+		recent = append(recent, summary)
+	}
+	return recent
+}
+
+// Size returns the number of executions the history keeps
+func (h *ExecutionHistory) Size() int {
+	if h == nil {
+		return 0
+	}
+	return len(h.entries)
+}
+
+// SetExecutionHistory serves history from /statusz. Until it or a startup
+// report provider is set, /statusz returns 404.
+func (s *Server) SetExecutionHistory(history *ExecutionHistory) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.executionHistory = history
+}
+
+// SetStatsProvider serves the value returned by stats in the stats field of
+// /statusz, e.g. the executor's Stats. stats must be safe for concurrent use.
+func (s *Server) SetStatsProvider(stats func() any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statsProvider = stats
+}
+
+// SetFeatureFlagsProvider serves the value returned by flags in the
+// feature_flags field of /statusz, e.g. the report of the feature flag store.
+// flags must be safe for concurrent use.
+func (s *Server) SetFeatureFlagsProvider(flags func() any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.featureFlagsProvider = flags
+}
+
+// SetFaultsProvider serves the value returned by faults in the faults field of
+// /statusz, e.g. the active rules of the fault injector. faults must be safe
+// for concurrent use.
+func (s *Server) SetFaultsProvider(faults func() any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faultsProvider = faults
+}
+
+// SetStartupReportProvider serves the value returned by report in the startup
+// field of /statusz, e.g. the report of the startup sequence. /statusz is served
+// from then on, before the execution history is set. report must be safe for
+// concurrent use.
+func (s *Server) SetStartupReportProvider(report func() any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.startupReportProvider = report
+}
+
+// statuszHandler serves the recent execution summaries as JSON, optionally
+// filtered with ?status=success|skipped|failed.
+func (s *Server) statuszHandler(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	history := s.executionHistory
+	statsProvider := s.statsProvider
+	startupReportProvider := s.startupReportProvider
+	featureFlagsProvider := s.featureFlagsProvider
+	faultsProvider := s.faultsProvider
+	s.mu.RUnlock()
+
+	if history == nil && startupReportProvider == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	response := StatuszResponse{
+		Executions: history.Recent(r.URL.Query().Get("status")),
+		Size:       history.Size(),
+	}
+	if response.Executions == nil {
+		response.Executions = []ExecutionSummary{}
+	}
+	if statsProvider != nil {
+		response.Stats = statsProvider()
+	}
+	if startupReportProvider != nil {
+		response.Startup = startupReportProvider()
+	}
+	if featureFlagsProvider != nil {
+		response.FeatureFlags = featureFlagsProvider()
+	}
+	if faultsProvider != nil {
+		response.Faults = faultsProvider()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	//nolint:errcheck // best-effort response
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,158 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func summary(id, status string) ExecutionSummary {
+	return ExecutionSummary{EventID: id, EventType: "io.hyperfleet.cluster.updated", Status: status}
+}
+
+func eventIDs(summaries []ExecutionSummary) []string {
+	ids := make([]string, len(summaries))
+	for i, s := range summaries {
+		ids[i] = s.EventID
+	}
+	return ids
+}
+
+func TestExecutionHistory_KeepsMostRecent(t *testing.T) {
+	history := NewExecutionHistory(3)
+	assert.Empty(t, history.Recent(""))
+
+	history.Record(summary("e1", "success"))
+	history.Record(summary("e2", "failed"))
+	assert.Equal(t, []string{"e2", "e1"}, eventIDs(history.Recent("")))
+
+	history.Record(summary("e3", "skipped"))
+	history.Record(summary("e4", "failed"))
+	history.Record(summary("e5", "success"))
+	assert.Equal(t, []string{"e5", "e4", "e3"}, eventIDs(history.Recent("")), "oldest entries are overwritten")
+	assert.Equal(t, []string{"e4"}, eventIDs(history.Recent("failed")))
+	assert.Equal(t, 3, history.Size())
+}
+
+func TestExecutionHistory_DefaultSize(t *testing.T) {
+	assert.Equal(t, DefaultExecutionHistorySize, NewExecutionHistory(0).Size())
+}
+
+func TestExecutionHistory_NilSafe(t *testing.T) {
+	var history *ExecutionHistory
+	assert.NotPanics(t, func() { history.Record(summary("e1", "success")) })
+	assert.Nil(t, history.Recent(""))
+	assert.Zero(t, history.Size())
+}
+
+func TestExecutionHistory_Concurrent(t *testing.T) {
+	const writers, perWriter = 8, 500
+	history := NewExecutionHistory(50)
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				history.Record(summary(fmt.Sprintf("w%d-%d", w, i), "success"))
+			}
+		}(w)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			recent := history.Recent("")
+			assert.LessOrEqual(t, len(recent), 50)
+			for _, s := range recent {
+				assert.NotEmpty(t, s.EventID)
+			}
+		}
+	}()
+	wg.Wait()
+	<-done
+
+	assert.Len(t, history.Recent(""), 50)
+}
+
+func TestStatuszHandler(t *testing.T) {
+	server := NewServer(&mockLogger{}, "8080", "test-adapter")
+
+	w := httptest.NewRecorder()
+	server.statuszHandler(w, httptest.NewRequest(http.MethodGet, "/statusz", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code, "404 until a history is set")
+
+	history := NewExecutionHistory(10)
+	history.Record(summary("e1", "success"))
+	history.Record(summary("e2", "failed"))
+	server.SetExecutionHistory(history)
+
+	tests := []struct {
+		name    string
+		query   string
+		wantIDs []string
+	}{
+		{name: "all", query: "", wantIDs: []string{"e2", "e1"}},
+		{name: "failed only", query: "?status=failed", wantIDs: []string{"e2"}},
+		{name: "no match", query: "?status=skipped", wantIDs: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			server.statuszHandler(w, httptest.NewRequest(http.MethodGet, "/statusz"+tt.query, nil))
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+			var response StatuszResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t, tt.wantIDs, eventIDs(response.Executions))
+			assert.Equal(t, 10, response.Size)
+			assert.Nil(t, response.Stats, "no stats until a provider is set")
+		})
+	}
+
+	server.SetStatsProvider(func() any { return map[string]int{"succeeded": 1} })
+	w = httptest.NewRecorder()
+	server.statuszHandler(w, httptest.NewRequest(http.MethodGet, "/statusz", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var response StatuszResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, map[string]interface{}{"succeeded": float64(1)}, response.Stats)
+	assert.Nil(t, response.FeatureFlags, "no feature flags until a provider is set")
+
+	server.SetFeatureFlagsProvider(func() any { return map[string]bool{"canary": true} })
+	w = httptest.NewRecorder()
+	server.statuszHandler(w, httptest.NewRequest(http.MethodGet, "/statusz", nil))
+	response = StatuszResponse{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, map[string]interface{}{"canary": true}, response.FeatureFlags)
+	assert.Nil(t, response.Faults, "no faults until a provider is set")
+
+	server.SetFaultsProvider(func() any { return []string{} })
+	w = httptest.NewRecorder()
+	server.statuszHandler(w, httptest.NewRequest(http.MethodGet, "/statusz", nil))
+	response = StatuszResponse{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, []interface{}{}, response.Faults, "an enabled injector without rules is reported")
+}
+
+func TestStatuszHandler_StartupReport(t *testing.T) {
+	server := NewServer(&mockLogger{}, "8080", "test-adapter")
+	server.SetStartupReportProvider(func() any { return map[string]bool{"ready": false} })
+
+	w := httptest.NewRecorder()
+	server.statuszHandler(w, httptest.NewRequest(http.MethodGet, "/statusz", nil))
+	require.Equal(t, http.StatusOK, w.Code, "served while starting, before the history is set")
+	var response StatuszResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, map[string]interface{}{"ready": false}, response.Startup)
+	assert.NotNil(t, response.Executions)
+	assert.Empty(t, response.Executions)
+}
@@ -13,6 +13,8 @@ type contextKey string
 // Context keys for storing values in context.Context
 const (
 	LogFieldsKey contextKey = "log_fields"
+	// infoAsDebugKey marks a context whose info logs are emitted at debug level
+	infoAsDebugKey contextKey = "info_as_debug"
 )
 
 // Log field name constants - use these directly in WithFields maps
@@ -30,6 +32,9 @@ const (
 	TraceIDKey = "trace_id"
 	SpanIDKey  = "span_id"
 	EventIDKey = "event_id"
+	// CorrelationIDKey is the correlation ID of an execution, also sent to the
+	// HyperFleet API and set on applied resources
+	CorrelationIDKey = "correlation_id"
 
 	// Resource fields (from event data)
 	ResourceTypeKey = "resource_type"
@@ -40,13 +45,29 @@ const (
 	K8sNamespaceKey = "k8s_namespace"
 	K8sResultKey    = "k8s_result"
 
+	// Executor fields: the phase and the named step (precondition, resource,
+	// post action) a log line was emitted in
+	PhaseKey = "phase"
+	StepKey  = "step"
+
 	// Adapter-specific fields
 	AdapterKey            = "adapter"
 	ObservedGenerationKey = "observed_generation"
 	SubscriptionKey       = "subscription"
+	// DeliveryAttemptKey is the broker delivery attempt of the event, "unknown"
+	// outside of the broker consumer
+	DeliveryAttemptKey = "delivery_attempt"
 
 	// Maestro-specific fields
 	MaestroConsumerKey = "maestro_consumer"
+
+	// HyperFleet API fields
+	APITargetKey = "api_target"
+
+	// Event data fields: the media type of the event data and what the
+	// executor did with it (accepted, wrapped, filtered, invalid)
+	DataContentTypeKey = "data_content_type"
+	DataDecisionKey    = "data_decision"
 )
 
 // LogFields holds dynamic key-value pairs for logging
@@ -102,6 +123,11 @@ func WithEventID(ctx context.Context, eventID string) context.Context {
 	return WithLogField(ctx, EventIDKey, eventID)
 }
 
+// WithCorrelationID returns a context with the execution correlation ID set
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return WithLogField(ctx, CorrelationIDKey, correlationID)
+}
+
 // WithResourceType returns a context with the event resource type set (e.g., "cluster", "nodepool")
 func WithResourceType(ctx context.Context, resourceType string) context.Context {
 	return WithLogField(ctx, ResourceTypeKey, resourceType)
@@ -142,11 +168,46 @@ func WithSubscription(ctx context.Context, subscription string) context.Context
 	return WithLogField(ctx, SubscriptionKey, subscription)
 }
 
+// WithDeliveryAttempt returns a context with the broker delivery attempt set
+func WithDeliveryAttempt(ctx context.Context, attempt string) context.Context {
+	return WithLogField(ctx, DeliveryAttemptKey, attempt)
+}
+
 // WithMaestroConsumer returns a context with the Maestro consumer name set
 func WithMaestroConsumer(ctx context.Context, consumer string) context.Context {
 	return WithLogField(ctx, MaestroConsumerKey, consumer)
 }
 
+// WithAPITarget returns a context with the HyperFleet API target name set
+func WithAPITarget(ctx context.Context, target string) context.Context {
+	return WithLogField(ctx, APITargetKey, target)
+}
+
+// WithDataContentType returns a context with the event data content type set
+func WithDataContentType(ctx context.Context, contentType string) context.Context {
+	return WithLogField(ctx, DataContentTypeKey, contentType)
+}
+
+// WithDataDecision returns a context with the event data decision set
+func WithDataDecision(ctx context.Context, decision string) context.Context {
+	return WithLogField(ctx, DataDecisionKey, decision)
+}
+
+// WithInfoAsDebug returns a context whose info logs are emitted at debug level,
+// e.g. to quiet the logs of an outcome repeated execution after execution
+func WithInfoAsDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, infoAsDebugKey, true)
+}
+
+// infoAsDebug reports whether the info logs of ctx are emitted at debug level
+func infoAsDebug(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	quiet, _ := ctx.Value(infoAsDebugKey).(bool)
+	return quiet
+}
+
 // WithErrorField returns a context with the error message set.
 // Stack traces are captured only for unexpected/internal errors to avoid
 // performance overhead under high event load. Expected operational errors
@@ -0,0 +1,349 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Log outputs writing to a file, in addition to "stdout" and "stderr"
+const (
+	// OutputFile writes log lines to Config.File only
+	OutputFile = "file"
+	// OutputBoth writes log lines to stdout and Config.File
+	OutputBoth = "both"
+)
+
+// DefaultMaxSizeMB is the size at which the log file is rotated when
+// FileConfig.MaxSizeMB is not set
+const DefaultMaxSizeMB = 100
+
+// backupTimeFormat is the timestamp inserted in the names of rotated files
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+const compressSuffix = ".gz"
+
+// FileConfig configures a rotating log file
+type FileConfig struct {
+	// Path of the active log file. Rotated files are written next to it as
+	// <name>-<timestamp><ext>, with a .gz suffix when compressed.
+	Path string
+	// MaxSizeMB is the size in megabytes at which the file is rotated. Default: 100.
+	MaxSizeMB int
+	// MaxBackups is the number of rotated files kept; 0 keeps all of them
+	MaxBackups int
+	// MaxAgeDays is the number of days rotated files are kept; 0 keeps them regardless of age
+	MaxAgeDays int
+	// Compress gzips rotated files
+	Compress bool
+}
+
+// RotatingFile is an io.WriteCloser appending to a log file, rotating it when
+// it would grow past the configured size. Old files are removed and compressed
+// in the background. It is safe for concurrent use.
+type RotatingFile struct {
+	file *os.File
+	// now returns the time used in backup names, replaced in tests
+	now      func() time.Time
+	cfg      FileConfig
+	maxBytes int64
+	size     int64
+	millWG   sync.WaitGroup
+	mu       sync.Mutex
+	millMu   sync.Mutex
+}
+
+// OpenRotatingFile opens, or creates, the log file of cfg and its directory
+func OpenRotatingFile(cfg FileConfig) (*RotatingFile, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("log file path is required")
+	}
+	if cfg.MaxSizeMB < 0 || cfg.MaxBackups < 0 || cfg.MaxAgeDays < 0 {
+		return nil, fmt.Errorf("log file max_size_mb, max_backups and max_age_days must not be negative")
+	}
+	maxSizeMB := cfg.MaxSizeMB
+	if maxSizeMB == 0 {
+		maxSizeMB = DefaultMaxSizeMB
+	}
+	f := &RotatingFile{cfg: cfg, maxBytes: int64(maxSizeMB) * 1024 * 1024, now: time.Now}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.openLocked(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Path returns the path of the active log file
+func (f *RotatingFile) Path() string {
+	return f.cfg.Path
+}
+
+// Write appends p to the file, rotating it first if p would make it exceed
+// the maximum size. A single write larger than the maximum size is written
+// to a fresh file rather than split.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		if err := f.openLocked(); err != nil {
+			return 0, err
+		}
+	}
+	if f.size > 0 && f.size+int64(len(p)) > f.maxBytes {
+		if err := f.rotateLocked(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the active file, renames it to a backup and opens a new one
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rotateLocked()
+}
+
+// Reopen closes and reopens the file at its path, for external rotation
+// with logrotate: after logrotate renamed the file, send SIGHUP so new lines
+// go to a new file at the configured path.
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// A broken file is what Reopen recovers from, so closing it may fail
+	_ = f.closeLocked() //nolint:errcheck // see above
+	return f.openLocked()
+}
+
+// Close closes the file and waits for pending compression and cleanup
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	err := f.closeLocked()
+	f.mu.Unlock()
+	f.millWG.Wait()
+	return err
+}
+
+func (f *RotatingFile) openLocked() error {
+	if err := os.MkdirAll(filepath.Dir(f.cfg.Path), 0o750); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(filepath.Clean(f.cfg.Path), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close() //nolint:errcheck // already failing
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *RotatingFile) closeLocked() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *RotatingFile) rotateLocked() error {
+	if err := f.closeLocked(); err != nil {
+		return fmt.Errorf("failed to close log file for rotation: %w", err)
+	}
+	// Rotations within the same millisecond get distinct names
+	rotatedAt := f.now()
+	backup := f.backupName(rotatedAt)
+	for fileExists(backup) || fileExists(backup+compressSuffix) {
+		rotatedAt = rotatedAt.Add(time.Millisecond)
+		backup = f.backupName(rotatedAt)
+	}
+	if err := os.Rename(f.cfg.Path, backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rename log file for rotation: %w", err)
+	}
+	if err := f.openLocked(); err != nil {
+		return err
+	}
+	f.millWG.Add(1)
+	go func() {
+		defer f.millWG.Done()
+		f.mill()
+	}()
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// backupName returns the name of the backup of the active file rotated at t
+func (f *RotatingFile) backupName(t time.Time) string {
+	dir, prefix, ext := f.nameParts()
+	return filepath.Join(dir, prefix+t.UTC().Format(backupTimeFormat)+ext)
+}
+
+func (f *RotatingFile) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(f.cfg.Path)
+	base := filepath.Base(f.cfg.Path)
+	ext = filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+type backupFile struct {
+	rotatedAt time.Time
+	path      string
+}
+
+// backups lists the rotated files, newest first
+func (f *RotatingFile) backups() ([]backupFile, error) {
+	dir, prefix, ext := f.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimPrefix(name, prefix)
+		stamp = strings.TrimSuffix(stamp, compressSuffix)
+		if !strings.HasSuffix(stamp, ext) {
+			continue
+		}
+		rotatedAt, err := time.Parse(backupTimeFormat, strings.TrimSuffix(stamp, ext))
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{rotatedAt: rotatedAt, path: filepath.Join(dir, name)})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].rotatedAt.After(backups[j].rotatedAt) })
+	return backups, nil
+}
+
+// mill removes the backups beyond MaxBackups or older than MaxAgeDays and
+// compresses the remaining ones when Compress is set. Errors are ignored:
+// they must not affect logging, and the next rotation retries.
+func (f *RotatingFile) mill() {
+	f.millMu.Lock()
+	defer f.millMu.Unlock()
+
+	backups, err := f.backups()
+	if err != nil {
+		return
+	}
+	cutoff := time.Time{}
+	if f.cfg.MaxAgeDays > 0 {
+		cutoff = f.now().Add(-time.Duration(f.cfg.MaxAgeDays) * 24 * time.Hour)
+	}
+	for i, backup := range backups {
+		if (f.cfg.MaxBackups > 0 && i >= f.cfg.MaxBackups) || backup.rotatedAt.Before(cutoff) {
+			_ = os.Remove(backup.path) //nolint:errcheck // retried on the next rotation
+			continue
+		}
+		if f.cfg.Compress && !strings.HasSuffix(backup.path, compressSuffix) {
+			_ = compressFile(backup.path) //nolint:errcheck // retried on the next rotation
+		}
+	}
+}
+
+// compressFile gzips path to path.gz and removes path
+func compressFile(path string) (err error) {
+	src, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer src.Close() //nolint:errcheck // read-only
+
+	dstPath := path + compressSuffix
+	dst, err := os.OpenFile(filepath.Clean(dstPath), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(dstPath) //nolint:errcheck // partial output
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = dst.Close() //nolint:errcheck // already failing
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		_ = dst.Close() //nolint:errcheck // already failing
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// fileSink writes log lines to a file and, with OutputBoth, to the console.
+// When writing to the file fails, lines go to the console instead and a
+// warning is logged there once, until a write succeeds again.
+type fileSink struct {
+	file    io.Writer
+	console io.Writer
+	// warn logs the one-time warning to the console in the logger's format
+	warn    *slog.Logger
+	path    string
+	both    bool
+	failing atomic.Bool
+}
+
+func (s *fileSink) Write(p []byte) (int, error) {
+	if s.both {
+		if _, err := s.console.Write(p); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := s.file.Write(p); err != nil {
+		if !s.failing.Swap(true) {
+			s.warn.Warn("Failed to write log file, logging to the console until it recovers",
+				"path", s.path, ErrorKey, err.Error())
+		}
+		if !s.both {
+			return s.console.Write(p)
+		}
+		return len(p), nil
+	}
+	if s.failing.Swap(false) {
+		s.warn.Info("Log file writes recovered", "path", s.path)
+	}
+	return len(p), nil
+}
+
+// newFileSink returns the writer of a logger with a file output
+func newFileSink(cfg Config, console io.Writer, newHandler func(io.Writer) slog.Handler) (io.Writer, error) {
+	if cfg.File == nil {
+		return nil, fmt.Errorf("log output %q requires a log file", cfg.Output)
+	}
+	warn := slog.New(newHandler(console)).With(ComponentKey, cfg.Component, VersionKey, cfg.Version)
+	return &fileSink{
+		file:    cfg.File,
+		console: console,
+		warn:    warn,
+		path:    cfg.File.Path(),
+		both:    cfg.Output == OutputBoth,
+	}, nil
+}
@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFileLogger(t *testing.T, output string, file *RotatingFile) (Logger, *bytes.Buffer) {
+	t.Helper()
+	console := &bytes.Buffer{}
+	log, err := NewLogger(Config{
+		Level: "info", Format: "json", Output: output, Writer: console, File: file,
+		Component: "test", Version: "test",
+	})
+	require.NoError(t, err)
+	return log, console
+}
+
+// readJSONLines parses every line of a log file, gunzipping .gz files, and
+// returns their messages
+func readJSONLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Clean(path))
+	require.NoError(t, err)
+	var reader io.Reader = bytes.NewReader(data)
+	if strings.HasSuffix(path, compressSuffix) {
+		gz, err := gzip.NewReader(reader)
+		require.NoError(t, err)
+		reader = gz
+	}
+	var messages []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry), "line of %s is JSON: %s", path, scanner.Text())
+		messages = append(messages, entry["msg"].(string))
+	}
+	require.NoError(t, scanner.Err())
+	return messages
+}
+
+func backupPaths(t *testing.T, f *RotatingFile) []string {
+	t.Helper()
+	backups, err := f.backups()
+	require.NoError(t, err)
+	paths := make([]string, 0, len(backups))
+	for _, backup := range backups {
+		paths = append(paths, backup.path)
+	}
+	return paths
+}
+
+// writeLines logs n lines of about 300 bytes
+func writeLines(log Logger, n int) {
+	padding := strings.Repeat("x", 200)
+	for i := 0; i < n; i++ {
+		log.Infof(context.Background(), "line %06d %s", i, padding)
+	}
+}
+
+func TestRotatingFile_RotatesPastMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "adapter.log")
+	file, err := OpenRotatingFile(FileConfig{Path: path, MaxSizeMB: 1})
+	require.NoError(t, err)
+	log, console := newFileLogger(t, OutputFile, file)
+
+	const lines = 10000 // about 3 MB
+	writeLines(log, lines)
+	require.NoError(t, file.Close())
+
+	backups := backupPaths(t, file)
+	require.GreaterOrEqual(t, len(backups), 2, "writing 3 MB with a 1 MB limit rotates at least twice")
+
+	total := 0
+	for _, backup := range append(backups, path) {
+		info, err := os.Stat(backup)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(1024*1024), "%s is within the size limit", backup)
+		total += len(readJSONLines(t, backup))
+	}
+	assert.Equal(t, lines, total, "no line is lost or split across files")
+	assert.Empty(t, console.String(), "file output does not write to the console")
+}
+
+func TestRotatingFile_CompressAndMaxBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "adapter.log")
+	file, err := OpenRotatingFile(FileConfig{Path: path, MaxSizeMB: 1, MaxBackups: 2, Compress: true})
+	require.NoError(t, err)
+	log, _ := newFileLogger(t, OutputFile, file)
+
+	writeLines(log, 15000) // about 4.5 MB, 4 rotations
+	require.NoError(t, file.Close())
+
+	backups := backupPaths(t, file)
+	require.Len(t, backups, 2)
+	for _, backup := range backups {
+		assert.True(t, strings.HasSuffix(backup, ".log"+compressSuffix), backup)
+		assert.NotEmpty(t, readJSONLines(t, backup))
+	}
+	// The newest backup holds the lines right before the active file
+	active := readJSONLines(t, path)
+	newest := readJSONLines(t, backups[0])
+	assert.Less(t, newest[len(newest)-1], active[0])
+}
+
+func TestRotatingFile_MaxAge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "adapter.log")
+	old := filepath.Join(dir, "adapter-"+time.Now().Add(-72*time.Hour).UTC().Format(backupTimeFormat)+".log")
+	require.NoError(t, os.WriteFile(old, []byte("{}\n"), 0o600))
+
+	file, err := OpenRotatingFile(FileConfig{Path: path, MaxAgeDays: 1})
+	require.NoError(t, err)
+	_, err = file.Write([]byte("{}\n"))
+	require.NoError(t, err)
+	require.NoError(t, file.Rotate())
+	require.NoError(t, file.Close())
+
+	_, err = os.Stat(old)
+	assert.True(t, os.IsNotExist(err), "backups older than max_age_days are removed")
+	assert.Len(t, backupPaths(t, file), 1)
+}
+
+func TestRotatingFile_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "adapter.log")
+	file, err := OpenRotatingFile(FileConfig{Path: path})
+	require.NoError(t, err)
+	defer file.Close() //nolint:errcheck // test cleanup
+	log, _ := newFileLogger(t, OutputFile, file)
+
+	log.Info(context.Background(), "before logrotate")
+	// logrotate renames the file, then signals the adapter
+	rotated := filepath.Join(dir, "adapter.log.1")
+	require.NoError(t, os.Rename(path, rotated))
+	require.NoError(t, file.Reopen())
+	log.Info(context.Background(), "after logrotate")
+
+	assert.Equal(t, []string{"before logrotate"}, readJSONLines(t, rotated))
+	assert.Equal(t, []string{"after logrotate"}, readJSONLines(t, path))
+}
+
+func TestFileOutput_FallsBackToConsole(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "adapter.log")
+	file, err := OpenRotatingFile(FileConfig{Path: path})
+	require.NoError(t, err)
+	defer file.Close() //nolint:errcheck // test cleanup
+	log, console := newFileLogger(t, OutputFile, file)
+
+	// Break the file behind the RotatingFile's back
+	require.NoError(t, file.file.Close())
+	log.Info(context.Background(), "first while failing")
+	log.Info(context.Background(), "second while failing")
+
+	out := console.String()
+	assert.Contains(t, out, "first while failing")
+	assert.Contains(t, out, "second while failing")
+	assert.Equal(t, 1, strings.Count(out, "Failed to write log file"), "the warning is logged once")
+
+	require.NoError(t, file.Reopen())
+	console.Reset()
+	log.Info(context.Background(), "after recovery")
+	assert.Contains(t, console.String(), "Log file writes recovered")
+	assert.NotContains(t, console.String(), "after recovery")
+	assert.Equal(t, []string{"after recovery"}, readJSONLines(t, path))
+}
+
+func TestFileOutput_Both(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "adapter.log")
+	file, err := OpenRotatingFile(FileConfig{Path: path})
+	require.NoError(t, err)
+	defer file.Close() //nolint:errcheck // test cleanup
+	log, console := newFileLogger(t, OutputBoth, file)
+
+	log.Info(context.Background(), "to both")
+	assert.Contains(t, console.String(), "to both")
+	assert.Equal(t, []string{"to both"}, readJSONLines(t, path))
+}
+
+func TestFileOutput_RequiresFile(t *testing.T) {
+	_, err := NewLogger(Config{Output: OutputFile})
+	assert.Error(t, err)
+
+	_, err = OpenRotatingFile(FileConfig{})
+	assert.Error(t, err)
+	_, err = OpenRotatingFile(FileConfig{Path: filepath.Join(t.TempDir(), "a.log"), MaxBackups: -1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// normalizeJSONLine decodes one JSON log line and replaces the values that
+// change between runs, after checking their shape
+func normalizeJSONLine(t *testing.T, line string) map[string]interface{} {
+	t.Helper()
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(line), &entry), "every line is a JSON object: %s", line)
+
+	ts, ok := entry[TimestampKey].(string)
+	require.True(t, ok, "ts is a string")
+	_, err := time.Parse(time.RFC3339Nano, ts)
+	require.NoError(t, err, "ts is RFC 3339")
+	entry[TimestampKey] = "<ts>"
+
+	require.NotEmpty(t, entry[HostnameKey])
+	entry[HostnameKey] = "<hostname>"
+
+	if errorObject, ok := entry[ErrorKey].(map[string]interface{}); ok {
+		if stack, ok := errorObject[ErrorStackKey].([]interface{}); ok {
+			require.NotEmpty(t, stack)
+			errorObject[ErrorStackKey] = []string{"<frames>"}
+		}
+	}
+	return entry
+}
+
+func TestJSONFormat_Golden(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(log Logger)
+	}{
+		{
+			name: "fields",
+			log: func(log Logger) {
+				ctx := WithEventID(context.Background(), "evt-123")
+				ctx = WithResourceType(ctx, "cluster")
+				log.With(SubscriptionKey, "cluster-events").
+					WithFields(map[string]interface{}{ObservedGenerationKey: 3, "dropped": true}).
+					Without("dropped").
+					Infof(ctx, "Processed event %s", "evt-123")
+			},
+		},
+		{
+			name: "error_with_stack",
+			log: func(log Logger) {
+				ctx := WithErrorField(WithEventID(context.Background(), "evt-123"), errors.New("template failed"))
+				log.Error(ctx, "Event processing failed")
+			},
+		},
+		{
+			name: "error_without_stack",
+			log: func(log Logger) {
+				ctx := WithErrorField(context.Background(), context.Canceled)
+				log.Warn(ctx, "Shutting down")
+			},
+		},
+		{
+			name: "levels",
+			log: func(log Logger) {
+				ctx := context.Background()
+				log.Debug(ctx, "debug message")
+				log.Info(ctx, "info message")
+				log.Warn(ctx, "warn message")
+				log.Error(ctx, "error message")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			log, err := NewLogger(Config{
+				Level: "debug", Format: "json", Writer: buf, Component: "adapter", Version: "v1.2.3",
+			})
+			require.NoError(t, err)
+			tt.log(log)
+
+			var entries []map[string]interface{}
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				entries = append(entries, normalizeJSONLine(t, line))
+			}
+			var out bytes.Buffer
+			encoder := json.NewEncoder(&out)
+			encoder.SetEscapeHTML(false)
+			encoder.SetIndent("", "  ")
+			require.NoError(t, encoder.Encode(entries))
+			got := out.Bytes()
+
+			golden := filepath.Join("testdata", "json", tt.name+".golden")
+			if *updateGolden {
+				require.NoError(t, os.MkdirAll(filepath.Dir(golden), 0o755))
+				require.NoError(t, os.WriteFile(golden, got, 0o600))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err, "run go test ./pkg/logger -run TestJSONFormat_Golden -update to create it")
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func TestTextFormat_ErrorFieldsStayFlat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log, err := NewLogger(Config{Level: "info", Format: "text", Writer: buf, Component: "adapter", Version: "v1"})
+	require.NoError(t, err)
+
+	log.Error(WithErrorField(context.Background(), errors.New("boom")), "failed")
+	assert.Contains(t, buf.String(), "error=boom")
+	assert.Contains(t, buf.String(), "stack_trace=")
+	assert.Contains(t, buf.String(), "level=ERROR")
+}
@@ -0,0 +1,235 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// levelNames lists the log levels in the order Cycle steps through them
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+// LevelController holds the level of the loggers created with it, so it can be
+// changed at runtime, e.g. to debug for the duration of an incident. Loggers
+// read the level atomically on every call; all methods are safe for concurrent
+// use with logging.
+type LevelController struct {
+	// revertAt is when the pending revert to revertTo happens, zero if none
+	revertAt time.Time
+	revert   *time.Timer
+	level    slog.LevelVar
+	onChange []func(level string)
+	revertTo slog.Level
+	// generation invalidates a revert timer that fired after a newer change
+	generation uint64
+	mu         sync.Mutex
+}
+
+// LevelResponse is the JSON body returned by the log level admin endpoint
+type LevelResponse struct {
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+	Level    string     `json:"level"`
+	// RevertTo is the level restored at RevertAt
+	RevertTo string `json:"revert_to,omitempty"`
+}
+
+// LevelRequest is the JSON body accepted by the log level admin endpoint
+type LevelRequest struct {
+	Level string `json:"level"`
+	// RevertAfter is an optional duration (e.g. "10m") after which the
+	// previous level is restored
+	RevertAfter string `json:"revert_after,omitempty"`
+}
+
+// NewLevelController creates a controller starting at level. Unknown levels
+// fall back to info, like Config.Level.
+func NewLevelController(level string) *LevelController {
+	c := &LevelController{}
+	c.level.Set(parseLevel(level))
+	return c
+}
+
+// Level returns the current level name
+func (c *LevelController) Level() string {
+	return levelName(c.level.Level())
+}
+
+// OnChange registers fn to be called with the new level name after every
+// change, including automatic reverts, and right away with the current level.
+// Calls are serialized in change order; fn must not change the level.
+func (c *LevelController) OnChange(fn func(level string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onChange = append(c.onChange, fn)
+	fn(c.Level())
+}
+
+// SetLevel changes the level. With a positive revertAfter, the level in force
+// before the change is restored once it elapses; a later change cancels the
+// pending revert, but a later temporary change still reverts to the original
+// level rather than to the temporary one. Returns an error for unknown levels.
+func (c *LevelController) SetLevel(level string, revertAfter time.Duration) error {
+	parsed, err := parseLevelStrict(level)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	pendingRevert := c.revert != nil
+	c.cancelRevertLocked()
+	if revertAfter > 0 {
+		if !pendingRevert {
+			c.revertTo = c.level.Level()
+		}
+		generation := c.generation
+		c.revertAt = time.Now().Add(revertAfter)
+		c.revert = time.AfterFunc(revertAfter, func() { c.expire(generation) })
+	}
+	c.level.Set(parsed)
+	c.notifyLocked()
+	c.mu.Unlock()
+	return nil
+}
+
+// Cycle moves to the next level, wrapping from error back to debug,
+// and returns it. Any pending revert is cancelled.
+func (c *LevelController) Cycle() string {
+	current := c.Level()
+	next := levelNames[0]
+	for i, name := range levelNames {
+		if name == current {
+			next = levelNames[(i+1)%len(levelNames)]
+			break
+		}
+	}
+	//nolint:errcheck // next is always a valid level
+	_ = c.SetLevel(next, 0)
+	return next
+}
+
+// Reload handles SIGHUP: it sets the level read from file, or cycles to the
+// next level when file is empty. Returns the new level.
+func (c *LevelController) Reload(file string) (string, error) {
+	if file == "" {
+		return c.Cycle(), nil
+	}
+	data, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return "", fmt.Errorf("failed to read log level file: %w", err)
+	}
+	level := strings.TrimSpace(string(data))
+	if err := c.SetLevel(level, 0); err != nil {
+		return "", err
+	}
+	return c.Level(), nil
+}
+
+// Handler serves the log level admin endpoint: GET returns the current level,
+// PUT with a LevelRequest body changes it.
+func (c *LevelController) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var body LevelRequest
+			if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 1024)).Decode(&body); err != nil {
+				writeLevelError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+				return
+			}
+			var revertAfter time.Duration
+			if body.RevertAfter != "" {
+				var err error
+				if revertAfter, err = time.ParseDuration(body.RevertAfter); err != nil || revertAfter <= 0 {
+					writeLevelError(w, http.StatusBadRequest,
+						fmt.Sprintf("invalid revert_after %q: must be a positive duration", body.RevertAfter))
+					return
+				}
+			}
+			if err := c.SetLevel(body.Level, revertAfter); err != nil {
+				writeLevelError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeLevelError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(c.response()) //nolint:errcheck
+	}
+}
+
+// leveler returns the level read by slog handlers on every record
+func (c *LevelController) leveler() slog.Leveler {
+	return &c.level
+}
+
+func (c *LevelController) response() LevelResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	response := LevelResponse{Level: c.Level()}
+	if c.revert != nil {
+		revertAt := c.revertAt
+		response.RevertAt = &revertAt
+		response.RevertTo = levelName(c.revertTo)
+	}
+	return response
+}
+
+// expire restores the level saved by a temporary SetLevel, unless a newer
+// change happened since the timer was armed.
+func (c *LevelController) expire(generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	c.cancelRevertLocked()
+	c.level.Set(c.revertTo)
+	c.notifyLocked()
+}
+
+// cancelRevertLocked drops the pending revert. c.mu must be held.
+func (c *LevelController) cancelRevertLocked() {
+	if c.revert != nil {
+		c.revert.Stop()
+	}
+	c.revert = nil
+	c.revertAt = time.Time{}
+	c.generation++
+}
+
+// notifyLocked calls the OnChange callbacks. c.mu must be held.
+func (c *LevelController) notifyLocked() {
+	level := c.Level()
+	for _, fn := range c.onChange {
+		fn(level)
+	}
+}
+
+func writeLevelError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message}) //nolint:errcheck
+}
+
+// parseLevelStrict converts a level name to slog.Level, rejecting unknown names
+func parseLevelStrict(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug", "info", "warn", "warning", "error":
+		return parseLevel(level), nil
+	default:
+		return 0, fmt.Errorf("invalid log level %q: must be one of %s", level, strings.Join(levelNames, ", "))
+	}
+}
+
+// levelName returns the lowercase name of level as used in Config.Level
+func levelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
@@ -0,0 +1,187 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLevelLogger(t *testing.T, levels *LevelController) (Logger, *bytes.Buffer) {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	log, err := NewLogger(Config{Format: "text", Writer: buf, Component: "test", Version: "test", Levels: levels})
+	require.NoError(t, err)
+	return log, buf
+}
+
+func TestLevelController_SetLevel(t *testing.T) {
+	levels := NewLevelController("info")
+	log, buf := newLevelLogger(t, levels)
+	ctx := context.Background()
+
+	log.Debug(ctx, "hidden")
+	require.NoError(t, levels.SetLevel("debug", 0))
+	log.Debug(ctx, "visible")
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.Contains(t, buf.String(), "visible")
+
+	// Loggers derived with With share the level
+	buf.Reset()
+	child := log.With("key", "value")
+	require.NoError(t, levels.SetLevel("error", 0))
+	child.Warn(ctx, "dropped")
+	child.Error(ctx, "kept")
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), "kept")
+
+	assert.Error(t, levels.SetLevel("verbose", 0))
+	assert.Equal(t, "error", levels.Level(), "an invalid level leaves the level unchanged")
+}
+
+func TestLevelController_RevertAfter(t *testing.T) {
+	levels := NewLevelController("info")
+	changes := make(chan string, 10)
+	levels.OnChange(func(level string) { changes <- level })
+	assert.Equal(t, "info", <-changes)
+
+	require.NoError(t, levels.SetLevel("debug", 50*time.Millisecond))
+	assert.Equal(t, "debug", <-changes)
+	// A second temporary change still reverts to the original level
+	require.NoError(t, levels.SetLevel("warn", 50*time.Millisecond))
+	assert.Equal(t, "warn", <-changes)
+
+	select {
+	case level := <-changes:
+		assert.Equal(t, "info", level)
+	case <-time.After(5 * time.Second):
+		t.Fatal("level was not reverted")
+	}
+	assert.Equal(t, "info", levels.Level())
+}
+
+func TestLevelController_PermanentChangeCancelsRevert(t *testing.T) {
+	levels := NewLevelController("info")
+	require.NoError(t, levels.SetLevel("debug", 20*time.Millisecond))
+	require.NoError(t, levels.SetLevel("warn", 0))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, "warn", levels.Level())
+}
+
+func TestLevelController_Cycle(t *testing.T) {
+	levels := NewLevelController("warn")
+	assert.Equal(t, "error", levels.Cycle())
+	assert.Equal(t, "debug", levels.Cycle())
+	assert.Equal(t, "info", levels.Cycle())
+}
+
+func TestLevelController_Reload(t *testing.T) {
+	levels := NewLevelController("info")
+
+	level, err := levels.Reload("")
+	require.NoError(t, err)
+	assert.Equal(t, "warn", level, "without a file SIGHUP cycles")
+
+	file := filepath.Join(t.TempDir(), "level")
+	require.NoError(t, os.WriteFile(file, []byte("debug\n"), 0o600))
+	level, err = levels.Reload(file)
+	require.NoError(t, err)
+	assert.Equal(t, "debug", level)
+
+	require.NoError(t, os.WriteFile(file, []byte("loud"), 0o600))
+	_, err = levels.Reload(file)
+	assert.Error(t, err)
+	assert.Equal(t, "debug", levels.Level())
+}
+
+func TestLevelController_Handler(t *testing.T) {
+	levels := NewLevelController("info")
+	handler := levels.Handler()
+
+	serve := func(method, body string) (*httptest.ResponseRecorder, LevelResponse) {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(method, "/admin/loglevel", strings.NewReader(body)))
+		var response LevelResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		}
+		return w, response
+	}
+
+	w, response := serve(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "info", response.Level)
+	assert.Nil(t, response.RevertAt)
+
+	w, response = serve(http.MethodPut, `{"level":"debug","revert_after":"10m"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "debug", response.Level)
+	assert.Equal(t, "info", response.RevertTo)
+	require.NotNil(t, response.RevertAt)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), *response.RevertAt, time.Minute)
+
+	w, response = serve(http.MethodPut, `{"level":"error"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "error", response.Level)
+	assert.Nil(t, response.RevertAt, "a permanent change cancels the revert")
+
+	for _, tt := range []struct {
+		name, method, body string
+		wantCode           int
+	}{
+		{name: "invalid level", method: http.MethodPut, body: `{"level":"loud"}`, wantCode: http.StatusBadRequest},
+		{name: "invalid json", method: http.MethodPut, body: `{`, wantCode: http.StatusBadRequest},
+		{
+			name: "invalid revert_after", method: http.MethodPut,
+			body: `{"level":"debug","revert_after":"-1m"}`, wantCode: http.StatusBadRequest,
+		},
+		{name: "unsupported method", method: http.MethodPost, body: `{}`, wantCode: http.StatusMethodNotAllowed},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			w, _ := serve(tt.method, tt.body)
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+	assert.Equal(t, "error", levels.Level())
+}
+
+// TestLevelController_ConcurrentLogging changes the level while loggers log;
+// run with -race.
+func TestLevelController_ConcurrentLogging(t *testing.T) {
+	levels := NewLevelController("info")
+	log, _ := newLevelLogger(t, levels)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			child := log.With("worker", i)
+			for j := 0; j < 200; j++ {
+				child.Debugf(ctx, "message %d", j)
+				child.Info(ctx, "message")
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 100; j++ {
+			levels.Cycle()
+			_ = levels.SetLevel("debug", time.Millisecond) //nolint:errcheck // valid level
+			levels.Handler()(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/admin/loglevel", nil))
+		}
+	}()
+	wg.Wait()
+}
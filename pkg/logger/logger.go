@@ -48,6 +48,9 @@ type logger struct {
 	component string
 	version   string
 	hostname  string
+	// structuredErrors nests the error and stack trace fields under a single
+	// "error" object, set in JSON format
+	structuredErrors bool
 }
 
 // Config holds logger configuration
@@ -56,16 +59,25 @@ type Config struct {
 	Level string
 	// Format is the output format: "text" or "json"
 	Format string
-	// Output is the output destination: "stdout", "stderr", or empty (defaults to stdout)
-	// Ignored if Writer is set.
+	// Output is the output destination: "stdout", "stderr", "file", "both"
+	// (stdout and file), or empty (defaults to stdout)
 	Output string
-	// Writer is an optional custom io.Writer for log output.
-	// If set, Output is ignored. Useful for testing (e.g., bytes.Buffer).
+	// Writer is an optional custom io.Writer replacing stdout or stderr.
+	// Useful for testing (e.g., bytes.Buffer).
 	Writer io.Writer
+	// File receives log lines when Output is "file" or "both". When writing
+	// to it fails, lines go to the console instead.
+	File *RotatingFile
 	// Component is the component name (e.g., "adapter", "sentinel")
 	Component string
 	// Version is the component version
 	Version string
+	// Levels, when set, controls the level at runtime and Level is ignored.
+	// Loggers sharing a LevelController change level together.
+	Levels *LevelController
+	// Redactor replaces secrets in messages and field values before output.
+	// Defaults to DefaultRedactor, where RegisterSecret adds secrets.
+	Redactor *Redactor
 }
 
 // DefaultConfig returns a configuration with sensible defaults
@@ -101,23 +113,27 @@ func ConfigFromEnv() Config {
 func NewLogger(cfg Config) (Logger, error) {
 	// Determine output writer
 	var writer io.Writer
+	switch cfg.Output {
+	case "stdout", "", OutputFile, OutputBoth:
+		writer = os.Stdout
+	case "stderr":
+		writer = os.Stderr
+	default:
+		if cfg.Writer == nil {
+			return nil, fmt.Errorf(
+				"invalid log output %q: must be 'stdout', 'stderr', 'file', 'both', or empty", cfg.Output)
+		}
+	}
 	if cfg.Writer != nil {
 		// Use custom writer (e.g., for testing with bytes.Buffer)
 		writer = cfg.Writer
-	} else {
-		// Use Output string config
-		switch cfg.Output {
-		case "stdout", "":
-			writer = os.Stdout
-		case "stderr":
-			writer = os.Stderr
-		default:
-			return nil, fmt.Errorf("invalid log output %q: must be 'stdout', 'stderr', or empty", cfg.Output)
-		}
 	}
 
 	// Parse log level
-	level := parseLevel(cfg.Level)
+	var level slog.Leveler = parseLevel(cfg.Level)
+	if cfg.Levels != nil {
+		level = cfg.Levels.leveler()
+	}
 
 	// Create handler options
 	opts := &slog.HandlerOptions{
@@ -127,12 +143,29 @@ func NewLogger(cfg Config) (Logger, error) {
 	}
 
 	// Create handler based on format
-	var handler slog.Handler
-	if cfg.Format == "json" {
-		handler = slog.NewJSONHandler(writer, opts)
-	} else {
-		handler = slog.NewTextHandler(writer, opts)
+	jsonFormat := cfg.Format == "json"
+	if jsonFormat {
+		opts.ReplaceAttr = replaceJSONAttr
+	}
+	newHandler := func(w io.Writer) slog.Handler {
+		if jsonFormat {
+			return slog.NewJSONHandler(w, opts)
+		}
+		return slog.NewTextHandler(w, opts)
 	}
+	if cfg.Output == OutputFile || cfg.Output == OutputBoth {
+		sink, err := newFileSink(cfg, writer, newHandler)
+		if err != nil {
+			return nil, err
+		}
+		writer = sink
+	}
+	var handler slog.Handler = newHandler(writer)
+	redactor := cfg.Redactor
+	if redactor == nil {
+		redactor = defaultRedactor
+	}
+	handler = &redactingHandler{next: handler, redactor: redactor}
 
 	// Get hostname
 	hostname, _ := os.Hostname() //nolint:errcheck // fallback to alternatives below
@@ -151,14 +184,42 @@ func NewLogger(cfg Config) (Logger, error) {
 	)
 
 	return &logger{
-		slog:      slogLogger,
-		fields:    make(map[string]interface{}),
-		component: cfg.Component,
-		version:   cfg.Version,
-		hostname:  hostname,
+		slog:             slogLogger,
+		fields:           make(map[string]interface{}),
+		component:        cfg.Component,
+		version:          cfg.Version,
+		hostname:         hostname,
+		structuredErrors: jsonFormat,
 	}, nil
 }
 
+// JSON format keys that differ from the slog defaults
+const (
+	// TimestampKey is the timestamp key of JSON log lines
+	TimestampKey = "ts"
+	// ErrorMessageKey is the error message key inside the JSON "error" object
+	ErrorMessageKey = "message"
+	// ErrorStackKey is the stack trace key inside the JSON "error" object
+	ErrorStackKey = "stack"
+)
+
+// replaceJSONAttr renames the slog time key to "ts" and lowercases the level,
+// matching the log pipeline's parser.
+func replaceJSONAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.TimeKey:
+		a.Key = TimestampKey
+	case slog.LevelKey:
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			a.Value = slog.StringValue(levelName(level))
+		}
+	}
+	return a
+}
+
 // parseLevel converts string level to slog.Level
 func parseLevel(level string) slog.Level {
 	switch strings.ToLower(level) {
@@ -176,21 +237,47 @@ func parseLevel(level string) slog.Level {
 // buildArgs builds the slog args from fields and context
 func (l *logger) buildArgs(ctx context.Context) []any {
 	args := make([]any, 0, len(l.fields)*2+10)
+	var errorMessage, stackTrace interface{}
+
+	add := func(k string, v interface{}) {
+		if l.structuredErrors {
+			switch k {
+			case ErrorKey:
+				errorMessage = v
+				return
+			case StackTraceKey:
+				stackTrace = v
+				return
+			}
+		}
+		args = append(args, k, v)
+	}
 
 	// Add fields from the logger
 	for k, v := range l.fields {
-		args = append(args, k, v)
+		add(k, v)
 	}
 
 	// Extract all log fields from context (flat structure)
 	if ctx != nil {
 		if logFields, ok := ctx.Value(LogFieldsKey).(LogFields); ok {
 			for k, v := range logFields {
-				args = append(args, k, v)
+				add(k, v)
 			}
 		}
 	}
 
+	if errorMessage != nil || stackTrace != nil {
+		errorAttrs := make([]any, 0, 2)
+		if errorMessage != nil {
+			errorAttrs = append(errorAttrs, slog.Any(ErrorMessageKey, errorMessage))
+		}
+		if stackTrace != nil {
+			errorAttrs = append(errorAttrs, slog.Any(ErrorStackKey, stackTrace))
+		}
+		args = append(args, slog.Group(ErrorKey, errorAttrs...))
+	}
+
 	return args
 }
 
@@ -204,14 +291,18 @@ func (l *logger) Debugf(ctx context.Context, format string, args ...interface{})
 	l.slog.DebugContext(ctx, fmt.Sprintf(format, args...), l.buildArgs(ctx)...)
 }
 
-// Info logs at info level
+// Info logs at info level, or at debug level in a WithInfoAsDebug context
 func (l *logger) Info(ctx context.Context, message string) {
+	if infoAsDebug(ctx) {
+		l.slog.DebugContext(ctx, message, l.buildArgs(ctx)...)
+		return
+	}
 	l.slog.InfoContext(ctx, message, l.buildArgs(ctx)...)
 }
 
-// Infof logs at info level with formatting
+// Infof logs at info level with formatting, or at debug level in a WithInfoAsDebug context
 func (l *logger) Infof(ctx context.Context, format string, args ...interface{}) {
-	l.slog.InfoContext(ctx, fmt.Sprintf(format, args...), l.buildArgs(ctx)...)
+	l.Info(ctx, fmt.Sprintf(format, args...))
 }
 
 // Warn logs at warn level
@@ -257,11 +348,12 @@ func (l *logger) With(key string, value interface{}) Logger {
 	newFields := copyFields(l.fields)
 	newFields[key] = value
 	return &logger{
-		slog:      l.slog,
-		fields:    newFields,
-		component: l.component,
-		version:   l.version,
-		hostname:  l.hostname,
+		slog:             l.slog,
+		fields:           newFields,
+		component:        l.component,
+		version:          l.version,
+		hostname:         l.hostname,
+		structuredErrors: l.structuredErrors,
 	}
 }
 
@@ -272,11 +364,12 @@ func (l *logger) WithFields(fields map[string]interface{}) Logger {
 		newFields[k] = v
 	}
 	return &logger{
-		slog:      l.slog,
-		fields:    newFields,
-		component: l.component,
-		version:   l.version,
-		hostname:  l.hostname,
+		slog:             l.slog,
+		fields:           newFields,
+		component:        l.component,
+		version:          l.version,
+		hostname:         l.hostname,
+		structuredErrors: l.structuredErrors,
 	}
 }
 
@@ -286,10 +379,11 @@ func (l *logger) Without(key string) Logger {
 	newFields := copyFields(l.fields)
 	delete(newFields, key)
 	return &logger{
-		slog:      l.slog,
-		fields:    newFields,
-		component: l.component,
-		version:   l.version,
-		hostname:  l.hostname,
+		slog:             l.slog,
+		fields:           newFields,
+		component:        l.component,
+		version:          l.version,
+		hostname:         l.hostname,
+		structuredErrors: l.structuredErrors,
 	}
 }
@@ -1,7 +1,9 @@
 package logger
 
 import (
+	"bytes"
 	"context"
+	"strings"
 	"testing"
 )
 
@@ -347,6 +349,30 @@ func TestContextHelpers(t *testing.T) {
 	})
 }
 
+func TestWithInfoAsDebug(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewLogger(Config{Level: "debug", Format: "text", Writer: &buf, Component: "test", Version: "test"})
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+	quiet := WithInfoAsDebug(context.Background())
+
+	log.Infof(quiet, "quiet %d", 1)
+	log.Info(context.Background(), "loud")
+	log.Warn(quiet, "warning")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{"level=DEBUG msg=\"quiet 1\"", "level=INFO msg=loud", "level=WARN msg=warning"}
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %d lines, got %q", len(want), lines)
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, want[i]) {
+			t.Errorf("Expected line %d to contain %q, got %q", i, want[i], line)
+		}
+	}
+}
+
 func TestConfigFromEnv(t *testing.T) {
 	t.Run("defaults_without_env_vars", func(t *testing.T) {
 		cfg := ConfigFromEnv()
@@ -0,0 +1,250 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// RedactedPlaceholder replaces secret values and pattern matches in log output
+const RedactedPlaceholder = "[REDACTED]"
+
+// MinSecretLength is the length below which registered secret values are
+// ignored: replacing very short strings everywhere would garble logs without
+// protecting anything.
+const MinSecretLength = 4
+
+// DefaultRedactionPatterns are the patterns every Redactor created with
+// NewRedactor starts with
+var DefaultRedactionPatterns = []string{
+	`Bearer [A-Za-z0-9\-_\.~+/]+=*`,
+}
+
+// Redactor replaces secret values and regex matches in log messages and field
+// values. Secrets are registered at runtime, e.g. by a client once it has
+// read its token. Redact is lock-free; registration rebuilds the matcher, so
+// it is meant for a handful of values registered at startup or on rotation.
+type Redactor struct {
+	secrets map[string]struct{}
+	state   atomic.Pointer[redactorState]
+	mu      sync.Mutex
+}
+
+// redactorState is the immutable matcher swapped on every registration
+type redactorState struct {
+	replacer *strings.Replacer
+	patterns []redactionPattern
+}
+
+type redactionPattern struct {
+	re *regexp.Regexp
+	// prefix is the literal prefix of every match, checked with strings.Contains
+	// before running the regex
+	prefix string
+}
+
+// NewRedactor creates a Redactor with DefaultRedactionPatterns
+func NewRedactor() *Redactor {
+	r := &Redactor{secrets: make(map[string]struct{})}
+	state := &redactorState{}
+	for _, pattern := range DefaultRedactionPatterns {
+		state.patterns = append(state.patterns, newRedactionPattern(regexp.MustCompile(pattern)))
+	}
+	r.state.Store(state)
+	return r
+}
+
+func newRedactionPattern(re *regexp.Regexp) redactionPattern {
+	prefix, _ := re.LiteralPrefix()
+	return redactionPattern{re: re, prefix: prefix}
+}
+
+// AddSecrets registers values that must never appear in log output. Empty
+// values, values shorter than MinSecretLength and known values are ignored.
+func (r *Redactor) AddSecrets(values ...string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	added := false
+	for _, value := range values {
+		if len(value) < MinSecretLength {
+			continue
+		}
+		if _, ok := r.secrets[value]; ok {
+			continue
+		}
+		r.secrets[value] = struct{}{}
+		added = true
+	}
+	if !added {
+		return
+	}
+
+	// Longer secrets first, so a secret containing another one is replaced whole
+	secrets := make([]string, 0, len(r.secrets))
+	for secret := range r.secrets {
+		secrets = append(secrets, secret)
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		if len(secrets[i]) != len(secrets[j]) {
+			return len(secrets[i]) > len(secrets[j])
+		}
+		return secrets[i] < secrets[j]
+	})
+	pairs := make([]string, 0, len(secrets)*2)
+	for _, secret := range secrets {
+		pairs = append(pairs, secret, RedactedPlaceholder)
+	}
+	current := r.state.Load()
+	r.state.Store(&redactorState{replacer: strings.NewReplacer(pairs...), patterns: current.patterns})
+}
+
+// AddPattern registers a regular expression whose matches are redacted.
+// Returns an error if pattern does not compile.
+func (r *Redactor) AddPattern(pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+	}
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current := r.state.Load()
+	patterns := append(append([]redactionPattern(nil), current.patterns...), newRedactionPattern(re))
+	r.state.Store(&redactorState{replacer: current.replacer, patterns: patterns})
+	return nil
+}
+
+// Redact returns s with registered secrets and pattern matches replaced by
+// RedactedPlaceholder
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	state := r.state.Load()
+	if state.replacer != nil {
+		s = state.replacer.Replace(s)
+	}
+	for _, pattern := range state.patterns {
+		if pattern.prefix != "" && !strings.Contains(s, pattern.prefix) {
+			continue
+		}
+		s = pattern.re.ReplaceAllLiteralString(s, RedactedPlaceholder)
+	}
+	return s
+}
+
+// empty reports whether Redact can only return its input unchanged
+func (r *Redactor) empty() bool {
+	if r == nil {
+		return true
+	}
+	state := r.state.Load()
+	return state.replacer == nil && len(state.patterns) == 0
+}
+
+// redactValue redacts string values, including the strings of errors, string
+// slices and groups. Values of other types are redacted through their string
+// form, and only replaced when it contained a secret.
+func (r *Redactor) redactValue(v slog.Value) slog.Value {
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.StringValue(r.Redact(v.String()))
+	case slog.KindGroup:
+		return slog.GroupValue(r.redactAttrs(v.Group())...)
+	case slog.KindLogValuer:
+		return r.redactValue(v.Resolve())
+	case slog.KindAny:
+		switch value := v.Any().(type) {
+		case []string:
+			redacted := make([]string, len(value))
+			for i, s := range value {
+				redacted[i] = r.Redact(s)
+			}
+			return slog.AnyValue(redacted)
+		case error:
+			return r.redactIfChanged(v, value.Error())
+		default:
+			return r.redactIfChanged(v, fmt.Sprint(value))
+		}
+	default:
+		return v
+	}
+}
+
+func (r *Redactor) redactIfChanged(v slog.Value, s string) slog.Value {
+	if redacted := r.Redact(s); redacted != s {
+		return slog.StringValue(redacted)
+	}
+	return v
+}
+
+func (r *Redactor) redactAttrs(attrs []slog.Attr) []slog.Attr {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = slog.Attr{Key: attr.Key, Value: r.redactValue(attr.Value)}
+	}
+	return redacted
+}
+
+// redactingHandler redacts the message and every attribute of a record
+// before passing it to the next handler
+type redactingHandler struct {
+	next     slog.Handler
+	redactor *Redactor
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if h.redactor.empty() {
+		return h.next.Handle(ctx, record)
+	}
+	redacted := slog.NewRecord(record.Time, record.Level, h.redactor.Redact(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(slog.Attr{Key: attr.Key, Value: h.redactor.redactValue(attr.Value)})
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs redacts attrs with the secrets known now; attributes added with
+// the slog logger are only the static component, version and hostname
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &redactingHandler{next: h.next.WithAttrs(h.redactor.redactAttrs(attrs)), redactor: h.redactor}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{next: h.next.WithGroup(name), redactor: h.redactor}
+}
+
+// defaultRedactor is used by loggers created without Config.Redactor
+var defaultRedactor = NewRedactor()
+
+// DefaultRedactor returns the process-wide Redactor used by loggers created
+// without Config.Redactor
+func DefaultRedactor() *Redactor {
+	return defaultRedactor
+}
+
+// RegisterSecret registers values with the default Redactor, so they are
+// redacted from the output of every logger using it
+func RegisterSecret(values ...string) {
+	defaultRedactor.AddSecrets(values...)
+}
+
+// RegisterRedactionPattern registers a regular expression with the default Redactor
+func RegisterRedactionPattern(pattern string) error {
+	return defaultRedactor.AddPattern(pattern)
+}
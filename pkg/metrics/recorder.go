@@ -3,6 +3,7 @@
 package metrics
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -15,10 +16,54 @@ type Recorder struct {
 	eventsProcessed    *prometheus.CounterVec
 	processingDuration prometheus.Observer
 	errorsTotal        *prometheus.CounterVec
+	rateLimitWait      prometheus.Observer
+	rateLimitQueued    prometheus.Gauge
+	requeueDelay       *prometheus.HistogramVec
+	requeueHoldLimit   *prometheus.CounterVec
+	eventRedeliveries  *prometheus.CounterVec
+	handlerResults     *prometheus.CounterVec
+	handlerPanics      *prometheus.CounterVec
+	duplicateEvents    prometheus.Counter
+	filteredEvents     prometheus.Counter
+	decodeErrors       *prometheus.CounterVec
+	subscriptionUp     *prometheus.GaugeVec
+	startupDuration    prometheus.Gauge
+	e2eLatency         *prometheus.HistogramVec
+	eventProcessing    *prometheus.HistogramVec
+	eventsInFlight     *prometheus.GaugeVec
+	clockSkew          *prometheus.CounterVec
+	logLevel           *prometheus.GaugeVec
+	configInfo         *prometheus.GaugeVec
+	apiCalls           *prometheus.CounterVec
+	httpConnections    *prometheus.CounterVec
+	auditWriteFailures *prometheus.CounterVec
+	oversizedEvents    *prometheus.CounterVec
+	unchangedPosts     *prometheus.CounterVec
+	postBufferCalls    *prometheus.CounterVec
+	fenceWait          prometheus.Observer
+	stepDuration       *prometheus.HistogramVec
+	workflowRuns       *prometheus.CounterVec
+	precondNotMet      *prometheus.CounterVec
+	precondNotMetBy    *prometheus.CounterVec
+	precondAPIFailures *prometheus.CounterVec
+	contractViolations *prometheus.CounterVec
+	queueWait          prometheus.Observer
+	queueDepth         prometheus.Gauge
+	queueRejected      prometheus.Counter
+	policyViolations   *prometheus.CounterVec
 }
 
+// OtherEventType is the event_type label value of event types outside the
+// configured type list, which keeps the label cardinality bounded.
+const OtherEventType = "other"
+
+// logLevels are the level label values of the log_level gauge
+var logLevels = []string{"debug", "info", "warn", "error"}
+
 // NewRecorder creates a new Recorder and registers metrics with the given registerer.
-// If reg is nil, prometheus.DefaultRegisterer is used.
+// If reg is nil, prometheus.DefaultRegisterer is used. A Recorder created on a
+// registry that already has its metrics, e.g. from another Recorder of the same
+// component and version, records into them instead of panicking (see Register).
 func NewRecorder(component, version string, reg prometheus.Registerer) *Recorder {
 	if reg == nil {
 		reg = prometheus.DefaultRegisterer
@@ -57,17 +102,505 @@ func NewRecorder(component, version string, reg prometheus.Registerer) *Recorder
 				"version":   version,
 			},
 		},
-		[]string{"error_type"},
+		[]string{"error_type", "code"},
+	)
+
+	rateLimitWait := prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "hyperfleet_adapter_rate_limit_wait_duration_seconds",
+			Help:    "Time events spent waiting on the broker consumer rate limiter in seconds",
+			Buckets: []float64{0.001, 0.01, 0.1, 0.5, 1, 2, 5, 10, 30, 60},
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+	)
+
+	rateLimitQueued := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "hyperfleet_adapter_rate_limit_queue_depth",
+			Help: "Number of events currently waiting on the broker consumer rate limiter",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+	)
+
+	requeueDelay := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "hyperfleet_adapter_requeue_delay_seconds",
+			Help:    "Redelivery delays applied by the broker consumer to events requesting a retry after a delay",
+			Buckets: []float64{1, 5, 10, 30, 60, 120, 300, 600},
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"subscription", "capped"},
+	)
+
+	requeueHoldLimit := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_requeue_hold_limit_total",
+			Help: "Total number of requeued events redelivered without delay because their subscription held the maximum number of events",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"subscription"},
+	)
+
+	eventRedeliveries := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_event_redeliveries_total",
+			Help: "Total number of events NACKed for redelivery by the phase and error code of their failure",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"phase", "error_code"},
+	)
+
+	handlerResults := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_handler_results_total",
+			Help: "Total number of broker handler invocations by subscription and outcome",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"subscription", "outcome"},
+	)
+
+	handlerPanics := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_handler_panics_total",
+			Help: "Total number of panics recovered in the broker handler",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"subscription"},
+	)
+
+	duplicateEvents := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_duplicate_events_total",
+			Help: "Total number of redelivered events skipped by deduplication",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+	)
+
+	filteredEvents := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_filtered_events_total",
+			Help: "Total number of events acknowledged without executing because their data content type is not allowed",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+	)
+
+	decodeErrors := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_event_decode_errors_total",
+			Help: "Total number of broker messages that could not be decoded into a processable CloudEvent",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"reason"},
+	)
+
+	subscriptionUp := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "hyperfleet_adapter_subscription_up",
+			Help: "Whether the broker subscription is receiving (1) or failed (0)",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"subscription"},
+	)
+
+	startupDuration := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "hyperfleet_adapter_startup_duration_seconds",
+			Help: "Time the adapter took to start until it first became ready in seconds",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+	)
+
+	e2eLatency := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "hyperfleet_adapter_event_e2e_latency_seconds",
+			Help:    "Time from the CloudEvent time attribute to the end of its processing in seconds",
+			Buckets: []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60, 120, 300, 600},
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"status", "event_type"},
+	)
+
+	eventProcessing := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "hyperfleet_adapter_event_processing_seconds",
+			Help:    "Handler wall time of event processing in seconds",
+			Buckets: []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60, 120},
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"status", "event_type"},
+	)
+
+	eventsInFlight := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "hyperfleet_adapter_events_in_flight",
+			Help: "Number of events currently being processed",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"event_type"},
+	)
+
+	clockSkew := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_event_clock_skew_total",
+			Help: "Total number of events whose CloudEvent time is after their processing completed",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"event_type"},
+	)
+
+	logLevel := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "hyperfleet_adapter_log_level",
+			Help: "Current log level of the adapter (1 for the active level, 0 otherwise)",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"level"},
+	)
+
+	configInfo := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "hyperfleet_adapter_config_info",
+			Help: "Hash of the effective config the adapter runs with (always 1)",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"config_hash"},
+	)
+
+	apiCalls := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_api_calls_total",
+			Help: "Total number of HyperFleet API calls made by the executor",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"target", "outcome"},
+	)
+
+	httpConnections := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_http_connections_total",
+			Help: "Total number of HTTP requests of the API clients by whether they got a new or reused connection",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"client", "connection"},
+	)
+
+	auditWriteFailures := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_audit_write_failures_total",
+			Help: "Total number of execution audit records that could not be written to a sink",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"sink"},
+	)
+
+	oversizedEvents := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_oversized_events_total",
+			Help: "Total number of events acknowledged without handling because their data exceeded the size limit",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"subscription"},
+	)
+
+	unchangedPosts := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_post_actions_unchanged_total",
+			Help: "Total number of post action API calls skipped because the body was unchanged",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"action"},
+	)
+
+	postBufferCalls := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_post_buffer_calls_total",
+			Help: "Total number of post action API calls buffered by buffer_on_failure, by outcome",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"action", "outcome"},
+	)
+
+	fenceWait := prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "hyperfleet_adapter_execution_fence_wait_seconds",
+			Help:    "Time executions waited for another execution with the same execution_fence key in seconds",
+			Buckets: []float64{0.001, 0.01, 0.1, 0.5, 1, 2, 5, 10, 30, 60},
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+	)
+
+	stepDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "hyperfleet_adapter_step_duration_seconds",
+			Help:    "Duration of each precondition, resource and post action in seconds",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 30, 60},
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"phase", "step"},
+	)
+
+	workflowRuns := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_workflow_executions_total",
+			Help: "Total number of executions by selected workflow and status",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"workflow", "status"},
+	)
+
+	precondNotMet := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_preconditions_not_met_total",
+			Help: "Total number of precondition evaluations not met, by precondition",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"precondition"},
+	)
+
+	precondNotMetBy := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_preconditions_not_met_reasons_total",
+			Help: "Total number of precondition evaluations not met, by precondition and configured reason_label",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"precondition", "reason"},
 	)
 
-	reg.MustRegister(eventsProcessed)
-	reg.MustRegister(processingDuration)
-	reg.MustRegister(errorsTotal)
+	precondAPIFailures := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_preconditions_api_failures_total",
+			Help: "Total number of failed precondition API calls, by precondition",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"precondition"},
+	)
+
+	contractViolations := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_api_contract_violations_total",
+			Help: "Total number of precondition API responses violating an expect assertion, by precondition and path",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"precondition", "path"},
+	)
+
+	queueWait := prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "hyperfleet_adapter_queue_wait_duration_seconds",
+			Help:    "Time events spent in the broker consumer queue before a worker took them in seconds",
+			Buckets: []float64{0.001, 0.01, 0.1, 0.5, 1, 2, 5, 10, 30, 60},
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+	)
+
+	queueDepth := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "hyperfleet_adapter_queue_depth",
+			Help: "Number of events currently waiting in the broker consumer queue",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+	)
+
+	queueRejected := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_queue_rejected_total",
+			Help: "Total number of events NACKed because the broker consumer queue was full",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+	)
+
+	policyViolations := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hyperfleet_adapter_policy_violations_total",
+			Help: "Total number of rendered resources rejected by the resource policy, by violated rule",
+			ConstLabels: prometheus.Labels{
+				"component": component,
+				"version":   version,
+			},
+		},
+		[]string{"rule"},
+	)
+
+	eventsProcessed = Register(reg, eventsProcessed)
+	processingDuration = Register(reg, processingDuration)
+	errorsTotal = Register(reg, errorsTotal)
+	rateLimitWait = Register(reg, rateLimitWait)
+	rateLimitQueued = Register(reg, rateLimitQueued)
+	requeueDelay = Register(reg, requeueDelay)
+	requeueHoldLimit = Register(reg, requeueHoldLimit)
+	eventRedeliveries = Register(reg, eventRedeliveries)
+	handlerResults = Register(reg, handlerResults)
+	handlerPanics = Register(reg, handlerPanics)
+	duplicateEvents = Register(reg, duplicateEvents)
+	filteredEvents = Register(reg, filteredEvents)
+	decodeErrors = Register(reg, decodeErrors)
+	subscriptionUp = Register(reg, subscriptionUp)
+	startupDuration = Register(reg, startupDuration)
+	e2eLatency = Register(reg, e2eLatency)
+	eventProcessing = Register(reg, eventProcessing)
+	eventsInFlight = Register(reg, eventsInFlight)
+	clockSkew = Register(reg, clockSkew)
+	logLevel = Register(reg, logLevel)
+	configInfo = Register(reg, configInfo)
+	apiCalls = Register(reg, apiCalls)
+	httpConnections = Register(reg, httpConnections)
+	auditWriteFailures = Register(reg, auditWriteFailures)
+	oversizedEvents = Register(reg, oversizedEvents)
+	unchangedPosts = Register(reg, unchangedPosts)
+	postBufferCalls = Register(reg, postBufferCalls)
+	fenceWait = Register(reg, fenceWait)
+	stepDuration = Register(reg, stepDuration)
+	workflowRuns = Register(reg, workflowRuns)
+	precondNotMet = Register(reg, precondNotMet)
+	precondNotMetBy = Register(reg, precondNotMetBy)
+	precondAPIFailures = Register(reg, precondAPIFailures)
+	contractViolations = Register(reg, contractViolations)
+	queueWait = Register(reg, queueWait)
+	queueDepth = Register(reg, queueDepth)
+	queueRejected = Register(reg, queueRejected)
+	policyViolations = Register(reg, policyViolations)
 
 	return &Recorder{
 		eventsProcessed:    eventsProcessed,
 		processingDuration: processingDuration,
 		errorsTotal:        errorsTotal,
+		rateLimitWait:      rateLimitWait,
+		rateLimitQueued:    rateLimitQueued,
+		requeueDelay:       requeueDelay,
+		requeueHoldLimit:   requeueHoldLimit,
+		eventRedeliveries:  eventRedeliveries,
+		handlerResults:     handlerResults,
+		handlerPanics:      handlerPanics,
+		duplicateEvents:    duplicateEvents,
+		filteredEvents:     filteredEvents,
+		decodeErrors:       decodeErrors,
+		subscriptionUp:     subscriptionUp,
+		startupDuration:    startupDuration,
+		e2eLatency:         e2eLatency,
+		eventProcessing:    eventProcessing,
+		eventsInFlight:     eventsInFlight,
+		clockSkew:          clockSkew,
+		logLevel:           logLevel,
+		configInfo:         configInfo,
+		apiCalls:           apiCalls,
+		httpConnections:    httpConnections,
+		auditWriteFailures: auditWriteFailures,
+		oversizedEvents:    oversizedEvents,
+		unchangedPosts:     unchangedPosts,
+		postBufferCalls:    postBufferCalls,
+		fenceWait:          fenceWait,
+		stepDuration:       stepDuration,
+		workflowRuns:       workflowRuns,
+		precondNotMet:      precondNotMet,
+		precondNotMetBy:    precondNotMetBy,
+		precondAPIFailures: precondAPIFailures,
+		contractViolations: contractViolations,
+		queueWait:          queueWait,
+		queueDepth:         queueDepth,
+		queueRejected:      queueRejected,
+		policyViolations:   policyViolations,
 	}
 }
 
@@ -88,12 +621,338 @@ func (r *Recorder) ObserveProcessingDuration(d time.Duration) {
 	r.processingDuration.Observe(d.Seconds())
 }
 
-// RecordError increments the errors_total counter for the given error type.
+// RecordError increments the errors_total counter for the given error type and code.
 // Error types correspond to execution phases: "param_extraction", "preconditions",
-// "resources", "post_actions".
-func (r *Recorder) RecordError(errorType string) {
+// "resources", "post_actions". Codes are the executor error codes, e.g. "APICallFailed".
+func (r *Recorder) RecordError(errorType, code string) {
+	if r == nil {
+		return
+	}
+	r.errorsTotal.WithLabelValues(errorType, code).Inc()
+}
+
+// ObserveRateLimitWait records how long an event waited on the rate limiter.
+func (r *Recorder) ObserveRateLimitWait(d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.rateLimitWait.Observe(d.Seconds())
+}
+
+// IncRateLimitQueueDepth counts an event starting to wait on the rate limiter.
+func (r *Recorder) IncRateLimitQueueDepth() {
+	if r == nil {
+		return
+	}
+	r.rateLimitQueued.Inc()
+}
+
+// DecRateLimitQueueDepth counts an event done waiting on the rate limiter.
+func (r *Recorder) DecRateLimitQueueDepth() {
+	if r == nil {
+		return
+	}
+	r.rateLimitQueued.Dec()
+}
+
+// ObserveQueueWait records how long an event waited in the queue for a worker.
+func (r *Recorder) ObserveQueueWait(d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.queueWait.Observe(d.Seconds())
+}
+
+// IncQueueDepth counts an event entering the queue.
+func (r *Recorder) IncQueueDepth() {
+	if r == nil {
+		return
+	}
+	r.queueDepth.Inc()
+}
+
+// DecQueueDepth counts an event taken from the queue by a worker.
+func (r *Recorder) DecQueueDepth() {
+	if r == nil {
+		return
+	}
+	r.queueDepth.Dec()
+}
+
+// RecordQueueRejected counts an event NACKed because the queue was full.
+func (r *Recorder) RecordQueueRejected() {
+	if r == nil {
+		return
+	}
+	r.queueRejected.Inc()
+}
+
+// RecordPolicyViolation increments the policy_violations_total counter for the
+// violated rule of the resource policy, a field name of the policy.
+func (r *Recorder) RecordPolicyViolation(rule string) {
+	if r == nil {
+		return
+	}
+	r.policyViolations.WithLabelValues(rule).Inc()
+}
+
+// ObserveRequeueDelay records the redelivery delay applied to an event of the given
+// subscription. capped reports whether the requested delay exceeded the configured maximum.
+func (r *Recorder) ObserveRequeueDelay(subscription string, d time.Duration, capped bool) {
+	if r == nil {
+		return
+	}
+	r.requeueDelay.WithLabelValues(subscription, strconv.FormatBool(capped)).Observe(d.Seconds())
+}
+
+// RecordRequeueHoldLimit increments the requeue_hold_limit_total counter for an
+// event of the given subscription redelivered without delay.
+func (r *Recorder) RecordRequeueHoldLimit(subscription string) {
+	if r == nil {
+		return
+	}
+	r.requeueHoldLimit.WithLabelValues(subscription).Inc()
+}
+
+// RecordEventRedelivery increments the event_redeliveries_total counter for an
+// event NACKed after failing in phase with errorCode.
+func (r *Recorder) RecordEventRedelivery(phase, errorCode string) {
+	if r == nil {
+		return
+	}
+	r.eventRedeliveries.WithLabelValues(phase, errorCode).Inc()
+}
+
+// RecordAPICall increments the api_calls_total counter for the given HyperFleet API
+// target and outcome. Targets are configured target names, which keeps the label
+// bounded. Valid outcome values: "success", "failed".
+func (r *Recorder) RecordAPICall(target, outcome string) {
+	if r == nil {
+		return
+	}
+	r.apiCalls.WithLabelValues(target, outcome).Inc()
+}
+
+// ObserveHTTPConnection increments the http_connections_total counter of an HTTP
+// request of client ("hyperfleet_api" or "webhook") with a new or reused
+// connection. It implements httpx.ConnObserver.
+func (r *Recorder) ObserveHTTPConnection(client string, reused bool) {
+	if r == nil {
+		return
+	}
+	connection := "new"
+	if reused {
+		connection = "reused"
+	}
+	r.httpConnections.WithLabelValues(client, connection).Inc()
+}
+
+// RecordAuditWriteFailure increments the audit_write_failures_total counter for
+// the given sink. Valid sink values: "file", "http", and "queue" for records
+// dropped because the audit queue was full.
+func (r *Recorder) RecordAuditWriteFailure(sink string) {
+	if r == nil {
+		return
+	}
+	r.auditWriteFailures.WithLabelValues(sink).Inc()
+}
+
+// RecordHandlerResult increments the handler_results_total counter for the given
+// subscription and outcome. Valid outcome values: "ack", "nack".
+func (r *Recorder) RecordHandlerResult(subscription, outcome string) {
+	if r == nil {
+		return
+	}
+	r.handlerResults.WithLabelValues(subscription, outcome).Inc()
+}
+
+// RecordHandlerPanic increments the handler_panics_total counter for the given subscription.
+func (r *Recorder) RecordHandlerPanic(subscription string) {
+	if r == nil {
+		return
+	}
+	r.handlerPanics.WithLabelValues(subscription).Inc()
+}
+
+// RecordOversizedEvent increments the oversized_events_total counter for the given subscription.
+func (r *Recorder) RecordOversizedEvent(subscription string) {
+	if r == nil {
+		return
+	}
+	r.oversizedEvents.WithLabelValues(subscription).Inc()
+}
+
+// RecordUnchangedPostAction increments the post_actions_unchanged_total counter for
+// the given post action. Action names come from the config, which keeps the label bounded.
+func (r *Recorder) RecordUnchangedPostAction(action string) {
+	if r == nil {
+		return
+	}
+	r.unchangedPosts.WithLabelValues(action).Inc()
+}
+
+// RecordPostBufferCall increments the post_buffer_calls_total counter for a
+// call of the given post action: buffered, replayed, expired (dropped past its
+// max age) or rejected (dropped on a status that is not retryable).
+func (r *Recorder) RecordPostBufferCall(action, outcome string) {
+	if r == nil {
+		return
+	}
+	r.postBufferCalls.WithLabelValues(action, outcome).Inc()
+}
+
+// ObserveExecutionFenceWait records how long an execution waited for its execution_fence key.
+func (r *Recorder) ObserveExecutionFenceWait(d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.fenceWait.Observe(d.Seconds())
+}
+
+// ObserveStepDuration records the duration of a precondition, resource or post
+// action. step is the configured name, so the label values are bounded by the config.
+func (r *Recorder) ObserveStepDuration(phase, step string, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.stepDuration.WithLabelValues(phase, step).Observe(d.Seconds())
+}
+
+// RecordWorkflowExecution increments the workflow_executions_total counter.
+// workflow is a configured workflow name, so the label values are bounded by the config.
+func (r *Recorder) RecordWorkflowExecution(workflow, status string) {
+	if r == nil {
+		return
+	}
+	r.workflowRuns.WithLabelValues(workflow, status).Inc()
+}
+
+// RecordPreconditionNotMet increments the preconditions_not_met_total counter of
+// a precondition and, when the precondition has a reason label, the
+// preconditions_not_met_reasons_total counter. Both come from the config, which
+// keeps the labels bounded.
+func (r *Recorder) RecordPreconditionNotMet(precondition, reason string) {
+	if r == nil {
+		return
+	}
+	r.precondNotMet.WithLabelValues(precondition).Inc()
+	if reason != "" {
+		r.precondNotMetBy.WithLabelValues(precondition, reason).Inc()
+	}
+}
+
+// RecordPreconditionAPIFailure increments the preconditions_api_failures_total
+// counter of a configured precondition whose API call failed.
+func (r *Recorder) RecordPreconditionAPIFailure(precondition string) {
+	if r == nil {
+		return
+	}
+	r.precondAPIFailures.WithLabelValues(precondition).Inc()
+}
+
+// RecordAPIContractViolation increments the api_contract_violations_total
+// counter of an expect assertion of a precondition violated by its response.
+func (r *Recorder) RecordAPIContractViolation(precondition, path string) {
+	if r == nil {
+		return
+	}
+	r.contractViolations.WithLabelValues(precondition, path).Inc()
+}
+
+// RecordDuplicateEvent increments the duplicate_events_total counter.
+func (r *Recorder) RecordDuplicateEvent() {
+	if r == nil {
+		return
+	}
+	r.duplicateEvents.Inc()
+}
+
+// RecordFilteredEvent increments the filtered_events_total counter.
+func (r *Recorder) RecordFilteredEvent() {
+	if r == nil {
+		return
+	}
+	r.filteredEvents.Inc()
+}
+
+// RecordEventDecodeError increments the event_decode_errors_total counter for the given reason.
+// Valid reason values: "invalid_data".
+func (r *Recorder) RecordEventDecodeError(reason string) {
+	if r == nil {
+		return
+	}
+	r.decodeErrors.WithLabelValues(reason).Inc()
+}
+
+// SetSubscriptionUp sets the subscription_up gauge for the given subscription.
+func (r *Recorder) SetSubscriptionUp(subscription string, up bool) {
+	if r == nil {
+		return
+	}
+	value := 0.0
+	if up {
+		value = 1
+	}
+	r.subscriptionUp.WithLabelValues(subscription).Set(value)
+}
+
+// SetStartupDuration sets the startup_duration_seconds gauge.
+func (r *Recorder) SetStartupDuration(d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.startupDuration.Set(d.Seconds())
+}
+
+// ObserveEventProcessing records the handler wall time of an event.
+func (r *Recorder) ObserveEventProcessing(status, eventType string, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.eventProcessing.WithLabelValues(status, eventType).Observe(d.Seconds())
+}
+
+// ObserveEventE2ELatency records the time from the CloudEvent time attribute to
+// the end of its processing. Negative latencies caused by clock skew between the
+// producer and the adapter are recorded as zero and counted in event_clock_skew_total.
+func (r *Recorder) ObserveEventE2ELatency(status, eventType string, d time.Duration) {
+	if r == nil {
+		return
+	}
+	if d < 0 {
+		r.clockSkew.WithLabelValues(eventType).Inc()
+		d = 0
+	}
+	r.e2eLatency.WithLabelValues(status, eventType).Observe(d.Seconds())
+}
+
+// AddEventsInFlight adds delta to the events_in_flight gauge of the event type.
+func (r *Recorder) AddEventsInFlight(eventType string, delta int) {
+	if r == nil {
+		return
+	}
+	r.eventsInFlight.WithLabelValues(eventType).Add(float64(delta))
+}
+
+// SetLogLevel sets the log_level gauge to 1 for level and 0 for the other levels.
+func (r *Recorder) SetLogLevel(level string) {
+	if r == nil {
+		return
+	}
+	for _, name := range logLevels {
+		value := 0.0
+		if name == level {
+			value = 1
+		}
+		r.logLevel.WithLabelValues(name).Set(value)
+	}
+}
+
+// SetConfigInfo sets the config_info gauge to 1 for hash, replacing the previous hash.
+func (r *Recorder) SetConfigInfo(hash string) {
 	if r == nil {
 		return
 	}
-	r.errorsTotal.WithLabelValues(errorType).Inc()
+	r.configInfo.Reset()
+	r.configInfo.WithLabelValues(hash).Set(1)
 }
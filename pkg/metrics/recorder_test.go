@@ -11,14 +11,14 @@ import (
 )
 
 func TestNewRecorder(t *testing.T) {
-	registry := prometheus.NewRegistry()
+	registry := NewTestRegistry()
 	recorder := NewRecorder("test-adapter", "v0.1.0", registry)
 	require.NotNil(t, recorder)
 
 	// Trigger all metrics so they appear in Gather()
 	recorder.RecordEventProcessed("success")
 	recorder.ObserveProcessingDuration(1 * time.Millisecond)
-	recorder.RecordError("test")
+	recorder.RecordError("test", "Internal")
 
 	families, err := registry.Gather()
 	require.NoError(t, err)
@@ -141,10 +141,11 @@ func TestRecordError(t *testing.T) {
 	registry := prometheus.NewRegistry()
 	recorder := NewRecorder("test-adapter", "v0.1.0", registry)
 
-	recorder.RecordError("param_extraction")
-	recorder.RecordError("preconditions")
-	recorder.RecordError("preconditions")
-	recorder.RecordError("resources")
+	recorder.RecordError("param_extraction", "ParamMissing")
+	recorder.RecordError("preconditions", "APICallFailed")
+	recorder.RecordError("preconditions", "APICallFailed")
+	recorder.RecordError("preconditions", "Timeout")
+	recorder.RecordError("resources", "ApplyConflict")
 
 	families, err := registry.Gather()
 	require.NoError(t, err)
@@ -160,16 +161,17 @@ func TestRecordError(t *testing.T) {
 
 	counts := make(map[string]float64)
 	for _, m := range errorsFamily.GetMetric() {
+		labels := make(map[string]string)
 		for _, l := range m.GetLabel() {
-			if l.GetName() == "error_type" {
-				counts[l.GetValue()] = m.GetCounter().GetValue()
-			}
+			labels[l.GetName()] = l.GetValue()
 		}
+		counts[labels["error_type"]+"/"+labels["code"]] = m.GetCounter().GetValue()
 	}
 
-	assert.Equal(t, float64(1), counts["param_extraction"], "param_extraction error count")
-	assert.Equal(t, float64(2), counts["preconditions"], "preconditions error count")
-	assert.Equal(t, float64(1), counts["resources"], "resources error count")
+	assert.Equal(t, float64(1), counts["param_extraction/ParamMissing"], "param_extraction error count")
+	assert.Equal(t, float64(2), counts["preconditions/APICallFailed"], "preconditions API error count")
+	assert.Equal(t, float64(1), counts["preconditions/Timeout"], "preconditions timeout count")
+	assert.Equal(t, float64(1), counts["resources/ApplyConflict"], "resources error count")
 }
 
 func TestNilRecorderNoPanic(t *testing.T) {
@@ -185,6 +187,246 @@ func TestNilRecorderNoPanic(t *testing.T) {
 	}, "ObserveProcessingDuration on nil recorder")
 
 	assert.NotPanics(t, func() {
-		recorder.RecordError("test_error")
+		recorder.RecordError("test_error", "Internal")
 	}, "RecordError on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.RecordEventDecodeError("invalid_data")
+	}, "RecordEventDecodeError on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.SetSubscriptionUp("cluster-events", true)
+	}, "SetSubscriptionUp on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.RecordEventRedelivery("preconditions", "APICallFailed")
+	}, "RecordEventRedelivery on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.RecordAPICall("default", "success")
+	}, "RecordAPICall on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.ObserveHTTPConnection("hyperfleet_api", true)
+	}, "ObserveHTTPConnection on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.RecordAuditWriteFailure("file")
+	}, "RecordAuditWriteFailure on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.RecordOversizedEvent("cluster-events")
+	}, "RecordOversizedEvent on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.RecordFilteredEvent()
+	}, "RecordFilteredEvent on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.RecordUnchangedPostAction("reportStatus")
+	}, "RecordUnchangedPostAction on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.RecordPostBufferCall("reportStatus", "buffered")
+	}, "RecordPostBufferCall on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.ObserveExecutionFenceWait(time.Second)
+	}, "ObserveExecutionFenceWait on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.ObserveStepDuration("resources", "clusterNamespace", time.Second)
+	}, "ObserveStepDuration on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.ObserveRequeueDelay("cluster-events", time.Second, false)
+	}, "ObserveRequeueDelay on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.RecordWorkflowExecution("teardown", "success")
+	}, "RecordWorkflowExecution on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.RecordPreconditionNotMet("clusterReady", "cluster_not_ready")
+		recorder.RecordPreconditionAPIFailure("clusterReady")
+		recorder.RecordAPIContractViolation("clusterReady", "status.phase")
+	}, "precondition metrics on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.IncQueueDepth()
+		recorder.DecQueueDepth()
+		recorder.ObserveQueueWait(time.Second)
+		recorder.RecordQueueRejected()
+	}, "queue metrics on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.RecordPolicyViolation("denied_kinds")
+	}, "RecordPolicyViolation on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.SetStartupDuration(time.Second)
+	}, "SetStartupDuration on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.ObserveEventProcessing("success", "io.hyperfleet.cluster.updated", time.Second)
+		recorder.ObserveEventE2ELatency("success", "io.hyperfleet.cluster.updated", -time.Second)
+		recorder.AddEventsInFlight("io.hyperfleet.cluster.updated", 1)
+	}, "event latency methods on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.SetLogLevel("debug")
+	}, "SetLogLevel on nil recorder")
+
+	assert.NotPanics(t, func() {
+		recorder.SetConfigInfo("abc123")
+	}, "SetConfigInfo on nil recorder")
+}
+
+func TestRecordEventDecodeError(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := NewRecorder("test-adapter", "v0.1.0", registry)
+
+	recorder.RecordEventDecodeError("invalid_data")
+	recorder.RecordEventDecodeError("invalid_data")
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	counts := make(map[string]float64)
+	for _, f := range families {
+		if f.GetName() != "hyperfleet_adapter_event_decode_errors_total" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "reason" {
+					counts[l.GetValue()] = m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]float64{"invalid_data": 2}, counts)
+}
+
+func TestSetSubscriptionUp(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := NewRecorder("test-adapter", "v0.1.0", registry)
+
+	recorder.SetSubscriptionUp("cluster-events", true)
+	recorder.SetSubscriptionUp("nodepool-events", true)
+	recorder.SetSubscriptionUp("nodepool-events", false)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	values := make(map[string]float64)
+	for _, f := range families {
+		if f.GetName() != "hyperfleet_adapter_subscription_up" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "subscription" {
+					values[l.GetValue()] = m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]float64{"cluster-events": 1, "nodepool-events": 0}, values)
+}
+
+func TestSetLogLevel(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := NewRecorder("test-adapter", "v0.1.0", registry)
+
+	recorder.SetLogLevel("info")
+	recorder.SetLogLevel("debug")
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	values := make(map[string]float64)
+	for _, f := range families {
+		if f.GetName() != "hyperfleet_adapter_log_level" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "level" {
+					values[l.GetValue()] = m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]float64{"debug": 1, "info": 0, "warn": 0, "error": 0}, values)
+}
+
+func TestSetConfigInfo(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := NewRecorder("test-adapter", "v0.1.0", registry)
+
+	recorder.SetConfigInfo("first")
+	recorder.SetConfigInfo("second")
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	values := make(map[string]float64)
+	for _, f := range families {
+		if f.GetName() != "hyperfleet_adapter_config_info" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "config_hash" {
+					values[l.GetValue()] = m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]float64{"second": 1}, values)
+}
+
+func TestSetStartupDuration(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := NewRecorder("test-adapter", "v0.1.0", registry)
+
+	recorder.SetStartupDuration(1500 * time.Millisecond)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	var value float64
+	for _, f := range families {
+		if f.GetName() == "hyperfleet_adapter_startup_duration_seconds" {
+			value = f.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	assert.Equal(t, 1.5, value)
+}
+
+func TestObserveEventE2ELatency_ClampsClockSkew(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := NewRecorder("test-adapter", "v0.1.0", registry)
+
+	recorder.ObserveEventE2ELatency("success", OtherEventType, -5*time.Second)
+	recorder.ObserveEventE2ELatency("success", OtherEventType, 3*time.Second)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	for _, f := range families {
+		switch f.GetName() {
+		case "hyperfleet_adapter_event_e2e_latency_seconds":
+			histogram := f.GetMetric()[0].GetHistogram()
+			assert.Equal(t, uint64(2), histogram.GetSampleCount())
+			assert.Equal(t, 3.0, histogram.GetSampleSum(), "negative latency is clamped to zero")
+		case "hyperfleet_adapter_event_clock_skew_total":
+			assert.Equal(t, 1.0, f.GetMetric()[0].GetCounter().GetValue())
+		}
+	}
 }
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Register registers collector with reg, prometheus.DefaultRegisterer when
+// nil, and returns the collector to record into. Registering the same metrics
+// twice, e.g. when an executor is embedded in another binary or created by
+// several tests, does not panic: the collector registered first is returned,
+// so both record into the same metrics. When the first one is of another type,
+// collector is returned unregistered, and what it records is not exported.
+// Any other error, e.g. a metric registered with other label names, is a bug
+// and panics like prometheus.MustRegister.
+func Register[T prometheus.Collector](reg prometheus.Registerer, collector T) T {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	err := reg.Register(collector)
+	if err == nil {
+		return collector
+	}
+	var registered prometheus.AlreadyRegisteredError
+	if !errors.As(err, &registered) {
+		panic(err)
+	}
+	if existing, ok := registered.ExistingCollector.(T); ok {
+		return existing
+	}
+	return collector
+}
+
+// NewTestRegistry returns a registry for the metrics of a test, so tests do
+// not share the global registry. It is pedantic: gathering fails for a
+// collector whose metrics do not match the descriptors it registered.
+func NewTestRegistry() *prometheus.Registry {
+	return prometheus.NewPedanticRegistry()
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRecorder_SharedRegistry(t *testing.T) {
+	registry := NewTestRegistry()
+	first := NewRecorder("test-adapter", "v0.1.0", registry)
+	var second *Recorder
+	require.NotPanics(t, func() { second = NewRecorder("test-adapter", "v0.1.0", registry) })
+
+	first.RecordEventProcessed("success")
+	second.RecordEventProcessed("success")
+	assert.Equal(t, 2.0, testutil.ToFloat64(first.eventsProcessed.WithLabelValues("success")),
+		"both recorders record into the metrics registered first")
+
+	count, err := testutil.GatherAndCount(registry, "hyperfleet_adapter_events_processed_total")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestRegister(t *testing.T) {
+	opts := prometheus.CounterOpts{Name: "hyperfleet_adapter_test_total", Help: "Test counter"}
+
+	t.Run("already registered", func(t *testing.T) {
+		registry := NewTestRegistry()
+		first := Register(registry, prometheus.NewCounter(opts))
+		second := Register(registry, prometheus.NewCounter(opts))
+		assert.Same(t, first, second)
+	})
+
+	t.Run("already registered with another type", func(t *testing.T) {
+		registry := NewTestRegistry()
+		Register(registry, prometheus.NewCounter(opts))
+		vec := prometheus.NewCounterVec(opts, nil)
+		got := Register(registry, vec)
+		assert.Same(t, vec, got, "the collector is returned unregistered")
+		assert.NotPanics(t, func() { got.WithLabelValues().Inc() })
+	})
+
+	t.Run("conflicting registration", func(t *testing.T) {
+		registry := NewTestRegistry()
+		Register(registry, prometheus.NewCounter(opts))
+		other := prometheus.NewCounterVec(opts, []string{"status"})
+		assert.Panics(t, func() { Register(registry, other) })
+	})
+}
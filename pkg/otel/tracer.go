@@ -6,13 +6,16 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 // Tracing configuration constants
@@ -23,8 +26,61 @@ const (
 	// DefaultTraceSampleRatio is the default trace sampling ratio (10% of traces)
 	// Can be overridden via TRACE_SAMPLE_RATIO env var
 	DefaultTraceSampleRatio = 0.1
+
+	// Standard OpenTelemetry env vars that enable and configure the OTLP exporter
+	EnvOTLPEndpoint       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	EnvOTLPTracesEndpoint = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	EnvTracesExporter     = "OTEL_TRACES_EXPORTER"
+	EnvSDKDisabled        = "OTEL_SDK_DISABLED"
 )
 
+// ExporterConfigured reports whether traces should be exported over OTLP: an
+// OTLP endpoint is set, OTEL_TRACES_EXPORTER is unset or "otlp", and the SDK is
+// not disabled with OTEL_SDK_DISABLED=true.
+func ExporterConfigured() bool {
+	if strings.EqualFold(os.Getenv(EnvSDKDisabled), "true") {
+		return false
+	}
+	if exporter := os.Getenv(EnvTracesExporter); exporter != "" && !strings.EqualFold(exporter, "otlp") {
+		return false
+	}
+	return os.Getenv(EnvOTLPEndpoint) != "" || os.Getenv(EnvOTLPTracesEndpoint) != ""
+}
+
+// Setup configures tracing for the adapter and returns a function flushing and
+// stopping it on shutdown.
+//
+// When ExporterConfigured, spans are sampled with the TRACE_SAMPLE_RATIO and
+// exported with the OTLP HTTP exporter, configured by the standard
+// OTEL_EXPORTER_OTLP_* env vars (endpoint, headers, timeout, TLS). Otherwise a
+// no-op TracerProvider is installed so tracing costs nothing: spans are not
+// recorded, but an upstream trace context extracted from an event is still
+// propagated to logs and outgoing HTTP requests.
+func Setup(ctx context.Context, log logger.Logger, serviceName, serviceVersion string) (
+	func(context.Context) error, error,
+) {
+	// TraceContext propagator handles W3C traceparent/tracestate headers
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	if !ExporterConfigured() {
+		log.Infof(ctx, "OpenTelemetry trace export disabled, set %s to enable it", EnvOTLPEndpoint)
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+	sampleRatio := GetTraceSampleRatio(log, ctx)
+	tp, err := InitTracer(serviceName, serviceVersion, sampleRatio, sdktrace.WithBatcher(exporter))
+	if err != nil {
+		return nil, err
+	}
+	log.Info(ctx, "OpenTelemetry trace export enabled over OTLP/HTTP")
+	return tp.Shutdown, nil
+}
+
 // GetTraceSampleRatio reads the trace sample ratio from TRACE_SAMPLE_RATIO env var.
 // Returns DefaultTraceSampleRatio (0.1 = 10%) if not set or invalid.
 // Valid range is 0.0 to 1.0 where:
@@ -74,7 +130,10 @@ func GetTraceSampleRatio(log logger.Logger, ctx context.Context) float64 {
 // - Respects the parent span's sampling decision when present (from traceparent header)
 // - Applies probabilistic sampling for root spans based on sampleRatio
 // This allows distributed tracing visibility while controlling observability costs.
-func InitTracer(serviceName, serviceVersion string, sampleRatio float64) (*sdktrace.TracerProvider, error) {
+// opts are appended to the provider options, e.g. sdktrace.WithBatcher to export spans.
+func InitTracer(
+	serviceName, serviceVersion string, sampleRatio float64, opts ...sdktrace.TracerProviderOption,
+) (*sdktrace.TracerProvider, error) {
 	// Create resource with service attributes.
 	// Note: We don't merge with resource.Default() to avoid schema URL conflicts
 	// between the SDK's bundled semconv version and our imported version.
@@ -98,10 +157,10 @@ func InitTracer(serviceName, serviceVersion string, sampleRatio float64) (*sdktr
 	// This enables proper sampling propagation across service boundaries
 	sampler := sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))
 
-	tp := sdktrace.NewTracerProvider(
+	tp := sdktrace.NewTracerProvider(append([]sdktrace.TracerProviderOption{
 		sdktrace.WithResource(res),
 		sdktrace.WithSampler(sampler),
-	)
+	}, opts...)...)
 	otel.SetTracerProvider(tp)
 	// TraceContext propagator handles W3C traceparent/tracestate headers
 	// ensuring sampling decisions propagate through message headers
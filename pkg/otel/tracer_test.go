@@ -0,0 +1,91 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestExporterConfigured(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		name string
+		want bool
+	}{
+		{name: "no_endpoint", env: map[string]string{}, want: false},
+		{name: "endpoint", env: map[string]string{EnvOTLPEndpoint: "http://collector:4318"}, want: true},
+		{
+			name: "traces_endpoint",
+			env:  map[string]string{EnvOTLPTracesEndpoint: "http://collector:4318/v1/traces"},
+			want: true,
+		},
+		{
+			name: "otlp_exporter",
+			env:  map[string]string{EnvOTLPEndpoint: "http://collector:4318", EnvTracesExporter: "otlp"},
+			want: true,
+		},
+		{
+			name: "exporter_none",
+			env:  map[string]string{EnvOTLPEndpoint: "http://collector:4318", EnvTracesExporter: "none"},
+			want: false,
+		},
+		{
+			name: "sdk_disabled",
+			env:  map[string]string{EnvOTLPEndpoint: "http://collector:4318", EnvSDKDisabled: "true"},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{EnvOTLPEndpoint, EnvOTLPTracesEndpoint, EnvTracesExporter, EnvSDKDisabled} {
+				t.Setenv(key, tt.env[key])
+			}
+			if got := ExporterConfigured(); got != tt.want {
+				t.Errorf("ExporterConfigured() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetup_NoopWithoutExporter(t *testing.T) {
+	t.Setenv(EnvOTLPEndpoint, "")
+	t.Setenv(EnvOTLPTracesEndpoint, "")
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	shutdown, err := Setup(context.Background(), logger.NewTestLogger(), "test", "v0")
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if _, ok := otel.GetTracerProvider().(noop.TracerProvider); !ok {
+		t.Errorf("expected a no-op TracerProvider, got %T", otel.GetTracerProvider())
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+}
+
+func TestSetup_OTLPExporter(t *testing.T) {
+	t.Setenv(EnvOTLPEndpoint, "http://127.0.0.1:1")
+	t.Setenv(EnvTraceSampleRatio, "1")
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	shutdown, err := Setup(context.Background(), logger.NewTestLogger(), "test", "v0")
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	_, span := otel.Tracer("test").Start(context.Background(), "span")
+	if !span.SpanContext().IsSampled() {
+		t.Error("expected spans to be sampled with TRACE_SAMPLE_RATIO=1")
+	}
+	span.End()
+	// The collector is unreachable; shutdown must still return once its context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = shutdown(ctx) //nolint:errcheck // export to the unreachable collector fails
+}
@@ -13,6 +13,9 @@ import (
 	"golang.org/x/text/language"
 )
 
+// templates caches the templates parsed by RenderTemplate
+var templates = NewTemplateCache(DefaultTemplateCacheSize)
+
 // TemplateFuncs provides helper functions for Go templates.
 // These functions are available within {{ }} template expressions.
 var TemplateFuncs = template.FuncMap{
@@ -116,6 +119,14 @@ var TemplateFuncs = template.FuncMap{
 	"string": func(v interface{}) string {
 		return fmt.Sprintf("%v", v)
 	},
+
+	// Encoding functions, e.g. to embed a JSON payload param in a manifest
+	"toJson":   ToJSON,
+	"fromJson": FromJSON,
+	"toYaml":   ToYAML,
+	"fromYaml": FromYAML,
+	"indent":   Indent,
+	"nindent":  Nindent,
 }
 
 // RenderTemplate renders a Go template string with the given data.
@@ -137,7 +148,7 @@ func RenderTemplate(templateStr string, data map[string]interface{}) (string, er
 		return templateStr, nil
 	}
 
-	tmpl, err := template.New("template").Funcs(TemplateFuncs).Option("missingkey=error").Parse(templateStr)
+	tmpl, err := templates.Parse(templateStr, TemplateFuncs, "missingkey=error")
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/lru"
+)
+
+// DefaultTemplateCacheSize bounds the parsed templates kept by RenderTemplate and the executor.
+// A config renders a fixed set of template texts, so this only matters for
+// templates built from event data, which would otherwise grow the cache without limit.
+const DefaultTemplateCacheSize = 1024
+
+// templateKey identifies a parsed template: the same text parsed with another
+// function map or other options is a different template
+type templateKey struct {
+	text    string
+	options string
+	funcs   uintptr
+}
+
+// TemplateCache is a bounded, concurrency-safe LRU cache of parsed templates,
+// so templates rendered for every event are parsed once.
+// Parsed templates are safe to execute concurrently.
+type TemplateCache struct {
+	templates *lru.Cache[templateKey, *template.Template]
+}
+
+// NewTemplateCache creates a cache of at most maxEntries parsed templates.
+// A cache with maxEntries <= 0 parses on every call.
+func NewTemplateCache(maxEntries int) *TemplateCache {
+	return &TemplateCache{templates: lru.New[templateKey, *template.Template](maxEntries)}
+}
+
+// Parse returns the template parsed from text with the given function map and
+// options (see template.Option), from the cache when it was parsed before.
+// The function map is identified by its identity, not its content, so it must
+// not be modified after its first use.
+func (c *TemplateCache) Parse(text string, funcs template.FuncMap, options ...string) (*template.Template, error) {
+	key := templateKey{
+		text:    text,
+		options: strings.Join(options, ","),
+		funcs:   reflect.ValueOf(funcs).Pointer(),
+	}
+
+	if tmpl, ok := c.templates.Get(key); ok {
+		return tmpl, nil
+	}
+
+	tmpl, err := template.New("template").Funcs(funcs).Option(options...).Parse(text)
+	if err != nil {
+		return tmpl, err
+	}
+	// Another goroutine may have parsed the same text meanwhile; either template is fine
+	c.templates.Add(key, tmpl)
+	return tmpl, nil
+}
+
+// Len returns the number of cached templates
+func (c *TemplateCache) Len() int {
+	return c.templates.Len()
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateCache_Parse(t *testing.T) {
+	cache := NewTemplateCache(10)
+	otherFuncs := template.FuncMap{"upper": func(s string) string { return s + "!" }}
+
+	strict, err := cache.Parse("{{ .name }}", TemplateFuncs, "missingkey=error")
+	require.NoError(t, err)
+	again, err := cache.Parse("{{ .name }}", TemplateFuncs, "missingkey=error")
+	require.NoError(t, err)
+	assert.Same(t, strict, again, "same text, funcs and options should hit the cache")
+
+	lenient, err := cache.Parse("{{ .name }}", TemplateFuncs)
+	require.NoError(t, err)
+	assert.NotSame(t, strict, lenient, "options are part of the key")
+	withOtherFuncs, err := cache.Parse("{{ .name }}", otherFuncs, "missingkey=error")
+	require.NoError(t, err)
+	assert.NotSame(t, strict, withOtherFuncs, "the function map is part of the key")
+	assert.Equal(t, 3, cache.Len())
+
+	var buf bytes.Buffer
+	assert.Error(t, strict.Execute(&buf, map[string]interface{}{}), "strict template fails on a missing key")
+	require.NoError(t, lenient.Execute(&buf, map[string]interface{}{}))
+	assert.Equal(t, "<no value>", buf.String())
+
+	upper, err := cache.Parse(`{{ upper "a" }}`, otherFuncs)
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, upper.Execute(&buf, nil))
+	assert.Equal(t, "a!", buf.String())
+}
+
+func TestTemplateCache_Eviction(t *testing.T) {
+	cache := NewTemplateCache(2)
+	parse := func(text string) *template.Template {
+		tmpl, err := cache.Parse(text, TemplateFuncs)
+		require.NoError(t, err)
+		return tmpl
+	}
+
+	a := parse("{{ .a }}")
+	b := parse("{{ .b }}")
+	assert.Same(t, a, parse("{{ .a }}"), "a is now the most recently used")
+	parse("{{ .c }}")
+
+	assert.Equal(t, 2, cache.Len())
+	assert.Same(t, a, parse("{{ .a }}"), "a should still be cached")
+	assert.NotSame(t, b, parse("{{ .b }}"), "b should have been evicted")
+}
+
+func TestTemplateCache_Uncached(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		cache := NewTemplateCache(0)
+		first, err := cache.Parse("{{ .a }}", TemplateFuncs)
+		require.NoError(t, err)
+		second, err := cache.Parse("{{ .a }}", TemplateFuncs)
+		require.NoError(t, err)
+		assert.NotSame(t, first, second)
+		assert.Zero(t, cache.Len())
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		cache := NewTemplateCache(10)
+		_, err := cache.Parse("{{ .a ", TemplateFuncs)
+		assert.Error(t, err)
+		assert.Zero(t, cache.Len())
+	})
+}
+
+// Concurrent renders of the same template share one parsed template; run with -race
+func TestTemplateCache_ConcurrentRenders(t *testing.T) {
+	cache := NewTemplateCache(4)
+	var wg sync.WaitGroup
+	errs := make(chan error, 32)
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				text := "cluster-{{ .id }}"
+				if j%10 == 0 {
+					// churn the LRU list while others hit it
+					text = fmt.Sprintf("{{ .id }}-%d", j)
+				}
+				tmpl, err := cache.Parse(text, TemplateFuncs, "missingkey=error")
+				if err != nil {
+					errs <- err
+					return
+				}
+				var buf bytes.Buffer
+				if err := tmpl.Execute(&buf, map[string]interface{}{"id": i}); err != nil {
+					errs <- err
+					return
+				}
+				if j%10 != 0 && buf.String() != fmt.Sprintf("cluster-%d", i) {
+					errs <- fmt.Errorf("goroutine %d rendered %q", i, buf.String())
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	assert.LessOrEqual(t, cache.Len(), 4)
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// maxSnippetLength bounds the input quoted in the errors of the encoding template functions
+const maxSnippetLength = 40
+
+// ToJSON encodes v as compact JSON, for the toJson template function
+func ToJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode %s: %w", snippet(fmt.Sprintf("%v", v)), err)
+	}
+	return string(data), nil
+}
+
+// FromJSON decodes a JSON document, for the fromJson template function.
+// Objects decode to map[string]interface{}, navigable with template dot notation,
+// e.g. {{ (fromJson .clusterPayload).status.phase }}.
+func FromJSON(s string) (interface{}, error) {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil, fmt.Errorf("invalid JSON %s: %w", snippet(s), err)
+	}
+	return v, nil
+}
+
+// ToYAML encodes v as YAML without a trailing newline, for the toYaml template function
+func ToYAML(v interface{}) (string, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode %s: %w", snippet(fmt.Sprintf("%v", v)), err)
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// FromYAML decodes a YAML document, for the fromYaml template function.
+// Mappings decode to map[string]interface{} as in FromJSON.
+func FromYAML(s string) (interface{}, error) {
+	var v interface{}
+	if err := yaml.Unmarshal([]byte(s), &v); err != nil {
+		return nil, fmt.Errorf("invalid YAML %s: %w", snippet(s), err)
+	}
+	return v, nil
+}
+
+// Indent prefixes every line of s with spaces spaces, for the indent template function
+func Indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+// Nindent is Indent preceded by a newline, for the nindent template function,
+// e.g. to embed a document under a YAML key: {{ .payload | fromJson | toYaml | nindent 4 }}
+func Nindent(spaces int, s string) string {
+	return "\n" + Indent(spaces, s)
+}
+
+// snippet quotes the start of s for error messages
+func snippet(s string) string {
+	if len(s) > maxSnippetLength {
+		return fmt.Sprintf("%q...", s[:maxSnippetLength])
+	}
+	return fmt.Sprintf("%q", s)
+}
@@ -2,7 +2,10 @@
 // Version values are set at build time via ldflags.
 package version
 
-import "os"
+import (
+	"fmt"
+	"os"
+)
 
 // Environment variable for overriding UserAgent
 const EnvUserAgent = "HYPERFLEET_USER_AGENT"
@@ -27,21 +30,35 @@ func UserAgent() string {
 	if ua := os.Getenv(EnvUserAgent); ua != "" {
 		return ua
 	}
-	return "hyperfleet-adapter/" + Version
+	return "hyperfleet-adapter/" + Info().Version
 }
 
-// Info returns all version information as a struct
+// Info returns all version information as a struct.
+// Values left empty by ldflags are reported as "dev" (version) or "unknown".
 func Info() VersionInfo {
 	return VersionInfo{
-		Version:   Version,
-		Commit:    Commit,
-		BuildDate: BuildDate,
+		Version:   valueOr(Version, "dev"),
+		Commit:    valueOr(Commit, "unknown"),
+		BuildDate: valueOr(BuildDate, "unknown"),
 	}
 }
 
+// String renders the version information as printed by the version command
+func (v VersionInfo) String() string {
+	return fmt.Sprintf("HyperFleet Adapter\n  Version:    %s\n  Commit:     %s\n  Built:      %s\n",
+		v.Version, v.Commit, v.BuildDate)
+}
+
 // VersionInfo contains all build version information
 type VersionInfo struct {
-	Version   string
-	Commit    string
-	BuildDate string
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
 }
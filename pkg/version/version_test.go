@@ -0,0 +1,38 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setBuildVars(t *testing.T, v, commit, buildDate string) {
+	t.Helper()
+	oldVersion, oldCommit, oldBuildDate := Version, Commit, BuildDate
+	t.Cleanup(func() {
+		Version, Commit, BuildDate = oldVersion, oldCommit, oldBuildDate
+	})
+	Version, Commit, BuildDate = v, commit, buildDate
+}
+
+func TestInfo_Populated(t *testing.T) {
+	setBuildVars(t, "1.2.3", "abc1234", "2026-01-02T03:04:05Z")
+
+	assert.Equal(t, VersionInfo{Version: "1.2.3", Commit: "abc1234", BuildDate: "2026-01-02T03:04:05Z"}, Info())
+	assert.Equal(t, "HyperFleet Adapter\n  Version:    1.2.3\n  Commit:     abc1234\n  Built:      2026-01-02T03:04:05Z\n",
+		Info().String())
+}
+
+func TestInfo_Unpopulated(t *testing.T) {
+	setBuildVars(t, "", "", "")
+
+	assert.Equal(t, VersionInfo{Version: "dev", Commit: "unknown", BuildDate: "unknown"}, Info())
+}
+
+func TestUserAgent(t *testing.T) {
+	setBuildVars(t, "1.2.3", "abc1234", "")
+
+	assert.Equal(t, "hyperfleet-adapter/1.2.3", UserAgent())
+	t.Setenv(EnvUserAgent, "custom-agent/1.0")
+	assert.Equal(t, "custom-agent/1.0", UserAgent())
+}
@@ -0,0 +1,111 @@
+//go:build integration
+
+package broker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2/pstest"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/brokerconsumer"
+	apierrors "github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/errors"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/eventtest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/openshift-hyperfleet/hyperfleet-broker/broker"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// redeliveryTolerance absorbs the emulator's NACK-to-redelivery latency and
+// timer granularity when comparing delivery times
+const redeliveryTolerance = 100 * time.Millisecond
+
+// startPubSubEmulator starts an in-process Pub/Sub emulator and points the
+// Pub/Sub client at it. It returns the broker config map for the emulator.
+func startPubSubEmulator(t *testing.T) map[string]string {
+	t.Helper()
+	server := pstest.NewServer()
+	t.Cleanup(func() {
+		if err := server.Close(); err != nil {
+			t.Logf("failed to close the pubsub emulator: %v", err)
+		}
+	})
+	t.Setenv("PUBSUB_EMULATOR_HOST", server.Addr)
+
+	return map[string]string{
+		"broker.type":                                        "googlepubsub",
+		"broker.googlepubsub.project_id":                     "test-project",
+		"broker.googlepubsub.create_topic_if_missing":        "true",
+		"broker.googlepubsub.create_subscription_if_missing": "true",
+		"subscriber.parallelism":                             "1",
+	}
+}
+
+// TestRequeue_PubSubRedeliversAfterDelay verifies that an event whose handler
+// returned a RetryAfterError is redelivered by Pub/Sub no earlier than the delay
+func TestRequeue_PubSubRedeliversAfterDelay(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested time.Duration
+		maxDelay  time.Duration
+		expected  time.Duration
+	}{
+		{name: "requested delay", requested: 2 * time.Second, maxDelay: time.Minute, expected: 2 * time.Second},
+		{name: "capped delay", requested: time.Hour, maxDelay: time.Second, expected: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configMap := startPubSubEmulator(t)
+			log := logger.NewTestLogger()
+			brokerMetrics := broker.NewMetricsRecorder("test", "v0.0.0-test", prometheus.NewRegistry())
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			var mu sync.Mutex
+			var deliveries []time.Time
+			redelivered := make(chan struct{})
+			handler := func(ctx context.Context, evt *event.Event) error {
+				mu.Lock()
+				defer mu.Unlock()
+				deliveries = append(deliveries, time.Now())
+				if len(deliveries) == 1 {
+					return apierrors.NewRetryAfterError(tt.requested, nil)
+				}
+				if len(deliveries) == 2 {
+					close(redelivered)
+				}
+				return nil
+			}
+			requeue := brokerconsumer.Requeue(ctx, brokerconsumer.RequeueConfig{MaxDelay: tt.maxDelay}, log, nil)
+
+			subscriber, err := broker.NewSubscriber(log, "requeue-sub", brokerMetrics, configMap)
+			require.NoError(t, err)
+			defer subscriber.Close() //nolint:errcheck // best-effort cleanup
+			require.NoError(t, subscriber.Subscribe(ctx, "requeue-topic", brokerconsumer.Chain(handler, requeue)))
+
+			publisher, err := broker.NewPublisher(log, brokerMetrics, configMap)
+			require.NoError(t, err)
+			defer publisher.Close() //nolint:errcheck // best-effort cleanup
+			evt := eventtest.NewEvent().WithID("evt-requeue").WithDataJSON(map[string]interface{}{"id": "c1"}).Build()
+			require.NoError(t, publisher.Publish(ctx, "requeue-topic", evt))
+
+			select {
+			case <-redelivered:
+			case <-ctx.Done():
+				t.Fatal("the event was not redelivered")
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			elapsed := deliveries[1].Sub(deliveries[0])
+			assert.GreaterOrEqual(t, elapsed, tt.expected-redeliveryTolerance,
+				"the event was redelivered before the requested delay")
+			assert.Less(t, elapsed, tt.expected+5*time.Second, "the event was redelivered too late")
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/configloader"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/executor"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/eventtest"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/test/integration/testutil"
 	"github.com/stretchr/testify/assert"
@@ -39,22 +40,17 @@ func getK8sEnvForTest(t *testing.T) *K8sTestEnv {
 
 // createTestEvent creates a CloudEvent for testing
 func createTestEvent(clusterID string) *event.Event {
-	evt := event.New()
-	evt.SetID("test-event-" + clusterID)
-	evt.SetType("com.redhat.hyperfleet.cluster.provision")
-	evt.SetSource("test")
-	evt.SetTime(time.Now())
-
-	eventData := map[string]interface{}{
-		"id":            clusterID,
-		"resource_type": "cluster",
-		"generation":    "gen-001",
-		"href":          "/api/v1/clusters/" + clusterID,
-	}
-	eventDataBytes, _ := json.Marshal(eventData)
-	_ = evt.SetData(event.ApplicationJSON, eventDataBytes)
-
-	return &evt
+	return eventtest.NewEvent().
+		WithID("test-event-" + clusterID).
+		WithType("com.redhat.hyperfleet.cluster.provision").
+		WithTime(time.Now()).
+		WithDataJSON(map[string]interface{}{
+			"id":            clusterID,
+			"resource_type": "cluster",
+			"generation":    1,
+			"href":          "/api/v1/clusters/" + clusterID,
+		}).
+		Build()
 }
 
 // createTestConfig creates a unified Config for executor integration tests.
@@ -191,7 +187,7 @@ func TestExecutor_FullFlow_Success(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	result := exec.Execute(ctx, evt)
+	result := exec.ExecuteEvent(ctx, evt)
 
 	// Verify result
 	require.Equal(t, executor.StatusSuccess, result.Status, "Expected success status; errors=%v", result.Errors)
@@ -321,7 +317,7 @@ func TestExecutor_PreconditionNotMet(t *testing.T) {
 	// Execute
 	evt := createTestEvent("cluster-456")
 	ctx := context.Background()
-	result := exec.Execute(ctx, evt)
+	result := exec.ExecuteEvent(ctx, evt)
 
 	// Verify result - should be success with resources skipped (precondition not met is valid outcome)
 	if result.Status != executor.StatusSuccess {
@@ -382,9 +378,9 @@ func TestExecutor_PreconditionNotMet(t *testing.T) {
 				result.ExecutionContext.Adapter.ExecutionStatus)
 		}
 		// No executionError should be set - precondition not matching is not an error
-		if result.ExecutionContext.Adapter.ExecutionError != nil {
+		if result.ExecutionContext.GetExecutionError() != nil {
 			t.Errorf("Expected no executionError for precondition not met, got %+v",
-				result.ExecutionContext.Adapter.ExecutionError)
+				result.ExecutionContext.GetExecutionError())
 		}
 	}
 
@@ -424,7 +420,7 @@ func TestExecutor_PreconditionAPIFailure(t *testing.T) {
 	// Execute
 	evt := createTestEvent("cluster-notfound")
 	ctx := context.Background()
-	result := exec.Execute(ctx, evt)
+	result := exec.ExecuteEvent(ctx, evt)
 
 	// Verify result - should be failed (API error)
 	if result.Status != executor.StatusFailed {
@@ -543,7 +539,7 @@ func TestExecutor_CELExpressionEvaluation(t *testing.T) {
 	// Execute
 	evt := createTestEvent("cluster-cel-test")
 	ctx := context.Background()
-	result := exec.Execute(ctx, evt)
+	result := exec.ExecuteEvent(ctx, evt)
 
 	// Verify CEL evaluation passed
 	require.Equal(t, executor.StatusSuccess, result.Status, "Expected success status; errors=%v", result.Errors)
@@ -592,7 +588,7 @@ func TestExecutor_MultipleMessages(t *testing.T) {
 
 	for i, clusterID := range clusterIDs {
 		evt := createTestEvent(clusterID)
-		results[i] = exec.Execute(context.Background(), evt)
+		results[i] = exec.ExecuteEvent(context.Background(), evt)
 	}
 
 	// Verify all succeeded with isolated params
@@ -729,7 +725,7 @@ func TestExecutor_ContextCancellation(t *testing.T) {
 	cancel() // Cancel immediately
 
 	evt := createTestEvent("cluster-canceled")
-	result := exec.Execute(ctx, evt)
+	result := exec.ExecuteEvent(ctx, evt)
 
 	// Should fail due to context cancellation
 	// Note: The exact behavior depends on where cancellation is checked
@@ -766,7 +762,7 @@ func TestExecutor_MissingRequiredParam(t *testing.T) {
 	}
 
 	evt := createTestEvent("cluster-missing-param")
-	result := exec.Execute(context.Background(), evt)
+	result := exec.ExecuteEvent(context.Background(), evt)
 
 	// Should fail during param extraction
 	if result.Status != executor.StatusFailed {
@@ -827,16 +823,13 @@ func TestExecutor_InvalidEventJSON(t *testing.T) {
 	}
 
 	// Create event with invalid JSON data
-	evt := event.New()
-	evt.SetID("invalid-event-123")
-	evt.SetType("com.redhat.hyperfleet.cluster.provision")
-	evt.SetSource("test")
-
-	// Set malformed JSON data that can't be parsed
-	invalidJSON := []byte("this is not valid JSON {{{")
-	_ = evt.SetData(event.ApplicationJSON, invalidJSON)
+	evt := eventtest.NewEvent().
+		WithID("invalid-event-123").
+		WithType("com.redhat.hyperfleet.cluster.provision").
+		WithDataJSON("this is not valid JSON {{{").
+		Build()
 
-	result := exec.Execute(context.Background(), &evt)
+	result := exec.ExecuteEvent(context.Background(), evt)
 
 	// Should fail during param extraction (JSON parsing)
 	assert.Equal(t, executor.StatusFailed, result.Status, "Should fail with invalid JSON")
@@ -851,7 +844,7 @@ func TestExecutor_InvalidEventJSON(t *testing.T) {
 
 	// Test handler behavior: should ACK (not NACK) invalid events
 	handler := exec.CreateHandler()
-	err = handler(context.Background(), &evt)
+	err = handler(context.Background(), evt)
 	assert.Nil(t, err, "Handler should ACK (return nil) for invalid events, not NACK")
 
 	t.Log("Expected behavior: Invalid event is ACKed (not NACKed), all phases skipped")
@@ -879,18 +872,15 @@ func TestExecutor_MissingEventFields(t *testing.T) {
 	}
 
 	// Create event missing required field (id)
-	evt := event.New()
-	evt.SetID("missing-field-event")
-	evt.SetType("com.redhat.hyperfleet.cluster.provision")
-	evt.SetSource("test")
-
-	eventData := map[string]interface{}{
-		// Missing id (required)
-	}
-	eventDataBytes, _ := json.Marshal(eventData)
-	_ = evt.SetData(event.ApplicationJSON, eventDataBytes)
+	evt := eventtest.NewEvent().
+		WithID("missing-field-event").
+		WithType("com.redhat.hyperfleet.cluster.provision").
+		WithDataJSON(map[string]interface{}{
+			// Missing id (required)
+		}).
+		Build()
 
-	result := exec.Execute(context.Background(), &evt)
+	result := exec.ExecuteEvent(context.Background(), evt)
 
 	// Should fail during param extraction (missing required param from event)
 	assert.Equal(t, executor.StatusFailed, result.Status, "Should fail with missing required field")
@@ -909,7 +899,7 @@ func TestExecutor_MissingEventFields(t *testing.T) {
 
 	// Test handler behavior: should ACK (not NACK) events with missing required fields
 	handler := exec.CreateHandler()
-	errPhase = handler(context.Background(), &evt)
+	errPhase = handler(context.Background(), evt)
 	assert.Nil(t, errPhase, "Handler should ACK (return nil) for missing required fields, not NACK")
 
 	t.Log("Expected behavior: Event with missing required field is ACKed (not NACKed), all phases skipped")
@@ -1025,7 +1015,7 @@ func TestExecutor_LogAction(t *testing.T) {
 
 	// Execute
 	evt := createTestEvent("log-test-clusterx")
-	result := exec.Execute(context.Background(), evt)
+	result := exec.ExecuteEvent(context.Background(), evt)
 
 	// Should succeed
 	if result.Status != executor.StatusSuccess {
@@ -1095,7 +1085,7 @@ func TestExecutor_PostActionAPIFailure(t *testing.T) {
 	// Execute
 	evt := createTestEvent("cluster-post-fail")
 	ctx := context.Background()
-	result := exec.Execute(ctx, evt)
+	result := exec.ExecuteEvent(ctx, evt)
 
 	// Verify result - should be failed due to post action API error
 	assert.Equal(t, executor.StatusFailed, result.Status, "Expected failed status for post action API error")
@@ -1128,9 +1118,9 @@ func TestExecutor_PostActionAPIFailure(t *testing.T) {
 	// Verify ExecutionError was populated in execution context
 	assert.NotNil(t, result.ExecutionContext, "Expected execution context to be set")
 	if result.ExecutionContext != nil {
-		assert.NotNil(t, result.ExecutionContext.Adapter.ExecutionError, "Expected ExecutionError to be populated")
-		if result.ExecutionContext.Adapter.ExecutionError != nil {
-			execErr := result.ExecutionContext.Adapter.ExecutionError
+		assert.NotNil(t, result.ExecutionContext.GetExecutionError(), "Expected ExecutionError to be populated")
+		if result.ExecutionContext.GetExecutionError() != nil {
+			execErr := result.ExecutionContext.GetExecutionError()
 			assert.Equal(t, "post_actions", execErr.Phase, "Expected error in post_actions phase")
 			assert.Equal(t, "reportClusterStatus", execErr.Step, "Expected error in reportClusterStatus step")
 			assert.Contains(t, execErr.Message, "500", "Expected error message to contain 500 status code")
@@ -1280,7 +1270,7 @@ func TestExecutor_ExecutionError_CELAccess(t *testing.T) {
 	// Execute - should fail due to precondition API error
 	evt := createTestEvent("cluster-cel-error-test")
 	ctx := context.Background()
-	result := exec.Execute(ctx, evt)
+	result := exec.ExecuteEvent(ctx, evt)
 
 	// Verify execution failed (due to precondition failure)
 	assert.Equal(t, executor.StatusFailed, result.Status, "Expected failed status")
@@ -1420,7 +1410,7 @@ func TestExecutor_PayloadBuildFailure(t *testing.T) {
 	// Execute
 	evt := createTestEvent("test-cluster")
 	ctx := context.Background()
-	result := exec.Execute(ctx, evt)
+	result := exec.ExecuteEvent(ctx, evt)
 
 	// Verify execution failed in post_actions phase (payload build)
 	assert.Equal(t, executor.StatusFailed, result.Status, "Expected failed status")
@@ -1439,11 +1429,11 @@ func TestExecutor_PayloadBuildFailure(t *testing.T) {
 	// Verify ExecutionError was set
 	assert.NotNil(t, result.ExecutionContext, "Expected execution context")
 	if result.ExecutionContext != nil {
-		assert.NotNil(t, result.ExecutionContext.Adapter.ExecutionError, "Expected ExecutionError to be set")
-		if result.ExecutionContext.Adapter.ExecutionError != nil {
-			assert.Equal(t, "post_actions", result.ExecutionContext.Adapter.ExecutionError.Phase)
-			assert.Equal(t, "build_payloads", result.ExecutionContext.Adapter.ExecutionError.Step)
-			t.Logf("ExecutionError: %+v", result.ExecutionContext.Adapter.ExecutionError)
+		assert.NotNil(t, result.ExecutionContext.GetExecutionError(), "Expected ExecutionError to be set")
+		if result.ExecutionContext.GetExecutionError() != nil {
+			assert.Equal(t, "post_actions", result.ExecutionContext.GetExecutionError().Phase)
+			assert.Equal(t, "build_payloads", result.ExecutionContext.GetExecutionError().Step)
+			t.Logf("ExecutionError: %+v", result.ExecutionContext.GetExecutionError())
 		}
 	}
 
@@ -16,8 +16,10 @@ import (
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/executor"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/hyperfleetapi"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/manifest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/eventtest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
@@ -123,22 +125,18 @@ func (m *k8sTestAPIServer) GetStatusResponses() []map[string]interface{} {
 
 // createK8sTestEvent creates a CloudEvent for K8s integration testing
 func createK8sTestEvent(clusterID string) *event.Event {
-	evt := event.New()
-	evt.SetID("k8s-test-event-" + clusterID)
-	evt.SetType("com.redhat.hyperfleet.cluster.provision")
-	evt.SetSource("k8s-integration-test")
-	evt.SetTime(time.Now())
-
-	eventData := map[string]interface{}{
-		"id":            clusterID,
-		"resource_type": "cluster",
-		"generation":    "gen-001",
-		"href":          "/api/v1/clusters/" + clusterID,
-	}
-	eventDataBytes, _ := json.Marshal(eventData)
-	_ = evt.SetData(event.ApplicationJSON, eventDataBytes)
-
-	return &evt
+	return eventtest.NewEvent().
+		WithID("k8s-test-event-" + clusterID).
+		WithType("com.redhat.hyperfleet.cluster.provision").
+		WithSource("k8s-integration-test").
+		WithTime(time.Now()).
+		WithDataJSON(map[string]interface{}{
+			"id":            clusterID,
+			"resource_type": "cluster",
+			"generation":    1,
+			"href":          "/api/v1/clusters/" + clusterID,
+		}).
+		Build()
 }
 
 // createK8sTestConfig creates a unified Config with K8s resources
@@ -352,7 +350,7 @@ func TestExecutor_K8s_CreateResources(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 
-	result := exec.Execute(ctx, evt)
+	result := exec.ExecuteEvent(ctx, evt)
 
 	// Verify execution succeeded
 	if result.Status != executor.StatusSuccess {
@@ -500,7 +498,7 @@ func TestExecutor_K8s_UpdateExistingResource(t *testing.T) {
 
 	// Execute - should update existing resource
 	evt := createK8sTestEvent(clusterID)
-	result := exec.Execute(ctx, evt)
+	result := exec.ExecuteEvent(ctx, evt)
 
 	require.Equal(t, executor.StatusSuccess, result.Status, "Execution should succeed: errors=%v", result.Errors)
 
@@ -609,14 +607,14 @@ func TestExecutor_K8s_DiscoveryByLabels(t *testing.T) {
 
 	// First execution - should create
 	evt := createK8sTestEvent(clusterID)
-	result1 := exec.Execute(ctx, evt)
+	result1 := exec.ExecuteEvent(ctx, evt)
 	require.Equal(t, executor.StatusSuccess, result1.Status)
 	assert.Equal(t, manifest.OperationCreate, result1.ResourceResults[0].Operation)
 	t.Logf("First execution: %s", result1.ResourceResults[0].Operation)
 
 	// Second execution - should find by labels and update
 	evt2 := createK8sTestEvent(clusterID)
-	result2 := exec.Execute(ctx, evt2)
+	result2 := exec.ExecuteEvent(ctx, evt2)
 	require.Equal(t, executor.StatusSuccess, result2.Status)
 	assert.Equal(t, manifest.OperationUpdate, result2.ResourceResults[0].Operation)
 	t.Logf("Second execution: %s (discovered by labels)", result2.ResourceResults[0].Operation)
@@ -680,7 +678,7 @@ func TestExecutor_K8s_RecreateOnChange(t *testing.T) {
 
 	// First execution - create
 	evt := createK8sTestEvent(clusterID)
-	result1 := exec.Execute(ctx, evt)
+	result1 := exec.ExecuteEvent(ctx, evt)
 	require.Equal(t, executor.StatusSuccess, result1.Status)
 	assert.Equal(t, manifest.OperationCreate, result1.ResourceResults[0].Operation)
 
@@ -694,7 +692,7 @@ func TestExecutor_K8s_RecreateOnChange(t *testing.T) {
 
 	// Second execution - should recreate (delete + create)
 	evt2 := createK8sTestEvent(clusterID)
-	result2 := exec.Execute(ctx, evt2)
+	result2 := exec.ExecuteEvent(ctx, evt2)
 	require.Equal(t, executor.StatusSuccess, result2.Status)
 	assert.Equal(t, manifest.OperationRecreate, result2.ResourceResults[0].Operation)
 	t.Logf("Second execution: %s", result2.ResourceResults[0].Operation)
@@ -737,7 +735,7 @@ func TestExecutor_K8s_MultipleResourceTypes(t *testing.T) {
 	clusterID := fmt.Sprintf("multi-cluster-%d", time.Now().UnixNano())
 	evt := createK8sTestEvent(clusterID)
 
-	result := exec.Execute(context.Background(), evt)
+	result := exec.ExecuteEvent(context.Background(), evt)
 
 	require.Equal(t, executor.StatusSuccess, result.Status)
 	require.Len(t, result.ResourceResults, 2)
@@ -783,7 +781,7 @@ func TestExecutor_K8s_ResourceCreationFailure(t *testing.T) {
 	require.NoError(t, err)
 
 	evt := createK8sTestEvent("failure-test")
-	result := exec.Execute(context.Background(), evt)
+	result := exec.ExecuteEvent(context.Background(), evt)
 
 	// Should fail during resource creation
 	assert.Equal(t, executor.StatusFailed, result.Status)
@@ -931,7 +929,7 @@ func TestExecutor_K8s_MultipleMatchingResources(t *testing.T) {
 	require.NoError(t, err)
 
 	evt := createK8sTestEvent(clusterID)
-	result := exec.Execute(ctx, evt)
+	result := exec.ExecuteEvent(ctx, evt)
 
 	require.Equal(t, executor.StatusSuccess, result.Status, "Execution should succeed: errors=%v", result.Errors)
 
@@ -1008,7 +1006,7 @@ func TestExecutor_K8s_PostActionsAfterPreconditionNotMet(t *testing.T) {
 	clusterID := fmt.Sprintf("precond-fail-%d", time.Now().UnixNano())
 	evt := createK8sTestEvent(clusterID)
 
-	result := exec.Execute(context.Background(), evt)
+	result := exec.ExecuteEvent(context.Background(), evt)
 
 	// Should be success with resources skipped (precondition not met is valid outcome)
 	assert.Equal(t, executor.StatusSuccess, result.Status, "Should be success when precondition not met (valid outcome)")
@@ -1053,3 +1051,196 @@ func TestExecutor_K8s_PostActionsAfterPreconditionNotMet(t *testing.T) {
 		}
 	}
 }
+
+// TestExecutor_K8s_Finalizer tests that the adapter keeps its finalizer on the
+// source object until the teardown workflow applied its resources
+func TestExecutor_K8s_Finalizer(t *testing.T) {
+	k8sEnv := SetupK8sTestEnv(t)
+	defer k8sEnv.Cleanup(t)
+
+	testNamespace := fmt.Sprintf("executor-finalizer-%d", time.Now().Unix())
+	k8sEnv.CreateTestNamespace(t, testNamespace)
+	defer k8sEnv.CleanupTestNamespace(t, testNamespace)
+
+	const finalizer = "hyperfleet.io/k8s-test-adapter"
+	clusterID := fmt.Sprintf("cluster-%d", time.Now().UnixNano())
+	cmGVK := schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"}
+	configMap := func(name string) []configloader.Resource {
+		return []configloader.Resource{{
+			Name: name + "ConfigMap",
+			Manifest: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name":      name + "-{{ .clusterID }}",
+					"namespace": testNamespace,
+				},
+			},
+		}}
+	}
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "k8s-test-adapter", Version: "1.0.0"},
+		Params: []configloader.Parameter{
+			{Name: "clusterID", Source: "event.id", Required: true},
+		},
+		// The source object is a ConfigMap standing in for the cluster CR
+		Finalizer: &configloader.Finalizer{
+			Name: finalizer,
+			On: configloader.ObjectRef{
+				APIVersion: "v1", Kind: "ConfigMap", Namespace: testNamespace, Name: "source-{{ .clusterID }}",
+			},
+			TeardownWorkflow: "teardown",
+		},
+		Workflows: []configloader.Workflow{
+			{
+				Name:      "teardown",
+				Match:     &configloader.WorkflowMatch{EventTypes: []string{"com.redhat.hyperfleet.cluster.deleted"}},
+				Resources: configMap("teardown"),
+			},
+			{Name: "reconcile", Resources: configMap("reconcile")},
+		},
+	}
+	apiClient, err := hyperfleetapi.NewClient(testLog())
+	require.NoError(t, err)
+	exec, err := executor.NewBuilder().
+		WithConfig(config).
+		WithAPIClient(apiClient).
+		WithTransportClient(k8sEnv.Client).
+		WithLogger(k8sEnv.Log).
+		Build()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	source := &unstructured.Unstructured{}
+	source.SetGroupVersionKind(cmGVK)
+	source.SetNamespace(testNamespace)
+	source.SetName("source-" + clusterID)
+	_, err = k8sEnv.Client.CreateResource(ctx, source)
+	require.NoError(t, err)
+
+	// The reconcile workflow adds the finalizer before applying its resources
+	result := exec.ExecuteEvent(ctx, createK8sTestEvent(clusterID))
+	require.Equal(t, executor.StatusSuccess, result.Status, "errors: %v", result.Errors)
+	require.Len(t, result.ResourceResults, 2)
+	assert.Equal(t, executor.OperationAddFinalizer, result.ResourceResults[0].Operation)
+	assert.Equal(t, manifest.OperationCreate, result.ResourceResults[1].Operation)
+
+	obj, err := k8sEnv.Client.GetResource(ctx, cmGVK, testNamespace, "source-"+clusterID, nil)
+	require.NoError(t, err)
+	assert.Contains(t, obj.GetFinalizers(), finalizer)
+
+	// Deleting the source object leaves it pending on the finalizer
+	require.NoError(t, k8sEnv.Client.DeleteResource(ctx, cmGVK, testNamespace, "source-"+clusterID))
+	obj, err = k8sEnv.Client.GetResource(ctx, cmGVK, testNamespace, "source-"+clusterID, nil)
+	require.NoError(t, err, "the finalizer should keep the source object")
+	assert.NotNil(t, obj.GetDeletionTimestamp())
+
+	// Another reconcile does not add the finalizer back
+	result = exec.ExecuteEvent(ctx, createK8sTestEvent(clusterID))
+	require.Equal(t, executor.StatusSuccess, result.Status, "errors: %v", result.Errors)
+	assert.Equal(t, manifest.OperationSkip, result.ResourceResults[0].Operation)
+
+	// The teardown workflow removes the finalizer after applying its resources
+	teardown := eventtest.NewEvent().
+		WithID("k8s-test-teardown-" + clusterID).
+		WithType("com.redhat.hyperfleet.cluster.deleted").
+		WithSource("k8s-integration-test").
+		WithDataJSON(map[string]interface{}{"id": clusterID}).
+		Build()
+	result = exec.ExecuteEvent(ctx, teardown)
+	require.Equal(t, executor.StatusSuccess, result.Status, "errors: %v", result.Errors)
+	require.Len(t, result.ResourceResults, 2)
+	assert.Equal(t, manifest.OperationCreate, result.ResourceResults[0].Operation)
+	assert.Equal(t, executor.OperationRemoveFinalizer, result.ResourceResults[1].Operation)
+
+	_, err = k8sEnv.Client.GetResource(ctx, cmGVK, testNamespace, "teardown-"+clusterID, nil)
+	require.NoError(t, err, "the teardown resources should be applied")
+	require.Eventually(t, func() bool {
+		_, err := k8sEnv.Client.GetResource(ctx, cmGVK, testNamespace, "source-"+clusterID, nil)
+		return apierrors.IsNotFound(err)
+	}, 10*time.Second, 100*time.Millisecond, "the source object should be deleted once the finalizer is removed")
+}
+
+// TestExecutor_K8s_FieldRefParams tests params read from a field of a Kubernetes object
+func TestExecutor_K8s_FieldRefParams(t *testing.T) {
+	k8sEnv := SetupK8sTestEnv(t)
+	defer k8sEnv.Cleanup(t)
+
+	testNamespace := fmt.Sprintf("executor-fieldref-%d", time.Now().Unix())
+	k8sEnv.CreateTestNamespace(t, testNamespace)
+	defer k8sEnv.CleanupTestNamespace(t, testNamespace)
+
+	clusterID := fmt.Sprintf("cluster-%d", time.Now().UnixNano())
+	cmGVK := schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"}
+	fieldRef := func(name, fieldPath string) *configloader.ParamValueFrom {
+		return &configloader.ParamValueFrom{K8sFieldRef: &configloader.K8sFieldRef{
+			ObjectRef: configloader.ObjectRef{APIVersion: "v1", Kind: "ConfigMap", Namespace: testNamespace, Name: name},
+			FieldPath: fieldPath,
+		}}
+	}
+	config := &configloader.Config{
+		Adapter: configloader.AdapterInfo{Name: "k8s-test-adapter", Version: "1.0.0"},
+		Params: []configloader.Parameter{
+			{Name: "clusterID", Source: "event.id", Required: true},
+			// The source ConfigMap stands in for a ClusterDeployment
+			{Name: "infraID", ValueFrom: fieldRef("source-{{ .clusterID }}", "data.infraID"), Required: true},
+			{Name: "region", ValueFrom: fieldRef("source-{{ .clusterID }}", "{.data.region}"), Default: "us-east-1"},
+			{Name: "pullSecret", ValueFrom: fieldRef("missing-{{ .clusterID }}", "data.name"), Default: "none"},
+		},
+		Resources: []configloader.Resource{{
+			Name: "infraConfigMap",
+			Manifest: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name":      "infra-{{ .clusterID }}",
+					"namespace": testNamespace,
+				},
+				"data": map[string]interface{}{
+					"infraID":    "{{ .infraID }}",
+					"region":     "{{ .region }}",
+					"pullSecret": "{{ .pullSecret }}",
+				},
+			},
+		}},
+	}
+	apiClient, err := hyperfleetapi.NewClient(testLog())
+	require.NoError(t, err)
+	exec, err := executor.NewBuilder().
+		WithConfig(config).
+		WithAPIClient(apiClient).
+		WithTransportClient(k8sEnv.Client).
+		WithLogger(k8sEnv.Log).
+		Build()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	// Without the source object the required param is missing
+	result := exec.ExecuteEvent(ctx, createK8sTestEvent(clusterID))
+	require.Equal(t, executor.StatusFailed, result.Status)
+	assert.Equal(t, executor.ErrorCodeParamMissing, executor.ErrorCodeOf(result.Errors[executor.PhaseParamExtraction]))
+
+	source := &unstructured.Unstructured{}
+	source.SetGroupVersionKind(cmGVK)
+	source.SetNamespace(testNamespace)
+	source.SetName("source-" + clusterID)
+	require.NoError(t, unstructured.SetNestedStringMap(source.Object,
+		map[string]string{"infraID": clusterID + "-x7k2p", "region": "eu-west-1"}, "data"))
+	_, err = k8sEnv.Client.CreateResource(ctx, source)
+	require.NoError(t, err)
+
+	result = exec.ExecuteEvent(ctx, createK8sTestEvent(clusterID))
+	require.Equal(t, executor.StatusSuccess, result.Status, "errors: %v", result.Errors)
+
+	obj, err := k8sEnv.Client.GetResource(ctx, cmGVK, testNamespace, "infra-"+clusterID, nil)
+	require.NoError(t, err)
+	data, _, err := unstructured.NestedStringMap(obj.Object, "data")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"infraID": clusterID + "-x7k2p", "region": "eu-west-1", "pullSecret": "none",
+	}, data)
+}
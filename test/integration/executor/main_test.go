@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/test/integration/testutil"
@@ -197,10 +198,11 @@ func waitForAPIServerReady(config *rest.Config, timeout time.Duration) error {
 	}
 
 	healthURL := config.Host + "/healthz"
-	deadline := time.Now().Add(timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	for time.Now().Before(deadline) {
-		req, err := http.NewRequest(http.MethodGet, healthURL, nil)
+	for ctx.Err() == nil {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL, nil)
 		if err != nil {
 			return fmt.Errorf("failed to create request: %w", err)
 		}
@@ -214,7 +216,9 @@ func waitForAPIServerReady(config *rest.Config, timeout time.Duration) error {
 			}
 		}
 
-		time.Sleep(500 * time.Millisecond)
+		if clock.Real.Sleep(ctx, 500*time.Millisecond) != nil {
+			break
+		}
 	}
 
 	return fmt.Errorf("timeout waiting for API server to be ready")
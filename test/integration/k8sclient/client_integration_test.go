@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -40,6 +41,36 @@ func TestIntegration_NewClient(t *testing.T) {
 	})
 }
 
+// TestIntegration_Ping tests the API server readiness check
+func TestIntegration_Ping(t *testing.T) {
+	env := GetSharedEnv(t)
+
+	t.Run("reachable API server with valid credentials", func(t *testing.T) {
+		require.NoError(t, env.GetClient().Ping(env.GetContext()))
+	})
+}
+
+// TestIntegration_CheckAccess tests the RBAC access reviews of self-test
+func TestIntegration_CheckAccess(t *testing.T) {
+	env := GetSharedEnv(t)
+
+	t.Run("allowed verbs", func(t *testing.T) {
+		err := env.GetClient().CheckAccess(env.GetContext(), []k8sclient.AccessCheck{
+			{GVK: gvk.Namespace, Verb: "create"},
+			{GVK: gvk.ConfigMap, Namespace: "default", Verb: "update"},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("kind not served", func(t *testing.T) {
+		err := env.GetClient().CheckAccess(env.GetContext(), []k8sclient.AccessCheck{
+			{GVK: schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}, Verb: "get"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not served")
+	})
+}
+
 // TestIntegration_CreateResource tests creating resources in K8s
 func TestIntegration_CreateResource(t *testing.T) {
 	env := GetSharedEnv(t)
@@ -376,7 +407,7 @@ func TestIntegration_PatchResource(t *testing.T) {
 			}
 		}`)
 
-		patched, err := env.GetClient().PatchResource(env.GetContext(), gvk.ConfigMap, "default", cmName, patchData)
+		patched, err := env.GetClient().PatchResource(env.GetContext(), gvk.ConfigMap, "default", cmName, patchData, nil)
 		require.NoError(t, err)
 		require.NotNil(t, patched)
 
@@ -423,7 +454,7 @@ func TestIntegration_PatchResource(t *testing.T) {
 			}
 		}`)
 
-		patched, err := env.GetClient().PatchResource(env.GetContext(), gvk.ConfigMap, "default", cmName, patchData)
+		patched, err := env.GetClient().PatchResource(env.GetContext(), gvk.ConfigMap, "default", cmName, patchData, nil)
 		require.NoError(t, err)
 
 		data, _, _ := unstructured.NestedStringMap(patched.Object, "data")
@@ -434,7 +465,7 @@ func TestIntegration_PatchResource(t *testing.T) {
 	t.Run("patch non-existent resource returns error", func(t *testing.T) {
 		patchData := []byte(`{"data": {"key": "value"}}`)
 		_, err := env.GetClient().PatchResource(
-			env.GetContext(), gvk.ConfigMap, "default", "non-existent-cm-12345", patchData,
+			env.GetContext(), gvk.ConfigMap, "default", "non-existent-cm-12345", patchData, nil,
 		)
 		require.Error(t, err)
 		assert.True(t, k8serrors.IsNotFound(err), "Should return NotFound error")
@@ -461,7 +492,7 @@ func TestIntegration_PatchResource(t *testing.T) {
 
 		// Try to patch with invalid JSON
 		invalidPatchData := []byte(`{invalid json}`)
-		_, err = env.GetClient().PatchResource(env.GetContext(), gvk.ConfigMap, "default", cmName, invalidPatchData)
+		_, err = env.GetClient().PatchResource(env.GetContext(), gvk.ConfigMap, "default", cmName, invalidPatchData, nil)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid patch data", "Should return invalid patch data error")
 	})
@@ -17,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
 
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/k8sclient"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
 	"github.com/openshift-hyperfleet/hyperfleet-adapter/test/integration/testutil"
@@ -47,14 +48,15 @@ func waitForAPIServerReady(kubeAPIServer string, timeout time.Duration) error {
 	}
 
 	healthURL := kubeAPIServer + "/healthz"
-	deadline := time.Now().Add(timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 	backoff := 500 * time.Millisecond
 
 	var lastErr error
 	var lastStatus int
 
-	for time.Now().Before(deadline) {
-		req, err := http.NewRequest(http.MethodGet, healthURL, nil)
+	for ctx.Err() == nil {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL, nil)
 		if err != nil {
 			return fmt.Errorf("failed to create request: %w", err)
 		}
@@ -74,7 +76,9 @@ func waitForAPIServerReady(kubeAPIServer string, timeout time.Duration) error {
 			}
 		}
 
-		time.Sleep(backoff)
+		if clock.Real.Sleep(ctx, backoff) != nil {
+			break
+		}
 	}
 
 	if lastErr != nil {
@@ -0,0 +1,718 @@
+//go:build integration
+
+// Package maestro provides an in-process mock of a Maestro server for transport integration tests.
+//
+// The mock serves the two APIs the maestro client talks to:
+//   - the CloudEvents-over-gRPC service, which receives ManifestWork create, update and delete
+//     requests and streams status updates back to the subscribed sources
+//   - the REST API, from which the client reads the resource bundles (stored ManifestWorks)
+//
+// It records every received work, can fail or delay publishes on request, and can emit status
+// updates, so the wire path of the client can be exercised without a Maestro deployment.
+package maestro
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cetypes "github.com/cloudevents/sdk-go/v2/types"
+	"github.com/google/uuid"
+	"github.com/openshift-online/maestro/pkg/api/openapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"open-cluster-management.io/sdk-go/pkg/cloudevents/clients/work/payload"
+	pbv1 "open-cluster-management.io/sdk-go/pkg/cloudevents/generic/options/grpc/protobuf/v1"
+	"open-cluster-management.io/sdk-go/pkg/cloudevents/generic/options/grpc/protocol"
+	"open-cluster-management.io/sdk-go/pkg/cloudevents/generic/types"
+)
+
+const (
+	// DefaultHeartbeatInterval is how often the server sends heartbeats on subscription streams.
+	// The maestro client reconnects when it sees no heartbeat within its server healthiness timeout.
+	DefaultHeartbeatInterval = time.Second
+
+	// resourceBundlesPath and consumersPath are the REST API paths used by the maestro client
+	resourceBundlesPath = "/api/maestro/v1/resource-bundles"
+	consumersPath       = "/api/maestro/v1/consumers"
+
+	// statusSource is the CloudEvents source of the status updates sent by the server
+	statusSource = "maestro-mock"
+)
+
+// consumerNotFoundMessage mimics the error Maestro returns for a work addressed to an
+// unregistered consumer, a foreign-key violation the maestro client recognizes
+const consumerNotFoundMessage = `insert or update on table "resources" violates foreign key constraint ` +
+	`"fk_resources_consumers": consumer %q does not exist`
+
+var (
+	searchSourcePattern   = regexp.MustCompile(`source='([^']*)'`)
+	searchConsumerPattern = regexp.MustCompile(`consumer_name='([^']*)'`)
+	searchLabelsPattern   = regexp.MustCompile(`payload->'metadata'->'labels'@>'(\{[^']*\})'`)
+)
+
+// ReceivedWork is a ManifestWork request received over gRPC
+type ReceivedWork struct {
+	// Work is the ManifestWork decoded from the request; deletes carry no spec
+	Work *workv1.ManifestWork
+	// Action is the request action: create_request, update_request or delete_request
+	Action types.EventAction
+	// Source is the source ID of the client that published the request
+	Source string
+	// ResourceID is the Maestro resource ID the client derived from the work
+	ResourceID string
+	// ResourceVersion is the resource version the client sent with the request
+	ResourceVersion int64
+}
+
+// resourceBundle is a stored ManifestWork
+type resourceBundle struct {
+	createdAt time.Time
+	updatedAt time.Time
+	status    *payload.ManifestBundleStatus
+	spec      payload.ManifestBundle
+	meta      metav1.ObjectMeta
+	id        string
+	source    string
+	consumer  string
+	version   int32
+}
+
+// subscriber is an open Subscribe stream
+type subscriber struct {
+	events chan *pbv1.CloudEvent
+	source string
+}
+
+// Server is an in-process mock Maestro server.
+// Stored works survive Restart, so clients can be tested across a gRPC server restart.
+type Server struct {
+	pbv1.UnimplementedCloudEventServiceServer
+
+	grpcServer  *grpc.Server
+	httpServer  *httptest.Server
+	bundles     map[string]*resourceBundle
+	consumers   map[string]bool
+	subscribers map[*subscriber]struct{}
+	grpcAddr    string
+	received    []ReceivedWork
+	// publishErrors are returned by the next publishes, one per publish
+	publishErrors     []error
+	publishDelay      time.Duration
+	heartbeatInterval time.Duration
+	mu                sync.Mutex
+}
+
+// NewServer starts a mock Maestro server with the given consumers registered.
+// Works addressed to other consumers are rejected like Maestro rejects them.
+func NewServer(consumers ...string) (*Server, error) {
+	s := &Server{
+		bundles:           make(map[string]*resourceBundle),
+		consumers:         make(map[string]bool),
+		subscribers:       make(map[*subscriber]struct{}),
+		heartbeatInterval: DefaultHeartbeatInterval,
+	}
+	for _, consumer := range consumers {
+		s.consumers[consumer] = true
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for gRPC: %w", err)
+	}
+	s.grpcAddr = listener.Addr().String()
+	s.serveGRPC(listener)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(resourceBundlesPath, s.handleListResourceBundles)
+	mux.HandleFunc(resourceBundlesPath+"/", s.handleGetResourceBundle)
+	mux.HandleFunc(consumersPath, s.handleListConsumers)
+	s.httpServer = httptest.NewServer(mux)
+
+	return s, nil
+}
+
+// GRPCAddr returns the host:port of the CloudEvents gRPC service
+func (s *Server) GRPCAddr() string {
+	return s.grpcAddr
+}
+
+// APIURL returns the base URL of the REST API
+func (s *Server) APIURL() string {
+	return s.httpServer.URL
+}
+
+// Close stops both servers
+func (s *Server) Close() {
+	s.Stop()
+	s.httpServer.Close()
+}
+
+// Stop stops the gRPC server, closing all subscription streams.
+// The REST API keeps serving the stored works.
+func (s *Server) Stop() {
+	s.mu.Lock()
+	grpcServer := s.grpcServer
+	s.grpcServer = nil
+	s.mu.Unlock()
+	if grpcServer != nil {
+		grpcServer.Stop()
+	}
+}
+
+// Restart stops the gRPC server and starts it again on the same address
+func (s *Server) Restart() error {
+	s.Stop()
+	listener, err := net.Listen("tcp", s.grpcAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for gRPC on %s: %w", s.grpcAddr, err)
+	}
+	s.serveGRPC(listener)
+	return nil
+}
+
+// serveGRPC serves the CloudEvents service on listener
+func (s *Server) serveGRPC(listener net.Listener) {
+	grpcServer := grpc.NewServer()
+	pbv1.RegisterCloudEventServiceServer(grpcServer, s)
+	s.mu.Lock()
+	s.grpcServer = grpcServer
+	s.mu.Unlock()
+	go grpcServer.Serve(listener) //nolint:errcheck // Serve returns once the server is stopped
+}
+
+// AddConsumer registers a consumer (target cluster)
+func (s *Server) AddConsumer(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.consumers[name] = true
+}
+
+// FailNextPublish makes the next publish fail with err; successive calls queue
+// errors for successive publishes. A gRPC status error reaches the client with its
+// code, any other error as codes.Unknown.
+func (s *Server) FailNextPublish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.publishErrors = append(s.publishErrors, err)
+}
+
+// SetPublishDelay delays every publish by d before it is processed; zero disables the delay
+func (s *Server) SetPublishDelay(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.publishDelay = d
+}
+
+// SetHeartbeatInterval sets the heartbeat interval of subscription streams opened afterwards
+func (s *Server) SetHeartbeatInterval(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.heartbeatInterval = d
+}
+
+// Received returns the ManifestWork requests received so far, in order
+func (s *Server) Received() []ReceivedWork {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	received := make([]ReceivedWork, len(s.received))
+	copy(received, s.received)
+	return received
+}
+
+// Subscribers returns the number of open subscription streams
+func (s *Server) Subscribers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers)
+}
+
+// Work returns the stored ManifestWork name of consumer, as the REST API serves it
+func (s *Server) Work(consumer, name string) (*workv1.ManifestWork, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rb := s.findBundle(consumer, name)
+	if rb == nil {
+		return nil, false
+	}
+	return rb.toWork(), true
+}
+
+// SetStatus sets the status of the stored ManifestWork name of consumer, as an agent
+// reporting back would, and sends the status update to the subscribed sources
+func (s *Server) SetStatus(consumer, name string, workStatus workv1.ManifestWorkStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rb := s.findBundle(consumer, name)
+	if rb == nil {
+		return fmt.Errorf("ManifestWork %s/%s not found", consumer, name)
+	}
+
+	spec := rb.spec
+	rb.status = &payload.ManifestBundleStatus{
+		Conditions:     workStatus.Conditions,
+		ResourceStatus: workStatus.ResourceStatus.Manifests,
+		ManifestBundle: &spec,
+	}
+	rb.updatedAt = time.Now()
+
+	evt, err := rb.statusEvent()
+	if err != nil {
+		return err
+	}
+	for sub := range s.subscribers {
+		if sub.source != rb.source {
+			continue
+		}
+		select {
+		case sub.events <- evt:
+		default:
+			return fmt.Errorf("subscriber of source %s is not receiving", sub.source)
+		}
+	}
+	return nil
+}
+
+// findBundle returns the stored work name of consumer; callers hold s.mu
+func (s *Server) findBundle(consumer, name string) *resourceBundle {
+	for _, rb := range s.bundles {
+		if rb.consumer == consumer && rb.meta.Name == name {
+			return rb
+		}
+	}
+	return nil
+}
+
+// Publish implements the CloudEvents service: it stores the ManifestWork carried by the event
+func (s *Server) Publish(ctx context.Context, req *pbv1.PublishRequest) (*emptypb.Empty, error) {
+	s.mu.Lock()
+	delay := s.publishDelay
+	var scripted error
+	if len(s.publishErrors) > 0 {
+		scripted = s.publishErrors[0]
+		s.publishErrors = s.publishErrors[1:]
+	}
+	s.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	if scripted != nil {
+		return nil, scripted
+	}
+
+	evt, err := binding.ToEvent(ctx, protocol.NewMessage(req.GetEvent()))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid cloudevent: %v", err)
+	}
+	eventType, err := types.ParseCloudEventsType(evt.Type())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid cloudevent type: %v", err)
+	}
+	if eventType.CloudEventsDataType != payload.ManifestBundleEventDataType ||
+		eventType.SubResource != types.SubResourceSpec {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported cloudevent type %s", evt.Type())
+	}
+
+	extensions := evt.Extensions()
+	resourceID, err := cetypes.ToString(extensions[types.ExtensionResourceID])
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid %s extension: %v", types.ExtensionResourceID, err)
+	}
+	consumer, err := cetypes.ToString(extensions[types.ExtensionClusterName])
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid %s extension: %v", types.ExtensionClusterName, err)
+	}
+	resourceVersion, err := cetypes.ToInteger(extensions[types.ExtensionResourceVersion])
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid %s extension: %v",
+			types.ExtensionResourceVersion, err)
+	}
+	metaJSON, err := cetypes.ToString(extensions[types.ExtensionWorkMeta])
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid %s extension: %v", types.ExtensionWorkMeta, err)
+	}
+	var meta metav1.ObjectMeta
+	if err := json.Unmarshal([]byte(metaJSON), &meta); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid work metadata: %v", err)
+	}
+	var spec payload.ManifestBundle
+	if eventType.Action != types.DeleteRequestAction {
+		if err := evt.DataAs(&spec); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid manifest bundle: %v", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.consumers[consumer] {
+		return nil, status.Errorf(codes.FailedPrecondition, consumerNotFoundMessage, consumer)
+	}
+
+	now := time.Now()
+	rb, exists := s.bundles[resourceID]
+	switch eventType.Action {
+	case types.CreateRequestAction:
+		if exists {
+			return nil, status.Errorf(codes.AlreadyExists, "resource %s already exists", resourceID)
+		}
+		rb = &resourceBundle{
+			id:        resourceID,
+			source:    evt.Source(),
+			consumer:  consumer,
+			version:   1,
+			createdAt: now,
+		}
+		s.bundles[resourceID] = rb
+	case types.UpdateRequestAction:
+		if !exists {
+			return nil, status.Errorf(codes.NotFound, "resource %s not found", resourceID)
+		}
+		rb.version++
+	case types.DeleteRequestAction:
+		if !exists {
+			return nil, status.Errorf(codes.NotFound, "resource %s not found", resourceID)
+		}
+		delete(s.bundles, resourceID)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported action %s", eventType.Action)
+	}
+	rb.meta = meta
+	rb.spec = spec
+	rb.updatedAt = now
+
+	work := &workv1.ManifestWork{ObjectMeta: meta}
+	work.Namespace = consumer
+	work.Spec.Workload.Manifests = spec.Manifests
+	work.Spec.DeleteOption = spec.DeleteOption
+	work.Spec.ManifestConfigs = spec.ManifestConfigs
+	work.Spec.Executor = spec.Executer
+	s.received = append(s.received, ReceivedWork{
+		Work:            work,
+		Action:          eventType.Action,
+		Source:          evt.Source(),
+		ResourceID:      resourceID,
+		ResourceVersion: int64(resourceVersion),
+	})
+	return &emptypb.Empty{}, nil
+}
+
+// Subscribe implements the CloudEvents service: it streams the status updates of the
+// works of the subscribed source, with heartbeats, until the client or the server goes away
+func (s *Server) Subscribe(req *pbv1.SubscriptionRequest, stream pbv1.CloudEventService_SubscribeServer) error {
+	if req.GetSource() == "" {
+		return status.Error(codes.InvalidArgument, "only source subscriptions are supported")
+	}
+
+	sub := &subscriber{source: req.GetSource(), events: make(chan *pbv1.CloudEvent, 100)}
+	s.mu.Lock()
+	s.subscribers[sub] = struct{}{}
+	interval := s.heartbeatInterval
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, sub)
+		s.mu.Unlock()
+	}()
+
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+	for {
+		var evt *pbv1.CloudEvent
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case evt = <-sub.events:
+		case <-heartbeat.C:
+			evt = &pbv1.CloudEvent{
+				SpecVersion: "1.0",
+				Id:          uuid.NewString(),
+				Type:        types.HeartbeatCloudEventsType,
+			}
+		}
+		if err := stream.Send(evt); err != nil {
+			return err
+		}
+	}
+}
+
+// toWork converts the stored work to the ManifestWork the maestro client reads
+func (rb *resourceBundle) toWork() *workv1.ManifestWork {
+	work := &workv1.ManifestWork{ObjectMeta: *rb.meta.DeepCopy()}
+	work.Namespace = rb.consumer
+	work.Generation = int64(rb.version)
+	work.ResourceVersion = strconv.Itoa(int(rb.version))
+	work.Spec.Workload.Manifests = rb.spec.Manifests
+	work.Spec.DeleteOption = rb.spec.DeleteOption
+	work.Spec.ManifestConfigs = rb.spec.ManifestConfigs
+	work.Spec.Executor = rb.spec.Executer
+	if rb.status != nil {
+		work.Status.Conditions = rb.status.Conditions
+		work.Status.ResourceStatus.Manifests = rb.status.ResourceStatus
+	}
+	return work
+}
+
+// statusEvent builds the status update event of the stored work
+func (rb *resourceBundle) statusEvent() (*pbv1.CloudEvent, error) {
+	eventType := types.CloudEventsType{
+		CloudEventsDataType: payload.ManifestBundleEventDataType,
+		SubResource:         types.SubResourceStatus,
+		Action:              types.UpdateRequestAction,
+	}
+	evt := types.NewEventBuilder(statusSource, eventType).
+		WithResourceID(rb.id).
+		WithResourceVersion(int64(rb.version)).
+		WithStatusUpdateSequenceID(uuid.NewString()).
+		WithClusterName(rb.consumer).
+		NewEvent()
+
+	meta := rb.meta.DeepCopy()
+	meta.Namespace = rb.consumer
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	evt.SetExtension(types.ExtensionWorkMeta, string(metaJSON))
+	if err := evt.SetData("application/json", rb.status); err != nil {
+		return nil, err
+	}
+
+	pbEvt := &pbv1.CloudEvent{}
+	if err := protocol.WritePBMessage(context.Background(), binding.ToMessage(&evt), pbEvt); err != nil {
+		return nil, err
+	}
+	return pbEvt, nil
+}
+
+// toResourceBundle converts the stored work to its REST representation
+func (rb *resourceBundle) toResourceBundle() (*openapi.ResourceBundle, error) {
+	var metadata map[string]interface{}
+	meta := rb.meta.DeepCopy()
+	meta.Namespace = rb.consumer
+	if err := convert(meta, &metadata); err != nil {
+		return nil, err
+	}
+	var manifests []map[string]interface{}
+	if err := convert(rb.spec.Manifests, &manifests); err != nil {
+		return nil, err
+	}
+	var deleteOption map[string]interface{}
+	if err := convert(rb.spec.DeleteOption, &deleteOption); err != nil {
+		return nil, err
+	}
+	var manifestConfigs []map[string]interface{}
+	if err := convert(rb.spec.ManifestConfigs, &manifestConfigs); err != nil {
+		return nil, err
+	}
+	var bundleStatus map[string]interface{}
+	if rb.status != nil {
+		if err := convert(rb.status, &bundleStatus); err != nil {
+			return nil, err
+		}
+	}
+
+	kind := "ResourceBundle"
+	href := resourceBundlesPath + "/" + rb.id
+	return &openapi.ResourceBundle{
+		Id:              &rb.id,
+		Kind:            &kind,
+		Href:            &href,
+		Name:            &rb.meta.Name,
+		ConsumerName:    &rb.consumer,
+		Version:         &rb.version,
+		CreatedAt:       &rb.createdAt,
+		UpdatedAt:       &rb.updatedAt,
+		Metadata:        metadata,
+		Manifests:       manifests,
+		DeleteOption:    deleteOption,
+		ManifestConfigs: manifestConfigs,
+		Status:          bundleStatus,
+	}, nil
+}
+
+// matches reports whether the stored work matches a resource bundle search
+// built by the maestro client: source, consumer names and label equality
+func (rb *resourceBundle) matches(search string) (bool, error) {
+	if m := searchSourcePattern.FindStringSubmatch(search); m != nil && m[1] != rb.source {
+		return false, nil
+	}
+	if consumers := searchConsumerPattern.FindAllStringSubmatch(search, -1); len(consumers) > 0 {
+		found := false
+		for _, m := range consumers {
+			found = found || m[1] == rb.consumer
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	rest := searchLabelsPattern.ReplaceAllString(search, "")
+	if strings.Contains(rest, "payload->") {
+		return false, fmt.Errorf("unsupported label search %q", search)
+	}
+	for _, m := range searchLabelsPattern.FindAllStringSubmatch(search, -1) {
+		var labels map[string]string
+		if err := json.Unmarshal([]byte(m[1]), &labels); err != nil {
+			return false, fmt.Errorf("invalid label search %q: %w", m[1], err)
+		}
+		for key, value := range labels {
+			if rb.meta.Labels[key] != value {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// handleGetResourceBundle serves GET /api/maestro/v1/resource-bundles/{id}
+func (s *Server) handleGetResourceBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, resourceBundlesPath+"/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rb, ok := s.bundles[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "resource bundle with id='%s' not found", id)
+		return
+	}
+	bundle, err := rb.toResourceBundle()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	writeJSON(w, bundle)
+}
+
+// handleListResourceBundles serves GET /api/maestro/v1/resource-bundles?search=&page=&size=
+func (s *Server) handleListResourceBundles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+		return
+	}
+	search := r.URL.Query().Get("search")
+
+	s.mu.Lock()
+	matched := []*resourceBundle{}
+	for _, rb := range s.bundles {
+		ok, err := rb.matches(search)
+		if err != nil {
+			s.mu.Unlock()
+			writeError(w, http.StatusBadRequest, "%v", err)
+			return
+		}
+		if ok {
+			matched = append(matched, rb)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].createdAt.Before(matched[j].createdAt) })
+
+	page, size := pagination(r)
+	list := openapi.ResourceBundleList{
+		Kind:  "ResourceBundleList",
+		Page:  int32(page),
+		Total: int32(len(matched)),
+		Items: []openapi.ResourceBundle{},
+	}
+	for i := (page - 1) * size; i < len(matched) && i < page*size; i++ {
+		bundle, err := matched[i].toResourceBundle()
+		if err != nil {
+			s.mu.Unlock()
+			writeError(w, http.StatusInternalServerError, "%v", err)
+			return
+		}
+		list.Items = append(list.Items, *bundle)
+	}
+	s.mu.Unlock()
+
+	list.Size = int32(len(list.Items))
+	writeJSON(w, list)
+}
+
+// handleListConsumers serves GET /api/maestro/v1/consumers, which the client uses as a ping
+func (s *Server) handleListConsumers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+		return
+	}
+
+	s.mu.Lock()
+	names := make([]string, 0, len(s.consumers))
+	for name := range s.consumers {
+		names = append(names, name)
+	}
+	s.mu.Unlock()
+	sort.Strings(names)
+
+	page, size := pagination(r)
+	list := openapi.ConsumerList{
+		Kind:  "ConsumerList",
+		Page:  int32(page),
+		Total: int32(len(names)),
+		Items: []openapi.Consumer{},
+	}
+	for i := (page - 1) * size; i < len(names) && i < page*size; i++ {
+		name := names[i]
+		list.Items = append(list.Items, openapi.Consumer{Id: &name, Name: &name})
+	}
+	list.Size = int32(len(list.Items))
+	writeJSON(w, list)
+}
+
+// pagination returns the 1-based page and the page size of a list request
+func pagination(r *http.Request) (page, size int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	size, err = strconv.Atoi(r.URL.Query().Get("size"))
+	if err != nil || size < 1 {
+		size = 100
+	}
+	return page, size
+}
+
+// convert round-trips in through JSON into out
+func convert(in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v) //nolint:errcheck // the client sees a truncated body
+}
+
+func writeError(w http.ResponseWriter, code int, format string, args ...interface{}) {
+	reason := fmt.Sprintf(format, args...)
+	kind := "Error"
+	errorCode := fmt.Sprintf("maestro-mock-%d", code)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	//nolint:errcheck // the client sees a truncated body
+	_ = json.NewEncoder(w).Encode(openapi.Error{Kind: &kind, Code: &errorCode, Reason: &reason})
+}
@@ -0,0 +1,301 @@
+//go:build integration
+
+package maestro
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/maestroclient"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/manifest"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/constants"
+	apperrors "github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/errors"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"open-cluster-management.io/sdk-go/pkg/cloudevents/generic/clients"
+	"open-cluster-management.io/sdk-go/pkg/cloudevents/generic/types"
+)
+
+const (
+	testSourceID = "hyperfleet-adapter-test"
+	testConsumer = "cluster-1"
+
+	// reconnectTimeout bounds how long the client takes to resubscribe after a server restart
+	reconnectTimeout = 10 * time.Second
+)
+
+func TestMain(m *testing.M) {
+	// The CloudEvents client backs off 5s to 1min between reconnects, and the backoff grows
+	// across the tests of the process; retry quickly against the local server instead
+	clients.DelayFn = func() time.Duration { return 100 * time.Millisecond }
+	os.Exit(m.Run())
+}
+
+// startServer starts a mock server with testConsumer registered, stopped at the end of the test
+func startServer(t *testing.T) *Server {
+	t.Helper()
+	srv, err := NewServer(testConsumer)
+	require.NoError(t, err)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newClient connects a maestro client to srv for the duration of the test
+func newClient(t *testing.T, srv *Server) *maestroclient.Client {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	client, err := maestroclient.NewMaestroClient(ctx, &maestroclient.Config{
+		MaestroServerAddr: srv.APIURL(),
+		GRPCServerAddr:    srv.GRPCAddr(),
+		SourceID:          testSourceID,
+		Insecure:          true,
+	}, logger.NewTestLogger())
+	if err != nil {
+		cancel()
+		require.NoError(t, err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		_ = client.Close() //nolint:errcheck // the connection is gone with the test
+	})
+
+	// The client subscribes asynchronously; status updates need the stream
+	require.Eventually(t, func() bool { return srv.Subscribers() > 0 }, 10*time.Second, 50*time.Millisecond,
+		"client should subscribe")
+	return client
+}
+
+// testWork renders a ManifestWork carrying a namespace, both at generation
+func testWork(t *testing.T, name string, generation int64) []byte {
+	t.Helper()
+	gen := fmt.Sprintf("%d", generation)
+	namespace, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata": map[string]interface{}{
+			"name":        name + "-ns",
+			"annotations": map[string]interface{}{constants.AnnotationGeneration: gen},
+		},
+	})
+	require.NoError(t, err)
+	work, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "work.open-cluster-management.io/v1",
+		"kind":       "ManifestWork",
+		"metadata": map[string]interface{}{
+			"name":        name,
+			"labels":      map[string]interface{}{"hyperfleet.io/cluster-id": testConsumer},
+			"annotations": map[string]interface{}{constants.AnnotationGeneration: gen},
+		},
+		"spec": map[string]interface{}{
+			"workload": map[string]interface{}{
+				"manifests": []json.RawMessage{namespace},
+			},
+		},
+	})
+	require.NoError(t, err)
+	return work
+}
+
+// apply applies a rendered ManifestWork for testConsumer
+func apply(ctx context.Context, client *maestroclient.Client, work []byte) (manifest.Operation, error) {
+	return applyTo(ctx, client, testConsumer, work)
+}
+
+// applyTo applies a rendered ManifestWork for consumer
+func applyTo(
+	ctx context.Context, client *maestroclient.Client, consumer string, work []byte,
+) (manifest.Operation, error) {
+	result, err := client.ApplyResource(ctx, work, nil, &maestroclient.TransportContext{ConsumerName: consumer})
+	if err != nil {
+		return "", err
+	}
+	return result.Operation, nil
+}
+
+func TestTransport_CreateUpdateDelete(t *testing.T) {
+	srv := startServer(t)
+	client := newClient(t, srv)
+	ctx := context.Background()
+
+	op, err := apply(ctx, client, testWork(t, "work-1", 1))
+	require.NoError(t, err)
+	assert.Equal(t, manifest.OperationCreate, op)
+
+	stored, ok := srv.Work(testConsumer, "work-1")
+	require.True(t, ok, "the created work should be stored")
+	assert.Equal(t, "1", stored.Annotations[constants.AnnotationGeneration])
+	require.Len(t, stored.Spec.Workload.Manifests, 1)
+
+	// Same generation: nothing is published
+	op, err = apply(ctx, client, testWork(t, "work-1", 1))
+	require.NoError(t, err)
+	assert.Equal(t, manifest.OperationSkip, op)
+	require.Len(t, srv.Received(), 1)
+
+	// Generation bump: the work is patched and republished
+	op, err = apply(ctx, client, testWork(t, "work-1", 2))
+	require.NoError(t, err)
+	assert.Equal(t, manifest.OperationUpdate, op)
+
+	stored, ok = srv.Work(testConsumer, "work-1")
+	require.True(t, ok)
+	assert.Equal(t, "2", stored.Annotations[constants.AnnotationGeneration])
+	assert.Equal(t, int64(2), stored.Generation, "each update bumps the Maestro resource version")
+
+	got, err := client.GetManifestWork(ctx, testConsumer, "work-1")
+	require.NoError(t, err)
+	assert.Equal(t, "2", got.Annotations[constants.AnnotationGeneration])
+
+	require.NoError(t, client.DeleteManifestWork(ctx, testConsumer, "work-1"))
+	_, ok = srv.Work(testConsumer, "work-1")
+	assert.False(t, ok, "the deleted work should be gone")
+	_, err = client.GetManifestWork(ctx, testConsumer, "work-1")
+	assert.True(t, apierrors.IsNotFound(err), "expected NotFound, got %v", err)
+
+	received := srv.Received()
+	require.Len(t, received, 3)
+	assert.Equal(t, types.CreateRequestAction, received[0].Action)
+	assert.Equal(t, types.UpdateRequestAction, received[1].Action)
+	assert.Equal(t, types.DeleteRequestAction, received[2].Action)
+	for _, r := range received {
+		assert.Equal(t, testSourceID, r.Source)
+		assert.Equal(t, testConsumer, r.Work.Namespace)
+		assert.Equal(t, "work-1", r.Work.Name)
+	}
+	assert.Equal(t, "1", received[0].Work.Annotations[constants.AnnotationGeneration])
+	assert.Equal(t, "2", received[1].Work.Annotations[constants.AnnotationGeneration])
+	assert.Equal(t, int64(1), received[1].ResourceVersion, "the update is based on the stored version")
+}
+
+func TestTransport_Status(t *testing.T) {
+	srv := startServer(t)
+	client := newClient(t, srv)
+	ctx := context.Background()
+
+	_, err := apply(ctx, client, testWork(t, "work-status", 1))
+	require.NoError(t, err)
+
+	watcher, err := client.WorkClient().ManifestWorks(testConsumer).Watch(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	defer watcher.Stop()
+
+	applied := workv1.ManifestWorkStatus{
+		Conditions: []metav1.Condition{{
+			Type:               workv1.WorkApplied,
+			Status:             metav1.ConditionTrue,
+			Reason:             "AppliedManifestWorkComplete",
+			LastTransitionTime: metav1.Now(),
+		}},
+	}
+	require.NoError(t, srv.SetStatus(testConsumer, "work-status", applied))
+
+	// The status update is streamed to the watcher...
+	require.Eventually(t, func() bool {
+		for {
+			select {
+			case evt := <-watcher.ResultChan():
+				work, ok := evt.Object.(*workv1.ManifestWork)
+				if ok && evt.Type == watch.Modified && work.Name == "work-status" &&
+					conditionTrue(work.Status.Conditions, workv1.WorkApplied) {
+					return true
+				}
+			default:
+				return false
+			}
+		}
+	}, 10*time.Second, 50*time.Millisecond, "watcher should see the status update")
+
+	// ...and read back through the REST API
+	got, err := client.GetManifestWork(ctx, testConsumer, "work-status")
+	require.NoError(t, err)
+	assert.True(t, conditionTrue(got.Status.Conditions, workv1.WorkApplied))
+}
+
+// conditionTrue reports whether conditionType is true in conditions
+func conditionTrue(conditions []metav1.Condition, conditionType string) bool {
+	for _, c := range conditions {
+		if c.Type == conditionType {
+			return c.Status == metav1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func TestTransport_PublishErrors(t *testing.T) {
+	srv := startServer(t)
+	client := newClient(t, srv)
+	ctx := context.Background()
+
+	t.Run("scripted error", func(t *testing.T) {
+		srv.FailNextPublish(status.Error(codes.Unavailable, "maestro is overloaded"))
+		_, err := apply(ctx, client, testWork(t, "work-error", 1))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "maestro is overloaded")
+		_, ok := srv.Work(testConsumer, "work-error")
+		assert.False(t, ok, "a failed publish stores nothing")
+
+		// Only the next publish fails
+		op, err := apply(ctx, client, testWork(t, "work-error", 1))
+		require.NoError(t, err)
+		assert.Equal(t, manifest.OperationCreate, op)
+	})
+
+	t.Run("delay past the deadline", func(t *testing.T) {
+		srv.SetPublishDelay(2 * time.Second)
+		defer srv.SetPublishDelay(0)
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+		defer cancel()
+		_, err := apply(timeoutCtx, client, testWork(t, "work-slow", 1))
+		require.Error(t, err)
+	})
+
+	t.Run("unknown consumer", func(t *testing.T) {
+		_, err := applyTo(ctx, client, "unknown", testWork(t, "work-orphan", 1))
+		require.Error(t, err)
+
+		var appErr *apperrors.ServiceError
+		require.True(t, errors.As(err, &appErr), "expected a service error, got %v", err)
+		assert.True(t, appErr.Is404(), "an unregistered consumer is reported as not found: %v", err)
+	})
+}
+
+func TestTransport_ReconnectAfterRestart(t *testing.T) {
+	srv := startServer(t)
+	client := newClient(t, srv)
+	ctx := context.Background()
+
+	_, err := apply(ctx, client, testWork(t, "work-restart", 1))
+	require.NoError(t, err)
+
+	srv.Stop()
+	require.Eventually(t, func() bool { return srv.Subscribers() == 0 }, 5*time.Second, 50*time.Millisecond)
+	_, err = apply(ctx, client, testWork(t, "work-restart", 2))
+	require.Error(t, err, "publishing while the gRPC server is down should fail")
+
+	require.NoError(t, srv.Restart())
+	require.Eventually(t, func() bool { return srv.Subscribers() > 0 }, reconnectTimeout, 100*time.Millisecond,
+		"client should resubscribe after the restart")
+
+	// Works stored before the restart are still known, so the bump is an update
+	op, err := apply(ctx, client, testWork(t, "work-restart", 2))
+	require.NoError(t, err)
+	assert.Equal(t, manifest.OperationUpdate, op)
+
+	received := srv.Received()
+	require.Len(t, received, 2)
+	assert.Equal(t, types.UpdateRequestAction, received[1].Action)
+	assert.Equal(t, "2", received[1].Work.Annotations[constants.AnnotationGeneration])
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/docker/go-connections/nat"
+	"github.com/openshift-hyperfleet/hyperfleet-adapter/internal/clock"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
@@ -130,7 +131,9 @@ func StartContainer(t *testing.T, config ContainerConfig) (*ContainerResult, err
 				"Retry attempt %d/%d for %s container (waiting %v)...",
 				attempt, config.MaxRetries, config.Name, delay,
 			)
-			time.Sleep(delay)
+			if sleepErr := clock.Real.Sleep(ctx, delay); sleepErr != nil {
+				return nil, sleepErr
+			}
 		}
 
 		// Create context with timeout for this attempt
@@ -487,7 +490,9 @@ func StartSharedContainer(config ContainerConfig) (*SharedContainer, error) {
 		if attempt < config.MaxRetries {
 			delay := config.RetryDelay * time.Duration(attempt)
 			println(fmt.Sprintf("   Retrying in %v...", delay))
-			time.Sleep(delay)
+			if sleepErr := clock.Real.Sleep(ctx, delay); sleepErr != nil {
+				return nil, sleepErr
+			}
 		}
 	}
 